@@ -64,6 +64,14 @@ type DeliveryInfo interface {
 
 	// Delivered returns true if the metric was processed successfully.
 	Delivered() bool
+
+	// Accepted returns the number of metrics successfully written by the
+	// outputs, each output accepts its own copy of the metrics.
+	Accepted() int
+
+	// Rejected returns the number of metrics that could not be written by
+	// the outputs.
+	Rejected() int
 }
 
 // TrackingAccumulator is an Accumulator that provides a signal when the
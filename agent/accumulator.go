@@ -4,7 +4,9 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/memlimit"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
 )
 
 type MetricMaker interface {
@@ -17,16 +19,19 @@ type accumulator struct {
 	maker     MetricMaker
 	metrics   chan<- telegraf.Metric
 	precision time.Duration
+	input     bool
 }
 
 func NewAccumulator(
 	maker MetricMaker,
 	metrics chan<- telegraf.Metric,
 ) telegraf.Accumulator {
+	_, isInput := maker.(*models.RunningInput)
 	acc := accumulator{
 		maker:     maker,
 		metrics:   metrics,
 		precision: time.Nanosecond,
+		input:     isInput,
 	}
 	return &acc
 }
@@ -79,7 +84,7 @@ func (ac *accumulator) AddHistogram(
 func (ac *accumulator) AddMetric(m telegraf.Metric) {
 	m.SetTime(m.Time().Round(ac.precision))
 	if m := ac.maker.MakeMetric(m); m != nil {
-		ac.metrics <- m
+		ac.send(m)
 	}
 }
 
@@ -92,10 +97,18 @@ func (ac *accumulator) addMeasurement(
 ) {
 	m := metric.New(measurement, tags, fields, ac.getTime(t), tp)
 	if m := ac.maker.MakeMetric(m); m != nil {
-		ac.metrics <- m
+		ac.send(m)
 	}
 }
 
+func (ac *accumulator) send(m telegraf.Metric) {
+	// Block inputs while the memory limit is exceeded to create backpressure
+	if ac.input {
+		memlimit.Wait()
+	}
+	ac.metrics <- m
+}
+
 // AddError passes a runtime error to the accumulator.
 // The error will be tagged with the plugin name and written to the log.
 func (ac *accumulator) AddError(err error) {
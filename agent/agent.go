@@ -10,10 +10,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/robfig/cron/v3"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 )
@@ -21,12 +24,23 @@ import (
 // Agent runs a set of plugins.
 type Agent struct {
 	Config *config.Config
+
+	// Reload is called if a configuration reload is requested via the
+	// control API, reloading is not supported if unset.
+	Reload func()
+
+	taps *tapHub
+
+	flushListeners map[chan struct{}]bool
+	flushLock      sync.Mutex
 }
 
 // NewAgent returns an Agent for the given Config.
 func NewAgent(cfg *config.Config) *Agent {
 	a := &Agent{
-		Config: cfg,
+		Config:         cfg,
+		taps:           newTapHub(),
+		flushListeners: make(map[chan struct{}]bool),
 	}
 	return a
 }
@@ -105,6 +119,8 @@ func (a *Agent) Run(ctx context.Context) error {
 		time.Duration(a.Config.Agent.Interval), a.Config.Agent.Quiet,
 		a.Config.Agent.Hostname, time.Duration(a.Config.Agent.FlushInterval))
 
+	metric.SetInternLimit(a.Config.Agent.TagInternLimit)
+
 	log.Printf("D! [agent] Initializing plugins")
 	if err := a.initPlugins(); err != nil {
 		return err
@@ -123,76 +139,85 @@ func (a *Agent) Run(ctx context.Context) error {
 		}
 	}
 
-	startTime := time.Now()
+	// Metrics are only tapped if the control socket is enabled to avoid the
+	// overhead of the additional channels otherwise
+	tapping := a.Config.Agent.ControlSocket != ""
+	if tapping {
+		control := newControlServer(a, a.Config.Agent.ControlSocket)
+		if err := control.start(); err != nil {
+			return err
+		}
+		defer control.stop()
+	}
 
-	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
-	if err != nil {
+	pipelines := a.pipelines()
+	if err := checkPipelines(pipelines); err != nil {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		aggC := next
-		if len(a.Config.AggProcessors) != 0 {
-			aggC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
+	startTime := time.Now()
 
-		next, au = a.startAggregators(aggC, next, a.Config.Aggregators)
-	}
+	log.Printf("D! [agent] Connecting outputs")
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	inputsC := make([]chan<- telegraf.Metric, 0, len(pipelines))
+	for _, p := range pipelines {
+		next, ou, err := a.startOutputs(ctx, p.outputs)
+		if err != nil {
+			a.stopPipelines(units, inputsC)
+			return err
+		}
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+		unit := &pipelineUnit{outputs: ou}
+		next, err = a.startProcessing(next, p, unit, tapping)
 		if err != nil {
+			stopRunningOutputs(ou.outputs)
+			a.stopPipelines(units, inputsC)
 			return err
 		}
+		units = append(units, unit)
+		inputsC = append(inputsC, next)
 	}
 
-	iu, err := a.startInputs(next, a.Config.Inputs)
-	if err != nil {
-		return err
+	// Shed load when exceeding the memory limit
+	if a.Config.Agent.MaxMemory > 0 {
+		memCtx, memCancel := context.WithCancel(ctx)
+		memDone := make(chan struct{})
+		go func() {
+			defer close(memDone)
+			a.watchMemory(memCtx, a.Config.Outputs)
+		}()
+		defer func() {
+			memCancel()
+			<-memDone
+		}()
 	}
 
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runOutputs(ou)
-	}()
+	for i, p := range pipelines {
+		iu, err := a.startInputs(inputsC[i], p.inputs)
+		if err != nil {
+			a.stopPipelines(units, inputsC)
+			return err
+		}
+		units[i].inputs = iu
+	}
 
-	if au != nil {
+	var wg sync.WaitGroup
+	for _, unit := range units {
 		wg.Add(1)
-		go func() {
+		go func(unit *pipelineUnit) {
 			defer wg.Done()
-			a.runProcessors(apu)
-		}()
+			a.runOutputs(unit.outputs)
+		}(unit)
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
+		a.runProcessing(&wg, startTime, unit)
 
-	if pu != nil {
 		wg.Add(1)
-		go func() {
+		go func(unit *pipelineUnit) {
 			defer wg.Done()
-			a.runProcessors(pu)
-		}()
+			a.runInputs(ctx, startTime, unit.inputs)
+		}(unit)
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runInputs(ctx, startTime, iu)
-	}()
-
 	wg.Wait()
 
 	if a.Config.Persister != nil {
@@ -200,10 +225,13 @@ func (a *Agent) Run(ctx context.Context) error {
 		if err := a.Config.Persister.Store(); err != nil {
 			return err
 		}
+		if err := a.Config.Persister.Close(); err != nil {
+			log.Printf("E! [agent] Closing state store failed: %v", err)
+		}
 	}
 
 	log.Printf("D! [agent] Stopped Successfully")
-	return err
+	return nil
 }
 
 // initPlugins runs the Init function on plugins.
@@ -259,7 +287,7 @@ func (a *Agent) initPersister() error {
 
 		name := input.LogName()
 		id := input.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register("inputs."+input.Config.Name, id, plugin); err != nil {
 			return fmt.Errorf("could not register input %s: %w", name, err)
 		}
 	}
@@ -272,7 +300,7 @@ func (a *Agent) initPersister() error {
 
 		name := processor.LogName()
 		id := processor.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register("processors."+processor.Config.Name, id, plugin); err != nil {
 			return fmt.Errorf("could not register processor %s: %w", name, err)
 		}
 	}
@@ -285,7 +313,7 @@ func (a *Agent) initPersister() error {
 
 		name := aggregator.LogName()
 		id := aggregator.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register("aggregators."+aggregator.Config.Name, id, plugin); err != nil {
 			return fmt.Errorf("could not register aggregator %s: %w", name, err)
 		}
 	}
@@ -298,7 +326,7 @@ func (a *Agent) initPersister() error {
 
 		name := processor.LogName()
 		id := processor.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register("processors."+processor.Config.Name, id, plugin); err != nil {
 			return fmt.Errorf("could not register aggregating processor %s: %w", name, err)
 		}
 	}
@@ -311,7 +339,7 @@ func (a *Agent) initPersister() error {
 
 		name := output.LogName()
 		id := output.ID()
-		if err := a.Config.Persister.Register(id, plugin); err != nil {
+		if err := a.Config.Persister.Register("outputs."+output.Config.Name, id, plugin); err != nil {
 			return fmt.Errorf("could not register output %s: %w", name, err)
 		}
 	}
@@ -331,22 +359,7 @@ func (a *Agent) startInputs(
 
 	for _, input := range inputs {
 		if si, ok := input.Input.(telegraf.ServiceInput); ok {
-			// Service input plugins are not normally subject to timestamp
-			// rounding except for when precision is set on the input plugin.
-			//
-			// This only applies to the accumulator passed to Start(), the
-			// Gather() accumulator does apply rounding according to the
-			// precision and interval agent/plugin settings.
-			var interval time.Duration
-			var precision time.Duration
-			if input.Config.Precision != 0 {
-				precision = input.Config.Precision
-			}
-
-			acc := NewAccumulator(input, dst)
-			acc.SetPrecision(getPrecision(precision, interval))
-
-			err := si.Start(acc)
+			err := si.Start(newServiceAccumulator(input, dst))
 			if err != nil {
 				stopServiceInputs(unit.inputs)
 				return nil, fmt.Errorf("starting input %s: %w", input.LogName(), err)
@@ -358,6 +371,26 @@ func (a *Agent) startInputs(
 	return unit, nil
 }
 
+// newServiceAccumulator creates the accumulator passed to Start() of service
+// inputs.
+func newServiceAccumulator(input *models.RunningInput, dst chan<- telegraf.Metric) telegraf.Accumulator {
+	// Service input plugins are not normally subject to timestamp
+	// rounding except for when precision is set on the input plugin.
+	//
+	// This only applies to the accumulator passed to Start(), the
+	// Gather() accumulator does apply rounding according to the
+	// precision and interval agent/plugin settings.
+	var interval time.Duration
+	var precision time.Duration
+	if input.Config.Precision != 0 {
+		precision = input.Config.Precision
+	}
+
+	acc := NewAccumulator(input, dst)
+	acc.SetPrecision(getPrecision(precision, interval))
+	return acc
+}
+
 // runInputs starts and triggers the periodic gather for Inputs.
 //
 // When the context is done the timers are stopped and this function returns
@@ -395,7 +428,21 @@ func (a *Agent) runInputs(
 		}
 
 		var ticker Ticker
-		if a.Config.Agent.RoundInterval {
+		var schedule cron.Schedule
+		if input.Config.Schedule != "" {
+			var err error
+			if schedule, err = cron.ParseStandard(input.Config.Schedule); err != nil {
+				log.Printf("E! [agent] Invalid schedule for %s, using interval instead: %v", input.LogName(), err)
+			}
+		}
+		if schedule != nil {
+			ticker = NewCronTicker(startTime, schedule, jitter, offset)
+
+			// Use the time between two scheduled runs as interval for the
+			// precision and the warnings about slow collections
+			next := schedule.Next(startTime)
+			interval = schedule.Next(next).Sub(next)
+		} else if a.Config.Agent.RoundInterval {
 			ticker = NewAlignedTicker(startTime, interval, jitter, offset)
 		} else {
 			ticker = NewUnalignedTicker(interval, jitter, offset)
@@ -549,28 +596,52 @@ func (a *Agent) gatherLoop(
 ) {
 	defer panicRecover(input)
 
+	// Collection abandoned by the watchdog, no further collections are run
+	// on the instance until it completed
+	var abandoned *abandonedCall
 	for {
 		select {
 		case <-ticker.Elapsed():
-			err := a.gatherOnce(acc, input, ticker, interval)
+			if input.Paused() {
+				continue
+			}
+			if abandoned != nil {
+				if abandoned.running() {
+					log.Printf("D! [%s] Abandoned collection has not completed; scheduled collection skipped",
+						input.LogName())
+					continue
+				}
+				abandoned = nil
+			}
+
+			var err error
+			abandoned, err = a.gatherOnce(acc, input, ticker, interval)
 			if err != nil {
 				acc.AddError(err)
 			}
 		case <-ctx.Done():
+			// Do not stop the input while the collection is still running
+			if abandoned != nil {
+				abandoned.wait()
+			}
 			return
 		}
 	}
 }
 
 // gatherOnce runs the input's Gather function once, logging a warning each
-// interval it fails to complete before.
+// interval it fails to complete before. Collections still running after the
+// configured number of watchdog intervals are reported and optionally
+// abandoned. The abandoned collection is returned to block further
+// collections until it completed.
 func (a *Agent) gatherOnce(
 	acc telegraf.Accumulator,
 	input *models.RunningInput,
 	ticker Ticker,
 	interval time.Duration,
-) error {
-	done := make(chan error)
+) (*abandonedCall, error) {
+	// Use a buffered channel to allow abandoned collections to finish
+	done := make(chan error, 1)
 	go func() {
 		done <- input.Gather(acc)
 	}()
@@ -581,14 +652,25 @@ func (a *Agent) gatherOnce(
 	slowWarning := time.NewTicker(interval)
 	defer slowWarning.Stop()
 
+	var elapsed int
 	for {
 		select {
 		case err := <-done:
-			return err
+			return nil, err
 		case <-slowWarning.C:
 			log.Printf("W! [%s] Collection took longer than expected; not complete after interval of %s",
 				input.LogName(), interval)
 			input.IncrGatherTimeouts()
+
+			elapsed++
+			if limit := a.Config.Agent.WatchdogGatherIntervals; limit > 0 && elapsed == limit {
+				log.Printf("E! [%s] Collection hung for %d intervals; goroutines of the plugin:\n%s",
+					input.LogName(), elapsed, pluginStacks(input.Input))
+				if a.Config.Agent.WatchdogRestart {
+					abandoned := &abandonedCall{name: input.LogName(), kind: "collection", done: done}
+					return abandoned, errors.New("hung collection abandoned")
+				}
+			}
 		case <-ticker.Elapsed():
 			log.Printf("D! [%s] Previous collection has not completed; scheduled collection skipped",
 				input.LogName())
@@ -688,7 +770,7 @@ func (a *Agent) runAggregators(
 
 	// Before calling Add, initialize the aggregation window.  This ensures
 	// that any metric created after start time will be aggregated.
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
 		since, until := updateWindow(startTime, a.Config.Agent.RoundInterval, agg.Period())
 		agg.UpdateWindow(since, until)
 	}
@@ -699,7 +781,7 @@ func (a *Agent) runAggregators(
 		defer wg.Done()
 		for metric := range unit.src {
 			var dropOriginal bool
-			for _, agg := range a.Config.Aggregators {
+			for _, agg := range unit.aggregators {
 				if ok := agg.Add(metric); ok {
 					dropOriginal = true
 				}
@@ -714,7 +796,7 @@ func (a *Agent) runAggregators(
 		cancel()
 	}()
 
-	for _, agg := range a.Config.Aggregators {
+	for _, agg := range unit.aggregators {
 		wg.Add(1)
 		go func(agg *models.RunningAggregator) {
 			defer wg.Done()
@@ -859,12 +941,14 @@ func (a *Agent) runOutputs(
 		}(output)
 	}
 
-	for metric := range unit.src {
+	// The outputs share the metrics and only copy them when modifying e.g.
+	// the name or the tags of a metric
+	for m := range unit.src {
 		for i, output := range unit.outputs {
-			if i == len(a.Config.Outputs)-1 {
-				output.AddMetric(metric)
+			if i == len(unit.outputs)-1 {
+				output.AddMetric(m)
 			} else {
-				output.AddMetric(metric.Copy())
+				output.AddMetric(metric.CopyOnWrite(m))
 			}
 		}
 	}
@@ -895,50 +979,147 @@ func (a *Agent) flushLoop(
 	watchForFlushSignal(flushRequested)
 	defer stopListeningForFlushSignal(flushRequested)
 
+	// watch for flush requests via the control API
+	flushTriggered := a.watchFlush()
+	defer a.unwatchFlush(flushTriggered)
+
+	// Write abandoned by the watchdog, no further writes are run on the
+	// instance until it completed and the output is connected again
+	var abandoned *abandonedCall
+	restart := func() {
+		abandoned = nil
+		if err := a.restartOutput(output); err != nil {
+			log.Printf("E! [agent] Restarting [%q] failed: %v", output.LogName(), err)
+		}
+	}
+	ready := func() bool {
+		if abandoned == nil {
+			return true
+		}
+		if abandoned.running() {
+			log.Printf("D! [agent] [%q] abandoned write has not completed; flush skipped", output.LogName())
+			return false
+		}
+		restart()
+		return true
+	}
+	flush := func(writeFunc func() error) {
+		if !ready() {
+			return
+		}
+
+		var err error
+		abandoned, err = a.flushOnce(output, ticker, writeFunc)
+		logError(err)
+	}
+	shutdown := func() {
+		if abandoned != nil {
+			abandoned.wait()
+			restart()
+		}
+		flush(output.Write)
+	}
+
 	for {
 		// Favor shutdown over other methods.
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			shutdown()
 			return
 		default:
 		}
 
 		select {
 		case <-ctx.Done():
-			logError(a.flushOnce(output, ticker, output.Write))
+			shutdown()
 			return
 		case <-ticker.Elapsed():
-			logError(a.flushOnce(output, ticker, output.Write))
+			flush(output.Write)
 		case <-flushRequested:
-			logError(a.flushOnce(output, ticker, output.Write))
+			flush(output.Write)
+		case <-flushTriggered:
+			flush(output.Write)
 		case <-output.BatchReady:
-			logError(a.flushBatch(output, output.WriteBatch))
+			if ready() {
+				logError(a.flushBatch(output, output.WriteBatch))
+			}
+		}
+	}
+}
+
+// watchFlush returns a channel notified on flush requests
+func (a *Agent) watchFlush() chan struct{} {
+	ch := make(chan struct{}, 1)
+	a.flushLock.Lock()
+	a.flushListeners[ch] = true
+	a.flushLock.Unlock()
+	return ch
+}
+
+// unwatchFlush stops notifying the channel on flush requests
+func (a *Agent) unwatchFlush(ch chan struct{}) {
+	a.flushLock.Lock()
+	delete(a.flushListeners, ch)
+	a.flushLock.Unlock()
+}
+
+// requestFlush triggers an immediate flush of all running outputs and returns
+// the number of notified outputs
+func (a *Agent) requestFlush() int {
+	a.flushLock.Lock()
+	defer a.flushLock.Unlock()
+
+	for ch := range a.flushListeners {
+		// A pending request already causes a flush
+		select {
+		case ch <- struct{}{}:
+		default:
 		}
 	}
+	return len(a.flushListeners)
 }
 
 // flushOnce runs the output's Write function once, logging a warning each
-// interval it fails to complete before the flush interval elapses.
+// interval it fails to complete before the flush interval elapses. Writes
+// still running after the watchdog timeout are reported and optionally
+// abandoned. The abandoned write is returned to block further writes until it
+// completed.
 func (a *Agent) flushOnce(
 	output *models.RunningOutput,
 	ticker Ticker,
 	writeFunc func() error,
-) error {
-	done := make(chan error)
+) (*abandonedCall, error) {
+	// Use a buffered channel to allow abandoned writes to finish
+	done := make(chan error, 1)
 	go func() {
 		done <- writeFunc()
 	}()
 
+	// Report and optionally abandon hung writes
+	var deadline <-chan time.Time
+	if timeout := time.Duration(a.Config.Agent.WatchdogWriteTimeout); timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
 	for {
 		select {
 		case err := <-done:
 			output.LogBufferStatus()
-			return err
+			return nil, err
 		case <-ticker.Elapsed():
 			log.Printf("W! [agent] [%q] did not complete within its flush interval",
 				output.LogName())
 			output.LogBufferStatus()
+		case <-deadline:
+			deadline = nil
+			log.Printf("E! [agent] [%q] write hung for more than %s; goroutines of the plugin:\n%s",
+				output.LogName(), time.Duration(a.Config.Agent.WatchdogWriteTimeout), pluginStacks(output.Output))
+			if a.Config.Agent.WatchdogRestart {
+				abandoned := &abandonedCall{name: output.LogName(), kind: "write", done: done}
+				return abandoned, errors.New("hung write abandoned")
+			}
 		}
 	}
 }
@@ -998,62 +1179,49 @@ func (a *Agent) runTest(ctx context.Context, wait time.Duration, outputC chan<-
 
 	startTime := time.Now()
 
-	next := outputC
-
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 {
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
-
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
-	}
-
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+	// Merge the metrics of all pipelines into the output channel
+	pipelines := a.pipelines()
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	inputsC := make([]chan<- telegraf.Metric, 0, len(pipelines))
+	var forward sync.WaitGroup
+	for _, p := range pipelines {
+		src := make(chan telegraf.Metric, 100)
+		unit := &pipelineUnit{}
+		next, err := a.startProcessing(src, p, unit, false)
 		if err != nil {
+			a.stopPipelines(units, inputsC)
+			forward.Wait()
 			return err
 		}
-	}
-
-	iu := a.testStartInputs(next, a.Config.Inputs)
-
-	var wg sync.WaitGroup
-	if au != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runProcessors(apu)
-		}()
 
-		wg.Add(1)
+		forward.Add(1)
 		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
+			defer forward.Done()
+			for m := range src {
+				outputC <- m
+			}
 		}()
+		units = append(units, unit)
+		inputsC = append(inputsC, next)
 	}
 
-	if pu != nil {
+	var wg sync.WaitGroup
+	for i, p := range pipelines {
+		unit := units[i]
+		unit.inputs = a.testStartInputs(inputsC[i], p.inputs)
+
+		a.runProcessing(&wg, startTime, unit)
+
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.testRunInputs(ctx, wait, unit.inputs)
 		}()
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.testRunInputs(ctx, wait, iu)
-	}()
-
 	wg.Wait()
+	forward.Wait()
+	close(outputC)
 
 	log.Printf("D! [agent] Stopped Successfully")
 
@@ -1091,73 +1259,54 @@ func (a *Agent) runOnce(ctx context.Context, wait time.Duration) error {
 		return err
 	}
 
-	startTime := time.Now()
-
-	log.Printf("D! [agent] Connecting outputs")
-	next, ou, err := a.startOutputs(ctx, a.Config.Outputs)
-	if err != nil {
+	pipelines := a.pipelines()
+	if err := checkPipelines(pipelines); err != nil {
 		return err
 	}
 
-	var apu []*processorUnit
-	var au *aggregatorUnit
-	if len(a.Config.Aggregators) != 0 {
-		procC := next
-		if len(a.Config.AggProcessors) != 0 {
-			procC, apu, err = a.startProcessors(next, a.Config.AggProcessors)
-			if err != nil {
-				return err
-			}
-		}
+	startTime := time.Now()
 
-		next, au = a.startAggregators(procC, next, a.Config.Aggregators)
-	}
+	log.Printf("D! [agent] Connecting outputs")
+	units := make([]*pipelineUnit, 0, len(pipelines))
+	inputsC := make([]chan<- telegraf.Metric, 0, len(pipelines))
+	for _, p := range pipelines {
+		next, ou, err := a.startOutputs(ctx, p.outputs)
+		if err != nil {
+			a.stopPipelines(units, inputsC)
+			return err
+		}
 
-	var pu []*processorUnit
-	if len(a.Config.Processors) != 0 {
-		next, pu, err = a.startProcessors(next, a.Config.Processors)
+		unit := &pipelineUnit{outputs: ou}
+		next, err = a.startProcessing(next, p, unit, false)
 		if err != nil {
+			stopRunningOutputs(ou.outputs)
+			a.stopPipelines(units, inputsC)
 			return err
 		}
+		units = append(units, unit)
+		inputsC = append(inputsC, next)
 	}
 
-	iu := a.testStartInputs(next, a.Config.Inputs)
-
 	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.runOutputs(ou)
-	}()
+	for i, p := range pipelines {
+		unit := units[i]
+		unit.inputs = a.testStartInputs(inputsC[i], p.inputs)
 
-	if au != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(apu)
+			a.runOutputs(unit.outputs)
 		}()
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			a.runAggregators(startTime, au)
-		}()
-	}
+		a.runProcessing(&wg, startTime, unit)
 
-	if pu != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			a.runProcessors(pu)
+			a.testRunInputs(ctx, wait, unit.inputs)
 		}()
 	}
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		a.testRunInputs(ctx, wait, iu)
-	}()
-
 	wg.Wait()
 
 	log.Printf("D! [agent] Stopped Successfully")
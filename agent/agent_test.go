@@ -14,12 +14,15 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	csvSerializer "github.com/influxdata/telegraf/plugins/serializers/csv"
+	influxSerializer "github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -260,3 +263,67 @@ func collect(ctx context.Context, a *Agent, wait time.Duration) ([]telegraf.Metr
 	}
 	return received, nil
 }
+
+type serializingOutput struct {
+	serializer telegraf.Serializer
+	written    []byte
+}
+
+func (*serializingOutput) SampleConfig() string {
+	return ""
+}
+
+func (*serializingOutput) Connect() error {
+	return nil
+}
+
+func (*serializingOutput) Close() error {
+	return nil
+}
+
+func (o *serializingOutput) Write(metrics []telegraf.Metric) error {
+	buf, err := o.serializer.SerializeBatch(metrics)
+	if err != nil {
+		return err
+	}
+	o.written = append(o.written, buf...)
+	return nil
+}
+
+// Outputs share the metrics, so serializers sorting the fields must not
+// modify the shared field list. Run with -race to detect violations.
+func TestRunOutputsSharedMetricSorted(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+
+	src := make(chan telegraf.Metric, 1)
+	unit := &outputUnit{src: src}
+	serializers := []telegraf.Serializer{
+		&influxSerializer.Serializer{SortFields: true},
+		&influxSerializer.Serializer{SortFields: true},
+		&csvSerializer.Serializer{Header: true},
+		&csvSerializer.Serializer{Header: true},
+	}
+	plugins := make([]*serializingOutput, 0, len(serializers))
+	for _, serializer := range serializers {
+		require.NoError(t, serializer.(telegraf.Initializer).Init())
+		plugin := &serializingOutput{serializer: serializer}
+		plugins = append(plugins, plugin)
+
+		output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "serializing"}, 10, 100)
+		require.NoError(t, output.Init())
+		unit.outputs = append(unit.outputs, output)
+	}
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{}, time.Unix(0, 0))
+	for _, key := range []string{"d", "c", "b", "a", "f", "e"} {
+		m.AddField(key, 1)
+	}
+	src <- m
+	close(src)
+	a.runOutputs(unit)
+
+	require.Equal(t, "test a=1i,b=1i,c=1i,d=1i,e=1i,f=1i 0\n", string(plugins[0].written))
+	require.Equal(t, "test a=1i,b=1i,c=1i,d=1i,e=1i,f=1i 0\n", string(plugins[1].written))
+	require.Equal(t, "timestamp,measurement,a,b,c,d,e,f\n0,test,1,1,1,1,1,1\n", string(plugins[2].written))
+	require.Equal(t, "timestamp,measurement,a,b,c,d,e,f\n0,test,1,1,1,1,1,1\n", string(plugins[3].written))
+}
@@ -0,0 +1,378 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/influxdata/wlog"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// PluginStatus describes a running plugin in the responses of the control API
+type PluginStatus struct {
+	ID     string           `json:"id"`
+	Name   string           `json:"name"`
+	Alias  string           `json:"alias,omitempty"`
+	Paused bool             `json:"paused,omitempty"`
+	Stats  map[string]int64 `json:"stats,omitempty"`
+}
+
+// PluginList contains the status of all running plugins by category
+type PluginList struct {
+	Inputs      []PluginStatus `json:"inputs"`
+	Processors  []PluginStatus `json:"processors"`
+	Aggregators []PluginStatus `json:"aggregators"`
+	Outputs     []PluginStatus `json:"outputs"`
+}
+
+// LogLevels contains the global log-level and the overrides by plugin
+type LogLevels struct {
+	Level   string            `json:"level"`
+	Plugins map[string]string `json:"plugins"`
+}
+
+// OutputBuffer contains the buffer statistics of a running output
+type OutputBuffer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Alias string `json:"alias,omitempty"`
+	models.BufferStats
+}
+
+// controlServer provides the local control API of the agent via HTTP on a
+// unix socket
+type controlServer struct {
+	agent    *Agent
+	path     string
+	listener net.Listener
+	server   *http.Server
+}
+
+func newControlServer(a *Agent, path string) *controlServer {
+	c := &controlServer{
+		agent: a,
+		path:  path,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tap", c.handleTap)
+	mux.HandleFunc("/reload", c.handleReload)
+	mux.HandleFunc("/plugins", c.handlePlugins)
+	mux.HandleFunc("/flush", c.handleFlush)
+	mux.HandleFunc("/inputs/pause", c.handlePause)
+	mux.HandleFunc("/inputs/resume", c.handlePause)
+	mux.HandleFunc("/buffers", c.handleBuffers)
+	mux.HandleFunc("/loglevel", c.handleLogLevel)
+	c.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return c
+}
+
+// start listens on the control socket and serves the API in the background
+func (c *controlServer) start() error {
+	// Remove a stale socket of a previous run
+	if info, err := os.Stat(c.path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return fmt.Errorf("control socket %q exists and is not a socket", c.path)
+		}
+		if err := os.Remove(c.path); err != nil {
+			return fmt.Errorf("removing stale control socket failed: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", c.path)
+	if err != nil {
+		return fmt.Errorf("listening on control socket failed: %w", err)
+	}
+	if err := os.Chmod(c.path, 0660); err != nil {
+		listener.Close()
+		return fmt.Errorf("setting permissions of control socket failed: %w", err)
+	}
+	c.listener = listener
+
+	go func() {
+		if err := c.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("E! [agent] Serving control socket failed: %v", err)
+		}
+	}()
+	log.Printf("I! [agent] Control socket listening on %q", c.path)
+
+	return nil
+}
+
+// stop closes all connections and removes the socket
+func (c *controlServer) stop() {
+	if err := c.server.Close(); err != nil {
+		log.Printf("E! [agent] Closing control socket failed: %v", err)
+	}
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("E! [agent] Removing control socket failed: %v", err)
+	}
+}
+
+// handleTap streams the metrics passing a tap point in line-protocol format
+// until the client disconnects
+func (c *controlServer) handleTap(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	point := r.URL.Query().Get("point")
+	if point == "" {
+		point = TapOutput
+	}
+	t, err := c.agent.taps.subscribe(point, r.URL.Query()["filter"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer c.agent.taps.unsubscribe(t)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case line := <-t.lines:
+			if _, err := w.Write(line); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// handleReload requests a reload of the configuration. The control socket is
+// closed and reopened during the reload.
+func (c *controlServer) handleReload(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if c.agent.Reload == nil {
+		http.Error(w, "reloading not supported", http.StatusNotImplemented)
+		return
+	}
+
+	log.Printf("I! [agent] Reload requested via control socket")
+	w.WriteHeader(http.StatusAccepted)
+
+	// Reload asynchronously as the reload stops this server
+	go c.agent.Reload()
+}
+
+// handlePlugins lists the running plugins with their status and statistics
+func (c *controlServer) handlePlugins(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	list := PluginList{
+		Inputs:      make([]PluginStatus, 0, len(c.agent.Config.Inputs)),
+		Processors:  make([]PluginStatus, 0, len(c.agent.Config.Processors)),
+		Aggregators: make([]PluginStatus, 0, len(c.agent.Config.Aggregators)),
+		Outputs:     make([]PluginStatus, 0, len(c.agent.Config.Outputs)),
+	}
+	for _, input := range c.agent.Config.Inputs {
+		list.Inputs = append(list.Inputs, PluginStatus{
+			ID:     input.ID(),
+			Name:   input.Config.Name,
+			Alias:  input.Config.Alias,
+			Paused: input.Paused(),
+			Stats: map[string]int64{
+				"metrics_gathered": input.MetricsGathered.Get(),
+				"gather_time_ns":   input.GatherTime.Get(),
+				"gather_timeouts":  input.GatherTimeouts.Get(),
+			},
+		})
+	}
+	for _, processor := range c.agent.Config.Processors {
+		list.Processors = append(list.Processors, PluginStatus{
+			ID:    processor.ID(),
+			Name:  processor.Config.Name,
+			Alias: processor.Config.Alias,
+		})
+	}
+	for _, aggregator := range c.agent.Config.Aggregators {
+		list.Aggregators = append(list.Aggregators, PluginStatus{
+			ID:    aggregator.ID(),
+			Name:  aggregator.Config.Name,
+			Alias: aggregator.Config.Alias,
+			Stats: map[string]int64{
+				"metrics_pushed":   aggregator.MetricsPushed.Get(),
+				"metrics_filtered": aggregator.MetricsFiltered.Get(),
+				"metrics_dropped":  aggregator.MetricsDropped.Get(),
+			},
+		})
+	}
+	for _, output := range c.agent.Config.Outputs {
+		stats := output.BufferStats()
+		list.Outputs = append(list.Outputs, PluginStatus{
+			ID:    output.ID(),
+			Name:  output.Config.Name,
+			Alias: output.Config.Alias,
+			Stats: map[string]int64{
+				"buffer_size":      stats.Size,
+				"buffer_limit":     stats.Limit,
+				"metrics_written":  stats.Written,
+				"metrics_dropped":  stats.Dropped,
+				"metrics_filtered": output.MetricsFiltered.Get(),
+			},
+		})
+	}
+
+	writeJSON(w, list)
+}
+
+// handleFlush triggers an immediate flush of all outputs
+func (c *controlServer) handleFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	n := c.agent.requestFlush()
+	log.Printf("D! [agent] Flush of %d output(s) requested via control socket", n)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePause pauses or resumes the input given by the "id" parameter, the
+// parameter matches the ID or the alias of the input
+func (c *controlServer) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "missing input id", http.StatusBadRequest)
+		return
+	}
+
+	var input *models.RunningInput
+	for _, ri := range c.agent.Config.Inputs {
+		if ri.ID() == id || ri.Config.Alias == id {
+			input = ri
+			break
+		}
+	}
+	if input == nil {
+		http.Error(w, fmt.Sprintf("unknown input %q", id), http.StatusNotFound)
+		return
+	}
+
+	if r.URL.Path == "/inputs/pause" {
+		input.Pause()
+		log.Printf("I! [agent] Paused %s via control socket", input.LogName())
+	} else {
+		input.Resume()
+		log.Printf("I! [agent] Resumed %s via control socket", input.LogName())
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleBuffers returns the buffer statistics of all outputs
+func (c *controlServer) handleBuffers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	buffers := make([]OutputBuffer, 0, len(c.agent.Config.Outputs))
+	for _, output := range c.agent.Config.Outputs {
+		buffers = append(buffers, OutputBuffer{
+			ID:          output.ID(),
+			Name:        output.Config.Name,
+			Alias:       output.Config.Alias,
+			BufferStats: output.BufferStats(),
+		})
+	}
+
+	writeJSON(w, buffers)
+}
+
+// handleLogLevel returns the current log-levels on GET and changes the level
+// given by the "level" parameter on POST. If a "plugin" parameter is given
+// the level only applies to plugins with that log name, e.g. "inputs.cpu" or
+// "inputs.cpu::alias". The "default" level removes the override of a plugin.
+func (c *controlServer) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, LogLevels{
+			Level:   models.LogLevelName(wlog.LogLevel()),
+			Plugins: models.LogLevels(),
+		})
+	case http.MethodPost:
+		level := r.URL.Query().Get("level")
+		plugin := r.URL.Query().Get("plugin")
+		switch {
+		case plugin != "" && level == "default":
+			models.ClearLogLevel(plugin)
+			log.Printf("I! [agent] Removed log-level of %q via control socket", plugin)
+		case plugin != "":
+			if err := models.SetLogLevel(plugin, level); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			log.Printf("I! [agent] Set log-level of %q to %q via control socket", plugin, level)
+		default:
+			l, err := models.ParseLogLevel(level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			wlog.SetLevel(l)
+			log.Printf("I! [agent] Set log-level to %q via control socket", level)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(buf, '\n'))
+}
+
+// NewControlClient returns a HTTP client connecting to the control socket at
+// the given path. The host of the request URLs is ignored.
+func NewControlClient(path string) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
@@ -0,0 +1,165 @@
+package agent
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/influxdata/wlog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestControlSocket(t *testing.T) {
+	c := config.NewConfig()
+	// Load the inputs separately as the order of different plugins within one
+	// configuration is not guaranteed
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.mem]]
+  alias = "mymem"
+`)))
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.swap]]
+[[outputs.discard]]
+`)))
+
+	var reloaded atomic.Bool
+	a := NewAgent(c)
+	a.Reload = func() { reloaded.Store(true) }
+
+	path := filepath.Join(t.TempDir(), "telegraf.sock")
+	server := newControlServer(a, path)
+	require.NoError(t, server.start())
+	defer server.stop()
+
+	client := NewControlClient(path)
+	request := func(method, endpoint string) (int, []byte) {
+		req, err := http.NewRequest(method, "http://telegraf"+endpoint, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, body
+	}
+
+	// Pause and resume an input
+	status, _ := request(http.MethodPost, "/inputs/pause?id=mymem")
+	require.Equal(t, http.StatusNoContent, status)
+	require.True(t, c.Inputs[0].Paused())
+	require.False(t, c.Inputs[1].Paused())
+
+	status, body := request(http.MethodGet, "/plugins")
+	require.Equal(t, http.StatusOK, status)
+	var list PluginList
+	require.NoError(t, json.Unmarshal(body, &list))
+	require.Len(t, list.Inputs, 2)
+	require.Equal(t, "mem", list.Inputs[0].Name)
+	require.Equal(t, "mymem", list.Inputs[0].Alias)
+	require.True(t, list.Inputs[0].Paused)
+	require.Equal(t, c.Inputs[1].ID(), list.Inputs[1].ID)
+	require.False(t, list.Inputs[1].Paused)
+	require.Empty(t, list.Processors)
+	require.Empty(t, list.Aggregators)
+	require.Len(t, list.Outputs, 1)
+	require.Equal(t, int64(10000), list.Outputs[0].Stats["buffer_limit"])
+
+	status, _ = request(http.MethodPost, "/inputs/resume?id="+c.Inputs[0].ID())
+	require.Equal(t, http.StatusNoContent, status)
+	require.False(t, c.Inputs[0].Paused())
+
+	status, body = request(http.MethodPost, "/inputs/pause?id=foo")
+	require.Equal(t, http.StatusNotFound, status)
+	require.Contains(t, string(body), `unknown input "foo"`)
+
+	status, _ = request(http.MethodGet, "/inputs/pause?id=mymem")
+	require.Equal(t, http.StatusMethodNotAllowed, status)
+
+	// Check the buffer statistics
+	status, body = request(http.MethodGet, "/buffers")
+	require.Equal(t, http.StatusOK, status)
+	var buffers []OutputBuffer
+	require.NoError(t, json.Unmarshal(body, &buffers))
+	require.Equal(t, []OutputBuffer{
+		{
+			ID:          c.Outputs[0].ID(),
+			Name:        "discard",
+			BufferStats: models.BufferStats{Limit: 10000},
+		},
+	}, buffers)
+
+	// Trigger a flush
+	ch := a.watchFlush()
+	defer a.unwatchFlush(ch)
+	status, _ = request(http.MethodPost, "/flush")
+	require.Equal(t, http.StatusNoContent, status)
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		require.Fail(t, "flush not triggered")
+	}
+
+	// Request a reload
+	status, _ = request(http.MethodPost, "/reload")
+	require.Equal(t, http.StatusAccepted, status)
+	require.Eventually(t, func() bool { return reloaded.Load() }, time.Second, 10*time.Millisecond)
+}
+
+func TestControlSocketLogLevel(t *testing.T) {
+	defer models.ResetLogLevels()
+	defer wlog.SetLevel(wlog.INFO)
+	wlog.SetLevel(wlog.INFO)
+
+	a := NewAgent(config.NewConfig())
+	path := filepath.Join(t.TempDir(), "telegraf.sock")
+	server := newControlServer(a, path)
+	require.NoError(t, server.start())
+	defer server.stop()
+
+	client := NewControlClient(path)
+	post := func(query string) int {
+		resp, err := client.Post("http://telegraf/loglevel?"+query, "", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusNoContent, post("level=debug&plugin=inputs.cpu"))
+	require.Equal(t, http.StatusNoContent, post("level=error&plugin=inputs.mem"))
+	require.Equal(t, http.StatusNoContent, post("level=default&plugin=inputs.mem"))
+	require.Equal(t, http.StatusNoContent, post("level=warn"))
+	require.Equal(t, http.StatusBadRequest, post("level=foo"))
+	require.Equal(t, http.StatusBadRequest, post("level=default"))
+	require.Equal(t, wlog.WARN, wlog.LogLevel())
+
+	resp, err := client.Get("http://telegraf/loglevel")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var levels LogLevels
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&levels))
+	require.Equal(t, LogLevels{
+		Level:   "warn",
+		Plugins: map[string]string{"inputs.cpu": "debug"},
+	}, levels)
+}
+
+func TestControlSocketReloadUnsupported(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+
+	path := filepath.Join(t.TempDir(), "telegraf.sock")
+	server := newControlServer(a, path)
+	require.NoError(t, server.start())
+	defer server.stop()
+
+	resp, err := NewControlClient(path).Post("http://telegraf/reload", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotImplemented, resp.StatusCode)
+}
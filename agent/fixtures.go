@@ -0,0 +1,257 @@
+package agent
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// FixtureConfig contains the settings for testing the processing pipeline
+// using recorded payloads
+type FixtureConfig struct {
+	// Directory containing one sub-directory per input named by the alias or
+	// the name of the input
+	Directory string
+	// Update writes the current results as golden files instead of comparing
+	Update bool
+	// IgnoreTime sets the timestamp of all resulting metrics to zero to allow
+	// comparing metrics without timestamp in the payload
+	IgnoreTime bool
+}
+
+// fixtureSuffix is the file suffix of the golden output files
+const fixtureSuffix = ".expected"
+
+// RunFixtures feeds the payloads of the fixture directory through the parsers
+// of the configured inputs and the processors. The resulting metrics are
+// compared in line-protocol format against the golden output stored next to
+// the payload with an additional ".expected" suffix. The number of failed
+// fixtures is returned.
+func (a *Agent) RunFixtures(cfg FixtureConfig, w io.Writer) (int, error) {
+	log.Printf("D! [agent] Initializing plugins")
+	for _, processor := range a.Config.Processors {
+		if err := processor.Init(); err != nil {
+			return 0, fmt.Errorf("could not initialize processor %s: %w", processor.LogName(), err)
+		}
+	}
+
+	// Map the fixture directories to the inputs
+	inputs := make(map[string]*models.RunningInput, len(a.Config.Inputs))
+	for _, input := range a.Config.Inputs {
+		if input.ParserFunc == nil {
+			continue
+		}
+		id := input.Config.Alias
+		if id == "" {
+			id = input.Config.Name
+		}
+		if _, found := inputs[id]; found {
+			return 0, fmt.Errorf("ambiguous fixture directory %q, please set an alias for the inputs", id)
+		}
+		inputs[id] = input
+	}
+
+	entries, err := os.ReadDir(cfg.Directory)
+	if err != nil {
+		return 0, fmt.Errorf("reading fixture directory failed: %w", err)
+	}
+
+	var total, failed int
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		input, found := inputs[entry.Name()]
+		if !found {
+			return failed, fmt.Errorf("no input with data format found for fixtures in %q", entry.Name())
+		}
+
+		dir := filepath.Join(cfg.Directory, entry.Name())
+		payloads, err := os.ReadDir(dir)
+		if err != nil {
+			return failed, fmt.Errorf("reading fixture directory failed: %w", err)
+		}
+		for _, payload := range payloads {
+			if payload.IsDir() || strings.HasSuffix(payload.Name(), fixtureSuffix) {
+				continue
+			}
+			fn := filepath.Join(dir, payload.Name())
+			total++
+
+			ok, err := a.runFixture(cfg, input, fn, w)
+			if err != nil {
+				return failed, fmt.Errorf("running fixture %q failed: %w", fn, err)
+			}
+			if !ok {
+				failed++
+			}
+		}
+	}
+
+	if total == 0 {
+		return 0, errors.New("no fixtures found")
+	}
+	fmt.Fprintf(w, "%d of %d fixture(s) passed\n", total-failed, total)
+
+	return failed, nil
+}
+
+func (a *Agent) runFixture(cfg FixtureConfig, input *models.RunningInput, fn string, w io.Writer) (bool, error) {
+	payload, err := os.ReadFile(fn)
+	if err != nil {
+		return false, err
+	}
+
+	parser, err := input.ParserFunc()
+	if err != nil {
+		return false, fmt.Errorf("creating parser failed: %w", err)
+	}
+	metrics, err := parser.Parse(payload)
+	if err != nil {
+		return false, fmt.Errorf("parsing failed: %w", err)
+	}
+
+	result, err := a.processFixture(input, metrics)
+	if err != nil {
+		return false, err
+	}
+
+	serializer := &influx.Serializer{SortFields: true}
+	if err := serializer.Init(); err != nil {
+		return false, err
+	}
+	var actual bytes.Buffer
+	for _, m := range result {
+		if cfg.IgnoreTime {
+			m.SetTime(time.Unix(0, 0))
+		}
+		octets, err := serializer.Serialize(m)
+		if err != nil {
+			return false, fmt.Errorf("serializing failed: %w", err)
+		}
+		actual.Write(octets)
+	}
+
+	goldenFn := fn + fixtureSuffix
+	if cfg.Update {
+		if err := os.WriteFile(goldenFn, actual.Bytes(), 0640); err != nil {
+			return false, err
+		}
+		fmt.Fprintf(w, "UPDATED %s\n", fn)
+		return true, nil
+	}
+
+	expected, err := os.ReadFile(goldenFn)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(expected, actual.Bytes()) {
+		fmt.Fprintf(w, "PASS %s\n", fn)
+		return true, nil
+	}
+
+	fmt.Fprintf(w, "FAIL %s\n", fn)
+	printLineDiff(w, string(expected), actual.String())
+	return false, nil
+}
+
+// processFixture passes the metrics through the input's modifications and the
+// processors and returns the resulting metrics
+func (a *Agent) processFixture(input *models.RunningInput, metrics []telegraf.Metric) ([]telegraf.Metric, error) {
+	outputC := make(chan telegraf.Metric, 100)
+
+	next := chan<- telegraf.Metric(outputC)
+	var pu []*processorUnit
+	if len(a.Config.Processors) != 0 {
+		var err error
+		next, pu, err = a.startProcessors(outputC, a.Config.Processors)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	go func() {
+		for _, m := range metrics {
+			if m = input.MakeMetric(m); m != nil {
+				next <- m
+			}
+		}
+		close(next)
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if pu != nil {
+			a.runProcessors(pu)
+		}
+	}()
+
+	result := make([]telegraf.Metric, 0, len(metrics))
+	for m := range outputC {
+		result = append(result, m)
+		m.Accept()
+	}
+	<-done
+
+	return result, nil
+}
+
+// printLineDiff prints the lines missing in the actual output prefixed by "-"
+// and the additional lines prefixed by "+"
+func printLineDiff(w io.Writer, expected, actual string) {
+	expectedLines := splitLines(expected)
+	actualLines := splitLines(actual)
+
+	count := make(map[string]int, len(expectedLines))
+	for _, line := range actualLines {
+		count[line]++
+	}
+	var missing []string
+	for _, line := range expectedLines {
+		if count[line] > 0 {
+			count[line]--
+			continue
+		}
+		missing = append(missing, line)
+	}
+	var extra []string
+	for _, line := range actualLines {
+		if count[line] > 0 {
+			count[line]--
+			extra = append(extra, line)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+
+	if len(missing) == 0 && len(extra) == 0 {
+		fmt.Fprintln(w, "  metrics are equal but in a different order")
+		return
+	}
+	for _, line := range missing {
+		fmt.Fprintf(w, "- %s\n", line)
+	}
+	for _, line := range extra {
+		fmt.Fprintf(w, "+ %s\n", line)
+	}
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestRunFixtures(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[agent]
+  omit_hostname = true
+
+[[inputs.file]]
+  files = []
+  data_format = "influx"
+
+[[processors.override]]
+  [processors.override.tags]
+    source = "fixture"
+`)))
+
+	dir := t.TempDir()
+	fixtures := filepath.Join(dir, "file")
+	require.NoError(t, os.Mkdir(fixtures, 0750))
+	payload := filepath.Join(fixtures, "payload.influx")
+	require.NoError(t, os.WriteFile(payload, []byte("cpu value=42i 1689252834000000000\n"), 0640))
+
+	// Create the golden file
+	var buf bytes.Buffer
+	failed, err := NewAgent(cfg).RunFixtures(FixtureConfig{Directory: dir, Update: true}, &buf)
+	require.NoError(t, err)
+	require.Zero(t, failed)
+	require.Contains(t, buf.String(), "UPDATED "+payload)
+
+	golden, err := os.ReadFile(payload + fixtureSuffix)
+	require.NoError(t, err)
+	require.Equal(t, "cpu,source=fixture value=42i 1689252834000000000\n", string(golden))
+
+	// Compare against the golden file
+	buf.Reset()
+	failed, err = NewAgent(cfg).RunFixtures(FixtureConfig{Directory: dir}, &buf)
+	require.NoError(t, err)
+	require.Zero(t, failed)
+	require.Contains(t, buf.String(), "PASS "+payload)
+	require.Contains(t, buf.String(), "1 of 1 fixture(s) passed")
+
+	// Detect differences
+	require.NoError(t, os.WriteFile(payload+fixtureSuffix, []byte("cpu,source=fixture value=23i 1689252834000000000\n"), 0640))
+	buf.Reset()
+	failed, err = NewAgent(cfg).RunFixtures(FixtureConfig{Directory: dir}, &buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, failed)
+	require.Contains(t, buf.String(), "FAIL "+payload)
+	require.Contains(t, buf.String(), "- cpu,source=fixture value=23i 1689252834000000000")
+	require.Contains(t, buf.String(), "+ cpu,source=fixture value=42i 1689252834000000000")
+}
+
+func TestRunFixturesUnknownInput(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[[inputs.file]]
+  files = []
+  data_format = "influx"
+`)))
+
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "unknown"), 0750))
+
+	var buf bytes.Buffer
+	_, err := NewAgent(cfg).RunFixtures(FixtureConfig{Directory: dir}, &buf)
+	require.ErrorContains(t, err, `no input with data format found for fixtures in "unknown"`)
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/metrics"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/memlimit"
+	"github.com/influxdata/telegraf/models"
+)
+
+const (
+	// Interval of checking the memory usage
+	memoryCheckInterval = time.Second
+	// Fraction of the buffered metrics dropped per check for the drop_oldest
+	// policy
+	memoryDropFraction = 0.1
+	// Fraction of the limit the memory usage must fall below to stop shedding
+	// load to avoid flapping
+	memoryReleaseFraction = 0.9
+)
+
+// heapUsage returns the memory occupied by live and not yet collected
+// objects on the heap
+func heapUsage() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
+	return sample[0].Value.Uint64()
+}
+
+// watchMemory checks the memory usage of the agent periodically and sheds
+// load according to the configured policy while the limit is exceeded until
+// the context is done.
+func (a *Agent) watchMemory(ctx context.Context, outputs []*models.RunningOutput) {
+	limit := uint64(a.Config.Agent.MaxMemory)
+	policy := a.Config.Agent.MemorySheddingPolicy
+	memlimit.SetPolicy(policy)
+
+	// Release waiting inputs on shutdown
+	defer memlimit.Update(false)
+
+	ticker := time.NewTicker(memoryCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.checkMemory(heapUsage(), limit, policy, outputs)
+		}
+	}
+}
+
+func (a *Agent) checkMemory(usage, limit uint64, policy string, outputs []*models.RunningOutput) {
+	if usage <= limit {
+		if memlimit.Exceeded() && float64(usage) < float64(limit)*memoryReleaseFraction {
+			log.Printf("I! [agent] Memory usage of %s below limit again; stop shedding load",
+				formatBytes(usage))
+			memlimit.Update(false)
+		}
+		return
+	}
+
+	if !memlimit.Exceeded() {
+		log.Printf("W! [agent] Memory usage of %s exceeds limit of %s; shedding load using policy %q",
+			formatBytes(usage), formatBytes(limit), policy)
+		memlimit.Update(true)
+	}
+
+	if policy != memlimit.PolicyDropOldest {
+		return
+	}
+	var dropped int
+	for _, output := range outputs {
+		dropped += output.DropOldest(memoryDropFraction)
+	}
+	if dropped > 0 {
+		log.Printf("W! [agent] Dropped %d buffered metrics due to memory limit", dropped)
+		// Free the memory of the dropped metrics before the next check
+		runtime.GC()
+	}
+}
+
+func formatBytes(b uint64) string {
+	return fmt.Sprintf("%.1f MiB", float64(b)/(1<<20))
+}
@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/memlimit"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestCheckMemoryDropOldest(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	defer memlimit.Update(false)
+
+	plugin := &hungOutput{release: make(chan struct{})}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "test"}, 10, 100)
+	require.NoError(t, output.Init())
+	for i := 0; i < 20; i++ {
+		output.AddMetric(testutil.TestMetric(i))
+	}
+	outputs := []*models.RunningOutput{output}
+
+	// Below the limit nothing is dropped
+	a.checkMemory(50, 100, memlimit.PolicyDropOldest, outputs)
+	require.False(t, memlimit.Exceeded())
+	require.Equal(t, 20, output.BufferLength())
+
+	// Exceeding the limit drops a fraction of the buffer on each check
+	a.checkMemory(150, 100, memlimit.PolicyDropOldest, outputs)
+	require.True(t, memlimit.Exceeded())
+	require.Equal(t, 18, output.BufferLength())
+	a.checkMemory(150, 100, memlimit.PolicyDropOldest, outputs)
+	require.Equal(t, 16, output.BufferLength())
+
+	// The limit is only released well below the limit
+	a.checkMemory(95, 100, memlimit.PolicyDropOldest, outputs)
+	require.True(t, memlimit.Exceeded())
+	a.checkMemory(80, 100, memlimit.PolicyDropOldest, outputs)
+	require.False(t, memlimit.Exceeded())
+	require.Equal(t, 16, output.BufferLength())
+}
+
+func TestCheckMemoryPause(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	memlimit.SetPolicy(memlimit.PolicyPause)
+	defer memlimit.SetPolicy("")
+	defer memlimit.Update(false)
+
+	plugin := &hungInput{release: make(chan struct{})}
+	input := models.NewRunningInput(plugin, &models.InputConfig{Name: "test"})
+	require.NoError(t, input.Init())
+	dst := make(chan telegraf.Metric, 10)
+	acc := NewAccumulator(input, dst)
+
+	a.checkMemory(150, 100, memlimit.PolicyPause, nil)
+	require.True(t, memlimit.Exceeded())
+
+	// Adding metrics blocks while the limit is exceeded
+	done := make(chan struct{})
+	go func() {
+		acc.AddFields("test", map[string]interface{}{"value": 42}, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+		require.FailNow(t, "metric added while memory limit is exceeded")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	a.checkMemory(50, 100, memlimit.PolicyPause, nil)
+	require.False(t, memlimit.Exceeded())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "adding metric still blocked after memory limit was released")
+	}
+	require.Len(t, dst, 1)
+}
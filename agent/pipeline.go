@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// pipeline is a set of plugins processing metrics isolated from the plugins of
+// other pipelines. The metrics of the pipeline's inputs are only passed to the
+// processors, aggregators and outputs of the same pipeline.
+type pipeline struct {
+	name          string
+	inputs        []*models.RunningInput
+	processors    models.RunningProcessors
+	aggProcessors models.RunningProcessors
+	aggregators   []*models.RunningAggregator
+	outputs       []*models.RunningOutput
+}
+
+func (p *pipeline) String() string {
+	if p.name == "" {
+		return "default pipeline"
+	}
+	return fmt.Sprintf("pipeline %q", p.name)
+}
+
+// pipelineUnit contains the started units of a pipeline
+type pipelineUnit struct {
+	inputs        *inputUnit
+	processors    []*processorUnit
+	aggProcessors []*processorUnit
+	aggregators   *aggregatorUnit
+	outputs       *outputUnit
+}
+
+// pipelines groups the configured plugins by their pipeline setting. The
+// pipelines are sorted by name with the default pipeline being first.
+func (a *Agent) pipelines() []*pipeline {
+	var pipelines []*pipeline
+	byName := make(map[string]*pipeline)
+	get := func(name string) *pipeline {
+		p, found := byName[name]
+		if !found {
+			p = &pipeline{name: name}
+			byName[name] = p
+			pipelines = append(pipelines, p)
+		}
+		return p
+	}
+
+	for _, input := range a.Config.Inputs {
+		p := get(input.Config.Pipeline)
+		p.inputs = append(p.inputs, input)
+	}
+	for _, processor := range a.Config.Processors {
+		p := get(processor.Config.Pipeline)
+		p.processors = append(p.processors, processor)
+	}
+	for _, processor := range a.Config.AggProcessors {
+		p := get(processor.Config.Pipeline)
+		p.aggProcessors = append(p.aggProcessors, processor)
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		p := get(aggregator.Config.Pipeline)
+		p.aggregators = append(p.aggregators, aggregator)
+	}
+	for _, output := range a.Config.Outputs {
+		p := get(output.Config.Pipeline)
+		p.outputs = append(p.outputs, output)
+	}
+
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		return pipelines[i].name < pipelines[j].name
+	})
+	return pipelines
+}
+
+// checkPipelines makes sure the metrics of all inputs are written somewhere
+func checkPipelines(pipelines []*pipeline) error {
+	for _, p := range pipelines {
+		if len(p.inputs) > 0 && len(p.outputs) == 0 {
+			return fmt.Errorf("%s has inputs but no outputs", p)
+		}
+		if len(p.inputs) == 0 {
+			log.Printf("W! [agent] The %s has no inputs, its plugins will not receive any metrics", p)
+		}
+	}
+	return nil
+}
+
+// startProcessing sets up the aggregators and processors of the pipeline
+// sending the metrics to the given channel and returns the source channel for
+// the pipeline's inputs.
+func (a *Agent) startProcessing(
+	next chan<- telegraf.Metric,
+	p *pipeline,
+	unit *pipelineUnit,
+	tapping bool,
+) (chan<- telegraf.Metric, error) {
+	var err error
+
+	if tapping {
+		next = a.tapChannel(next, TapOutput)
+	}
+
+	if len(p.aggregators) != 0 {
+		aggC := next
+		if len(p.aggProcessors) != 0 {
+			aggC, unit.aggProcessors, err = a.startProcessors(next, p.aggProcessors)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		next, unit.aggregators = a.startAggregators(aggC, next, p.aggregators)
+	}
+	if tapping {
+		next = a.tapChannel(next, TapProcessor)
+	}
+
+	if len(p.processors) != 0 {
+		next, unit.processors, err = a.startProcessors(next, p.processors)
+		if err != nil {
+			for _, u := range unit.aggProcessors {
+				u.processor.Stop()
+			}
+			return nil, err
+		}
+	}
+	if tapping {
+		next = a.tapChannel(next, TapInput)
+	}
+
+	return next, nil
+}
+
+// runProcessing runs the aggregators and processors of the pipeline until
+// their source channels are closed.
+func (a *Agent) runProcessing(wg *sync.WaitGroup, startTime time.Time, unit *pipelineUnit) {
+	if unit.aggregators != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(unit.aggProcessors)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runAggregators(startTime, unit.aggregators)
+		}()
+	}
+
+	if unit.processors != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.runProcessors(unit.processors)
+		}()
+	}
+}
+
+// stopPipelines shuts down the started but not yet running pipelines, e.g. if
+// starting another pipeline failed. Closing the input channel of a pipeline
+// stops its processors, aggregators and outputs in order.
+func (a *Agent) stopPipelines(units []*pipelineUnit, inputsC []chan<- telegraf.Metric) {
+	var wg sync.WaitGroup
+	for i, unit := range units {
+		if unit.inputs != nil {
+			stopServiceInputs(unit.inputs.inputs)
+		}
+		close(inputsC[i])
+
+		a.runProcessing(&wg, time.Now(), unit)
+
+		if unit.outputs != nil {
+			wg.Add(1)
+			go func(unit *pipelineUnit) {
+				defer wg.Done()
+				a.runOutputs(unit.outputs)
+			}(unit)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestPipelines(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[[inputs.file]]
+  files = ["testcases/processor-order-explicit/input.influx"]
+  data_format = "influx"
+
+[[inputs.file]]
+  files = ["testcases/processor-order-explicit/input.influx"]
+  data_format = "influx"
+  pipeline = "team_a"
+
+[[processors.override]]
+  name_override = "default"
+
+[[processors.override]]
+  name_override = "team_a"
+  pipeline = "team_a"
+
+[[outputs.discard]]
+
+[[outputs.discard]]
+  pipeline = "team_a"
+`)))
+
+	a := NewAgent(cfg)
+	pipelines := a.pipelines()
+	require.Len(t, pipelines, 2)
+	require.Equal(t, "default pipeline", pipelines[0].String())
+	require.Equal(t, `pipeline "team_a"`, pipelines[1].String())
+	for _, p := range pipelines {
+		require.Len(t, p.inputs, 1)
+		require.Len(t, p.processors, 1)
+		require.Len(t, p.outputs, 1)
+	}
+	require.NoError(t, checkPipelines(pipelines))
+
+	// Each metric is only processed by the processors of its own pipeline
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	actual, err := collect(ctx, a, 0)
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New("default", map[string]string{"mood": "good"}, map[string]interface{}{"value": 23}, time.Unix(1689253834, 0)),
+		metric.New("team_a", map[string]string{"mood": "good"}, map[string]interface{}{"value": 23}, time.Unix(1689253834, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTags("host"), testutil.SortMetrics())
+}
+
+func TestPipelinesWithoutOutputs(t *testing.T) {
+	cfg := config.NewConfig()
+	require.NoError(t, cfg.LoadConfigData([]byte(`
+[[inputs.file]]
+  files = ["testcases/processor-order-explicit/input.influx"]
+  data_format = "influx"
+  pipeline = "team_a"
+
+[[outputs.discard]]
+`)))
+
+	a := NewAgent(cfg)
+	require.ErrorContains(t, checkPipelines(a.pipelines()), `pipeline "team_a" has inputs but no outputs`)
+}
+
+type closingOutput struct {
+	closed bool
+}
+
+func (*closingOutput) SampleConfig() string {
+	return ""
+}
+
+func (*closingOutput) Connect() error {
+	return nil
+}
+
+func (o *closingOutput) Close() error {
+	o.closed = true
+	return nil
+}
+
+func (*closingOutput) Write([]telegraf.Metric) error {
+	return nil
+}
+
+type failingProcessor struct{}
+
+func (*failingProcessor) SampleConfig() string {
+	return ""
+}
+
+func (*failingProcessor) Start(telegraf.Accumulator) error {
+	return errors.New("start failed")
+}
+
+func (*failingProcessor) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	acc.AddMetric(m)
+	return nil
+}
+
+func (*failingProcessor) Stop() {}
+
+func TestPipelinesStartFailure(t *testing.T) {
+	cfg := config.NewConfig()
+	first := &closingOutput{}
+	second := &closingOutput{}
+	cfg.Outputs = append(cfg.Outputs,
+		models.NewRunningOutput(first, &models.OutputConfig{Name: "closing"}, 10, 100),
+		models.NewRunningOutput(second, &models.OutputConfig{Name: "closing", Pipeline: "team_a"}, 10, 100),
+	)
+	cfg.Processors = append(cfg.Processors,
+		models.NewRunningProcessor(&failingProcessor{}, &models.ProcessorConfig{Name: "failing", Pipeline: "team_a"}),
+	)
+
+	// The outputs of all pipelines started so far must be closed
+	a := NewAgent(cfg)
+	require.ErrorContains(t, a.Run(context.Background()), "start failed")
+	require.True(t, first.closed)
+	require.True(t, second.closed)
+}
@@ -0,0 +1,134 @@
+package agent
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// Points in the processing pipeline metrics can be tapped at
+const (
+	// TapInput taps the metrics produced by the inputs before processing
+	TapInput = "input"
+	// TapProcessor taps the metrics after the processors but before aggregation
+	TapProcessor = "processor"
+	// TapOutput taps the metrics passed to the outputs
+	TapOutput = "output"
+)
+
+// tapBufferSize is the number of serialized metrics buffered per tap before
+// dropping metrics for slow clients
+const tapBufferSize = 1000
+
+// tap is a single subscription to the metrics of a tap point
+type tap struct {
+	point   string
+	filter  filter.Filter
+	lines   chan []byte
+	dropped atomic.Uint64
+}
+
+// tapHub distributes the metrics passing the tap points to the subscribed
+// taps. Publishing is cheap as long as no tap is active.
+type tapHub struct {
+	active atomic.Int32
+	taps   map[*tap]bool
+	sync.RWMutex
+}
+
+func newTapHub() *tapHub {
+	return &tapHub{taps: make(map[*tap]bool)}
+}
+
+// subscribe creates a new tap for the given point and metric name patterns
+func (h *tapHub) subscribe(point string, patterns []string) (*tap, error) {
+	switch point {
+	case TapInput, TapProcessor, TapOutput:
+	default:
+		return nil, fmt.Errorf("invalid tap point %q", point)
+	}
+	f, err := filter.Compile(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	t := &tap{
+		point:  point,
+		filter: f,
+		lines:  make(chan []byte, tapBufferSize),
+	}
+
+	h.Lock()
+	h.taps[t] = true
+	h.Unlock()
+	h.active.Add(1)
+
+	return t, nil
+}
+
+// unsubscribe removes the tap from the hub
+func (h *tapHub) unsubscribe(t *tap) {
+	h.Lock()
+	delete(h.taps, t)
+	h.Unlock()
+	h.active.Add(-1)
+
+	if dropped := t.dropped.Load(); dropped > 0 {
+		log.Printf("D! [agent] Tap on %q dropped %d metric(s) due to a slow client", t.point, dropped)
+	}
+}
+
+// publish passes the metric to all taps subscribed to the given point with a
+// matching filter
+func (h *tapHub) publish(point string, serializer *influx.Serializer, m telegraf.Metric) {
+	if h.active.Load() == 0 {
+		return
+	}
+
+	h.RLock()
+	defer h.RUnlock()
+
+	var line []byte
+	for t := range h.taps {
+		if t.point != point || (t.filter != nil && !t.filter.Match(m.Name())) {
+			continue
+		}
+		if line == nil {
+			octets, err := serializer.Serialize(m)
+			if err != nil {
+				log.Printf("D! [agent] Could not serialize metric for tap: %v", err)
+				return
+			}
+			line = append([]byte(nil), octets...)
+		}
+		select {
+		case t.lines <- line:
+		default:
+			t.dropped.Add(1)
+		}
+	}
+}
+
+// tapChannel returns a channel passing all metrics to dst after publishing them
+// to the taps of the given point. The returned channel must be closed by the
+// caller, dst is closed afterwards.
+func (a *Agent) tapChannel(dst chan<- telegraf.Metric, point string) chan<- telegraf.Metric {
+	src := make(chan telegraf.Metric, 100)
+	go func() {
+		serializer := &influx.Serializer{SortFields: true}
+		if err := serializer.Init(); err != nil {
+			log.Printf("E! [agent] Initializing tap serializer failed: %v", err)
+		}
+		for m := range src {
+			a.taps.publish(point, serializer, m)
+			dst <- m
+		}
+		close(dst)
+	}()
+	return src
+}
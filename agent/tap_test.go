@@ -0,0 +1,111 @@
+package agent
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestTapInvalid(t *testing.T) {
+	hub := newTapHub()
+
+	_, err := hub.subscribe("foo", nil)
+	require.ErrorContains(t, err, `invalid tap point "foo"`)
+
+	_, err = hub.subscribe(TapOutput, []string{"["})
+	require.ErrorContains(t, err, "invalid filter")
+}
+
+func TestTapChannel(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+
+	tp, err := a.taps.subscribe(TapProcessor, []string{"cpu*"})
+	require.NoError(t, err)
+	other, err := a.taps.subscribe(TapOutput, nil)
+	require.NoError(t, err)
+	defer a.taps.unsubscribe(other)
+
+	dst := make(chan telegraf.Metric, 10)
+	src := a.tapChannel(dst, TapProcessor)
+	src <- metric.New("cpu", map[string]string{"cpu": "cpu0"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	src <- metric.New("mem", map[string]string{}, map[string]interface{}{"value": 23}, time.Unix(0, 0))
+	close(src)
+
+	// All metrics must pass independent of the tap filter
+	var names []string
+	for _, m := range collectMetrics(dst) {
+		names = append(names, m.Name())
+	}
+	require.Equal(t, []string{"cpu", "mem"}, names)
+
+	// Only the matching metric must be published to the tap of the point
+	require.Len(t, tp.lines, 1)
+	require.Equal(t, "cpu,cpu=cpu0 value=42i 0\n", string(<-tp.lines))
+	require.Empty(t, other.lines)
+
+	// Unsubscribed taps must not receive any metrics
+	a.taps.unsubscribe(tp)
+	dst = make(chan telegraf.Metric, 10)
+	src = a.tapChannel(dst, TapProcessor)
+	src <- metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	close(src)
+	collectMetrics(dst)
+	require.Empty(t, tp.lines)
+}
+
+func TestControlSocketTap(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+	path := filepath.Join(t.TempDir(), "telegraf.sock")
+
+	server := newControlServer(a, path)
+	require.NoError(t, server.start())
+	defer server.stop()
+
+	client := NewControlClient(path)
+
+	// Check an invalid request
+	resp, err := client.Get("http://telegraf/tap?point=foo")
+	require.NoError(t, err)
+	msg, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Contains(t, string(msg), `invalid tap point "foo"`)
+
+	// Stream the metrics of a valid tap
+	resp, err = client.Get("http://telegraf/tap?point=input&filter=cpu")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Eventually(t, func() bool {
+		return a.taps.active.Load() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	dst := make(chan telegraf.Metric, 10)
+	src := a.tapChannel(dst, TapInput)
+	src <- metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	close(src)
+	collectMetrics(dst)
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "cpu value=42i 0\n", line)
+}
+
+// collectMetrics returns all metrics of the channel until it is closed
+func collectMetrics(ch <-chan telegraf.Metric) []telegraf.Metric {
+	var metrics []telegraf.Metric
+	for m := range ch {
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/robfig/cron/v3"
 
 	"github.com/influxdata/telegraf/internal"
 )
@@ -279,3 +280,84 @@ func (t *RollingTicker) Stop() {
 	t.cancel()
 	t.wg.Wait()
 }
+
+// CronTicker delivers ticks at the wall-clock times of a cron schedule plus an
+// optional offset and jitter. Each tick is scheduled based on the current time
+// to handle changes to the system clock.
+//
+// The jitter should be smaller than the time between scheduled ticks, otherwise
+// ticks may be skipped.
+//
+// The first tick is emitted at the next scheduled time.
+//
+// Ticks are dropped for slow consumers.
+type CronTicker struct {
+	schedule cron.Schedule
+	jitter   time.Duration
+	offset   time.Duration
+	ch       chan time.Time
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewCronTicker(now time.Time, schedule cron.Schedule, jitter, offset time.Duration) *CronTicker {
+	t := &CronTicker{
+		schedule: schedule,
+		jitter:   jitter,
+		offset:   offset,
+	}
+	t.start(now, clock.New())
+	return t
+}
+
+func (t *CronTicker) start(now time.Time, clk clock.Clock) {
+	t.ch = make(chan time.Time, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+
+	d := t.next(now)
+	timer := clk.Timer(d)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.run(ctx, timer)
+	}()
+}
+
+func (t *CronTicker) next(now time.Time) time.Duration {
+	// Remove the offset to get the scheduled time of the current tick so the
+	// next scheduled time is strictly after it
+	next := t.schedule.Next(now.Add(-t.offset))
+	d := next.Add(t.offset).Sub(now)
+	d += internal.RandomDuration(t.jitter)
+	return d
+}
+
+func (t *CronTicker) run(ctx context.Context, timer *clock.Timer) {
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case now := <-timer.C:
+			select {
+			case t.ch <- now:
+			default:
+			}
+
+			d := t.next(now)
+			timer.Reset(d)
+		}
+	}
+}
+
+func (t *CronTicker) Elapsed() <-chan time.Time {
+	return t.ch
+}
+
+func (t *CronTicker) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
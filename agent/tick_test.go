@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/robfig/cron/v3"
 	"github.com/stretchr/testify/require"
 )
 
@@ -121,6 +122,43 @@ func TestAlignedTickerOffset(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestCronTicker(t *testing.T) {
+	schedule, err := cron.ParseStandard("*/5 * * * *")
+	require.NoError(t, err)
+	offset := 10 * time.Second
+
+	clk := clock.NewMock()
+	since := clk.Now()
+	until := since.Add(20 * time.Minute)
+
+	ticker := &CronTicker{
+		schedule: schedule,
+		offset:   offset,
+	}
+	ticker.start(since, clk)
+	defer ticker.Stop()
+
+	// The offset tick of the scheduled time at start is still in the future
+	expected := []time.Time{
+		time.Unix(10, 0).UTC(),
+		time.Unix(310, 0).UTC(),
+		time.Unix(610, 0).UTC(),
+		time.Unix(910, 0).UTC(),
+	}
+
+	actual := []time.Time{}
+	for !clk.Now().After(until) {
+		select {
+		case tm := <-ticker.Elapsed():
+			actual = append(actual, tm.UTC())
+		default:
+		}
+		clk.Add(10 * time.Second)
+	}
+
+	require.Equal(t, expected, actual)
+}
+
 func TestAlignedTickerMissedTick(t *testing.T) {
 	interval := 10 * time.Second
 	jitter := 0 * time.Second
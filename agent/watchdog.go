@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"reflect"
+	"runtime/pprof"
+	"strings"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// pluginStacks returns the stack traces of all goroutines executing code of
+// the plugin's package. If no goroutine can be attributed to the plugin, the
+// traces of all goroutines are returned.
+func pluginStacks(plugin interface{}) string {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		return fmt.Sprintf("getting goroutines failed: %v", err)
+	}
+	dump := buf.String()
+
+	t := reflect.TypeOf(plugin)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.PkgPath() == "" {
+		return dump
+	}
+	pkg := t.PkgPath() + "."
+
+	var stacks []string
+	for _, stack := range strings.Split(dump, "\n\n") {
+		if strings.Contains(stack, pkg) {
+			stacks = append(stacks, stack)
+		}
+	}
+	if len(stacks) == 0 {
+		return dump
+	}
+	return strings.Join(stacks, "\n\n")
+}
+
+// abandonedCall is a hung call of a plugin abandoned by the watchdog. No
+// further calls must be made on the plugin instance until it returned.
+type abandonedCall struct {
+	name string
+	kind string
+	done <-chan error
+}
+
+// running checks if the call is still running and reports its completion
+// otherwise.
+func (c *abandonedCall) running() bool {
+	select {
+	case err := <-c.done:
+		c.report(err)
+		return false
+	default:
+		return true
+	}
+}
+
+// wait blocks until the call returned.
+func (c *abandonedCall) wait() {
+	log.Printf("I! [%s] Waiting for abandoned %s to complete", c.name, c.kind)
+	c.report(<-c.done)
+}
+
+func (c *abandonedCall) report(err error) {
+	if err != nil {
+		log.Printf("E! [%s] Abandoned %s completed with error: %v", c.name, c.kind, err)
+		return
+	}
+	log.Printf("I! [%s] Abandoned %s completed", c.name, c.kind)
+}
+
+// restartOutput closes the output and connects it again. It must only be
+// called with no write in flight.
+func (a *Agent) restartOutput(output *models.RunningOutput) error {
+	log.Printf("I! [agent] Restarting [%s]", output.LogName())
+	// Only close the plugin, closing the running output is final
+	if err := output.Output.Close(); err != nil {
+		log.Printf("E! [agent] Closing [%s] failed: %v", output.LogName(), err)
+	}
+	if err := output.Output.Connect(); err != nil {
+		return fmt.Errorf("connecting failed: %w", err)
+	}
+	return nil
+}
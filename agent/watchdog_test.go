@@ -0,0 +1,233 @@
+package agent
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type manualTicker struct {
+	ch chan time.Time
+}
+
+func (t *manualTicker) Elapsed() <-chan time.Time {
+	return t.ch
+}
+
+func (*manualTicker) Stop() {}
+
+type hungInput struct {
+	release chan struct{}
+	gathers int32
+	running int32
+	overlap int32
+}
+
+func (*hungInput) SampleConfig() string {
+	return ""
+}
+
+func (h *hungInput) Gather(telegraf.Accumulator) error {
+	atomic.AddInt32(&h.gathers, 1)
+	if atomic.AddInt32(&h.running, 1) > 1 {
+		atomic.StoreInt32(&h.overlap, 1)
+	}
+	defer atomic.AddInt32(&h.running, -1)
+
+	<-h.release
+	return nil
+}
+
+type hungOutput struct {
+	release  chan struct{}
+	writes   int32
+	writing  int32
+	connects int32
+	closes   int32
+	overlap  int32
+}
+
+func (*hungOutput) SampleConfig() string {
+	return ""
+}
+
+func (h *hungOutput) Connect() error {
+	if atomic.LoadInt32(&h.writing) > 0 {
+		atomic.StoreInt32(&h.overlap, 1)
+	}
+	atomic.AddInt32(&h.connects, 1)
+	return nil
+}
+
+func (h *hungOutput) Close() error {
+	if atomic.LoadInt32(&h.writing) > 0 {
+		atomic.StoreInt32(&h.overlap, 1)
+	}
+	atomic.AddInt32(&h.closes, 1)
+	return nil
+}
+
+func (h *hungOutput) Write([]telegraf.Metric) error {
+	atomic.AddInt32(&h.writes, 1)
+	if atomic.AddInt32(&h.writing, 1) > 1 {
+		atomic.StoreInt32(&h.overlap, 1)
+	}
+	defer atomic.AddInt32(&h.writing, -1)
+
+	<-h.release
+	return nil
+}
+
+func TestPluginStacks(t *testing.T) {
+	plugin := &hungInput{release: make(chan struct{})}
+	defer close(plugin.release)
+
+	go func() {
+		_ = plugin.Gather(nil)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(pluginStacks(plugin)) > 0
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, pluginStacks(plugin), "(*hungInput).Gather")
+}
+
+func TestWatchdogRestartInput(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.WatchdogGatherIntervals = 2
+	c.Agent.WatchdogRestart = true
+	a := NewAgent(c)
+
+	plugin := &hungInput{release: make(chan struct{})}
+	input := models.NewRunningInput(plugin, &models.InputConfig{Name: "hung"})
+	require.NoError(t, input.Init())
+
+	dst := make(chan telegraf.Metric, 10)
+	acc := NewAccumulator(input, dst)
+	ticker := &manualTicker{ch: make(chan time.Time)}
+
+	start := time.Now()
+	abandoned, err := a.gatherOnce(acc, input, ticker, 50*time.Millisecond)
+	require.ErrorContains(t, err, "hung collection abandoned")
+	require.NotNil(t, abandoned)
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	require.True(t, abandoned.running())
+
+	close(plugin.release)
+	require.Eventually(t, func() bool {
+		return !abandoned.running()
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWatchdogInputBlockedWhileHung(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.WatchdogGatherIntervals = 2
+	c.Agent.WatchdogRestart = true
+	a := NewAgent(c)
+
+	plugin := &hungInput{release: make(chan struct{})}
+	input := models.NewRunningInput(plugin, &models.InputConfig{Name: "hung"})
+	require.NoError(t, input.Init())
+
+	dst := make(chan telegraf.Metric, 10)
+	acc := NewAccumulator(input, dst)
+	ticker := &manualTicker{ch: make(chan time.Time)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		a.gatherLoop(ctx, acc, input, ticker, 20*time.Millisecond)
+		close(stopped)
+	}()
+
+	// Further collections are skipped while the abandoned one is running
+	ticker.ch <- time.Now()
+	time.Sleep(100 * time.Millisecond)
+	ticker.ch <- time.Now()
+	ticker.ch <- time.Now()
+	require.Equal(t, int32(1), atomic.LoadInt32(&plugin.gathers))
+
+	// Collections resume once the abandoned one completed
+	close(plugin.release)
+	require.Eventually(t, func() bool {
+		ticker.ch <- time.Now()
+		return atomic.LoadInt32(&plugin.gathers) > 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+	require.Zero(t, atomic.LoadInt32(&plugin.overlap))
+}
+
+func TestWatchdogRestartOutput(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.WatchdogWriteTimeout = config.Duration(50 * time.Millisecond)
+	c.Agent.WatchdogRestart = true
+	a := NewAgent(c)
+
+	plugin := &hungOutput{release: make(chan struct{})}
+	output := models.NewRunningOutput(plugin, &models.OutputConfig{Name: "hung"}, 10, 100)
+	require.NoError(t, output.Init())
+	output.AddMetric(testutil.TestMetric(1.0))
+	ticker := &manualTicker{ch: make(chan time.Time)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		a.flushLoop(ctx, output, ticker)
+		close(stopped)
+	}()
+
+	// Further writes are skipped and the output is not closed while the
+	// abandoned write is running
+	ticker.ch <- time.Now()
+	time.Sleep(150 * time.Millisecond)
+	ticker.ch <- time.Now()
+	ticker.ch <- time.Now()
+	require.Equal(t, int32(1), atomic.LoadInt32(&plugin.writes))
+	require.Zero(t, atomic.LoadInt32(&plugin.closes))
+
+	// The output is restarted once the abandoned write completed
+	close(plugin.release)
+	output.AddMetric(testutil.TestMetric(2.0))
+	require.Eventually(t, func() bool {
+		ticker.ch <- time.Now()
+		return atomic.LoadInt32(&plugin.writes) > 1
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-stopped
+	require.Equal(t, int32(1), atomic.LoadInt32(&plugin.closes))
+	require.Equal(t, int32(1), atomic.LoadInt32(&plugin.connects))
+	require.Zero(t, atomic.LoadInt32(&plugin.overlap))
+	require.Equal(t, 0, output.BufferLength())
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	a := NewAgent(config.NewConfig())
+
+	plugin := &hungInput{release: make(chan struct{})}
+	input := models.NewRunningInput(plugin, &models.InputConfig{Name: "hung"})
+	require.NoError(t, input.Init())
+
+	dst := make(chan telegraf.Metric, 10)
+	acc := NewAccumulator(input, dst)
+	ticker := &manualTicker{ch: make(chan time.Time)}
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		close(plugin.release)
+	}()
+	abandoned, err := a.gatherOnce(acc, input, ticker, 20*time.Millisecond)
+	require.NoError(t, err)
+	require.Nil(t, abandoned)
+	require.Equal(t, int32(1), atomic.LoadInt32(&plugin.gathers))
+}
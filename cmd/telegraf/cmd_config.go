@@ -57,6 +57,60 @@ InfluxDB v2 output plugin use
 						return nil
 					},
 				},
+				{
+					Name:  "render",
+					Usage: "print the effective configuration with secrets masked",
+					Description: `
+The 'render' command loads the configuration files specified via '--config' or
+'--config-directory' and prints the effective configuration of the agent and
+all plugins in TOML format. The output shows the merged configuration after
+environment variable substitution with the defaults of all plugin options
+applied. If no configuration file is explicitly specified the command reads
+the default locations. Secrets and sensitive options are masked. Secret-store
+definitions are not included in the output.
+
+To show the configuration resulting from 'telegraf.conf' and the files in the
+'telegraf.d' directory use
+
+> telegraf --config telegraf.conf --config-directory telegraf.d config render
+`,
+					Flags: pluginFilterFlags,
+					Action: func(cCtx *cli.Context) error {
+						// Setup logging
+						telegraf.Debug = cCtx.Bool("debug")
+						logConfig := logger.LogConfig{Debug: telegraf.Debug}
+						if err := logger.SetupLogging(logConfig); err != nil {
+							return err
+						}
+
+						filters := processFilterFlags(cCtx)
+						c := config.NewConfig()
+						c.InputFilters = filters.input
+						c.OutputFilters = filters.output
+						c.SecretStoreFilters = filters.secretstore
+
+						// Collect the given configuration files
+						configFiles := cCtx.StringSlice("config")
+						for _, fConfigDirectory := range cCtx.StringSlice("config-directory") {
+							files, err := config.WalkDirectory(fConfigDirectory)
+							if err != nil {
+								return err
+							}
+							configFiles = append(configFiles, files...)
+						}
+
+						// Providing no "config" or "config-directory" flag(s)
+						// should load the default configuration files
+						if len(configFiles) == 0 {
+							configFiles = append(configFiles, "")
+						}
+						if err := c.LoadAll(configFiles...); err != nil {
+							return err
+						}
+
+						return c.Render(outputBuffer)
+					},
+				},
 				{
 					Name:  "migrate",
 					Usage: "migrate deprecated plugins and options of the configuration(s)",
@@ -72,15 +126,27 @@ with a '.migrated' suffix.
 It is highly recommended to test those migrated configurations before using
 those files unattended!
 
+Using the '--write' flag, local configuration files are rewritten in place
+instead. Each migrated plugin is annotated with a comment describing the
+changes.
+
 To migrate the file 'mysettings.conf' use
 
 > telegraf --config mysettings.conf config migrate
+
+To rewrite the file 'mysettings.conf' in place use
+
+> telegraf --config mysettings.conf config migrate --write
 `,
 					Flags: []cli.Flag{
 						&cli.BoolFlag{
 							Name:  "force",
 							Usage: "forces overwriting of an existing migration file",
 						},
+						&cli.BoolFlag{
+							Name:  "write",
+							Usage: "rewrite the configuration files in place instead of creating migration files",
+						},
 					},
 					Action: func(cCtx *cli.Context) error {
 						// Setup logging
@@ -139,6 +205,24 @@ To migrate the file 'mysettings.conf' use
 								continue
 							}
 
+							// Rewrite local files in place if requested
+							if cCtx.Bool("write") {
+								if remote {
+									return fmt.Errorf("cannot rewrite remote configuration %q in place", fn)
+								}
+								log.Printf("I! %d migration applied for %q, rewriting file", applied, fn)
+
+								// Keep the permissions of the original file
+								info, err := os.Stat(fn)
+								if err != nil {
+									return err
+								}
+								if err := os.WriteFile(fn, out, info.Mode().Perm()); err != nil {
+									return fmt.Errorf("writing output %q failed: %w", fn, err)
+								}
+								continue
+							}
+
 							// Construct the output filename
 							// For remote locations we just save the filename
 							// with the migrated suffix.
@@ -0,0 +1,245 @@
+// Command handling for commands using the control socket of a running agent
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf/agent"
+	"github.com/influxdata/telegraf/config"
+)
+
+// controlSocketFlag allows to specify the control socket of the agent
+var controlSocketFlag = &cli.StringFlag{
+	Name:  "socket",
+	Usage: "path of the control socket, by default the 'control_socket' agent setting of the configuration is used",
+}
+
+// controlSocket returns the path of the control socket either given via flag
+// or taken from the agent settings of the configuration
+func controlSocket(cCtx *cli.Context) (string, error) {
+	if path := cCtx.String("socket"); path != "" {
+		return path, nil
+	}
+
+	configFiles := cCtx.StringSlice("config")
+	for _, fConfigDirectory := range cCtx.StringSlice("config-directory") {
+		files, err := config.WalkDirectory(fConfigDirectory)
+		if err != nil {
+			return "", err
+		}
+		configFiles = append(configFiles, files...)
+	}
+	if len(configFiles) == 0 {
+		configFiles = append(configFiles, "")
+	}
+
+	c := config.NewConfig()
+	if err := c.LoadAgentConfig(configFiles...); err != nil {
+		return "", err
+	}
+	if c.Agent.ControlSocket == "" {
+		return "", errors.New("no control socket configured, please set 'control_socket' in the agent settings")
+	}
+	return c.Agent.ControlSocket, nil
+}
+
+// controlRequest sends a request to the control socket of the running agent
+// and copies the response to the given writer
+func controlRequest(cCtx *cli.Context, w io.Writer, method, endpoint string, params url.Values) error {
+	path, err := controlSocket(cCtx)
+	if err != nil {
+		return err
+	}
+
+	addr := "http://telegraf" + endpoint
+	if len(params) > 0 {
+		addr += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(cCtx.Context, method, addr, nil)
+	if err != nil {
+		return err
+	}
+
+	client := agent.NewControlClient(path)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("connecting to control socket failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request failed: %s", strings.TrimSpace(string(msg)))
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+func getControlCommands(outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "tap",
+			Usage: "stream the metrics passing a point of the pipeline of a running agent",
+			Description: `
+The 'tap' command connects to the control socket of a running agent and prints
+the metrics passing the given point of the processing pipeline in line-protocol
+format. This allows to debug filters and processors without changing the
+configuration. The command runs until interrupted.
+
+The tap point can be one of
+  input     - metrics produced by the inputs before any processing
+  processor - metrics after the processors but before the aggregators
+  output    - metrics passed to the outputs (default)
+
+The control socket must be enabled via the 'control_socket' agent setting.
+
+To stream all 'cpu' metrics passed to the outputs use
+
+> telegraf --config telegraf.conf tap --filter cpu
+
+To compare the metrics produced by the inputs use
+
+> telegraf --config telegraf.conf tap --filter cpu --point input
+`,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "filter",
+					Usage: "only show metrics with names matching the given glob pattern(s)",
+				},
+				&cli.StringFlag{
+					Name:  "point",
+					Usage: "point of the pipeline to tap, one of 'input', 'processor' or 'output'",
+					Value: agent.TapOutput,
+				},
+				controlSocketFlag,
+			},
+			Action: func(cCtx *cli.Context) error {
+				params := url.Values{}
+				params.Set("point", cCtx.String("point"))
+				for _, f := range cCtx.StringSlice("filter") {
+					params.Add("filter", f)
+				}
+				return controlRequest(cCtx, outputBuffer, http.MethodGet, "/tap", params)
+			},
+		},
+		{
+			Name:  "control",
+			Usage: "commands for managing a running agent via its control socket",
+			Description: `
+The 'control' command allows to manage a running agent via its control socket
+without sending signals to the process. The control socket must be enabled via
+the 'control_socket' agent setting.
+
+To pause the collection of an input use the ID or alias of the input as shown
+by the 'plugins' subcommand
+
+> telegraf --config telegraf.conf control pause my_input
+`,
+			Subcommands: []*cli.Command{
+				{
+					Name:  "reload",
+					Usage: "reload the configuration of the running agent",
+					Flags: []cli.Flag{controlSocketFlag},
+					Action: func(cCtx *cli.Context) error {
+						return controlRequest(cCtx, outputBuffer, http.MethodPost, "/reload", nil)
+					},
+				},
+				{
+					Name:  "plugins",
+					Usage: "list the running plugins with their status in JSON format",
+					Flags: []cli.Flag{controlSocketFlag},
+					Action: func(cCtx *cli.Context) error {
+						return controlRequest(cCtx, outputBuffer, http.MethodGet, "/plugins", nil)
+					},
+				},
+				{
+					Name:  "flush",
+					Usage: "trigger an immediate flush of all outputs",
+					Flags: []cli.Flag{controlSocketFlag},
+					Action: func(cCtx *cli.Context) error {
+						return controlRequest(cCtx, outputBuffer, http.MethodPost, "/flush", nil)
+					},
+				},
+				{
+					Name:      "pause",
+					Usage:     "pause the collection of the given input",
+					ArgsUsage: "<input id or alias>",
+					Flags:     []cli.Flag{controlSocketFlag},
+					Action: func(cCtx *cli.Context) error {
+						if cCtx.NArg() != 1 {
+							return errors.New("exactly one input must be specified")
+						}
+						params := url.Values{"id": []string{cCtx.Args().First()}}
+						return controlRequest(cCtx, outputBuffer, http.MethodPost, "/inputs/pause", params)
+					},
+				},
+				{
+					Name:      "resume",
+					Usage:     "resume the collection of the given paused input",
+					ArgsUsage: "<input id or alias>",
+					Flags:     []cli.Flag{controlSocketFlag},
+					Action: func(cCtx *cli.Context) error {
+						if cCtx.NArg() != 1 {
+							return errors.New("exactly one input must be specified")
+						}
+						params := url.Values{"id": []string{cCtx.Args().First()}}
+						return controlRequest(cCtx, outputBuffer, http.MethodPost, "/inputs/resume", params)
+					},
+				},
+				{
+					Name:      "loglevel",
+					Usage:     "show or change the log-level of the running agent or of a plugin",
+					ArgsUsage: "[debug|info|warn|error|default]",
+					Description: `
+Without argument the current global log-level and all plugin overrides are
+printed in JSON format. Otherwise the global level is set or, if a plugin is
+given, the level of the plugin is overridden. Use 'default' to remove the
+override of a plugin.
+
+To enable debug messages for a single input use
+
+> telegraf --config telegraf.conf control loglevel --plugin inputs.mqtt_consumer debug
+`,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "plugin",
+							Usage: "log name of the plugin, e.g. 'inputs.cpu' or 'inputs.cpu::alias'",
+						},
+						controlSocketFlag,
+					},
+					Action: func(cCtx *cli.Context) error {
+						switch cCtx.NArg() {
+						case 0:
+							return controlRequest(cCtx, outputBuffer, http.MethodGet, "/loglevel", nil)
+						case 1:
+						default:
+							return errors.New("at most one log-level must be specified")
+						}
+
+						params := url.Values{"level": []string{cCtx.Args().First()}}
+						if plugin := cCtx.String("plugin"); plugin != "" {
+							params.Set("plugin", plugin)
+						}
+						return controlRequest(cCtx, outputBuffer, http.MethodPost, "/loglevel", params)
+					},
+				},
+				{
+					Name:  "buffers",
+					Usage: "show the buffer statistics of all outputs in JSON format",
+					Flags: []cli.Flag{controlSocketFlag},
+					Action: func(cCtx *cli.Context) error {
+						return controlRequest(cCtx, outputBuffer, http.MethodGet, "/buffers", nil)
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,71 @@
+// Command handling for the pipeline "test" command
+package main
+
+import (
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf/agent"
+)
+
+func getFixtureCommands(pluginFilterFlags []cli.Flag, m App) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "test",
+			Usage: "run recorded payloads through the configured pipeline and compare with golden output",
+			Description: `
+The 'test' command feeds recorded payloads through the parsers of the inputs
+and the processors of the configuration(s) specified via '--config' or
+'--config-directory'. The resulting metrics are compared in line-protocol
+format against golden output files, allowing to test processing pipelines
+without live endpoints, e.g. in CI.
+
+The fixture directory contains a sub-directory for each input named by the
+alias of the input or, if no alias is set, by the plugin name. Each file in
+those directories is a payload for the parser of the input. The golden output
+is stored next to the payload in a file with an additional '.expected' suffix.
+Aggregators and outputs are not used.
+
+To test the fixtures in the 'testdata' directory use
+
+> telegraf --config telegraf.conf test --fixtures testdata
+
+To create or update the golden files with the current output use
+
+> telegraf --config telegraf.conf test --fixtures testdata --update
+`,
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:     "fixtures",
+					Usage:    "directory containing the payloads and golden output files",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:  "update",
+					Usage: "write the current output as golden files instead of comparing",
+				},
+				&cli.BoolFlag{
+					Name:  "ignore-time",
+					Usage: "zero the metric timestamps before comparing, e.g. for payloads without timestamps",
+				},
+			}, pluginFilterFlags...),
+			Action: func(cCtx *cli.Context) error {
+				filters := processFilterFlags(cCtx)
+				g := GlobalFlags{
+					config:     cCtx.StringSlice("config"),
+					configDir:  cCtx.StringSlice("config-directory"),
+					plugindDir: cCtx.String("plugin-directory"),
+					password:   cCtx.String("password"),
+					debug:      cCtx.Bool("debug"),
+					quiet:      cCtx.Bool("quiet"),
+				}
+				m.Init(nil, filters, g, WindowFlags{})
+
+				return m.RunFixtures(agent.FixtureConfig{
+					Directory:  cCtx.String("fixtures"),
+					Update:     cCtx.Bool("update"),
+					IgnoreTime: cCtx.Bool("ignore-time"),
+				})
+			},
+		},
+	}
+}
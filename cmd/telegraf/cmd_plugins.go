@@ -0,0 +1,87 @@
+// Command handling for plugin inspection "plugins" command
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func getPluginCommands(outputBuffer io.Writer) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "plugins",
+			Usage: "commands for inspecting the available plugins",
+			Subcommands: []*cli.Command{
+				{
+					Name:  "inspect",
+					Usage: "print a JSON schema of the plugin's options",
+					Description: `
+The 'inspect' command prints a machine-readable description of the options of
+the given plugin in JSON format. For each option the TOML key, the type, the
+default value and deprecation information is included, allowing external tools
+to generate or validate configurations.
+
+The plugin is specified as <category>.<name> with category being one of
+'inputs', 'outputs', 'processors', 'aggregators', 'parsers', 'serializers' or
+'secretstores'. The category can be omitted if the name is unique.
+
+To inspect the options of the MQTT consumer input plugin use
+
+> telegraf plugins inspect inputs.mqtt_consumer
+`,
+					ArgsUsage: "<plugin>",
+					Action: func(cCtx *cli.Context) error {
+						if cCtx.NArg() != 1 {
+							return errors.New("expected exactly one plugin name")
+						}
+
+						category, name, err := resolvePluginName(cCtx.Args().First())
+						if err != nil {
+							return err
+						}
+						schema, err := config.NewPluginSchema(category, name)
+						if err != nil {
+							return err
+						}
+
+						buf, err := json.MarshalIndent(schema, "", "  ")
+						if err != nil {
+							return fmt.Errorf("encoding schema failed: %w", err)
+						}
+						_, err = outputBuffer.Write(append(buf, '\n'))
+						return err
+					},
+				},
+			},
+		},
+	}
+}
+
+// resolvePluginName splits the given plugin name into category and name. For
+// names without category, all categories are searched for the plugin.
+func resolvePluginName(plugin string) (string, string, error) {
+	if category, name, found := strings.Cut(plugin, "."); found {
+		return category, name, nil
+	}
+
+	var matches []string
+	for _, category := range config.SchemaCategories {
+		if _, err := config.NewPluginSchema(category, plugin); err == nil {
+			matches = append(matches, category)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", "", fmt.Errorf("unknown plugin %q", plugin)
+	case 1:
+		return matches[0], plugin, nil
+	}
+	return "", "", fmt.Errorf("ambiguous plugin %q, please specify one of the categories %s", plugin, strings.Join(matches, ", "))
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf/internal/goplugin"
 	"github.com/influxdata/telegraf/logger"
 	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
+	"github.com/influxdata/telegraf/plugins/common/grpcplugin"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -151,6 +152,16 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 			}
 		}
 
+		// Register the plugin binaries speaking the gRPC plugin API
+		if dir := cCtx.String("external-plugin-directory"); dir != "" {
+			log.Printf("I! Loading external gRPC plugins from: %s", dir)
+			names, err := grpcplugin.LoadDirectory(dir)
+			if err != nil {
+				return err
+			}
+			log.Printf("I! Registered external gRPC plugins: %s", strings.Join(names, " "))
+		}
+
 		// switch for flags which just do something and exit immediately
 		switch {
 		// print available input plugins
@@ -250,6 +261,9 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 		getConfigCommands(pluginFilterFlags, outputBuffer),
 		getSecretStoreCommands(m)...,
 	)
+	commands = append(commands, getFixtureCommands(pluginFilterFlags, m)...)
+	commands = append(commands, getPluginCommands(outputBuffer)...)
+	commands = append(commands, getControlCommands(outputBuffer)...)
 
 	app := &cli.App{
 		Name:   "Telegraf",
@@ -293,6 +307,10 @@ func runApp(args []string, outputBuffer io.Writer, pprof Server, c TelegrafConfi
 					Name:  "password",
 					Usage: "password to unlock secret-stores",
 				},
+				&cli.StringFlag{
+					Name:  "external-plugin-directory",
+					Usage: "directory containing external plugin binaries speaking the gRPC plugin API",
+				},
 				//
 				// Bool flags
 				&cli.BoolFlag{
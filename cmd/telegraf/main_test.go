@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
@@ -42,6 +44,8 @@ var secrets = map[string]map[string][]byte{
 type MockTelegraf struct {
 	GlobalFlags
 	WindowFlags
+
+	fixtures agent.FixtureConfig
 }
 
 func NewMockTelegraf() *MockTelegraf {
@@ -57,6 +61,11 @@ func (m *MockTelegraf) Run() error {
 	return nil
 }
 
+func (m *MockTelegraf) RunFixtures(cfg agent.FixtureConfig) error {
+	m.fixtures = cfg
+	return nil
+}
+
 func (m *MockTelegraf) ListSecretStores() ([]string, error) {
 	ids := make([]string, 0, len(secrets))
 	for k := range secrets {
@@ -563,3 +572,40 @@ func TestFlagsAreSet(t *testing.T) {
 	require.Equal(t, expectedString, m.watchConfig)
 	require.Equal(t, expectedString, m.pidFile)
 }
+
+func TestCommandTest(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := os.Args[0:1]
+	args = append(args, "--config", "telegraf.conf", "test", "--fixtures", "testdata", "--ignore-time")
+	m := NewMockTelegraf()
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), m)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"telegraf.conf"}, m.config)
+	require.Equal(t, agent.FixtureConfig{Directory: "testdata", IgnoreTime: true}, m.fixtures)
+
+	// The fixture directory is required
+	args = append(os.Args[0:1], "test")
+	require.ErrorContains(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()), "fixtures")
+}
+
+func TestCommandPluginsInspect(t *testing.T) {
+	buf := new(bytes.Buffer)
+	args := append(os.Args[0:1], "plugins", "inspect", "inputs.cpu")
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+
+	var schema config.PluginSchema
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &schema))
+	require.Equal(t, "inputs.cpu", schema.Name)
+	require.NotEmpty(t, schema.Options)
+
+	// The category is optional for unique plugin names
+	buf.Reset()
+	args = append(os.Args[0:1], "plugins", "inspect", "cpu")
+	require.NoError(t, runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf()))
+	require.Contains(t, buf.String(), `"name": "inputs.cpu"`)
+
+	args = append(os.Args[0:1], "plugins", "inspect", "json")
+	err := runApp(args, buf, NewMockServer(), NewMockConfig(buf), NewMockTelegraf())
+	require.ErrorContains(t, err, `ambiguous plugin "json"`)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -62,11 +63,18 @@ type App interface {
 	// Secret store commands
 	ListSecretStores() ([]string, error)
 	GetSecretStore(string) (telegraf.SecretStore, error)
+
+	// Pipeline test command
+	RunFixtures(agent.FixtureConfig) error
 }
 
 type Telegraf struct {
 	pprofErr <-chan error
 
+	// requestReload triggers a reload of the configuration of the running
+	// agent
+	requestReload func()
+
 	inputFilters       []string
 	outputFilters      []string
 	configFiles        []string
@@ -121,6 +129,32 @@ func (t *Telegraf) GetSecretStore(id string) (telegraf.SecretStore, error) {
 	return store, nil
 }
 
+func (t *Telegraf) RunFixtures(cfg agent.FixtureConfig) error {
+	c, err := t.loadConfiguration()
+	if err != nil {
+		return err
+	}
+
+	telegraf.Debug = c.Agent.Debug || t.debug
+	logConfig := logger.LogConfig{
+		Debug: telegraf.Debug,
+		Quiet: c.Agent.Quiet || t.quiet,
+	}
+	if err := logger.SetupLogging(logConfig); err != nil {
+		return err
+	}
+
+	ag := agent.NewAgent(c)
+	failed, err := ag.RunFixtures(cfg, os.Stdout)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d fixture(s) failed", failed)
+	}
+	return nil
+}
+
 func (t *Telegraf) reloadLoop() error {
 	reloadConfig := false
 	cfg, err := t.loadConfiguration()
@@ -137,6 +171,12 @@ func (t *Telegraf) reloadLoop() error {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Interrupt, syscall.SIGHUP,
 			syscall.SIGTERM, syscall.SIGINT)
+		t.requestReload = func() {
+			select {
+			case signals <- syscall.SIGHUP:
+			default:
+			}
+		}
 		if t.watchConfig != "" {
 			for _, fConfig := range t.configFiles {
 				if _, err := os.Stat(fConfig); err == nil {
@@ -248,6 +288,8 @@ func (t *Telegraf) loadConfiguration() (*config.Config, error) {
 func (t *Telegraf) runAgent(ctx context.Context, c *config.Config, reloadConfig bool) error {
 	var err error
 	if reloadConfig {
+		// Drop the log-level overrides of the previous configuration
+		models.ResetLogLevels()
 		if c, err = t.loadConfiguration(); err != nil {
 			return err
 		}
@@ -279,6 +321,8 @@ func (t *Telegraf) runAgent(ctx context.Context, c *config.Config, reloadConfig
 		RotationMaxSize:     c.Agent.LogfileRotationMaxSize,
 		RotationMaxArchives: c.Agent.LogfileRotationMaxArchives,
 		LogWithTimezone:     c.Agent.LogWithTimezone,
+		LogFormat:           c.Agent.LogFormat,
+		SamplingInterval:    c.Agent.LogSamplingInterval,
 	}
 
 	if err := logger.SetupLogging(logConfig); err != nil {
@@ -334,6 +378,7 @@ func (t *Telegraf) runAgent(ctx context.Context, c *config.Config, reloadConfig
 	}
 
 	ag := agent.NewAgent(c)
+	ag.Reload = t.requestReload
 
 	// Notify systemd that telegraf is ready
 	// SdNotify() only tries to notify if the NOTIFY_SOCKET environment is set, so it's safe to call when systemd isn't present.
@@ -25,9 +25,12 @@ import (
 	"github.com/coreos/go-semver/semver"
 	"github.com/influxdata/toml"
 	"github.com/influxdata/toml/ast"
+	"github.com/robfig/cron/v3"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/memlimit"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
 	"github.com/influxdata/telegraf/persister"
 	"github.com/influxdata/telegraf/plugins/aggregators"
@@ -68,6 +71,7 @@ type Config struct {
 	unusedFieldsMutex *sync.Mutex
 
 	Tags               map[string]string
+	hostMetadata       map[string]string
 	InputFilters       []string
 	OutputFilters      []string
 	SecretStoreFilters []string
@@ -121,6 +125,7 @@ func NewConfig() *Config {
 			FlushInterval:              Duration(10 * time.Second),
 			LogTarget:                  "file",
 			LogfileRotationMaxArchives: 5,
+			TagInternLimit:             metric.DefaultInternLimit,
 		},
 
 		Tags:               make(map[string]string),
@@ -247,9 +252,22 @@ type AgentConfig struct {
 	// Pick a timezone to use when logging or type 'local' for local time.
 	LogWithTimezone string `toml:"log_with_timezone"`
 
+	// Format of the log messages, either "text" or "json" for structured
+	// messages containing the plugin, alias and error class as fields.
+	LogFormat string `toml:"log_format"`
+
+	// Identical error messages of the same plugin repeated within the given
+	// interval are suppressed. When set to 0 no messages are suppressed.
+	LogSamplingInterval Duration `toml:"log_sampling_interval"`
+
 	Hostname     string
 	OmitHostname bool
 
+	// Flag to add tags identifying the host, i.e. the operating system,
+	// architecture, kernel version and Telegraf version, to all metrics of
+	// input plugins.
+	AddHostMetadata bool `toml:"add_host_metadata"`
+
 	// Method for translating SNMP objects. 'netsnmp' to call external programs,
 	// 'gosmi' to use the built-in library.
 	SnmpTranslator string `toml:"snmp_translator"`
@@ -260,6 +278,23 @@ type AgentConfig struct {
 	// the state in the file will be restored for the plugins.
 	Statefile string `toml:"statefile"`
 
+	// Address of the store to load the state of plugins from and store the
+	// state to, e.g. "redis://localhost:6379/0". Cannot be used together
+	// with the statefile setting.
+	StateStore string `toml:"state_store"`
+
+	// Prefix of the keys used in the state store to allow sharing the store
+	// between multiple agents.
+	StatePrefix string `toml:"state_prefix"`
+
+	// Path of the unix socket providing the control API of the agent, e.g.
+	// for tapping into the metric stream. The API is disabled if empty.
+	ControlSocket string `toml:"control_socket"`
+
+	// Maximum number of distinct tag keys and values kept to share the memory
+	// of equal strings between metrics. Set to 0 to disable interning.
+	TagInternLimit int `toml:"tag_intern_limit"`
+
 	// Flag to always keep tags explicitly defined in the plugin itself and
 	// ensure those tags always pass filtering.
 	AlwaysIncludeLocalTags bool `toml:"always_include_local_tags"`
@@ -267,6 +302,25 @@ type AgentConfig struct {
 	// Flag to always keep tags explicitly defined in the global tags section
 	// and ensure those tags always pass filtering.
 	AlwaysIncludeGlobalTags bool `toml:"always_include_global_tags"`
+
+	// Number of intervals after which a collection of an input still running
+	// is considered hung. Set to 0 to disable the detection.
+	WatchdogGatherIntervals int `toml:"watchdog_gather_intervals"`
+
+	// Time after which a write of an output still running is considered hung.
+	// Set to 0 to disable the detection.
+	WatchdogWriteTimeout Duration `toml:"watchdog_write_timeout"`
+
+	// Flag to restart hung plugin instances detected by the watchdog.
+	WatchdogRestart bool `toml:"watchdog_restart"`
+
+	// Maximum heap memory used by the agent before shedding load. Set to 0 to
+	// disable the limit.
+	MaxMemory Size `toml:"max_memory"`
+
+	// Policy for shedding load while the memory limit is exceeded, one of
+	// "drop_oldest", "pause" or "reject".
+	MemorySheddingPolicy string `toml:"memory_shedding_policy"`
 }
 
 // InputNames returns a list of strings of the configured inputs.
@@ -470,6 +524,48 @@ func (c *Config) LoadConfig(path string) error {
 	return nil
 }
 
+// LoadAgentConfig only loads the agent settings of the given configuration
+// files without instantiating any plugin. An empty path loads the default
+// configuration files.
+func (c *Config) LoadAgentConfig(files ...string) error {
+	var paths []string
+	for _, path := range files {
+		if path != "" {
+			paths = append(paths, path)
+			continue
+		}
+		defaults, err := GetDefaultConfigPath()
+		if err != nil {
+			return err
+		}
+		paths = append(paths, defaults...)
+	}
+
+	for _, path := range paths {
+		data, _, err := LoadConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("error loading config file %s: %w", path, err)
+		}
+		tbl, err := parseConfig(data)
+		if err != nil {
+			return fmt.Errorf("error parsing config file %s: %w", path, err)
+		}
+
+		val, ok := tbl.Fields["agent"]
+		if !ok {
+			continue
+		}
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("invalid configuration, error parsing agent table")
+		}
+		if err = c.toml.UnmarshalTable(subTable, c.Agent); err != nil {
+			return fmt.Errorf("error parsing [agent]: %w", err)
+		}
+	}
+	return nil
+}
+
 func (c *Config) LoadAll(configFiles ...string) error {
 	for _, fConfig := range configFiles {
 		if err := c.LoadConfig(fConfig); err != nil {
@@ -538,6 +634,10 @@ func (c *Config) LoadConfigData(data []byte) error {
 		c.Tags["host"] = c.Agent.Hostname
 	}
 
+	if c.Agent.AddHostMetadata && c.hostMetadata == nil {
+		c.hostMetadata = hostMetadata()
+	}
+
 	// Warn when explicitly setting the old snmp translator
 	if c.Agent.SnmpTranslator == "netsnmp" {
 		models.PrintOptionValueDeprecationNotice(telegraf.Warn, "agent", "snmp_translator", "netsnmp", telegraf.DeprecationInfo{
@@ -547,10 +647,37 @@ func (c *Config) LoadConfigData(data []byte) error {
 		})
 	}
 
+	// Check the memory limit settings
+	if c.Agent.MaxMemory < 0 {
+		return errors.New("'max_memory' must not be negative")
+	}
+	if c.Agent.MemorySheddingPolicy == "" {
+		c.Agent.MemorySheddingPolicy = memlimit.PolicyDropOldest
+	}
+	switch c.Agent.MemorySheddingPolicy {
+	case memlimit.PolicyDropOldest, memlimit.PolicyPause, memlimit.PolicyReject:
+	default:
+		return fmt.Errorf("invalid 'memory_shedding_policy' %q", c.Agent.MemorySheddingPolicy)
+	}
+
 	// Setup the persister if requested
-	if c.Agent.Statefile != "" {
+	if c.Agent.Statefile != "" && c.Agent.StateStore != "" {
+		return errors.New("'statefile' and 'state_store' cannot be used at the same time")
+	}
+	if c.Agent.Statefile != "" || c.Agent.StateStore != "" {
 		c.Persister = &persister.Persister{
 			Filename: c.Agent.Statefile,
+			Prefix:   c.Agent.StatePrefix,
+		}
+		if c.Agent.StatePrefix == "" {
+			c.Persister.Prefix = persister.DefaultPrefix
+		}
+		if c.Agent.StateStore != "" {
+			backend, err := persister.NewBackend(c.Agent.StateStore)
+			if err != nil {
+				return err
+			}
+			c.Persister.Backend = backend
 		}
 	}
 
@@ -925,7 +1052,11 @@ func (c *Config) addAggregator(name string, table *ast.Table) error {
 		return err
 	}
 
-	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(aggregator, conf))
+	ra := models.NewRunningAggregator(aggregator, conf)
+	if err := setPluginLogLevel(ra.LogName(), conf.LogLevel); err != nil {
+		return err
+	}
+	c.Aggregators = append(c.Aggregators, ra)
 	return nil
 }
 
@@ -1118,6 +1249,9 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		return err
 	}
 	rf := models.NewRunningProcessor(processorBefore, processorBeforeConfig)
+	if err := setPluginLogLevel(rf.LogName(), processorBeforeConfig.LogLevel); err != nil {
+		return err
+	}
 	c.fileProcessors = append(c.fileProcessors, &OrderedPlugin{table.Line, rf})
 
 	// Setup another (new) processor instance running after the aggregator
@@ -1192,7 +1326,7 @@ func (c *Config) setupProcessor(name string, creator processors.StreamingCreator
 		optionTestCount++
 	}
 
-	if err := c.toml.UnmarshalTable(table, processor); err != nil {
+	if err := c.unmarshalPlugin(table, processor); err != nil {
 		return nil, 0, fmt.Errorf("unmarshalling failed: %w", err)
 	}
 
@@ -1229,22 +1363,25 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
+	var serializer telegraf.Serializer
 	if t, ok := output.(telegraf.SerializerPlugin); ok {
 		missThreshold = 1
-		serializer, err := c.addSerializer(name, table)
+		s, err := c.addSerializer(name, table)
 		if err != nil {
 			return err
 		}
-		t.SetSerializer(serializer)
+		t.SetSerializer(s)
+		serializer = s
 	} else if t, ok := output.(serializers.SerializerOutput); ok {
 		// Keep the old interface for backward compatibility
 		// DEPRECATED: Please switch your plugin to telegraf.Serializers
 		missThreshold = 1
-		serializer, err := c.addSerializer(name, table)
+		s, err := c.addSerializer(name, table)
 		if err != nil {
 			return err
 		}
-		t.SetSerializer(serializer)
+		t.SetSerializer(s)
+		serializer = s
 	}
 
 	outputConfig, err := c.buildOutput(name, table)
@@ -1252,7 +1389,7 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.toml.UnmarshalTable(table, output); err != nil {
+	if err := c.unmarshalPlugin(table, output); err != nil {
 		return err
 	}
 
@@ -1277,11 +1414,42 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	}
 
 	ro := models.NewRunningOutput(output, outputConfig, c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+	ro.Serializer = serializer
+	if err := setPluginLogLevel(ro.LogName(), outputConfig.LogLevel); err != nil {
+		return err
+	}
 	c.Outputs = append(c.Outputs, ro)
 
 	return nil
 }
 
+// pluginConfigSetter is implemented by plugins accepting arbitrary settings,
+// e.g. external plugins passing the settings to the plugin process
+type pluginConfigSetter interface {
+	SetPluginConfig(settings map[string]interface{}) error
+}
+
+// unmarshalPlugin sets the options of the plugin from the table
+func (c *Config) unmarshalPlugin(table *ast.Table, plugin interface{}) error {
+	p, ok := plugin.(pluginConfigSetter)
+	if !ok {
+		return c.toml.UnmarshalTable(table, plugin)
+	}
+
+	settings := make(map[string]interface{})
+	if err := c.toml.UnmarshalTable(table, &settings); err != nil {
+		return err
+	}
+
+	// Only pass on the settings of the plugin itself
+	for key := range settings {
+		if isAgentOption(key) {
+			delete(settings, key)
+		}
+	}
+	return p.SetPluginConfig(settings)
+}
+
 func (c *Config) addInput(name string, table *ast.Table) error {
 	if len(c.InputFilters) > 0 && !sliceContains(name, c.InputFilters) {
 		return nil
@@ -1312,6 +1480,7 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 
 	// If the input has a SetParser or SetParserFunc function, it can accept
 	// arbitrary data-formats, so build the requested parser and set it.
+	var parserFunc telegraf.ParserFunc
 	if t, ok := input.(telegraf.ParserPlugin); ok {
 		missCountThreshold = 1
 		parser, err := c.addParser("inputs", name, table)
@@ -1319,16 +1488,24 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 			return fmt.Errorf("adding parser failed: %w", err)
 		}
 		t.SetParser(parser)
+		parserFunc = func() (telegraf.Parser, error) {
+			return c.addParser("inputs", name, table)
+		}
 	}
 
 	if t, ok := input.(telegraf.ParserFuncPlugin); ok {
-		missCountThreshold = 1
-		if !c.probeParser("inputs", name, table) {
-			return errors.New("parser not found")
+		// Inputs accepting both a parser and a parser function already got
+		// their parser options checked above
+		if parserFunc == nil {
+			missCountThreshold = 1
+			if !c.probeParser("inputs", name, table) {
+				return errors.New("parser not found")
+			}
+			parserFunc = func() (telegraf.Parser, error) {
+				return c.addParser("inputs", name, table)
+			}
 		}
-		t.SetParserFunc(func() (telegraf.Parser, error) {
-			return c.addParser("inputs", name, table)
-		})
+		t.SetParserFunc(parserFunc)
 	}
 
 	pluginConfig, err := c.buildInput(name, table)
@@ -1336,7 +1513,7 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return err
 	}
 
-	if err := c.toml.UnmarshalTable(table, input); err != nil {
+	if err := c.unmarshalPlugin(table, input); err != nil {
 		return err
 	}
 
@@ -1361,12 +1538,28 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 	}
 
 	rp := models.NewRunningInput(input, pluginConfig)
+	rp.ParserFunc = parserFunc
 	rp.SetDefaultTags(c.Tags)
+	if err := setPluginLogLevel(rp.LogName(), pluginConfig.LogLevel); err != nil {
+		return err
+	}
 	c.Inputs = append(c.Inputs, rp)
 
 	return nil
 }
 
+// setPluginLogLevel overrides the global log-level for the plugin with the
+// given log name if a level is configured
+func setPluginLogLevel(name, level string) error {
+	if level == "" {
+		return nil
+	}
+	if err := models.SetLogLevel(name, level); err != nil {
+		return fmt.Errorf("setting log-level of %s failed: %w", name, err)
+	}
+	return nil
+}
+
 // buildAggregator parses Aggregator specific items from the ast.Table,
 // builds the filter and returns a
 // models.AggregatorConfig to be inserted into models.RunningAggregator
@@ -1381,11 +1574,15 @@ func (c *Config) buildAggregator(name string, tbl *ast.Table) (*models.Aggregato
 	c.getFieldDuration(tbl, "period", &conf.Period)
 	c.getFieldDuration(tbl, "delay", &conf.Delay)
 	c.getFieldDuration(tbl, "grace", &conf.Grace)
+	c.getFieldBool(tbl, "event_time", &conf.EventTime)
+	c.getFieldDuration(tbl, "watermark", &conf.Watermark)
 	c.getFieldBool(tbl, "drop_original", &conf.DropOriginal)
 	c.getFieldString(tbl, "name_prefix", &conf.MeasurementPrefix)
 	c.getFieldString(tbl, "name_suffix", &conf.MeasurementSuffix)
 	c.getFieldString(tbl, "name_override", &conf.NameOverride)
 	c.getFieldString(tbl, "alias", &conf.Alias)
+	c.getFieldString(tbl, "pipeline", &conf.Pipeline)
+	c.getFieldString(tbl, "log_level", &conf.LogLevel)
 
 	conf.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1419,6 +1616,8 @@ func (c *Config) buildProcessor(category, name string, tbl *ast.Table) (*models.
 
 	c.getFieldInt64(tbl, "order", &conf.Order)
 	c.getFieldString(tbl, "alias", &conf.Alias)
+	c.getFieldString(tbl, "pipeline", &conf.Pipeline)
+	c.getFieldString(tbl, "log_level", &conf.LogLevel)
 
 	if c.hasErrs() {
 		return nil, c.firstErr()
@@ -1483,10 +1682,13 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 	c.getFieldDuration(tbl, "precision", &cp.Precision)
 	c.getFieldDuration(tbl, "collection_jitter", &cp.CollectionJitter)
 	c.getFieldDuration(tbl, "collection_offset", &cp.CollectionOffset)
+	c.getFieldString(tbl, "schedule", &cp.Schedule)
 	c.getFieldString(tbl, "name_prefix", &cp.MeasurementPrefix)
 	c.getFieldString(tbl, "name_suffix", &cp.MeasurementSuffix)
 	c.getFieldString(tbl, "name_override", &cp.NameOverride)
 	c.getFieldString(tbl, "alias", &cp.Alias)
+	c.getFieldString(tbl, "pipeline", &cp.Pipeline)
+	c.getFieldString(tbl, "log_level", &cp.LogLevel)
 
 	cp.Tags = make(map[string]string)
 	if node, ok := tbl.Fields["tags"]; ok {
@@ -1497,10 +1699,26 @@ func (c *Config) buildInput(name string, tbl *ast.Table) (*models.InputConfig, e
 		}
 	}
 
+	if node, ok := tbl.Fields["extra_tags"]; ok {
+		if subtbl, ok := node.(*ast.Table); ok {
+			cp.ExtraTags = make(map[string]string)
+			if err := c.toml.UnmarshalTable(subtbl, cp.ExtraTags); err != nil {
+				return nil, fmt.Errorf("could not parse extra tags for input %s", name)
+			}
+		}
+	}
+	cp.HostMetadata = c.hostMetadata
+
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
 
+	if cp.Schedule != "" {
+		if _, err := cron.ParseStandard(cp.Schedule); err != nil {
+			return nil, fmt.Errorf("invalid schedule %q for input %s: %w", cp.Schedule, name, err)
+		}
+	}
+
 	var err error
 	cp.Filter, err = c.buildFilter(tbl)
 	if err != nil {
@@ -1534,10 +1752,37 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 	c.getFieldInt(tbl, "metric_buffer_limit", &oc.MetricBufferLimit)
 	c.getFieldInt(tbl, "metric_batch_size", &oc.MetricBatchSize)
 	c.getFieldString(tbl, "alias", &oc.Alias)
+	c.getFieldString(tbl, "pipeline", &oc.Pipeline)
+	c.getFieldString(tbl, "log_level", &oc.LogLevel)
 	c.getFieldString(tbl, "name_override", &oc.NameOverride)
 	c.getFieldString(tbl, "name_suffix", &oc.NameSuffix)
 	c.getFieldString(tbl, "name_prefix", &oc.NamePrefix)
 
+	if node, ok := tbl.Fields["rate_limit"]; ok {
+		subtbl, ok := node.(*ast.Table)
+		if !ok {
+			return nil, fmt.Errorf("rate_limit for output %s must be a table", name)
+		}
+		var rl struct {
+			PointsPerSecond float64 `toml:"points_per_second"`
+			PointsBurst     int     `toml:"points_burst"`
+			BytesPerSecond  Size    `toml:"bytes_per_second"`
+			BytesBurst      Size    `toml:"bytes_burst"`
+		}
+		if err := c.toml.UnmarshalTable(subtbl, &rl); err != nil {
+			return nil, fmt.Errorf("could not parse rate_limit for output %s: %w", name, err)
+		}
+		if rl.PointsPerSecond < 0 || rl.PointsBurst < 0 || rl.BytesPerSecond < 0 || rl.BytesBurst < 0 {
+			return nil, fmt.Errorf("rate_limit for output %s must not be negative", name)
+		}
+		oc.RateLimit = models.RateLimitConfig{
+			PointsPerSecond: rl.PointsPerSecond,
+			PointsBurst:     rl.PointsBurst,
+			BytesPerSecond:  int64(rl.BytesPerSecond),
+			BytesBurst:      int64(rl.BytesBurst),
+		}
+	}
+
 	if c.hasErrs() {
 		return nil, c.firstErr()
 	}
@@ -1548,33 +1793,47 @@ func (c *Config) buildOutput(name string, tbl *ast.Table) (*models.OutputConfig,
 }
 
 func (c *Config) missingTomlField(_ reflect.Type, key string) error {
+	if !isAgentOption(key) {
+		c.unusedFieldsMutex.Lock()
+		c.UnusedFields[key] = true
+		c.unusedFieldsMutex.Unlock()
+	}
+	return nil
+}
+
+// isAgentOption returns true if the key is an option handled by Telegraf
+// itself instead of the plugin
+func isAgentOption(key string) bool {
 	switch key {
 	// General options to ignore
 	case "alias", "always_include_local_tags",
 		"collection_jitter", "collection_offset",
 		"data_format", "delay", "drop", "drop_original",
+		"event_time", "extra_tags",
 		"fielddrop", "fieldpass", "flush_interval", "flush_jitter",
 		"grace",
 		"interval",
+		"log_level",
 		"lvm", // What is this used for?
 		"metric_batch_size", "metric_buffer_limit", "metricpass",
 		"name_override", "name_prefix", "name_suffix", "namedrop", "namepass",
 		"order",
-		"pass", "period", "precision",
-		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags":
+		"pass", "period", "pipeline", "precision",
+		"rate_limit",
+		"schedule",
+		"tagdrop", "tagexclude", "taginclude", "tagpass", "tags",
+		"watermark":
+		return true
 
 	// Secret-store options to ignore
 	case "id":
+		return true
 
 	// Parser and serializer options to ignore
 	case "data_type", "influx_parser_type":
-
-	default:
-		c.unusedFieldsMutex.Lock()
-		c.UnusedFields[key] = true
-		c.unusedFieldsMutex.Unlock()
+		return true
 	}
-	return nil
+	return false
 }
 
 func (c *Config) setLocalMissingTomlFieldTracker(counter map[string]int) {
@@ -443,6 +443,166 @@ func TestConfig_AzureMonitorNamespacePrefix(t *testing.T) {
 	}
 }
 
+func TestConfig_OutputRateLimit(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/rate_limit.toml"))
+	require.Len(t, c.Outputs, 2)
+	require.Empty(t, c.UnusedFields)
+
+	require.Equal(t, models.RateLimitConfig{}, c.Outputs[0].Config.RateLimit)
+	expected := models.RateLimitConfig{
+		PointsPerSecond: 500,
+		BytesPerSecond:  1024 * 1024,
+		BytesBurst:      4 * 1024 * 1024,
+	}
+	require.Equal(t, expected, c.Outputs[1].Config.RateLimit)
+}
+
+func TestConfig_PluginLogLevel(t *testing.T) {
+	defer models.ResetLogLevels()
+
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/log_level.toml"))
+	require.Len(t, c.Inputs, 2)
+	require.Len(t, c.Outputs, 1)
+	require.Empty(t, c.UnusedFields)
+
+	require.Equal(t, "debug", c.Inputs[0].Config.LogLevel)
+	require.Equal(t, map[string]string{
+		"inputs.memcached":        "debug",
+		"inputs.memcached::quiet": "error",
+		"outputs.http":            "warn",
+	}, models.LogLevels())
+
+	c = config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  log_level = "verbose"
+`))
+	require.ErrorContains(t, err, `setting log-level of inputs.memcached failed: invalid log level "verbose"`)
+}
+
+func TestConfig_HostMetadata(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[agent]
+  add_host_metadata = true
+
+[[inputs.memcached]]
+  [inputs.memcached.extra_tags]
+    datacenter = "eu-west"
+
+[[inputs.memcached]]
+  alias = "plain"
+`)))
+	require.Len(t, c.Inputs, 2)
+	require.Empty(t, c.UnusedFields)
+
+	require.Equal(t, map[string]string{"datacenter": "eu-west"}, c.Inputs[0].Config.ExtraTags)
+	require.Nil(t, c.Inputs[1].Config.ExtraTags)
+	for _, input := range c.Inputs {
+		require.Equal(t, runtime.GOOS, input.Config.HostMetadata["os"])
+		require.Equal(t, runtime.GOARCH, input.Config.HostMetadata["arch"])
+		require.Contains(t, input.Config.HostMetadata, "telegraf_version")
+	}
+
+	c = config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+`)))
+	require.Nil(t, c.Inputs[0].Config.HostMetadata)
+}
+
+func TestConfig_InputSchedule(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  schedule = "0 * * * *"
+  collection_offset = "10s"
+`)))
+	require.Len(t, c.Inputs, 1)
+	require.Empty(t, c.UnusedFields)
+	require.Equal(t, "0 * * * *", c.Inputs[0].Config.Schedule)
+	require.Equal(t, 10*time.Second, c.Inputs[0].Config.CollectionOffset)
+
+	c = config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  schedule = "every hour"
+`))
+	require.ErrorContains(t, err, `invalid schedule "every hour" for input memcached`)
+}
+
+func TestConfig_MemoryLimit(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[agent]
+  max_memory = "512MiB"
+
+[[inputs.memcached]]
+`)))
+	require.Equal(t, config.Size(512*1024*1024), c.Agent.MaxMemory)
+	require.Equal(t, "drop_oldest", c.Agent.MemorySheddingPolicy)
+
+	c = config.NewConfig()
+	err := c.LoadConfigData([]byte(`
+[agent]
+  max_memory = "512MiB"
+  memory_shedding_policy = "panic"
+
+[[inputs.memcached]]
+`))
+	require.ErrorContains(t, err, `invalid 'memory_shedding_policy' "panic"`)
+}
+
+func TestConfig_Pipeline(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.memcached]]
+  pipeline = "team_a"
+
+[[processors.processor]]
+  pipeline = "team_a"
+
+[[outputs.azure_monitor]]
+  pipeline = "team_a"
+`)))
+	require.Empty(t, c.UnusedFields)
+	require.Equal(t, "team_a", c.Inputs[0].Config.Pipeline)
+	require.Equal(t, "team_a", c.Processors[0].Config.Pipeline)
+	require.Equal(t, "team_a", c.AggProcessors[0].Config.Pipeline)
+	require.Equal(t, "team_a", c.Outputs[0].Config.Pipeline)
+}
+
+func TestConfig_PluginConfigSetter(t *testing.T) {
+	c := config.NewConfig()
+	require.NoError(t, c.LoadConfigData([]byte(`
+[[inputs.settings_test]]
+  interval = "5s"
+  alias = "mockup"
+  namepass = ["foo"]
+  server = "localhost:1234"
+  ports = [1, 2]
+
+  [inputs.settings_test.tls]
+    enabled = true
+
+  [inputs.settings_test.tags]
+    source = "test"
+`)))
+	require.Len(t, c.Inputs, 1)
+	require.Equal(t, 5*time.Second, c.Inputs[0].Config.Interval)
+
+	input, ok := c.Inputs[0].Input.(*MockupSettingsPlugin)
+	require.True(t, ok)
+	require.Equal(t, "localhost:1234", input.settings["server"])
+	require.Equal(t, []interface{}{int64(1), int64(2)}, input.settings["ports"])
+	require.Equal(t, map[string]interface{}{"enabled": true}, input.settings["tls"])
+
+	// Options handled by Telegraf must not be passed to the plugin
+	require.Len(t, input.settings, 3)
+}
+
 func TestGetDefaultConfigPathFromEnvURL(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -1111,7 +1271,7 @@ func TestPersisterInputStoreLoad(t *testing.T) {
 
 		// Register
 		p := plugin.Input.(*MockupStatePlugin)
-		require.NoError(t, persisterStore.Register(plugin.ID(), p))
+		require.NoError(t, persisterStore.Register("inputs."+plugin.Config.Name, plugin.ID(), p))
 
 		// Change the state
 		p.state.Name += "_" + strings.Repeat("a", i+1)
@@ -1143,7 +1303,7 @@ func TestPersisterInputStoreLoad(t *testing.T) {
 
 		// Register
 		p := plugin.Input.(*MockupStatePlugin)
-		require.NoError(t, persisterLoad.Register(plugin.ID(), p))
+		require.NoError(t, persisterLoad.Register("inputs."+plugin.Config.Name, plugin.ID(), p))
 
 		// Check that the states are not yet restored
 		require.NotNil(t, expected[plugin.ID()])
@@ -1178,7 +1338,7 @@ func TestPersisterProcessorRegistration(t *testing.T) {
 		unwrapped := plugin.Processor.(processors.HasUnwrap).Unwrap()
 
 		p := unwrapped.(*MockupProcessorPlugin)
-		require.NoError(t, dut.Register(plugin.ID(), p))
+		require.NoError(t, dut.Register("processors."+plugin.Config.Name, plugin.ID(), p))
 	}
 
 	// Register the after-aggregator processors
@@ -1186,7 +1346,7 @@ func TestPersisterProcessorRegistration(t *testing.T) {
 		unwrapped := plugin.Processor.(processors.HasUnwrap).Unwrap()
 
 		p := unwrapped.(*MockupProcessorPlugin)
-		require.NoError(t, dut.Register(plugin.ID(), p))
+		require.NoError(t, dut.Register("processors."+plugin.Config.Name, plugin.ID(), p))
 	}
 }
 
@@ -1498,6 +1658,24 @@ func (m *MockupStatePlugin) Gather(_ telegraf.Accumulator) error {
 	return nil
 }
 
+/*** Mockup INPUT plugin accepting arbitrary settings ***/
+type MockupSettingsPlugin struct {
+	settings map[string]interface{}
+}
+
+func (m *MockupSettingsPlugin) SetPluginConfig(settings map[string]interface{}) error {
+	m.settings = settings
+	return nil
+}
+
+func (m *MockupSettingsPlugin) SampleConfig() string {
+	return "Mockup test plugin"
+}
+
+func (m *MockupSettingsPlugin) Gather(_ telegraf.Accumulator) error {
+	return nil
+}
+
 // Register the mockup plugin on loading
 func init() {
 	// Register the mockup input plugin for the required names
@@ -1528,6 +1706,9 @@ func init() {
 	inputs.Add("statetest", func() telegraf.Input {
 		return &MockupStatePlugin{}
 	})
+	inputs.Add("settings_test", func() telegraf.Input {
+		return &MockupSettingsPlugin{}
+	})
 
 	// Register the mockup processor plugin for the required names
 	processors.Add("parser_test", func() telegraf.Processor {
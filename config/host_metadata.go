@@ -0,0 +1,22 @@
+package config
+
+import (
+	"runtime"
+
+	"github.com/shirou/gopsutil/v3/host"
+
+	"github.com/influxdata/telegraf/internal"
+)
+
+// hostMetadata returns the tags identifying the host the agent is running on
+func hostMetadata() map[string]string {
+	tags := map[string]string{
+		"os":               runtime.GOOS,
+		"arch":             runtime.GOARCH,
+		"telegraf_version": internal.Version,
+	}
+	if kernel, err := host.KernelVersion(); err == nil && kernel != "" {
+		tags["kernel_version"] = kernel
+	}
+	return tags
+}
@@ -161,13 +161,27 @@ func ApplyMigrations(data []byte) ([]byte, uint64, error) {
 
 		log.Printf("D!   migrating plugin %q in line %d...", s.name, s.begin)
 		result, msg, err := migrate(s.content)
+		if errors.Is(err, migrations.ErrNotApplicable) {
+			continue
+		}
 		if err != nil {
 			return nil, 0, fmt.Errorf("migrating %q (line %d) failed: %w", s.name, s.begin, err)
 		}
 		if msg != "" {
 			log.Printf("I! Plugin %q in line %d: %s", s.name, s.begin, msg)
 		}
-		s.raw = bytes.NewBuffer(result)
+
+		// Keep the comments in front of the plugin and document the migration
+		var buf bytes.Buffer
+		buf.Write(leadingComments(s.raw.Bytes()))
+		fmt.Fprintf(&buf, "# Migrated deprecated plugin settings of %q\n", s.name)
+		for _, line := range strings.Split(strings.TrimSpace(msg), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintf(&buf, "#   %s\n", line)
+			}
+		}
+		buf.Write(result)
+		s.raw = &buf
 		sections[idx] = s
 		applied++
 	}
@@ -182,3 +196,19 @@ func ApplyMigrations(data []byte) ([]byte, uint64, error) {
 
 	return buf.Bytes(), applied, nil
 }
+
+// leadingComments returns the comment lines at the beginning of the given
+// section text
+func leadingComments(data []byte) []byte {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewBuffer(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "#") {
+			break
+		}
+		_, _ = buf.Write(scanner.Bytes())
+		_, _ = buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,569 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+// maskedSecret replaces the value of secrets in the rendered configuration
+const maskedSecret = `"********"`
+
+var (
+	// bareKeyRe matches the keys that can be used without quoting in TOML
+	bareKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	// sensitiveKeyRe matches the keys of plain string options and map entries
+	// that are masked in the rendered configuration even though they are not
+	// secrets, e.g. HTTP headers
+	sensitiveKeyRe = regexp.MustCompile(
+		`(?i)(password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|secret[_-]?key|auth|authorization|credentials|cookie)$`,
+	)
+
+	// dsnUserinfoRe matches the credentials of DSNs in the form
+	// "user:password@protocol(address)/dbname" as used e.g. by MySQL
+	dsnUserinfoRe = regexp.MustCompile(`^([^:/@\s]+):([^@\s]+)@(\w+\(.*\))?/`)
+
+	// dsnPasswordRe matches the password of DSNs in the key-value form
+	// "host=localhost password=secret" as used e.g. by PostgreSQL
+	dsnPasswordRe = regexp.MustCompile(`(?i)\b(password|pwd)=('(?:[^'\\]|\\.)*'|[^\s;]*)`)
+
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Render writes the effective configuration in TOML format, i.e. the merged
+// configuration of all loaded files with environment variables substituted
+// and the plugin defaults applied. Secrets are masked in the output.
+func (c *Config) Render(w io.Writer) error {
+	var sections []*renderSection
+
+	if len(c.Tags) > 0 {
+		tags := &renderTable{}
+		tags.addMap(c.Tags)
+		sections = append(sections, &renderSection{header: "[global_tags]", path: "global_tags", table: tags})
+	}
+
+	agent := &renderTable{}
+	agent.addStruct(reflect.ValueOf(c.Agent))
+	sections = append(sections, &renderSection{header: "[agent]", path: "agent", table: agent})
+
+	for _, output := range c.Outputs {
+		sections = append(sections, renderOutput(output))
+	}
+	for _, processor := range c.Processors {
+		sections = append(sections, renderProcessor(processor))
+	}
+	for _, aggregator := range c.Aggregators {
+		sections = append(sections, renderAggregator(aggregator))
+	}
+	for _, input := range c.Inputs {
+		section, err := renderInput(input)
+		if err != nil {
+			return err
+		}
+		sections = append(sections, section)
+	}
+
+	for i, section := range sections {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if err := section.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderOutput(output *models.RunningOutput) *renderSection {
+	cfg := output.Config
+
+	t := &renderTable{}
+	t.addString("alias", cfg.Alias)
+	t.addString("log_level", cfg.LogLevel)
+	t.addDuration("flush_interval", cfg.FlushInterval)
+	t.addDuration("flush_jitter", cfg.FlushJitter)
+	t.addInt("metric_batch_size", int64(output.MetricBatchSize))
+	t.addInt("metric_buffer_limit", int64(output.MetricBufferLimit))
+	t.addString("name_override", cfg.NameOverride)
+	t.addString("name_prefix", cfg.NamePrefix)
+	t.addString("name_suffix", cfg.NameSuffix)
+	t.addFilter(&cfg.Filter)
+	if cfg.RateLimit != (models.RateLimitConfig{}) {
+		rl := t.addTable("rate_limit")
+		rl.set("points_per_second", formatFloat(cfg.RateLimit.PointsPerSecond))
+		rl.set("points_burst", strconv.Itoa(cfg.RateLimit.PointsBurst))
+		rl.set("bytes_per_second", strconv.FormatInt(cfg.RateLimit.BytesPerSecond, 10))
+		rl.set("bytes_burst", strconv.FormatInt(cfg.RateLimit.BytesBurst, 10))
+	}
+	t.addStruct(reflect.ValueOf(output.Output))
+	if rs, ok := output.Serializer.(*models.RunningSerializer); ok {
+		t.addString("data_format", rs.Config.DataFormat)
+		t.addStruct(reflect.ValueOf(rs.Serializer))
+	}
+
+	return newPluginSection("outputs", cfg.Name, t)
+}
+
+func renderProcessor(processor *models.RunningProcessor) *renderSection {
+	cfg := processor.Config
+
+	t := &renderTable{}
+	t.addString("alias", cfg.Alias)
+	t.addString("log_level", cfg.LogLevel)
+	t.addInt("order", cfg.Order)
+	t.addFilter(&cfg.Filter)
+	var plugin interface{} = processor.Processor
+	if p, ok := plugin.(processors.HasUnwrap); ok {
+		plugin = p.Unwrap()
+	}
+	t.addStruct(reflect.ValueOf(plugin))
+
+	return newPluginSection("processors", cfg.Name, t)
+}
+
+func renderAggregator(aggregator *models.RunningAggregator) *renderSection {
+	cfg := aggregator.Config
+
+	t := &renderTable{}
+	t.addString("alias", cfg.Alias)
+	t.addString("log_level", cfg.LogLevel)
+	t.set("period", quote(cfg.Period.String()))
+	t.set("delay", quote(cfg.Delay.String()))
+	t.set("grace", quote(cfg.Grace.String()))
+	if cfg.EventTime {
+		t.addBool("event_time", cfg.EventTime)
+		t.set("watermark", quote(cfg.Watermark.String()))
+	}
+	t.addBool("drop_original", cfg.DropOriginal)
+	t.addString("name_override", cfg.NameOverride)
+	t.addString("name_prefix", cfg.MeasurementPrefix)
+	t.addString("name_suffix", cfg.MeasurementSuffix)
+	t.addFilter(&cfg.Filter)
+	if len(cfg.Tags) > 0 {
+		t.addTable("tags").addMap(cfg.Tags)
+	}
+	t.addStruct(reflect.ValueOf(aggregator.Aggregator))
+
+	return newPluginSection("aggregators", cfg.Name, t)
+}
+
+func renderInput(input *models.RunningInput) (*renderSection, error) {
+	cfg := input.Config
+
+	t := &renderTable{}
+	t.addString("alias", cfg.Alias)
+	t.addString("log_level", cfg.LogLevel)
+	t.addDuration("interval", cfg.Interval)
+	t.addDuration("precision", cfg.Precision)
+	t.addDuration("collection_jitter", cfg.CollectionJitter)
+	t.addDuration("collection_offset", cfg.CollectionOffset)
+	t.addString("name_override", cfg.NameOverride)
+	t.addString("name_prefix", cfg.MeasurementPrefix)
+	t.addString("name_suffix", cfg.MeasurementSuffix)
+	t.addFilter(&cfg.Filter)
+	if len(cfg.Tags) > 0 {
+		t.addTable("tags").addMap(cfg.Tags)
+	}
+	t.addStruct(reflect.ValueOf(input.Input))
+	if input.ParserFunc != nil {
+		parser, err := input.ParserFunc()
+		if err != nil {
+			return nil, fmt.Errorf("creating parser for %s failed: %w", input.LogName(), err)
+		}
+		if rp, ok := parser.(*models.RunningParser); ok {
+			t.addString("data_format", rp.Config.DataFormat)
+			t.addStruct(reflect.ValueOf(rp.Parser))
+		}
+	}
+
+	return newPluginSection("inputs", cfg.Name, t), nil
+}
+
+// renderSection is a top-level table of the rendered configuration
+type renderSection struct {
+	header string
+	path   string
+	table  *renderTable
+}
+
+func newPluginSection(category, name string, t *renderTable) *renderSection {
+	path := category + "." + name
+	return &renderSection{header: "[[" + path + "]]", path: path, table: t}
+}
+
+func (s *renderSection) write(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, s.header); err != nil {
+		return err
+	}
+	return s.table.write(w, s.path, "  ")
+}
+
+// renderTable collects the values and sub-tables of a TOML table in the order
+// they are added. Keys are only added once, so the first occurrence wins.
+type renderTable struct {
+	values []renderEntry
+	tables []renderEntry
+	keys   map[string]bool
+}
+
+type renderEntry struct {
+	key   string
+	value string
+	table *renderTable
+	array []*renderTable
+}
+
+func (t *renderTable) claim(key string) bool {
+	if t.keys == nil {
+		t.keys = make(map[string]bool)
+	}
+	if t.keys[key] {
+		return false
+	}
+	t.keys[key] = true
+	return true
+}
+
+func (t *renderTable) set(key, literal string) {
+	if t.claim(key) {
+		t.values = append(t.values, renderEntry{key: key, value: literal})
+	}
+}
+
+func (t *renderTable) addTable(key string) *renderTable {
+	sub := &renderTable{}
+	if t.claim(key) {
+		t.tables = append(t.tables, renderEntry{key: key, table: sub})
+	}
+	return sub
+}
+
+func (t *renderTable) addArray(key string, tables []*renderTable) {
+	if t.claim(key) {
+		t.tables = append(t.tables, renderEntry{key: key, array: tables})
+	}
+}
+
+func (t *renderTable) addString(key, value string) {
+	if value != "" {
+		t.set(key, quote(value))
+	}
+}
+
+func (t *renderTable) addBool(key string, value bool) {
+	if value {
+		t.set(key, "true")
+	}
+}
+
+func (t *renderTable) addInt(key string, value int64) {
+	if value != 0 {
+		t.set(key, strconv.FormatInt(value, 10))
+	}
+}
+
+func (t *renderTable) addDuration(key string, value time.Duration) {
+	if value != 0 {
+		t.set(key, quote(value.String()))
+	}
+}
+
+func (t *renderTable) addStrings(key string, values []string) {
+	if len(values) > 0 {
+		t.set(key, quoteStrings(values))
+	}
+}
+
+func (t *renderTable) addMap(m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		t.set(k, quote(m[k]))
+	}
+}
+
+func (t *renderTable) addFilter(f *models.Filter) {
+	t.addStrings("namepass", f.NamePass)
+	t.addStrings("namedrop", f.NameDrop)
+	t.addStrings("fieldpass", f.FieldPass)
+	t.addStrings("fielddrop", f.FieldDrop)
+	t.addStrings("taginclude", f.TagInclude)
+	t.addStrings("tagexclude", f.TagExclude)
+	t.addString("metricpass", f.MetricPass)
+	t.addTagFilters("tagpass", f.TagPassFilters)
+	t.addTagFilters("tagdrop", f.TagDropFilters)
+}
+
+func (t *renderTable) addTagFilters(key string, filters []models.TagFilter) {
+	if len(filters) == 0 {
+		return
+	}
+	sub := t.addTable(key)
+	for _, tf := range filters {
+		sub.set(tf.Name, quoteStrings(tf.Values))
+	}
+}
+
+// addStruct adds the options of the given plugin structure
+func (t *renderTable) addStruct(value reflect.Value) {
+	walkOptions(value, func(key string, field reflect.StructField, fv reflect.Value) {
+		// Do not render unset deprecated options to not trigger deprecation
+		// warnings when using the rendered configuration
+		if fieldDeprecation(field) != nil && fv.IsZero() {
+			return
+		}
+		// Skip fields that cannot be set in the configuration
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if _, ok := schemaType(ft); !ok {
+			return
+		}
+		t.addValue(key, fv)
+	})
+}
+
+func (t *renderTable) addValue(key string, value reflect.Value) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		// Keep pointers to types with custom text encoding
+		if value.Type().Implements(textMarshalerType) && value.Type() != reflect.PointerTo(secretType) {
+			break
+		}
+		value = value.Elem()
+	}
+
+	if value.Type() == secretType {
+		secret := value.Interface().(Secret)
+		if secret.Empty() {
+			t.set(key, `""`)
+		} else {
+			t.set(key, maskedSecret)
+		}
+		return
+	}
+
+	if literal, ok := formatLiteral(value); ok {
+		if value.Kind() == reflect.String && value.Len() > 0 && sensitiveKeyRe.MatchString(key) {
+			literal = maskedSecret
+		}
+		t.set(key, literal)
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		sub := &renderTable{}
+		sub.addStruct(value)
+		if (len(sub.values) > 0 || len(sub.tables) > 0) && t.claim(key) {
+			t.tables = append(t.tables, renderEntry{key: key, table: sub})
+		}
+	case reflect.Array, reflect.Slice:
+		tables := make([]*renderTable, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			sub := &renderTable{}
+			sub.addStruct(value.Index(i))
+			tables = append(tables, sub)
+		}
+		if len(tables) > 0 {
+			t.addArray(key, tables)
+		}
+	case reflect.Map:
+		if value.Len() == 0 {
+			return
+		}
+		sub := t.addTable(key)
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for _, k := range keys {
+			// Mask all entries of maps with a sensitive key, other entries
+			// are masked if their own key is sensitive e.g. for headers
+			if sensitiveKeyRe.MatchString(key) {
+				sub.set(fmt.Sprint(k), maskedSecret)
+				continue
+			}
+			sub.addValue(fmt.Sprint(k), value.MapIndex(k))
+		}
+	}
+}
+
+func (t *renderTable) write(w io.Writer, path, indent string) error {
+	for _, entry := range t.values {
+		if _, err := fmt.Fprintf(w, "%s%s = %s\n", indent, formatKey(entry.key), entry.value); err != nil {
+			return err
+		}
+	}
+	for _, entry := range t.tables {
+		subpath := path + "." + formatKey(entry.key)
+		if entry.table != nil {
+			if _, err := fmt.Fprintf(w, "%s[%s]\n", indent, subpath); err != nil {
+				return err
+			}
+			if err := entry.table.write(w, subpath, indent+"  "); err != nil {
+				return err
+			}
+			continue
+		}
+		for _, sub := range entry.array {
+			if _, err := fmt.Fprintf(w, "%s[[%s]]\n", indent, subpath); err != nil {
+				return err
+			}
+			if err := sub.write(w, subpath, indent+"  "); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// formatLiteral returns the TOML representation of scalar values and arrays
+// of scalar values
+func formatLiteral(value reflect.Value) (string, bool) {
+	switch value.Type() {
+	case durationType, timeDurationType:
+		return quote(time.Duration(value.Int()).String()), true
+	case secretType:
+		return maskedSecret, true
+	}
+	if !value.Type().Implements(textMarshalerType) && value.CanAddr() && value.Addr().Type().Implements(textMarshalerType) {
+		value = value.Addr()
+	}
+	if value.Type().Implements(textMarshalerType) {
+		buf, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return "", false
+		}
+		return quote(string(buf)), true
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return quote(redactCredentials(value.String())), true
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return formatFloat(value.Float()), true
+	case reflect.Interface:
+		if value.IsNil() {
+			return "", false
+		}
+		return formatLiteral(value.Elem())
+	case reflect.Array, reflect.Slice:
+		elements := make([]string, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			element, ok := formatLiteral(value.Index(i))
+			if !ok {
+				return "", false
+			}
+			elements = append(elements, element)
+		}
+		// Only treat empty arrays as literal if the elements are scalars
+		if value.Len() == 0 {
+			if _, ok := schemaType(value.Type().Elem()); !ok || isTable(value.Type().Elem()) {
+				return "", false
+			}
+		}
+		return "[" + strings.Join(elements, ", ") + "]", true
+	}
+	return "", false
+}
+
+// redactCredentials masks the password of URLs and DSNs contained in the
+// given string
+func redactCredentials(s string) string {
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			return u.Redacted()
+		}
+		return s
+	}
+	if m := dsnUserinfoRe.FindStringSubmatchIndex(s); m != nil {
+		s = s[:m[4]] + "xxxxx" + s[m[5]:]
+	}
+	return dsnPasswordRe.ReplaceAllString(s, "${1}=xxxxx")
+}
+
+func isTable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	typ, _ := schemaType(t)
+	return typ == "table" || typ == "map" || typ == "array"
+}
+
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eEnN") {
+		s += ".0"
+	}
+	return s
+}
+
+func formatKey(key string) string {
+	if bareKeyRe.MatchString(key) {
+		return key
+	}
+	return quote(key)
+}
+
+// quote returns the string as TOML basic string
+func quote(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\r':
+			buf.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&buf, `\u%04X`, r)
+			} else {
+				buf.WriteRune(r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func quoteStrings(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, quote(v))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/toml"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+// SchemaCategories contains the plugin categories supported by PluginSchema
+var SchemaCategories = []string{
+	"inputs", "outputs", "processors", "aggregators", "parsers", "serializers", "secretstores",
+}
+
+// PluginSchema describes the options of a plugin in a machine-readable way
+type PluginSchema struct {
+	// Name of the plugin in the form <category>.<name>
+	Name string `json:"name"`
+	// DataFormat is set for plugins accepting the parser or serializer
+	// options of the "data_format" setting
+	DataFormat bool `json:"data_format,omitempty"`
+	// Deprecated contains the deprecation information if the plugin is deprecated
+	Deprecated *DeprecationSchema `json:"deprecated,omitempty"`
+	// Options of the plugin
+	Options []OptionSchema `json:"options"`
+}
+
+// OptionSchema describes a single plugin option
+type OptionSchema struct {
+	// Name is the TOML key of the option
+	Name string `json:"name"`
+	// Type of the option, one of "string", "boolean", "integer", "unsigned",
+	// "number", "duration", "size", "secret", "array", "map", "table" or "any"
+	Type string `json:"type"`
+	// Element is the type of the elements of array and map options
+	Element string `json:"element,omitempty"`
+	// Default value of the option, unset for options without default
+	Default interface{} `json:"default,omitempty"`
+	// Deprecated contains the deprecation information if the option is deprecated
+	Deprecated *DeprecationSchema `json:"deprecated,omitempty"`
+	// Options of tables or of tables in arrays or maps
+	Options []OptionSchema `json:"options,omitempty"`
+}
+
+// DeprecationSchema describes the deprecation of a plugin or option
+type DeprecationSchema struct {
+	Since     string `json:"since"`
+	RemovalIn string `json:"removal_in,omitempty"`
+	Notice    string `json:"notice,omitempty"`
+}
+
+var (
+	durationType        = reflect.TypeOf(Duration(0))
+	timeDurationType    = reflect.TypeOf(time.Duration(0))
+	sizeType            = reflect.TypeOf(Size(0))
+	secretType          = reflect.TypeOf(Secret{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// NewPluginSchema creates the schema of the plugin with the given category
+// and name by inspecting the plugin's configuration structure. The defaults
+// are the values set by the plugin's creator function.
+func NewPluginSchema(category, name string) (*PluginSchema, error) {
+	var plugin interface{}
+	var deprecation telegraf.DeprecationInfo
+	var dataFormat bool
+
+	switch category {
+	case "inputs":
+		creator, found := inputs.Inputs[name]
+		if !found {
+			return nil, fmt.Errorf("unknown input %q", name)
+		}
+		input := creator()
+		_, parser := input.(telegraf.ParserPlugin)
+		_, parserFunc := input.(telegraf.ParserFuncPlugin)
+		plugin, dataFormat = input, parser || parserFunc
+		deprecation = inputs.Deprecations[name]
+	case "outputs":
+		creator, found := outputs.Outputs[name]
+		if !found {
+			return nil, fmt.Errorf("unknown output %q", name)
+		}
+		output := creator()
+		_, serializer := output.(telegraf.SerializerPlugin)
+		_, serializerOutput := output.(serializers.SerializerOutput)
+		plugin, dataFormat = output, serializer || serializerOutput
+		deprecation = outputs.Deprecations[name]
+	case "processors":
+		creator, found := processors.Processors[name]
+		if !found {
+			return nil, fmt.Errorf("unknown processor %q", name)
+		}
+		plugin = creator()
+		if p, ok := plugin.(processors.HasUnwrap); ok {
+			plugin = p.Unwrap()
+		}
+		_, parser := plugin.(telegraf.ParserPlugin)
+		_, parserFunc := plugin.(telegraf.ParserFuncPlugin)
+		_, serializer := plugin.(telegraf.SerializerPlugin)
+		dataFormat = parser || parserFunc || serializer
+		deprecation = processors.Deprecations[name]
+	case "aggregators":
+		creator, found := aggregators.Aggregators[name]
+		if !found {
+			return nil, fmt.Errorf("unknown aggregator %q", name)
+		}
+		plugin = creator()
+		deprecation = aggregators.Deprecations[name]
+	case "parsers":
+		creator, found := parsers.Parsers[name]
+		if !found {
+			return nil, fmt.Errorf("unknown parser %q", name)
+		}
+		plugin = creator("")
+	case "serializers":
+		creator, found := serializers.Serializers[name]
+		if !found {
+			return nil, fmt.Errorf("unknown serializer %q", name)
+		}
+		plugin = creator()
+	case "secretstores":
+		creator, found := secretstores.SecretStores[name]
+		if !found {
+			return nil, fmt.Errorf("unknown secret-store %q", name)
+		}
+		plugin = creator("")
+	default:
+		return nil, fmt.Errorf("unknown plugin category %q", category)
+	}
+
+	schema := &PluginSchema{
+		Name:       category + "." + name,
+		DataFormat: dataFormat,
+		Options:    structOptions(reflect.ValueOf(plugin)),
+	}
+	if deprecation.Since != "" {
+		schema.Deprecated = &DeprecationSchema{
+			Since:     deprecation.Since,
+			RemovalIn: deprecation.RemovalIn,
+			Notice:    deprecation.Notice,
+		}
+	}
+
+	return schema, nil
+}
+
+// structOptions returns the options of the given structure including the
+// options of embedded structures. The value might be the zero value of the
+// structure in which case no defaults are reported.
+func structOptions(value reflect.Value) []OptionSchema {
+	options := make([]OptionSchema, 0)
+	walkOptions(value, func(key string, field reflect.StructField, fv reflect.Value) {
+		option, ok := newOptionSchema(key, field.Type, fv)
+		if !ok {
+			return
+		}
+		option.Deprecated = fieldDeprecation(field)
+		options = append(options, option)
+	})
+	sort.SliceStable(options, func(i, j int) bool { return options[i].Name < options[j].Name })
+
+	return options
+}
+
+// walkOptions calls the given function for all fields of the structure that
+// can be set in the configuration using the field's TOML key. Embedded
+// structures without key are flattened into the parent like the TOML decoder
+// does.
+func walkOptions(value reflect.Value, fn func(key string, field reflect.StructField, fv reflect.Value)) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return
+	}
+	t := value.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("toml")
+		if key == "-" {
+			continue
+		}
+
+		if field.Anonymous && key == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				fv := value.Field(i)
+				if fv.Kind() == reflect.Pointer && fv.IsNil() {
+					fv = reflect.New(ft)
+				}
+				walkOptions(fv, fn)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+		if key == "" {
+			key = toml.DefaultConfig.FieldToKey(t, field.Name)
+		}
+		fn(key, field, value.Field(i))
+	}
+}
+
+func newOptionSchema(key string, t reflect.Type, value reflect.Value) (OptionSchema, bool) {
+	option := OptionSchema{Name: key}
+
+	// Dereference pointers and make sure we work on valid values to be able
+	// to determine the defaults
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if value.IsNil() {
+			value = reflect.Zero(t)
+		} else {
+			value = value.Elem()
+		}
+	}
+
+	typ, ok := schemaType(t)
+	if !ok {
+		return option, false
+	}
+	option.Type = typ
+
+	switch typ {
+	case "array", "map":
+		et := t.Elem()
+		if et.Kind() == reflect.Pointer {
+			et = et.Elem()
+		}
+		var ok bool
+		if option.Element, ok = schemaType(et); !ok {
+			return option, false
+		}
+		if option.Element == "table" {
+			option.Options = structOptions(reflect.New(et))
+		} else if value.Len() > 0 {
+			option.Default = value.Interface()
+		}
+	case "table":
+		option.Options = structOptions(value)
+	case "secret":
+	case "duration":
+		if !value.IsZero() {
+			option.Default = time.Duration(value.Int()).String()
+		}
+	default:
+		if !value.IsZero() {
+			option.Default = value.Interface()
+		}
+	}
+
+	return option, true
+}
+
+// schemaType maps the Go type of an option to the type names of the schema
+func schemaType(t reflect.Type) (string, bool) {
+	switch t {
+	case durationType, timeDurationType:
+		return "duration", true
+	case sizeType:
+		return "size", true
+	case secretType:
+		return "secret", true
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return "string", true
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string", true
+	case reflect.Bool:
+		return "boolean", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "unsigned", true
+	case reflect.Float32, reflect.Float64:
+		return "number", true
+	case reflect.Array, reflect.Slice:
+		return "array", true
+	case reflect.Map:
+		return "map", true
+	case reflect.Struct:
+		return "table", true
+	case reflect.Interface:
+		// Only empty interfaces can be set from the configuration
+		return "any", t.NumMethod() == 0
+	}
+	return "", false
+}
+
+// fieldDeprecation returns the deprecation information of the struct field's
+// "deprecated" tag in the form "since[;removal];notice"
+func fieldDeprecation(field reflect.StructField) *DeprecationSchema {
+	tags := strings.SplitN(field.Tag.Get("deprecated"), ";", 3)
+	if tags[0] == "" {
+		return nil
+	}
+
+	info := &DeprecationSchema{Since: tags[0]}
+	if len(tags) > 1 {
+		info.Notice = tags[len(tags)-1]
+	}
+	if len(tags) > 2 {
+		info.RemovalIn = tags[1]
+	}
+	return info
+}
@@ -0,0 +1,45 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+func TestPluginSchema(t *testing.T) {
+	schema, err := config.NewPluginSchema("inputs", "exec")
+	require.NoError(t, err)
+	require.Equal(t, "inputs.exec", schema.Name)
+	require.True(t, schema.DataFormat)
+	require.Nil(t, schema.Deprecated)
+
+	options := make(map[string]config.OptionSchema, len(schema.Options))
+	for _, option := range schema.Options {
+		options[option.Name] = option
+	}
+
+	// Options without TOML tag use the snake-case field name
+	require.Equal(t, config.OptionSchema{Name: "pid_file", Type: "string"}, options["pid_file"])
+	require.Equal(t, config.OptionSchema{Name: "timeout", Type: "duration", Default: "5s"}, options["timeout"])
+	require.Equal(t, config.OptionSchema{Name: "max_body_size", Type: "size"}, options["max_body_size"])
+	require.Equal(t, config.OptionSchema{Name: "servers", Type: "array", Element: "string"}, options["servers"])
+	require.Equal(t, config.OptionSchema{Name: "port", Type: "integer"}, options["port"])
+
+	// Options of embedded structures are flattened
+	require.Contains(t, options, "tls_cert")
+	require.Equal(t, "string", options["tls_cert"].Type)
+
+	// Ignored fields are not part of the schema
+	require.NotContains(t, options, "log")
+	require.NotContains(t, options, "parser")
+}
+
+func TestPluginSchemaUnknown(t *testing.T) {
+	_, err := config.NewPluginSchema("inputs", "does_not_exist")
+	require.EqualError(t, err, `unknown input "does_not_exist"`)
+
+	_, err = config.NewPluginSchema("foo", "exec")
+	require.EqualError(t, err, `unknown plugin category "foo"`)
+}
@@ -0,0 +1,62 @@
+package filter
+
+import (
+	"container/list"
+	"sync"
+)
+
+// cacheSize is the maximum number of compiled filters kept for reuse
+const cacheSize = 1024
+
+type cacheEntry struct {
+	key    string
+	filter Filter
+}
+
+// filterCache keeps the most recently used filters by their patterns. The
+// cache is bounded as filters might also be compiled at runtime from data.
+type filterCache struct {
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+	sync.Mutex
+}
+
+func newFilterCache(capacity int) *filterCache {
+	return &filterCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element, capacity),
+	}
+}
+
+func (c *filterCache) get(key string) (Filter, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	e, found := c.entries[key]
+	if !found {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).filter, true
+}
+
+func (c *filterCache) put(key string, f Filter) {
+	c.Lock()
+	defer c.Unlock()
+
+	if e, found := c.entries[key]; found {
+		e.Value.(*cacheEntry).filter = f
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, filter: f})
+
+	// Evict the least recently used filter
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
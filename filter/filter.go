@@ -10,6 +10,10 @@ type Filter interface {
 	Match(string) bool
 }
 
+// cache holds the recently compiled filters by their patterns. Filters are
+// immutable and thus can be shared.
+var cache = newFilterCache(cacheSize)
+
 // Compile takes a list of string filters and returns a Filter interface
 // for matching a given string against the filter list. The filter list
 // supports glob matching too, ie:
@@ -33,15 +37,32 @@ func Compile(filters []string) (Filter, error) {
 		}
 	}
 
+	// reuse the filter if the same patterns were compiled before e.g. for
+	// another plugin instance
+	key := strings.Join(filters, "\x00")
+	if f, found := cache.get(key); found {
+		return f, nil
+	}
+
+	var f Filter
+	var err error
 	switch {
 	case noGlob:
 		// return non-globbing filter if not needed.
-		return compileFilterNoGlob(filters), nil
+		f = compileFilterNoGlob(filters)
 	case len(filters) == 1:
-		return glob.Compile(filters[0])
+		f, err = glob.Compile(filters[0])
+	case len(filters) < multiFilterMinPatterns:
+		f, err = glob.Compile("{" + strings.Join(filters, ",") + "}")
 	default:
-		return glob.Compile("{" + strings.Join(filters, ",") + "}")
+		f, err = compileMultiFilter(filters)
 	}
+	if err != nil {
+		return nil, err
+	}
+	cache.put(key, f)
+
+	return f, nil
 }
 
 func MustCompile(filters []string) Filter {
@@ -1,9 +1,13 @@
 package filter
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/gobwas/glob"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCompile(t *testing.T) {
@@ -37,6 +41,78 @@ func TestCompile(t *testing.T) {
 	assert.True(t, f.Match("network"))
 }
 
+func TestCompileMixedPatterns(t *testing.T) {
+	patterns := []string{
+		"cpu", "mem*", "*_total", "disk?", "net[0-9]", "*", "a*b", "swap{in,out}", `esc\*`,
+	}
+	inputs := []string{
+		"cpu", "cpu0", "mem", "memory", "requests_total", "_total", "total",
+		"disk", "disk0", "disk01", "net1", "netx", "ab", "axxb", "abx", "swapin",
+		"swapout", "swap", "esc*", "escx",
+	}
+
+	// Check all combinations of up to three patterns against the equivalent
+	// alternation of globs used previously
+	for i := range patterns {
+		for j := i; j < len(patterns); j++ {
+			for k := j; k < len(patterns); k++ {
+				list := []string{patterns[i], patterns[j], patterns[k]}
+				if !hasMeta(strings.Join(list, "")) {
+					continue
+				}
+				f, err := compileMultiFilter(list)
+				require.NoError(t, err)
+				expected := glob.MustCompile("{" + strings.Join(list, ",") + "}")
+				for _, s := range inputs {
+					require.Equalf(t, expected.Match(s), f.Match(s), "%q on %v", s, list)
+				}
+			}
+		}
+	}
+}
+
+func TestCompileCache(t *testing.T) {
+	patterns := []string{"cpu", "mem*", "disk*", "net*", "*_total", "swap", "diskio", "kernel"}
+	f1, err := Compile(patterns)
+	require.NoError(t, err)
+	f2, err := Compile(append([]string{}, patterns...))
+	require.NoError(t, err)
+	require.Same(t, f1, f2)
+
+	f3, err := Compile(patterns[1:])
+	require.NoError(t, err)
+	require.NotSame(t, f1, f3)
+
+	_, err = Compile([]string{"cpu", "mem[*"})
+	require.Error(t, err)
+}
+
+func TestFilterCacheEviction(t *testing.T) {
+	c := newFilterCache(2)
+	a := &filtersingle{s: "a"}
+	b := &filtersingle{s: "b"}
+	d := &filtersingle{s: "d"}
+
+	c.put("a", a)
+	c.put("b", b)
+
+	// Using "a" makes "b" the least recently used filter to be evicted
+	f, found := c.get("a")
+	require.True(t, found)
+	require.Same(t, a, f)
+	c.put("d", d)
+
+	_, found = c.get("b")
+	require.False(t, found)
+	f, found = c.get("a")
+	require.True(t, found)
+	require.Same(t, a, f)
+	f, found = c.get("d")
+	require.True(t, found)
+	require.Same(t, d, f)
+	require.Equal(t, 2, c.order.Len())
+}
+
 func TestIncludeExclude(t *testing.T) {
 	tags := []string{}
 	labels := []string{"best", "com_influxdata", "timeseries", "com_influxdata_telegraf", "ever"}
@@ -112,3 +188,39 @@ func BenchmarkFilter2NoGlob(b *testing.B) {
 	}
 	benchbool = tmp
 }
+
+func BenchmarkFilterLargeList(b *testing.B) {
+	patterns := make([]string, 0, 300)
+	for i := 0; i < 100; i++ {
+		patterns = append(patterns,
+			fmt.Sprintf("metric_%d", i),
+			fmt.Sprintf("prefix_%d_*", i),
+			fmt.Sprintf("*_suffix_%d", i),
+		)
+	}
+	f, _ := Compile(patterns)
+	var tmp bool
+	for n := 0; n < b.N; n++ {
+		tmp = f.Match("prefix_99_metric")
+	}
+	benchbool = tmp
+}
+
+func BenchmarkFilterMultiVsGlob(b *testing.B) {
+	for _, size := range []int{2, 4, 8, 16} {
+		patterns := make([]string, 0, size)
+		for i := 0; len(patterns) < size; i++ {
+			patterns = append(patterns, fmt.Sprintf("metric_%d", i), fmt.Sprintf("prefix_%d_*", i))
+		}
+		for name, f := range map[string]Filter{"multi": MustCompile(patterns), "glob": glob.MustCompile("{" + strings.Join(patterns, ",") + "}")} {
+			match := fmt.Sprintf("prefix_%d_metric", size/2-1)
+			b.Run(fmt.Sprintf("%s_%d", name, size), func(b *testing.B) {
+				var tmp bool
+				for n := 0; n < b.N; n++ {
+					tmp = f.Match(match) || f.Match("prefix_unmatched")
+				}
+				benchbool = tmp
+			})
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// multiFilterMinPatterns is the number of patterns from which on the
+// multiFilter outperforms a single glob alternation in the benchmarks
+const multiFilterMinPatterns = 8
+
+// multiFilter matches a string against a list of patterns split by their
+// kind, so the cost of a match does not grow with the number of patterns for
+// the common cases of exact strings, prefixes (e.g. "cpu*") and suffixes (e.g.
+// "*_total"). Only the remaining patterns are matched as generic globs.
+type multiFilter struct {
+	exact    Filter
+	prefixes *affixSet
+	suffixes *affixSet
+	globs    []glob.Glob
+}
+
+func compileMultiFilter(filters []string) (Filter, error) {
+	f := &multiFilter{}
+	var exact []string
+	for _, pattern := range filters {
+		switch kind, literal := classify(pattern); kind {
+		case patternExact:
+			exact = append(exact, literal)
+		case patternPrefix:
+			if f.prefixes == nil {
+				f.prefixes = &affixSet{}
+			}
+			f.prefixes.insert(literal)
+		case patternSuffix:
+			if f.suffixes == nil {
+				f.suffixes = &affixSet{suffix: true}
+			}
+			f.suffixes.insert(literal)
+		default:
+			g, err := glob.Compile(pattern)
+			if err != nil {
+				return nil, err
+			}
+			f.globs = append(f.globs, g)
+		}
+	}
+	if len(exact) > 0 {
+		f.exact = compileFilterNoGlob(exact)
+	}
+	return f, nil
+}
+
+func (f *multiFilter) Match(s string) bool {
+	if f.exact != nil && f.exact.Match(s) {
+		return true
+	}
+	if f.prefixes != nil && f.prefixes.match(s) {
+		return true
+	}
+	if f.suffixes != nil && f.suffixes.match(s) {
+		return true
+	}
+	for _, g := range f.globs {
+		if g.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+type patternKind int
+
+const (
+	patternGlob patternKind = iota
+	patternExact
+	patternPrefix
+	patternSuffix
+)
+
+// classify returns the kind of the pattern and the literal part for
+// non-glob patterns. Patterns with escapes or alternations are always treated
+// as generic globs.
+func classify(pattern string) (patternKind, string) {
+	const special = "*?[]{}\\"
+
+	if !strings.ContainsAny(pattern, special) {
+		return patternExact, pattern
+	}
+	if literal, found := strings.CutSuffix(pattern, "*"); found && !strings.ContainsAny(literal, special) {
+		return patternPrefix, literal
+	}
+	if literal, found := strings.CutPrefix(pattern, "*"); found && !strings.ContainsAny(literal, special) {
+		return patternSuffix, literal
+	}
+	return patternGlob, ""
+}
+
+// affixSet stores prefixes or suffixes grouped by their length, so checking
+// a string costs one lookup per distinct length instead of one comparison per
+// pattern
+type affixSet struct {
+	groups []affixGroup
+	suffix bool
+}
+
+type affixGroup struct {
+	length int
+	values map[string]struct{}
+}
+
+func (a *affixSet) insert(s string) {
+	for _, g := range a.groups {
+		if g.length == len(s) {
+			g.values[s] = struct{}{}
+			return
+		}
+	}
+	a.groups = append(a.groups, affixGroup{
+		length: len(s),
+		values: map[string]struct{}{s: {}},
+	})
+}
+
+func (a *affixSet) match(s string) bool {
+	for _, g := range a.groups {
+		if len(s) < g.length {
+			continue
+		}
+		affix := s[:g.length]
+		if a.suffix {
+			affix = s[len(s)-g.length:]
+		}
+		if _, found := g.values[affix]; found {
+			return true
+		}
+	}
+	return false
+}
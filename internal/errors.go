@@ -0,0 +1,18 @@
+package internal
+
+// PartialWriteError indicates that only a subset of the metrics of a batch
+// was written. The failed metrics are kept for the next write while all other
+// metrics of the batch are accepted. Please note: the metrics are specified as
+// indices into the batch passed to the output's Write function.
+type PartialWriteError struct {
+	Err           error
+	MetricsFailed []int
+}
+
+func (e *PartialWriteError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
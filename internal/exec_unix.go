@@ -17,30 +17,31 @@ const KillGrace = 5 * time.Second
 // It assumes the command has already been started.
 // If the command times out, it attempts to kill the process.
 func WaitTimeout(c *exec.Cmd, timeout time.Duration) error {
-	var kill *time.Timer
+	// The kill timer is only armed after sending SIGTERM but created upfront
+	// to not race on the timer when shutting down
+	kill := time.AfterFunc(KillGrace, func() {
+		err := c.Process.Kill()
+		if err != nil {
+			log.Printf("E! [agent] Error killing process: %s", err)
+			return
+		}
+	})
+	kill.Stop()
+
 	term := time.AfterFunc(timeout, func() {
 		err := c.Process.Signal(syscall.SIGTERM)
 		if err != nil {
 			log.Printf("E! [agent] Error terminating process: %s", err)
 			return
 		}
-
-		kill = time.AfterFunc(KillGrace, func() {
-			err := c.Process.Kill()
-			if err != nil {
-				log.Printf("E! [agent] Error killing process: %s", err)
-				return
-			}
-		})
+		kill.Reset(KillGrace)
 	})
 
 	err := c.Wait()
 
 	// Shutdown all timers
-	if kill != nil {
-		kill.Stop()
-	}
 	termSent := !term.Stop()
+	kill.Stop()
 
 	// If the process exited without error treat it as success.  This allows a
 	// process to do a clean shutdown on signal.
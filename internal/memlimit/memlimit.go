@@ -0,0 +1,101 @@
+// Package memlimit provides the state of the agent's memory limit allowing
+// plugins to shed load while the limit is exceeded.
+package memlimit
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// Policies for shedding load while the memory limit is exceeded
+const (
+	// PolicyDropOldest drops the oldest metrics buffered in the outputs
+	PolicyDropOldest = "drop_oldest"
+	// PolicyPause blocks the inputs when adding metrics to create
+	// backpressure for listeners
+	PolicyPause = "pause"
+	// PolicyReject rejects requests of HTTP based listeners
+	PolicyReject = "reject"
+)
+
+var (
+	policy   atomic.Value
+	exceeded atomic.Bool
+
+	mu       sync.Mutex
+	released = make(chan struct{})
+)
+
+func init() {
+	policy.Store("")
+}
+
+// SetPolicy sets the shedding policy applied while the limit is exceeded
+func SetPolicy(p string) {
+	policy.Store(p)
+}
+
+// Policy returns the shedding policy
+func Policy() string {
+	return policy.Load().(string)
+}
+
+// Update sets the state of the memory limit and releases the waiting callers
+// once the limit is not exceeded anymore
+func Update(limitExceeded bool) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if exceeded.Load() == limitExceeded {
+		return
+	}
+	exceeded.Store(limitExceeded)
+	if !limitExceeded {
+		close(released)
+		released = make(chan struct{})
+	}
+}
+
+// Exceeded returns true if the memory limit is currently exceeded
+func Exceeded() bool {
+	return exceeded.Load()
+}
+
+// Reject returns true if new requests should be rejected
+func Reject() bool {
+	return exceeded.Load() && Policy() == PolicyReject
+}
+
+// Wait blocks while the limit is exceeded when using the pause policy
+func Wait() {
+	if !exceeded.Load() || Policy() != PolicyPause {
+		return
+	}
+
+	mu.Lock()
+	ch := released
+	limitExceeded := exceeded.Load()
+	mu.Unlock()
+	if limitExceeded {
+		<-ch
+	}
+}
+
+// Handler wraps the given handler to reject requests with "503 Service
+// Unavailable" while the limit is exceeded when using the reject policy
+func Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if Reject() {
+			ServiceUnavailable(res)
+			return
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+// ServiceUnavailable writes a response asking the client to retry later
+func ServiceUnavailable(res http.ResponseWriter) {
+	res.Header().Set("Retry-After", "10")
+	http.Error(res, "memory limit exceeded", http.StatusServiceUnavailable)
+}
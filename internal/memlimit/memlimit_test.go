@@ -0,0 +1,60 @@
+package memlimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWait(t *testing.T) {
+	SetPolicy(PolicyPause)
+	defer SetPolicy("")
+
+	// Not exceeded, so no blocking
+	Wait()
+
+	Update(true)
+	done := make(chan struct{})
+	go func() {
+		Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		require.FailNow(t, "wait returned while limit is exceeded")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	Update(false)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.FailNow(t, "wait did not return after release")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	SetPolicy(PolicyReject)
+	defer SetPolicy("")
+
+	handler := Handler(http.HandlerFunc(func(res http.ResponseWriter, _ *http.Request) {
+		res.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	Update(true)
+	defer Update(false)
+	require.True(t, Reject())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, "10", rec.Header().Get("Retry-After"))
+}
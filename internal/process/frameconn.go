@@ -0,0 +1,154 @@
+package process
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/framing"
+)
+
+// Protocols for communicating with the process
+const (
+	// ProtocolV1 exchanges newline-delimited data
+	ProtocolV1 = "v1"
+	// ProtocolV2 exchanges length-prefixed frames
+	ProtocolV2 = "v2"
+)
+
+// heartbeatMisses is the number of heartbeat intervals without any frame
+// received after which the process is considered to be stalled
+const heartbeatMisses = 3
+
+// FrameConn handles the communication with a process using the length-prefixed
+// framing of the protocol v2 including heartbeats and configuration push.
+type FrameConn struct {
+	// HeartbeatInterval is the interval of sending heartbeats to the process,
+	// the process is restarted if no frame is received for three intervals.
+	// Heartbeats are disabled if zero.
+	HeartbeatInterval time.Duration
+	// Config is sent to the process in a config frame on each (re)start
+	Config []byte
+	Log    telegraf.Logger
+
+	process   *Process
+	lastSeen  atomic.Int64
+	writeLock sync.Mutex
+	done      chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewFrameConn creates a connection for the given process. The reader
+// returned by Reader must be used as stdout reading function of the process.
+func NewFrameConn(p *Process) *FrameConn {
+	c := &FrameConn{
+		Log:     p.Log,
+		process: p,
+	}
+	p.OnStartFn = c.pushConfig
+	return c
+}
+
+// pushConfig sends the configuration on (re)start of the process before any
+// other frame
+func (c *FrameConn) pushConfig() {
+	c.lastSeen.Store(time.Now().UnixNano())
+	if len(c.Config) == 0 {
+		return
+	}
+	if err := c.Write(framing.Config, c.Config); err != nil {
+		c.Log.Errorf("Sending configuration failed: %v", err)
+	}
+}
+
+// Write sends a frame to the process
+func (c *FrameConn) Write(frameType byte, payload []byte) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	return framing.Write(c.process, frameType, payload)
+}
+
+// Reader returns a function reading the frames of the process and passing all
+// frames except heartbeats to the given handler
+func (c *FrameConn) Reader(handler func(framing.Frame)) func(io.Reader) {
+	return func(r io.Reader) {
+		for {
+			f, err := framing.Read(r)
+			if err != nil {
+				if errors.Is(err, io.EOF) || errors.Is(err, os.ErrClosed) {
+					return
+				}
+
+				// We cannot find the start of the next frame so restart the
+				// process to get back in sync
+				c.Log.Errorf("Reading frame failed, restarting process: %v", err)
+				if err := c.process.Kill(); err != nil {
+					c.Log.Errorf("Killing process failed: %v", err)
+				}
+				_, _ = io.Copy(io.Discard, r)
+				return
+			}
+			c.lastSeen.Store(time.Now().UnixNano())
+
+			if f.Type == framing.Heartbeat {
+				continue
+			}
+			handler(f)
+		}
+	}
+}
+
+// Start starts sending heartbeats and watching for a stalled process
+func (c *FrameConn) Start() {
+	c.done = make(chan struct{})
+	if c.HeartbeatInterval <= 0 {
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+			}
+
+			if err := c.Write(framing.Heartbeat, nil); err != nil {
+				c.Log.Debugf("Sending heartbeat failed: %v", err)
+			}
+
+			since := time.Since(time.Unix(0, c.lastSeen.Load()))
+			if since > heartbeatMisses*c.HeartbeatInterval {
+				c.Log.Errorf("No frame received for %s, restarting process", since.Truncate(time.Millisecond))
+				c.lastSeen.Store(time.Now().UnixNano())
+				if err := c.process.Kill(); err != nil {
+					c.Log.Errorf("Killing process failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops the heartbeats and requests the process to shut down gracefully.
+// The process itself must be stopped by the caller afterwards.
+func (c *FrameConn) Stop() {
+	if c.done != nil {
+		close(c.done)
+	}
+	c.wg.Wait()
+
+	if err := c.Write(framing.Shutdown, nil); err != nil {
+		c.Log.Debugf("Sending shutdown request failed: %v", err)
+	}
+}
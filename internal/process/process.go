@@ -14,6 +14,10 @@ import (
 	"github.com/influxdata/telegraf"
 )
 
+// ErrWriteTimeout is returned by Write if the process did not consume the
+// data in time
+var ErrWriteTimeout = errors.New("timeout writing to process")
+
 // Process is a long-running process manager that will restart processes if they stop.
 type Process struct {
 	Cmd          *exec.Cmd
@@ -22,7 +26,18 @@ type Process struct {
 	Stderr       io.ReadCloser
 	ReadStdoutFn func(io.Reader)
 	ReadStderrFn func(io.Reader)
+	OnStartFn    func()
 	RestartDelay time.Duration
+	// RestartDelayMax enables an exponential backoff of the restart delay
+	// up to the given value if greater than RestartDelay
+	RestartDelayMax time.Duration
+	// MaxRestarts is the number of consecutive restarts after which the
+	// process is given up. Zero means restarting forever.
+	MaxRestarts int
+	// WriteTimeout is the maximum time for Write to hand over the data to
+	// the process. A process not consuming its input in time is killed and
+	// restarted. Zero means no timeout.
+	WriteTimeout time.Duration
 	Log          telegraf.Logger
 
 	name       string
@@ -31,6 +46,9 @@ type Process struct {
 	pid        int32
 	cancel     context.CancelFunc
 	mainLoopWg sync.WaitGroup
+
+	errLock sync.Mutex
+	err     error
 }
 
 // New creates a new process wrapper
@@ -67,6 +85,9 @@ func (p *Process) Start() error {
 	go func() {
 		if err := p.cmdLoop(ctx); err != nil {
 			p.Log.Errorf("Process quit with message: %v", err)
+			p.errLock.Lock()
+			p.err = err
+			p.errLock.Unlock()
 		}
 		p.mainLoopWg.Done()
 	}()
@@ -116,9 +137,59 @@ func (p *Process) cmdStart() error {
 		return fmt.Errorf("error starting process: %w", err)
 	}
 	atomic.StoreInt32(&p.pid, int32(p.Cmd.Process.Pid))
+
+	if p.OnStartFn != nil {
+		p.OnStartFn()
+	}
 	return nil
 }
 
+// Err returns the reason if the process was given up and is not restarted
+// anymore, nil otherwise
+func (p *Process) Err() error {
+	p.errLock.Lock()
+	defer p.errLock.Unlock()
+	return p.err
+}
+
+// Write writes the data to stdin of the process respecting the write
+// timeout. On timeout the process is killed to unblock the write and
+// restarted according to the restart policy.
+func (p *Process) Write(data []byte) (int, error) {
+	if err := p.Err(); err != nil {
+		return 0, fmt.Errorf("process not running: %w", err)
+	}
+
+	stdin := p.Stdin
+	if p.WriteTimeout <= 0 {
+		return stdin.Write(data)
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := stdin.Write(data)
+		done <- result{n, err}
+	}()
+
+	timer := time.NewTimer(p.WriteTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-timer.C:
+		p.Log.Errorf("Process did not consume its input within %s, restarting process", p.WriteTimeout)
+		if err := p.Kill(); err != nil {
+			p.Log.Errorf("Killing process failed: %v", err)
+		}
+		return 0, ErrWriteTimeout
+	}
+}
+
 func (p *Process) Pid() int {
 	pid := atomic.LoadInt32(&p.pid)
 	return int(pid)
@@ -126,25 +197,46 @@ func (p *Process) Pid() int {
 
 // cmdLoop watches an already running process, restarting it when appropriate.
 func (p *Process) cmdLoop(ctx context.Context) error {
+	delay := p.RestartDelay
+	var restarts int
 	for {
+		started := time.Now()
 		err := p.cmdWait(ctx)
 		if isQuitting(ctx) {
 			p.Log.Infof("Process %s shut down", p.Cmd.Path)
 			return nil
 		}
-
 		p.Log.Errorf("Process %s exited: %v", p.Cmd.Path, err)
-		p.Log.Infof("Restarting in %s...", p.RestartDelay)
+
+		// A process running for longer than the maximum restart delay is
+		// considered to be healthy so the restart policy starts over
+		if time.Since(started) >= p.RestartDelay && time.Since(started) >= p.RestartDelayMax {
+			delay = p.RestartDelay
+			restarts = 0
+		}
+		if p.MaxRestarts > 0 && restarts >= p.MaxRestarts {
+			return fmt.Errorf("giving up after %d restarts: %w", restarts, err)
+		}
+		restarts++
+
+		p.Log.Infof("Restarting in %s...", delay)
 
 		select {
 		case <-ctx.Done():
 			return nil
-		case <-time.After(p.RestartDelay):
+		case <-time.After(delay):
 			// Continue the loop and restart the process
 			if err := p.cmdStart(); err != nil {
 				return err
 			}
 		}
+
+		if p.RestartDelayMax > p.RestartDelay {
+			delay *= 2
+			if delay > p.RestartDelayMax || delay <= 0 {
+				delay = p.RestartDelayMax
+			}
+		}
 	}
 }
 
@@ -197,3 +289,12 @@ func isQuitting(ctx context.Context) bool {
 func defaultReadPipe(r io.Reader) {
 	_, _ = io.Copy(io.Discard, r)
 }
+
+// Kill terminates the current process immediately. Unless the process is
+// stopped, it is restarted after the restart delay.
+func (p *Process) Kill() error {
+	if p.Cmd == nil || p.Cmd.Process == nil {
+		return nil
+	}
+	return p.Cmd.Process.Kill()
+}
@@ -56,6 +56,58 @@ func TestRestartingRebindsPipes(t *testing.T) {
 	p.Stop()
 }
 
+func TestMaxRestarts(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	p, err := New([]string{exe, "-external"}, []string{"INTERNAL_PROCESS_MODE=exit"})
+	require.NoError(t, err)
+	p.RestartDelay = time.Millisecond
+	p.RestartDelayMax = time.Second
+	p.MaxRestarts = 2
+	p.Log = testutil.Logger{}
+
+	var starts atomic.Int64
+	p.OnStartFn = func() { starts.Add(1) }
+
+	require.NoError(t, p.Start())
+	require.Eventually(t, func() bool {
+		return p.Err() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.ErrorContains(t, p.Err(), "giving up after 2 restarts")
+	require.EqualValues(t, 3, starts.Load())
+
+	_, err = p.Write([]byte("test\n"))
+	require.ErrorContains(t, err, "process not running")
+	p.Stop()
+}
+
+func TestWriteTimeout(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	p, err := New([]string{exe, "-external"}, []string{"INTERNAL_PROCESS_MODE=application"})
+	require.NoError(t, err)
+	p.RestartDelay = time.Millisecond
+	p.WriteTimeout = 100 * time.Millisecond
+	p.Log = testutil.Logger{}
+
+	var starts atomic.Int64
+	p.OnStartFn = func() { starts.Add(1) }
+	require.NoError(t, p.Start())
+
+	// The process never reads its input, so the write blocks as soon as the
+	// pipe buffer is full
+	_, err = p.Write(make([]byte, 1024*1024))
+	require.ErrorIs(t, err, ErrWriteTimeout)
+
+	// The process is restarted after being killed
+	require.Eventually(t, func() bool {
+		return starts.Load() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	p.Stop()
+}
+
 var external = flag.Bool("external", false,
 	"if true, run externalProcess instead of tests")
 
@@ -66,6 +118,9 @@ func TestMain(m *testing.M) {
 		externalProcess()
 		os.Exit(0)
 	}
+	if *external && runMode == "exit" {
+		os.Exit(1)
+	}
 	code := m.Run()
 	os.Exit(code)
 }
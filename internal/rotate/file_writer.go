@@ -2,6 +2,7 @@ package rotate
 
 // Rotating things
 import (
+	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/klauspost/compress/zstd"
 )
 
 // FilePerm defines the permissions that Writer will use for all
@@ -32,18 +35,34 @@ type FileWriter struct {
 	interval                 time.Duration
 	maxSizeInBytes           int64
 	maxArchives              int
+	maxAge                   time.Duration
+	compression              string
 	expireTime               time.Time
 	bytesWritten             int64
 	sync.Mutex
 }
 
-// NewFileWriter creates a new file writer.
-func NewFileWriter(filename string, interval time.Duration, maxSizeInBytes int64, maxArchives int) (io.WriteCloser, error) {
-	if interval == 0 && maxSizeInBytes <= 0 {
-		// No rotation needed so a basic io.Writer will do the trick
-		return openFile(filename)
+// Option configures optional behavior of the FileWriter
+type Option func(*FileWriter)
+
+// WithMaxAge removes archives older than the given age at rotation time.
+func WithMaxAge(age time.Duration) Option {
+	return func(w *FileWriter) {
+		w.maxAge = age
 	}
+}
 
+// WithCompression compresses the archives using the given algorithm.
+// Supported algorithms are "gzip" and "zstd", an empty string disables
+// compression.
+func WithCompression(algorithm string) Option {
+	return func(w *FileWriter) {
+		w.compression = algorithm
+	}
+}
+
+// NewFileWriter creates a new file writer.
+func NewFileWriter(filename string, interval time.Duration, maxSizeInBytes int64, maxArchives int, options ...Option) (io.WriteCloser, error) {
 	w := &FileWriter{
 		filename:                 filename,
 		interval:                 interval,
@@ -51,6 +70,20 @@ func NewFileWriter(filename string, interval time.Duration, maxSizeInBytes int64
 		maxArchives:              maxArchives,
 		filenameRotationTemplate: getFilenameRotationTemplate(filename),
 	}
+	for _, opt := range options {
+		opt(w)
+	}
+
+	switch w.compression {
+	case "", "gzip", "zstd":
+	default:
+		return nil, fmt.Errorf("unsupported compression algorithm %q", w.compression)
+	}
+
+	if interval == 0 && maxSizeInBytes <= 0 {
+		// No rotation needed so a basic io.Writer will do the trick
+		return openFile(filename)
+	}
 
 	if err := w.openCurrent(); err != nil {
 		return nil, err
@@ -155,19 +188,102 @@ func (w *FileWriter) rotate() (err error) {
 		return err
 	}
 
+	if w.compression != "" {
+		if err := compressFile(rotatedFilename, w.compression); err != nil {
+			return err
+		}
+	}
+
 	return w.purgeArchivesIfNeeded()
 }
 
+// compressFile replaces the given file by a compressed version with the
+// extension of the algorithm appended to the name
+func compressFile(filename, algorithm string) error {
+	src, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	ext := ".gz"
+	if algorithm == "zstd" {
+		ext = ".zst"
+	}
+	dst, err := os.OpenFile(filename+ext, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, FilePerm)
+	if err != nil {
+		return err
+	}
+
+	var encoder io.WriteCloser
+	if algorithm == "zstd" {
+		encoder, err = zstd.NewWriter(dst)
+		if err != nil {
+			dst.Close()
+			return err
+		}
+	} else {
+		encoder = gzip.NewWriter(dst)
+	}
+
+	if _, err := io.Copy(encoder, src); err != nil {
+		encoder.Close()
+		dst.Close()
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(filename)
+}
+
 func (w *FileWriter) purgeArchivesIfNeeded() (err error) {
-	if w.maxArchives == -1 {
+	if w.maxArchives == -1 && w.maxAge <= 0 {
 		//Skip archiving
 		return nil
 	}
 
+	// Find plain and compressed archives
+	pattern := fmt.Sprintf(w.filenameRotationTemplate, "*", "*")
 	var matches []string
-	if matches, err = filepath.Glob(fmt.Sprintf(w.filenameRotationTemplate, "*", "*")); err != nil {
+	if matches, err = filepath.Glob(pattern); err != nil {
+		return err
+	}
+	compressed, err := filepath.Glob(pattern + ".*")
+	if err != nil {
 		return err
 	}
+	for _, filename := range compressed {
+		// Files without extension already match the plain pattern
+		if ok, _ := filepath.Match(pattern, filename); !ok {
+			matches = append(matches, filename)
+		}
+	}
+
+	if w.maxAge > 0 {
+		cutoff := time.Now().Add(-w.maxAge)
+		remaining := matches[:0]
+		for _, filename := range matches {
+			info, err := os.Stat(filename)
+			if err == nil && info.ModTime().Before(cutoff) {
+				if err := os.Remove(filename); err != nil {
+					return err
+				}
+				continue
+			}
+			remaining = append(remaining, filename)
+		}
+		matches = remaining
+	}
+
+	if w.maxArchives == -1 {
+		return nil
+	}
 
 	//if there are more archives than the configured maximum, then purge older files
 	if len(matches) > w.maxArchives {
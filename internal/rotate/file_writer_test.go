@@ -1,11 +1,14 @@
 package rotate
 
 import (
+	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 )
 
@@ -139,3 +142,71 @@ func TestFileWriter_CloseDoesNotRotate(t *testing.T) {
 	require.Equal(t, 1, len(files))
 	require.Regexp(t, "^test.log$", files[0].Name())
 }
+
+func TestFileWriter_CompressArchives(t *testing.T) {
+	for _, algorithm := range []string{"gzip", "zstd"} {
+		t.Run(algorithm, func(t *testing.T) {
+			tempDir := t.TempDir()
+			writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, 5, -1, WithCompression(algorithm))
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, writer.Close()) })
+
+			_, err = writer.Write([]byte("Hello World"))
+			require.NoError(t, err)
+
+			archives, err := filepath.Glob(filepath.Join(tempDir, "test.*-*.log.*"))
+			require.NoError(t, err)
+			require.Len(t, archives, 1)
+
+			f, err := os.Open(archives[0])
+			require.NoError(t, err)
+			defer f.Close()
+
+			var reader io.Reader
+			if algorithm == "gzip" {
+				require.Equal(t, ".gz", filepath.Ext(archives[0]))
+				reader, err = gzip.NewReader(f)
+			} else {
+				require.Equal(t, ".zst", filepath.Ext(archives[0]))
+				reader, err = zstd.NewReader(f)
+			}
+			require.NoError(t, err)
+			content, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, "Hello World", string(content))
+
+			// The uncompressed archive is removed
+			files, _ := os.ReadDir(tempDir)
+			require.Len(t, files, 2)
+		})
+	}
+}
+
+func TestFileWriter_InvalidCompression(t *testing.T) {
+	_, err := NewFileWriter(filepath.Join(t.TempDir(), "test.log"), 0, 5, -1, WithCompression("lz4"))
+	require.ErrorContains(t, err, "unsupported compression algorithm")
+}
+
+func TestFileWriter_MaxAge(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create an old and a recent archive
+	old := filepath.Join(tempDir, "test.2020-01-01-1577836800.log.gz")
+	recent := filepath.Join(tempDir, "test.2020-01-02-1577923200.log")
+	require.NoError(t, os.WriteFile(old, []byte("old"), 0640))
+	require.NoError(t, os.WriteFile(recent, []byte("recent"), 0640))
+	past := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(old, past, past))
+
+	writer, err := NewFileWriter(filepath.Join(tempDir, "test.log"), 0, 5, -1, WithMaxAge(24*time.Hour))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, writer.Close()) })
+
+	_, err = writer.Write([]byte("Hello World"))
+	require.NoError(t, err)
+
+	require.NoFileExists(t, old)
+	require.FileExists(t, recent)
+	files, _ := os.ReadDir(tempDir)
+	require.Len(t, files, 3)
+}
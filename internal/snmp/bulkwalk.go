@@ -0,0 +1,134 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// defaultMaxRepetitions is used by gosnmp if no max-repetitions are configured
+const defaultMaxRepetitions = 50
+
+// bulkRequester is the part of *gosnmp.GoSNMP required for walking a table
+type bulkRequester interface {
+	Get(oids []string) (*gosnmp.SnmpPacket, error)
+	GetBulk(oids []string, nonRepeaters uint8, maxRepetitions uint32) (*gosnmp.SnmpPacket, error)
+}
+
+// repetitionTuner adapts the max-repetitions of GETBULK requests to the
+// agent. The value is halved if the agent cannot send the response because
+// it is too big or if the request times out e.g. because the response was
+// dropped, and is doubled again for complete responses up to the configured
+// maximum while staying below the smallest value known to fail.
+type repetitionTuner struct {
+	max     uint32
+	current uint32
+	failing uint32
+}
+
+func newRepetitionTuner(maxRepetitions uint32) *repetitionTuner {
+	if maxRepetitions == 0 {
+		maxRepetitions = defaultMaxRepetitions
+	}
+	return &repetitionTuner{max: maxRepetitions, current: maxRepetitions}
+}
+
+func (t *repetitionTuner) shrink() bool {
+	if t.current <= 1 {
+		return false
+	}
+	if t.failing == 0 || t.current < t.failing {
+		t.failing = t.current
+	}
+	t.current /= 2
+	return true
+}
+
+func (t *repetitionTuner) grow() {
+	next := t.current * 2
+	if next > t.max {
+		next = t.max
+	}
+	if t.failing > 0 && next >= t.failing {
+		next = t.failing - 1
+	}
+	if next > t.current {
+		t.current = next
+	}
+}
+
+// walk requests the table below the given root OID using GETBULK requests
+// and calls fn for each entry. Contrary to the gosnmp implementation, a walk
+// is not silently truncated if the agent reports the response to be too big
+// but the request is repeated with less repetitions.
+func (t *repetitionTuner) walk(client bulkRequester, root string, fn gosnmp.WalkFunc) error {
+	if root == "" || root == "." {
+		root = ".1.3.6.1.2.1"
+	}
+	if !strings.HasPrefix(root, ".") {
+		root = "." + root
+	}
+
+	oid := root
+	for requests := 1; ; requests++ {
+		response, err := client.GetBulk([]string{oid}, 0, t.current)
+		if err != nil {
+			if strings.Contains(err.Error(), "timeout") && t.shrink() {
+				continue
+			}
+			return err
+		}
+		if response.Error == gosnmp.TooBig {
+			if t.shrink() {
+				continue
+			}
+			return errors.New("response too big even for a single repetition")
+		}
+		if response.Error != gosnmp.NoError || len(response.Variables) == 0 {
+			return nil
+		}
+
+		for i, pdu := range response.Variables {
+			if pdu.Type == gosnmp.EndOfMibView || pdu.Type == gosnmp.NoSuchObject || pdu.Type == gosnmp.NoSuchInstance {
+				return nil
+			}
+			if !strings.HasPrefix(pdu.Name, root+".") {
+				// The root OID might be a leaf, so get it directly if
+				// nothing is below the OID
+				if requests == 1 && i == 0 {
+					return getLeaf(client, root, fn)
+				}
+				return nil
+			}
+			if pdu.Name == oid {
+				return fmt.Errorf("OID not increasing: %s", pdu.Name)
+			}
+			if err := fn(pdu); err != nil {
+				return err
+			}
+		}
+
+		if uint32(len(response.Variables)) >= t.current {
+			t.grow()
+		}
+		oid = response.Variables[len(response.Variables)-1].Name
+	}
+}
+
+func getLeaf(client bulkRequester, oid string, fn gosnmp.WalkFunc) error {
+	response, err := client.Get([]string{oid})
+	if err != nil {
+		return err
+	}
+	for _, pdu := range response.Variables {
+		if pdu.Type == gosnmp.NoSuchObject || pdu.Type == gosnmp.NoSuchInstance {
+			continue
+		}
+		if err := fn(pdu); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAgent serves a table of integers with a limit on the number of entries
+// per response similar to the maximum transfer size of an agent
+type fakeAgent struct {
+	oids        []string
+	limit       int
+	timeout     bool
+	repetitions []uint32
+}
+
+func newFakeAgent(root string, n int) *fakeAgent {
+	a := &fakeAgent{}
+	for i := 1; i <= n; i++ {
+		a.oids = append(a.oids, fmt.Sprintf("%s.%d", root, i))
+	}
+	a.oids = append(a.oids, ".1.3.6.1.2.1.99")
+	sort.Slice(a.oids, func(i, j int) bool {
+		return oidLess(a.oids[i], a.oids[j])
+	})
+	return a
+}
+
+func oidLess(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 1; i < len(pa) && i < len(pb); i++ {
+		var va, vb int
+		fmt.Sscan(pa[i], &va)
+		fmt.Sscan(pb[i], &vb)
+		if va != vb {
+			return va < vb
+		}
+	}
+	return len(pa) < len(pb)
+}
+
+func (a *fakeAgent) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	return &gosnmp.SnmpPacket{
+		Variables: []gosnmp.SnmpPDU{{Name: oids[0], Type: gosnmp.NoSuchObject}},
+	}, nil
+}
+
+func (a *fakeAgent) GetBulk(oids []string, _ uint8, maxRepetitions uint32) (*gosnmp.SnmpPacket, error) {
+	a.repetitions = append(a.repetitions, maxRepetitions)
+	if a.limit > 0 && int(maxRepetitions) > a.limit {
+		if a.timeout {
+			return nil, errors.New("request timeout (after 3 retries)")
+		}
+		return &gosnmp.SnmpPacket{Error: gosnmp.TooBig}, nil
+	}
+
+	response := &gosnmp.SnmpPacket{}
+	for _, oid := range a.oids {
+		if !oidLess(oids[0], oid) {
+			continue
+		}
+		response.Variables = append(response.Variables, gosnmp.SnmpPDU{Name: oid, Type: gosnmp.Integer, Value: 1})
+		if len(response.Variables) == int(maxRepetitions) {
+			break
+		}
+	}
+	if len(response.Variables) == 0 {
+		response.Variables = append(response.Variables, gosnmp.SnmpPDU{Name: oids[0], Type: gosnmp.EndOfMibView})
+	}
+	return response, nil
+}
+
+func TestRepetitionTunerWalk(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		timeout     bool
+		repetitions []uint32
+	}{
+		{
+			name:        "no limit",
+			repetitions: []uint32{10, 10, 10},
+		},
+		{
+			name:        "too big",
+			limit:       4,
+			repetitions: []uint32{10, 5, 2, 4, 4, 4, 4, 4, 4},
+		},
+		{
+			name:        "timeout",
+			limit:       4,
+			timeout:     true,
+			repetitions: []uint32{10, 5, 2, 4, 4, 4, 4, 4, 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := newFakeAgent(".1.3.6.1.2.1.2", 25)
+			agent.limit = tt.limit
+			agent.timeout = tt.timeout
+
+			tuner := newRepetitionTuner(10)
+			var received []string
+			require.NoError(t, tuner.walk(agent, ".1.3.6.1.2.1.2", func(pdu gosnmp.SnmpPDU) error {
+				received = append(received, pdu.Name)
+				return nil
+			}))
+			require.Len(t, received, 25)
+			require.Equal(t, tt.repetitions, agent.repetitions)
+
+			// The learned value is kept for the next walk
+			if tt.limit > 0 {
+				require.Equal(t, uint32(tt.limit), tuner.current)
+			}
+		})
+	}
+}
+
+func TestRepetitionTunerSingleRepetitionTooBig(t *testing.T) {
+	agent := &tooBigAgent{newFakeAgent(".1.3.6.1.2.1.2", 5)}
+
+	tuner := newRepetitionTuner(4)
+	err := tuner.walk(agent, ".1.3.6.1.2.1.2", func(gosnmp.SnmpPDU) error { return nil })
+	require.ErrorContains(t, err, "response too big")
+	require.Equal(t, uint32(1), tuner.current)
+}
+
+// tooBigAgent rejects all bulk requests as too big
+type tooBigAgent struct {
+	*fakeAgent
+}
+
+func (a *tooBigAgent) GetBulk([]string, uint8, uint32) (*gosnmp.SnmpPacket, error) {
+	return &gosnmp.SnmpPacket{Error: gosnmp.TooBig}, nil
+}
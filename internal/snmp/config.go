@@ -21,6 +21,8 @@ type ClientConfig struct {
 
 	// Parameters for Version 2 & 3
 	MaxRepetitions uint32 `toml:"max_repetitions"`
+	// Reduce the max-repetitions for agents not able to handle the value
+	AutoTuneMaxRepetitions bool `toml:"auto_tune_max_repetitions"`
 
 	// Parameters for Version 3
 	ContextName string `toml:"context_name"`
@@ -0,0 +1,80 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// FingerprintMibPaths summarizes the names, sizes and modification times of
+// all files in the given MIB paths. The fingerprint is cheap to compute
+// compared to parsing the MIBs and changes whenever a MIB file is added,
+// removed or modified.
+func FingerprintMibPaths(paths []string) (string, error) {
+	sorted := append([]string(nil), paths...)
+	sort.Strings(sorted)
+
+	h := fnv.New64a()
+	for _, mibPath := range sorted {
+		fp, err := fingerprintPath(mibPath)
+		if err != nil {
+			return "", err
+		}
+		_, _ = h.Write([]byte(mibPath + "\x00" + fp + "\x00"))
+	}
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
+
+// fingerprintPath computes the fingerprint of a single MIB path following
+// symbolic links to files. Non-existing paths result in a constant
+// fingerprint, so creating the path later is detected as a change.
+func fingerprintPath(mibPath string) (string, error) {
+	h := fnv.New64a()
+	err := filepath.WalkDir(mibPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			// Ignore dangling symbolic links as they are skipped on loading
+			return nil //nolint:nilerr // skip the file
+		}
+		rel, err := filepath.Rel(mibPath, path)
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\x00", rel, info.Size(), info.ModTime().UnixNano())
+			return nil
+		}
+
+		// Only the files directly in a linked directory are loaded, so do
+		// not descend any further
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil //nolint:nilerr // unreadable directories are skipped on loading
+		}
+		for _, e := range entries {
+			if info, err := e.Info(); err == nil {
+				_, _ = fmt.Fprintf(h, "%s\x00%d\x00%d\x00", filepath.Join(rel, e.Name()), info.Size(), info.ModTime().UnixNano())
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("fingerprinting path %q failed: %w", mibPath, err)
+	}
+	return strconv.FormatUint(h.Sum64(), 16), nil
+}
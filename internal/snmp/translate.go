@@ -17,7 +17,22 @@ import (
 // or gosmi will fail without saying why
 var m sync.Mutex
 var once sync.Once
-var cache = make(map[string]bool)
+
+// cache holds the fingerprint of the MIB paths at the time of loading
+var cache = make(map[string]string)
+
+// generation is incremented each time the loaded MIBs are discarded
+var generation uint64
+
+// MibGeneration returns a counter changing whenever the loaded MIBs are
+// discarded due to a reload, so nodes and translations resolved before
+// must not be used anymore
+func MibGeneration() uint64 {
+	m.Lock()
+	defer m.Unlock()
+
+	return generation
+}
 
 type MibLoader interface {
 	// appendPath takes the path of a directory
@@ -96,11 +111,36 @@ func walkPaths(paths []string, log telegraf.Logger) ([]string, error) {
 	once.Do(gosmi.Init)
 	folders := []string{}
 
+	fingerprints := make(map[string]string, len(paths))
+	for _, mibPath := range paths {
+		fp, err := fingerprintPath(mibPath)
+		if err != nil {
+			return nil, err
+		}
+		fingerprints[mibPath] = fp
+	}
+
+	// gosmi cannot unload modules, so start from scratch if any of the paths
+	// changed since loading e.g. when reloading the configuration after
+	// updating the MIB files
+	m.Lock()
+	for mibPath, fp := range fingerprints {
+		if previous, found := cache[mibPath]; found && previous != fp {
+			log.Infof("MIB path %q changed, reloading MIBs", mibPath)
+			gosmi.Exit()
+			gosmi.Init()
+			cache = make(map[string]string)
+			generation++
+			break
+		}
+	}
+	m.Unlock()
+
 	for _, mibPath := range paths {
 		// Check if we loaded that path already and skip it if so
 		m.Lock()
-		cached := cache[mibPath]
-		cache[mibPath] = true
+		_, cached := cache[mibPath]
+		cache[mibPath] = fingerprints[mibPath]
 		m.Unlock()
 		if cached {
 			continue
@@ -1,9 +1,11 @@
 package snmp
 
 import (
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -151,3 +153,51 @@ func TestFolderLookup(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadMibsFromPathReload(t *testing.T) {
+	// Copy the MIBs to be able to modify them
+	path := t.TempDir()
+	buf, err := os.ReadFile(filepath.Join("testdata", "mibs", "testmib"))
+	require.NoError(t, err)
+	filename := filepath.Join(path, "testmib")
+	require.NoError(t, os.WriteFile(filename, buf, 0600))
+
+	require.NoError(t, LoadMibsFromPath([]string{path}, testutil.Logger{}, &GosmiMibLoader{}))
+	gen := MibGeneration()
+	_, err = TrapLookup(".1.3.6.1.6.3.1.1.5.1")
+	require.NoError(t, err)
+
+	// Loading unchanged paths again does not reload the MIBs
+	require.NoError(t, LoadMibsFromPath([]string{path}, testutil.Logger{}, &GosmiMibLoader{}))
+	require.Equal(t, gen, MibGeneration())
+
+	// Modifying a file reloads all MIBs
+	mtime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+	require.NoError(t, LoadMibsFromPath([]string{path}, testutil.Logger{}, &GosmiMibLoader{}))
+	require.Equal(t, gen+1, MibGeneration())
+
+	entry, err := TrapLookup(".1.3.6.1.6.3.1.1.5.1")
+	require.NoError(t, err)
+	require.Equal(t, MibEntry{MibName: "TGTEST-MIB", OidText: "coldStart"}, entry)
+}
+
+func TestFingerprintMibPaths(t *testing.T) {
+	path := t.TempDir()
+	filename := filepath.Join(path, "testmib")
+	require.NoError(t, os.WriteFile(filename, []byte("foo"), 0600))
+
+	fp, err := FingerprintMibPaths([]string{path, filepath.Join(path, "missing")})
+	require.NoError(t, err)
+
+	// The order of the paths does not matter
+	actual, err := FingerprintMibPaths([]string{filepath.Join(path, "missing"), path})
+	require.NoError(t, err)
+	require.Equal(t, fp, actual)
+
+	// Adding a file changes the fingerprint
+	require.NoError(t, os.WriteFile(filepath.Join(path, "othermib"), []byte("bar"), 0600))
+	actual, err = FingerprintMibPaths([]string{path, filepath.Join(path, "missing")})
+	require.NoError(t, err)
+	require.NotEqual(t, fp, actual)
+}
@@ -13,6 +13,8 @@ import (
 // GosnmpWrapper wraps a *gosnmp.GoSNMP object so we can use it as a snmpConnection.
 type GosnmpWrapper struct {
 	*gosnmp.GoSNMP
+
+	tuner *repetitionTuner
 }
 
 // Host returns the value of GoSNMP.Target.
@@ -26,11 +28,14 @@ func (gs GosnmpWrapper) Walk(oid string, fn gosnmp.WalkFunc) error {
 	if gs.Version == gosnmp.Version1 {
 		return gs.GoSNMP.Walk(oid, fn)
 	}
+	if gs.tuner != nil {
+		return gs.tuner.walk(gs.GoSNMP, oid, fn)
+	}
 	return gs.GoSNMP.BulkWalk(oid, fn)
 }
 
 func NewWrapper(s ClientConfig) (GosnmpWrapper, error) {
-	gs := GosnmpWrapper{&gosnmp.GoSNMP{}}
+	gs := GosnmpWrapper{GoSNMP: &gosnmp.GoSNMP{}}
 
 	gs.Timeout = time.Duration(s.Timeout)
 
@@ -58,6 +63,9 @@ func NewWrapper(s ClientConfig) (GosnmpWrapper, error) {
 	}
 
 	gs.MaxRepetitions = s.MaxRepetitions
+	if s.AutoTuneMaxRepetitions {
+		gs.tuner = newRepetitionTuner(s.MaxRepetitions)
+	}
 
 	if s.Version == 3 {
 		gs.ContextName = s.ContextName
@@ -0,0 +1,30 @@
+package templating
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// MetricData exposes a metric to text/template templates. The metric name is
+// available as ".Name", tags as ".Tag" and the field key as ".Field" if the
+// template is executed for a single field of the metric.
+type MetricData struct {
+	Metric   telegraf.Metric
+	FieldKey string
+}
+
+// Name returns the name of the metric
+func (d *MetricData) Name() string {
+	return d.Metric.Name()
+}
+
+// Tag returns the value of the given tag or an empty string if the tag does
+// not exist
+func (d *MetricData) Tag(key string) string {
+	v, _ := d.Metric.GetTag(key)
+	return v
+}
+
+// Field returns the key of the field the template is executed for
+func (d *MetricData) Field() string {
+	return d.FieldKey
+}
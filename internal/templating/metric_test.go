@@ -0,0 +1,28 @@
+package templating
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/metric"
+)
+
+func TestMetricData(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{"usage_idle": 42.0},
+		time.Unix(0, 0),
+	)
+
+	tmpl, err := template.New("test").Parse(`{{ .Name }}.{{ .Tag "host" }}.{{ .Tag "missing" }}.{{ .Field }}`)
+	require.NoError(t, err)
+
+	var b strings.Builder
+	require.NoError(t, tmpl.Execute(&b, &MetricData{Metric: m, FieldKey: "usage_idle"}))
+	require.Equal(t, "cpu.server01..usage_idle", b.String())
+}
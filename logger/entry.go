@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/wlog"
+
+	"github.com/influxdata/telegraf/models"
+)
+
+// maxSampledErrors is the number of distinct error messages tracked for
+// suppressing repetitions before expired messages are removed
+const maxSampledErrors = 1000
+
+// entry is a log message of the form "L! [source] message"
+type entry struct {
+	level   byte
+	source  string
+	message []byte
+}
+
+// parseEntry splits the log line into its parts, lines without level prefix
+// are considered to be of level info
+func parseEntry(b []byte) entry {
+	e := entry{level: 'I', message: b}
+	if prefixRegex.Match(b) {
+		e.level = b[0]
+		e.message = bytes.TrimLeft(b[2:], " ")
+	}
+	if len(e.message) > 0 && e.message[0] == '[' {
+		if end := bytes.IndexByte(e.message, ']'); end > 0 {
+			e.source = string(e.message[1:end])
+			e.message = bytes.TrimLeft(e.message[end+1:], " ")
+		}
+	}
+	e.message = bytes.TrimRight(e.message, "\r\n")
+
+	return e
+}
+
+// enabled checks the level of the entry against the log-level override of
+// the source or the global log-level
+func (e *entry) enabled() bool {
+	threshold := wlog.LogLevel()
+	if e.source != "" {
+		if level, found := models.LogLevel(e.source); found {
+			threshold = level
+		}
+	}
+	return wlog.Levels[e.level] >= threshold
+}
+
+// jsonEntry is the structured representation of a log entry
+type jsonEntry struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	Plugin     string `json:"plugin,omitempty"`
+	Alias      string `json:"alias,omitempty"`
+	Source     string `json:"source,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+	Message    string `json:"msg"`
+	Suppressed int    `json:"suppressed,omitempty"`
+}
+
+// json returns the entry as JSON line including the given number of
+// suppressed identical messages
+func (e *entry) json(t time.Time, suppressed int) ([]byte, error) {
+	je := jsonEntry{
+		Time:       t.Format(time.RFC3339),
+		Level:      models.LogLevelName(wlog.Levels[e.level]),
+		Message:    string(e.message),
+		Suppressed: suppressed,
+	}
+
+	// Plugins are logged with a source of the form "category.name::alias"
+	// while other parts of Telegraf use sources like "agent"
+	if strings.Contains(e.source, ".") {
+		je.Plugin, je.Alias, _ = strings.Cut(e.source, "::")
+	} else {
+		je.Source = e.source
+	}
+	if e.level == 'E' {
+		je.ErrorClass = models.ErrorClass(je.Message)
+	}
+
+	buf, err := json.Marshal(je)
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, '\n'), nil
+}
+
+// errorSampler suppresses identical error messages of the same source
+// repeated within the interval
+type errorSampler struct {
+	interval time.Duration
+	seen     map[string]*sample
+	sync.Mutex
+}
+
+type sample struct {
+	last       time.Time
+	suppressed int
+}
+
+func newErrorSampler(interval time.Duration) *errorSampler {
+	return &errorSampler{
+		interval: interval,
+		seen:     make(map[string]*sample),
+	}
+}
+
+// check returns true if the entry should be logged together with the number
+// of identical messages suppressed since the entry was logged last
+func (s *errorSampler) check(e entry, now time.Time) (bool, int) {
+	key := e.source + "\x00" + string(e.message)
+
+	s.Lock()
+	defer s.Unlock()
+
+	if last, found := s.seen[key]; found {
+		if now.Sub(last.last) < s.interval {
+			last.suppressed++
+			return false, 0
+		}
+		suppressed := last.suppressed
+		last.last = now
+		last.suppressed = 0
+		return true, suppressed
+	}
+
+	// Remove expired messages to limit the memory consumption
+	if len(s.seen) >= maxSampledErrors {
+		for k, v := range s.seen {
+			if now.Sub(v.last) >= s.interval {
+				delete(s.seen, k)
+			}
+		}
+	}
+	s.seen[key] = &sample{last: now}
+
+	return true, 0
+}
+
+// levelFilter drops log lines below the log-level of their source
+type levelFilter struct {
+	writer io.Writer
+}
+
+func (f *levelFilter) Write(b []byte) (int, error) {
+	if e := parseEntry(b); !e.enabled() {
+		return len(b), nil
+	}
+	return f.writer.Write(b)
+}
@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/wlog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/models"
+)
+
+func TestParseEntry(t *testing.T) {
+	tests := []struct {
+		line   string
+		level  byte
+		source string
+		msg    string
+	}{
+		{"E! [inputs.cpu] failed\n", 'E', "inputs.cpu", "failed"},
+		{"D! [inputs.cpu::foo]  bar\n", 'D', "inputs.cpu::foo", "bar"},
+		{"W! DeprecationWarning: [x]\n", 'W', "", "DeprecationWarning: [x]"},
+		{"no level\n", 'I', "", "no level"},
+		{"I! [agent\n", 'I', "", "[agent"},
+	}
+	for _, tt := range tests {
+		e := parseEntry([]byte(tt.line))
+		require.Equal(t, tt.level, e.level, tt.line)
+		require.Equal(t, tt.source, e.source, tt.line)
+		require.Equal(t, tt.msg, string(e.message), tt.line)
+	}
+}
+
+func TestLogLevelOverride(t *testing.T) {
+	defer models.ResetLogLevels()
+	defer wlog.SetLevel(wlog.INFO)
+
+	var buf bytes.Buffer
+	w, err := newTelegrafWriter(&buf, LogConfig{})
+	require.NoError(t, err)
+
+	wlog.SetLevel(wlog.INFO)
+	require.NoError(t, models.SetLogLevel("inputs.cpu", "debug"))
+	require.NoError(t, models.SetLogLevel("inputs.mem", "error"))
+
+	for _, line := range []string{
+		"D! [inputs.cpu] shown",
+		"D! [inputs.disk] hidden",
+		"W! [inputs.mem] hidden",
+		"E! [inputs.mem] shown",
+		"I! [agent] shown",
+	} {
+		_, err := w.Write([]byte(line + "\n"))
+		require.NoError(t, err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		require.True(t, strings.HasSuffix(line, "shown"), line)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newTelegrafWriter(&buf, LogConfig{LogFormat: LogFormatJSON})
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("E! [outputs.amqp::primary] Error writing: dial tcp: connection refused\n"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("I! [agent] Starting\n"))
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var actual jsonEntry
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &actual))
+	_, err = time.Parse(time.RFC3339, actual.Time)
+	require.NoError(t, err)
+	actual.Time = ""
+	require.Equal(t, jsonEntry{
+		Level:      "error",
+		Plugin:     "outputs.amqp",
+		Alias:      "primary",
+		ErrorClass: "connection",
+		Message:    "Error writing: dial tcp: connection refused",
+	}, actual)
+
+	actual = jsonEntry{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &actual))
+	actual.Time = ""
+	require.Equal(t, jsonEntry{Level: "info", Source: "agent", Message: "Starting"}, actual)
+}
+
+func TestInvalidFormat(t *testing.T) {
+	_, err := newTelegrafWriter(&bytes.Buffer{}, LogConfig{LogFormat: "xml"})
+	require.ErrorContains(t, err, `invalid log format "xml"`)
+}
+
+func TestErrorSampling(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newTelegrafWriter(&buf, LogConfig{SamplingInterval: config.Duration(time.Hour)})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := w.Write([]byte("E! [inputs.cpu] failed\n"))
+		require.NoError(t, err)
+		_, err = w.Write([]byte("W! [inputs.cpu] warning\n"))
+		require.NoError(t, err)
+	}
+	_, err = w.Write([]byte("E! [inputs.mem] failed\n"))
+	require.NoError(t, err)
+
+	// Only errors are sampled
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+	require.True(t, strings.HasSuffix(lines[0], "E! [inputs.cpu] failed"))
+	require.True(t, strings.HasSuffix(lines[4], "E! [inputs.mem] failed"))
+
+	// Report the suppressed messages once the interval elapsed
+	s := newErrorSampler(time.Second)
+	now := time.Now()
+	e := parseEntry([]byte("E! [inputs.cpu] failed\n"))
+	ok, suppressed := s.check(e, now)
+	require.True(t, ok)
+	require.Zero(t, suppressed)
+	for i := 0; i < 3; i++ {
+		ok, _ = s.check(e, now.Add(time.Duration(i)*100*time.Millisecond))
+		require.False(t, ok)
+	}
+	ok, suppressed = s.check(e, now.Add(time.Second))
+	require.True(t, ok)
+	require.Equal(t, 3, suppressed)
+}
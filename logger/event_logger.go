@@ -7,7 +7,6 @@ import (
 	"log"
 	"strings"
 
-	"github.com/influxdata/wlog"
 	"golang.org/x/sys/windows/svc/eventlog"
 )
 
@@ -49,7 +48,7 @@ type eventLoggerCreator struct {
 }
 
 func (e *eventLoggerCreator) CreateLogger(_ LogConfig) (io.Writer, error) {
-	return wlog.NewWriter(&eventLogger{logger: e.logger}), nil
+	return &levelFilter{writer: &eventLogger{logger: e.logger}}, nil
 }
 
 func RegisterEventLogger(name string) error {
@@ -1,7 +1,9 @@
 package logger
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -20,6 +22,9 @@ var prefixRegex = regexp.MustCompile("^[DIWE]!")
 const (
 	LogTargetFile   = "file"
 	LogTargetStderr = "stderr"
+
+	LogFormatText = "text"
+	LogFormatJSON = "json"
 )
 
 // LogConfig contains the log configuration settings
@@ -42,6 +47,10 @@ type LogConfig struct {
 	RotationMaxArchives int
 	// pick a timezone to use when logging. or type 'local' for local time.
 	LogWithTimezone string
+	// format of the messages, either "text" (default) or "json"
+	LogFormat string
+	// suppress identical error messages repeated within this interval
+	SamplingInterval config.Duration
 }
 
 type creator interface {
@@ -61,17 +70,44 @@ type telegrafLog struct {
 	writer         io.Writer
 	internalWriter io.Writer
 	timezone       *time.Location
+	format         string
+	sampler        *errorSampler
 }
 
 func (t *telegrafLog) Write(b []byte) (n int, err error) {
-	var line []byte
-	timeToPrint := time.Now().In(t.timezone)
+	e := parseEntry(b)
+	if !e.enabled() {
+		return len(b), nil
+	}
+
+	now := time.Now()
+	var suppressed int
+	if t.sampler != nil && e.level == 'E' {
+		var ok bool
+		if ok, suppressed = t.sampler.check(e, now); !ok {
+			return len(b), nil
+		}
+	}
+	timeToPrint := now.In(t.timezone)
+
+	if t.format == LogFormatJSON {
+		line, err := e.json(timeToPrint, suppressed)
+		if err != nil {
+			return 0, err
+		}
+		return t.writer.Write(line)
+	}
 
+	var line []byte
 	if !prefixRegex.Match(b) {
 		line = append([]byte(timeToPrint.Format(time.RFC3339)+" I! "), b...)
 	} else {
 		line = append([]byte(timeToPrint.Format(time.RFC3339)+" "), b...)
 	}
+	if suppressed > 0 {
+		line = bytes.TrimRight(line, "\r\n")
+		line = append(line, fmt.Sprintf(" (%d identical messages suppressed)\n", suppressed)...)
+	}
 
 	return t.writer.Write(line)
 }
@@ -103,10 +139,23 @@ func newTelegrafWriter(w io.Writer, c LogConfig) (io.Writer, error) {
 		return nil, errors.New("error while setting logging timezone: " + err.Error())
 	}
 
+	switch c.LogFormat {
+	case "", LogFormatText, LogFormatJSON:
+	default:
+		return nil, fmt.Errorf("invalid log format %q", c.LogFormat)
+	}
+
+	var sampler *errorSampler
+	if c.SamplingInterval > 0 {
+		sampler = newErrorSampler(time.Duration(c.SamplingInterval))
+	}
+
 	return &telegrafLog{
-		writer:         wlog.NewWriter(w),
+		writer:         w,
 		internalWriter: w,
 		timezone:       tz,
+		format:         c.LogFormat,
+		sampler:        sampler,
 	}, nil
 }
 
@@ -161,11 +210,15 @@ func newLogWriter(cfg LogConfig) (io.Writer, error) {
 		wlog.SetLevel(wlog.INFO)
 	}
 	var logWriter io.Writer
+	var err error
 	if logCreator, ok := loggerRegistry[cfg.LogTarget]; ok {
-		logWriter, _ = logCreator.CreateLogger(cfg)
+		logWriter, err = logCreator.CreateLogger(cfg)
 	}
 	if logWriter == nil {
-		logWriter, _ = (&telegrafLogCreator{}).CreateLogger(cfg)
+		logWriter, err = (&telegrafLogCreator{}).CreateLogger(cfg)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	if closer, isCloser := actualLogger.(io.Closer); isCloser {
@@ -15,8 +15,62 @@ const (
 	Untyped
 	Summary
 	Histogram
+	Event
 )
 
+// Field keys holding the severity and the message body of event metrics.
+const (
+	EventSeverityField = "severity"
+	EventMessageField  = "message"
+)
+
+// Severity is the level of an event metric.
+type Severity int
+
+// Possible values for the Severity enum, ordered by increasing importance.
+const (
+	SeverityUnknown Severity = iota
+	SeverityDebug
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityDebug:
+		return "debug"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	case SeverityCritical:
+		return "critical"
+	}
+	return "unknown"
+}
+
+// ParseSeverity returns the severity for the given name. The syslog severity
+// keywords are accepted as well, unknown names result in SeverityUnknown.
+func ParseSeverity(name string) Severity {
+	switch name {
+	case "debug", "trace":
+		return SeverityDebug
+	case "info", "informational", "notice":
+		return SeverityInfo
+	case "warning", "warn":
+		return SeverityWarning
+	case "error", "err":
+		return SeverityError
+	case "critical", "crit", "fatal", "alert", "emerg", "emergency", "panic":
+		return SeverityCritical
+	}
+	return SeverityUnknown
+}
+
 // Tag represents a single tag key and value.
 type Tag struct {
 	Key   string
@@ -29,6 +83,50 @@ type Field struct {
 	Value interface{}
 }
 
+// HistogramValue is a field value containing a complete histogram. It allows
+// to pass a histogram through Telegraf without flattening it into separate
+// fields or metrics per bucket.
+type HistogramValue struct {
+	Count   uint64
+	Sum     float64
+	Buckets []Bucket
+}
+
+// Bucket of a histogram. The count is cumulative, i.e. it contains the number
+// of observations less than or equal to the upper bound.
+type Bucket struct {
+	UpperBound float64
+	Count      uint64
+}
+
+// Copy returns a deep copy of the histogram.
+func (h *HistogramValue) Copy() *HistogramValue {
+	c := *h
+	c.Buckets = append([]Bucket(nil), h.Buckets...)
+	return &c
+}
+
+// SummaryValue is a field value containing a complete summary with its
+// precomputed quantiles.
+type SummaryValue struct {
+	Count     uint64
+	Sum       float64
+	Quantiles []Quantile
+}
+
+// Quantile of a summary.
+type Quantile struct {
+	Quantile float64
+	Value    float64
+}
+
+// Copy returns a deep copy of the summary.
+func (s *SummaryValue) Copy() *SummaryValue {
+	c := *s
+	c.Quantiles = append([]Quantile(nil), s.Quantiles...)
+	return &c
+}
+
 // Metric is the type of data that is processed by Telegraf.  Input plugins,
 // and to a lesser degree, Processor and Aggregator plugins create new Metrics
 // and Output plugins write them.
@@ -0,0 +1,55 @@
+package metric
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// NewEvent creates an event metric with the given severity and message body.
+// Additional information can be added as tags or fields to the returned
+// metric.
+func NewEvent(
+	name string,
+	tags map[string]string,
+	severity telegraf.Severity,
+	message string,
+	tm time.Time,
+) telegraf.Metric {
+	fields := map[string]interface{}{
+		telegraf.EventSeverityField: severity.String(),
+		telegraf.EventMessageField:  message,
+	}
+	return New(name, tags, fields, tm, telegraf.Event)
+}
+
+// EventSeverity returns the severity of an event metric. SeverityUnknown is
+// returned for other metrics or events without a severity.
+func EventSeverity(m telegraf.Metric) telegraf.Severity {
+	if m.Type() != telegraf.Event {
+		return telegraf.SeverityUnknown
+	}
+	v, ok := m.GetField(telegraf.EventSeverityField)
+	if !ok {
+		return telegraf.SeverityUnknown
+	}
+	s, ok := v.(string)
+	if !ok {
+		return telegraf.SeverityUnknown
+	}
+	return telegraf.ParseSeverity(s)
+}
+
+// EventMessage returns the message body of an event metric and a boolean to
+// indicate if the metric is an event containing a message.
+func EventMessage(m telegraf.Metric) (string, bool) {
+	if m.Type() != telegraf.Event {
+		return "", false
+	}
+	v, ok := m.GetField(telegraf.EventMessageField)
+	if !ok {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
@@ -0,0 +1,48 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+)
+
+func TestNewEvent(t *testing.T) {
+	now := time.Now()
+
+	m := NewEvent("syslog", map[string]string{"host": "localhost"}, telegraf.SeverityWarning, "disk almost full", now)
+	require.Equal(t, telegraf.Event, m.Type())
+	require.Equal(t, "syslog", m.Name())
+	require.Equal(t, now, m.Time())
+	require.Equal(t, telegraf.SeverityWarning, EventSeverity(m))
+
+	msg, ok := EventMessage(m)
+	require.True(t, ok)
+	require.Equal(t, "disk almost full", msg)
+
+	// Copies keep the event type
+	require.Equal(t, telegraf.Event, m.Copy().Type())
+}
+
+func TestEventOfOtherMetrics(t *testing.T) {
+	fields := map[string]interface{}{
+		"message":  "hello",
+		"severity": "error",
+	}
+	m := New("log", map[string]string{}, fields, time.Now())
+	require.Equal(t, telegraf.SeverityUnknown, EventSeverity(m))
+	_, ok := EventMessage(m)
+	require.False(t, ok)
+}
+
+func TestParseSeverity(t *testing.T) {
+	require.Equal(t, telegraf.SeverityInfo, telegraf.ParseSeverity("notice"))
+	require.Equal(t, telegraf.SeverityError, telegraf.ParseSeverity("err"))
+	require.Equal(t, telegraf.SeverityCritical, telegraf.ParseSeverity("emerg"))
+	require.Equal(t, telegraf.SeverityUnknown, telegraf.ParseSeverity("whatever"))
+	for s := telegraf.SeverityUnknown; s <= telegraf.SeverityCritical; s++ {
+		require.Equal(t, s, telegraf.ParseSeverity(s.String()))
+	}
+}
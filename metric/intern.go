@@ -0,0 +1,92 @@
+package metric
+
+import (
+	"hash/maphash"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultInternLimit is the default number of distinct strings kept for
+// interning tag keys and values
+const DefaultInternLimit = 100000
+
+// internMaxLength is the maximum length of interned strings, longer tag
+// values are usually unique, like IDs or paths, and not worth deduplicating
+const internMaxLength = 128
+
+const internShards = 32
+
+type internShard struct {
+	strings map[string]string
+	sync.RWMutex
+}
+
+// internTable deduplicates strings to share the memory of equal tag keys and
+// values between metrics. The table is sharded to reduce lock contention as
+// metrics are created concurrently by all plugins.
+type internTable struct {
+	shards [internShards]internShard
+	limit  int
+	seed   maphash.Seed
+}
+
+var interned atomic.Pointer[internTable]
+
+func init() {
+	SetInternLimit(DefaultInternLimit)
+}
+
+// SetInternLimit sets the maximum number of distinct strings kept for
+// interning tag keys and values of new metrics. A limit of zero disables
+// interning. Previously interned strings are dropped.
+func SetInternLimit(limit int) {
+	if limit <= 0 {
+		interned.Store(nil)
+		return
+	}
+
+	t := &internTable{
+		limit: (limit + internShards - 1) / internShards,
+		seed:  maphash.MakeSeed(),
+	}
+	for i := range t.shards {
+		t.shards[i].strings = make(map[string]string)
+	}
+	interned.Store(t)
+}
+
+// intern returns a shared copy of the given string
+func intern(s string) string {
+	t := interned.Load()
+	if t == nil || s == "" || len(s) > internMaxLength {
+		return s
+	}
+
+	shard := &t.shards[maphash.String(t.seed, s)%internShards]
+	shard.RLock()
+	v, found := shard.strings[s]
+	shard.RUnlock()
+	if found {
+		return v
+	}
+
+	shard.Lock()
+	defer shard.Unlock()
+	if v, found := shard.strings[s]; found {
+		return v
+	}
+
+	// Start over if the shard is full to bound the memory used in case of
+	// high-cardinality values; common strings will be added again quickly.
+	if len(shard.strings) >= t.limit {
+		shard.strings = make(map[string]string, len(shard.strings))
+	}
+
+	// Keep a copy to not reference the possibly large buffer the string was
+	// sliced from, e.g. by a parser
+	v = strings.Clone(s)
+	shard.strings[v] = v
+
+	return v
+}
@@ -0,0 +1,80 @@
+package metric
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInternTags(t *testing.T) {
+	defer SetInternLimit(DefaultInternLimit)
+	SetInternLimit(DefaultInternLimit)
+
+	// Create the strings dynamically to get distinct memory locations
+	value := strings.Repeat("us-east-", 2)
+	m1 := New("cpu", map[string]string{"datacenter": value + "1"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	m2 := New("cpu", map[string]string{"datacenter": value + "1"}, map[string]interface{}{"value": 42}, time.Unix(0, 0))
+	m2.AddTag("host", "localhost")
+	m1.AddTag("host", strings.Clone("localhost"))
+
+	for i := range m1.TagList() {
+		require.Equal(t, m1.TagList()[i], m2.TagList()[i])
+		require.Equal(t, unsafe.StringData(m1.TagList()[i].Key), unsafe.StringData(m2.TagList()[i].Key))
+		require.Equal(t, unsafe.StringData(m1.TagList()[i].Value), unsafe.StringData(m2.TagList()[i].Value))
+	}
+}
+
+func TestInternLongValues(t *testing.T) {
+	defer SetInternLimit(DefaultInternLimit)
+	SetInternLimit(DefaultInternLimit)
+
+	value := strings.Repeat("x", internMaxLength+1)
+	require.Equal(t, unsafe.StringData(value), unsafe.StringData(intern(value)))
+}
+
+func TestInternDisabled(t *testing.T) {
+	defer SetInternLimit(DefaultInternLimit)
+	SetInternLimit(0)
+
+	value := strings.Clone("localhost")
+	require.Equal(t, unsafe.StringData(value), unsafe.StringData(intern(value)))
+}
+
+func TestInternLimit(t *testing.T) {
+	defer SetInternLimit(DefaultInternLimit)
+	SetInternLimit(internShards)
+
+	for i := 0; i < 100*internShards; i++ {
+		intern(strconv.Itoa(i))
+	}
+
+	table := interned.Load()
+	for i := range table.shards {
+		require.LessOrEqual(t, len(table.shards[i].strings), table.limit)
+	}
+}
+
+func BenchmarkNewWithTags(b *testing.B) {
+	defer SetInternLimit(DefaultInternLimit)
+
+	tags := map[string]string{
+		"datacenter": "us-east-1",
+		"host":       "server01.example.com",
+		"cpu":        "cpu-total",
+	}
+	fields := map[string]interface{}{"usage_idle": 98.5}
+
+	for _, limit := range []int{0, DefaultInternLimit} {
+		b.Run("limit="+strconv.Itoa(limit), func(b *testing.B) {
+			SetInternLimit(limit)
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				New("cpu", tags, fields, time.Unix(0, 0))
+			}
+		})
+	}
+}
@@ -16,6 +16,10 @@ type metric struct {
 	tm     time.Time
 
 	tp telegraf.ValueType
+
+	// shared is set if the tags and fields might be shared with other copies
+	// of the metric and have to be copied before any modification
+	shared bool
 }
 
 func New(
@@ -44,7 +48,7 @@ func New(
 		m.tags = make([]*telegraf.Tag, 0, len(tags))
 		for k, v := range tags {
 			m.tags = append(m.tags,
-				&telegraf.Tag{Key: k, Value: v})
+				&telegraf.Tag{Key: intern(k), Value: intern(v)})
 		}
 		sort.Slice(m.tags, func(i, j int) bool { return m.tags[i].Key < m.tags[j].Key })
 	}
@@ -138,23 +142,24 @@ func (m *metric) AddSuffix(suffix string) {
 }
 
 func (m *metric) AddTag(key, value string) {
+	m.own()
 	for i, tag := range m.tags {
 		if key > tag.Key {
 			continue
 		}
 
 		if key == tag.Key {
-			tag.Value = value
+			tag.Value = intern(value)
 			return
 		}
 
 		m.tags = append(m.tags, nil)
 		copy(m.tags[i+1:], m.tags[i:])
-		m.tags[i] = &telegraf.Tag{Key: key, Value: value}
+		m.tags[i] = &telegraf.Tag{Key: intern(key), Value: intern(value)}
 		return
 	}
 
-	m.tags = append(m.tags, &telegraf.Tag{Key: key, Value: value})
+	m.tags = append(m.tags, &telegraf.Tag{Key: intern(key), Value: intern(value)})
 }
 
 func (m *metric) HasTag(key string) bool {
@@ -181,6 +186,7 @@ func (m *metric) Tag(key string) string {
 }
 
 func (m *metric) RemoveTag(key string) {
+	m.own()
 	for i, tag := range m.tags {
 		if tag.Key == key {
 			copy(m.tags[i:], m.tags[i+1:])
@@ -192,6 +198,7 @@ func (m *metric) RemoveTag(key string) {
 }
 
 func (m *metric) AddField(key string, value interface{}) {
+	m.own()
 	for i, field := range m.fields {
 		if key == field.Key {
 			m.fields[i] = &telegraf.Field{Key: key, Value: convertField(value)}
@@ -227,6 +234,7 @@ func (m *metric) Field(key string) interface{} {
 }
 
 func (m *metric) RemoveField(key string) {
+	m.own()
 	for i, field := range m.fields {
 		if field.Key == key {
 			copy(m.fields[i:], m.fields[i+1:])
@@ -242,22 +250,70 @@ func (m *metric) SetTime(t time.Time) {
 }
 
 func (m *metric) Copy() telegraf.Metric {
-	m2 := &metric{
+	return &metric{
 		name:   m.name,
-		tags:   make([]*telegraf.Tag, len(m.tags)),
-		fields: make([]*telegraf.Field, len(m.fields)),
+		tags:   copyTags(m.tags),
+		fields: copyFields(m.fields),
 		tm:     m.tm,
 		tp:     m.tp,
 	}
+}
 
-	for i, tag := range m.tags {
-		m2.tags[i] = &telegraf.Tag{Key: tag.Key, Value: tag.Value}
+// own detaches the tags and fields from other copies of the metric before
+// modifying them
+func (m *metric) own() {
+	if !m.shared {
+		return
 	}
+	m.tags = copyTags(m.tags)
+	m.fields = copyFields(m.fields)
+	m.shared = false
+}
 
-	for i, field := range m.fields {
-		m2.fields[i] = &telegraf.Field{Key: field.Key, Value: field.Value}
+func copyTags(tags []*telegraf.Tag) []*telegraf.Tag {
+	c := make([]*telegraf.Tag, len(tags))
+	for i, tag := range tags {
+		c[i] = &telegraf.Tag{Key: tag.Key, Value: tag.Value}
+	}
+	return c
+}
+
+func copyFields(fields []*telegraf.Field) []*telegraf.Field {
+	c := make([]*telegraf.Field, len(fields))
+	for i, field := range fields {
+		value := field.Value
+		switch v := value.(type) {
+		case *telegraf.HistogramValue:
+			value = v.Copy()
+		case *telegraf.SummaryValue:
+			value = v.Copy()
+		}
+		c[i] = &telegraf.Field{Key: field.Key, Value: value}
+	}
+	return c
+}
+
+// CopyOnWrite returns a copy of the metric sharing the tags and fields with
+// the original until either of the two is modified.  This avoids copying
+// metrics that are passed to multiple consumers but most often only read.
+// TagList and FieldList of both metrics must not be modified directly, and
+// the function must not be called concurrently with other accesses to the
+// given metric.  Metrics not created by this package are deep-copied.
+func CopyOnWrite(m telegraf.Metric) telegraf.Metric {
+	switch v := m.(type) {
+	case *metric:
+		v.shared = true
+		m2 := *v
+		return &m2
+	case *trackingMetric:
+		v.d.incr()
+		return &trackingMetric{
+			Metric:   CopyOnWrite(v.Metric),
+			d:        v.d,
+			accepted: v.accepted,
+		}
 	}
-	return m2
+	return m.Copy()
 }
 
 func (m *metric) HashID() uint64 {
@@ -375,6 +431,18 @@ func convertField(v interface{}) interface{} {
 		if v != nil {
 			return float64(*v)
 		}
+	case *telegraf.HistogramValue:
+		if v != nil {
+			return v
+		}
+	case telegraf.HistogramValue:
+		return v.Copy()
+	case *telegraf.SummaryValue:
+		if v != nil {
+			return v
+		}
+	case telegraf.SummaryValue:
+		return v.Copy()
 	default:
 		return nil
 	}
@@ -326,3 +326,66 @@ func TestValueType(t *testing.T) {
 
 	require.Equal(t, telegraf.Gauge, m.Type())
 }
+
+func TestHistogramValueCopy(t *testing.T) {
+	now := time.Now()
+
+	fields := map[string]interface{}{
+		"latency": telegraf.HistogramValue{
+			Count:   3,
+			Sum:     1.5,
+			Buckets: []telegraf.Bucket{{UpperBound: 0.5, Count: 2}, {UpperBound: 1, Count: 3}},
+		},
+	}
+	m := New("http", map[string]string{}, fields, now, telegraf.Histogram)
+
+	v, ok := m.GetField("latency")
+	require.True(t, ok)
+	h, ok := v.(*telegraf.HistogramValue)
+	require.True(t, ok)
+	require.Equal(t, uint64(3), h.Count)
+
+	m2 := m.Copy()
+	h.Buckets[0].Count = 1
+	v, _ = m2.GetField("latency")
+	require.Equal(t, uint64(2), v.(*telegraf.HistogramValue).Buckets[0].Count)
+}
+
+func TestCopyOnWrite(t *testing.T) {
+	now := time.Now()
+
+	m := New("cpu",
+		map[string]string{"host": "localhost", "cpu": "cpu0"},
+		map[string]interface{}{"usage": float64(42), "idle": float64(58)},
+		now,
+	)
+	original := m.Copy()
+
+	m2 := CopyOnWrite(m)
+	m3 := CopyOnWrite(m)
+	require.Equal(t, original.TagList(), m2.TagList())
+	require.Equal(t, original.FieldList(), m2.FieldList())
+	require.Same(t, m.TagList()[0], m2.TagList()[0])
+
+	m2.SetName("mem")
+	m2.AddTag("host", "remote")
+	m2.RemoveField("idle")
+	m3.RemoveTag("cpu")
+	m3.AddField("usage", float64(23))
+
+	require.Equal(t, "cpu", m.Name())
+	require.Equal(t, original.TagList(), m.TagList())
+	require.Equal(t, original.FieldList(), m.FieldList())
+
+	require.Equal(t, "mem", m2.Name())
+	require.Equal(t, map[string]string{"host": "remote", "cpu": "cpu0"}, m2.Tags())
+	require.Equal(t, map[string]interface{}{"usage": float64(42)}, m2.Fields())
+
+	require.Equal(t, map[string]string{"host": "localhost"}, m3.Tags())
+	require.Equal(t, map[string]interface{}{"usage": float64(23), "idle": float64(58)}, m3.Fields())
+
+	// Modifying the original must not affect the copies either
+	m.AddTag("region", "eu")
+	require.False(t, m2.HasTag("region"))
+	require.False(t, m3.HasTag("region"))
+}
@@ -23,6 +23,27 @@ func WithGroupTracking(metric []telegraf.Metric, fn NotifyFunc) ([]telegraf.Metr
 	return newTrackingMetricGroup(metric, fn)
 }
 
+// WithTrackingOnAccept adds tracking to the metric like WithTracking but calls
+// the notify function as soon as the metric is accepted by the first output
+// instead of waiting for all outputs.
+func WithTrackingOnAccept(metric telegraf.Metric, fn NotifyFunc) (telegraf.Metric, telegraf.TrackingID) {
+	m, id := newTrackingMetric(metric, fn)
+	m.(*trackingMetric).d.acceptLimit = 1
+	return m, id
+}
+
+// WithGroupTrackingOnAccept adds tracking to the metrics like
+// WithGroupTracking but calls the notify function as soon as every metric of
+// the group was accepted by at least one output instead of waiting for all
+// outputs.
+func WithGroupTrackingOnAccept(metric []telegraf.Metric, fn NotifyFunc) ([]telegraf.Metric, telegraf.TrackingID) {
+	group, id := newTrackingMetricGroup(metric, fn)
+	if len(group) > 0 {
+		group[0].(*trackingMetric).d.acceptLimit = int32(len(group))
+	}
+	return group, id
+}
+
 var (
 	lastID    uint64
 	finalizer func(*trackingData)
@@ -38,6 +59,13 @@ type trackingData struct {
 	acceptCount int32
 	rejectCount int32
 	notifyFunc  NotifyFunc
+
+	// acceptLimit is the number of distinct metrics accepted by at least one
+	// output triggering the notification before all metrics are done, zero
+	// disables the limit
+	acceptLimit   int32
+	acceptedCount int32
+	notified      int32
 }
 
 func (d *trackingData) incr() {
@@ -48,8 +76,16 @@ func (d *trackingData) decr() int32 {
 	return atomic.AddInt32(&d.rc, -1)
 }
 
-func (d *trackingData) accept() {
+func (d *trackingData) accept(accepted *int32) {
 	atomic.AddInt32(&d.acceptCount, 1)
+
+	// Count each metric only once, no matter how many outputs accepted a copy
+	if d.acceptLimit == 0 || !atomic.CompareAndSwapInt32(accepted, 0, 1) {
+		return
+	}
+	if atomic.AddInt32(&d.acceptedCount, 1) == d.acceptLimit {
+		d.notify()
+	}
 }
 
 func (d *trackingData) reject() {
@@ -57,11 +93,16 @@ func (d *trackingData) reject() {
 }
 
 func (d *trackingData) notify() {
+	// Notify only once if the accept limit was reached before all metrics
+	// are done
+	if !atomic.CompareAndSwapInt32(&d.notified, 0, 1) {
+		return
+	}
 	d.notifyFunc(
 		&deliveryInfo{
 			id:       d.id,
-			accepted: int(d.acceptCount),
-			rejected: int(d.rejectCount),
+			accepted: int(atomic.LoadInt32(&d.acceptCount)),
+			rejected: int(atomic.LoadInt32(&d.rejectCount)),
 		},
 	)
 }
@@ -69,11 +110,16 @@ func (d *trackingData) notify() {
 type trackingMetric struct {
 	telegraf.Metric
 	d *trackingData
+
+	// accepted is shared by all copies of the metric and set once the first
+	// copy is accepted
+	accepted *int32
 }
 
 func newTrackingMetric(metric telegraf.Metric, fn NotifyFunc) (telegraf.Metric, telegraf.TrackingID) {
 	m := &trackingMetric{
-		Metric: metric,
+		Metric:   metric,
+		accepted: new(int32),
 		d: &trackingData{
 			id:          newTrackingID(),
 			rc:          1,
@@ -101,8 +147,9 @@ func newTrackingMetricGroup(group []telegraf.Metric, fn NotifyFunc) ([]telegraf.
 	for i, m := range group {
 		d.incr()
 		dm := &trackingMetric{
-			Metric: m,
-			d:      d,
+			Metric:   m,
+			d:        d,
+			accepted: new(int32),
 		}
 		group[i] = dm
 	}
@@ -120,13 +167,14 @@ func newTrackingMetricGroup(group []telegraf.Metric, fn NotifyFunc) ([]telegraf.
 func (m *trackingMetric) Copy() telegraf.Metric {
 	m.d.incr()
 	return &trackingMetric{
-		Metric: m.Metric.Copy(),
-		d:      m.d,
+		Metric:   m.Metric.Copy(),
+		d:        m.d,
+		accepted: m.accepted,
 	}
 }
 
 func (m *trackingMetric) Accept() {
-	m.d.accept()
+	m.d.accept(m.accepted)
 	m.decr()
 }
 
@@ -168,3 +216,11 @@ func (r *deliveryInfo) ID() telegraf.TrackingID {
 func (r *deliveryInfo) Delivered() bool {
 	return r.rejected == 0
 }
+
+func (r *deliveryInfo) Accepted() int {
+	return r.accepted
+}
+
+func (r *deliveryInfo) Rejected() int {
+	return r.rejected
+}
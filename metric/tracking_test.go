@@ -158,6 +158,25 @@ func TestTracking(t *testing.T) {
 			},
 			delivered: false,
 		},
+		{
+			name: "copy-on-write with accept",
+			metric: mustMetric(
+				"memory",
+				map[string]string{},
+				map[string]interface{}{
+					"value": 42,
+				},
+				time.Unix(0, 0),
+				telegraf.Gauge,
+			),
+			actions: func(m telegraf.Metric) {
+				m2 := CopyOnWrite(m)
+				m2.AddTag("foo", "bar")
+				m.Accept()
+				m2.Accept()
+			},
+			delivered: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -298,3 +317,72 @@ func TestGroupTracking(t *testing.T) {
 		})
 	}
 }
+
+func TestGroupTrackingOnAccept(t *testing.T) {
+	var count int
+	var info telegraf.DeliveryInfo
+	notify := func(di telegraf.DeliveryInfo) {
+		count++
+		info = di
+	}
+
+	group := []telegraf.Metric{
+		mustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+		mustMetric("mem", map[string]string{}, map[string]interface{}{"value": 23}, time.Unix(0, 0)),
+	}
+	first, _ := WithGroupTrackingOnAccept(group, notify)
+
+	// A second output getting a copy of each metric but never finishing them
+	second := make([]telegraf.Metric, 0, len(first))
+	for _, m := range first {
+		second = append(second, m.Copy())
+	}
+
+	first[0].Accept()
+	require.Zero(t, count)
+	first[1].Accept()
+	require.Equal(t, 1, count)
+	require.Equal(t, 2, info.Accepted())
+	require.True(t, info.Delivered())
+
+	// Finishing the remaining copies must not notify again
+	for _, m := range second {
+		m.Reject()
+	}
+	require.Equal(t, 1, count)
+}
+
+func TestGroupTrackingOnAcceptSplitAcrossOutputs(t *testing.T) {
+	var count int
+	notify := func(telegraf.DeliveryInfo) {
+		count++
+	}
+
+	group := []telegraf.Metric{
+		mustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+		mustMetric("mem", map[string]string{}, map[string]interface{}{"value": 23}, time.Unix(0, 0)),
+		mustMetric("disk", map[string]string{}, map[string]interface{}{"value": 7}, time.Unix(0, 0)),
+	}
+	first, _ := WithGroupTrackingOnAccept(group, notify)
+
+	second := make([]telegraf.Metric, 0, len(first))
+	for _, m := range first {
+		second = append(second, CopyOnWrite(m))
+	}
+
+	// Both outputs accept the first metric, so three accepts do not cover
+	// the whole group
+	first[0].Accept()
+	first[1].Accept()
+	second[0].Accept()
+	require.Zero(t, count)
+
+	// The last metric is only delivered by the second output
+	first[2].Reject()
+	require.Zero(t, count)
+	second[2].Accept()
+	require.Equal(t, 1, count)
+
+	second[1].Reject()
+	require.Equal(t, 1, count)
+}
@@ -0,0 +1,89 @@
+package outputs_amqp
+
+import (
+	"strings"
+
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
+
+	"github.com/influxdata/telegraf/migrations"
+)
+
+// Default values of the deprecated options sent as headers
+const (
+	defaultDatabase        = "telegraf"
+	defaultRetentionPolicy = "default"
+)
+
+// Migration function
+func migrate(tbl *ast.Table) ([]byte, string, error) {
+	// Decode the configuration into a generic map to keep all other settings
+	var plugin map[string]interface{}
+	if err := toml.UnmarshalTable(tbl, &plugin); err != nil {
+		return nil, "", err
+	}
+
+	var notices []string
+
+	// Replace the 'url' option by 'brokers' if no brokers are set
+	if u, found := plugin["url"]; found {
+		if _, found := plugin["brokers"]; !found {
+			plugin["brokers"] = []interface{}{u}
+			notices = append(notices, "replaced 'url' by 'brokers'")
+		} else {
+			notices = append(notices, "removed 'url' as 'brokers' is set")
+		}
+		delete(plugin, "url")
+	}
+
+	// The 'database' and 'retention_policy' settings are sent as headers
+	// if no headers are configured.
+	database, hasDatabase := plugin["database"]
+	rp, hasRP := plugin["retention_policy"]
+	if hasDatabase || hasRP {
+		if _, found := plugin["headers"]; !found {
+			if !hasDatabase {
+				database = defaultDatabase
+			}
+			if !hasRP {
+				rp = defaultRetentionPolicy
+			}
+			plugin["headers"] = map[string]interface{}{
+				"database":         database,
+				"retention_policy": rp,
+			}
+			notices = append(notices, "replaced 'database' and 'retention_policy' by 'headers'")
+		} else {
+			notices = append(notices, "removed 'database' and 'retention_policy' as 'headers' are set")
+		}
+		delete(plugin, "database")
+		delete(plugin, "retention_policy")
+	}
+
+	// The 'precision' setting is ignored by the plugin
+	if _, found := plugin["precision"]; found {
+		delete(plugin, "precision")
+		notices = append(notices, "removed ignored 'precision'")
+	}
+
+	if len(notices) == 0 {
+		return nil, "", migrations.ErrNotApplicable
+	}
+
+	// Create the corresponding plugin configuration
+	cfg := migrations.CreateTOMLStruct("outputs", "amqp")
+	cfg.Add("outputs", "amqp", plugin)
+
+	output, err := toml.Marshal(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	output = append(output, []byte("\n")...)
+
+	return output, strings.Join(notices, "\n"), nil
+}
+
+// Register the migration function for the plugin type
+func init() {
+	migrations.AddPluginMigration("outputs.amqp", migrate)
+}
@@ -1,11 +1,16 @@
 package migrations
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/influxdata/toml/ast"
 )
 
+// ErrNotApplicable is returned by migration functions if the plugin
+// configuration does not contain anything to migrate
+var ErrNotApplicable = errors.New("no migration applicable")
+
 type PluginMigrationFunc func(*ast.Table) ([]byte, string, error)
 
 var PluginMigrations = make(map[string]PluginMigrationFunc)
@@ -2,6 +2,7 @@ package models
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/selfstat"
@@ -13,6 +14,13 @@ var (
 )
 
 // Buffer stores metrics in a circular buffer.
+//
+// Adding metrics does not lock the buffer as long as the buffer is not full.
+// Instead, the metrics are reserved a slot in the buffer and passed via a
+// lock-free queue. The queue is drained into the buffer by the consumer side,
+// i.e. the output, while holding the lock. This way a slow output, e.g. one
+// accepting or rejecting large batches, does not block the agent adding
+// metrics to all outputs.
 type Buffer struct {
 	sync.Mutex
 	buf   []telegraf.Metric
@@ -24,11 +32,22 @@ type Buffer struct {
 	batchFirst int // index of the first metric in the batch
 	batchSize  int // number of metrics currently in the batch
 
+	// incoming holds the metrics added but not yet moved to the buffer
+	incoming *metricQueue
+	// free is the number of free slots in the buffer not yet reserved by
+	// metrics in the incoming queue
+	free atomic.Int64
+	// batchLen mirrors batchSize for lock-free reading
+	batchLen atomic.Int64
+
 	MetricsAdded   selfstat.Stat
 	MetricsWritten selfstat.Stat
 	MetricsDropped selfstat.Stat
 	BufferSize     selfstat.Stat
 	BufferLimit    selfstat.Stat
+
+	// errorsDropped counts the dropped metrics as errors of the output
+	errorsDropped selfstat.Stat
 }
 
 // NewBuffer returns a new empty Buffer with the given capacity.
@@ -37,7 +56,12 @@ func NewBuffer(name string, alias string, capacity int) *Buffer {
 	if alias != "" {
 		tags["alias"] = alias
 	}
+	return newBuffer(tags, capacity)
+}
 
+// newBuffer returns a new empty Buffer reporting its statistics with the
+// given tags, which must contain the output name.
+func newBuffer(tags map[string]string, capacity int) *Buffer {
 	b := &Buffer{
 		buf:   make([]telegraf.Metric, capacity),
 		first: 0,
@@ -71,6 +95,14 @@ func NewBuffer(name string, alias string, capacity int) *Buffer {
 			tags,
 		),
 	}
+	errorTags := map[string]string{"plugin": "outputs." + tags["output"]}
+	if alias, found := tags["alias"]; found {
+		errorTags["alias"] = alias
+	}
+	b.errorsDropped = selfstat.Register("errors", "dropped_metrics", errorTags)
+	b.incoming = newMetricQueue(min(capacity, maxIncomingQueueSize))
+	b.free.Store(int64(capacity))
+
 	b.BufferSize.Set(int64(0))
 	b.BufferLimit.Set(int64(capacity))
 	return b
@@ -80,6 +112,7 @@ func NewBuffer(name string, alias string, capacity int) *Buffer {
 func (b *Buffer) Len() int {
 	b.Lock()
 	defer b.Unlock()
+	b.drain()
 
 	return b.length()
 }
@@ -101,57 +134,133 @@ func (b *Buffer) metricWritten(metric telegraf.Metric) {
 func (b *Buffer) metricDropped(metric telegraf.Metric) {
 	AgentMetricsDropped.Incr(1)
 	b.MetricsDropped.Incr(1)
+	b.errorsDropped.Incr(1)
 	metric.Reject()
 }
 
-func (b *Buffer) addMetric(m telegraf.Metric) int {
-	dropped := 0
-	// Check if Buffer is full
-	if b.size == b.cap {
-		b.metricDropped(b.buf[b.last])
-		dropped++
-
-		if b.batchSize > 0 {
-			b.batchSize--
-			b.batchFirst = b.next(b.batchFirst)
+// reserve tries to reserve up to count free slots in the buffer and returns
+// the number of reserved slots
+func (b *Buffer) reserve(count int) int {
+	for {
+		free := b.free.Load()
+		n := min64(free, int64(count))
+		if n <= 0 {
+			return 0
+		}
+		if b.free.CompareAndSwap(free, free-n) {
+			return int(n)
 		}
 	}
+}
 
-	b.metricAdded()
-
+// place puts the metric into a slot reserved before. Must be called while
+// holding the lock.
+func (b *Buffer) place(m telegraf.Metric) {
 	b.buf[b.last] = m
 	b.last = b.next(b.last)
+	b.size++
+}
 
-	if b.size == b.cap {
-		b.first = b.next(b.first)
+// drain moves the metrics of the incoming queue to the buffer. Must be called
+// while holding the lock.
+func (b *Buffer) drain() {
+	for {
+		m, ok := b.incoming.pop()
+		if !ok {
+			return
+		}
+		b.place(m)
+	}
+}
+
+// addMetric adds the metric to the full buffer by dropping the oldest metric
+// and returns the dropped metric. Must be called while holding the lock.
+func (b *Buffer) addMetric(m telegraf.Metric) telegraf.Metric {
+	// All slots might be reserved by metrics not yet in the incoming queue,
+	// so drop the new metric as there is nothing older to drop.
+	if b.size == 0 {
+		return m
+	}
+
+	dropped := b.buf[b.first]
+	b.buf[b.first] = nil
+	b.first = b.next(b.first)
+	b.size--
+
+	if b.batchSize > 0 {
+		b.batchSize--
+		b.batchFirst = b.next(b.batchFirst)
+		b.batchLen.Store(int64(b.batchSize))
 	}
 
-	b.size = min(b.size+1, b.cap)
+	b.place(m)
 	return dropped
 }
 
 // Add adds metrics to the buffer and returns number of dropped metrics.
 func (b *Buffer) Add(metrics ...telegraf.Metric) int {
+	var dropped []telegraf.Metric
+	for i, m := range metrics {
+		if b.reserve(1) == 0 {
+			// The buffer is full so we need to drop the oldest metrics
+			dropped = b.addFull(metrics[i:])
+			break
+		}
+		b.metricAdded()
+
+		if !b.incoming.push(m) {
+			// The queue is full, move the metrics to the buffer ourselves
+			b.Lock()
+			b.drain()
+			b.place(m)
+			b.Unlock()
+		}
+	}
+
+	// Notify the dropped metrics outside of the lock as this might block
+	for _, m := range dropped {
+		b.metricDropped(m)
+	}
+
+	b.BufferSize.Set(b.estimatedLength())
+	return len(dropped)
+}
+
+// addFull adds the metrics while holding the lock, dropping the oldest
+// metrics if the buffer is full, and returns the dropped metrics.
+func (b *Buffer) addFull(metrics []telegraf.Metric) []telegraf.Metric {
 	b.Lock()
 	defer b.Unlock()
-
-	dropped := 0
-	for i := range metrics {
-		if n := b.addMetric(metrics[i]); n != 0 {
-			dropped += n
+	b.drain()
+
+	dropped := make([]telegraf.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		b.metricAdded()
+		// Slots might have been freed in the meantime
+		if b.reserve(1) > 0 {
+			b.place(m)
+			continue
 		}
+		dropped = append(dropped, b.addMetric(m))
 	}
 
-	b.BufferSize.Set(int64(b.length()))
 	return dropped
 }
 
+// estimatedLength returns the length of the buffer including the metrics
+// not yet moved from the incoming queue without locking
+func (b *Buffer) estimatedLength() int64 {
+	used := int64(b.cap) - b.free.Load() + b.batchLen.Load()
+	return min64(used, int64(b.cap))
+}
+
 // Batch returns a slice containing up to batchSize of the oldest metrics not
 // yet dropped.  Metrics are ordered from oldest to newest in the batch.  The
 // batch must not be modified by the client.
 func (b *Buffer) Batch(batchSize int) []telegraf.Metric {
 	b.Lock()
 	defer b.Unlock()
+	b.drain()
 
 	outLen := min(b.size, batchSize)
 	out := make([]telegraf.Metric, outLen)
@@ -161,6 +270,7 @@ func (b *Buffer) Batch(batchSize int) []telegraf.Metric {
 
 	b.batchFirst = b.first
 	b.batchSize = outLen
+	b.batchLen.Store(int64(outLen))
 
 	batchIndex := b.batchFirst
 	for i := range out {
@@ -171,53 +281,114 @@ func (b *Buffer) Batch(batchSize int) []telegraf.Metric {
 
 	b.first = b.nextby(b.first, b.batchSize)
 	b.size -= outLen
+	b.free.Add(int64(outLen))
 	return out
 }
 
 // Accept marks the batch, acquired from Batch(), as successfully written.
 func (b *Buffer) Accept(batch []telegraf.Metric) {
 	b.Lock()
-	defer b.Unlock()
+	b.resetBatch()
+	b.Unlock()
 
+	// Notify the written metrics outside of the lock as this might block
 	for _, m := range batch {
 		b.metricWritten(m)
 	}
 
-	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
+	b.BufferSize.Set(b.estimatedLength())
+}
+
+// AcceptPartial returns the metrics of the batch, acquired from Batch(), at
+// the given indices to the buffer and marks all other metrics of the batch as
+// successfully written.
+func (b *Buffer) AcceptPartial(batch []telegraf.Metric, failed []int) {
+	if len(failed) == 0 {
+		b.Accept(batch)
+		return
+	}
+
+	isFailed := make([]bool, len(batch))
+	for _, i := range failed {
+		if i >= 0 && i < len(batch) {
+			isFailed[i] = true
+		}
+	}
+	rejected := make([]telegraf.Metric, 0, len(failed))
+	accepted := make([]telegraf.Metric, 0, len(batch)-len(failed))
+	for i, m := range batch {
+		if isFailed[i] {
+			rejected = append(rejected, m)
+		} else {
+			accepted = append(accepted, m)
+		}
+	}
+
+	b.Reject(rejected)
+	for _, m := range accepted {
+		b.metricWritten(m)
+	}
 }
 
 // Reject returns the batch, acquired from Batch(), to the buffer and marks it
 // as unsent.
 func (b *Buffer) Reject(batch []telegraf.Metric) {
-	b.Lock()
-	defer b.Unlock()
-
 	if len(batch) == 0 {
 		return
 	}
 
-	free := b.cap - b.size
-	restore := min(len(batch), free)
+	b.Lock()
+	b.drain()
+
+	restore := b.reserve(len(batch))
 	skip := len(batch) - restore
 
 	b.first = b.prevby(b.first, restore)
-	b.size = min(b.size+restore, b.cap)
+	b.size += restore
 
 	re := b.first
 
 	// Copy metrics from the batch back into the buffer
-	for i := range batch {
-		if i < skip {
-			b.metricDropped(batch[i])
-		} else {
-			b.buf[re] = batch[i]
-			re = b.next(re)
-		}
+	for i := range batch[skip:] {
+		b.buf[re] = batch[skip+i]
+		re = b.next(re)
 	}
 
 	b.resetBatch()
-	b.BufferSize.Set(int64(b.length()))
+	b.Unlock()
+
+	// Notify the dropped metrics outside of the lock as this might block
+	for _, m := range batch[:skip] {
+		b.metricDropped(m)
+	}
+
+	b.BufferSize.Set(b.estimatedLength())
+}
+
+// DropOldest drops up to count of the oldest metrics in the buffer not being
+// part of the current batch and returns the number of dropped metrics.
+func (b *Buffer) DropOldest(count int) int {
+	b.Lock()
+	b.drain()
+
+	count = min(count, b.size)
+	dropped := make([]telegraf.Metric, 0, count)
+	for i := 0; i < count; i++ {
+		dropped = append(dropped, b.buf[b.first])
+		b.buf[b.first] = nil
+		b.first = b.next(b.first)
+	}
+	b.size -= count
+	b.free.Add(int64(count))
+	b.Unlock()
+
+	// Notify the dropped metrics outside of the lock as this might block
+	for _, m := range dropped {
+		b.metricDropped(m)
+	}
+
+	b.BufferSize.Set(b.estimatedLength())
+	return count
 }
 
 // next returns the next index with wrapping.
@@ -250,6 +421,7 @@ func (b *Buffer) prevby(index, count int) int {
 func (b *Buffer) resetBatch() {
 	b.batchFirst = 0
 	b.batchSize = 0
+	b.batchLen.Store(0)
 }
 
 func min(a, b int) int {
@@ -258,3 +430,10 @@ func min(a, b int) int {
 	}
 	return a
 }
+
+func min64(a, b int64) int64 {
+	if b < a {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"sync/atomic"
+
+	"github.com/influxdata/telegraf"
+)
+
+// maxIncomingQueueSize is the maximum number of metrics in the lock-free
+// queue of the buffer before adding metrics falls back to locking
+const maxIncomingQueueSize = 4096
+
+type queueSlot struct {
+	seq    atomic.Uint64
+	metric telegraf.Metric
+}
+
+// metricQueue is a bounded lock-free multi-producer single-consumer queue
+// based on the design of Dmitry Vyukov. Each slot carries a sequence number
+// telling producers whether the slot is free and the consumer whether the
+// metric in the slot is published.
+type metricQueue struct {
+	slots []queueSlot
+	mask  uint64
+	head  atomic.Uint64 // next position to push to
+	tail  uint64        // next position to pop from, only used by the consumer
+}
+
+// newMetricQueue creates a queue with a capacity of at least the given size
+func newMetricQueue(size int) *metricQueue {
+	n := 1
+	for n < size {
+		n <<= 1
+	}
+
+	q := &metricQueue{
+		slots: make([]queueSlot, n),
+		mask:  uint64(n - 1),
+	}
+	for i := range q.slots {
+		q.slots[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// push adds the metric to the queue and returns false if the queue is full.
+// It is safe to call push concurrently.
+func (q *metricQueue) push(m telegraf.Metric) bool {
+	pos := q.head.Load()
+	for {
+		slot := &q.slots[pos&q.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq - pos); {
+		case diff == 0:
+			// The slot is free, try to claim it
+			if q.head.CompareAndSwap(pos, pos+1) {
+				slot.metric = m
+				slot.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.head.Load()
+		case diff < 0:
+			// The slot still holds a metric not consumed yet
+			return false
+		default:
+			// Another producer claimed the slot in the meantime
+			pos = q.head.Load()
+		}
+	}
+}
+
+// pop removes the oldest published metric from the queue. Only one consumer
+// must call pop at a time.
+func (q *metricQueue) pop() (telegraf.Metric, bool) {
+	slot := &q.slots[q.tail&q.mask]
+	if int64(slot.seq.Load()-(q.tail+1)) < 0 {
+		// The queue is empty or the metric is not published yet
+		return nil, false
+	}
+
+	m := slot.metric
+	slot.metric = nil
+	slot.seq.Store(q.tail + q.mask + 1)
+	q.tail++
+	return m, true
+}
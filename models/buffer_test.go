@@ -1,6 +1,8 @@
 package models
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -723,3 +725,143 @@ func TestBuffer_RejectEmptyBatch(t *testing.T) {
 		require.NotNil(t, m)
 	}
 }
+
+func TestBuffer_AcceptPartial(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	b.Add(MetricTime(3))
+	batch := b.Batch(3)
+	b.Add(MetricTime(4))
+	b.AcceptPartial(batch, []int{1})
+
+	require.Equal(t, int64(2), b.MetricsWritten.Get())
+	require.Equal(t, int64(0), b.MetricsDropped.Get())
+
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			MetricTime(2),
+			MetricTime(4),
+		}, batch)
+}
+
+func TestBuffer_DropOldest(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	b.Add(MetricTime(3))
+	b.Add(MetricTime(4))
+
+	require.Equal(t, 2, b.DropOldest(2))
+	require.Equal(t, int64(2), b.MetricsDropped.Get())
+	require.Equal(t, 2, b.Len())
+
+	batch := b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			MetricTime(3),
+			MetricTime(4),
+		}, batch)
+}
+
+func TestBuffer_DropOldestKeepsBatch(t *testing.T) {
+	b := setup(NewBuffer("test", "", 5))
+	b.Add(MetricTime(1))
+	b.Add(MetricTime(2))
+	batch := b.Batch(2)
+	b.Add(MetricTime(3))
+	b.Add(MetricTime(4))
+	b.Add(MetricTime(5))
+
+	require.Equal(t, 3, b.DropOldest(10))
+	require.Equal(t, int64(3), b.MetricsDropped.Get())
+
+	b.Reject(batch)
+	batch = b.Batch(5)
+	testutil.RequireMetricsEqual(t,
+		[]telegraf.Metric{
+			MetricTime(1),
+			MetricTime(2),
+		}, batch)
+}
+
+func TestBuffer_ConcurrentAddAndBatch(t *testing.T) {
+	var accepted, rejected atomic.Int64
+	mm := &MockMetric{
+		Metric:  Metric(),
+		AcceptF: func() { accepted.Add(1) },
+		RejectF: func() { rejected.Add(1) },
+	}
+
+	b := setup(NewBuffer("test", "", 100))
+
+	// Add metrics from multiple producers while the output is writing
+	producers, perProducer := 8, 10000
+	var wg sync.WaitGroup
+	wg.Add(producers)
+	for i := 0; i < producers; i++ {
+		go func() {
+			defer wg.Done()
+			for n := 0; n < perProducer; n++ {
+				b.Add(mm)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+		}
+
+		batch := b.Batch(10)
+		require.LessOrEqual(t, b.Len(), 100)
+		if len(batch)%2 == 0 {
+			b.Accept(batch)
+		} else {
+			b.Reject(batch)
+		}
+	}
+
+	// Nothing must get lost, all metrics are either written, dropped or
+	// still in the buffer
+	remaining := b.Len()
+	require.LessOrEqual(t, remaining, 100)
+	require.Equal(t, int64(producers*perProducer), b.MetricsAdded.Get())
+	require.Equal(t, int64(producers*perProducer), accepted.Load()+rejected.Load()+int64(remaining))
+	require.Equal(t, accepted.Load(), b.MetricsWritten.Get())
+	require.Equal(t, rejected.Load(), b.MetricsDropped.Get())
+}
+
+func BenchmarkAddMetricsParallel(b *testing.B) {
+	buf := NewBuffer("test", "", 10000)
+	m := Metric()
+
+	// Consume the metrics concurrently like an output does
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				buf.Accept(buf.Batch(1000))
+			}
+		}
+	}()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			buf.Add(m)
+		}
+	})
+}
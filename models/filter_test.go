@@ -567,6 +567,16 @@ func TestFilter_MetricPass(t *testing.T) {
 			expression: `time >= timestamp("2023-04-25T00:00:00Z") - duration("24h")`,
 			expected:   true,
 		},
+		{
+			name:       "mixed numeric types",
+			expression: `fields.value < 20 && tags.status == "ok"`,
+			expected:   true,
+		},
+		{
+			name:       "optional tag",
+			expression: `"env" in tags && tags.env == "prod"`,
+			expected:   false,
+		},
 		{
 			name:       "complex field filtering",
 			expression: `fields.exists(f, type(fields[f]) in [int, uint, double] and fields[f] > 20.0)`,
@@ -1,23 +1,39 @@
 package models
 
 import (
+	"fmt"
 	"log"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/fatih/color"
+	"github.com/influxdata/wlog"
+
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/selfstat"
+)
+
+// Log-level overrides of plugins keyed by the log name of the plugin
+var (
+	logLevels     = make(map[string]wlog.Level)
+	logLevelsLock sync.RWMutex
 )
 
 // Logger defines a logging structure for plugins.
 type Logger struct {
 	OnErrs []func()
 	Name   string // Name is the plugin name, will be printed in the `[]`.
+
+	errors errorStats
 }
 
 // NewLogger creates a new logger instance
 func NewLogger(pluginType, name, alias string) *Logger {
 	return &Logger{
-		Name: logName(pluginType, name, alias),
+		Name:   logName(pluginType, name, alias),
+		errors: newErrorStats(pluginType, name, alias),
 	}
 }
 
@@ -31,7 +47,9 @@ func (l *Logger) Errorf(format string, args ...interface{}) {
 	for _, f := range l.OnErrs {
 		f()
 	}
-	log.Printf("E! ["+l.Name+"] "+format, args...)
+	msg := fmt.Sprintf(format, args...)
+	l.errors.count(msg)
+	log.Print("E! [" + l.Name + "] " + msg)
 }
 
 // Error logs an error message, patterned after log.Print.
@@ -39,7 +57,9 @@ func (l *Logger) Error(args ...interface{}) {
 	for _, f := range l.OnErrs {
 		f()
 	}
-	log.Print(append([]interface{}{"E! [" + l.Name + "] "}, args...)...)
+	msg := fmt.Sprint(args...)
+	l.errors.count(msg)
+	log.Print("E! [" + l.Name + "] " + msg)
 }
 
 // Debugf logs a debug message, patterned after log.Printf.
@@ -80,6 +100,126 @@ func logName(pluginType, name, alias string) string {
 	return pluginType + "." + name + "::" + alias
 }
 
+// ParseLogLevel returns the log-level for the given name, one of "debug",
+// "info", "warn" or "error"
+func ParseLogLevel(level string) (wlog.Level, error) {
+	l, found := wlog.StringToLevel[strings.ToUpper(level)]
+	if !found || l == wlog.OFF {
+		return 0, fmt.Errorf("invalid log level %q", level)
+	}
+	return l, nil
+}
+
+// LogLevelName returns the name of the given log-level
+func LogLevelName(level wlog.Level) string {
+	for name, l := range wlog.StringToLevel {
+		if l == level {
+			return strings.ToLower(name)
+		}
+	}
+	return ""
+}
+
+// SetLogLevel overrides the global log-level for all plugins with the given
+// log name, e.g. "inputs.cpu" or "inputs.cpu::alias"
+func SetLogLevel(name, level string) error {
+	l, err := ParseLogLevel(level)
+	if err != nil {
+		return err
+	}
+
+	logLevelsLock.Lock()
+	defer logLevelsLock.Unlock()
+	logLevels[name] = l
+
+	return nil
+}
+
+// ClearLogLevel removes the log-level override for plugins with the given
+// log name
+func ClearLogLevel(name string) {
+	logLevelsLock.Lock()
+	defer logLevelsLock.Unlock()
+	delete(logLevels, name)
+}
+
+// ResetLogLevels removes all log-level overrides, e.g. on reload
+func ResetLogLevels() {
+	logLevelsLock.Lock()
+	defer logLevelsLock.Unlock()
+	logLevels = make(map[string]wlog.Level)
+}
+
+// LogLevel returns the log-level override for plugins with the given log name
+func LogLevel(name string) (wlog.Level, bool) {
+	logLevelsLock.RLock()
+	defer logLevelsLock.RUnlock()
+	l, found := logLevels[name]
+	return l, found
+}
+
+// LogLevels returns the names of all log-level overrides by log name
+func LogLevels() map[string]string {
+	logLevelsLock.RLock()
+	defer logLevelsLock.RUnlock()
+
+	levels := make(map[string]string, len(logLevels))
+	for name, l := range logLevels {
+		levels[name] = LogLevelName(l)
+	}
+	return levels
+}
+
+// Patterns for classifying error messages, the first matching class is used
+var errorClasses = []struct {
+	class   string
+	pattern *regexp.Regexp
+}{
+	{"timeout", regexp.MustCompile(`(?i)timeout|timed out|deadline exceeded`)},
+	{"tls", regexp.MustCompile(`(?i)x509|tls|certificate`)},
+	{"authentication", regexp.MustCompile(`(?i)unauthori[sz]ed|forbidden|authenticat|permission denied|access denied|\b40[13]\b`)},
+	{"connection", regexp.MustCompile(`(?i)connection|connect:|dial |no such host|broken pipe|unreachable|\bEOF\b`)},
+	{"serialization", regexp.MustCompile(`(?i)serializ|marshal|pars(e|ing)|invalid character|unexpected end of`)},
+}
+
+// ErrorClass returns the class of the given error message, one of "timeout",
+// "tls", "authentication", "connection", "serialization" or "other"
+func ErrorClass(msg string) string {
+	for _, c := range errorClasses {
+		if c.pattern.MatchString(msg) {
+			return c.class
+		}
+	}
+	return "other"
+}
+
+// errorStats counts the errors of a plugin by error class in the
+// "internal_errors" measurement
+type errorStats map[string]selfstat.Stat
+
+func newErrorStats(pluginType, name, alias string) errorStats {
+	tags := map[string]string{"plugin": pluginType + "." + name}
+	if alias != "" {
+		tags["alias"] = alias
+	}
+
+	// Register all classes upfront to report zero values for alerting
+	stats := make(errorStats, len(errorClasses)+1)
+	for _, c := range errorClasses {
+		stats[c.class] = selfstat.Register("errors", c.class, tags)
+	}
+	stats["other"] = selfstat.Register("errors", "other", tags)
+
+	return stats
+}
+
+// count increments the counter of the error class of the given message
+func (s errorStats) count(msg string) {
+	if stat, found := s[ErrorClass(msg)]; found {
+		stat.Incr(1)
+	}
+}
+
 func SetLoggerOnPlugin(i interface{}, logger telegraf.Logger) {
 	valI := reflect.ValueOf(i)
 
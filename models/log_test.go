@@ -8,6 +8,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/wlog"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/stretchr/testify/require"
@@ -258,3 +260,57 @@ func TestPluginOptionValueDeprecation(t *testing.T) {
 		})
 	}
 }
+
+func TestLogLevels(t *testing.T) {
+	defer ResetLogLevels()
+
+	require.NoError(t, SetLogLevel("inputs.cpu", "debug"))
+	require.NoError(t, SetLogLevel("inputs.cpu::foo", "WARN"))
+	require.ErrorContains(t, SetLogLevel("inputs.mem", "off"), `invalid log level "off"`)
+	require.ErrorContains(t, SetLogLevel("inputs.mem", "verbose"), `invalid log level "verbose"`)
+
+	level, found := LogLevel("inputs.cpu")
+	require.True(t, found)
+	require.Equal(t, wlog.DEBUG, level)
+	_, found = LogLevel("inputs.mem")
+	require.False(t, found)
+	require.Equal(t, map[string]string{"inputs.cpu": "debug", "inputs.cpu::foo": "warn"}, LogLevels())
+
+	ClearLogLevel("inputs.cpu")
+	require.Equal(t, map[string]string{"inputs.cpu::foo": "warn"}, LogLevels())
+
+	ResetLogLevels()
+	require.Empty(t, LogLevels())
+}
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		msg   string
+		class string
+	}{
+		{"dial tcp 127.0.0.1:5672: connect: connection refused", "connection"},
+		{"Post \"http://localhost\": context deadline exceeded", "timeout"},
+		{"read tcp 127.0.0.1:5672: i/o timeout", "timeout"},
+		{"x509: certificate signed by unknown authority", "tls"},
+		{"received status code 401 (Unauthorized)", "authentication"},
+		{"could not serialize metric: invalid field", "serialization"},
+		{"unexpected EOF", "connection"},
+		{"something went wrong", "other"},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.class, ErrorClass(tt.msg), tt.msg)
+	}
+}
+
+func TestErrorClassCounting(t *testing.T) {
+	l := NewLogger("inputs", "test_error_class", "foo")
+	l.Errorf("connecting failed: %v", "dial tcp: connection refused")
+	l.Error("request timed out")
+	l.Error("request timed out")
+	l.Warn("connection refused")
+
+	tags := map[string]string{"plugin": "inputs.test_error_class", "alias": "foo"}
+	require.Equal(t, int64(1), selfstat.Register("errors", "connection", tags).Get())
+	require.Equal(t, int64(2), selfstat.Register("errors", "timeout", tags).Get())
+	require.Equal(t, int64(0), selfstat.Register("errors", "other", tags).Get())
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+// RateLimitConfig limits the number of metrics and bytes written by an output
+// per second. A zero rate disables the respective limit.
+type RateLimitConfig struct {
+	PointsPerSecond float64
+	PointsBurst     int
+	BytesPerSecond  int64
+	BytesBurst      int64
+}
+
+// rateLimiter delays writes exceeding the configured rates. The size of the
+// metrics is estimated using their line protocol representation as the actual
+// wire format depends on the output.
+type rateLimiter struct {
+	points     *rate.Limiter
+	bytes      *rate.Limiter
+	serializer *influx.Serializer
+}
+
+func newRateLimiter(cfg RateLimitConfig) *rateLimiter {
+	if cfg.PointsPerSecond <= 0 && cfg.BytesPerSecond <= 0 {
+		return nil
+	}
+
+	l := &rateLimiter{}
+	if cfg.PointsPerSecond > 0 {
+		burst := cfg.PointsBurst
+		if burst <= 0 {
+			burst = int(math.Ceil(cfg.PointsPerSecond))
+		}
+		l.points = rate.NewLimiter(rate.Limit(cfg.PointsPerSecond), burst)
+	}
+	if cfg.BytesPerSecond > 0 {
+		burst := cfg.BytesBurst
+		if burst <= 0 {
+			burst = cfg.BytesPerSecond
+		}
+		if burst > math.MaxInt32 {
+			burst = math.MaxInt32
+		}
+		l.bytes = rate.NewLimiter(rate.Limit(cfg.BytesPerSecond), int(burst))
+		l.serializer = &influx.Serializer{}
+		// The serializer initialization cannot fail with the default settings
+		_ = l.serializer.Init()
+	}
+	return l
+}
+
+// delay reserves the tokens for writing the metrics and returns the time to
+// wait before the write. Batches exceeding the burst size are delayed until
+// enough tokens accumulated for the whole batch.
+func (l *rateLimiter) delay(metrics []telegraf.Metric) time.Duration {
+	now := time.Now()
+
+	var d time.Duration
+	if l.points != nil {
+		d = reserve(l.points, len(metrics), now)
+	}
+	// Only estimate the size if a byte limit is configured as serializing the
+	// metrics is expensive
+	if l.bytes != nil {
+		if db := reserve(l.bytes, l.size(metrics), now); db > d {
+			d = db
+		}
+	}
+	return d
+}
+
+// size estimates the number of bytes written for the metrics
+func (l *rateLimiter) size(metrics []telegraf.Metric) int {
+	var size int
+	for _, m := range metrics {
+		if buf, err := l.serializer.Serialize(m); err == nil {
+			size += len(buf)
+		}
+	}
+	return size
+}
+
+// reserve takes n tokens from the limiter in chunks not exceeding the burst
+// and returns the delay of the last chunk
+func reserve(limiter *rate.Limiter, n int, now time.Time) time.Duration {
+	var d time.Duration
+	for n > 0 {
+		chunk := n
+		if burst := limiter.Burst(); chunk > burst {
+			chunk = burst
+		}
+		d = limiter.ReserveN(now, chunk).DelayFrom(now)
+		n -= chunk
+	}
+	return d
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -15,6 +17,7 @@ type RunningAggregator struct {
 	Config      *AggregatorConfig
 	periodStart time.Time
 	periodEnd   time.Time
+	windows     map[int64]*eventWindow
 	log         telegraf.Logger
 
 	MetricsPushed   selfstat.Stat
@@ -28,6 +31,9 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 	if config.Alias != "" {
 		tags["alias"] = config.Alias
 	}
+	if config.Pipeline != "" {
+		tags["pipeline"] = config.Pipeline
+	}
 
 	aggErrorsRegister := selfstat.Register("aggregate", "errors", tags)
 	logger := NewLogger("aggregators", config.Name, config.Alias)
@@ -60,25 +66,41 @@ func NewRunningAggregator(aggregator telegraf.Aggregator, config *AggregatorConf
 			"push_time_ns",
 			tags,
 		),
-		log: logger,
+		windows: make(map[int64]*eventWindow),
+		log:     logger,
 	}
 }
 
+// eventWindow holds the metrics of an aggregation period when aggregating by
+// metric timestamp. The metrics are kept until the watermark passed to be
+// able to emit corrected aggregates for late metrics.
+type eventWindow struct {
+	start   time.Time
+	end     time.Time
+	metrics []telegraf.Metric
+	emitted bool
+	dirty   bool
+}
+
 // AggregatorConfig is the common config for all aggregators.
 type AggregatorConfig struct {
 	Name         string
 	Alias        string
 	ID           string
+	Pipeline     string
 	DropOriginal bool
 	Period       time.Duration
 	Delay        time.Duration
 	Grace        time.Duration
+	EventTime    bool
+	Watermark    time.Duration
 
 	NameOverride      string
 	MeasurementPrefix string
 	MeasurementSuffix string
 	Tags              map[string]string
 	Filter            Filter
+	LogLevel          string
 }
 
 func (r *RunningAggregator) LogName() string {
@@ -86,6 +108,9 @@ func (r *RunningAggregator) LogName() string {
 }
 
 func (r *RunningAggregator) Init() error {
+	if r.Config.EventTime && r.Config.Period <= 0 {
+		return errors.New("'period' must be positive when aggregating by event time")
+	}
 	if p, ok := r.Aggregator.(telegraf.Initializer); ok {
 		err := p.Init()
 		if err != nil {
@@ -155,6 +180,11 @@ func (r *RunningAggregator) Add(m telegraf.Metric) bool {
 	r.Lock()
 	defer r.Unlock()
 
+	if r.Config.EventTime {
+		r.addEventTime(m, time.Now())
+		return r.Config.DropOriginal
+	}
+
 	if m.Time().Before(r.periodStart.Add(-r.Config.Grace)) || m.Time().After(r.periodEnd.Add(r.Config.Delay)) {
 		r.log.Debugf("Metric is outside aggregation window; discarding. %s: m: %s e: %s g: %s",
 			m.Time(), r.periodStart, r.periodEnd, r.Config.Grace)
@@ -174,6 +204,11 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	until := r.periodEnd.Add(r.Config.Period)
 	r.UpdateWindow(since, until)
 
+	if r.Config.EventTime {
+		r.pushEventTime(acc, time.Now())
+		return
+	}
+
 	start := time.Now()
 	r.Aggregator.Push(acc)
 	elapsed := time.Since(start)
@@ -181,6 +216,98 @@ func (r *RunningAggregator) Push(acc telegraf.Accumulator) {
 	r.Aggregator.Reset()
 }
 
+// addEventTime adds the metric to the aggregation period its timestamp
+// belongs to. Metrics for periods already finalized are dropped.
+func (r *RunningAggregator) addEventTime(m telegraf.Metric, now time.Time) {
+	start := m.Time().Truncate(r.Config.Period)
+	end := start.Add(r.Config.Period)
+	if !now.Before(end.Add(r.Config.Delay + r.Config.Watermark)) {
+		r.log.Debugf("Metric is older than the watermark; discarding. %s: s: %s e: %s w: %s",
+			m.Time(), start, end, r.Config.Watermark)
+		r.MetricsDropped.Incr(1)
+		return
+	}
+
+	w, found := r.windows[start.UnixNano()]
+	if !found {
+		w = &eventWindow{start: start, end: end}
+		r.windows[start.UnixNano()] = w
+	}
+	w.metrics = append(w.metrics, m)
+	w.dirty = true
+}
+
+// pushEventTime emits the aggregates of all finished periods with new metrics.
+// Periods receiving late metrics are emitted again with the corrected
+// aggregate using the same timestamp, so the previous aggregate is replaced
+// in outputs supporting this. Periods older than the watermark are finalized.
+func (r *RunningAggregator) pushEventTime(acc telegraf.Accumulator, now time.Time) {
+	windows := make([]*eventWindow, 0, len(r.windows))
+	for _, w := range r.windows {
+		windows = append(windows, w)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i].start.Before(windows[j].start) })
+
+	start := time.Now()
+	for _, w := range windows {
+		closed := w.end.Add(r.Config.Delay)
+		if now.Before(closed) {
+			continue
+		}
+
+		if w.dirty {
+			if w.emitted {
+				r.log.Debugf("Emitting corrected aggregate for [%s, %s]", w.start, w.end)
+			}
+			r.Aggregator.Reset()
+			for _, m := range w.metrics {
+				r.Aggregator.Add(m)
+			}
+			r.Aggregator.Push(&eventTimeAccumulator{Accumulator: acc, timestamp: w.end})
+			r.Aggregator.Reset()
+			w.emitted = true
+			w.dirty = false
+		}
+
+		if !now.Before(closed.Add(r.Config.Watermark)) {
+			delete(r.windows, w.start.UnixNano())
+		}
+	}
+	r.PushTime.Incr(time.Since(start).Nanoseconds())
+}
+
 func (r *RunningAggregator) Log() telegraf.Logger {
 	return r.log
 }
+
+// eventTimeAccumulator sets the timestamp of all aggregates to the end of the
+// aggregation period
+type eventTimeAccumulator struct {
+	telegraf.Accumulator
+	timestamp time.Time
+}
+
+func (a *eventTimeAccumulator) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, _ ...time.Time) {
+	a.Accumulator.AddFields(measurement, fields, tags, a.timestamp)
+}
+
+func (a *eventTimeAccumulator) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, _ ...time.Time) {
+	a.Accumulator.AddGauge(measurement, fields, tags, a.timestamp)
+}
+
+func (a *eventTimeAccumulator) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, _ ...time.Time) {
+	a.Accumulator.AddCounter(measurement, fields, tags, a.timestamp)
+}
+
+func (a *eventTimeAccumulator) AddSummary(measurement string, fields map[string]interface{}, tags map[string]string, _ ...time.Time) {
+	a.Accumulator.AddSummary(measurement, fields, tags, a.timestamp)
+}
+
+func (a *eventTimeAccumulator) AddHistogram(measurement string, fields map[string]interface{}, tags map[string]string, _ ...time.Time) {
+	a.Accumulator.AddHistogram(measurement, fields, tags, a.timestamp)
+}
+
+func (a *eventTimeAccumulator) AddMetric(m telegraf.Metric) {
+	m.SetTime(a.timestamp)
+	a.Accumulator.AddMetric(m)
+}
@@ -238,6 +238,73 @@ func TestAddDoesNotModifyMetric(t *testing.T) {
 	testutil.RequireMetricEqual(t, expected, m)
 }
 
+func TestEventTime(t *testing.T) {
+	ra := NewRunningAggregator(&TestAggregator{}, &AggregatorConfig{
+		Name:      "TestRunningAggregator",
+		Period:    time.Minute,
+		EventTime: true,
+		Watermark: 2 * time.Minute,
+	})
+	require.NoError(t, ra.Config.Filter.Compile())
+	require.NoError(t, ra.Init())
+
+	start := time.Unix(1677628800, 0)
+	newMetric := func(offset time.Duration, v int64) telegraf.Metric {
+		return testutil.MustMetric("RITest", map[string]string{}, map[string]interface{}{"value": v}, start.Add(offset))
+	}
+
+	// Metrics are assigned to the period of their timestamp
+	now := start.Add(90 * time.Second)
+	ra.addEventTime(newMetric(10*time.Second, 1), now)
+	ra.addEventTime(newMetric(70*time.Second, 10), now)
+	ra.addEventTime(newMetric(20*time.Second, 2), now)
+
+	// Only the finished period is emitted
+	acc := testutil.Accumulator{}
+	ra.pushEventTime(&acc, now)
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int64(3), acc.Metrics[0].Fields["sum"])
+	require.Equal(t, start.Add(time.Minute), acc.Metrics[0].Time)
+
+	// A late metric causes a corrected aggregate with the same timestamp
+	now = start.Add(150 * time.Second)
+	ra.addEventTime(newMetric(30*time.Second, 4), now)
+	acc.ClearMetrics()
+	ra.pushEventTime(&acc, now)
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, int64(7), acc.Metrics[0].Fields["sum"])
+	require.Equal(t, start.Add(time.Minute), acc.Metrics[0].Time)
+	require.Equal(t, int64(10), acc.Metrics[1].Fields["sum"])
+	require.Equal(t, start.Add(2*time.Minute), acc.Metrics[1].Time)
+
+	// Nothing is emitted without new metrics
+	acc.ClearMetrics()
+	ra.pushEventTime(&acc, start.Add(170*time.Second))
+	require.Empty(t, acc.Metrics)
+
+	// After the watermark passed the period is finalized and late metrics
+	// are dropped
+	now = start.Add(3 * time.Minute)
+	ra.pushEventTime(&acc, now)
+	require.Len(t, ra.windows, 1)
+	dropped := ra.MetricsDropped.Get()
+	ra.addEventTime(newMetric(40*time.Second, 100), now)
+	require.Len(t, ra.windows, 1)
+	require.Equal(t, dropped+1, ra.MetricsDropped.Get())
+
+	ra.pushEventTime(&acc, start.Add(5*time.Minute))
+	require.Empty(t, acc.Metrics)
+	require.Empty(t, ra.windows)
+}
+
+func TestEventTimeInvalidPeriod(t *testing.T) {
+	ra := NewRunningAggregator(&TestAggregator{}, &AggregatorConfig{
+		Name:      "TestRunningAggregator",
+		EventTime: true,
+	})
+	require.ErrorContains(t, ra.Init(), "'period' must be positive")
+}
+
 type TestAggregator struct {
 	sum int64
 }
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -17,8 +18,13 @@ type RunningInput struct {
 	Input  telegraf.Input
 	Config *InputConfig
 
+	// ParserFunc creates a new instance of the parser configured for the
+	// input, it is nil for inputs not supporting data formats.
+	ParserFunc telegraf.ParserFunc
+
 	log         telegraf.Logger
 	defaultTags map[string]string
+	paused      atomic.Bool
 
 	MetricsGathered selfstat.Stat
 	GatherTime      selfstat.Stat
@@ -30,6 +36,9 @@ func NewRunningInput(input telegraf.Input, config *InputConfig) *RunningInput {
 	if config.Alias != "" {
 		tags["alias"] = config.Alias
 	}
+	if config.Pipeline != "" {
+		tags["pipeline"] = config.Pipeline
+	}
 
 	inputErrorsRegister := selfstat.Register("gather", "errors", tags)
 	logger := NewLogger("inputs", config.Name, config.Alias)
@@ -66,18 +75,23 @@ type InputConfig struct {
 	Name             string
 	Alias            string
 	ID               string
+	Pipeline         string
 	Interval         time.Duration
 	CollectionJitter time.Duration
 	CollectionOffset time.Duration
+	Schedule         string
 	Precision        time.Duration
 
 	NameOverride            string
 	MeasurementPrefix       string
 	MeasurementSuffix       string
 	Tags                    map[string]string
+	ExtraTags               map[string]string
+	HostMetadata            map[string]string
 	Filter                  Filter
 	AlwaysIncludeLocalTags  bool
 	AlwaysIncludeGlobalTags bool
+	LogLevel                string
 }
 
 func (r *RunningInput) metricFiltered(metric telegraf.Metric) {
@@ -106,6 +120,12 @@ func (r *RunningInput) ID() string {
 }
 
 func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
+	// Drop metrics of paused service inputs
+	if r.paused.Load() {
+		metric.Drop()
+		return nil
+	}
+
 	ok, err := r.Config.Filter.Select(metric)
 	if err != nil {
 		r.log.Errorf("filtering failed: %v", err)
@@ -139,6 +159,13 @@ func (r *RunningInput) MakeMetric(metric telegraf.Metric) telegraf.Metric {
 		makemetric(metric, "", "", "", local, global)
 	}
 
+	// Extra tags and host metadata are added after filtering as they
+	// identify the source of the metric
+	for k, v := range r.Config.ExtraTags {
+		metric.AddTag(k, v)
+	}
+	makemetric(metric, "", "", "", nil, r.Config.HostMetadata)
+
 	r.MetricsGathered.Incr(1)
 	GlobalMetricsGathered.Incr(1)
 	return metric
@@ -164,3 +191,19 @@ func (r *RunningInput) IncrGatherTimeouts() {
 	GlobalGatherTimeouts.Incr(1)
 	r.GatherTimeouts.Incr(1)
 }
+
+// Pause stops the input from producing metrics until resumed. Gathering is
+// skipped for paused inputs while metrics of service inputs are dropped.
+func (r *RunningInput) Pause() {
+	r.paused.Store(true)
+}
+
+// Resume continues producing metrics of a paused input
+func (r *RunningInput) Resume() {
+	r.paused.Store(false)
+}
+
+// Paused returns true if the input is paused
+func (r *RunningInput) Paused() bool {
+	return r.paused.Load()
+}
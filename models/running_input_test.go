@@ -117,6 +117,47 @@ func TestMakeMetricWithPluginTags(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func TestMakeMetricWithExtraTagsAndHostMetadata(t *testing.T) {
+	now := time.Now()
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name:      "TestRunningInput",
+		ExtraTags: map[string]string{"datacenter": "eu-west", "os": "custom"},
+		HostMetadata: map[string]string{
+			"os":               "linux",
+			"arch":             "amd64",
+			"telegraf_version": "1.28.0",
+		},
+		Filter: Filter{TagInclude: []string{"foo"}},
+	})
+	require.NoError(t, ri.Config.Filter.Compile())
+
+	m := testutil.MustMetric("RITest",
+		map[string]string{"foo": "bar", "datacenter": "us-east", "other": "value"},
+		map[string]interface{}{
+			"value": int64(101),
+		},
+		now,
+		telegraf.Untyped)
+	actual := ri.MakeMetric(m)
+
+	// Extra tags override the plugin tags and both are not affected by tag
+	// filtering
+	expected := metric.New("RITest",
+		map[string]string{
+			"foo":              "bar",
+			"datacenter":       "eu-west",
+			"os":               "custom",
+			"arch":             "amd64",
+			"telegraf_version": "1.28.0",
+		},
+		map[string]interface{}{
+			"value": 101,
+		},
+		now,
+	)
+	testutil.RequireMetricEqual(t, expected, actual)
+}
+
 func TestMakeMetricFilteredOut(t *testing.T) {
 	now := time.Now()
 	ri := NewRunningInput(&testInput{}, &InputConfig{
@@ -140,6 +181,27 @@ func TestMakeMetricFilteredOut(t *testing.T) {
 	require.Nil(t, actual)
 }
 
+func TestMakeMetricPaused(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{Name: "TestRunningInput"})
+	require.NoError(t, ri.Config.Filter.Compile())
+
+	m := metric.New("RITest",
+		map[string]string{},
+		map[string]interface{}{
+			"value": int64(101),
+		},
+		time.Now(),
+		telegraf.Untyped)
+
+	ri.Pause()
+	require.True(t, ri.Paused())
+	require.Nil(t, ri.MakeMetric(m.Copy()))
+
+	ri.Resume()
+	require.False(t, ri.Paused())
+	require.NotNil(t, ri.MakeMetric(m))
+}
+
 func TestMakeMetricWithDaemonTags(t *testing.T) {
 	now := time.Now()
 	ri := NewRunningInput(&testInput{}, &InputConfig{
@@ -1,11 +1,14 @@
 package models
 
 import (
+	"errors"
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
@@ -19,10 +22,11 @@ const (
 
 // OutputConfig containing name and filter
 type OutputConfig struct {
-	Name   string
-	Alias  string
-	ID     string
-	Filter Filter
+	Name     string
+	Alias    string
+	ID       string
+	Pipeline string
+	Filter   Filter
 
 	FlushInterval     time.Duration
 	FlushJitter       time.Duration
@@ -32,6 +36,9 @@ type OutputConfig struct {
 	NameOverride string
 	NamePrefix   string
 	NameSuffix   string
+
+	RateLimit RateLimitConfig
+	LogLevel  string
 }
 
 // RunningOutput contains the output configuration
@@ -45,13 +52,24 @@ type RunningOutput struct {
 	MetricBufferLimit int
 	MetricBatchSize   int
 
+	// Serializer configured for the output, it is nil for outputs not
+	// supporting data formats
+	Serializer telegraf.Serializer
+
 	MetricsFiltered selfstat.Stat
 	WriteTime       selfstat.Stat
+	RateLimitTime   selfstat.Stat
 
 	BatchReady chan time.Time
 
-	buffer *Buffer
-	log    telegraf.Logger
+	// closed is closed by Close to interrupt writes waiting for the rate limit
+	closed    chan struct{}
+	closeOnce sync.Once
+
+	buffer  *Buffer
+	limiter *rateLimiter
+	log     telegraf.Logger
+	errors  errorStats
 
 	aggMutex sync.Mutex
 }
@@ -66,6 +84,9 @@ func NewRunningOutput(
 	if config.Alias != "" {
 		tags["alias"] = config.Alias
 	}
+	if config.Pipeline != "" {
+		tags["pipeline"] = config.Pipeline
+	}
 
 	writeErrorsRegister := selfstat.Register("write", "errors", tags)
 	logger := NewLogger("outputs", config.Name, config.Alias)
@@ -88,8 +109,9 @@ func NewRunningOutput(
 	}
 
 	ro := &RunningOutput{
-		buffer:            NewBuffer(config.Name, config.Alias, bufferLimit),
+		buffer:            newBuffer(tags, bufferLimit),
 		BatchReady:        make(chan time.Time, 1),
+		closed:            make(chan struct{}),
 		Output:            output,
 		Config:            config,
 		MetricBufferLimit: bufferLimit,
@@ -104,7 +126,12 @@ func NewRunningOutput(
 			"write_time_ns",
 			tags,
 		),
-		log: logger,
+		limiter: newRateLimiter(config.RateLimit),
+		log:     logger,
+		errors:  logger.errors,
+	}
+	if ro.limiter != nil {
+		ro.RateLimitTime = selfstat.Register("write", "rate_limit_wait_ns", tags)
 	}
 
 	return ro
@@ -210,7 +237,8 @@ func (r *RunningOutput) Write() error {
 
 		err := r.writeMetrics(batch)
 		if err != nil {
-			r.buffer.Reject(batch)
+			r.errors.count(err.Error())
+			r.reject(batch, err)
 			return err
 		}
 		r.buffer.Accept(batch)
@@ -227,7 +255,8 @@ func (r *RunningOutput) WriteBatch() error {
 
 	err := r.writeMetrics(batch)
 	if err != nil {
-		r.buffer.Reject(batch)
+		r.errors.count(err.Error())
+		r.reject(batch, err)
 		return err
 	}
 	r.buffer.Accept(batch)
@@ -235,8 +264,22 @@ func (r *RunningOutput) WriteBatch() error {
 	return nil
 }
 
-// Close closes the output
+// reject returns the metrics of a failed write to the buffer. For partial
+// writes only the failed metrics are returned and all others are accepted.
+func (r *RunningOutput) reject(batch []telegraf.Metric, err error) {
+	var partial *internal.PartialWriteError
+	if errors.As(err, &partial) {
+		r.buffer.AcceptPartial(batch, partial.MetricsFailed)
+		return
+	}
+	r.buffer.Reject(batch)
+}
+
+// Close closes the output. Writes waiting for the rate limit are interrupted
+// and keep their metrics in the buffer.
 func (r *RunningOutput) Close() {
+	r.closeOnce.Do(func() { close(r.closed) })
+
 	err := r.Output.Close()
 	if err != nil {
 		r.log.Errorf("Error closing output: %v", err)
@@ -250,6 +293,21 @@ func (r *RunningOutput) writeMetrics(metrics []telegraf.Metric) error {
 		atomic.StoreInt64(&r.droppedMetrics, 0)
 	}
 
+	if r.limiter != nil {
+		if d := r.limiter.delay(metrics); d > 0 {
+			r.log.Debugf("Rate limit exceeded, delaying write of %d metrics by %s", len(metrics), d)
+			r.RateLimitTime.Incr(d.Nanoseconds())
+
+			timer := time.NewTimer(d)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-r.closed:
+				return errors.New("output closed while waiting for the rate limit")
+			}
+		}
+	}
+
 	start := time.Now()
 	err := r.Output.Write(metrics)
 	elapsed := time.Since(start)
@@ -273,3 +331,33 @@ func (r *RunningOutput) Log() telegraf.Logger {
 func (r *RunningOutput) BufferLength() int {
 	return r.buffer.Len()
 }
+
+// DropOldest drops the given fraction of the buffered metrics starting with
+// the oldest ones and returns the number of dropped metrics.
+func (r *RunningOutput) DropOldest(fraction float64) int {
+	count := int(math.Ceil(float64(r.buffer.Len()) * fraction))
+	if count == 0 {
+		return 0
+	}
+	return r.buffer.DropOldest(count)
+}
+
+// BufferStats contains the statistics of an output's metric buffer
+type BufferStats struct {
+	Size    int64 `json:"size"`
+	Limit   int64 `json:"limit"`
+	Added   int64 `json:"added"`
+	Written int64 `json:"written"`
+	Dropped int64 `json:"dropped"`
+}
+
+// BufferStats returns the current statistics of the metric buffer
+func (r *RunningOutput) BufferStats() BufferStats {
+	return BufferStats{
+		Size:    int64(r.buffer.Len()),
+		Limit:   int64(r.MetricBufferLimit),
+		Added:   r.buffer.MetricsAdded.Get(),
+		Written: r.buffer.MetricsWritten.Get(),
+		Dropped: r.buffer.MetricsDropped.Get(),
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/selfstat"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -318,6 +319,29 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	require.Len(t, m.Metrics(), 10)
 }
 
+func TestRunningOutputErrorStats(t *testing.T) {
+	conf := &OutputConfig{
+		Name:   "test_error_stats",
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput(m, conf, 4, 4)
+
+	// Overflow the buffer by two metrics
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	ro.AddMetric(next5[0])
+	require.Error(t, ro.Write())
+
+	tags := map[string]string{"plugin": "outputs.test_error_stats"}
+	require.Equal(t, int64(1), selfstat.Register("errors", "other", tags).Get())
+	require.Equal(t, int64(2), selfstat.Register("errors", "dropped_metrics", tags).Get())
+	require.Equal(t, int64(0), selfstat.Register("errors", "connection", tags).Get())
+}
+
 // Verify that the order of points is preserved during write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -503,6 +527,113 @@ func TestInternalMetrics(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
 }
 
+func TestRunningOutputPartialWrite(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{failIndices: []int{0, 2}}
+	ro := NewRunningOutput(m, conf, 5, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+
+	// Only the failed metrics are kept for the next write
+	require.Error(t, ro.Write())
+	require.Len(t, m.Metrics(), 3)
+	require.Equal(t, 2, ro.BufferLength())
+
+	m.failIndices = nil
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 5)
+	require.Zero(t, ro.BufferLength())
+}
+
+func TestRunningOutputRateLimit(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		RateLimit: RateLimitConfig{
+			PointsPerSecond: 100,
+			PointsBurst:     5,
+		},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 5, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+
+	// The first batch is covered by the burst the second one has to wait for
+	// the tokens to refill
+	start := time.Now()
+	require.NoError(t, ro.Write())
+	require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	require.Len(t, m.Metrics(), 10)
+	require.Positive(t, ro.RateLimitTime.Get())
+}
+
+func TestRunningOutputRateLimitInterrupted(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+		RateLimit: RateLimitConfig{
+			PointsPerSecond: 0.1,
+			PointsBurst:     5,
+		},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput(m, conf, 5, 10000)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+
+	// The second batch has to wait for a minute, closing the output must
+	// interrupt the wait and keep the metrics in the buffer
+	time.AfterFunc(50*time.Millisecond, ro.Close)
+	start := time.Now()
+	require.ErrorContains(t, ro.Write(), "rate limit")
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.Len(t, m.Metrics(), 5)
+	require.Equal(t, 5, ro.BufferLength())
+}
+
+func TestRateLimiterPointsOnly(t *testing.T) {
+	// The size of the metrics is not estimated without a byte limit
+	limiter := newRateLimiter(RateLimitConfig{PointsPerSecond: 1000})
+	require.NotNil(t, limiter)
+	require.Nil(t, limiter.bytes)
+	require.Nil(t, limiter.serializer)
+	require.Zero(t, limiter.delay(first5))
+}
+
+func TestRateLimiterBytes(t *testing.T) {
+	limiter := newRateLimiter(RateLimitConfig{BytesPerSecond: 1000})
+	require.NotNil(t, limiter)
+	require.Nil(t, limiter.points)
+
+	// The first batch of roughly 250 bytes fits into the burst
+	require.Zero(t, limiter.delay(first5))
+
+	// Exceeding the burst delays the write until enough bytes accumulated
+	batch := make([]telegraf.Metric, 0, 25)
+	for i := 0; i < 5; i++ {
+		batch = append(batch, first5...)
+	}
+	require.Greater(t, limiter.delay(batch), 200*time.Millisecond)
+
+	require.Nil(t, newRateLimiter(RateLimitConfig{}))
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -510,6 +641,9 @@ type mockOutput struct {
 
 	// if true, mock write failure
 	failWrite bool
+
+	// indices of the metrics failing in a partial write
+	failIndices []int
 }
 
 func (m *mockOutput) Connect() error {
@@ -539,6 +673,22 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 		m.metrics = []telegraf.Metric{}
 	}
 
+	if len(m.failIndices) > 0 {
+		failed := make(map[int]bool, len(m.failIndices))
+		for _, i := range m.failIndices {
+			failed[i] = true
+		}
+		for i, metric := range metrics {
+			if !failed[i] {
+				m.metrics = append(m.metrics, metric)
+			}
+		}
+		return &internal.PartialWriteError{
+			Err:           fmt.Errorf("failed write"),
+			MetricsFailed: m.failIndices,
+		}
+	}
+
 	m.metrics = append(m.metrics, metrics...)
 	return nil
 }
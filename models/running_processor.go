@@ -22,11 +22,13 @@ func (rp RunningProcessors) Less(i, j int) bool { return rp[i].Config.Order < rp
 
 // ProcessorConfig containing a name and filter
 type ProcessorConfig struct {
-	Name   string
-	Alias  string
-	ID     string
-	Order  int64
-	Filter Filter
+	Name     string
+	Alias    string
+	ID       string
+	Pipeline string
+	Order    int64
+	Filter   Filter
+	LogLevel string
 }
 
 func NewRunningProcessor(processor telegraf.StreamingProcessor, config *ProcessorConfig) *RunningProcessor {
@@ -34,6 +36,9 @@ func NewRunningProcessor(processor telegraf.StreamingProcessor, config *Processo
 	if config.Alias != "" {
 		tags["alias"] = config.Alias
 	}
+	if config.Pipeline != "" {
+		tags["pipeline"] = config.Pipeline
+	}
 
 	processErrorsRegister := selfstat.Register("process", "errors", tags)
 	logger := NewLogger("processors", config.Name, config.Alias)
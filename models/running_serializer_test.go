@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/plugins/serializers/json"
+)
+
+func TestRunningSerializer_SerializeBatchTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer serializers.Serializer
+		expected   string
+	}{
+		{
+			name:       "batch serializer",
+			serializer: &json.Serializer{},
+			expected:   `{"metrics":[{"fields":{"value":1},"name":"cpu","tags":{},"timestamp":0},{"fields":{"value":2},"name":"cpu","tags":{},"timestamp":0}]}`,
+		},
+		{
+			name:       "fallback",
+			serializer: &influx.Serializer{},
+			expected:   "cpu value=1i 0\ncpu value=2i 0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := models.NewRunningSerializer(tt.serializer, &models.SerializerConfig{
+				Parent:     "TestRunningSerializer_SerializeBatchTo",
+				Alias:      tt.name,
+				DataFormat: "test",
+			})
+			require.NoError(t, rs.Init())
+			require.Implements(t, (*telegraf.BatchSerializer)(nil), rs)
+
+			metrics := []telegraf.Metric{
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+				metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, serializers.SerializeBatchTo(rs, &buf, metrics))
+			require.Equal(t, tt.expected, buf.String())
+			require.Equal(t, int64(2), rs.MetricsSerialized.Get())
+			require.Equal(t, int64(buf.Len()), rs.BytesSerialized.Get())
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package persister
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Backend is a store for the serialized states of the plugins
+type Backend interface {
+	// Read returns the states of the given keys, keys without a stored state
+	// are omitted in the result
+	Read(keys []string) (map[string][]byte, error)
+
+	// Write stores the given states atomically, i.e. either all or none of
+	// the states are written
+	Write(states map[string][]byte) error
+
+	// Close releases the resources of the backend
+	Close() error
+}
+
+// NewBackend creates the backend for the given address. Supported are
+// "file:///path/to/file" for local state files, "redis://" and "rediss://"
+// URLs for Redis servers as well as "etcd://" and "etcds://" URLs for the
+// gRPC gateway of etcd servers.
+func NewBackend(address string) (Backend, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing state store address failed: %w", err)
+	}
+
+	switch u.Scheme {
+	case "file":
+		filename := u.Path
+		if u.Host != "" {
+			filename = u.Host + filename
+		}
+		if filename == "" {
+			return nil, fmt.Errorf("no filename given in state store address %q", address)
+		}
+		return &FileBackend{Filename: filename}, nil
+	case "redis", "rediss":
+		return newRedisBackend(address)
+	case "etcd", "etcds":
+		return newEtcdBackend(u)
+	}
+
+	return nil, fmt.Errorf("unsupported state store scheme %q", u.Scheme)
+}
@@ -0,0 +1,192 @@
+package persister
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// etcdBackend stores the states as individual keys in etcd using the JSON
+// gRPC gateway of the v3 API
+type etcdBackend struct {
+	endpoint string
+	username string
+	password string
+	token    string
+	client   *http.Client
+}
+
+type etcdKeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value,omitempty"`
+}
+
+type etcdRequestOp struct {
+	RequestRange *etcdKeyValue `json:"request_range,omitempty"`
+	RequestPut   *etcdKeyValue `json:"request_put,omitempty"`
+}
+
+type etcdTxnRequest struct {
+	Success []etcdRequestOp `json:"success"`
+}
+
+type etcdTxnResponse struct {
+	Succeeded bool `json:"succeeded"`
+	Responses []struct {
+		ResponseRange struct {
+			Kvs []etcdKeyValue `json:"kvs"`
+		} `json:"response_range"`
+	} `json:"responses"`
+}
+
+func newEtcdBackend(u *url.URL) (*etcdBackend, error) {
+	if u.Host == "" {
+		return nil, fmt.Errorf("no host given in state store address %q", u.Redacted())
+	}
+
+	scheme := "http"
+	if u.Scheme == "etcds" {
+		scheme = "https"
+	}
+	b := &etcdBackend{
+		endpoint: scheme + "://" + u.Host + strings.TrimRight(u.Path, "/"),
+		client:   &http.Client{Timeout: backendTimeout},
+	}
+	if u.User != nil {
+		b.username = u.User.Username()
+		b.password, _ = u.User.Password()
+	}
+
+	return b, nil
+}
+
+func (b *etcdBackend) Read(keys []string) (map[string][]byte, error) {
+	states := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return states, nil
+	}
+
+	// Read all keys in a single transaction to get a consistent view
+	request := etcdTxnRequest{Success: make([]etcdRequestOp, 0, len(keys))}
+	for _, key := range keys {
+		request.Success = append(request.Success, etcdRequestOp{
+			RequestRange: &etcdKeyValue{Key: []byte(key)},
+		})
+	}
+
+	var response etcdTxnResponse
+	if err := b.call("/v3/kv/txn", request, &response); err != nil {
+		return nil, err
+	}
+	for _, r := range response.Responses {
+		for _, kv := range r.ResponseRange.Kvs {
+			states[string(kv.Key)] = kv.Value
+		}
+	}
+
+	return states, nil
+}
+
+func (b *etcdBackend) Write(states map[string][]byte) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	request := etcdTxnRequest{Success: make([]etcdRequestOp, 0, len(states))}
+	for key, state := range states {
+		request.Success = append(request.Success, etcdRequestOp{
+			RequestPut: &etcdKeyValue{Key: []byte(key), Value: state},
+		})
+	}
+
+	var response etcdTxnResponse
+	if err := b.call("/v3/kv/txn", request, &response); err != nil {
+		return err
+	}
+	if !response.Succeeded {
+		return fmt.Errorf("transaction failed")
+	}
+
+	return nil
+}
+
+func (b *etcdBackend) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
+
+// authenticate requests a token for the configured user
+func (b *etcdBackend) authenticate() error {
+	request := map[string]string{"name": b.username, "password": b.password}
+	var response struct {
+		Token string `json:"token"`
+	}
+	b.token = ""
+	if err := b.post("/v3/auth/authenticate", request, &response); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+	b.token = response.Token
+
+	return nil
+}
+
+// call sends the request to the given API endpoint and authenticates first
+// if credentials are configured
+func (b *etcdBackend) call(endpoint string, request, response interface{}) error {
+	if b.username != "" && b.token == "" {
+		if err := b.authenticate(); err != nil {
+			return err
+		}
+	}
+
+	err := b.post(endpoint, request, response)
+	if err != nil && b.username != "" && strings.Contains(err.Error(), "invalid auth token") {
+		// The token expired so authenticate again and retry
+		if err := b.authenticate(); err != nil {
+			return err
+		}
+		err = b.post(endpoint, request, response)
+	}
+
+	return err
+}
+
+func (b *etcdBackend) post(endpoint string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint+endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.token != "" {
+		req.Header.Set("Authorization", b.token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("received status %d (%s): %s", resp.StatusCode, http.StatusText(resp.StatusCode), strings.TrimSpace(string(buf)))
+	}
+
+	return json.Unmarshal(buf, response)
+}
@@ -0,0 +1,76 @@
+package persister
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileBackend stores the states as JSON-encoded map in a local file
+type FileBackend struct {
+	Filename string
+}
+
+func (b *FileBackend) Read(keys []string) (map[string][]byte, error) {
+	in, err := os.ReadFile(b.Filename)
+	if err != nil {
+		return nil, fmt.Errorf("reading states file failed: %w", err)
+	}
+
+	var stored map[string][]byte
+	if err := json.Unmarshal(in, &stored); err != nil {
+		return nil, fmt.Errorf("unmarshalling states failed: %w", err)
+	}
+
+	states := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if state, found := stored[key]; found {
+			states[key] = state
+		}
+	}
+
+	return states, nil
+}
+
+func (b *FileBackend) Write(states map[string][]byte) error {
+	serialized, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("marshalling states failed: %w", err)
+	}
+
+	// Write the states to a temporary file in the same directory and replace
+	// the states file afterwards to never leave a partially written file
+	dir, base := filepath.Split(b.Filename)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, base+".tmp*")
+	if err != nil {
+		return fmt.Errorf("creating temporary states file failed: %w", err)
+	}
+	tmpname := f.Name()
+	defer os.Remove(tmpname)
+
+	if _, err := f.Write(serialized); err != nil {
+		f.Close()
+		return fmt.Errorf("writing states failed: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("syncing states failed: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing temporary states file failed: %w", err)
+	}
+
+	if err := os.Rename(tmpname, b.Filename); err != nil {
+		return fmt.Errorf("replacing states file %q failed: %w", b.Filename, err)
+	}
+
+	return nil
+}
+
+func (b *FileBackend) Close() error {
+	return nil
+}
@@ -0,0 +1,68 @@
+package persister
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// backendTimeout is the maximum duration of a request to a remote backend
+const backendTimeout = 10 * time.Second
+
+// redisBackend stores the states as individual keys in Redis
+type redisBackend struct {
+	client *redis.Client
+}
+
+func newRedisBackend(address string) (*redisBackend, error) {
+	options, err := redis.ParseURL(address)
+	if err != nil {
+		return nil, fmt.Errorf("parsing redis address failed: %w", err)
+	}
+	return &redisBackend{client: redis.NewClient(options)}, nil
+}
+
+func (b *redisBackend) Read(keys []string) (map[string][]byte, error) {
+	states := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return states, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+
+	values, err := b.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		// Non-existing keys are returned as nil
+		if s, ok := v.(string); ok {
+			states[keys[i]] = []byte(s)
+		}
+	}
+
+	return states, nil
+}
+
+func (b *redisBackend) Write(states map[string][]byte) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+	defer cancel()
+
+	// MSET sets all keys atomically
+	values := make([]interface{}, 0, 2*len(states))
+	for key, state := range states {
+		values = append(values, key, state)
+	}
+	return b.client.MSet(ctx, values...).Err()
+}
+
+func (b *redisBackend) Close() error {
+	return b.client.Close()
+}
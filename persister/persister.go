@@ -3,66 +3,87 @@ package persister
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"reflect"
 
 	"github.com/influxdata/telegraf"
 )
 
+// DefaultPrefix is the namespace prefix of the state keys if none is given
+const DefaultPrefix = "telegraf"
+
 type Persister struct {
+	// Filename of the state file used if no backend is given
 	Filename string
+	// Backend to load the states from and store the states to
+	Backend Backend
+	// Prefix of the state keys to allow sharing a store between agents
+	Prefix string
+
+	register map[string]registration
+}
 
-	register map[string]telegraf.StatefulPlugin
+type registration struct {
+	id     string
+	plugin telegraf.StatefulPlugin
 }
 
 func (p *Persister) Init() error {
-	p.register = make(map[string]telegraf.StatefulPlugin)
+	p.register = make(map[string]registration)
+
+	if p.Backend == nil {
+		p.Backend = &FileBackend{Filename: p.Filename}
+	}
 
 	return nil
 }
 
-func (p *Persister) Register(id string, plugin telegraf.StatefulPlugin) error {
-	if _, found := p.register[id]; found {
+// Register adds the plugin with the given name, e.g. "inputs.tail", and ID to
+// the persisted plugins. The state of the plugin is stored under a key
+// namespaced by the prefix and the plugin name.
+func (p *Persister) Register(name, id string, plugin telegraf.StatefulPlugin) error {
+	key := p.key(name, id)
+	if _, found := p.register[key]; found {
 		return fmt.Errorf("plugin with ID %q already registered", id)
 	}
-	p.register[id] = plugin
+	p.register[key] = registration{id: id, plugin: plugin}
 
 	return nil
 }
 
 func (p *Persister) Load() error {
-	// Read the states from disk
-	in, err := os.ReadFile(p.Filename)
-	if err != nil {
-		return fmt.Errorf("reading states file failed: %w", err)
+	// Request the namespaced keys as well as the plain IDs used by previous
+	// versions to be able to restore states written by those
+	keys := make([]string, 0, 2*len(p.register))
+	for key, r := range p.register {
+		keys = append(keys, key, r.id)
 	}
 
-	// Unmarshal the id to serialized states map
-	var states map[string][]byte
-	if err := json.Unmarshal(in, &states); err != nil {
-		return fmt.Errorf("unmarshalling states failed: %w", err)
+	// Read the states from the backend
+	states, err := p.Backend.Read(keys)
+	if err != nil {
+		return fmt.Errorf("reading states failed: %w", err)
 	}
 
-	// Get the initialized state as blueprint for unmarshalling
-	for id, serialized := range states {
-		// Check if we have a plugin with that ID
-		plugin, found := p.register[id]
+	for key, r := range p.register {
+		serialized, found := states[key]
 		if !found {
-			continue
+			if serialized, found = states[r.id]; !found {
+				continue
+			}
 		}
 
 		// Create a new empty state of the "state"-type. As we need a pointer
 		// of the state, we cannot dereference it here due to the unknown
 		// nature of the state-type.
-		nstate := reflect.New(reflect.TypeOf(plugin.GetState())).Interface()
+		nstate := reflect.New(reflect.TypeOf(r.plugin.GetState())).Interface()
 		if err := json.Unmarshal(serialized, &nstate); err != nil {
-			return fmt.Errorf("unmarshalling state for %q failed: %w", id, err)
+			return fmt.Errorf("unmarshalling state for %q failed: %w", r.id, err)
 		}
 		state := reflect.ValueOf(nstate).Elem().Interface()
 
 		// Set the state in the plugin
-		if err := plugin.SetState(state); err != nil {
-			return fmt.Errorf("setting state of %q failed: %w", id, err)
+		if err := r.plugin.SetState(state); err != nil {
+			return fmt.Errorf("setting state of %q failed: %w", r.id, err)
 		}
 	}
 
@@ -70,34 +91,36 @@ func (p *Persister) Load() error {
 }
 
 func (p *Persister) Store() error {
-	states := make(map[string][]byte)
+	states := make(map[string][]byte, len(p.register))
 
 	// Collect the states and serialize the individual data chunks
-	// to later serialize all items in the id / serialized-states map
-	for id, plugin := range p.register {
-		state, err := json.Marshal(plugin.GetState())
+	for key, r := range p.register {
+		state, err := json.Marshal(r.plugin.GetState())
 		if err != nil {
-			return fmt.Errorf("marshalling state for id %q failed: %w", id, err)
+			return fmt.Errorf("marshalling state for id %q failed: %w", r.id, err)
 		}
-		states[id] = state
+		states[key] = state
 	}
 
-	// Serialize the states
-	serialized, err := json.Marshal(states)
-	if err != nil {
-		return fmt.Errorf("marshalling states failed: %w", err)
+	// Write all states at once to avoid inconsistencies between plugins
+	if err := p.Backend.Write(states); err != nil {
+		return fmt.Errorf("writing states failed: %w", err)
 	}
 
-	// Write the states to disk
-	f, err := os.Create(p.Filename)
-	if err != nil {
-		return fmt.Errorf("creating states file %q failed: %w", p.Filename, err)
-	}
-	defer f.Close()
+	return nil
+}
 
-	if _, err := f.Write(serialized); err != nil {
-		return fmt.Errorf("writing states failed: %w", err)
+// Close releases the resources of the backend
+func (p *Persister) Close() error {
+	if p.Backend == nil {
+		return nil
 	}
+	return p.Backend.Close()
+}
 
-	return nil
+func (p *Persister) key(name, id string) string {
+	if p.Prefix == "" {
+		return name + "/" + id
+	}
+	return p.Prefix + "/" + name + "/" + id
 }
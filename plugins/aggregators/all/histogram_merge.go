@@ -0,0 +1,5 @@
+//go:build !custom || aggregators || aggregators.histogram_merge
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/aggregators/histogram_merge" // register plugin
@@ -3,12 +3,17 @@ package histogram
 
 import (
 	_ "embed"
+	"errors"
+	"fmt"
+	"math"
 	"sort"
 	"strconv"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	telegrafConfig "github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 )
 
@@ -41,16 +46,35 @@ type HistogramAggregator struct {
 
 // config is the config, which contains name, field of metric and histogram buckets.
 type config struct {
-	Metric  string   `toml:"measurement_name"`
-	Fields  []string `toml:"fields"`
-	Buckets buckets  `toml:"buckets"`
+	Metric     string   `toml:"measurement_name"`
+	Fields     []string `toml:"fields"`
+	Buckets    buckets  `toml:"buckets"`
+	BucketType string   `toml:"bucket_type"`
+	Start      float64  `toml:"start"`
+	Factor     float64  `toml:"factor"`
+	Count      int      `toml:"count"`
+	Min        float64  `toml:"min"`
+	Max        float64  `toml:"max"`
+	Steps      int      `toml:"steps"`
+	Cumulative *bool    `toml:"cumulative"`
+	Reset      *bool    `toml:"reset"`
+
+	metricFilter filter.Filter
+	fieldFilter  filter.Filter
 }
 
 // bucketsByMetrics contains the buckets grouped by metric and field name
 type bucketsByMetrics map[string]bucketsByFields
 
 // bucketsByFields contains the buckets grouped by field name
-type bucketsByFields map[string]buckets
+type bucketsByFields map[string]*fieldBuckets
+
+// fieldBuckets contains the buckets and output settings of a field
+type fieldBuckets struct {
+	buckets    buckets
+	cumulative bool
+	reset      bool
+}
 
 // buckets contains the right borders buckets
 type buckets []float64
@@ -91,15 +115,48 @@ func (*HistogramAggregator) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *HistogramAggregator) Init() error {
+	for i := range h.Configs {
+		cfg := &h.Configs[i]
+
+		var err error
+		cfg.metricFilter, err = filter.Compile([]string{cfg.Metric})
+		if err != nil {
+			return fmt.Errorf("creating measurement filter for %q failed: %w", cfg.Metric, err)
+		}
+		cfg.fieldFilter, err = filter.Compile(cfg.Fields)
+		if err != nil {
+			return fmt.Errorf("creating field filter for %q failed: %w", cfg.Metric, err)
+		}
+
+		if cfg.BucketType == "" {
+			cfg.BucketType = "explicit"
+		}
+		if err := choice.Check(cfg.BucketType, []string{"explicit", "exponential", "log_linear"}); err != nil {
+			return fmt.Errorf("invalid 'bucket_type' for %q: %w", cfg.Metric, err)
+		}
+		if cfg.BucketType != "explicit" {
+			if len(cfg.Buckets) > 0 {
+				return fmt.Errorf("'buckets' cannot be used with bucket type %q for %q", cfg.BucketType, cfg.Metric)
+			}
+			cfg.Buckets, err = generateBuckets(cfg)
+			if err != nil {
+				return fmt.Errorf("generating buckets for %q failed: %w", cfg.Metric, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Add adds new hit to the buckets
 func (h *HistogramAggregator) Add(in telegraf.Metric) {
 	addTime := timeNow()
 
 	bucketsByField := make(map[string][]float64)
 	for field := range in.Fields() {
-		buckets := h.getBuckets(in.Name(), field)
-		if buckets != nil {
-			bucketsByField[field] = buckets
+		if fb := h.getBuckets(in.Name(), field); fb != nil {
+			bucketsByField[field] = fb.buckets
 		}
 	}
 
@@ -171,10 +228,11 @@ func (h *HistogramAggregator) groupFieldsByBuckets(
 	counts []int64,
 ) {
 	sum := int64(0)
-	buckets := h.getBuckets(name, field) // note that len(buckets) + 1 == len(counts)
+	fb := h.getBuckets(name, field)
+	buckets := fb.buckets // note that len(buckets) + 1 == len(counts)
 
 	for index, count := range counts {
-		if !h.Cumulative {
+		if !fb.cumulative {
 			sum = 0 // reset sum -> don't store cumulative counts
 
 			tags[bucketLeftTag] = bucketNegInf
@@ -221,10 +279,18 @@ func (h *HistogramAggregator) groupField(
 // Reset does nothing by default, because we typically need to collect counts for a long time.
 // Otherwise if config parameter 'reset' has 'true' value, we will get a histogram
 // with a small amount of the distribution. However in some use cases a reset is useful.
+// The 'reset' parameter can be overridden per config so only some of the
+// histograms are reset.
 func (h *HistogramAggregator) Reset() {
-	if h.ResetBuckets {
-		h.resetCache()
-		h.buckets = make(bucketsByMetrics)
+	for id, aggregate := range h.cache {
+		for field := range aggregate.histogramCollection {
+			if fb := h.getBuckets(aggregate.name, field); fb == nil || fb.reset {
+				delete(aggregate.histogramCollection, field)
+			}
+		}
+		if len(aggregate.histogramCollection) == 0 {
+			delete(h.cache, id)
+		}
 	}
 }
 
@@ -233,24 +299,34 @@ func (h *HistogramAggregator) resetCache() {
 	h.cache = make(map[uint64]metricHistogramCollection)
 }
 
-// getBuckets finds buckets and returns them
-func (h *HistogramAggregator) getBuckets(metric string, field string) []float64 {
-	if buckets, ok := h.buckets[metric][field]; ok {
-		return buckets
+// getBuckets finds buckets and returns them. If multiple configs match the
+// field, the last one is used so later configs override earlier ones.
+func (h *HistogramAggregator) getBuckets(metric string, field string) *fieldBuckets {
+	if fb, ok := h.buckets[metric][field]; ok {
+		return fb
 	}
 
 	for _, config := range h.Configs {
-		if config.Metric == metric {
-			if !isBucketExists(field, config) {
-				continue
-			}
+		if !config.metricFilter.Match(metric) || !isBucketExists(field, config) {
+			continue
+		}
 
-			if _, ok := h.buckets[metric]; !ok {
-				h.buckets[metric] = make(bucketsByFields)
-			}
+		if _, ok := h.buckets[metric]; !ok {
+			h.buckets[metric] = make(bucketsByFields)
+		}
 
-			h.buckets[metric][field] = sortBuckets(config.Buckets)
+		fb := &fieldBuckets{
+			buckets:    sortBuckets(config.Buckets),
+			cumulative: h.Cumulative,
+			reset:      h.ResetBuckets,
 		}
+		if config.Cumulative != nil {
+			fb.cumulative = *config.Cumulative
+		}
+		if config.Reset != nil {
+			fb.reset = *config.Reset
+		}
+		h.buckets[metric][field] = fb
 	}
 
 	return h.buckets[metric][field]
@@ -258,17 +334,66 @@ func (h *HistogramAggregator) getBuckets(metric string, field string) []float64
 
 // isBucketExists checks if buckets exists for the passed field
 func isBucketExists(field string, cfg config) bool {
-	if len(cfg.Fields) == 0 {
-		return true
-	}
+	return cfg.fieldFilter == nil || cfg.fieldFilter.Match(field)
+}
 
-	for _, fl := range cfg.Fields {
-		if fl == field {
-			return true
+// generateBuckets creates the bucket borders for the automatic bucket types
+func generateBuckets(cfg *config) (buckets, error) {
+	var borders buckets
+	switch cfg.BucketType {
+	case "exponential":
+		// Buckets growing by a constant factor, i.e. start * factor^i
+		if cfg.Start <= 0 {
+			return nil, errors.New("'start' must be greater than zero")
+		}
+		if cfg.Factor <= 1 {
+			return nil, errors.New("'factor' must be greater than one")
+		}
+		if cfg.Count < 1 {
+			return nil, errors.New("'count' must be at least one")
+		}
+		border := cfg.Start
+		for i := 0; i < cfg.Count; i++ {
+			borders = append(borders, roundBorder(border))
+			border *= cfg.Factor
+		}
+	case "log_linear":
+		// Each power of ten is divided into the given number of linear
+		// steps, e.g. 1, 2, ..., 9, 10, 20, ..., 90, 100 for nine steps
+		if cfg.Min <= 0 {
+			return nil, errors.New("'min' must be greater than zero")
+		}
+		if cfg.Max <= cfg.Min {
+			return nil, errors.New("'max' must be greater than 'min'")
+		}
+		if cfg.Steps < 1 {
+			return nil, errors.New("'steps' must be at least one")
+		}
+		for exponent := math.Floor(math.Log10(cfg.Min)); ; exponent++ {
+			decade := math.Pow(10, exponent)
+			for i := 0; i < cfg.Steps; i++ {
+				border := roundBorder(decade * (1 + 9*float64(i)/float64(cfg.Steps)))
+				if border < cfg.Min {
+					continue
+				}
+				borders = append(borders, border)
+				if border >= cfg.Max {
+					return borders, nil
+				}
+			}
 		}
 	}
 
-	return false
+	return borders, nil
+}
+
+// roundBorder removes floating-point artifacts of computed bucket borders
+func roundBorder(v float64) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 12, 64), 64)
+	if err != nil {
+		return v
+	}
+	return rounded
 }
 
 // sortBuckets sorts the buckets if it is needed
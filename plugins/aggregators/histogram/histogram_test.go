@@ -34,6 +34,9 @@ func NewTestHistogramWithExpirationInterval(
 	htm.Cumulative = cumulative
 	htm.ExpirationInterval = expirationInterval
 	htm.PushOnlyOnUpdate = pushOnlyOnUpdate
+	if err := htm.Init(); err != nil {
+		panic(err)
+	}
 
 	return htm
 }
@@ -419,6 +422,105 @@ func TestWrongBucketsOrder(t *testing.T) {
 	histogram.Add(firstMetric2)
 }
 
+// TestGeneratedBuckets tests the automatic bucket generation
+func TestGeneratedBuckets(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config
+		expected buckets
+	}{
+		{
+			name:     "exponential",
+			cfg:      config{Metric: "m", BucketType: "exponential", Start: 0.1, Factor: 2, Count: 5},
+			expected: buckets{0.1, 0.2, 0.4, 0.8, 1.6},
+		},
+		{
+			name:     "log-linear",
+			cfg:      config{Metric: "m", BucketType: "log_linear", Min: 3, Max: 100, Steps: 9},
+			expected: buckets{3, 4, 5, 6, 7, 8, 9, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+		},
+		{
+			name:     "log-linear fractions",
+			cfg:      config{Metric: "m", BucketType: "log_linear", Min: 0.01, Max: 0.5, Steps: 3},
+			expected: buckets{0.01, 0.04, 0.07, 0.1, 0.4, 0.7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			histogram := NewHistogramAggregator()
+			histogram.Configs = []config{tt.cfg}
+			require.NoError(t, histogram.Init())
+			require.Equal(t, tt.expected, histogram.Configs[0].Buckets)
+		})
+	}
+}
+
+// TestInvalidBucketConfig tests the validation of the bucket settings
+func TestInvalidBucketConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config
+		expected string
+	}{
+		{
+			name:     "unknown type",
+			cfg:      config{Metric: "m", BucketType: "linear"},
+			expected: "invalid 'bucket_type'",
+		},
+		{
+			name:     "buckets with generated type",
+			cfg:      config{Metric: "m", BucketType: "exponential", Buckets: buckets{1}, Start: 1, Factor: 2, Count: 2},
+			expected: "'buckets' cannot be used",
+		},
+		{
+			name:     "exponential without factor",
+			cfg:      config{Metric: "m", BucketType: "exponential", Start: 1, Count: 2},
+			expected: "'factor' must be greater than one",
+		},
+		{
+			name:     "log-linear without max",
+			cfg:      config{Metric: "m", BucketType: "log_linear", Min: 1, Steps: 2},
+			expected: "'max' must be greater than 'min'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			histogram := NewHistogramAggregator()
+			histogram.Configs = []config{tt.cfg}
+			require.ErrorContains(t, histogram.Init(), tt.expected)
+		})
+	}
+}
+
+// TestHistogramOverrides tests glob matching and overriding settings per config
+func TestHistogramOverrides(t *testing.T) {
+	noReset := false
+	nonCumulative := false
+	cfg := []config{
+		{Metric: "*_metric_name", BucketType: "exponential", Start: 10, Factor: 2, Count: 2},
+		{Metric: "first_*", Fields: []string{"c"}, Buckets: []float64{50}, Cumulative: &nonCumulative, Reset: &noReset},
+	}
+	histogram := NewTestHistogram(cfg, true, true, false)
+
+	acc := &testutil.Accumulator{}
+	histogram.Add(firstMetric1)
+	histogram.Add(firstMetric2)
+	histogram.Reset()
+	histogram.Add(firstMetric2)
+	histogram.Push(acc)
+
+	// Field "a" uses the generic buckets and is reset, field "c" uses the
+	// override which is not reset and not cumulative
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(0)}, tags{bucketRightTag: "10"})
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(1)}, tags{bucketRightTag: "20"})
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"a_bucket": int64(1)}, tags{bucketRightTag: bucketPosInf})
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"c_bucket": int64(2)}, tags{bucketLeftTag: bucketNegInf, bucketRightTag: "50"})
+	assertContainsTaggedField(t, acc, "first_metric_name", fields{"c_bucket": int64(0)}, tags{bucketLeftTag: "50", bucketRightTag: bucketPosInf})
+	require.Len(t, acc.Metrics, 5, "Incorrect number of metrics")
+}
+
 // TestHistogram tests two metrics getting added and metric expiration
 func TestHistogramMetricExpiration(t *testing.T) {
 	currentTime := time.Unix(10, 0)
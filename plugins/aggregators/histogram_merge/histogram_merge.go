@@ -0,0 +1,341 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package histogram_merge
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type HistogramMerge struct {
+	SourceTags    []string        `toml:"source_tags"`
+	SourceTimeout config.Duration `toml:"source_timeout"`
+	Log           telegraf.Logger `toml:"-"`
+
+	groups map[string]*group
+}
+
+// group is the merged histogram of all sources with the same name, field and
+// tags except for the source tags
+type group struct {
+	name    string
+	field   string
+	tags    map[string]string
+	native  bool
+	sources map[string]*source
+	latest  time.Time
+	updated bool
+}
+
+// source contains the state of the histogram of a single source
+type source struct {
+	count   counter
+	sum     counter
+	buckets map[float64]*counter
+	seen    time.Time
+}
+
+// counter is a cumulative value compensating for counter resets by
+// accumulating the values seen before each reset
+type counter struct {
+	last   float64
+	offset float64
+	valid  bool
+}
+
+func (*HistogramMerge) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *HistogramMerge) Init() error {
+	if len(h.SourceTags) == 0 {
+		return errors.New("no 'source_tags' specified")
+	}
+	if h.SourceTimeout <= 0 {
+		return errors.New("'source_timeout' must be greater than zero")
+	}
+	h.groups = make(map[string]*group)
+	return nil
+}
+
+func (h *HistogramMerge) Add(m telegraf.Metric) {
+	now := time.Now()
+
+	// Native histograms containing all buckets in a single field
+	for _, field := range m.FieldList() {
+		v, ok := field.Value.(*telegraf.HistogramValue)
+		if !ok {
+			continue
+		}
+		s := h.source(m, field.Key, true, now)
+		reset := s.count.update(float64(v.Count))
+		s.sum.follow(v.Sum, reset)
+		for _, b := range v.Buckets {
+			s.bucket(b.UpperBound).update(float64(b.Count))
+		}
+	}
+
+	// Prometheus style histograms with one metric per bucket and one metric
+	// for count and sum
+	if m.Type() != telegraf.Histogram {
+		return
+	}
+	if le, found := m.GetTag("le"); found {
+		bound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			h.Log.Debugf("Ignoring bucket with invalid bound %q of %q: %v", le, m.Name(), err)
+			return
+		}
+		for _, field := range m.FieldList() {
+			name, found := strings.CutSuffix(field.Key, "_bucket")
+			if !found {
+				continue
+			}
+			v, err := internal.ToFloat64(field.Value)
+			if err != nil {
+				continue
+			}
+			h.source(m, name, false, now).bucket(bound).update(v)
+		}
+		return
+	}
+	for _, field := range m.FieldList() {
+		name, found := strings.CutSuffix(field.Key, "_count")
+		if !found {
+			continue
+		}
+		count, err := internal.ToFloat64(field.Value)
+		if err != nil {
+			continue
+		}
+		s := h.source(m, name, false, now)
+		reset := s.count.update(count)
+		if raw, found := m.GetField(name + "_sum"); found {
+			if sum, err := internal.ToFloat64(raw); err == nil {
+				s.sum.follow(sum, reset)
+			}
+		}
+	}
+}
+
+func (h *HistogramMerge) Push(acc telegraf.Accumulator) {
+	// Preserve timestamp of original metric
+	acc.SetPrecision(time.Nanosecond)
+
+	now := time.Now()
+	for key, g := range h.groups {
+		for id, s := range g.sources {
+			if now.Sub(s.seen) > time.Duration(h.SourceTimeout) {
+				delete(g.sources, id)
+			}
+		}
+		if len(g.sources) == 0 {
+			delete(h.groups, key)
+			continue
+		}
+		if g.updated {
+			h.emit(acc, g)
+		}
+	}
+}
+
+func (h *HistogramMerge) Reset() {
+	for _, g := range h.groups {
+		g.updated = false
+	}
+}
+
+// source returns the state of the metric's source creating it if necessary
+func (h *HistogramMerge) source(m telegraf.Metric, field string, native bool, now time.Time) *source {
+	tags := m.Tags()
+	delete(tags, "le")
+	values := make([]string, 0, len(h.SourceTags))
+	for _, tag := range h.SourceTags {
+		values = append(values, tags[tag])
+		delete(tags, tag)
+	}
+
+	key := groupKey(m.Name(), field, native, tags)
+	g, found := h.groups[key]
+	if !found {
+		g = &group{
+			name:    m.Name(),
+			field:   field,
+			tags:    tags,
+			native:  native,
+			sources: make(map[string]*source),
+		}
+		h.groups[key] = g
+	}
+	g.updated = true
+	if m.Time().After(g.latest) {
+		g.latest = m.Time()
+	}
+
+	id := strings.Join(values, "\x00")
+	s, found := g.sources[id]
+	if !found {
+		s = &source{buckets: make(map[float64]*counter)}
+		g.sources[id] = s
+	}
+	s.seen = now
+	return s
+}
+
+// emit adds the merged histogram of the group to the accumulator
+func (h *HistogramMerge) emit(acc telegraf.Accumulator, g *group) {
+	// Only keep the bucket boundaries present in all sources as the counts
+	// for other boundaries cannot be determined exactly
+	var withBuckets int
+	occurrences := make(map[float64]int)
+	for _, s := range g.sources {
+		if len(s.buckets) == 0 {
+			continue
+		}
+		withBuckets++
+		for bound := range s.buckets {
+			occurrences[bound]++
+		}
+	}
+	bounds := make([]float64, 0, len(occurrences))
+	for bound, n := range occurrences {
+		if n == withBuckets {
+			bounds = append(bounds, bound)
+		}
+	}
+	if len(bounds) < len(occurrences) {
+		h.Log.Debugf("Dropping %d bucket(s) of %q not present in all sources", len(occurrences)-len(bounds), g.field)
+	}
+	sort.Float64s(bounds)
+
+	var count, sum float64
+	var hasCount bool
+	buckets := make([]float64, len(bounds))
+	for _, s := range g.sources {
+		if s.count.valid {
+			hasCount = true
+			count += s.count.value()
+			sum += s.sum.value()
+		}
+		for i, bound := range bounds {
+			if c, found := s.buckets[bound]; found {
+				buckets[i] += c.value()
+			}
+		}
+	}
+
+	if g.native {
+		v := &telegraf.HistogramValue{
+			Count:   uint64(count),
+			Sum:     sum,
+			Buckets: make([]telegraf.Bucket, 0, len(bounds)),
+		}
+		for i, bound := range bounds {
+			v.Buckets = append(v.Buckets, telegraf.Bucket{UpperBound: bound, Count: uint64(buckets[i])})
+		}
+		acc.AddHistogram(g.name, map[string]interface{}{g.field: v}, g.tags, g.latest)
+		return
+	}
+
+	if hasCount {
+		fields := map[string]interface{}{
+			g.field + "_count": count,
+			g.field + "_sum":   sum,
+		}
+		acc.AddHistogram(g.name, fields, g.tags, g.latest)
+	}
+	for i, bound := range bounds {
+		tags := make(map[string]string, len(g.tags)+1)
+		for k, v := range g.tags {
+			tags[k] = v
+		}
+		tags["le"] = formatBound(bound)
+		acc.AddHistogram(g.name, map[string]interface{}{g.field + "_bucket": buckets[i]}, tags, g.latest)
+	}
+}
+
+func (s *source) bucket(bound float64) *counter {
+	c, found := s.buckets[bound]
+	if !found {
+		c = &counter{}
+		s.buckets[bound] = c
+	}
+	return c
+}
+
+// update sets the current value and returns true if a counter reset was
+// detected, i.e. the value decreased
+func (c *counter) update(v float64) bool {
+	reset := c.valid && v < c.last
+	if reset {
+		c.offset += c.last
+	}
+	c.last, c.valid = v, true
+	return reset
+}
+
+// follow sets the current value of counters which might legitimately
+// decrease, like the sum of negative observations, using an external reset
+// indication
+func (c *counter) follow(v float64, reset bool) {
+	if reset {
+		c.offset += c.last
+	}
+	c.last, c.valid = v, true
+}
+
+func (c *counter) value() float64 {
+	return c.offset + c.last
+}
+
+// groupKey builds a unique key of the name, field and tags
+func groupKey(name, field string, native bool, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte(0)
+	b.WriteString(field)
+	b.WriteByte(0)
+	b.WriteString(strconv.FormatBool(native))
+	for _, k := range keys {
+		b.WriteByte(0)
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(tags[k])
+	}
+	return b.String()
+}
+
+// formatBound formats the bucket boundary like the prometheus parser
+func formatBound(bound float64) string {
+	if math.IsInf(bound, 1) {
+		return "+Inf"
+	}
+	return fmt.Sprint(bound)
+}
+
+func init() {
+	aggregators.Add("histogram_merge", func() telegraf.Aggregator {
+		return &HistogramMerge{
+			SourceTimeout: config.Duration(5 * time.Minute),
+		}
+	})
+}
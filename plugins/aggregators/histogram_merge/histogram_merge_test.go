@@ -0,0 +1,208 @@
+package histogram_merge
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPlugin(t *testing.T) *HistogramMerge {
+	plugin := &HistogramMerge{
+		SourceTags:    []string{"pod"},
+		SourceTimeout: config.Duration(5 * time.Minute),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	return plugin
+}
+
+// histogram creates the metrics of a histogram in prometheus metric_version 2
+// format with the given cumulative bucket counts
+func histogram(tags map[string]string, count, sum float64, buckets map[string]float64, ts time.Time) []telegraf.Metric {
+	metrics := []telegraf.Metric{
+		metric.New("prometheus", tags, map[string]interface{}{"latency_count": count, "latency_sum": sum}, ts, telegraf.Histogram),
+	}
+	for le, v := range buckets {
+		bucketTags := map[string]string{"le": le}
+		for k, v := range tags {
+			bucketTags[k] = v
+		}
+		metrics = append(metrics, metric.New("prometheus", bucketTags, map[string]interface{}{"latency_bucket": v}, ts, telegraf.Histogram))
+	}
+	return metrics
+}
+
+func TestInitErrors(t *testing.T) {
+	plugin := &HistogramMerge{SourceTimeout: config.Duration(time.Minute)}
+	require.ErrorContains(t, plugin.Init(), "no 'source_tags' specified")
+
+	plugin = &HistogramMerge{SourceTags: []string{"pod"}}
+	require.ErrorContains(t, plugin.Init(), "'source_timeout' must be greater than zero")
+}
+
+func TestMerge(t *testing.T) {
+	plugin := newPlugin(t)
+
+	ts := time.Unix(1682700000, 0)
+	var input []telegraf.Metric
+	input = append(input, histogram(map[string]string{"pod": "a", "app": "x"}, 3, 1.5, map[string]float64{"0.5": 2, "+Inf": 3}, ts)...)
+	input = append(input, histogram(map[string]string{"pod": "b", "app": "x"}, 5, 4, map[string]float64{"0.5": 1, "+Inf": 5}, ts.Add(time.Second))...)
+	input = append(input, histogram(map[string]string{"pod": "c", "app": "y"}, 1, 0.1, map[string]float64{"0.5": 1, "+Inf": 1}, ts)...)
+
+	// Metrics not being histograms are ignored
+	input = append(input, metric.New("prometheus", map[string]string{"pod": "a"}, map[string]interface{}{"latency_count": 42.0}, ts))
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("prometheus", map[string]string{"app": "x"}, map[string]interface{}{"latency_count": 8.0, "latency_sum": 5.5},
+			ts.Add(time.Second), telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"app": "x", "le": "0.5"}, map[string]interface{}{"latency_bucket": 3.0},
+			ts.Add(time.Second), telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"app": "x", "le": "+Inf"}, map[string]interface{}{"latency_bucket": 8.0},
+			ts.Add(time.Second), telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"app": "y"}, map[string]interface{}{"latency_count": 1.0, "latency_sum": 0.1},
+			ts, telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"app": "y", "le": "0.5"}, map[string]interface{}{"latency_bucket": 1.0},
+			ts, telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"app": "y", "le": "+Inf"}, map[string]interface{}{"latency_bucket": 1.0},
+			ts, telegraf.Histogram),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Groups without update in the period are not emitted
+	plugin.Reset()
+	acc.ClearMetrics()
+	plugin.Push(&acc)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestCounterReset(t *testing.T) {
+	plugin := newPlugin(t)
+
+	ts := time.Unix(1682700000, 0)
+	for _, m := range histogram(map[string]string{"pod": "a"}, 10, 5, map[string]float64{"1": 8, "+Inf": 10}, ts) {
+		plugin.Add(m)
+	}
+	for _, m := range histogram(map[string]string{"pod": "b"}, 4, 2, map[string]float64{"1": 4, "+Inf": 4}, ts) {
+		plugin.Add(m)
+	}
+	plugin.Reset()
+
+	// Pod "a" restarted and reports lower counts
+	for _, m := range histogram(map[string]string{"pod": "a"}, 2, 1, map[string]float64{"1": 1, "+Inf": 2}, ts.Add(time.Minute)) {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("prometheus", map[string]string{}, map[string]interface{}{"latency_count": 16.0, "latency_sum": 8.0},
+			ts.Add(time.Minute), telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"le": "1"}, map[string]interface{}{"latency_bucket": 13.0},
+			ts.Add(time.Minute), telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"le": "+Inf"}, map[string]interface{}{"latency_bucket": 16.0},
+			ts.Add(time.Minute), telegraf.Histogram),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestBoundaryMismatch(t *testing.T) {
+	plugin := newPlugin(t)
+
+	ts := time.Unix(1682700000, 0)
+	for _, m := range histogram(map[string]string{"pod": "a"}, 3, 1, map[string]float64{"0.1": 1, "1": 2, "+Inf": 3}, ts) {
+		plugin.Add(m)
+	}
+	for _, m := range histogram(map[string]string{"pod": "b"}, 3, 1, map[string]float64{"1": 3, "+Inf": 3}, ts) {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	// The "0.1" bucket is only present in one source and thus dropped
+	expected := []telegraf.Metric{
+		metric.New("prometheus", map[string]string{}, map[string]interface{}{"latency_count": 6.0, "latency_sum": 2.0}, ts, telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"le": "1"}, map[string]interface{}{"latency_bucket": 5.0}, ts, telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"le": "+Inf"}, map[string]interface{}{"latency_bucket": 6.0}, ts, telegraf.Histogram),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestSourceTimeout(t *testing.T) {
+	plugin := newPlugin(t)
+
+	ts := time.Unix(1682700000, 0)
+	for _, m := range histogram(map[string]string{"pod": "a"}, 3, 1, map[string]float64{"+Inf": 3}, ts) {
+		plugin.Add(m)
+	}
+	for _, m := range histogram(map[string]string{"pod": "b"}, 5, 1, map[string]float64{"+Inf": 5}, ts) {
+		plugin.Add(m)
+	}
+
+	// Pretend pod "b" was not seen for a long time
+	for _, g := range plugin.groups {
+		g.sources["b"].seen = time.Now().Add(-time.Hour)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("prometheus", map[string]string{}, map[string]interface{}{"latency_count": 3.0, "latency_sum": 1.0}, ts, telegraf.Histogram),
+		metric.New("prometheus", map[string]string{"le": "+Inf"}, map[string]interface{}{"latency_bucket": 3.0}, ts, telegraf.Histogram),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestNativeHistogram(t *testing.T) {
+	plugin := newPlugin(t)
+
+	ts := time.Unix(1682700000, 0)
+	input := []telegraf.Metric{
+		metric.New("http", map[string]string{"pod": "a"}, map[string]interface{}{
+			"latency": &telegraf.HistogramValue{
+				Count:   3,
+				Sum:     1.5,
+				Buckets: []telegraf.Bucket{{UpperBound: 0.5, Count: 2}, {UpperBound: math.Inf(1), Count: 3}},
+			},
+		}, ts, telegraf.Histogram),
+		metric.New("http", map[string]string{"pod": "b"}, map[string]interface{}{
+			"latency": &telegraf.HistogramValue{
+				Count:   5,
+				Sum:     4,
+				Buckets: []telegraf.Bucket{{UpperBound: 0.5, Count: 1}, {UpperBound: math.Inf(1), Count: 5}},
+			},
+		}, ts, telegraf.Histogram),
+	}
+	for _, m := range input {
+		plugin.Add(m)
+	}
+
+	var acc testutil.Accumulator
+	plugin.Push(&acc)
+
+	expected := []telegraf.Metric{
+		metric.New("http", map[string]string{}, map[string]interface{}{
+			"latency": &telegraf.HistogramValue{
+				Count:   8,
+				Sum:     5.5,
+				Buckets: []telegraf.Bucket{{UpperBound: 0.5, Count: 3}, {UpperBound: math.Inf(1), Count: 8}},
+			},
+		}, ts, telegraf.Histogram),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
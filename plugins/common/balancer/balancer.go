@@ -0,0 +1,199 @@
+package balancer
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+)
+
+// BalancingConfig contains the settings for distributing writes across multiple
+// endpoints of an output
+type BalancingConfig struct {
+	LoadBalancing    string          `toml:"load_balancing"`
+	FailureThreshold int             `toml:"endpoint_failure_threshold"`
+	EjectionTime     config.Duration `toml:"endpoint_ejection_time"`
+}
+
+// Balancer selects the endpoints to write to and tracks their health.
+// Endpoints failing the configured number of consecutive writes are ejected
+// and only used as last resort until the ejection time passed. After that
+// the endpoint is readmitted, a single failure ejects it again.
+type Balancer struct {
+	strategy  string
+	threshold int
+	ejection  time.Duration
+	log       telegraf.Logger
+
+	endpoints []*endpoint
+	ring      *Ring
+	next      int
+	mu        sync.Mutex
+}
+
+type endpoint struct {
+	name         string
+	failures     int
+	ejected      bool
+	ejectedUntil time.Time
+}
+
+type group struct {
+	order   []int
+	metrics []telegraf.Metric
+	indices []int
+}
+
+// NewBalancer creates a balancer for the given endpoint names
+func (cfg *BalancingConfig) NewBalancer(endpoints []string, log telegraf.Logger) (*Balancer, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("no endpoints given")
+	}
+
+	strategy := cfg.LoadBalancing
+	switch strategy {
+	case "":
+		strategy = "random"
+	case "random", "round_robin", "hash_series":
+	default:
+		return nil, fmt.Errorf("invalid load_balancing strategy %q", cfg.LoadBalancing)
+	}
+
+	b := &Balancer{
+		strategy:  strategy,
+		threshold: cfg.FailureThreshold,
+		ejection:  time.Duration(cfg.EjectionTime),
+		log:       log,
+		endpoints: make([]*endpoint, 0, len(endpoints)),
+		ring:      NewRing(endpoints),
+	}
+	for _, name := range endpoints {
+		b.endpoints = append(b.endpoints, &endpoint{name: name})
+	}
+	return b, nil
+}
+
+// Write distributes the metrics across the endpoints and calls the write
+// function with the endpoint index for each group of metrics. Failed writes
+// are retried on the next endpoint until one succeeds. The returned error
+// contains the last error of each group not written to any endpoint. If only
+// some of the groups failed, an internal.PartialWriteError with the indices of
+// the failed metrics is returned to not write the other groups again.
+func (b *Balancer) Write(metrics []telegraf.Metric, write func(int, []telegraf.Metric) error) error {
+	var errs []error
+	var failed []int
+	for _, g := range b.distribute(metrics, time.Now()) {
+		if err := b.writeGroup(g, write); err != nil {
+			errs = append(errs, err)
+			failed = append(failed, g.indices...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	err := errors.Join(errs...)
+	if len(failed) == len(metrics) {
+		return err
+	}
+	sort.Ints(failed)
+	return &internal.PartialWriteError{Err: err, MetricsFailed: failed}
+}
+
+func (b *Balancer) writeGroup(g group, write func(int, []telegraf.Metric) error) error {
+	var err error
+	for _, idx := range g.order {
+		err = write(idx, g.metrics)
+		b.done(idx, err)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// distribute returns the groups of metrics with the order of endpoints to
+// try for each group
+func (b *Balancer) distribute(metrics []telegraf.Metric, now time.Time) []group {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.strategy != "hash_series" {
+		indices := make([]int, 0, len(metrics))
+		for i := range metrics {
+			indices = append(indices, i)
+		}
+		return []group{{order: b.order(now), metrics: metrics, indices: indices}}
+	}
+
+	// Use rendezvous hashing for a stable assignment of series to endpoints.
+	// Ejecting an endpoint only moves its own series to the other endpoints.
+	groups := make([]group, 0, len(b.endpoints))
+	index := make(map[string]int, len(b.endpoints))
+	for j, m := range metrics {
+		order := b.healthyFirst(b.ring.Order(m.HashID()), now)
+		key := fmt.Sprint(order)
+		i, found := index[key]
+		if !found {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group{order: order})
+		}
+		groups[i].metrics = append(groups[i].metrics, m)
+		groups[i].indices = append(groups[i].indices, j)
+	}
+	return groups
+}
+
+// order returns the endpoint indices to try for the non-hashing strategies
+func (b *Balancer) order(now time.Time) []int {
+	var order []int
+	switch b.strategy {
+	case "random":
+		order = rand.Perm(len(b.endpoints))
+	case "round_robin":
+		order = make([]int, 0, len(b.endpoints))
+		for i := range b.endpoints {
+			order = append(order, (b.next+i)%len(b.endpoints))
+		}
+		b.next = (b.next + 1) % len(b.endpoints)
+	}
+	return b.healthyFirst(order, now)
+}
+
+// healthyFirst moves the ejected endpoints to the end keeping the order
+func (b *Balancer) healthyFirst(order []int, now time.Time) []int {
+	sort.SliceStable(order, func(i, j int) bool {
+		return !b.endpoints[order[i]].ejectedUntil.After(now) && b.endpoints[order[j]].ejectedUntil.After(now)
+	})
+	return order
+}
+
+func (b *Balancer) done(idx int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e := b.endpoints[idx]
+	if err == nil {
+		if e.ejected {
+			b.log.Infof("Endpoint %q readmitted", e.name)
+		}
+		e.failures = 0
+		e.ejected = false
+		e.ejectedUntil = time.Time{}
+		return
+	}
+
+	e.failures++
+	if b.threshold > 0 && e.failures >= b.threshold {
+		e.ejected = true
+		e.ejectedUntil = time.Now().Add(b.ejection)
+		b.log.Warnf("Ejecting endpoint %q for %s after %d consecutive failures", e.name, b.ejection, e.failures)
+	}
+}
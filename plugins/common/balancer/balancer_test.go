@@ -0,0 +1,213 @@
+package balancer
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func series(n int) []telegraf.Metric {
+	metrics := make([]telegraf.Metric, 0, n)
+	for i := 0; i < n; i++ {
+		metrics = append(metrics, metric.New(
+			"test",
+			map[string]string{"id": strconv.Itoa(i)},
+			map[string]interface{}{"value": i},
+			time.Unix(0, 0),
+		))
+	}
+	return metrics
+}
+
+func TestInvalidStrategy(t *testing.T) {
+	cfg := &BalancingConfig{LoadBalancing: "fastest"}
+	_, err := cfg.NewBalancer([]string{"a"}, testutil.Logger{})
+	require.ErrorContains(t, err, "invalid load_balancing strategy")
+
+	cfg = &BalancingConfig{}
+	_, err = cfg.NewBalancer(nil, testutil.Logger{})
+	require.ErrorContains(t, err, "no endpoints given")
+}
+
+func TestRoundRobin(t *testing.T) {
+	cfg := &BalancingConfig{LoadBalancing: "round_robin"}
+	b, err := cfg.NewBalancer([]string{"a", "b", "c"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	var written []int
+	for i := 0; i < 4; i++ {
+		require.NoError(t, b.Write(series(1), func(idx int, _ []telegraf.Metric) error {
+			written = append(written, idx)
+			return nil
+		}))
+	}
+	require.Equal(t, []int{0, 1, 2, 0}, written)
+}
+
+func TestFailoverAndEjection(t *testing.T) {
+	cfg := &BalancingConfig{
+		LoadBalancing:    "round_robin",
+		FailureThreshold: 2,
+		EjectionTime:     config.Duration(time.Hour),
+	}
+	b, err := cfg.NewBalancer([]string{"a", "b"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	var attempts []int
+	write := func(idx int, _ []telegraf.Metric) error {
+		attempts = append(attempts, idx)
+		if idx == 0 {
+			return errors.New("down")
+		}
+		return nil
+	}
+
+	// Endpoint "a" fails twice and is ejected afterwards
+	for i := 0; i < 4; i++ {
+		require.NoError(t, b.Write(series(1), write))
+	}
+	require.Equal(t, []int{0, 1, 1, 0, 1, 1}, attempts)
+	require.True(t, b.endpoints[0].ejected)
+
+	// Ejected endpoints are still used if all others fail
+	attempts = nil
+	err = b.Write(series(1), func(idx int, _ []telegraf.Metric) error {
+		attempts = append(attempts, idx)
+		return errors.New("down")
+	})
+	require.ErrorContains(t, err, "down")
+	require.Equal(t, []int{1, 0}, attempts)
+}
+
+func TestReadmission(t *testing.T) {
+	cfg := &BalancingConfig{
+		LoadBalancing:    "round_robin",
+		FailureThreshold: 1,
+		EjectionTime:     config.Duration(time.Millisecond),
+	}
+	b, err := cfg.NewBalancer([]string{"a", "b"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	require.NoError(t, b.Write(series(1), func(idx int, _ []telegraf.Metric) error {
+		if idx == 0 {
+			return errors.New("down")
+		}
+		return nil
+	}))
+	require.True(t, b.endpoints[0].ejected)
+
+	time.Sleep(5 * time.Millisecond)
+	var written []int
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Write(series(1), func(idx int, _ []telegraf.Metric) error {
+			written = append(written, idx)
+			return nil
+		}))
+	}
+	require.ElementsMatch(t, []int{0, 1}, written)
+	require.False(t, b.endpoints[0].ejected)
+	require.Zero(t, b.endpoints[0].failures)
+}
+
+func TestHashSeries(t *testing.T) {
+	cfg := &BalancingConfig{
+		LoadBalancing:    "hash_series",
+		FailureThreshold: 1,
+		EjectionTime:     config.Duration(time.Hour),
+	}
+	b, err := cfg.NewBalancer([]string{"a", "b", "c"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	metrics := series(300)
+	assignment := make(map[uint64]int)
+	require.NoError(t, b.Write(metrics, func(idx int, batch []telegraf.Metric) error {
+		for _, m := range batch {
+			assignment[m.HashID()] = idx
+		}
+		return nil
+	}))
+	require.Len(t, assignment, 300)
+
+	// All endpoints receive a share of the series
+	counts := make(map[int]int)
+	for _, idx := range assignment {
+		counts[idx]++
+	}
+	require.Len(t, counts, 3)
+
+	// The assignment is stable
+	require.NoError(t, b.Write(metrics, func(idx int, batch []telegraf.Metric) error {
+		for _, m := range batch {
+			require.Equal(t, assignment[m.HashID()], idx)
+		}
+		return nil
+	}))
+
+	// Failing an endpoint only moves its own series
+	require.NoError(t, b.Write(metrics, func(idx int, batch []telegraf.Metric) error {
+		if idx == 0 {
+			return errors.New("down")
+		}
+		return nil
+	}))
+	require.NoError(t, b.Write(metrics, func(idx int, batch []telegraf.Metric) error {
+		require.NotEqual(t, 0, idx)
+		for _, m := range batch {
+			if previous := assignment[m.HashID()]; previous != 0 {
+				require.Equal(t, previous, idx)
+			}
+		}
+		return nil
+	}))
+}
+
+func TestHashSeriesPartialWrite(t *testing.T) {
+	cfg := &BalancingConfig{LoadBalancing: "hash_series"}
+	b, err := cfg.NewBalancer([]string{"a", "b", "c"}, testutil.Logger{})
+	require.NoError(t, err)
+
+	// All endpoints fail for the series of the first endpoint so only those
+	// metrics must be reported as failed
+	metrics := series(30)
+	primary := make(map[uint64]int)
+	require.NoError(t, b.Write(metrics, func(idx int, batch []telegraf.Metric) error {
+		for _, m := range batch {
+			primary[m.HashID()] = idx
+		}
+		return nil
+	}))
+
+	var expected []int
+	for i, m := range metrics {
+		if primary[m.HashID()] == 0 {
+			expected = append(expected, i)
+		}
+	}
+	require.NotEmpty(t, expected)
+
+	err = b.Write(metrics, func(_ int, batch []telegraf.Metric) error {
+		if primary[batch[0].HashID()] == 0 {
+			return errors.New("down")
+		}
+		return nil
+	})
+	var partial *internal.PartialWriteError
+	require.ErrorAs(t, err, &partial)
+	require.Equal(t, expected, partial.MetricsFailed)
+
+	// Failing all groups is no partial write
+	err = b.Write(metrics, func(int, []telegraf.Metric) error {
+		return errors.New("down")
+	})
+	require.ErrorContains(t, err, "down")
+	require.False(t, errors.As(err, &partial))
+}
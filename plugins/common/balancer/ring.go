@@ -0,0 +1,55 @@
+package balancer
+
+import (
+	"hash/fnv"
+	"sort"
+)
+
+// Ring assigns keys to endpoints using rendezvous hashing. Each key has a
+// stable order of preference over the endpoints, so removing an endpoint only
+// moves the keys preferring that endpoint and adding one only moves keys to
+// the new endpoint.
+type Ring struct {
+	seeds []uint64
+}
+
+// NewRing creates a ring for the given endpoint names
+func NewRing(endpoints []string) *Ring {
+	r := &Ring{seeds: make([]uint64, 0, len(endpoints))}
+	for _, name := range endpoints {
+		r.seeds = append(r.seeds, HashKey(name))
+	}
+	return r
+}
+
+// Order returns the endpoint indices in the order of preference for the key
+func (r *Ring) Order(key uint64) []int {
+	scores := make([]uint64, len(r.seeds))
+	order := make([]int, 0, len(r.seeds))
+	for i, seed := range r.seeds {
+		scores[i] = mix(key ^ seed)
+		order = append(order, i)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return scores[order[i]] > scores[order[j]]
+	})
+	return order
+}
+
+// HashKey returns the hash of a string key for use with the ring
+func HashKey(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
+
+// mix is the finalizer of the SplitMix64 generator spreading the bits of the
+// combined key and endpoint hash
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,40 @@
+package balancer
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	endpoints := []string{"relay1:2004", "relay2:2004", "relay3:2004"}
+	ring := NewRing(endpoints)
+
+	// Each key is routed consistently and all endpoints get a share
+	counts := make(map[int]int)
+	for i := 0; i < 3000; i++ {
+		key := HashKey("servers.host" + strconv.Itoa(i) + ".cpu.usage")
+		order := ring.Order(key)
+		require.Equal(t, order, NewRing(endpoints).Order(key))
+		require.ElementsMatch(t, []int{0, 1, 2}, order)
+		counts[order[0]]++
+	}
+	require.Len(t, counts, len(endpoints))
+	for _, c := range counts {
+		require.Greater(t, c, 500)
+	}
+}
+
+func TestRingStability(t *testing.T) {
+	ring := NewRing([]string{"relay1:2004", "relay2:2004", "relay3:2004"})
+	extended := NewRing([]string{"relay1:2004", "relay2:2004", "relay3:2004", "relay4:2004"})
+
+	// Adding an endpoint only moves keys to the new endpoint
+	for i := 0; i < 1000; i++ {
+		key := HashKey("servers.host" + strconv.Itoa(i) + ".cpu.usage")
+		if n := extended.Order(key)[0]; n != 3 {
+			require.Equal(t, ring.Order(key)[0], n)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package delivery
+
+import (
+	"fmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// DeliveryConfig contains the settings deciding when messages consumed from a
+// queue are acknowledged back to the source
+type DeliveryConfig struct {
+	DeliveryMode string `toml:"delivery_mode"`
+}
+
+// Init checks the delivery mode and applies the default
+func (cfg *DeliveryConfig) Init() error {
+	switch cfg.DeliveryMode {
+	case "":
+		cfg.DeliveryMode = "all"
+	case "all", "any":
+	default:
+		return fmt.Errorf("invalid delivery_mode %q", cfg.DeliveryMode)
+	}
+	return nil
+}
+
+// Delivered returns true if the tracked metrics are written according to the
+// delivery mode. With "all" every output has to write the metrics, the "any"
+// mode requires at least one output to succeed. Metrics dropped by all outputs,
+// e.g. because of metric filtering, count as delivered in both modes. Use
+// WrapAccumulator to receive the delivery information in "any" mode without
+// waiting for all outputs.
+func (cfg *DeliveryConfig) Delivered(info telegraf.DeliveryInfo) bool {
+	if cfg.DeliveryMode == "any" {
+		return info.Accepted() > 0 || info.Rejected() == 0
+	}
+	return info.Delivered()
+}
+
+// WrapAccumulator returns an accumulator whose tracking accumulators report
+// the delivery according to the delivery mode. In "any" mode the delivery is
+// reported as soon as one output wrote the metrics, so a stalled output does
+// not hold back the acknowledgement of messages.
+func (cfg *DeliveryConfig) WrapAccumulator(acc telegraf.Accumulator) telegraf.Accumulator {
+	if cfg.DeliveryMode != "any" {
+		return acc
+	}
+	return &anyAccumulator{Accumulator: acc}
+}
+
+type anyAccumulator struct {
+	telegraf.Accumulator
+}
+
+func (a *anyAccumulator) WithTracking(maxTracked int) telegraf.TrackingAccumulator {
+	return &anyTrackingAccumulator{
+		Accumulator: a.Accumulator,
+		delivered:   make(chan telegraf.DeliveryInfo, maxTracked),
+	}
+}
+
+type anyTrackingAccumulator struct {
+	telegraf.Accumulator
+	delivered chan telegraf.DeliveryInfo
+}
+
+func (a *anyTrackingAccumulator) AddTrackingMetric(m telegraf.Metric) telegraf.TrackingID {
+	dm, id := metric.WithTrackingOnAccept(m, a.onDelivery)
+	a.AddMetric(dm)
+	return id
+}
+
+func (a *anyTrackingAccumulator) AddTrackingMetricGroup(group []telegraf.Metric) telegraf.TrackingID {
+	db, id := metric.WithGroupTrackingOnAccept(group, a.onDelivery)
+	for _, m := range db {
+		a.AddMetric(m)
+	}
+	return id
+}
+
+func (a *anyTrackingAccumulator) Delivered() <-chan telegraf.DeliveryInfo {
+	return a.delivered
+}
+
+func (a *anyTrackingAccumulator) onDelivery(info telegraf.DeliveryInfo) {
+	select {
+	case a.delivered <- info:
+	default:
+		// This is a programming error in the input.  More items were sent for
+		// tracking than space requested.
+		panic("channel is full")
+	}
+}
@@ -0,0 +1,132 @@
+package delivery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestDeliveryModes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     string
+		results  []bool
+		expected bool
+	}{
+		{
+			name:     "all outputs succeeded",
+			mode:     "all",
+			results:  []bool{true, true},
+			expected: true,
+		},
+		{
+			name:     "one output failed",
+			mode:     "all",
+			results:  []bool{true, false},
+			expected: false,
+		},
+		{
+			name:     "default mode",
+			results:  []bool{false, true},
+			expected: false,
+		},
+		{
+			name:     "any output succeeded",
+			mode:     "any",
+			results:  []bool{false, true},
+			expected: true,
+		},
+		{
+			name:     "no output succeeded",
+			mode:     "any",
+			results:  []bool{false, false},
+			expected: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &DeliveryConfig{DeliveryMode: tt.mode}
+			require.NoError(t, cfg.Init())
+
+			var info telegraf.DeliveryInfo
+			m, _ := metric.WithTracking(testutil.TestMetric(1.0), func(di telegraf.DeliveryInfo) { info = di })
+
+			// Each output consumes its own copy of the metric
+			copies := []telegraf.Metric{m}
+			for i := 1; i < len(tt.results); i++ {
+				copies = append(copies, m.Copy())
+			}
+			for i, accepted := range tt.results {
+				if accepted {
+					copies[i].Accept()
+				} else {
+					copies[i].Reject()
+				}
+			}
+
+			require.NotNil(t, info)
+			require.Equal(t, tt.expected, cfg.Delivered(info))
+		})
+	}
+}
+
+func TestDroppedMetricsAreDelivered(t *testing.T) {
+	cfg := &DeliveryConfig{DeliveryMode: "any"}
+	require.NoError(t, cfg.Init())
+
+	var info telegraf.DeliveryInfo
+	m, _ := metric.WithTracking(testutil.MustMetric("test", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		func(di telegraf.DeliveryInfo) { info = di })
+	m.Drop()
+
+	require.NotNil(t, info)
+	require.True(t, cfg.Delivered(info))
+}
+
+// captureAccumulator keeps the metrics added to simulate outputs
+type captureAccumulator struct {
+	testutil.Accumulator
+	metrics []telegraf.Metric
+}
+
+func (a *captureAccumulator) AddMetric(m telegraf.Metric) {
+	a.metrics = append(a.metrics, m)
+}
+
+func TestStalledOutput(t *testing.T) {
+	cfg := &DeliveryConfig{DeliveryMode: "any"}
+	require.NoError(t, cfg.Init())
+
+	var base captureAccumulator
+	acc := cfg.WrapAccumulator(&base).WithTracking(1)
+	acc.AddTrackingMetricGroup([]telegraf.Metric{
+		testutil.MustMetric("cpu", nil, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		testutil.MustMetric("mem", nil, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	})
+	require.Len(t, base.metrics, 2)
+
+	// The second output gets its copies but never acknowledges them
+	for _, m := range base.metrics {
+		m.Copy()
+	}
+	for _, m := range base.metrics {
+		m.Accept()
+	}
+
+	select {
+	case info := <-acc.Delivered():
+		require.True(t, cfg.Delivered(info))
+	default:
+		require.Fail(t, "missing delivery notification")
+	}
+}
+
+func TestInvalidMode(t *testing.T) {
+	cfg := &DeliveryConfig{DeliveryMode: "some"}
+	require.EqualError(t, cfg.Init(), `invalid delivery_mode "some"`)
+}
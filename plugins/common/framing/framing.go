@@ -0,0 +1,117 @@
+// Package framing implements the length-prefixed framing of the execd
+// protocol v2. Each frame consists of a one byte frame type, the length of
+// the payload as 32-bit unsigned big-endian integer and the payload itself.
+package framing
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Types of frames exchanged between Telegraf and the process
+const (
+	// Data frames contain serialized metrics in the configured data format
+	Data byte = 'D'
+	// Ack frames acknowledge the processing of a data frame
+	Ack byte = 'A'
+	// Error frames contain an error message, e.g. if a data frame could not
+	// be processed
+	Error byte = 'E'
+	// Heartbeat frames are sent periodically to detect stalled peers
+	Heartbeat byte = 'H'
+	// Config frames contain configuration pushed to the process on start
+	Config byte = 'C'
+	// Gather frames request the process to collect and send metrics
+	Gather byte = 'G'
+	// Shutdown frames request the process to gracefully terminate
+	Shutdown byte = 'S'
+)
+
+// HeaderSize is the size of the frame header in bytes
+const HeaderSize = 5
+
+// MaxPayloadSize is the maximum payload size accepted when reading frames
+const MaxPayloadSize = 64 * 1024 * 1024
+
+// SequenceSize is the size of the sequence number prefixing the payload of
+// data frames sent by the execd output and of the responses to them
+const SequenceSize = 8
+
+// ErrPayloadTooLarge is returned when reading a frame exceeding the maximum
+// payload size
+var ErrPayloadTooLarge = errors.New("payload too large")
+
+// Frame is a single message of the protocol
+type Frame struct {
+	Type    byte
+	Payload []byte
+}
+
+// Write writes a frame of the given type and payload using a single write
+// call, so frames of concurrent writers sharing a lock are never interleaved
+func Write(w io.Writer, frameType byte, payload []byte) error {
+	if len(payload) > MaxPayloadSize {
+		return ErrPayloadTooLarge
+	}
+
+	buf := make([]byte, HeaderSize+len(payload))
+	buf[0] = frameType
+	binary.BigEndian.PutUint32(buf[1:HeaderSize], uint32(len(payload)))
+	copy(buf[HeaderSize:], payload)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+// Read reads the next frame. It returns io.EOF if the stream ended before a
+// new frame started and io.ErrUnexpectedEOF if the stream ended within a frame.
+func Read(r io.Reader) (Frame, error) {
+	var header [HeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return Frame{}, err
+	}
+
+	switch header[0] {
+	case Data, Ack, Error, Heartbeat, Config, Gather, Shutdown:
+	default:
+		return Frame{}, fmt.Errorf("invalid frame type 0x%02x", header[0])
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxPayloadSize {
+		return Frame{}, fmt.Errorf("%w: %d bytes", ErrPayloadTooLarge, size)
+	}
+
+	f := Frame{Type: header[0]}
+	if size > 0 {
+		f.Payload = make([]byte, size)
+		if _, err := io.ReadFull(r, f.Payload); err != nil {
+			if errors.Is(err, io.EOF) {
+				return Frame{}, io.ErrUnexpectedEOF
+			}
+			return Frame{}, err
+		}
+	}
+
+	return f, nil
+}
+
+// PrefixSequence returns the payload prefixed by the sequence number as 64-bit
+// unsigned big-endian integer
+func PrefixSequence(seq uint64, payload []byte) []byte {
+	buf := make([]byte, SequenceSize+len(payload))
+	binary.BigEndian.PutUint64(buf, seq)
+	copy(buf[SequenceSize:], payload)
+	return buf
+}
+
+// SplitSequence splits the payload into the prefixed sequence number and the
+// remaining payload
+func SplitSequence(payload []byte) (uint64, []byte, error) {
+	if len(payload) < SequenceSize {
+		return 0, nil, fmt.Errorf("payload of %d bytes too short for sequence number", len(payload))
+	}
+	return binary.BigEndian.Uint64(payload), payload[SequenceSize:], nil
+}
@@ -0,0 +1,60 @@
+package framing
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Data, []byte("cpu value=42i 0\nmem value=\"multi\nline\" 0\n")))
+	require.NoError(t, Write(&buf, Heartbeat, nil))
+	require.Equal(t, []byte{'H', 0, 0, 0, 0}, buf.Bytes()[buf.Len()-HeaderSize:])
+
+	f, err := Read(&buf)
+	require.NoError(t, err)
+	require.Equal(t, Data, f.Type)
+	require.Equal(t, "cpu value=42i 0\nmem value=\"multi\nline\" 0\n", string(f.Payload))
+
+	f, err = Read(&buf)
+	require.NoError(t, err)
+	require.Equal(t, Frame{Type: Heartbeat}, f)
+
+	_, err = Read(&buf)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadInvalid(t *testing.T) {
+	_, err := Read(bytes.NewReader([]byte{'X', 0, 0, 0, 0}))
+	require.ErrorContains(t, err, "invalid frame type 0x58")
+
+	_, err = Read(bytes.NewReader([]byte{'D', 0xff, 0xff, 0xff, 0xff}))
+	require.ErrorIs(t, err, ErrPayloadTooLarge)
+
+	_, err = Read(bytes.NewReader([]byte{'D', 0, 0, 0, 4, 'a'}))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = Read(bytes.NewReader([]byte{'D', 0}))
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestSequence(t *testing.T) {
+	payload := PrefixSequence(42, []byte("cpu value=42i 0\n"))
+	require.Len(t, payload, SequenceSize+16)
+
+	seq, data, err := SplitSequence(payload)
+	require.NoError(t, err)
+	require.Equal(t, uint64(42), seq)
+	require.Equal(t, "cpu value=42i 0\n", string(data))
+
+	seq, data, err = SplitSequence(PrefixSequence(7, nil))
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), seq)
+	require.Empty(t, data)
+
+	_, _, err = SplitSequence([]byte{0, 1})
+	require.ErrorContains(t, err, "too short for sequence number")
+}
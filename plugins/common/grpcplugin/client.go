@@ -0,0 +1,101 @@
+package grpcplugin
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+)
+
+// startTimeout is the maximum time to wait for the plugin handshake
+const startTimeout = 10 * time.Second
+
+// client is a running plugin process and the gRPC connection to it
+type client struct {
+	plugin *plugin.Client
+	conn   *grpc.ClientConn
+}
+
+// startPlugin starts the plugin binary and connects to it. The connection is
+// secured by mutual TLS using certificates generated for this process only.
+func startPlugin(category, path string, log telegraf.Logger) (*client, error) {
+	c := plugin.NewClient(&plugin.ClientConfig{
+		HandshakeConfig:  handshake,
+		Plugins:          plugin.PluginSet{category: &grpcPlugin{}},
+		Cmd:              exec.Command(path),
+		AllowedProtocols: []plugin.Protocol{plugin.ProtocolGRPC},
+		AutoMTLS:         true,
+		StartTimeout:     startTimeout,
+		Stderr:           &logWriter{log: log},
+		SyncStderr:       &logWriter{log: log},
+		Logger:           hclog.NewNullLogger(),
+	})
+
+	protocol, err := c.Client()
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("connecting to plugin failed: %w", err)
+	}
+	raw, err := protocol.Dispense(category)
+	if err != nil {
+		c.Kill()
+		return nil, fmt.Errorf("dispensing plugin failed: %w", err)
+	}
+
+	return &client{plugin: c, conn: raw.(*grpc.ClientConn)}, nil
+}
+
+// stop closes the connection and terminates the plugin, the plugin is killed
+// if it does not terminate in time
+func (c *client) stop() {
+	c.plugin.Kill()
+}
+
+// logWriter passes the log messages written by the plugin to the logger
+// keeping the log-level of the message if any
+type logWriter struct {
+	log telegraf.Logger
+	buf []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.logLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *logWriter) logLine(line string) {
+	if line == "" {
+		return
+	}
+
+	level, msg := "I", line
+	if len(line) > 2 && line[1] == '!' {
+		level, msg = line[:1], strings.TrimSpace(line[2:])
+	}
+
+	switch level {
+	case "E":
+		w.log.Error(msg)
+	case "W":
+		w.log.Warn(msg)
+	case "D":
+		w.log.Debug(msg)
+	default:
+		w.log.Info(msg)
+	}
+}
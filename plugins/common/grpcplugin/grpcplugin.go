@@ -0,0 +1,191 @@
+// Package grpcplugin allows to run input, output and processor plugins as
+// separate processes speaking a versioned gRPC API. Telegraf starts the plugin
+// binaries found in the external plugin directory on demand and communicates
+// with them using hashicorp/go-plugin with mutual TLS.
+package grpcplugin
+
+//go:generate protoc --go_out=api/ --go-grpc_out=api/ api/plugin.proto
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/grpcplugin/api"
+)
+
+const (
+	// MagicCookieKey is the environment variable set when starting a plugin
+	// to prevent running plugin binaries by accident
+	MagicCookieKey = "TELEGRAF_PLUGIN_MAGIC_COOKIE"
+	// MagicCookieValue is the value of the magic cookie
+	MagicCookieValue = "d3a1b8e8c5f34f0c9f6b4a7e2c1d0f9e"
+
+	// APIVersion is the version of the gRPC plugin API
+	APIVersion = 1
+)
+
+// handshake is verified by Telegraf and the plugin before communicating
+var handshake = plugin.HandshakeConfig{
+	ProtocolVersion:  APIVersion,
+	MagicCookieKey:   MagicCookieKey,
+	MagicCookieValue: MagicCookieValue,
+}
+
+// grpcPlugin connects the plugin API to go-plugin. Telegraf uses the client
+// connection to create the API clients while the plugin process registers
+// the services of the plugin.
+type grpcPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+	register func(*grpc.Server)
+}
+
+func (p *grpcPlugin) GRPCServer(_ *plugin.GRPCBroker, s *grpc.Server) error {
+	p.register(s)
+	return nil
+}
+
+func (*grpcPlugin) GRPCClient(_ context.Context, _ *plugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return conn, nil
+}
+
+// encodeMetrics converts the metrics to their protobuf representation
+func encodeMetrics(metrics []telegraf.Metric) ([]*api.Metric, error) {
+	encoded := make([]*api.Metric, 0, len(metrics))
+	for _, m := range metrics {
+		e := &api.Metric{
+			Name:   m.Name(),
+			Tags:   make([]*api.Tag, 0, len(m.TagList())),
+			Fields: make([]*api.Field, 0, len(m.FieldList())),
+			Time:   m.Time().UnixNano(),
+			Type:   encodeValueType(m.Type()),
+		}
+		for _, tag := range m.TagList() {
+			e.Tags = append(e.Tags, &api.Tag{Key: tag.Key, Value: tag.Value})
+		}
+		for _, field := range m.FieldList() {
+			f, err := encodeField(field)
+			if err != nil {
+				return nil, err
+			}
+			e.Fields = append(e.Fields, f)
+		}
+		encoded = append(encoded, e)
+	}
+	return encoded, nil
+}
+
+func encodeField(field *telegraf.Field) (*api.Field, error) {
+	f := &api.Field{Key: field.Key}
+	switch v := field.Value.(type) {
+	case float64:
+		f.Value = &api.Field_FloatValue{FloatValue: v}
+	case int64:
+		f.Value = &api.Field_IntValue{IntValue: v}
+	case uint64:
+		f.Value = &api.Field_UintValue{UintValue: v}
+	case string:
+		f.Value = &api.Field_StringValue{StringValue: v}
+	case bool:
+		f.Value = &api.Field_BoolValue{BoolValue: v}
+	case *telegraf.HistogramValue:
+		h := &api.Histogram{Count: v.Count, Sum: v.Sum, Buckets: make([]*api.Bucket, 0, len(v.Buckets))}
+		for _, b := range v.Buckets {
+			h.Buckets = append(h.Buckets, &api.Bucket{UpperBound: b.UpperBound, Count: b.Count})
+		}
+		f.Value = &api.Field_HistogramValue{HistogramValue: h}
+	case *telegraf.SummaryValue:
+		s := &api.Summary{Count: v.Count, Sum: v.Sum, Quantiles: make([]*api.Quantile, 0, len(v.Quantiles))}
+		for _, q := range v.Quantiles {
+			s.Quantiles = append(s.Quantiles, &api.Quantile{Quantile: q.Quantile, Value: q.Value})
+		}
+		f.Value = &api.Field_SummaryValue{SummaryValue: s}
+	default:
+		return nil, fmt.Errorf("unsupported type %T of field %q", field.Value, field.Key)
+	}
+	return f, nil
+}
+
+// decodeMetrics converts the protobuf representation to metrics
+func decodeMetrics(encoded []*api.Metric) ([]telegraf.Metric, error) {
+	metrics := make([]telegraf.Metric, 0, len(encoded))
+	for _, e := range encoded {
+		tags := make(map[string]string, len(e.Tags))
+		for _, tag := range e.Tags {
+			tags[tag.Key] = tag.Value
+		}
+		m := metric.New(e.Name, tags, nil, time.Unix(0, e.Time), decodeValueType(e.Type))
+
+		// Keep the order of the fields
+		for _, f := range e.Fields {
+			v, err := decodeField(f)
+			if err != nil {
+				return nil, err
+			}
+			m.AddField(f.Key, v)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, nil
+}
+
+func decodeField(f *api.Field) (interface{}, error) {
+	switch v := f.Value.(type) {
+	case *api.Field_FloatValue:
+		return v.FloatValue, nil
+	case *api.Field_IntValue:
+		return v.IntValue, nil
+	case *api.Field_UintValue:
+		return v.UintValue, nil
+	case *api.Field_StringValue:
+		return v.StringValue, nil
+	case *api.Field_BoolValue:
+		return v.BoolValue, nil
+	case *api.Field_HistogramValue:
+		h := &telegraf.HistogramValue{Count: v.HistogramValue.Count, Sum: v.HistogramValue.Sum}
+		for _, b := range v.HistogramValue.Buckets {
+			h.Buckets = append(h.Buckets, telegraf.Bucket{UpperBound: b.UpperBound, Count: b.Count})
+		}
+		return h, nil
+	case *api.Field_SummaryValue:
+		s := &telegraf.SummaryValue{Count: v.SummaryValue.Count, Sum: v.SummaryValue.Sum}
+		for _, q := range v.SummaryValue.Quantiles {
+			s.Quantiles = append(s.Quantiles, telegraf.Quantile{Quantile: q.Quantile, Value: q.Value})
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("missing value of field %q", f.Key)
+}
+
+func encodeValueType(tp telegraf.ValueType) api.ValueType {
+	switch tp {
+	case telegraf.Counter:
+		return api.ValueType_COUNTER
+	case telegraf.Gauge:
+		return api.ValueType_GAUGE
+	case telegraf.Summary:
+		return api.ValueType_SUMMARY
+	case telegraf.Histogram:
+		return api.ValueType_HISTOGRAM
+	}
+	return api.ValueType_UNTYPED
+}
+
+func decodeValueType(tp api.ValueType) telegraf.ValueType {
+	switch tp {
+	case api.ValueType_COUNTER:
+		return telegraf.Counter
+	case api.ValueType_GAUGE:
+		return telegraf.Gauge
+	case api.ValueType_SUMMARY:
+		return telegraf.Summary
+	case api.ValueType_HISTOGRAM:
+		return telegraf.Histogram
+	}
+	return telegraf.Untyped
+}
@@ -0,0 +1,318 @@
+package grpcplugin
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// The test binary acts as plugin if started by Telegraf, the plugin served
+// is selected by the name of the executable
+func TestMain(m *testing.M) {
+	if os.Getenv(MagicCookieKey) == MagicCookieValue {
+		var plugin interface{}
+		switch filepath.Base(os.Args[0]) {
+		case "telegraf-input-test":
+			plugin = &testInput{}
+		case "telegraf-output-test":
+			plugin = &testOutput{}
+		case "telegraf-processor-test":
+			plugin = &testProcessor{}
+		}
+		if err := Serve(plugin); err != nil {
+			fmt.Fprintln(os.Stderr, "E!", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type testInput struct {
+	Value int64           `toml:"value"`
+	Log   telegraf.Logger `toml:"-"`
+}
+
+func (*testInput) SampleConfig() string {
+	return "  ## Value to report\n  # value = 42\n"
+}
+
+func (i *testInput) Init() error {
+	if i.Value < 0 {
+		return fmt.Errorf("invalid value %d", i.Value)
+	}
+	return nil
+}
+
+func (i *testInput) Gather(acc telegraf.Accumulator) error {
+	i.Log.Info("gathering")
+	acc.AddFields("test", map[string]interface{}{"value": i.Value}, map[string]string{"source": "plugin"}, time.Unix(0, 0))
+	acc.AddError(fmt.Errorf("partial failure"))
+	return nil
+}
+
+type testOutput struct {
+	Filename string `toml:"filename"`
+}
+
+func (*testOutput) SampleConfig() string {
+	return ""
+}
+
+func (*testOutput) Connect() error {
+	return nil
+}
+
+func (o *testOutput) Write(metrics []telegraf.Metric) error {
+	s := &influx.Serializer{UintSupport: true}
+	if err := s.Init(); err != nil {
+		return err
+	}
+	buf, err := s.SerializeBatch(metrics)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(o.Filename, buf, 0600)
+}
+
+func (*testOutput) Close() error {
+	return nil
+}
+
+type testProcessor struct {
+	Tag string `toml:"tag"`
+}
+
+func (*testProcessor) SampleConfig() string {
+	return ""
+}
+
+func (p *testProcessor) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for _, m := range in {
+		m.AddTag(p.Tag, "true")
+	}
+	return in
+}
+
+// setupPlugins links the test binary as plugins into a temporary directory
+func setupPlugins(t *testing.T, names ...string) string {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.Symlink(executable, filepath.Join(dir, name)))
+	}
+	return dir
+}
+
+func TestEncodeDecodeMetrics(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"host": "a", "region": "eu"},
+			map[string]interface{}{
+				"float":  42.5,
+				"int":    int64(-3),
+				"uint":   uint64(18446744073709551615),
+				"string": "multi\nline",
+				"bool":   true,
+			},
+			time.Unix(1, 23),
+			telegraf.Counter,
+		),
+		metric.New(
+			"latency",
+			map[string]string{},
+			map[string]interface{}{
+				"histogram": &telegraf.HistogramValue{
+					Count:   3,
+					Sum:     1.5,
+					Buckets: []telegraf.Bucket{{UpperBound: 0.5, Count: 2}, {UpperBound: math.Inf(1), Count: 3}},
+				},
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		metric.New(
+			"latency",
+			map[string]string{},
+			map[string]interface{}{
+				"summary": &telegraf.SummaryValue{
+					Count:     3,
+					Sum:       1.5,
+					Quantiles: []telegraf.Quantile{{Quantile: 0.5, Value: 0.4}, {Quantile: 0.99, Value: 0.9}},
+				},
+			},
+			time.Unix(0, 0),
+			telegraf.Summary,
+		),
+	}
+
+	encoded, err := encodeMetrics(metrics)
+	require.NoError(t, err)
+	decoded, err := decodeMetrics(encoded)
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, metrics, decoded)
+	for i, m := range decoded {
+		require.Equal(t, metrics[i].Type(), m.Type())
+		require.Equal(t, metrics[i].FieldList(), m.FieldList())
+	}
+}
+
+func TestServeWithoutMagicCookie(t *testing.T) {
+	require.ErrorContains(t, Serve(&testInput{}), "must be started by Telegraf")
+}
+
+func TestParseFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		category string
+		name     string
+		found    bool
+	}{
+		{filename: "telegraf-input-foo", category: "inputs", name: "foo", found: true},
+		{filename: "telegraf-output-foo_bar", category: "outputs", name: "foo_bar", found: true},
+		{filename: "telegraf-processor-my-plugin", category: "processors", name: "my-plugin", found: true},
+		{filename: "telegraf-aggregator-foo"},
+		{filename: "telegraf-input-"},
+		{filename: "input-foo"},
+		{filename: "README.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			category, name, found := parseFilename(tt.filename)
+			require.Equal(t, tt.found, found)
+			require.Equal(t, tt.category, category)
+			require.Equal(t, tt.name, name)
+		})
+	}
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir := setupPlugins(t, "telegraf-input-test", "telegraf-output-test", "telegraf-processor-test")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), nil, 0600))
+
+	names, err := LoadDirectory(dir)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"inputs.test", "outputs.test", "processors.test"}, names)
+	require.Contains(t, inputs.Inputs, "test")
+	require.Contains(t, outputs.Outputs, "test")
+	require.Contains(t, processors.Processors, "test")
+
+	// Loading the same directory again must not fail
+	_, err = LoadDirectory(dir)
+	require.NoError(t, err)
+
+	// Plugins with the same name from another location must be rejected
+	_, err = LoadDirectory(setupPlugins(t, "telegraf-input-test"))
+	require.ErrorContains(t, err, "conflicts with")
+}
+
+func TestInput(t *testing.T) {
+	dir := setupPlugins(t, "telegraf-input-test")
+	plugin := &Input{
+		Log:      testutil.Logger{},
+		external: external{category: "inputs", name: "test", path: filepath.Join(dir, "telegraf-input-test")},
+	}
+	require.NoError(t, plugin.SetPluginConfig(map[string]interface{}{"value": int64(23)}))
+	require.Contains(t, plugin.SampleConfig(), "value = 42")
+
+	require.NoError(t, plugin.Init())
+	defer plugin.Stop()
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"source": "plugin"},
+			map[string]interface{}{"value": int64(23)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "partial failure")
+}
+
+func TestInputInitFail(t *testing.T) {
+	dir := setupPlugins(t, "telegraf-input-test")
+	plugin := &Input{
+		Log:      testutil.Logger{},
+		external: external{category: "inputs", name: "test", path: filepath.Join(dir, "telegraf-input-test")},
+	}
+	require.NoError(t, plugin.SetPluginConfig(map[string]interface{}{"value": int64(-1)}))
+	require.ErrorContains(t, plugin.Init(), "invalid value -1")
+}
+
+func TestOutput(t *testing.T) {
+	dir := setupPlugins(t, "telegraf-output-test")
+	filename := filepath.Join(t.TempDir(), "metrics.out")
+	plugin := &Output{
+		Log:      testutil.Logger{},
+		external: external{category: "outputs", name: "test", path: filepath.Join(dir, "telegraf-output-test")},
+	}
+	require.NoError(t, plugin.SetPluginConfig(map[string]interface{}{"filename": filename}))
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5}, time.Unix(0, 0)),
+		metric.New("mem", map[string]string{}, map[string]interface{}{"free": uint64(1024)}, time.Unix(1, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Close())
+
+	buf, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, []string{"cpu,host=a usage=42.5 0", "mem free=1024u 1000000000"}, strings.Split(strings.TrimSpace(string(buf)), "\n"))
+}
+
+func TestProcessor(t *testing.T) {
+	dir := setupPlugins(t, "telegraf-processor-test")
+	plugin := &Processor{
+		Log:      testutil.Logger{},
+		external: external{category: "processors", name: "test", path: filepath.Join(dir, "telegraf-processor-test")},
+	}
+	require.NoError(t, plugin.SetPluginConfig(map[string]interface{}{"tag": "processed"}))
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	input := metric.New("cpu", map[string]string{}, map[string]interface{}{"usage": 42.5}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(input, &acc))
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"processed": "true"}, map[string]interface{}{"usage": 42.5}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestInputUnusedSettings(t *testing.T) {
+	dir := setupPlugins(t, "telegraf-input-test")
+	plugin := &Input{
+		Log:      testutil.Logger{},
+		external: external{category: "inputs", name: "test", path: filepath.Join(dir, "telegraf-input-test")},
+	}
+	require.NoError(t, plugin.SetPluginConfig(map[string]interface{}{"value": int64(23), "foo": "bar", "valeu": int64(1)}))
+	require.ErrorContains(t, plugin.Init(), `configuration specified the fields ["foo" "valeu"], but they weren't used`)
+}
@@ -0,0 +1,90 @@
+package grpcplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/grpcplugin/api"
+)
+
+// Input runs an external input plugin
+type Input struct {
+	Log telegraf.Logger `toml:"-"`
+
+	external
+	api api.InputClient
+}
+
+func (i *Input) SampleConfig() string {
+	return i.sampleConfig(func(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+		resp, err := api.NewInputClient(conn).SampleConfig(ctx, &api.SampleConfigRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.Config, nil
+	})
+}
+
+func (i *Input) Init() error {
+	settings, err := i.start(i.Log)
+	if err != nil {
+		return err
+	}
+	i.api = api.NewInputClient(i.client.conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := i.api.Init(ctx, &api.InitRequest{Config: settings})
+	if err != nil {
+		i.stop()
+		return fmt.Errorf("initializing plugin failed: %w", err)
+	}
+	if len(resp.UnusedKeys) > 0 {
+		i.stop()
+		return unusedKeysError(resp.UnusedKeys)
+	}
+
+	return nil
+}
+
+// Start is a no-op as the plugin process is started on initialization
+func (i *Input) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (i *Input) Gather(acc telegraf.Accumulator) error {
+	resp, err := i.api.Gather(context.Background(), &api.GatherRequest{})
+	if err != nil {
+		return err
+	}
+
+	metrics, err := decodeMetrics(resp.Metrics)
+	if err != nil {
+		return fmt.Errorf("decoding metrics failed: %w", err)
+	}
+	for _, m := range metrics {
+		acc.AddMetric(m)
+	}
+	for _, msg := range resp.Errors {
+		acc.AddError(errors.New(msg))
+	}
+
+	return nil
+}
+
+func (i *Input) Stop() {
+	if i.client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if _, err := i.api.Stop(ctx, &api.StopRequest{}); err != nil {
+		i.Log.Errorf("Stopping plugin failed: %v", err)
+	}
+	i.stop()
+}
@@ -0,0 +1,109 @@
+package grpcplugin
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+// loaded keeps the paths of the registered plugins to detect conflicts
+var loaded = make(map[string]string)
+
+// LoadDirectory registers all plugin binaries found in the given directory.
+// The plugin category and name are derived from the filename which must be
+// of the form "telegraf-<category>-<name>", e.g. "telegraf-input-foo" for an
+// input called "foo".
+func LoadDirectory(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin directory failed: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		category, name, found := parseFilename(entry.Name())
+		if !found {
+			continue
+		}
+
+		path, err := filepath.Abs(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := register(category, name, path); err != nil {
+			return nil, err
+		}
+		names = append(names, category+"."+name)
+	}
+
+	return names, nil
+}
+
+// parseFilename extracts the category and name of the plugin binary
+func parseFilename(filename string) (category, name string, found bool) {
+	if runtime.GOOS == "windows" {
+		filename = strings.TrimSuffix(filename, ".exe")
+	}
+
+	remainder, found := strings.CutPrefix(filename, "telegraf-")
+	if !found {
+		return "", "", false
+	}
+	category, name, found = strings.Cut(remainder, "-")
+	if !found || name == "" {
+		return "", "", false
+	}
+
+	switch category {
+	case "input", "output", "processor":
+		return category + "s", name, true
+	}
+	return "", "", false
+}
+
+func register(category, name, path string) error {
+	key := category + "." + name
+	if p, found := loaded[key]; found {
+		if p == path {
+			return nil
+		}
+		return fmt.Errorf("plugin %q from %q conflicts with %q", key, path, p)
+	}
+
+	switch category {
+	case "inputs":
+		if _, found := inputs.Inputs[name]; found {
+			return fmt.Errorf("plugin %q from %q conflicts with a built-in plugin", key, path)
+		}
+		inputs.Add(name, func() telegraf.Input {
+			return &Input{external: external{category: category, name: name, path: path}}
+		})
+	case "outputs":
+		if _, found := outputs.Outputs[name]; found {
+			return fmt.Errorf("plugin %q from %q conflicts with a built-in plugin", key, path)
+		}
+		outputs.Add(name, func() telegraf.Output {
+			return &Output{external: external{category: category, name: name, path: path}}
+		})
+	case "processors":
+		if _, found := processors.Processors[name]; found {
+			return fmt.Errorf("plugin %q from %q conflicts with a built-in plugin", key, path)
+		}
+		processors.AddStreaming(name, func() telegraf.StreamingProcessor {
+			return &Processor{external: external{category: category, name: name, path: path}}
+		})
+	}
+	loaded[key] = path
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/grpcplugin/api"
+)
+
+// Output runs an external output plugin
+type Output struct {
+	Log telegraf.Logger `toml:"-"`
+
+	external
+	api api.OutputClient
+}
+
+func (o *Output) SampleConfig() string {
+	return o.sampleConfig(func(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+		resp, err := api.NewOutputClient(conn).SampleConfig(ctx, &api.SampleConfigRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.Config, nil
+	})
+}
+
+func (o *Output) Init() error {
+	settings, err := o.start(o.Log)
+	if err != nil {
+		return err
+	}
+	o.api = api.NewOutputClient(o.client.conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := o.api.Init(ctx, &api.InitRequest{Config: settings})
+	if err != nil {
+		o.stop()
+		return fmt.Errorf("initializing plugin failed: %w", err)
+	}
+	if len(resp.UnusedKeys) > 0 {
+		o.stop()
+		return unusedKeysError(resp.UnusedKeys)
+	}
+
+	return nil
+}
+
+func (o *Output) Connect() error {
+	_, err := o.api.Connect(context.Background(), &api.ConnectRequest{})
+	return err
+}
+
+func (o *Output) Write(metrics []telegraf.Metric) error {
+	encoded, err := encodeMetrics(metrics)
+	if err != nil {
+		return fmt.Errorf("encoding metrics failed: %w", err)
+	}
+	_, err = o.api.Write(context.Background(), &api.WriteRequest{Metrics: encoded})
+	return err
+}
+
+func (o *Output) Close() error {
+	if o.client == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	_, err := o.api.Stop(ctx, &api.StopRequest{})
+	o.stop()
+	return err
+}
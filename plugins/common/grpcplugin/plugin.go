@@ -0,0 +1,91 @@
+package grpcplugin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/models"
+)
+
+// requestTimeout is the timeout for management requests like Init or Stop
+const requestTimeout = 30 * time.Second
+
+// external holds the state shared by all types of external plugins
+type external struct {
+	category string
+	name     string
+	path     string
+	settings map[string]interface{}
+	client   *client
+}
+
+// SetPluginConfig stores the settings of the plugin table to pass them to
+// the plugin process on initialization
+func (e *external) SetPluginConfig(settings map[string]interface{}) error {
+	e.settings = settings
+	return nil
+}
+
+// start runs the plugin process and returns the encoded settings
+func (e *external) start(log telegraf.Logger) ([]byte, error) {
+	if log == nil {
+		log = models.NewLogger(e.category, e.name, "")
+	}
+
+	var buf bytes.Buffer
+	if len(e.settings) > 0 {
+		if err := toml.NewEncoder(&buf).Encode(e.settings); err != nil {
+			return nil, fmt.Errorf("encoding settings failed: %w", err)
+		}
+	}
+
+	c, err := startPlugin(e.category, e.path, log)
+	if err != nil {
+		return nil, fmt.Errorf("starting plugin %q failed: %w", e.path, err)
+	}
+	e.client = c
+
+	return buf.Bytes(), nil
+}
+
+// stop terminates the plugin process
+func (e *external) stop() {
+	if e.client != nil {
+		e.client.stop()
+		e.client = nil
+	}
+}
+
+// unusedKeysError reports the settings not used by the plugin the same way
+// as for built-in plugins
+func unusedKeysError(keys []string) error {
+	sort.Strings(keys)
+	return fmt.Errorf("configuration specified the fields %q, but they weren't used", keys)
+}
+
+// sampleConfig starts the plugin to request its sample configuration
+func (e *external) sampleConfig(request func(context.Context, *grpc.ClientConn) (string, error)) string {
+	log := models.NewLogger(e.category, e.name, "")
+	if _, err := e.start(log); err != nil {
+		log.Errorf("Getting sample configuration failed: %v", err)
+		return ""
+	}
+	defer e.stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	conf, err := request(ctx, e.client.conn)
+	if err != nil {
+		log.Errorf("Getting sample configuration failed: %v", err)
+		return ""
+	}
+	return conf
+}
@@ -0,0 +1,93 @@
+package grpcplugin
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/common/grpcplugin/api"
+)
+
+// Processor runs an external processor plugin
+type Processor struct {
+	Log telegraf.Logger `toml:"-"`
+
+	external
+	api api.ProcessorClient
+}
+
+func (p *Processor) SampleConfig() string {
+	return p.sampleConfig(func(ctx context.Context, conn *grpc.ClientConn) (string, error) {
+		resp, err := api.NewProcessorClient(conn).SampleConfig(ctx, &api.SampleConfigRequest{})
+		if err != nil {
+			return "", err
+		}
+		return resp.Config, nil
+	})
+}
+
+func (p *Processor) Init() error {
+	settings, err := p.start(p.Log)
+	if err != nil {
+		return err
+	}
+	p.api = api.NewProcessorClient(p.client.conn)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	resp, err := p.api.Init(ctx, &api.InitRequest{Config: settings})
+	if err != nil {
+		p.stop()
+		return fmt.Errorf("initializing plugin failed: %w", err)
+	}
+	if len(resp.UnusedKeys) > 0 {
+		p.stop()
+		return unusedKeysError(resp.UnusedKeys)
+	}
+
+	return nil
+}
+
+// Start is a no-op as the plugin process is started on initialization
+func (p *Processor) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+func (p *Processor) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	encoded, err := encodeMetrics([]telegraf.Metric{m})
+	if err != nil {
+		return fmt.Errorf("encoding metric failed: %w", err)
+	}
+	resp, err := p.api.Apply(context.Background(), &api.ApplyRequest{Metrics: encoded})
+	if err != nil {
+		return err
+	}
+
+	metrics, err := decodeMetrics(resp.Metrics)
+	if err != nil {
+		return fmt.Errorf("decoding metrics failed: %w", err)
+	}
+
+	// The metrics are recreated by decoding, so release the original one
+	m.Accept()
+	for _, result := range metrics {
+		acc.AddMetric(result)
+	}
+
+	return nil
+}
+
+func (p *Processor) Stop() {
+	if p.client == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if _, err := p.api.Stop(ctx, &api.StopRequest{}); err != nil {
+		p.Log.Errorf("Stopping plugin failed: %v", err)
+	}
+	p.stop()
+}
@@ -0,0 +1,319 @@
+package grpcplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log" //nolint:depguard // The shim logger uses the standard logger
+	"os"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/grpcplugin/api"
+	"github.com/influxdata/telegraf/plugins/common/shim"
+)
+
+// Serve runs the given input, output or processor plugin as external plugin.
+// The function is meant to be called in the main function of the plugin
+// binary and blocks until Telegraf stops the plugin.
+func Serve(p interface{}) error {
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return errors.New("this binary is a Telegraf plugin and must be started by Telegraf")
+	}
+
+	// Telegraf adds the timestamp when relaying the log messages
+	log.SetFlags(0)
+	models.SetLoggerOnPlugin(p, shim.NewLogger())
+
+	var category string
+	var register func(*grpc.Server)
+	switch p := p.(type) {
+	case telegraf.Input:
+		category = "inputs"
+		register = func(s *grpc.Server) {
+			api.RegisterInputServer(s, &inputServer{plugin: p, acc: &collector{}})
+		}
+	case telegraf.Output:
+		category = "outputs"
+		register = func(s *grpc.Server) {
+			api.RegisterOutputServer(s, &outputServer{plugin: p})
+		}
+	case telegraf.Processor:
+		category = "processors"
+		register = func(s *grpc.Server) {
+			api.RegisterProcessorServer(s, &processorServer{plugin: p})
+		}
+	default:
+		return fmt.Errorf("unsupported plugin type %T", p)
+	}
+
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: handshake,
+		Plugins:         plugin.PluginSet{category: &grpcPlugin{register: register}},
+		GRPCServer:      plugin.DefaultGRPCServer,
+		Logger:          hclog.NewNullLogger(),
+	})
+	return nil
+}
+
+// initPlugin applies the TOML settings sent by Telegraf to the plugin and
+// initializes it, the settings not used by the plugin are returned
+func initPlugin(plugin interface{}, settings []byte) ([]string, error) {
+	var unused []string
+	if len(settings) > 0 {
+		md, err := toml.Decode(string(settings), plugin)
+		if err != nil {
+			return nil, fmt.Errorf("decoding settings failed: %w", err)
+		}
+		for _, key := range md.Undecoded() {
+			unused = append(unused, key.String())
+		}
+	}
+	if p, ok := plugin.(telegraf.Initializer); ok {
+		if err := p.Init(); err != nil {
+			return nil, err
+		}
+	}
+	return unused, nil
+}
+
+type inputServer struct {
+	api.UnimplementedInputServer
+	plugin telegraf.Input
+	acc    *collector
+}
+
+func (s *inputServer) Init(_ context.Context, req *api.InitRequest) (*api.InitResponse, error) {
+	unused, err := initPlugin(s.plugin, req.Config)
+	if err != nil {
+		return nil, err
+	}
+	if len(unused) > 0 {
+		// Telegraf refuses the plugin so there is no need to start it
+		return &api.InitResponse{UnusedKeys: unused}, nil
+	}
+	if p, ok := s.plugin.(telegraf.ServiceInput); ok {
+		if err := p.Start(s.acc); err != nil {
+			return nil, fmt.Errorf("starting service input failed: %w", err)
+		}
+	}
+	return &api.InitResponse{UnusedKeys: unused}, nil
+}
+
+func (s *inputServer) SampleConfig(context.Context, *api.SampleConfigRequest) (*api.SampleConfigResponse, error) {
+	return &api.SampleConfigResponse{Config: s.plugin.SampleConfig()}, nil
+}
+
+func (s *inputServer) Gather(context.Context, *api.GatherRequest) (*api.GatherResponse, error) {
+	if err := s.plugin.Gather(s.acc); err != nil {
+		s.acc.AddError(err)
+	}
+
+	metrics, errs := s.acc.flush()
+	encoded, err := encodeMetrics(metrics)
+	if err != nil {
+		return nil, fmt.Errorf("encoding metrics failed: %w", err)
+	}
+
+	// The metrics are handed over to Telegraf now
+	for _, m := range metrics {
+		m.Accept()
+	}
+
+	return &api.GatherResponse{Metrics: encoded, Errors: errs}, nil
+}
+
+func (s *inputServer) Stop(context.Context, *api.StopRequest) (*api.StopResponse, error) {
+	if p, ok := s.plugin.(telegraf.ServiceInput); ok {
+		p.Stop()
+	}
+	return &api.StopResponse{}, nil
+}
+
+type outputServer struct {
+	api.UnimplementedOutputServer
+	plugin telegraf.Output
+}
+
+func (s *outputServer) Init(_ context.Context, req *api.InitRequest) (*api.InitResponse, error) {
+	unused, err := initPlugin(s.plugin, req.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &api.InitResponse{UnusedKeys: unused}, nil
+}
+
+func (s *outputServer) SampleConfig(context.Context, *api.SampleConfigRequest) (*api.SampleConfigResponse, error) {
+	return &api.SampleConfigResponse{Config: s.plugin.SampleConfig()}, nil
+}
+
+func (s *outputServer) Connect(context.Context, *api.ConnectRequest) (*api.ConnectResponse, error) {
+	if err := s.plugin.Connect(); err != nil {
+		return nil, err
+	}
+	return &api.ConnectResponse{}, nil
+}
+
+func (s *outputServer) Write(_ context.Context, req *api.WriteRequest) (*api.WriteResponse, error) {
+	metrics, err := decodeMetrics(req.Metrics)
+	if err != nil {
+		return nil, fmt.Errorf("decoding metrics failed: %w", err)
+	}
+	if err := s.plugin.Write(metrics); err != nil {
+		return nil, err
+	}
+	return &api.WriteResponse{}, nil
+}
+
+func (s *outputServer) Stop(context.Context, *api.StopRequest) (*api.StopResponse, error) {
+	if err := s.plugin.Close(); err != nil {
+		return nil, err
+	}
+	return &api.StopResponse{}, nil
+}
+
+type processorServer struct {
+	api.UnimplementedProcessorServer
+	plugin telegraf.Processor
+}
+
+func (s *processorServer) Init(_ context.Context, req *api.InitRequest) (*api.InitResponse, error) {
+	unused, err := initPlugin(s.plugin, req.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &api.InitResponse{UnusedKeys: unused}, nil
+}
+
+func (s *processorServer) SampleConfig(context.Context, *api.SampleConfigRequest) (*api.SampleConfigResponse, error) {
+	return &api.SampleConfigResponse{Config: s.plugin.SampleConfig()}, nil
+}
+
+func (s *processorServer) Apply(_ context.Context, req *api.ApplyRequest) (*api.ApplyResponse, error) {
+	metrics, err := decodeMetrics(req.Metrics)
+	if err != nil {
+		return nil, fmt.Errorf("decoding metrics failed: %w", err)
+	}
+	encoded, err := encodeMetrics(s.plugin.Apply(metrics...))
+	if err != nil {
+		return nil, fmt.Errorf("encoding metrics failed: %w", err)
+	}
+	return &api.ApplyResponse{Metrics: encoded}, nil
+}
+
+func (*processorServer) Stop(context.Context, *api.StopRequest) (*api.StopResponse, error) {
+	return &api.StopResponse{}, nil
+}
+
+// collector is the accumulator passed to input plugins collecting the
+// metrics and errors until the next gather request
+type collector struct {
+	metrics []telegraf.Metric
+	errors  []string
+	sync.Mutex
+}
+
+func (c *collector) AddFields(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	c.addMeasurement(measurement, tags, fields, telegraf.Untyped, t...)
+}
+
+func (c *collector) AddGauge(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	c.addMeasurement(measurement, tags, fields, telegraf.Gauge, t...)
+}
+
+func (c *collector) AddCounter(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	c.addMeasurement(measurement, tags, fields, telegraf.Counter, t...)
+}
+
+func (c *collector) AddSummary(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	c.addMeasurement(measurement, tags, fields, telegraf.Summary, t...)
+}
+
+func (c *collector) AddHistogram(measurement string, fields map[string]interface{}, tags map[string]string, t ...time.Time) {
+	c.addMeasurement(measurement, tags, fields, telegraf.Histogram, t...)
+}
+
+func (c *collector) AddMetric(m telegraf.Metric) {
+	c.Lock()
+	defer c.Unlock()
+	c.metrics = append(c.metrics, m)
+}
+
+func (c *collector) addMeasurement(
+	measurement string,
+	tags map[string]string,
+	fields map[string]interface{},
+	tp telegraf.ValueType,
+	t ...time.Time,
+) {
+	tm := time.Now()
+	if len(t) > 0 {
+		tm = t[0]
+	}
+	c.AddMetric(metric.New(measurement, tags, fields, tm, tp))
+}
+
+func (c *collector) AddError(err error) {
+	if err == nil {
+		return
+	}
+	c.Lock()
+	defer c.Unlock()
+	c.errors = append(c.errors, err.Error())
+}
+
+// SetPrecision is a no-op as Telegraf applies the precision itself
+func (c *collector) SetPrecision(time.Duration) {}
+
+func (c *collector) WithTracking(maxTracked int) telegraf.TrackingAccumulator {
+	return &trackingCollector{
+		collector: c,
+		delivered: make(chan telegraf.DeliveryInfo, maxTracked),
+	}
+}
+
+// flush returns and resets the collected metrics and errors
+func (c *collector) flush() ([]telegraf.Metric, []string) {
+	c.Lock()
+	defer c.Unlock()
+	metrics, errs := c.metrics, c.errors
+	c.metrics, c.errors = nil, nil
+	return metrics, errs
+}
+
+// trackingCollector reports tracked metrics as delivered as soon as they
+// are passed to Telegraf
+type trackingCollector struct {
+	*collector
+	delivered chan telegraf.DeliveryInfo
+}
+
+func (c *trackingCollector) AddTrackingMetric(m telegraf.Metric) telegraf.TrackingID {
+	dm, id := metric.WithTracking(m, c.onDelivery)
+	c.AddMetric(dm)
+	return id
+}
+
+func (c *trackingCollector) AddTrackingMetricGroup(group []telegraf.Metric) telegraf.TrackingID {
+	db, id := metric.WithGroupTracking(group, c.onDelivery)
+	for _, m := range db {
+		c.AddMetric(m)
+	}
+	return id
+}
+
+func (c *trackingCollector) Delivered() <-chan telegraf.DeliveryInfo {
+	return c.delivered
+}
+
+func (c *trackingCollector) onDelivery(info telegraf.DeliveryInfo) {
+	c.delivered <- info
+}
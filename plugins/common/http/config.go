@@ -2,8 +2,11 @@ package httpconfig
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/benbjohnson/clock"
@@ -13,7 +16,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/common/cookie"
 	oauthConfig "github.com/influxdata/telegraf/plugins/common/oauth"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
-	"github.com/influxdata/telegraf/plugins/common/tls"
+	commontls "github.com/influxdata/telegraf/plugins/common/tls"
 )
 
 // Common HTTP client struct.
@@ -22,9 +25,14 @@ type HTTPClientConfig struct {
 	IdleConnTimeout     config.Duration `toml:"idle_conn_timeout"`
 	MaxIdleConns        int             `toml:"max_idle_conn"`
 	MaxIdleConnsPerHost int             `toml:"max_idle_conn_per_host"`
+	MaxConnsPerHost     int             `toml:"max_conn_per_host"`
+	DisableKeepAlives   bool            `toml:"disable_keep_alives"`
+	KeepAlivePeriod     config.Duration `toml:"keep_alive_period"`
+	EnableHTTP2         bool            `toml:"enable_http2"`
+	DNSRefreshInterval  config.Duration `toml:"dns_refresh_interval"`
 
 	proxy.HTTPProxy
-	tls.ClientConfig
+	commontls.ClientConfig
 	oauthConfig.OAuth2Config
 	cookie.CookieAuthConfig
 }
@@ -40,12 +48,26 @@ func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger
 		return nil, fmt.Errorf("failed to set proxy: %w", err)
 	}
 
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: time.Duration(h.KeepAlivePeriod),
+	}
+
 	transport := &http.Transport{
 		TLSClientConfig:     tlsCfg,
 		Proxy:               prox,
+		DialContext:         dialer.DialContext,
 		IdleConnTimeout:     time.Duration(h.IdleConnTimeout),
 		MaxIdleConns:        h.MaxIdleConns,
 		MaxIdleConnsPerHost: h.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     h.MaxConnsPerHost,
+		DisableKeepAlives:   h.DisableKeepAlives,
+		ForceAttemptHTTP2:   h.EnableHTTP2,
+	}
+	if !h.EnableHTTP2 {
+		// Setting an empty map disables HTTP/2 even if a proxy or the
+		// environment (GODEBUG) would enable it.
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
 	}
 
 	timeout := h.Timeout
@@ -53,8 +75,17 @@ func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger
 		timeout = config.Duration(time.Second * 5)
 	}
 
+	var roundTripper http.RoundTripper = transport
+	if h.DNSRefreshInterval > 0 {
+		roundTripper = &refreshingTransport{
+			Transport: transport,
+			interval:  time.Duration(h.DNSRefreshInterval),
+			last:      time.Now(),
+		}
+	}
+
 	client := &http.Client{
-		Transport: transport,
+		Transport: roundTripper,
 		Timeout:   time.Duration(timeout),
 	}
 
@@ -68,3 +99,26 @@ func (h *HTTPClientConfig) CreateClient(ctx context.Context, log telegraf.Logger
 
 	return client, nil
 }
+
+// refreshingTransport closes the idle connections of the transport in the
+// given interval. New connections resolve the host names again, so
+// long-lived connections do not pin a single backend of a DNS based load
+// balancer.
+type refreshingTransport struct {
+	*http.Transport
+	interval time.Duration
+
+	last time.Time
+	mu   sync.Mutex
+}
+
+func (t *refreshingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	if now := time.Now(); now.Sub(t.last) >= t.interval {
+		t.Transport.CloseIdleConnections()
+		t.last = now
+	}
+	t.mu.Unlock()
+
+	return t.Transport.RoundTrip(req)
+}
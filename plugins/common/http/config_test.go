@@ -0,0 +1,101 @@
+package httpconfig
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestTransportOptions(t *testing.T) {
+	cfg := &HTTPClientConfig{
+		MaxIdleConnsPerHost: 4,
+		MaxConnsPerHost:     8,
+		DisableKeepAlives:   true,
+	}
+	client, err := cfg.CreateClient(context.Background(), testutil.Logger{})
+	require.NoError(t, err)
+
+	transport, ok := client.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.Equal(t, 4, transport.MaxIdleConnsPerHost)
+	require.Equal(t, 8, transport.MaxConnsPerHost)
+	require.True(t, transport.DisableKeepAlives)
+	require.False(t, transport.ForceAttemptHTTP2)
+	require.NotNil(t, transport.TLSNextProto)
+
+	cfg = &HTTPClientConfig{EnableHTTP2: true}
+	client, err = cfg.CreateClient(context.Background(), testutil.Logger{})
+	require.NoError(t, err)
+	transport, ok = client.Transport.(*http.Transport)
+	require.True(t, ok)
+	require.True(t, transport.ForceAttemptHTTP2)
+	require.Nil(t, transport.TLSNextProto)
+}
+
+func TestHTTP2(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	for _, enabled := range []bool{false, true} {
+		cfg := &HTTPClientConfig{EnableHTTP2: enabled}
+		cfg.InsecureSkipVerify = true
+		client, err := cfg.CreateClient(context.Background(), testutil.Logger{})
+		require.NoError(t, err)
+
+		resp, err := client.Get(ts.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		if enabled {
+			require.Equal(t, 2, resp.ProtoMajor)
+		} else {
+			require.Equal(t, 1, resp.ProtoMajor)
+		}
+	}
+}
+
+func TestDNSRefreshInterval(t *testing.T) {
+	var connections atomic.Int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	cfg := &HTTPClientConfig{DNSRefreshInterval: config.Duration(50 * time.Millisecond)}
+	client, err := cfg.CreateClient(context.Background(), testutil.Logger{})
+	require.NoError(t, err)
+
+	get := func() {
+		resp, err := client.Get(ts.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// Connections are reused within the interval
+	get()
+	get()
+	require.Equal(t, int32(1), connections.Load())
+
+	// After the interval a new connection is established
+	time.Sleep(60 * time.Millisecond)
+	get()
+	require.Equal(t, int32(2), connections.Load())
+}
@@ -24,6 +24,7 @@ type ClientConfig struct {
 	Username        string
 	Password        string
 	Origin          string
+	MaxBulkRequests int
 	ProxyConfig     *ProxyConfig
 	tls.ClientConfig
 }
@@ -121,6 +122,31 @@ func NewClient(address string, config *ClientConfig) (*Client, error) {
 
 func (c *Client) read(requests []ReadRequest) ([]ReadResponse, error) {
 	jRequests := makeJolokiaRequests(requests, c.config.ProxyConfig)
+
+	// Split the requests into multiple bulk requests if the number of
+	// requests per bulk request is limited
+	batchSize := len(jRequests)
+	if c.config.MaxBulkRequests > 0 && c.config.MaxBulkRequests < batchSize {
+		batchSize = c.config.MaxBulkRequests
+	}
+
+	responses := make([]ReadResponse, 0, len(jRequests))
+	for start := 0; start < len(jRequests); start += batchSize {
+		end := start + batchSize
+		if end > len(jRequests) {
+			end = len(jRequests)
+		}
+		batch, err := c.bulkRead(jRequests[start:end])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, batch...)
+	}
+
+	return responses, nil
+}
+
+func (c *Client) bulkRead(jRequests []jolokiaRequest) ([]ReadResponse, error) {
 	requestBody, err := json.Marshal(jRequests)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,208 @@
+package jolokia2
+
+import (
+	"fmt"
+	"sort"
+)
+
+// profiles contains predefined metrics for commonly monitored applications.
+// The metric names are prefixed with the profile name to avoid collisions
+// when combining multiple profiles.
+var profiles = map[string][]MetricConfig{
+	"java": {
+		{
+			Name:  "java_runtime",
+			Mbean: "java.lang:type=Runtime",
+			Paths: []string{"Uptime"},
+		},
+		{
+			Name:  "java_memory",
+			Mbean: "java.lang:type=Memory",
+			Paths: []string{"HeapMemoryUsage", "NonHeapMemoryUsage", "ObjectPendingFinalizationCount"},
+		},
+		{
+			Name:    "java_garbage_collector",
+			Mbean:   "java.lang:name=*,type=GarbageCollector",
+			Paths:   []string{"CollectionTime", "CollectionCount"},
+			TagKeys: []string{"name"},
+		},
+		{
+			Name:  "java_threading",
+			Mbean: "java.lang:type=Threading",
+			Paths: []string{"TotalStartedThreadCount", "ThreadCount", "DaemonThreadCount", "PeakThreadCount"},
+		},
+		{
+			Name:  "java_class_loading",
+			Mbean: "java.lang:type=ClassLoading",
+			Paths: []string{"LoadedClassCount", "UnloadedClassCount", "TotalLoadedClassCount"},
+		},
+		{
+			Name:    "java_memory_pool",
+			Mbean:   "java.lang:name=*,type=MemoryPool",
+			Paths:   []string{"Usage", "PeakUsage", "CollectionUsage"},
+			TagKeys: []string{"name"},
+		},
+	},
+	"kafka": {
+		{
+			Name:        "kafka_controller",
+			Mbean:       "kafka.controller:name=*,type=*",
+			FieldPrefix: stringPtr("$1."),
+		},
+		{
+			Name:        "kafka_replica_manager",
+			Mbean:       "kafka.server:name=*,type=ReplicaManager",
+			FieldPrefix: stringPtr("$1."),
+		},
+		{
+			Name:        "kafka_purgatory",
+			Mbean:       "kafka.server:delayedOperation=*,name=*,type=DelayedOperationPurgatory",
+			FieldPrefix: stringPtr("$1."),
+			FieldName:   stringPtr("$2"),
+		},
+		{
+			Name:        "kafka_request",
+			Mbean:       "kafka.network:name=*,request=*,type=RequestMetrics",
+			FieldPrefix: stringPtr("$1."),
+			TagKeys:     []string{"request"},
+		},
+		{
+			Name:        "kafka_topics",
+			Mbean:       "kafka.server:name=*,type=BrokerTopicMetrics",
+			FieldPrefix: stringPtr("$1."),
+		},
+		{
+			Name:        "kafka_topic",
+			Mbean:       "kafka.server:name=*,topic=*,type=BrokerTopicMetrics",
+			FieldPrefix: stringPtr("$1."),
+			TagKeys:     []string{"topic"},
+		},
+		{
+			Name:      "kafka_partition",
+			Mbean:     "kafka.log:name=*,partition=*,topic=*,type=Log",
+			FieldName: stringPtr("$1"),
+			TagKeys:   []string{"topic", "partition"},
+		},
+		{
+			Name:      "kafka_partition",
+			Mbean:     "kafka.cluster:name=UnderReplicated,partition=*,topic=*,type=Partition",
+			FieldName: stringPtr("UnderReplicatedPartitions"),
+			TagKeys:   []string{"topic", "partition"},
+		},
+	},
+	"cassandra": {
+		{
+			Name:        "cassandra_cache",
+			Mbean:       "org.apache.cassandra.metrics:name=*,scope=*,type=Cache",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name", "scope"},
+		},
+		{
+			Name:        "cassandra_client",
+			Mbean:       "org.apache.cassandra.metrics:name=*,type=Client",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name"},
+		},
+		{
+			Name:        "cassandra_client_request",
+			Mbean:       "org.apache.cassandra.metrics:name=*,scope=*,type=ClientRequest",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name", "scope"},
+		},
+		{
+			Name:        "cassandra_commit_log",
+			Mbean:       "org.apache.cassandra.metrics:name=*,type=CommitLog",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name"},
+		},
+		{
+			Name:        "cassandra_compaction",
+			Mbean:       "org.apache.cassandra.metrics:name=*,type=Compaction",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name"},
+		},
+		{
+			Name:        "cassandra_dropped_message",
+			Mbean:       "org.apache.cassandra.metrics:name=*,scope=*,type=DroppedMessage",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name", "scope"},
+		},
+		{
+			Name:        "cassandra_storage",
+			Mbean:       "org.apache.cassandra.metrics:name=*,type=Storage",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name"},
+		},
+		{
+			Name:        "cassandra_table",
+			Mbean:       "org.apache.cassandra.metrics:keyspace=*,name=*,scope=*,type=Table",
+			FieldPrefix: stringPtr("$2_"),
+			TagKeys:     []string{"keyspace", "name", "scope"},
+		},
+		{
+			Name:        "cassandra_thread_pools",
+			Mbean:       "org.apache.cassandra.metrics:name=*,path=*,scope=*,type=ThreadPools",
+			FieldPrefix: stringPtr("$1_"),
+			TagKeys:     []string{"name", "path", "scope"},
+		},
+	},
+	"tomcat": {
+		{
+			Name:    "tomcat_global_request_processor",
+			Mbean:   "Catalina:name=*,type=GlobalRequestProcessor",
+			Paths:   []string{"requestCount", "bytesReceived", "bytesSent", "processingTime", "errorCount"},
+			TagKeys: []string{"name"},
+		},
+		{
+			Name:    "tomcat_jsp_monitor",
+			Mbean:   "Catalina:J2EEApplication=*,J2EEServer=*,WebModule=*,name=jsp,type=JspMonitor",
+			Paths:   []string{"jspReloadCount", "jspCount", "jspUnloadCount"},
+			TagKeys: []string{"J2EEApplication", "J2EEServer", "WebModule"},
+		},
+		{
+			Name:    "tomcat_thread_pool",
+			Mbean:   "Catalina:name=*,type=ThreadPool",
+			Paths:   []string{"maxThreads", "currentThreadCount", "currentThreadsBusy"},
+			TagKeys: []string{"name"},
+		},
+		{
+			Name:    "tomcat_servlet",
+			Mbean:   "Catalina:J2EEApplication=*,J2EEServer=*,WebModule=*,j2eeType=Servlet,name=*",
+			Paths:   []string{"processingTime", "errorCount", "requestCount"},
+			TagKeys: []string{"name", "J2EEApplication", "J2EEServer", "WebModule"},
+		},
+		{
+			Name:    "tomcat_cache",
+			Mbean:   "Catalina:context=*,host=*,name=Cache,type=WebResourceRoot",
+			Paths:   []string{"hitCount", "lookupCount"},
+			TagKeys: []string{"context", "host"},
+		},
+	},
+}
+
+// ProfileMetrics returns the metric configurations of the given profiles
+func ProfileMetrics(names []string) ([]MetricConfig, error) {
+	var metrics []MetricConfig
+	for _, name := range names {
+		configs, found := profiles[name]
+		if !found {
+			return nil, fmt.Errorf("unknown profile %q, available profiles: %v", name, ProfileNames())
+		}
+		metrics = append(metrics, configs...)
+	}
+	return metrics, nil
+}
+
+// ProfileNames returns the sorted names of all available profiles
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Shopify/sarama"
 	"github.com/influxdata/telegraf"
 	tgConf "github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 )
 
@@ -53,6 +54,7 @@ func (k *WriteConfig) SetConfig(config *sarama.Config, log telegraf.Logger) erro
 type Config struct {
 	SASLAuth
 	tls.ClientConfig
+	proxy.TCPProxy
 
 	Version          string           `toml:"version"`
 	ClientID         string           `toml:"client_id"`
@@ -119,6 +121,15 @@ func (k *Config) SetConfig(config *sarama.Config, log telegraf.Logger) error {
 		}
 	}
 
+	if k.UseProxy {
+		dialer, err := k.TCPProxy.Proxy()
+		if err != nil {
+			return fmt.Errorf("setting up proxy failed: %w", err)
+		}
+		config.Net.Proxy.Enable = true
+		config.Net.Proxy.Dialer = dialer
+	}
+
 	if k.KeepAlivePeriod != nil {
 		// Defaults to OS setting (15s currently)
 		config.Net.KeepAlive = time.Duration(*k.KeepAlivePeriod)
@@ -9,6 +9,7 @@ import (
 	paho "github.com/eclipse/paho.mqtt.golang"
 
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 )
 
@@ -20,6 +21,9 @@ type PublishProperties struct {
 	MessageExpiry  config.Duration   `toml:"message_expiry"`
 	TopicAlias     *uint16           `toml:"topic_alias"`
 	UserProperties map[string]string `toml:"user_properties"`
+
+	// Tags added as user properties to each message, used by the output
+	UserPropertyTags []string `toml:"user_property_tags"`
 }
 
 type MqttConfig struct {
@@ -37,6 +41,7 @@ type MqttConfig struct {
 	PublishPropertiesV5 *PublishProperties `toml:"v5"`
 
 	tls.ClientConfig
+	proxy.TCPProxy
 
 	AutoReconnect    bool        `toml:"-"`
 	OnConnectionLost func(error) `toml:"-"`
@@ -48,6 +53,10 @@ type MqttConfig struct {
 type Client interface {
 	Connect() (bool, error)
 	Publish(topic string, data []byte) error
+	// PublishWithProperties publishes the data with the given user properties
+	// added to the configured ones. The properties are ignored for protocols
+	// not supporting them.
+	PublishWithProperties(topic string, data []byte, userProperties map[string]string) error
 	SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error
 	AddRoute(topic string, callback paho.MessageHandler)
 	Close() error
@@ -70,6 +79,9 @@ func NewClient(cfg *MqttConfig) (Client, error) {
 	case "", "3.1.1":
 		return NewMQTTv311Client(cfg)
 	case "5":
+		if cfg.UseProxy {
+			return nil, errors.New("use_proxy is not supported with protocol 5")
+		}
 		return NewMQTTv5Client(cfg)
 	}
 	return nil, fmt.Errorf("unsuported protocol %q: must be \"3.1.1\" or \"5\"", cfg.Protocol)
@@ -1,9 +1,14 @@
 package mqtt
 
 import (
+	"net"
+	"net/url"
 	"testing"
 
+	mqttv3 "github.com/eclipse/paho.mqtt.golang"
 	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 )
 
 // Test that default client has random ID
@@ -24,3 +29,37 @@ func TestRandomClientID(t *testing.T) {
 	options2 := client2.client.OptionsReader()
 	require.NotEqual(t, options1.ClientID(), options2.ClientID())
 }
+
+func TestProxyConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	p := &proxy.TCPProxy{}
+	dialer, err := p.Proxy()
+	require.NoError(t, err)
+	connect := ProxyConnectionFunc(dialer)
+
+	conn, err := connect(&url.URL{Scheme: "tcp", Host: listener.Addr().String()}, *mqttv3.NewClientOptions())
+	require.NoError(t, err)
+	conn.Close()
+
+	_, err = connect(&url.URL{Scheme: "ws", Host: listener.Addr().String()}, *mqttv3.NewClientOptions())
+	require.ErrorContains(t, err, "not supported with a proxy")
+}
+
+func TestProxyProtocolV5(t *testing.T) {
+	cfg := &MqttConfig{
+		Servers:  []string{"tcp://localhost:1883"},
+		Protocol: "5",
+		TCPProxy: proxy.TCPProxy{UseProxy: true},
+	}
+	_, err := NewClient(cfg)
+	require.ErrorContains(t, err, "not supported with protocol 5")
+}
@@ -49,6 +49,14 @@ func NewMQTTv311Client(cfg *MqttConfig) (*mqttv311Client, error) {
 	}
 	opts.SetTLSConfig(tlsCfg)
 
+	if cfg.UseProxy {
+		dialer, err := cfg.TCPProxy.Proxy()
+		if err != nil {
+			return nil, fmt.Errorf("setting up proxy failed: %w", err)
+		}
+		opts.SetCustomOpenConnectionFn(ProxyConnectionFunc(dialer))
+	}
+
 	if !cfg.Username.Empty() {
 		user, err := cfg.Username.Get()
 		if err != nil {
@@ -113,6 +121,11 @@ func (m *mqttv311Client) Publish(topic string, body []byte) error {
 	return token.Error()
 }
 
+func (m *mqttv311Client) PublishWithProperties(topic string, body []byte, _ map[string]string) error {
+	// MQTT 3.1.1 does not support properties
+	return m.Publish(topic, body)
+}
+
 func (m *mqttv311Client) SubscribeMultiple(filters map[string]byte, callback mqttv3.MessageHandler) error {
 	token := m.client.SubscribeMultiple(filters, callback)
 	token.Wait()
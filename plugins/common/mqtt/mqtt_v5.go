@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"time"
 
 	mqttv5auto "github.com/eclipse/paho.golang/autopaho"
@@ -123,18 +124,56 @@ func (m *mqttv5Client) Connect() (bool, error) {
 }
 
 func (m *mqttv5Client) Publish(topic string, body []byte) error {
+	return m.PublishWithProperties(topic, body, nil)
+}
+
+func (m *mqttv5Client) PublishWithProperties(topic string, body []byte, userProperties map[string]string) error {
+	properties := m.properties
+	if len(userProperties) > 0 {
+		if properties == nil {
+			properties = &mqttv5.PublishProperties{}
+		} else {
+			// Copy the properties to not modify the configured ones
+			p := *properties
+			properties = &p
+		}
+		user := make(mqttv5.UserProperties, 0, len(properties.User)+len(userProperties))
+		user = append(user, properties.User...)
+		keys := make([]string, 0, len(userProperties))
+		for k := range userProperties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			user.Add(k, userProperties[k])
+		}
+		properties.User = user
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
 	defer cancel()
 
-	_, err := m.client.Publish(ctx, &mqttv5.Publish{
+	resp, err := m.client.Publish(ctx, &mqttv5.Publish{
 		Topic:      topic,
 		QoS:        byte(m.qos),
 		Retain:     m.retain,
 		Payload:    body,
-		Properties: m.properties,
+		Properties: properties,
 	})
+	if err != nil {
+		return err
+	}
+
+	// For QoS 1 and 2 the broker reports failures in the reason code of the
+	// acknowledgement
+	if resp != nil && resp.ReasonCode >= 0x80 {
+		if resp.Properties != nil && resp.Properties.ReasonString != "" {
+			return fmt.Errorf("publish rejected with reason code 0x%02x: %s", resp.ReasonCode, resp.Properties.ReasonString)
+		}
+		return fmt.Errorf("publish rejected with reason code 0x%02x", resp.ReasonCode)
+	}
 
-	return err
+	return nil
 }
 
 func (m *mqttv5Client) SubscribeMultiple(filters map[string]byte, callback paho.MessageHandler) error {
@@ -0,0 +1,58 @@
+package mqtt
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+
+	mqttv3 "github.com/eclipse/paho.mqtt.golang"
+
+	"github.com/influxdata/telegraf/plugins/common/proxy"
+)
+
+// ProxyConnectionFunc returns a function for establishing the broker
+// connections through the given proxy dialer. Only plain TCP and TLS
+// connections are supported.
+func ProxyConnectionFunc(dialer *proxy.ProxiedDialer) mqttv3.OpenConnectionFunc {
+	return func(uri *url.URL, options mqttv3.ClientOptions) (net.Conn, error) {
+		var secure bool
+		switch uri.Scheme {
+		case "tcp", "mqtt":
+		case "ssl", "tls", "tcps", "mqtts", "mqtt+ssl":
+			secure = true
+		default:
+			return nil, fmt.Errorf("scheme %q is not supported with a proxy", uri.Scheme)
+		}
+
+		ctx := context.Background()
+		if options.ConnectTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, options.ConnectTimeout)
+			defer cancel()
+		}
+
+		conn, err := dialer.DialContext(ctx, "tcp", uri.Host)
+		if err != nil {
+			return nil, err
+		}
+		if !secure {
+			return conn, nil
+		}
+
+		cfg := &tls.Config{}
+		if options.TLSConfig != nil {
+			cfg = options.TLSConfig.Clone()
+		}
+		if cfg.ServerName == "" {
+			cfg.ServerName = uri.Hostname()
+		}
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
@@ -4,34 +4,57 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"golang.org/x/net/http/httpproxy"
 	"golang.org/x/net/proxy"
 )
 
 type HTTPProxy struct {
-	UseSystemProxy bool   `toml:"use_system_proxy"`
-	HTTPProxyURL   string `toml:"http_proxy_url"`
+	UseSystemProxy bool     `toml:"use_system_proxy"`
+	HTTPProxyURL   string   `toml:"http_proxy_url"`
+	NoProxy        []string `toml:"no_proxy"`
 }
 
 type proxyFunc func(req *http.Request) (*url.URL, error)
 
 func (p *HTTPProxy) Proxy() (proxyFunc, error) {
+	var cfg *httpproxy.Config
 	if p.UseSystemProxy {
-		return http.ProxyFromEnvironment, nil
+		cfg = httpproxy.FromEnvironment()
+		if len(p.NoProxy) == 0 {
+			return http.ProxyFromEnvironment, nil
+		}
 	} else if len(p.HTTPProxyURL) > 0 {
 		address, err := url.Parse(p.HTTPProxyURL)
 		if err != nil {
 			return nil, fmt.Errorf("error parsing proxy url %q: %w", p.HTTPProxyURL, err)
 		}
-		return http.ProxyURL(address), nil
+		if len(p.NoProxy) == 0 {
+			return http.ProxyURL(address), nil
+		}
+		cfg = &httpproxy.Config{
+			HTTPProxy:  address.String(),
+			HTTPSProxy: address.String(),
+		}
+	} else {
+		return nil, nil
 	}
 
-	return nil, nil
+	// Exclude the given hosts in addition to the ones from the environment
+	exclude := append(strings.Split(cfg.NoProxy, ","), p.NoProxy...)
+	cfg.NoProxy = strings.Join(exclude, ",")
+	fn := cfg.ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return fn(req.URL)
+	}, nil
 }
 
 type TCPProxy struct {
-	UseProxy bool   `toml:"use_proxy"`
-	ProxyURL string `toml:"proxy_url"`
+	UseProxy bool     `toml:"use_proxy"`
+	ProxyURL string   `toml:"proxy_url"`
+	NoProxy  []string `toml:"no_proxy"`
 }
 
 func (p *TCPProxy) Proxy() (*ProxiedDialer, error) {
@@ -49,6 +72,13 @@ func (p *TCPProxy) Proxy() (*ProxiedDialer, error) {
 		} else {
 			dialer = proxy.FromEnvironment()
 		}
+
+		// Connect to the excluded hosts directly
+		if len(p.NoProxy) > 0 {
+			perHost := proxy.NewPerHost(dialer, proxy.Direct)
+			perHost.AddFromString(strings.Join(p.NoProxy, ","))
+			dialer = perHost
+		}
 	} else {
 		dialer = proxy.Direct
 	}
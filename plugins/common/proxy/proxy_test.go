@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPProxyNoProxy(t *testing.T) {
+	p := &HTTPProxy{
+		HTTPProxyURL: "http://proxy.example.com:3128",
+		NoProxy:      []string{".internal.example.com", "10.0.0.0/8"},
+	}
+	fn, err := p.Proxy()
+	require.NoError(t, err)
+
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{address: "https://influxdata.com/api", expected: "http://proxy.example.com:3128"},
+		{address: "http://db.internal.example.com:8086/write"},
+		{address: "http://10.1.2.3:8086/write"},
+		{address: "http://192.168.1.1:8086/write", expected: "http://proxy.example.com:3128"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.address, nil)
+			require.NoError(t, err)
+			u, err := fn(req)
+			require.NoError(t, err)
+			if tt.expected == "" {
+				require.Nil(t, u)
+			} else {
+				require.Equal(t, tt.expected, u.String())
+			}
+		})
+	}
+}
+
+func TestHTTPProxyUnset(t *testing.T) {
+	p := &HTTPProxy{NoProxy: []string{"localhost"}}
+	fn, err := p.Proxy()
+	require.NoError(t, err)
+	require.Nil(t, fn)
+}
+
+func TestTCPProxyNoProxy(t *testing.T) {
+	// HTTP CONNECT proxy counting the tunnel requests
+	var requests atomic.Int32
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer proxyServer.Close()
+
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer target.Close()
+	go func() {
+		for {
+			conn, err := target.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	u, err := url.Parse(proxyServer.URL)
+	require.NoError(t, err)
+
+	p := &TCPProxy{UseProxy: true, ProxyURL: u.String()}
+	dialer, err := p.Proxy()
+	require.NoError(t, err)
+	_, err = dialer.DialTimeout("tcp", target.Addr().String(), time.Second)
+	require.ErrorContains(t, err, "403 Forbidden")
+	require.Equal(t, int32(1), requests.Load())
+
+	p.NoProxy = []string{"127.0.0.0/8"}
+	dialer, err = p.Proxy()
+	require.NoError(t, err)
+	conn, err := dialer.DialTimeout("tcp", target.Addr().String(), time.Second)
+	require.NoError(t, err)
+	conn.Close()
+	require.Equal(t, int32(1), requests.Load())
+}
@@ -0,0 +1,279 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+)
+
+// maxBudget is the maximum number of retries saved up in the retry budget
+const maxBudget = 10.0
+
+// Class of an error deciding whether an operation is retried
+type Class int
+
+const (
+	// Transient errors are temporary failures, e.g. connection errors or
+	// internal server errors, which might succeed on retry
+	Transient Class = iota
+	// Throttled errors are returned if the server asked the client to slow
+	// down, the retry honors the delay requested by the server
+	Throttled
+	// Permanent errors are caused by invalid requests and will never succeed
+	Permanent
+)
+
+func (c Class) String() string {
+	switch c {
+	case Transient:
+		return "transient"
+	case Throttled:
+		return "throttled"
+	case Permanent:
+		return "permanent"
+	}
+	return fmt.Sprintf("unknown(%d)", int(c))
+}
+
+func parseClass(s string) (Class, error) {
+	switch s {
+	case "transient":
+		return Transient, nil
+	case "throttled":
+		return Throttled, nil
+	}
+	return Permanent, fmt.Errorf("invalid error class %q", s)
+}
+
+// ClassifiedError attaches the class to an error
+type ClassifiedError struct {
+	Err   error
+	Class Class
+	After time.Duration
+}
+
+func (e *ClassifiedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassifiedError) Unwrap() error {
+	return e.Err
+}
+
+// NewPermanentError marks the error as not retryable
+func NewPermanentError(err error) error {
+	return &ClassifiedError{Err: err, Class: Permanent}
+}
+
+// NewThrottledError marks the error as caused by throttling of the server.
+// The retry is delayed for at least the given duration.
+func NewThrottledError(err error, after time.Duration) error {
+	return &ClassifiedError{Err: err, Class: Throttled, After: after}
+}
+
+// Classify returns the class of the error and the minimum delay before the
+// next attempt. Errors not classified explicitly are transient, except for
+// canceled operations.
+func Classify(err error) (Class, time.Duration) {
+	var cerr *ClassifiedError
+	if errors.As(err, &cerr) {
+		return cerr.Class, cerr.After
+	}
+	if errors.Is(err, context.Canceled) {
+		return Permanent, 0
+	}
+	return Transient, 0
+}
+
+// ClassifyHTTPStatus returns the class for failed requests with the given
+// HTTP status code
+func ClassifyHTTPStatus(code int) Class {
+	switch {
+	case code == http.StatusTooManyRequests || code == http.StatusServiceUnavailable:
+		return Throttled
+	case code == http.StatusRequestTimeout:
+		return Transient
+	case code >= 400 && code < 500:
+		return Permanent
+	}
+	return Transient
+}
+
+// RetryConfig contains the settings for retrying failed operations within a
+// single flush of an output
+type RetryConfig struct {
+	MaxAttempts     int             `toml:"retry_max_attempts"`
+	InitialInterval config.Duration `toml:"retry_initial_interval"`
+	MaxInterval     config.Duration `toml:"retry_max_interval"`
+	Multiplier      float64         `toml:"retry_multiplier"`
+	Jitter          float64         `toml:"retry_jitter"`
+	Budget          float64         `toml:"retry_budget"`
+	RetryOn         []string        `toml:"retry_on"`
+}
+
+// Retrier retries failed operations using a jittered exponential backoff.
+// Each operation adds the configured budget ratio to the retry budget and each
+// retry consumes one unit, so the number of retries is limited relative to
+// the number of operations. This avoids overloading a recovering server with
+// retries of many clients.
+type Retrier struct {
+	attempts int
+	initial  time.Duration
+	max      time.Duration
+	factor   float64
+	jitter   float64
+	ratio    float64
+	classes  map[Class]bool
+	log      telegraf.Logger
+
+	budget float64
+	mu     sync.Mutex
+
+	// Function for waiting between the attempts, overridden in tests
+	sleep func(context.Context, time.Duration) error
+}
+
+// NewRetrier creates a retrier from the settings. Retries are disabled if the
+// maximum number of attempts is less than two.
+func (cfg *RetryConfig) NewRetrier(log telegraf.Logger) (*Retrier, error) {
+	r := &Retrier{
+		attempts: cfg.MaxAttempts,
+		initial:  time.Duration(cfg.InitialInterval),
+		max:      time.Duration(cfg.MaxInterval),
+		factor:   cfg.Multiplier,
+		jitter:   cfg.Jitter,
+		ratio:    cfg.Budget,
+		classes:  map[Class]bool{Transient: true, Throttled: true},
+		log:      log,
+		budget:   maxBudget,
+		sleep:    sleep,
+	}
+	if r.attempts < 2 {
+		return r, nil
+	}
+
+	if r.initial <= 0 {
+		return nil, errors.New("retry_initial_interval must be positive")
+	}
+	if r.max < r.initial {
+		return nil, errors.New("retry_max_interval must not be less than retry_initial_interval")
+	}
+	if r.factor < 1 {
+		return nil, errors.New("retry_multiplier must be at least 1")
+	}
+	if r.jitter < 0 || r.jitter > 1 {
+		return nil, errors.New("retry_jitter must be between 0 and 1")
+	}
+	if r.ratio < 0 {
+		return nil, errors.New("retry_budget must not be negative")
+	}
+
+	if len(cfg.RetryOn) > 0 {
+		r.classes = make(map[Class]bool, len(cfg.RetryOn))
+		for _, s := range cfg.RetryOn {
+			class, err := parseClass(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid retry_on setting: %w", err)
+			}
+			r.classes[class] = true
+		}
+	}
+
+	return r, nil
+}
+
+// Do calls the function until it succeeds, the error is not retryable, the
+// attempts or the retry budget are exhausted or the context is done. The last
+// error is returned.
+func (r *Retrier) Do(ctx context.Context, fn func() error) error {
+	r.deposit()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= r.attempts {
+			return err
+		}
+
+		class, after := Classify(err)
+		if !r.classes[class] {
+			return err
+		}
+
+		delay := r.backoff(attempt)
+		if after > delay {
+			// Do not block the flush if the server asks for a longer pause,
+			// the next flush will retry the write instead.
+			if after > r.max {
+				return err
+			}
+			delay = after
+		}
+
+		if !r.withdraw() {
+			r.log.Debugf("Retry budget exhausted, not retrying: %v", err)
+			return err
+		}
+
+		r.log.Debugf("Attempt %d of %d failed with %s error, retrying in %s: %v", attempt, r.attempts, class, delay, err)
+		if serr := r.sleep(ctx, delay); serr != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns the delay before the next attempt, the interval grows
+// exponentially with the number of attempts and is randomly shortened by up
+// to the jitter fraction.
+func (r *Retrier) backoff(attempt int) time.Duration {
+	interval := float64(r.initial) * math.Pow(r.factor, float64(attempt-1))
+	interval = math.Min(interval, float64(r.max))
+	interval -= interval * r.jitter * rand.Float64()
+	return time.Duration(interval)
+}
+
+func (r *Retrier) deposit() {
+	if r.ratio == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.budget = math.Min(r.budget+r.ratio, maxBudget)
+}
+
+func (r *Retrier) withdraw() bool {
+	if r.ratio == 0 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.budget < 1 {
+		return false
+	}
+	r.budget--
+	return true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
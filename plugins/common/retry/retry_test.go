@@ -0,0 +1,257 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newTestRetrier(t *testing.T, cfg *RetryConfig) (*Retrier, *[]time.Duration) {
+	r, err := cfg.NewRetrier(testutil.Logger{})
+	require.NoError(t, err)
+
+	var delays []time.Duration
+	r.sleep = func(_ context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+	return r, &delays
+}
+
+func TestRetryBackoff(t *testing.T) {
+	r, delays := newTestRetrier(t, &RetryConfig{
+		MaxAttempts:     5,
+		InitialInterval: config.Duration(100 * time.Millisecond),
+		MaxInterval:     config.Duration(300 * time.Millisecond),
+		Multiplier:      2,
+	})
+
+	var calls int
+	err := r.Do(context.Background(), func() error {
+		calls++
+		return errors.New("connection refused")
+	})
+	require.EqualError(t, err, "connection refused")
+	require.Equal(t, 5, calls)
+
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		300 * time.Millisecond,
+		300 * time.Millisecond,
+	}
+	require.Equal(t, expected, *delays)
+}
+
+func TestRetryJitter(t *testing.T) {
+	r, delays := newTestRetrier(t, &RetryConfig{
+		MaxAttempts:     20,
+		InitialInterval: config.Duration(time.Second),
+		MaxInterval:     config.Duration(time.Second),
+		Multiplier:      1,
+		Jitter:          0.5,
+	})
+
+	require.Error(t, r.Do(context.Background(), func() error { return errors.New("failed") }))
+	require.Len(t, *delays, 19)
+	for _, d := range *delays {
+		require.GreaterOrEqual(t, d, 500*time.Millisecond)
+		require.LessOrEqual(t, d, time.Second)
+	}
+}
+
+func TestRetrySuccess(t *testing.T) {
+	r, delays := newTestRetrier(t, &RetryConfig{
+		MaxAttempts:     3,
+		InitialInterval: config.Duration(time.Second),
+		MaxInterval:     config.Duration(time.Second),
+		Multiplier:      2,
+	})
+
+	var calls int
+	err := r.Do(context.Background(), func() error {
+		calls++
+		if calls < 2 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+	require.Len(t, *delays, 1)
+}
+
+func TestRetryClasses(t *testing.T) {
+	cfg := &RetryConfig{
+		MaxAttempts:     3,
+		InitialInterval: config.Duration(100 * time.Millisecond),
+		MaxInterval:     config.Duration(time.Second),
+		Multiplier:      2,
+	}
+
+	// Permanent errors are never retried
+	r, delays := newTestRetrier(t, cfg)
+	var calls int
+	err := r.Do(context.Background(), func() error {
+		calls++
+		return NewPermanentError(errors.New("bad request"))
+	})
+	require.EqualError(t, err, "bad request")
+	require.Equal(t, 1, calls)
+	require.Empty(t, *delays)
+
+	// Throttled errors wait for the requested time if longer than the backoff
+	r, delays = newTestRetrier(t, cfg)
+	calls = 0
+	err = r.Do(context.Background(), func() error {
+		calls++
+		return NewThrottledError(errors.New("too many requests"), 500*time.Millisecond)
+	})
+	require.Error(t, err)
+	require.Equal(t, 3, calls)
+	require.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, *delays)
+
+	// Delays requested by the server exceeding the maximum interval are left
+	// to the next flush
+	r, delays = newTestRetrier(t, cfg)
+	calls = 0
+	err = r.Do(context.Background(), func() error {
+		calls++
+		return NewThrottledError(errors.New("too many requests"), time.Minute)
+	})
+	require.Error(t, err)
+	require.Equal(t, 1, calls)
+	require.Empty(t, *delays)
+
+	// Restrict the retries to throttled errors
+	cfg.RetryOn = []string{"throttled"}
+	r, _ = newTestRetrier(t, cfg)
+	calls = 0
+	require.Error(t, r.Do(context.Background(), func() error {
+		calls++
+		return errors.New("connection refused")
+	}))
+	require.Equal(t, 1, calls)
+}
+
+func TestRetryBudget(t *testing.T) {
+	r, _ := newTestRetrier(t, &RetryConfig{
+		MaxAttempts:     3,
+		InitialInterval: config.Duration(time.Millisecond),
+		MaxInterval:     config.Duration(time.Millisecond),
+		Multiplier:      1,
+		Budget:          0.5,
+	})
+
+	var calls int
+	fail := func() error {
+		calls++
+		return errors.New("failed")
+	}
+
+	// The initial budget of ten retries shrinks by 1.5 with each operation
+	// retried twice, leaving a budget of two after five operations
+	for i := 0; i < 5; i++ {
+		require.Error(t, r.Do(context.Background(), fail))
+	}
+	require.Equal(t, 15, calls)
+
+	// Afterwards the deposits only allow a retry for every second operation
+	calls = 0
+	for i := 0; i < 2; i++ {
+		require.Error(t, r.Do(context.Background(), fail))
+	}
+	require.Equal(t, 5, calls)
+	calls = 0
+	for i := 0; i < 4; i++ {
+		require.Error(t, r.Do(context.Background(), fail))
+	}
+	require.Equal(t, 6, calls)
+}
+
+func TestRetryDisabled(t *testing.T) {
+	r, delays := newTestRetrier(t, &RetryConfig{})
+
+	var calls int
+	require.Error(t, r.Do(context.Background(), func() error {
+		calls++
+		return errors.New("failed")
+	}))
+	require.Equal(t, 1, calls)
+	require.Empty(t, *delays)
+}
+
+func TestRetryCanceled(t *testing.T) {
+	r, err := (&RetryConfig{
+		MaxAttempts:     3,
+		InitialInterval: config.Duration(time.Hour),
+		MaxInterval:     config.Duration(time.Hour),
+		Multiplier:      1,
+	}).NewRetrier(testutil.Logger{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	err = r.Do(ctx, func() error {
+		calls++
+		cancel()
+		return errors.New("failed")
+	})
+	require.EqualError(t, err, "failed")
+	require.Equal(t, 1, calls)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      RetryConfig
+		expected string
+	}{
+		{
+			name:     "missing interval",
+			cfg:      RetryConfig{MaxAttempts: 2, Multiplier: 1},
+			expected: "retry_initial_interval must be positive",
+		},
+		{
+			name: "invalid multiplier",
+			cfg: RetryConfig{
+				MaxAttempts:     2,
+				InitialInterval: config.Duration(time.Second),
+				MaxInterval:     config.Duration(time.Second),
+			},
+			expected: "retry_multiplier must be at least 1",
+		},
+		{
+			name: "invalid class",
+			cfg: RetryConfig{
+				MaxAttempts:     2,
+				InitialInterval: config.Duration(time.Second),
+				MaxInterval:     config.Duration(time.Second),
+				Multiplier:      2,
+				RetryOn:         []string{"permanent"},
+			},
+			expected: `invalid error class "permanent"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cfg.NewRetrier(testutil.Logger{})
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestClassifyHTTPStatus(t *testing.T) {
+	require.Equal(t, Throttled, ClassifyHTTPStatus(http.StatusTooManyRequests))
+	require.Equal(t, Throttled, ClassifyHTTPStatus(http.StatusServiceUnavailable))
+	require.Equal(t, Transient, ClassifyHTTPStatus(http.StatusRequestTimeout))
+	require.Equal(t, Transient, ClassifyHTTPStatus(http.StatusBadGateway))
+	require.Equal(t, Permanent, ClassifyHTTPStatus(http.StatusBadRequest))
+}
@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/youmark/pkcs8"
 )
@@ -28,6 +30,10 @@ type ClientConfig struct {
 	RenegotiationMethod string `toml:"tls_renegotiation_method"`
 	Enable              *bool  `toml:"tls_enable"`
 
+	TLSCertReloadInterval string   `toml:"tls_cert_reload_interval"`
+	SPIFFESocket          string   `toml:"tls_spiffe_socket"`
+	SPIFFEAllowedIDs      []string `toml:"tls_spiffe_allowed_ids"`
+
 	SSLCA   string `toml:"ssl_ca" deprecated:"1.7.0;use 'tls_ca' instead"`
 	SSLCert string `toml:"ssl_cert" deprecated:"1.7.0;use 'tls_cert' instead"`
 	SSLKey  string `toml:"ssl_key" deprecated:"1.7.0;use 'tls_key' instead"`
@@ -43,6 +49,15 @@ type ServerConfig struct {
 	TLSMinVersion      string   `toml:"tls_min_version"`
 	TLSMaxVersion      string   `toml:"tls_max_version"`
 	TLSAllowedDNSNames []string `toml:"tls_allowed_dns_names"`
+	TLSAllowedSANs     []string `toml:"tls_allowed_sans"`
+	TLSAllowedOUs      []string `toml:"tls_allowed_ous"`
+
+	TLSCertReloadInterval string   `toml:"tls_cert_reload_interval"`
+	SPIFFESocket          string   `toml:"tls_spiffe_socket"`
+	SPIFFEAllowedIDs      []string `toml:"tls_spiffe_allowed_ids"`
+
+	allowedSANs filter.Filter
+	allowedOUs  filter.Filter
 }
 
 // TLSConfig returns a tls.Config, may be nil without error if TLS is not
@@ -75,6 +90,7 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 	empty := c.TLSCA == "" && c.TLSKey == "" && c.TLSCert == ""
 	empty = empty && !c.InsecureSkipVerify && c.ServerName == ""
 	empty = empty && (c.RenegotiationMethod == "" || c.RenegotiationMethod == "never")
+	empty = empty && c.SPIFFESocket == ""
 
 	if empty {
 		// Check if TLS config is forcefully enabled and supposed to
@@ -112,10 +128,22 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 	}
 
 	if c.TLSCert != "" && c.TLSKey != "" {
-		err := loadCertificate(tlsConfig, c.TLSCert, c.TLSKey, c.TLSKeyPwd)
+		interval, err := parseReloadInterval(c.TLSCertReloadInterval)
 		if err != nil {
 			return nil, err
 		}
+		if interval > 0 {
+			reloader, err := newCertReloader(c.TLSCert, c.TLSKey, c.TLSKeyPwd, interval)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.GetClientCertificate = reloader.getClientCertificate
+		} else {
+			err := loadCertificate(tlsConfig, c.TLSCert, c.TLSKey, c.TLSKeyPwd)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Explicitly and consistently set the minimal accepted version using the
@@ -135,13 +163,24 @@ func (c *ClientConfig) TLSConfig() (*tls.Config, error) {
 		tlsConfig.ServerName = c.ServerName
 	}
 
+	// The SVID and trust bundle are fetched from the SPIFFE Workload API and
+	// replace the file based certificates.
+	if c.SPIFFESocket != "" {
+		if c.TLSCA != "" || c.TLSCert != "" || c.TLSKey != "" {
+			return nil, errors.New("tls_spiffe_socket cannot be used together with tls_ca, tls_cert or tls_key")
+		}
+		if err := hookSPIFFEClient(tlsConfig, c.SPIFFESocket, c.SPIFFEAllowedIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	return tlsConfig, nil
 }
 
 // TLSConfig returns a tls.Config, may be nil without error if TLS is not
 // configured.
 func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
-	if c.TLSCert == "" && c.TLSKey == "" && len(c.TLSAllowedCACerts) == 0 {
+	if c.TLSCert == "" && c.TLSKey == "" && len(c.TLSAllowedCACerts) == 0 && c.SPIFFESocket == "" {
 		return nil, nil
 	}
 
@@ -157,10 +196,22 @@ func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
 	}
 
 	if c.TLSCert != "" && c.TLSKey != "" {
-		err := loadCertificate(tlsConfig, c.TLSCert, c.TLSKey, c.TLSKeyPwd)
+		interval, err := parseReloadInterval(c.TLSCertReloadInterval)
 		if err != nil {
 			return nil, err
 		}
+		if interval > 0 {
+			reloader, err := newCertReloader(c.TLSCert, c.TLSKey, c.TLSKeyPwd, interval)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.GetCertificate = reloader.getCertificate
+		} else {
+			err := loadCertificate(tlsConfig, c.TLSCert, c.TLSKey, c.TLSKeyPwd)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if len(c.TLSCipherSuites) != 0 {
@@ -198,12 +249,42 @@ func (c *ServerConfig) TLSConfig() (*tls.Config, error) {
 		return nil, fmt.Errorf("tls min version %q can't be greater than tls max version %q", tlsConfig.MinVersion, tlsConfig.MaxVersion)
 	}
 
+	// Authorize clients by the names and organizational units of their
+	// certificates
+	if len(c.TLSAllowedSANs) > 0 || len(c.TLSAllowedOUs) > 0 {
+		if len(c.TLSAllowedCACerts) == 0 {
+			return nil, errors.New("tls_allowed_sans and tls_allowed_ous require tls_allowed_cacerts")
+		}
+		var err error
+		if len(c.TLSAllowedSANs) > 0 {
+			if c.allowedSANs, err = filter.Compile(c.TLSAllowedSANs); err != nil {
+				return nil, fmt.Errorf("could not compile tls_allowed_sans: %w", err)
+			}
+		}
+		if len(c.TLSAllowedOUs) > 0 {
+			if c.allowedOUs, err = filter.Compile(c.TLSAllowedOUs); err != nil {
+				return nil, fmt.Errorf("could not compile tls_allowed_ous: %w", err)
+			}
+		}
+	}
+
 	// Since clientAuth is tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
 	// there must be certs to validate.
-	if len(c.TLSAllowedCACerts) > 0 && len(c.TLSAllowedDNSNames) > 0 {
+	if len(c.TLSAllowedCACerts) > 0 && (len(c.TLSAllowedDNSNames) > 0 || c.allowedSANs != nil || c.allowedOUs != nil) {
 		tlsConfig.VerifyPeerCertificate = c.verifyPeerCertificate
 	}
 
+	// The SVID and trust bundle are fetched from the SPIFFE Workload API and
+	// clients are required to present an SVID of a trusted bundle.
+	if c.SPIFFESocket != "" {
+		if c.TLSCert != "" || c.TLSKey != "" || len(c.TLSAllowedCACerts) > 0 {
+			return nil, errors.New("tls_spiffe_socket cannot be used together with tls_cert, tls_key or tls_allowed_cacerts")
+		}
+		if err := hookSPIFFEServer(tlsConfig, c.SPIFFESocket, c.SPIFFEAllowedIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	return tlsConfig, nil
 }
 
@@ -221,6 +302,19 @@ func makeCertPool(certFiles []string) (*x509.CertPool, error) {
 	return pool, nil
 }
 
+// parseReloadInterval parses the certificate reload interval, an empty
+// setting disables reloading.
+func parseReloadInterval(interval string) (time.Duration, error) {
+	if interval == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse tls_cert_reload_interval %q: %w", interval, err)
+	}
+	return d, nil
+}
+
 func loadCertificate(config *tls.Config, certFile, keyFile, privateKeyPassphrase string) error {
 	certBytes, err := os.ReadFile(certFile)
 	if err != nil {
@@ -273,11 +367,77 @@ func (c *ServerConfig) verifyPeerCertificate(rawCerts [][]byte, _ [][]*x509.Cert
 		return fmt.Errorf("could not validate peer certificate: %w", err)
 	}
 
-	for _, name := range cert.DNSNames {
-		if choice.Contains(name, c.TLSAllowedDNSNames) {
-			return nil
+	if len(c.TLSAllowedDNSNames) > 0 {
+		var found bool
+		for _, name := range cert.DNSNames {
+			if choice.Contains(name, c.TLSAllowedDNSNames) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("peer certificate not in allowed DNS Name list: %v", cert.DNSNames)
+		}
+	}
+
+	if c.allowedSANs != nil && c.matchingSAN(cert) == "" {
+		return fmt.Errorf("peer certificate not in allowed SAN list: %v", subjectAltNames(cert))
+	}
+
+	if c.allowedOUs != nil {
+		var found bool
+		for _, ou := range cert.Subject.OrganizationalUnit {
+			if c.allowedOUs.Match(ou) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("peer certificate not in allowed organizational unit list: %v", cert.Subject.OrganizationalUnit)
 		}
 	}
 
-	return fmt.Errorf("peer certificate not in allowed DNS Name list: %v", cert.DNSNames)
+	return nil
+}
+
+// ClientIdentity returns the identity of the client certificate used for the
+// connection, or an empty string if the client did not present a certificate.
+// The identity is the first subject alternative name matching
+// tls_allowed_sans or, if unset, the first URI, DNS name, email address or IP
+// address of the certificate. Certificates without subject alternative names
+// are identified by the common name of the subject.
+func (c *ServerConfig) ClientIdentity(state *tls.ConnectionState) string {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return ""
+	}
+	cert := state.PeerCertificates[0]
+
+	if name := c.matchingSAN(cert); name != "" {
+		return name
+	}
+	return cert.Subject.CommonName
+}
+
+// matchingSAN returns the first subject alternative name of the certificate
+// allowed by tls_allowed_sans or the first name if no allowlist is set.
+func (c *ServerConfig) matchingSAN(cert *x509.Certificate) string {
+	for _, name := range subjectAltNames(cert) {
+		if c.allowedSANs == nil || c.allowedSANs.Match(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+func subjectAltNames(cert *x509.Certificate) []string {
+	names := make([]string, 0, len(cert.URIs)+len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.IPAddresses))
+	for _, u := range cert.URIs {
+		names = append(names, u.String())
+	}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return names
 }
@@ -1,9 +1,18 @@
 package tls_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	cryptotls "crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -612,3 +621,251 @@ func TestEnableFlagEnabled(t *testing.T) {
 	expected := &cryptotls.Config{}
 	require.Equal(t, expected, cfg)
 }
+
+func TestServerCertificateReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	copyFile(t, pki.ServerCertPath(), certFile)
+	copyFile(t, pki.ServerKeyPath(), keyFile)
+
+	serverConfig := tls.ServerConfig{
+		TLSCert:               certFile,
+		TLSKey:                keyFile,
+		TLSCertReloadInterval: "1ns",
+	}
+	serverTLSConfig, err := serverConfig.TLSConfig()
+	require.NoError(t, err)
+	require.Empty(t, serverTLSConfig.Certificates)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = serverTLSConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	client := http.Client{
+		Transport: &http.Transport{
+			// Send the server name as the test server uses its builtin
+			// certificate for connections without SNI
+			TLSClientConfig: &cryptotls.Config{
+				ServerName:         "localhost",
+				InsecureSkipVerify: true,
+			},
+			DisableKeepAlives: true,
+		},
+		Timeout: 10 * time.Second,
+	}
+	serial := func() string {
+		resp, err := client.Get(ts.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return resp.TLS.PeerCertificates[0].SerialNumber.String()
+	}
+	initial := serial()
+
+	// Replace the certificate and make sure the change is detected
+	copyFile(t, pki.ClientCertPath(), certFile)
+	copyFile(t, pki.ClientKeyPath(), keyFile)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+	require.NoError(t, os.Chtimes(keyFile, future, future))
+	require.NotEqual(t, initial, serial())
+
+	// Broken files keep the current certificate
+	current := serial()
+	require.NoError(t, os.WriteFile(keyFile, []byte("garbage"), 0600))
+	require.Equal(t, current, serial())
+}
+
+func TestCertificateReloadInvalidInterval(t *testing.T) {
+	clientConfig := tls.ClientConfig{
+		TLSCert:               pki.ClientCertPath(),
+		TLSKey:                pki.ClientKeyPath(),
+		TLSCertReloadInterval: "often",
+	}
+	_, err := clientConfig.TLSConfig()
+	require.ErrorContains(t, err, "could not parse tls_cert_reload_interval")
+}
+
+func TestClientCertificateReload(t *testing.T) {
+	clientConfig := tls.ClientConfig{
+		TLSCA:                 pki.CACertPath(),
+		TLSCert:               pki.ClientCertPath(),
+		TLSKey:                pki.ClientKeyPath(),
+		TLSCertReloadInterval: "5m",
+	}
+	serverConfig := tls.ServerConfig{
+		TLSCert:           pki.ServerCertPath(),
+		TLSKey:            pki.ServerKeyPath(),
+		TLSAllowedCACerts: []string{pki.CACertPath()},
+	}
+
+	serverTLSConfig, err := serverConfig.TLSConfig()
+	require.NoError(t, err)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = serverTLSConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	clientTLSConfig, err := clientConfig.TLSConfig()
+	require.NoError(t, err)
+	require.Empty(t, clientTLSConfig.Certificates)
+	require.NotNil(t, clientTLSConfig.GetClientCertificate)
+
+	client := http.Client{
+		Transport: &http.Transport{TLSClientConfig: clientTLSConfig},
+		Timeout:   10 * time.Second,
+	}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestSPIFFEConfigErrors(t *testing.T) {
+	clientConfig := tls.ClientConfig{
+		TLSCA:        pki.CACertPath(),
+		SPIFFESocket: "unix:///tmp/spire-agent.sock",
+	}
+	_, err := clientConfig.TLSConfig()
+	require.ErrorContains(t, err, "cannot be used together")
+
+	serverConfig := tls.ServerConfig{
+		TLSAllowedCACerts: []string{pki.CACertPath()},
+		SPIFFESocket:      "unix:///tmp/spire-agent.sock",
+	}
+	_, err = serverConfig.TLSConfig()
+	require.ErrorContains(t, err, "cannot be used together")
+
+	clientConfig = tls.ClientConfig{
+		SPIFFESocket:     "unix:///tmp/spire-agent.sock",
+		SPIFFEAllowedIDs: []string{"example.org/server"},
+	}
+	_, err = clientConfig.TLSConfig()
+	require.ErrorContains(t, err, "invalid SPIFFE ID")
+}
+
+func TestServerAuthorization(t *testing.T) {
+	cert := createCertificate(t, &x509.Certificate{
+		Subject: pkix.Name{
+			CommonName:         "client",
+			OrganizationalUnit: []string{"team-a"},
+		},
+		URIs:     []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/team-a/client"}},
+		DNSNames: []string{"client.team-a.example.org"},
+	})
+
+	tests := []struct {
+		name     string
+		sans     []string
+		ous      []string
+		expected string
+		identity string
+	}{
+		{
+			name:     "matching DNS name",
+			sans:     []string{"*.team-a.example.org"},
+			identity: "client.team-a.example.org",
+		},
+		{
+			name:     "matching URI",
+			sans:     []string{"spiffe://example.org/team-a/*"},
+			identity: "spiffe://example.org/team-a/client",
+		},
+		{
+			name:     "no matching name",
+			sans:     []string{"*.team-b.example.org"},
+			expected: "peer certificate not in allowed SAN list",
+		},
+		{
+			name:     "matching organizational unit",
+			ous:      []string{"team-*"},
+			identity: "spiffe://example.org/team-a/client",
+		},
+		{
+			name:     "no matching organizational unit",
+			sans:     []string{"*.team-a.example.org"},
+			ous:      []string{"team-b"},
+			expected: "peer certificate not in allowed organizational unit list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig := tls.ServerConfig{
+				TLSAllowedCACerts: []string{pki.CACertPath()},
+				TLSAllowedSANs:    tt.sans,
+				TLSAllowedOUs:     tt.ous,
+			}
+			serverTLSConfig, err := serverConfig.TLSConfig()
+			require.NoError(t, err)
+			require.NotNil(t, serverTLSConfig.VerifyPeerCertificate)
+
+			err = serverTLSConfig.VerifyPeerCertificate([][]byte{cert.Raw}, nil)
+			if tt.expected != "" {
+				require.ErrorContains(t, err, tt.expected)
+				return
+			}
+			require.NoError(t, err)
+
+			state := &cryptotls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+			require.Equal(t, tt.identity, serverConfig.ClientIdentity(state))
+		})
+	}
+}
+
+func TestServerAuthorizationWithoutCA(t *testing.T) {
+	serverConfig := tls.ServerConfig{
+		TLSCert:        pki.ServerCertPath(),
+		TLSKey:         pki.ServerKeyPath(),
+		TLSAllowedSANs: []string{"*.example.org"},
+	}
+	_, err := serverConfig.TLSConfig()
+	require.ErrorContains(t, err, "require tls_allowed_cacerts")
+}
+
+func TestClientIdentity(t *testing.T) {
+	var serverConfig tls.ServerConfig
+	require.Empty(t, serverConfig.ClientIdentity(nil))
+	require.Empty(t, serverConfig.ClientIdentity(&cryptotls.ConnectionState{}))
+
+	// Use the common name for certificates without SANs
+	cert := createCertificate(t, &x509.Certificate{Subject: pkix.Name{CommonName: "client"}})
+	state := &cryptotls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	require.Equal(t, "client", serverConfig.ClientIdentity(state))
+
+	cert = createCertificate(t, &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "client"},
+		DNSNames:       []string{"client.example.org"},
+		EmailAddresses: []string{"client@example.org"},
+	})
+	state = &cryptotls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+	require.Equal(t, "client.example.org", serverConfig.ClientIdentity(state))
+}
+
+// createCertificate creates a self-signed certificate from the template
+func createCertificate(t *testing.T, template *x509.Certificate) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template.SerialNumber = big.NewInt(1)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	raw, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(raw)
+	require.NoError(t, err)
+	return cert
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	buf, err := os.ReadFile(src)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(dst, buf, 0600))
+}
@@ -0,0 +1,114 @@
+package tls
+
+import (
+	"crypto/tls"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// certReloader provides the certificate for the TLS handshakes and reloads
+// it if the certificate or key file changed. The files are checked at most
+// once per interval. If reloading fails, the previous certificate is kept.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	password string
+	interval time.Duration
+
+	cert      *tls.Certificate
+	certStat  fileStat
+	keyStat   fileStat
+	lastCheck time.Time
+	mu        sync.Mutex
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func newCertReloader(certFile, keyFile, password string, interval time.Duration) (*certReloader, error) {
+	r := &certReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		password: password,
+		interval: interval,
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *certReloader) load() error {
+	// Get the file information before reading the files so a change while
+	// loading triggers another reload on the next check.
+	certStat, err := stat(r.certFile)
+	if err != nil {
+		return err
+	}
+	keyStat, err := stat(r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	var cfg tls.Config
+	if err := loadCertificate(&cfg, r.certFile, r.keyFile, r.password); err != nil {
+		return err
+	}
+	r.cert = &cfg.Certificates[0]
+	r.certStat = certStat
+	r.keyStat = keyStat
+	r.lastCheck = time.Now()
+	return nil
+}
+
+func (r *certReloader) certificate() *tls.Certificate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if time.Since(r.lastCheck) < r.interval {
+		return r.cert
+	}
+	r.lastCheck = time.Now()
+
+	certStat, err := stat(r.certFile)
+	if err != nil {
+		log.Printf("W! [tls] Checking certificate %q failed, keeping the current one: %v", r.certFile, err)
+		return r.cert
+	}
+	keyStat, err := stat(r.keyFile)
+	if err != nil {
+		log.Printf("W! [tls] Checking private key %q failed, keeping the current one: %v", r.keyFile, err)
+		return r.cert
+	}
+	if certStat == r.certStat && keyStat == r.keyStat {
+		return r.cert
+	}
+
+	if err := r.load(); err != nil {
+		log.Printf("W! [tls] Reloading certificate %q failed, keeping the current one: %v", r.certFile, err)
+		return r.cert
+	}
+	log.Printf("I! [tls] Reloaded certificate %q", r.certFile)
+	return r.cert
+}
+
+func (r *certReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.certificate(), nil
+}
+
+func (r *certReloader) getClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return r.certificate(), nil
+}
+
+func stat(filename string) (fileStat, error) {
+	// Follow symlinks as used for mounted Kubernetes secrets
+	info, err := os.Stat(filename)
+	if err != nil {
+		return fileStat{}, err
+	}
+	return fileStat{modTime: info.ModTime(), size: info.Size()}, nil
+}
@@ -0,0 +1,96 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// spiffeTimeout is the maximum time to wait for the initial SVID
+const spiffeTimeout = 10 * time.Second
+
+// The sources are shared between all plugins using the same Workload API
+// socket. They keep watching the SVID and bundle updates for the lifetime of
+// the process, so rotated certificates are used for new connections.
+var (
+	spiffeSources   = make(map[string]*workloadapi.X509Source)
+	spiffeSourcesMu sync.Mutex
+)
+
+func spiffeSource(socket string) (*workloadapi.X509Source, error) {
+	spiffeSourcesMu.Lock()
+	defer spiffeSourcesMu.Unlock()
+
+	if source, found := spiffeSources[socket]; found {
+		return source, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spiffeTimeout)
+	defer cancel()
+	source, err := workloadapi.NewX509Source(ctx, workloadapi.WithClientOptions(workloadapi.WithAddr(socket)))
+	if err != nil {
+		return nil, fmt.Errorf("fetching SVID from %q failed: %w", socket, err)
+	}
+	spiffeSources[socket] = source
+	return source, nil
+}
+
+// spiffeAuthorizer accepts peers with one of the given SPIFFE IDs. IDs without
+// a path allow all members of the trust domain. If no IDs are given, all peers
+// with an SVID of a trusted bundle are accepted.
+func spiffeAuthorizer(allowed []string) (tlsconfig.Authorizer, error) {
+	if len(allowed) == 0 {
+		return tlsconfig.AuthorizeAny(), nil
+	}
+
+	ids := make([]spiffeid.ID, 0, len(allowed))
+	for _, s := range allowed {
+		id, err := spiffeid.FromString(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPIFFE ID %q: %w", s, err)
+		}
+		ids = append(ids, id)
+	}
+
+	return func(actual spiffeid.ID, _ [][]*x509.Certificate) error {
+		for _, id := range ids {
+			if id.Path() == "" && actual.MemberOf(id.TrustDomain()) || id == actual {
+				return nil
+			}
+		}
+		return fmt.Errorf("unexpected SPIFFE ID %q", actual)
+	}, nil
+}
+
+func hookSPIFFEClient(cfg *tls.Config, socket string, allowed []string) error {
+	authorizer, err := spiffeAuthorizer(allowed)
+	if err != nil {
+		return err
+	}
+	source, err := spiffeSource(socket)
+	if err != nil {
+		return err
+	}
+	tlsconfig.HookMTLSClientConfig(cfg, source, source, authorizer)
+	return nil
+}
+
+func hookSPIFFEServer(cfg *tls.Config, socket string, allowed []string) error {
+	authorizer, err := spiffeAuthorizer(allowed)
+	if err != nil {
+		return err
+	}
+	source, err := spiffeSource(socket)
+	if err != nil {
+		return err
+	}
+	tlsconfig.HookMTLSServerConfig(cfg, source, source, authorizer)
+	return nil
+}
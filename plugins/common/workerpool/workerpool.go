@@ -0,0 +1,35 @@
+// Package workerpool provides a helper for plugins processing multiple
+// targets, e.g. servers, concurrently with a limited number of workers.
+package workerpool
+
+import "sync"
+
+// Run calls fn for each of the targets using at most the given number of
+// concurrent workers and blocks until all targets are processed. A
+// concurrency of zero or less processes all targets in parallel.
+func Run[T any](concurrency int, targets []T, fn func(T)) {
+	if len(targets) == 0 {
+		return
+	}
+	if concurrency <= 0 || concurrency > len(targets) {
+		concurrency = len(targets)
+	}
+
+	queue := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for target := range queue {
+				fn(target)
+			}
+		}()
+	}
+
+	for _, target := range targets {
+		queue <- target
+	}
+	close(queue)
+	wg.Wait()
+}
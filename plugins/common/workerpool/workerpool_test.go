@@ -0,0 +1,61 @@
+package workerpool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	targets := make([]int, 0, 100)
+	for i := 0; i < cap(targets); i++ {
+		targets = append(targets, i)
+	}
+
+	tests := []struct {
+		name        string
+		concurrency int
+		expected    int32
+	}{
+		{name: "serial", concurrency: 1, expected: 1},
+		{name: "limited", concurrency: 4, expected: 4},
+		{name: "unlimited", concurrency: 0, expected: 100},
+		{name: "more workers than targets", concurrency: 1000, expected: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			processed := make(map[int]bool, len(targets))
+			var running, maxRunning atomic.Int32
+
+			Run(tt.concurrency, targets, func(target int) {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					current := maxRunning.Load()
+					if n <= current || maxRunning.CompareAndSwap(current, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				processed[target] = true
+				mu.Unlock()
+			})
+
+			require.Len(t, processed, len(targets))
+			require.LessOrEqual(t, maxRunning.Load(), tt.expected)
+		})
+	}
+}
+
+func TestRunEmpty(t *testing.T) {
+	Run(4, []string{}, func(string) {
+		require.Fail(t, "unexpected call")
+	})
+}
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.etcd
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/etcd" // register plugin
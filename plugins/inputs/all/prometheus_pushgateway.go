@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.prometheus_pushgateway
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/prometheus_pushgateway" // register plugin
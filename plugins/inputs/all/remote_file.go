@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.remote_file
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/remote_file" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.smb_client
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/smb_client" // register plugin
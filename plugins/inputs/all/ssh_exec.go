@@ -0,0 +1,5 @@
+//go:build !custom || inputs || inputs.ssh_exec
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/inputs/ssh_exec" // register plugin
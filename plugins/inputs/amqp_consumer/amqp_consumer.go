@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/delivery"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -55,6 +56,7 @@ type AMQPConsumer struct {
 	// AMQP Auth method
 	AuthMethod string
 	tls.ClientConfig
+	delivery.DeliveryConfig
 
 	ContentEncoding      string      `toml:"content_encoding"`
 	MaxDecompressionSize config.Size `toml:"max_decompression_size"`
@@ -115,7 +117,7 @@ func (a *AMQPConsumer) Init() error {
 		a.MaxUndeliveredMessages = 1000
 	}
 
-	return nil
+	return a.DeliveryConfig.Init()
 }
 
 func (a *AMQPConsumer) SetParser(parser telegraf.Parser) {
@@ -377,7 +379,7 @@ func (a *AMQPConsumer) declareQueue(channel *amqp.Channel) (*amqp.Queue, error)
 func (a *AMQPConsumer) process(ctx context.Context, msgs <-chan amqp.Delivery, ac telegraf.Accumulator) {
 	a.deliveries = make(map[telegraf.TrackingID]amqp.Delivery)
 
-	acc := ac.WithTracking(a.MaxUndeliveredMessages)
+	acc := a.DeliveryConfig.WrapAccumulator(ac).WithTracking(a.MaxUndeliveredMessages)
 	sem := make(semaphore, a.MaxUndeliveredMessages)
 
 	for {
@@ -441,22 +443,22 @@ func (a *AMQPConsumer) onMessage(acc telegraf.TrackingAccumulator, d amqp.Delive
 }
 
 func (a *AMQPConsumer) onDelivery(track telegraf.DeliveryInfo) bool {
-	delivery, ok := a.deliveries[track.ID()]
+	msg, ok := a.deliveries[track.ID()]
 	if !ok {
 		// Added by a previous connection
 		return false
 	}
 
-	if track.Delivered() {
-		err := delivery.Ack(false)
+	if a.Delivered(track) {
+		err := msg.Ack(false)
 		if err != nil {
-			a.Log.Errorf("Unable to ack written delivery: %d: %v", delivery.DeliveryTag, err)
+			a.Log.Errorf("Unable to ack written delivery: %d: %v", msg.DeliveryTag, err)
 			a.conn.Close()
 		}
 	} else {
-		err := delivery.Reject(false)
+		err := msg.Reject(false)
 		if err != nil {
-			a.Log.Errorf("Unable to reject failed delivery: %d: %v", delivery.DeliveryTag, err)
+			a.Log.Errorf("Unable to reject failed delivery: %d: %v", msg.DeliveryTag, err)
 			a.conn.Close()
 		}
 	}
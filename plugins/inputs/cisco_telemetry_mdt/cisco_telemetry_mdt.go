@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"path"
 	"strconv"
@@ -44,11 +45,22 @@ const (
 // this value is specified in the GRPC docs via GRPC_ARG_HTTP2_MIN_RECV_PING_INTERVAL_WITHOUT_DATA_MS
 const defaultKeepaliveMinTime = config.Duration(time.Second * 300)
 
+// minimum window size accepted by GRPC, smaller values are ignored
+const minWindowSize = 64 * 1024
+
 type GRPCEnforcementPolicy struct {
 	PermitKeepaliveWithoutCalls bool            `toml:"permit_keepalive_without_calls"`
 	KeepaliveMinTime            config.Duration `toml:"keepalive_minimum_time"`
 }
 
+type GRPCKeepalive struct {
+	MaxConnectionIdle     config.Duration `toml:"max_connection_idle"`
+	MaxConnectionAge      config.Duration `toml:"max_connection_age"`
+	MaxConnectionAgeGrace config.Duration `toml:"max_connection_age_grace"`
+	Time                  config.Duration `toml:"time"`
+	Timeout               config.Duration `toml:"timeout"`
+}
+
 // CiscoTelemetryMDT plugin for IOS XR, IOS XE and NXOS platforms
 type CiscoTelemetryMDT struct {
 	// Common configuration
@@ -59,8 +71,14 @@ type CiscoTelemetryMDT struct {
 	Dmes               map[string]string     `toml:"dmes"`
 	EmbeddedTags       []string              `toml:"embedded_tags"`
 	EnforcementPolicy  GRPCEnforcementPolicy `toml:"grpc_enforcement_policy"`
+	Keepalive          GRPCKeepalive         `toml:"grpc_keepalive"`
+	WindowSize         config.Size           `toml:"grpc_initial_window_size"`
+	ConnWindowSize     config.Size           `toml:"grpc_initial_conn_window_size"`
 	IncludeDeleteField bool                  `toml:"include_delete_field"`
 	SourceFieldName    string                `toml:"source_field_name"`
+	SensorPathInclude  []string              `toml:"sensor_path_include"`
+	SensorPathExclude  []string              `toml:"sensor_path_exclude"`
+	DecoderWorkers     int                   `toml:"decoder_workers"`
 
 	Log telegraf.Logger
 
@@ -78,10 +96,16 @@ type CiscoTelemetryMDT struct {
 	extraTags       map[string]map[string]struct{}
 	nxpathMap       map[string]map[string]string //per path map
 	propMap         map[string]func(field *telemetry.TelemetryField, value interface{}) interface{}
+	pathFilter      *sensorPathFilter
 	mutex           sync.Mutex
 	acc             telegraf.Accumulator
 	wg              sync.WaitGroup
 
+	// Decoder workers
+	decodeQueue chan []byte
+	decodeDone  chan struct{}
+	decodeWg    sync.WaitGroup
+
 	// Though unused in the code, required by protoc-gen-go-grpc to maintain compatibility
 	dialout.UnimplementedGRPCMdtDialoutServer
 }
@@ -167,6 +191,19 @@ func (c *CiscoTelemetryMDT) Start(acc telegraf.Accumulator) error {
 		c.extraTags[dir][path.Base(tag)] = struct{}{}
 	}
 
+	c.pathFilter, err = newSensorPathFilter(c.SensorPathInclude, c.SensorPathExclude)
+	if err != nil {
+		c.listener.Close()
+		return err
+	}
+
+	// Create the queue before starting the transport to allow queueing
+	// messages; the workers are started once the transport is running
+	if c.DecoderWorkers > 1 {
+		c.decodeQueue = make(chan []byte, c.DecoderWorkers)
+		c.decodeDone = make(chan struct{})
+	}
+
 	switch c.Transport {
 	case "tcp":
 		// TCP dialout server accept routine
@@ -199,6 +236,31 @@ func (c *CiscoTelemetryMDT) Start(acc telegraf.Accumulator) error {
 			}))
 		}
 
+		if c.Keepalive != (GRPCKeepalive{}) {
+			opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
+				MaxConnectionIdle:     time.Duration(c.Keepalive.MaxConnectionIdle),
+				MaxConnectionAge:      time.Duration(c.Keepalive.MaxConnectionAge),
+				MaxConnectionAgeGrace: time.Duration(c.Keepalive.MaxConnectionAgeGrace),
+				Time:                  time.Duration(c.Keepalive.Time),
+				Timeout:               time.Duration(c.Keepalive.Timeout),
+			}))
+		}
+
+		if c.WindowSize > 0 {
+			if c.WindowSize < minWindowSize || c.WindowSize > math.MaxInt32 {
+				c.listener.Close()
+				return fmt.Errorf("invalid grpc_initial_window_size %d, must be between %d and %d", c.WindowSize, minWindowSize, math.MaxInt32)
+			}
+			opts = append(opts, grpc.InitialWindowSize(int32(c.WindowSize)))
+		}
+		if c.ConnWindowSize > 0 {
+			if c.ConnWindowSize < minWindowSize || c.ConnWindowSize > math.MaxInt32 {
+				c.listener.Close()
+				return fmt.Errorf("invalid grpc_initial_conn_window_size %d, must be between %d and %d", c.ConnWindowSize, minWindowSize, math.MaxInt32)
+			}
+			opts = append(opts, grpc.InitialConnWindowSize(int32(c.ConnWindowSize)))
+		}
+
 		c.grpcServer = grpc.NewServer(opts...)
 		dialout.RegisterGRPCMdtDialoutServer(c.grpcServer, c)
 
@@ -215,9 +277,61 @@ func (c *CiscoTelemetryMDT) Start(acc telegraf.Accumulator) error {
 		return fmt.Errorf("invalid Cisco MDT transport: %s", c.Transport)
 	}
 
+	if c.decodeQueue != nil {
+		for i := 0; i < c.DecoderWorkers; i++ {
+			c.decodeWg.Add(1)
+			go func() {
+				defer c.decodeWg.Done()
+				c.runDecoder()
+			}()
+		}
+	}
+
 	return nil
 }
 
+// runDecoder handles the queued telemetry messages until the plugin is stopped
+// and processes the remaining messages before returning
+func (c *CiscoTelemetryMDT) runDecoder() {
+	for {
+		select {
+		case data := <-c.decodeQueue:
+			c.handleTelemetry(data)
+		case <-c.decodeDone:
+			for {
+				select {
+				case data := <-c.decodeQueue:
+					c.handleTelemetry(data)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// decode filters the telemetry message by its encoding path and either passes
+// it to the decoder workers or decodes it directly if no workers are used
+func (c *CiscoTelemetryMDT) decode(data []byte) {
+	if c.pathFilter != nil {
+		// Let the decoding report invalid messages
+		if encodingPath, err := extractEncodingPath(data); err == nil && !c.pathFilter.match(encodingPath) {
+			return
+		}
+	}
+
+	if c.decodeQueue == nil {
+		c.handleTelemetry(data)
+		return
+	}
+
+	// Copy the data as the transports reuse their buffers
+	select {
+	case c.decodeQueue <- bytes.Clone(data):
+	case <-c.decodeDone:
+	}
+}
+
 // AcceptTCPDialoutClients defines the TCP dialout server main routine
 func (c *CiscoTelemetryMDT) acceptTCPClients() {
 	// Keep track of all active connections, so we can close them if necessary
@@ -306,7 +420,7 @@ func (c *CiscoTelemetryMDT) handleTCPClient(conn net.Conn) error {
 			return fmt.Errorf("TCP dialout premature EOF")
 		}
 
-		c.handleTelemetry(payload.Bytes())
+		c.decode(payload.Bytes())
 	}
 }
 
@@ -335,13 +449,13 @@ func (c *CiscoTelemetryMDT) MdtDialout(stream dialout.GRPCMdtDialout_MdtDialoutS
 
 		// Reassemble chunked telemetry data received from NX-OS
 		if packet.TotalSize == 0 {
-			c.handleTelemetry(packet.Data)
+			c.decode(packet.Data)
 		} else if int(packet.TotalSize) <= c.MaxMsgSize {
 			if _, err := chunkBuffer.Write(packet.Data); err != nil {
 				c.acc.AddError(fmt.Errorf("writing packet %q failed: %w", packet.Data, err))
 			}
 			if chunkBuffer.Len() >= int(packet.TotalSize) {
-				c.handleTelemetry(chunkBuffer.Bytes())
+				c.decode(chunkBuffer.Bytes())
 				chunkBuffer.Reset()
 			}
 		} else {
@@ -739,6 +853,11 @@ func (c *CiscoTelemetryMDT) Stop() {
 		c.listener.Close()
 	}
 	c.wg.Wait()
+
+	if c.decodeDone != nil {
+		close(c.decodeDone)
+		c.decodeWg.Wait()
+	}
 }
 
 // Gather plugin measurements (unused)
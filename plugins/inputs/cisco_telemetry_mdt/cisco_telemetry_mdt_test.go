@@ -17,6 +17,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -1032,3 +1033,198 @@ func TestSourceFieldRewrite(t *testing.T) {
 	fields := map[string]interface{}{"bool": false}
 	acc.AssertContainsTaggedFields(t, "alias", fields, tags)
 }
+
+func TestSensorPathFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		path     string
+		expected bool
+	}{
+		{
+			name:     "exact",
+			include:  []string{"Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters"},
+			path:     "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters",
+			expected: true,
+		},
+		{
+			name:     "leading slashes",
+			include:  []string{"/Cisco-IOS-XR-infra-statsd-oper:/infra-statistics/interfaces/interface/latest/generic-counters"},
+			path:     "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters",
+			expected: true,
+		},
+		{
+			name:     "single element wildcard",
+			include:  []string{"Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/*/latest/*"},
+			path:     "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters",
+			expected: true,
+		},
+		{
+			name:     "single element wildcard too short",
+			include:  []string{"Cisco-IOS-XR-infra-statsd-oper:infra-statistics/*"},
+			path:     "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters",
+			expected: false,
+		},
+		{
+			name:     "multi element wildcard",
+			include:  []string{"Cisco-IOS-XR-infra-statsd-oper:infra-statistics/**"},
+			path:     "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters",
+			expected: true,
+		},
+		{
+			name:     "no match",
+			include:  []string{"Cisco-IOS-XR-wdsysmon-fd-oper:system-monitoring/**"},
+			path:     "Cisco-IOS-XR-infra-statsd-oper:infra-statistics/interfaces/interface/latest/generic-counters",
+			expected: false,
+		},
+		{
+			name:     "excluded",
+			exclude:  []string{"sys/intf/**"},
+			path:     "sys/intf/phys-[eth1/1]",
+			expected: false,
+		},
+		{
+			name:     "included but excluded",
+			include:  []string{"sys/**"},
+			exclude:  []string{"sys/intf/**"},
+			path:     "sys/intf/phys-[eth1/1]",
+			expected: false,
+		},
+		{
+			name:     "not excluded",
+			exclude:  []string{"sys/intf/**"},
+			path:     "show ip route summary",
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newSensorPathFilter(tt.include, tt.exclude)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, f.match(tt.path))
+		})
+	}
+}
+
+func TestExtractEncodingPath(t *testing.T) {
+	data, err := proto.Marshal(mockTelemetryMessage())
+	require.NoError(t, err)
+
+	encodingPath, err := extractEncodingPath(data)
+	require.NoError(t, err)
+	require.Equal(t, "type:model/some/path", encodingPath)
+
+	// Encoding path field with a length exceeding the data
+	_, err = extractEncodingPath([]byte{0x32, 0x10, 'a'})
+	require.Error(t, err)
+}
+
+func TestTCPDialoutDecoderWorkers(t *testing.T) {
+	c := &CiscoTelemetryMDT{
+		Log:               testutil.Logger{},
+		Transport:         "tcp",
+		ServiceAddress:    "127.0.0.1:0",
+		Aliases:           map[string]string{"some": "type:model/some/path", "other": "type:model/other/path"},
+		SensorPathExclude: []string{"type:model/other/*"},
+		DecoderWorkers:    4,
+	}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, c.Start(acc))
+
+	hdr := struct {
+		MsgType       uint16
+		MsgEncap      uint16
+		MsgHdrVersion uint16
+		MsgFlags      uint16
+		MsgLen        uint32
+	}{}
+
+	addr := c.Address()
+	conn, err := net.Dial(addr.Network(), addr.String())
+	require.NoError(t, err)
+
+	telemetry := mockTelemetryMessage()
+	for i := 0; i < 10; i++ {
+		telemetry.EncodingPath = "type:model/some/path"
+		if i%2 == 1 {
+			telemetry.EncodingPath = "type:model/other/path"
+		}
+		telemetry.MsgTimestamp = uint64(1543236572000 + i*1000)
+		data, err := proto.Marshal(telemetry)
+		require.NoError(t, err)
+		hdr.MsgLen = uint32(len(data))
+		require.NoError(t, binary.Write(conn, binary.BigEndian, hdr))
+		_, err = conn.Write(data)
+		require.NoError(t, err)
+	}
+
+	// We use the invalid dialout flags to let the server close the connection
+	_, err = conn.Write([]byte{0, 0, 0, 0, 0, 0, 1, 1, 0, 0, 0, 0})
+	require.NoError(t, err)
+	_, err = conn.Read([]byte{0})
+	require.True(t, err == nil || errors.Is(err, io.EOF))
+	c.Stop()
+	require.NoError(t, conn.Close())
+
+	require.Equal(t, acc.Errors, []error{errors.New("invalid dialout flags: 257")})
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 5)
+	for _, m := range metrics {
+		require.Equal(t, "some", m.Name())
+	}
+}
+
+func TestGRPCDialoutKeepaliveParameters(t *testing.T) {
+	c := &CiscoTelemetryMDT{
+		Log:            testutil.Logger{},
+		Transport:      "grpc",
+		ServiceAddress: "127.0.0.1:0",
+		Aliases:        map[string]string{"some": "type:model/some/path"},
+		Keepalive: GRPCKeepalive{
+			MaxConnectionIdle: config.Duration(time.Minute),
+			Time:              config.Duration(30 * time.Second),
+			Timeout:           config.Duration(10 * time.Second),
+		},
+		WindowSize:     config.Size(1024 * 1024),
+		ConnWindowSize: config.Size(4 * 1024 * 1024),
+		DecoderWorkers: 2,
+	}
+	acc := &testutil.Accumulator{}
+	require.NoError(t, c.Start(acc))
+
+	addr := c.Address()
+	conn, err := grpc.Dial(addr.String(), grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	require.NoError(t, err)
+	client := dialout.NewGRPCMdtDialoutClient(conn)
+	stream, err := client.MdtDialout(context.Background())
+	require.NoError(t, err)
+
+	data, err := proto.Marshal(mockTelemetryMessage())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&dialout.MdtDialoutArgs{Data: data, ReqId: 456}))
+	require.NoError(t, stream.Send(&dialout.MdtDialoutArgs{Errors: "testclose"}))
+	_, err = stream.Recv()
+	require.True(t, err == nil || errors.Is(err, io.EOF))
+
+	c.Stop()
+	require.NoError(t, conn.Close())
+
+	require.Equal(t, acc.Errors, []error{errors.New("GRPC dialout error: testclose")})
+	tags := map[string]string{"path": "type:model/some/path", "name": "str", "source": "hostname", "subscription": "subscription"}
+	fields := map[string]interface{}{"value": int64(-1)}
+	acc.AssertContainsTaggedFields(t, "some", fields, tags)
+}
+
+func TestGRPCInvalidWindowSize(t *testing.T) {
+	c := &CiscoTelemetryMDT{
+		Log:            testutil.Logger{},
+		Transport:      "grpc",
+		ServiceAddress: "127.0.0.1:0",
+		WindowSize:     config.Size(1024),
+	}
+	acc := &testutil.Accumulator{}
+	require.ErrorContains(t, c.Start(acc), "invalid grpc_initial_window_size 1024")
+}
@@ -0,0 +1,103 @@
+package cisco_telemetry_mdt
+
+import (
+	"fmt"
+	"strings"
+
+	telemetry "github.com/cisco-ie/nx-telemetry-proto/telemetry_bis"
+	"github.com/gobwas/glob"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field number of the encoding path in the telemetry message
+var encodingPathNumber = (&telemetry.Telemetry{}).ProtoReflect().Descriptor().Fields().ByName("encoding_path").Number()
+
+// sensorPathFilter matches the encoding path of telemetry messages against
+// gNMI-style paths where a single wildcard matches one path element and a
+// double wildcard matches any number of elements.
+type sensorPathFilter struct {
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+func newSensorPathFilter(include, exclude []string) (*sensorPathFilter, error) {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil, nil
+	}
+
+	f := &sensorPathFilter{}
+	for _, pattern := range include {
+		g, err := glob.Compile(normalizeSensorPath(pattern), '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid sensor path include %q: %w", pattern, err)
+		}
+		f.include = append(f.include, g)
+	}
+	for _, pattern := range exclude {
+		g, err := glob.Compile(normalizeSensorPath(pattern), '/')
+		if err != nil {
+			return nil, fmt.Errorf("invalid sensor path exclude %q: %w", pattern, err)
+		}
+		f.exclude = append(f.exclude, g)
+	}
+	return f, nil
+}
+
+func (f *sensorPathFilter) match(encodingPath string) bool {
+	p := normalizeSensorPath(encodingPath)
+
+	matched := len(f.include) == 0
+	for _, g := range f.include {
+		if g.Match(p) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return false
+	}
+	for _, g := range f.exclude {
+		if g.Match(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeSensorPath removes the leading slashes of the path and of the path
+// following the origin, so "/origin:/a/b", "origin:/a/b" and "origin:a/b" are
+// treated as the same path.
+func normalizeSensorPath(p string) string {
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.IndexRune(p, ':'); i >= 0 && !strings.ContainsRune(p[:i], '/') {
+		p = p[:i+1] + strings.TrimPrefix(p[i+1:], "/")
+	}
+	return p
+}
+
+// extractEncodingPath reads the encoding path from the serialized telemetry
+// message without decoding the remaining message
+func extractEncodingPath(data []byte) (string, error) {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if num == encodingPathNumber && typ == protowire.BytesType {
+			v, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return "", protowire.ParseError(n)
+			}
+			return string(v), nil
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, data)
+		if n < 0 {
+			return "", protowire.ParseError(n)
+		}
+		data = data[n:]
+	}
+	return "", nil
+}
@@ -150,6 +150,13 @@ func (c *Conntrack) Gather(acc telegraf.Accumulator) error {
 			"Is the conntrack kernel module loaded?")
 	}
 
+	// Exhausting the table drops new connections, so expose the usage
+	count, hasCount := fields["ip_conntrack_count"].(float64)
+	size, hasSize := fields["ip_conntrack_max"].(float64)
+	if hasCount && hasSize && size > 0 {
+		fields["ip_conntrack_utilization"] = count / size * 100.0
+	}
+
 	acc.AddFields(inputName, fields, nil)
 	return nil
 }
@@ -99,6 +99,28 @@ func TestConfigsUsed(t *testing.T) {
 		})
 }
 
+func TestUtilization(t *testing.T) {
+	tmpdir := t.TempDir()
+	require.NoError(t, os.WriteFile(path.Join(tmpdir, "nf_conntrack_count"), []byte("1000\n"), 0640))
+	require.NoError(t, os.WriteFile(path.Join(tmpdir, "nf_conntrack_max"), []byte("4000\n"), 0640))
+
+	c := &Conntrack{
+		Dirs:  []string{tmpdir},
+		Files: []string{"nf_conntrack_count", "nf_conntrack_max"},
+	}
+	require.NoError(t, c.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, c.Gather(acc))
+
+	acc.AssertContainsFields(t, inputName,
+		map[string]interface{}{
+			"ip_conntrack_count":       float64(1000),
+			"ip_conntrack_max":         float64(4000),
+			"ip_conntrack_utilization": float64(25),
+		})
+}
+
 func TestCollectStats(t *testing.T) {
 	var mps system.MockPS
 	defer mps.AssertExpectations(t)
@@ -164,7 +186,7 @@ func TestCollectStats(t *testing.T) {
 	acc.AssertContainsFields(t, inputName, expectedFields)
 	acc.AssertContainsTaggedFields(t, inputName, expectedFields, expectedTags)
 
-	require.Equal(t, 19, acc.NFields())
+	require.Equal(t, 20, acc.NFields())
 }
 
 func TestCollectStatsPerCpu(t *testing.T) {
@@ -328,7 +350,7 @@ func TestCollectStatsPerCpu(t *testing.T) {
 			"cpu": "all",
 		})
 
-	require.Equal(t, 53, acc.NFields())
+	require.Equal(t, 54, acc.NFields())
 }
 
 func TestCollectPsSystemInit(t *testing.T) {
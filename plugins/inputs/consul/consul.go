@@ -2,13 +2,16 @@
 package consul
 
 import (
+	"context"
 	_ "embed"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/hashicorp/consul/api"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -27,6 +30,7 @@ type Consul struct {
 	tls.ClientConfig
 	TagDelimiter  string
 	MetricVersion int
+	ClusterHealth []string `toml:"cluster_health"`
 	Log           telegraf.Logger
 
 	// client used to connect to Consul agnet
@@ -42,6 +46,10 @@ func (c *Consul) Init() error {
 		c.Log.Warnf("Use of deprecated configuration: 'metric_version = 1'; please update to 'metric_version = 2'")
 	}
 
+	if err := choice.CheckSlice(c.ClusterHealth, []string{"autopilot", "license", "peering"}); err != nil {
+		return fmt.Errorf("invalid 'cluster_health': %w", err)
+	}
+
 	return nil
 }
 
@@ -147,6 +155,139 @@ func (c *Consul) Gather(acc telegraf.Accumulator) error {
 
 	c.GatherHealthCheck(acc, checks)
 
+	for _, kind := range c.ClusterHealth {
+		var err error
+		switch kind {
+		case "autopilot":
+			err = c.gatherAutopilot(acc)
+		case "license":
+			err = c.gatherLicense(acc)
+		case "peering":
+			err = c.gatherPeerings(acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s health failed: %w", kind, err))
+		}
+	}
+
+	return nil
+}
+
+// gatherAutopilot reports the raft health of the cluster and its servers as
+// determined by autopilot on the leader
+func (c *Consul) gatherAutopilot(acc telegraf.Accumulator) error {
+	reply, err := c.client.Operator().AutopilotServerHealth(nil)
+	if err != nil {
+		return err
+	}
+
+	var leader string
+	var voters int
+	for _, server := range reply.Servers {
+		if server.Leader {
+			leader = server.Name
+		}
+		if server.Voter {
+			voters++
+		}
+	}
+
+	tags := map[string]string{}
+	if leader != "" {
+		tags["leader"] = leader
+	}
+	fields := map[string]interface{}{
+		"healthy":           reply.Healthy,
+		"failure_tolerance": reply.FailureTolerance,
+		"servers":           len(reply.Servers),
+		"voters":            voters,
+	}
+	acc.AddFields("consul_autopilot", fields, tags)
+
+	for _, server := range reply.Servers {
+		tags := map[string]string{
+			"server_id":   server.ID,
+			"server_name": server.Name,
+			"address":     server.Address,
+			"version":     server.Version,
+			"serf_status": server.SerfStatus,
+		}
+		if leader != "" {
+			tags["leader"] = leader
+		}
+		fields := map[string]interface{}{
+			"healthy":         server.Healthy,
+			"is_leader":       server.Leader,
+			"voter":           server.Voter,
+			"last_contact_ns": server.LastContact.Duration().Nanoseconds(),
+			"last_term":       server.LastTerm,
+			"last_index":      server.LastIndex,
+			"stable_since":    server.StableSince.Unix(),
+		}
+		acc.AddFields("consul_autopilot_server", fields, tags)
+	}
+
+	return nil
+}
+
+// gatherLicense reports the license state, only available for Consul
+// Enterprise
+func (c *Consul) gatherLicense(acc telegraf.Accumulator) error {
+	reply, err := c.client.Operator().LicenseGet(nil)
+	if err != nil {
+		return err
+	}
+
+	tags := map[string]string{}
+	fields := map[string]interface{}{
+		"valid":    reply.Valid,
+		"warnings": len(reply.Warnings),
+	}
+	if reply.License != nil {
+		tags["license_id"] = reply.License.LicenseID
+		tags["product"] = reply.License.Product
+		fields["expiration_time"] = reply.License.ExpirationTime.Unix()
+		fields["termination_time"] = reply.License.TerminationTime.Unix()
+	}
+	acc.AddFields("consul_license", fields, tags)
+
+	return nil
+}
+
+// gatherPeerings reports the state of the peering connections to other
+// clusters
+func (c *Consul) gatherPeerings(acc telegraf.Accumulator) error {
+	peerings, _, err := c.client.Peerings().List(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+
+	for _, peering := range peerings {
+		tags := map[string]string{
+			"peer_name":         peering.Name,
+			"state":             string(peering.State),
+			"remote_datacenter": peering.Remote.Datacenter,
+		}
+		if peering.Partition != "" {
+			tags["partition"] = peering.Partition
+		}
+		fields := map[string]interface{}{
+			"active":            peering.State == api.PeeringStateActive,
+			"imported_services": len(peering.StreamStatus.ImportedServices),
+			"exported_services": len(peering.StreamStatus.ExportedServices),
+		}
+		if t := peering.StreamStatus.LastHeartbeat; t != nil {
+			fields["last_heartbeat"] = t.Unix()
+		}
+		if t := peering.StreamStatus.LastReceive; t != nil {
+			fields["last_receive"] = t.Unix()
+		}
+		if t := peering.StreamStatus.LastSend; t != nil {
+			fields["last_send"] = t.Unix()
+		}
+		acc.AddFields("consul_peering", fields, tags)
+	}
+
 	return nil
 }
 
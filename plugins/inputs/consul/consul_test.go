@@ -1,9 +1,17 @@
 package consul
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -135,3 +143,187 @@ func TestGatherHealthCheckWithDelimitedTagsV2(t *testing.T) {
 
 	acc.AssertContainsTaggedFields(t, "consul_health_checks", expectedFields, expectedTags)
 }
+
+func TestInitInvalidClusterHealth(t *testing.T) {
+	plugin := &Consul{
+		MetricVersion: 2,
+		ClusterHealth: []string{"autopilot", "quorum"},
+		Log:           testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid 'cluster_health'")
+}
+
+func TestGatherClusterHealth(t *testing.T) {
+	responses := map[string]string{
+		"/v1/health/state/any": `[]`,
+		"/v1/operator/autopilot/health": `{
+  "Healthy": true,
+  "FailureTolerance": 1,
+  "Servers": [
+    {
+      "ID": "e349749b-3303-3ddf-959c-b5885a0e1f6e",
+      "Name": "node1",
+      "Address": "127.0.0.1:8300",
+      "SerfStatus": "alive",
+      "Version": "1.16.0",
+      "Leader": true,
+      "LastContact": "0s",
+      "LastTerm": 2,
+      "LastIndex": 46,
+      "Healthy": true,
+      "Voter": true,
+      "StableSince": "2023-07-20T10:00:00Z"
+    },
+    {
+      "ID": "e36ee410-cc3c-0a0c-c724-63817ab30303",
+      "Name": "node2",
+      "Address": "127.0.0.2:8300",
+      "SerfStatus": "alive",
+      "Version": "1.16.0",
+      "Leader": false,
+      "LastContact": "15ms",
+      "LastTerm": 2,
+      "LastIndex": 45,
+      "Healthy": true,
+      "Voter": true,
+      "StableSince": "2023-07-20T10:00:05Z"
+    }
+  ]
+}`,
+		"/v1/operator/license": `{
+  "Valid": true,
+  "License": {
+    "license_id": "2afbf681-0d1a-0649-cb6c-2c1ff6fd7a42",
+    "product": "consul",
+    "expiration_time": "2024-01-01T00:00:00Z",
+    "termination_time": "2024-02-01T00:00:00Z"
+  },
+  "Warnings": []
+}`,
+		"/v1/peerings": `[
+  {
+    "ID": "462c45e8-018e-f19d-85eb-1fc1bcc2ef12",
+    "Name": "cluster-02",
+    "State": "ACTIVE",
+    "StreamStatus": {
+      "ImportedServices": ["web", "api"],
+      "ExportedServices": ["db"],
+      "LastHeartbeat": "2023-07-20T10:01:00Z"
+    },
+    "Remote": {"Datacenter": "dc2"}
+  }
+]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, found := responses[r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(body))
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	plugin := &Consul{
+		Address:       u.Host,
+		Scheme:        "http",
+		MetricVersion: 2,
+		ClusterHealth: []string{"autopilot", "license", "peering"},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"consul_autopilot",
+			map[string]string{"leader": "node1"},
+			map[string]interface{}{
+				"healthy":           true,
+				"failure_tolerance": 1,
+				"servers":           2,
+				"voters":            2,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"consul_autopilot_server",
+			map[string]string{
+				"server_id":   "e349749b-3303-3ddf-959c-b5885a0e1f6e",
+				"server_name": "node1",
+				"address":     "127.0.0.1:8300",
+				"version":     "1.16.0",
+				"serf_status": "alive",
+				"leader":      "node1",
+			},
+			map[string]interface{}{
+				"healthy":         true,
+				"is_leader":       true,
+				"voter":           true,
+				"last_contact_ns": int64(0),
+				"last_term":       uint64(2),
+				"last_index":      uint64(46),
+				"stable_since":    int64(1689847200),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"consul_autopilot_server",
+			map[string]string{
+				"server_id":   "e36ee410-cc3c-0a0c-c724-63817ab30303",
+				"server_name": "node2",
+				"address":     "127.0.0.2:8300",
+				"version":     "1.16.0",
+				"serf_status": "alive",
+				"leader":      "node1",
+			},
+			map[string]interface{}{
+				"healthy":         true,
+				"is_leader":       false,
+				"voter":           true,
+				"last_contact_ns": int64(15 * time.Millisecond),
+				"last_term":       uint64(2),
+				"last_index":      uint64(45),
+				"stable_since":    int64(1689847205),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"consul_license",
+			map[string]string{
+				"license_id": "2afbf681-0d1a-0649-cb6c-2c1ff6fd7a42",
+				"product":    "consul",
+			},
+			map[string]interface{}{
+				"valid":            true,
+				"warnings":         0,
+				"expiration_time":  int64(1704067200),
+				"termination_time": int64(1706745600),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"consul_peering",
+			map[string]string{
+				"peer_name":         "cluster-02",
+				"state":             "ACTIVE",
+				"remote_datacenter": "dc2",
+			},
+			map[string]interface{}{
+				"active":            true,
+				"imported_services": 2,
+				"exported_services": 1,
+				"last_heartbeat":    int64(1689847260),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
@@ -2,6 +2,8 @@
 package directory_monitor
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bufio"
 	"compress/gzip"
 	"context"
@@ -17,6 +19,7 @@ import (
 	"time"
 
 	"github.com/djherbis/times"
+	"github.com/klauspost/compress/zstd"
 	"golang.org/x/sync/semaphore"
 
 	"github.com/influxdata/telegraf"
@@ -37,6 +40,7 @@ var (
 	defaultDirectoryDurationThreshold = config.Duration(0 * time.Millisecond)
 	defaultFileQueueSize              = 100000
 	defaultParseMethod                = "line-by-line"
+	defaultConcurrency                = 1
 )
 
 type DirectoryMonitor struct {
@@ -53,6 +57,8 @@ type DirectoryMonitor struct {
 	Log                        telegraf.Logger `toml:"-"`
 	FileQueueSize              int             `toml:"file_queue_size"`
 	ParseMethod                string          `toml:"parse_method"`
+	Concurrency                int             `toml:"concurrency"`
+	WriteErrorFile             bool            `toml:"write_error_file"`
 
 	filesInUse          sync.Map
 	cancel              context.CancelFunc
@@ -144,12 +150,15 @@ func (monitor *DirectoryMonitor) Start(acc telegraf.Accumulator) error {
 		}
 	}()
 
-	// Monitor the files channel and read what they receive.
-	monitor.waitGroup.Add(1)
-	go func() {
-		monitor.Monitor()
-		monitor.waitGroup.Done()
-	}()
+	// Monitor the files channel and read what they receive using the
+	// configured number of workers.
+	for i := 0; i < monitor.Concurrency; i++ {
+		monitor.waitGroup.Add(1)
+		go func() {
+			monitor.Monitor()
+			monitor.waitGroup.Done()
+		}()
+	}
 
 	return nil
 }
@@ -217,6 +226,9 @@ func (monitor *DirectoryMonitor) read(filePath string) {
 		monitor.filesDroppedDir.Incr(1)
 		if monitor.ErrorDirectory != "" {
 			monitor.moveFile(filePath, monitor.ErrorDirectory)
+			if monitor.WriteErrorFile {
+				monitor.writeErrorFile(filePath, err)
+			}
 		}
 		return
 	}
@@ -234,23 +246,109 @@ func (monitor *DirectoryMonitor) ingestFile(filePath string) error {
 	}
 	defer file.Close()
 
+	if filepath.Ext(filePath) == ".zip" {
+		return monitor.ingestZip(file)
+	}
+
+	// Handle compressed files.
+	reader, name, err := decompress(file, file.Name())
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	if filepath.Ext(name) == ".tar" {
+		return monitor.ingestTar(reader)
+	}
+	return monitor.ingestReader(reader, file.Name())
+}
+
+// ingestReader parses the content of a single file with a new parser
+func (monitor *DirectoryMonitor) ingestReader(reader io.Reader, fileName string) error {
 	parser, err := monitor.parserFunc()
 	if err != nil {
 		return fmt.Errorf("creating parser: %w", err)
 	}
 
-	// Handle gzipped files.
-	var reader io.Reader
-	if filepath.Ext(filePath) == ".gz" {
-		reader, err = gzip.NewReader(file)
+	return monitor.parseFile(parser, reader, fileName)
+}
+
+// ingestTar parses all regular files contained in the tar archive
+func (monitor *DirectoryMonitor) ingestTar(reader io.Reader) error {
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
 		if err != nil {
-			return err
+			return fmt.Errorf("reading archive failed: %w", err)
 		}
-	} else {
-		reader = file
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := monitor.ingestReader(archive, header.Name); err != nil {
+			return fmt.Errorf("parsing %q in archive failed: %w", header.Name, err)
+		}
+	}
+}
+
+// ingestZip parses all regular files contained in the zip archive
+func (monitor *DirectoryMonitor) ingestZip(file *os.File) error {
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	archive, err := zip.NewReader(file, stat.Size())
+	if err != nil {
+		return fmt.Errorf("reading archive failed: %w", err)
+	}
+	for _, f := range archive.File {
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		if err := monitor.ingestZipFile(f); err != nil {
+			return fmt.Errorf("parsing %q in archive failed: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (monitor *DirectoryMonitor) ingestZipFile(f *zip.File) error {
+	reader, err := f.Open()
+	if err != nil {
+		return err
 	}
+	defer reader.Close()
 
-	return monitor.parseFile(parser, reader, file.Name())
+	return monitor.ingestReader(reader, f.Name)
+}
+
+// decompress returns a reader decompressing the file according to its
+// extension and the file name without the compression extension
+func decompress(file io.Reader, name string) (io.ReadCloser, string, error) {
+	switch filepath.Ext(name) {
+	case ".gz":
+		reader, err := gzip.NewReader(file)
+		return reader, strings.TrimSuffix(name, ".gz"), err
+	case ".tgz":
+		reader, err := gzip.NewReader(file)
+		return reader, strings.TrimSuffix(name, ".tgz") + ".tar", err
+	case ".zst":
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			return nil, "", err
+		}
+		return decoder.IOReadCloser(), strings.TrimSuffix(name, ".zst"), nil
+	case ".tzst":
+		decoder, err := zstd.NewReader(file)
+		if err != nil {
+			return nil, "", err
+		}
+		return decoder.IOReadCloser(), strings.TrimSuffix(name, ".tzst") + ".tar", nil
+	}
+	return io.NopCloser(file), name, nil
 }
 
 func (monitor *DirectoryMonitor) parseFile(parser telegraf.Parser, reader io.Reader, fileName string) error {
@@ -365,6 +463,16 @@ func (monitor *DirectoryMonitor) moveFile(srcPath string, dstBaseDir string) {
 	}
 }
 
+// writeErrorFile writes the error next to the file moved to the error
+// directory to ease finding the cause of the failure
+func (monitor *DirectoryMonitor) writeErrorFile(srcPath string, fileErr error) {
+	basePath := strings.Replace(srcPath, monitor.Directory, "", 1)
+	dstPath := filepath.Join(monitor.ErrorDirectory, basePath) + ".error"
+	if err := os.WriteFile(dstPath, []byte(fileErr.Error()+"\n"), 0640); err != nil {
+		monitor.Log.Errorf("Writing error file failed: %s", err)
+	}
+}
+
 func (monitor *DirectoryMonitor) isMonitoredFile(fileName string) bool {
 	if len(monitor.fileRegexesToMatch) == 0 {
 		return true
@@ -404,6 +512,10 @@ func (monitor *DirectoryMonitor) Init() error {
 		return errors.New("file queue size needs to be more than 0")
 	}
 
+	if monitor.Concurrency <= 0 {
+		monitor.Concurrency = 1
+	}
+
 	// Finished directory can be created if not exists for convenience.
 	if _, err := os.Stat(monitor.FinishedDirectory); os.IsNotExist(err) {
 		err = os.Mkdir(monitor.FinishedDirectory, 0750)
@@ -469,6 +581,7 @@ func init() {
 			DirectoryDurationThreshold: defaultDirectoryDurationThreshold,
 			FileQueueSize:              defaultFileQueueSize,
 			ParseMethod:                defaultParseMethod,
+			Concurrency:                defaultConcurrency,
 		}
 	})
 }
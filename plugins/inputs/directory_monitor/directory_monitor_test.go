@@ -1,13 +1,18 @@
 package directory_monitor
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
@@ -28,6 +33,7 @@ func TestCreator(t *testing.T) {
 		DirectoryDurationThreshold: defaultDirectoryDurationThreshold,
 		FileQueueSize:              defaultFileQueueSize,
 		ParseMethod:                defaultParseMethod,
+		Concurrency:                defaultConcurrency,
 	}
 
 	require.Equal(t, expected, creator())
@@ -680,3 +686,188 @@ func TestParseSubdirectoriesFilesIgnore(t *testing.T) {
 	_, err = os.Stat(filepath.Join(finishedDirectory, testJSONFile))
 	require.NoError(t, err)
 }
+
+func TestArchives(t *testing.T) {
+	csvFiles := map[string]string{
+		"a.csv":     "thing,color\nsky,blue\n",
+		"sub/b.csv": "thing,color\ngrass,green\nclifford,red\n",
+	}
+
+	// Create the different archives
+	var tarBuf bytes.Buffer
+	tw := tar.NewWriter(&tarBuf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "sub/", Typeflag: tar.TypeDir, Mode: 0750}))
+	for _, name := range []string{"a.csv", "sub/b.csv"} {
+		content := csvFiles[name]
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0640, Size: int64(len(content))}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+
+	var tgzBuf bytes.Buffer
+	gw := gzip.NewWriter(&tgzBuf)
+	_, err := gw.Write(tarBuf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+
+	var tzstBuf bytes.Buffer
+	zw, err := zstd.NewWriter(&tzstBuf)
+	require.NoError(t, err)
+	_, err = zw.Write(tarBuf.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var zstBuf bytes.Buffer
+	zw, err = zstd.NewWriter(&zstBuf)
+	require.NoError(t, err)
+	_, err = zw.Write([]byte(csvFiles["sub/b.csv"]))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	var zipBuf bytes.Buffer
+	zipw := zip.NewWriter(&zipBuf)
+	for _, name := range []string{"a.csv", "sub/b.csv"} {
+		w, err := zipw.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(csvFiles[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zipw.Close())
+
+	tests := []struct {
+		filename string
+		content  []byte
+		expected int
+	}{
+		{filename: "test.tar", content: tarBuf.Bytes(), expected: 3},
+		{filename: "test.tar.gz", content: tgzBuf.Bytes(), expected: 3},
+		{filename: "test.tgz", content: tgzBuf.Bytes(), expected: 3},
+		{filename: "test.tar.zst", content: tzstBuf.Bytes(), expected: 3},
+		{filename: "test.tzst", content: tzstBuf.Bytes(), expected: 3},
+		{filename: "test.csv.zst", content: zstBuf.Bytes(), expected: 2},
+		{filename: "test.zip", content: zipBuf.Bytes(), expected: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			finishedDirectory := t.TempDir()
+			processDirectory := t.TempDir()
+
+			r := DirectoryMonitor{
+				Directory:          processDirectory,
+				FinishedDirectory:  finishedDirectory,
+				FileTag:            "filename",
+				MaxBufferedMetrics: defaultMaxBufferedMetrics,
+				FileQueueSize:      defaultFileQueueSize,
+				ParseMethod:        defaultParseMethod,
+				Log:                testutil.Logger{},
+			}
+			require.NoError(t, r.Init())
+			r.SetParserFunc(func() (telegraf.Parser, error) {
+				parser := csv.Parser{
+					HeaderRowCount: 1,
+					TagColumns:     []string{"thing"},
+				}
+				err := parser.Init()
+				return &parser, err
+			})
+
+			require.NoError(t, os.WriteFile(filepath.Join(processDirectory, tt.filename), tt.content, 0640))
+
+			var acc testutil.Accumulator
+			require.NoError(t, r.Start(&acc))
+			require.NoError(t, r.Gather(&acc))
+			acc.Wait(tt.expected)
+			r.Stop()
+
+			require.NoError(t, acc.FirstError())
+			require.Len(t, acc.Metrics, tt.expected)
+			for _, m := range acc.GetTelegrafMetrics() {
+				v, found := m.GetTag("filename")
+				require.True(t, found)
+				require.Contains(t, []string{"a.csv", "b.csv", tt.filename}, v)
+			}
+
+			_, err = os.Stat(filepath.Join(finishedDirectory, tt.filename))
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestErrorFile(t *testing.T) {
+	finishedDirectory := t.TempDir()
+	errorDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		ErrorDirectory:     errorDirectory,
+		WriteErrorFile:     true,
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        "at-once",
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, r.Init())
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		p := &json.Parser{NameKey: "name"}
+		err := p.Init()
+		return p, err
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(processDirectory, "test.json"), []byte("{invalid"), 0640))
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(errorDirectory, "test.json.error"))
+		return err == nil
+	}, 3*time.Second, 10*time.Millisecond)
+	r.Stop()
+
+	_, err := os.Stat(filepath.Join(errorDirectory, "test.json"))
+	require.NoError(t, err)
+	buf, err := os.ReadFile(filepath.Join(errorDirectory, "test.json.error"))
+	require.NoError(t, err)
+	require.NotEmpty(t, buf)
+}
+
+func TestConcurrency(t *testing.T) {
+	finishedDirectory := t.TempDir()
+	processDirectory := t.TempDir()
+
+	r := DirectoryMonitor{
+		Directory:          processDirectory,
+		FinishedDirectory:  finishedDirectory,
+		MaxBufferedMetrics: defaultMaxBufferedMetrics,
+		FileQueueSize:      defaultFileQueueSize,
+		ParseMethod:        defaultParseMethod,
+		Concurrency:        4,
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, r.Init())
+	r.SetParserFunc(func() (telegraf.Parser, error) {
+		p := &json.Parser{NameKey: "name"}
+		err := p.Init()
+		return p, err
+	})
+
+	for i := 0; i < 20; i++ {
+		content := fmt.Sprintf(`{"name": "test", "value": %d}`, i)
+		require.NoError(t, os.WriteFile(filepath.Join(processDirectory, fmt.Sprintf("test%d.json", i)), []byte(content), 0640))
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, r.Start(&acc))
+	require.NoError(t, r.Gather(&acc))
+	acc.Wait(20)
+	r.Stop()
+
+	require.Len(t, acc.Metrics, 20)
+	files, err := os.ReadDir(finishedDirectory)
+	require.NoError(t, err)
+	require.Len(t, files, 20)
+}
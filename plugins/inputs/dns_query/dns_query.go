@@ -2,11 +2,17 @@
 package dns_query
 
 import (
+	"bytes"
+	"crypto/tls"
 	_ "embed"
 	"errors"
 	"fmt"
+	"io"
 	"net"
+	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,6 +20,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -26,18 +34,27 @@ const (
 	Success ResultType = iota
 	Timeout
 	Error
+	Mismatch
 )
 
 type DNSQuery struct {
-	Domains       []string        `toml:"domains"`
-	Network       string          `toml:"network"`
-	Servers       []string        `toml:"servers"`
-	RecordType    string          `toml:"record_type"`
-	Port          int             `toml:"port"`
-	Timeout       config.Duration `toml:"timeout"`
-	IncludeFields []string        `toml:"include_fields"`
+	Domains         []string        `toml:"domains"`
+	Network         string          `toml:"network"`
+	Servers         []string        `toml:"servers"`
+	RecordType      string          `toml:"record_type"`
+	Port            int             `toml:"port"`
+	Timeout         config.Duration `toml:"timeout"`
+	IncludeFields   []string        `toml:"include_fields"`
+	DNSSEC          bool            `toml:"dnssec"`
+	ExpectedIPs     []string        `toml:"expected_ips"`
+	ExpectedPattern string          `toml:"expected_pattern"`
+	tlsint.ClientConfig
 
 	fieldEnabled map[string]bool
+	expectedIPs  map[string]bool
+	expected     *regexp.Regexp
+	tlsConfig    *tls.Config
+	httpClient   *http.Client
 }
 
 func (*DNSQuery) SampleConfig() string {
@@ -60,6 +77,9 @@ func (d *DNSQuery) Init() error {
 	if d.Network == "" {
 		d.Network = "udp"
 	}
+	if err := choice.Check(d.Network, []string{"udp", "tcp", "tcp-tls", "https"}); err != nil {
+		return fmt.Errorf("invalid 'network': %w", err)
+	}
 
 	if d.RecordType == "" {
 		d.RecordType = "NS"
@@ -71,7 +91,49 @@ func (d *DNSQuery) Init() error {
 	}
 
 	if d.Port < 1 {
-		d.Port = 53
+		switch d.Network {
+		case "tcp-tls":
+			d.Port = 853
+		case "https":
+			d.Port = 443
+		default:
+			d.Port = 53
+		}
+	}
+
+	if len(d.ExpectedIPs) > 0 {
+		d.expectedIPs = make(map[string]bool, len(d.ExpectedIPs))
+		for _, addr := range d.ExpectedIPs {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				return fmt.Errorf("invalid expected IP %q", addr)
+			}
+			d.expectedIPs[ip.String()] = true
+		}
+	}
+	if d.ExpectedPattern != "" {
+		var err error
+		d.expected, err = regexp.Compile(d.ExpectedPattern)
+		if err != nil {
+			return fmt.Errorf("compiling expected pattern failed: %w", err)
+		}
+	}
+
+	if d.Network == "tcp-tls" || d.Network == "https" {
+		var err error
+		d.tlsConfig, err = d.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		if d.tlsConfig == nil {
+			d.tlsConfig = &tls.Config{}
+		}
+	}
+	if d.Network == "https" {
+		d.httpClient = &http.Client{
+			Transport: &http.Transport{TLSClientConfig: d.tlsConfig},
+			Timeout:   time.Duration(d.Timeout),
+		}
 	}
 
 	return nil
@@ -115,11 +177,6 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 		"result_code":   uint64(Error),
 	}
 
-	c := dns.Client{
-		ReadTimeout: time.Duration(d.Timeout),
-		Net:         d.Network,
-	}
-
 	recordType, err := d.parseRecordType()
 	if err != nil {
 		return fields, tags, err
@@ -128,9 +185,11 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 	var msg dns.Msg
 	msg.SetQuestion(dns.Fqdn(domain), recordType)
 	msg.RecursionDesired = true
+	if d.DNSSEC {
+		msg.SetEdns0(4096, true)
+	}
 
-	addr := net.JoinHostPort(server, strconv.Itoa(d.Port))
-	r, rtt, err := c.Exchange(&msg, addr)
+	r, rtt, err := d.exchange(&msg, server)
 	if err != nil {
 		var opErr *net.OpError
 		if errors.As(err, &opErr) && opErr.Timeout() {
@@ -151,6 +210,22 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 		return fields, tags, fmt.Errorf("invalid answer (%s) from %s after %s query for %s", dns.RcodeToString[r.Rcode], server, d.RecordType, domain)
 	}
 
+	if d.DNSSEC {
+		// The authenticated data flag is set by validating resolvers only
+		// if all records of the answer passed the validation
+		if r.AuthenticatedData {
+			fields["dnssec_status"] = "secure"
+		} else {
+			fields["dnssec_status"] = "insecure"
+		}
+	}
+
+	if err := d.validate(r.Answer); err != nil {
+		tags["result"] = "mismatch"
+		fields["result_code"] = uint64(Mismatch)
+		return fields, tags, fmt.Errorf("unexpected answer from %s after %s query for %s: %w", server, d.RecordType, domain, err)
+	}
+
 	// Success
 	tags["result"] = "success"
 	fields["result_code"] = uint64(Success)
@@ -174,6 +249,102 @@ func (d *DNSQuery) query(domain string, server string) (map[string]interface{},
 	return fields, tags, nil
 }
 
+// exchange sends the query to the server using the configured transport
+func (d *DNSQuery) exchange(msg *dns.Msg, server string) (*dns.Msg, time.Duration, error) {
+	if d.Network == "https" {
+		return d.exchangeHTTPS(msg, server)
+	}
+
+	c := dns.Client{
+		ReadTimeout: time.Duration(d.Timeout),
+		Net:         d.Network,
+		TLSConfig:   d.tlsConfig,
+	}
+	return c.Exchange(msg, net.JoinHostPort(server, strconv.Itoa(d.Port)))
+}
+
+// exchangeHTTPS sends the query as DNS-over-HTTPS request according to
+// RFC 8484. Servers can be given as URL or host, the latter using the
+// "/dns-query" path.
+func (d *DNSQuery) exchangeHTTPS(msg *dns.Msg, server string) (*dns.Msg, time.Duration, error) {
+	address := server
+	if !strings.HasPrefix(server, "https://") {
+		address = "https://" + net.JoinHostPort(server, strconv.Itoa(d.Port)) + "/dns-query"
+	}
+
+	// The message ID should be zero to improve caching, see RFC 8484 section 4.1
+	id := msg.Id
+	msg.Id = 0
+	query, err := msg.Pack()
+	if err != nil {
+		return nil, 0, fmt.Errorf("packing query failed: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", address, bytes.NewReader(query))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+
+	start := time.Now()
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	rtt := time.Since(start)
+	if err != nil {
+		return nil, rtt, fmt.Errorf("reading response failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, rtt, fmt.Errorf("received status code %d (%s) from %s", resp.StatusCode, http.StatusText(resp.StatusCode), address)
+	}
+
+	var r dns.Msg
+	if err := r.Unpack(body); err != nil {
+		return nil, rtt, fmt.Errorf("unpacking response failed: %w", err)
+	}
+	r.Id = id
+	return &r, rtt, nil
+}
+
+// validate checks the answer against the expected IPs and pattern. All IP
+// addresses of the answer must be expected and at least one record has to
+// match the pattern.
+func (d *DNSQuery) validate(answer []dns.RR) error {
+	if d.expectedIPs != nil {
+		var found bool
+		for _, record := range answer {
+			ip, ok := extractIP(record)
+			if !ok {
+				continue
+			}
+			if !d.expectedIPs[ip] {
+				return fmt.Errorf("unexpected IP %s", ip)
+			}
+			found = true
+		}
+		if !found {
+			return errors.New("no IP in answer")
+		}
+	}
+
+	if d.expected != nil {
+		for _, record := range answer {
+			data := strings.TrimPrefix(record.String(), record.Header().String())
+			if d.expected.MatchString(data) {
+				return nil
+			}
+		}
+		return errors.New("no record matching the expected pattern")
+	}
+
+	return nil
+}
+
 func (d *DNSQuery) parseRecordType() (uint16, error) {
 	var recordType uint16
 	var err error
@@ -1,6 +1,12 @@
 package dns_query
 
 import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -260,3 +266,221 @@ func TestRecordTypeParserError(t *testing.T) {
 	_, err := plugin.parseRecordType()
 	require.Error(t, err)
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *DNSQuery
+		expected string
+	}{
+		{
+			name:     "invalid network",
+			plugin:   &DNSQuery{Network: "quic"},
+			expected: "invalid 'network'",
+		},
+		{
+			name:     "invalid expected ip",
+			plugin:   &DNSQuery{ExpectedIPs: []string{"10.0.0.256"}},
+			expected: `invalid expected IP "10.0.0.256"`,
+		},
+		{
+			name:     "invalid expected pattern",
+			plugin:   &DNSQuery{ExpectedPattern: "mail[("},
+			expected: "compiling expected pattern failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDefaultPorts(t *testing.T) {
+	for network, port := range map[string]int{"udp": 53, "tcp": 53, "tcp-tls": 853, "https": 443} {
+		plugin := &DNSQuery{Network: network}
+		require.NoError(t, plugin.Init())
+		require.Equal(t, port, plugin.Port, network)
+	}
+}
+
+// handleQuery answers A queries with fixed addresses and signals validated
+// DNSSEC answers if requested
+func handleQuery(w dns.ResponseWriter, req *dns.Msg) {
+	var resp dns.Msg
+	resp.SetReply(req)
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP(ip),
+		})
+	}
+	if opt := req.IsEdns0(); opt != nil && opt.Do() {
+		resp.AuthenticatedData = true
+	}
+	_ = w.WriteMsg(&resp)
+}
+
+func startServer(t *testing.T) (string, int) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &dns.Server{PacketConn: conn, Handler: dns.HandlerFunc(handleQuery)}
+	go func() {
+		_ = server.ActivateAndServe()
+	}()
+	t.Cleanup(func() {
+		_ = server.Shutdown()
+	})
+
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestAnswerValidation(t *testing.T) {
+	server, port := startServer(t)
+
+	tests := []struct {
+		name            string
+		expectedIPs     []string
+		expectedPattern string
+		result          string
+		resultCode      uint64
+	}{
+		{
+			name:        "expected ips",
+			expectedIPs: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+			result:      "success",
+			resultCode:  uint64(Success),
+		},
+		{
+			name:        "unexpected ip",
+			expectedIPs: []string{"10.0.0.1"},
+			result:      "mismatch",
+			resultCode:  uint64(Mismatch),
+		},
+		{
+			name:            "matching pattern",
+			expectedPattern: `^10\.0\.0\.2$`,
+			result:          "success",
+			resultCode:      uint64(Success),
+		},
+		{
+			name:            "non-matching pattern",
+			expectedPattern: `^192\.168\.`,
+			result:          "mismatch",
+			resultCode:      uint64(Mismatch),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &DNSQuery{
+				Servers:         []string{server},
+				Domains:         []string{"example.org"},
+				RecordType:      "A",
+				Port:            port,
+				Timeout:         config.Duration(time.Second),
+				ExpectedIPs:     tt.expectedIPs,
+				ExpectedPattern: tt.expectedPattern,
+			}
+			require.NoError(t, plugin.Init())
+
+			fields, tags, err := plugin.query("example.org", server)
+			if tt.resultCode == uint64(Success) {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, "unexpected answer")
+			}
+			require.Equal(t, tt.result, tags["result"])
+			require.Equal(t, tt.resultCode, fields["result_code"])
+		})
+	}
+}
+
+func TestDNSSECStatus(t *testing.T) {
+	server, port := startServer(t)
+
+	plugin := &DNSQuery{
+		Servers:    []string{server},
+		Domains:    []string{"example.org"},
+		RecordType: "A",
+		Port:       port,
+		Timeout:    config.Duration(time.Second),
+		DNSSEC:     true,
+	}
+	require.NoError(t, plugin.Init())
+
+	fields, _, err := plugin.query("example.org", server)
+	require.NoError(t, err)
+	require.Equal(t, "secure", fields["dnssec_status"])
+
+	plugin.DNSSEC = false
+	fields, _, err = plugin.query("example.org", server)
+	require.NoError(t, err)
+	require.NotContains(t, fields, "dnssec_status")
+}
+
+func TestDNSOverHTTPS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/dns-query" || r.Header.Get("Content-Type") != "application/dns-message" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var req dns.Msg
+		if err := req.Unpack(body); err != nil || req.Id != 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var resp dns.Msg
+		resp.SetReply(&req)
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+			A:   net.ParseIP("10.0.0.1"),
+		})
+		buf, err := resp.Pack()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/dns-message")
+		_, _ = w.Write(buf)
+	}))
+	defer ts.Close()
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	host, port, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	plugin := &DNSQuery{
+		Network:       "https",
+		Servers:       []string{host, ts.URL + "/dns-query"},
+		Domains:       []string{"example.org"},
+		RecordType:    "A",
+		Port:          portNumber,
+		Timeout:       config.Duration(time.Second),
+		IncludeFields: []string{"first_ip"},
+		ExpectedIPs:   []string{"10.0.0.1"},
+	}
+	plugin.InsecureSkipVerify = true
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.Metrics, 2)
+	for _, m := range acc.Metrics {
+		require.Equal(t, "success", m.Tags["result"])
+		require.Equal(t, "10.0.0.1", m.Fields["ip"])
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"regexp"
 	"sort"
 	"strings"
@@ -17,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	jsonparser "github.com/influxdata/telegraf/plugins/parsers/json"
@@ -86,6 +89,14 @@ type clusterStats struct {
 	Nodes       interface{} `json:"nodes"`
 }
 
+type nodeHTTPInfo struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+	HTTP  struct {
+		PublishAddress string `json:"publish_address"`
+	} `json:"http"`
+}
+
 type indexStat struct {
 	Primaries interface{}              `json:"primaries"`
 	Total     interface{}              `json:"total"`
@@ -108,6 +119,14 @@ type Elasticsearch struct {
 	Username                   string          `toml:"username"`
 	Password                   string          `toml:"password"`
 	NumMostRecentIndices       int             `toml:"num_most_recent_indices"`
+	IndicesMetrics             []string        `toml:"indices_metrics"`
+	NodeDiscovery              bool            `toml:"node_discovery"`
+	NodeDiscoveryRoles         []string        `toml:"node_discovery_roles"`
+	ILMStats                   bool            `toml:"ilm_stats"`
+	SLMStats                   bool            `toml:"slm_stats"`
+	Distribution               string          `toml:"distribution"`
+
+	Log telegraf.Logger `toml:"-"`
 
 	tls.ClientConfig
 
@@ -115,6 +134,7 @@ type Elasticsearch struct {
 	serverInfo      map[string]serverInfo
 	serverInfoMutex sync.Mutex
 	indexMatchers   map[string]filter.Filter
+	servers         []string
 }
 type serverInfo struct {
 	nodeID   string
@@ -131,6 +151,7 @@ func NewElasticsearch() *Elasticsearch {
 		HTTPTimeout:                config.Duration(time.Second * 5),
 		ClusterStatsOnlyFromMaster: true,
 		ClusterHealthLevel:         "indices",
+		Distribution:               "elasticsearch",
 	}
 }
 
@@ -168,6 +189,14 @@ func (*Elasticsearch) SampleConfig() string {
 
 // Init the plugin.
 func (e *Elasticsearch) Init() error {
+	switch e.Distribution {
+	case "":
+		e.Distribution = "elasticsearch"
+	case "elasticsearch", "opensearch":
+	default:
+		return fmt.Errorf("invalid distribution %q", e.Distribution)
+	}
+
 	// Compile the configured indexes to match for sorting.
 	indexMatchers, err := e.compileIndexMatchers()
 	if err != nil {
@@ -191,12 +220,27 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 		e.client = client
 	}
 
-	if e.ClusterStats || len(e.IndicesInclude) > 0 || len(e.IndicesLevel) > 0 {
+	servers := e.Servers
+	if e.NodeDiscovery {
+		discovered, err := e.discoverServers()
+		if err != nil {
+			acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
+		}
+		// Keep using the previously discovered servers if discovery fails
+		if len(discovered) > 0 {
+			e.servers = discovered
+		}
+		if len(e.servers) > 0 {
+			servers = e.servers
+		}
+	}
+
+	if e.ClusterStats || len(e.IndicesInclude) > 0 || len(e.IndicesLevel) > 0 || e.ILMStats || e.SLMStats {
 		var wgC sync.WaitGroup
-		wgC.Add(len(e.Servers))
+		wgC.Add(len(servers))
 
 		e.serverInfo = make(map[string]serverInfo)
-		for _, serv := range e.Servers {
+		for _, serv := range servers {
 			go func(s string, acc telegraf.Accumulator) {
 				defer wgC.Done()
 				info := serverInfo{}
@@ -211,7 +255,7 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 
 				// get cat/master information here so NodeStats can determine
 				// whether this node is the Master
-				if info.masterID, err = e.getCatMaster(s + "/_cat/master"); err != nil {
+				if info.masterID, err = e.getCatMaster(s + e.catMasterPath()); err != nil {
 					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
 					return
 				}
@@ -225,9 +269,9 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 	}
 
 	var wg sync.WaitGroup
-	wg.Add(len(e.Servers))
+	wg.Add(len(servers))
 
-	for _, serv := range e.Servers {
+	for _, serv := range servers {
 		go func(s string, acc telegraf.Accumulator) {
 			defer wg.Done()
 			url := e.nodeStatsURL(s)
@@ -257,16 +301,23 @@ func (e *Elasticsearch) Gather(acc telegraf.Accumulator) error {
 			}
 
 			if len(e.IndicesInclude) > 0 && (e.serverInfo[s].isMaster() || !e.ClusterStatsOnlyFromMaster || !e.Local) {
-				if e.IndicesLevel != "shards" {
-					if err := e.gatherIndicesStats(s+"/"+strings.Join(e.IndicesInclude, ",")+"/_stats", acc); err != nil {
-						acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
-						return
-					}
-				} else {
-					if err := e.gatherIndicesStats(s+"/"+strings.Join(e.IndicesInclude, ",")+"/_stats?level=shards", acc); err != nil {
-						acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
-						return
-					}
+				if err := e.gatherIndicesStats(e.indicesStatsURL(s), acc); err != nil {
+					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
+					return
+				}
+			}
+
+			if e.ILMStats && (e.serverInfo[s].isMaster() || !e.ClusterStatsOnlyFromMaster || !e.Local) {
+				if err := e.gatherLifecycleStats(s, acc); err != nil {
+					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
+					return
+				}
+			}
+
+			if e.SLMStats && (e.serverInfo[s].isMaster() || !e.ClusterStatsOnlyFromMaster || !e.Local) {
+				if err := e.gatherSnapshotLifecycleStats(s, acc); err != nil {
+					acc.AddError(errors.New(mask.ReplaceAllString(err.Error(), "http(s)://XXX:XXX@")))
+					return
 				}
 			}
 		}(serv, acc)
@@ -309,6 +360,113 @@ func (e *Elasticsearch) nodeStatsURL(baseURL string) string {
 	return fmt.Sprintf("%s/%s", url, strings.Join(e.NodeStats, ","))
 }
 
+// indicesStatsURL returns the URL of the indices stats restricted to the
+// configured indices and metrics so filtering is done by the server
+func (e *Elasticsearch) indicesStatsURL(baseURL string) string {
+	url := baseURL + "/" + strings.Join(e.IndicesInclude, ",") + "/_stats"
+	if len(e.IndicesMetrics) > 0 {
+		url += "/" + strings.Join(e.IndicesMetrics, ",")
+	}
+	if e.IndicesLevel == "shards" {
+		url += "?level=shards"
+	}
+	return url
+}
+
+// catMasterPath returns the endpoint of the elected master node, OpenSearch
+// renamed the master node to cluster manager
+func (e *Elasticsearch) catMasterPath() string {
+	if e.Distribution == "opensearch" {
+		return "/_cat/cluster_manager"
+	}
+	return "/_cat/master"
+}
+
+// discoverServers queries the HTTP addresses of all nodes of the cluster from
+// the first configured server responding. The scheme, credentials and path of
+// that server are used for the discovered nodes.
+func (e *Elasticsearch) discoverServers() ([]string, error) {
+	var errs []error
+	for _, server := range e.Servers {
+		servers, err := e.discoverServersFrom(server)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("discovering nodes from %q failed: %w", server, err))
+			continue
+		}
+		return servers, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+func (e *Elasticsearch) discoverServersFrom(server string) ([]string, error) {
+	base, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := &struct {
+		Nodes map[string]nodeHTTPInfo `json:"nodes"`
+	}{}
+	if err := e.gatherJSONData(server+"/_nodes/http", nodes); err != nil {
+		return nil, err
+	}
+
+	servers := make([]string, 0, len(nodes.Nodes))
+	for _, n := range nodes.Nodes {
+		if !hasRole(n.Roles, e.NodeDiscoveryRoles) {
+			continue
+		}
+		address, err := publishAddress(n.HTTP.PublishAddress)
+		if err != nil {
+			e.Log.Debugf("Ignoring node %q: %v", n.Name, err)
+			continue
+		}
+		u := *base
+		u.Host = address
+		servers = append(servers, u.String())
+	}
+	if len(servers) == 0 {
+		return nil, errors.New("no nodes with HTTP enabled found")
+	}
+	sort.Strings(servers)
+	return servers, nil
+}
+
+// publishAddress converts the HTTP publish address of a node to a host and
+// port, the address has the form "hostname/ip:port" if a hostname is known
+func publishAddress(address string) (string, error) {
+	if address == "" {
+		return "", errors.New("HTTP not enabled")
+	}
+	hostname, address, found := strings.Cut(address, "/")
+	if !found {
+		address = hostname
+		hostname = ""
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+	if hostname != "" {
+		host = hostname
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// hasRole returns true if one of the roles is in the list of wanted roles or
+// no roles are wanted
+func hasRole(roles, wanted []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if choice.Contains(role, wanted) {
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Elasticsearch) gatherNodeID(url string) (string, error) {
 	nodeStats := &struct {
 		ClusterName string               `json:"cluster_name"`
@@ -0,0 +1,252 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// ilmIndex is the index lifecycle management state of an index in
+// Elasticsearch
+type ilmIndex struct {
+	Managed bool   `json:"managed"`
+	Policy  string `json:"policy"`
+	Phase   string `json:"phase"`
+	Step    string `json:"step"`
+}
+
+// ismIndex is the index state management state of an index in OpenSearch
+type ismIndex struct {
+	PolicyID string `json:"policy_id"`
+	State    struct {
+		Name string `json:"name"`
+	} `json:"state"`
+	Action struct {
+		Failed bool `json:"failed"`
+	} `json:"action"`
+	Step struct {
+		StepStatus string `json:"step_status"`
+	} `json:"step"`
+}
+
+type slmStats struct {
+	RetentionRuns                 int64 `json:"retention_runs"`
+	RetentionFailed               int64 `json:"retention_failed"`
+	RetentionTimedOut             int64 `json:"retention_timed_out"`
+	RetentionDeletionTimeMillis   int64 `json:"retention_deletion_time_millis"`
+	TotalSnapshotsTaken           int64 `json:"total_snapshots_taken"`
+	TotalSnapshotsFailed          int64 `json:"total_snapshots_failed"`
+	TotalSnapshotsDeleted         int64 `json:"total_snapshots_deleted"`
+	TotalSnapshotDeletionFailures int64 `json:"total_snapshot_deletion_failures"`
+}
+
+type slmInvocation struct {
+	Time int64 `json:"time"`
+}
+
+type slmPolicy struct {
+	LastSuccess         *slmInvocation `json:"last_success"`
+	LastFailure         *slmInvocation `json:"last_failure"`
+	NextExecutionMillis int64          `json:"next_execution_millis"`
+	Stats               struct {
+		SnapshotsTaken           int64 `json:"snapshots_taken"`
+		SnapshotsFailed          int64 `json:"snapshots_failed"`
+		SnapshotsDeleted         int64 `json:"snapshots_deleted"`
+		SnapshotDeletionFailures int64 `json:"snapshot_deletion_failures"`
+	} `json:"stats"`
+}
+
+// smPolicy is the explanation of a snapshot management policy in OpenSearch
+type smPolicy struct {
+	Name     string `json:"name"`
+	Enabled  bool   `json:"enabled"`
+	Creation *struct {
+		Trigger struct {
+			Time int64 `json:"time"`
+		} `json:"trigger"`
+		LatestExecution *struct {
+			Status  string `json:"status"`
+			EndTime int64  `json:"end_time"`
+		} `json:"latest_execution"`
+	} `json:"creation"`
+}
+
+// lifecyclePhase counts the indices of a policy in a phase or state
+type lifecyclePhase struct {
+	policy string
+	phase  string
+}
+
+// lifecycleIndices returns the configured index patterns for the lifecycle
+// explain APIs
+func (e *Elasticsearch) lifecycleIndices() string {
+	if len(e.IndicesInclude) == 0 {
+		return "_all"
+	}
+	return strings.Join(e.IndicesInclude, ",")
+}
+
+// gatherLifecycleStats reports the number of indices managed by index
+// lifecycle management and the indices stuck in an error step. OpenSearch
+// uses index state management with states instead of phases.
+func (e *Elasticsearch) gatherLifecycleStats(url string, acc telegraf.Accumulator) error {
+	now := time.Now()
+	fields := map[string]interface{}{}
+	phases := map[lifecyclePhase]map[string]interface{}{}
+	var managed, failed int64
+
+	count := func(policy, phase string, hasFailed bool) {
+		key := lifecyclePhase{policy: policy, phase: phase}
+		if _, found := phases[key]; !found {
+			phases[key] = map[string]interface{}{"indices": int64(0), "failed_indices": int64(0)}
+		}
+		phases[key]["indices"] = phases[key]["indices"].(int64) + 1
+		managed++
+		if hasFailed {
+			phases[key]["failed_indices"] = phases[key]["failed_indices"].(int64) + 1
+			failed++
+		}
+	}
+
+	if e.Distribution == "opensearch" {
+		explainURL := url + "/_plugins/_ism/explain"
+		if indices := e.lifecycleIndices(); indices != "_all" {
+			explainURL += "/" + indices
+		}
+		explain := map[string]json.RawMessage{}
+		if err := e.gatherJSONData(explainURL, &explain); err != nil {
+			return err
+		}
+		for name, raw := range explain {
+			if name == "total_managed_indices" {
+				continue
+			}
+			var index ismIndex
+			if err := json.Unmarshal(raw, &index); err != nil {
+				return fmt.Errorf("decoding state of index %q failed: %w", name, err)
+			}
+			if index.PolicyID == "" {
+				continue
+			}
+			count(index.PolicyID, index.State.Name, index.Action.Failed || index.Step.StepStatus == "failed")
+		}
+	} else {
+		status := &struct {
+			OperationMode string `json:"operation_mode"`
+		}{}
+		if err := e.gatherJSONData(url+"/_ilm/status", status); err != nil {
+			return err
+		}
+		fields["operation_mode"] = status.OperationMode
+
+		explain := &struct {
+			Indices map[string]ilmIndex `json:"indices"`
+		}{}
+		if err := e.gatherJSONData(url+"/"+e.lifecycleIndices()+"/_ilm/explain", explain); err != nil {
+			return err
+		}
+		for _, index := range explain.Indices {
+			if !index.Managed {
+				continue
+			}
+			count(index.Policy, index.Phase, index.Step == "ERROR")
+		}
+	}
+
+	fields["managed_indices"] = managed
+	fields["failed_indices"] = failed
+	acc.AddFields("elasticsearch_ilm", fields, map[string]string{}, now)
+
+	for key, phaseFields := range phases {
+		tags := map[string]string{"policy": key.policy, "phase": key.phase}
+		acc.AddFields("elasticsearch_ilm_phase", phaseFields, tags, now)
+	}
+	return nil
+}
+
+// gatherSnapshotLifecycleStats reports the snapshot lifecycle management
+// statistics and the state of each policy. OpenSearch only provides the state
+// of the policies through its snapshot management API.
+func (e *Elasticsearch) gatherSnapshotLifecycleStats(url string, acc telegraf.Accumulator) error {
+	now := time.Now()
+
+	if e.Distribution == "opensearch" {
+		explain := &struct {
+			Policies []smPolicy `json:"policies"`
+		}{}
+		if err := e.gatherJSONData(url+"/_plugins/_sm/policies/*/_explain", explain); err != nil {
+			return err
+		}
+		for _, policy := range explain.Policies {
+			fields := map[string]interface{}{"enabled": policy.Enabled}
+			if policy.Creation != nil {
+				fields["next_execution_millis"] = policy.Creation.Trigger.Time
+				if execution := policy.Creation.LatestExecution; execution != nil {
+					switch execution.Status {
+					case "SUCCESS":
+						fields["last_success_time"] = execution.EndTime
+						fields["last_run_failed"] = false
+					case "FAILED", "TIME_LIMIT_EXCEEDED":
+						fields["last_failure_time"] = execution.EndTime
+						fields["last_run_failed"] = true
+					}
+				}
+			}
+			acc.AddFields("elasticsearch_slm_policy", fields, map[string]string{"policy": policy.Name}, now)
+		}
+		return nil
+	}
+
+	status := &struct {
+		OperationMode string `json:"operation_mode"`
+	}{}
+	if err := e.gatherJSONData(url+"/_slm/status", status); err != nil {
+		return err
+	}
+	stats := &slmStats{}
+	if err := e.gatherJSONData(url+"/_slm/stats", stats); err != nil {
+		return err
+	}
+	acc.AddFields("elasticsearch_slm", map[string]interface{}{
+		"operation_mode":                   status.OperationMode,
+		"retention_runs":                   stats.RetentionRuns,
+		"retention_failed":                 stats.RetentionFailed,
+		"retention_timed_out":              stats.RetentionTimedOut,
+		"retention_deletion_time_millis":   stats.RetentionDeletionTimeMillis,
+		"total_snapshots_taken":            stats.TotalSnapshotsTaken,
+		"total_snapshots_failed":           stats.TotalSnapshotsFailed,
+		"total_snapshots_deleted":          stats.TotalSnapshotsDeleted,
+		"total_snapshot_deletion_failures": stats.TotalSnapshotDeletionFailures,
+	}, map[string]string{}, now)
+
+	policies := map[string]slmPolicy{}
+	if err := e.gatherJSONData(url+"/_slm/policy", &policies); err != nil {
+		return err
+	}
+	for name, policy := range policies {
+		fields := map[string]interface{}{
+			"snapshots_taken":            policy.Stats.SnapshotsTaken,
+			"snapshots_failed":           policy.Stats.SnapshotsFailed,
+			"snapshots_deleted":          policy.Stats.SnapshotsDeleted,
+			"snapshot_deletion_failures": policy.Stats.SnapshotDeletionFailures,
+			"next_execution_millis":      policy.NextExecutionMillis,
+		}
+		var lastSuccess, lastFailure int64
+		if policy.LastSuccess != nil {
+			lastSuccess = policy.LastSuccess.Time
+			fields["last_success_time"] = lastSuccess
+		}
+		if policy.LastFailure != nil {
+			lastFailure = policy.LastFailure.Time
+			fields["last_failure_time"] = lastFailure
+		}
+		if policy.LastSuccess != nil || policy.LastFailure != nil {
+			fields["last_run_failed"] = lastFailure > lastSuccess
+		}
+		acc.AddFields("elasticsearch_slm_policy", fields, map[string]string{"policy": name}, now)
+	}
+	return nil
+}
@@ -5848,3 +5848,168 @@ var clusterIndicesReplicaShardsExpected = map[string]interface{}{
 	"warmer_total":                           float64(3),
 	"warmer_total_time_in_millis":            float64(0),
 }
+
+const nodesHTTPResponse = `
+{
+  "cluster_name": "es-testcluster",
+  "nodes": {
+    "SDFsfSDFsdfFSDSDfSFDSDF": {
+      "name": "node-1",
+      "roles": ["data", "ingest", "master"],
+      "http": {
+        "bound_address": ["[::]:9200"],
+        "publish_address": "10.0.0.1:9200"
+      }
+    },
+    "oqvR8I1dTpONvwRM30etww": {
+      "name": "node-2",
+      "roles": ["data"],
+      "http": {
+        "bound_address": ["[::]:9200"],
+        "publish_address": "node-2.example.com/10.0.0.2:9201"
+      }
+    },
+    "Piuu8I1dTpONvwRM30etaa": {
+      "name": "node-3",
+      "roles": ["master"]
+    }
+  }
+}
+`
+
+const ilmStatusResponse = `{"operation_mode": "RUNNING"}`
+
+const ilmExplainResponse = `
+{
+  "indices": {
+    "logs-000001": {
+      "index": "logs-000001",
+      "managed": true,
+      "policy": "logs",
+      "phase": "hot",
+      "action": "rollover",
+      "step": "check-rollover-ready"
+    },
+    "logs-000002": {
+      "index": "logs-000002",
+      "managed": true,
+      "policy": "logs",
+      "phase": "hot",
+      "action": "rollover",
+      "step": "ERROR",
+      "failed_step": "check-rollover-ready"
+    },
+    "metrics-000001": {
+      "index": "metrics-000001",
+      "managed": true,
+      "policy": "metrics",
+      "phase": "delete",
+      "action": "complete",
+      "step": "complete"
+    },
+    "twitter": {
+      "index": "twitter",
+      "managed": false
+    }
+  }
+}
+`
+
+const ismExplainResponse = `
+{
+  "logs-000001": {
+    "index.plugins.index_state_management.policy_id": "logs",
+    "index": "logs-000001",
+    "policy_id": "logs",
+    "enabled": true,
+    "state": {"name": "hot"},
+    "action": {"name": "rollover", "failed": false},
+    "step": {"name": "attempt_rollover", "step_status": "starting"}
+  },
+  "logs-000002": {
+    "index.plugins.index_state_management.policy_id": "logs",
+    "index": "logs-000002",
+    "policy_id": "logs",
+    "enabled": true,
+    "state": {"name": "hot"},
+    "action": {"name": "rollover", "failed": true},
+    "step": {"name": "attempt_rollover", "step_status": "failed"}
+  },
+  "twitter": {
+    "index.plugins.index_state_management.policy_id": null,
+    "enabled": null
+  },
+  "total_managed_indices": 2
+}
+`
+
+const slmStatusResponse = `{"operation_mode": "RUNNING"}`
+
+const slmStatsResponse = `
+{
+  "retention_runs": 13,
+  "retention_failed": 0,
+  "retention_timed_out": 0,
+  "retention_deletion_time": "1.4s",
+  "retention_deletion_time_millis": 1404,
+  "policy_stats": [],
+  "total_snapshots_taken": 12,
+  "total_snapshots_failed": 1,
+  "total_snapshots_deleted": 3,
+  "total_snapshot_deletion_failures": 0
+}
+`
+
+const slmPolicyResponse = `
+{
+  "daily-snapshots": {
+    "version": 1,
+    "modified_date_millis": 1690000000000,
+    "policy": {
+      "name": "<daily-snap-{now/d}>",
+      "schedule": "0 30 1 * * ?",
+      "repository": "my_repository"
+    },
+    "last_success": {
+      "snapshot_name": "daily-snap-2023.07.22",
+      "start_time": 1690000000000,
+      "time": 1690000010000
+    },
+    "last_failure": {
+      "snapshot_name": "daily-snap-2023.07.23",
+      "time": 1690086410000,
+      "details": "repository is read-only"
+    },
+    "next_execution_millis": 1690172800000,
+    "stats": {
+      "policy": "daily-snapshots",
+      "snapshots_taken": 12,
+      "snapshots_failed": 1,
+      "snapshots_deleted": 3,
+      "snapshot_deletion_failures": 0
+    }
+  }
+}
+`
+
+const smExplainResponse = `
+{
+  "policies": [
+    {
+      "name": "daily-policy",
+      "creation": {
+        "current_state": "CREATION_START",
+        "trigger": {"time": 1690172800000},
+        "latest_execution": {
+          "status": "SUCCESS",
+          "start_time": 1690086400000,
+          "end_time": 1690086410000
+        }
+      },
+      "policy_seq_no": 0,
+      "policy_primary_term": 1,
+      "enabled": true
+    }
+  ]
+}
+`
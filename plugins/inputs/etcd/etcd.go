@@ -0,0 +1,291 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Etcd struct {
+	Endpoints  []string        `toml:"endpoints"`
+	GRPCHealth bool            `toml:"grpc_health"`
+	Metrics    []string        `toml:"metrics"`
+	Timeout    config.Duration `toml:"timeout"`
+	Log        telegraf.Logger `toml:"-"`
+	tlsint.ClientConfig
+
+	endpoints []*url.URL
+	metrics   filter.Filter
+	tlsCfg    *tls.Config
+	client    *http.Client
+}
+
+// healthResponse is the response of the /health endpoint
+type healthResponse struct {
+	Health string `json:"health"`
+	Reason string `json:"reason"`
+}
+
+// statusResponse is the response of the maintenance status call of the gRPC gateway
+// with all 64-bit integers encoded as strings
+type statusResponse struct {
+	Header struct {
+		ClusterID uint64 `json:"cluster_id,string"`
+		MemberID  uint64 `json:"member_id,string"`
+		Revision  int64  `json:"revision,string"`
+	} `json:"header"`
+	Version          string   `json:"version"`
+	DBSize           int64    `json:"dbSize,string"`
+	DBSizeInUse      int64    `json:"dbSizeInUse,string"`
+	Leader           uint64   `json:"leader,string"`
+	RaftIndex        uint64   `json:"raftIndex,string"`
+	RaftTerm         uint64   `json:"raftTerm,string"`
+	RaftAppliedIndex uint64   `json:"raftAppliedIndex,string"`
+	IsLearner        bool     `json:"isLearner"`
+	Errors           []string `json:"errors"`
+}
+
+func (*Etcd) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Etcd) Init() error {
+	if len(e.Endpoints) == 0 {
+		return errors.New("no endpoints specified")
+	}
+	for _, endpoint := range e.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return fmt.Errorf("parsing endpoint %q failed: %w", endpoint, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return fmt.Errorf("invalid scheme %q of endpoint %q", u.Scheme, endpoint)
+		}
+		e.endpoints = append(e.endpoints, u)
+	}
+
+	var err error
+	e.metrics, err = filter.Compile(e.Metrics)
+	if err != nil {
+		return fmt.Errorf("compiling metrics filter failed: %w", err)
+	}
+
+	e.tlsCfg, err = e.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("setting up TLS configuration failed: %w", err)
+	}
+	e.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: e.tlsCfg,
+		},
+		Timeout: time.Duration(e.Timeout),
+	}
+
+	return nil
+}
+
+func (e *Etcd) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, endpoint := range e.endpoints {
+		wg.Add(1)
+		go func(u *url.URL) {
+			defer wg.Done()
+			if err := e.gatherEndpoint(acc, u); err != nil {
+				acc.AddError(fmt.Errorf("%s: %w", u.Redacted(), err))
+			}
+		}(endpoint)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (e *Etcd) gatherEndpoint(acc telegraf.Accumulator, u *url.URL) error {
+	tags := map[string]string{"endpoint": u.Redacted()}
+	fields := make(map[string]interface{})
+
+	// An unhealthy member does still report its status and metrics
+	var h healthResponse
+	if err := e.request(http.MethodGet, u, "/health", nil, &h); err != nil {
+		return fmt.Errorf("querying health failed: %w", err)
+	}
+	fields["healthy"] = h.Health == "true"
+	if h.Reason != "" {
+		fields["health_reason"] = h.Reason
+	}
+
+	if e.GRPCHealth {
+		serving, err := e.grpcHealth(u)
+		if err != nil {
+			acc.AddError(fmt.Errorf("%s: checking gRPC health failed: %w", u.Redacted(), err))
+		}
+		fields["grpc_serving"] = serving
+	}
+
+	var s statusResponse
+	if err := e.request(http.MethodPost, u, "/v3/maintenance/status", []byte("{}"), &s); err != nil {
+		return fmt.Errorf("querying status failed: %w", err)
+	}
+	tags["cluster_id"] = strconv.FormatUint(s.Header.ClusterID, 16)
+	tags["member_id"] = strconv.FormatUint(s.Header.MemberID, 16)
+	tags["version"] = s.Version
+	if s.Leader != 0 {
+		tags["leader_id"] = strconv.FormatUint(s.Leader, 16)
+	}
+	fields["is_leader"] = s.Leader != 0 && s.Leader == s.Header.MemberID
+	fields["is_learner"] = s.IsLearner
+	fields["revision"] = s.Header.Revision
+	fields["db_size"] = s.DBSize
+	fields["db_size_in_use"] = s.DBSizeInUse
+	fields["raft_index"] = s.RaftIndex
+	fields["raft_term"] = s.RaftTerm
+	fields["raft_applied_index"] = s.RaftAppliedIndex
+	fields["errors"] = len(s.Errors)
+
+	if e.metrics != nil {
+		if err := e.gatherMetrics(u, fields); err != nil {
+			return fmt.Errorf("querying metrics failed: %w", err)
+		}
+	}
+
+	acc.AddFields("etcd", fields, tags)
+	return nil
+}
+
+// gatherMetrics adds the selected metrics without labels of the Prometheus
+// endpoint to the fields
+func (e *Etcd) gatherMetrics(u *url.URL, fields map[string]interface{}) error {
+	resp, err := e.do(http.MethodGet, u, "/metrics", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return fmt.Errorf("parsing metrics failed: %w", err)
+	}
+
+	for name, family := range families {
+		if !e.metrics.Match(name) {
+			continue
+		}
+		key := strings.TrimPrefix(name, "etcd_")
+		for _, m := range family.Metric {
+			if len(m.Label) > 0 {
+				continue
+			}
+			switch family.GetType() {
+			case dto.MetricType_GAUGE:
+				fields[key] = m.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				fields[key] = m.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				fields[key] = m.GetUntyped().GetValue()
+			}
+		}
+	}
+	return nil
+}
+
+// grpcHealth checks the state of the gRPC health service of the member
+func (e *Etcd) grpcHealth(u *url.URL) (bool, error) {
+	creds := insecure.NewCredentials()
+	if u.Scheme == "https" {
+		creds = credentials.NewTLS(e.tlsCfg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.Timeout))
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, u.Host, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return false, err
+	}
+	return resp.Status == grpc_health_v1.HealthCheckResponse_SERVING, nil
+}
+
+func (e *Etcd) request(method string, u *url.URL, path string, body []byte, v interface{}) error {
+	resp, err := e.do(method, u, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (e *Etcd) do(method string, u *url.URL, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u.JoinPath(path).String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// The health endpoint reports unhealthy members with a server error
+	if resp.StatusCode != http.StatusOK && path != "/health" {
+		resp.Body.Close()
+		return nil, fmt.Errorf("received status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+func init() {
+	inputs.Add("etcd", func() telegraf.Input {
+		return &Etcd{
+			Metrics: []string{
+				"etcd_server_has_leader",
+				"etcd_server_leader_changes_seen_total",
+				"etcd_server_proposals_*",
+				"etcd_mvcc_db_total_size_in_bytes",
+			},
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,213 @@
+package etcd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const statusJSON = `{
+  "header": {
+    "cluster_id": "14841639068965178418",
+    "member_id": "10276657743932975437",
+    "revision": "42",
+    "raft_term": "3"
+  },
+  "version": "3.5.9",
+  "dbSize": "20480",
+  "leader": "10276657743932975437",
+  "raftIndex": "128",
+  "raftTerm": "3",
+  "raftAppliedIndex": "127",
+  "dbSizeInUse": "16384"
+}`
+
+const metricsText = `# HELP etcd_server_has_leader Whether or not a leader exists. 1 is existence, 0 is not.
+# TYPE etcd_server_has_leader gauge
+etcd_server_has_leader 1
+# HELP etcd_server_leader_changes_seen_total The number of leader changes seen.
+# TYPE etcd_server_leader_changes_seen_total counter
+etcd_server_leader_changes_seen_total 2
+# HELP etcd_server_proposals_pending The current number of pending proposals to commit.
+# TYPE etcd_server_proposals_pending gauge
+etcd_server_proposals_pending 0
+# HELP etcd_network_peer_sent_bytes_total The total number of bytes sent to peers.
+# TYPE etcd_network_peer_sent_bytes_total counter
+etcd_network_peer_sent_bytes_total{To="8e9e05c52164694d"} 1024
+# HELP go_goroutines Number of goroutines that currently exist.
+# TYPE go_goroutines gauge
+go_goroutines 98
+`
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Etcd
+		expected string
+	}{
+		{
+			name:     "no endpoints",
+			plugin:   &Etcd{},
+			expected: "no endpoints specified",
+		},
+		{
+			name:     "invalid scheme",
+			plugin:   &Etcd{Endpoints: []string{"unix:///var/run/etcd.sock"}},
+			expected: `invalid scheme "unix"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestGather(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/health":
+			_, err = w.Write([]byte(`{"health":"true","reason":""}`))
+		case "/v3/maintenance/status":
+			if r.Method != http.MethodPost {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			_, err = w.Write([]byte(statusJSON))
+		case "/metrics":
+			_, err = w.Write([]byte(metricsText))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	plugin := &Etcd{
+		Endpoints: []string{server.URL},
+		Metrics:   []string{"etcd_server_*", "etcd_network_*"},
+		Timeout:   config.Duration(5 * time.Second),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"etcd",
+			map[string]string{
+				"endpoint":   server.URL,
+				"cluster_id": "cdf818194e3a8c32",
+				"member_id":  "8e9e05c52164694d",
+				"leader_id":  "8e9e05c52164694d",
+				"version":    "3.5.9",
+			},
+			map[string]interface{}{
+				"healthy":                          true,
+				"is_leader":                        true,
+				"is_learner":                       false,
+				"revision":                         int64(42),
+				"db_size":                          int64(20480),
+				"db_size_in_use":                   int64(16384),
+				"raft_index":                       uint64(128),
+				"raft_term":                        uint64(3),
+				"raft_applied_index":               uint64(127),
+				"errors":                           0,
+				"server_has_leader":                float64(1),
+				"server_leader_changes_seen_total": float64(2),
+				"server_proposals_pending":         float64(0),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherUnhealthy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, err = w.Write([]byte(`{"health":"false","reason":"RAFT NO LEADER"}`))
+		case "/v3/maintenance/status":
+			_, err = w.Write([]byte(`{"header":{"cluster_id":"1","member_id":"2"},"version":"3.5.9"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	plugin := &Etcd{
+		Endpoints: []string{server.URL},
+		Timeout:   config.Duration(5 * time.Second),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	metrics := acc.GetTelegrafMetrics()
+	require.Len(t, metrics, 1)
+	m := metrics[0]
+	require.Equal(t, map[string]string{
+		"endpoint":   server.URL,
+		"cluster_id": "1",
+		"member_id":  "2",
+		"version":    "3.5.9",
+	}, m.Tags())
+	healthy, _ := m.GetField("healthy")
+	require.Equal(t, false, healthy)
+	reason, _ := m.GetField("health_reason")
+	require.Equal(t, "RAFT NO LEADER", reason)
+	leader, _ := m.GetField("is_leader")
+	require.Equal(t, false, leader)
+}
+
+func TestGRPCHealth(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := health.NewServer()
+	grpcServer := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	defer grpcServer.Stop()
+
+	plugin := &Etcd{
+		Endpoints: []string{fmt.Sprintf("http://%s", listener.Addr())},
+		Timeout:   config.Duration(5 * time.Second),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	serving, err := plugin.grpcHealth(plugin.endpoints[0])
+	require.NoError(t, err)
+	require.True(t, serving)
+
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	serving, err = plugin.grpcHealth(plugin.endpoints[0])
+	require.NoError(t, err)
+	require.False(t, serving)
+}
@@ -16,6 +16,8 @@ type Command interface {
 	Interfaces(includeNamespaces bool) ([]NamespacedInterface, error)
 	Stats(intf NamespacedInterface) (map[string]uint64, error)
 	Get(intf NamespacedInterface) (map[string]uint64, error)
+	ModuleEeprom(intf NamespacedInterface) ([]byte, error)
+	Qdiscs(intf NamespacedInterface) ([]Qdisc, error)
 }
 
 func (*Ethtool) SampleConfig() string {
@@ -3,13 +3,16 @@
 package ethtool
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 
 	"github.com/vishvananda/netns"
 
@@ -28,6 +31,24 @@ const (
 
 var downInterfacesBehaviors = []string{"expose", "skip"}
 
+var collectOptions = []string{"queues", "module", "qdisc"}
+
+// Naming schemes of the drivers for per-queue statistics, e.g. virtio and
+// ixgbe use "rx_queue_0_packets", i40e "rx-0.rx_packets", mlx5 "rx0_packets",
+// ena "queue_0_rx_cnt" and bnxt "[0]: rx_ucast_packets".
+var queueStatPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^(?P<direction>rx|tx)_queue_(?P<queue>\d+)_(?P<name>.+)$`),
+	regexp.MustCompile(`^(?P<direction>rx|tx)-(?P<queue>\d+)\.(?:rx_|tx_)?(?P<name>.+)$`),
+	regexp.MustCompile(`^(?P<direction>rx|tx)(?P<queue>\d+)_(?P<name>.+)$`),
+	regexp.MustCompile(`^queue_(?P<queue>\d+)_(?P<direction>rx|tx)_(?P<name>.+)$`),
+	regexp.MustCompile(`^\[(?P<queue>\d+)\]: (?P<direction>rx|tx)_(?P<name>.+)$`),
+}
+
+type queueKey struct {
+	queue     string
+	direction string
+}
+
 type Ethtool struct {
 	// This is the list of interface names to include
 	InterfaceInclude []string `toml:"interface_include"`
@@ -47,8 +68,14 @@ type Ethtool struct {
 	// Normalization on the key names
 	NormalizeKeys []string `toml:"normalize_keys"`
 
+	// Additional statistics to collect
+	Collect []string `toml:"collect"`
+
 	Log telegraf.Logger `toml:"-"`
 
+	queueStats        bool
+	moduleStats       bool
+	qdiscStats        bool
 	interfaceFilter   filter.Filter
 	namespaceFilter   filter.Filter
 	includeNamespaces bool
@@ -77,6 +104,13 @@ func (e *Ethtool) Init() error {
 		return fmt.Errorf("down_interfaces: %w", err)
 	}
 
+	if err := choice.CheckSlice(e.Collect, collectOptions); err != nil {
+		return fmt.Errorf("collect: %w", err)
+	}
+	e.queueStats = choice.Contains("queues", e.Collect)
+	e.moduleStats = choice.Contains("module", e.Collect)
+	e.qdiscStats = choice.Contains("qdisc", e.Collect)
+
 	// If no namespace include or exclude filters were provided, then default
 	// to just the initial namespace.
 	e.includeNamespaces = len(e.NamespaceInclude) > 0 || len(e.NamespaceExclude) > 0
@@ -166,7 +200,17 @@ func (e *Ethtool) gatherEthtoolStats(iface NamespacedInterface, acc telegraf.Acc
 	}
 
 	fields[fieldInterfaceUp] = interfaceUp(iface)
+	queues := make(map[queueKey]map[string]interface{})
 	for k, v := range stats {
+		if e.queueStats {
+			if key, name, found := splitQueueStat(k); found {
+				if _, ok := queues[key]; !ok {
+					queues[key] = make(map[string]interface{})
+				}
+				queues[key][e.normalizeKey(name)] = v
+				continue
+			}
+		}
 		fields[e.normalizeKey(k)] = v
 	}
 
@@ -181,6 +225,143 @@ func (e *Ethtool) gatherEthtoolStats(iface NamespacedInterface, acc telegraf.Acc
 	}
 
 	acc.AddFields(pluginName, fields, tags)
+
+	for key, qfields := range queues {
+		qtags := map[string]string{
+			"queue":     key.queue,
+			"direction": key.direction,
+		}
+		for k, v := range tags {
+			qtags[k] = v
+		}
+		acc.AddFields(pluginName+"_queue", qfields, qtags)
+	}
+
+	if e.moduleStats {
+		e.gatherModule(iface, tags, acc)
+	}
+	if e.qdiscStats {
+		e.gatherQdiscs(iface, tags, acc)
+	}
+}
+
+// Gather the diagnostics of the transceiver module plugged into the interface
+func (e *Ethtool) gatherModule(iface NamespacedInterface, tags map[string]string, acc telegraf.Accumulator) {
+	data, err := e.command.ModuleEeprom(iface)
+	if err != nil {
+		// Interfaces without (or with an empty) module slot
+		if errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.ENODEV) || errors.Is(err, syscall.EIO) {
+			e.Log.Debugf("No module information for %q: %v", iface.Name, err)
+			return
+		}
+		acc.AddError(fmt.Errorf("%q module: %w", iface.Name, err))
+		return
+	}
+
+	diagnostics := parseModuleEeprom(data)
+	if diagnostics == nil {
+		return
+	}
+
+	mtags := map[string]string{"type": diagnostics.moduleType}
+	for k, v := range tags {
+		mtags[k] = v
+	}
+	fields := map[string]interface{}{
+		"temperature": diagnostics.temperature,
+		"voltage":     diagnostics.voltage,
+	}
+	acc.AddFields(pluginName+"_module", fields, mtags)
+
+	for i, lane := range diagnostics.lanes {
+		ltags := map[string]string{"lane": strconv.Itoa(i + 1)}
+		for k, v := range mtags {
+			ltags[k] = v
+		}
+		fields := map[string]interface{}{
+			"tx_bias":  lane.txBias,
+			"tx_power": lane.txPower,
+			"rx_power": lane.rxPower,
+		}
+		if dbm, ok := milliWattToDBm(lane.txPower); ok {
+			fields["tx_power_dbm"] = dbm
+		}
+		if dbm, ok := milliWattToDBm(lane.rxPower); ok {
+			fields["rx_power_dbm"] = dbm
+		}
+		acc.AddFields(pluginName+"_module_lane", fields, ltags)
+	}
+}
+
+// Gather the statistics of the queueing disciplines of the interface
+func (e *Ethtool) gatherQdiscs(iface NamespacedInterface, tags map[string]string, acc telegraf.Accumulator) {
+	qdiscs, err := e.command.Qdiscs(iface)
+	if err != nil {
+		acc.AddError(fmt.Errorf("%q qdisc: %w", iface.Name, err))
+		return
+	}
+
+	for _, qdisc := range qdiscs {
+		qtags := map[string]string{
+			"kind":   qdisc.Kind,
+			"handle": formatTcHandle(qdisc.Handle),
+			"parent": formatTcHandle(qdisc.Parent),
+		}
+		for k, v := range tags {
+			qtags[k] = v
+		}
+		fields := map[string]interface{}{
+			"bytes":      qdisc.Bytes,
+			"packets":    qdisc.Packets,
+			"qlen":       qdisc.Qlen,
+			"backlog":    qdisc.Backlog,
+			"drops":      qdisc.Drops,
+			"requeues":   qdisc.Requeues,
+			"overlimits": qdisc.Overlimits,
+		}
+		acc.AddFields(pluginName+"_qdisc", fields, qtags)
+	}
+}
+
+// splitQueueStat extracts the queue, the direction and the counter name of
+// per-queue statistics
+func splitQueueStat(key string) (queueKey, string, bool) {
+	for _, re := range queueStatPatterns {
+		match := re.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		var qk queueKey
+		var name string
+		for i, group := range re.SubexpNames() {
+			switch group {
+			case "queue":
+				qk.queue = match[i]
+			case "direction":
+				qk.direction = match[i]
+			case "name":
+				name = match[i]
+			}
+		}
+		return qk, name, true
+	}
+	return queueKey{}, "", false
+}
+
+// formatTcHandle formats the traffic control handles in the same way as "tc"
+func formatTcHandle(handle uint32) string {
+	switch handle {
+	case 0xffffffff:
+		return "root"
+	case 0xfffffff1:
+		return "ingress"
+	case 0:
+		return "none"
+	}
+	if handle&0xffff == 0 {
+		return fmt.Sprintf("%x:", handle>>16)
+	}
+	return fmt.Sprintf("%x:%x", handle>>16, handle&0xffff)
 }
 
 // normalize key string; order matters to avoid replacing whitespace with
@@ -273,6 +454,14 @@ func (c *CommandEthtool) Get(intf NamespacedInterface) (stats map[string]uint64,
 	return intf.Namespace.Get(intf)
 }
 
+func (c *CommandEthtool) ModuleEeprom(intf NamespacedInterface) ([]byte, error) {
+	return intf.Namespace.ModuleEeprom(intf)
+}
+
+func (c *CommandEthtool) Qdiscs(intf NamespacedInterface) ([]Qdisc, error) {
+	return intf.Namespace.Qdiscs(intf)
+}
+
 func (c *CommandEthtool) Interfaces(includeNamespaces bool) ([]NamespacedInterface, error) {
 	const namespaceDirectory = "/var/run/netns"
 
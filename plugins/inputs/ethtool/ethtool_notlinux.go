@@ -26,6 +26,9 @@ type Ethtool struct {
 	// Normalization on the key names
 	NormalizeKeys []string `toml:"normalize_keys"`
 
+	// Additional statistics to collect
+	Collect []string `toml:"collect"`
+
 	Log telegraf.Logger `toml:"-"`
 }
 
@@ -3,12 +3,21 @@
 package ethtool
 
 import (
+	"encoding/binary"
 	"errors"
 	"net"
+	"strconv"
+	"syscall"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/stretchr/testify/require"
+	"github.com/vishvananda/netlink/nl"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -25,6 +34,8 @@ type InterfaceMock struct {
 	LoopBack      bool
 	InterfaceUp   bool
 	CmdGet        map[string]uint64
+	Eeprom        []byte
+	Qdiscs        []Qdisc
 }
 
 type NamespaceMock struct {
@@ -51,6 +62,14 @@ func (n *NamespaceMock) Get(_ NamespacedInterface) (map[string]uint64, error) {
 	return nil, errors.New("it is a test bug to invoke this function")
 }
 
+func (n *NamespaceMock) ModuleEeprom(_ NamespacedInterface) ([]byte, error) {
+	return nil, errors.New("it is a test bug to invoke this function")
+}
+
+func (n *NamespaceMock) Qdiscs(_ NamespacedInterface) ([]Qdisc, error) {
+	return nil, errors.New("it is a test bug to invoke this function")
+}
+
 type CommandEthtoolMock struct {
 	InterfaceMap map[string]*InterfaceMock
 }
@@ -130,6 +149,25 @@ func (c *CommandEthtoolMock) Get(intf NamespacedInterface) (map[string]uint64, e
 	return nil, errors.New("interface not found")
 }
 
+func (c *CommandEthtoolMock) ModuleEeprom(intf NamespacedInterface) ([]byte, error) {
+	i := c.InterfaceMap[intf.Name]
+	if i == nil {
+		return nil, errors.New("interface not found")
+	}
+	if i.Eeprom == nil {
+		return nil, syscall.EOPNOTSUPP
+	}
+	return i.Eeprom, nil
+}
+
+func (c *CommandEthtoolMock) Qdiscs(intf NamespacedInterface) ([]Qdisc, error) {
+	i := c.InterfaceMap[intf.Name]
+	if i != nil {
+		return i.Qdiscs, nil
+	}
+	return nil, errors.New("interface not found")
+}
+
 func setup() {
 	interfaceMap = make(map[string]*InterfaceMock)
 
@@ -238,7 +276,7 @@ func setup() {
 		"link":    1,
 		"speed":   1000,
 	}
-	eth1 := &InterfaceMock{"eth1", "driver1", "", eth1Stat, false, true, eth1Get}
+	eth1 := &InterfaceMock{"eth1", "driver1", "", eth1Stat, false, true, eth1Get, nil, nil}
 	interfaceMap[eth1.Name] = eth1
 
 	eth2Stat := map[string]uint64{
@@ -346,7 +384,7 @@ func setup() {
 		"link":    0,
 		"speed":   9223372036854775807,
 	}
-	eth2 := &InterfaceMock{"eth2", "driver1", "", eth2Stat, false, false, eth2Get}
+	eth2 := &InterfaceMock{"eth2", "driver1", "", eth2Stat, false, false, eth2Get, nil, nil}
 	interfaceMap[eth2.Name] = eth2
 
 	eth3Stat := map[string]uint64{
@@ -454,7 +492,7 @@ func setup() {
 		"link":    1,
 		"speed":   1000,
 	}
-	eth3 := &InterfaceMock{"eth3", "driver1", "namespace1", eth3Stat, false, true, eth3Get}
+	eth3 := &InterfaceMock{"eth3", "driver1", "namespace1", eth3Stat, false, true, eth3Get, nil, nil}
 	interfaceMap[eth3.Name] = eth3
 
 	eth4Stat := map[string]uint64{
@@ -562,7 +600,7 @@ func setup() {
 		"link":    1,
 		"speed":   100,
 	}
-	eth4 := &InterfaceMock{"eth4", "driver1", "namespace2", eth4Stat, false, true, eth4Get}
+	eth4 := &InterfaceMock{"eth4", "driver1", "namespace2", eth4Stat, false, true, eth4Get, nil, nil}
 	interfaceMap[eth4.Name] = eth4
 
 	// dummy loopback including dummy stat to ensure that the ignore feature is working
@@ -575,7 +613,7 @@ func setup() {
 		"link":    1,
 		"speed":   1000,
 	}
-	lo0 := &InterfaceMock{"lo0", "", "", lo0Stat, true, true, lo0Get}
+	lo0 := &InterfaceMock{"lo0", "", "", lo0Stat, true, true, lo0Get, nil, nil}
 	interfaceMap[lo0.Name] = lo0
 
 	c := &CommandEthtoolMock{interfaceMap}
@@ -960,7 +998,7 @@ func TestNormalizedKeys(t *testing.T) {
 	}
 
 	for _, c := range cases {
-		eth0 := &InterfaceMock{"eth0", "e1000e", "", toStringMapUint(c.stats), false, true, map[string]uint64{}}
+		eth0 := &InterfaceMock{"eth0", "e1000e", "", toStringMapUint(c.stats), false, true, map[string]uint64{}, nil, nil}
 		expectedTags := map[string]string{
 			"interface": eth0.Name,
 			"driver":    eth0.DriverName,
@@ -991,3 +1029,330 @@ func TestNormalizedKeys(t *testing.T) {
 		acc.AssertContainsTaggedFields(t, pluginName, c.expectedFields, expectedTags)
 	}
 }
+
+func TestGatherQueueStats(t *testing.T) {
+	eth0 := &InterfaceMock{
+		Name:        "eth0",
+		DriverName:  "virtio_net",
+		Stat:        map[string]uint64{"rx_queue_0_packets": 10, "rx_queue_0_bytes": 1000, "tx_queue_1_packets": 5, "rx_drops": 1},
+		InterfaceUp: true,
+	}
+	eth1 := &InterfaceMock{
+		Name:        "eth1",
+		DriverName:  "mlx5_core",
+		Stat:        map[string]uint64{"rx0_packets": 7, "tx0_bytes": 700, "rx_packets": 7},
+		InterfaceUp: true,
+	}
+	interfaceMap = map[string]*InterfaceMock{eth0.Name: eth0, eth1.Name: eth1}
+
+	plugin := &Ethtool{
+		Collect: []string{"queues"},
+		command: &CommandEthtoolMock{interfaceMap},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ethtool",
+			map[string]string{"interface": "eth0", "driver": "virtio_net", "namespace": ""},
+			map[string]interface{}{"rx_drops": uint64(1), "interface_up": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_queue",
+			map[string]string{"interface": "eth0", "driver": "virtio_net", "namespace": "", "queue": "0", "direction": "rx"},
+			map[string]interface{}{"packets": uint64(10), "bytes": uint64(1000)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_queue",
+			map[string]string{"interface": "eth0", "driver": "virtio_net", "namespace": "", "queue": "1", "direction": "tx"},
+			map[string]interface{}{"packets": uint64(5)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool",
+			map[string]string{"interface": "eth1", "driver": "mlx5_core", "namespace": ""},
+			map[string]interface{}{"rx_packets": uint64(7), "interface_up": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_queue",
+			map[string]string{"interface": "eth1", "driver": "mlx5_core", "namespace": "", "queue": "0", "direction": "rx"},
+			map[string]interface{}{"packets": uint64(7)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_queue",
+			map[string]string{"interface": "eth1", "driver": "mlx5_core", "namespace": "", "queue": "0", "direction": "tx"},
+			map[string]interface{}{"bytes": uint64(700)},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestSplitQueueStat(t *testing.T) {
+	tests := []struct {
+		key       string
+		queue     string
+		direction string
+		name      string
+	}{
+		{"rx_queue_3_bytes", "3", "rx", "bytes"},
+		{"tx-2.tx_packets", "2", "tx", "packets"},
+		{"rx12_xdp_drop", "12", "rx", "xdp_drop"},
+		{"queue_1_tx_cnt", "1", "tx", "cnt"},
+		{"[4]: rx_ucast_packets", "4", "rx", "ucast_packets"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			key, name, found := splitQueueStat(tt.key)
+			require.True(t, found)
+			require.Equal(t, tt.queue, key.queue)
+			require.Equal(t, tt.direction, key.direction)
+			require.Equal(t, tt.name, name)
+		})
+	}
+
+	for _, key := range []string{"rx_packets", "tx_errors", "rx_1024_to_1518_bytes"} {
+		_, _, found := splitQueueStat(key)
+		require.False(t, found, key)
+	}
+}
+
+func TestGatherModule(t *testing.T) {
+	// SFP module with internally calibrated diagnostics
+	sfp := make([]byte, 512)
+	sfp[0] = moduleSFP
+	sfp[92] = 0x60
+	binary.BigEndian.PutUint16(sfp[256+96:], 0x1980) // 25.5 °C
+	binary.BigEndian.PutUint16(sfp[256+98:], 33000)  // 3.3 V
+	binary.BigEndian.PutUint16(sfp[256+100:], 3000)  // 6 mA
+	binary.BigEndian.PutUint16(sfp[256+102:], 10000) // 1 mW
+	binary.BigEndian.PutUint16(sfp[256+104:], 0)     // no light
+
+	// QSFP28 module
+	qsfp := make([]byte, 256)
+	qsfp[0] = moduleQSFP28
+	binary.BigEndian.PutUint16(qsfp[22:], 0x2100) // 33 °C
+	binary.BigEndian.PutUint16(qsfp[26:], 32500)  // 3.25 V
+	for i := 0; i < 4; i++ {
+		binary.BigEndian.PutUint16(qsfp[34+2*i:], 1000)           // 0.1 mW
+		binary.BigEndian.PutUint16(qsfp[42+2*i:], uint16(4000+i)) // ~8 mA
+		binary.BigEndian.PutUint16(qsfp[50+2*i:], 10000)          // 1 mW
+	}
+
+	eth0 := &InterfaceMock{Name: "eth0", DriverName: "ixgbe", Stat: map[string]uint64{}, InterfaceUp: true, Eeprom: sfp}
+	eth1 := &InterfaceMock{Name: "eth1", DriverName: "mlx5_core", Stat: map[string]uint64{}, InterfaceUp: true, Eeprom: qsfp}
+	eth2 := &InterfaceMock{Name: "eth2", DriverName: "virtio_net", Stat: map[string]uint64{}, InterfaceUp: true}
+	interfaceMap = map[string]*InterfaceMock{eth0.Name: eth0, eth1.Name: eth1, eth2.Name: eth2}
+
+	plugin := &Ethtool{
+		Collect: []string{"module"},
+		Log:     testutil.Logger{},
+		command: &CommandEthtoolMock{interfaceMap},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ethtool",
+			map[string]string{"interface": "eth0", "driver": "ixgbe", "namespace": ""},
+			map[string]interface{}{"interface_up": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_module",
+			map[string]string{"interface": "eth0", "driver": "ixgbe", "namespace": "", "type": "SFP"},
+			map[string]interface{}{"temperature": 25.5, "voltage": 3.3},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_module_lane",
+			map[string]string{"interface": "eth0", "driver": "ixgbe", "namespace": "", "type": "SFP", "lane": "1"},
+			map[string]interface{}{"tx_bias": 6.0, "tx_power": 1.0, "tx_power_dbm": 0.0, "rx_power": 0.0},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool",
+			map[string]string{"interface": "eth1", "driver": "mlx5_core", "namespace": ""},
+			map[string]interface{}{"interface_up": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_module",
+			map[string]string{"interface": "eth1", "driver": "mlx5_core", "namespace": "", "type": "QSFP28"},
+			map[string]interface{}{"temperature": 33.0, "voltage": 3.25},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool",
+			map[string]string{"interface": "eth2", "driver": "virtio_net", "namespace": ""},
+			map[string]interface{}{"interface_up": true},
+			time.Unix(0, 0),
+		),
+	}
+	for i := 0; i < 4; i++ {
+		expected = append(expected, metric.New(
+			"ethtool_module_lane",
+			map[string]string{"interface": "eth1", "driver": "mlx5_core", "namespace": "", "type": "QSFP28", "lane": strconv.Itoa(i + 1)},
+			map[string]interface{}{
+				"tx_bias":      float64(4000+i) * 0.002,
+				"tx_power":     1.0,
+				"tx_power_dbm": 0.0,
+				"rx_power":     0.1,
+				"rx_power_dbm": -10.0,
+			},
+			time.Unix(0, 0),
+		))
+	}
+	options := []cmp.Option{
+		testutil.IgnoreTime(),
+		testutil.SortMetrics(),
+		cmpopts.EquateApprox(0, 1e-9),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), options...)
+}
+
+func TestParseModuleEepromExternalCalibration(t *testing.T) {
+	sfp := make([]byte, 512)
+	sfp[0] = moduleSFP
+	sfp[92] = 0x50
+	a2 := sfp[256:]
+	binary.BigEndian.PutUint16(a2[96:], 0x1000)     // 16 °C raw
+	binary.BigEndian.PutUint16(a2[98:], 30000)      // 3 V raw
+	binary.BigEndian.PutUint16(a2[100:], 1000)      // 2 mA raw
+	binary.BigEndian.PutUint16(a2[102:], 5000)      // 0.5 mW raw
+	binary.BigEndian.PutUint16(a2[104:], 5000)      // 0.5 mW raw
+	binary.BigEndian.PutUint16(a2[76:], 0x0200)     // tx bias slope 2.0
+	binary.BigEndian.PutUint16(a2[80:], 0x0100)     // tx power slope 1.0
+	binary.BigEndian.PutUint16(a2[82:], 5000)       // tx power offset 0.5 mW
+	binary.BigEndian.PutUint16(a2[84:], 0x0100)     // temperature slope 1.0
+	binary.BigEndian.PutUint16(a2[86:], 0x0100)     // temperature offset 1 °C
+	binary.BigEndian.PutUint16(a2[88:], 0x0100)     // voltage slope 1.0
+	binary.BigEndian.PutUint32(a2[68:], 0x40000000) // rx power coefficient 1 = 2.0
+
+	diagnostics := parseModuleEeprom(sfp)
+	require.NotNil(t, diagnostics)
+	require.Equal(t, "SFP", diagnostics.moduleType)
+	require.InDelta(t, 17.0, diagnostics.temperature, 1e-9)
+	require.InDelta(t, 3.0, diagnostics.voltage, 1e-9)
+	require.Len(t, diagnostics.lanes, 1)
+	require.InDelta(t, 4.0, diagnostics.lanes[0].txBias, 1e-9)
+	require.InDelta(t, 1.0, diagnostics.lanes[0].txPower, 1e-9)
+	require.InDelta(t, 1.0, diagnostics.lanes[0].rxPower, 1e-9)
+
+	// Modules without diagnostics
+	sfp[92] = 0
+	require.Nil(t, parseModuleEeprom(sfp))
+	require.Nil(t, parseModuleEeprom(nil))
+}
+
+func TestGatherQdisc(t *testing.T) {
+	eth0 := &InterfaceMock{
+		Name:        "eth0",
+		DriverName:  "e1000e",
+		Stat:        map[string]uint64{},
+		InterfaceUp: true,
+		Qdiscs: []Qdisc{
+			{Kind: "mq", Handle: 0x00010000, Parent: 0xffffffff, Bytes: 2000, Packets: 20, Drops: 3},
+			{Kind: "fq_codel", Handle: 0, Parent: 0x00010001, Bytes: 1000, Packets: 10, Backlog: 1514, Qlen: 1, Drops: 3, Requeues: 1, Overlimits: 2},
+		},
+	}
+	interfaceMap = map[string]*InterfaceMock{eth0.Name: eth0}
+
+	plugin := &Ethtool{
+		Collect: []string{"qdisc"},
+		command: &CommandEthtoolMock{interfaceMap},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ethtool",
+			map[string]string{"interface": "eth0", "driver": "e1000e", "namespace": ""},
+			map[string]interface{}{"interface_up": true},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_qdisc",
+			map[string]string{"interface": "eth0", "driver": "e1000e", "namespace": "", "kind": "mq", "handle": "1:", "parent": "root"},
+			map[string]interface{}{
+				"bytes":      uint64(2000),
+				"packets":    uint64(20),
+				"qlen":       uint64(0),
+				"backlog":    uint64(0),
+				"drops":      uint64(3),
+				"requeues":   uint64(0),
+				"overlimits": uint64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"ethtool_qdisc",
+			map[string]string{"interface": "eth0", "driver": "e1000e", "namespace": "", "kind": "fq_codel", "handle": "none", "parent": "1:1"},
+			map[string]interface{}{
+				"bytes":      uint64(1000),
+				"packets":    uint64(10),
+				"qlen":       uint64(1),
+				"backlog":    uint64(1514),
+				"drops":      uint64(3),
+				"requeues":   uint64(1),
+				"overlimits": uint64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestParseQdisc(t *testing.T) {
+	native := nl.NativeEndian()
+
+	basic := make([]byte, 16)
+	native.PutUint64(basic[0:], 123456)
+	native.PutUint32(basic[8:], 789)
+	queue := make([]byte, 20)
+	for i, v := range []uint32{1, 1514, 3, 4, 5} {
+		native.PutUint32(queue[4*i:], v)
+	}
+
+	stats := nl.NewRtAttr(nl.TCA_STATS2, nil)
+	stats.AddRtAttr(nl.TCA_STATS_BASIC, basic)
+	stats.AddRtAttr(nl.TCA_STATS_QUEUE, queue)
+	data := append(nl.NewRtAttr(nl.TCA_KIND, nl.ZeroTerminated("fq_codel")).Serialize(), stats.Serialize()...)
+
+	qdisc, err := parseQdisc(&nl.TcMsg{Handle: 0x80010000, Parent: 0xffffffff}, data)
+	require.NoError(t, err)
+	require.Equal(t, Qdisc{
+		Kind:       "fq_codel",
+		Handle:     0x80010000,
+		Parent:     0xffffffff,
+		Bytes:      123456,
+		Packets:    789,
+		Qlen:       1,
+		Backlog:    1514,
+		Drops:      3,
+		Requeues:   4,
+		Overlimits: 5,
+	}, qdisc)
+}
+
+func TestInvalidCollect(t *testing.T) {
+	plugin := &Ethtool{
+		Collect: []string{"foo"},
+		command: &CommandEthtoolMock{},
+	}
+	require.ErrorContains(t, plugin.Init(), "collect")
+}
@@ -0,0 +1,143 @@
+//go:build linux
+
+package ethtool
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// Identifiers of the transceiver types as defined in SFF-8024
+const (
+	moduleSFP      = 0x03
+	moduleQSFP     = 0x0c
+	moduleQSFPPlus = 0x0d
+	moduleQSFP28   = 0x11
+)
+
+var moduleTypes = map[byte]string{
+	moduleSFP:      "SFP",
+	moduleQSFP:     "QSFP",
+	moduleQSFPPlus: "QSFP+",
+	moduleQSFP28:   "QSFP28",
+}
+
+// moduleDiagnostics contains the digital diagnostic monitoring (DOM) values
+// of a transceiver module
+type moduleDiagnostics struct {
+	moduleType  string
+	temperature float64 // degree Celsius
+	voltage     float64 // Volts
+	lanes       []laneDiagnostics
+}
+
+type laneDiagnostics struct {
+	txBias  float64 // milli-Amperes
+	txPower float64 // milli-Watts
+	rxPower float64 // milli-Watts
+}
+
+// parseModuleEeprom decodes the diagnostics of the module EEPROM data. Modules
+// without diagnostics support or of unknown type return nil.
+func parseModuleEeprom(data []byte) *moduleDiagnostics {
+	if len(data) == 0 {
+		return nil
+	}
+
+	switch data[0] {
+	case moduleSFP:
+		return parseSFF8472(data)
+	case moduleQSFP, moduleQSFPPlus, moduleQSFP28:
+		return parseSFF8636(data)
+	}
+	return nil
+}
+
+// parseSFF8472 decodes the diagnostics of SFP modules. The diagnostics are
+// located in the second 256 byte page (address A2h) of the EEPROM.
+func parseSFF8472(data []byte) *moduleDiagnostics {
+	const (
+		page                = 256
+		diagnosticType      = 92
+		diagnosticsDDM      = 1 << 6
+		diagnosticsExternal = 1 << 4
+	)
+
+	if len(data) < 2*page || data[diagnosticType]&diagnosticsDDM == 0 {
+		return nil
+	}
+	a2 := data[page : 2*page]
+
+	temperature := float64(int16(binary.BigEndian.Uint16(a2[96:])))
+	voltage := float64(binary.BigEndian.Uint16(a2[98:]))
+	txBias := float64(binary.BigEndian.Uint16(a2[100:]))
+	txPower := float64(binary.BigEndian.Uint16(a2[102:]))
+	rxPower := float64(binary.BigEndian.Uint16(a2[104:]))
+
+	// Externally calibrated modules provide the constants to convert the raw
+	// values, see SFF-8472 section 9.3
+	if data[diagnosticType]&diagnosticsExternal != 0 {
+		calibrate := func(value float64, offset int) float64 {
+			slope := float64(binary.BigEndian.Uint16(a2[offset:])) / 256.0
+			return value*slope + float64(int16(binary.BigEndian.Uint16(a2[offset+2:])))
+		}
+		txBias = calibrate(txBias, 76)
+		txPower = calibrate(txPower, 80)
+		temperature = calibrate(temperature, 84)
+		voltage = calibrate(voltage, 88)
+
+		// The receive power is calibrated using a polynomial of fourth order
+		raw := rxPower
+		rxPower = 0
+		for i := 0; i < 5; i++ {
+			coefficient := math.Float32frombits(binary.BigEndian.Uint32(a2[72-4*i:]))
+			rxPower += float64(coefficient) * math.Pow(raw, float64(i))
+		}
+	}
+
+	return &moduleDiagnostics{
+		moduleType:  moduleTypes[data[0]],
+		temperature: temperature / 256.0,
+		voltage:     voltage / 10000.0,
+		lanes: []laneDiagnostics{
+			{
+				txBias:  txBias * 0.002,
+				txPower: txPower / 10000.0,
+				rxPower: rxPower / 10000.0,
+			},
+		},
+	}
+}
+
+// parseSFF8636 decodes the diagnostics of QSFP modules which are located in
+// the lower memory page and are always internally calibrated.
+func parseSFF8636(data []byte) *moduleDiagnostics {
+	const lanes = 4
+
+	if len(data) < 58 {
+		return nil
+	}
+
+	diagnostics := &moduleDiagnostics{
+		moduleType:  moduleTypes[data[0]],
+		temperature: float64(int16(binary.BigEndian.Uint16(data[22:]))) / 256.0,
+		voltage:     float64(binary.BigEndian.Uint16(data[26:])) / 10000.0,
+		lanes:       make([]laneDiagnostics, 0, lanes),
+	}
+	for i := 0; i < lanes; i++ {
+		diagnostics.lanes = append(diagnostics.lanes, laneDiagnostics{
+			rxPower: float64(binary.BigEndian.Uint16(data[34+2*i:])) / 10000.0,
+			txBias:  float64(binary.BigEndian.Uint16(data[42+2*i:])) * 0.002,
+			txPower: float64(binary.BigEndian.Uint16(data[50+2*i:])) / 10000.0,
+		})
+	}
+	return diagnostics
+}
+
+// milliWattToDBm converts the power to dBm, zero power cannot be represented
+func milliWattToDBm(power float64) (float64, bool) {
+	if power <= 0 {
+		return 0, false
+	}
+	return 10 * math.Log10(power), true
+}
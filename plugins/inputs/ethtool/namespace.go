@@ -8,9 +8,26 @@ type Namespace interface {
 	DriverName(intf NamespacedInterface) (string, error)
 	Stats(intf NamespacedInterface) (map[string]uint64, error)
 	Get(intf NamespacedInterface) (map[string]uint64, error)
+	ModuleEeprom(intf NamespacedInterface) ([]byte, error)
+	Qdiscs(intf NamespacedInterface) ([]Qdisc, error)
 }
 
 type NamespacedInterface struct {
 	net.Interface
 	Namespace Namespace
 }
+
+// Qdisc contains the statistics of a queueing discipline attached to an
+// interface
+type Qdisc struct {
+	Kind       string
+	Handle     uint32
+	Parent     uint32
+	Bytes      uint64
+	Packets    uint64
+	Qlen       uint64
+	Backlog    uint64
+	Drops      uint64
+	Requeues   uint64
+	Overlimits uint64
+}
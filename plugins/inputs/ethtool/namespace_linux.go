@@ -1,12 +1,16 @@
 package ethtool
 
 import (
+	"bytes"
+	"fmt"
 	"math"
 	"net"
 	"runtime"
 
 	ethtoolLib "github.com/safchain/ethtool"
+	"github.com/vishvananda/netlink/nl"
 	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
 
 	"github.com/influxdata/telegraf"
 )
@@ -102,6 +106,99 @@ func (n *NamespaceGoroutine) Get(intf NamespacedInterface) (map[string]uint64, e
 	return nil, err
 }
 
+func (n *NamespaceGoroutine) ModuleEeprom(intf NamespacedInterface) ([]byte, error) {
+	result, err := n.Do(func(n *NamespaceGoroutine) (interface{}, error) {
+		return n.ethtoolClient.ModuleEeprom(intf.Name)
+	})
+	if result != nil {
+		return result.([]byte), err
+	}
+	return nil, err
+}
+
+func (n *NamespaceGoroutine) Qdiscs(intf NamespacedInterface) ([]Qdisc, error) {
+	result, err := n.Do(func(n *NamespaceGoroutine) (interface{}, error) {
+		// The netlink socket is created in the namespace of the current thread
+		req := nl.NewNetlinkRequest(unix.RTM_GETQDISC, unix.NLM_F_DUMP)
+		req.AddData(&nl.TcMsg{
+			Family:  nl.FAMILY_ALL,
+			Ifindex: int32(intf.Index),
+		})
+		msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWQDISC)
+		if err != nil {
+			return nil, err
+		}
+
+		qdiscs := make([]Qdisc, 0, len(msgs))
+		for _, m := range msgs {
+			msg := nl.DeserializeTcMsg(m)
+			if msg.Ifindex != int32(intf.Index) {
+				continue
+			}
+			qdisc, err := parseQdisc(msg, m[msg.Len():])
+			if err != nil {
+				return nil, err
+			}
+			qdiscs = append(qdiscs, qdisc)
+		}
+		return qdiscs, nil
+	})
+
+	if result != nil {
+		return result.([]Qdisc), err
+	}
+	return nil, err
+}
+
+// parseQdisc decodes the kind and the generic statistics of a qdisc message
+func parseQdisc(msg *nl.TcMsg, data []byte) (Qdisc, error) {
+	qdisc := Qdisc{
+		Handle: msg.Handle,
+		Parent: msg.Parent,
+	}
+
+	attrs, err := nl.ParseRouteAttr(data)
+	if err != nil {
+		return qdisc, err
+	}
+
+	// Netlink uses the byte order of the host
+	native := nl.NativeEndian()
+	for _, attr := range attrs {
+		switch attr.Attr.Type {
+		case nl.TCA_KIND:
+			qdisc.Kind = string(bytes.TrimRight(attr.Value, "\x00"))
+		case nl.TCA_STATS2:
+			stats, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return qdisc, err
+			}
+			for _, stat := range stats {
+				switch stat.Attr.Type {
+				case nl.TCA_STATS_BASIC:
+					// struct gnet_stats_basic { __u64 bytes; __u32 packets; }
+					if len(stat.Value) < 12 {
+						return qdisc, fmt.Errorf("invalid basic statistics length %d", len(stat.Value))
+					}
+					qdisc.Bytes = native.Uint64(stat.Value[0:8])
+					qdisc.Packets = uint64(native.Uint32(stat.Value[8:12]))
+				case nl.TCA_STATS_QUEUE:
+					// struct gnet_stats_queue { __u32 qlen, backlog, drops, requeues, overlimits; }
+					if len(stat.Value) < 20 {
+						return qdisc, fmt.Errorf("invalid queue statistics length %d", len(stat.Value))
+					}
+					qdisc.Qlen = uint64(native.Uint32(stat.Value[0:4]))
+					qdisc.Backlog = uint64(native.Uint32(stat.Value[4:8]))
+					qdisc.Drops = uint64(native.Uint32(stat.Value[8:12]))
+					qdisc.Requeues = uint64(native.Uint32(stat.Value[12:16]))
+					qdisc.Overlimits = uint64(native.Uint32(stat.Value[16:20]))
+				}
+			}
+		}
+	}
+	return qdisc, nil
+}
+
 // Start locks a goroutine to an OS thread and ties it to the namespace, then
 // loops for actions to run in the namespace.
 func (n *NamespaceGoroutine) Start() error {
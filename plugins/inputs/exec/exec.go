@@ -2,6 +2,7 @@
 package exec
 
 import (
+	"bufio"
 	"bytes"
 	_ "embed"
 	"errors"
@@ -30,14 +31,18 @@ var sampleConfig string
 
 const MaxStderrBytes int = 512
 
+// maxLineSize is the maximum size of a line in streaming mode
+const maxLineSize = 16 * 1024 * 1024
+
 type exitcodeHandlerFunc func([]telegraf.Metric, error, []byte) []telegraf.Metric
 
 type Exec struct {
-	Commands    []string        `toml:"commands"`
-	Command     string          `toml:"command"`
-	Environment []string        `toml:"environment"`
-	Timeout     config.Duration `toml:"timeout"`
-	Log         telegraf.Logger `toml:"-"`
+	Commands       []string        `toml:"commands"`
+	Command        string          `toml:"command"`
+	Environment    []string        `toml:"environment"`
+	Timeout        config.Duration `toml:"timeout"`
+	CommandConfigs []CommandConfig `toml:"command_config"`
+	Log            telegraf.Logger `toml:"-"`
 
 	parser telegraf.Parser
 
@@ -48,6 +53,21 @@ type Exec struct {
 	parseDespiteError bool
 }
 
+// CommandConfig contains the settings of a command overriding the global ones
+type CommandConfig struct {
+	Command          string          `toml:"command"`
+	Environment      []string        `toml:"environment"`
+	WorkingDirectory string          `toml:"working_directory"`
+	Timeout          config.Duration `toml:"timeout"`
+	Streaming        bool            `toml:"streaming"`
+}
+
+// job is a command to run with its settings
+type job struct {
+	command  string
+	settings *CommandConfig
+}
+
 func NewExec() *Exec {
 	return &Exec{
 		runner:  CommandRunner{},
@@ -56,27 +76,39 @@ func NewExec() *Exec {
 }
 
 type Runner interface {
-	Run(string, []string, time.Duration) ([]byte, []byte, error)
+	Run(string, string, []string, time.Duration) ([]byte, []byte, error)
+	Stream(string, string, []string, time.Duration, func([]byte)) ([]byte, error)
 }
 
 type CommandRunner struct{}
 
-func (c CommandRunner) Run(
-	command string,
-	environments []string,
-	timeout time.Duration,
-) ([]byte, []byte, error) {
+func newCommand(command string, dir string, environments []string) (*osExec.Cmd, error) {
 	splitCmd, err := shellquote.Split(command)
 	if err != nil || len(splitCmd) == 0 {
-		return nil, nil, fmt.Errorf("exec: unable to parse command: %w", err)
+		return nil, fmt.Errorf("exec: unable to parse command: %w", err)
 	}
 
 	cmd := osExec.Command(splitCmd[0], splitCmd[1:]...)
+	cmd.Dir = dir
 
 	if len(environments) > 0 {
 		cmd.Env = append(os.Environ(), environments...)
 	}
 
+	return cmd, nil
+}
+
+func (c CommandRunner) Run(
+	command string,
+	dir string,
+	environments []string,
+	timeout time.Duration,
+) ([]byte, []byte, error) {
+	cmd, err := newCommand(command, dir, environments)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	var (
 		out    bytes.Buffer
 		stderr bytes.Buffer
@@ -95,6 +127,70 @@ func (c CommandRunner) Run(
 	return out.Bytes(), stderr.Bytes(), runErr
 }
 
+// Stream runs the command and calls the given function for each line of the
+// output as soon as the line is complete.
+func (c CommandRunner) Stream(
+	command string,
+	dir string,
+	environments []string,
+	timeout time.Duration,
+	onLine func([]byte),
+) ([]byte, error) {
+	cmd, err := newCommand(command, dir, environments)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use an explicit pipe instead of cmd.StdoutPipe() to be able to wait
+	// for the command while still reading its output
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("exec: creating pipe failed: %w", err)
+	}
+	defer reader.Close()
+
+	var stderr bytes.Buffer
+	cmd.Stdout = writer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		writer.Close()
+		return nil, err
+	}
+	writer.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- internal.WaitTimeout(cmd, timeout)
+	}()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		if line := bytes.TrimRight(scanner.Bytes(), "\r"); len(line) > 0 {
+			onLine(line)
+		}
+	}
+	scanErr := scanner.Err()
+
+	// Make sure the command terminates even if we stopped reading due to an
+	// error as it might block writing to the pipe otherwise
+	if scanErr != nil {
+		reader.Close()
+	}
+	runErr := <-done
+
+	if stderr.Len() > 0 && !telegraf.Debug {
+		stderr = removeWindowsCarriageReturns(stderr)
+		stderr = c.truncate(stderr)
+	}
+
+	if runErr == nil && scanErr != nil {
+		runErr = fmt.Errorf("reading output failed: %w", scanErr)
+	}
+	return stderr.Bytes(), runErr
+}
+
 func (c CommandRunner) truncate(buf bytes.Buffer) bytes.Buffer {
 	// Limit the number of bytes.
 	didTruncate := false
@@ -139,9 +235,32 @@ func (*Exec) SampleConfig() string {
 }
 
 func (e *Exec) ProcessCommand(command string, acc telegraf.Accumulator, wg *sync.WaitGroup) {
+	e.processJob(job{command: command}, acc, wg)
+}
+
+func (e *Exec) processJob(j job, acc telegraf.Accumulator, wg *sync.WaitGroup) {
 	defer wg.Done()
 
-	out, errBuf, runErr := e.runner.Run(command, e.Environment, time.Duration(e.Timeout))
+	command := j.command
+	environment := e.Environment
+	timeout := time.Duration(e.Timeout)
+	var dir string
+	if j.settings != nil {
+		if len(j.settings.Environment) > 0 {
+			environment = append(append([]string{}, e.Environment...), j.settings.Environment...)
+		}
+		if j.settings.Timeout > 0 {
+			timeout = time.Duration(j.settings.Timeout)
+		}
+		dir = j.settings.WorkingDirectory
+
+		if j.settings.Streaming {
+			e.streamCommand(command, dir, environment, timeout, acc)
+			return
+		}
+	}
+
+	out, errBuf, runErr := e.runner.Run(command, dir, environment, timeout)
 	if !e.parseDespiteError && runErr != nil {
 		err := fmt.Errorf("exec: %w for command %q: %s", runErr, command, string(errBuf))
 		acc.AddError(err)
@@ -163,6 +282,24 @@ func (e *Exec) ProcessCommand(command string, acc telegraf.Accumulator, wg *sync
 	}
 }
 
+// streamCommand parses every line of the command output as soon as it is
+// received instead of waiting for the command to finish
+func (e *Exec) streamCommand(command, dir string, environment []string, timeout time.Duration, acc telegraf.Accumulator) {
+	errBuf, runErr := e.runner.Stream(command, dir, environment, timeout, func(line []byte) {
+		metrics, err := e.parser.Parse(line)
+		if err != nil {
+			acc.AddError(fmt.Errorf("exec: parsing output of command %q failed: %w", command, err))
+			return
+		}
+		for _, m := range metrics {
+			acc.AddMetric(m)
+		}
+	})
+	if runErr != nil {
+		acc.AddError(fmt.Errorf("exec: %w for command %q: %s", runErr, command, string(errBuf)))
+	}
+}
+
 func (e *Exec) SetParser(parser telegraf.Parser) {
 	e.parser = parser
 	unwrapped, ok := parser.(*models.RunningParser)
@@ -182,46 +319,76 @@ func (e *Exec) Gather(acc telegraf.Accumulator) error {
 		e.Command = ""
 	}
 
-	commands := make([]string, 0, len(e.Commands))
+	jobs := make([]job, 0, len(e.Commands)+len(e.CommandConfigs))
 	for _, pattern := range e.Commands {
-		cmdAndArgs := strings.SplitN(pattern, " ", 2)
-		if len(cmdAndArgs) == 0 {
+		commands, err := expandCommand(pattern)
+		if err != nil {
+			acc.AddError(err)
 			continue
 		}
-
-		matches, err := filepath.Glob(cmdAndArgs[0])
+		for _, command := range commands {
+			jobs = append(jobs, job{command: command})
+		}
+	}
+	for i := range e.CommandConfigs {
+		settings := &e.CommandConfigs[i]
+		commands, err := expandCommand(settings.Command)
 		if err != nil {
 			acc.AddError(err)
 			continue
 		}
-
-		if len(matches) == 0 {
-			// There were no matches with the glob pattern, so let's assume
-			// that the command is in PATH and just run it as it is
-			commands = append(commands, pattern)
-		} else {
-			// There were matches, so we'll append each match together with
-			// the arguments to the commands slice
-			for _, match := range matches {
-				if len(cmdAndArgs) == 1 {
-					commands = append(commands, match)
-				} else {
-					commands = append(commands,
-						strings.Join([]string{match, cmdAndArgs[1]}, " "))
-				}
-			}
+		for _, command := range commands {
+			jobs = append(jobs, job{command: command, settings: settings})
 		}
 	}
 
-	wg.Add(len(commands))
-	for _, command := range commands {
-		go e.ProcessCommand(command, acc, &wg)
+	wg.Add(len(jobs))
+	for _, j := range jobs {
+		go e.processJob(j, acc, &wg)
 	}
 	wg.Wait()
 	return nil
 }
 
+// expandCommand returns the commands matching the glob pattern of the
+// executable keeping the arguments
+func expandCommand(pattern string) ([]string, error) {
+	cmdAndArgs := strings.SplitN(pattern, " ", 2)
+	if len(cmdAndArgs) == 0 {
+		return nil, nil
+	}
+
+	matches, err := filepath.Glob(cmdAndArgs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(matches) == 0 {
+		// There were no matches with the glob pattern, so let's assume
+		// that the command is in PATH and just run it as it is
+		return []string{pattern}, nil
+	}
+
+	// There were matches, so we'll append each match together with
+	// the arguments to the commands slice
+	commands := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if len(cmdAndArgs) == 1 {
+			commands = append(commands, match)
+		} else {
+			commands = append(commands,
+				strings.Join([]string{match, cmdAndArgs[1]}, " "))
+		}
+	}
+	return commands, nil
+}
+
 func (e *Exec) Init() error {
+	for _, c := range e.CommandConfigs {
+		if c.Command == "" {
+			return errors.New("command_config without command")
+		}
+	}
 	return nil
 }
 
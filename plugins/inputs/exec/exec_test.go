@@ -8,6 +8,7 @@ package exec
 import (
 	"bytes"
 	"fmt"
+	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
@@ -81,10 +82,19 @@ func newRunnerMock(out []byte, errout []byte, err error) Runner {
 	}
 }
 
-func (r runnerMock) Run(_ string, _ []string, _ time.Duration) ([]byte, []byte, error) {
+func (r runnerMock) Run(_ string, _ string, _ []string, _ time.Duration) ([]byte, []byte, error) {
 	return r.out, r.errout, r.err
 }
 
+func (r runnerMock) Stream(_ string, _ string, _ []string, _ time.Duration, onLine func([]byte)) ([]byte, error) {
+	for _, line := range bytes.Split(r.out, []byte("\n")) {
+		if len(line) > 0 {
+			onLine(line)
+		}
+	}
+	return r.errout, r.err
+}
+
 func TestExec(t *testing.T) {
 	parser := &json.Parser{MetricName: "exec"}
 	require.NoError(t, parser.Init())
@@ -222,6 +232,104 @@ func TestExecCommandWithEnv(t *testing.T) {
 	acc.AssertContainsFields(t, "metric", fields)
 }
 
+func TestExecCommandConfig(t *testing.T) {
+	parser := value.Parser{
+		MetricName: "metric",
+		DataType:   "string",
+	}
+	require.NoError(t, parser.Init())
+
+	dir := t.TempDir()
+	e := NewExec()
+	e.Environment = []string{"GLOBAL=global"}
+	e.CommandConfigs = []CommandConfig{
+		{
+			Command:          "/bin/sh -c 'echo ${GLOBAL}-${LOCAL}-$(pwd)'",
+			Environment:      []string{"LOCAL=local"},
+			WorkingDirectory: dir,
+		},
+	}
+	e.SetParser(&parser)
+	require.NoError(t, e.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+
+	realDir, err := filepath.EvalSymlinks(dir)
+	require.NoError(t, err)
+	acc.AssertContainsFields(t, "metric", map[string]interface{}{
+		"value": "global-local-" + realDir,
+	})
+}
+
+func TestExecCommandConfigTimeout(t *testing.T) {
+	parser := value.Parser{
+		MetricName: "metric",
+		DataType:   "string",
+	}
+	require.NoError(t, parser.Init())
+
+	e := NewExec()
+	e.Timeout = config.Duration(100 * time.Millisecond)
+	e.CommandConfigs = []CommandConfig{
+		{
+			Command: "/bin/sh -c 'sleep 0.5 && echo done'",
+			Timeout: config.Duration(5 * time.Second),
+		},
+	}
+	e.SetParser(&parser)
+	require.NoError(t, e.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+	acc.AssertContainsFields(t, "metric", map[string]interface{}{"value": "done"})
+
+	// The global timeout applies if none is given
+	e.CommandConfigs[0].Timeout = 0
+	acc.ClearMetrics()
+	require.ErrorContains(t, acc.GatherError(e.Gather), "timed out")
+}
+
+func TestExecStreaming(t *testing.T) {
+	parser := &json.Parser{MetricName: "exec"}
+	require.NoError(t, parser.Init())
+
+	e := NewExec()
+	e.CommandConfigs = []CommandConfig{
+		{
+			Command:   `/bin/sh -c 'for i in 1 2 3; do echo "{\"value\": $i}"; done'`,
+			Streaming: true,
+		},
+	}
+	e.SetParser(parser)
+	require.NoError(t, e.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New("exec", map[string]string{}, map[string]interface{}{"value": float64(1)}, time.Unix(0, 0)),
+		metric.New("exec", map[string]string{}, map[string]interface{}{"value": float64(2)}, time.Unix(0, 0)),
+		metric.New("exec", map[string]string{}, map[string]interface{}{"value": float64(3)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestExecStreamingMalformed(t *testing.T) {
+	parser := &json.Parser{MetricName: "exec"}
+	require.NoError(t, parser.Init())
+	e := &Exec{
+		Log:            testutil.Logger{},
+		runner:         newRunnerMock([]byte("{\"value\": 1}\n{invalid\n{\"value\": 3}\n"), nil, nil),
+		CommandConfigs: []CommandConfig{{Command: "testcommand", Streaming: true}},
+		parser:         parser,
+	}
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(e.Gather))
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
+
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		name string
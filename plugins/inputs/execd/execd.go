@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/process"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/framing"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 )
@@ -30,7 +31,12 @@ type Execd struct {
 	Log          telegraf.Logger `toml:"-"`
 	BufferSize   config.Size     `toml:"buffer_size"`
 
+	Protocol          string          `toml:"protocol"`
+	HeartbeatInterval config.Duration `toml:"heartbeat_interval"`
+	PluginConfig      string          `toml:"plugin_config"`
+
 	process      *process.Process
+	conn         *process.FrameConn
 	acc          telegraf.Accumulator
 	parser       telegraf.Parser
 	outputReader func(io.Reader)
@@ -63,6 +69,12 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
 	e.process.ReadStdoutFn = e.outputReader
 	e.process.ReadStderrFn = e.cmdReadErr
+	if e.Protocol == process.ProtocolV2 {
+		e.conn = process.NewFrameConn(e.process)
+		e.conn.HeartbeatInterval = time.Duration(e.HeartbeatInterval)
+		e.conn.Config = []byte(e.PluginConfig)
+		e.process.ReadStdoutFn = e.conn.Reader(e.handleFrame)
+	}
 
 	if err = e.process.Start(); err != nil {
 		// if there was only one argument, and it contained spaces, warn the user
@@ -74,14 +86,46 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 		}
 		return fmt.Errorf("failed to start process %s: %w", e.Command, err)
 	}
+	if e.conn != nil {
+		e.conn.Start()
+	}
 
 	return nil
 }
 
 func (e *Execd) Stop() {
+	if e.conn != nil {
+		e.conn.Stop()
+	}
 	e.process.Stop()
 }
 
+// handleFrame processes the frames of the protocol v2 and acknowledges the
+// data frames after adding the metrics
+func (e *Execd) handleFrame(f framing.Frame) {
+	switch f.Type {
+	case framing.Data:
+		metrics, err := e.parser.Parse(f.Payload)
+		if err != nil {
+			e.acc.AddError(fmt.Errorf("parse error: %w", err))
+			if err := e.conn.Write(framing.Error, []byte(err.Error())); err != nil {
+				e.Log.Errorf("Sending error failed: %v", err)
+			}
+			return
+		}
+		for _, metric := range metrics {
+			e.acc.AddMetric(metric)
+		}
+		if err := e.conn.Write(framing.Ack, nil); err != nil {
+			e.Log.Errorf("Sending acknowledgement failed: %v", err)
+		}
+	case framing.Error:
+		e.acc.AddError(fmt.Errorf("process reported error: %s", f.Payload))
+	default:
+		e.Log.Debugf("Ignoring unexpected frame of type %q", f.Type)
+	}
+}
+
 func (e *Execd) cmdReadOut(out io.Reader) {
 	rdr := bufio.NewReaderSize(out, int(e.BufferSize))
 
@@ -146,6 +190,34 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
+
+	switch e.Protocol {
+	case "":
+		e.Protocol = process.ProtocolV1
+	case process.ProtocolV1, process.ProtocolV2:
+	default:
+		return fmt.Errorf("invalid protocol %q", e.Protocol)
+	}
+
+	return nil
+}
+
+// signalStdin requests the process to gather metrics via stdin
+func (e *Execd) signalStdin() error {
+	if e.conn != nil {
+		return e.conn.Write(framing.Gather, nil)
+	}
+
+	if osStdin, ok := e.process.Stdin.(*os.File); ok {
+		if err := osStdin.SetWriteDeadline(time.Now().Add(1 * time.Second)); err != nil {
+			if !errors.Is(err, os.ErrNoDeadline) {
+				return fmt.Errorf("setting write deadline failed: %w", err)
+			}
+		}
+	}
+	if _, err := io.WriteString(e.process.Stdin, "\n"); err != nil {
+		return fmt.Errorf("writing to stdin failed: %w", err)
+	}
 	return nil
 }
 
@@ -155,6 +227,7 @@ func init() {
 			Signal:       "none",
 			RestartDelay: config.Duration(10 * time.Second),
 			BufferSize:   config.Size(64 * 1024),
+			Protocol:     process.ProtocolV1,
 		}
 	})
 }
@@ -4,10 +4,7 @@ package execd
 
 import (
 	"fmt"
-	"io"
-	"os"
 	"syscall"
-	"time"
 
 	"github.com/influxdata/telegraf"
 )
@@ -29,14 +26,7 @@ func (e *Execd) Gather(_ telegraf.Accumulator) error {
 	case "SIGUSR2":
 		return osProcess.Signal(syscall.SIGUSR2)
 	case "STDIN":
-		if osStdin, ok := e.process.Stdin.(*os.File); ok {
-			if err := osStdin.SetWriteDeadline(time.Now().Add(1 * time.Second)); err != nil {
-				return fmt.Errorf("setting write deadline failed: %w", err)
-			}
-		}
-		if _, err := io.WriteString(e.process.Stdin, "\n"); err != nil {
-			return fmt.Errorf("writing to stdin failed: %w", err)
-		}
+		return e.signalStdin()
 	case "none":
 	default:
 		return fmt.Errorf("invalid signal: %s", e.Signal)
@@ -2,8 +2,10 @@ package execd
 
 import (
 	"bufio"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"testing"
@@ -16,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/models"
+	"github.com/influxdata/telegraf/plugins/common/framing"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
 	influxSerializer "github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -76,6 +79,52 @@ func TestExternalInputWorks(t *testing.T) {
 	require.EqualValues(t, 0, val)
 }
 
+func TestExternalInputProtocolV2(t *testing.T) {
+	influxParser := models.NewRunningParser(&influx.Parser{}, &models.ParserConfig{})
+	require.NoError(t, influxParser.Init())
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	e := &Execd{
+		Command:           []string{exe, "-framed"},
+		Environment:       []string{"PLUGINS_INPUTS_EXECD_MODE=application"},
+		RestartDelay:      config.Duration(5 * time.Second),
+		Signal:            "STDIN",
+		Protocol:          "v2",
+		HeartbeatInterval: config.Duration(time.Second),
+		PluginConfig:      "framed_counter",
+		Log:               testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+	e.SetParser(influxParser)
+
+	metrics := make(chan telegraf.Metric, 10)
+	defer close(metrics)
+	acc := agent.NewAccumulator(&TestMetricMaker{}, metrics)
+
+	require.NoError(t, e.Start(acc))
+	require.NoError(t, e.Gather(acc))
+	m := readChanWithTimeout(t, metrics, 10*time.Second)
+	require.NoError(t, e.Gather(acc))
+	m2 := readChanWithTimeout(t, metrics, 10*time.Second)
+	e.Stop()
+
+	// The metric name is pushed via configuration and the process only sends
+	// the second metric after the first one was acknowledged
+	require.Equal(t, "framed_counter", m.Name())
+	require.Equal(t, map[string]interface{}{"count": int64(0), "message": "multi\nline"}, m.Fields())
+	require.Equal(t, map[string]interface{}{"count": int64(1), "message": "multi\nline"}, m2.Fields())
+}
+
+func TestInvalidProtocol(t *testing.T) {
+	e := &Execd{
+		Command:  []string{"foo"},
+		Protocol: "v3",
+	}
+	require.ErrorContains(t, e.Init(), `invalid protocol "v3"`)
+}
+
 func TestParsesLinesContainingNewline(t *testing.T) {
 	parser := models.NewRunningParser(&influx.Parser{}, &models.ParserConfig{})
 	require.NoError(t, parser.Init())
@@ -191,6 +240,9 @@ func (tm *TestMetricMaker) Log() telegraf.Logger {
 var counter = flag.Bool("counter", false,
 	"if true, act like line input program instead of test")
 
+var framed = flag.Bool("framed", false,
+	"if true, act like framed input program instead of test")
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 	runMode := os.Getenv("PLUGINS_INPUTS_EXECD_MODE")
@@ -200,6 +252,13 @@ func TestMain(m *testing.M) {
 		}
 		os.Exit(0)
 	}
+	if *framed && runMode == "application" {
+		if err := runFramedProgram(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	code := m.Run()
 	os.Exit(code)
 }
@@ -234,3 +293,58 @@ func runCounterProgram() error {
 	}
 	return nil
 }
+
+func runFramedProgram() error {
+	serializer := &influxSerializer.Serializer{}
+	if err := serializer.Init(); err != nil {
+		return err
+	}
+
+	var name string
+	var count int
+	acknowledged := true
+	for {
+		f, err := framing.Read(os.Stdin)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch f.Type {
+		case framing.Config:
+			name = string(f.Payload)
+		case framing.Ack:
+			acknowledged = true
+		case framing.Heartbeat:
+			if err := framing.Write(os.Stdout, framing.Heartbeat, nil); err != nil {
+				return err
+			}
+		case framing.Gather:
+			if !acknowledged {
+				return errors.New("gathering before acknowledgement")
+			}
+			m := metric.New(name,
+				map[string]string{},
+				map[string]interface{}{
+					"count":   count,
+					"message": "multi\nline",
+				},
+				time.Now(),
+			)
+			count++
+
+			b, err := serializer.Serialize(m)
+			if err != nil {
+				return err
+			}
+			if err := framing.Write(os.Stdout, framing.Data, b); err != nil {
+				return err
+			}
+			acknowledged = false
+		case framing.Shutdown:
+			return nil
+		}
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -31,6 +32,8 @@ type haproxy struct {
 	KeepFieldNames bool
 	Username       string
 	Password       string
+	TypedStats     bool     `toml:"typed_stats"`
+	RuntimeInfo    []string `toml:"runtime_info"`
 	tls.ClientConfig
 
 	client *http.Client
@@ -40,6 +43,13 @@ func (*haproxy) SampleConfig() string {
 	return sampleConfig
 }
 
+func (h *haproxy) Init() error {
+	if err := choice.CheckSlice(h.RuntimeInfo, []string{"servers_state", "stick_tables"}); err != nil {
+		return fmt.Errorf("invalid 'runtime_info': %w", err)
+	}
+	return nil
+}
+
 // Reads stats from all configured servers accumulates stats.
 // Returns one of the errors encountered while gather stats (if any).
 func (h *haproxy) Gather(acc telegraf.Accumulator) error {
@@ -95,17 +105,55 @@ func (h *haproxy) gatherServerSocket(addr string, acc telegraf.Accumulator) erro
 		address = getSocketAddr(addr)
 	}
 
+	if h.TypedStats {
+		c, err := socketCommand(network, address, "show stat typed")
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		if err := h.importTypedResult(c, acc, address); err != nil {
+			return fmt.Errorf("unable to parse typed stat result from '%s://%s': %w", network, address, err)
+		}
+	} else {
+		c, err := socketCommand(network, address, "show stat")
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		if err := h.importCsvResult(c, acc, address); err != nil {
+			return err
+		}
+	}
+
+	for _, info := range h.RuntimeInfo {
+		var err error
+		switch info {
+		case "servers_state":
+			err = gatherServersState(network, address, acc)
+		case "stick_tables":
+			err = gatherStickTables(network, address, acc)
+		}
+		if err != nil {
+			acc.AddError(fmt.Errorf("gathering %s from '%s://%s' failed: %w", info, network, address, err))
+		}
+	}
+
+	return nil
+}
+
+// socketCommand sends the command to the Runtime API. The connection is
+// closed by HAProxy after sending the response.
+func socketCommand(network, address, command string) (net.Conn, error) {
 	c, err := net.Dial(network, address)
 	if err != nil {
-		return fmt.Errorf("could not connect to '%s://%s': %w", network, address, err)
+		return nil, fmt.Errorf("could not connect to '%s://%s': %w", network, address, err)
 	}
 
-	_, errw := c.Write([]byte("show stat\n"))
-	if errw != nil {
-		return fmt.Errorf("could not write to socket '%s://%s': %w", network, address, errw)
+	if _, err := c.Write([]byte(command + "\n")); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("could not write to socket '%s://%s': %w", network, address, err)
 	}
-
-	return h.importCsvResult(c, acc, address)
+	return c, nil
 }
 
 func (h *haproxy) gatherServer(addr string, acc telegraf.Accumulator) error {
@@ -11,9 +11,12 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -326,3 +329,176 @@ func HaproxyGetFieldValues() map[string]interface{} {
 
 // Can obtain from official haproxy demo: 'http://demo.haproxy.org/;csv'
 var csvOutputSample = mustReadSampleOutput()
+
+// runtimeServer answers the Runtime API commands with the given responses
+func runtimeServer(t *testing.T, l net.Listener, responses map[string]string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		go func(c net.Conn) {
+			defer c.Close()
+
+			buf := make([]byte, 1024)
+			n, _ := c.Read(buf)
+
+			command := strings.TrimSuffix(string(buf[:n]), "\n")
+			filename, found := responses[command]
+			if !found {
+				c.Write([]byte("Unknown command.\n")) //nolint:errcheck // we return anyway
+				return
+			}
+			data, err := os.ReadFile(filename)
+			require.NoError(t, err)
+			c.Write(data) //nolint:errcheck // we return anyway
+		}(conn)
+	}
+}
+
+func TestInitInvalidRuntimeInfo(t *testing.T) {
+	plugin := &haproxy{RuntimeInfo: []string{"servers_state", "pools"}}
+	require.ErrorContains(t, plugin.Init(), "invalid 'runtime_info'")
+}
+
+func TestHaproxyRuntimeAPI(t *testing.T) {
+	sockname := filepath.Join(t.TempDir(), "haproxy.sock")
+	l, err := net.Listen("unix", sockname)
+	require.NoError(t, err)
+	defer l.Close()
+
+	go runtimeServer(t, l, map[string]string{
+		"show stat typed":    "testdata/show_stat_typed.txt",
+		"show servers state": "testdata/show_servers_state.txt",
+		"show table":         "testdata/show_table.txt",
+		"show table http-in": "testdata/show_table_http-in.txt",
+	})
+
+	plugin := &haproxy{
+		Servers:     []string{sockname},
+		TypedStats:  true,
+		RuntimeInfo: []string{"servers_state", "stick_tables"},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"haproxy",
+			map[string]string{
+				"server": sockname,
+				"proxy":  "http-in",
+				"sv":     "FRONTEND",
+				"type":   "frontend",
+			},
+			map[string]interface{}{
+				"scur":              uint64(3),
+				"slim":              uint64(262124),
+				"status":            "OPEN",
+				"http_response.2xx": uint64(127),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy",
+			map[string]string{
+				"server": sockname,
+				"proxy":  "app",
+				"sv":     "app1",
+				"type":   "server",
+			},
+			map[string]interface{}{
+				"scur":         uint64(0),
+				"status":       "MAINT",
+				"weight":       uint64(0),
+				"check_status": "L4OK",
+				"lastsess":     int64(-1),
+				"addr":         "127.0.0.1:8080",
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy_server_state",
+			map[string]string{
+				"server": sockname,
+				"proxy":  "app",
+				"sv":     "app1",
+			},
+			map[string]interface{}{
+				"be_id":                  int64(3),
+				"id":                     int64(1),
+				"addr":                   "127.0.0.1",
+				"op_state":               int64(0),
+				"op_state_name":          "stopped",
+				"admin_state":            int64(1),
+				"admin_state_name":       "maint",
+				"uweight":                int64(1),
+				"iweight":                int64(1),
+				"time_since_last_change": int64(29),
+				"check_status":           int64(6),
+				"check_result":           int64(2),
+				"check_health":           int64(0),
+				"check_state":            int64(6),
+				"agent_state":            int64(0),
+				"bk_f_forced_id":         int64(0),
+				"f_forced_id":            int64(0),
+				"port":                   int64(8080),
+				"use_ssl":                int64(0),
+				"check_port":             int64(0),
+				"agent_port":             int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy_server_state",
+			map[string]string{
+				"server": sockname,
+				"proxy":  "app",
+				"sv":     "app2",
+			},
+			map[string]interface{}{
+				"be_id":                  int64(3),
+				"id":                     int64(2),
+				"addr":                   "127.0.0.2",
+				"op_state":               int64(2),
+				"op_state_name":          "running",
+				"admin_state":            int64(8),
+				"admin_state_name":       "drain",
+				"uweight":                int64(1),
+				"iweight":                int64(1),
+				"time_since_last_change": int64(1822),
+				"check_status":           int64(6),
+				"check_result":           int64(3),
+				"check_health":           int64(4),
+				"check_state":            int64(6),
+				"agent_state":            int64(0),
+				"bk_f_forced_id":         int64(0),
+				"f_forced_id":            int64(0),
+				"port":                   int64(8080),
+				"use_ssl":                int64(0),
+				"check_port":             int64(0),
+				"agent_port":             int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"haproxy_stick_table",
+			map[string]string{
+				"server":     sockname,
+				"table":      "http-in",
+				"table_type": "ip",
+			},
+			map[string]interface{}{
+				"size":         int64(204800),
+				"used":         int64(2),
+				"conn_rate":    int64(4),
+				"http_req_cnt": int64(15),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
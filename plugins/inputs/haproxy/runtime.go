@@ -0,0 +1,339 @@
+package haproxy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Object types of the typed stat output
+var typedObjectTypes = map[string]string{
+	"F": "frontend",
+	"B": "backend",
+	"S": "server",
+	"L": "listener",
+}
+
+// importTypedResult parses the output of the 'show stat typed' command where
+// each line contains a single field in the form
+// <objtype>.<proxy id>.<object id>.<process>.<name>.<position>:<tags>:<type>:<value>
+func (h *haproxy) importTypedResult(r io.Reader, acc telegraf.Accumulator, host string) error {
+	now := time.Now()
+
+	type object struct {
+		fields map[string]interface{}
+		tags   map[string]string
+	}
+	var order []string
+	objects := make(map[string]*object)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		parts := strings.SplitN(line, ":", 4)
+		if len(parts) != 4 {
+			return fmt.Errorf("invalid line %q", line)
+		}
+		position := strings.Split(parts[0], ".")
+		if len(position) != 6 {
+			return fmt.Errorf("invalid field position %q", parts[0])
+		}
+		objType, found := typedObjectTypes[position[0]]
+		if !found {
+			return fmt.Errorf("received unknown object type %q", position[0])
+		}
+
+		id := strings.Join(position[:4], ".")
+		obj, found := objects[id]
+		if !found {
+			obj = &object{
+				fields: make(map[string]interface{}),
+				tags:   map[string]string{"server": host, "type": objType},
+			}
+			objects[id] = obj
+			order = append(order, id)
+		}
+
+		colName, valueType, v := position[4], parts[2], parts[3]
+		if v == "" {
+			continue
+		}
+		fieldName := colName
+		if !h.KeepFieldNames {
+			if fieldRename, ok := fieldRenames[colName]; ok {
+				fieldName = fieldRename
+			}
+		}
+
+		switch colName {
+		case "pxname", "svname":
+			obj.tags[fieldName] = v
+			continue
+		case "type", "check_desc", "agent_desc":
+			// The type is already known from the object type and the
+			// descriptions are verbose variants of the status fields
+			continue
+		}
+
+		var value interface{}
+		var err error
+		switch valueType {
+		case "s32", "s64":
+			value, err = strconv.ParseInt(v, 10, 64)
+		case "u32", "u64":
+			value, err = strconv.ParseUint(v, 10, 64)
+		case "flt":
+			value, err = strconv.ParseFloat(v, 64)
+		case "str":
+			value = v
+		default:
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		obj.fields[fieldName] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range order {
+		acc.AddFields("haproxy", objects[id].fields, objects[id].tags, now)
+	}
+	return nil
+}
+
+// Operational states of servers in the 'show servers state' output
+var serverOperationalStates = []string{"stopped", "starting", "running", "stopping"}
+
+// Administrative state flags of servers in the 'show servers state' output
+const (
+	adminStateMaint = 0x01 | 0x02 | 0x04 | 0x20 | 0x40
+	adminStateDrain = 0x08 | 0x10
+)
+
+// gatherServersState reports the operational and administrative states of
+// all backend servers
+func gatherServersState(network, address string, acc telegraf.Accumulator) error {
+	c, err := socketCommand(network, address, "show servers state")
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	now := time.Now()
+
+	var headers []string
+	scanner := bufio.NewScanner(c)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "# ") {
+			headers = strings.Fields(line[2:])
+			continue
+		}
+		// Skip the format version preceding the header
+		if headers == nil {
+			continue
+		}
+
+		row := strings.Fields(line)
+		if len(row) != len(headers) {
+			return fmt.Errorf("number of columns does not match number of headers. headers=%d columns=%d", len(headers), len(row))
+		}
+
+		fields := make(map[string]interface{})
+		tags := map[string]string{"server": address}
+		for i, v := range row {
+			switch headers[i] {
+			case "be_name":
+				tags["proxy"] = v
+				continue
+			case "srv_name":
+				tags["sv"] = v
+				continue
+			}
+			if v == "-" {
+				continue
+			}
+
+			name := strings.TrimPrefix(headers[i], "srv_")
+			if vi, err := strconv.ParseInt(v, 10, 64); err == nil {
+				fields[name] = vi
+			} else {
+				fields[name] = v
+			}
+		}
+
+		if state, ok := fields["op_state"].(int64); ok && state >= 0 && state < int64(len(serverOperationalStates)) {
+			fields["op_state_name"] = serverOperationalStates[state]
+		}
+		if state, ok := fields["admin_state"].(int64); ok {
+			switch {
+			case state&adminStateMaint != 0:
+				fields["admin_state_name"] = "maint"
+			case state&adminStateDrain != 0:
+				fields["admin_state_name"] = "drain"
+			default:
+				fields["admin_state_name"] = "ready"
+			}
+		}
+
+		acc.AddFields("haproxy_server_state", fields, tags, now)
+	}
+	return scanner.Err()
+}
+
+// stickTable is the header of a stick-table in the 'show table' output
+type stickTable struct {
+	name      string
+	tableType string
+	size      int64
+	used      int64
+}
+
+// parseStickTableHeader parses lines like
+// # table: http-in, type: ip, size:204800, used:3
+func parseStickTableHeader(line string) (stickTable, error) {
+	var table stickTable
+	for _, item := range strings.Split(strings.TrimPrefix(line, "# "), ",") {
+		key, value, found := strings.Cut(item, ":")
+		if !found {
+			return table, fmt.Errorf("invalid table header %q", line)
+		}
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch strings.TrimSpace(key) {
+		case "table":
+			table.name = value
+		case "type":
+			table.tableType = value
+		case "size":
+			table.size, err = strconv.ParseInt(value, 10, 64)
+		case "used":
+			table.used, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil {
+			return table, fmt.Errorf("invalid table header %q: %w", line, err)
+		}
+	}
+	if table.name == "" {
+		return table, fmt.Errorf("missing table name in header %q", line)
+	}
+	return table, nil
+}
+
+// gatherStickTables reports the usage of all stick-tables together with the
+// sum of their stored counters across all entries
+func gatherStickTables(network, address string, acc telegraf.Accumulator) error {
+	c, err := socketCommand(network, address, "show table")
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var tables []stickTable
+	scanner := bufio.NewScanner(c)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "# table:") {
+			continue
+		}
+		table, err := parseStickTableHeader(line)
+		if err != nil {
+			return err
+		}
+		tables = append(tables, table)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, table := range tables {
+		counters, err := stickTableCounters(network, address, table.name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("table %q: %w", table.name, err))
+			continue
+		}
+
+		tags := map[string]string{
+			"server":     address,
+			"table":      table.name,
+			"table_type": table.tableType,
+		}
+		fields := map[string]interface{}{
+			"size": table.size,
+			"used": table.used,
+		}
+		for name, value := range counters {
+			fields[name] = value
+		}
+		acc.AddFields("haproxy_stick_table", fields, tags, now)
+	}
+	return errors.Join(errs...)
+}
+
+// Entry attributes of stick-tables not being counters
+var stickTableAttributes = map[string]bool{
+	"key":         true,
+	"use":         true,
+	"exp":         true,
+	"shard":       true,
+	"server_id":   true,
+	"server_key":  true,
+	"server_name": true,
+}
+
+// stickTableCounters sums up the counters of all entries of the table. The
+// period of rate counters is removed from the name.
+func stickTableCounters(network, address, name string) (map[string]int64, error) {
+	c, err := socketCommand(network, address, "show table "+name)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	counters := make(map[string]int64)
+	scanner := bufio.NewScanner(c)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Entries start with their address, e.g.
+		// 0x55d5b1a0: key=127.0.0.1 use=0 exp=2893 shard=0 conn_rate(10000)=1 http_req_cnt=5
+		items := strings.Fields(line)
+		for _, item := range items[1:] {
+			key, value, found := strings.Cut(item, "=")
+			if !found || stickTableAttributes[key] {
+				continue
+			}
+			if i := strings.Index(key, "("); i > 0 {
+				key = key[:i]
+			}
+			v, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				continue
+			}
+			counters[key] += v
+		}
+	}
+	return counters, scanner.Err()
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/internal/memlimit"
 	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -57,6 +58,7 @@ type HTTPListenerV2 struct {
 	BasicUsername  string            `toml:"basic_username"`
 	BasicPassword  string            `toml:"basic_password"`
 	HTTPHeaderTags map[string]string `toml:"http_header_tags"`
+	TLSIdentityTag string            `toml:"tls_identity_tag"`
 
 	tlsint.ServerConfig
 	tlsConf *tls.Config
@@ -185,6 +187,12 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 	default:
 	}
 
+	// Shed load while the memory limit of the agent is exceeded
+	if memlimit.Reject() {
+		memlimit.ServiceUnavailable(res)
+		return
+	}
+
 	// Check that the content length is not too large for us to handle.
 	if req.ContentLength > int64(h.MaxBodySize) {
 		if err := tooLarge(res); err != nil {
@@ -231,6 +239,7 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	identity := h.ClientIdentity(req.TLS)
 	for _, m := range metrics {
 		for headerName, measurementName := range h.HTTPHeaderTags {
 			headerValues := req.Header.Get(headerName)
@@ -243,6 +252,10 @@ func (h *HTTPListenerV2) serveWrite(res http.ResponseWriter, req *http.Request)
 			m.AddTag(pathTag, req.URL.Path)
 		}
 
+		if h.TLSIdentityTag != "" && identity != "" {
+			m.AddTag(h.TLSIdentityTag, identity)
+		}
+
 		h.acc.AddMetric(m)
 	}
 
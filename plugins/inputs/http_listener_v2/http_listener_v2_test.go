@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"runtime"
@@ -18,6 +19,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/memlimit"
 	"github.com/influxdata/telegraf/plugins/parsers/form_urlencoded"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -254,6 +256,56 @@ func TestWriteHTTP(t *testing.T) {
 }
 
 // http listener should add request path as configured path_tag
+func TestWriteHTTPMemoryLimitExceeded(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	memlimit.SetPolicy(memlimit.PolicyReject)
+	defer memlimit.SetPolicy("")
+	memlimit.Update(true)
+	defer memlimit.Update(false)
+
+	resp, err := http.Post(createURL(listener, "http", "/write", "db=mydb"), "", bytes.NewBuffer([]byte(testMsg)))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.EqualValues(t, 503, resp.StatusCode)
+	require.Equal(t, "10", resp.Header.Get("Retry-After"))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestWriteHTTPWithTLSIdentityTag(t *testing.T) {
+	listener, err := newTestHTTPListenerV2()
+	require.NoError(t, err)
+	listener.TLSIdentityTag = "client"
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	// Fake the connection state of a client authenticated by certificate
+	req := httptest.NewRequest("POST", "/write", bytes.NewBufferString(testMsg))
+	req.TLS = &tls.ConnectionState{
+		PeerCertificates: []*x509.Certificate{
+			{DNSNames: []string{"client.example.org"}},
+		},
+	}
+	res := httptest.NewRecorder()
+	listener.ServeHTTP(res, req)
+	require.EqualValues(t, 204, res.Code)
+
+	acc.Wait(1)
+	acc.AssertContainsTaggedFields(t, "cpu_load_short",
+		map[string]interface{}{"value": float64(12)},
+		map[string]string{"host": "server01", "client": "client.example.org"},
+	)
+}
+
 func TestWriteHTTPWithPathTag(t *testing.T) {
 	listener, err := newTestHTTPListenerV2()
 	require.NoError(t, err)
@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/common/workerpool"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -50,6 +51,7 @@ type HTTPResponse struct {
 	ResponseStringMatch string
 	ResponseStatusCode  int
 	Interface           string
+	Concurrency         int `toml:"concurrency"`
 	// HTTP Basic Auth Credentials
 	Username config.Secret `toml:"username"`
 	Password config.Secret `toml:"password"`
@@ -263,9 +265,6 @@ func (h *HTTPResponse) httpGather(u string) (map[string]interface{}, map[string]
 	tags["status_code"] = strconv.Itoa(resp.StatusCode)
 	fields["http_response_code"] = resp.StatusCode
 
-	if h.ResponseBodyMaxSize == 0 {
-		h.ResponseBodyMaxSize = config.Size(defaultResponseBodyMaxSize)
-	}
 	bodyBytes, err := io.ReadAll(io.LimitReader(resp.Body, int64(h.ResponseBodyMaxSize)+1))
 	// Check first if the response body size exceeds the limit.
 	if err == nil && int64(len(bodyBytes)) > int64(h.ResponseBodyMaxSize) {
@@ -351,6 +350,9 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 	if h.Method == "" {
 		h.Method = "GET"
 	}
+	if h.ResponseBodyMaxSize == 0 {
+		h.ResponseBodyMaxSize = config.Size(defaultResponseBodyMaxSize)
+	}
 
 	if len(h.URLs) == 0 {
 		if h.Address == "" {
@@ -368,32 +370,28 @@ func (h *HTTPResponse) Gather(acc telegraf.Accumulator) error {
 		h.client = client
 	}
 
-	for _, u := range h.URLs {
+	workerpool.Run(h.Concurrency, h.URLs, func(u string) {
 		addr, err := url.Parse(u)
 		if err != nil {
 			acc.AddError(err)
-			continue
+			return
 		}
 
 		if addr.Scheme != "http" && addr.Scheme != "https" {
 			acc.AddError(errors.New("only http and https are supported"))
-			continue
+			return
 		}
 
-		// Prepare data
-		var fields map[string]interface{}
-		var tags map[string]string
-
 		// Gather data
-		fields, tags, err = h.httpGather(u)
+		fields, tags, err := h.httpGather(u)
 		if err != nil {
 			acc.AddError(err)
-			continue
+			return
 		}
 
 		// Add metrics
 		acc.AddFields("http_response", fields, tags)
-	}
+	})
 
 	return nil
 }
@@ -420,6 +418,6 @@ func (h *HTTPResponse) setRequestAuth(request *http.Request) error {
 
 func init() {
 	inputs.Add("http_response", func() telegraf.Input {
-		return &HTTPResponse{}
+		return &HTTPResponse{Concurrency: 1}
 	})
 }
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -890,6 +891,35 @@ func TestTimeout(t *testing.T) {
 	checkOutput(t, &acc, expectedFields, expectedTags, absentFields, absentTags)
 }
 
+func TestConcurrency(t *testing.T) {
+	// The handler only responds once all requests arrived so the test can
+	// only succeed if the URLs are queried concurrently
+	var wg sync.WaitGroup
+	wg.Add(4)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		wg.Done()
+		wg.Wait()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	h := &HTTPResponse{
+		Log:             testutil.Logger{},
+		URLs:            []string{ts.URL + "/a", ts.URL + "/b", ts.URL + "/c", ts.URL + "/d"},
+		Method:          "GET",
+		ResponseTimeout: config.Duration(5 * time.Second),
+		Concurrency:     4,
+	}
+	var acc testutil.Accumulator
+	require.NoError(t, h.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 4)
+	for _, m := range acc.GetTelegrafMetrics() {
+		result, _ := m.GetTag("result")
+		require.Equal(t, "success", result)
+	}
+}
+
 func TestBadRegex(t *testing.T) {
 	mux := setUpTestMux()
 	ts := httptest.NewServer(mux)
@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/memlimit"
 	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
@@ -47,6 +48,7 @@ type InfluxDBListener struct {
 	TokenUsername      string          `toml:"token_username"`
 	DatabaseTag        string          `toml:"database_tag"`
 	RetentionPolicyTag string          `toml:"retention_policy_tag"`
+	TLSIdentityTag     string          `toml:"tls_identity_tag"`
 	ParserType         string          `toml:"parser_type"`
 
 	timeFunc influx.TimeFunc
@@ -240,6 +242,11 @@ func (h *InfluxDBListener) handleDefault() http.HandlerFunc {
 
 func (h *InfluxDBListener) handleWrite() http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
+		// Shed load while the memory limit of the agent is exceeded
+		if memlimit.Reject() {
+			memlimit.ServiceUnavailable(res)
+			return
+		}
 		if h.ParserType == "upstream" {
 			h.handleWriteUpstreamParser(res, req)
 		} else {
@@ -260,6 +267,7 @@ func (h *InfluxDBListener) handleWriteInternalParser(res http.ResponseWriter, re
 
 	db := req.URL.Query().Get("db")
 	rp := req.URL.Query().Get("rp")
+	identity := h.ClientIdentity(req.TLS)
 
 	body := req.Body
 	body = http.MaxBytesReader(res, body, int64(h.MaxBodySize))
@@ -328,6 +336,10 @@ func (h *InfluxDBListener) handleWriteInternalParser(res http.ResponseWriter, re
 			m.AddTag(h.RetentionPolicyTag, rp)
 		}
 
+		if h.TLSIdentityTag != "" && identity != "" {
+			m.AddTag(h.TLSIdentityTag, identity)
+		}
+
 		h.acc.AddMetric(m)
 	}
 	if !errors.Is(err, influx.EOF) {
@@ -369,6 +381,7 @@ func (h *InfluxDBListener) handleWriteUpstreamParser(res http.ResponseWriter, re
 
 	db := req.URL.Query().Get("db")
 	rp := req.URL.Query().Get("rp")
+	identity := h.ClientIdentity(req.TLS)
 
 	body := req.Body
 	body = http.MaxBytesReader(res, body, int64(h.MaxBodySize))
@@ -444,6 +457,10 @@ func (h *InfluxDBListener) handleWriteUpstreamParser(res http.ResponseWriter, re
 			m.AddTag(h.RetentionPolicyTag, rp)
 		}
 
+		if h.TLSIdentityTag != "" && identity != "" {
+			m.AddTag(h.TLSIdentityTag, identity)
+		}
+
 		h.acc.AddMetric(m)
 	}
 	if !errors.Is(err, influx_upstream.ErrEOF) {
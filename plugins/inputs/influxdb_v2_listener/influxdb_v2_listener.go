@@ -2,6 +2,7 @@
 package influxdb_v2_listener
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
 	"crypto/tls"
@@ -12,11 +13,13 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/memlimit"
 	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
@@ -42,8 +45,10 @@ var ErrEOF = errors.New("EOF")
 type BadRequestCode string
 
 const (
-	InternalError BadRequestCode = "internal error"
-	Invalid       BadRequestCode = "invalid"
+	InternalError   BadRequestCode = "internal error"
+	Invalid         BadRequestCode = "invalid"
+	Forbidden       BadRequestCode = "forbidden"
+	TooManyRequests BadRequestCode = "too many requests"
 )
 
 type InfluxDBV2Listener struct {
@@ -55,8 +60,11 @@ type InfluxDBV2Listener struct {
 	WriteTimeout config.Duration `toml:"write_timeout"`
 	MaxBodySize  config.Size     `toml:"max_body_size"`
 	Token        string          `toml:"token"`
+	Tenants      []*Tenant       `toml:"tenant"`
 	BucketTag    string          `toml:"bucket_tag"`
+	OrgTag       string          `toml:"org_tag"`
 	ParserType   string          `toml:"parser_type"`
+	PartialWrite bool            `toml:"partial_write"`
 
 	timeFunc influx.TimeFunc
 
@@ -72,6 +80,7 @@ type InfluxDBV2Listener struct {
 	requestsRecv    selfstat.Stat
 	notFoundsServed selfstat.Stat
 	authFailures    selfstat.Stat
+	rateLimited     selfstat.Stat
 
 	startTime time.Time
 
@@ -99,12 +108,25 @@ func (h *InfluxDBV2Listener) routes() {
 		},
 	)
 
+	if len(h.Tenants) > 0 {
+		authHandler = h.tenantAuthHandler
+	}
+
 	h.mux.Handle("/api/v2/write", authHandler(h.handleWrite()))
 	h.mux.Handle("/api/v2/ready", h.handleReady())
 	h.mux.Handle("/", authHandler(h.handleDefault()))
 }
 
 func (h *InfluxDBV2Listener) Init() error {
+	if h.Token != "" && len(h.Tenants) > 0 {
+		return errors.New("'token' cannot be used together with tenants")
+	}
+	for i, t := range h.Tenants {
+		if err := t.init(); err != nil {
+			return fmt.Errorf("tenant %d: %w", i+1, err)
+		}
+	}
+
 	tags := map[string]string{
 		"address": h.ServiceAddress,
 	}
@@ -115,6 +137,7 @@ func (h *InfluxDBV2Listener) Init() error {
 	h.requestsRecv = selfstat.Register("influxdb_v2_listener", "requests_received", tags)
 	h.notFoundsServed = selfstat.Register("influxdb_v2_listener", "not_founds_served", tags)
 	h.authFailures = selfstat.Register("influxdb_v2_listener", "auth_failures", tags)
+	h.rateLimited = selfstat.Register("influxdb_v2_listener", "rate_limited", tags)
 	h.routes()
 
 	if h.MaxBodySize == 0 {
@@ -218,6 +241,11 @@ func (h *InfluxDBV2Listener) handleDefault() http.HandlerFunc {
 func (h *InfluxDBV2Listener) handleWrite() http.HandlerFunc {
 	return func(res http.ResponseWriter, req *http.Request) {
 		defer h.writesServed.Incr(1)
+		// Shed load while the memory limit of the agent is exceeded
+		if memlimit.Reject() {
+			memlimit.ServiceUnavailable(res)
+			return
+		}
 		// Check that the content length is not too large for us to handle.
 		if req.ContentLength > int64(h.MaxBodySize) {
 			if err := tooLarge(res, int64(h.MaxBodySize)); err != nil {
@@ -227,6 +255,21 @@ func (h *InfluxDBV2Listener) handleWrite() http.HandlerFunc {
 		}
 
 		bucket := req.URL.Query().Get("bucket")
+		org := req.URL.Query().Get("org")
+
+		// Check the permissions of the tenant authenticated by the token
+		tenant := tenantFromRequest(req)
+		if tenant != nil {
+			if err := tenant.authorized(org, bucket); err != nil {
+				if err := forbidden(res, err.Error()); err != nil {
+					h.Log.Debugf("error in forbidden: %v", err)
+				}
+				return
+			}
+			if tenant.Org != "" {
+				org = tenant.Org
+			}
+		}
 
 		body := req.Body
 		body = http.MaxBytesReader(res, body, int64(h.MaxBodySize))
@@ -258,8 +301,14 @@ func (h *InfluxDBV2Listener) handleWrite() http.HandlerFunc {
 		precisionStr := req.URL.Query().Get("precision")
 
 		var metrics []telegraf.Metric
+		var lineErrs []error
 		var err error
-		if h.ParserType == "upstream" {
+		if h.PartialWrite {
+			metrics, lineErrs, err = h.parseLines(bytes, precisionStr)
+			if err == nil && len(metrics) == 0 && len(lineErrs) > 0 {
+				err = lineErrs[0]
+			}
+		} else if h.ParserType == "upstream" {
 			parser := influx_upstream.Parser{}
 			err = parser.Init()
 			if !errors.Is(err, ErrEOF) && err != nil {
@@ -302,20 +351,107 @@ func (h *InfluxDBV2Listener) handleWrite() http.HandlerFunc {
 			return
 		}
 
+		// Enforce the rate limit of the tenant
+		if tenant != nil {
+			delay, err := tenant.reserve(len(metrics))
+			if err != nil || delay > 0 {
+				h.rateLimited.Incr(1)
+				msg := "rate limit exceeded"
+				if err != nil {
+					msg = err.Error()
+				}
+				if err := tooManyRequests(res, delay, msg); err != nil {
+					h.Log.Debugf("error in too-many-requests: %v", err)
+				}
+				return
+			}
+		}
+
 		for _, m := range metrics {
 			// Handle bucket_tag override
 			if h.BucketTag != "" && bucket != "" {
 				m.AddTag(h.BucketTag, bucket)
 			}
+			if h.OrgTag != "" && org != "" {
+				m.AddTag(h.OrgTag, org)
+			}
 
 			h.acc.AddMetric(m)
 		}
 
+		// Report the lines dropped due to parsing errors like InfluxDB does
+		// for partial writes
+		if len(lineErrs) > 0 {
+			msg := fmt.Sprintf("partial write error (%d written, %d dropped): %v", len(metrics), len(lineErrs), lineErrs[0])
+			h.Log.Debugf("Error parsing the request body: %s", msg)
+			if err := badRequest(res, Invalid, msg); err != nil {
+				h.Log.Debugf("error in bad-request: %v", err)
+			}
+			return
+		}
+
 		// http request success
 		res.WriteHeader(http.StatusNoContent)
 	}
 }
 
+// parseLines parses the body line by line returning the metrics of the valid
+// lines and the errors of the invalid ones
+func (h *InfluxDBV2Listener) parseLines(body []byte, precisionStr string) ([]telegraf.Metric, []error, error) {
+	var next func() (telegraf.Metric, error)
+	if h.ParserType == "upstream" {
+		parser := influx_upstream.NewStreamParser(bytes.NewReader(body))
+		parser.SetTimeFunc(influx_upstream.TimeFunc(h.timeFunc))
+		if precisionStr != "" {
+			if err := parser.SetTimePrecision(getPrecisionMultiplier(precisionStr)); err != nil {
+				return nil, nil, err
+			}
+		}
+		next = func() (telegraf.Metric, error) {
+			m, err := parser.Next()
+			if errors.Is(err, influx_upstream.ErrEOF) {
+				return nil, io.EOF
+			}
+			return m, err
+		}
+	} else {
+		parser := influx.NewStreamParser(bytes.NewReader(body))
+		parser.SetTimeFunc(h.timeFunc)
+		if precisionStr != "" {
+			parser.SetTimePrecision(getPrecisionMultiplier(precisionStr))
+		}
+		next = func() (telegraf.Metric, error) {
+			m, err := parser.Next()
+			if errors.Is(err, influx.EOF) {
+				return nil, io.EOF
+			}
+			return m, err
+		}
+	}
+
+	var metrics []telegraf.Metric
+	var lineErrs []error
+	for {
+		m, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *influx.ParseError
+			var upstreamErr *influx_upstream.ParseError
+			if !errors.As(err, &parseErr) && !errors.As(err, &upstreamErr) {
+				return nil, nil, err
+			}
+			lineErrs = append(lineErrs, err)
+			continue
+		}
+		if m != nil {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, lineErrs, nil
+}
+
 func tooLarge(res http.ResponseWriter, maxLength int64) error {
 	res.Header().Set("Content-Type", "application/json")
 	res.Header().Set("X-Influxdb-Error", "http: request body too large")
@@ -345,6 +481,37 @@ func badRequest(res http.ResponseWriter, code BadRequestCode, errString string)
 	return err
 }
 
+func forbidden(res http.ResponseWriter, errString string) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Influxdb-Error", errString)
+	res.WriteHeader(http.StatusForbidden)
+	b, _ := json.Marshal(map[string]string{
+		"code":    fmt.Sprint(Forbidden),
+		"message": errString,
+	})
+	_, err := res.Write(b)
+	return err
+}
+
+func tooManyRequests(res http.ResponseWriter, retryAfter time.Duration, errString string) error {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Influxdb-Error", errString)
+	if retryAfter > 0 {
+		seconds := int64(retryAfter / time.Second)
+		if retryAfter%time.Second != 0 {
+			seconds++
+		}
+		res.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+	}
+	res.WriteHeader(http.StatusTooManyRequests)
+	b, _ := json.Marshal(map[string]string{
+		"code":    fmt.Sprint(TooManyRequests),
+		"message": errString,
+	})
+	_, err := res.Write(b)
+	return err
+}
+
 func getPrecisionMultiplier(precision string) time.Duration {
 	// Influxdb defaults silently to nanoseconds if precision isn't
 	// one of the following:
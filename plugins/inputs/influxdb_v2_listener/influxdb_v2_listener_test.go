@@ -259,6 +259,191 @@ func TestAllOrNothing(t *testing.T) {
 	}
 }
 
+func TestPartialWrite(t *testing.T) {
+	for _, tc := range parserTestCases {
+		t.Run(fmt.Sprintf("parser %s", tc.parser), func(t *testing.T) {
+			listener := newTestListener()
+			listener.ParserType = tc.parser
+			listener.PartialWrite = true
+
+			acc := &testutil.Accumulator{}
+			require.NoError(t, listener.Init())
+			require.NoError(t, listener.Start(acc))
+			defer listener.Stop()
+
+			resp, err := http.Post(createURL(listener, "http", "/api/v2/write", "bucket=mybucket"), "", bytes.NewBuffer([]byte(testPartial)))
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.EqualValues(t, 400, resp.StatusCode)
+			require.Contains(t, string(body), "partial write error (2 written, 1 dropped)")
+
+			acc.Wait(2)
+			acc.AssertContainsTaggedFields(t, "cpu",
+				map[string]interface{}{"value1": float64(1)},
+				map[string]string{"host": "a"},
+			)
+			acc.AssertContainsTaggedFields(t, "cpu",
+				map[string]interface{}{"value1": float64(1)},
+				map[string]string{"host": "c"},
+			)
+
+			// Writes without any valid line are rejected completely
+			resp, err = http.Post(createURL(listener, "http", "/api/v2/write", "bucket=mybucket"), "", bytes.NewBuffer([]byte(badMsg)))
+			require.NoError(t, err)
+			body, err = io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.EqualValues(t, 400, resp.StatusCode)
+			require.NotContains(t, string(body), "partial write")
+			require.Len(t, acc.GetTelegrafMetrics(), 2)
+		})
+	}
+}
+
+func TestTenantsWithToken(t *testing.T) {
+	listener := newTestAuthListener()
+	listener.Tenants = []*Tenant{{Token: config.NewSecret([]byte("tenant-token"))}}
+	require.ErrorContains(t, listener.Init(), "'token' cannot be used together with tenants")
+}
+
+func TestWriteTenants(t *testing.T) {
+	listener := newTestListener()
+	listener.BucketTag = "bucket"
+	listener.OrgTag = "org"
+	listener.Tenants = []*Tenant{
+		{
+			Token:   config.NewSecret([]byte("token-a")),
+			Org:     "org_a",
+			Buckets: []string{"telegraf_*"},
+		},
+		{
+			Token: config.NewSecret([]byte("token-b")),
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	tests := []struct {
+		name     string
+		token    string
+		query    string
+		status   int
+		expected map[string]string
+	}{
+		{
+			name:   "no token",
+			query:  "bucket=telegraf_a",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:   "unknown token",
+			token:  "token-c",
+			query:  "bucket=telegraf_a",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:     "tenant organization",
+			token:    "token-a",
+			query:    "bucket=telegraf_a",
+			status:   http.StatusNoContent,
+			expected: map[string]string{"host": "server01", "bucket": "telegraf_a", "org": "org_a"},
+		},
+		{
+			name:   "forbidden organization",
+			token:  "token-a",
+			query:  "bucket=telegraf_a&org=org_b",
+			status: http.StatusForbidden,
+		},
+		{
+			name:   "forbidden bucket",
+			token:  "token-a",
+			query:  "bucket=other&org=org_a",
+			status: http.StatusForbidden,
+		},
+		{
+			name:     "request organization",
+			token:    "token-b",
+			query:    "bucket=other&org=org_b",
+			status:   http.StatusNoContent,
+			expected: map[string]string{"host": "server01", "bucket": "other", "org": "org_b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc.ClearMetrics()
+
+			req, err := http.NewRequest("POST", createURL(listener, "http", "/api/v2/write", tt.query), bytes.NewBuffer([]byte(testMsg)))
+			require.NoError(t, err)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Token "+tt.token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.EqualValues(t, tt.status, resp.StatusCode)
+
+			if tt.expected == nil {
+				require.Empty(t, acc.GetTelegrafMetrics())
+				return
+			}
+			acc.Wait(1)
+			acc.AssertContainsTaggedFields(t, "cpu_load_short",
+				map[string]interface{}{"value": float64(12)},
+				tt.expected,
+			)
+		})
+	}
+}
+
+func TestWriteTenantRateLimit(t *testing.T) {
+	listener := newTestListener()
+	listener.Tenants = []*Tenant{
+		{
+			Token:     config.NewSecret([]byte(token)),
+			RateLimit: 0.1,
+			RateBurst: 5,
+		},
+	}
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, listener.Init())
+	require.NoError(t, listener.Start(acc))
+	defer listener.Stop()
+
+	write := func(msg string) *http.Response {
+		req, err := http.NewRequest("POST", createURL(listener, "http", "/api/v2/write", "bucket=mybucket"), bytes.NewBuffer([]byte(msg)))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Token "+token)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		return resp
+	}
+
+	// The first write uses up the burst
+	resp := write(testMsgs)
+	require.EqualValues(t, http.StatusNoContent, resp.StatusCode)
+	acc.Wait(5)
+
+	// Further writes are rejected until the limit allows them
+	resp = write(testMsg)
+	require.EqualValues(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.NotEmpty(t, resp.Header.Get("Retry-After"))
+
+	// Writes exceeding the burst are never accepted
+	resp = write(testMsgs + testMsg)
+	require.EqualValues(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Retry-After"))
+
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+}
+
 func TestWriteMaxLineSizeIncrease(t *testing.T) {
 	// The term 'master_repl' used here is archaic language from redis
 	hugeMetric, err := os.ReadFile("./testdata/huge_metric")
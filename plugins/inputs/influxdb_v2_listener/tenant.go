@@ -0,0 +1,132 @@
+package influxdb_v2_listener
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Tenant is a client allowed to write to the listener identified by its token
+type Tenant struct {
+	Token     config.Secret `toml:"token"`
+	Org       string        `toml:"org"`
+	Buckets   []string      `toml:"buckets"`
+	RateLimit float64       `toml:"rate_limit"`
+	RateBurst int           `toml:"rate_burst"`
+
+	buckets filter.Filter
+	limiter *rate.Limiter
+}
+
+type tenantKey struct{}
+
+func (t *Tenant) init() error {
+	if t.Token.Empty() {
+		return errors.New("empty token")
+	}
+	if t.RateLimit < 0 {
+		return errors.New("'rate_limit' must not be negative")
+	}
+
+	var err error
+	if t.buckets, err = filter.Compile(t.Buckets); err != nil {
+		return fmt.Errorf("creating bucket filter failed: %w", err)
+	}
+
+	if t.RateLimit > 0 {
+		burst := t.RateBurst
+		if burst <= 0 {
+			burst = int(math.Ceil(t.RateLimit))
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(t.RateLimit), burst)
+	}
+	return nil
+}
+
+// matches checks if the token of the tenant is equal to the given one using a
+// constant-time comparison. The token is retrieved on each call to allow
+// changing tokens in secret-stores.
+func (t *Tenant) matches(token []byte) (bool, error) {
+	secret, err := t.Token.Get()
+	if err != nil {
+		return false, err
+	}
+	defer config.ReleaseSecret(secret)
+
+	return subtle.ConstantTimeCompare(secret, token) == 1, nil
+}
+
+// authorized checks if the tenant is allowed to write to the given
+// organization and bucket
+func (t *Tenant) authorized(org, bucket string) error {
+	if t.Org != "" && org != "" && org != t.Org {
+		return fmt.Errorf("insufficient permissions for write to organization %q", org)
+	}
+	if t.buckets != nil && !t.buckets.Match(bucket) {
+		return fmt.Errorf("insufficient permissions for write to bucket %q", bucket)
+	}
+	return nil
+}
+
+// reserve takes the given number of metrics from the tenant's rate limit and
+// returns the time to wait before retrying if the limit is exceeded
+func (t *Tenant) reserve(n int) (time.Duration, error) {
+	if t.limiter == nil {
+		return 0, nil
+	}
+
+	now := time.Now()
+	r := t.limiter.ReserveN(now, n)
+	if !r.OK() {
+		return 0, fmt.Errorf("write of %d metrics exceeds the rate burst of %d", n, t.limiter.Burst())
+	}
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return delay, nil
+	}
+	return 0, nil
+}
+
+// tenantAuthHandler authenticates the requests using the tokens of the tenants
+// and passes the tenant to the next handler in the request's context
+func (h *InfluxDBV2Listener) tenantAuthHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Token ")
+		if !found || token == "" {
+			h.authFailures.Incr(1)
+			http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		for _, t := range h.Tenants {
+			matched, err := t.matches([]byte(token))
+			if err != nil {
+				h.Log.Errorf("Getting token failed: %v", err)
+				continue
+			}
+			if matched {
+				ctx := context.WithValue(req.Context(), tenantKey{}, t)
+				next.ServeHTTP(res, req.WithContext(ctx))
+				return
+			}
+		}
+
+		h.authFailures.Incr(1)
+		http.Error(res, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+func tenantFromRequest(req *http.Request) *Tenant {
+	t, _ := req.Context().Value(tenantKey{}).(*Tenant)
+	return t
+}
@@ -3,7 +3,7 @@ package jolokia2_agent
 
 import (
 	_ "embed"
-
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -28,9 +28,11 @@ type JolokiaAgent struct {
 	Password        string          `toml:"password"`
 	Origin          string          `toml:"origin"`
 	ResponseTimeout config.Duration `toml:"response_timeout"`
+	MaxBulkRequests int             `toml:"max_bulk_requests"`
 
 	tls.ClientConfig
 
+	Profiles []string              `toml:"profiles"`
 	Metrics  []common.MetricConfig `toml:"metric"`
 	gatherer *common.Gatherer
 	clients  []*common.Client
@@ -40,6 +42,20 @@ func (*JolokiaAgent) SampleConfig() string {
 	return sampleConfig
 }
 
+func (ja *JolokiaAgent) Init() error {
+	if ja.MaxBulkRequests < 0 {
+		return errors.New("'max_bulk_requests' must not be negative")
+	}
+
+	metrics, err := common.ProfileMetrics(ja.Profiles)
+	if err != nil {
+		return err
+	}
+	ja.Metrics = append(ja.Metrics, metrics...)
+
+	return nil
+}
+
 func (ja *JolokiaAgent) Gather(acc telegraf.Accumulator) error {
 	if ja.gatherer == nil {
 		ja.gatherer = common.NewGatherer(ja.createMetrics())
@@ -92,6 +108,7 @@ func (ja *JolokiaAgent) createClient(url string) (*common.Client, error) {
 		Password:        ja.Password,
 		Origin:          ja.Origin,
 		ResponseTimeout: time.Duration(ja.ResponseTimeout),
+		MaxBulkRequests: ja.MaxBulkRequests,
 		ClientConfig:    ja.ClientConfig,
 	})
 }
@@ -651,6 +651,103 @@ func TestJolokia2_ClientAuthRequest(t *testing.T) {
 	require.EqualValuesf(t, "hello:foo=bar", request, "Expected to query mbean %s, but was %s", "hello:foo=bar", request)
 }
 
+func TestMaxBulkRequests(t *testing.T) {
+	var batches [][]map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var requests []map[string]interface{}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &requests))
+		batches = append(batches, requests)
+
+		responses := make([]map[string]interface{}, 0, len(requests))
+		for _, request := range requests {
+			responses = append(responses, map[string]interface{}{
+				"request": request,
+				"value":   42,
+				"status":  200,
+			})
+		}
+		w.WriteHeader(http.StatusOK)
+		require.NoError(t, json.NewEncoder(w).Encode(responses))
+	}))
+	defer server.Close()
+
+	plugin := SetupPlugin(t, fmt.Sprintf(`
+		[jolokia2_agent]
+			urls = ["%s/jolokia"]
+			max_bulk_requests = 2
+		[[jolokia2_agent.metric]]
+			name  = "first"
+			mbean = "first:foo=bar"
+		[[jolokia2_agent.metric]]
+			name  = "second"
+			mbean = "second:foo=bar"
+		[[jolokia2_agent.metric]]
+			name  = "third"
+			mbean = "third:foo=bar"
+	`, server.URL))
+	require.NoError(t, plugin.(*jolokia2_agent.JolokiaAgent).Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0], 2)
+	require.Len(t, batches[1], 1)
+
+	for _, name := range []string{"first", "second", "third"} {
+		acc.AssertContainsTaggedFields(t, name, map[string]interface{}{
+			"value": 42.0,
+		}, map[string]string{
+			"jolokia_agent_url": server.URL + "/jolokia",
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(body, &requests))
+
+		w.WriteHeader(http.StatusOK)
+		_, err = fmt.Fprint(w, "[]")
+		require.NoError(t, err)
+	}))
+	defer server.Close()
+
+	plugin := SetupPlugin(t, fmt.Sprintf(`
+		[jolokia2_agent]
+			urls = ["%s/jolokia"]
+			profiles = ["tomcat"]
+		[[jolokia2_agent.metric]]
+			name  = "hello"
+			mbean = "hello:foo=bar"
+	`, server.URL))
+	require.NoError(t, plugin.(*jolokia2_agent.JolokiaAgent).Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	mbeans := make([]string, 0, len(requests))
+	for _, request := range requests {
+		mbeans = append(mbeans, request["mbean"].(string))
+	}
+	require.Contains(t, mbeans, "hello:foo=bar")
+	require.Contains(t, mbeans, "Catalina:name=*,type=ThreadPool")
+}
+
+func TestUnknownProfile(t *testing.T) {
+	plugin := &jolokia2_agent.JolokiaAgent{
+		URLs:     []string{"http://localhost:8080/jolokia"},
+		Profiles: []string{"unknown"},
+	}
+	require.ErrorContains(t, plugin.Init(), `unknown profile "unknown"`)
+}
+
 func TestFillFields(t *testing.T) {
 	complexPoint := map[string]interface{}{"Value": []interface{}{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
 		0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
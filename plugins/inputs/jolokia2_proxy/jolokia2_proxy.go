@@ -3,6 +3,7 @@ package jolokia2_proxy
 
 import (
 	_ "embed"
+	"errors"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -29,8 +30,10 @@ type JolokiaProxy struct {
 	Password        string          `toml:"password"`
 	Origin          string          `toml:"origin"`
 	ResponseTimeout config.Duration `toml:"response_timeout"`
+	MaxBulkRequests int             `toml:"max_bulk_requests"`
 	tls.ClientConfig
 
+	Profiles []string              `toml:"profiles"`
 	Metrics  []common.MetricConfig `toml:"metric"`
 	client   *common.Client
 	gatherer *common.Gatherer
@@ -46,6 +49,20 @@ func (*JolokiaProxy) SampleConfig() string {
 	return sampleConfig
 }
 
+func (jp *JolokiaProxy) Init() error {
+	if jp.MaxBulkRequests < 0 {
+		return errors.New("'max_bulk_requests' must not be negative")
+	}
+
+	metrics, err := common.ProfileMetrics(jp.Profiles)
+	if err != nil {
+		return err
+	}
+	jp.Metrics = append(jp.Metrics, metrics...)
+
+	return nil
+}
+
 func (jp *JolokiaProxy) Gather(acc telegraf.Accumulator) error {
 	if jp.gatherer == nil {
 		jp.gatherer = common.NewGatherer(jp.createMetrics())
@@ -91,6 +108,7 @@ func (jp *JolokiaProxy) createClient() (*common.Client, error) {
 		Username:        jp.Username,
 		Password:        jp.Password,
 		ResponseTimeout: time.Duration(jp.ResponseTimeout),
+		MaxBulkRequests: jp.MaxBulkRequests,
 		ClientConfig:    jp.ClientConfig,
 		ProxyConfig:     proxyConfig,
 	})
@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/delivery"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -48,6 +49,7 @@ type KafkaConsumer struct {
 	ConnectionStrategy     string          `toml:"connection_strategy"`
 
 	kafka.ReadConfig
+	delivery.DeliveryConfig
 
 	kafka.Logger
 
@@ -105,6 +107,9 @@ func (k *KafkaConsumer) Init() error {
 	if k.ConsumerGroup == "" {
 		k.ConsumerGroup = defaultConsumerGroup
 	}
+	if err := k.DeliveryConfig.Init(); err != nil {
+		return err
+	}
 
 	cfg := sarama.NewConfig()
 
@@ -308,9 +313,10 @@ func (k *KafkaConsumer) Start(acc telegraf.Accumulator) error {
 		k.startErrorAdder(acc)
 
 		for ctx.Err() == nil {
-			handler := NewConsumerGroupHandler(acc, k.MaxUndeliveredMessages, k.parser, k.Log)
+			handler := NewConsumerGroupHandler(k.DeliveryConfig.WrapAccumulator(acc), k.MaxUndeliveredMessages, k.parser, k.Log)
 			handler.MaxMessageLen = k.MaxMessageLen
 			handler.TopicTag = k.TopicTag
+			handler.Delivery = k.DeliveryConfig
 			// We need to copy allWantedTopics; the Consume() is
 			// long-running and we can easily deadlock if our
 			// topic-update-checker fires.
@@ -373,6 +379,7 @@ func NewConsumerGroupHandler(acc telegraf.Accumulator, maxUndelivered int, parse
 type ConsumerGroupHandler struct {
 	MaxMessageLen int
 	TopicTag      string
+	Delivery      delivery.DeliveryConfig
 
 	acc    telegraf.TrackingAccumulator
 	sem    semaphore
@@ -424,7 +431,7 @@ func (h *ConsumerGroupHandler) onDelivery(track telegraf.DeliveryInfo) {
 		return
 	}
 
-	if track.Delivered() {
+	if h.Delivery.Delivered(track) {
 		msg.session.MarkMessage(msg.message, "")
 	}
 
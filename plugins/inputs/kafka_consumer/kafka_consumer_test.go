@@ -15,6 +15,8 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/delivery"
 	"github.com/influxdata/telegraf/plugins/common/kafka"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -234,7 +236,8 @@ func TestStartStop(t *testing.T) {
 }
 
 type FakeConsumerGroupSession struct {
-	ctx context.Context
+	ctx    context.Context
+	marked []*sarama.ConsumerMessage
 }
 
 func (s *FakeConsumerGroupSession) Claims() map[string][]int32 {
@@ -257,7 +260,8 @@ func (s *FakeConsumerGroupSession) ResetOffset(_ string, _ int32, _ int64, _ str
 	panic("not implemented")
 }
 
-func (s *FakeConsumerGroupSession) MarkMessage(_ *sarama.ConsumerMessage, _ string) {
+func (s *FakeConsumerGroupSession) MarkMessage(msg *sarama.ConsumerMessage, _ string) {
+	s.marked = append(s.marked, msg)
 }
 
 func (s *FakeConsumerGroupSession) Context() context.Context {
@@ -375,6 +379,37 @@ func TestConsumerGroupHandler_ConsumeClaim(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
 }
 
+func TestConsumerGroupHandler_DeliveryMode(t *testing.T) {
+	tests := []struct {
+		mode     string
+		expected int
+	}{
+		{mode: "all", expected: 0},
+		{mode: "any", expected: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var acc testutil.Accumulator
+			cg := NewConsumerGroupHandler(&acc, 1, nil, testutil.Logger{})
+			cg.Delivery = delivery.DeliveryConfig{DeliveryMode: tt.mode}
+			require.NoError(t, cg.Delivery.Init())
+			require.NoError(t, cg.Reserve(context.Background()))
+
+			// Written by one of two outputs
+			var info telegraf.DeliveryInfo
+			m, id := metric.WithTracking(testutil.TestMetric(42), func(di telegraf.DeliveryInfo) { info = di })
+			m.Copy().Accept()
+			m.Reject()
+
+			session := &FakeConsumerGroupSession{ctx: context.Background()}
+			cg.undelivered[id] = Message{message: &sarama.ConsumerMessage{Value: []byte("42")}, session: session}
+			cg.onDelivery(info)
+			require.Len(t, session.marked, tt.expected)
+			require.Empty(t, cg.undelivered)
+		})
+	}
+}
+
 func TestConsumerGroupHandler_Handle(t *testing.T) {
 	tests := []struct {
 		name                string
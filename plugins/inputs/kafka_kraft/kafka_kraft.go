@@ -0,0 +1,302 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package kafka_kraft
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Topic and partition of the cluster metadata log replicated by the quorum
+const (
+	metadataTopic     = "__cluster_metadata"
+	metadataPartition = 0
+)
+
+type KafkaKRaft struct {
+	Brokers      []string        `toml:"brokers"`
+	ClientID     string          `toml:"client_id"`
+	Timeout      config.Duration `toml:"timeout"`
+	SASLUsername config.Secret   `toml:"sasl_username"`
+	SASLPassword config.Secret   `toml:"sasl_password"`
+	tlsint.ClientConfig
+
+	tlsCfg        *tls.Config
+	correlationID int32
+}
+
+func (*KafkaKRaft) SampleConfig() string {
+	return sampleConfig
+}
+
+func (k *KafkaKRaft) Init() error {
+	if len(k.Brokers) == 0 {
+		return errors.New("no brokers specified")
+	}
+	for _, broker := range k.Brokers {
+		if _, _, err := net.SplitHostPort(broker); err != nil {
+			return fmt.Errorf("invalid broker address %q: %w", broker, err)
+		}
+	}
+	if k.ClientID == "" {
+		k.ClientID = "telegraf"
+	}
+
+	var err error
+	k.tlsCfg, err = k.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("setting up TLS configuration failed: %w", err)
+	}
+
+	return nil
+}
+
+// Gather queries the quorum state from the first broker responding as each
+// broker forwards the request to the active controller
+func (k *KafkaKRaft) Gather(acc telegraf.Accumulator) error {
+	var errs []error
+	for _, broker := range k.Brokers {
+		partitions, err := k.describeQuorum(broker)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", broker, err))
+			continue
+		}
+
+		for _, p := range partitions {
+			k.addPartition(acc, broker, p)
+		}
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+func (k *KafkaKRaft) addPartition(acc telegraf.Accumulator, broker string, p partitionQuorum) {
+	now := time.Now()
+	tags := map[string]string{
+		"broker":    broker,
+		"topic":     p.topic,
+		"partition": strconv.Itoa(int(p.partition)),
+	}
+	if p.errorCode != 0 {
+		acc.AddError(fmt.Errorf("%s: describing quorum of %s-%d failed with error code %d", broker, p.topic, p.partition, p.errorCode))
+		return
+	}
+
+	var maxLag int64
+	var caughtUp int
+	for _, r := range p.voters {
+		lag := replicaLag(p.highWatermark, r)
+		if lag > maxLag {
+			maxLag = lag
+		}
+		if lag == 0 {
+			caughtUp++
+		}
+	}
+
+	acc.AddFields("kafka_kraft_quorum", map[string]interface{}{
+		"leader_id":          p.leaderID,
+		"leader_epoch":       p.leaderEpoch,
+		"high_watermark":     p.highWatermark,
+		"voters":             len(p.voters),
+		"voters_caught_up":   caughtUp,
+		"observers":          len(p.observers),
+		"max_voter_lag":      maxLag,
+		"has_leader":         p.leaderID >= 0,
+		"majority_caught_up": caughtUp > len(p.voters)/2,
+	}, tags, now)
+
+	addReplicas := func(replicas []replicaState, role string) {
+		for _, r := range replicas {
+			replicaRole := role
+			if role == "voter" && r.replicaID == p.leaderID {
+				replicaRole = "leader"
+			}
+			rtags := map[string]string{
+				"broker":     broker,
+				"topic":      p.topic,
+				"partition":  tags["partition"],
+				"replica_id": strconv.Itoa(int(r.replicaID)),
+				"role":       replicaRole,
+			}
+			fields := map[string]interface{}{
+				"log_end_offset": r.logEndOffset,
+				"lag":            replicaLag(p.highWatermark, r),
+			}
+			if r.lastFetchTimestamp >= 0 {
+				fields["last_fetch_timestamp"] = r.lastFetchTimestamp
+			}
+			if r.lastCaughtUpTimestamp >= 0 {
+				fields["last_caught_up_timestamp"] = r.lastCaughtUpTimestamp
+			}
+			acc.AddFields("kafka_kraft_replica", fields, rtags, now)
+		}
+	}
+	addReplicas(p.voters, "voter")
+	addReplicas(p.observers, "observer")
+}
+
+// replicaLag returns the number of records the replica is behind the high
+// watermark. Replicas with an unknown log end offset are lagging by the
+// complete log.
+func replicaLag(highWatermark int64, r replicaState) int64 {
+	if r.logEndOffset < 0 {
+		return highWatermark
+	}
+	if lag := highWatermark - r.logEndOffset; lag > 0 {
+		return lag
+	}
+	return 0
+}
+
+func (k *KafkaKRaft) describeQuorum(broker string) ([]partitionQuorum, error) {
+	timeout := time.Duration(k.Timeout)
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	var err error
+	if k.tlsCfg != nil {
+		conn, err = tls.DialWithDialer(dialer, "tcp", broker, k.tlsCfg)
+	} else {
+		conn, err = dialer.Dial("tcp", broker)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	if !k.SASLUsername.Empty() {
+		if err := k.authenticate(conn); err != nil {
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	// Negotiate the version of the DescribeQuorum API
+	d, err := k.request(conn, apiKeyAPIVersions, 0, false, nil)
+	if err != nil {
+		return nil, fmt.Errorf("querying API versions failed: %w", err)
+	}
+	versions, err := decodeAPIVersions(d)
+	if err != nil {
+		return nil, fmt.Errorf("querying API versions failed: %w", err)
+	}
+	supported, found := versions[apiKeyDescribeQuorum]
+	if !found {
+		return nil, errors.New("broker does not support describing the quorum, is the cluster running in KRaft mode?")
+	}
+	version := supported.max
+	if version > maxDescribeQuorumVersion {
+		version = maxDescribeQuorumVersion
+	}
+	if version < supported.min {
+		return nil, fmt.Errorf("broker requires DescribeQuorum version %d or higher", supported.min)
+	}
+
+	d, err = k.request(conn, apiKeyDescribeQuorum, version, true, func(e *encoder) {
+		encodeDescribeQuorum(e, metadataTopic, metadataPartition)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing quorum failed: %w", err)
+	}
+	partitions, err := decodeDescribeQuorum(d, version)
+	if err != nil {
+		return nil, fmt.Errorf("describing quorum failed: %w", err)
+	}
+	return partitions, nil
+}
+
+// authenticate performs a SASL/PLAIN authentication on the connection
+func (k *KafkaKRaft) authenticate(conn net.Conn) error {
+	d, err := k.request(conn, apiKeySaslHandshake, 1, false, func(e *encoder) {
+		e.string("PLAIN")
+	})
+	if err != nil {
+		return err
+	}
+	if code := d.int16(); code != 0 {
+		return fmt.Errorf("handshake failed with error code %d", code)
+	}
+
+	username, err := k.SASLUsername.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer config.ReleaseSecret(username)
+	password, err := k.SASLPassword.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer config.ReleaseSecret(password)
+
+	token := make([]byte, 0, len(username)+len(password)+2)
+	token = append(token, 0)
+	token = append(token, username...)
+	token = append(token, 0)
+	token = append(token, password...)
+
+	d, err = k.request(conn, apiKeySaslAuthenticate, 0, false, func(e *encoder) {
+		e.bytes(token)
+	})
+	if err != nil {
+		return err
+	}
+	if code := d.int16(); code != 0 {
+		return fmt.Errorf("error code %d: %s", code, d.nullableString())
+	}
+	return d.err
+}
+
+// request sends a request with the body written by the given function and
+// returns a decoder positioned after the response header. Flexible versions
+// use headers with tagged fields.
+func (k *KafkaKRaft) request(conn net.Conn, apiKey, version int16, flexible bool, body func(e *encoder)) (*decoder, error) {
+	k.correlationID++
+	correlationID := k.correlationID
+
+	e := header(apiKey, version, correlationID, k.ClientID, flexible)
+	if body != nil {
+		body(e)
+	}
+	if err := writeMessage(conn, e.buf); err != nil {
+		return nil, err
+	}
+	msg, err := readMessage(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{buf: msg}
+	if id := d.int32(); id != correlationID {
+		return nil, fmt.Errorf("received correlation ID %d but expected %d", id, correlationID)
+	}
+	if flexible {
+		d.skipTags()
+	}
+	return d, d.err
+}
+
+func init() {
+	inputs.Add("kafka_kraft", func() telegraf.Input {
+		return &KafkaKRaft{
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
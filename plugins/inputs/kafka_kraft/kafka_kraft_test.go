@@ -0,0 +1,314 @@
+package kafka_kraft
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeBroker answers the requests of the plugin with a fixed quorum state
+type fakeBroker struct {
+	listener          net.Listener
+	maxQuorumVersion  int16
+	supportQuorum     bool
+	expectedAuthToken []byte
+	apiKeys           []int16
+	sync.Mutex
+}
+
+func newFakeBroker(t *testing.T) *fakeBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	b := &fakeBroker{
+		listener:         listener,
+		maxQuorumVersion: 1,
+		supportQuorum:    true,
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(t, conn)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) serve(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		msg, err := readMessage(conn)
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			t.Errorf("reading request failed: %v", err)
+			return
+		}
+
+		d := &decoder{buf: msg}
+		apiKey, version, correlationID := d.int16(), d.int16(), d.int32()
+		d.nullableString()
+		b.Lock()
+		b.apiKeys = append(b.apiKeys, apiKey)
+		b.Unlock()
+
+		e := &encoder{}
+		e.int32(correlationID)
+		switch apiKey {
+		case apiKeySaslHandshake:
+			if d.nullableString() != "PLAIN" {
+				e.int16(33)
+			} else {
+				e.int16(0)
+			}
+			e.int32(1)
+			e.string("PLAIN")
+		case apiKeySaslAuthenticate:
+			if string(d.bytes()) != string(b.expectedAuthToken) {
+				e.int16(58)
+				e.string("invalid credentials")
+			} else {
+				e.int16(0)
+				e.int16(-1)
+			}
+			e.bytes(nil)
+		case apiKeyAPIVersions:
+			keys := []int16{apiKeySaslHandshake, apiKeyAPIVersions, apiKeySaslAuthenticate}
+			if b.supportQuorum {
+				keys = append(keys, apiKeyDescribeQuorum)
+			}
+			e.int16(0)
+			e.int32(int32(len(keys)))
+			for _, key := range keys {
+				e.int16(key)
+				e.int16(0)
+				if key == apiKeyDescribeQuorum {
+					e.int16(b.maxQuorumVersion)
+				} else {
+					e.int16(3)
+				}
+			}
+		case apiKeyDescribeQuorum:
+			d.skipTags()
+			require.Equal(t, 1, d.compactArray())
+			require.Equal(t, metadataTopic, d.compactString())
+
+			e.emptyTags()
+			e.int16(0)
+			e.compactArrayLen(1)
+			e.compactString(metadataTopic)
+			e.compactArrayLen(1)
+			e.int32(0)
+			e.int16(0)
+			e.int32(1)   // leader
+			e.int32(5)   // leader epoch
+			e.int64(100) // high watermark
+			replicas := func(states []replicaState) {
+				e.compactArrayLen(len(states))
+				for _, s := range states {
+					e.int32(s.replicaID)
+					e.int64(s.logEndOffset)
+					if version >= 1 {
+						e.int64(s.lastFetchTimestamp)
+						e.int64(s.lastCaughtUpTimestamp)
+					}
+					e.emptyTags()
+				}
+			}
+			replicas([]replicaState{
+				{replicaID: 1, logEndOffset: 100, lastFetchTimestamp: -1, lastCaughtUpTimestamp: -1},
+				{replicaID: 2, logEndOffset: 100, lastFetchTimestamp: 1690000000000, lastCaughtUpTimestamp: 1690000000000},
+				{replicaID: 3, logEndOffset: 90, lastFetchTimestamp: 1690000000000, lastCaughtUpTimestamp: 1680000000000},
+			})
+			replicas([]replicaState{
+				{replicaID: 4, logEndOffset: -1, lastFetchTimestamp: -1, lastCaughtUpTimestamp: -1},
+			})
+			e.emptyTags()
+			e.emptyTags()
+			e.emptyTags()
+		default:
+			t.Errorf("unexpected API key %d", apiKey)
+			return
+		}
+		require.NoError(t, writeMessage(conn, e.buf))
+	}
+}
+
+func TestGather(t *testing.T) {
+	broker := newFakeBroker(t)
+	defer broker.listener.Close()
+	addr := broker.listener.Addr().String()
+
+	plugin := &KafkaKRaft{
+		Brokers: []string{addr},
+		Timeout: config.Duration(5 * time.Second),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	replicaTags := func(id, role string) map[string]string {
+		return map[string]string{
+			"broker":     addr,
+			"topic":      metadataTopic,
+			"partition":  "0",
+			"replica_id": id,
+			"role":       role,
+		}
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"kafka_kraft_quorum",
+			map[string]string{
+				"broker":    addr,
+				"topic":     metadataTopic,
+				"partition": "0",
+			},
+			map[string]interface{}{
+				"leader_id":          int32(1),
+				"leader_epoch":       int32(5),
+				"high_watermark":     int64(100),
+				"voters":             3,
+				"voters_caught_up":   2,
+				"observers":          1,
+				"max_voter_lag":      int64(10),
+				"has_leader":         true,
+				"majority_caught_up": true,
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"kafka_kraft_replica",
+			replicaTags("1", "leader"),
+			map[string]interface{}{
+				"log_end_offset": int64(100),
+				"lag":            int64(0),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"kafka_kraft_replica",
+			replicaTags("2", "voter"),
+			map[string]interface{}{
+				"log_end_offset":           int64(100),
+				"lag":                      int64(0),
+				"last_fetch_timestamp":     int64(1690000000000),
+				"last_caught_up_timestamp": int64(1690000000000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"kafka_kraft_replica",
+			replicaTags("3", "voter"),
+			map[string]interface{}{
+				"log_end_offset":           int64(90),
+				"lag":                      int64(10),
+				"last_fetch_timestamp":     int64(1690000000000),
+				"last_caught_up_timestamp": int64(1680000000000),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"kafka_kraft_replica",
+			replicaTags("4", "observer"),
+			map[string]interface{}{
+				"log_end_offset": int64(-1),
+				"lag":            int64(100),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestGatherVersion0(t *testing.T) {
+	broker := newFakeBroker(t)
+	broker.maxQuorumVersion = 0
+	defer broker.listener.Close()
+
+	plugin := &KafkaKRaft{
+		Brokers: []string{broker.listener.Addr().String()},
+		Timeout: config.Duration(5 * time.Second),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	// Version 0 does not report the fetch timestamps
+	for _, m := range acc.GetTelegrafMetrics() {
+		require.False(t, m.HasField("last_fetch_timestamp"))
+	}
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+}
+
+func TestGatherSASL(t *testing.T) {
+	broker := newFakeBroker(t)
+	broker.expectedAuthToken = []byte("\x00kafka\x00secret")
+	defer broker.listener.Close()
+
+	plugin := &KafkaKRaft{
+		Brokers:      []string{broker.listener.Addr().String()},
+		Timeout:      config.Duration(5 * time.Second),
+		SASLUsername: config.NewSecret([]byte("kafka")),
+		SASLPassword: config.NewSecret([]byte("secret")),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+	broker.Lock()
+	require.Equal(t, []int16{apiKeySaslHandshake, apiKeySaslAuthenticate, apiKeyAPIVersions, apiKeyDescribeQuorum}, broker.apiKeys)
+	broker.Unlock()
+
+	plugin.SASLPassword = config.NewSecret([]byte("wrong"))
+	acc = testutil.Accumulator{}
+	require.ErrorContains(t, plugin.Gather(&acc), "invalid credentials")
+}
+
+func TestGatherFallback(t *testing.T) {
+	// Brokers of a cluster not running in KRaft mode do not support the
+	// DescribeQuorum API
+	zkBroker := newFakeBroker(t)
+	zkBroker.supportQuorum = false
+	defer zkBroker.listener.Close()
+
+	broker := newFakeBroker(t)
+	defer broker.listener.Close()
+
+	plugin := &KafkaKRaft{
+		Brokers: []string{zkBroker.listener.Addr().String()},
+		Timeout: config.Duration(5 * time.Second),
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Gather(&acc), "KRaft mode")
+
+	// The next broker is used if a broker fails
+	plugin.Brokers = append(plugin.Brokers, broker.listener.Addr().String())
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 5)
+}
+
+func TestInitInvalidBroker(t *testing.T) {
+	plugin := &KafkaKRaft{Brokers: []string{"localhost"}}
+	require.ErrorContains(t, plugin.Init(), "invalid broker address")
+}
@@ -0,0 +1,319 @@
+package kafka_kraft
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// API keys of the Kafka protocol used by the plugin, see
+// https://kafka.apache.org/protocol.html#protocol_api_keys
+const (
+	apiKeySaslHandshake    int16 = 17
+	apiKeyAPIVersions      int16 = 18
+	apiKeySaslAuthenticate int16 = 36
+	apiKeyDescribeQuorum   int16 = 55
+)
+
+// Highest version of the DescribeQuorum API supported by the plugin
+const maxDescribeQuorumVersion int16 = 1
+
+// Maximum size of a response accepted from the broker
+const maxResponseSize = 16 * 1024 * 1024
+
+var errUnexpectedEOF = errors.New("unexpected end of message")
+
+// encoder serializes the primitive types of the Kafka protocol
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int16(v int16) {
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+}
+
+func (e *encoder) int32(v int32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+}
+
+func (e *encoder) int64(v int64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v))
+}
+
+func (e *encoder) uvarint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) string(v string) {
+	e.int16(int16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) bytes(v []byte) {
+	e.int32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) compactString(v string) {
+	e.uvarint(uint64(len(v)) + 1)
+	e.buf = append(e.buf, v...)
+}
+
+// compactArrayLen writes the length of a compact array
+func (e *encoder) compactArrayLen(n int) {
+	e.uvarint(uint64(n) + 1)
+}
+
+// emptyTags writes an empty tagged field section of flexible versions
+func (e *encoder) emptyTags() {
+	e.uvarint(0)
+}
+
+// decoder deserializes the primitive types of the Kafka protocol
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errUnexpectedEOF
+		return nil
+	}
+	v := d.buf[:n]
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = errUnexpectedEOF
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+// nullableString reads a string with a length of -1 denoting null
+func (d *decoder) nullableString() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.take(int(n))
+}
+
+func (d *decoder) compactString() string {
+	n := d.uvarint()
+	if n == 0 {
+		return ""
+	}
+	return string(d.take(int(n - 1)))
+}
+
+// array reads the length of an array with a length of -1 denoting null
+func (d *decoder) array() int {
+	n := d.int32()
+	if n < 0 || int(n) > len(d.buf) {
+		if n >= 0 {
+			d.err = errUnexpectedEOF
+		}
+		return 0
+	}
+	return int(n)
+}
+
+// compactArray reads the length of a compact array
+func (d *decoder) compactArray() int {
+	n := d.uvarint()
+	if n == 0 {
+		return 0
+	}
+	if n-1 > uint64(len(d.buf)) {
+		d.err = errUnexpectedEOF
+		return 0
+	}
+	return int(n - 1)
+}
+
+// skipTags skips the tagged fields of flexible versions
+func (d *decoder) skipTags() {
+	for i := d.uvarint(); i > 0 && d.err == nil; i-- {
+		d.uvarint()
+		d.take(int(d.uvarint()))
+	}
+}
+
+// header writes the request header, flexible versions use header version 2
+// with an additional tagged field section
+func header(apiKey, apiVersion int16, correlationID int32, clientID string, flexible bool) *encoder {
+	e := &encoder{buf: make([]byte, 0, 64)}
+	e.int16(apiKey)
+	e.int16(apiVersion)
+	e.int32(correlationID)
+	e.string(clientID)
+	if flexible {
+		e.emptyTags()
+	}
+	return e
+}
+
+// writeMessage writes the size-delimited message
+func writeMessage(w io.Writer, msg []byte) error {
+	buf := make([]byte, 0, len(msg)+4)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(msg)))
+	buf = append(buf, msg...)
+	_, err := w.Write(buf)
+	return err
+}
+
+// readMessage reads a size-delimited message
+func readMessage(r io.Reader) ([]byte, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(r, size[:]); err != nil {
+		return nil, err
+	}
+	n := binary.BigEndian.Uint32(size[:])
+	if n > maxResponseSize {
+		return nil, fmt.Errorf("message size %d exceeds maximum of %d", n, maxResponseSize)
+	}
+	msg := make([]byte, n)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// apiVersionRange is the range of versions supported by the broker for an API
+type apiVersionRange struct {
+	min int16
+	max int16
+}
+
+func decodeAPIVersions(d *decoder) (map[int16]apiVersionRange, error) {
+	if code := d.int16(); code != 0 {
+		return nil, fmt.Errorf("error code %d", code)
+	}
+	n := d.array()
+	versions := make(map[int16]apiVersionRange, n)
+	for i := 0; i < n; i++ {
+		key := d.int16()
+		versions[key] = apiVersionRange{min: d.int16(), max: d.int16()}
+	}
+	return versions, d.err
+}
+
+// replicaState is the state of a voter or observer of the quorum
+type replicaState struct {
+	replicaID             int32
+	logEndOffset          int64
+	lastFetchTimestamp    int64
+	lastCaughtUpTimestamp int64
+}
+
+// partitionQuorum is the quorum state of a partition of the metadata topic
+type partitionQuorum struct {
+	topic         string
+	partition     int32
+	errorCode     int16
+	leaderID      int32
+	leaderEpoch   int32
+	highWatermark int64
+	voters        []replicaState
+	observers     []replicaState
+}
+
+func encodeDescribeQuorum(e *encoder, topic string, partition int32) {
+	e.compactArrayLen(1)
+	e.compactString(topic)
+	e.compactArrayLen(1)
+	e.int32(partition)
+	e.emptyTags()
+	e.emptyTags()
+	e.emptyTags()
+}
+
+func decodeReplicaStates(d *decoder, version int16) []replicaState {
+	n := d.compactArray()
+	states := make([]replicaState, 0, n)
+	for i := 0; i < n; i++ {
+		state := replicaState{
+			replicaID:             d.int32(),
+			logEndOffset:          d.int64(),
+			lastFetchTimestamp:    -1,
+			lastCaughtUpTimestamp: -1,
+		}
+		if version >= 1 {
+			state.lastFetchTimestamp = d.int64()
+			state.lastCaughtUpTimestamp = d.int64()
+		}
+		d.skipTags()
+		states = append(states, state)
+	}
+	return states
+}
+
+func decodeDescribeQuorum(d *decoder, version int16) ([]partitionQuorum, error) {
+	if code := d.int16(); code != 0 {
+		return nil, fmt.Errorf("error code %d", code)
+	}
+
+	var partitions []partitionQuorum
+	for i, topics := 0, d.compactArray(); i < topics; i++ {
+		topic := d.compactString()
+		for j, n := 0, d.compactArray(); j < n; j++ {
+			p := partitionQuorum{
+				topic:         topic,
+				partition:     d.int32(),
+				errorCode:     d.int16(),
+				leaderID:      d.int32(),
+				leaderEpoch:   d.int32(),
+				highWatermark: d.int64(),
+			}
+			p.voters = decodeReplicaStates(d, version)
+			p.observers = decodeReplicaStates(d, version)
+			d.skipTags()
+			partitions = append(partitions, p)
+		}
+		d.skipTags()
+	}
+	d.skipTags()
+	return partitions, d.err
+}
@@ -7,6 +7,7 @@ import (
 	"compress/zlib"
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
@@ -49,6 +50,7 @@ type (
 		sem    chan struct{}
 
 		checkpoint    consumer.Store
+		states        *stateStore
 		checkpoints   map[string]checkpoint
 		records       map[telegraf.TrackingID]string
 		checkpointTex sync.Mutex
@@ -92,7 +94,9 @@ func (k *KinesisConsumer) connect(ac telegraf.Accumulator) error {
 	}
 	client := kinesis.NewFromConfig(cfg)
 
-	k.checkpoint = &noopStore{}
+	// Keep the checkpoints in the plugin state, persisted by the agent, unless
+	// a DynamoDB table is configured
+	k.checkpoint = k.states
 	if k.DynamoDB != nil {
 		var err error
 		k.checkpoint, err = ddb.New(
@@ -308,13 +312,62 @@ func (k *KinesisConsumer) configureProcessContentEncodingFunc() error {
 }
 
 func (k *KinesisConsumer) Init() error {
+	k.states = &stateStore{checkpoints: make(map[string]string)}
 	return k.configureProcessContentEncodingFunc()
 }
 
-type noopStore struct{}
+func (k *KinesisConsumer) GetState() interface{} {
+	return k.states.get()
+}
+
+func (k *KinesisConsumer) SetState(state interface{}) error {
+	checkpoints, ok := state.(map[string]string)
+	if !ok {
+		return errors.New("state has to be of type 'map[string]string'")
+	}
+	k.states.set(checkpoints)
+	return nil
+}
+
+// stateStore keeps the checkpoints of the shards in memory so they can be
+// persisted as state of the plugin
+type stateStore struct {
+	checkpoints map[string]string
+	sync.Mutex
+}
+
+func (s *stateStore) SetCheckpoint(streamName, shardID, sequenceNumber string) error {
+	s.Lock()
+	s.checkpoints[streamName+"/"+shardID] = sequenceNumber
+	s.Unlock()
+	return nil
+}
+
+func (s *stateStore) GetCheckpoint(streamName, shardID string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	return s.checkpoints[streamName+"/"+shardID], nil
+}
+
+func (s *stateStore) get() map[string]string {
+	s.Lock()
+	defer s.Unlock()
+
+	checkpoints := make(map[string]string, len(s.checkpoints))
+	for k, v := range s.checkpoints {
+		checkpoints[k] = v
+	}
+	return checkpoints
+}
+
+func (s *stateStore) set(checkpoints map[string]string) {
+	s.Lock()
+	defer s.Unlock()
 
-func (n noopStore) SetCheckpoint(string, string, string) error   { return nil }
-func (n noopStore) GetCheckpoint(string, string) (string, error) { return "", nil }
+	for k, v := range checkpoints {
+		s.checkpoints[k] = v
+	}
+}
 
 func init() {
 	negOne, _ = new(big.Int).SetString("-1", 10)
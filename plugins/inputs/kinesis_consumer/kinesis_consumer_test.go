@@ -230,3 +230,26 @@ func TestKinesisConsumer_onMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestStateCheckpoints(t *testing.T) {
+	k := &KinesisConsumer{}
+	require.NoError(t, k.Init())
+
+	require.NoError(t, k.states.SetCheckpoint("stream", "shard-0", "42"))
+	require.NoError(t, k.states.SetCheckpoint("stream", "shard-1", "23"))
+	state := k.GetState()
+	require.Equal(t, map[string]string{"stream/shard-0": "42", "stream/shard-1": "23"}, state)
+
+	// Restore the state in a new instance
+	restored := &KinesisConsumer{}
+	require.NoError(t, restored.Init())
+	require.NoError(t, restored.SetState(state))
+	seq, err := restored.states.GetCheckpoint("stream", "shard-0")
+	require.NoError(t, err)
+	require.Equal(t, "42", seq)
+	seq, err = restored.states.GetCheckpoint("stream", "shard-2")
+	require.NoError(t, err)
+	require.Empty(t, seq)
+
+	require.ErrorContains(t, restored.SetState("foo"), "state has to be of type")
+}
@@ -16,6 +16,9 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/delivery"
+	mqttcommon "github.com/influxdata/telegraf/plugins/common/mqtt"
+	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/selfstat"
@@ -79,6 +82,8 @@ type MQTTConsumer struct {
 	ClientID          string `toml:"client_id"`
 
 	tls.ClientConfig
+	proxy.TCPProxy
+	delivery.DeliveryConfig
 
 	Log           telegraf.Logger
 	clientFactory ClientFactory
@@ -123,6 +128,9 @@ func (m *MQTTConsumer) Init() error {
 	if time.Duration(m.ConnectionTimeout) < 1*time.Second {
 		return fmt.Errorf("connection_timeout must be greater than 1s: %s", time.Duration(m.ConnectionTimeout))
 	}
+	if err := m.DeliveryConfig.Init(); err != nil {
+		return err
+	}
 	m.topicTagParse = "topic"
 	if m.TopicTag != nil {
 		m.topicTagParse = *m.TopicTag
@@ -165,7 +173,7 @@ func (m *MQTTConsumer) Init() error {
 }
 func (m *MQTTConsumer) Start(acc telegraf.Accumulator) error {
 	m.state = Disconnected
-	m.acc = acc.WithTracking(m.MaxUndeliveredMessages)
+	m.acc = m.DeliveryConfig.WrapAccumulator(acc).WithTracking(m.MaxUndeliveredMessages)
 	m.sem = make(semaphore, m.MaxUndeliveredMessages)
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 
@@ -257,7 +265,7 @@ func (m *MQTTConsumer) onDelivered(track telegraf.DeliveryInfo) {
 		return
 	}
 
-	if track.Delivered() && m.PersistentSession {
+	if m.Delivered(track) && m.PersistentSession {
 		msg.Ack()
 	}
 
@@ -365,6 +373,13 @@ func (m *MQTTConsumer) createOpts() (*mqtt.ClientOptions, error) {
 	if tlsCfg != nil {
 		opts.SetTLSConfig(tlsCfg)
 	}
+	if m.UseProxy {
+		dialer, err := m.TCPProxy.Proxy()
+		if err != nil {
+			return nil, fmt.Errorf("setting up proxy failed: %w", err)
+		}
+		opts.SetCustomOpenConnectionFn(mqttcommon.ProxyConnectionFunc(dialer))
+	}
 	if !m.Username.Empty() {
 		user, err := m.Username.Get()
 		if err != nil {
@@ -3,6 +3,7 @@ package net_response
 
 import (
 	"bufio"
+	"crypto/tls"
 	_ "embed"
 	"errors"
 	"fmt"
@@ -14,6 +15,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/choice"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -28,6 +30,7 @@ const (
 	ConnectionFailed ResultType = 2
 	ReadFailed       ResultType = 3
 	StringMismatch   ResultType = 4
+	TLSFailed        ResultType = 5
 )
 
 // NetResponse struct
@@ -38,6 +41,21 @@ type NetResponse struct {
 	Send        string
 	Expect      string
 	Protocol    string
+	Steps       []*Step `toml:"step"`
+	tlsint.ClientConfig
+
+	tlsConfig *tls.Config
+}
+
+// Step is a single step of a TCP dialogue sending a string and waiting for
+// a line matching the expected pattern
+type Step struct {
+	Name     string `toml:"name"`
+	Send     string `toml:"send"`
+	Expect   string `toml:"expect"`
+	StartTLS bool   `toml:"starttls"`
+
+	expect *regexp.Regexp
 }
 
 func (*NetResponse) SampleConfig() string {
@@ -67,6 +85,18 @@ func (n *NetResponse) TCPGather() (map[string]string, map[string]interface{}, er
 		return tags, fields, nil
 	}
 	defer conn.Close()
+	// Run the dialogue if configured
+	if len(n.Steps) > 0 {
+		result, err := n.dialogue(conn, fields)
+		if err != nil {
+			return nil, nil, err
+		}
+		setResult(result, fields, tags, "")
+		if result == Success {
+			fields["response_time"] = time.Since(start).Seconds()
+		}
+		return tags, fields, nil
+	}
 	// Send string if needed
 	if n.Send != "" {
 		msg := []byte(n.Send)
@@ -109,6 +139,75 @@ func (n *NetResponse) TCPGather() (map[string]string, map[string]interface{}, er
 	return tags, fields, nil
 }
 
+// dialogue runs the configured steps on the connection and records the time
+// taken for each step. The name of the first failing step is added as field.
+func (n *NetResponse) dialogue(conn net.Conn, fields map[string]interface{}) (ResultType, error) {
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	for _, step := range n.Steps {
+		start := time.Now()
+		result, upgraded, err := n.runStep(conn, reader, step)
+		if err != nil {
+			return result, err
+		}
+		if result != Success {
+			fields["failed_step"] = step.Name
+			return result, nil
+		}
+		if upgraded != nil {
+			conn = upgraded
+			reader = textproto.NewReader(bufio.NewReader(conn))
+		}
+		fields[step.Name+"_response_time"] = time.Since(start).Seconds()
+	}
+	return Success, nil
+}
+
+func (n *NetResponse) runStep(conn net.Conn, reader *textproto.Reader, step *Step) (ResultType, net.Conn, error) {
+	if step.Send != "" {
+		if err := conn.SetWriteDeadline(time.Now().Add(time.Duration(n.Timeout))); err != nil {
+			return ConnectionFailed, nil, err
+		}
+		if _, err := conn.Write([]byte(step.Send)); err != nil {
+			return ConnectionFailed, nil, nil //nolint:nilerr // error encoded in result
+		}
+	}
+
+	if step.expect != nil {
+		if err := conn.SetReadDeadline(time.Now().Add(time.Duration(n.ReadTimeout))); err != nil {
+			return ReadFailed, nil, err
+		}
+		// Read lines until the expected one is found to skip e.g. the
+		// continuation lines of multi-line responses
+		var received bool
+		for {
+			line, err := reader.ReadLine()
+			if err != nil {
+				if received {
+					return StringMismatch, nil, nil
+				}
+				return ReadFailed, nil, nil
+			}
+			received = true
+			if step.expect.MatchString(line) {
+				break
+			}
+		}
+	}
+
+	if !step.StartTLS {
+		return Success, nil, nil
+	}
+
+	tlsConn := tls.Client(conn, n.tlsConfig)
+	if err := tlsConn.SetDeadline(time.Now().Add(time.Duration(n.Timeout))); err != nil {
+		return TLSFailed, nil, err
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		return TLSFailed, nil, nil //nolint:nilerr // error encoded in result
+	}
+	return Success, tlsConn, nil
+}
+
 // UDPGather will execute if there are UDP tests defined in the configuration.
 // It will return a map[string]interface{} for fields and a map[string]string for tags
 func (n *NetResponse) UDPGather() (map[string]string, map[string]interface{}, error) {
@@ -200,6 +299,50 @@ func (n *NetResponse) Init() error {
 		return fmt.Errorf("config option protocol: %w", err)
 	}
 
+	// Check the dialogue steps
+	if len(n.Steps) > 0 {
+		if n.Protocol != "tcp" {
+			return errors.New("steps are only supported for the tcp protocol")
+		}
+		if n.Send != "" || n.Expect != "" {
+			return errors.New("send and expect cannot be used together with steps")
+		}
+	}
+	names := make(map[string]bool, len(n.Steps))
+	for i, step := range n.Steps {
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("step%d", i+1)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("duplicate step name %q", step.Name)
+		}
+		names[step.Name] = true
+
+		if step.Expect != "" {
+			var err error
+			step.expect, err = regexp.Compile(step.Expect)
+			if err != nil {
+				return fmt.Errorf("compiling expected pattern of step %q failed: %w", step.Name, err)
+			}
+		}
+
+		if step.StartTLS && n.tlsConfig == nil {
+			n.tlsConfig, err = n.ClientConfig.TLSConfig()
+			if err != nil {
+				return err
+			}
+			if n.tlsConfig == nil {
+				n.tlsConfig = &tls.Config{}
+			}
+			if n.tlsConfig.ServerName == "" {
+				n.tlsConfig.ServerName, _, err = net.SplitHostPort(n.Address)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -256,6 +399,8 @@ func setResult(result ResultType, fields map[string]interface{}, tags map[string
 		tag = "read_failed"
 	case StringMismatch:
 		tag = "string_mismatch"
+	case TLSFailed:
+		tag = "tls_failed"
 	}
 
 	tags["result"] = tag
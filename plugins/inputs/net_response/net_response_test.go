@@ -1,6 +1,8 @@
 package net_response
 
 import (
+	"bufio"
+	"crypto/tls"
 	"net"
 	"sync"
 	"testing"
@@ -295,3 +297,178 @@ func TCPServer(t *testing.T, wg *sync.WaitGroup) {
 	require.NoError(t, conn.CloseWrite())
 	require.NoError(t, tcpServer.Close())
 }
+
+func TestStepsInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *NetResponse
+		expected string
+	}{
+		{
+			name: "udp protocol",
+			plugin: &NetResponse{
+				Protocol: "udp",
+				Address:  ":25",
+				Send:     "a",
+				Expect:   "b",
+				Steps:    []*Step{{Expect: "220"}},
+			},
+			expected: "steps are only supported for the tcp protocol",
+		},
+		{
+			name: "send and steps",
+			plugin: &NetResponse{
+				Protocol: "tcp",
+				Address:  ":25",
+				Send:     "a",
+				Steps:    []*Step{{Expect: "220"}},
+			},
+			expected: "send and expect cannot be used together with steps",
+		},
+		{
+			name: "duplicate name",
+			plugin: &NetResponse{
+				Protocol: "tcp",
+				Address:  ":25",
+				Steps:    []*Step{{Name: "step2"}, {}},
+			},
+			expected: `duplicate step name "step2"`,
+		},
+		{
+			name: "invalid pattern",
+			plugin: &NetResponse{
+				Protocol: "tcp",
+				Address:  ":25",
+				Steps:    []*Step{{Name: "banner", Expect: "220[("}},
+			},
+			expected: `compiling expected pattern of step "banner" failed`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+// smtpServer accepts a single connection and runs an SMTP like dialogue
+// including STARTTLS
+func smtpServer(t *testing.T, listener net.Listener) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+	cert, err := tls.LoadX509KeyPair(pki.ServerCertPath(), pki.ServerKeyPath())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	_, _ = conn.Write([]byte("220 mail.example.org ESMTP\r\n"))
+	if line, _ := reader.ReadString('\n'); line != "EHLO telegraf\r\n" {
+		t.Errorf("unexpected command %q", line)
+		return
+	}
+	_, _ = conn.Write([]byte("250-mail.example.org\r\n250-SIZE 1024\r\n250 STARTTLS\r\n"))
+	if line, _ := reader.ReadString('\n'); line != "STARTTLS\r\n" {
+		t.Errorf("unexpected command %q", line)
+		return
+	}
+	_, _ = conn.Write([]byte("220 Ready to start TLS\r\n"))
+
+	tlsConn := tls.Server(conn, &tls.Config{Certificates: []tls.Certificate{cert}})
+	if err := tlsConn.Handshake(); err != nil {
+		t.Error(err)
+		return
+	}
+	tlsReader := bufio.NewReader(tlsConn)
+	if line, _ := tlsReader.ReadString('\n'); line != "NOOP\r\n" {
+		t.Errorf("unexpected command %q", line)
+		return
+	}
+	_, _ = tlsConn.Write([]byte("250 OK\r\n"))
+}
+
+func TestSteps(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		smtpServer(t, listener)
+	}()
+
+	plugin := &NetResponse{
+		Protocol:    "tcp",
+		Address:     listener.Addr().String(),
+		Timeout:     config.Duration(time.Second),
+		ReadTimeout: config.Duration(time.Second),
+		Steps: []*Step{
+			{Name: "banner", Expect: "^220 "},
+			{Name: "ehlo", Send: "EHLO telegraf\r\n", Expect: "^250 "},
+			{Name: "starttls", Send: "STARTTLS\r\n", Expect: "^220 ", StartTLS: true},
+			{Send: "NOOP\r\n", Expect: "^250 OK"},
+		},
+	}
+	plugin.InsecureSkipVerify = true
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	wg.Wait()
+
+	require.Len(t, acc.Metrics, 1)
+	m := acc.Metrics[0]
+	require.Equal(t, "success", m.Tags["result"])
+	require.Equal(t, uint64(0), m.Fields["result_code"])
+	for _, field := range []string{"response_time", "banner_response_time", "ehlo_response_time", "starttls_response_time", "step4_response_time"} {
+		require.Contains(t, m.Fields, field)
+	}
+	require.NotContains(t, m.Fields, "failed_step")
+}
+
+func TestStepsMismatch(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("220 mail.example.org ESMTP\r\n554 No service\r\n"))
+	}()
+
+	plugin := &NetResponse{
+		Protocol:    "tcp",
+		Address:     listener.Addr().String(),
+		Timeout:     config.Duration(time.Second),
+		ReadTimeout: config.Duration(100 * time.Millisecond),
+		Steps: []*Step{
+			{Name: "banner", Expect: "^220 "},
+			{Name: "ehlo", Send: "EHLO telegraf\r\n", Expect: "^250 "},
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	require.Len(t, acc.Metrics, 1)
+	m := acc.Metrics[0]
+	require.Equal(t, "string_mismatch", m.Tags["result"])
+	require.Equal(t, "ehlo", m.Fields["failed_step"])
+	require.Contains(t, m.Fields, "banner_response_time")
+	require.NotContains(t, m.Fields, "response_time")
+}
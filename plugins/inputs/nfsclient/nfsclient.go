@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -25,10 +26,25 @@ type NFSClient struct {
 	ExcludeMounts     []string        `toml:"exclude_mounts"`
 	IncludeOperations []string        `toml:"include_operations"`
 	ExcludeOperations []string        `toml:"exclude_operations"`
+	OperationLatency  bool            `toml:"operation_latency"`
+	LatencyBuckets    []float64       `toml:"latency_buckets"`
 	Log               telegraf.Logger `toml:"-"`
 	nfs3Ops           map[string]bool
 	nfs4Ops           map[string]bool
 	mountstatsPath    string
+	latency           map[string]*opLatency
+}
+
+// opLatency holds the counters of an operation on a mount at the previous
+// gather and the RTT histogram accumulated since the start
+type opLatency struct {
+	ops     uint64
+	queue   uint64
+	rtt     uint64
+	exe     uint64
+	buckets []uint64
+	count   uint64
+	sum     uint64
 }
 
 func convertToUint64(line []string) ([]uint64, error) {
@@ -218,9 +234,98 @@ func (n *NFSClient) parseStat(mountpoint string, export string, version string,
 		}
 	}
 
+	if n.OperationLatency && len(nline) >= 8 {
+		if (version == "3" && n.nfs3Ops[first]) || (version == "4" && n.nfs4Ops[first]) {
+			n.addLatency(mountpoint, export, first, nline, acc)
+		}
+	}
+
 	return nil
 }
 
+// addLatency calculates the latency of the operation over the interval since
+// the last gather. The per-op line contains the number of operations,
+// transmissions, timeouts, bytes sent and received followed by the
+// cumulative queue, round-trip and execution time in milliseconds.
+func (n *NFSClient) addLatency(mountpoint, export, operation string, nline []uint64, acc telegraf.Accumulator) {
+	if n.latency == nil {
+		n.latency = make(map[string]*opLatency)
+	}
+
+	ops, queue, rtt, exe := nline[0], nline[5], nline[6], nline[7]
+	key := mountpoint + "|" + export + "|" + operation
+	prev, found := n.latency[key]
+	if !found {
+		n.latency[key] = &opLatency{
+			ops:     ops,
+			queue:   queue,
+			rtt:     rtt,
+			exe:     exe,
+			buckets: make([]uint64, len(n.LatencyBuckets)),
+		}
+		return
+	}
+
+	// Counters are reset on remount so start over with the current values
+	if ops < prev.ops || queue < prev.queue || rtt < prev.rtt || exe < prev.exe {
+		prev.ops, prev.queue, prev.rtt, prev.exe = ops, queue, rtt, exe
+		return
+	}
+
+	deltaOps := ops - prev.ops
+	deltaRTT := rtt - prev.rtt
+	tags := map[string]string{
+		"mountpoint":   mountpoint,
+		"serverexport": export,
+		"operation":    operation,
+	}
+	fields := map[string]interface{}{
+		"ops": deltaOps,
+	}
+	if deltaOps > 0 {
+		fields["queue_time_avg"] = float64(queue-prev.queue) / float64(deltaOps)
+		fields["rtt_avg"] = float64(deltaRTT) / float64(deltaOps)
+		fields["exe_avg"] = float64(exe-prev.exe) / float64(deltaOps)
+
+		// The kernel only exposes the total time so all operations of the
+		// interval are counted with the average RTT
+		avg := float64(deltaRTT) / float64(deltaOps)
+		for i, bound := range n.LatencyBuckets {
+			if avg <= bound {
+				prev.buckets[i] += deltaOps
+			}
+		}
+		prev.count += deltaOps
+		prev.sum += deltaRTT
+	}
+	prev.ops, prev.queue, prev.rtt, prev.exe = ops, queue, rtt, exe
+
+	if len(n.LatencyBuckets) == 0 {
+		acc.AddFields("nfs_op_latency", fields, tags)
+		return
+	}
+	fields["rtt_count"] = prev.count
+	fields["rtt_sum"] = prev.sum
+	acc.AddFields("nfs_op_latency", fields, tags)
+
+	for i, bound := range n.LatencyBuckets {
+		btags := map[string]string{
+			"mountpoint":   mountpoint,
+			"serverexport": export,
+			"operation":    operation,
+			"le":           strconv.FormatFloat(bound, 'f', -1, 64),
+		}
+		acc.AddFields("nfs_op_latency", map[string]interface{}{"rtt_bucket": prev.buckets[i]}, btags)
+	}
+	btags := map[string]string{
+		"mountpoint":   mountpoint,
+		"serverexport": export,
+		"operation":    operation,
+		"le":           "+Inf",
+	}
+	acc.AddFields("nfs_op_latency", map[string]interface{}{"rtt_bucket": prev.count}, btags)
+}
+
 func (n *NFSClient) processText(scanner *bufio.Scanner, acc telegraf.Accumulator) error {
 	var mount string
 	var version string
@@ -443,6 +548,14 @@ func (n *NFSClient) Init() error {
 	n.nfs3Ops = nfs3Ops
 	n.nfs4Ops = nfs4Ops
 
+	for _, bound := range n.LatencyBuckets {
+		if bound <= 0 {
+			return fmt.Errorf("invalid latency bucket %v, must be positive", bound)
+		}
+	}
+	sort.Float64s(n.LatencyBuckets)
+	n.latency = make(map[string]*opLatency)
+
 	if len(n.IncludeMounts) > 0 {
 		n.Log.Debugf("Including these mount patterns: %v", n.IncludeMounts)
 	} else {
@@ -5,7 +5,10 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -205,3 +208,88 @@ func TestNFSClientProcessFull(t *testing.T) {
 	acc.AssertContainsFields(t, "nfs_bytes", fieldsBytes)
 	acc.AssertContainsFields(t, "nfs_xprt_tcp", fieldsXprtTCP)
 }
+
+func TestNFSClientOperationLatency(t *testing.T) {
+	nfsclient := NFSClient{
+		OperationLatency: true,
+		LatencyBuckets:   []float64{1, 2},
+		Log:              testutil.Logger{},
+	}
+	nfsclient.nfs3Ops = map[string]bool{"READ": true, "GETATTR": false}
+	nfsclient.nfs4Ops = map[string]bool{"READ": true, "GETATTR": false}
+
+	latency := func(acc *testutil.Accumulator) []telegraf.Metric {
+		var metrics []telegraf.Metric
+		for _, m := range acc.GetTelegrafMetrics() {
+			if m.Name() == "nfs_op_latency" {
+				metrics = append(metrics, m)
+			}
+		}
+		return metrics
+	}
+
+	// The first gather only records the counters
+	var acc testutil.Accumulator
+	data := strings.Fields("READ: 100 100 0 0 0 10 150 200")
+	require.NoError(t, nfsclient.parseStat("/A", "1.2.3.4:/storage/NFS", "3", data, &acc))
+	require.Empty(t, latency(&acc))
+
+	acc.ClearMetrics()
+	data = strings.Fields("READ: 200 200 0 0 0 30 300 400")
+	require.NoError(t, nfsclient.parseStat("/A", "1.2.3.4:/storage/NFS", "3", data, &acc))
+
+	tags := map[string]string{
+		"mountpoint":   "/A",
+		"serverexport": "1.2.3.4:/storage/NFS",
+		"operation":    "READ",
+	}
+	withBound := func(le string) map[string]string {
+		btags := map[string]string{"le": le}
+		for k, v := range tags {
+			btags[k] = v
+		}
+		return btags
+	}
+	expected := []telegraf.Metric{
+		metric.New(
+			"nfs_op_latency",
+			tags,
+			map[string]interface{}{
+				"ops":            uint64(100),
+				"queue_time_avg": 0.2,
+				"rtt_avg":        1.5,
+				"exe_avg":        2.0,
+				"rtt_count":      uint64(100),
+				"rtt_sum":        uint64(150),
+			},
+			time.Unix(0, 0),
+		),
+		metric.New("nfs_op_latency", withBound("1"), map[string]interface{}{"rtt_bucket": uint64(0)}, time.Unix(0, 0)),
+		metric.New("nfs_op_latency", withBound("2"), map[string]interface{}{"rtt_bucket": uint64(100)}, time.Unix(0, 0)),
+		metric.New("nfs_op_latency", withBound("+Inf"), map[string]interface{}{"rtt_bucket": uint64(100)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, latency(&acc), testutil.IgnoreTime())
+
+	// Counter resets restart the calculation
+	acc.ClearMetrics()
+	data = strings.Fields("READ: 10 10 0 0 0 1 1 1")
+	require.NoError(t, nfsclient.parseStat("/A", "1.2.3.4:/storage/NFS", "3", data, &acc))
+	require.Empty(t, latency(&acc))
+
+	// Excluded operations are not collected
+	acc.ClearMetrics()
+	data = strings.Fields("GETATTR: 10 10 0 0 0 1 1 1")
+	require.NoError(t, nfsclient.parseStat("/A", "1.2.3.4:/storage/NFS", "3", data, &acc))
+	data = strings.Fields("GETATTR: 20 20 0 0 0 2 2 2")
+	require.NoError(t, nfsclient.parseStat("/A", "1.2.3.4:/storage/NFS", "3", data, &acc))
+	require.Empty(t, latency(&acc))
+}
+
+func TestNFSClientInvalidLatencyBuckets(t *testing.T) {
+	nfsclient := NFSClient{
+		OperationLatency: true,
+		LatencyBuckets:   []float64{5, 0},
+		Log:              testutil.Logger{},
+	}
+	require.ErrorContains(t, nfsclient.Init(), "invalid latency bucket")
+}
@@ -43,10 +43,14 @@ const (
 	httpUpstreamsPath     = "http/upstreams"
 	httpCachesPath        = "http/caches"
 	httpLimitReqsPath     = "http/limit_reqs"
+	httpLimitConnsPath    = "http/limit_conns"
 	resolverZonesPath     = "resolvers"
 
 	streamServerZonesPath = "stream/server_zones"
 	streamUpstreamsPath   = "stream/upstreams"
+	streamLimitConnsPath  = "stream/limit_conns"
+
+	workersPath = "workers"
 )
 
 func (*NginxPlusAPI) SampleConfig() string {
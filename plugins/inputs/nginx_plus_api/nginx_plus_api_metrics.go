@@ -37,6 +37,11 @@ func (n *NginxPlusAPI) gatherMetrics(addr *url.URL, acc telegraf.Accumulator) {
 	}
 	if n.APIVersion >= 6 {
 		addError(acc, n.gatherHTTPLimitReqsMetrics(addr, acc))
+		addError(acc, n.gatherLimitConnsMetrics(addr, httpLimitConnsPath, "nginx_plus_api_http_limit_conns", acc))
+		addError(acc, n.gatherLimitConnsMetrics(addr, streamLimitConnsPath, "nginx_plus_api_stream_limit_conns", acc))
+	}
+	if n.APIVersion >= 9 {
+		addError(acc, n.gatherWorkersMetrics(addr, acc))
 	}
 }
 
@@ -199,19 +204,41 @@ func (n *NginxPlusAPI) gatherSslMetrics(addr *url.URL, acc telegraf.Accumulator)
 		return err
 	}
 
-	acc.AddFields(
-		"nginx_plus_api_ssl",
-		map[string]interface{}{
-			"handshakes":        ssl.Handshakes,
-			"handshakes_failed": ssl.HandshakesFailed,
-			"session_reuses":    ssl.SessionReuses,
-		},
-		getTags(addr),
-	)
+	fields := make(map[string]interface{})
+	addSslFields(fields, "", ssl)
+	acc.AddFields("nginx_plus_api_ssl", fields, getTags(addr))
 
 	return nil
 }
 
+// addSslFields adds the SSL statistics with the given prefix to the fields
+func addSslFields(fields map[string]interface{}, prefix string, ssl *Ssl) {
+	fields[prefix+"handshakes"] = ssl.Handshakes
+	fields[prefix+"handshakes_failed"] = ssl.HandshakesFailed
+	fields[prefix+"session_reuses"] = ssl.SessionReuses
+	if ssl.NoCommonProtocol != nil {
+		fields[prefix+"no_common_protocol"] = *ssl.NoCommonProtocol
+	}
+	if ssl.NoCommonCipher != nil {
+		fields[prefix+"no_common_cipher"] = *ssl.NoCommonCipher
+	}
+	if ssl.HandshakeTimeout != nil {
+		fields[prefix+"handshake_timeout"] = *ssl.HandshakeTimeout
+	}
+	if ssl.PeerRejectedCert != nil {
+		fields[prefix+"peer_rejected_cert"] = *ssl.PeerRejectedCert
+	}
+	if v := ssl.VerifyFailures; v != nil {
+		fields[prefix+"verify_failures_no_cert"] = v.NoCert
+		fields[prefix+"verify_failures_expired_cert"] = v.ExpiredCert
+		fields[prefix+"verify_failures_revoked_cert"] = v.RevokedCert
+		fields[prefix+"verify_failures_other"] = v.Other
+		if v.HostnameMismatch != nil {
+			fields[prefix+"verify_failures_hostname_mismatch"] = *v.HostnameMismatch
+		}
+	}
+}
+
 func (n *NginxPlusAPI) gatherHTTPRequestsMetrics(addr *url.URL, acc telegraf.Accumulator) error {
 	body, err := n.gatherURL(addr, httpRequestsPath)
 	if err != nil {
@@ -274,6 +301,9 @@ func (n *NginxPlusAPI) gatherHTTPServerZonesMetrics(addr *url.URL, acc telegraf.
 				if zone.Discarded != nil {
 					result["discarded"] = *zone.Discarded
 				}
+				if zone.Ssl != nil {
+					addSslFields(result, "ssl_", zone.Ssl)
+				}
 				return result
 			}(),
 			zoneTags,
@@ -400,6 +430,9 @@ func (n *NginxPlusAPI) gatherHTTPUpstreamsMetrics(addr *url.URL, acc telegraf.Ac
 			if peer.MaxConns != nil {
 				peerFields["max_conns"] = *peer.MaxConns
 			}
+			if peer.Ssl != nil {
+				addSslFields(peerFields, "ssl_", peer.Ssl)
+			}
 			peerTags := map[string]string{}
 			for k, v := range upstreamTags {
 				peerTags[k] = v
@@ -488,16 +521,16 @@ func (n *NginxPlusAPI) gatherStreamServerZonesMetrics(addr *url.URL, acc telegra
 			zoneTags[k] = v
 		}
 		zoneTags["zone"] = zoneName
-		acc.AddFields(
-			"nginx_plus_api_stream_server_zones",
-			map[string]interface{}{
-				"processing":  zone.Processing,
-				"connections": zone.Connections,
-				"received":    zone.Received,
-				"sent":        zone.Sent,
-			},
-			zoneTags,
-		)
+		zoneFields := map[string]interface{}{
+			"processing":  zone.Processing,
+			"connections": zone.Connections,
+			"received":    zone.Received,
+			"sent":        zone.Sent,
+		}
+		if zone.Ssl != nil {
+			addSslFields(zoneFields, "ssl_", zone.Ssl)
+		}
+		acc.AddFields("nginx_plus_api_stream_server_zones", zoneFields, zoneTags)
 	}
 
 	return nil
@@ -602,6 +635,9 @@ func (n *NginxPlusAPI) gatherStreamUpstreamsMetrics(addr *url.URL, acc telegraf.
 			if peer.ResponseTime != nil {
 				peerFields["response_time"] = *peer.ResponseTime
 			}
+			if peer.Ssl != nil {
+				addSslFields(peerFields, "ssl_", peer.Ssl)
+			}
 			peerTags := map[string]string{}
 			for k, v := range upstreamTags {
 				peerTags[k] = v
@@ -653,6 +689,80 @@ func (n *NginxPlusAPI) gatherHTTPLimitReqsMetrics(addr *url.URL, acc telegraf.Ac
 	return nil
 }
 
+// Added in 6 API version
+func (n *NginxPlusAPI) gatherLimitConnsMetrics(addr *url.URL, path, measurement string, acc telegraf.Accumulator) error {
+	body, err := n.gatherURL(addr, path)
+	if err != nil {
+		return err
+	}
+
+	var limitConns LimitConns
+
+	if err := json.Unmarshal(body, &limitConns); err != nil {
+		return err
+	}
+
+	tags := getTags(addr)
+
+	for limitConnName, limit := range limitConns {
+		limitConnsTags := map[string]string{}
+		for k, v := range tags {
+			limitConnsTags[k] = v
+		}
+		limitConnsTags["limit"] = limitConnName
+		acc.AddFields(
+			measurement,
+			map[string]interface{}{
+				"passed":           limit.Passed,
+				"rejected":         limit.Rejected,
+				"rejected_dry_run": limit.RejectedDryRun,
+			},
+			limitConnsTags,
+		)
+	}
+
+	return nil
+}
+
+// Added in 9 API version
+func (n *NginxPlusAPI) gatherWorkersMetrics(addr *url.URL, acc telegraf.Accumulator) error {
+	body, err := n.gatherURL(addr, workersPath)
+	if err != nil {
+		return err
+	}
+
+	var workers Workers
+
+	if err := json.Unmarshal(body, &workers); err != nil {
+		return err
+	}
+
+	tags := getTags(addr)
+
+	for _, worker := range workers {
+		workerTags := map[string]string{}
+		for k, v := range tags {
+			workerTags[k] = v
+		}
+		workerTags["id"] = strconv.Itoa(worker.ID)
+		acc.AddFields(
+			"nginx_plus_api_workers",
+			map[string]interface{}{
+				"pid":                   worker.Pid,
+				"connections_accepted":  worker.Connections.Accepted,
+				"connections_dropped":   worker.Connections.Dropped,
+				"connections_active":    worker.Connections.Active,
+				"connections_idle":      worker.Connections.Idle,
+				"http_requests_total":   worker.HTTP.Requests.Total,
+				"http_requests_current": worker.HTTP.Requests.Current,
+			},
+			workerTags,
+		)
+	}
+
+	return nil
+}
+
 func getTags(addr *url.URL) map[string]string {
 	h := addr.Host
 	host, port, err := net.SplitHostPort(h)
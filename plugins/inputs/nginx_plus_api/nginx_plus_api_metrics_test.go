@@ -164,6 +164,55 @@ const sslPayload = `
 }
 `
 
+const sslExtendedPayload = `
+{
+	"handshakes": 79572,
+	"handshakes_failed": 21025,
+	"session_reuses": 15762,
+	"no_common_protocol": 4,
+	"no_common_cipher": 2,
+	"handshake_timeout": 0,
+	"peer_rejected_cert": 0,
+	"verify_failures": {
+		"no_cert": 0,
+		"expired_cert": 2,
+		"revoked_cert": 1,
+		"other": 1
+	}
+}
+`
+
+const limitConnsPayload = `
+{
+	"addr": {
+		"passed": 515,
+		"rejected": 3,
+		"rejected_dry_run": 0
+	}
+}
+`
+
+const workersPayload = `
+[
+	{
+		"id": 0,
+		"pid": 32212,
+		"connections": {
+			"accepted": 1,
+			"dropped": 0,
+			"active": 1,
+			"idle": 0
+		},
+		"http": {
+			"requests": {
+				"total": 15,
+				"current": 1
+			}
+		}
+	}
+]
+`
+
 const resolverZonesPayload = `
 {
   "resolver_zone1": {
@@ -800,6 +849,37 @@ func TestGatherSslMetrics(t *testing.T) {
 		})
 }
 
+func TestGatherSslExtendedMetrics(t *testing.T) {
+	ts, n := prepareEndpoint(t, sslPath, sslExtendedPayload)
+	defer ts.Close()
+
+	var acc testutil.Accumulator
+	addr, host, port := prepareAddr(t, ts)
+
+	require.NoError(t, n.gatherSslMetrics(addr, &acc))
+
+	acc.AssertContainsTaggedFields(
+		t,
+		"nginx_plus_api_ssl",
+		map[string]interface{}{
+			"handshakes":                   int64(79572),
+			"handshakes_failed":            int64(21025),
+			"session_reuses":               int64(15762),
+			"no_common_protocol":           int64(4),
+			"no_common_cipher":             int64(2),
+			"handshake_timeout":            int64(0),
+			"peer_rejected_cert":           int64(0),
+			"verify_failures_no_cert":      int64(0),
+			"verify_failures_expired_cert": int64(2),
+			"verify_failures_revoked_cert": int64(1),
+			"verify_failures_other":        int64(1),
+		},
+		map[string]string{
+			"source": host,
+			"port":   port,
+		})
+}
+
 func TestGatherHttpRequestsMetrics(t *testing.T) {
 	ts, n := prepareEndpoint(t, httpRequestsPath, httpRequestsPayload)
 	defer ts.Close()
@@ -918,6 +998,69 @@ func TestGatherHttpLimitReqsMetrics(t *testing.T) {
 		})
 }
 
+func TestGatherLimitConnsMetrics(t *testing.T) {
+	tests := []struct {
+		path        string
+		measurement string
+	}{
+		{path: httpLimitConnsPath, measurement: "nginx_plus_api_http_limit_conns"},
+		{path: streamLimitConnsPath, measurement: "nginx_plus_api_stream_limit_conns"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ts, n := prepareEndpoint(t, tt.path, limitConnsPayload)
+			defer ts.Close()
+
+			var acc testutil.Accumulator
+			addr, host, port := prepareAddr(t, ts)
+
+			require.NoError(t, n.gatherLimitConnsMetrics(addr, tt.path, tt.measurement, &acc))
+
+			acc.AssertContainsTaggedFields(
+				t,
+				tt.measurement,
+				map[string]interface{}{
+					"passed":           int64(515),
+					"rejected":         int64(3),
+					"rejected_dry_run": int64(0),
+				},
+				map[string]string{
+					"source": host,
+					"port":   port,
+					"limit":  "addr",
+				})
+		})
+	}
+}
+
+func TestGatherWorkersMetrics(t *testing.T) {
+	ts, n := prepareEndpoint(t, workersPath, workersPayload)
+	defer ts.Close()
+
+	var acc testutil.Accumulator
+	addr, host, port := prepareAddr(t, ts)
+
+	require.NoError(t, n.gatherWorkersMetrics(addr, &acc))
+
+	acc.AssertContainsTaggedFields(
+		t,
+		"nginx_plus_api_workers",
+		map[string]interface{}{
+			"pid":                   32212,
+			"connections_accepted":  int64(1),
+			"connections_dropped":   int64(0),
+			"connections_active":    int64(1),
+			"connections_idle":      int64(0),
+			"http_requests_total":   int64(15),
+			"http_requests_current": int64(1),
+		},
+		map[string]string{
+			"source": host,
+			"port":   port,
+			"id":     "0",
+		})
+}
+
 func TestGatherHttpLocationZonesMetrics(t *testing.T) {
 	ts, n := prepareEndpoint(t, httpLocationZonesPath, httpLocationZonesPayload)
 	defer ts.Close()
@@ -25,9 +25,22 @@ type Slabs map[string]struct {
 }
 
 type Ssl struct { // added in version 6
-	Handshakes       int64 `json:"handshakes"`
-	HandshakesFailed int64 `json:"handshakes_failed"`
-	SessionReuses    int64 `json:"session_reuses"`
+	Handshakes       int64           `json:"handshakes"`
+	HandshakesFailed int64           `json:"handshakes_failed"`
+	SessionReuses    int64           `json:"session_reuses"`
+	NoCommonProtocol *int64          `json:"no_common_protocol"` // added in version 8
+	NoCommonCipher   *int64          `json:"no_common_cipher"`   // added in version 8
+	HandshakeTimeout *int64          `json:"handshake_timeout"`  // added in version 8
+	PeerRejectedCert *int64          `json:"peer_rejected_cert"` // added in version 8
+	VerifyFailures   *VerifyFailures `json:"verify_failures"`    // added in version 8
+}
+
+type VerifyFailures struct {
+	NoCert           int64  `json:"no_cert"`
+	ExpiredCert      int64  `json:"expired_cert"`
+	RevokedCert      int64  `json:"revoked_cert"`
+	HostnameMismatch *int64 `json:"hostname_mismatch"` // upstreams only
+	Other            int64  `json:"other"`
 }
 
 type ResolverZones map[string]struct {
@@ -69,6 +82,7 @@ type HTTPServerZones map[string]struct {
 	Discarded  *int64        `json:"discarded"` // added in version 6
 	Received   int64         `json:"received"`
 	Sent       int64         `json:"sent"`
+	Ssl        *Ssl          `json:"ssl"` // added in version 8
 }
 
 type HTTPLocationZones map[string]struct {
@@ -106,6 +120,7 @@ type HTTPUpstreams map[string]struct {
 		Downtime     int64            `json:"downtime"`
 		HeaderTime   *int64           `json:"header_time"`   // added in version 5
 		ResponseTime *int64           `json:"response_time"` // added in version 5
+		Ssl          *Ssl             `json:"ssl"`           // added in version 8
 	} `json:"peers"`
 	Keepalive int       `json:"keepalive"`
 	Zombies   int       `json:"zombies"` // added in version 6
@@ -123,6 +138,7 @@ type StreamServerZones map[string]struct {
 	Discarded   *int64         `json:"discarded"` // added in version 7
 	Received    int64          `json:"received"`
 	Sent        int64          `json:"sent"`
+	Ssl         *Ssl           `json:"ssl"` // added in version 8
 }
 
 type StreamUpstreams map[string]struct {
@@ -143,6 +159,7 @@ type StreamUpstreams map[string]struct {
 		Unavail       int64            `json:"unavail"`
 		HealthChecks  HealthCheckStats `json:"health_checks"`
 		Downtime      int64            `json:"downtime"`
+		Ssl           *Ssl             `json:"ssl"` // added in version 8
 	} `json:"peers"`
 	Zombies int `json:"zombies"`
 }
@@ -178,3 +195,19 @@ type HTTPLimitReqs map[string]struct {
 	DelayedDryRun  int64 `json:"delayed_dry_run"`
 	RejectedDryRun int64 `json:"rejected_dry_run"`
 }
+
+// LimitConns is used for both HTTP and stream zones
+type LimitConns map[string]struct { // added in version 6
+	Passed         int64 `json:"passed"`
+	Rejected       int64 `json:"rejected"`
+	RejectedDryRun int64 `json:"rejected_dry_run"`
+}
+
+type Workers []struct { // added in version 9
+	ID          int         `json:"id"`
+	Pid         int         `json:"pid"`
+	Connections Connections `json:"connections"`
+	HTTP        struct {
+		Requests HTTPRequests `json:"requests"`
+	} `json:"http"`
+}
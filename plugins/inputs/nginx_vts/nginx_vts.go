@@ -107,7 +107,11 @@ func (n *NginxVTS) gatherURL(addr *url.URL, acc telegraf.Accumulator) error {
 }
 
 type NginxVTSResponse struct {
-	Connections struct {
+	HostName     string `json:"hostName"`
+	NginxVersion string `json:"nginxVersion"`
+	LoadMsec     uint64 `json:"loadMsec"`
+	NowMsec      uint64 `json:"nowMsec"`
+	Connections  struct {
 		Active   uint64 `json:"active"`
 		Reading  uint64 `json:"reading"`
 		Writing  uint64 `json:"writing"`
@@ -120,6 +124,15 @@ type NginxVTSResponse struct {
 	FilterZones   map[string]map[string]Server `json:"filterZones"`
 	UpstreamZones map[string][]Upstream        `json:"upstreamZones"`
 	CacheZones    map[string]Cache             `json:"cacheZones"`
+	SharedZones   *SharedZone                  `json:"sharedZones"`
+}
+
+// SharedZone describes the shared memory zone used by the module itself
+type SharedZone struct {
+	Name     string `json:"name"`
+	MaxSize  uint64 `json:"maxSize"`
+	UsedSize uint64 `json:"usedSize"`
+	UsedNode uint64 `json:"usedNode"`
 }
 
 type Server struct {
@@ -199,6 +212,31 @@ func gatherStatusURL(r *bufio.Reader, tags map[string]string, acc telegraf.Accum
 		"requests": status.Connections.Requests,
 	}, tags)
 
+	if status.NginxVersion != "" {
+		infoFields := map[string]interface{}{
+			"host_name": status.HostName,
+			"version":   status.NginxVersion,
+		}
+		if status.NowMsec >= status.LoadMsec {
+			infoFields["uptime"] = (status.NowMsec - status.LoadMsec) / 1000
+		}
+		acc.AddFields("nginx_vts_info", infoFields, tags)
+	}
+
+	if zone := status.SharedZones; zone != nil {
+		zoneTags := map[string]string{}
+		for k, v := range tags {
+			zoneTags[k] = v
+		}
+		zoneTags["zone"] = zone.Name
+
+		acc.AddFields("nginx_vts_shared_zone", map[string]interface{}{
+			"max_bytes":  zone.MaxSize,
+			"used_bytes": zone.UsedSize,
+			"used_nodes": zone.UsedNode,
+		}, zoneTags)
+	}
+
 	for zoneName, zone := range status.ServerZones {
 		zoneTags := map[string]string{}
 		for k, v := range tags {
@@ -27,6 +27,12 @@ const sampleStatusResponse = `
         "handled": 666,
         "requests": 777
     },
+    "sharedZones": {
+        "name": "ngx_http_vhost_traffic_status",
+        "maxSize": 1048575,
+        "usedSize": 18016,
+        "usedNode": 3
+    },
     "serverZones": {
         "example.com": {
             "requestCounter": 1415887,
@@ -254,6 +260,33 @@ func TestNginxPlusGeneratesMetrics(t *testing.T) {
 			"port":   port,
 		})
 
+	acc.AssertContainsTaggedFields(
+		t,
+		"nginx_vts_info",
+		map[string]interface{}{
+			"host_name": "test.example.com",
+			"version":   "1.12.2",
+			"uptime":    uint64(75730),
+		},
+		map[string]string{
+			"source": host,
+			"port":   port,
+		})
+
+	acc.AssertContainsTaggedFields(
+		t,
+		"nginx_vts_shared_zone",
+		map[string]interface{}{
+			"max_bytes":  uint64(1048575),
+			"used_bytes": uint64(18016),
+			"used_nodes": uint64(3),
+		},
+		map[string]string{
+			"source": host,
+			"port":   port,
+			"zone":   "ngx_http_vhost_traffic_status",
+		})
+
 	acc.AssertContainsTaggedFields(
 		t,
 		"nginx_vts_server",
@@ -28,6 +28,7 @@ type OpenTelemetry struct {
 	ServiceAddress string   `toml:"service_address"`
 	SpanDimensions []string `toml:"span_dimensions"`
 	MetricsSchema  string   `toml:"metrics_schema"`
+	TLSIdentityTag string   `toml:"tls_identity_tag"`
 
 	tls.ServerConfig
 	Timeout config.Duration `toml:"timeout"`
@@ -60,7 +61,11 @@ func (o *OpenTelemetry) Start(accumulator telegraf.Accumulator) error {
 	}
 
 	logger := &otelLogger{o.Log}
-	influxWriter := &writeToAccumulator{accumulator}
+	influxWriter := &writeToAccumulator{
+		accumulator: accumulator,
+		identityTag: o.TLSIdentityTag,
+		identity:    o.ClientIdentity,
+	}
 	o.grpcServer = grpc.NewServer(grpcOptions...)
 
 	traceSvc, err := newTraceService(logger, influxWriter, o.SpanDimensions)
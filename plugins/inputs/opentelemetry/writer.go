@@ -2,11 +2,14 @@ package opentelemetry
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"time"
 
 	"github.com/influxdata/influxdb-observability/common"
 	"github.com/influxdata/influxdb-observability/otel2influx"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 
 	"github.com/influxdata/telegraf"
 )
@@ -18,6 +21,8 @@ var (
 
 type writeToAccumulator struct {
 	accumulator telegraf.Accumulator
+	identityTag string
+	identity    func(*tls.ConnectionState) string
 }
 
 func (w *writeToAccumulator) NewBatch() otel2influx.InfluxWriterBatch {
@@ -25,13 +30,25 @@ func (w *writeToAccumulator) NewBatch() otel2influx.InfluxWriterBatch {
 }
 
 func (w *writeToAccumulator) EnqueuePoint(
-	_ context.Context,
+	ctx context.Context,
 	measurement string,
 	tags map[string]string,
 	fields map[string]interface{},
 	ts time.Time,
 	vType common.InfluxMetricValueType,
 ) error {
+	if w.identityTag != "" {
+		if identity := w.clientIdentity(ctx); identity != "" {
+			// Do not modify the tags owned by the converter
+			withIdentity := make(map[string]string, len(tags)+1)
+			for k, v := range tags {
+				withIdentity[k] = v
+			}
+			withIdentity[w.identityTag] = identity
+			tags = withIdentity
+		}
+	}
+
 	switch vType {
 	case common.InfluxMetricValueTypeUntyped:
 		w.accumulator.AddFields(measurement, fields, tags, ts)
@@ -52,3 +69,17 @@ func (w *writeToAccumulator) EnqueuePoint(
 func (w *writeToAccumulator) WriteBatch(_ context.Context) error {
 	return nil
 }
+
+// clientIdentity returns the identity of the TLS client certificate of the
+// gRPC request
+func (w *writeToAccumulator) clientIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+	return w.identity(&info.State)
+}
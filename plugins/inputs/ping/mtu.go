@@ -0,0 +1,101 @@
+package ping
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	ping "github.com/prometheus-community/pro-bing"
+)
+
+// Minimum MTU of the IP versions and the size of the IP and ICMP headers
+// not part of the payload
+const (
+	minMTUv4     = 68
+	minMTUv6     = 1280
+	headerSizeV4 = 20 + 8
+	headerSizeV6 = 40 + 8
+)
+
+// nativeMTU discovers the path MTU to the destination by sending echo
+// requests of varying size with the do-not-fragment bit set
+func (p *Ping) nativeMTU(destination string) (int, error) {
+	network := "ip"
+	if p.IPv6 {
+		network = "ip6"
+	}
+	pinger, err := ping.NewPinger(destination)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create new pinger: %w", err)
+	}
+	pinger.SetNetwork(network)
+	if err := pinger.Resolve(); err != nil {
+		return 0, err
+	}
+
+	minMTU, headerSize := minMTUv4, headerSizeV4
+	if pinger.IPAddr().IP.To4() == nil {
+		minMTU, headerSize = minMTUv6, headerSizeV6
+	}
+
+	probe := func(mtu int) (bool, error) {
+		pinger, err := ping.NewPinger(destination)
+		if err != nil {
+			return false, fmt.Errorf("failed to create new pinger: %w", err)
+		}
+		pinger.SetPrivileged(true)
+		pinger.SetNetwork(network)
+		pinger.SetDoNotFragment(true)
+		pinger.Source = p.sourceAddress
+		pinger.Size = mtu - headerSize
+		pinger.Count = 1
+		pinger.Timeout = p.calcTimeout
+		if err := pinger.Run(); err != nil {
+			if errors.Is(err, syscall.EMSGSIZE) {
+				return false, nil
+			}
+			return false, err
+		}
+		return pinger.Statistics().PacketsRecv > 0, nil
+	}
+
+	return searchMTU(minMTU, p.MTUDiscoveryMax, probe)
+}
+
+// searchMTU returns the largest MTU between min and max for which the probe
+// succeeds using a binary search
+func searchMTU(lower, upper int, probe func(mtu int) (bool, error)) (int, error) {
+	if upper < lower {
+		return 0, fmt.Errorf("maximum MTU %d is below the minimum of %d", upper, lower)
+	}
+
+	ok, err := probe(upper)
+	if err != nil {
+		return 0, err
+	}
+	if ok {
+		return upper, nil
+	}
+	ok, err = probe(lower)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("no response for the minimum MTU of %d", lower)
+	}
+
+	// The probe succeeds for the lower and fails for the upper bound
+	for upper-lower > 1 {
+		mtu := lower + (upper-lower)/2
+		ok, err := probe(mtu)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			lower = mtu
+		} else {
+			upper = mtu
+		}
+	}
+	return lower, nil
+}
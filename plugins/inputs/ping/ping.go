@@ -18,6 +18,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/workerpool"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -34,12 +35,10 @@ const (
 type HostPinger func(binary string, timeout float64, args ...string) (string, error)
 
 type Ping struct {
-	// wg is used to wait for ping with multiple URLs
-	wg sync.WaitGroup
-
 	// Pre-calculated interval and timeout
-	calcInterval time.Duration
-	calcTimeout  time.Duration
+	calcInterval  time.Duration
+	calcIntervals map[string]time.Duration
+	calcTimeout   time.Duration
 
 	sourceAddress string
 
@@ -48,6 +47,9 @@ type Ping struct {
 	// Interval at which to ping (ping -i <INTERVAL>)
 	PingInterval float64 `toml:"ping_interval"`
 
+	// Intervals overriding the ping interval for specific urls
+	PingIntervals map[string]float64 `toml:"ping_intervals"`
+
 	// Number of pings to send (ping -c <COUNT>)
 	Count int
 
@@ -80,12 +82,28 @@ type Ping struct {
 	pingHost HostPinger
 
 	nativePingFunc NativePingFunc
+	nativeMTUFunc  func(destination string) (int, error)
 
 	// Calculate the given percentiles when using native method
 	Percentiles []int
 
 	// Packet size
 	Size *int
+
+	// Maximum number of hosts to ping concurrently, 0 means all hosts
+	Concurrency int `toml:"concurrency"`
+
+	// Emit a metric for each probe in addition to the statistics when
+	// using the native method
+	PerProbe bool `toml:"per_probe"`
+
+	// Discover the path MTU using probes with the do-not-fragment bit set
+	// when using the native method
+	MTUDiscovery    bool `toml:"mtu_discovery"`
+	MTUDiscoveryMax int  `toml:"mtu_discovery_max"`
+
+	// IPv6 flow label of the packets (ping -F <FLOWLABEL>)
+	FlowLabel int `toml:"flow_label"`
 }
 
 func (*Ping) SampleConfig() string {
@@ -93,28 +111,32 @@ func (*Ping) SampleConfig() string {
 }
 
 func (p *Ping) Gather(acc telegraf.Accumulator) error {
-	for _, host := range p.Urls {
-		p.wg.Add(1)
-		go func(host string) {
-			defer p.wg.Done()
-
-			switch p.Method {
-			case "native":
-				p.pingToURLNative(host, acc)
-			default:
-				p.pingToURL(host, acc)
-			}
-		}(host)
-	}
-
-	p.wg.Wait()
+	workerpool.Run(p.Concurrency, p.Urls, func(host string) {
+		switch p.Method {
+		case "native":
+			p.pingToURLNative(host, acc)
+		default:
+			p.pingToURL(host, acc)
+		}
+	})
 
 	return nil
 }
 
 type pingStats struct {
 	ping.Statistics
-	ttl int
+	ttl    int
+	probes []*probe
+}
+
+// probe is the record of a single echo request
+type probe struct {
+	seq      int
+	sent     time.Time
+	size     int
+	received bool
+	rtt      time.Duration
+	ttl      int
 }
 
 type NativePingFunc func(destination string) (*pingStats, error)
@@ -141,7 +163,7 @@ func (p *Ping) nativePing(destination string) (*pingStats, error) {
 	}
 
 	pinger.Source = p.sourceAddress
-	pinger.Interval = p.calcInterval
+	pinger.Interval = p.interval(destination)
 
 	if p.Deadline > 0 {
 		pinger.Timeout = time.Duration(p.Deadline) * time.Second
@@ -155,6 +177,26 @@ func (p *Ping) nativePing(destination string) (*pingStats, error) {
 		})
 	}
 
+	if p.PerProbe {
+		// The callbacks are called from the same goroutine of the pinger
+		probes := make(map[int]*probe)
+		pinger.OnSend = func(pkt *ping.Packet) {
+			pr := &probe{seq: pkt.Seq, sent: time.Now(), size: pkt.Nbytes}
+			probes[pkt.Seq] = pr
+			ps.probes = append(ps.probes, pr)
+		}
+		pinger.OnRecv = func(pkt *ping.Packet) {
+			once.Do(func() {
+				ps.ttl = pkt.TTL
+			})
+			if pr, found := probes[pkt.Seq]; found {
+				pr.received = true
+				pr.rtt = pkt.Rtt
+				pr.ttl = pkt.TTL
+			}
+		}
+	}
+
 	pinger.Count = p.Count
 	err = pinger.Run()
 	if err != nil {
@@ -177,6 +219,15 @@ func (p *Ping) pingToURLNative(destination string, acc telegraf.Accumulator) {
 	tags := map[string]string{"url": destination}
 	fields := map[string]interface{}{}
 
+	if p.MTUDiscovery {
+		mtu, err := p.nativeMTUFunc(destination)
+		if err != nil {
+			p.Log.Errorf("MTU discovery for %q failed: %v", destination, err)
+		} else {
+			fields["path_mtu"] = mtu
+		}
+	}
+
 	stats, err := p.nativePingFunc(destination)
 	if err != nil {
 		p.Log.Errorf("ping failed: %s", err.Error())
@@ -189,11 +240,10 @@ func (p *Ping) pingToURLNative(destination string, acc telegraf.Accumulator) {
 		return
 	}
 
-	fields = map[string]interface{}{
-		"result_code":         0,
-		"packets_transmitted": stats.PacketsSent,
-		"packets_received":    stats.PacketsRecv,
-	}
+	fields["result_code"] = 0
+	fields["packets_transmitted"] = stats.PacketsSent
+	fields["packets_received"] = stats.PacketsRecv
+	p.addProbes(destination, stats.probes, acc)
 
 	if stats.PacketsSent == 0 {
 		p.Log.Debug("no packets sent")
@@ -233,6 +283,34 @@ func (p *Ping) pingToURLNative(destination string, acc telegraf.Accumulator) {
 	acc.AddFields("ping", fields, tags)
 }
 
+// addProbes emits a metric for each probe sent
+func (p *Ping) addProbes(destination string, probes []*probe, acc telegraf.Accumulator) {
+	tags := map[string]string{"url": destination}
+	for _, pr := range probes {
+		fields := map[string]interface{}{
+			"icmp_seq": pr.seq,
+			"size":     pr.size,
+			"received": pr.received,
+		}
+		if pr.received {
+			fields["response_ms"] = float64(pr.rtt) / float64(time.Millisecond)
+			switch runtime.GOOS {
+			case "aix", "darwin", "dragonfly", "freebsd", "linux", "netbsd", "openbsd", "solaris":
+				fields["ttl"] = pr.ttl
+			}
+		}
+		acc.AddFields("ping_probe", fields, tags, pr.sent)
+	}
+}
+
+// interval returns the interval between the packets sent to the url
+func (p *Ping) interval(url string) time.Duration {
+	if interval, found := p.calcIntervals[url]; found {
+		return interval
+	}
+	return p.calcInterval
+}
+
 type durationSlice []time.Duration
 
 func (p durationSlice) Len() int           { return len(p) }
@@ -273,10 +351,28 @@ func (p *Ping) Init() error {
 	}
 
 	// The interval cannot be below 0.2 seconds, matching ping implementation: https://linux.die.net/man/8/ping
-	if p.PingInterval < 0.2 {
-		p.calcInterval = time.Duration(.2 * float64(time.Second))
-	} else {
-		p.calcInterval = time.Duration(p.PingInterval * float64(time.Second))
+	p.calcInterval = clampInterval(p.PingInterval)
+	p.calcIntervals = make(map[string]time.Duration, len(p.PingIntervals))
+	for url, interval := range p.PingIntervals {
+		p.calcIntervals[url] = clampInterval(interval)
+	}
+
+	if p.Method == "native" && p.FlowLabel != 0 {
+		return errors.New("'flow_label' is not supported with the native method")
+	}
+	if p.FlowLabel < 0 || p.FlowLabel > 0xfffff {
+		return errors.New("'flow_label' must be a 20-bit value")
+	}
+	if p.MTUDiscovery {
+		if p.Method != "native" {
+			return errors.New("'mtu_discovery' is only supported with the native method")
+		}
+		if p.MTUDiscoveryMax == 0 {
+			p.MTUDiscoveryMax = 1500
+		}
+		if p.MTUDiscoveryMax < minMTUv4 {
+			return fmt.Errorf("'mtu_discovery_max' must be at least %d", minMTUv4)
+		}
 	}
 
 	// If no timeout is given default to 5 seconds, matching original implementation
@@ -306,6 +402,13 @@ func (p *Ping) Init() error {
 	return nil
 }
 
+func clampInterval(interval float64) time.Duration {
+	if interval < 0.2 {
+		return time.Duration(.2 * float64(time.Second))
+	}
+	return time.Duration(interval * float64(time.Second))
+}
+
 func hostPinger(binary string, timeout float64, args ...string) (string, error) {
 	bin, err := exec.LookPath(binary)
 	if err != nil {
@@ -331,6 +434,7 @@ func init() {
 			Percentiles:  []int{},
 		}
 		p.nativePingFunc = p.nativePing
+		p.nativeMTUFunc = p.nativeMTU
 		return p
 	})
 }
@@ -112,8 +112,15 @@ func (p *Ping) args(url string, system string) []string {
 
 	// build the ping command args based on toml config
 	args := []string{"-c", strconv.Itoa(p.Count), "-n", "-s", "16"}
-	if p.PingInterval > 0 {
-		args = append(args, "-i", strconv.FormatFloat(p.PingInterval, 'f', -1, 64))
+	interval := p.PingInterval
+	if v, found := p.PingIntervals[url]; found {
+		interval = v
+	}
+	if interval > 0 {
+		args = append(args, "-i", strconv.FormatFloat(interval, 'f', -1, 64))
+	}
+	if p.FlowLabel > 0 && system == "linux" {
+		args = append(args, "-F", strconv.FormatInt(int64(p.FlowLabel), 16))
 	}
 	if p.Timeout > 0 {
 		switch system {
@@ -13,6 +13,8 @@ import (
 	ping "github.com/prometheus-community/pro-bing"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -211,6 +213,29 @@ func TestArgs6(t *testing.T) {
 	}
 }
 
+func TestArgsPerURL(t *testing.T) {
+	p := Ping{
+		Count:         2,
+		PingInterval:  1.2,
+		PingIntervals: map[string]float64{"10.0.0.1": 0.5},
+		FlowLabel:     0xbeef,
+	}
+
+	require.Equal(t,
+		[]string{"-c", "2", "-n", "-s", "16", "-i", "0.5", "-F", "beef", "10.0.0.1"},
+		p.args("10.0.0.1", "linux"),
+	)
+	require.Equal(t,
+		[]string{"-c", "2", "-n", "-s", "16", "-i", "1.2", "-F", "beef", "10.0.0.2"},
+		p.args("10.0.0.2", "linux"),
+	)
+	// Flow labels are only supported by the Linux ping
+	require.Equal(t,
+		[]string{"-c", "2", "-n", "-s", "16", "-i", "1.2", "10.0.0.2"},
+		p.args("10.0.0.2", "darwin"),
+	)
+}
+
 func TestArguments(t *testing.T) {
 	arguments := []string{"-c", "3"}
 	expected := append(arguments, "www.google.com")
@@ -484,6 +509,162 @@ func TestPingGatherNative(t *testing.T) {
 	}
 }
 
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Ping
+		expected string
+	}{
+		{
+			name:     "flow label with native method",
+			plugin:   &Ping{Count: 1, Method: "native", FlowLabel: 1},
+			expected: "'flow_label' is not supported with the native method",
+		},
+		{
+			name:     "flow label out of range",
+			plugin:   &Ping{Count: 1, Method: "exec", FlowLabel: 0x100000},
+			expected: "'flow_label' must be a 20-bit value",
+		},
+		{
+			name:     "mtu discovery with exec method",
+			plugin:   &Ping{Count: 1, Method: "exec", MTUDiscovery: true},
+			expected: "'mtu_discovery' is only supported with the native method",
+		},
+		{
+			name:     "mtu discovery maximum too small",
+			plugin:   &Ping{Count: 1, Method: "native", MTUDiscovery: true, MTUDiscoveryMax: 60},
+			expected: "'mtu_discovery_max' must be at least 68",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPingIntervals(t *testing.T) {
+	p := &Ping{
+		Count:         1,
+		Method:        "native",
+		PingInterval:  1,
+		PingIntervals: map[string]float64{"10.0.0.1": 0.1, "10.0.0.2": 2.5},
+	}
+	require.NoError(t, p.Init())
+	require.Equal(t, 200*time.Millisecond, p.interval("10.0.0.1"))
+	require.Equal(t, 2500*time.Millisecond, p.interval("10.0.0.2"))
+	require.Equal(t, time.Second, p.interval("10.0.0.3"))
+}
+
+func TestPerProbe(t *testing.T) {
+	start := time.Unix(1677628800, 0)
+	p := &Ping{
+		Log:      testutil.Logger{},
+		Urls:     []string{"localhost"},
+		Method:   "native",
+		Count:    2,
+		PerProbe: true,
+		nativePingFunc: func(destination string) (*pingStats, error) {
+			return &pingStats{
+				Statistics: ping.Statistics{
+					PacketsSent: 2,
+					PacketsRecv: 1,
+					PacketLoss:  50,
+					Rtts:        []time.Duration{3 * time.Millisecond},
+				},
+				ttl: 64,
+				probes: []*probe{
+					{seq: 0, sent: start, size: 64, received: true, rtt: 3 * time.Millisecond, ttl: 64},
+					{seq: 1, sent: start.Add(time.Second), size: 64},
+				},
+			}, nil
+		},
+	}
+	require.NoError(t, p.Init())
+
+	var acc testutil.Accumulator
+	p.pingToURLNative("localhost", &acc)
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"ping_probe",
+			map[string]string{"url": "localhost"},
+			map[string]interface{}{
+				"icmp_seq":    0,
+				"size":        64,
+				"received":    true,
+				"response_ms": 3.0,
+				"ttl":         64,
+			},
+			start,
+		),
+		metric.New(
+			"ping_probe",
+			map[string]string{"url": "localhost"},
+			map[string]interface{}{
+				"icmp_seq": 1,
+				"size":     64,
+				"received": false,
+			},
+			start.Add(time.Second),
+		),
+	}
+	var actual []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if m.Name() == "ping_probe" {
+			actual = append(actual, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+	require.True(t, acc.HasPoint("ping", map[string]string{"url": "localhost"}, "packets_received", 1))
+}
+
+func TestMTUDiscovery(t *testing.T) {
+	p := &Ping{
+		Log:          testutil.Logger{},
+		Urls:         []string{"localhost"},
+		Method:       "native",
+		Count:        1,
+		MTUDiscovery: true,
+		nativePingFunc: func(destination string) (*pingStats, error) {
+			return &pingStats{
+				Statistics: ping.Statistics{PacketsSent: 1, PacketsRecv: 1, Rtts: []time.Duration{time.Millisecond}},
+			}, nil
+		},
+		nativeMTUFunc: func(destination string) (int, error) {
+			return 1400, nil
+		},
+	}
+	require.NoError(t, p.Init())
+	require.Equal(t, 1500, p.MTUDiscoveryMax)
+
+	var acc testutil.Accumulator
+	p.pingToURLNative("localhost", &acc)
+	require.True(t, acc.HasPoint("ping", map[string]string{"url": "localhost"}, "path_mtu", 1400))
+	require.True(t, acc.HasPoint("ping", map[string]string{"url": "localhost"}, "result_code", 0))
+}
+
+func TestSearchMTU(t *testing.T) {
+	for _, pathMTU := range []int{68, 69, 576, 1280, 1499, 1500} {
+		var probes int
+		actual, err := searchMTU(68, 1500, func(mtu int) (bool, error) {
+			probes++
+			return mtu <= pathMTU, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, pathMTU, actual)
+		require.LessOrEqual(t, probes, 13)
+	}
+
+	_, err := searchMTU(68, 1500, func(int) (bool, error) { return false, nil })
+	require.ErrorContains(t, err, "no response for the minimum MTU of 68")
+
+	_, err = searchMTU(68, 1500, func(int) (bool, error) { return false, errors.New("denied") })
+	require.ErrorContains(t, err, "denied")
+}
+
 func TestNoPacketsSent(t *testing.T) {
 	p := &Ping{
 		Log:         testutil.Logger{},
@@ -77,7 +77,8 @@ type Prometheus struct {
 
 	ResponseTimeout config.Duration `toml:"response_timeout" deprecated:"1.26.0;use 'timeout' instead"`
 
-	MetricVersion int `toml:"metric_version"`
+	MetricVersion    int  `toml:"metric_version"`
+	NativeHistograms bool `toml:"native_histograms"`
 
 	URLTag string `toml:"url_tag"`
 
@@ -162,6 +163,10 @@ func (p *Prometheus) Init() error {
 		p.Log.Infof("Using pod scrape scope at node level to get pod list using cAdvisor.")
 	}
 
+	if p.NativeHistograms && p.MetricVersion != 2 {
+		return errors.New("native_histograms requires metric_version = 2")
+	}
+
 	if p.MonitorKubernetesPodsMethod == MonitorMethodNone {
 		p.MonitorKubernetesPodsMethod = MonitorMethodAnnotations
 	}
@@ -440,8 +445,9 @@ func (p *Prometheus) gatherURL(u URLAndAddress, acc telegraf.Accumulator) error
 
 	if p.MetricVersion == 2 {
 		parser := parserV2.Parser{
-			Header:          resp.Header,
-			IgnoreTimestamp: p.IgnoreTimestamp,
+			Header:           resp.Header,
+			IgnoreTimestamp:  p.IgnoreTimestamp,
+			NativeHistograms: p.NativeHistograms,
 		}
 		metrics, err = parser.Parse(body)
 	} else {
@@ -0,0 +1,438 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package prometheus_pushgateway
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/subtle"
+	"crypto/tls"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// defaultMaxBodySize is the default maximum request body size, in bytes.
+// 500 MB
+const defaultMaxBodySize = 500 * 1024 * 1024
+
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// group holds the metric families pushed with the same grouping labels
+type group struct {
+	labels          map[string]string
+	families        map[string][]telegraf.Metric
+	pushTime        time.Time
+	pushFailureTime time.Time
+}
+
+type Pushgateway struct {
+	ServiceAddress string          `toml:"service_address"`
+	PathPrefix     string          `toml:"path_prefix"`
+	ReadTimeout    config.Duration `toml:"read_timeout"`
+	WriteTimeout   config.Duration `toml:"write_timeout"`
+	MaxBodySize    config.Size     `toml:"max_body_size"`
+	BasicUsername  config.Secret   `toml:"basic_username"`
+	BasicPassword  config.Secret   `toml:"basic_password"`
+	Expiration     config.Duration `toml:"expiration"`
+	Log            telegraf.Logger `toml:"-"`
+	tlsint.ServerConfig
+
+	tlsConf  *tls.Config
+	listener net.Listener
+	server   *http.Server
+	wg       sync.WaitGroup
+
+	groups map[string]*group
+	sync.Mutex
+}
+
+func (*Pushgateway) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Pushgateway) Init() error {
+	if p.MaxBodySize == 0 {
+		p.MaxBodySize = config.Size(defaultMaxBodySize)
+	}
+	if p.ReadTimeout < config.Duration(time.Second) {
+		p.ReadTimeout = config.Duration(time.Second * 10)
+	}
+	if p.WriteTimeout < config.Duration(time.Second) {
+		p.WriteTimeout = config.Duration(time.Second * 10)
+	}
+	p.PathPrefix = strings.TrimSuffix(p.PathPrefix, "/")
+	if p.PathPrefix != "" && !strings.HasPrefix(p.PathPrefix, "/") {
+		return fmt.Errorf("path prefix %q must start with a slash", p.PathPrefix)
+	}
+	if p.BasicUsername.Empty() != p.BasicPassword.Empty() {
+		return errors.New("both basic_username and basic_password must be set")
+	}
+
+	tlsConf, err := p.ServerConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	p.tlsConf = tlsConf
+	p.groups = make(map[string]*group)
+
+	return nil
+}
+
+func (p *Pushgateway) Start(_ telegraf.Accumulator) error {
+	var err error
+	if p.tlsConf != nil {
+		p.listener, err = tls.Listen("tcp", p.ServiceAddress, p.tlsConf)
+	} else {
+		p.listener, err = net.Listen("tcp", p.ServiceAddress)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.server = &http.Server{
+		Addr:         p.ServiceAddress,
+		Handler:      p,
+		ReadTimeout:  time.Duration(p.ReadTimeout),
+		WriteTimeout: time.Duration(p.WriteTimeout),
+		TLSConfig:    p.tlsConf,
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := p.server.Serve(p.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			p.Log.Errorf("Serve failed: %v", err)
+		}
+	}()
+
+	p.Log.Infof("Listening on %s", p.listener.Addr().String())
+
+	return nil
+}
+
+func (p *Pushgateway) Stop() {
+	if p.server != nil {
+		p.server.Close()
+	}
+	p.wg.Wait()
+}
+
+// Gather emits the metrics of all groups in the same way the metrics are
+// exposed by the Pushgateway on each scrape
+func (p *Pushgateway) Gather(acc telegraf.Accumulator) error {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+	for key, g := range p.groups {
+		if p.Expiration > 0 && now.Sub(g.pushTime) > time.Duration(p.Expiration) {
+			p.Log.Debugf("Removing expired group %v", g.labels)
+			delete(p.groups, key)
+			continue
+		}
+
+		for _, family := range g.families {
+			for _, m := range family {
+				m := m.Copy()
+				m.SetTime(now)
+				acc.AddMetric(m)
+			}
+		}
+
+		var pushFailureTime float64
+		if !g.pushFailureTime.IsZero() {
+			pushFailureTime = float64(g.pushFailureTime.UnixNano()) / 1e9
+		}
+		fields := map[string]interface{}{
+			"push_time_seconds":         float64(g.pushTime.UnixNano()) / 1e9,
+			"push_failure_time_seconds": pushFailureTime,
+		}
+		acc.AddMetric(metric.New("prometheus", g.labels, fields, now, telegraf.Gauge))
+	}
+
+	return nil
+}
+
+func (p *Pushgateway) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	if !p.authenticate(req) {
+		http.Error(res, "Unauthorized.", http.StatusUnauthorized)
+		return
+	}
+
+	path, found := strings.CutPrefix(req.URL.EscapedPath(), p.PathPrefix)
+	if !found {
+		http.NotFound(res, req)
+		return
+	}
+
+	switch {
+	case path == "/-/healthy" || path == "/-/ready":
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		res.WriteHeader(http.StatusOK)
+	case path == "/api/v1/admin/wipe":
+		if req.Method != http.MethodPut {
+			http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		p.Lock()
+		p.groups = make(map[string]*group)
+		p.Unlock()
+		res.WriteHeader(http.StatusAccepted)
+	case strings.HasPrefix(path, "/metrics/"):
+		p.serveMetrics(res, req, strings.TrimPrefix(path, "/metrics/"))
+	default:
+		http.NotFound(res, req)
+	}
+}
+
+func (p *Pushgateway) authenticate(req *http.Request) bool {
+	if p.BasicUsername.Empty() {
+		return true
+	}
+
+	username, err := p.BasicUsername.Get()
+	if err != nil {
+		p.Log.Errorf("Getting username failed: %v", err)
+		return false
+	}
+	defer config.ReleaseSecret(username)
+	password, err := p.BasicPassword.Get()
+	if err != nil {
+		p.Log.Errorf("Getting password failed: %v", err)
+		return false
+	}
+	defer config.ReleaseSecret(password)
+
+	reqUsername, reqPassword, ok := req.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(reqUsername), username) == 1 &&
+		subtle.ConstantTimeCompare([]byte(reqPassword), password) == 1
+}
+
+func (p *Pushgateway) serveMetrics(res http.ResponseWriter, req *http.Request, path string) {
+	labels, err := parseGroupingKey(path)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	key := groupKey(labels)
+
+	switch req.Method {
+	case http.MethodDelete:
+		p.Lock()
+		delete(p.groups, key)
+		p.Unlock()
+		res.WriteHeader(http.StatusAccepted)
+		return
+	case http.MethodPut, http.MethodPost:
+	default:
+		http.Error(res, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	families, err := p.parse(res, req, labels)
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		p.Log.Debugf("Rejecting push to group %v: %v", labels, err)
+		p.Lock()
+		if g, found := p.groups[key]; found {
+			g.pushFailureTime = time.Now()
+		}
+		p.Unlock()
+		http.Error(res, err.Error(), status)
+		return
+	}
+
+	p.Lock()
+	g, found := p.groups[key]
+	if !found || req.Method == http.MethodPut {
+		// PUT replaces all metrics of the group while POST only replaces
+		// the metrics with the same name
+		replaced := &group{
+			labels:   labels,
+			families: make(map[string][]telegraf.Metric),
+		}
+		if found {
+			replaced.pushFailureTime = g.pushFailureTime
+		}
+		g = replaced
+		p.groups[key] = g
+	}
+	for name, metrics := range families {
+		g.families[name] = metrics
+	}
+	g.pushTime = time.Now()
+	p.Unlock()
+
+	res.WriteHeader(http.StatusOK)
+}
+
+// parse reads the metric families of the request body in text or protobuf
+// format and converts them to metrics tagged with the grouping labels
+func (p *Pushgateway) parse(res http.ResponseWriter, req *http.Request, labels map[string]string) (map[string][]telegraf.Metric, error) {
+	reader := io.Reader(http.MaxBytesReader(res, req.Body, int64(p.MaxBodySize)))
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		r, err := gzip.NewReader(reader)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		reader = r
+	}
+
+	var families []*dto.MetricFamily
+	mediatype, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err == nil && mediatype == "application/vnd.google.protobuf" &&
+		params["encoding"] == "delimited" &&
+		params["proto"] == "io.prometheus.client.MetricFamily" {
+		r := bufio.NewReader(reader)
+		for {
+			mf := &dto.MetricFamily{}
+			if _, err := pbutil.ReadDelimited(r, mf); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("reading metric family protocol buffer failed: %w", err)
+			}
+			families = append(families, mf)
+		}
+	} else {
+		var parser expfmt.TextParser
+		parsed, err := parser.TextToMetricFamilies(reader)
+		if err != nil {
+			return nil, fmt.Errorf("reading text format failed: %w", err)
+		}
+		for _, mf := range parsed {
+			families = append(families, mf)
+		}
+	}
+
+	parser := &prometheus.Parser{
+		DefaultTags:     labels,
+		IgnoreTimestamp: true,
+	}
+	result := make(map[string][]telegraf.Metric, len(families))
+	for _, mf := range families {
+		// Labels of the grouping key must not be overridden by the metrics
+		for _, m := range mf.Metric {
+			for _, lp := range m.Label {
+				if v, found := labels[lp.GetName()]; found && v != lp.GetValue() {
+					return nil, fmt.Errorf("label %q of metric %q conflicts with grouping key", lp.GetName(), mf.GetName())
+				}
+			}
+		}
+
+		var buf bytes.Buffer
+		if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+			return nil, fmt.Errorf("converting metric family %q failed: %w", mf.GetName(), err)
+		}
+		metrics, err := parser.Parse(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("parsing metric family %q failed: %w", mf.GetName(), err)
+		}
+		result[mf.GetName()] = metrics
+	}
+	return result, nil
+}
+
+// parseGroupingKey parses the grouping labels from a path of the form
+// job/<JOB>{/<LABEL_NAME>/<LABEL_VALUE>}. Label names suffixed with @base64
+// have base64url encoded values.
+func parseGroupingKey(path string) (map[string]string, error) {
+	parts := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf("odd number of components in grouping key %q", path)
+	}
+
+	labels := make(map[string]string, len(parts)/2)
+	for i := 0; i < len(parts); i += 2 {
+		name, value := parts[i], parts[i+1]
+		name, encoded := strings.CutSuffix(name, "@base64")
+		if !labelNameRe.MatchString(name) || strings.HasPrefix(name, "__") {
+			return nil, fmt.Errorf("invalid label name %q", name)
+		}
+		if encoded {
+			decoded, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid base64 encoding of label %q: %w", name, err)
+			}
+			value = string(decoded)
+		} else {
+			unescaped, err := url.PathUnescape(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of label %q: %w", name, err)
+			}
+			value = unescaped
+		}
+		if i == 0 && name != "job" {
+			return nil, errors.New("grouping key must start with the job label")
+		}
+		if _, found := labels[name]; found {
+			return nil, fmt.Errorf("duplicate label %q in grouping key", name)
+		}
+		labels[name] = value
+	}
+	if labels["job"] == "" {
+		return nil, errors.New("job name is required")
+	}
+	return labels, nil
+}
+
+// groupKey returns a unique identifier of the grouping labels
+func groupKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var key strings.Builder
+	for _, name := range names {
+		key.WriteString(name)
+		key.WriteByte(0)
+		key.WriteString(labels[name])
+		key.WriteByte(0)
+	}
+	return key.String()
+}
+
+func init() {
+	inputs.Add("prometheus_pushgateway", func() telegraf.Input {
+		return &Pushgateway{
+			ServiceAddress: ":9091",
+		}
+	})
+}
@@ -0,0 +1,327 @@
+package prometheus_pushgateway
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const jobMetrics = `# TYPE batch_duration_seconds gauge
+batch_duration_seconds 12.5
+# TYPE batch_records_total counter
+batch_records_total{table="users"} 100
+`
+
+func newTestPushgateway(t *testing.T) (*Pushgateway, string) {
+	plugin := &Pushgateway{
+		ServiceAddress: "127.0.0.1:0",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	t.Cleanup(plugin.Stop)
+
+	return plugin, "http://" + plugin.listener.Addr().String()
+}
+
+func push(t *testing.T, method, url, contentType, body string) int {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// gatherJobMetrics returns the pushed metrics without the push time metrics
+func gatherJobMetrics(t *testing.T, plugin *Pushgateway) []telegraf.Metric {
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	var metrics []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		if !m.HasField("push_time_seconds") {
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics
+}
+
+func TestPushAndGather(t *testing.T) {
+	plugin, url := newTestPushgateway(t)
+
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/metrics/job/backup/instance/db1", "", jobMetrics))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"prometheus",
+			map[string]string{"job": "backup", "instance": "db1"},
+			map[string]interface{}{"batch_duration_seconds": 12.5},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+		metric.New(
+			"prometheus",
+			map[string]string{"job": "backup", "instance": "db1", "table": "users"},
+			map[string]interface{}{"batch_records_total": float64(100)},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, gatherJobMetrics(t, plugin), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// The metrics are kept for every gather
+	testutil.RequireMetricsEqual(t, expected, gatherJobMetrics(t, plugin), testutil.IgnoreTime(), testutil.SortMetrics())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	var found bool
+	for _, m := range acc.GetTelegrafMetrics() {
+		if v, ok := m.GetField("push_time_seconds"); ok {
+			found = true
+			require.Equal(t, map[string]string{"job": "backup", "instance": "db1"}, m.Tags())
+			require.InDelta(t, float64(time.Now().Unix()), v, 60)
+			failure, ok := m.GetField("push_failure_time_seconds")
+			require.True(t, ok)
+			require.Zero(t, failure)
+		}
+	}
+	require.True(t, found, "push time metric missing")
+}
+
+func TestPushReplace(t *testing.T) {
+	plugin, url := newTestPushgateway(t)
+
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/metrics/job/backup", "", jobMetrics))
+	require.Len(t, gatherJobMetrics(t, plugin), 2)
+
+	// POST only replaces the metrics with the same name
+	body := "# TYPE batch_duration_seconds gauge\nbatch_duration_seconds 3\n"
+	require.Equal(t, http.StatusOK, push(t, http.MethodPost, url+"/metrics/job/backup", "", body))
+	metrics := gatherJobMetrics(t, plugin)
+	require.Len(t, metrics, 2)
+	for _, m := range metrics {
+		if v, ok := m.GetField("batch_duration_seconds"); ok {
+			require.Equal(t, float64(3), v)
+		}
+	}
+
+	// PUT replaces all metrics of the group
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/metrics/job/backup", "", body))
+	metrics = gatherJobMetrics(t, plugin)
+	require.Len(t, metrics, 1)
+	require.True(t, metrics[0].HasField("batch_duration_seconds"))
+
+	// Other groups are not affected
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/metrics/job/restore", "", jobMetrics))
+	require.Len(t, gatherJobMetrics(t, plugin), 3)
+
+	// Deleting removes the whole group
+	require.Equal(t, http.StatusAccepted, push(t, http.MethodDelete, url+"/metrics/job/backup", "", ""))
+	metrics = gatherJobMetrics(t, plugin)
+	require.Len(t, metrics, 2)
+	for _, m := range metrics {
+		require.Equal(t, "restore", m.Tags()["job"])
+	}
+
+	require.Equal(t, http.StatusAccepted, push(t, http.MethodPut, url+"/api/v1/admin/wipe", "", ""))
+	require.Empty(t, gatherJobMetrics(t, plugin))
+}
+
+func TestPushProtobuf(t *testing.T) {
+	plugin, url := newTestPushgateway(t)
+
+	var buf bytes.Buffer
+	mf := &dto.MetricFamily{
+		Name: proto.String("batch_duration_seconds"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{
+			{Gauge: &dto.Gauge{Value: proto.Float64(42)}},
+		},
+	}
+	_, err := pbutil.WriteDelimited(&buf, mf)
+	require.NoError(t, err)
+
+	contentType := "application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited"
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/metrics/job/backup", contentType, buf.String()))
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"prometheus",
+			map[string]string{"job": "backup"},
+			map[string]interface{}{"batch_duration_seconds": float64(42)},
+			time.Unix(0, 0),
+			telegraf.Gauge,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, gatherJobMetrics(t, plugin), testutil.IgnoreTime())
+}
+
+func TestPushInvalid(t *testing.T) {
+	plugin, url := newTestPushgateway(t)
+
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/metrics/job/backup/instance/db1", "", jobMetrics))
+
+	// Labels of the metrics must not conflict with the grouping key
+	body := "# TYPE batch_duration_seconds gauge\nbatch_duration_seconds{instance=\"db2\"} 3\n"
+	require.Equal(t, http.StatusBadRequest, push(t, http.MethodPut, url+"/metrics/job/backup/instance/db1", "", body))
+	require.Equal(t, http.StatusBadRequest, push(t, http.MethodPut, url+"/metrics/job/backup/instance/db1", "", "invalid{"))
+	require.Equal(t, http.StatusBadRequest, push(t, http.MethodPut, url+"/metrics/job/backup/instance", "", jobMetrics))
+	require.Equal(t, http.StatusMethodNotAllowed, push(t, http.MethodGet, url+"/metrics/job/backup", "", ""))
+	require.Equal(t, http.StatusNotFound, push(t, http.MethodPut, url+"/unknown", "", jobMetrics))
+
+	// Failed pushes keep the previous metrics but update the failure time
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+	for _, m := range acc.GetTelegrafMetrics() {
+		if v, ok := m.GetField("push_failure_time_seconds"); ok {
+			require.NotZero(t, v)
+		}
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	plugin := &Pushgateway{
+		ServiceAddress: "127.0.0.1:0",
+		BasicUsername:  config.NewSecret([]byte("user")),
+		BasicPassword:  config.NewSecret([]byte("secret")),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	url := "http://" + plugin.listener.Addr().String() + "/metrics/job/backup"
+
+	require.Equal(t, http.StatusUnauthorized, push(t, http.MethodPut, url, "", jobMetrics))
+
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(jobMetrics))
+	require.NoError(t, err)
+	req.SetBasicAuth("user", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestPathPrefix(t *testing.T) {
+	plugin := &Pushgateway{
+		ServiceAddress: "127.0.0.1:0",
+		PathPrefix:     "/pushgateway/",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	url := "http://" + plugin.listener.Addr().String()
+
+	require.Equal(t, http.StatusNotFound, push(t, http.MethodPut, url+"/metrics/job/backup", "", jobMetrics))
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/pushgateway/metrics/job/backup", "", jobMetrics))
+	require.Equal(t, http.StatusOK, push(t, http.MethodGet, url+"/pushgateway/-/healthy", "", ""))
+}
+
+func TestExpiration(t *testing.T) {
+	plugin, url := newTestPushgateway(t)
+	plugin.Expiration = config.Duration(time.Hour)
+
+	require.Equal(t, http.StatusOK, push(t, http.MethodPut, url+"/metrics/job/backup", "", jobMetrics))
+	require.Len(t, gatherJobMetrics(t, plugin), 2)
+
+	plugin.Lock()
+	for _, g := range plugin.groups {
+		g.pushTime = g.pushTime.Add(-2 * time.Hour)
+	}
+	plugin.Unlock()
+	require.Empty(t, gatherJobMetrics(t, plugin))
+}
+
+func TestParseGroupingKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected map[string]string
+		err      string
+	}{
+		{
+			name:     "job only",
+			path:     "job/backup",
+			expected: map[string]string{"job": "backup"},
+		},
+		{
+			name:     "additional labels",
+			path:     "job/backup/instance/db1/zone/eu",
+			expected: map[string]string{"job": "backup", "instance": "db1", "zone": "eu"},
+		},
+		{
+			name:     "escaped value",
+			path:     "job/backup/path/var%2Flib",
+			expected: map[string]string{"job": "backup", "path": "var/lib"},
+		},
+		{
+			name:     "base64 value",
+			path:     "job@base64/YmFja3VwL2RhaWx5/path@base64/L3Zhci90bXA",
+			expected: map[string]string{"job": "backup/daily", "path": "/var/tmp"},
+		},
+		{
+			name:     "base64 empty value",
+			path:     "job/backup/instance@base64/=",
+			expected: map[string]string{"job": "backup", "instance": ""},
+		},
+		{
+			name: "missing value",
+			path: "job/backup/instance",
+			err:  "odd number of components",
+		},
+		{
+			name: "not starting with job",
+			path: "instance/db1/job/backup",
+			err:  "must start with the job label",
+		},
+		{
+			name: "empty job",
+			path: "job@base64/=",
+			err:  "job name is required",
+		},
+		{
+			name: "invalid label name",
+			path: "job/backup/1abc/x",
+			err:  "invalid label name",
+		},
+		{
+			name: "reserved label name",
+			path: "job/backup/__name__/x",
+			err:  "invalid label name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			labels, err := parseGroupingKey(tt.path)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, labels)
+		})
+	}
+}
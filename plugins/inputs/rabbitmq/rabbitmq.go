@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -58,6 +61,9 @@ type RabbitMQ struct {
 	MetricExclude             []string `toml:"metric_exclude"`
 	QueueInclude              []string `toml:"queue_name_include"`
 	QueueExclude              []string `toml:"queue_name_exclude"`
+	QueueNameRegex            string   `toml:"queue_name_regex"`
+	QueuePageSize             int      `toml:"queue_page_size"`
+	QueueColumns              []string `toml:"queue_columns"`
 	FederationUpstreamInclude []string `toml:"federation_upstream_include"`
 	FederationUpstreamExclude []string `toml:"federation_upstream_exclude"`
 
@@ -65,6 +71,7 @@ type RabbitMQ struct {
 
 	client            *http.Client
 	excludeEveryQueue bool
+	queueColumns      []string
 	metricFilter      filter.Filter
 	queueFilter       filter.Filter
 	upstreamFilter    filter.Filter
@@ -129,6 +136,13 @@ type QueueTotals struct {
 	MessagePersistent          int64 `json:"message_bytes_persistent"`
 }
 
+// QueuesPage is a page of the paginated queues response
+type QueuesPage struct {
+	Items     []Queue `json:"items"`
+	Page      int     `json:"page"`
+	PageCount int     `json:"page_count"`
+}
+
 // Queue ...
 type Queue struct {
 	QueueTotals            // just to not repeat the same code
@@ -276,6 +290,41 @@ var gatherFunctions = map[string]gatherFunc{
 	"queue":      gatherQueues,
 }
 
+// Maximum number of queues per page accepted by the management API
+const maxQueuePageSize = 500
+
+// Columns always requested for queues as they are used as tags
+var queueTagColumns = []string{"name", "vhost", "node", "durable", "auto_delete"}
+
+// Columns of the queues API containing the values of the queue fields
+var queueFieldColumns = map[string]string{
+	"consumers":                 "consumers",
+	"consumer_utilisation":      "consumer_utilisation",
+	"idle_since":                "idle_since",
+	"slave_nodes":               "slave_nodes",
+	"synchronised_slave_nodes":  "synchronised_slave_nodes",
+	"memory":                    "memory",
+	"message_bytes":             "message_bytes",
+	"message_bytes_ready":       "message_bytes_ready",
+	"message_bytes_unacked":     "message_bytes_unacknowledged",
+	"message_bytes_ram":         "message_bytes_ram",
+	"message_bytes_persist":     "message_bytes_persistent",
+	"messages":                  "messages",
+	"messages_ready":            "messages_ready",
+	"messages_unack":            "messages_unacknowledged",
+	"messages_ack":              "message_stats.ack",
+	"messages_ack_rate":         "message_stats.ack_details.rate",
+	"messages_deliver":          "message_stats.deliver",
+	"messages_deliver_rate":     "message_stats.deliver_details.rate",
+	"messages_deliver_get":      "message_stats.deliver_get",
+	"messages_deliver_get_rate": "message_stats.deliver_get_details.rate",
+	"messages_publish":          "message_stats.publish",
+	"messages_publish_rate":     "message_stats.publish_details.rate",
+	"messages_redeliver":        "message_stats.redeliver",
+	"messages_redeliver_rate":   "message_stats.redeliver_details.rate",
+	"head_message_timestamp":    "head_message_timestamp",
+}
+
 func boolToInt(b bool) int64 {
 	if b {
 		return 1
@@ -298,6 +347,23 @@ func (r *RabbitMQ) Init() error {
 		return err
 	}
 
+	// Check the queue query settings
+	if r.QueuePageSize < 0 || r.QueuePageSize > maxQueuePageSize {
+		return fmt.Errorf("invalid queue_page_size %d, must be between 0 and %d", r.QueuePageSize, maxQueuePageSize)
+	}
+	if r.QueueNameRegex != "" {
+		if _, err := regexp.Compile(r.QueueNameRegex); err != nil {
+			return fmt.Errorf("invalid queue_name_regex: %w", err)
+		}
+		// Filtering on the server-side requires pagination
+		if r.QueuePageSize == 0 {
+			r.QueuePageSize = 100
+		}
+	}
+	if err := r.createQueueColumns(); err != nil {
+		return err
+	}
+
 	// Create a filter for the metrics
 	if r.metricFilter, err = filter.NewIncludeExcludeFilter(r.MetricInclude, r.MetricExclude); err != nil {
 		return err
@@ -567,8 +633,7 @@ func gatherQueues(r *RabbitMQ, acc telegraf.Accumulator) {
 		return
 	}
 	// Gather information about queues
-	queues := make([]Queue, 0)
-	err := r.requestJSON("/api/queues", &queues)
+	queues, err := r.requestQueues()
 	if err != nil {
 		acc.AddError(err)
 		return
@@ -620,6 +685,16 @@ func gatherQueues(r *RabbitMQ, acc telegraf.Accumulator) {
 			fields["head_message_timestamp"] = *queue.HeadMessageTimestamp
 		}
 
+		// Omit the fields not requested from the server to avoid reporting
+		// zero values for them
+		if len(r.queueColumns) > 0 {
+			for name := range fields {
+				if !r.queueColumnRequested(queueFieldColumns[name]) {
+					delete(fields, name)
+				}
+			}
+		}
+
 		acc.AddFields(
 			"rabbitmq_queue",
 			fields,
@@ -628,6 +703,61 @@ func gatherQueues(r *RabbitMQ, acc telegraf.Accumulator) {
 	}
 }
 
+// requestQueues queries all queues, optionally using pagination with
+// server-side filtering and a restricted set of columns
+func (r *RabbitMQ) requestQueues() ([]Queue, error) {
+	params := url.Values{}
+	if len(r.queueColumns) > 0 {
+		params.Set("columns", strings.Join(r.queueColumns, ","))
+	}
+
+	if r.QueuePageSize == 0 {
+		u := "/api/queues"
+		if len(params) > 0 {
+			u += "?" + params.Encode()
+		}
+		queues := make([]Queue, 0)
+		if err := r.requestJSON(u, &queues); err != nil {
+			return nil, err
+		}
+		return queues, nil
+	}
+
+	params.Set("page_size", strconv.Itoa(r.QueuePageSize))
+	if r.QueueNameRegex != "" {
+		params.Set("name", r.QueueNameRegex)
+		params.Set("use_regex", "true")
+	}
+
+	queues := make([]Queue, 0)
+	for page := 1; ; page++ {
+		params.Set("page", strconv.Itoa(page))
+
+		var response QueuesPage
+		if err := r.requestJSON("/api/queues?"+params.Encode(), &response); err != nil {
+			return nil, err
+		}
+		queues = append(queues, response.Items...)
+
+		// Stop at the last page or if the server does not report pages
+		if page >= response.PageCount {
+			break
+		}
+	}
+	return queues, nil
+}
+
+// queueColumnRequested checks if the given column or one of its parents is
+// part of the requested queue columns
+func (r *RabbitMQ) queueColumnRequested(column string) bool {
+	for _, c := range r.queueColumns {
+		if column == c || strings.HasPrefix(column, c+".") {
+			return true
+		}
+	}
+	return false
+}
+
 func gatherExchanges(r *RabbitMQ, acc telegraf.Accumulator) {
 	// Gather information about exchanges
 	exchanges := make([]Exchange, 0)
@@ -745,6 +875,32 @@ func (r *RabbitMQ) createQueueFilter() error {
 	return nil
 }
 
+func (r *RabbitMQ) createQueueColumns() error {
+	if len(r.QueueColumns) == 0 {
+		return nil
+	}
+
+	known := make(map[string]bool, len(queueFieldColumns))
+	for _, column := range queueFieldColumns {
+		known[column] = true
+		// Allow to request parent objects like "message_stats"
+		for i, c := range column {
+			if c == '.' {
+				known[column[:i]] = true
+			}
+		}
+	}
+
+	r.queueColumns = append(r.queueColumns, queueTagColumns...)
+	for _, column := range r.QueueColumns {
+		if !known[column] {
+			return fmt.Errorf("unknown queue column %q", column)
+		}
+		r.queueColumns = append(r.queueColumns, column)
+	}
+	return nil
+}
+
 func (r *RabbitMQ) createUpstreamFilter() error {
 	upstreamFilter, err := filter.NewIncludeExcludeFilter(r.FederationUpstreamInclude, r.FederationUpstreamExclude)
 	if err != nil {
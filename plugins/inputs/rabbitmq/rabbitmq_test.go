@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"testing"
@@ -619,6 +621,98 @@ func TestRabbitMQGeneratesMetricsSet2(t *testing.T) {
 	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
 }
 
+func TestRabbitMQQueuePagination(t *testing.T) {
+	pages := []string{
+		`{"items": [{"name": "queue-1", "vhost": "/", "node": "rabbit@node1", "durable": true, "auto_delete": false, "messages": 5, "consumers": 1}],
+		  "page": 1, "page_count": 2, "page_size": 1}`,
+		`{"items": [{"name": "queue-2", "vhost": "/", "node": "rabbit@node1", "durable": false, "auto_delete": true, "messages": 7, "consumers": 2}],
+		  "page": 2, "page_count": 2, "page_size": 1}`,
+	}
+
+	var queries []url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/queues" {
+			http.Error(w, fmt.Sprintf("unknown path %q", r.URL.Path), http.StatusNotFound)
+			return
+		}
+		query := r.URL.Query()
+		queries = append(queries, query)
+
+		page, err := strconv.Atoi(query.Get("page"))
+		require.NoError(t, err)
+		require.LessOrEqual(t, page, len(pages))
+
+		_, err = w.Write([]byte(pages[page-1]))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	plugin := &RabbitMQ{
+		URL:            ts.URL,
+		Log:            testutil.Logger{},
+		MetricInclude:  []string{"queue"},
+		QueueNameRegex: "^queue-",
+		QueuePageSize:  1,
+		QueueColumns:   []string{"messages", "consumers"},
+	}
+	require.NoError(t, plugin.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, plugin.Gather(acc))
+	require.Empty(t, acc.Errors)
+
+	require.Len(t, queries, 2)
+	for i, query := range queries {
+		require.Equal(t, strconv.Itoa(i+1), query.Get("page"))
+		require.Equal(t, "1", query.Get("page_size"))
+		require.Equal(t, "^queue-", query.Get("name"))
+		require.Equal(t, "true", query.Get("use_regex"))
+		require.Equal(t, "name,vhost,node,durable,auto_delete,messages,consumers", query.Get("columns"))
+	}
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric("rabbitmq_queue",
+			map[string]string{
+				"url":         ts.URL,
+				"queue":       "queue-1",
+				"vhost":       "/",
+				"node":        "rabbit@node1",
+				"durable":     "true",
+				"auto_delete": "false",
+			},
+			map[string]interface{}{
+				"messages":  int64(5),
+				"consumers": int64(1),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric("rabbitmq_queue",
+			map[string]string{
+				"url":         ts.URL,
+				"queue":       "queue-2",
+				"vhost":       "/",
+				"node":        "rabbit@node1",
+				"durable":     "false",
+				"auto_delete": "true",
+			},
+			map[string]interface{}{
+				"messages":  int64(7),
+				"consumers": int64(2),
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestRabbitMQInvalidQueueSettings(t *testing.T) {
+	plugin := &RabbitMQ{QueuePageSize: 1000}
+	require.ErrorContains(t, plugin.Init(), "invalid queue_page_size")
+
+	plugin = &RabbitMQ{QueueColumns: []string{"foo"}}
+	require.ErrorContains(t, plugin.Init(), `unknown queue column "foo"`)
+}
+
 func TestRabbitMQMetricFilerts(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("unknown path %q", r.URL.Path), http.StatusNotFound)
@@ -2,18 +2,23 @@
 package redfish
 
 import (
+	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -21,16 +26,55 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+const sessionsPath = "/redfish/v1/SessionService/Sessions"
+
 type Redfish struct {
-	Address          string          `toml:"address"`
-	Username         string          `toml:"username"`
-	Password         string          `toml:"password"`
-	ComputerSystemID string          `toml:"computer_system_id"`
-	Timeout          config.Duration `toml:"timeout"`
+	Address               string          `toml:"address"`
+	Username              string          `toml:"username"`
+	Password              string          `toml:"password"`
+	ComputerSystemID      string          `toml:"computer_system_id"`
+	Discover              bool            `toml:"discover"`
+	IncludeMetrics        []string        `toml:"include_metrics"`
+	MaxConcurrentRequests int             `toml:"max_concurrent_requests"`
+	UseSession            bool            `toml:"use_session"`
+	UseETags              bool            `toml:"use_etags"`
+	Timeout               config.Duration `toml:"timeout"`
+	Log                   telegraf.Logger `toml:"-"`
 
 	client http.Client
 	tls.ClientConfig
 	baseURL *url.URL
+
+	// Semaphore limiting the number of concurrent requests
+	requests chan struct{}
+
+	// Session token and URI of the session to delete on stop
+	sessionToken    string
+	sessionLocation string
+	sessionLock     sync.Mutex
+
+	// Cached responses by URL for revalidation using ETags
+	cache     map[string]cachedResponse
+	cacheLock sync.Mutex
+}
+
+type cachedResponse struct {
+	etag string
+	body []byte
+}
+
+type odataRef struct {
+	Ref string `json:"@odata.id"`
+}
+
+type ServiceRoot struct {
+	Systems  odataRef
+	Chassis  odataRef
+	Managers odataRef
+}
+
+type Collection struct {
+	Members []odataRef
 }
 
 type System struct {
@@ -39,6 +83,9 @@ type System struct {
 		Chassis []struct {
 			Ref string `json:"@odata.id"`
 		}
+		ManagedBy []struct {
+			Ref string `json:"@odata.id"`
+		}
 	}
 }
 
@@ -50,6 +97,9 @@ type Chassis struct {
 	Thermal struct {
 		Ref string `json:"@odata.id"`
 	}
+	Sensors struct {
+		Ref string `json:"@odata.id"`
+	}
 }
 
 type Power struct {
@@ -114,6 +164,35 @@ type Thermal struct {
 	}
 }
 
+type Sensor struct {
+	ID           string `json:"Id"`
+	Name         string
+	Reading      *float64
+	ReadingType  string
+	ReadingUnits string
+	Status       Status
+	Thresholds   struct {
+		UpperCritical Threshold
+		UpperFatal    Threshold
+		LowerCritical Threshold
+		LowerFatal    Threshold
+	}
+}
+
+type Threshold struct {
+	Reading *float64
+}
+
+type Manager struct {
+	ID              string `json:"Id"`
+	Name            string
+	ManagerType     string
+	Model           string
+	FirmwareVersion string
+	PowerState      string
+	Status          Status
+}
+
 type Location struct {
 	PostalAddress struct {
 		DataCenter string
@@ -130,6 +209,15 @@ type Status struct {
 	Health string
 }
 
+// chassisData holds all resources gathered for a chassis
+type chassisData struct {
+	source  string
+	chassis *Chassis
+	thermal *Thermal
+	power   *Power
+	sensors []*Sensor
+}
+
 func (*Redfish) SampleConfig() string {
 	return sampleConfig
 }
@@ -143,10 +231,23 @@ func (r *Redfish) Init() error {
 		return fmt.Errorf("did not provide username and password")
 	}
 
-	if r.ComputerSystemID == "" {
+	if r.ComputerSystemID == "" && !r.Discover {
 		return fmt.Errorf("did not provide the computer system ID of the resource")
 	}
 
+	if len(r.IncludeMetrics) == 0 {
+		r.IncludeMetrics = []string{"thermal", "power"}
+	}
+	if err := choice.CheckSlice(r.IncludeMetrics, []string{"thermal", "power", "sensors", "managers"}); err != nil {
+		return fmt.Errorf("invalid include_metrics: %w", err)
+	}
+
+	if r.MaxConcurrentRequests < 1 {
+		r.MaxConcurrentRequests = 1
+	}
+	r.requests = make(chan struct{}, r.MaxConcurrentRequests)
+	r.cache = make(map[string]cachedResponse)
+
 	var err error
 	r.baseURL, err = url.Parse(r.Address)
 	if err != nil {
@@ -169,33 +270,166 @@ func (r *Redfish) Init() error {
 	return nil
 }
 
-func (r *Redfish) getData(address string, payload interface{}) error {
-	req, err := http.NewRequest("GET", address, nil)
+func (*Redfish) Start(telegraf.Accumulator) error {
+	return nil
+}
+
+// Stop deletes the session to free the session slot on the BMC
+func (r *Redfish) Stop() {
+	r.sessionLock.Lock()
+	defer r.sessionLock.Unlock()
+
+	if r.sessionLocation == "" {
+		return
+	}
+	req, err := http.NewRequest("DELETE", r.sessionLocation, nil)
 	if err != nil {
-		return err
+		return
+	}
+	req.Header.Set("X-Auth-Token", r.sessionToken)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.Log.Debugf("Deleting session failed: %v", err)
+		return
+	}
+	resp.Body.Close()
+	r.sessionToken = ""
+	r.sessionLocation = ""
+}
+
+// session returns the current session token, creating a new session if none
+// exists or the given token was rejected
+func (r *Redfish) session(rejected string) (string, error) {
+	r.sessionLock.Lock()
+	defer r.sessionLock.Unlock()
+
+	if r.sessionToken != "" && r.sessionToken != rejected {
+		return r.sessionToken, nil
 	}
 
-	req.SetBasicAuth(r.Username, r.Password)
+	body, err := json.Marshal(map[string]string{"UserName": r.Username, "Password": r.Password})
+	if err != nil {
+		return "", err
+	}
+	loc := r.baseURL.ResolveReference(&url.URL{Path: sessionsPath})
+	req, err := http.NewRequest("POST", loc.String(), bytes.NewBuffer(body))
+	if err != nil {
+		return "", err
+	}
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("OData-Version", "4.0")
 	resp, err := r.client.Do(req)
 	if err != nil {
-		return err
+		return "", fmt.Errorf("creating session failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("received status code %d (%s) for address %s, expected 200",
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("creating session failed: received status code %d (%s) for address %s",
 			resp.StatusCode,
 			http.StatusText(resp.StatusCode),
 			r.Address)
 	}
+	token := resp.Header.Get("X-Auth-Token")
+	if token == "" {
+		return "", errors.New("creating session failed: no token received")
+	}
+
+	r.sessionToken = token
+	r.sessionLocation = ""
+	if location := resp.Header.Get("Location"); location != "" {
+		if u, err := url.Parse(location); err == nil {
+			r.sessionLocation = loc.ResolveReference(u).String()
+		}
+	}
+	return token, nil
+}
+
+func (r *Redfish) request(address, token string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Auth-Token", token)
+	} else {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("OData-Version", "4.0")
+
+	if r.UseETags {
+		r.cacheLock.Lock()
+		if cached, found := r.cache[address]; found {
+			req.Header.Set("If-None-Match", cached.etag)
+		}
+		r.cacheLock.Unlock()
+	}
+
+	return r.client.Do(req)
+}
+
+func (r *Redfish) getData(address string, payload interface{}) error {
+	r.requests <- struct{}{}
+	defer func() { <-r.requests }()
+
+	var token string
+	if r.UseSession {
+		var err error
+		if token, err = r.session(""); err != nil {
+			return err
+		}
+	}
 
-	body, err := io.ReadAll(resp.Body)
+	resp, err := r.request(address, token)
 	if err != nil {
 		return err
 	}
+	// Sessions might expire or get deleted on the BMC so retry once with a
+	// new session
+	if r.UseSession && resp.StatusCode == http.StatusUnauthorized {
+		resp.Body.Close()
+		if token, err = r.session(token); err != nil {
+			return err
+		}
+		if resp, err = r.request(address, token); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	var body []byte
+	if r.UseETags && resp.StatusCode == http.StatusNotModified {
+		r.cacheLock.Lock()
+		cached, found := r.cache[address]
+		r.cacheLock.Unlock()
+		if found {
+			body = cached.body
+		}
+	}
+
+	if body == nil {
+		if resp.StatusCode != 200 {
+			return fmt.Errorf("received status code %d (%s) for address %s, expected 200",
+				resp.StatusCode,
+				http.StatusText(resp.StatusCode),
+				r.Address)
+		}
+
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		if etag := resp.Header.Get("ETag"); r.UseETags && etag != "" {
+			r.cacheLock.Lock()
+			r.cache[address] = cachedResponse{etag: etag, body: body}
+			r.cacheLock.Unlock()
+		}
+	}
 
 	err = json.Unmarshal(body, &payload)
 	if err != nil {
@@ -205,8 +439,8 @@ func (r *Redfish) getData(address string, payload interface{}) error {
 	return nil
 }
 
-func (r *Redfish) getComputerSystem(id string) (*System, error) {
-	loc := r.baseURL.ResolveReference(&url.URL{Path: path.Join("/redfish/v1/Systems/", id)})
+func (r *Redfish) getComputerSystem(ref string) (*System, error) {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
 	system := &System{}
 	err := r.getData(loc.String(), system)
 	if err != nil {
@@ -245,168 +479,400 @@ func (r *Redfish) getThermal(ref string) (*Thermal, error) {
 	return thermal, nil
 }
 
-func (r *Redfish) Gather(acc telegraf.Accumulator) error {
-	address, _, err := net.SplitHostPort(r.baseURL.Host)
+func (r *Redfish) getSensor(ref string) (*Sensor, error) {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
+	sensor := &Sensor{}
+	err := r.getData(loc.String(), sensor)
 	if err != nil {
-		address = r.baseURL.Host
+		return nil, err
 	}
+	return sensor, nil
+}
 
-	system, err := r.getComputerSystem(r.ComputerSystemID)
+func (r *Redfish) getManager(ref string) (*Manager, error) {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
+	manager := &Manager{}
+	err := r.getData(loc.String(), manager)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	return manager, nil
+}
 
-	for _, link := range system.Links.Chassis {
-		chassis, err := r.getChassis(link.Ref)
-		if err != nil {
-			return err
+func (r *Redfish) getMembers(ref string) ([]string, error) {
+	if ref == "" {
+		return nil, nil
+	}
+	loc := r.baseURL.ResolveReference(&url.URL{Path: ref})
+	collection := &Collection{}
+	if err := r.getData(loc.String(), collection); err != nil {
+		return nil, err
+	}
+	members := make([]string, 0, len(collection.Members))
+	for _, member := range collection.Members {
+		members = append(members, member.Ref)
+	}
+	return members, nil
+}
+
+// discover walks the service root and returns the references of all
+// systems, chassis and managers
+func (r *Redfish) discover() (systems, chassis, managers []string, err error) {
+	loc := r.baseURL.ResolveReference(&url.URL{Path: "/redfish/v1/"})
+	root := &ServiceRoot{}
+	if err := r.getData(loc.String(), root); err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = forEach(3, func(i int) error {
+		var err error
+		switch i {
+		case 0:
+			systems, err = r.getMembers(root.Systems.Ref)
+		case 1:
+			chassis, err = r.getMembers(root.Chassis.Ref)
+		case 2:
+			if choice.Contains("managers", r.IncludeMetrics) {
+				managers, err = r.getMembers(root.Managers.Ref)
+			}
 		}
+		return err
+	})
+	return systems, chassis, managers, err
+}
+
+func (r *Redfish) Gather(acc telegraf.Accumulator) error {
+	address, _, err := net.SplitHostPort(r.baseURL.Host)
+	if err != nil {
+		address = r.baseURL.Host
+	}
 
-		thermal, err := r.getThermal(chassis.Thermal.Ref)
+	var systemRefs, chassisRefs, managerRefs []string
+	if r.Discover {
+		systemRefs, chassisRefs, managerRefs, err = r.discover()
 		if err != nil {
 			return err
 		}
+	} else {
+		systemRefs = []string{path.Join("/redfish/v1/Systems/", r.ComputerSystemID)}
+	}
 
-		for _, j := range thermal.Temperatures {
-			tags := map[string]string{}
-			tags["member_id"] = j.MemberID
-			tags["address"] = address
-			tags["name"] = j.Name
-			tags["source"] = system.Hostname
-			tags["state"] = j.Status.State
-			tags["health"] = j.Status.Health
-			if chassis.Location != nil {
-				tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-				tags["room"] = chassis.Location.PostalAddress.Room
-				tags["rack"] = chassis.Location.Placement.Rack
-				tags["row"] = chassis.Location.Placement.Row
-			}
-
-			fields := make(map[string]interface{})
-			fields["reading_celsius"] = j.ReadingCelsius
-			fields["upper_threshold_critical"] = j.UpperThresholdCritical
-			fields["upper_threshold_fatal"] = j.UpperThresholdFatal
-			fields["lower_threshold_critical"] = j.LowerThresholdCritical
-			fields["lower_threshold_fatal"] = j.LowerThresholdFatal
-			acc.AddFields("redfish_thermal_temperatures", fields, tags)
-		}
+	systems := make([]*System, len(systemRefs))
+	err = forEach(len(systemRefs), func(i int) error {
+		var err error
+		systems[i], err = r.getComputerSystem(systemRefs[i])
+		return err
+	})
+	if err != nil {
+		return err
+	}
 
-		for _, j := range thermal.Fans {
-			tags := map[string]string{}
-			fields := make(map[string]interface{})
-			tags["member_id"] = j.MemberID
-			tags["address"] = address
-			tags["name"] = j.Name
-			tags["source"] = system.Hostname
-			tags["state"] = j.Status.State
-			tags["health"] = j.Status.Health
-			if chassis.Location != nil {
-				tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-				tags["room"] = chassis.Location.PostalAddress.Room
-				tags["rack"] = chassis.Location.Placement.Rack
-				tags["row"] = chassis.Location.Placement.Row
+	// Resources are tagged with the hostname of the system they are linked to
+	sources := make(map[string]string)
+	for _, system := range systems {
+		for _, link := range system.Links.Chassis {
+			key := strings.TrimSuffix(link.Ref, "/")
+			if _, found := sources[key]; !found {
+				sources[key] = system.Hostname
+				if !r.Discover {
+					chassisRefs = append(chassisRefs, link.Ref)
+				}
 			}
-
-			if j.ReadingUnits != nil && *j.ReadingUnits == "RPM" {
-				fields["upper_threshold_critical"] = j.UpperThresholdCritical
-				fields["upper_threshold_fatal"] = j.UpperThresholdFatal
-				fields["lower_threshold_critical"] = j.LowerThresholdCritical
-				fields["lower_threshold_fatal"] = j.LowerThresholdFatal
-				fields["reading_rpm"] = j.Reading
-			} else {
-				fields["reading_percent"] = j.Reading
+		}
+		for _, link := range system.Links.ManagedBy {
+			key := strings.TrimSuffix(link.Ref, "/")
+			if _, found := sources[key]; !found {
+				sources[key] = system.Hostname
+				if !r.Discover && choice.Contains("managers", r.IncludeMetrics) {
+					managerRefs = append(managerRefs, link.Ref)
+				}
 			}
-			acc.AddFields("redfish_thermal_fans", fields, tags)
 		}
+	}
 
-		power, err := r.getPower(chassis.Power.Ref)
-		if err != nil {
+	// Fetch all resources in parallel but add the metrics in a stable order
+	chassis := make([]*chassisData, len(chassisRefs))
+	managers := make([]*Manager, len(managerRefs))
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		errs[0] = forEach(len(chassisRefs), func(i int) error {
+			var err error
+			chassis[i], err = r.getChassisData(chassisRefs[i])
+			if chassis[i] != nil {
+				chassis[i].source = sources[strings.TrimSuffix(chassisRefs[i], "/")]
+			}
 			return err
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		errs[1] = forEach(len(managerRefs), func(i int) error {
+			var err error
+			managers[i], err = r.getManager(managerRefs[i])
+			return err
+		})
+	}()
+	wg.Wait()
+
+	for _, data := range chassis {
+		if data == nil {
+			continue
+		}
+		if data.thermal != nil {
+			addThermal(acc, address, data.source, data.chassis, data.thermal)
 		}
+		if data.power != nil {
+			addPower(acc, address, data.source, data.chassis, data.power)
+		}
+		for _, sensor := range data.sensors {
+			addSensor(acc, address, data.source, data.chassis, sensor)
+		}
+	}
+	for i, manager := range managers {
+		if manager != nil {
+			addManager(acc, address, sources[strings.TrimSuffix(managerRefs[i], "/")], manager)
+		}
+	}
+
+	return errors.Join(errs...)
+}
 
-		for _, j := range power.PowerControl {
-			tags := map[string]string{
-				"member_id": j.MemberID,
-				"address":   address,
-				"name":      j.Name,
-				"source":    system.Hostname,
+// getChassisData fetches the chassis and its enabled resources in parallel.
+// The returned data contains all successfully fetched resources even in case
+// of an error.
+func (r *Redfish) getChassisData(ref string) (*chassisData, error) {
+	chassis, err := r.getChassis(ref)
+	if err != nil {
+		return nil, err
+	}
+	data := &chassisData{chassis: chassis}
+
+	err = forEach(3, func(i int) error {
+		var err error
+		switch i {
+		case 0:
+			if chassis.Thermal.Ref != "" && choice.Contains("thermal", r.IncludeMetrics) {
+				data.thermal, err = r.getThermal(chassis.Thermal.Ref)
 			}
-			if chassis.Location != nil {
-				tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-				tags["room"] = chassis.Location.PostalAddress.Room
-				tags["rack"] = chassis.Location.Placement.Rack
-				tags["row"] = chassis.Location.Placement.Row
+		case 1:
+			if chassis.Power.Ref != "" && choice.Contains("power", r.IncludeMetrics) {
+				data.power, err = r.getPower(chassis.Power.Ref)
 			}
-
-			fields := map[string]interface{}{
-				"power_allocated_watts":  j.PowerAllocatedWatts,
-				"power_available_watts":  j.PowerAvailableWatts,
-				"power_capacity_watts":   j.PowerCapacityWatts,
-				"power_consumed_watts":   j.PowerConsumedWatts,
-				"power_requested_watts":  j.PowerRequestedWatts,
-				"average_consumed_watts": j.PowerMetrics.AverageConsumedWatts,
-				"interval_in_min":        j.PowerMetrics.IntervalInMin,
-				"max_consumed_watts":     j.PowerMetrics.MaxConsumedWatts,
-				"min_consumed_watts":     j.PowerMetrics.MinConsumedWatts,
+		case 2:
+			if chassis.Sensors.Ref != "" && choice.Contains("sensors", r.IncludeMetrics) {
+				data.sensors, err = r.getSensors(chassis.Sensors.Ref)
 			}
-
-			acc.AddFields("redfish_power_powercontrol", fields, tags)
 		}
+		return err
+	})
+	return data, err
+}
 
-		for _, j := range power.PowerSupplies {
-			tags := map[string]string{}
-			tags["member_id"] = j.MemberID
-			tags["address"] = address
-			tags["name"] = j.Name
-			tags["source"] = system.Hostname
-			tags["state"] = j.Status.State
-			tags["health"] = j.Status.Health
-			if chassis.Location != nil {
-				tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-				tags["room"] = chassis.Location.PostalAddress.Room
-				tags["rack"] = chassis.Location.Placement.Rack
-				tags["row"] = chassis.Location.Placement.Row
-			}
+func (r *Redfish) getSensors(ref string) ([]*Sensor, error) {
+	members, err := r.getMembers(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	sensors := make([]*Sensor, len(members))
+	err = forEach(len(members), func(i int) error {
+		var err error
+		sensors[i], err = r.getSensor(members[i])
+		return err
+	})
 
-			fields := make(map[string]interface{})
-			fields["power_input_watts"] = j.PowerInputWatts
-			fields["power_output_watts"] = j.PowerOutputWatts
-			fields["line_input_voltage"] = j.LineInputVoltage
-			fields["last_power_output_watts"] = j.LastPowerOutputWatts
-			fields["power_capacity_watts"] = j.PowerCapacityWatts
-			acc.AddFields("redfish_power_powersupplies", fields, tags)
+	// Skip sensors failed to fetch
+	valid := make([]*Sensor, 0, len(sensors))
+	for _, sensor := range sensors {
+		if sensor != nil {
+			valid = append(valid, sensor)
 		}
+	}
+	return valid, err
+}
 
-		for _, j := range power.Voltages {
-			tags := map[string]string{}
-			tags["member_id"] = j.MemberID
-			tags["address"] = address
-			tags["name"] = j.Name
-			tags["source"] = system.Hostname
-			tags["state"] = j.Status.State
-			tags["health"] = j.Status.Health
-			if chassis.Location != nil {
-				tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
-				tags["room"] = chassis.Location.PostalAddress.Room
-				tags["rack"] = chassis.Location.Placement.Rack
-				tags["row"] = chassis.Location.Placement.Row
-			}
+func addLocationTags(tags map[string]string, chassis *Chassis) {
+	if chassis.Location != nil {
+		tags["datacenter"] = chassis.Location.PostalAddress.DataCenter
+		tags["room"] = chassis.Location.PostalAddress.Room
+		tags["rack"] = chassis.Location.Placement.Rack
+		tags["row"] = chassis.Location.Placement.Row
+	}
+}
 
-			fields := make(map[string]interface{})
-			fields["reading_volts"] = j.ReadingVolts
+func addThermal(acc telegraf.Accumulator, address, source string, chassis *Chassis, thermal *Thermal) {
+	for _, j := range thermal.Temperatures {
+		tags := map[string]string{}
+		tags["member_id"] = j.MemberID
+		tags["address"] = address
+		tags["name"] = j.Name
+		tags["source"] = source
+		tags["state"] = j.Status.State
+		tags["health"] = j.Status.Health
+		addLocationTags(tags, chassis)
+
+		fields := make(map[string]interface{})
+		fields["reading_celsius"] = j.ReadingCelsius
+		fields["upper_threshold_critical"] = j.UpperThresholdCritical
+		fields["upper_threshold_fatal"] = j.UpperThresholdFatal
+		fields["lower_threshold_critical"] = j.LowerThresholdCritical
+		fields["lower_threshold_fatal"] = j.LowerThresholdFatal
+		acc.AddFields("redfish_thermal_temperatures", fields, tags)
+	}
+
+	for _, j := range thermal.Fans {
+		tags := map[string]string{}
+		fields := make(map[string]interface{})
+		tags["member_id"] = j.MemberID
+		tags["address"] = address
+		tags["name"] = j.Name
+		tags["source"] = source
+		tags["state"] = j.Status.State
+		tags["health"] = j.Status.Health
+		addLocationTags(tags, chassis)
+
+		if j.ReadingUnits != nil && *j.ReadingUnits == "RPM" {
 			fields["upper_threshold_critical"] = j.UpperThresholdCritical
 			fields["upper_threshold_fatal"] = j.UpperThresholdFatal
 			fields["lower_threshold_critical"] = j.LowerThresholdCritical
 			fields["lower_threshold_fatal"] = j.LowerThresholdFatal
-			acc.AddFields("redfish_power_voltages", fields, tags)
+			fields["reading_rpm"] = j.Reading
+		} else {
+			fields["reading_percent"] = j.Reading
 		}
+		acc.AddFields("redfish_thermal_fans", fields, tags)
 	}
+}
 
-	return nil
+func addPower(acc telegraf.Accumulator, address, source string, chassis *Chassis, power *Power) {
+	for _, j := range power.PowerControl {
+		tags := map[string]string{
+			"member_id": j.MemberID,
+			"address":   address,
+			"name":      j.Name,
+			"source":    source,
+		}
+		addLocationTags(tags, chassis)
+
+		fields := map[string]interface{}{
+			"power_allocated_watts":  j.PowerAllocatedWatts,
+			"power_available_watts":  j.PowerAvailableWatts,
+			"power_capacity_watts":   j.PowerCapacityWatts,
+			"power_consumed_watts":   j.PowerConsumedWatts,
+			"power_requested_watts":  j.PowerRequestedWatts,
+			"average_consumed_watts": j.PowerMetrics.AverageConsumedWatts,
+			"interval_in_min":        j.PowerMetrics.IntervalInMin,
+			"max_consumed_watts":     j.PowerMetrics.MaxConsumedWatts,
+			"min_consumed_watts":     j.PowerMetrics.MinConsumedWatts,
+		}
+
+		acc.AddFields("redfish_power_powercontrol", fields, tags)
+	}
+
+	for _, j := range power.PowerSupplies {
+		tags := map[string]string{}
+		tags["member_id"] = j.MemberID
+		tags["address"] = address
+		tags["name"] = j.Name
+		tags["source"] = source
+		tags["state"] = j.Status.State
+		tags["health"] = j.Status.Health
+		addLocationTags(tags, chassis)
+
+		fields := make(map[string]interface{})
+		fields["power_input_watts"] = j.PowerInputWatts
+		fields["power_output_watts"] = j.PowerOutputWatts
+		fields["line_input_voltage"] = j.LineInputVoltage
+		fields["last_power_output_watts"] = j.LastPowerOutputWatts
+		fields["power_capacity_watts"] = j.PowerCapacityWatts
+		acc.AddFields("redfish_power_powersupplies", fields, tags)
+	}
+
+	for _, j := range power.Voltages {
+		tags := map[string]string{}
+		tags["member_id"] = j.MemberID
+		tags["address"] = address
+		tags["name"] = j.Name
+		tags["source"] = source
+		tags["state"] = j.Status.State
+		tags["health"] = j.Status.Health
+		addLocationTags(tags, chassis)
+
+		fields := make(map[string]interface{})
+		fields["reading_volts"] = j.ReadingVolts
+		fields["upper_threshold_critical"] = j.UpperThresholdCritical
+		fields["upper_threshold_fatal"] = j.UpperThresholdFatal
+		fields["lower_threshold_critical"] = j.LowerThresholdCritical
+		fields["lower_threshold_fatal"] = j.LowerThresholdFatal
+		acc.AddFields("redfish_power_voltages", fields, tags)
+	}
+}
+
+func addSensor(acc telegraf.Accumulator, address, source string, chassis *Chassis, sensor *Sensor) {
+	tags := map[string]string{
+		"member_id":     sensor.ID,
+		"address":       address,
+		"name":          sensor.Name,
+		"source":        source,
+		"reading_type":  sensor.ReadingType,
+		"reading_units": sensor.ReadingUnits,
+		"state":         sensor.Status.State,
+		"health":        sensor.Status.Health,
+	}
+	addLocationTags(tags, chassis)
+
+	fields := map[string]interface{}{
+		"reading":                  sensor.Reading,
+		"upper_threshold_critical": sensor.Thresholds.UpperCritical.Reading,
+		"upper_threshold_fatal":    sensor.Thresholds.UpperFatal.Reading,
+		"lower_threshold_critical": sensor.Thresholds.LowerCritical.Reading,
+		"lower_threshold_fatal":    sensor.Thresholds.LowerFatal.Reading,
+	}
+	acc.AddFields("redfish_sensors", fields, tags)
+}
+
+func addManager(acc telegraf.Accumulator, address, source string, manager *Manager) {
+	tags := map[string]string{
+		"member_id":    manager.ID,
+		"address":      address,
+		"name":         manager.Name,
+		"source":       source,
+		"manager_type": manager.ManagerType,
+		"model":        manager.Model,
+		"state":        manager.Status.State,
+		"health":       manager.Status.Health,
+	}
+
+	fields := map[string]interface{}{
+		"firmware_version": manager.FirmwareVersion,
+		"power_state":      manager.PowerState,
+	}
+	acc.AddFields("redfish_managers", fields, tags)
+}
+
+// forEach runs the function for the indices 0 to n-1 concurrently and
+// returns all errors
+func forEach(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
 }
 
 func init() {
 	inputs.Add("redfish", func() telegraf.Input {
-		return &Redfish{}
+		return &Redfish{
+			MaxConcurrentRequests: 4,
+		}
 	})
 }
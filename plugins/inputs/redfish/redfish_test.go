@@ -1,10 +1,14 @@
 package redfish
 
 import (
+	"encoding/json"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -912,3 +916,256 @@ func TestInvalidHPJSON(t *testing.T) {
 		})
 	}
 }
+
+// discoveryServer serves the resources of the discovery testdata including
+// session authentication and ETags
+type discoveryServer struct {
+	*httptest.Server
+	token       string
+	sessions    int
+	deleted     int
+	notModified int
+	sync.Mutex
+}
+
+func newDiscoveryServer(t *testing.T) *discoveryServer {
+	s := &discoveryServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Lock()
+		defer s.Unlock()
+
+		if r.URL.Path == "/redfish/v1/SessionService/Sessions" && r.Method == "POST" {
+			var credentials struct {
+				UserName string
+				Password string
+			}
+			if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if credentials.UserName != "test" || credentials.Password != "test" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			s.sessions++
+			s.token = "token" + strconv.Itoa(s.sessions)
+			w.Header().Set("X-Auth-Token", s.token)
+			w.Header().Set("Location", "/redfish/v1/SessionService/Sessions/"+strconv.Itoa(s.sessions))
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		token := r.Header.Get("X-Auth-Token")
+		if !checkAuth(r, "test", "test") && (token == "" || token != s.token) {
+			http.Error(w, "Unauthorized.", 401)
+			return
+		}
+
+		if r.Method == "DELETE" {
+			s.deleted++
+			s.token = ""
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("ETag", strconv.Quote(r.URL.Path))
+		if r.Header.Get("If-None-Match") == strconv.Quote(r.URL.Path) {
+			s.notModified++
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", "discovery", r.URL.Path, "index.json"))
+	}))
+	t.Cleanup(s.Server.Close)
+
+	return s
+}
+
+func TestDiscovery(t *testing.T) {
+	ts := newDiscoveryServer(t)
+
+	u, err := url.Parse(ts.URL)
+	require.NoError(t, err)
+	address, _, err := net.SplitHostPort(u.Host)
+	require.NoError(t, err)
+
+	plugin := &Redfish{
+		Address:               ts.URL,
+		Username:              "test",
+		Password:              "test",
+		Discover:              true,
+		IncludeMetrics:        []string{"thermal", "power", "sensors", "managers"},
+		MaxConcurrentRequests: 4,
+		Log:                   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	location := map[string]string{
+		"datacenter": "DC1",
+		"room":       "R1",
+		"rack":       "A1",
+		"row":        "1",
+	}
+	withLocation := func(tags map[string]string) map[string]string {
+		for k, v := range location {
+			tags[k] = v
+		}
+		return tags
+	}
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"redfish_thermal_temperatures",
+			withLocation(map[string]string{
+				"name":      "CPU1 Temp",
+				"member_id": "0",
+				"source":    "node1",
+				"address":   address,
+				"health":    "OK",
+				"state":     "Enabled",
+			}),
+			map[string]interface{}{
+				"reading_celsius":          41.0,
+				"upper_threshold_critical": 85.0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"redfish_thermal_fans",
+			withLocation(map[string]string{
+				"name":      "Fan1",
+				"member_id": "0",
+				"source":    "node1",
+				"address":   address,
+				"health":    "OK",
+				"state":     "Enabled",
+			}),
+			map[string]interface{}{
+				"reading_rpm": int64(4200),
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"redfish_power_powersupplies",
+			withLocation(map[string]string{
+				"name":      "PSU1",
+				"member_id": "0",
+				"source":    "node1",
+				"address":   address,
+				"health":    "OK",
+				"state":     "Enabled",
+			}),
+			map[string]interface{}{
+				"power_output_watts": 210.0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"redfish_sensors",
+			withLocation(map[string]string{
+				"name":          "Inlet Temperature",
+				"member_id":     "Inlet",
+				"source":        "node1",
+				"address":       address,
+				"reading_type":  "Temperature",
+				"reading_units": "Cel",
+				"health":        "OK",
+				"state":         "Enabled",
+			}),
+			map[string]interface{}{
+				"reading":                  22.5,
+				"upper_threshold_critical": 40.0,
+				"upper_threshold_fatal":    45.0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"redfish_sensors",
+			map[string]string{
+				"name":          "PSU0 Input Power",
+				"member_id":     "PSU0Power",
+				"source":        "",
+				"address":       address,
+				"reading_type":  "Power",
+				"reading_units": "W",
+				"health":        "OK",
+				"state":         "Enabled",
+			},
+			map[string]interface{}{
+				"reading": 374.0,
+			},
+			time.Unix(0, 0),
+		),
+		testutil.MustMetric(
+			"redfish_managers",
+			map[string]string{
+				"name":         "Manager",
+				"member_id":    "BMC",
+				"source":       "node1",
+				"address":      address,
+				"manager_type": "BMC",
+				"model":        "Joo Janta 200",
+				"health":       "OK",
+				"state":        "Enabled",
+			},
+			map[string]interface{}{
+				"firmware_version": "4.4.6521",
+				"power_state":      "On",
+			},
+			time.Unix(0, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestSessionAndETags(t *testing.T) {
+	ts := newDiscoveryServer(t)
+
+	plugin := &Redfish{
+		Address:               ts.URL,
+		Username:              "test",
+		Password:              "test",
+		ComputerSystemID:      "1",
+		UseSession:            true,
+		UseETags:              true,
+		MaxConcurrentRequests: 2,
+		Log:                   testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+
+	// The session is reused and unchanged resources are served from cache
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+	ts.Lock()
+	require.Equal(t, 1, ts.sessions)
+	require.Equal(t, 4, ts.notModified)
+
+	// Expired sessions are re-created
+	ts.token = "expired"
+	ts.Unlock()
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 3)
+
+	plugin.Stop()
+	ts.Lock()
+	require.Equal(t, 2, ts.sessions)
+	require.Equal(t, 1, ts.deleted)
+	ts.Unlock()
+}
+
+func TestInvalidIncludeMetrics(t *testing.T) {
+	plugin := &Redfish{
+		Address:          "http://127.0.0.1",
+		Username:         "test",
+		Password:         "test",
+		ComputerSystemID: "1",
+		IncludeMetrics:   []string{"storage"},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid include_metrics")
+}
@@ -0,0 +1,315 @@
+package remote_file
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ftpFetcher implements the subset of the FTP protocol (RFC 959) required
+// for downloading files including explicit TLS (RFC 4217) and the size and
+// modification time extensions (RFC 3659). Only passive mode is supported.
+type ftpFetcher struct {
+	host    string
+	conn    net.Conn
+	text    *textproto.Conn
+	tlsCfg  *tls.Config
+	timeout time.Duration
+}
+
+func connectFTP(host, username, password string, tlsCfg *tls.Config, timeout time.Duration) (fetcher, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "21")
+	}
+	if username == "" {
+		username = "anonymous"
+		password = "anonymous"
+	}
+
+	conn, err := net.DialTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, err
+	}
+	f := &ftpFetcher{
+		host:    host,
+		conn:    conn,
+		text:    textproto.NewConn(conn),
+		timeout: timeout,
+	}
+	if err := f.login(username, password, tlsCfg); err != nil {
+		f.conn.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *ftpFetcher) login(username, password string, tlsCfg *tls.Config) error {
+	f.deadline()
+	if _, _, err := f.text.ReadResponse(220); err != nil {
+		return fmt.Errorf("unexpected greeting: %w", err)
+	}
+
+	if tlsCfg != nil {
+		if _, err := f.cmd(234, "AUTH TLS"); err != nil {
+			return fmt.Errorf("enabling TLS failed: %w", err)
+		}
+		cfg := tlsCfg.Clone()
+		if cfg.ServerName == "" {
+			cfg.ServerName, _, _ = net.SplitHostPort(f.host)
+		}
+		// Servers commonly require the data connections to resume the TLS
+		// session of the control connection
+		if cfg.ClientSessionCache == nil {
+			cfg.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+		}
+		f.tlsCfg = cfg
+		f.conn = tls.Client(f.conn, cfg)
+		f.text = textproto.NewConn(f.conn)
+		if _, err := f.cmd(200, "PBSZ 0"); err != nil {
+			return err
+		}
+		if _, err := f.cmd(200, "PROT P"); err != nil {
+			return err
+		}
+	}
+
+	code, msg, err := f.cmdAny("USER " + username)
+	if err != nil {
+		return err
+	}
+	switch code {
+	case 230:
+	case 331:
+		if _, err := f.cmd(230, "PASS "+password); err != nil {
+			return fmt.Errorf("login failed: %w", err)
+		}
+	default:
+		return fmt.Errorf("login failed: %d %s", code, msg)
+	}
+
+	_, err = f.cmd(200, "TYPE I")
+	return err
+}
+
+func (f *ftpFetcher) deadline() {
+	if f.timeout > 0 {
+		_ = f.conn.SetDeadline(time.Now().Add(f.timeout))
+	}
+}
+
+// cmd sends the command and expects a response with the given code
+func (f *ftpFetcher) cmd(expected int, command string) (string, error) {
+	code, msg, err := f.cmdAny(command)
+	if err != nil {
+		return "", err
+	}
+	if code != expected {
+		return "", &textproto.Error{Code: code, Msg: msg}
+	}
+	return msg, nil
+}
+
+func (f *ftpFetcher) cmdAny(command string) (int, string, error) {
+	f.deadline()
+	if _, err := f.text.Cmd("%s", command); err != nil {
+		return 0, "", err
+	}
+	// Without an expected code errors are only returned for I/O or protocol
+	// failures
+	code, msg, err := f.text.ReadResponse(0)
+	if err != nil {
+		return 0, "", err
+	}
+	return code, msg, nil
+}
+
+// dataConn opens a passive data connection preferring extended passive mode
+func (f *ftpFetcher) dataConn() (net.Conn, error) {
+	host, _, err := net.SplitHostPort(f.host)
+	if err != nil {
+		return nil, err
+	}
+
+	var port int
+	if msg, err := f.cmd(229, "EPSV"); err == nil {
+		// Response in the form "Entering Extended Passive Mode (|||6446|)"
+		start, end := strings.Index(msg, "(|||"), strings.LastIndex(msg, "|)")
+		if start < 0 || end < start+4 {
+			return nil, fmt.Errorf("invalid extended passive mode response %q", msg)
+		}
+		if port, err = strconv.Atoi(msg[start+4 : end]); err != nil {
+			return nil, fmt.Errorf("invalid extended passive mode response %q", msg)
+		}
+	} else {
+		msg, err := f.cmd(227, "PASV")
+		if err != nil {
+			return nil, fmt.Errorf("entering passive mode failed: %w", err)
+		}
+		// Response in the form "Entering Passive Mode (h1,h2,h3,h4,p1,p2)".
+		// The address is ignored as it is often wrong for servers behind NAT.
+		start, end := strings.Index(msg, "("), strings.LastIndex(msg, ")")
+		if start < 0 || end < start {
+			return nil, fmt.Errorf("invalid passive mode response %q", msg)
+		}
+		parts := strings.Split(msg[start+1:end], ",")
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("invalid passive mode response %q", msg)
+		}
+		p1, err1 := strconv.Atoi(parts[4])
+		p2, err2 := strconv.Atoi(parts[5])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid passive mode response %q", msg)
+		}
+		port = p1<<8 | p2
+	}
+
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), f.timeout)
+	if err != nil {
+		return nil, err
+	}
+	if f.tlsCfg != nil {
+		conn = tls.Client(conn, f.tlsCfg)
+	}
+	if f.timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(f.timeout))
+	}
+	return conn, nil
+}
+
+// transfer runs a command transferring data via a data connection
+func (f *ftpFetcher) transfer(command string) (*ftpData, error) {
+	conn, err := f.dataConn()
+	if err != nil {
+		return nil, err
+	}
+	code, msg, err := f.cmdAny(command)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if code != 125 && code != 150 {
+		conn.Close()
+		return nil, &textproto.Error{Code: code, Msg: msg}
+	}
+	return &ftpData{Conn: conn, fetcher: f}, nil
+}
+
+func (f *ftpFetcher) list(pattern string) ([]remoteFile, error) {
+	dir, name := path.Split(pattern)
+	if !hasMeta(name) {
+		file, err := f.stat(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return []remoteFile{file}, nil
+	}
+
+	data, err := f.transfer("NLST " + dir)
+	if err != nil {
+		return nil, fmt.Errorf("listing directory %q failed: %w", dir, err)
+	}
+	listing, err := io.ReadAll(data)
+	if cerr := data.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing directory %q failed: %w", dir, err)
+	}
+
+	var files []remoteFile
+	for _, entry := range strings.Split(string(listing), "\n") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		// Some servers return the full path, others only the name
+		matched, err := path.Match(name, path.Base(entry))
+		if err != nil {
+			return nil, err
+		}
+		if !matched {
+			continue
+		}
+		file, err := f.stat(path.Join(dir, path.Base(entry)))
+		if err != nil {
+			// Directories do not have a size
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func (f *ftpFetcher) stat(name string) (remoteFile, error) {
+	file := remoteFile{path: name}
+
+	msg, err := f.cmd(213, "SIZE "+name)
+	if err != nil {
+		return file, fmt.Errorf("getting size failed: %w", err)
+	}
+	if file.size, err = strconv.ParseInt(strings.TrimSpace(msg), 10, 64); err != nil {
+		return file, fmt.Errorf("invalid size %q", msg)
+	}
+
+	// The modification time is optional
+	if msg, err := f.cmd(213, "MDTM "+name); err == nil {
+		// Fractional seconds are accepted by the parser without being part of
+		// the layout
+		if t, err := time.Parse("20060102150405", strings.TrimSpace(msg)); err == nil {
+			file.modTime = t
+		}
+	}
+	return file, nil
+}
+
+func (f *ftpFetcher) open(file remoteFile, offset int64) (io.ReadCloser, error) {
+	if offset > 0 {
+		if _, err := f.cmd(350, "REST "+strconv.FormatInt(offset, 10)); err != nil {
+			return nil, fmt.Errorf("resuming at offset %d failed: %w", offset, err)
+		}
+	}
+	data, err := f.transfer("RETR " + file.path)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving file failed: %w", err)
+	}
+	return data, nil
+}
+
+func (f *ftpFetcher) close() error {
+	_, _, _ = f.cmdAny("QUIT")
+	return f.conn.Close()
+}
+
+// ftpData is the data connection of a transfer, closing it waits for the
+// completion of the transfer on the control connection
+type ftpData struct {
+	net.Conn
+	fetcher *ftpFetcher
+}
+
+func (d *ftpData) Close() error {
+	if err := d.Conn.Close(); err != nil {
+		return err
+	}
+	d.fetcher.deadline()
+	code, msg, err := d.fetcher.text.ReadResponse(0)
+	if err != nil {
+		return err
+	}
+	if code != 226 && code != 250 {
+		return &textproto.Error{Code: code, Msg: msg}
+	}
+	return nil
+}
+
+// hasMeta reports whether the path contains any of the magic characters
+// recognized by path.Match
+func hasMeta(p string) bool {
+	return strings.ContainsAny(p, `*?[\`)
+}
@@ -0,0 +1,101 @@
+package remote_file
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// httpFetcher reads a single file from a HTTP(S) server. Wildcards are not
+// supported as there is no standard way to list files.
+type httpFetcher struct {
+	url      *url.URL
+	username string
+	password string
+	client   *http.Client
+}
+
+func newHTTPFetcher(u *url.URL, username, password string, tlsCfg *tls.Config, timeout time.Duration) fetcher {
+	// Do not send the credentials of the URL unless requested
+	target := *u
+	target.User = nil
+
+	return &httpFetcher{
+		url:      &target,
+		username: username,
+		password: password,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsCfg,
+			},
+			Timeout: timeout,
+		},
+	}
+}
+
+func (f *httpFetcher) request(method string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequest(method, f.url.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if f.username != "" || f.password != "" {
+		req.SetBasicAuth(f.username, f.password)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("received status code %d (%s)", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+func (f *httpFetcher) list(string) ([]remoteFile, error) {
+	resp, err := f.request(http.MethodHead, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	file := remoteFile{path: f.url.Path, size: -1}
+	if resp.ContentLength >= 0 {
+		file.size = resp.ContentLength
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		if t, err := http.ParseTime(lastModified); err == nil {
+			file.modTime = t
+		}
+	}
+	return []remoteFile{file}, nil
+}
+
+func (f *httpFetcher) open(_ remoteFile, offset int64) (io.ReadCloser, error) {
+	resp, err := f.request(http.MethodGet, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers not supporting range requests send the whole content
+	if offset > 0 && resp.StatusCode != http.StatusPartialContent {
+		if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+			resp.Body.Close()
+			return nil, fmt.Errorf("skipping to offset %d failed: %w", offset, err)
+		}
+	}
+	return resp.Body, nil
+}
+
+func (f *httpFetcher) close() error {
+	f.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,367 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package remote_file
+
+import (
+	"bytes"
+	"crypto/tls"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// remoteFile describes a file on the remote endpoint
+type remoteFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// fetcher lists and reads files from a remote endpoint
+type fetcher interface {
+	// list returns the files matching the pattern, only the last element of
+	// the path may contain wildcards
+	list(pattern string) ([]remoteFile, error)
+	// open returns a reader for the content of the file starting at the
+	// given offset
+	open(file remoteFile, offset int64) (io.ReadCloser, error)
+	close() error
+}
+
+// fileState is the state of a processed file
+type fileState struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+	Offset  int64     `json:"offset"`
+}
+
+type RemoteFile struct {
+	URLs                  []string        `toml:"urls"`
+	Username              config.Secret   `toml:"username"`
+	Password              config.Secret   `toml:"password"`
+	PrivateKey            string          `toml:"private_key"`
+	PrivateKeyPassphrase  config.Secret   `toml:"private_key_passphrase"`
+	KnownHosts            string          `toml:"known_hosts"`
+	InsecureIgnoreHostKey bool            `toml:"insecure_ignore_host_key"`
+	ReadMode              string          `toml:"read_mode"`
+	MaxFileSize           config.Size     `toml:"max_file_size"`
+	FileTag               string          `toml:"file_tag"`
+	Timeout               config.Duration `toml:"timeout"`
+	Log                   telegraf.Logger `toml:"-"`
+	tlsint.ClientConfig
+
+	parserFunc telegraf.ParserFunc
+	tlsCfg     *tls.Config
+	sftp       *sftpConfig
+	endpoints  []*url.URL
+
+	// Parsers of files read in append mode to keep the state, e.g. the
+	// header of CSV files, across reads
+	parsers map[string]telegraf.Parser
+	states  map[string]fileState
+	sync.Mutex
+}
+
+func (*RemoteFile) SampleConfig() string {
+	return sampleConfig
+}
+
+// SetParserFunc takes the data_format from the config and finds the right parser for that format
+func (r *RemoteFile) SetParserFunc(fn telegraf.ParserFunc) {
+	r.parserFunc = fn
+}
+
+func (r *RemoteFile) Init() error {
+	if len(r.URLs) == 0 {
+		return errors.New("no urls specified")
+	}
+	if r.ReadMode == "" {
+		r.ReadMode = "modified"
+	}
+	if err := choice.Check(r.ReadMode, []string{"modified", "append"}); err != nil {
+		return fmt.Errorf("invalid read_mode: %w", err)
+	}
+
+	var needsSFTP bool
+	for _, raw := range r.URLs {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("parsing url %q failed: %w", raw, err)
+		}
+		switch u.Scheme {
+		case "sftp":
+			needsSFTP = true
+		case "ftp", "ftps", "http", "https":
+		default:
+			return fmt.Errorf("unsupported scheme %q of url %q", u.Scheme, raw)
+		}
+		if u.Path == "" || u.Path == "/" {
+			return fmt.Errorf("missing file path in url %q", raw)
+		}
+		r.endpoints = append(r.endpoints, u)
+	}
+
+	tlsCfg, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("setting up TLS configuration failed: %w", err)
+	}
+	r.tlsCfg = tlsCfg
+
+	if needsSFTP {
+		cfg, err := r.newSFTPConfig()
+		if err != nil {
+			return err
+		}
+		r.sftp = cfg
+	}
+
+	r.parsers = make(map[string]telegraf.Parser)
+	r.states = make(map[string]fileState)
+
+	return nil
+}
+
+func (r *RemoteFile) GetState() interface{} {
+	r.Lock()
+	defer r.Unlock()
+
+	states := make(map[string]fileState, len(r.states))
+	for k, v := range r.states {
+		states[k] = v
+	}
+	return states
+}
+
+func (r *RemoteFile) SetState(state interface{}) error {
+	states, ok := state.(map[string]fileState)
+	if !ok {
+		return errors.New("state has to be of type 'map[string]fileState'")
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	for k, v := range states {
+		r.states[k] = v
+	}
+	return nil
+}
+
+func (r *RemoteFile) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, u := range r.endpoints {
+		wg.Add(1)
+		go func(u *url.URL) {
+			defer wg.Done()
+			if err := r.gatherURL(acc, u); err != nil {
+				acc.AddError(fmt.Errorf("[url=%s]: %w", u.Redacted(), err))
+			}
+		}(u)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (r *RemoteFile) gatherURL(acc telegraf.Accumulator, u *url.URL) error {
+	f, err := r.connect(u)
+	if err != nil {
+		return fmt.Errorf("connecting failed: %w", err)
+	}
+	defer f.close()
+
+	files, err := f.list(u.Path)
+	if err != nil {
+		return fmt.Errorf("listing files failed: %w", err)
+	}
+
+	for _, file := range files {
+		key := stateKey(u, file.path)
+		if err := r.gatherFile(acc, f, key, file); err != nil {
+			acc.AddError(fmt.Errorf("[url=%s]: processing %q failed: %w", u.Redacted(), file.path, err))
+		}
+	}
+	return nil
+}
+
+func (r *RemoteFile) gatherFile(acc telegraf.Accumulator, f fetcher, key string, file remoteFile) error {
+	r.Lock()
+	state, found := r.states[key]
+	r.Unlock()
+
+	var offset int64
+	switch r.ReadMode {
+	case "modified":
+		if found && state.ModTime.Equal(file.modTime) && state.Size == file.size {
+			return nil
+		}
+	case "append":
+		offset = state.Offset
+		if file.size >= 0 && file.size < offset {
+			r.Log.Infof("File %q was truncated, reading from the beginning", file.path)
+			offset = 0
+			r.Lock()
+			delete(r.parsers, key)
+			r.Unlock()
+		}
+		if found && file.size >= 0 && file.size == offset {
+			return nil
+		}
+	}
+
+	if r.MaxFileSize > 0 && file.size-offset > int64(r.MaxFileSize) {
+		return fmt.Errorf("size %d exceeds the maximum file size", file.size-offset)
+	}
+
+	reader, err := f.open(file, offset)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var content []byte
+	if r.MaxFileSize > 0 {
+		content, err = io.ReadAll(io.LimitReader(reader, int64(r.MaxFileSize)+1))
+		if err == nil && int64(len(content)) > int64(r.MaxFileSize) {
+			err = errors.New("content exceeds the maximum file size")
+		}
+	} else {
+		content, err = io.ReadAll(reader)
+	}
+	if err != nil {
+		return fmt.Errorf("reading failed: %w", err)
+	}
+
+	// Only process complete lines in append mode as the file might still be
+	// written to
+	if r.ReadMode == "append" {
+		end := bytes.LastIndexByte(content, '\n')
+		if end < 0 {
+			return nil
+		}
+		content = content[:end+1]
+	}
+
+	parser, err := r.parser(key)
+	if err != nil {
+		return fmt.Errorf("instantiating parser failed: %w", err)
+	}
+	metrics, err := parser.Parse(content)
+	if err != nil {
+		return fmt.Errorf("parsing failed: %w", err)
+	}
+	for _, m := range metrics {
+		if r.FileTag != "" {
+			m.AddTag(r.FileTag, file.path)
+		}
+		acc.AddMetric(m)
+	}
+
+	r.Lock()
+	r.states[key] = fileState{
+		ModTime: file.modTime,
+		Size:    file.size,
+		Offset:  offset + int64(len(content)),
+	}
+	r.Unlock()
+
+	return nil
+}
+
+// parser returns the parser for the file. In append mode the parser is kept
+// to allow stateful parsers to process the following content.
+func (r *RemoteFile) parser(key string) (telegraf.Parser, error) {
+	if r.ReadMode != "append" {
+		return r.parserFunc()
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	if parser, found := r.parsers[key]; found {
+		return parser, nil
+	}
+	parser, err := r.parserFunc()
+	if err != nil {
+		return nil, err
+	}
+	r.parsers[key] = parser
+	return parser, nil
+}
+
+func (r *RemoteFile) connect(u *url.URL) (fetcher, error) {
+	username, password, err := r.credentials(u)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(r.Timeout)
+
+	switch u.Scheme {
+	case "sftp":
+		return r.sftp.connect(u.Host, username, password, timeout)
+	case "ftp", "ftps":
+		var tlsCfg *tls.Config
+		if u.Scheme == "ftps" {
+			tlsCfg = r.tlsCfg
+			if tlsCfg == nil {
+				tlsCfg = &tls.Config{MinVersion: tls.VersionTLS12}
+			}
+		}
+		return connectFTP(u.Host, username, password, tlsCfg, timeout)
+	default:
+		return newHTTPFetcher(u, username, password, r.tlsCfg, timeout), nil
+	}
+}
+
+// credentials returns the configured credentials falling back to the ones
+// of the URL
+func (r *RemoteFile) credentials(u *url.URL) (string, string, error) {
+	var username, password string
+	if u.User != nil {
+		username = u.User.Username()
+		password, _ = u.User.Password()
+	}
+
+	if !r.Username.Empty() {
+		secret, err := r.Username.Get()
+		if err != nil {
+			return "", "", fmt.Errorf("getting username failed: %w", err)
+		}
+		username = string(secret)
+		config.ReleaseSecret(secret)
+	}
+	if !r.Password.Empty() {
+		secret, err := r.Password.Get()
+		if err != nil {
+			return "", "", fmt.Errorf("getting password failed: %w", err)
+		}
+		password = string(secret)
+		config.ReleaseSecret(secret)
+	}
+	return username, password, nil
+}
+
+// stateKey identifies a file across all endpoints without credentials
+func stateKey(u *url.URL, file string) string {
+	return u.Scheme + "://" + u.Host + path.Clean("/"+file)
+}
+
+func init() {
+	inputs.Add("remote_file", func() telegraf.Input {
+		return &RemoteFile{
+			ReadMode: "modified",
+			Timeout:  config.Duration(30 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,586 @@
+package remote_file
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newParser() (telegraf.Parser, error) {
+	parser := &influx.Parser{}
+	err := parser.Init()
+	return parser, err
+}
+
+type ftpFile struct {
+	content []byte
+	modTime time.Time
+}
+
+// fakeFTPServer is a minimal FTP server serving files from memory in
+// extended passive mode
+type fakeFTPServer struct {
+	listener net.Listener
+	files    map[string]ftpFile
+	sync.Mutex
+}
+
+func newFakeFTPServer(t *testing.T) *fakeFTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeFTPServer{listener: listener, files: make(map[string]ftpFile)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+
+	return s
+}
+
+func (s *fakeFTPServer) setFile(name, content string, modTime time.Time) {
+	s.Lock()
+	defer s.Unlock()
+	s.files[name] = ftpFile{content: []byte(content), modTime: modTime}
+}
+
+func (s *fakeFTPServer) file(name string) (ftpFile, bool) {
+	s.Lock()
+	defer s.Unlock()
+	f, found := s.files[name]
+	return f, found
+}
+
+func (s *fakeFTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reply := func(code int, msg string) {
+		fmt.Fprintf(conn, "%d %s\r\n", code, msg)
+	}
+	reply(220, "ready")
+
+	var user string
+	var offset int64
+	var data net.Listener
+	transfer := func(content []byte) {
+		if data == nil {
+			reply(425, "no data connection")
+			return
+		}
+		defer func() {
+			data.Close()
+			data = nil
+		}()
+		reply(150, "opening data connection")
+		dc, err := data.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = dc.Write(content)
+		dc.Close()
+		reply(226, "transfer complete")
+	}
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		command, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		switch command {
+		case "USER":
+			user = arg
+			reply(331, "password required")
+		case "PASS":
+			if user != "telegraf" || arg != "secret" {
+				reply(530, "login incorrect")
+				continue
+			}
+			reply(230, "logged in")
+		case "TYPE":
+			reply(200, "type set")
+		case "EPSV":
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				reply(425, err.Error())
+				continue
+			}
+			data = listener
+			port := listener.Addr().(*net.TCPAddr).Port
+			reply(229, fmt.Sprintf("Entering Extended Passive Mode (|||%d|)", port))
+		case "NLST":
+			s.Lock()
+			var names []string
+			for name := range s.files {
+				if path.Dir(name) == path.Clean(arg) {
+					names = append(names, path.Base(name))
+				}
+			}
+			s.Unlock()
+			sort.Strings(names)
+			transfer([]byte(strings.Join(names, "\r\n") + "\r\n"))
+		case "SIZE":
+			f, found := s.file(arg)
+			if !found {
+				reply(550, "no such file")
+				continue
+			}
+			reply(213, strconv.Itoa(len(f.content)))
+		case "MDTM":
+			f, found := s.file(arg)
+			if !found {
+				reply(550, "no such file")
+				continue
+			}
+			reply(213, f.modTime.UTC().Format("20060102150405"))
+		case "REST":
+			v, err := strconv.ParseInt(arg, 10, 64)
+			if err != nil {
+				reply(501, "invalid offset")
+				continue
+			}
+			offset = v
+			reply(350, "restarting")
+		case "RETR":
+			f, found := s.file(arg)
+			if !found {
+				reply(550, "no such file")
+				continue
+			}
+			transfer(f.content[offset:])
+			offset = 0
+		case "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			reply(502, "not implemented")
+		}
+	}
+}
+
+// newFakeSFTPServer starts a SSH server providing the SFTP subsystem for the
+// local filesystem
+func newFakeSFTPServer(t *testing.T) string {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == "telegraf" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+	}
+	cfg.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSFTP(conn, cfg)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+
+	return listener.Addr().String()
+}
+
+func serveSFTP(conn net.Conn, cfg *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			for req := range requests {
+				_ = req.Reply(req.Type == "subsystem" && string(req.Payload[4:]) == "sftp", nil)
+			}
+		}()
+		go func() {
+			defer channel.Close()
+			server, err := sftp.NewServer(channel)
+			if err != nil {
+				return
+			}
+			_ = server.Serve()
+		}()
+	}
+}
+
+func TestHTTPModified(t *testing.T) {
+	var content string
+	modTime := time.Unix(1690000000, 0)
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if !ok || user != "telegraf" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		http.ServeContent(w, r, "metrics.influx", modTime, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	mu.Lock()
+	content = "cpu usage=12.5 1690000000000000000\n"
+	mu.Unlock()
+
+	plugin := &RemoteFile{
+		URLs:     []string{"http://telegraf:secret@" + server.Listener.Addr().String() + "/metrics.influx"},
+		FileTag:  "file",
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+		ReadMode: "modified",
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"file": "/metrics.influx"},
+			map[string]interface{}{"usage": 12.5},
+			time.Unix(0, 1690000000000000000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Unchanged files are not processed again
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// Modified files are processed completely
+	mu.Lock()
+	content = "cpu usage=12.5 1690000000000000000\ncpu usage=42.0 1690000010000000000\n"
+	modTime = modTime.Add(10 * time.Second)
+	mu.Unlock()
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 2)
+}
+
+func TestHTTPAppend(t *testing.T) {
+	var content string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		http.ServeContent(w, r, "metrics.influx", time.Unix(1690000000, 0), strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	mu.Lock()
+	content = "cpu usage=12.5 1690000000000000000\ncpu usage=13"
+	mu.Unlock()
+
+	plugin := &RemoteFile{
+		URLs:     []string{server.URL + "/metrics.influx"},
+		ReadMode: "append",
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	// Incomplete lines are not processed
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	// Only the appended content is processed
+	mu.Lock()
+	content += ".5 1690000010000000000\n"
+	mu.Unlock()
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"usage": 13.5},
+			time.Unix(0, 1690000010000000000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Truncated files are read from the beginning
+	mu.Lock()
+	content = "mem used=1i 1690000020000000000\n"
+	mu.Unlock()
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.Equal(t, "mem", acc.GetTelegrafMetrics()[0].Name())
+}
+
+func TestFTP(t *testing.T) {
+	server := newFakeFTPServer(t)
+	modTime := time.Unix(1690000000, 0)
+	server.setFile("/data/a.influx", "cpu usage=1 1690000000000000000\n", modTime)
+	server.setFile("/data/b.influx", "cpu usage=2 1690000000000000000\n", modTime)
+	server.setFile("/data/c.csv", "ignored\n", modTime)
+
+	plugin := &RemoteFile{
+		URLs:     []string{"ftp://" + server.listener.Addr().String() + "/data/*.influx"},
+		Username: config.NewSecret([]byte("telegraf")),
+		Password: config.NewSecret([]byte("secret")),
+		ReadMode: "append",
+		FileTag:  "file",
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"file": "/data/a.influx"},
+			map[string]interface{}{"usage": 1.0},
+			time.Unix(0, 1690000000000000000),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"file": "/data/b.influx"},
+			map[string]interface{}{"usage": 2.0},
+			time.Unix(0, 1690000000000000000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+
+	// Resume the transfer at the processed offset
+	server.setFile("/data/a.influx", "cpu usage=1 1690000000000000000\ncpu usage=3 1690000010000000000\n", modTime)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	expected = []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"file": "/data/a.influx"},
+			map[string]interface{}{"usage": 3.0},
+			time.Unix(0, 1690000010000000000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestFTPLoginFailure(t *testing.T) {
+	server := newFakeFTPServer(t)
+
+	plugin := &RemoteFile{
+		URLs:     []string{"ftp://telegraf:wrong@" + server.listener.Addr().String() + "/data/a.influx"},
+		ReadMode: "modified",
+		Timeout:  config.Duration(5 * time.Second),
+		Log:      testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "login failed")
+	require.NotContains(t, acc.Errors[0].Error(), "wrong")
+}
+
+func TestSFTP(t *testing.T) {
+	address := newFakeSFTPServer(t)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.influx"), []byte("cpu usage=1 1690000000000000000\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.txt"), []byte("ignored\n"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub.influx"), 0700))
+
+	plugin := &RemoteFile{
+		URLs:                  []string{"sftp://" + address + filepath.ToSlash(dir) + "/*.influx"},
+		Username:              config.NewSecret([]byte("telegraf")),
+		Password:              config.NewSecret([]byte("secret")),
+		InsecureIgnoreHostKey: true,
+		ReadMode:              "modified",
+		Timeout:               config.Duration(5 * time.Second),
+		Log:                   testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestState(t *testing.T) {
+	server := newFakeFTPServer(t)
+	server.setFile("/data/a.influx", "cpu usage=1 1690000000000000000\n", time.Unix(1690000000, 0))
+
+	newPlugin := func() *RemoteFile {
+		plugin := &RemoteFile{
+			URLs:     []string{"ftp://telegraf:secret@" + server.listener.Addr().String() + "/data/a.influx"},
+			ReadMode: "append",
+			Timeout:  config.Duration(5 * time.Second),
+			Log:      testutil.Logger{},
+		}
+		plugin.SetParserFunc(newParser)
+		require.NoError(t, plugin.Init())
+		return plugin
+	}
+
+	plugin := newPlugin()
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	state, ok := plugin.GetState().(map[string]fileState)
+	require.True(t, ok)
+	key := "ftp://" + server.listener.Addr().String() + "/data/a.influx"
+	require.Contains(t, state, key)
+	require.Equal(t, int64(32), state[key].Offset)
+
+	// Restoring the state prevents processing the content again
+	restored := newPlugin()
+	require.NoError(t, restored.SetState(state))
+	acc.ClearMetrics()
+	require.NoError(t, restored.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	require.Error(t, restored.SetState("invalid"))
+}
+
+func TestMaxFileSize(t *testing.T) {
+	server := newFakeFTPServer(t)
+	server.setFile("/data/a.influx", strings.Repeat("cpu usage=1 1690000000000000000\n", 10), time.Unix(1690000000, 0))
+
+	plugin := &RemoteFile{
+		URLs:        []string{"ftp://telegraf:secret@" + server.listener.Addr().String() + "/data/a.influx"},
+		ReadMode:    "modified",
+		MaxFileSize: config.Size(100),
+		Timeout:     config.Duration(5 * time.Second),
+		Log:         testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "exceeds the maximum file size")
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *RemoteFile
+		expected string
+	}{
+		{
+			name:     "no urls",
+			plugin:   &RemoteFile{},
+			expected: "no urls specified",
+		},
+		{
+			name:     "invalid read mode",
+			plugin:   &RemoteFile{URLs: []string{"ftp://localhost/a.csv"}, ReadMode: "tail"},
+			expected: "invalid read_mode",
+		},
+		{
+			name:     "unsupported scheme",
+			plugin:   &RemoteFile{URLs: []string{"smb://localhost/a.csv"}},
+			expected: "unsupported scheme",
+		},
+		{
+			name:     "missing path",
+			plugin:   &RemoteFile{URLs: []string{"https://localhost/"}},
+			expected: "missing file path",
+		},
+		{
+			name:     "no host key verification",
+			plugin:   &RemoteFile{URLs: []string{"sftp://localhost/a.csv"}},
+			expected: "either known_hosts or insecure_ignore_host_key must be set",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestHasMeta(t *testing.T) {
+	require.True(t, hasMeta("*.csv"))
+	require.True(t, hasMeta("file[0-9].csv"))
+	require.False(t, hasMeta("file.csv"))
+}
@@ -0,0 +1,142 @@
+package remote_file
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// sftpConfig holds the SSH settings shared by all SFTP connections
+type sftpConfig struct {
+	signers         []ssh.Signer
+	hostKeyCallback ssh.HostKeyCallback
+}
+
+func (r *RemoteFile) newSFTPConfig() (*sftpConfig, error) {
+	cfg := &sftpConfig{}
+
+	switch {
+	case r.InsecureIgnoreHostKey:
+		//nolint:gosec // G106: Explicitly requested by the user
+		cfg.hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	case r.KnownHosts != "":
+		callback, err := knownhosts.New(r.KnownHosts)
+		if err != nil {
+			return nil, fmt.Errorf("loading known hosts failed: %w", err)
+		}
+		cfg.hostKeyCallback = callback
+	default:
+		return nil, errors.New("either known_hosts or insecure_ignore_host_key must be set for SFTP")
+	}
+
+	if r.PrivateKey != "" {
+		key, err := os.ReadFile(r.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("reading private key failed: %w", err)
+		}
+		var signer ssh.Signer
+		if r.PrivateKeyPassphrase.Empty() {
+			signer, err = ssh.ParsePrivateKey(key)
+		} else {
+			passphrase, perr := r.PrivateKeyPassphrase.Get()
+			if perr != nil {
+				return nil, fmt.Errorf("getting passphrase failed: %w", perr)
+			}
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
+			config.ReleaseSecret(passphrase)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing private key failed: %w", err)
+		}
+		cfg.signers = append(cfg.signers, signer)
+	}
+
+	return cfg, nil
+}
+
+type sftpFetcher struct {
+	conn   *ssh.Client
+	client *sftp.Client
+}
+
+func (c *sftpConfig) connect(host, username, password string, timeout time.Duration) (fetcher, error) {
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+
+	var methods []ssh.AuthMethod
+	if len(c.signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(c.signers...))
+	}
+	if password != "" {
+		methods = append(methods, ssh.Password(password))
+	}
+
+	conn, err := ssh.Dial("tcp", host, &ssh.ClientConfig{
+		User:            username,
+		Auth:            methods,
+		HostKeyCallback: c.hostKeyCallback,
+		Timeout:         timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("starting SFTP session failed: %w", err)
+	}
+	return &sftpFetcher{conn: conn, client: client}, nil
+}
+
+func (f *sftpFetcher) list(pattern string) ([]remoteFile, error) {
+	matches, err := f.client.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]remoteFile, 0, len(matches))
+	for _, match := range matches {
+		info, err := f.client.Stat(match)
+		if err != nil {
+			return nil, err
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, remoteFile{
+			path:    path.Clean(match),
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		})
+	}
+	return files, nil
+}
+
+func (f *sftpFetcher) open(file remoteFile, offset int64) (io.ReadCloser, error) {
+	r, err := f.client.Open(file.path)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := r.Seek(offset, io.SeekStart); err != nil {
+			r.Close()
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (f *sftpFetcher) close() error {
+	return errors.Join(f.client.Close(), f.conn.Close())
+}
@@ -0,0 +1,310 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package smb_client
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Names of the SMB2/3 commands in the order of the kernel's statistics
+var commands = []string{
+	"NEGOTIATE",
+	"SESSION_SETUP",
+	"LOGOFF",
+	"TREE_CONNECT",
+	"TREE_DISCONNECT",
+	"CREATE",
+	"CLOSE",
+	"FLUSH",
+	"READ",
+	"WRITE",
+	"LOCK",
+	"IOCTL",
+	"CANCEL",
+	"ECHO",
+	"QUERY_DIRECTORY",
+	"CHANGE_NOTIFY",
+	"QUERY_INFO",
+	"SET_INFO",
+	"OPLOCK_BREAK",
+}
+
+var (
+	globalPatterns = []struct {
+		re     *regexp.Regexp
+		fields []string
+	}{
+		{regexp.MustCompile(`^CIFS Session: (\d+)$`), []string{"sessions"}},
+		{regexp.MustCompile(`^Share \(unique mount targets\): (\d+)$`), []string{"shares"}},
+		{regexp.MustCompile(`^SMB Request/Response Buffer: (\d+) Pool size: (\d+)$`), []string{"buffers", "buffers_pool_size"}},
+		{regexp.MustCompile(`^SMB Small Req/Resp Buffer: (\d+) Pool size: (\d+)$`), []string{"small_buffers", "small_buffers_pool_size"}},
+		{regexp.MustCompile(`^Total Large (\d+) Small (\d+) Allocations$`), []string{"buffer_allocations", "small_buffer_allocations"}},
+		{regexp.MustCompile(`^Operations \(MIDs\): (\d+)$`), []string{"mids"}},
+		{regexp.MustCompile(`^(\d+) session (\d+) share reconnects$`), []string{"session_reconnects", "share_reconnects"}},
+		{regexp.MustCompile(`^Total vfs operations: (\d+) maximum at one time: (\d+)$`), []string{"vfs_operations", "vfs_operations_max"}},
+	}
+
+	maxInFlightRe = regexp.MustCompile(`^Max requests in flight: (\d+)$`)
+	jiffiesRe     = regexp.MustCompile(`jiffies \((\d+) per second\)`)
+	commandRe     = regexp.MustCompile(`^(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s+(\d+)$`)
+	shareRe       = regexp.MustCompile(`^\d+\) (\S+)(\s+DISCONNECTED)?$`)
+	smbsRe        = regexp.MustCompile(`^SMBs: (\d+)$`)
+	bytesRe       = regexp.MustCompile(`^Bytes read: (\d+)\s+Bytes written: (\d+)$`)
+	openFilesRe   = regexp.MustCompile(`^Open files: (\d+) total \(local\), (\d+) open on server$`)
+	shareOpRe     = regexp.MustCompile(`^(\w+): (\d+) (?:total|sent) (\d+) failed$`)
+)
+
+type SMBClient struct {
+	StatsFile string          `toml:"stats_file"`
+	Log       telegraf.Logger `toml:"-"`
+}
+
+// commandStats accumulates the statistics of a command across all servers
+type commandStats struct {
+	count   uint64
+	time    uint64
+	fastest uint64
+	slowest uint64
+}
+
+type share struct {
+	name         string
+	disconnected bool
+	fields       map[string]interface{}
+}
+
+func (*SMBClient) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SMBClient) Init() error {
+	if s.StatsFile == "" {
+		procPath := "/proc"
+		if os.Getenv("HOST_PROC") != "" {
+			procPath = os.Getenv("HOST_PROC")
+		}
+		s.StatsFile = filepath.Join(procPath, "fs", "cifs", "Stats")
+	}
+	return nil
+}
+
+func (s *SMBClient) Gather(acc telegraf.Accumulator) error {
+	file, err := os.Open(s.StatsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			s.Log.Debugf("Stats file %q not found, is the cifs module loaded?", s.StatsFile)
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	return s.parse(file, acc)
+}
+
+func (s *SMBClient) parse(r io.Reader, acc telegraf.Accumulator) error {
+	global := make(map[string]interface{})
+	stats := make([]commandStats, len(commands))
+	var hasCommands bool
+	var maxInFlight uint64
+	hz := uint64(100)
+
+	var shares []*share
+	var current *share
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		// Shares are listed after the statistics of the server they are
+		// connected to, so the next server's statistics end the share
+		if m := maxInFlightRe.FindStringSubmatch(line); m != nil {
+			current = nil
+			v, err := strconv.ParseUint(m[1], 10, 64)
+			if err != nil {
+				return fmt.Errorf("parsing %q failed: %w", line, err)
+			}
+			if v > maxInFlight {
+				maxInFlight = v
+			}
+			global["max_requests_in_flight"] = maxInFlight
+			continue
+		}
+		if m := jiffiesRe.FindStringSubmatch(line); m != nil {
+			v, err := strconv.ParseUint(m[1], 10, 64)
+			if err != nil || v == 0 {
+				return fmt.Errorf("invalid time unit in %q", line)
+			}
+			hz = v
+			continue
+		}
+		if m := commandRe.FindStringSubmatch(line); m != nil {
+			values, err := parseUints(m[1:])
+			if err != nil {
+				return fmt.Errorf("parsing %q failed: %w", line, err)
+			}
+			idx := values[0]
+			if idx >= uint64(len(stats)) {
+				continue
+			}
+			hasCommands = true
+			stat := &stats[idx]
+			if values[1] > 0 && (stat.count == 0 || values[3] < stat.fastest) {
+				stat.fastest = values[3]
+			}
+			if values[4] > stat.slowest {
+				stat.slowest = values[4]
+			}
+			stat.count += values[1]
+			stat.time += values[2]
+			continue
+		}
+		if m := shareRe.FindStringSubmatch(line); m != nil {
+			current = &share{
+				name:         m[1],
+				disconnected: m[2] != "",
+				fields:       make(map[string]interface{}),
+			}
+			shares = append(shares, current)
+			continue
+		}
+
+		if current == nil {
+			for _, p := range globalPatterns {
+				m := p.re.FindStringSubmatch(line)
+				if m == nil {
+					continue
+				}
+				values, err := parseUints(m[1:])
+				if err != nil {
+					return fmt.Errorf("parsing %q failed: %w", line, err)
+				}
+				for i, name := range p.fields {
+					global[name] = values[i]
+				}
+				break
+			}
+			continue
+		}
+
+		if err := current.parse(line); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(global) > 0 {
+		acc.AddCounter("smb_client", global, map[string]string{})
+	}
+
+	for _, sh := range shares {
+		sh.fields["disconnected"] = sh.disconnected
+		acc.AddCounter("smb_client_share", sh.fields, map[string]string{"share": sh.name})
+	}
+
+	if hasCommands {
+		// Convert the times from jiffies to milliseconds
+		scale := 1000.0 / float64(hz)
+		for i, stat := range stats {
+			fields := map[string]interface{}{
+				"count":         stat.count,
+				"total_time_ms": float64(stat.time) * scale,
+				"fastest_ms":    float64(stat.fastest) * scale,
+				"slowest_ms":    float64(stat.slowest) * scale,
+			}
+			acc.AddCounter("smb_client_command", fields, map[string]string{"command": commands[i]})
+		}
+	}
+
+	return nil
+}
+
+func (sh *share) parse(line string) error {
+	if m := smbsRe.FindStringSubmatch(line); m != nil {
+		values, err := parseUints(m[1:])
+		if err != nil {
+			return fmt.Errorf("parsing %q failed: %w", line, err)
+		}
+		sh.fields["smbs"] = values[0]
+		return nil
+	}
+	if m := bytesRe.FindStringSubmatch(line); m != nil {
+		values, err := parseUints(m[1:])
+		if err != nil {
+			return fmt.Errorf("parsing %q failed: %w", line, err)
+		}
+		sh.fields["bytes_read"] = values[0]
+		sh.fields["bytes_written"] = values[1]
+		return nil
+	}
+	if m := openFilesRe.FindStringSubmatch(line); m != nil {
+		values, err := parseUints(m[1:])
+		if err != nil {
+			return fmt.Errorf("parsing %q failed: %w", line, err)
+		}
+		sh.fields["open_files"] = values[0]
+		sh.fields["open_files_server"] = values[1]
+		return nil
+	}
+	if m := shareOpRe.FindStringSubmatch(line); m != nil {
+		values, err := parseUints(m[2:])
+		if err != nil {
+			return fmt.Errorf("parsing %q failed: %w", line, err)
+		}
+		name := snakeCase(m[1])
+		sh.fields[name] = values[0]
+		sh.fields[name+"_failed"] = values[1]
+	}
+	return nil
+}
+
+func parseUints(raw []string) ([]uint64, error) {
+	values := make([]uint64, 0, len(raw))
+	for _, r := range raw {
+		v, err := strconv.ParseUint(r, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// snakeCase converts names like "QueryDirectories" to "query_directories"
+// keeping abbreviations like "IOCTLs" together
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func init() {
+	inputs.Add("smb_client", func() telegraf.Input {
+		return &SMBClient{}
+	})
+}
@@ -0,0 +1,173 @@
+package smb_client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestGather(t *testing.T) {
+	plugin := &SMBClient{
+		StatsFile: filepath.Join("testdata", "Stats"),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	expectedGlobal := metric.New(
+		"smb_client",
+		map[string]string{},
+		map[string]interface{}{
+			"sessions":                 uint64(2),
+			"shares":                   uint64(3),
+			"buffers":                  uint64(1),
+			"buffers_pool_size":        uint64(5),
+			"small_buffers":            uint64(1),
+			"small_buffers_pool_size":  uint64(30),
+			"buffer_allocations":       uint64(23),
+			"small_buffer_allocations": uint64(1546),
+			"mids":                     uint64(0),
+			"session_reconnects":       uint64(1),
+			"share_reconnects":         uint64(2),
+			"vfs_operations":           uint64(1234),
+			"vfs_operations_max":       uint64(3),
+			"max_requests_in_flight":   uint64(8),
+		},
+		time.Unix(0, 0),
+		telegraf.Counter,
+	)
+	expectedShares := []telegraf.Metric{
+		metric.New(
+			"smb_client_share",
+			map[string]string{"share": `\\fileserver\projects`},
+			map[string]interface{}{
+				"disconnected":             false,
+				"smbs":                     uint64(181),
+				"bytes_read":               uint64(1048576),
+				"bytes_written":            uint64(524288),
+				"open_files":               uint64(2),
+				"open_files_server":        uint64(1),
+				"tree_connects":            uint64(1),
+				"tree_connects_failed":     uint64(0),
+				"tree_disconnects":         uint64(0),
+				"tree_disconnects_failed":  uint64(0),
+				"creates":                  uint64(80),
+				"creates_failed":           uint64(2),
+				"closes":                   uint64(78),
+				"closes_failed":            uint64(0),
+				"flushes":                  uint64(0),
+				"flushes_failed":           uint64(0),
+				"reads":                    uint64(30),
+				"reads_failed":             uint64(0),
+				"writes":                   uint64(20),
+				"writes_failed":            uint64(0),
+				"locks":                    uint64(0),
+				"locks_failed":             uint64(0),
+				"ioctls":                   uint64(1),
+				"ioctls_failed":            uint64(1),
+				"query_directories":        uint64(4),
+				"query_directories_failed": uint64(0),
+				"change_notifies":          uint64(0),
+				"change_notifies_failed":   uint64(0),
+				"query_infos":              uint64(24),
+				"query_infos_failed":       uint64(0),
+				"set_infos":                uint64(2),
+				"set_infos_failed":         uint64(0),
+				"oplock_breaks":            uint64(0),
+				"oplock_breaks_failed":     uint64(0),
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+	}
+
+	var global, shares, commands []telegraf.Metric
+	for _, m := range acc.GetTelegrafMetrics() {
+		switch m.Name() {
+		case "smb_client":
+			global = append(global, m)
+		case "smb_client_share":
+			shares = append(shares, m)
+		case "smb_client_command":
+			commands = append(commands, m)
+		}
+	}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expectedGlobal}, global, testutil.IgnoreTime())
+	testutil.RequireMetricsEqual(t, expectedShares, shares[:1], testutil.IgnoreTime())
+
+	require.Len(t, shares, 2)
+	disconnected, found := shares[1].GetField("disconnected")
+	require.True(t, found)
+	require.Equal(t, true, disconnected)
+	require.Equal(t, `\\nas\backup`, shares[1].Tags()["share"])
+
+	// Command statistics are accumulated across servers
+	require.Len(t, commands, 19)
+	expectedRead := metric.New(
+		"smb_client_command",
+		map[string]string{"command": "READ"},
+		map[string]interface{}{
+			"count":         uint64(50),
+			"total_time_ms": float64(3000),
+			"fastest_ms":    float64(8),
+			"slowest_ms":    float64(400),
+		},
+		time.Unix(0, 0),
+		telegraf.Counter,
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expectedRead}, commands[8:9], testutil.IgnoreTime())
+}
+
+func TestGatherMissingFile(t *testing.T) {
+	plugin := &SMBClient{
+		StatsFile: filepath.Join(t.TempDir(), "Stats"),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestGatherWithoutStats2(t *testing.T) {
+	stats := `Resources in use
+CIFS Session: 1
+Share (unique mount targets): 1
+SMB Request/Response Buffer: 1 Pool size: 5
+Operations (MIDs): 0
+
+0 session 0 share reconnects
+Total vfs operations: 10 maximum at one time: 1
+
+Max requests in flight: 1
+1) \\server\share
+SMBs: 9
+`
+	filename := filepath.Join(t.TempDir(), "Stats")
+	require.NoError(t, os.WriteFile(filename, []byte(stats), 0600))
+
+	plugin := &SMBClient{StatsFile: filename, Log: testutil.Logger{}}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.True(t, acc.HasMeasurement("smb_client"))
+	require.True(t, acc.HasMeasurement("smb_client_share"))
+	require.False(t, acc.HasMeasurement("smb_client_command"))
+}
+
+func TestSnakeCase(t *testing.T) {
+	require.Equal(t, "query_directories", snakeCase("QueryDirectories"))
+	require.Equal(t, "ioctls", snakeCase("IOCTLs"))
+	require.Equal(t, "reads", snakeCase("Reads"))
+}
@@ -12,16 +12,57 @@ import (
 )
 
 type gosmiTranslator struct {
+	paths []string
+	log   telegraf.Logger
+
+	// cache of resolved translations, the MIBs are only loaded on the first
+	// translation missing in the cache
+	cache  *mibCache
+	loaded bool
+	sync.Mutex
 }
 
 func NewGosmiTranslator(paths []string, log telegraf.Logger) (*gosmiTranslator, error) {
 	err := snmp.LoadMibsFromPath(paths, log, &snmp.GosmiMibLoader{})
 	if err == nil {
-		return &gosmiTranslator{}, nil
+		return &gosmiTranslator{paths: paths, log: log, loaded: true}, nil
 	}
 	return nil, err
 }
 
+// newCachingGosmiTranslator creates a translator using the translations
+// persisted in the given file and loading the MIBs on demand
+func newCachingGosmiTranslator(paths []string, filename string, log telegraf.Logger) (*gosmiTranslator, error) {
+	cache, err := loadMibCache(filename, paths, log)
+	if err != nil {
+		return nil, err
+	}
+	return &gosmiTranslator{paths: paths, log: log, cache: cache}, nil
+}
+
+func (g *gosmiTranslator) load() error {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.loaded {
+		return nil
+	}
+	g.log.Debug("Loading MIBs")
+	if err := snmp.LoadMibsFromPath(g.paths, g.log, &snmp.GosmiMibLoader{}); err != nil {
+		return err
+	}
+	g.loaded = true
+	return nil
+}
+
+// persist writes new translations to the cache file, if any
+func (g *gosmiTranslator) persist() error {
+	if g.cache == nil {
+		return nil
+	}
+	return g.cache.persist()
+}
+
 type gosmiSnmpTranslateCache struct {
 	mibName    string
 	oidNum     string
@@ -34,9 +75,27 @@ type gosmiSnmpTranslateCache struct {
 var gosmiSnmpTranslateCachesLock sync.Mutex
 var gosmiSnmpTranslateCaches map[string]gosmiSnmpTranslateCache
 
+// gosmiSnmpTranslateCachesGeneration is the generation of the loaded MIBs the
+// translation cache refers to
+var gosmiSnmpTranslateCachesGeneration uint64
+
 //nolint:revive //function-result-limit conditionally 5 return results allowed
 func (g *gosmiTranslator) SnmpTranslate(oid string) (mibName string, oidNum string, oidText string, conversion string, err error) {
+	if g.cache != nil {
+		if t, found := g.cache.translation(oid); found {
+			return t.MibName, t.OidNum, t.OidText, t.Conversion, nil
+		}
+	}
+
 	mibName, oidNum, oidText, conversion, _, err = g.SnmpTranslateFull(oid)
+	if err == nil && g.cache != nil {
+		g.cache.addTranslation(oid, cachedTranslation{
+			MibName:    mibName,
+			OidNum:     oidNum,
+			OidText:    oidText,
+			Conversion: conversion,
+		})
+	}
 	return mibName, oidNum, oidText, conversion, err
 }
 
@@ -46,7 +105,14 @@ func (g *gosmiTranslator) SnmpTranslateFull(oid string) (
 	conversion string,
 	node gosmi.SmiNode,
 	err error) {
+	if err := g.load(); err != nil {
+		return oid, oid, oid, "", gosmi.SmiNode{}, err
+	}
+
 	gosmiSnmpTranslateCachesLock.Lock()
+	if reloadedMibs(&gosmiSnmpTranslateCachesGeneration) {
+		gosmiSnmpTranslateCaches = nil
+	}
 	if gosmiSnmpTranslateCaches == nil {
 		gosmiSnmpTranslateCaches = map[string]gosmiSnmpTranslateCache{}
 	}
@@ -81,6 +147,7 @@ type gosmiSnmpTableCache struct {
 
 var gosmiSnmpTableCaches map[string]gosmiSnmpTableCache
 var gosmiSnmpTableCachesLock sync.Mutex
+var gosmiSnmpTableCachesGeneration uint64
 
 // snmpTable resolves the given OID as a table, providing information about the
 // table and fields within.
@@ -90,7 +157,23 @@ func (g *gosmiTranslator) SnmpTable(oid string) (
 	mibName string, oidNum string, oidText string,
 	fields []Field,
 	err error) {
+	if g.cache != nil {
+		if t, found := g.cache.table(oid); found {
+			for _, f := range t.Fields {
+				fields = append(fields, Field{Name: f.Name, Oid: f.Oid, IsTag: f.IsTag})
+			}
+			return t.MibName, t.OidNum, t.OidText, fields, nil
+		}
+	}
+
+	if err := g.load(); err != nil {
+		return "", "", "", nil, err
+	}
+
 	gosmiSnmpTableCachesLock.Lock()
+	if reloadedMibs(&gosmiSnmpTableCachesGeneration) {
+		gosmiSnmpTableCaches = nil
+	}
 	if gosmiSnmpTableCaches == nil {
 		gosmiSnmpTableCaches = map[string]gosmiSnmpTableCache{}
 	}
@@ -103,6 +186,14 @@ func (g *gosmiTranslator) SnmpTable(oid string) (
 	}
 
 	gosmiSnmpTableCachesLock.Unlock()
+
+	if stc.err == nil && g.cache != nil {
+		t := cachedTable{MibName: stc.mibName, OidNum: stc.oidNum, OidText: stc.oidText}
+		for _, f := range stc.fields {
+			t.Fields = append(t.Fields, cachedField{Name: f.Name, Oid: f.Oid, IsTag: f.IsTag})
+		}
+		g.cache.addTable(oid, t)
+	}
 	return stc.mibName, stc.oidNum, stc.oidText, stc.fields, stc.err
 }
 
@@ -141,3 +232,15 @@ func (g *gosmiTranslator) SnmpFormatEnum(oid string, value interface{}, full boo
 
 	return v.Formatted, nil
 }
+
+// reloadedMibs reports if the MIBs were reloaded since the given generation,
+// updating the generation. Cached nodes refer to the discarded MIBs in this
+// case and must not be used anymore.
+func reloadedMibs(gen *uint64) bool {
+	current := snmp.MibGeneration()
+	if current == *gen {
+		return false
+	}
+	*gen = current
+	return true
+}
@@ -592,6 +592,55 @@ func TestFieldConvertGosmi(t *testing.T) {
 	}
 }
 
+func TestSnmpInitGosmiMibCache(t *testing.T) {
+	testDataPath, err := filepath.Abs("./testdata")
+	require.NoError(t, err)
+	cacheFile := filepath.Join(t.TempDir(), "mib_cache.json")
+
+	newPlugin := func() *Snmp {
+		return &Snmp{
+			Tables: []Table{
+				{Oid: "RFC1213-MIB::atTable"},
+			},
+			Fields: []Field{
+				{Oid: "RFC1213-MIB::atPhysAddress"},
+			},
+			MibCacheFile: cacheFile,
+			ClientConfig: snmp.ClientConfig{
+				Path:       []string{testDataPath},
+				Translator: "gosmi",
+			},
+			Log: testutil.Logger{},
+		}
+	}
+
+	// The first start resolves the OIDs using the MIBs and fills the cache
+	first := newPlugin()
+	require.NoError(t, first.Init())
+	require.True(t, first.translator.(*gosmiTranslator).loaded)
+	require.FileExists(t, cacheFile)
+
+	// The second start uses the cache without loading the MIBs
+	second := newPlugin()
+	require.NoError(t, second.Init())
+	require.False(t, second.translator.(*gosmiTranslator).loaded)
+	require.Equal(t, first.Tables, second.Tables)
+	require.Equal(t, first.Fields, second.Fields)
+
+	// Translations missing in the cache load the MIBs on demand
+	mibName, _, oidText, _, err := second.translator.SnmpTranslate("RFC1213-MIB::sysName.0")
+	require.NoError(t, err)
+	require.Equal(t, "RFC1213-MIB", mibName)
+	require.Equal(t, "sysName.0", oidText)
+	require.True(t, second.translator.(*gosmiTranslator).loaded)
+
+	// A cache for different MIB files is discarded
+	cache, err := loadMibCache(cacheFile, []string{filepath.Join(testDataPath, "tableMib")}, testutil.Logger{})
+	require.NoError(t, err)
+	require.Empty(t, cache.content.Translations)
+	require.Empty(t, cache.content.Tables)
+}
+
 func TestSnmpTranslateCache_missGosmi(t *testing.T) {
 	gosmiSnmpTranslateCaches = nil
 	oid := "IF-MIB::ifPhysAddress.1"
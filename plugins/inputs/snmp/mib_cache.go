@@ -0,0 +1,193 @@
+package snmp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/snmp"
+)
+
+type cachedTranslation struct {
+	MibName    string `json:"mib_name"`
+	OidNum     string `json:"oid_num"`
+	OidText    string `json:"oid_text"`
+	Conversion string `json:"conversion,omitempty"`
+}
+
+type cachedField struct {
+	Name  string `json:"name"`
+	Oid   string `json:"oid"`
+	IsTag bool   `json:"is_tag,omitempty"`
+}
+
+type cachedTable struct {
+	MibName string        `json:"mib_name"`
+	OidNum  string        `json:"oid_num"`
+	OidText string        `json:"oid_text"`
+	Fields  []cachedField `json:"fields"`
+}
+
+type mibCacheContent struct {
+	Fingerprint  string                       `json:"fingerprint"`
+	Translations map[string]cachedTranslation `json:"translations"`
+	Tables       map[string]cachedTable       `json:"tables"`
+}
+
+// mibCache persists the OIDs and tables resolved using the MIBs, so the MIBs
+// only need to be parsed if the MIB files changed or an unknown OID is used.
+// The cache is invalidated by a fingerprint over the MIB files.
+type mibCache struct {
+	filename string
+	content  mibCacheContent
+	modified bool
+	log      telegraf.Logger
+
+	sync.Mutex
+}
+
+func loadMibCache(filename string, paths []string, log telegraf.Logger) (*mibCache, error) {
+	fingerprint, err := snmp.FingerprintMibPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &mibCache{
+		filename: filename,
+		content: mibCacheContent{
+			Fingerprint:  fingerprint,
+			Translations: make(map[string]cachedTranslation),
+			Tables:       make(map[string]cachedTable),
+		},
+		log: log,
+	}
+
+	content, err := c.read()
+	if err != nil {
+		log.Warnf("Ignoring MIB cache: %v", err)
+		return c, nil
+	}
+	if content == nil {
+		return c, nil
+	}
+	if content.Fingerprint != fingerprint {
+		log.Info("MIB files changed, discarding MIB cache")
+		return c, nil
+	}
+	c.merge(content)
+
+	return c, nil
+}
+
+func (c *mibCache) read() (*mibCacheContent, error) {
+	buf, err := os.ReadFile(c.filename)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var content mibCacheContent
+	if err := json.Unmarshal(buf, &content); err != nil {
+		return nil, fmt.Errorf("decoding %q failed: %w", c.filename, err)
+	}
+	return &content, nil
+}
+
+// merge adds the entries of the given content not known to the cache yet
+func (c *mibCache) merge(content *mibCacheContent) {
+	for oid, t := range content.Translations {
+		if _, found := c.content.Translations[oid]; !found {
+			c.content.Translations[oid] = t
+		}
+	}
+	for oid, t := range content.Tables {
+		if _, found := c.content.Tables[oid]; !found {
+			c.content.Tables[oid] = t
+		}
+	}
+}
+
+func (c *mibCache) translation(oid string) (cachedTranslation, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	t, found := c.content.Translations[oid]
+	return t, found
+}
+
+func (c *mibCache) addTranslation(oid string, t cachedTranslation) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.content.Translations[oid] = t
+	c.modified = true
+}
+
+func (c *mibCache) table(oid string) (cachedTable, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	t, found := c.content.Tables[oid]
+	return t, found
+}
+
+func (c *mibCache) addTable(oid string, t cachedTable) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.content.Tables[oid] = t
+	c.modified = true
+}
+
+// persist writes the cache to disk if new entries were added. Entries written
+// by other plugin instances in the meantime are kept if they refer to the
+// same MIB files.
+func (c *mibCache) persist() error {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.modified {
+		return nil
+	}
+
+	if content, err := c.read(); err == nil && content != nil && content.Fingerprint == c.content.Fingerprint {
+		c.merge(content)
+	}
+
+	buf, err := json.Marshal(c.content)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first to not leave a broken cache behind
+	dir := filepath.Dir(c.filename)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("creating directory %q failed: %w", dir, err)
+	}
+	f, err := os.CreateTemp(dir, filepath.Base(c.filename)+".*")
+	if err != nil {
+		return fmt.Errorf("creating temporary file failed: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %q failed: %w", f.Name(), err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing %q failed: %w", f.Name(), err)
+	}
+	if err := os.Rename(f.Name(), c.filename); err != nil {
+		return fmt.Errorf("replacing %q failed: %w", c.filename, err)
+	}
+	c.modified = false
+
+	c.log.Debugf("Wrote %d translations and %d tables to MIB cache", len(c.content.Translations), len(c.content.Tables))
+	return nil
+}
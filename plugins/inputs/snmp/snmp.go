@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/snmp"
+	"github.com/influxdata/telegraf/plugins/common/workerpool"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -52,6 +53,19 @@ type Snmp struct {
 	// The tag used to name the agent host
 	AgentHostTag string `toml:"agent_host_tag"`
 
+	// Maximum number of agents to query concurrently, 0 means all agents
+	Concurrency int `toml:"concurrency"`
+
+	// Maximum number of concurrent requests across all plugin instances
+	// setting this option, 0 means unlimited
+	GlobalConcurrency int `toml:"global_concurrency"`
+
+	// Maximum number of tables of a single agent to query concurrently
+	AgentConcurrency int `toml:"agent_concurrency"`
+
+	// File to persist the translations resolved from the MIBs in
+	MibCacheFile string `toml:"mib_cache_file"`
+
 	snmp.ClientConfig
 
 	Tables []Table `toml:"table"`
@@ -64,6 +78,11 @@ type Snmp struct {
 
 	connectionCache []snmpConnection
 
+	// additional connections of the agents for querying tables concurrently
+	workerConnections [][]snmpConnection
+
+	limiter chan struct{}
+
 	Log telegraf.Logger `toml:"-"`
 
 	translator Translator
@@ -81,7 +100,11 @@ func (s *Snmp) Init() error {
 	var err error
 	switch s.Translator {
 	case "gosmi":
-		s.translator, err = NewGosmiTranslator(s.Path, s.Log)
+		if s.MibCacheFile != "" {
+			s.translator, err = newCachingGosmiTranslator(s.Path, s.MibCacheFile, s.Log)
+		} else {
+			s.translator, err = NewGosmiTranslator(s.Path, s.Log)
+		}
 		if err != nil {
 			return err
 		}
@@ -92,6 +115,11 @@ func (s *Snmp) Init() error {
 	}
 
 	s.connectionCache = make([]snmpConnection, len(s.Agents))
+	s.workerConnections = make([][]snmpConnection, len(s.Agents))
+
+	if s.GlobalConcurrency > 0 {
+		s.limiter = globalLimiter(s.GlobalConcurrency, s.Log)
+	}
 
 	for i := range s.Tables {
 		if err := s.Tables[i].Init(s.translator); err != nil {
@@ -105,6 +133,12 @@ func (s *Snmp) Init() error {
 		}
 	}
 
+	if g, ok := s.translator.(*gosmiTranslator); ok {
+		if err := g.persist(); err != nil {
+			s.Log.Warnf("Writing MIB cache failed: %v", err)
+		}
+	}
+
 	if len(s.AgentHostTag) == 0 {
 		s.AgentHostTag = "agent_host"
 	}
@@ -310,38 +344,115 @@ func (e *walkError) Unwrap() error {
 // Any error encountered does not halt the process. The errors are accumulated
 // and returned at the end.
 func (s *Snmp) Gather(acc telegraf.Accumulator) error {
-	var wg sync.WaitGroup
-	for i, agent := range s.Agents {
-		wg.Add(1)
-		go func(i int, agent string) {
-			defer wg.Done()
-			gs, err := s.getConnection(i)
+	if len(s.workerConnections) != len(s.Agents) {
+		s.workerConnections = make([][]snmpConnection, len(s.Agents))
+	}
+
+	indices := make([]int, len(s.Agents))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	workerpool.Run(s.Concurrency, indices, func(i int) {
+		s.gatherAgent(acc, i)
+	})
+
+	return nil
+}
+
+func (s *Snmp) gatherAgent(acc telegraf.Accumulator, idx int) {
+	agent := s.Agents[idx]
+	gs, err := s.getConnection(idx)
+	if err != nil {
+		acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+		return
+	}
+
+	// First is the top-level fields. We treat the fields as table prefixes with an empty index.
+	t := Table{
+		Name:   s.Name,
+		Fields: s.Fields,
+	}
+	topTags := map[string]string{}
+	s.acquire()
+	err = s.gatherTable(acc, gs, t, topTags, false)
+	s.release()
+	if err != nil {
+		acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+	}
+
+	// Now is the real tables. The top-level tags are only read from here on,
+	// so the tables can be queried concurrently using one connection each.
+	workers := s.AgentConcurrency
+	if workers < 1 {
+		workers = 1
+	}
+	pool := make(chan snmpConnection, workers)
+	pool <- gs
+	for _, conn := range s.workerConnections[idx] {
+		pool <- conn
+	}
+	var mu sync.Mutex
+	workerpool.Run(workers, s.Tables, func(t Table) {
+		var conn snmpConnection
+		select {
+		case conn = <-pool:
+		default:
+			c, err := s.newConnection(agent)
 			if err != nil {
-				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
+				acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
 				return
 			}
+			mu.Lock()
+			s.workerConnections[idx] = append(s.workerConnections[idx], c)
+			mu.Unlock()
+			conn = c
+		}
+		defer func() { pool <- conn }()
 
-			// First is the top-level fields. We treat the fields as table prefixes with an empty index.
-			t := Table{
-				Name:   s.Name,
-				Fields: s.Fields,
-			}
-			topTags := map[string]string{}
-			if err := s.gatherTable(acc, gs, t, topTags, false); err != nil {
-				acc.AddError(fmt.Errorf("agent %s: %w", agent, err))
-			}
+		if err := conn.Reconnect(); err != nil {
+			acc.AddError(fmt.Errorf("agent %s: gathering table %s: reconnecting: %w", agent, t.Name, err))
+			return
+		}
 
-			// Now is the real tables.
-			for _, t := range s.Tables {
-				if err := s.gatherTable(acc, gs, t, topTags, true); err != nil {
-					acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
-				}
-			}
-		}(i, agent)
+		s.acquire()
+		defer s.release()
+		if err := s.gatherTable(acc, conn, t, topTags, true); err != nil {
+			acc.AddError(fmt.Errorf("agent %s: gathering table %s: %w", agent, t.Name, err))
+		}
+	})
+}
+
+var globalLimiters = struct {
+	sync.Mutex
+	limiter chan struct{}
+}{}
+
+// globalLimiter returns the limiter shared by all plugin instances setting
+// the global concurrency. The limit of the first instance is used.
+func globalLimiter(concurrency int, log telegraf.Logger) chan struct{} {
+	globalLimiters.Lock()
+	defer globalLimiters.Unlock()
+
+	if globalLimiters.limiter == nil {
+		globalLimiters.limiter = make(chan struct{}, concurrency)
+	} else if c := cap(globalLimiters.limiter); c != concurrency {
+		log.Warnf("Ignoring global_concurrency %d, using %d set by another instance", concurrency, c)
 	}
-	wg.Wait()
+	return globalLimiters.limiter
+}
 
-	return nil
+// acquire blocks until the global concurrency limit allows another request
+func (s *Snmp) acquire() {
+	if s.limiter != nil {
+		s.limiter <- struct{}{}
+	}
+}
+
+func (s *Snmp) release() {
+	if s.limiter != nil {
+		<-s.limiter
+	}
 }
 
 func (s *Snmp) gatherTable(acc telegraf.Accumulator, gs snmpConnection, t Table, topTags map[string]string, walk bool) error {
@@ -582,20 +693,10 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 		return gs, nil
 	}
 
-	agent := s.Agents[idx]
-
-	var err error
-	var gs snmp.GosnmpWrapper
-	gs, err = snmp.NewWrapper(s.ClientConfig)
-	if err != nil {
-		return nil, err
-	}
-
-	err = gs.SetAgent(agent)
+	gs, err := s.newConnection(s.Agents[idx])
 	if err != nil {
 		return nil, err
 	}
-
 	s.connectionCache[idx] = gs
 
 	if err := gs.Connect(); err != nil {
@@ -605,6 +706,18 @@ func (s *Snmp) getConnection(idx int) (snmpConnection, error) {
 	return gs, nil
 }
 
+// newConnection creates an unconnected snmpConnection for the given agent.
+func (s *Snmp) newConnection(agent string) (snmp.GosnmpWrapper, error) {
+	gs, err := snmp.NewWrapper(s.ClientConfig)
+	if err != nil {
+		return gs, err
+	}
+	if err := gs.SetAgent(agent); err != nil {
+		return gs, err
+	}
+	return gs, nil
+}
+
 // fieldConvert converts from any type according to the conv specification
 func fieldConvert(tr Translator, conv string, ent gosnmp.SnmpPDU) (v interface{}, err error) {
 	if conv == "" {
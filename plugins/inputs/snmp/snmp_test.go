@@ -6,6 +6,7 @@ import (
 	"net"
 	"os/exec"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -799,6 +800,76 @@ func TestGather(t *testing.T) {
 	assert.Equal(t, 123456, m2.Fields["myOtherField"])
 }
 
+// blockingSNMPConnection records the number of concurrent walks
+type blockingSNMPConnection struct {
+	*testSNMPConnection
+	running    *atomic.Int32
+	maxRunning *atomic.Int32
+}
+
+func (c *blockingSNMPConnection) Walk(oid string, wf gosnmp.WalkFunc) error {
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+	for {
+		current := c.maxRunning.Load()
+		if n <= current || c.maxRunning.CompareAndSwap(current, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return c.testSNMPConnection.Walk(oid, wf)
+}
+
+func TestGatherConcurrency(t *testing.T) {
+	tests := []struct {
+		name              string
+		agentConcurrency  int
+		globalConcurrency int
+		expected          int32
+	}{
+		{name: "serial", agentConcurrency: 1, expected: 1},
+		{name: "agent concurrency", agentConcurrency: 4, expected: 4},
+		{name: "global limit", agentConcurrency: 4, globalConcurrency: 2, expected: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			conns := make([]snmpConnection, 0, tt.agentConcurrency)
+			for i := 0; i < tt.agentConcurrency; i++ {
+				conns = append(conns, &blockingSNMPConnection{tsc, &running, &maxRunning})
+			}
+
+			s := &Snmp{
+				Agents:           []string{"TestGather"},
+				AgentConcurrency: tt.agentConcurrency,
+				connectionCache:  conns[:1],
+				workerConnections: [][]snmpConnection{
+					conns[1:],
+				},
+			}
+			if tt.globalConcurrency > 0 {
+				s.limiter = make(chan struct{}, tt.globalConcurrency)
+			}
+			for i := 0; i < 8; i++ {
+				s.Tables = append(s.Tables, Table{
+					Name:   fmt.Sprintf("table%d", i),
+					Fields: []Field{{Name: "myfield", Oid: ".1.0.0.0.1.5"}},
+				})
+			}
+
+			acc := &testutil.Accumulator{}
+			require.NoError(t, s.Gather(acc))
+			require.Empty(t, acc.Errors)
+			require.Len(t, acc.Metrics, 8)
+			for _, m := range acc.Metrics {
+				require.Equal(t, 123456, m.Fields["myfield"])
+			}
+			require.Equal(t, tt.expected, maxRunning.Load())
+		})
+	}
+}
+
 func TestGather_host(t *testing.T) {
 	s := &Snmp{
 		Agents: []string{"TestGather"},
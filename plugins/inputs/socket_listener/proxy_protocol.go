@@ -0,0 +1,160 @@
+package socket_listener
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// proxyV2Signature is the binary signature starting a PROXY protocol v2 header
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyV1MaxLength is the maximum length of a PROXY protocol v1 header
+// including the trailing CRLF
+const proxyV1MaxLength = 107
+
+// proxyConn is a connection with the remote address taken from the PROXY
+// protocol header sent by a load-balancer or proxy in front of Telegraf
+type proxyConn struct {
+	net.Conn
+	reader *bufio.Reader
+	source net.Addr
+}
+
+func (c *proxyConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	if c.source != nil {
+		return c.source
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// newProxyConn reads the PROXY protocol header (v1 or v2) from the connection
+// and returns a connection reporting the original source address
+func newProxyConn(conn net.Conn) (*proxyConn, error) {
+	reader := bufio.NewReader(conn)
+
+	prefix, err := reader.Peek(6)
+	if err != nil {
+		return nil, fmt.Errorf("reading PROXY protocol header failed: %w", err)
+	}
+
+	var source net.Addr
+	if string(prefix) == "PROXY " {
+		source, err = readProxyV1(reader)
+	} else {
+		var signature []byte
+		signature, err = reader.Peek(len(proxyV2Signature))
+		if err != nil {
+			return nil, fmt.Errorf("reading PROXY protocol header failed: %w", err)
+		}
+		if !bytes.Equal(signature, proxyV2Signature) {
+			return nil, errors.New("missing PROXY protocol header")
+		}
+		source, err = readProxyV2(reader)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyConn{Conn: conn, reader: reader, source: source}, nil
+}
+
+// readProxyV1 parses a human-readable header like
+// "PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n"
+func readProxyV1(reader *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < proxyV1MaxLength {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("reading PROXY protocol v1 header failed: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	header, found := strings.CutSuffix(string(line), "\r\n")
+	if !found {
+		return nil, errors.New("PROXY protocol v1 header not terminated")
+	}
+
+	parts := strings.Split(header, " ")
+	if len(parts) >= 2 && parts[1] == "UNKNOWN" {
+		// The proxy cannot provide the source so use the connection address
+		return nil, nil
+	}
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("invalid PROXY protocol v1 header %q", header)
+	}
+	if parts[1] != "TCP4" && parts[1] != "TCP6" {
+		return nil, fmt.Errorf("unsupported PROXY protocol v1 protocol %q", parts[1])
+	}
+	ip := net.ParseIP(parts[2])
+	if ip == nil {
+		return nil, fmt.Errorf("invalid source address %q in PROXY protocol header", parts[2])
+	}
+	port, err := strconv.ParseUint(parts[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source port %q in PROXY protocol header", parts[4])
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2 parses the binary header
+func readProxyV2(reader *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return nil, fmt.Errorf("reading PROXY protocol v2 header failed: %w", err)
+	}
+	if version := header[12] >> 4; version != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", version)
+	}
+	command := header[12] & 0x0f
+	family := header[13]
+	length := binary.BigEndian.Uint16(header[14:16])
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, fmt.Errorf("reading PROXY protocol v2 addresses failed: %w", err)
+	}
+
+	// LOCAL connections e.g. health-checks of the proxy carry no address
+	if command == 0x0 {
+		return nil, nil
+	}
+	if command != 0x1 {
+		return nil, fmt.Errorf("unsupported PROXY protocol v2 command %d", command)
+	}
+
+	var size int
+	switch family >> 4 {
+	case 0x1: // IPv4
+		size = net.IPv4len
+	case 0x2: // IPv6
+		size = net.IPv6len
+	default:
+		// Unspecified or unix addresses, use the connection address
+		return nil, nil
+	}
+	if len(payload) < 2*size+4 {
+		return nil, errors.New("PROXY protocol v2 address block too short")
+	}
+	ip := net.IP(payload[:size])
+	port := binary.BigEndian.Uint16(payload[2*size : 2*size+2])
+
+	if family&0x0f == 0x2 {
+		return &net.UDPAddr{IP: ip, Port: int(port)}, nil
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
@@ -49,12 +49,16 @@ type SocketListener struct {
 	SplittingDelimiter   string           `toml:"splitting_delimiter"`
 	SplittingLength      int              `toml:"splitting_length"`
 	SplittingLengthField lengthFieldSpec  `toml:"splitting_length_field"`
+	ProxyProtocol        bool             `toml:"proxy_protocol"`
+	ConnectionStats      bool             `toml:"connection_stats"`
+	TLSIdentityTag       string           `toml:"tls_identity_tag"`
 	Log                  telegraf.Logger  `toml:"-"`
 	tlsint.ServerConfig
 
-	wg       sync.WaitGroup
-	parser   telegraf.Parser
-	splitter bufio.SplitFunc
+	wg         sync.WaitGroup
+	parser     telegraf.Parser
+	parserFunc telegraf.ParserFunc
+	splitter   bufio.SplitFunc
 
 	listener listener
 }
@@ -138,6 +142,10 @@ func (sl *SocketListener) SetParser(parser telegraf.Parser) {
 	sl.parser = parser
 }
 
+func (sl *SocketListener) SetParserFunc(fn telegraf.ParserFunc) {
+	sl.parserFunc = fn
+}
+
 func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 	// Resolve the interface to an address if any given
 	var ifname string
@@ -147,6 +155,15 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 		sl.ServiceAddress = strings.Replace(sl.ServiceAddress, "%"+ifname, "", 1)
 	}
 
+	// Packet listeners share a single parser for all senders
+	if sl.parser == nil && sl.parserFunc != nil {
+		parser, err := sl.parserFunc()
+		if err != nil {
+			return fmt.Errorf("creating parser failed: %w", err)
+		}
+		sl.parser = parser
+	}
+
 	// Preparing TLS configuration
 	tlsCfg, err := sl.ServerConfig.TLSConfig()
 	if err != nil {
@@ -167,8 +184,13 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 			KeepAlivePeriod: sl.KeepAlivePeriod,
 			MaxConnections:  sl.MaxConnections,
 			Encoding:        sl.ContentEncoding,
+			ProxyProtocol:   sl.ProxyProtocol,
+			ConnectionStats: sl.ConnectionStats,
+			IdentityTag:     sl.TLSIdentityTag,
+			Identity:        sl.ClientIdentity,
 			Splitter:        sl.splitter,
 			Parser:          sl.parser,
+			ParserFunc:      sl.parserFunc,
 			Log:             sl.Log,
 		}
 
@@ -183,8 +205,13 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 			KeepAlivePeriod: sl.KeepAlivePeriod,
 			MaxConnections:  sl.MaxConnections,
 			Encoding:        sl.ContentEncoding,
+			ProxyProtocol:   sl.ProxyProtocol,
+			ConnectionStats: sl.ConnectionStats,
+			IdentityTag:     sl.TLSIdentityTag,
+			Identity:        sl.ClientIdentity,
 			Splitter:        sl.splitter,
 			Parser:          sl.parser,
+			ParserFunc:      sl.parserFunc,
 			Log:             sl.Log,
 		}
 
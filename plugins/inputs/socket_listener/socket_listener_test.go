@@ -24,6 +24,7 @@ import (
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	_ "github.com/influxdata/telegraf/plugins/parsers/all"
+	"github.com/influxdata/telegraf/plugins/parsers/csv"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -246,6 +247,157 @@ func TestSocketListenerStream(t *testing.T) {
 	require.Empty(t, logger.Warnings())
 }
 
+func TestSocketListenerProxyProtocol(t *testing.T) {
+	v2Header := append([]byte{}, proxyV2Signature...)
+	v2Header = append(v2Header, 0x21, 0x11, 0x00, 0x0c)
+	v2Header = append(v2Header, 192, 168, 1, 10, 10, 0, 0, 1, 0xdb, 0xe8, 0x1f, 0x96)
+
+	tests := []struct {
+		name     string
+		header   []byte
+		source   string
+		expected string
+	}{
+		{
+			name:   "v1",
+			header: []byte("PROXY TCP4 192.168.1.10 10.0.0.1 56296 8086\r\n"),
+			source: "192.168.1.10",
+		},
+		{
+			name:   "v1 IPv6",
+			header: []byte("PROXY TCP6 2001:db8::1 2001:db8::2 56296 8086\r\n"),
+			source: "2001:db8::1",
+		},
+		{
+			name:   "v1 unknown",
+			header: []byte("PROXY UNKNOWN\r\n"),
+			source: "127.0.0.1",
+		},
+		{
+			name:   "v2",
+			header: v2Header,
+			source: "192.168.1.10",
+		},
+		{
+			name:     "missing header",
+			header:   []byte("test value=1i\n"),
+			expected: "missing PROXY protocol header",
+		},
+		{
+			name:     "invalid v1 header",
+			header:   []byte("PROXY TCP4 foo\r\n"),
+			expected: "invalid PROXY protocol v1 header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &SocketListener{
+				Log:             &testutil.Logger{},
+				ServiceAddress:  "tcp://127.0.0.1:0",
+				ProxyProtocol:   true,
+				ConnectionStats: true,
+			}
+			parser := &influx.Parser{}
+			require.NoError(t, parser.Init())
+			plugin.SetParser(parser)
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Init())
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			client, err := createClient(plugin.ServiceAddress, plugin.listener.addr(), nil)
+			require.NoError(t, err)
+			payload := "test value=42i\nbroken\n"
+			_, err = client.Write(append(tt.header, []byte(payload)...))
+			require.NoError(t, err)
+			require.NoError(t, client.Close())
+
+			if tt.expected != "" {
+				require.Eventually(t, func() bool {
+					acc.Lock()
+					defer acc.Unlock()
+					return len(acc.Errors) > 0
+				}, 3*time.Second, 100*time.Millisecond, "did not receive error")
+				require.ErrorContains(t, acc.FirstError(), tt.expected)
+				return
+			}
+
+			require.Eventually(t, func() bool {
+				return acc.HasMeasurement("socket_listener_connection")
+			}, 3*time.Second, 100*time.Millisecond, "did not receive connection statistics")
+			require.Len(t, acc.Errors, 1)
+			require.ErrorContains(t, acc.FirstError(), "parsing error")
+
+			var found bool
+			for _, m := range acc.GetTelegrafMetrics() {
+				if m.Name() != "socket_listener_connection" {
+					continue
+				}
+				found = true
+				require.Equal(t, map[string]string{"source": tt.source}, m.Tags())
+				require.Equal(t, int64(len(payload)), m.Fields()["bytes_received"])
+				require.Equal(t, int64(1), m.Fields()["metrics_received"])
+				require.Equal(t, int64(1), m.Fields()["parse_errors"])
+			}
+			require.True(t, found)
+		})
+	}
+}
+
+func TestSocketListenerParserPerConnection(t *testing.T) {
+	plugin := &SocketListener{
+		Log:            &testutil.Logger{},
+		ServiceAddress: "tcp://127.0.0.1:0",
+	}
+	plugin.SetParserFunc(func() (telegraf.Parser, error) {
+		parser := &csv.Parser{
+			MetricName:     "csv",
+			HeaderRowCount: 1,
+		}
+		err := parser.Init()
+		return parser, err
+	})
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Each client sends its own header which must not be parsed as data by
+	// the parser of the other connection
+	for _, header := range []string{"a,b", "c,d"} {
+		client, err := createClient(plugin.ServiceAddress, plugin.listener.addr(), nil)
+		require.NoError(t, err)
+		_, err = client.Write([]byte(header + "\n1,2\n"))
+		require.NoError(t, err)
+		require.NoError(t, client.Close())
+	}
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"csv",
+			map[string]string{},
+			map[string]interface{}{"a": int64(1), "b": int64(2)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"csv",
+			map[string]string{},
+			map[string]interface{}{"c": int64(1), "d": int64(2)},
+			time.Unix(0, 0),
+		),
+	}
+	require.Eventually(t, func() bool {
+		acc.Lock()
+		defer acc.Unlock()
+		return acc.NMetrics() >= uint64(len(expected))
+	}, 3*time.Second, 100*time.Millisecond, "did not receive metrics")
+	require.Empty(t, acc.Errors)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
 func TestCases(t *testing.T) {
 	// Get all directories in testdata
 	folders, err := os.ReadDir("testcases")
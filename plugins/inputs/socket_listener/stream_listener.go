@@ -29,11 +29,17 @@ type streamListener struct {
 	MaxConnections  int
 	ReadTimeout     config.Duration
 	KeepAlivePeriod *config.Duration
+	ProxyProtocol   bool
+	ConnectionStats bool
+	IdentityTag     string
+	Identity        func(*tls.ConnectionState) string
 	Splitter        bufio.SplitFunc
 	Parser          telegraf.Parser
+	ParserFunc      telegraf.ParserFunc
 	Log             telegraf.Logger
 
 	listener    net.Listener
+	tlsCfg      *tls.Config
 	connections map[net.Conn]struct{}
 	path        string
 
@@ -43,6 +49,12 @@ type streamListener struct {
 
 func (l *streamListener) setupTCP(u *url.URL, tlsCfg *tls.Config) error {
 	var err error
+	if l.ProxyProtocol {
+		// The PROXY protocol header is sent unencrypted before the TLS
+		// handshake, so TLS must be set up after reading the header
+		l.tlsCfg = tlsCfg
+		tlsCfg = nil
+	}
 	if tlsCfg == nil {
 		l.listener, err = net.Listen(u.Scheme, u.Host)
 	} else {
@@ -57,6 +69,10 @@ func (l *streamListener) setupUnix(u *url.URL, tlsCfg *tls.Config, socketMode st
 		return fmt.Errorf("removing socket failed: %w", err)
 	}
 
+	if l.ProxyProtocol {
+		l.tlsCfg = tlsCfg
+		tlsCfg = nil
+	}
 	if tlsCfg == nil {
 		l.listener, err = net.Listen(u.Scheme, u.Path)
 	} else {
@@ -134,6 +150,11 @@ func (l *streamListener) setupConnection(conn net.Conn) error {
 }
 
 func (l *streamListener) closeConnection(conn net.Conn) {
+	// The connections are tracked by their underlying network connection
+	if c, ok := conn.(*tls.Conn); ok {
+		conn = c.NetConn()
+	}
+
 	addr := conn.RemoteAddr().String()
 	if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) && !errors.Is(err, syscall.EPIPE) {
 		l.Log.Warnf("Cannot close connection to %q: %v", addr, err)
@@ -191,27 +212,105 @@ func (l *streamListener) listen(acc telegraf.Accumulator) {
 		wg.Add(1)
 		go func(c net.Conn) {
 			defer wg.Done()
-			if err := l.read(acc, c); err != nil {
-				if !errors.Is(err, io.EOF) && !errors.Is(err, syscall.ECONNRESET) {
-					acc.AddError(err)
-				}
-			}
+			l.handleConnection(acc, c)
 			l.Lock()
-			l.closeConnection(conn)
+			l.closeConnection(c)
 			l.Unlock()
 		}(conn)
 	}
 	wg.Wait()
 }
 
-func (l *streamListener) read(acc telegraf.Accumulator, conn net.Conn) error {
-	decoder, err := internal.NewStreamContentDecoder(l.Encoding, conn)
+// connectionStats collects the statistics over the lifetime of a connection
+type connectionStats struct {
+	bytes   int64
+	metrics int64
+	errors  int64
+}
+
+// countingReader counts the bytes received on the connection
+type countingReader struct {
+	io.Reader
+	stats *connectionStats
+}
+
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.Reader.Read(b)
+	r.stats.bytes += int64(n)
+	return n, err
+}
+
+func (l *streamListener) handleConnection(acc telegraf.Accumulator, conn net.Conn) {
+	start := time.Now()
+	timeout := time.Duration(l.ReadTimeout)
+
+	if l.ProxyProtocol {
+		if timeout > 0 {
+			if err := conn.SetReadDeadline(start.Add(timeout)); err != nil {
+				acc.AddError(fmt.Errorf("setting read deadline failed: %w", err))
+				return
+			}
+		}
+		pc, err := newProxyConn(conn)
+		if err != nil {
+			acc.AddError(fmt.Errorf("connection from %q: %w", conn.RemoteAddr(), err))
+			return
+		}
+		conn = pc
+		if l.tlsCfg != nil {
+			conn = tls.Server(pc, l.tlsCfg)
+		}
+	}
+
+	// Use a dedicated parser for each connection if possible, so parsers
+	// keeping state e.g. for headers in the data are not mixed up between
+	// connections
+	parser := l.Parser
+	if l.ParserFunc != nil {
+		p, err := l.ParserFunc()
+		if err != nil {
+			acc.AddError(fmt.Errorf("creating parser failed: %w", err))
+			return
+		}
+		parser = p
+	}
+
+	var stats connectionStats
+	if err := l.read(acc, conn, parser, &stats); err != nil {
+		if !errors.Is(err, io.EOF) && !errors.Is(err, syscall.ECONNRESET) {
+			acc.AddError(err)
+		}
+	}
+
+	if l.ConnectionStats {
+		source := conn.RemoteAddr().String()
+		if host, _, err := net.SplitHostPort(source); err == nil {
+			source = host
+		}
+		fields := map[string]interface{}{
+			"duration":         time.Since(start).Seconds(),
+			"bytes_received":   stats.bytes,
+			"metrics_received": stats.metrics,
+			"parse_errors":     stats.errors,
+		}
+		acc.AddFields("socket_listener_connection", fields, map[string]string{"source": source})
+	}
+}
+
+func (l *streamListener) read(acc telegraf.Accumulator, conn net.Conn, parser telegraf.Parser, stats *connectionStats) error {
+	decoder, err := internal.NewStreamContentDecoder(l.Encoding, &countingReader{Reader: conn, stats: stats})
 	if err != nil {
 		return fmt.Errorf("creating decoder failed: %w", err)
 	}
 
 	timeout := time.Duration(l.ReadTimeout)
 
+	// The identity of TLS clients is known after the handshake done on the
+	// first read
+	tlsConn, _ := conn.(*tls.Conn)
+	var identity string
+	var identified bool
+
 	scanner := bufio.NewScanner(decoder)
 	scanner.Split(l.Splitter)
 	for {
@@ -230,14 +329,25 @@ func (l *streamListener) read(acc telegraf.Accumulator, conn net.Conn) error {
 			break
 		}
 
+		if l.IdentityTag != "" && tlsConn != nil && !identified {
+			state := tlsConn.ConnectionState()
+			identity = l.Identity(&state)
+			identified = true
+		}
+
 		data := scanner.Bytes()
-		metrics, err := l.Parser.Parse(data)
+		metrics, err := parser.Parse(data)
 		if err != nil {
+			stats.errors++
 			acc.AddError(fmt.Errorf("parsing error: %w", err))
 			l.Log.Debugf("invalid data for parser: %v", data)
 			continue
 		}
+		stats.metrics += int64(len(metrics))
 		for _, m := range metrics {
+			if identity != "" {
+				m.AddTag(l.IdentityTag, identity)
+			}
 			acc.AddMetric(m)
 		}
 	}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -45,6 +47,13 @@ type Query struct {
 	FieldColumnsBool    []string `toml:"field_columns_bool"`
 	FieldColumnsString  []string `toml:"field_columns_string"`
 
+	Interval         config.Duration `toml:"interval"`
+	Timeout          config.Duration `toml:"timeout"`
+	Parameters       []config.Secret `toml:"parameters"`
+	WatermarkColumn  string          `toml:"watermark_column"`
+	WatermarkInitial string          `toml:"watermark_initial"`
+	CacheResults     bool            `toml:"cache_results"`
+
 	statement         *dbsql.Stmt
 	tagFilter         filter.Filter
 	fieldFilter       filter.Filter
@@ -53,12 +62,23 @@ type Query struct {
 	fieldFilterUint   filter.Filter
 	fieldFilterBool   filter.Filter
 	fieldFilterString filter.Filter
+
+	lastExecution time.Time
+	cache         []telegraf.Metric
+	watermark     interface{}
 }
 
-func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (int, error) {
+// watermark is the serializable representation of the watermark of
+// incremental queries keeping its type across restarts
+type watermark struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+func (q *Query) parse(rows *dbsql.Rows, t time.Time) ([]telegraf.Metric, interface{}, error) {
 	columnNames, err := rows.Columns()
 	if err != nil {
-		return 0, err
+		return nil, nil, err
 	}
 
 	// Prepare the list of datapoints according to the received row
@@ -69,7 +89,8 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 		columnDataPtr[i] = &columnData[i]
 	}
 
-	rowCount := 0
+	var metrics []telegraf.Metric
+	mark := q.watermark
 	for rows.Next() {
 		measurement := q.Measurement
 		timestamp := t
@@ -78,10 +99,24 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 
 		// Do the parsing with (hopefully) automatic type conversion
 		if err := rows.Scan(columnDataPtr...); err != nil {
-			return 0, err
+			return nil, nil, err
 		}
 
 		for i, name := range columnNames {
+			if q.WatermarkColumn != "" && name == q.WatermarkColumn && columnData[i] != nil {
+				v := columnData[i]
+				if raw, ok := v.([]byte); ok {
+					v = string(raw)
+				}
+				greater, err := watermarkGreater(v, mark)
+				if err != nil {
+					return nil, nil, fmt.Errorf("watermark column %q: %w", name, err)
+				}
+				if greater {
+					mark = v
+				}
+			}
+
 			if q.MeasurementColumn != "" && name == q.MeasurementColumn {
 				switch raw := columnData[i].(type) {
 				case string:
@@ -89,7 +124,7 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 				case []byte:
 					measurement = string(raw)
 				default:
-					return 0, fmt.Errorf("measurement column type \"%T\" unsupported", columnData[i])
+					return nil, nil, fmt.Errorf("measurement column type \"%T\" unsupported", columnData[i])
 				}
 			}
 
@@ -108,11 +143,11 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 				case fmt.Stringer:
 					fieldvalue = v.String()
 				default:
-					return 0, fmt.Errorf("time column %q of type \"%T\" unsupported", name, columnData[i])
+					return nil, nil, fmt.Errorf("time column %q of type \"%T\" unsupported", name, columnData[i])
 				}
 				if !skipParsing {
 					if timestamp, err = internal.ParseTimestamp(q.TimeFormat, fieldvalue, nil); err != nil {
-						return 0, fmt.Errorf("parsing time failed: %w", err)
+						return nil, nil, fmt.Errorf("parsing time failed: %w", err)
 					}
 				}
 			}
@@ -120,7 +155,7 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 			if q.tagFilter.Match(name) {
 				tagvalue, err := internal.ToString(columnData[i])
 				if err != nil {
-					return 0, fmt.Errorf("converting tag column %q failed: %w", name, err)
+					return nil, nil, fmt.Errorf("converting tag column %q failed: %w", name, err)
 				}
 				if v := strings.TrimSpace(tagvalue); v != "" {
 					tags[name] = v
@@ -131,7 +166,7 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 			if q.fieldFilterFloat.Match(name) {
 				v, err := internal.ToFloat64(columnData[i])
 				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to float failed: %w", name, err)
+					return nil, nil, fmt.Errorf("converting field column %q to float failed: %w", name, err)
 				}
 				fields[name] = v
 				continue
@@ -140,7 +175,7 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 			if q.fieldFilterInt.Match(name) {
 				v, err := internal.ToInt64(columnData[i])
 				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to int failed: %w", name, err)
+					return nil, nil, fmt.Errorf("converting field column %q to int failed: %w", name, err)
 				}
 				fields[name] = v
 				continue
@@ -149,7 +184,7 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 			if q.fieldFilterUint.Match(name) {
 				v, err := internal.ToUint64(columnData[i])
 				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to uint failed: %w", name, err)
+					return nil, nil, fmt.Errorf("converting field column %q to uint failed: %w", name, err)
 				}
 				fields[name] = v
 				continue
@@ -158,7 +193,7 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 			if q.fieldFilterBool.Match(name) {
 				v, err := internal.ToBool(columnData[i])
 				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to bool failed: %w", name, err)
+					return nil, nil, fmt.Errorf("converting field column %q to bool failed: %w", name, err)
 				}
 				fields[name] = v
 				continue
@@ -167,7 +202,7 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 			if q.fieldFilterString.Match(name) {
 				v, err := internal.ToString(columnData[i])
 				if err != nil {
-					return 0, fmt.Errorf("converting field column %q to string failed: %w", name, err)
+					return nil, nil, fmt.Errorf("converting field column %q to string failed: %w", name, err)
 				}
 				fields[name] = v
 				continue
@@ -188,22 +223,117 @@ func (q *Query) parse(acc telegraf.Accumulator, rows *dbsql.Rows, t time.Time) (
 				case fmt.Stringer:
 					fieldvalue = v.String()
 				default:
-					return 0, fmt.Errorf("field column %q of type \"%T\" unsupported", name, columnData[i])
+					return nil, nil, fmt.Errorf("field column %q of type \"%T\" unsupported", name, columnData[i])
 				}
 				if fieldvalue != nil {
 					fields[name] = fieldvalue
 				}
 			}
 		}
-		acc.AddFields(measurement, fields, tags, timestamp)
-		rowCount++
+		metrics = append(metrics, metric.New(measurement, tags, fields, timestamp))
 	}
 
 	if err := rows.Err(); err != nil {
-		return rowCount, err
+		return nil, nil, err
+	}
+
+	return metrics, mark, nil
+}
+
+// due checks if the query should be executed at the given time according to
+// its interval. The interval is shortened by a tenth to account for the jitter
+// between two gather cycles.
+func (q *Query) due(t time.Time) bool {
+	if q.Interval <= 0 || q.lastExecution.IsZero() {
+		return true
+	}
+	return t.Sub(q.lastExecution) >= time.Duration(q.Interval)-time.Duration(q.Interval)/10
+}
+
+// args resolves the parameters of the query and appends the watermark for
+// incremental queries
+func (q *Query) args() ([]interface{}, error) {
+	args := make([]interface{}, 0, len(q.Parameters)+1)
+	for i, param := range q.Parameters {
+		value, err := param.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting parameter %d failed: %w", i+1, err)
+		}
+		args = append(args, string(value))
+		config.ReleaseSecret(value)
+	}
+	if q.WatermarkColumn != "" {
+		args = append(args, q.watermark)
 	}
+	return args, nil
+}
+
+// watermarkGreater compares the value of the watermark column with the
+// current watermark
+func watermarkGreater(v, current interface{}) (bool, error) {
+	if current == nil {
+		return true, nil
+	}
+
+	switch value := v.(type) {
+	case time.Time:
+		c, ok := current.(time.Time)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %T with %T", v, current)
+		}
+		return value.After(c), nil
+	case string:
+		c, ok := current.(string)
+		if !ok {
+			return false, fmt.Errorf("cannot compare %T with %T", v, current)
+		}
+		return value > c, nil
+	}
+
+	value, err := internal.ToFloat64(v)
+	if err != nil {
+		return false, err
+	}
+	c, err := internal.ToFloat64(current)
+	if err != nil {
+		return false, err
+	}
+	return value > c, nil
+}
+
+func newWatermark(v interface{}) (watermark, error) {
+	switch value := v.(type) {
+	case time.Time:
+		return watermark{Type: "time", Value: value.Format(time.RFC3339Nano)}, nil
+	case string:
+		return watermark{Type: "string", Value: value}, nil
+	case int, int8, int16, int32, int64:
+		s, err := internal.ToString(value)
+		return watermark{Type: "int", Value: s}, err
+	case uint, uint8, uint16, uint32, uint64:
+		s, err := internal.ToString(value)
+		return watermark{Type: "uint", Value: s}, err
+	case float32, float64:
+		s, err := internal.ToString(value)
+		return watermark{Type: "float", Value: s}, err
+	}
+	return watermark{}, fmt.Errorf("unsupported watermark type %T", v)
+}
 
-	return rowCount, nil
+func (w watermark) value() (interface{}, error) {
+	switch w.Type {
+	case "time":
+		return time.Parse(time.RFC3339Nano, w.Value)
+	case "string":
+		return w.Value, nil
+	case "int":
+		return strconv.ParseInt(w.Value, 10, 64)
+	case "uint":
+		return strconv.ParseUint(w.Value, 10, 64)
+	case "float":
+		return strconv.ParseFloat(w.Value, 64)
+	}
+	return nil, fmt.Errorf("unknown watermark type %q", w.Type)
 }
 
 type SQL struct {
@@ -317,6 +447,14 @@ func (s *SQL) Init() error {
 		if q.Measurement == "" {
 			s.Queries[i].Measurement = "sql"
 		}
+
+		if q.Interval < 0 {
+			return fmt.Errorf("invalid interval %s for query %q", time.Duration(q.Interval), q.Query)
+		}
+		if q.WatermarkColumn != "" && s.Queries[i].watermark == nil {
+			// The watermark might have been restored from the state before
+			s.Queries[i].watermark = q.WatermarkInitial
+		}
 	}
 
 	// Derive the sql-framework driver name from our config name. This abstracts the actual driver
@@ -471,24 +609,85 @@ func (s *SQL) Gather(acc telegraf.Accumulator) error {
 	}
 
 	var wg sync.WaitGroup
+	var executed int
 	tstart := time.Now()
-	for _, query := range s.Queries {
+	for i := range s.Queries {
+		q := &s.Queries[i]
+
+		// Skip queries with a longer interval but report the cached results
+		// instead if requested
+		if !q.due(tstart) {
+			if q.CacheResults {
+				for _, m := range q.cache {
+					timestamp := m.Time()
+					if q.TimeColumn == "" {
+						timestamp = tstart
+					}
+					acc.AddFields(m.Name(), m.Fields(), m.Tags(), timestamp)
+				}
+			}
+			continue
+		}
+		q.lastExecution = tstart
+		executed++
+
+		timeout := s.Timeout
+		if q.Timeout > 0 {
+			timeout = q.Timeout
+		}
+
 		wg.Add(1)
-		go func(q Query) {
+		go func(q *Query) {
 			defer wg.Done()
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.Timeout))
+			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout))
 			defer cancel()
 			if err := s.executeQuery(ctx, acc, q, tstart); err != nil {
 				acc.AddError(err)
 			}
-		}(query)
+		}(q)
 	}
 	wg.Wait()
-	s.Log.Debugf("Executed %d queries in %s", len(s.Queries), time.Since(tstart).String())
+	s.Log.Debugf("Executed %d queries in %s", executed, time.Since(tstart).String())
 
 	return nil
 }
 
+func (s *SQL) GetState() interface{} {
+	state := make(map[string]watermark)
+	for _, q := range s.Queries {
+		if q.WatermarkColumn == "" || q.watermark == nil {
+			continue
+		}
+		w, err := newWatermark(q.watermark)
+		if err != nil {
+			s.Log.Errorf("Storing watermark of query %q failed: %v", q.Query, err)
+			continue
+		}
+		state[q.Query] = w
+	}
+	return state
+}
+
+func (s *SQL) SetState(state interface{}) error {
+	watermarks, ok := state.(map[string]watermark)
+	if !ok {
+		return errors.New("state has to be of type 'map[string]watermark'")
+	}
+
+	for i, q := range s.Queries {
+		w, found := watermarks[q.Query]
+		if !found || q.WatermarkColumn == "" {
+			continue
+		}
+		v, err := w.value()
+		if err != nil {
+			return fmt.Errorf("restoring watermark of query %q failed: %w", q.Query, err)
+		}
+		s.Queries[i].watermark = v
+	}
+	return nil
+}
+
 func init() {
 	inputs.Add("sql", func() telegraf.Input {
 		return &SQL{
@@ -500,20 +699,23 @@ func init() {
 	})
 }
 
-func (s *SQL) executeQuery(ctx context.Context, acc telegraf.Accumulator, q Query, tquery time.Time) error {
+func (s *SQL) executeQuery(ctx context.Context, acc telegraf.Accumulator, q *Query, tquery time.Time) error {
+	args, err := q.args()
+	if err != nil {
+		return err
+	}
+
 	// Execute the query either prepared or unprepared
 	var rows *dbsql.Rows
 	if q.statement != nil {
 		// Use the previously prepared query
-		var err error
-		rows, err = q.statement.QueryContext(ctx)
+		rows, err = q.statement.QueryContext(ctx, args...)
 		if err != nil {
 			return err
 		}
 	} else {
 		// Fallback to unprepared query
-		var err error
-		rows, err = s.db.Query(q.Query)
+		rows, err = s.db.QueryContext(ctx, q.Query, args...)
 		if err != nil {
 			return err
 		}
@@ -525,10 +727,21 @@ func (s *SQL) executeQuery(ctx context.Context, acc telegraf.Accumulator, q Quer
 	if err != nil {
 		return err
 	}
-	rowCount, err := q.parse(acc, rows, tquery)
-	s.Log.Debugf("Received %d rows and %d columns for query %q", rowCount, len(columnNames), q.Query)
+	metrics, mark, err := q.parse(rows, tquery)
+	if err != nil {
+		return err
+	}
+	s.Log.Debugf("Received %d rows and %d columns for query %q", len(metrics), len(columnNames), q.Query)
 
-	return err
+	for _, m := range metrics {
+		acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+	}
+	if q.CacheResults {
+		q.cache = metrics
+	}
+	q.watermark = mark
+
+	return nil
 }
 
 func (s *SQL) checkDSN() error {
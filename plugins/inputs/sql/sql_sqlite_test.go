@@ -0,0 +1,229 @@
+//go:build linux && (386 || amd64 || arm || arm64)
+
+package sql
+
+import (
+	dbsql "database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func setupSqlite(t *testing.T) (string, *dbsql.DB) {
+	dsn := filepath.Join(t.TempDir(), "test.db")
+	db, err := dbsql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE events (id INTEGER PRIMARY KEY, name TEXT, value INTEGER)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO events (id, name, value) VALUES (1, 'a', 10), (2, 'b', 20), (3, 'a', 30)`)
+	require.NoError(t, err)
+
+	return dsn, db
+}
+
+func TestSqliteParameters(t *testing.T) {
+	dsn, _ := setupSqlite(t)
+
+	plugin := &SQL{
+		Driver: "sqlite",
+		Dsn:    config.NewSecret([]byte(dsn)),
+		Queries: []Query{
+			{
+				Query:               "SELECT name, value FROM events WHERE name = ? ORDER BY id",
+				Parameters:          []config.Secret{config.NewSecret([]byte("a"))},
+				TagColumnsInclude:   []string{"name"},
+				FieldColumnsExclude: []string{"name"},
+				Timeout:             config.Duration(time.Second),
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New("sql", map[string]string{"name": "a"}, map[string]interface{}{"value": int64(10)}, time.Unix(0, 0)),
+		metric.New("sql", map[string]string{"name": "a"}, map[string]interface{}{"value": int64(30)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestSqliteIntervalAndCache(t *testing.T) {
+	dsn, db := setupSqlite(t)
+
+	plugin := &SQL{
+		Driver: "sqlite",
+		Dsn:    config.NewSecret([]byte(dsn)),
+		Queries: []Query{
+			{
+				Query:       "SELECT COUNT(*) AS total FROM events",
+				Measurement: "uncached",
+				Interval:    config.Duration(time.Hour),
+			},
+			{
+				Query:        "SELECT COUNT(*) AS total FROM events",
+				Measurement:  "cached",
+				Interval:     config.Duration(time.Hour),
+				CacheResults: true,
+			},
+			{
+				Query:       "SELECT COUNT(*) AS total FROM events",
+				Measurement: "always",
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	first := acc.GetTelegrafMetrics()
+	require.Len(t, first, 3)
+
+	// Change the data, only the query without interval must see the change
+	// while the cached query reports the previous result
+	_, err := db.Exec(`INSERT INTO events (id, name, value) VALUES (4, 'c', 40)`)
+	require.NoError(t, err)
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New("cached", map[string]string{}, map[string]interface{}{"total": int64(3)}, time.Unix(0, 0)),
+		metric.New("always", map[string]string{}, map[string]interface{}{"total": int64(4)}, time.Unix(0, 0)),
+	}
+	actual := acc.GetTelegrafMetrics()
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime(), testutil.SortMetrics())
+
+	// Cached results without time column get the time of the gather cycle
+	for _, m := range actual {
+		require.True(t, m.Time().After(first[0].Time()))
+	}
+
+	// Once the interval passed, the query is executed again
+	plugin.Queries[0].lastExecution = time.Now().Add(-2 * time.Hour)
+	plugin.Queries[1].lastExecution = time.Now().Add(-2 * time.Hour)
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected = []telegraf.Metric{
+		metric.New("uncached", map[string]string{}, map[string]interface{}{"total": int64(4)}, time.Unix(0, 0)),
+		metric.New("cached", map[string]string{}, map[string]interface{}{"total": int64(4)}, time.Unix(0, 0)),
+		metric.New("always", map[string]string{}, map[string]interface{}{"total": int64(4)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime(), testutil.SortMetrics())
+}
+
+func TestSqliteWatermark(t *testing.T) {
+	dsn, db := setupSqlite(t)
+
+	newPlugin := func() *SQL {
+		return &SQL{
+			Driver: "sqlite",
+			Dsn:    config.NewSecret([]byte(dsn)),
+			Queries: []Query{
+				{
+					Query:               "SELECT id, value FROM events WHERE id > ? ORDER BY id",
+					WatermarkColumn:     "id",
+					WatermarkInitial:    "1",
+					FieldColumnsExclude: []string{"id"},
+				},
+			},
+			Log: testutil.Logger{},
+		}
+	}
+
+	plugin := newPlugin()
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New("sql", map[string]string{}, map[string]interface{}{"value": int64(20)}, time.Unix(0, 0)),
+		metric.New("sql", map[string]string{}, map[string]interface{}{"value": int64(30)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+
+	// Without new data no metrics are expected
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	state := plugin.GetState()
+	require.Equal(t, map[string]watermark{
+		"SELECT id, value FROM events WHERE id > ? ORDER BY id": {Type: "int", Value: "3"},
+	}, state)
+	plugin.Stop()
+
+	// Restore the state in a new instance and only get the new data
+	_, err := db.Exec(`INSERT INTO events (id, name, value) VALUES (4, 'c', 40)`)
+	require.NoError(t, err)
+
+	plugin = newPlugin()
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.SetState(state))
+
+	acc.ClearMetrics()
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected = []telegraf.Metric{
+		metric.New("sql", map[string]string{}, map[string]interface{}{"value": int64(40)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestWatermarkRoundtrip(t *testing.T) {
+	ts := time.Date(2023, 5, 17, 22, 4, 45, 123456789, time.UTC)
+	for _, v := range []interface{}{int64(-42), uint64(42), 3.5, "abc", ts} {
+		w, err := newWatermark(v)
+		require.NoError(t, err)
+		actual, err := w.value()
+		require.NoError(t, err)
+		require.Equal(t, v, actual)
+	}
+
+	_, err := newWatermark(true)
+	require.Error(t, err)
+}
+
+func TestInvalidQueryInterval(t *testing.T) {
+	plugin := &SQL{
+		Driver: "sqlite",
+		Dsn:    config.NewSecret([]byte("test.db")),
+		Queries: []Query{
+			{
+				Query:    "SELECT 1",
+				Interval: config.Duration(-time.Second),
+			},
+		},
+	}
+	require.ErrorContains(t, plugin.Init(), "invalid interval")
+}
@@ -0,0 +1,373 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package ssh_exec
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/inputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// maxStderrBytes is the maximum length of stderr included in errors
+const maxStderrBytes = 512
+
+type SSHExec struct {
+	Hosts                 []string        `toml:"hosts"`
+	Commands              []string        `toml:"commands"`
+	Username              config.Secret   `toml:"username"`
+	Password              config.Secret   `toml:"password"`
+	PrivateKey            string          `toml:"private_key"`
+	PrivateKeyPassphrase  config.Secret   `toml:"private_key_passphrase"`
+	UseAgent              bool            `toml:"use_agent"`
+	KnownHosts            string          `toml:"known_hosts"`
+	InsecureIgnoreHostKey bool            `toml:"insecure_ignore_host_key"`
+	Bastion               string          `toml:"bastion"`
+	BastionUsername       config.Secret   `toml:"bastion_username"`
+	ConnectionTimeout     config.Duration `toml:"connection_timeout"`
+	Timeout               config.Duration `toml:"timeout"`
+	HostTag               string          `toml:"host_tag"`
+	Log                   telegraf.Logger `toml:"-"`
+
+	parserFunc      telegraf.ParserFunc
+	signers         []ssh.Signer
+	hostKeyCallback ssh.HostKeyCallback
+}
+
+func (*SSHExec) SampleConfig() string {
+	return sampleConfig
+}
+
+// SetParserFunc takes the data_format from the config and finds the right parser for that format
+func (s *SSHExec) SetParserFunc(fn telegraf.ParserFunc) {
+	s.parserFunc = fn
+}
+
+func (s *SSHExec) Init() error {
+	if len(s.Hosts) == 0 {
+		return errors.New("no hosts specified")
+	}
+	if len(s.Commands) == 0 {
+		return errors.New("no commands specified")
+	}
+	if s.Username.Empty() {
+		return errors.New("username is required")
+	}
+	for i, host := range s.Hosts {
+		s.Hosts[i] = withDefaultPort(host)
+	}
+	if s.Bastion != "" {
+		s.Bastion = withDefaultPort(s.Bastion)
+	}
+
+	// Setup the host key verification
+	switch {
+	case s.InsecureIgnoreHostKey:
+		//nolint:gosec // G106: Explicitly requested by the user
+		s.hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	case s.KnownHosts != "":
+		callback, err := knownhosts.New(s.KnownHosts)
+		if err != nil {
+			return fmt.Errorf("loading known hosts failed: %w", err)
+		}
+		s.hostKeyCallback = callback
+	default:
+		return errors.New("either known_hosts or insecure_ignore_host_key must be set")
+	}
+
+	if s.PrivateKey != "" {
+		signer, err := s.loadPrivateKey()
+		if err != nil {
+			return fmt.Errorf("loading private key failed: %w", err)
+		}
+		s.signers = append(s.signers, signer)
+	}
+
+	if s.PrivateKey == "" && s.Password.Empty() && !s.UseAgent {
+		return errors.New("no authentication method configured")
+	}
+
+	return nil
+}
+
+func (s *SSHExec) loadPrivateKey() (ssh.Signer, error) {
+	key, err := os.ReadFile(s.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.PrivateKeyPassphrase.Empty() {
+		return ssh.ParsePrivateKey(key)
+	}
+	passphrase, err := s.PrivateKeyPassphrase.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting passphrase failed: %w", err)
+	}
+	defer config.ReleaseSecret(passphrase)
+	return ssh.ParsePrivateKeyWithPassphrase(key, passphrase)
+}
+
+func (s *SSHExec) Gather(acc telegraf.Accumulator) error {
+	var wg sync.WaitGroup
+	for _, host := range s.Hosts {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			if err := s.gatherHost(acc, host); err != nil {
+				acc.AddError(fmt.Errorf("[host=%s]: %w", host, err))
+			}
+		}(host)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (s *SSHExec) gatherHost(acc telegraf.Accumulator, host string) error {
+	client, closer, err := s.connect(host)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	hostname, _, err := net.SplitHostPort(host)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range s.Commands {
+		stdout, err := s.run(client, command)
+		if err != nil {
+			acc.AddError(fmt.Errorf("[host=%s]: running %q failed: %w", host, command, err))
+			continue
+		}
+
+		// Instantiate a new parser for the new data to avoid trouble with stateful parsers
+		parser, err := s.parserFunc()
+		if err != nil {
+			return fmt.Errorf("instantiating parser failed: %w", err)
+		}
+		metrics, err := parser.Parse(stdout)
+		if err != nil {
+			acc.AddError(fmt.Errorf("[host=%s]: parsing output of %q failed: %w", host, command, err))
+			continue
+		}
+
+		for _, m := range metrics {
+			if s.HostTag != "" {
+				m.AddTag(s.HostTag, hostname)
+			}
+			acc.AddMetric(m)
+		}
+	}
+
+	return nil
+}
+
+// connect opens a connection to the host, optionally via the bastion host,
+// and returns a function to close all connections
+func (s *SSHExec) connect(host string) (*ssh.Client, func(), error) {
+	var agentConn net.Conn
+	if s.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, nil, errors.New("SSH_AUTH_SOCK not set for agent authentication")
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, nil, fmt.Errorf("connecting to agent failed: %w", err)
+		}
+		agentConn = conn
+	}
+	closeAgent := func() {
+		if agentConn != nil {
+			agentConn.Close()
+		}
+	}
+
+	clientConfig, err := s.clientConfig(s.Username, agentConn)
+	if err != nil {
+		closeAgent()
+		return nil, nil, err
+	}
+
+	if s.Bastion == "" {
+		client, err := ssh.Dial("tcp", host, clientConfig)
+		if err != nil {
+			closeAgent()
+			return nil, nil, err
+		}
+		return client, func() {
+			client.Close()
+			closeAgent()
+		}, nil
+	}
+
+	username := s.BastionUsername
+	if username.Empty() {
+		username = s.Username
+	}
+	bastionConfig, err := s.clientConfig(username, agentConn)
+	if err != nil {
+		closeAgent()
+		return nil, nil, err
+	}
+	bastion, err := ssh.Dial("tcp", s.Bastion, bastionConfig)
+	if err != nil {
+		closeAgent()
+		return nil, nil, fmt.Errorf("connecting to bastion %q failed: %w", s.Bastion, err)
+	}
+
+	conn, err := bastion.Dial("tcp", host)
+	if err != nil {
+		bastion.Close()
+		closeAgent()
+		return nil, nil, fmt.Errorf("connecting via bastion %q failed: %w", s.Bastion, err)
+	}
+	// Forwarded connections do not support deadlines so abort the handshake
+	// by closing the connection
+	var timer *time.Timer
+	if s.ConnectionTimeout > 0 {
+		timer = time.AfterFunc(time.Duration(s.ConnectionTimeout), func() { conn.Close() })
+	}
+	c, chans, reqs, err := ssh.NewClientConn(conn, host, clientConfig)
+	if timer != nil {
+		timer.Stop()
+	}
+	if err != nil {
+		conn.Close()
+		bastion.Close()
+		closeAgent()
+		return nil, nil, err
+	}
+	client := ssh.NewClient(c, chans, reqs)
+	return client, func() {
+		client.Close()
+		bastion.Close()
+		closeAgent()
+	}, nil
+}
+
+func (s *SSHExec) clientConfig(username config.Secret, agentConn net.Conn) (*ssh.ClientConfig, error) {
+	user, err := username.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting username failed: %w", err)
+	}
+	defer config.ReleaseSecret(user)
+
+	var methods []ssh.AuthMethod
+	if agentConn != nil {
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(agentConn).Signers))
+	}
+	if len(s.signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(s.signers...))
+	}
+	if !s.Password.Empty() {
+		password, err := s.Password.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting password failed: %w", err)
+		}
+		pw := string(password)
+		config.ReleaseSecret(password)
+
+		// Many appliances only accept keyboard-interactive authentication
+		// asking for the password
+		methods = append(methods,
+			ssh.Password(pw),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = pw
+				}
+				return answers, nil
+			}),
+		)
+	}
+
+	return &ssh.ClientConfig{
+		User:            string(user),
+		Auth:            methods,
+		HostKeyCallback: s.hostKeyCallback,
+		Timeout:         time.Duration(s.ConnectionTimeout),
+	}, nil
+}
+
+// run executes the command in a new session and returns its output
+func (s *SSHExec) run(client *ssh.Client, command string) ([]byte, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("creating session failed: %w", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	var timeout <-chan time.Time
+	if s.Timeout > 0 {
+		timer := time.NewTimer(time.Duration(s.Timeout))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case err = <-done:
+	case <-timeout:
+		// Not all servers support signals so closing the session is
+		// required to abort the command
+		_ = session.Signal(ssh.SIGKILL)
+		session.Close()
+		return nil, fmt.Errorf("command timed out after %s", time.Duration(s.Timeout))
+	}
+
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxStderrBytes {
+			msg = msg[:maxStderrBytes] + "..."
+		}
+		if msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// withDefaultPort adds the default SSH port if the address has no port
+func withDefaultPort(address string) string {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return net.JoinHostPort(address, "22")
+	}
+	return address
+}
+
+func init() {
+	inputs.Add("ssh_exec", func() telegraf.Input {
+		return &SSHExec{
+			ConnectionTimeout: config.Duration(5 * time.Second),
+			Timeout:           config.Duration(10 * time.Second),
+			HostTag:           "source",
+		}
+	})
+}
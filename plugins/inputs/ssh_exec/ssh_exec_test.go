@@ -0,0 +1,396 @@
+package ssh_exec
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+// fakeServer is a SSH server responding to a fixed set of commands and
+// forwarding connections to act as bastion host
+type fakeServer struct {
+	listener  net.Listener
+	hostKey   ssh.Signer
+	clientKey ssh.PublicKey
+	commands  []string
+	forwards  int
+	sync.Mutex
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &fakeServer{listener: listener, hostKey: hostKey}
+	cfg := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if c.User() == "telegraf" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+		PublicKeyCallback: func(c ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if s.clientKey != nil && c.User() == "telegraf" && string(key.Marshal()) == string(s.clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, errors.New("access denied")
+		},
+	}
+	cfg.AddHostKey(hostKey)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, cfg)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+
+	return s
+}
+
+func (s *fakeServer) serve(conn net.Conn, cfg *ssh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := ssh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		switch newChannel.ChannelType() {
+		case "session":
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				return
+			}
+			go s.handleSession(channel, requests)
+		case "direct-tcpip":
+			var payload struct {
+				Host       string
+				Port       uint32
+				OriginHost string
+				OriginPort uint32
+			}
+			if err := ssh.Unmarshal(newChannel.ExtraData(), &payload); err != nil {
+				_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			target, err := net.Dial("tcp", net.JoinHostPort(payload.Host, strconv.Itoa(int(payload.Port))))
+			if err != nil {
+				_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+				continue
+			}
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				target.Close()
+				return
+			}
+			s.Lock()
+			s.forwards++
+			s.Unlock()
+			go ssh.DiscardRequests(requests)
+			go func() {
+				defer channel.Close()
+				defer target.Close()
+				go func() {
+					_, _ = io.Copy(target, channel)
+				}()
+				_, _ = io.Copy(channel, target)
+			}()
+		default:
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported channel type")
+		}
+	}
+}
+
+func (s *fakeServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			_ = req.Reply(false, nil)
+			return
+		}
+		_ = req.Reply(true, nil)
+
+		s.Lock()
+		s.commands = append(s.commands, payload.Command)
+		s.Unlock()
+
+		var status uint32
+		switch payload.Command {
+		case "show interfaces":
+			_, _ = channel.Write([]byte("interface,name=eth0 rx_bytes=100i,tx_bytes=200i 1690000000000000000\n"))
+		case "show system":
+			_, _ = channel.Write([]byte("system cpu=12.5 1690000000000000000\n"))
+		case "sleep":
+			// Wait for the client to give up and close the channel
+			for range requests {
+			}
+			return
+		default:
+			_, _ = channel.Stderr().Write([]byte("unknown command"))
+			status = 127
+		}
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+func (s *fakeServer) address() string {
+	return s.listener.Addr().String()
+}
+
+func newParser() (telegraf.Parser, error) {
+	parser := &influx.Parser{}
+	err := parser.Init()
+	return parser, err
+}
+
+func TestGather(t *testing.T) {
+	server := newFakeServer(t)
+
+	plugin := &SSHExec{
+		Hosts:                 []string{server.address()},
+		Commands:              []string{"show interfaces", "show system"},
+		Username:              config.NewSecret([]byte("telegraf")),
+		Password:              config.NewSecret([]byte("secret")),
+		InsecureIgnoreHostKey: true,
+		ConnectionTimeout:     config.Duration(5 * time.Second),
+		Timeout:               config.Duration(5 * time.Second),
+		HostTag:               "source",
+		Log:                   testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"interface",
+			map[string]string{"name": "eth0", "source": "127.0.0.1"},
+			map[string]interface{}{"rx_bytes": int64(100), "tx_bytes": int64(200)},
+			time.Unix(0, 1690000000000000000),
+		),
+		metric.New(
+			"system",
+			map[string]string{"source": "127.0.0.1"},
+			map[string]interface{}{"cpu": 12.5},
+			time.Unix(0, 1690000000000000000),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.SortMetrics())
+}
+
+func TestGatherCommandErrors(t *testing.T) {
+	server := newFakeServer(t)
+
+	plugin := &SSHExec{
+		Hosts:                 []string{server.address()},
+		Commands:              []string{"invalid", "sleep", "show system"},
+		Username:              config.NewSecret([]byte("telegraf")),
+		Password:              config.NewSecret([]byte("secret")),
+		InsecureIgnoreHostKey: true,
+		ConnectionTimeout:     config.Duration(5 * time.Second),
+		Timeout:               config.Duration(500 * time.Millisecond),
+		Log:                   testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 2)
+	require.ErrorContains(t, acc.Errors[0], "unknown command")
+	require.ErrorContains(t, acc.Errors[1], "timed out")
+
+	// Failing commands do not prevent the following commands
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	require.False(t, acc.GetTelegrafMetrics()[0].HasTag("source"))
+}
+
+func TestGatherPrivateKeyAndKnownHosts(t *testing.T) {
+	server := newFakeServer(t)
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	server.clientKey = signer.PublicKey()
+
+	dir := t.TempDir()
+	keyfile := filepath.Join(dir, "id_ed25519")
+	require.NoError(t, os.WriteFile(keyfile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+	knownHostsFile := filepath.Join(dir, "known_hosts")
+	line := knownhosts.Line([]string{server.address()}, server.hostKey.PublicKey())
+	require.NoError(t, os.WriteFile(knownHostsFile, []byte(line+"\n"), 0600))
+
+	plugin := &SSHExec{
+		Hosts:             []string{server.address()},
+		Commands:          []string{"show system"},
+		Username:          config.NewSecret([]byte("telegraf")),
+		PrivateKey:        keyfile,
+		KnownHosts:        knownHostsFile,
+		ConnectionTimeout: config.Duration(5 * time.Second),
+		Timeout:           config.Duration(5 * time.Second),
+		Log:               testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	// Unknown host keys are rejected
+	other := newFakeServer(t)
+	other.clientKey = signer.PublicKey()
+	plugin.Hosts = []string{other.address()}
+	acc = testutil.Accumulator{}
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "key is unknown")
+}
+
+func TestGatherBastion(t *testing.T) {
+	bastion := newFakeServer(t)
+	server := newFakeServer(t)
+
+	plugin := &SSHExec{
+		Hosts:                 []string{server.address()},
+		Commands:              []string{"show system"},
+		Username:              config.NewSecret([]byte("telegraf")),
+		Password:              config.NewSecret([]byte("secret")),
+		InsecureIgnoreHostKey: true,
+		Bastion:               bastion.address(),
+		ConnectionTimeout:     config.Duration(5 * time.Second),
+		Timeout:               config.Duration(5 * time.Second),
+		Log:                   testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Empty(t, acc.Errors)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	bastion.Lock()
+	require.Equal(t, 1, bastion.forwards)
+	require.Empty(t, bastion.commands)
+	bastion.Unlock()
+	server.Lock()
+	require.Equal(t, []string{"show system"}, server.commands)
+	server.Unlock()
+}
+
+func TestGatherAuthenticationFailure(t *testing.T) {
+	server := newFakeServer(t)
+
+	plugin := &SSHExec{
+		Hosts:                 []string{server.address()},
+		Commands:              []string{"show system"},
+		Username:              config.NewSecret([]byte("telegraf")),
+		Password:              config.NewSecret([]byte("wrong")),
+		InsecureIgnoreHostKey: true,
+		ConnectionTimeout:     config.Duration(5 * time.Second),
+		Log:                   testutil.Logger{},
+	}
+	plugin.SetParserFunc(newParser)
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+	require.Len(t, acc.Errors, 1)
+	require.ErrorContains(t, acc.Errors[0], "unable to authenticate")
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *SSHExec
+		expected string
+	}{
+		{
+			name:     "no hosts",
+			plugin:   &SSHExec{},
+			expected: "no hosts specified",
+		},
+		{
+			name:     "no commands",
+			plugin:   &SSHExec{Hosts: []string{"localhost"}},
+			expected: "no commands specified",
+		},
+		{
+			name: "no host key verification",
+			plugin: &SSHExec{
+				Hosts:    []string{"localhost"},
+				Commands: []string{"uptime"},
+				Username: config.NewSecret([]byte("telegraf")),
+				Password: config.NewSecret([]byte("secret")),
+			},
+			expected: "either known_hosts or insecure_ignore_host_key must be set",
+		},
+		{
+			name: "no authentication",
+			plugin: &SSHExec{
+				Hosts:                 []string{"localhost"},
+				Commands:              []string{"uptime"},
+				Username:              config.NewSecret([]byte("telegraf")),
+				InsecureIgnoreHostKey: true,
+			},
+			expected: "no authentication method configured",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestWithDefaultPort(t *testing.T) {
+	require.Equal(t, "switch1:22", withDefaultPort("switch1"))
+	require.Equal(t, "switch1:2222", withDefaultPort("switch1:2222"))
+	require.Equal(t, "[fe80::1]:22", withDefaultPort("fe80::1"))
+}
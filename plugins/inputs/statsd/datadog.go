@@ -9,6 +9,9 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/influxdata/telegraf"
+	internalMetric "github.com/influxdata/telegraf/metric"
 )
 
 const (
@@ -19,8 +22,39 @@ const (
 	eventWarning = "warning"
 	eventError   = "error"
 	eventSuccess = "success"
+
+	serviceCheckOK       = "ok"
+	serviceCheckWarning  = "warning"
+	serviceCheckCritical = "critical"
+	serviceCheckUnknown  = "unknown"
 )
 
+// serviceCheckStatus maps the status of a service check to its name
+var serviceCheckStatus = map[string]string{
+	"0": serviceCheckOK,
+	"1": serviceCheckWarning,
+	"2": serviceCheckCritical,
+	"3": serviceCheckUnknown,
+}
+
+// eventSeverity maps the alert types of events to the severity of event
+// metrics
+var eventSeverity = map[string]telegraf.Severity{
+	eventInfo:    telegraf.SeverityInfo,
+	eventSuccess: telegraf.SeverityInfo,
+	eventWarning: telegraf.SeverityWarning,
+	eventError:   telegraf.SeverityError,
+}
+
+// serviceCheckSeverity maps the states of service checks to the severity of
+// event metrics
+var serviceCheckSeverity = map[string]telegraf.Severity{
+	serviceCheckOK:       telegraf.SeverityInfo,
+	serviceCheckWarning:  telegraf.SeverityWarning,
+	serviceCheckCritical: telegraf.SeverityCritical,
+	serviceCheckUnknown:  telegraf.SeverityUnknown,
+}
+
 var uncommenter = strings.NewReplacer("\\n", "\n")
 
 func (s *Statsd) parseEventMessage(now time.Time, message string, defaultHostname string) error {
@@ -31,6 +65,7 @@ func (s *Statsd) parseEventMessage(now time.Time, message string, defaultHostnam
 	//   |h:hostname
 	//   |t:alert_type
 	//   |s:source_type_nam
+	//   |c:container_id
 	//   |#tag1,tag2
 	//  ]
 	//
@@ -82,7 +117,7 @@ func (s *Statsd) parseEventMessage(now time.Time, message string, defaultHostnam
 	fields["priority"] = priorityNormal
 	ts := now
 	if len(message) < 2 {
-		s.acc.AddFields(name, fields, tags, ts)
+		s.addEvent(name, fields, tags, ts)
 		return nil
 	}
 
@@ -119,6 +154,8 @@ func (s *Statsd) parseEventMessage(now time.Time, message string, defaultHostnam
 			tags["aggregation_key"] = rawMetadataFields[i][2:]
 		case "s:":
 			fields["source_type_name"] = rawMetadataFields[i][2:]
+		case "c:":
+			tags["container_id"] = rawMetadataFields[i][2:]
 		default:
 			if rawMetadataFields[i][0] != '#' {
 				return fmt.Errorf("unknown metadata type: %q", rawMetadataFields[i])
@@ -132,10 +169,108 @@ func (s *Statsd) parseEventMessage(now time.Time, message string, defaultHostnam
 		delete(tags, "host")
 		tags["source"] = host
 	}
-	s.acc.AddFields(name, fields, tags, ts)
+	s.addEvent(name, fields, tags, ts)
+	return nil
+}
+
+func (s *Statsd) parseServiceCheckMessage(now time.Time, message string, defaultHostname string) error {
+	// _sc|name|status
+	//  [
+	//   |d:timestamp
+	//   |h:hostname
+	//   |#tag1,tag2
+	//   |c:container_id
+	//   |m:service_check_message
+	//  ]
+	//
+	// the message has to be the last metadata field as it may contain pipes
+	var text string
+	if idx := strings.Index(message, "|m:"); idx >= 0 {
+		text = uncommenter.Replace(message[idx+3:])
+		message = message[:idx]
+	}
+
+	parts := strings.Split(message, "|")
+	if len(parts) < 3 || parts[0] != "_sc" {
+		return errors.New("invalid service check format")
+	}
+	name := parts[1]
+	if name == "" {
+		return errors.New("invalid service check format: empty 'name' field")
+	}
+	status, found := serviceCheckStatus[parts[2]]
+	if !found {
+		return fmt.Errorf("invalid service check status %q", parts[2])
+	}
+
+	tags := make(map[string]string, len(parts))
+	if defaultHostname != "" {
+		tags["source"] = defaultHostname
+	}
+	fields := map[string]interface{}{"status": status}
+	for _, field := range parts[3:] {
+		if len(field) < 2 {
+			return errors.New("too short metadata field")
+		}
+		switch field[:2] {
+		case "d:":
+			ts, err := strconv.ParseInt(field[2:], 10, 64)
+			if err != nil {
+				continue
+			}
+			fields["ts"] = ts
+		case "h:":
+			tags["source"] = field[2:]
+		case "c:":
+			tags["container_id"] = field[2:]
+		default:
+			if field[0] != '#' {
+				return fmt.Errorf("unknown metadata type: %q", field)
+			}
+			parseDataDogTags(tags, field[1:])
+		}
+	}
+	if host, ok := tags["host"]; ok {
+		delete(tags, "host")
+		tags["source"] = host
+	}
+
+	if !s.DataDogEventMetrics {
+		if text != "" {
+			fields["message"] = text
+		}
+		s.acc.AddFields(name, fields, tags, now)
+		return nil
+	}
+
+	m := internalMetric.NewEvent(name, tags, serviceCheckSeverity[status], text, now)
+	for k, v := range fields {
+		m.AddField(k, v)
+	}
+	s.acc.AddMetric(m)
 	return nil
 }
 
+// addEvent adds the event either as plain metric or as event metric with the
+// severity derived from the alert type and the text as message
+func (s *Statsd) addEvent(name string, fields map[string]interface{}, tags map[string]string, ts time.Time) {
+	if !s.DataDogEventMetrics {
+		s.acc.AddFields(name, fields, tags, ts)
+		return
+	}
+
+	alertType, _ := fields["alert_type"].(string)
+	text, _ := fields["text"].(string)
+	m := internalMetric.NewEvent(name, tags, eventSeverity[alertType], text, ts)
+	for k, v := range fields {
+		if k == "text" {
+			continue
+		}
+		m.AddField(k, v)
+	}
+	s.acc.AddMetric(m)
+}
+
 func parseDataDogTags(tags map[string]string, message string) {
 	if len(message) == 0 {
 		return
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
 )
@@ -482,3 +483,132 @@ func TestEventError(t *testing.T) {
 	err = s.parseEventMessage(now, "_e{5,4}:title|text|x:1234", "default-hostname")
 	require.Error(t, err)
 }
+
+func TestServiceChecks(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		message  string
+		expected telegraf.Metric
+	}{
+		{
+			name:    "basic",
+			message: "_sc|agent.up|0",
+			expected: testutil.MustMetric(
+				"agent.up",
+				map[string]string{"source": "default-hostname"},
+				map[string]interface{}{"status": "ok"},
+				now,
+			),
+		},
+		{
+			name:    "all metadata",
+			message: "_sc|agent.up|2|d:21|h:localhost|#env:prod,role|c:83c0a99c0a54|m:check failed|reason:timeout",
+			expected: testutil.MustMetric(
+				"agent.up",
+				map[string]string{
+					"source":       "localhost",
+					"env":          "prod",
+					"role":         "true",
+					"container_id": "83c0a99c0a54",
+				},
+				map[string]interface{}{
+					"status":  "critical",
+					"ts":      int64(21),
+					"message": "check failed|reason:timeout",
+				},
+				now,
+			),
+		},
+		{
+			name:    "host tag",
+			message: "_sc|agent.up|1|#host:localhost",
+			expected: testutil.MustMetric(
+				"agent.up",
+				map[string]string{"source": "localhost"},
+				map[string]interface{}{"status": "warning"},
+				now,
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acc testutil.Accumulator
+			s := NewTestStatsd()
+			s.acc = &acc
+
+			require.NoError(t, s.parseServiceCheckMessage(now, tt.message, "default-hostname"))
+			testutil.RequireMetricsEqual(t, []telegraf.Metric{tt.expected}, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestServiceCheckError(t *testing.T) {
+	now := time.Now()
+	s := NewTestStatsd()
+	s.acc = &testutil.Accumulator{}
+
+	// missing status
+	require.Error(t, s.parseServiceCheckMessage(now, "_sc|agent.up", "default-hostname"))
+
+	// empty name
+	require.Error(t, s.parseServiceCheckMessage(now, "_sc||0", "default-hostname"))
+
+	// invalid status
+	require.Error(t, s.parseServiceCheckMessage(now, "_sc|agent.up|4", "default-hostname"))
+
+	// unknown metadata
+	require.Error(t, s.parseServiceCheckMessage(now, "_sc|agent.up|0|x:1234", "default-hostname"))
+}
+
+func TestEventMetrics(t *testing.T) {
+	now := time.Now()
+
+	var acc testutil.Accumulator
+	s := NewTestStatsd()
+	s.DataDogEventMetrics = true
+	s.acc = &acc
+
+	require.NoError(t, s.parseEventMessage(now, "_e{10,9}:test title|test text|t:warning|c:83c0a99c0a54", "default-hostname"))
+	require.NoError(t, s.parseServiceCheckMessage(now, "_sc|agent.up|2|m:check failed", "default-hostname"))
+	require.NoError(t, s.parseServiceCheckMessage(now, "_sc|agent.up|0", "default-hostname"))
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"test title",
+			map[string]string{"source": "default-hostname", "container_id": "83c0a99c0a54"},
+			map[string]interface{}{
+				"severity":   "warning",
+				"message":    "test text",
+				"alert_type": "warning",
+				"priority":   "normal",
+			},
+			now,
+			telegraf.Event,
+		),
+		testutil.MustMetric(
+			"agent.up",
+			map[string]string{"source": "default-hostname"},
+			map[string]interface{}{
+				"severity": "critical",
+				"message":  "check failed",
+				"status":   "critical",
+			},
+			now,
+			telegraf.Event,
+		),
+		testutil.MustMetric(
+			"agent.up",
+			map[string]string{"source": "default-hostname"},
+			map[string]interface{}{
+				"severity": "info",
+				"message":  "",
+				"status":   "ok",
+			},
+			now,
+			telegraf.Event,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -4,15 +4,18 @@ package statsd
 import (
 	"bufio"
 	"bytes"
+	"context"
 	_ "embed"
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -95,6 +98,10 @@ type Statsd struct {
 	// https://docs.datadoghq.com/developers/metrics/types/?tab=distribution#definition
 	DataDogDistributions bool `toml:"datadog_distributions"`
 
+	// Emits DataDog events and service checks as event metrics.
+	// Requires the DataDogExtension flag to be enabled.
+	DataDogEventMetrics bool `toml:"datadog_event_metrics"`
+
 	// UDPPacketSize is deprecated, it's only here for legacy support
 	// we now always create 1 max size buffer and then copy only what we need
 	// into the in channel
@@ -102,6 +109,7 @@ type Statsd struct {
 	UDPPacketSize int `toml:"udp_packet_size" deprecated:"0.12.1;2.0.0;option is ignored"`
 
 	ReadBufferSize      int              `toml:"read_buffer_size"`
+	UDPListeners        int              `toml:"udp_listeners"`
 	SanitizeNamesMethod string           `toml:"sanitize_name_method"`
 	Templates           []string         `toml:"templates"` // bucket -> influx templates
 	MaxTCPConnections   int              `toml:"max_tcp_connections"`
@@ -121,7 +129,7 @@ type Statsd struct {
 	// accept the connection
 	accept chan bool
 	// drops tracks the number of dropped metrics.
-	drops int
+	drops atomic.Int64
 
 	// Channel for all incoming statsd packets
 	in   chan input
@@ -141,6 +149,9 @@ type Statsd struct {
 	UDPlistener *net.UDPConn
 	TCPlistener *net.TCPListener
 
+	// all datagram sockets including the UDP listener
+	packetConns []net.PacketConn
+
 	// track current connections so we can close them in Stop()
 	conns          map[string]*net.TCPConn
 	graphiteParser *graphite.Parser
@@ -363,27 +374,25 @@ func (s *Statsd) Start(ac telegraf.Accumulator) error {
 		s.MetricSeparator = defaultSeparator
 	}
 
-	if s.isUDP() {
-		address, err := net.ResolveUDPAddr(s.Protocol, s.ServiceAddress)
+	if s.isPacket() {
+		conns, err := s.listenPacket()
 		if err != nil {
 			return err
 		}
-
-		conn, err := net.ListenUDP(s.Protocol, address)
-		if err != nil {
-			return err
+		s.packetConns = conns
+		if conn, ok := conns[0].(*net.UDPConn); ok {
+			s.UDPlistener = conn
 		}
 
-		s.Log.Infof("UDP listening on %q", conn.LocalAddr().String())
-		s.UDPlistener = conn
-
-		s.wg.Add(1)
-		go func() {
-			defer s.wg.Done()
-			if err := s.udpListen(conn); err != nil {
-				ac.AddError(err)
-			}
-		}()
+		for _, conn := range conns {
+			s.wg.Add(1)
+			go func(conn net.PacketConn) {
+				defer s.wg.Done()
+				if err := s.udpListen(conn); err != nil {
+					ac.AddError(err)
+				}
+			}(conn)
+		}
 	} else {
 		address, err := net.ResolveTCPAddr("tcp", s.ServiceAddress)
 		if err != nil {
@@ -465,11 +474,61 @@ func (s *Statsd) tcpListen(listener *net.TCPListener) error {
 	}
 }
 
-// udpListen starts listening for UDP packets on the configured port.
-func (s *Statsd) udpListen(conn *net.UDPConn) error {
+// listenPacket opens the datagram sockets. For UDP multiple sockets can share
+// the address, so the kernel distributes the packets across the sockets.
+func (s *Statsd) listenPacket() ([]net.PacketConn, error) {
+	if s.Protocol == "unixgram" {
+		if err := os.Remove(s.ServiceAddress); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("removing socket failed: %w", err)
+		}
+		conn, err := net.ListenPacket(s.Protocol, s.ServiceAddress)
+		if err != nil {
+			return nil, err
+		}
+		s.Log.Infof("Unix datagram socket listening on %q", s.ServiceAddress)
+		return []net.PacketConn{conn}, nil
+	}
+
+	if s.UDPListeners <= 1 {
+		address, err := net.ResolveUDPAddr(s.Protocol, s.ServiceAddress)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := net.ListenUDP(s.Protocol, address)
+		if err != nil {
+			return nil, err
+		}
+		s.Log.Infof("UDP listening on %q", conn.LocalAddr().String())
+		return []net.PacketConn{conn}, nil
+	}
+
+	lc := net.ListenConfig{Control: reusePort}
+	conns := make([]net.PacketConn, 0, s.UDPListeners)
+	address := s.ServiceAddress
+	for i := 0; i < s.UDPListeners; i++ {
+		conn, err := lc.ListenPacket(context.Background(), s.Protocol, address)
+		if err != nil {
+			for _, c := range conns {
+				c.Close()
+			}
+			return nil, err
+		}
+		// Use the resolved address for all other sockets in case a
+		// random port was requested
+		address = conn.LocalAddr().String()
+		conns = append(conns, conn)
+	}
+	s.Log.Infof("UDP listening on %q with %d sockets", address, len(conns))
+	return conns, nil
+}
+
+// udpListen starts listening for datagrams on the given socket.
+func (s *Statsd) udpListen(conn net.PacketConn) error {
 	if s.ReadBufferSize > 0 {
-		if err := s.UDPlistener.SetReadBuffer(s.ReadBufferSize); err != nil {
-			return err
+		if c, ok := conn.(interface{ SetReadBuffer(int) error }); ok {
+			if err := c.SetReadBuffer(s.ReadBufferSize); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -479,7 +538,7 @@ func (s *Statsd) udpListen(conn *net.UDPConn) error {
 		case <-s.done:
 			return nil
 		default:
-			n, addr, err := conn.ReadFromUDP(buf)
+			n, addr, err := conn.ReadFrom(buf)
 			if err != nil {
 				if !strings.Contains(err.Error(), "closed network") {
 					s.Log.Errorf("Error reading: %s", err.Error())
@@ -497,19 +556,24 @@ func (s *Statsd) udpListen(conn *net.UDPConn) error {
 			if _, err := b.Write(buf[:n]); err != nil {
 				return err
 			}
+			// Unix datagram sockets don't provide a source address
+			var source string
+			if udpAddr, ok := addr.(*net.UDPAddr); ok {
+				source = udpAddr.IP.String()
+			}
 			select {
 			case s.in <- input{
 				Buffer: b,
 				Time:   time.Now(),
-				Addr:   addr.IP.String()}:
+				Addr:   source}:
 				s.PendingMessages.Set(int64(len(s.in)))
 			default:
 				s.UDPPacketsDrop.Incr(1)
-				s.drops++
-				if s.drops == 1 || s.AllowedPendingMessages == 0 || s.drops%s.AllowedPendingMessages == 0 {
+				drops := s.drops.Add(1)
+				if drops == 1 || s.AllowedPendingMessages == 0 || drops%int64(s.AllowedPendingMessages) == 0 {
 					s.Log.Errorf("Statsd message queue full. "+
 						"We have dropped %d messages so far. "+
-						"You may want to increase allowed_pending_messages in the config", s.drops)
+						"You may want to increase allowed_pending_messages in the config", drops)
 				}
 			}
 		}
@@ -533,6 +597,11 @@ func (s *Statsd) parser() error {
 				line = strings.TrimSpace(line)
 				switch {
 				case line == "":
+				case s.DataDogExtensions && strings.HasPrefix(line, "_sc"):
+					if err := s.parseServiceCheckMessage(in.Time, line, in.Addr); err != nil {
+						s.Log.Errorf("Parsing line failed: %v", err)
+						s.Log.Debugf("  line was: %s", line)
+					}
 				case s.DataDogExtensions && strings.HasPrefix(line, "_e"):
 					if err := s.parseEventMessage(in.Time, line, in.Addr); err != nil {
 						// Log the line causing the parsing error and continue
@@ -568,11 +637,15 @@ func (s *Statsd) parseStatsdLine(line string) error {
 		// users.online:1|c|#sometagwithnovalue
 		// we will split on the pipe and remove any elements that are datadog
 		// tags, parse them, and rebuild the line sans the datadog tags
+		// the container ID of the origin is sent as "c:" segment
+		// users.online:1|c|#country:china|c:83c0a99c0a54c0c187f461c7980e
 		pipesplit := strings.Split(line, "|")
-		for _, segment := range pipesplit {
+		for i, segment := range pipesplit {
 			if len(segment) > 0 && segment[0] == '#' {
 				// we have ourselves a tag; they are comma separated
 				parseDataDogTags(lineTags, segment[1:])
+			} else if i > 1 && strings.HasPrefix(segment, "c:") && !isNumeric(segment[2:]) {
+				lineTags["container_id"] = segment[2:]
 			} else {
 				recombinedSegments = append(recombinedSegments, segment)
 			}
@@ -709,6 +782,13 @@ func (s *Statsd) parseStatsdLine(line string) error {
 	return nil
 }
 
+// isNumeric checks if the given string is a value in a line with multiple
+// values per bucket like "users.online:1|c:100|g"
+func isNumeric(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
 // parseName parses the given bucket name with the list of bucket maps in the
 // config file. If there is a match, it will parse the name of the metric and
 // map of tags.
@@ -940,11 +1020,11 @@ func (s *Statsd) handler(conn *net.TCPConn, id string) {
 			case s.in <- input{Buffer: b, Time: time.Now(), Addr: remoteIP}:
 				s.PendingMessages.Set(int64(len(s.in)))
 			default:
-				s.drops++
-				if s.drops == 1 || s.drops%s.AllowedPendingMessages == 0 {
+				drops := s.drops.Add(1)
+				if drops == 1 || drops%int64(s.AllowedPendingMessages) == 0 {
 					s.Log.Errorf("Statsd message queue full. "+
 						"We have dropped %d messages so far. "+
-						"You may want to increase allowed_pending_messages in the config", s.drops)
+						"You may want to increase allowed_pending_messages in the config", drops)
 				}
 			}
 		}
@@ -976,9 +1056,14 @@ func (s *Statsd) Stop() {
 	s.Lock()
 	s.Log.Infof("Stopping the statsd service")
 	close(s.done)
-	if s.isUDP() {
-		if s.UDPlistener != nil {
-			s.UDPlistener.Close()
+	if s.isPacket() {
+		for _, conn := range s.packetConns {
+			conn.Close()
+		}
+		if s.Protocol == "unixgram" {
+			if err := os.Remove(s.ServiceAddress); err != nil && !errors.Is(err, os.ErrNotExist) {
+				s.Log.Errorf("Removing socket failed: %v", err)
+			}
 		}
 	} else {
 		if s.TCPlistener != nil {
@@ -1009,9 +1094,10 @@ func (s *Statsd) Stop() {
 	s.Unlock()
 }
 
-// IsUDP returns true if the protocol is UDP, false otherwise.
-func (s *Statsd) isUDP() bool {
-	return strings.HasPrefix(s.Protocol, "udp")
+// isPacket returns true if the protocol is datagram based (UDP or unixgram),
+// false otherwise.
+func (s *Statsd) isPacket() bool {
+	return strings.HasPrefix(s.Protocol, "udp") || s.Protocol == "unixgram"
 }
 
 func (s *Statsd) expireCachedMetrics() {
@@ -3,6 +3,8 @@ package statsd
 import (
 	"fmt"
 	"net"
+	"path/filepath"
+	"runtime"
 	"sync"
 	"testing"
 	"time"
@@ -1062,6 +1064,25 @@ func TestParse_DataDogTags(t *testing.T) {
 				),
 			},
 		},
+		{
+			name: "container id",
+			line: "my_gauge:10.1|g|#live|c:83c0a99c0a54c0c187f461c7980e",
+			expected: []telegraf.Metric{
+				testutil.MustMetric(
+					"my_gauge",
+					map[string]string{
+						"container_id": "83c0a99c0a54c0c187f461c7980e",
+						"live":         "true",
+						"metric_type":  "gauge",
+					},
+					map[string]interface{}{
+						"value": 10.1,
+					},
+					time.Now(),
+					telegraf.Gauge,
+				),
+			},
+		},
 		{
 			name: "empty tag set",
 			line: "cpu:42|c|#",
@@ -1843,6 +1864,88 @@ func TestUdpFillQueue(t *testing.T) {
 	require.Lenf(t, errs, 0, "got errors: %v", errs)
 }
 
+func TestUdpListeners(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "solaris" {
+		t.Skip("Skipping as SO_REUSEPORT is not supported")
+	}
+
+	plugin := &Statsd{
+		Log:                    testutil.Logger{},
+		Protocol:               "udp",
+		ServiceAddress:         "localhost:0",
+		AllowedPendingMessages: 100,
+		NumberWorkerThreads:    2,
+		UDPListeners:           4,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.Len(t, plugin.packetConns, 4)
+
+	// Use different source ports so the datagrams are distributed
+	for i := 0; i < 10; i++ {
+		conn, err := net.Dial("udp", plugin.UDPlistener.LocalAddr().String())
+		require.NoError(t, err)
+		_, err = fmt.Fprintf(conn, "cpu.time_idle:%d|c\n", 1)
+		require.NoError(t, err)
+		require.NoError(t, conn.Close())
+	}
+
+	require.Eventually(t, func() bool {
+		return plugin.UDPPacketsRecv.Get() >= 10
+	}, 3*time.Second, 100*time.Millisecond)
+	require.Eventually(t, func() bool {
+		require.NoError(t, plugin.Gather(&acc))
+		m, found := acc.Get("cpu_time_idle")
+		return found && m.Fields["value"] == int64(10)
+	}, 3*time.Second, 100*time.Millisecond)
+}
+
+func TestUnixgram(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping as unixgram is not supported")
+	}
+
+	plugin := &Statsd{
+		Log:                    testutil.Logger{},
+		Protocol:               "unixgram",
+		ServiceAddress:         filepath.Join(t.TempDir(), "statsd.sock"),
+		AllowedPendingMessages: 10,
+		NumberWorkerThreads:    1,
+		DataDogExtensions:      true,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	conn, err := net.Dial("unixgram", plugin.ServiceAddress)
+	require.NoError(t, err)
+	_, err = conn.Write([]byte("cpu.time_idle:42|c|#env:prod\n_e{5,4}:title|text"))
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+
+	require.Eventually(t, func() bool {
+		return plugin.UDPPacketsRecv.Get() >= 1
+	}, 3*time.Second, 100*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return acc.HasMeasurement("title")
+	}, 3*time.Second, 100*time.Millisecond)
+	require.NoError(t, plugin.Gather(&acc))
+	plugin.Stop()
+
+	// Events received via unix sockets have no source
+	m, found := acc.Get("title")
+	require.True(t, found)
+	require.Empty(t, m.Tags)
+	m, found = acc.Get("cpu_time_idle")
+	require.True(t, found)
+	require.Equal(t, map[string]string{"env": "prod", "metric_type": "counter"}, m.Tags)
+
+	// The socket is removed on stop
+	require.NoFileExists(t, plugin.ServiceAddress)
+}
+
 func TestParse_Ints(t *testing.T) {
 	s := NewTestStatsd()
 	s.Percentiles = []Number{90}
@@ -24,6 +24,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	framing "github.com/influxdata/telegraf/internal/syslog"
+	"github.com/influxdata/telegraf/metric"
 	tlsConfig "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
@@ -50,6 +51,7 @@ type Syslog struct {
 	Trailer         nontransparent.TrailerType
 	BestEffort      bool
 	Separator       string `toml:"sdparam_separator"`
+	AsEvent         bool   `toml:"as_event"`
 
 	now      func() time.Time
 	lastTime time.Time
@@ -200,7 +202,7 @@ func (s *Syslog) listenPacket(acc telegraf.Accumulator) {
 
 		message, err := p.Parse(b[:n])
 		if message != nil {
-			acc.AddFields("syslog", fields(message, s), tags(message, sourceAddr), s.currentTime())
+			s.add(acc, message, sourceAddr)
 		}
 		if err != nil {
 			acc.AddError(err)
@@ -325,10 +327,27 @@ func (s *Syslog) store(res syslog.Result, remoteAddr net.Addr, acc telegraf.Accu
 		acc.AddError(res.Error)
 	}
 	if res.Message != nil {
-		acc.AddFields("syslog", fields(res.Message, s), tags(res.Message, remoteAddr), s.currentTime())
+		s.add(acc, res.Message, remoteAddr)
 	}
 }
 
+func (s *Syslog) add(acc telegraf.Accumulator, msg syslog.Message, sourceAddr net.Addr) {
+	flds := fields(msg, s)
+	ts := tags(msg, sourceAddr)
+	if !s.AsEvent {
+		acc.AddFields("syslog", flds, ts, s.currentTime())
+		return
+	}
+
+	// Events carry the severity as field instead of the syslog keyword tag
+	flds[telegraf.EventSeverityField] = telegraf.ParseSeverity(ts["severity"]).String()
+	delete(ts, "severity")
+	if _, found := flds[telegraf.EventMessageField]; !found {
+		flds[telegraf.EventMessageField] = ""
+	}
+	acc.AddMetric(metric.New("syslog", ts, flds, s.currentTime(), telegraf.Event))
+}
+
 func tags(msg syslog.Message, sourceAddr net.Addr) map[string]string {
 	ts := map[string]string{}
 
@@ -7,8 +7,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/go-syslog/v3/rfc5424"
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -68,3 +70,39 @@ func TestAddress(t *testing.T) {
 	require.Equal(t, "localhost:6514", rec.Address)
 	rec.Stop()
 }
+
+func TestAsEvent(t *testing.T) {
+	msg, err := rfc5424.NewParser().Parse([]byte(`<28>1 2017-12-31T23:59:59Z host app 123 - - disk almost full`))
+	require.NoError(t, err)
+
+	plugin := &Syslog{
+		AsEvent:   true,
+		Separator: "_",
+		now:       func() time.Time { return defaultTime },
+	}
+	var acc testutil.Accumulator
+	plugin.add(&acc, msg, nil)
+
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"syslog",
+			map[string]string{
+				"facility": "daemon",
+				"hostname": "host",
+				"appname":  "app",
+			},
+			map[string]interface{}{
+				"version":       uint16(1),
+				"facility_code": 3,
+				"severity_code": 4,
+				"timestamp":     time.Unix(1514764799, 0).UnixNano(),
+				"procid":        "123",
+				"message":       "disk almost full",
+				"severity":      "warning",
+			},
+			defaultTime,
+			telegraf.Event,
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
@@ -0,0 +1,84 @@
+//go:build !solaris
+
+package tail
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"io"
+	"os"
+)
+
+// fingerprintSize is the maximum number of bytes at the start of a file used
+// to identify the file
+const fingerprintSize = 1024
+
+// fingerprint identifies a file independent of its name by the inode and a
+// hash over the first bytes of the file, so a file can be recognized after
+// a restart and rotated or truncated files can be detected
+type fingerprint struct {
+	Inode uint64 `json:"inode,omitempty"`
+	Size  int64  `json:"size,omitempty"`
+	Hash  uint64 `json:"hash,omitempty"`
+}
+
+// fileState is the persisted reading position of a file
+type fileState struct {
+	Offset      int64        `json:"offset"`
+	Fingerprint *fingerprint `json:"fingerprint,omitempty"`
+}
+
+// UnmarshalJSON accepts the plain offsets stored by previous versions in
+// addition to the full state
+func (s *fileState) UnmarshalJSON(data []byte) error {
+	var offset int64
+	if err := json.Unmarshal(data, &offset); err == nil {
+		*s = fileState{Offset: offset}
+		return nil
+	}
+
+	type state fileState
+	return json.Unmarshal(data, (*state)(s))
+}
+
+// computeFingerprint reads the fingerprint of the given file with the hash
+// covering at most limit bytes, so a fingerprint can be compared to one taken
+// while the file was smaller
+func computeFingerprint(filename string, limit int64) (*fingerprint, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New64a()
+	n, err := io.CopyN(h, f, limit)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return &fingerprint{
+		Inode: inode(stat),
+		Size:  n,
+		Hash:  h.Sum64(),
+	}, nil
+}
+
+// matches checks if the file is still the one the fingerprint was taken
+// from, i.e. the file was neither replaced nor truncated and rewritten
+func (fp *fingerprint) matches(filename string) (bool, error) {
+	current, err := computeFingerprint(filename, fp.Size)
+	if err != nil {
+		return false, err
+	}
+	if fp.Inode != 0 && current.Inode != 0 && fp.Inode != current.Inode {
+		return false, nil
+	}
+	return current.Size == fp.Size && current.Hash == fp.Hash, nil
+}
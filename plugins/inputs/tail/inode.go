@@ -0,0 +1,15 @@
+//go:build !solaris && !windows
+
+package tail
+
+import (
+	"os"
+	"syscall"
+)
+
+func inode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino) //nolint:unconvert // int64 on some platforms
+	}
+	return 0
+}
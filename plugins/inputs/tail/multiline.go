@@ -20,6 +20,7 @@ type Multiline struct {
 	patternRegexp *regexp.Regexp
 	quote         byte
 	inQuote       bool
+	lines         int
 }
 
 type MultilineConfig struct {
@@ -29,6 +30,7 @@ type MultilineConfig struct {
 	PreserveNewline bool                    `toml:"preserve_newline"`
 	Quotation       string                  `toml:"quotation"`
 	Timeout         *config.Duration        `toml:"timeout"`
+	MaxLines        int                     `toml:"max_lines"`
 }
 
 const (
@@ -62,6 +64,10 @@ func (m *MultilineConfig) NewMultiline() (*Multiline, error) {
 		return nil, errors.New("invalid 'quotation' setting")
 	}
 
+	if m.MaxLines < 0 {
+		return nil, errors.New("'max_lines' must not be negative")
+	}
+
 	enabled := m.Pattern != "" || quote != 0
 	if m.Timeout == nil || time.Duration(*m.Timeout).Nanoseconds() == int64(0) {
 		d := config.Duration(5 * time.Second)
@@ -88,15 +94,23 @@ func (m *Multiline) ProcessLine(text string, buffer *bytes.Buffer) string {
 		}
 		// Ignore the returned error as we cannot do anything about it anyway
 		_, _ = buffer.WriteString(text)
+		m.lines++
+
+		// Emit the event if it exceeds the maximum number of lines
+		if m.config.MaxLines > 0 && m.lines >= m.config.MaxLines {
+			return m.Flush(buffer)
+		}
 		return ""
 	}
 
 	if m.config.MatchWhichLine == Previous {
 		previousText := buffer.String()
 		buffer.Reset()
+		m.lines = 0
 		if _, err := buffer.WriteString(text); err != nil {
 			return ""
 		}
+		m.lines = 1
 		text = previousText
 	} else {
 		// Next
@@ -109,6 +123,7 @@ func (m *Multiline) ProcessLine(text string, buffer *bytes.Buffer) string {
 			}
 			text = buffer.String()
 			buffer.Reset()
+			m.lines = 0
 		}
 	}
 
@@ -116,6 +131,7 @@ func (m *Multiline) ProcessLine(text string, buffer *bytes.Buffer) string {
 }
 
 func (m *Multiline) Flush(buffer *bytes.Buffer) string {
+	m.lines = 0
 	if buffer.Len() == 0 {
 		return ""
 	}
@@ -177,6 +177,31 @@ func TestMultiLineProcessLineNext(t *testing.T) {
 	require.Zero(t, buffer.Len())
 }
 
+func TestMultiLineProcessLineMaxLines(t *testing.T) {
+	c := &MultilineConfig{
+		Pattern:        "^\\s",
+		MatchWhichLine: Previous,
+		MaxLines:       3,
+	}
+	m, err := c.NewMultiline()
+	require.NoError(t, err, "Configuration was OK.")
+	var buffer bytes.Buffer
+
+	require.Empty(t, m.ProcessLine("Exception", &buffer))
+	require.Empty(t, m.ProcessLine(" at a", &buffer))
+	require.Equal(t, "Exception at a at b", m.ProcessLine(" at b", &buffer))
+	require.Zero(t, buffer.Len())
+
+	// The remaining lines of the event form a new one
+	require.Empty(t, m.ProcessLine(" at c", &buffer))
+	require.Equal(t, " at c", m.ProcessLine("next", &buffer))
+	require.Equal(t, "next", m.Flush(&buffer))
+
+	c = &MultilineConfig{MaxLines: -1}
+	_, err = c.NewMultiline()
+	require.Error(t, err)
+}
+
 func TestMultiLineMatchStringWithInvertMatchFalse(t *testing.T) {
 	c := &MultilineConfig{
 		Pattern:        "=>$",
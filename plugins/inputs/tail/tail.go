@@ -9,6 +9,7 @@ import (
 	_ "embed"
 	"errors"
 	"io"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -32,7 +33,7 @@ const (
 )
 
 var (
-	offsets      = make(map[string]int64)
+	offsets      = make(map[string]fileState)
 	offsetsMutex = new(sync.Mutex)
 )
 
@@ -53,7 +54,7 @@ type Tail struct {
 
 	Log        telegraf.Logger `toml:"-"`
 	tailers    map[string]*tail.Tail
-	offsets    map[string]int64
+	offsets    map[string]fileState
 	parserFunc telegraf.ParserFunc
 	wg         sync.WaitGroup
 
@@ -70,7 +71,7 @@ type Tail struct {
 
 func NewTail() *Tail {
 	offsetsMutex.Lock()
-	offsetsCopy := make(map[string]int64, len(offsets))
+	offsetsCopy := make(map[string]fileState, len(offsets))
 	for k, v := range offsets {
 		offsetsCopy[k] = v
 	}
@@ -100,7 +101,7 @@ func (t *Tail) Init() error {
 		}
 	}
 	// init offsets
-	t.offsets = make(map[string]int64)
+	t.offsets = make(map[string]fileState)
 
 	var err error
 	t.decoder, err = encoding.NewDecoder(t.CharacterEncoding)
@@ -112,9 +113,9 @@ func (t *Tail) GetState() interface{} {
 }
 
 func (t *Tail) SetState(state interface{}) error {
-	offsetsState, ok := state.(map[string]int64)
+	offsetsState, ok := state.(map[string]fileState)
 	if !ok {
-		return errors.New("state has to be of type 'map[string]int64'")
+		return errors.New("state has to be of type 'map[string]fileState'")
 	}
 	for k, v := range offsetsState {
 		t.offsets[k] = v
@@ -157,7 +158,7 @@ func (t *Tail) Start(acc telegraf.Accumulator) error {
 
 	// assumption that once Start is called, all parallel plugins have already been initialized
 	offsetsMutex.Lock()
-	offsets = make(map[string]int64)
+	offsets = make(map[string]fileState)
 	offsetsMutex.Unlock()
 
 	return err
@@ -183,7 +184,8 @@ func (t *Tail) tailNewFiles(fromBeginning bool) error {
 
 			var seek *tail.SeekInfo
 			if !t.Pipe && !fromBeginning {
-				if offset, ok := t.offsets[file]; ok {
+				if state, ok := t.offsets[file]; ok {
+					offset := t.resumeOffset(file, state)
 					t.Log.Debugf("Using offset %d for %q", offset, file)
 					seek = &tail.SeekInfo{
 						Whence: 0,
@@ -245,6 +247,31 @@ func (t *Tail) tailNewFiles(fromBeginning bool) error {
 	return nil
 }
 
+// resumeOffset returns the offset to continue reading the file at. The file is
+// read from the beginning if it is not the file the state was recorded for,
+// e.g. if it was rotated or truncated in the meantime.
+func (t *Tail) resumeOffset(file string, state fileState) int64 {
+	if info, err := os.Stat(file); err == nil && info.Size() < state.Offset {
+		t.Log.Infof("File %q was truncated, reading from the beginning", file)
+		return 0
+	}
+
+	// States of previous versions do not contain a fingerprint
+	if state.Fingerprint == nil {
+		return state.Offset
+	}
+	matches, err := state.Fingerprint.matches(file)
+	if err != nil {
+		t.Log.Debugf("Checking fingerprint of %q failed: %v", file, err)
+		return state.Offset
+	}
+	if !matches {
+		t.Log.Infof("File %q was rotated or truncated, reading from the beginning", file)
+		return 0
+	}
+	return state.Offset
+}
+
 // ParseLine parses a line of text.
 func parseLine(parser telegraf.Parser, line string) ([]telegraf.Metric, error) {
 	m, err := parser.Parse([]byte(line))
@@ -371,7 +398,11 @@ func (t *Tail) Stop() {
 			offset, err := tailer.Tell()
 			if err == nil {
 				t.Log.Debugf("Recording offset %d for %q", offset, tailer.Filename)
-				t.offsets[tailer.Filename] = offset
+				state := fileState{Offset: offset}
+				if state.Fingerprint, err = computeFingerprint(tailer.Filename, fingerprintSize); err != nil {
+					t.Log.Debugf("Computing fingerprint for %q failed: %v", tailer.Filename, err)
+				}
+				t.offsets[tailer.Filename] = state
 			} else {
 				t.Log.Errorf("Recording offset for %q: %s", tailer.Filename, err.Error())
 			}
@@ -2,6 +2,8 @@ package tail
 
 import (
 	"bytes"
+	stdjson "encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -37,7 +39,7 @@ func NewInfluxParser() (telegraf.Parser, error) {
 
 func NewTestTail() *Tail {
 	offsetsMutex.Lock()
-	offsetsCopy := make(map[string]int64, len(offsets))
+	offsetsCopy := make(map[string]fileState, len(offsets))
 	for k, v := range offsets {
 		offsetsCopy[k] = v
 	}
@@ -622,8 +624,8 @@ func TestCharacterEncoding(t *testing.T) {
 			require.NoError(t, plugin.Init())
 
 			if tt.offset != 0 {
-				plugin.offsets = map[string]int64{
-					plugin.Files[0]: tt.offset,
+				plugin.offsets = map[string]fileState{
+					plugin.Files[0]: {Offset: tt.offset},
 				}
 			}
 
@@ -711,7 +713,7 @@ func TestCSVBehavior(t *testing.T) {
 		Files:               []string{input.Name()},
 		FromBeginning:       true,
 		MaxUndeliveredLines: 1000,
-		offsets:             make(map[string]int64, 0),
+		offsets:             make(map[string]fileState, 0),
 		PathTag:             "path",
 		Log:                 testutil.Logger{},
 	}
@@ -785,3 +787,111 @@ func getTestdataDir() string {
 
 	return filepath.Join(dir, "testdata")
 }
+
+func TestStateCompatibility(t *testing.T) {
+	var state map[string]fileState
+	require.NoError(t, stdjson.Unmarshal([]byte(`{"a.log":42}`), &state))
+	require.Equal(t, map[string]fileState{"a.log": {Offset: 42}}, state)
+
+	buf, err := stdjson.Marshal(map[string]fileState{
+		"b.log": {Offset: 23, Fingerprint: &fingerprint{Inode: 1, Size: 10, Hash: 2}},
+	})
+	require.NoError(t, err)
+	state = nil
+	require.NoError(t, stdjson.Unmarshal(buf, &state))
+	require.Equal(t, map[string]fileState{
+		"b.log": {Offset: 23, Fingerprint: &fingerprint{Inode: 1, Size: 10, Hash: 2}},
+	}, state)
+}
+
+func TestResumeAfterRotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		rotate   func(t *testing.T, filename string)
+		expected []string
+	}{
+		{
+			name:     "unchanged",
+			rotate:   func(*testing.T, string) {},
+			expected: []string{"3"},
+		},
+		{
+			name: "copytruncate",
+			rotate: func(t *testing.T, filename string) {
+				require.NoError(t, os.WriteFile(filename, []byte("cpu value=4 0\ncpu value=5 0\ncpu value=6 0\n"), 0600))
+			},
+			expected: []string{"4", "5", "6"},
+		},
+		{
+			name: "truncated to smaller size",
+			rotate: func(t *testing.T, filename string) {
+				require.NoError(t, os.Truncate(filename, 0))
+				require.NoError(t, os.WriteFile(filename, []byte("cpu value=7 0\n"), 0600))
+			},
+			expected: []string{"7"},
+		},
+		{
+			name: "replaced",
+			rotate: func(t *testing.T, filename string) {
+				require.NoError(t, os.Rename(filename, filename+".1"))
+				require.NoError(t, os.WriteFile(filename, []byte("cpu value=1 0\ncpu value=2 0\ncpu value=8 0\n"), 0600))
+			},
+			expected: []string{"1", "2", "8"},
+		},
+	}
+
+	watchMethod := defaultWatchMethod
+	if runtime.GOOS == "windows" {
+		watchMethod = "poll"
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "test.log")
+			require.NoError(t, os.WriteFile(filename, []byte("cpu value=1 0\ncpu value=2 0\n"), 0600))
+
+			newPlugin := func() *Tail {
+				plugin := &Tail{
+					Files:               []string{filename},
+					MaxUndeliveredLines: 1000,
+					WatchMethod:         watchMethod,
+					Log:                 testutil.Logger{},
+				}
+				plugin.SetParserFunc(NewInfluxParser)
+				require.NoError(t, plugin.Init())
+				return plugin
+			}
+
+			// Read the file once to record the state
+			plugin := newPlugin()
+			require.NoError(t, plugin.SetState(map[string]fileState{filename: {Offset: 0}}))
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			acc.Wait(2)
+			plugin.Stop()
+			state := plugin.GetState()
+
+			// Modify the file and continue with the recorded state
+			f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0600)
+			require.NoError(t, err)
+			_, err = f.WriteString("cpu value=3 0\n")
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+			tt.rotate(t, filename)
+
+			plugin = newPlugin()
+			require.NoError(t, plugin.SetState(state))
+			acc.ClearMetrics()
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+			acc.Wait(len(tt.expected))
+
+			actual := make([]string, 0, len(tt.expected))
+			for _, m := range acc.GetTelegrafMetrics() {
+				v, _ := m.GetField("value")
+				actual = append(actual, fmt.Sprintf("%v", v))
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/memlimit"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/artifactory"
 	"github.com/influxdata/telegraf/plugins/inputs/webhooks/filestack"
@@ -105,7 +106,7 @@ func (wb *Webhooks) Start(acc telegraf.Accumulator) error {
 	}
 
 	wb.srv = &http.Server{
-		Handler:      r,
+		Handler:      memlimit.Handler(r),
 		ReadTimeout:  time.Duration(wb.ReadTimeout),
 		WriteTimeout: time.Duration(wb.WriteTimeout),
 	}
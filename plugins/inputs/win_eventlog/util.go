@@ -8,7 +8,10 @@ package win_eventlog
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"unicode/utf16"
 	"unicode/utf8"
@@ -149,3 +152,62 @@ func UniqueFieldNames(fields []EventField, fieldsUsage map[string]int, separator
 	}
 	return fieldsUnique
 }
+
+// queryList is the structured XML query, see
+// https://learn.microsoft.com/en-us/windows/win32/wes/queryschema-schema
+type queryList struct {
+	XMLName xml.Name `xml:"QueryList"`
+	Queries []struct {
+		ID       string   `xml:"Id,attr"`
+		Path     string   `xml:"Path,attr"`
+		Select   []string `xml:"Select"`
+		Suppress []string `xml:"Suppress"`
+	} `xml:"Query"`
+}
+
+// isXMLQuery returns true if the query is a structured XML query instead of
+// a plain XPath expression
+func isXMLQuery(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "<")
+}
+
+// validateXMLQuery checks a structured XML query for errors not reported in
+// a meaningful way by the Windows API
+func validateXMLQuery(query string) error {
+	var ql queryList
+	if err := xml.Unmarshal([]byte(query), &ql); err != nil {
+		return err
+	}
+	if len(ql.Queries) == 0 {
+		return errors.New("no query defined")
+	}
+	for _, q := range ql.Queries {
+		if len(q.Select) == 0 {
+			return fmt.Errorf("query %q has no select statement", q.ID)
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file with the given data by writing to a
+// temporary file and renaming it to not leave a partial file on crashes
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
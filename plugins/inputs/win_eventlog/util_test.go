@@ -10,9 +10,13 @@ import (
 	"encoding/binary"
 	"encoding/xml"
 	"io"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestDecodeUTF16(t *testing.T) {
@@ -199,3 +203,75 @@ func TestUniqueFieldNames(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateXMLQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{
+			name: "valid",
+			query: `
+  <QueryList>
+    <Query Id="0" Path="Security">
+      <Select Path="Security">*</Select>
+      <Suppress Path="Security">*[System[(EventID=4672)]]</Suppress>
+    </Query>
+    <Query Id="1" Path="Application">
+      <Select Path="Application">*[System[(Level &lt; 4)]]</Select>
+    </Query>
+  </QueryList>`,
+		},
+		{
+			name:     "broken",
+			query:    `<QueryList><Query Id="0">`,
+			expected: "unexpected EOF",
+		},
+		{
+			name:     "wrong root",
+			query:    `<Query Id="0"><Select Path="System">*</Select></Query>`,
+			expected: "expected element type <QueryList>",
+		},
+		{
+			name:     "no query",
+			query:    `<QueryList></QueryList>`,
+			expected: "no query defined",
+		},
+		{
+			name:     "no select",
+			query:    `<QueryList><Query Id="3" Path="System"></Query></QueryList>`,
+			expected: `query "3" has no select statement`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.True(t, isXMLQuery(tt.query))
+			err := validateXMLQuery(tt.query)
+			if tt.expected == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+
+	require.False(t, isXMLQuery("Event/System[EventID=999]"))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmark.xml")
+
+	require.NoError(t, writeFileAtomic(path, []byte("first")))
+	require.NoError(t, writeFileAtomic(path, []byte("second")))
+
+	buf, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "second", string(buf))
+
+	// No temporary files must be left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
@@ -13,6 +13,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -43,11 +44,16 @@ type WinEventLog struct {
 	EventFields            []string        `toml:"event_fields"`
 	ExcludeFields          []string        `toml:"exclude_fields"`
 	ExcludeEmpty           []string        `toml:"exclude_empty"`
+	RenderMessage          bool            `toml:"render_message"`
+	ResolveSID             bool            `toml:"resolve_sid"`
+	BookmarkFile           string          `toml:"bookmark_file"`
 	Log                    telegraf.Logger `toml:"-"`
 
 	subscription     EvtHandle
 	subscriptionFlag EvtSubscribeFlag
 	bookmark         EvtHandle
+	xmlQuery         bool
+	accounts         map[string]string
 }
 
 const bufferSize = 1 << 14
@@ -57,6 +63,17 @@ func (*WinEventLog) SampleConfig() string {
 }
 
 func (w *WinEventLog) Init() error {
+	// Structured queries contain the channels to subscribe to, while XPath
+	// queries require a channel name
+	w.xmlQuery = isXMLQuery(w.Query)
+	if w.xmlQuery {
+		if err := validateXMLQuery(w.Query); err != nil {
+			return fmt.Errorf("invalid XML query: %w", err)
+		}
+	} else if w.EventlogName == "" {
+		return errors.New("'eventlog_name' is required for XPath queries")
+	}
+
 	w.subscriptionFlag = EvtSubscribeToFutureEvents
 	if w.FromBeginning {
 		w.subscriptionFlag = EvtSubscribeStartAtOldestRecord
@@ -68,6 +85,20 @@ func (w *WinEventLog) Init() error {
 	}
 	w.bookmark = bookmark
 
+	if w.BookmarkFile != "" {
+		buf, err := os.ReadFile(w.BookmarkFile)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("reading bookmark file failed: %w", err)
+		}
+		if len(buf) > 0 {
+			if err := w.SetState(string(buf)); err != nil {
+				return fmt.Errorf("loading bookmark file failed: %w", err)
+			}
+		}
+	}
+
+	w.accounts = make(map[string]string)
+
 	return nil
 }
 
@@ -84,6 +115,9 @@ func (w *WinEventLog) Start(_ telegraf.Accumulator) error {
 
 func (w *WinEventLog) Stop() {
 	_ = _EvtClose(w.subscription)
+	if err := w.storeBookmark(); err != nil {
+		w.Log.Errorf("Storing bookmark failed: %v", err)
+	}
 }
 
 func (w *WinEventLog) GetState() interface{} {
@@ -101,6 +135,12 @@ func (w *WinEventLog) SetState(state interface{}) error {
 		return fmt.Errorf("invalid type %T for state", state)
 	}
 
+	// A bookmark loaded from the bookmark file is more recent than the state
+	// stored on the last shutdown
+	if w.subscriptionFlag == EvtSubscribeStartAfterBookmark {
+		return nil
+	}
+
 	ptr, err := syscall.UTF16PtrFromString(bookmarkXML)
 	if err != nil {
 		return fmt.Errorf("convertion to pointer failed: %w", err)
@@ -118,6 +158,7 @@ func (w *WinEventLog) SetState(state interface{}) error {
 
 // Gather Windows Event Log entries
 func (w *WinEventLog) Gather(acc telegraf.Accumulator) error {
+	var processed bool
 	for {
 		events, err := w.fetchEvents(w.subscription)
 		if err != nil {
@@ -183,14 +224,9 @@ func (w *WinEventLog) Gather(acc telegraf.Accumulator) error {
 				case "Security":
 					computedValues["UserID"] = event.Security.UserID
 					// Look up UserName and Domain from SID
-					if should, _ := w.shouldProcessField("UserName"); should {
-						sid := event.Security.UserID
-						usid, err := syscall.StringToSid(sid)
-						if err == nil {
-							username, domain, _, err := usid.LookupAccount("")
-							if err == nil {
-								computedValues["UserName"] = fmt.Sprint(domain, "\\", username)
-							}
+					if should, _ := w.shouldProcessField("UserName"); should && w.ResolveSID {
+						if name, err := w.lookupAccount(event.Security.UserID); err == nil {
+							computedValues["UserName"] = name
 						}
 					}
 				default:
@@ -245,12 +281,52 @@ func (w *WinEventLog) Gather(acc telegraf.Accumulator) error {
 
 			// Pass collected metrics
 			acc.AddFields("win_eventlog", fields, tags, timeStamp)
+			processed = true
+		}
+	}
+
+	// Only persist the bookmark after passing on the events to not skip
+	// events on a restart
+	if processed {
+		if err := w.storeBookmark(); err != nil {
+			acc.AddError(fmt.Errorf("storing bookmark failed: %w", err))
 		}
 	}
 
 	return nil
 }
 
+// storeBookmark writes the current bookmark to the bookmark file if any
+func (w *WinEventLog) storeBookmark() error {
+	if w.BookmarkFile == "" {
+		return nil
+	}
+	bookmarkXML, err := w.renderBookmark(w.bookmark)
+	if err != nil {
+		return fmt.Errorf("cannot render bookmark: %w", err)
+	}
+	return writeFileAtomic(w.BookmarkFile, []byte(bookmarkXML))
+}
+
+// lookupAccount resolves the SID to the account name in the form
+// DOMAIN\user and caches the result
+func (w *WinEventLog) lookupAccount(sid string) (string, error) {
+	if name, found := w.accounts[sid]; found {
+		return name, nil
+	}
+	usid, err := syscall.StringToSid(sid)
+	if err != nil {
+		return "", err
+	}
+	username, domain, _, err := usid.LookupAccount("")
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprint(domain, "\\", username)
+	w.accounts[sid] = name
+	return name, nil
+}
+
 func (w *WinEventLog) shouldExclude(field string) (should bool) {
 	for _, excludePattern := range w.ExcludeFields {
 		// Check if field name matches excluded list
@@ -303,9 +379,13 @@ func (w *WinEventLog) evtSubscribe() (EvtHandle, error) {
 	}
 	defer windows.CloseHandle(sigEvent)
 
-	logNamePtr, err := syscall.UTF16PtrFromString(w.EventlogName)
-	if err != nil {
-		return 0, err
+	// The channel must not be set for structured XML queries
+	var logNamePtr *uint16
+	if !w.xmlQuery {
+		logNamePtr, err = syscall.UTF16PtrFromString(w.EventlogName)
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	xqueryPtr, err := syscall.UTF16PtrFromString(w.Query)
@@ -436,14 +516,16 @@ func (w *WinEventLog) renderLocalMessage(event Event, eventHandle EvtHandle) (Ev
 	if err == nil {
 		event.Keywords = keywords
 	}
-	message, err := formatEventString(EvtFormatMessageEvent, eventHandle, publisherHandle)
-	if err == nil {
-		if w.OnlyFirstLineOfMessage {
-			scanner := bufio.NewScanner(strings.NewReader(message))
-			scanner.Scan()
-			message = scanner.Text()
+	if w.RenderMessage {
+		message, err := formatEventString(EvtFormatMessageEvent, eventHandle, publisherHandle)
+		if err == nil {
+			if w.OnlyFirstLineOfMessage {
+				scanner := bufio.NewScanner(strings.NewReader(message))
+				scanner.Scan()
+				message = scanner.Text()
+			}
+			event.Message = message
 		}
-		event.Message = message
 	}
 	level, err := formatEventString(EvtFormatMessageLevel, eventHandle, publisherHandle)
 	if err == nil {
@@ -465,7 +547,7 @@ func (w *WinEventLog) renderRemoteMessage(event Event) (Event, error) {
 	if len(event.RenderingInfo.Keywords) > 0 {
 		event.Keywords = strings.Join(event.RenderingInfo.Keywords, ",")
 	}
-	if event.RenderingInfo.Message != "" {
+	if w.RenderMessage && event.RenderingInfo.Message != "" {
 		message := event.RenderingInfo.Message
 		if w.OnlyFirstLineOfMessage {
 			scanner := bufio.NewScanner(strings.NewReader(message))
@@ -564,6 +646,8 @@ func init() {
 			EventTags:              []string{"Source", "EventID", "Level", "LevelText", "Keywords", "Channel", "Computer"},
 			EventFields:            []string{"*"},
 			ExcludeEmpty:           []string{"Task", "Opcode", "*ActivityID", "UserID"},
+			RenderMessage:          true,
+			ResolveSID:             true,
 		}
 	})
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/influxdata/telegraf/internal/globpath"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 	commontls "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/common/workerpool"
 	"github.com/influxdata/telegraf/plugins/inputs"
 )
 
@@ -44,6 +45,7 @@ type X509Cert struct {
 	Timeout          config.Duration `toml:"timeout"`
 	ServerName       string          `toml:"server_name"`
 	ExcludeRootCerts bool            `toml:"exclude_root_certs"`
+	Concurrency      int             `toml:"concurrency"`
 	Log              telegraf.Logger `toml:"-"`
 	commontls.ClientConfig
 	proxy.TCPProxy
@@ -51,8 +53,6 @@ type X509Cert struct {
 	tlsCfg    *tls.Config
 	locations []*url.URL
 	globpaths []*globpath.GlobPath
-
-	classification map[string]string
 }
 
 func (*X509Cert) SampleConfig() string {
@@ -96,123 +96,127 @@ func (c *X509Cert) Gather(acc telegraf.Accumulator) error {
 	now := time.Now()
 
 	collectedUrls := append(c.locations, c.collectCertURLs()...)
-	for _, location := range collectedUrls {
-		certs, ocspresp, err := c.getCert(location, time.Duration(c.Timeout))
-		if err != nil {
-			acc.AddError(fmt.Errorf("cannot get SSL cert %q: %w", location, err))
-		}
+	workerpool.Run(c.Concurrency, collectedUrls, func(location *url.URL) {
+		c.gatherLocation(acc, location, now)
+	})
 
-		// Add all returned certs to the pool of intermediates except for
-		// the leaf node which has to come first
-		intermediates := x509.NewCertPool()
-		if len(certs) > 1 {
-			for _, c := range certs[1:] {
-				intermediates.AddCert(c)
-			}
-		}
+	return nil
+}
 
-		dnsName := c.serverName(location)
-		results := make([]error, 0, len(certs))
-		c.classification = make(map[string]string)
-		for _, cert := range certs {
-			// The first certificate is the leaf/end-entity certificate which
-			// needs DNS name validation against the URL hostname.
-			opts := x509.VerifyOptions{
-				Intermediates: intermediates,
-				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
-				Roots:         c.tlsCfg.RootCAs,
-				DNSName:       dnsName,
-			}
-			// Reset DNS name to only use it for the leaf node
-			dnsName = ""
+func (c *X509Cert) gatherLocation(acc telegraf.Accumulator, location *url.URL, now time.Time) {
+	certs, ocspresp, err := c.getCert(location, time.Duration(c.Timeout))
+	if err != nil {
+		acc.AddError(fmt.Errorf("cannot get SSL cert %q: %w", location, err))
+	}
 
-			// Do the processing
-			results = append(results, c.processCertificate(cert, opts))
+	// Add all returned certs to the pool of intermediates except for
+	// the leaf node which has to come first
+	intermediates := x509.NewCertPool()
+	if len(certs) > 1 {
+		for _, c := range certs[1:] {
+			intermediates.AddCert(c)
 		}
+	}
 
-		for i, cert := range certs {
-			fields := getFields(cert, now)
-			tags := getTags(cert, location.String())
+	dnsName := c.serverName(location)
+	results := make([]error, 0, len(certs))
+	classification := make(map[string]string)
+	for _, cert := range certs {
+		// The first certificate is the leaf/end-entity certificate which
+		// needs DNS name validation against the URL hostname.
+		opts := x509.VerifyOptions{
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+			Roots:         c.tlsCfg.RootCAs,
+			DNSName:       dnsName,
+		}
+		// Reset DNS name to only use it for the leaf node
+		dnsName = ""
 
-			// Extract the verification result
-			err := results[i]
-			if err == nil {
-				tags["verification"] = "valid"
-				fields["verification_code"] = 0
-			} else {
-				tags["verification"] = "invalid"
-				fields["verification_code"] = 1
-				fields["verification_error"] = err.Error()
-			}
-			// OCSPResponse only for leaf cert
-			if i == 0 && ocspresp != nil && len(*ocspresp) > 0 {
-				var ocspissuer *x509.Certificate
-				for _, chaincert := range certs[1:] {
-					if cert.Issuer.CommonName == chaincert.Subject.CommonName &&
-						cert.Issuer.SerialNumber == chaincert.Subject.SerialNumber {
-						ocspissuer = chaincert
-						break
-					}
-				}
-				resp, err := ocsp.ParseResponse(*ocspresp, ocspissuer)
-				if err != nil {
-					if ocspissuer == nil {
-						tags["ocsp_stapled"] = "no"
-						fields["ocsp_error"] = err.Error()
-					} else {
-						ocspissuer = nil // retry parsing w/out issuer cert
-						resp, err = ocsp.ParseResponse(*ocspresp, ocspissuer)
-					}
+		// Do the processing
+		results = append(results, c.processCertificate(cert, opts, classification))
+	}
+
+	for i, cert := range certs {
+		fields := getFields(cert, now)
+		tags := getTags(cert, location.String())
+
+		// Extract the verification result
+		err := results[i]
+		if err == nil {
+			tags["verification"] = "valid"
+			fields["verification_code"] = 0
+		} else {
+			tags["verification"] = "invalid"
+			fields["verification_code"] = 1
+			fields["verification_error"] = err.Error()
+		}
+		// OCSPResponse only for leaf cert
+		if i == 0 && ocspresp != nil && len(*ocspresp) > 0 {
+			var ocspissuer *x509.Certificate
+			for _, chaincert := range certs[1:] {
+				if cert.Issuer.CommonName == chaincert.Subject.CommonName &&
+					cert.Issuer.SerialNumber == chaincert.Subject.SerialNumber {
+					ocspissuer = chaincert
+					break
 				}
-				if err != nil {
+			}
+			resp, err := ocsp.ParseResponse(*ocspresp, ocspissuer)
+			if err != nil {
+				if ocspissuer == nil {
 					tags["ocsp_stapled"] = "no"
 					fields["ocsp_error"] = err.Error()
 				} else {
-					tags["ocsp_stapled"] = "yes"
-					if ocspissuer != nil {
-						tags["ocsp_verified"] = "yes"
-					} else {
-						tags["ocsp_verified"] = "no"
-					}
-					// resp.Status: 0=Good 1=Revoked 2=Unknown
-					fields["ocsp_status_code"] = resp.Status
-					switch resp.Status {
-					case 0:
-						tags["ocsp_status"] = "good"
-					case 1:
-						tags["ocsp_status"] = "revoked"
-						// Status=Good: revoked_at always = -62135596800
-						fields["ocsp_revoked_at"] = resp.RevokedAt.Unix()
-					default:
-						tags["ocsp_status"] = "unknown"
-					}
-					fields["ocsp_produced_at"] = resp.ProducedAt.Unix()
-					fields["ocsp_this_update"] = resp.ThisUpdate.Unix()
-					fields["ocsp_next_update"] = resp.NextUpdate.Unix()
+					ocspissuer = nil // retry parsing w/out issuer cert
+					resp, err = ocsp.ParseResponse(*ocspresp, ocspissuer)
 				}
-			} else {
-				tags["ocsp_stapled"] = "no"
 			}
-
-			// Determine the classification
-			sig := hex.EncodeToString(cert.Signature)
-			if class, found := c.classification[sig]; found {
-				tags["type"] = class
+			if err != nil {
+				tags["ocsp_stapled"] = "no"
+				fields["ocsp_error"] = err.Error()
 			} else {
-				tags["type"] = "leaf"
+				tags["ocsp_stapled"] = "yes"
+				if ocspissuer != nil {
+					tags["ocsp_verified"] = "yes"
+				} else {
+					tags["ocsp_verified"] = "no"
+				}
+				// resp.Status: 0=Good 1=Revoked 2=Unknown
+				fields["ocsp_status_code"] = resp.Status
+				switch resp.Status {
+				case 0:
+					tags["ocsp_status"] = "good"
+				case 1:
+					tags["ocsp_status"] = "revoked"
+					// Status=Good: revoked_at always = -62135596800
+					fields["ocsp_revoked_at"] = resp.RevokedAt.Unix()
+				default:
+					tags["ocsp_status"] = "unknown"
+				}
+				fields["ocsp_produced_at"] = resp.ProducedAt.Unix()
+				fields["ocsp_this_update"] = resp.ThisUpdate.Unix()
+				fields["ocsp_next_update"] = resp.NextUpdate.Unix()
 			}
+		} else {
+			tags["ocsp_stapled"] = "no"
+		}
 
-			acc.AddFields("x509_cert", fields, tags)
-			if c.ExcludeRootCerts {
-				break
-			}
+		// Determine the classification
+		sig := hex.EncodeToString(cert.Signature)
+		if class, found := classification[sig]; found {
+			tags["type"] = class
+		} else {
+			tags["type"] = "leaf"
 		}
-	}
 
-	return nil
+		acc.AddFields("x509_cert", fields, tags)
+		if c.ExcludeRootCerts {
+			break
+		}
+	}
 }
 
-func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.VerifyOptions) error {
+func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.VerifyOptions, classification map[string]string) error {
 	chains, err := certificate.Verify(opts)
 	if err != nil {
 		c.Log.Debugf("Invalid certificate %v", certificate.SerialNumber.Text(16))
@@ -234,7 +238,7 @@ func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.V
 	rootErr := certificate.CheckSignature(certificate.SignatureAlgorithm, certificate.RawTBSCertificate, certificate.Signature)
 	if rootErr == nil {
 		sig := hex.EncodeToString(certificate.Signature)
-		c.classification[sig] = "root"
+		classification[sig] = "root"
 	}
 
 	// Identify intermediate certificates
@@ -246,14 +250,14 @@ func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.V
 		for _, cert := range chain[1:] {
 			// Never change a classification if we already have one
 			sig := hex.EncodeToString(cert.Signature)
-			if _, found := c.classification[sig]; found {
+			if _, found := classification[sig]; found {
 				continue
 			}
 
 			// We found an intermediate certificate which is not a CA. This
 			// should never happen actually.
 			if !cert.IsCA {
-				c.classification[sig] = "unknown"
+				classification[sig] = "unknown"
 				continue
 			}
 
@@ -262,9 +266,9 @@ func (c *X509Cert) processCertificate(certificate *x509.Certificate, opts x509.V
 			// i.e. you can verify the certificate with its own public key.
 			rootErr := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature)
 			if rootErr != nil {
-				c.classification[sig] = "intermediate"
+				classification[sig] = "intermediate"
 			} else {
-				c.classification[sig] = "root"
+				classification[sig] = "root"
 			}
 		}
 	}
@@ -527,7 +531,8 @@ func (c *X509Cert) collectCertURLs() []*url.URL {
 func init() {
 	inputs.Add("x509_cert", func() telegraf.Input {
 		return &X509Cert{
-			Timeout: config.Duration(5 * time.Second),
+			Timeout:     config.Duration(5 * time.Second),
+			Concurrency: 1,
 		}
 	})
 }
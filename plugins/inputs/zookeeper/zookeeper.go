@@ -26,9 +26,10 @@ var zookeeperFormatRE = regexp.MustCompile(`^zk_(\w[\w\.\-]*)\s+([\w\.\-]+)`)
 
 // Zookeeper is a zookeeper plugin
 type Zookeeper struct {
-	Servers     []string        `toml:"servers"`
-	Timeout     config.Duration `toml:"timeout"`
-	ParseFloats string          `toml:"parse_floats"`
+	Servers       []string        `toml:"servers"`
+	Timeout       config.Duration `toml:"timeout"`
+	ParseFloats   string          `toml:"parse_floats"`
+	JuteMaxBuffer int64           `toml:"jute_maxbuffer"`
 
 	EnableTLS bool `toml:"enable_tls"`
 	EnableSSL bool `toml:"enable_ssl" deprecated:"1.7.0;use 'enable_tls' instead"`
@@ -80,13 +81,37 @@ func (z *Zookeeper) Gather(acc telegraf.Accumulator) error {
 		z.Servers = []string{":2181"}
 	}
 
+	// Collect the stats of all servers first to identify the leader of the
+	// ensemble
+	var leader string
+	results := make([]serverStats, 0, len(z.Servers))
 	for _, serverAddress := range z.Servers {
-		acc.AddError(z.gatherServer(ctx, serverAddress, acc))
+		stats, err := z.gatherServer(ctx, serverAddress)
+		if err != nil {
+			acc.AddError(err)
+			continue
+		}
+		if stats.tags["state"] == "leader" {
+			leader = stats.tags["server"] + ":" + stats.tags["port"]
+		}
+		results = append(results, stats)
+	}
+
+	for _, stats := range results {
+		if leader != "" {
+			stats.tags["leader"] = leader
+		}
+		acc.AddFields("zookeeper", stats.fields, stats.tags)
 	}
 	return nil
 }
 
-func (z *Zookeeper) gatherServer(ctx context.Context, address string, acc telegraf.Accumulator) error {
+type serverStats struct {
+	fields map[string]interface{}
+	tags   map[string]string
+}
+
+func (z *Zookeeper) gatherServer(ctx context.Context, address string) (serverStats, error) {
 	var zookeeperState string
 	_, _, err := net.SplitHostPort(address)
 	if err != nil {
@@ -95,7 +120,7 @@ func (z *Zookeeper) gatherServer(ctx context.Context, address string, acc telegr
 
 	c, err := z.dial(ctx, address)
 	if err != nil {
-		return err
+		return serverStats{}, err
 	}
 	defer c.Close()
 
@@ -103,19 +128,19 @@ func (z *Zookeeper) gatherServer(ctx context.Context, address string, acc telegr
 	deadline, ok := ctx.Deadline()
 	if ok {
 		if err := c.SetDeadline(deadline); err != nil {
-			return err
+			return serverStats{}, err
 		}
 	}
 
 	if _, err := fmt.Fprintf(c, "%s\n", "mntr"); err != nil {
-		return err
+		return serverStats{}, err
 	}
 	rdr := bufio.NewReader(c)
 	scanner := bufio.NewScanner(rdr)
 
 	service := strings.Split(address, ":")
 	if len(service) != 2 {
-		return fmt.Errorf("invalid service address: %s", address)
+		return serverStats{}, fmt.Errorf("invalid service address: %s", address)
 	}
 
 	fields := make(map[string]interface{})
@@ -124,7 +149,7 @@ func (z *Zookeeper) gatherServer(ctx context.Context, address string, acc telegr
 		parts := zookeeperFormatRE.FindStringSubmatch(line)
 
 		if len(parts) != 3 {
-			return fmt.Errorf("unexpected line in mntr response: %q", line)
+			return serverStats{}, fmt.Errorf("unexpected line in mntr response: %q", line)
 		}
 
 		measurement := strings.TrimPrefix(parts[1], "zk_")
@@ -160,14 +185,21 @@ func (z *Zookeeper) gatherServer(ctx context.Context, address string, acc telegr
 		srv = service[0]
 	}
 
+	// Relate the largest response to the buffer size limit of the clients
+	if z.JuteMaxBuffer > 0 {
+		fields["jute_maxbuffer"] = z.JuteMaxBuffer
+		if size, ok := fields["max_client_response_size"].(int64); ok {
+			fields["jute_maxbuffer_usage_percent"] = float64(size) / float64(z.JuteMaxBuffer) * 100
+		}
+	}
+
 	tags := map[string]string{
 		"server": srv,
 		"port":   service[1],
 		"state":  zookeeperState,
 	}
-	acc.AddFields("zookeeper", fields, tags)
 
-	return nil
+	return serverStats{fields: fields, tags: tags}, nil
 }
 
 func init() {
@@ -1,13 +1,21 @@
 package zookeeper
 
 import (
+	"bufio"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -85,3 +93,102 @@ func TestZookeeperGeneratesMetricsIntegration(t *testing.T) {
 		})
 	}
 }
+
+// serveMntr answers the 'mntr' four-letter-word command with the given
+// server state on all connections of the listener
+func serveMntr(t *testing.T, listener net.Listener, state string) {
+	t.Helper()
+
+	response := "zk_version\t3.8.1-74db005175a4ec545697012f9069cb9dcc8cdda7, built on 2023-01-25 16:31 UTC\n" +
+		"zk_server_state\t" + state + "\n" +
+		"zk_znode_count\t5\n" +
+		"zk_max_client_response_size\t262144\n"
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			line, err := bufio.NewReader(conn).ReadString('\n')
+			if err == nil && line == "mntr\n" {
+				_, _ = conn.Write([]byte(response))
+			}
+			conn.Close()
+		}
+	}()
+}
+
+func TestGatherLeader(t *testing.T) {
+	var servers []string
+	for _, state := range []string{"follower", "leader"} {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		serveMntr(t, listener, state)
+		servers = append(servers, listener.Addr().String())
+	}
+
+	plugin := &Zookeeper{
+		Servers:       servers,
+		JuteMaxBuffer: 1048576,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+
+	var expected []telegraf.Metric
+	for i, state := range []string{"follower", "leader"} {
+		host, port, err := net.SplitHostPort(servers[i])
+		require.NoError(t, err)
+		expected = append(expected, metric.New(
+			"zookeeper",
+			map[string]string{
+				"server": host,
+				"port":   port,
+				"state":  state,
+				"leader": servers[1],
+			},
+			map[string]interface{}{
+				"version":                      "3.8.1-74db005175a4ec545697012f9069cb9dcc8cdda7",
+				"znode_count":                  int64(5),
+				"max_client_response_size":     int64(262144),
+				"jute_maxbuffer":               int64(1048576),
+				"jute_maxbuffer_usage_percent": float64(25),
+			},
+			time.Unix(0, 0),
+		))
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics(), testutil.IgnoreTime())
+}
+
+func TestGatherTLS(t *testing.T) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+	cert, err := tls.LoadX509KeyPair(pki.ServerCertPath(), pki.ServerKeyPath())
+	require.NoError(t, err)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	defer listener.Close()
+	serveMntr(t, listener, "standalone")
+
+	plugin := &Zookeeper{
+		Servers:   []string{listener.Addr().String()},
+		Timeout:   config.Duration(5 * time.Second),
+		EnableTLS: true,
+		ClientConfig: tlsint.ClientConfig{
+			InsecureSkipVerify: true,
+		},
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+	m := acc.GetTelegrafMetrics()[0]
+	require.Equal(t, "standalone", m.Tags()["state"])
+	require.NotContains(t, m.Tags(), "leader")
+	v, found := m.GetField("znode_count")
+	require.True(t, found)
+	require.Equal(t, int64(5), v)
+}
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.cassandra
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/cassandra" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.dogstatsd
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/dogstatsd" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || outputs || outputs.redis
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/outputs/redis" // register plugin
@@ -6,6 +6,7 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,6 +15,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
@@ -66,6 +68,7 @@ type AMQP struct {
 	Log                telegraf.Logger   `toml:"-"`
 	tls.ClientConfig
 	proxy.TCPProxy
+	balancer.BalancingConfig
 
 	serializer   serializers.Serializer
 	connect      func(*ClientConfig) (Client, error)
@@ -73,6 +76,12 @@ type AMQP struct {
 	config       *ClientConfig
 	sentMessages int
 	encoder      internal.ContentEncoder
+
+	// Per-broker connections used with load balancing
+	balancer      *balancer.Balancer
+	brokerConfigs []*ClientConfig
+	clients       []Client
+	sent          []int
 }
 
 type Client interface {
@@ -103,6 +112,10 @@ func (q *AMQP) Connect() error {
 		return err
 	}
 
+	if q.LoadBalancing != "" {
+		return q.connectBrokers()
+	}
+
 	q.client, err = q.connect(q.config)
 	if err != nil {
 		return err
@@ -111,10 +124,53 @@ func (q *AMQP) Connect() error {
 	return nil
 }
 
+// connectBrokers opens a connection to each of the brokers for distributing
+// the messages according to the load balancing strategy. Unreachable brokers
+// are connected on their next use.
+func (q *AMQP) connectBrokers() error {
+	b, err := q.BalancingConfig.NewBalancer(q.config.brokers, q.Log)
+	if err != nil {
+		return err
+	}
+	q.balancer = b
+
+	q.brokerConfigs = make([]*ClientConfig, 0, len(q.config.brokers))
+	for _, broker := range q.config.brokers {
+		cfg := *q.config
+		cfg.brokers = []string{broker}
+		q.brokerConfigs = append(q.brokerConfigs, &cfg)
+	}
+	q.clients = make([]Client, len(q.brokerConfigs))
+	q.sent = make([]int, len(q.brokerConfigs))
+
+	var connected bool
+	for idx, cfg := range q.brokerConfigs {
+		client, err := q.connect(cfg)
+		if err != nil {
+			q.Log.Warnf("Connecting to %q failed: %v", cfg.brokers[0], err)
+			continue
+		}
+		q.clients[idx] = client
+		connected = true
+	}
+	if !connected {
+		return errors.New("could not connect to any broker")
+	}
+	return nil
+}
+
 func (q *AMQP) Close() error {
 	if q.client != nil {
 		return q.client.Close()
 	}
+	for idx, client := range q.clients {
+		if client == nil {
+			continue
+		}
+		if err := client.Close(); err != nil {
+			q.Log.Errorf("Closing connection to %q failed: %v", q.brokerConfigs[idx].brokers[0], err)
+		}
+	}
 	return nil
 }
 
@@ -129,6 +185,10 @@ func (q *AMQP) routingKey(metric telegraf.Metric) string {
 }
 
 func (q *AMQP) Write(metrics []telegraf.Metric) error {
+	if q.balancer != nil {
+		return q.writeBalanced(metrics)
+	}
+
 	batches := make(map[string][]telegraf.Metric)
 	if q.ExchangeType == "header" {
 		// Since the routing_key is ignored for this exchange type send as a
@@ -191,6 +251,103 @@ func (q *AMQP) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// writeBalanced distributes the messages of each routing key across the
+// brokers, failed messages are published to the next broker. If only some of
+// the messages failed, the failed metrics are returned as partial write.
+func (q *AMQP) writeBalanced(metrics []telegraf.Metric) error {
+	// Group the metrics by routing key keeping their index in the batch. The
+	// routing key is ignored for the header exchange type.
+	var keys []string
+	indices := make(map[string][]int)
+	for i, m := range metrics {
+		var key string
+		if q.ExchangeType != "header" {
+			key = q.routingKey(m)
+		}
+		if _, found := indices[key]; !found {
+			keys = append(keys, key)
+		}
+		indices[key] = append(indices[key], i)
+	}
+
+	var errs []error
+	var failed []int
+	for _, key := range keys {
+		batch := make([]telegraf.Metric, 0, len(indices[key]))
+		for _, i := range indices[key] {
+			batch = append(batch, metrics[i])
+		}
+
+		err := q.balancer.Write(batch, func(idx int, batch []telegraf.Metric) error {
+			body, err := q.serialize(batch)
+			if err != nil {
+				return err
+			}
+			body, err = q.encoder.Encode(body)
+			if err != nil {
+				return err
+			}
+			if err := q.publishTo(idx, key, body); err != nil {
+				q.Log.Errorf("When publishing to %q: %v", q.brokerConfigs[idx].brokers[0], err)
+				return err
+			}
+			return nil
+		})
+		if err == nil {
+			continue
+		}
+		errs = append(errs, err)
+
+		var partial *internal.PartialWriteError
+		if errors.As(err, &partial) {
+			for _, i := range partial.MetricsFailed {
+				failed = append(failed, indices[key][i])
+			}
+		} else {
+			failed = append(failed, indices[key]...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	err := errors.Join(errs...)
+	if len(failed) == len(metrics) {
+		return err
+	}
+	sort.Ints(failed)
+	return &internal.PartialWriteError{Err: err, MetricsFailed: failed}
+}
+
+func (q *AMQP) publishTo(idx int, key string, body []byte) error {
+	if q.clients[idx] == nil {
+		client, err := q.connect(q.brokerConfigs[idx])
+		if err != nil {
+			return err
+		}
+		q.sent[idx] = 0
+		q.clients[idx] = client
+	}
+
+	if err := q.clients[idx].Publish(key, body); err != nil {
+		if err := q.clients[idx].Close(); err != nil {
+			q.Log.Debugf("Closing connection failed: %v", err)
+		}
+		q.clients[idx] = nil
+		return err
+	}
+	q.sent[idx]++
+
+	if q.MaxMessages > 0 && q.sent[idx] >= q.MaxMessages {
+		q.Log.Debug("Sent MaxMessages; closing connection")
+		if err := q.clients[idx].Close(); err != nil {
+			q.Log.Errorf("Closing connection failed: %v", err)
+		}
+		q.clients[idx] = nil
+	}
+	return nil
+}
+
 func (q *AMQP) publish(key string, body []byte) error {
 	if q.client == nil {
 		client, err := q.connect(q.config)
@@ -332,7 +489,11 @@ func init() {
 			Database:        DefaultDatabase,
 			RetentionPolicy: DefaultRetentionPolicy,
 			Timeout:         config.Duration(time.Second * 5),
-			connect:         connect,
+			BalancingConfig: balancer.BalancingConfig{
+				FailureThreshold: 3,
+				EjectionTime:     config.Duration(30 * time.Second),
+			},
+			connect: connect,
 		}
 	})
 }
@@ -1,10 +1,14 @@
 package amqp
 
 import (
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/stretchr/testify/require"
 )
@@ -154,3 +158,42 @@ func TestConnect(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteLoadBalancing(t *testing.T) {
+	clients := make(map[string]*MockClient)
+	failing := errors.New("connection closed")
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &AMQP{
+		Brokers: []string{"amqp://a:5672", "amqp://b:5672"},
+		BalancingConfig: balancer.BalancingConfig{
+			LoadBalancing:    "round_robin",
+			FailureThreshold: 1,
+			EjectionTime:     config.Duration(time.Hour),
+		},
+		Log: testutil.Logger{},
+		connect: func(cfg *ClientConfig) (Client, error) {
+			require.Len(t, cfg.brokers, 1)
+			client := NewMockClient().(*MockClient)
+			if cfg.brokers[0] == "amqp://a:5672" {
+				client.PublishF = func(string, []byte) error { return failing }
+			}
+			clients[cfg.brokers[0]] = client
+			return client, nil
+		},
+	}
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+	require.Len(t, clients, 2)
+
+	// The failing broker is ejected after the first message, all messages
+	// are published to the other one
+	for i := 0; i < 3; i++ {
+		require.NoError(t, plugin.Write(testutil.MockMetrics()))
+	}
+	require.Equal(t, 1, clients["amqp://a:5672"].PublishCallCount)
+	require.Equal(t, 3, clients["amqp://b:5672"].PublishCallCount)
+	require.NoError(t, plugin.Close())
+}
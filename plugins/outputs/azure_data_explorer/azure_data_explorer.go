@@ -27,14 +27,17 @@ import (
 var sampleConfig string
 
 type AzureDataExplorer struct {
-	Endpoint        string          `toml:"endpoint_url"`
-	Database        string          `toml:"database"`
-	Log             telegraf.Logger `toml:"-"`
-	Timeout         config.Duration `toml:"timeout"`
-	MetricsGrouping string          `toml:"metrics_grouping_type"`
-	TableName       string          `toml:"table_name"`
-	CreateTables    bool            `toml:"create_tables"`
-	IngestionType   string          `toml:"ingestion_type"`
+	Endpoint        string            `toml:"endpoint_url"`
+	Database        string            `toml:"database"`
+	Log             telegraf.Logger   `toml:"-"`
+	Timeout         config.Duration   `toml:"timeout"`
+	MetricsGrouping string            `toml:"metrics_grouping_type"`
+	TableName       string            `toml:"table_name"`
+	CreateTables    bool              `toml:"create_tables"`
+	IngestionType   string            `toml:"ingestion_type"`
+	TableMapping    map[string]string `toml:"table_mapping"`
+	AuthMethod      string            `toml:"auth_method"`
+	ClientID        string            `toml:"managed_identity_client_id"`
 	serializer      serializers.Serializer
 	kustoClient     *kusto.Client
 	metricIngestors map[string]ingest.Ingestor
@@ -50,6 +53,12 @@ const (
 
 const managedIngestion = "managed"
 const queuedIngestion = "queued"
+const streamingIngestion = "streaming"
+
+const (
+	authDefault         = "default"
+	authManagedIdentity = "managed_identity"
+)
 
 func (*AzureDataExplorer) SampleConfig() string {
 	return sampleConfig
@@ -57,7 +66,17 @@ func (*AzureDataExplorer) SampleConfig() string {
 
 // Initialize the client and the ingestor
 func (adx *AzureDataExplorer) Connect() error {
-	conn := kusto.NewConnectionStringBuilder(adx.Endpoint).WithDefaultAzureCredential()
+	conn := kusto.NewConnectionStringBuilder(adx.Endpoint)
+	switch adx.AuthMethod {
+	case authManagedIdentity:
+		if adx.ClientID != "" {
+			conn = conn.WithUserManagedIdentity(adx.ClientID)
+		} else {
+			conn = conn.WithSystemManagedIdentity()
+		}
+	default:
+		conn = conn.WithDefaultAzureCredential()
+	}
 	client, err := kusto.New(conn)
 	if err != nil {
 		return err
@@ -104,6 +123,9 @@ func (adx *AzureDataExplorer) writeTablePerMetric(metrics []telegraf.Metric) err
 	// Group metrics by name and serialize them
 	for _, m := range metrics {
 		tableName := m.Name()
+		if name, found := adx.TableMapping[tableName]; found {
+			tableName = name
+		}
 		metricInBytes, err := adx.serializer.Serialize(m)
 		if err != nil {
 			return err
@@ -204,6 +226,14 @@ func (adx *AzureDataExplorer) createAzureDataExplorerTable(ctx context.Context,
 		return err
 	}
 
+	// Streaming ingestion is rejected unless the policy is enabled on the
+	// table or the database
+	if adx.IngestionType == streamingIngestion {
+		if _, err := adx.kustoClient.Mgmt(ctx, adx.Database, enableStreamingPolicyCommand(tableName)); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -228,10 +258,26 @@ func (adx *AzureDataExplorer) Init() error {
 
 	if adx.IngestionType == "" {
 		adx.IngestionType = queuedIngestion
-	} else if !(choice.Contains(adx.IngestionType, []string{managedIngestion, queuedIngestion})) {
+	} else if !(choice.Contains(adx.IngestionType, []string{managedIngestion, queuedIngestion, streamingIngestion})) {
 		return fmt.Errorf("unknown ingestion type %q", adx.IngestionType)
 	}
 
+	switch adx.AuthMethod {
+	case "":
+		adx.AuthMethod = authDefault
+	case authDefault:
+		if adx.ClientID != "" {
+			return errors.New("managed identity client ID requires the managed_identity authentication method")
+		}
+	case authManagedIdentity:
+	default:
+		return fmt.Errorf("unknown authentication method %q", adx.AuthMethod)
+	}
+
+	if adx.MetricsGrouping == singleTable && len(adx.TableMapping) > 0 {
+		return errors.New("table mapping cannot be used with SingleTable metrics grouping type")
+	}
+
 	serializer := &json.Serializer{
 		TimestampUnits:  config.Duration(time.Nanosecond),
 		TimestampFormat: time.RFC3339Nano,
@@ -261,8 +307,11 @@ func createIngestorByTable(client *kusto.Client, database string, tableName stri
 	case queuedIngestion:
 		qi, err := ingest.New(client, database, tableName, ingest.WithStaticBuffer(bufferSize, maxBuffers))
 		return qi, err
+	case streamingIngestion:
+		si, err := ingest.NewStreaming(client, database, tableName)
+		return si, err
 	}
-	return nil, fmt.Errorf(`ingestion_type has to be one of %q or %q`, managedIngestion, queuedIngestion)
+	return nil, fmt.Errorf(`ingestion_type has to be one of %q, %q or %q`, managedIngestion, queuedIngestion, streamingIngestion)
 }
 
 func createTableCommand(table string) kusto.Statement {
@@ -283,3 +332,9 @@ func createTableMappingCommand(table string) kusto.Statement {
 
 	return builder
 }
+
+func enableStreamingPolicyCommand(table string) kusto.Statement {
+	builder := kql.New(`.alter table ['`).AddTable(table).AddLiteral(`'] policy streamingingestion enable`)
+
+	return builder
+}
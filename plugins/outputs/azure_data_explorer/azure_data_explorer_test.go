@@ -312,6 +312,99 @@ func TestQueryConstruction(t *testing.T) {
 		`"Properties":{"Path":"$[\'tags\']"}},{"column":"timestamp", "Properties":{"Path":"$[\'timestamp\']"}}]'`
 	require.Equal(t, expectedCreate, createTableCommand(tableName).String())
 	require.Equal(t, expectedMapping, createTableMappingCommand(tableName).String())
+
+	const expectedPolicy = `.alter table ['mytable'] policy streamingingestion enable`
+	require.Equal(t, expectedPolicy, enableStreamingPolicyCommand(tableName).String())
+}
+
+func TestInitInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   AzureDataExplorer
+		expected string
+	}{
+		{
+			name: "unknown ingestion type",
+			plugin: AzureDataExplorer{
+				IngestionType: "teleport",
+			},
+			expected: `unknown ingestion type "teleport"`,
+		},
+		{
+			name: "unknown auth method",
+			plugin: AzureDataExplorer{
+				AuthMethod: "password",
+			},
+			expected: `unknown authentication method "password"`,
+		},
+		{
+			name: "client ID without managed identity",
+			plugin: AzureDataExplorer{
+				AuthMethod: "default",
+				ClientID:   "00000000-0000-0000-0000-000000000000",
+			},
+			expected: "managed identity client ID requires the managed_identity authentication method",
+		},
+		{
+			name: "table mapping with single table",
+			plugin: AzureDataExplorer{
+				MetricsGrouping: singleTable,
+				TableName:       "metrics",
+				TableMapping:    map[string]string{"cpu": "cpu_metrics"},
+			},
+			expected: "table mapping cannot be used with SingleTable metrics grouping type",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := tt.plugin
+			plugin.Endpoint = "someendpoint"
+			plugin.Database = "databasename"
+			plugin.Log = testutil.Logger{}
+			require.EqualError(t, plugin.Init(), tt.expected)
+		})
+	}
+
+	plugin := AzureDataExplorer{
+		Endpoint:      "someendpoint",
+		Database:      "databasename",
+		IngestionType: streamingIngestion,
+		AuthMethod:    authManagedIdentity,
+		ClientID:      "00000000-0000-0000-0000-000000000000",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+}
+
+func TestWriteTableMapping(t *testing.T) {
+	serializer := &telegrafJson.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	mappedIngestor := &mockIngestor{}
+	unmappedIngestor := &mockIngestor{}
+	plugin := AzureDataExplorer{
+		Endpoint:        "someendpoint",
+		Database:        "databasename",
+		Log:             testutil.Logger{},
+		MetricsGrouping: tablePerMetric,
+		TableMapping:    map[string]string{"test2": "mapped"},
+		kustoClient:     kusto.NewMockClient(),
+		metricIngestors: map[string]ingest.Ingestor{
+			"mapped": mappedIngestor,
+			"test3":  unmappedIngestor,
+		},
+		serializer:    serializer,
+		IngestionType: streamingIngestion,
+	}
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(1.0, "test2"),
+		testutil.TestMetric(2.0, "test3"),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	require.JSONEq(t, `{"fields":{"value":1.0},"name":"test2","tags":{"tag1":"value1"},"timestamp":1257894000}`, mappedIngestor.records[0])
+	require.JSONEq(t, `{"fields":{"value":2.0},"name":"test3","tags":{"tag1":"value1"},"timestamp":1257894000}`, unmappedIngestor.records[0])
 }
 
 type fakeIngestor struct {
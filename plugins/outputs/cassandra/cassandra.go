@@ -0,0 +1,325 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cassandra
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gocql/gocql"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var identifier = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+const createTable = `CREATE TABLE IF NOT EXISTS %s.%s (
+	partition text,
+	bucket timestamp,
+	measurement text,
+	series text,
+	time timestamp,
+	tags map<text, text>,
+	fields map<text, double>,
+	string_fields map<text, text>,
+	PRIMARY KEY ((partition, bucket), measurement, series, time)
+) WITH CLUSTERING ORDER BY (measurement ASC, series ASC, time DESC)`
+
+const insertRow = `INSERT INTO %s.%s
+	(partition, bucket, measurement, series, time, tags, fields, string_fields)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?) USING TTL ?`
+
+type Cassandra struct {
+	Servers         []string                   `toml:"servers"`
+	Keyspace        string                     `toml:"keyspace"`
+	Table           string                     `toml:"table"`
+	Username        config.Secret              `toml:"username"`
+	Password        config.Secret              `toml:"password"`
+	LocalDC         string                     `toml:"local_dc"`
+	Consistency     string                     `toml:"consistency"`
+	Timeout         config.Duration            `toml:"timeout"`
+	CreateTable     bool                       `toml:"create_table"`
+	PartitionKey    string                     `toml:"partition_key"`
+	PartitionBucket config.Duration            `toml:"partition_bucket"`
+	TTL             config.Duration            `toml:"ttl"`
+	MeasurementTTL  map[string]config.Duration `toml:"measurement_ttl"`
+	MaxBatchSize    int                        `toml:"max_batch_size"`
+	Log             telegraf.Logger            `toml:"-"`
+	tls.ClientConfig
+
+	partitionKey *template.Template
+	consistency  gocql.Consistency
+	insert       string
+	session      *gocql.Session
+}
+
+// row is a metric converted to the table layout
+type row struct {
+	partition    string
+	bucket       time.Time
+	measurement  string
+	series       string
+	timestamp    time.Time
+	tags         map[string]string
+	fields       map[string]float64
+	stringFields map[string]string
+	ttl          int
+}
+
+func (*Cassandra) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *Cassandra) Init() error {
+	if len(c.Servers) == 0 {
+		return errors.New("no servers specified")
+	}
+	if !identifier.MatchString(c.Keyspace) {
+		return fmt.Errorf("invalid keyspace %q", c.Keyspace)
+	}
+	if !identifier.MatchString(c.Table) {
+		return fmt.Errorf("invalid table %q", c.Table)
+	}
+
+	var err error
+	c.consistency, err = gocql.ParseConsistencyWrapper(c.Consistency)
+	if err != nil {
+		return fmt.Errorf("invalid consistency: %w", err)
+	}
+
+	c.partitionKey, err = template.New("partition_key").Parse(c.PartitionKey)
+	if err != nil {
+		return fmt.Errorf("parsing partition key template failed: %w", err)
+	}
+	if c.PartitionBucket < 0 {
+		return errors.New("'partition_bucket' must not be negative")
+	}
+
+	if c.TTL < 0 {
+		return errors.New("'ttl' must not be negative")
+	}
+	for name, ttl := range c.MeasurementTTL {
+		if ttl < 0 {
+			return fmt.Errorf("TTL for measurement %q must not be negative", name)
+		}
+	}
+
+	if c.MaxBatchSize < 1 {
+		c.MaxBatchSize = 1
+	}
+	c.insert = fmt.Sprintf(insertRow, c.Keyspace, c.Table)
+
+	return nil
+}
+
+func (c *Cassandra) Connect() error {
+	cluster := gocql.NewCluster(c.Servers...)
+	cluster.Keyspace = c.Keyspace
+	cluster.Consistency = c.consistency
+	cluster.Timeout = time.Duration(c.Timeout)
+	cluster.ConnectTimeout = time.Duration(c.Timeout)
+
+	// Send each batch directly to a replica of its partition
+	fallback := gocql.RoundRobinHostPolicy()
+	if c.LocalDC != "" {
+		fallback = gocql.DCAwareRoundRobinPolicy(c.LocalDC)
+	}
+	cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(fallback)
+
+	if !c.Username.Empty() {
+		username, err := c.Username.Get()
+		if err != nil {
+			return fmt.Errorf("getting username failed: %w", err)
+		}
+		password, err := c.Password.Get()
+		if err != nil {
+			config.ReleaseSecret(username)
+			return fmt.Errorf("getting password failed: %w", err)
+		}
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: string(username),
+			Password: string(password),
+		}
+		config.ReleaseSecret(username)
+		config.ReleaseSecret(password)
+	}
+
+	tlsCfg, err := c.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	if tlsCfg != nil {
+		cluster.SslOpts = &gocql.SslOptions{
+			Config:                 tlsCfg,
+			EnableHostVerification: !tlsCfg.InsecureSkipVerify,
+		}
+	}
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return fmt.Errorf("connecting to cluster failed: %w", err)
+	}
+
+	if c.CreateTable {
+		if err := session.Query(fmt.Sprintf(createTable, c.Keyspace, c.Table)).Exec(); err != nil {
+			session.Close()
+			return fmt.Errorf("creating table failed: %w", err)
+		}
+	}
+	c.session = session
+
+	return nil
+}
+
+func (c *Cassandra) Close() error {
+	if c.session != nil {
+		c.session.Close()
+	}
+	return nil
+}
+
+func (c *Cassandra) Write(metrics []telegraf.Metric) error {
+	rows := make([]*row, 0, len(metrics))
+	for _, m := range metrics {
+		r, err := c.convert(m)
+		if err != nil {
+			c.Log.Errorf("Dropping metric: %v", err)
+			continue
+		}
+		rows = append(rows, r)
+	}
+
+	// Inserts are idempotent so batches already written before an error do
+	// not cause duplicates when the metrics are written again
+	for _, batch := range c.batches(rows) {
+		b := c.session.NewBatch(gocql.UnloggedBatch)
+		for _, r := range batch {
+			b.Query(c.insert,
+				r.partition, r.bucket, r.measurement, r.series, r.timestamp,
+				r.tags, r.fields, r.stringFields, r.ttl,
+			)
+		}
+		if err := c.session.ExecuteBatch(b); err != nil {
+			return fmt.Errorf("writing batch failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// convert turns a metric into a table row
+func (c *Cassandra) convert(m telegraf.Metric) (*row, error) {
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return nil, fmt.Errorf("metric %q is not a template metric", m.Name())
+	}
+	var buf bytes.Buffer
+	if err := c.partitionKey.Execute(&buf, tm); err != nil {
+		return nil, fmt.Errorf("executing partition key template failed: %w", err)
+	}
+
+	r := &row{
+		partition:    buf.String(),
+		measurement:  m.Name(),
+		timestamp:    m.Time(),
+		tags:         m.Tags(),
+		fields:       make(map[string]float64),
+		stringFields: make(map[string]string),
+	}
+	if c.PartitionBucket > 0 {
+		r.bucket = m.Time().Truncate(time.Duration(c.PartitionBucket))
+	} else {
+		r.bucket = time.Unix(0, 0)
+	}
+
+	series := make([]string, 0, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		series = append(series, tag.Key+"="+tag.Value)
+	}
+	sort.Strings(series)
+	r.series = strings.Join(series, ",")
+
+	for _, field := range m.FieldList() {
+		switch v := field.Value.(type) {
+		case float64:
+			r.fields[field.Key] = v
+		case int64:
+			r.fields[field.Key] = float64(v)
+		case uint64:
+			r.fields[field.Key] = float64(v)
+		case bool:
+			if v {
+				r.fields[field.Key] = 1
+			} else {
+				r.fields[field.Key] = 0
+			}
+		case string:
+			r.stringFields[field.Key] = v
+		}
+	}
+
+	ttl := c.TTL
+	if v, found := c.MeasurementTTL[m.Name()]; found {
+		ttl = v
+	}
+	r.ttl = int(time.Duration(ttl).Seconds())
+
+	return r, nil
+}
+
+// batches groups the rows by partition and splits the groups according to
+// the maximum batch size. Batches only contain rows of a single partition,
+// so they can be sent directly to a replica of the partition.
+func (c *Cassandra) batches(rows []*row) [][]*row {
+	type partition struct {
+		key    string
+		bucket int64
+	}
+
+	var order []partition
+	groups := make(map[partition][]*row)
+	for _, r := range rows {
+		p := partition{key: r.partition, bucket: r.bucket.UnixNano()}
+		if _, found := groups[p]; !found {
+			order = append(order, p)
+		}
+		groups[p] = append(groups[p], r)
+	}
+
+	var batches [][]*row
+	for _, p := range order {
+		group := groups[p]
+		for len(group) > c.MaxBatchSize {
+			batches = append(batches, group[:c.MaxBatchSize])
+			group = group[c.MaxBatchSize:]
+		}
+		batches = append(batches, group)
+	}
+	return batches
+}
+
+func init() {
+	outputs.Add("cassandra", func() telegraf.Output {
+		return &Cassandra{
+			Table:           "metrics",
+			Consistency:     "quorum",
+			Timeout:         config.Duration(5 * time.Second),
+			CreateTable:     true,
+			PartitionKey:    `{{.Name}}`,
+			PartitionBucket: config.Duration(24 * time.Hour),
+			MaxBatchSize:    100,
+		}
+	})
+}
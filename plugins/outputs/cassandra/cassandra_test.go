@@ -0,0 +1,217 @@
+package cassandra
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPlugin() *Cassandra {
+	return &Cassandra{
+		Servers:         []string{"127.0.0.1:9042"},
+		Keyspace:        "telegraf",
+		Table:           "metrics",
+		Consistency:     "quorum",
+		Timeout:         config.Duration(5 * time.Second),
+		PartitionKey:    `{{.Name}}`,
+		PartitionBucket: config.Duration(24 * time.Hour),
+		MaxBatchSize:    100,
+		Log:             testutil.Logger{},
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Cassandra)
+		expected string
+	}{
+		{
+			name:     "no servers",
+			modify:   func(c *Cassandra) { c.Servers = nil },
+			expected: "no servers specified",
+		},
+		{
+			name:     "invalid keyspace",
+			modify:   func(c *Cassandra) { c.Keyspace = "tele;graf" },
+			expected: "invalid keyspace",
+		},
+		{
+			name:     "invalid table",
+			modify:   func(c *Cassandra) { c.Table = "" },
+			expected: "invalid table",
+		},
+		{
+			name:     "invalid consistency",
+			modify:   func(c *Cassandra) { c.Consistency = "most" },
+			expected: "invalid consistency",
+		},
+		{
+			name:     "invalid template",
+			modify:   func(c *Cassandra) { c.PartitionKey = "{{.Name" },
+			expected: "parsing partition key template failed",
+		},
+		{
+			name:     "negative ttl",
+			modify:   func(c *Cassandra) { c.MeasurementTTL = map[string]config.Duration{"cpu": -1} },
+			expected: `TTL for measurement "cpu"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newPlugin()
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	plugin := newPlugin()
+	plugin.PartitionKey = `{{.Name}}/{{.Tag "host"}}`
+	plugin.TTL = config.Duration(time.Hour)
+	plugin.MeasurementTTL = map[string]config.Duration{"disk": config.Duration(48 * time.Hour)}
+	require.NoError(t, plugin.Init())
+
+	ts := time.Date(2023, 3, 1, 13, 14, 15, 0, time.UTC)
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "a", "cpu": "cpu0"},
+		map[string]interface{}{
+			"usage": 42.5,
+			"count": int64(3),
+			"total": uint64(7),
+			"ok":    true,
+			"state": "running",
+		},
+		ts,
+	)
+	r, err := plugin.convert(m)
+	require.NoError(t, err)
+	require.Equal(t, &row{
+		partition:    "cpu/a",
+		bucket:       time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+		measurement:  "cpu",
+		series:       "cpu=cpu0,host=a",
+		timestamp:    ts,
+		tags:         map[string]string{"host": "a", "cpu": "cpu0"},
+		fields:       map[string]float64{"usage": 42.5, "count": 3, "total": 7, "ok": 1},
+		stringFields: map[string]string{"state": "running"},
+		ttl:          3600,
+	}, r)
+
+	// Measurement specific TTL
+	m = metric.New("disk", map[string]string{"host": "a"}, map[string]interface{}{"free": 1.0}, ts)
+	r, err = plugin.convert(m)
+	require.NoError(t, err)
+	require.Equal(t, 172800, r.ttl)
+	require.Equal(t, "disk/a", r.partition)
+}
+
+func TestBatches(t *testing.T) {
+	plugin := newPlugin()
+	plugin.PartitionKey = `{{.Tag "host"}}`
+	plugin.PartitionBucket = config.Duration(time.Hour)
+	plugin.MaxBatchSize = 2
+	require.NoError(t, plugin.Init())
+
+	start := time.Unix(1677628800, 0)
+	var rows []*row
+	for i, host := range []string{"a", "b", "a", "a", "b", "a"} {
+		m := metric.New("cpu", map[string]string{"host": host}, map[string]interface{}{"value": float64(i)}, start.Add(time.Duration(i)*time.Minute))
+		r, err := plugin.convert(m)
+		require.NoError(t, err)
+		rows = append(rows, r)
+	}
+	// Next time bucket
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 6.0}, start.Add(time.Hour))
+	r, err := plugin.convert(m)
+	require.NoError(t, err)
+	rows = append(rows, r)
+
+	var actual [][]float64
+	for _, batch := range plugin.batches(rows) {
+		values := make([]float64, 0, len(batch))
+		for _, r := range batch {
+			values = append(values, r.fields["value"])
+		}
+		actual = append(actual, values)
+	}
+	require.Equal(t, [][]float64{{0, 2}, {3, 5}, {1, 4}, {6}}, actual)
+}
+
+func TestWriteIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	servicePort := "9042"
+	container := testutil.Container{
+		Image:        "scylladb/scylla",
+		ExposedPorts: []string{servicePort},
+		Cmd:          []string{"--smp", "1", "--developer-mode", "1"},
+		WaitingFor: wait.ForAll(
+			wait.ForListeningPort(nat.Port(servicePort)),
+			wait.ForLog("init - serving"),
+		),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	defer container.Terminate()
+	address := fmt.Sprintf("%s:%s", container.Address, container.Ports[servicePort])
+
+	// Create the keyspace
+	cluster := gocql.NewCluster(address)
+	cluster.Timeout = 10 * time.Second
+	session, err := cluster.CreateSession()
+	require.NoError(t, err)
+	defer session.Close()
+	require.NoError(t, session.Query(
+		`CREATE KEYSPACE telegraf WITH replication = {'class': 'SimpleStrategy', 'replication_factor': 1}`,
+	).Exec())
+
+	plugin := newPlugin()
+	plugin.Servers = []string{address}
+	plugin.Consistency = "one"
+	plugin.CreateTable = true
+	plugin.PartitionKey = `{{.Name}}/{{.Tag "host"}}`
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	ts := time.Unix(1677628800, 0)
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5, "state": "ok"}, ts),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 43.5, "state": "ok"}, ts.Add(time.Second)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// Writing the same metrics again must not duplicate rows
+	require.NoError(t, plugin.Write(metrics))
+
+	var count int
+	require.NoError(t, session.Query(
+		`SELECT COUNT(*) FROM telegraf.metrics WHERE partition = ? AND bucket = ?`,
+		"cpu/a", ts.Truncate(24*time.Hour),
+	).Scan(&count))
+	require.Equal(t, 2, count)
+
+	var fields map[string]float64
+	var stringFields map[string]string
+	require.NoError(t, session.Query(
+		`SELECT fields, string_fields FROM telegraf.metrics WHERE partition = ? AND bucket = ? LIMIT 1`,
+		"cpu/a", ts.Truncate(24*time.Hour),
+	).Scan(&fields, &stringFields))
+	require.Equal(t, map[string]float64{"usage": 43.5}, fields)
+	require.Equal(t, map[string]string{"state": "ok"}, stringFields)
+}
@@ -37,6 +37,13 @@ type PubSub struct {
 	Base64Data            bool            `toml:"base64_data"`
 	ContentEncoding       string          `toml:"content_encoding"`
 
+	ContentEncodingAttribute string `toml:"content_encoding_attribute"`
+	OrderingKeyTag           string `toml:"ordering_key_tag"`
+
+	FlowControlMaxMessages int    `toml:"publish_flow_control_max_messages"`
+	FlowControlMaxBytes    int    `toml:"publish_flow_control_max_bytes"`
+	FlowControlBehavior    string `toml:"publish_flow_control_behavior"`
+
 	Log telegraf.Logger `toml:"-"`
 
 	t topic
@@ -47,6 +54,7 @@ type PubSub struct {
 	serializer     serializers.Serializer
 	publishResults []publishResult
 	encoder        internal.ContentEncoder
+	limitBehavior  pubsub.LimitExceededBehavior
 }
 
 func (*PubSub) SampleConfig() string {
@@ -93,7 +101,7 @@ func (ps *PubSub) Write(metrics []telegraf.Metric) error {
 	// if PubSub batch limits have not been reached.
 	go ps.t.Stop()
 
-	return ps.waitForResults(cctx, cancel)
+	return ps.waitForResults(cctx, cancel, msgs)
 }
 
 func (ps *PubSub) initPubSubClient() error {
@@ -131,6 +139,7 @@ func (ps *PubSub) refreshTopic() {
 		ps.t = &topicWrapper{t}
 	}
 	ps.t.SetPublishSettings(ps.publishSettings())
+	ps.t.SetEnableMessageOrdering(ps.OrderingKeyTag != "")
 }
 
 func (ps *PubSub) publishSettings() pubsub.PublishSettings {
@@ -153,28 +162,52 @@ func (ps *PubSub) publishSettings() pubsub.PublishSettings {
 		settings.ByteThreshold = ps.PublishByteThreshold
 	}
 
+	settings.FlowControlSettings = pubsub.FlowControlSettings{
+		MaxOutstandingMessages: ps.FlowControlMaxMessages,
+		MaxOutstandingBytes:    ps.FlowControlMaxBytes,
+		LimitExceededBehavior:  ps.limitBehavior,
+	}
+
 	return settings
 }
 
 func (ps *PubSub) toMessages(metrics []telegraf.Metric) ([]*pubsub.Message, error) {
+	attributes := ps.messageAttributes()
+
 	if ps.SendBatched {
-		b, err := ps.serializer.SerializeBatch(metrics)
-		if err != nil {
-			return nil, err
+		// Messages can only carry a single ordering key, so group the metrics
+		// by key keeping the order of the metrics within each group.
+		keys := make([]string, 0, 1)
+		groups := make(map[string][]telegraf.Metric, 1)
+		for _, m := range metrics {
+			key := ps.orderingKey(m)
+			if _, found := groups[key]; !found {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], m)
 		}
 
-		b = ps.encodeB64Data(b)
+		msgs := make([]*pubsub.Message, 0, len(keys))
+		for _, key := range keys {
+			b, err := ps.serializer.SerializeBatch(groups[key])
+			if err != nil {
+				return nil, err
+			}
 
-		b, err = ps.compressData(b)
-		if err != nil {
-			return nil, fmt.Errorf("unable to compress message with %s: %w", ps.ContentEncoding, err)
-		}
+			b = ps.encodeB64Data(b)
 
-		msg := &pubsub.Message{Data: b}
-		if ps.Attributes != nil {
-			msg.Attributes = ps.Attributes
+			b, err = ps.compressData(b)
+			if err != nil {
+				return nil, fmt.Errorf("unable to compress message with %s: %w", ps.ContentEncoding, err)
+			}
+
+			msg := &pubsub.Message{Data: b, OrderingKey: key}
+			if attributes != nil {
+				msg.Attributes = attributes
+			}
+			msgs = append(msgs, msg)
 		}
-		return []*pubsub.Message{msg}, nil
+		return msgs, nil
 	}
 
 	msgs := make([]*pubsub.Message, 0, len(metrics))
@@ -194,10 +227,11 @@ func (ps *PubSub) toMessages(metrics []telegraf.Metric) ([]*pubsub.Message, erro
 		}
 
 		msg := &pubsub.Message{
-			Data: b,
+			Data:        b,
+			OrderingKey: ps.orderingKey(m),
 		}
-		if ps.Attributes != nil {
-			msg.Attributes = ps.Attributes
+		if attributes != nil {
+			msg.Attributes = attributes
 		}
 		msgs = append(msgs, msg)
 	}
@@ -205,6 +239,29 @@ func (ps *PubSub) toMessages(metrics []telegraf.Metric) ([]*pubsub.Message, erro
 	return msgs, nil
 }
 
+// messageAttributes returns the configured attributes including the content
+// encoding of the message data if requested
+func (ps *PubSub) messageAttributes() map[string]string {
+	if ps.ContentEncodingAttribute == "" || ps.ContentEncoding == "identity" {
+		return ps.Attributes
+	}
+
+	attributes := make(map[string]string, len(ps.Attributes)+1)
+	for k, v := range ps.Attributes {
+		attributes[k] = v
+	}
+	attributes[ps.ContentEncodingAttribute] = ps.ContentEncoding
+	return attributes
+}
+
+func (ps *PubSub) orderingKey(m telegraf.Metric) string {
+	if ps.OrderingKeyTag == "" {
+		return ""
+	}
+	key, _ := m.GetTag(ps.OrderingKeyTag)
+	return key
+}
+
 func (ps *PubSub) encodeB64Data(data []byte) []byte {
 	if ps.Base64Data {
 		encoded := base64.StdEncoding.EncodeToString(data)
@@ -231,25 +288,30 @@ func (ps *PubSub) compressData(data []byte) ([]byte, error) {
 	return data, nil
 }
 
-func (ps *PubSub) waitForResults(ctx context.Context, cancel context.CancelFunc) error {
+func (ps *PubSub) waitForResults(ctx context.Context, cancel context.CancelFunc, msgs []*pubsub.Message) error {
 	var pErr error
 	var setErr sync.Once
 	var wg sync.WaitGroup
 
-	for _, pr := range ps.publishResults {
+	for i, pr := range ps.publishResults {
 		wg.Add(1)
 
-		go func(r publishResult) {
+		go func(r publishResult, key string) {
 			defer wg.Done()
 			// Wait on each future
 			_, err := r.Get(ctx)
 			if err != nil {
+				// The client pauses publishing for an ordering key after a
+				// failure, so resume to be able to send the metrics again
+				if key != "" {
+					ps.t.ResumePublish(key)
+				}
 				setErr.Do(func() {
 					pErr = err
 					cancel()
 				})
 			}
-		}(pr)
+		}(pr, msgs[i].OrderingKey)
 	}
 
 	wg.Wait()
@@ -278,6 +340,21 @@ func (ps *PubSub) Init() error {
 		return fmt.Errorf("invalid value %q for content_encoding", ps.ContentEncoding)
 	}
 
+	switch ps.FlowControlBehavior {
+	case "":
+		if ps.FlowControlMaxMessages > 0 || ps.FlowControlMaxBytes > 0 {
+			ps.limitBehavior = pubsub.FlowControlBlock
+		}
+	case "ignore":
+		ps.limitBehavior = pubsub.FlowControlIgnore
+	case "block":
+		ps.limitBehavior = pubsub.FlowControlBlock
+	case "signal_error":
+		ps.limitBehavior = pubsub.FlowControlSignalError
+	default:
+		return fmt.Errorf("invalid value %q for publish_flow_control_behavior", ps.FlowControlBehavior)
+	}
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package cloud_pubsub
 import (
 	"encoding/base64"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/pubsub"
 	"github.com/stretchr/testify/require"
@@ -189,6 +190,121 @@ func TestPubSub_WriteGzipAndBase64Single(t *testing.T) {
 	}
 }
 
+func TestPubSub_WriteOrderingKey(t *testing.T) {
+	testMetrics := []testMetric{
+		{testutil.MustMetric("test", map[string]string{"host": "a"}, map[string]interface{}{"value": "value_1"}, time.Unix(0, 0)), false},
+		{testutil.MustMetric("test", map[string]string{"host": "b"}, map[string]interface{}{"value": "value_2"}, time.Unix(0, 0)), false},
+		{testutil.MustMetric("test", map[string]string{}, map[string]interface{}{"value": "value_3"}, time.Unix(0, 0)), false},
+	}
+
+	settings := pubsub.DefaultPublishSettings
+	ps, topic, metrics := getTestResources(t, settings, testMetrics)
+	ps.OrderingKeyTag = "host"
+
+	require.NoError(t, ps.Write(metrics))
+	require.True(t, topic.Ordering)
+
+	require.Equal(t, "a", verifyRawMetricPublished(t, testMetrics[0].m, topic.published).OrderingKey)
+	require.Equal(t, "b", verifyRawMetricPublished(t, testMetrics[1].m, topic.published).OrderingKey)
+	require.Empty(t, verifyRawMetricPublished(t, testMetrics[2].m, topic.published).OrderingKey)
+}
+
+func TestPubSub_WriteOrderingKeyResume(t *testing.T) {
+	testMetrics := []testMetric{
+		{testutil.MustMetric("test", map[string]string{"host": "a"}, map[string]interface{}{"value": "value_1"}, time.Unix(0, 0)), true},
+		{testutil.MustMetric("test", map[string]string{"host": "a"}, map[string]interface{}{"value": "value_2"}, time.Unix(0, 0)), false},
+	}
+
+	settings := pubsub.DefaultPublishSettings
+	ps, stub, metrics := getTestResources(t, settings, testMetrics)
+	ps.OrderingKeyTag = "host"
+
+	// Allow publishing to the same topic in multiple writes
+	ps.stubTopic = func(string) topic {
+		stub.pLock.Lock()
+		defer stub.pLock.Unlock()
+		stub.stopped = false
+		return stub
+	}
+
+	require.ErrorContains(t, ps.Write(metrics[:1]), errMockFail)
+
+	// The ordering key must be resumed after the failure to allow sending
+	// further messages with the same key
+	require.NoError(t, ps.Write(metrics[1:]))
+	require.Equal(t, "a", verifyRawMetricPublished(t, testMetrics[1].m, stub.published).OrderingKey)
+}
+
+func TestPubSub_WriteBatchedOrderingKey(t *testing.T) {
+	testMetrics := []testMetric{
+		{testutil.MustMetric("test", map[string]string{"host": "a"}, map[string]interface{}{"value": "value_1"}, time.Unix(0, 0)), false},
+		{testutil.MustMetric("test", map[string]string{"host": "b"}, map[string]interface{}{"value": "value_2"}, time.Unix(0, 0)), false},
+		{testutil.MustMetric("test", map[string]string{"host": "a"}, map[string]interface{}{"value": "value_3"}, time.Unix(0, 0)), false},
+	}
+
+	settings := pubsub.DefaultPublishSettings
+	ps, topic, metrics := getTestResources(t, settings, testMetrics)
+	ps.SendBatched = true
+	ps.OrderingKeyTag = "host"
+
+	require.NoError(t, ps.Write(metrics))
+
+	// Metrics with the same key are sent in one message in order
+	msgA := topic.published["value_1"]
+	require.NotNil(t, msgA)
+	require.Same(t, msgA, topic.published["value_3"])
+	require.Equal(t, "a", msgA.OrderingKey)
+	require.Equal(t, "test,host=a value=\"value_1\" 0\ntest,host=a value=\"value_3\" 0\n", string(msgA.Data))
+
+	msgB := topic.published["value_2"]
+	require.NotNil(t, msgB)
+	require.Equal(t, "b", msgB.OrderingKey)
+}
+
+func TestPubSub_WriteGzipAttribute(t *testing.T) {
+	testMetrics := []testMetric{
+		{testutil.TestMetric("value_1", "test"), false /*return error */},
+	}
+
+	settings := pubsub.DefaultPublishSettings
+	ps, topic, metrics := getTestResources(t, settings, testMetrics)
+	topic.ContentEncoding = "gzip"
+	ps.ContentEncoding = "gzip"
+	ps.ContentEncodingAttribute = "content_encoding"
+	ps.Attributes = map[string]string{"foo": "bar"}
+	var err error
+	ps.encoder, err = internal.NewContentEncoder(ps.ContentEncoding)
+	require.NoError(t, err)
+
+	require.NoError(t, ps.Write(metrics))
+
+	msg := verifyMetricPublished(t, testMetrics[0].m, topic.published, false /* base64encoded */, true /* Gzipencoded */)
+	require.Equal(t, map[string]string{"foo": "bar", "content_encoding": "gzip"}, msg.Attributes)
+	require.Equal(t, map[string]string{"foo": "bar"}, ps.Attributes)
+}
+
+func TestPubSub_FlowControl(t *testing.T) {
+	ps := &PubSub{
+		Project:                "test-project",
+		Topic:                  "test-topic",
+		FlowControlMaxMessages: 100,
+		FlowControlMaxBytes:    1024,
+	}
+	require.NoError(t, ps.Init())
+
+	settings := ps.publishSettings()
+	require.Equal(t, 100, settings.FlowControlSettings.MaxOutstandingMessages)
+	require.Equal(t, 1024, settings.FlowControlSettings.MaxOutstandingBytes)
+	require.Equal(t, pubsub.FlowControlBlock, settings.FlowControlSettings.LimitExceededBehavior)
+
+	ps.FlowControlBehavior = "signal_error"
+	require.NoError(t, ps.Init())
+	require.Equal(t, pubsub.FlowControlSignalError, ps.publishSettings().FlowControlSettings.LimitExceededBehavior)
+
+	ps.FlowControlBehavior = "drop"
+	require.ErrorContains(t, ps.Init(), "invalid value")
+}
+
 func verifyRawMetricPublished(t *testing.T, m telegraf.Metric, published map[string]*pubsub.Message) *pubsub.Message {
 	return verifyMetricPublished(t, m, published, false, false)
 }
@@ -12,6 +12,8 @@ type (
 		Publish(ctx context.Context, msg *pubsub.Message) publishResult
 		PublishSettings() pubsub.PublishSettings
 		SetPublishSettings(settings pubsub.PublishSettings)
+		SetEnableMessageOrdering(enabled bool)
+		ResumePublish(orderingKey string)
 	}
 
 	publishResult interface {
@@ -42,3 +44,11 @@ func (tw *topicWrapper) PublishSettings() pubsub.PublishSettings {
 func (tw *topicWrapper) SetPublishSettings(settings pubsub.PublishSettings) {
 	tw.topic.PublishSettings = settings
 }
+
+func (tw *topicWrapper) SetEnableMessageOrdering(enabled bool) {
+	tw.topic.EnableMessageOrdering = enabled
+}
+
+func (tw *topicWrapper) ResumePublish(orderingKey string) {
+	tw.topic.ResumePublish(orderingKey)
+}
@@ -22,7 +22,8 @@ import (
 )
 
 const (
-	errMockFail = "this is an error"
+	errMockFail   = "this is an error"
+	errMockPaused = "ordering key paused"
 )
 
 type (
@@ -51,11 +52,13 @@ type (
 		*testing.T
 		Base64Data      bool
 		ContentEncoding string
+		Ordering        bool
 
 		stopped bool
 		pLock   sync.Mutex
 
 		published map[string]*pubsub.Message
+		paused    map[string]bool
 
 		bundler     *bundler.Bundler
 		bLock       sync.Mutex
@@ -73,6 +76,7 @@ func getTestResources(tT *testing.T, settings pubsub.PublishSettings, testM []te
 		T:               tT,
 		ReturnErr:       make(map[string]bool),
 		published:       make(map[string]*pubsub.Message),
+		paused:          make(map[string]bool),
 		ContentEncoding: "identity",
 	}
 
@@ -121,6 +125,9 @@ func (t *stubTopic) Publish(ctx context.Context, msg *pubsub.Message) publishRes
 	if t.stopped || ctx.Err() != nil {
 		t.Fatalf("publish called after stop")
 	}
+	if !t.Ordering && msg.OrderingKey != "" {
+		t.Fatalf("ordering key set without message ordering enabled")
+	}
 
 	ids := t.parseIDs(msg)
 	r := &stubResult{
@@ -129,10 +136,19 @@ func (t *stubTopic) Publish(ctx context.Context, msg *pubsub.Message) publishRes
 		done:      make(chan struct{}, 1),
 	}
 
+	// Mimic the client failing all messages of a paused ordering key
+	if msg.OrderingKey != "" && t.paused[msg.OrderingKey] {
+		r.err <- errors.New(errMockPaused)
+		return r
+	}
+
 	for _, id := range ids {
 		_, ok := t.ReturnErr[id]
 		r.sendError = r.sendError || ok
 	}
+	if r.sendError && msg.OrderingKey != "" {
+		t.paused[msg.OrderingKey] = true
+	}
 
 	bundled := &bundledMsg{msg, r}
 	if err := t.bundler.Add(bundled, len(msg.Data)); err != nil {
@@ -150,6 +166,17 @@ func (t *stubTopic) SetPublishSettings(settings pubsub.PublishSettings) {
 	t.initBundler()
 }
 
+func (t *stubTopic) SetEnableMessageOrdering(enabled bool) {
+	t.Ordering = enabled
+}
+
+func (t *stubTopic) ResumePublish(orderingKey string) {
+	t.pLock.Lock()
+	defer t.pLock.Unlock()
+
+	delete(t.paused, orderingKey)
+}
+
 func (t *stubTopic) initBundler() *stubTopic {
 	t.bundler = bundler.NewBundler(&bundledMsg{}, t.sendBundle())
 	t.bundler.DelayThreshold = 10 * time.Second
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -15,7 +16,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/common/proxy"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -24,13 +27,17 @@ import (
 var sampleConfig string
 
 type Datadog struct {
-	Apikey      string          `toml:"apikey"`
-	Timeout     config.Duration `toml:"timeout"`
-	URL         string          `toml:"url"`
-	Compression string          `toml:"compression"`
-	Log         telegraf.Logger `toml:"-"`
+	Apikey        string          `toml:"apikey"`
+	Timeout       config.Duration `toml:"timeout"`
+	URL           string          `toml:"url"`
+	APIVersion    string          `toml:"api_version"`
+	PayloadFormat string          `toml:"payload_format"`
+	Compression   string          `toml:"compression"`
+	Distributions []string        `toml:"distributions"`
+	Log           telegraf.Logger `toml:"-"`
 
-	client *http.Client
+	client        *http.Client
+	distributions filter.Filter
 	proxy.HTTPProxy
 }
 
@@ -49,12 +56,79 @@ type Metric struct {
 
 type Point [2]float64
 
-const datadogAPI = "https://app.datadoghq.com/api/v1/series"
+const (
+	datadogAPI   = "https://app.datadoghq.com/api/v1/series"
+	datadogAPIv2 = "https://api.datadoghq.com/api/v2/series"
+
+	distributionPath = "/api/v1/distribution_points"
+)
+
+// Payload limits of the metrics API in bytes, see
+// https://docs.datadoghq.com/api/latest/metrics/#submit-metrics
+const (
+	maxCompressedSizeV1   = 3200000
+	maxUncompressedSizeV1 = 62914560
+	maxCompressedSizeV2   = 512000
+	maxUncompressedSizeV2 = 5242880
+)
+
+// endpoint describes how to encode and send a set of series to the API
+type endpoint struct {
+	url             string
+	contentType     string
+	apiKeyHeader    bool
+	maxCompressed   int
+	maxUncompressed int
+	encode          func(lo, hi int) ([]byte, error)
+}
 
 func (*Datadog) SampleConfig() string {
 	return sampleConfig
 }
 
+func (d *Datadog) Init() error {
+	if d.APIVersion == "" {
+		d.APIVersion = "v1"
+	}
+	if err := choice.Check(d.APIVersion, []string{"v1", "v2"}); err != nil {
+		return fmt.Errorf("invalid 'api_version': %w", err)
+	}
+
+	if d.PayloadFormat == "" {
+		d.PayloadFormat = "json"
+	}
+	if err := choice.Check(d.PayloadFormat, []string{"json", "protobuf"}); err != nil {
+		return fmt.Errorf("invalid 'payload_format': %w", err)
+	}
+	if d.PayloadFormat == "protobuf" && d.APIVersion != "v2" {
+		return errors.New("the protobuf payload format requires api_version v2")
+	}
+
+	d.Compression = strings.ToLower(d.Compression)
+	if d.Compression == "" {
+		d.Compression = "none"
+	}
+	if err := choice.Check(d.Compression, []string{"none", "zlib", "gzip"}); err != nil {
+		return fmt.Errorf("invalid 'compression': %w", err)
+	}
+
+	if d.URL == "" {
+		if d.APIVersion == "v2" {
+			d.URL = datadogAPIv2
+		} else {
+			d.URL = datadogAPI
+		}
+	}
+
+	var err error
+	d.distributions, err = filter.Compile(d.Distributions)
+	if err != nil {
+		return fmt.Errorf("creating distribution filter failed: %w", err)
+	}
+
+	return nil
+}
+
 func (d *Datadog) Connect() error {
 	if d.Apikey == "" {
 		return fmt.Errorf("apikey is a required field for datadog output")
@@ -75,9 +149,9 @@ func (d *Datadog) Connect() error {
 }
 
 func (d *Datadog) Write(metrics []telegraf.Metric) error {
-	ts := TimeSeries{}
-	tempSeries := []*Metric{}
-	metricCounter := 0
+	var series []*Metric
+	var distributions []*distributionSeries
+	distributionIndex := make(map[string]*distributionSeries)
 
 	for _, m := range metrics {
 		if dogMs, err := buildMetrics(m); err == nil {
@@ -97,6 +171,24 @@ func (d *Datadog) Write(metrics []telegraf.Metric) error {
 				} else {
 					dname = m.Name() + "." + fieldName
 				}
+
+				if d.distributions != nil && d.distributions.Match(dname) {
+					key := dname + "\n" + host + "\n" + strings.Join(metricTags, ",")
+					ds, found := distributionIndex[key]
+					if !found {
+						ds = &distributionSeries{
+							Metric: dname,
+							Host:   host,
+							Tags:   metricTags,
+							Type:   "distribution",
+						}
+						distributionIndex[key] = ds
+						distributions = append(distributions, ds)
+					}
+					ds.add(int64(dogM[0]), dogM[1])
+					continue
+				}
+
 				var tname string
 				switch m.Type() {
 				case telegraf.Counter:
@@ -114,53 +206,144 @@ func (d *Datadog) Write(metrics []telegraf.Metric) error {
 					Interval: 1,
 				}
 				metric.Points[0] = dogM
-				tempSeries = append(tempSeries, metric)
-				metricCounter++
+				series = append(series, metric)
 			}
 		} else {
 			d.Log.Infof("Unable to build Metric for %s due to error '%v', skipping", m.Name(), err)
 		}
 	}
 
-	if len(tempSeries) == 0 {
-		return nil
+	if len(series) > 0 {
+		if err := d.send(d.seriesEndpoint(series), 0, len(series)); err != nil {
+			return err
+		}
 	}
 
-	redactedAPIKey := "****************"
-	ts.Series = make([]*Metric, metricCounter)
-	copy(ts.Series, tempSeries[0:])
-	tsBytes, err := json.Marshal(ts)
+	if len(distributions) > 0 {
+		e, err := d.distributionEndpoint(distributions)
+		if err != nil {
+			return err
+		}
+		if err := d.send(e, 0, len(distributions)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Datadog) seriesEndpoint(series []*Metric) *endpoint {
+	if d.APIVersion != "v2" {
+		return &endpoint{
+			url:             d.authenticatedURL(),
+			contentType:     "application/json",
+			maxCompressed:   maxCompressedSizeV1,
+			maxUncompressed: maxUncompressedSizeV1,
+			encode: func(lo, hi int) ([]byte, error) {
+				tsBytes, err := json.Marshal(TimeSeries{Series: series[lo:hi]})
+				if err != nil {
+					return nil, fmt.Errorf("unable to marshal TimeSeries: %w", err)
+				}
+				return tsBytes, nil
+			},
+		}
+	}
+
+	e := &endpoint{
+		url:             d.URL,
+		apiKeyHeader:    true,
+		maxCompressed:   maxCompressedSizeV2,
+		maxUncompressed: maxUncompressedSizeV2,
+	}
+	if d.PayloadFormat == "protobuf" {
+		e.contentType = "application/x-protobuf"
+		e.encode = func(lo, hi int) ([]byte, error) {
+			return encodeProtobuf(series[lo:hi]), nil
+		}
+	} else {
+		e.contentType = "application/json"
+		e.encode = func(lo, hi int) ([]byte, error) {
+			return encodeJSONv2(series[lo:hi])
+		}
+	}
+	return e
+}
+
+func (d *Datadog) distributionEndpoint(distributions []*distributionSeries) (*endpoint, error) {
+	u, err := url.Parse(d.URL)
 	if err != nil {
-		return fmt.Errorf("unable to marshal TimeSeries: %w", err)
+		return nil, fmt.Errorf("parsing URL failed: %w", err)
 	}
+	u.Path = distributionPath
+	u.RawQuery = ""
 
-	var req *http.Request
-	c := strings.ToLower(d.Compression)
-	switch c {
-	case "zlib":
-		encoder, err := internal.NewContentEncoder(c)
+	return &endpoint{
+		url:             u.String(),
+		contentType:     "application/json",
+		apiKeyHeader:    true,
+		maxCompressed:   maxCompressedSizeV1,
+		maxUncompressed: maxUncompressedSizeV1,
+		encode: func(lo, hi int) ([]byte, error) {
+			buf, err := json.Marshal(distributionPayload{Series: distributions[lo:hi]})
+			if err != nil {
+				return nil, fmt.Errorf("unable to marshal distributions: %w", err)
+			}
+			return buf, nil
+		},
+	}, nil
+}
+
+// send encodes the series in range [lo, hi) and sends them to the endpoint.
+// Payloads exceeding the API limits are split into multiple requests.
+func (d *Datadog) send(e *endpoint, lo, hi int) error {
+	payload, err := e.encode(lo, hi)
+	if err != nil {
+		return err
+	}
+
+	body := payload
+	if d.Compression != "" && d.Compression != "none" {
+		encoder, err := internal.NewContentEncoder(d.Compression)
 		if err != nil {
 			return err
 		}
-		buf, err := encoder.Encode(tsBytes)
-		if err != nil {
+		if body, err = encoder.Encode(payload); err != nil {
 			return err
 		}
-		req, err = http.NewRequest("POST", d.authenticatedURL(), bytes.NewBuffer(buf))
-		if err != nil {
-			return err
+	}
+
+	if len(payload) > e.maxUncompressed || len(body) > e.maxCompressed {
+		if hi-lo > 1 {
+			middle := lo + (hi-lo)/2
+			if err := d.send(e, lo, middle); err != nil {
+				return err
+			}
+			return d.send(e, middle, hi)
 		}
-		req.Header.Set("Content-Encoding", "deflate")
-	case "none":
-		fallthrough
-	default:
-		req, err = http.NewRequest("POST", d.authenticatedURL(), bytes.NewBuffer(tsBytes))
+		d.Log.Errorf("Dropping series exceeding the payload limit with %d bytes", len(body))
+		return nil
 	}
 
+	return d.post(e, body)
+}
+
+func (d *Datadog) post(e *endpoint, body []byte) error {
+	redactedAPIKey := "****************"
+
+	req, err := http.NewRequest("POST", e.url, bytes.NewBuffer(body))
 	if err != nil {
 		return fmt.Errorf("unable to create http.Request, %s", strings.ReplaceAll(err.Error(), d.Apikey, redactedAPIKey))
 	}
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", e.contentType)
+	switch d.Compression {
+	case "zlib":
+		req.Header.Set("Content-Encoding", "deflate")
+	case "gzip":
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if e.apiKeyHeader {
+		req.Header.Set("DD-API-KEY", d.Apikey)
+	}
 
 	resp, err := d.client.Do(req)
 	if err != nil {
@@ -245,8 +428,9 @@ func (d *Datadog) Close() error {
 func init() {
 	outputs.Add("datadog", func() telegraf.Output {
 		return &Datadog{
-			URL:         datadogAPI,
-			Compression: "none",
+			APIVersion:    "v1",
+			PayloadFormat: "json",
+			Compression:   "none",
 		}
 	})
 }
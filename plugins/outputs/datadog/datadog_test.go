@@ -1,8 +1,10 @@
 package datadog
 
 import (
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +13,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
@@ -305,3 +308,218 @@ func TestInfIsSkipped(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Datadog
+		expected string
+	}{
+		{
+			name:     "invalid api version",
+			plugin:   &Datadog{APIVersion: "v3"},
+			expected: "invalid 'api_version'",
+		},
+		{
+			name:     "invalid payload format",
+			plugin:   &Datadog{PayloadFormat: "xml"},
+			expected: "invalid 'payload_format'",
+		},
+		{
+			name:     "protobuf with v1",
+			plugin:   &Datadog{APIVersion: "v1", PayloadFormat: "protobuf"},
+			expected: "requires api_version v2",
+		},
+		{
+			name:     "invalid compression",
+			plugin:   &Datadog{Compression: "lz4"},
+			expected: "invalid 'compression'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestDefaultURL(t *testing.T) {
+	d := &Datadog{}
+	require.NoError(t, d.Init())
+	require.Equal(t, datadogAPI, d.URL)
+
+	d = &Datadog{APIVersion: "v2"}
+	require.NoError(t, d.Init())
+	require.Equal(t, datadogAPIv2, d.URL)
+}
+
+func TestWriteV2(t *testing.T) {
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "server01", "cpu": "cpu0"},
+		map[string]interface{}{"usage_idle": 91.5},
+		time.Unix(1677628800, 0),
+		telegraf.Gauge,
+	)
+
+	var received []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v2/series" || r.Header.Get("DD-API-KEY") != fakeAPIKey {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var err error
+		received, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	d := &Datadog{
+		Apikey:     fakeAPIKey,
+		URL:        ts.URL + "/api/v2/series",
+		APIVersion: "v2",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, d.Init())
+	require.NoError(t, d.Connect())
+	require.NoError(t, d.Write([]telegraf.Metric{m}))
+
+	expected := `{"series":[{"metric":"cpu.usage_idle","type":3,"points":[{"timestamp":1677628800,"value":91.5}],` +
+		`"resources":[{"name":"server01","type":"host"}],"tags":["cpu:cpu0","host:server01"],"interval":1}]}`
+	require.JSONEq(t, expected, string(received))
+}
+
+func TestWriteV2Protobuf(t *testing.T) {
+	m := testutil.MustMetric(
+		"requests",
+		map[string]string{"host": "server01"},
+		map[string]interface{}{"value": int64(42)},
+		time.Unix(1677628800, 0),
+		telegraf.Counter,
+	)
+
+	var received []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		reader, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		received, err = io.ReadAll(reader)
+		require.NoError(t, err)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	d := &Datadog{
+		Apikey:        fakeAPIKey,
+		URL:           ts.URL,
+		APIVersion:    "v2",
+		PayloadFormat: "protobuf",
+		Compression:   "gzip",
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, d.Init())
+	require.NoError(t, d.Connect())
+	require.NoError(t, d.Write([]telegraf.Metric{m}))
+
+	// Decode the single MetricSeries message of the payload
+	num, typ, n := protowire.ConsumeTag(received)
+	require.Equal(t, protowire.Number(1), num)
+	require.Equal(t, protowire.BytesType, typ)
+	series, n2 := protowire.ConsumeBytes(received[n:])
+	require.Len(t, received, n+n2)
+
+	fields := make(map[protowire.Number]interface{})
+	for len(series) > 0 {
+		num, typ, n := protowire.ConsumeTag(series)
+		require.Greater(t, n, 0)
+		series = series[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(series)
+			fields[num] = string(v)
+			series = series[n:]
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(series)
+			fields[num] = v
+			series = series[n:]
+		default:
+			require.Failf(t, "unexpected type", "field %d", num)
+		}
+	}
+	require.Equal(t, "requests", fields[2])
+	require.Equal(t, "host:server01", fields[3])
+	require.Equal(t, uint64(typeCount), fields[5])
+	require.Equal(t, uint64(1), fields[8])
+}
+
+func TestWriteDistributions(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("latency", map[string]string{"host": "a"}, map[string]interface{}{"value": 1.5}, time.Unix(10, 0)),
+		testutil.MustMetric("latency", map[string]string{"host": "a"}, map[string]interface{}{"value": 2.5}, time.Unix(10, 0)),
+		testutil.MustMetric("latency", map[string]string{"host": "a"}, map[string]interface{}{"value": 3.5}, time.Unix(20, 0)),
+		testutil.MustMetric("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4.5}, time.Unix(10, 0)),
+	}
+
+	received := make(map[string]string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		received[r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	d := &Datadog{
+		Apikey:        fakeAPIKey,
+		URL:           ts.URL + "/api/v1/series",
+		Distributions: []string{"latency"},
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, d.Init())
+	require.NoError(t, d.Connect())
+	require.NoError(t, d.Write(metrics))
+
+	require.Len(t, received, 2)
+	require.JSONEq(t,
+		`{"series":[{"metric":"cpu","points":[[10,4.5]],"host":"a","tags":["host:a"],"interval":1}]}`,
+		received["/api/v1/series"],
+	)
+	require.JSONEq(t,
+		`{"series":[{"metric":"latency","points":[[10,[1.5,2.5]],[20,[3.5]]],"host":"a","tags":["host:a"],"type":"distribution"}]}`,
+		received["/api/v1/distribution_points"],
+	)
+}
+
+func TestPayloadSplitting(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer ts.Close()
+
+	d := &Datadog{
+		Apikey: fakeAPIKey,
+		URL:    ts.URL,
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, d.Init())
+	require.NoError(t, d.Connect())
+
+	// Each item encodes to ten bytes so at most two items fit into a request
+	e := &endpoint{
+		url:             ts.URL,
+		contentType:     "application/json",
+		maxCompressed:   25,
+		maxUncompressed: 25,
+		encode: func(lo, hi int) ([]byte, error) {
+			return make([]byte, 10*(hi-lo)), nil
+		},
+	}
+	require.NoError(t, d.send(e, 0, 7))
+	require.Equal(t, 4, requests)
+}
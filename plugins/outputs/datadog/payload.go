@@ -0,0 +1,147 @@
+package datadog
+
+import (
+	"encoding/json"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Metric types of the series v2 API
+const (
+	typeUnspecified int32 = 0
+	typeCount       int32 = 1
+	typeGauge       int32 = 3
+)
+
+type timeSeriesV2 struct {
+	Series []*seriesV2 `json:"series"`
+}
+
+type seriesV2 struct {
+	Metric    string       `json:"metric"`
+	Type      int32        `json:"type"`
+	Points    []pointV2    `json:"points"`
+	Resources []resourceV2 `json:"resources,omitempty"`
+	Tags      []string     `json:"tags,omitempty"`
+	Interval  int64        `json:"interval,omitempty"`
+}
+
+type pointV2 struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+}
+
+type resourceV2 struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func metricType(name string) int32 {
+	switch name {
+	case "count":
+		return typeCount
+	case "gauge":
+		return typeGauge
+	}
+	return typeUnspecified
+}
+
+func encodeJSONv2(series []*Metric) ([]byte, error) {
+	ts := timeSeriesV2{Series: make([]*seriesV2, 0, len(series))}
+	for _, m := range series {
+		s := &seriesV2{
+			Metric: m.Metric,
+			Type:   metricType(m.Type),
+			Points: []pointV2{{
+				Timestamp: int64(m.Points[0][0]),
+				Value:     m.Points[0][1],
+			}},
+			Tags:     m.Tags,
+			Interval: m.Interval,
+		}
+		if m.Host != "" {
+			s.Resources = []resourceV2{{Name: m.Host, Type: "host"}}
+		}
+		ts.Series = append(ts.Series, s)
+	}
+	return json.Marshal(ts)
+}
+
+// encodeProtobuf encodes the series as MetricPayload message of the series
+// v2 API, see https://github.com/DataDog/agent-payload/blob/master/proto/metrics/agent_payload.proto
+func encodeProtobuf(series []*Metric) []byte {
+	var payload []byte
+	for _, m := range series {
+		var s []byte
+		if m.Host != "" {
+			var resource []byte
+			resource = protowire.AppendTag(resource, 1, protowire.BytesType)
+			resource = protowire.AppendString(resource, "host")
+			resource = protowire.AppendTag(resource, 2, protowire.BytesType)
+			resource = protowire.AppendString(resource, m.Host)
+			s = protowire.AppendTag(s, 1, protowire.BytesType)
+			s = protowire.AppendBytes(s, resource)
+		}
+		s = protowire.AppendTag(s, 2, protowire.BytesType)
+		s = protowire.AppendString(s, m.Metric)
+		for _, tag := range m.Tags {
+			s = protowire.AppendTag(s, 3, protowire.BytesType)
+			s = protowire.AppendString(s, tag)
+		}
+
+		var point []byte
+		point = protowire.AppendTag(point, 1, protowire.Fixed64Type)
+		point = protowire.AppendFixed64(point, math.Float64bits(m.Points[0][1]))
+		point = protowire.AppendTag(point, 2, protowire.VarintType)
+		point = protowire.AppendVarint(point, uint64(int64(m.Points[0][0])))
+		s = protowire.AppendTag(s, 4, protowire.BytesType)
+		s = protowire.AppendBytes(s, point)
+
+		if t := metricType(m.Type); t != typeUnspecified {
+			s = protowire.AppendTag(s, 5, protowire.VarintType)
+			s = protowire.AppendVarint(s, uint64(t))
+		}
+		if m.Interval != 0 {
+			s = protowire.AppendTag(s, 8, protowire.VarintType)
+			s = protowire.AppendVarint(s, uint64(m.Interval))
+		}
+
+		payload = protowire.AppendTag(payload, 1, protowire.BytesType)
+		payload = protowire.AppendBytes(payload, s)
+	}
+	return payload
+}
+
+type distributionPayload struct {
+	Series []*distributionSeries `json:"series"`
+}
+
+// distributionSeries collects the raw values of a metric for the
+// distribution points API
+type distributionSeries struct {
+	Metric string              `json:"metric"`
+	Points []distributionPoint `json:"points"`
+	Host   string              `json:"host,omitempty"`
+	Tags   []string            `json:"tags,omitempty"`
+	Type   string              `json:"type"`
+}
+
+type distributionPoint struct {
+	timestamp int64
+	values    []float64
+}
+
+func (p distributionPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{p.timestamp, p.values})
+}
+
+func (s *distributionSeries) add(timestamp int64, value float64) {
+	for i := range s.Points {
+		if s.Points[i].timestamp == timestamp {
+			s.Points[i].values = append(s.Points[i].values, value)
+			return
+		}
+	}
+	s.Points = append(s.Points, distributionPoint{timestamp: timestamp, values: []float64{value}})
+}
@@ -0,0 +1,325 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package dogstatsd
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Default maximum packet sizes as used by the DogStatsD clients
+const (
+	defaultUDPPacketSize = 1432
+	defaultUDSPacketSize = 8192
+)
+
+var (
+	nameReplacer = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", " ", "_", "\n", "_")
+	tagReplacer  = strings.NewReplacer("|", "_", ",", "_", "#", "_", "\n", "_")
+)
+
+type DogStatsD struct {
+	Address        string          `toml:"address"`
+	Prefix         string          `toml:"prefix"`
+	Separator      string          `toml:"metric_separator"`
+	CounterType    string          `toml:"counter_type"`
+	SendTimestamps bool            `toml:"send_timestamps"`
+	MaxPacketSize  int             `toml:"max_packet_size"`
+	Telemetry      bool            `toml:"telemetry"`
+	ConstantTags   []string        `toml:"constant_tags"`
+	WriteTimeout   config.Duration `toml:"write_timeout"`
+	Log            telegraf.Logger `toml:"-"`
+
+	network  string
+	address  string
+	conn     net.Conn
+	previous map[uint64]map[string]float64
+
+	// Telemetry counters since the last report
+	sentMetrics    int
+	sentPackets    int
+	sentBytes      int
+	droppedMetrics int
+}
+
+func (*DogStatsD) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *DogStatsD) Init() error {
+	network, address, found := strings.Cut(d.Address, "://")
+	if !found || address == "" {
+		return fmt.Errorf("invalid address %q", d.Address)
+	}
+	switch network {
+	case "udp", "udp4", "udp6":
+		if d.MaxPacketSize == 0 {
+			d.MaxPacketSize = defaultUDPPacketSize
+		}
+	case "unix", "unixgram":
+		network = "unixgram"
+		if d.MaxPacketSize == 0 {
+			d.MaxPacketSize = defaultUDSPacketSize
+		}
+	default:
+		return fmt.Errorf("unsupported network %q, use udp or unix", network)
+	}
+	d.network = network
+	d.address = address
+
+	switch d.CounterType {
+	case "":
+		d.CounterType = "gauge"
+	case "gauge", "count":
+	default:
+		return fmt.Errorf("invalid counter_type %q", d.CounterType)
+	}
+
+	if d.Separator == "" {
+		d.Separator = "."
+	}
+	d.previous = make(map[uint64]map[string]float64)
+
+	return nil
+}
+
+func (d *DogStatsD) Connect() error {
+	conn, err := net.Dial(d.network, d.address)
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", d.Address, err)
+	}
+	d.conn = conn
+	return nil
+}
+
+func (d *DogStatsD) Close() error {
+	if d.conn == nil {
+		return nil
+	}
+	err := d.conn.Close()
+	d.conn = nil
+	return err
+}
+
+func (d *DogStatsD) Write(metrics []telegraf.Metric) error {
+	if d.conn == nil {
+		if err := d.Connect(); err != nil {
+			return err
+		}
+	}
+
+	var datagrams [][]byte
+	for _, m := range metrics {
+		datagrams = append(datagrams, d.convert(m)...)
+	}
+	if d.Telemetry {
+		// Account for the telemetry packets in the report itself
+		d.sentMetrics += len(datagrams)
+		datagrams = append(datagrams, d.telemetry()...)
+	}
+
+	packets := pack(datagrams, d.MaxPacketSize)
+	for i, packet := range packets {
+		if d.WriteTimeout > 0 {
+			if err := d.conn.SetWriteDeadline(time.Now().Add(time.Duration(d.WriteTimeout))); err != nil {
+				return err
+			}
+		}
+		if _, err := d.conn.Write(packet); err != nil {
+			d.Close()
+			if i > 0 {
+				d.Log.Warnf("Sent %d of %d packets", i, len(packets))
+			}
+			return fmt.Errorf("writing to %q failed: %w", d.Address, err)
+		}
+		d.sentPackets++
+		d.sentBytes += len(packet)
+	}
+
+	return nil
+}
+
+// convert creates the datagrams for all numeric fields of the metric
+func (d *DogStatsD) convert(m telegraf.Metric) [][]byte {
+	tags := d.tags(m)
+
+	var previous map[string]float64
+	if m.Type() == telegraf.Counter && d.CounterType == "count" {
+		id := m.HashID()
+		previous = d.previous[id]
+		if previous == nil {
+			previous = make(map[string]float64)
+			d.previous[id] = previous
+		}
+	}
+
+	datagrams := make([][]byte, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		value, ok := toFloat(field.Value)
+		if !ok {
+			d.Log.Debugf("Skipping field %q of metric %q with unsupported type %T", field.Key, m.Name(), field.Value)
+			d.droppedMetrics++
+			continue
+		}
+
+		metricType := "g"
+		switch m.Type() {
+		case telegraf.Counter:
+			if previous != nil {
+				// Send the increase since the last value, the first value
+				// only serves as the reference.
+				last, found := previous[field.Key]
+				previous[field.Key] = value
+				if !found || value < last {
+					continue
+				}
+				value -= last
+				metricType = "c"
+			}
+		case telegraf.Histogram, telegraf.Summary:
+			// The fields of aggregated metrics are already computed
+			// statistics, so send them as gauges.
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString(d.metricName(m.Name(), field.Key))
+		buf.WriteByte(':')
+		buf.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+		buf.WriteByte('|')
+		buf.WriteString(metricType)
+		if tags != "" {
+			buf.WriteString("|#")
+			buf.WriteString(tags)
+		}
+		if d.SendTimestamps {
+			buf.WriteString("|T")
+			buf.WriteString(strconv.FormatInt(m.Time().Unix(), 10))
+		}
+		datagrams = append(datagrams, buf.Bytes())
+	}
+	return datagrams
+}
+
+func (d *DogStatsD) metricName(name, field string) string {
+	if field != "value" {
+		name += d.Separator + field
+	}
+	if d.Prefix != "" {
+		name = d.Prefix + d.Separator + name
+	}
+	return nameReplacer.Replace(name)
+}
+
+func (d *DogStatsD) tags(m telegraf.Metric) string {
+	tags := make([]string, 0, len(d.ConstantTags)+len(m.TagList()))
+	tags = append(tags, d.ConstantTags...)
+	for _, tag := range m.TagList() {
+		tags = append(tags, tagReplacer.Replace(tag.Key)+":"+tagReplacer.Replace(tag.Value))
+	}
+	return strings.Join(tags, ",")
+}
+
+// telemetry returns the datagrams reporting the client statistics since the
+// last report and resets the counters
+func (d *DogStatsD) telemetry() [][]byte {
+	transport := "udp"
+	if d.network == "unixgram" {
+		transport = "uds"
+	}
+	tags := "client:telegraf,client_transport:" + transport
+	if len(d.ConstantTags) > 0 {
+		tags += "," + strings.Join(d.ConstantTags, ",")
+	}
+
+	counters := map[string]int{
+		"metrics":         d.sentMetrics,
+		"metrics_dropped": d.droppedMetrics,
+		"packets_sent":    d.sentPackets,
+		"bytes_sent":      d.sentBytes,
+	}
+	names := make([]string, 0, len(counters))
+	for name := range counters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	datagrams := make([][]byte, 0, len(names))
+	for _, name := range names {
+		datagram := fmt.Sprintf("datadog.dogstatsd.client.%s:%d|c|#%s", name, counters[name], tags)
+		datagrams = append(datagrams, []byte(datagram))
+	}
+
+	d.sentMetrics = 0
+	d.droppedMetrics = 0
+	d.sentPackets = 0
+	d.sentBytes = 0
+
+	return datagrams
+}
+
+// pack combines the datagrams, separated by newlines, into packets not
+// exceeding the given size. Datagrams larger than the size are sent alone.
+func pack(datagrams [][]byte, size int) [][]byte {
+	var packets [][]byte
+	var current []byte
+	for _, datagram := range datagrams {
+		if len(current) > 0 && len(current)+1+len(datagram) > size {
+			packets = append(packets, current)
+			current = nil
+		}
+		if len(current) > 0 {
+			current = append(current, '\n')
+		}
+		current = append(current, datagram...)
+	}
+	if len(current) > 0 {
+		packets = append(packets, current)
+	}
+	return packets
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	var v float64
+	switch x := value.(type) {
+	case float64:
+		v = x
+	case int64:
+		v = float64(x)
+	case uint64:
+		v = float64(x)
+	case bool:
+		if x {
+			v = 1
+		}
+	default:
+		return 0, false
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, false
+	}
+	return v, true
+}
+
+func init() {
+	outputs.Add("dogstatsd", func() telegraf.Output {
+		return &DogStatsD{
+			Address:      "udp://localhost:8125",
+			Separator:    ".",
+			CounterType:  "gauge",
+			WriteTimeout: config.Duration(time.Second),
+		}
+	})
+}
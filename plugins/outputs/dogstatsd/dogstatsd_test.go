@@ -0,0 +1,223 @@
+package dogstatsd
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func receive(t *testing.T, conn net.PacketConn, n int) []string {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	packets := make([]string, 0, n)
+	buf := make([]byte, 65536)
+	for i := 0; i < n; i++ {
+		count, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err, "received %d of %d packets", len(packets), n)
+		packets = append(packets, string(buf[:count]))
+	}
+	return packets
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &DogStatsD{Address: "localhost:8125"}
+	require.ErrorContains(t, plugin.Init(), "invalid address")
+
+	plugin = &DogStatsD{Address: "tcp://localhost:8125"}
+	require.ErrorContains(t, plugin.Init(), "unsupported network")
+
+	plugin = &DogStatsD{Address: "udp://localhost:8125", CounterType: "rate"}
+	require.ErrorContains(t, plugin.Init(), "invalid counter_type")
+}
+
+func TestInitDefaults(t *testing.T) {
+	plugin := &DogStatsD{Address: "udp://localhost:8125"}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, defaultUDPPacketSize, plugin.MaxPacketSize)
+	require.Equal(t, "gauge", plugin.CounterType)
+
+	plugin = &DogStatsD{Address: "unix:///tmp/dsd.socket"}
+	require.NoError(t, plugin.Init())
+	require.Equal(t, "unixgram", plugin.network)
+	require.Equal(t, "/tmp/dsd.socket", plugin.address)
+	require.Equal(t, defaultUDSPacketSize, plugin.MaxPacketSize)
+}
+
+func TestWriteUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	plugin := &DogStatsD{
+		Address:        "udp://" + conn.LocalAddr().String(),
+		Prefix:         "telegraf",
+		ConstantTags:   []string{"env:test"},
+		SendTimestamps: true,
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu|0"},
+			map[string]interface{}{
+				"usage_idle": 98.5,
+				"count":      uint64(3),
+				"online":     true,
+				"state":      "ok",
+			},
+			time.Unix(1700000000, 0),
+		),
+		metric.New(
+			"mem:used",
+			map[string]string{},
+			map[string]interface{}{"value": int64(42)},
+			time.Unix(1700000000, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// All datagrams fit into a single packet
+	expected := []string{
+		"telegraf.cpu.count:3|g|#env:test,cpu:cpu_0,host:a|T1700000000",
+		"telegraf.cpu.online:1|g|#env:test,cpu:cpu_0,host:a|T1700000000",
+		"telegraf.cpu.usage_idle:98.5|g|#env:test,cpu:cpu_0,host:a|T1700000000",
+		"telegraf.mem_used:42|g|#env:test|T1700000000",
+	}
+	packets := receive(t, conn, 1)
+	require.ElementsMatch(t, expected, strings.Split(packets[0], "\n"))
+}
+
+func TestWriteUDS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dsd.socket")
+	conn, err := net.ListenPacket("unixgram", path)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	plugin := &DogStatsD{
+		Address: "unix://" + path,
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"disk",
+			map[string]string{"path": "/"},
+			map[string]interface{}{"free": int64(100)},
+			time.Unix(0, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, []string{"disk.free:100|g|#path:/"}, receive(t, conn, 1))
+}
+
+func TestPacking(t *testing.T) {
+	datagrams := [][]byte{
+		[]byte("a:1|g"),
+		[]byte("b:2|g"),
+		[]byte("c:3|g"),
+		[]byte("a_very_long_name:4|g"),
+	}
+	expected := [][]byte{
+		[]byte("a:1|g\nb:2|g"),
+		[]byte("c:3|g"),
+		[]byte("a_very_long_name:4|g"),
+	}
+	require.Equal(t, expected, pack(datagrams, 12))
+	require.Empty(t, pack(nil, 12))
+}
+
+func TestCounterTypeCount(t *testing.T) {
+	plugin := &DogStatsD{
+		Address:     "udp://localhost:8125",
+		CounterType: "count",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	counter := func(v int64) telegraf.Metric {
+		return metric.New(
+			"requests",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"total": v},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		)
+	}
+
+	// The first value is kept as reference only
+	require.Empty(t, plugin.convert(counter(10)))
+	require.Equal(t, [][]byte{[]byte("requests.total:5|c|#host:a")}, plugin.convert(counter(15)))
+
+	// Counter resets are not sent
+	require.Empty(t, plugin.convert(counter(3)))
+	require.Equal(t, [][]byte{[]byte("requests.total:4|c|#host:a")}, plugin.convert(counter(7)))
+
+	// Other metric types are still sent as gauges
+	gauge := metric.New(
+		"requests",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"total": int64(7)},
+		time.Unix(0, 0),
+	)
+	require.Equal(t, [][]byte{[]byte("requests.total:7|g|#host:a")}, plugin.convert(gauge))
+}
+
+func TestTelemetry(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	plugin := &DogStatsD{
+		Address:       "udp://" + conn.LocalAddr().String(),
+		MaxPacketSize: 8192,
+		Telemetry:     true,
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"value": 1.0, "name": "skipped"},
+			time.Unix(0, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.NoError(t, plugin.Write(metrics))
+
+	packets := receive(t, conn, 2)
+	tags := "|c|#client:telegraf,client_transport:udp"
+	first := strings.Join([]string{
+		"test:1|g",
+		"datadog.dogstatsd.client.bytes_sent:0" + tags,
+		"datadog.dogstatsd.client.metrics:1" + tags,
+		"datadog.dogstatsd.client.metrics_dropped:1" + tags,
+		"datadog.dogstatsd.client.packets_sent:0" + tags,
+	}, "\n")
+	require.Equal(t, first, packets[0])
+
+	// The second report contains the packet statistics of the first write
+	require.Contains(t, packets[1], "datadog.dogstatsd.client.packets_sent:1"+tags)
+	require.Contains(t, packets[1], "datadog.dogstatsd.client.bytes_sent:"+
+		strconv.Itoa(len(first))+tags)
+}
@@ -27,7 +27,11 @@ import (
 var sampleConfig string
 
 type Elasticsearch struct {
+	APIKey              config.Secret   `toml:"api_key"`
 	AuthBearerToken     config.Secret   `toml:"auth_bearer_token"`
+	BulkRetryMax        int             `toml:"bulk_retry_max"`
+	BulkRetryBackoff    config.Duration `toml:"bulk_retry_backoff"`
+	DataStream          bool            `toml:"data_stream"`
 	DefaultPipeline     string          `toml:"default_pipeline"`
 	DefaultTagValue     string          `toml:"default_tag_value"`
 	EnableGzip          bool            `toml:"enable_gzip"`
@@ -43,6 +47,7 @@ type Elasticsearch struct {
 	Username            config.Secret   `toml:"username"`
 	Password            config.Secret   `toml:"password"`
 	TemplateName        string          `toml:"template_name"`
+	TemplateFormat      string          `toml:"template_format"`
 	Timeout             config.Duration `toml:"timeout"`
 	URLs                []string        `toml:"urls"`
 	UsePipeline         string          `toml:"use_pipeline"`
@@ -53,6 +58,10 @@ type Elasticsearch struct {
 	tagKeys             []string
 	tls.ClientConfig
 
+	// Context for aborting the retries of a write when closing the output
+	ctx    context.Context
+	cancel context.CancelFunc
+
 	Client *elastic.Client
 }
 
@@ -126,9 +135,99 @@ const telegrafTemplate = `
 	}
 }`
 
+// Mappings for composable templates, the field layout follows the Elastic
+// Common Schema (ECS) conventions of the built-in metrics templates.
+const telegrafComponentMappings = `
+{
+	"template": {
+		"mappings": {
+			"properties": {
+				"@timestamp": { "type": "date" },
+				"measurement_name": { "type": "keyword" }
+				{{ if .DataStream }},
+				"data_stream": {
+					"properties": {
+						"type": { "type": "constant_keyword" },
+						"dataset": { "type": "constant_keyword" },
+						"namespace": { "type": "constant_keyword" }
+					}
+				}
+				{{ end }}
+			},
+			"dynamic_templates": [
+				{
+					"tags": {
+						"match_mapping_type": "string",
+						"path_match": "tag.*",
+						"mapping": {
+							"ignore_above": 1024,
+							"type": "keyword"
+						}
+					}
+				},
+				{
+					"metrics_long": {
+						"match_mapping_type": "long",
+						"mapping": {
+							"type": "float",
+							"index": false
+						}
+					}
+				},
+				{
+					"metrics_double": {
+						"match_mapping_type": "double",
+						"mapping": {
+							"type": "float",
+							"index": false
+						}
+					}
+				},
+				{
+					"text_fields": {
+						"match": "*",
+						"mapping": {
+							"norms": false
+						}
+					}
+				}
+			]
+		}
+	}
+}`
+
+const telegrafComponentSettings = `
+{
+	"template": {
+		"settings": {
+			"index": {
+				"refresh_interval": "10s",
+				"mapping.total_fields.limit": 5000,
+				"auto_expand_replicas" : "0-1",
+				"codec" : "best_compression"
+			}
+		}
+	}
+}`
+
+const telegrafIndexTemplate = `
+{
+	"index_patterns": [ "{{.TemplatePattern}}" ],
+	{{ if .DataStream }}
+	"data_stream": {},
+	{{ end }}
+	"composed_of": [ "{{.Name}}-settings", "{{.Name}}-mappings" ],
+	"priority": 200,
+	"_meta": {
+		"managed_by": "telegraf"
+	}
+}`
+
 type templatePart struct {
 	TemplatePattern string
 	Version         int
+	Name            string
+	DataStream      bool
 }
 
 func (*Elasticsearch) SampleConfig() string {
@@ -149,6 +248,23 @@ func (a *Elasticsearch) Connect() error {
 		return fmt.Errorf("invalid float_handling type %q", a.FloatHandling)
 	}
 
+	switch a.TemplateFormat {
+	case "":
+		a.TemplateFormat = "legacy"
+		if a.DataStream {
+			a.TemplateFormat = "composable"
+		}
+	case "legacy", "composable":
+	default:
+		return fmt.Errorf("invalid template_format %q", a.TemplateFormat)
+	}
+	if a.DataStream && a.TemplateFormat != "composable" {
+		return fmt.Errorf("data streams require composable templates")
+	}
+	if a.DataStream && strings.Contains(a.IndexName, "%") {
+		return fmt.Errorf("date specifiers are not supported in index_name for data streams")
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
 	defer cancel()
 
@@ -216,8 +332,16 @@ func (a *Elasticsearch) Connect() error {
 
 	a.Log.Infof("Elasticsearch version: %q", esVersion)
 
+	if a.TemplateFormat == "composable" && !versionAtLeast(esVersion, 7, 8) {
+		return fmt.Errorf("composable templates require Elasticsearch 7.8 or later, found %s", esVersion)
+	}
+	if a.DataStream && !versionAtLeast(esVersion, 7, 9) {
+		return fmt.Errorf("data streams require Elasticsearch 7.9 or later, found %s", esVersion)
+	}
+
 	a.Client = client
 	a.majorReleaseNumber = majorReleaseNumber
+	a.ctx, a.cancel = context.WithCancel(context.Background())
 
 	if a.ManageTemplate {
 		err := a.manageTemplate(ctx)
@@ -249,8 +373,7 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
-	bulkRequest := a.Client.Bulk()
-
+	requests := make([]elastic.BulkableRequest, 0, len(metrics))
 	for _, metric := range metrics {
 		var name = metric.Name()
 
@@ -284,8 +407,25 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 		m["tag"] = metric.Tags()
 		m[name] = fields
 
+		// Use the common schema fields for the message and level of events
+		if metric.Type() == telegraf.Event {
+			if message, ok := fields[telegraf.EventMessageField]; ok {
+				m["message"] = message
+				delete(fields, telegraf.EventMessageField)
+			}
+			if severity, ok := fields[telegraf.EventSeverityField]; ok {
+				m["log"] = map[string]interface{}{"level": severity}
+				delete(fields, telegraf.EventSeverityField)
+			}
+		}
+
 		br := elastic.NewBulkIndexRequest().Index(indexName).Doc(m)
 
+		// Data streams are append-only and only accept the create operation
+		if a.DataStream {
+			br.OpType("create")
+		}
+
 		if a.ForceDocumentID {
 			id := GetPointID(metric)
 			br.Id(id)
@@ -301,33 +441,71 @@ func (a *Elasticsearch) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
-		bulkRequest.Add(br)
+		requests = append(requests, br)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(a.Timeout))
-	defer cancel()
+	return a.writeBulk(requests)
+}
 
-	res, err := bulkRequest.Do(ctx)
+// writeBulk sends the requests and resends only the items failing
+// temporarily, i.e. rejected due to overload (HTTP 429) or by a server error,
+// with an exponential backoff. Items failing permanently, e.g. due to mapping
+// errors, are logged and dropped as resending them would fail again.
+func (a *Elasticsearch) writeBulk(requests []elastic.BulkableRequest) error {
+	backoff := time.Duration(a.BulkRetryBackoff)
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(a.ctx, time.Duration(a.Timeout))
+		res, err := a.Client.Bulk().Add(requests...).Do(ctx)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("error sending bulk request to Elasticsearch: %w", err)
+		}
 
-	if err != nil {
-		return fmt.Errorf("error sending bulk request to Elasticsearch: %w", err)
-	}
+		if !res.Errors {
+			return nil
+		}
 
-	if res.Errors {
-		for id, err := range res.Failed() {
-			a.Log.Errorf(
-				"Elasticsearch indexing failure, id: %d, error: %s, caused by: %s, %s",
-				id,
-				err.Error.Reason,
-				err.Error.CausedBy["reason"],
-				err.Error.CausedBy["type"],
-			)
-			break
+		var retry []elastic.BulkableRequest
+		var dropped []*elastic.BulkResponseItem
+		for i, item := range res.Items {
+			for _, r := range item {
+				switch {
+				case r.Status >= 200 && r.Status < 300:
+				case r.Status == http.StatusConflict && a.DataStream && a.ForceDocumentID:
+					// The document was already written in a previous attempt
+				case (r.Status == http.StatusTooManyRequests || r.Status >= 500) && i < len(requests):
+					retry = append(retry, requests[i])
+				default:
+					dropped = append(dropped, r)
+				}
+			}
 		}
-		return fmt.Errorf("elasticsearch failed to index %d metrics", len(res.Failed()))
-	}
 
-	return nil
+		if len(dropped) > 0 {
+			msg := fmt.Sprintf("Elasticsearch failed to index %d metrics, dropping them; first failure: id %s, status %d",
+				len(dropped), dropped[0].Id, dropped[0].Status)
+			if err := dropped[0].Error; err != nil {
+				msg += fmt.Sprintf(", error: %s, caused by: %s, %s", err.Reason, err.CausedBy["reason"], err.CausedBy["type"])
+			}
+			a.Log.Error(msg)
+		}
+
+		if len(retry) == 0 {
+			return nil
+		}
+		if attempt >= a.BulkRetryMax {
+			return fmt.Errorf("elasticsearch rejected %d metrics after %d retries", len(retry), attempt)
+		}
+
+		a.Log.Debugf("Elasticsearch rejected %d of %d metrics, retrying in %s", len(retry), len(requests), backoff)
+		select {
+		case <-a.ctx.Done():
+			return fmt.Errorf("output closed before retrying %d rejected metrics", len(retry))
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		requests = retry
+	}
 }
 
 func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
@@ -335,12 +513,6 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 		return fmt.Errorf("elasticsearch template_name configuration not defined")
 	}
 
-	templateExists, errExists := a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
-
-	if errExists != nil {
-		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, errExists)
-	}
-
 	templatePattern := a.IndexName
 
 	if strings.Contains(templatePattern, "%") {
@@ -355,6 +527,16 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 		return fmt.Errorf("template cannot be created for dynamic index names without an index prefix")
 	}
 
+	if a.TemplateFormat == "composable" {
+		return a.manageComposableTemplate(ctx, templatePattern)
+	}
+
+	templateExists, errExists := a.Client.IndexTemplateExists(a.TemplateName).Do(ctx)
+
+	if errExists != nil {
+		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, errExists)
+	}
+
 	if (a.OverwriteTemplate) || (!templateExists) || (templatePattern != "") {
 		tp := templatePart{
 			TemplatePattern: templatePattern + "*",
@@ -380,6 +562,57 @@ func (a *Elasticsearch) manageTemplate(ctx context.Context) error {
 	return nil
 }
 
+// manageComposableTemplate creates the component templates for settings and
+// mappings and an index template composed of them.
+func (a *Elasticsearch) manageComposableTemplate(ctx context.Context, templatePattern string) error {
+	path := "/_index_template/" + url.PathEscape(a.TemplateName)
+	_, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{Method: http.MethodHead, Path: path})
+	templateExists := err == nil
+	if err != nil && !elastic.IsNotFound(err) {
+		return fmt.Errorf("elasticsearch template check failed, template name: %s, error: %w", a.TemplateName, err)
+	}
+
+	if templateExists && !a.OverwriteTemplate {
+		a.Log.Debug("Found existing Elasticsearch template. Skipping template management")
+		return nil
+	}
+
+	tp := templatePart{
+		TemplatePattern: templatePattern + "*",
+		Version:         a.majorReleaseNumber,
+		Name:            a.TemplateName,
+		DataStream:      a.DataStream,
+	}
+
+	templates := []struct {
+		path string
+		body string
+	}{
+		{"/_component_template/" + url.PathEscape(a.TemplateName+"-settings"), telegrafComponentSettings},
+		{"/_component_template/" + url.PathEscape(a.TemplateName+"-mappings"), telegrafComponentMappings},
+		{path, telegrafIndexTemplate},
+	}
+	for _, tmpl := range templates {
+		t := template.Must(template.New("template").Parse(tmpl.body))
+		var body bytes.Buffer
+		if err := t.Execute(&body, tp); err != nil {
+			return err
+		}
+
+		_, err := a.Client.PerformRequest(ctx, elastic.PerformRequestOptions{
+			Method: http.MethodPut,
+			Path:   tmpl.path,
+			Body:   body.String(),
+		})
+		if err != nil {
+			return fmt.Errorf("elasticsearch failed to create template %s: %w", tmpl.path, err)
+		}
+	}
+
+	a.Log.Debugf("Template %s created or updated", a.TemplateName)
+	return nil
+}
+
 func (a *Elasticsearch) GetTagKeys(indexName string) (string, []string) {
 	tagKeys := []string{}
 	startTag := strings.Index(indexName, "{{")
@@ -452,12 +685,35 @@ func (a *Elasticsearch) getPipelineName(pipelineInput string, tagKeys []string,
 	return fmt.Sprintf(pipelineInput, tagValues...)
 }
 
+// versionAtLeast checks if the given version string is at least major.minor
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.Split(version, ".")
+	vmajor, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return false
+	}
+	if vmajor != major {
+		return vmajor > major
+	}
+	if len(parts) < 2 {
+		return minor == 0
+	}
+	vminor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return false
+	}
+	return vminor >= minor
+}
+
 func getISOWeek(eventTime time.Time) string {
 	_, week := eventTime.ISOWeek()
 	return strconv.Itoa(week)
 }
 
 func (a *Elasticsearch) Close() error {
+	if a.cancel != nil {
+		a.cancel()
+	}
 	a.Client = nil
 	return nil
 }
@@ -465,6 +721,10 @@ func (a *Elasticsearch) Close() error {
 func (a *Elasticsearch) getAuthOptions() ([]elastic.ClientOptionFunc, error) {
 	var fns []elastic.ClientOptionFunc
 
+	if !a.APIKey.Empty() && !a.AuthBearerToken.Empty() {
+		return nil, fmt.Errorf("api_key and auth_bearer_token cannot be used together")
+	}
+
 	if !a.Username.Empty() && !a.Password.Empty() {
 		username, err := a.Username.Get()
 		if err != nil {
@@ -480,6 +740,16 @@ func (a *Elasticsearch) getAuthOptions() ([]elastic.ClientOptionFunc, error) {
 		config.ReleaseSecret(password)
 	}
 
+	if !a.APIKey.Empty() {
+		key, err := a.APIKey.Get()
+		if err != nil {
+			return nil, fmt.Errorf("getting API key failed: %w", err)
+		}
+		auth := []string{"ApiKey " + string(key)}
+		fns = append(fns, elastic.SetHeaders(http.Header{"Authorization": auth}))
+		config.ReleaseSecret(key)
+	}
+
 	if !a.AuthBearerToken.Empty() {
 		token, err := a.AuthBearerToken.Get()
 		if err != nil {
@@ -498,6 +768,8 @@ func init() {
 			Timeout:             config.Duration(time.Second * 5),
 			HealthCheckInterval: config.Duration(time.Second * 10),
 			HealthCheckTimeout:  config.Duration(time.Second * 1),
+			BulkRetryMax:        3,
+			BulkRetryBackoff:    config.Duration(time.Second),
 		}
 	})
 }
@@ -1,12 +1,16 @@
 package elasticsearch
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,6 +20,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -755,3 +760,269 @@ func TestAuthorizationHeaderWhenBearerTokenIsPresent(t *testing.T) {
 	err = e.Write(testutil.MockMetrics())
 	require.NoError(t, err)
 }
+
+func TestAuthorizationHeaderWhenAPIKeyIsPresent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_bulk":
+			require.Equal(t, "ApiKey VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==", r.Header.Get("Authorization"))
+			_, err := w.Write([]byte("{}"))
+			require.NoError(t, err)
+			return
+		default:
+			_, err := w.Write([]byte(`{"version": {"number": "7.8"}}`))
+			require.NoError(t, err)
+			return
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:      []string{"http://" + ts.Listener.Addr().String()},
+		IndexName: "{{host}}-%Y.%m.%d",
+		Timeout:   config.Duration(time.Second * 5),
+		Log:       testutil.Logger{},
+		APIKey:    config.NewSecret([]byte("VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==")),
+	}
+	require.NoError(t, e.Connect())
+	require.NoError(t, e.Write(testutil.MockMetrics()))
+}
+
+func TestDataStreamWithComposableTemplate(t *testing.T) {
+	var mu sync.Mutex
+	var templates []string
+	var actions []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/_bulk":
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				var line map[string]interface{}
+				require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+				for k := range line {
+					actions = append(actions, k)
+				}
+				// Skip the document line
+				scanner.Scan()
+			}
+			_, err := w.Write([]byte("{}"))
+			require.NoError(t, err)
+		case r.Method == http.MethodHead && strings.HasPrefix(r.URL.Path, "/_index_template/"):
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == http.MethodPut:
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			if strings.HasPrefix(r.URL.Path, "/_index_template/") {
+				require.Contains(t, body, "data_stream")
+				require.Equal(t, []interface{}{"metrics-telegraf-*"}, body["index_patterns"])
+			}
+			templates = append(templates, r.URL.Path)
+			_, err := w.Write([]byte(`{"acknowledged": true}`))
+			require.NoError(t, err)
+		default:
+			_, err := w.Write([]byte(`{"version": {"number": "8.6.0"}}`))
+			require.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:           []string{"http://" + ts.Listener.Addr().String()},
+		IndexName:      "metrics-telegraf-{{host}}",
+		Timeout:        config.Duration(time.Second * 5),
+		DataStream:     true,
+		ManageTemplate: true,
+		TemplateName:   "telegraf",
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+	require.NoError(t, e.Write(testutil.MockMetrics()))
+
+	require.Equal(t, []string{
+		"/_component_template/telegraf-settings",
+		"/_component_template/telegraf-mappings",
+		"/_index_template/telegraf",
+	}, templates)
+	require.Equal(t, []string{"create"}, actions)
+}
+
+func TestDataStreamRequiresComposableTemplate(t *testing.T) {
+	e := &Elasticsearch{
+		URLs:           []string{"http://localhost:9200"},
+		IndexName:      "metrics-telegraf-default",
+		DataStream:     true,
+		TemplateFormat: "legacy",
+		Log:            testutil.Logger{},
+	}
+	require.ErrorContains(t, e.Connect(), "data streams require composable templates")
+}
+
+func TestBulkPartialRetry(t *testing.T) {
+	var mu sync.Mutex
+	var requests []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/_bulk":
+			var items int
+			scanner := bufio.NewScanner(r.Body)
+			for scanner.Scan() {
+				items++
+				scanner.Scan()
+			}
+			requests = append(requests, items)
+
+			// Reject the second and third item temporarily and the fourth
+			// permanently on the first attempt
+			if len(requests) == 1 {
+				_, err := w.Write([]byte(`{"errors": true, "items": [
+					{"index": {"_id": "1", "status": 201}},
+					{"index": {"_id": "2", "status": 429, "error": {"type": "es_rejected_execution_exception", "reason": "rejected"}}},
+					{"index": {"_id": "3", "status": 503, "error": {"type": "unavailable_shards_exception", "reason": "unavailable"}}},
+					{"index": {"_id": "4", "status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse"}}},
+					{"index": {"_id": "5", "status": 201}}
+				]}`))
+				require.NoError(t, err)
+				return
+			}
+			_, err := w.Write([]byte(`{"errors": false, "items": [
+				{"index": {"_id": "2", "status": 201}},
+				{"index": {"_id": "3", "status": 201}}
+			]}`))
+			require.NoError(t, err)
+		default:
+			_, err := w.Write([]byte(`{"version": {"number": "7.17.0"}}`))
+			require.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:             []string{"http://" + ts.Listener.Addr().String()},
+		IndexName:        "telegraf-%Y.%m.%d",
+		Timeout:          config.Duration(time.Second * 5),
+		BulkRetryMax:     3,
+		BulkRetryBackoff: config.Duration(time.Millisecond),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	metrics := []telegraf.Metric{
+		testutil.TestMetric(1),
+		testutil.TestMetric(2),
+		testutil.TestMetric(3),
+		testutil.TestMetric(4),
+		testutil.TestMetric(5),
+	}
+	require.NoError(t, e.Write(metrics))
+	require.Equal(t, []int{5, 2}, requests)
+}
+
+func TestWriteEvent(t *testing.T) {
+	var doc map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_bulk":
+			scanner := bufio.NewScanner(r.Body)
+			require.True(t, scanner.Scan())
+			require.True(t, scanner.Scan())
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &doc))
+			_, err := w.Write([]byte(`{"errors": false, "items": [{"index": {"_id": "1", "status": 201}}]}`))
+			require.NoError(t, err)
+		default:
+			_, err := w.Write([]byte(`{"version": {"number": "7.17.0"}}`))
+			require.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:      []string{"http://" + ts.Listener.Addr().String()},
+		IndexName: "telegraf",
+		Timeout:   config.Duration(time.Second * 5),
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	event := metric.NewEvent("syslog", map[string]string{"host": "example.org"}, telegraf.SeverityWarning, "disk almost full", time.Unix(0, 0))
+	event.AddField("facility_code", 1)
+	require.NoError(t, e.Write([]telegraf.Metric{event}))
+
+	require.Equal(t, "disk almost full", doc["message"])
+	require.Equal(t, map[string]interface{}{"level": "warning"}, doc["log"])
+	require.Equal(t, map[string]interface{}{"facility_code": 1.0}, doc["syslog"])
+}
+
+func TestBulkRetryExhausted(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_bulk":
+			_, err := w.Write([]byte(`{"errors": true, "items": [{"index": {"_id": "1", "status": 429}}]}`))
+			require.NoError(t, err)
+		default:
+			_, err := w.Write([]byte(`{"version": {"number": "7.17.0"}}`))
+			require.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:             []string{"http://" + ts.Listener.Addr().String()},
+		IndexName:        "telegraf-%Y.%m.%d",
+		Timeout:          config.Duration(time.Second * 5),
+		BulkRetryMax:     2,
+		BulkRetryBackoff: config.Duration(time.Millisecond),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+	require.ErrorContains(t, e.Write([]telegraf.Metric{testutil.TestMetric(1)}), "rejected 1 metrics after 2 retries")
+}
+
+func TestBulkRetryAbortedOnClose(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/_bulk":
+			_, err := w.Write([]byte(`{"errors": true, "items": [{"index": {"_id": "1", "status": 429}}]}`))
+			require.NoError(t, err)
+			requested <- struct{}{}
+		default:
+			_, err := w.Write([]byte(`{"version": {"number": "7.17.0"}}`))
+			require.NoError(t, err)
+		}
+	}))
+	defer ts.Close()
+
+	e := &Elasticsearch{
+		URLs:             []string{"http://" + ts.Listener.Addr().String()},
+		IndexName:        "telegraf-%Y.%m.%d",
+		Timeout:          config.Duration(time.Second * 5),
+		BulkRetryMax:     2,
+		BulkRetryBackoff: config.Duration(time.Hour),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, e.Connect())
+
+	// Closing the output must abort the write instead of waiting for the
+	// backoff to elapse
+	go func() {
+		<-requested
+		require.NoError(t, e.Close())
+	}()
+	start := time.Now()
+	require.Error(t, e.Write([]telegraf.Metric{testutil.TestMetric(1)}))
+	require.Less(t, time.Since(start), time.Minute)
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	require.True(t, versionAtLeast("7.9.0", 7, 9))
+	require.True(t, versionAtLeast("8.0.0", 7, 9))
+	require.False(t, versionAtLeast("7.8.1", 7, 9))
+	require.False(t, versionAtLeast("6.8", 7, 8))
+	require.True(t, versionAtLeast("7", 7, 0))
+}
@@ -26,10 +26,11 @@ const maxStderrBytes = 512
 
 // Exec defines the exec output plugin.
 type Exec struct {
-	Command     []string        `toml:"command"`
-	Environment []string        `toml:"environment"`
-	Timeout     config.Duration `toml:"timeout"`
-	Log         telegraf.Logger `toml:"-"`
+	Command       []string        `toml:"command"`
+	Environment   []string        `toml:"environment"`
+	Timeout       config.Duration `toml:"timeout"`
+	DropExitCodes []int           `toml:"drop_exit_codes"`
+	Log           telegraf.Logger `toml:"-"`
 
 	runner     Runner
 	serializer serializers.Serializer
@@ -62,18 +63,29 @@ func (e *Exec) Close() error {
 
 // Write writes the metrics to the configured command.
 func (e *Exec) Write(metrics []telegraf.Metric) error {
-	var buffer bytes.Buffer
-	serializedMetrics, err := e.serializer.SerializeBatch(metrics)
-	if err != nil {
+	buffer := serializers.GetBuffer()
+	defer serializers.PutBuffer(buffer)
+
+	if err := serializers.SerializeBatchTo(e.serializer, buffer, metrics); err != nil {
 		return err
 	}
-	buffer.Write(serializedMetrics)
 
 	if buffer.Len() <= 0 {
 		return nil
 	}
 
-	return e.runner.Run(time.Duration(e.Timeout), e.Command, e.Environment, &buffer)
+	err := e.runner.Run(time.Duration(e.Timeout), e.Command, e.Environment, buffer)
+	if status, ok := internal.ExitStatus(err); ok {
+		for _, code := range e.DropExitCodes {
+			if status == code {
+				// The command rejected the data permanently so retrying
+				// the batch would block the output forever
+				e.Log.Errorf("Dropping %d metrics: %v", len(metrics), err)
+				return nil
+			}
+		}
+	}
+	return err
 }
 
 // Runner provides an interface for running exec.Cmd.
@@ -2,6 +2,7 @@ package exec
 
 import (
 	"bytes"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -67,6 +68,32 @@ func TestExec(t *testing.T) {
 	}
 }
 
+func TestDropExitCodes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping test due to OS/executable dependencies")
+	}
+
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	logger := &testutil.CaptureLogger{}
+	e := &Exec{
+		Command:       []string{"sh", "-c", "cat > /dev/null; exit 3"},
+		Timeout:       config.Duration(5 * time.Second),
+		DropExitCodes: []int{2},
+		Log:           logger,
+	}
+	e.SetSerializer(s)
+	require.NoError(t, e.Init())
+
+	// Exit codes not listed fail the write to retry the metrics later
+	require.ErrorContains(t, e.Write(testutil.MockMetrics()), "exited 3")
+
+	e.DropExitCodes = []int{2, 3}
+	require.NoError(t, e.Write(testutil.MockMetrics()))
+	require.Contains(t, logger.LastError(), "Dropping 1 metrics")
+}
+
 func TestTruncate(t *testing.T) {
 	tests := []struct {
 		name string
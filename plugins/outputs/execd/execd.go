@@ -4,14 +4,17 @@ package execd
 import (
 	"bufio"
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/process"
+	"github.com/influxdata/telegraf/plugins/common/framing"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -23,12 +26,26 @@ type Execd struct {
 	Command                  []string        `toml:"command"`
 	Environment              []string        `toml:"environment"`
 	RestartDelay             config.Duration `toml:"restart_delay"`
+	RestartDelayMax          config.Duration `toml:"restart_delay_max"`
+	MaxRestarts              int             `toml:"max_restarts"`
+	WriteTimeout             config.Duration `toml:"write_timeout"`
 	IgnoreSerializationError bool            `toml:"ignore_serialization_error"`
 	UseBatchFormat           bool            `toml:"use_batch_format"`
+	Protocol                 string          `toml:"protocol"`
+	HeartbeatInterval        config.Duration `toml:"heartbeat_interval"`
+	AckTimeout               config.Duration `toml:"ack_timeout"`
+	PluginConfig             string          `toml:"plugin_config"`
 	Log                      telegraf.Logger
 
 	process    *process.Process
+	conn       *process.FrameConn
+	acks       chan framing.Frame
 	serializer serializers.Serializer
+
+	// Sequence number of the last data frame sent and of the frame waiting
+	// for a response, zero if none is waiting
+	seq     uint64
+	pending atomic.Uint64
 }
 
 func (*Execd) SampleConfig() string {
@@ -44,6 +61,18 @@ func (e *Execd) Init() error {
 		return fmt.Errorf("no command specified")
 	}
 
+	if e.MaxRestarts < 0 {
+		return errors.New("'max_restarts' must not be negative")
+	}
+
+	switch e.Protocol {
+	case "":
+		e.Protocol = process.ProtocolV1
+	case process.ProtocolV1, process.ProtocolV2:
+	default:
+		return fmt.Errorf("invalid protocol %q", e.Protocol)
+	}
+
 	var err error
 
 	e.process, err = process.New(e.Command, e.Environment)
@@ -52,8 +81,18 @@ func (e *Execd) Init() error {
 	}
 	e.process.Log = e.Log
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
+	e.process.RestartDelayMax = time.Duration(e.RestartDelayMax)
+	e.process.MaxRestarts = e.MaxRestarts
+	e.process.WriteTimeout = time.Duration(e.WriteTimeout)
 	e.process.ReadStdoutFn = e.cmdReadOut
 	e.process.ReadStderrFn = e.cmdReadErr
+	if e.Protocol == process.ProtocolV2 {
+		e.acks = make(chan framing.Frame, 1)
+		e.conn = process.NewFrameConn(e.process)
+		e.conn.HeartbeatInterval = time.Duration(e.HeartbeatInterval)
+		e.conn.Config = []byte(e.PluginConfig)
+		e.process.ReadStdoutFn = e.conn.Reader(e.handleFrame)
+	}
 
 	return nil
 }
@@ -69,23 +108,33 @@ func (e *Execd) Connect() error {
 		}
 		return fmt.Errorf("failed to start process %s: %w", e.Command, err)
 	}
+	if e.conn != nil {
+		e.conn.Start()
+	}
 
 	return nil
 }
 
 func (e *Execd) Close() error {
+	if e.conn != nil {
+		e.conn.Stop()
+	}
 	e.process.Stop()
 	return nil
 }
 
 func (e *Execd) Write(metrics []telegraf.Metric) error {
+	if e.conn != nil {
+		return e.writeFrame(metrics)
+	}
+
 	if e.UseBatchFormat {
 		b, err := e.serializer.SerializeBatch(metrics)
 		if err != nil {
 			return fmt.Errorf("error serializing metrics: %w", err)
 		}
 
-		if _, err = e.process.Stdin.Write(b); err != nil {
+		if _, err = e.process.Write(b); err != nil {
 			return fmt.Errorf("error writing metrics: %w", err)
 		}
 		return nil
@@ -100,13 +149,97 @@ func (e *Execd) Write(metrics []telegraf.Metric) error {
 			continue
 		}
 
-		if _, err = e.process.Stdin.Write(b); err != nil {
+		if _, err = e.process.Write(b); err != nil {
 			return fmt.Errorf("error writing metrics: %w", err)
 		}
 	}
 	return nil
 }
 
+// writeFrame sends the metrics as a single data frame and waits for the
+// process to acknowledge it. The frame is prefixed with a sequence number the
+// process has to echo in its response, so late responses to previous frames
+// are not mistaken for the current one. Errors reported by the process or a
+// missing acknowledgement fail the write so the metrics are kept in the
+// buffer.
+func (e *Execd) writeFrame(metrics []telegraf.Metric) error {
+	var payload []byte
+	if e.UseBatchFormat {
+		b, err := e.serializer.SerializeBatch(metrics)
+		if err != nil {
+			return fmt.Errorf("error serializing metrics: %w", err)
+		}
+		payload = b
+	} else {
+		for _, m := range metrics {
+			b, err := e.serializer.Serialize(m)
+			if err != nil {
+				if !e.IgnoreSerializationError {
+					return fmt.Errorf("error serializing metrics: %w", err)
+				}
+				e.Log.Errorf("Skipping metric due to a serialization error: %v", err)
+				continue
+			}
+			payload = append(payload, b...)
+		}
+	}
+
+	e.seq++
+	e.pending.Store(e.seq)
+	defer e.pending.Store(0)
+
+	// Discard responses to previous writes still queued
+	select {
+	case <-e.acks:
+	default:
+	}
+
+	if err := e.conn.Write(framing.Data, framing.PrefixSequence(e.seq, payload)); err != nil {
+		return fmt.Errorf("error writing metrics: %w", err)
+	}
+
+	timer := time.NewTimer(time.Duration(e.AckTimeout))
+	defer timer.Stop()
+	for {
+		select {
+		case f := <-e.acks:
+			seq, msg, err := framing.SplitSequence(f.Payload)
+			if err != nil || seq != e.seq {
+				continue
+			}
+			if f.Type == framing.Error {
+				return fmt.Errorf("process reported error: %s", msg)
+			}
+			return nil
+		case <-timer.C:
+			return errors.New("timeout waiting for acknowledgement")
+		}
+	}
+}
+
+// handleFrame processes the frames of the protocol v2
+func (e *Execd) handleFrame(f framing.Frame) {
+	switch f.Type {
+	case framing.Ack, framing.Error:
+		seq, _, err := framing.SplitSequence(f.Payload)
+		if err != nil {
+			e.Log.Errorf("Invalid frame of type %q: %v", f.Type, err)
+			return
+		}
+		if pending := e.pending.Load(); seq != pending {
+			e.Log.Debugf("Dropping frame of type %q for sequence %d while waiting for %d", f.Type, seq, pending)
+			return
+		}
+		select {
+		case e.acks <- f:
+		default:
+			e.Log.Debugf("Dropping duplicate frame of type %q for sequence %d", f.Type, seq)
+		}
+	default:
+		e.Log.Debugf("Ignoring unexpected frame of type %q", f.Type)
+	}
+}
+
 func (e *Execd) cmdReadErr(out io.Reader) {
 	scanner := bufio.NewScanner(out)
 
@@ -129,6 +262,9 @@ func (e *Execd) cmdReadOut(out io.Reader) {
 
 func init() {
 	outputs.Add("execd", func() telegraf.Output {
-		return &Execd{}
+		return &Execd{
+			Protocol:   process.ProtocolV1,
+			AckTimeout: config.Duration(10 * time.Second),
+		}
 	})
 }
@@ -18,6 +18,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/framing"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	influxSerializer "github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -201,16 +202,158 @@ func TestPartiallyUnserializableCanBeSkipped(t *testing.T) {
 	require.NoError(t, e.Close())
 }
 
+func TestExternalOutputProtocolV2(t *testing.T) {
+	serializer := &influxSerializer.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	e := &Execd{
+		Command:      []string{exe, "-framedoutput"},
+		Environment:  []string{"PLUGINS_OUTPUTS_EXECD_MODE=application"},
+		RestartDelay: config.Duration(5 * time.Second),
+		Protocol:     "v2",
+		AckTimeout:   config.Duration(5 * time.Second),
+		PluginConfig: "reject-first",
+		serializer:   serializer,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"name": "cpu1"},
+		map[string]interface{}{"idle": 50, "message": "multi\nline"},
+		now,
+	)
+
+	require.NoError(t, e.Connect())
+	require.ErrorContains(t, e.Write([]telegraf.Metric{m}), "process reported error: not ready")
+	require.NoError(t, e.Write([]telegraf.Metric{m, m}))
+	require.NoError(t, e.Close())
+}
+
+func TestExternalOutputProtocolV2Timeout(t *testing.T) {
+	serializer := &influxSerializer.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	e := &Execd{
+		Command:      []string{exe, "-framedoutput"},
+		Environment:  []string{"PLUGINS_OUTPUTS_EXECD_MODE=application"},
+		RestartDelay: config.Duration(5 * time.Second),
+		Protocol:     "v2",
+		AckTimeout:   config.Duration(100 * time.Millisecond),
+		PluginConfig: "silent",
+		serializer:   serializer,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 50}, now)
+
+	require.NoError(t, e.Connect())
+	require.ErrorContains(t, e.Write([]telegraf.Metric{m}), "timeout waiting for acknowledgement")
+	require.NoError(t, e.Close())
+}
+
+func TestExternalOutputProtocolV2LateAck(t *testing.T) {
+	serializer := &influxSerializer.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	e := &Execd{
+		Command:      []string{exe, "-framedoutput"},
+		Environment:  []string{"PLUGINS_OUTPUTS_EXECD_MODE=application"},
+		RestartDelay: config.Duration(5 * time.Second),
+		Protocol:     "v2",
+		AckTimeout:   config.Duration(200 * time.Millisecond),
+		PluginConfig: "late-ack",
+		serializer:   serializer,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"idle": 50}, now)
+
+	require.NoError(t, e.Connect())
+	require.ErrorContains(t, e.Write([]telegraf.Metric{m}), "timeout waiting for acknowledgement")
+
+	// The late acknowledgement of the first frame must not be taken for the
+	// second one
+	require.ErrorContains(t, e.Write([]telegraf.Metric{m}), "timeout waiting for acknowledgement")
+	require.NoError(t, e.Write([]telegraf.Metric{m}))
+	require.NoError(t, e.Close())
+}
+
+func TestWriteTimeout(t *testing.T) {
+	serializer := &influxSerializer.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	e := &Execd{
+		Command:      []string{exe, "-wedgedoutput"},
+		Environment:  []string{"PLUGINS_OUTPUTS_EXECD_MODE=application"},
+		RestartDelay: config.Duration(10 * time.Millisecond),
+		WriteTimeout: config.Duration(100 * time.Millisecond),
+		serializer:   serializer,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, e.Init())
+
+	// The metric exceeds the pipe buffer of the process not reading its input
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"message": strings.Repeat("x", 1024*1024)}, now)
+
+	require.NoError(t, e.Connect())
+	require.ErrorContains(t, e.Write([]telegraf.Metric{m}), "timeout writing to process")
+	require.NoError(t, e.Close())
+}
+
+func TestInitFail(t *testing.T) {
+	e := &Execd{
+		Command:     []string{"my-output"},
+		MaxRestarts: -1,
+		Log:         testutil.Logger{},
+	}
+	require.ErrorContains(t, e.Init(), "'max_restarts' must not be negative")
+}
+
 var testoutput = flag.Bool("testoutput", false,
 	"if true, act like line input program instead of test")
 
+var framedoutput = flag.Bool("framedoutput", false,
+	"if true, act like framed output program instead of test")
+
+var wedgedoutput = flag.Bool("wedgedoutput", false,
+	"if true, act like an output program never reading its input instead of test")
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 	runMode := os.Getenv("PLUGINS_OUTPUTS_EXECD_MODE")
+	if *wedgedoutput && runMode == "application" {
+		// Wait for being killed
+		time.Sleep(time.Hour)
+		os.Exit(0)
+	}
 	if *testoutput && runMode == "application" {
 		runOutputConsumerProgram()
 		os.Exit(0)
 	}
+	if *framedoutput && runMode == "application" {
+		if err := runFramedOutputProgram(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+			//nolint:revive // error code is important for this "test"
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	code := m.Run()
 	os.Exit(code)
 }
@@ -262,3 +405,68 @@ func runOutputConsumerProgram() {
 		os.Exit(1)
 	}
 }
+
+// runFramedOutputProgram acknowledges all data frames containing valid
+// metrics. Depending on the pushed configuration the first frame is rejected,
+// the first frame is only acknowledged after the second one was received or
+// no frame is acknowledged at all.
+func runFramedOutputProgram() error {
+	parser := &influx.Parser{}
+	if err := parser.Init(); err != nil {
+		return err
+	}
+
+	var mode string
+	var frames int
+	var previous uint64
+	for {
+		f, err := framing.Read(os.Stdin)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch f.Type {
+		case framing.Config:
+			mode = string(f.Payload)
+		case framing.Data:
+			frames++
+			seq, payload, err := framing.SplitSequence(f.Payload)
+			if err != nil {
+				return err
+			}
+			metrics, err := parser.Parse(payload)
+			if err != nil {
+				return err
+			}
+			for _, m := range metrics {
+				if v, found := m.GetField("message"); found && v != "multi\nline" {
+					return fmt.Errorf("unexpected message %q", v)
+				}
+			}
+
+			switch {
+			case mode == "silent":
+			case mode == "reject-first" && frames == 1:
+				msg := framing.PrefixSequence(seq, []byte("not ready"))
+				if err := framing.Write(os.Stdout, framing.Error, msg); err != nil {
+					return err
+				}
+			case mode == "late-ack" && frames == 1:
+			case mode == "late-ack" && frames == 2:
+				if err := framing.Write(os.Stdout, framing.Ack, framing.PrefixSequence(previous, nil)); err != nil {
+					return err
+				}
+			default:
+				if err := framing.Write(os.Stdout, framing.Ack, framing.PrefixSequence(seq, nil)); err != nil {
+					return err
+				}
+			}
+			previous = seq
+		case framing.Shutdown:
+			return nil
+		}
+	}
+}
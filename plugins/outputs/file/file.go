@@ -3,9 +3,12 @@ package file
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"text/template"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -24,6 +27,8 @@ type File struct {
 	RotationInterval     config.Duration `toml:"rotation_interval"`
 	RotationMaxSize      config.Size     `toml:"rotation_max_size"`
 	RotationMaxArchives  int             `toml:"rotation_max_archives"`
+	RotationMaxAge       config.Duration `toml:"rotation_max_age"`
+	RotationCompression  string          `toml:"rotation_compression"`
 	UseBatchFormat       bool            `toml:"use_batch_format"`
 	CompressionAlgorithm string          `toml:"compression_algorithm"`
 	CompressionLevel     int             `toml:"compression_level"`
@@ -33,6 +38,10 @@ type File struct {
 	writer     io.Writer
 	closers    []io.Closer
 	serializer serializers.Serializer
+
+	// Files with templated paths and the writers of the rendered paths
+	templates []*pathTemplate
+	writers   map[string]io.WriteCloser
 }
 
 func (*File) SampleConfig() string {
@@ -58,8 +67,32 @@ func (f *File) Init() error {
 		options = append(options, internal.WithCompressionLevel(f.CompressionLevel))
 	}
 	f.encoder, err = internal.NewContentEncoder(f.CompressionAlgorithm, options...)
+	if err != nil {
+		return err
+	}
 
-	return err
+	switch f.RotationCompression {
+	case "", "gzip", "zstd":
+	default:
+		return fmt.Errorf("invalid 'rotation_compression' %q", f.RotationCompression)
+	}
+	if f.RotationMaxAge < 0 {
+		return errors.New("'rotation_max_age' must not be negative")
+	}
+
+	f.templates = nil
+	for _, file := range f.Files {
+		if file == "stdout" || !isTemplate(file) {
+			continue
+		}
+		tmpl, err := template.New("file").Parse(file)
+		if err != nil {
+			return fmt.Errorf("parsing file template %q failed: %w", file, err)
+		}
+		f.templates = append(f.templates, &pathTemplate{tmpl: tmpl})
+	}
+
+	return nil
 }
 
 func (f *File) Connect() error {
@@ -68,9 +101,8 @@ func (f *File) Connect() error {
 	for _, file := range f.Files {
 		if file == "stdout" {
 			writers = append(writers, os.Stdout)
-		} else {
-			of, err := rotate.NewFileWriter(
-				file, time.Duration(f.RotationInterval), int64(f.RotationMaxSize), f.RotationMaxArchives)
+		} else if !isTemplate(file) {
+			of, err := f.openFile(file)
 			if err != nil {
 				return err
 			}
@@ -79,10 +111,21 @@ func (f *File) Connect() error {
 			f.closers = append(f.closers, of)
 		}
 	}
-	f.writer = io.MultiWriter(writers...)
+	if len(writers) > 0 {
+		f.writer = io.MultiWriter(writers...)
+	}
+	f.writers = make(map[string]io.WriteCloser)
 	return nil
 }
 
+func (f *File) openFile(path string) (io.WriteCloser, error) {
+	return rotate.NewFileWriter(
+		path, time.Duration(f.RotationInterval), int64(f.RotationMaxSize), f.RotationMaxArchives,
+		rotate.WithMaxAge(time.Duration(f.RotationMaxAge)),
+		rotate.WithCompression(f.RotationCompression),
+	)
+}
+
 func (f *File) Close() error {
 	var err error
 	for _, c := range f.closers {
@@ -91,24 +134,93 @@ func (f *File) Close() error {
 			err = errClose
 		}
 	}
+	for path, w := range f.writers {
+		if errClose := w.Close(); errClose != nil {
+			err = errClose
+		}
+		delete(f.writers, path)
+	}
 	return err
 }
 
 func (f *File) Write(metrics []telegraf.Metric) error {
 	var writeErr error
+	if f.writer != nil {
+		writeErr = f.write(f.writer, metrics)
+	}
+
+	if len(f.templates) == 0 {
+		return writeErr
+	}
+
+	// Group the metrics by their rendered path
+	var order []string
+	groups := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		for _, t := range f.templates {
+			path, err := t.render(m)
+			if err != nil {
+				f.Log.Errorf("Could not render file path for metric %q: %v", m.Name(), err)
+				continue
+			}
+			if _, found := groups[path]; !found {
+				order = append(order, path)
+			}
+			groups[path] = append(groups[path], m)
+		}
+	}
+
+	for _, path := range order {
+		w, found := f.writers[path]
+		if !found {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				writeErr = fmt.Errorf("creating directory for %q failed: %w", path, err)
+				continue
+			}
+			of, err := f.openFile(path)
+			if err != nil {
+				writeErr = fmt.Errorf("opening %q failed: %w", path, err)
+				continue
+			}
+			f.writers[path] = of
+			w = of
+		}
+		if err := f.write(w, groups[path]); err != nil {
+			writeErr = err
+		}
+	}
+
+	// Close files not written anymore, e.g. for paths of past days
+	for path, w := range f.writers {
+		if _, found := groups[path]; found {
+			continue
+		}
+		if err := w.Close(); err != nil {
+			f.Log.Errorf("Closing %q failed: %v", path, err)
+		}
+		delete(f.writers, path)
+	}
+
+	return writeErr
+}
+
+func (f *File) write(writer io.Writer, metrics []telegraf.Metric) error {
+	var writeErr error
 
 	if f.UseBatchFormat {
-		octets, err := f.serializer.SerializeBatch(metrics)
-		if err != nil {
+		buf := serializers.GetBuffer()
+		defer serializers.PutBuffer(buf)
+
+		if err := serializers.SerializeBatchTo(f.serializer, buf, metrics); err != nil {
 			f.Log.Errorf("Could not serialize metric: %v", err)
 		}
 
-		octets, err = f.encoder.Encode(octets)
+		octets, err := f.encoder.Encode(buf.Bytes())
 		if err != nil {
 			f.Log.Errorf("Could not compress metrics: %v", err)
 		}
 
-		_, err = f.writer.Write(octets)
+		_, err = writer.Write(octets)
 		if err != nil {
 			f.Log.Errorf("Error writing to file: %v", err)
 		}
@@ -124,7 +236,7 @@ func (f *File) Write(metrics []telegraf.Metric) error {
 				f.Log.Errorf("Could not compress metrics: %v", err)
 			}
 
-			_, err = f.writer.Write(b)
+			_, err = writer.Write(b)
 			if err != nil {
 				writeErr = fmt.Errorf("failed to write message: %w", err)
 			}
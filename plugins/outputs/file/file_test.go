@@ -2,13 +2,19 @@ package file
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -280,6 +286,85 @@ func TestFileStdout(t *testing.T) {
 	require.Equal(t, expNewFile, out)
 }
 
+func TestInitFail(t *testing.T) {
+	f := File{RotationCompression: "lz4", CompressionLevel: -1}
+	require.ErrorContains(t, f.Init(), "invalid 'rotation_compression'")
+
+	f = File{Files: []string{"/tmp/{{.Name"}, CompressionLevel: -1}
+	require.ErrorContains(t, f.Init(), "parsing file template")
+}
+
+func TestTemplatedPaths(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	f := File{
+		Files:            []string{filepath.Join(dir, `{{.Tag "host"}}`, "%Y-%m-%d.out")},
+		serializer:       s,
+		CompressionLevel: -1,
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+	defer f.Close()
+
+	day1 := time.Date(2023, 3, 1, 12, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, day1),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": 2}, day1),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 3}, day2),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 4}, day1),
+	}
+	require.NoError(t, f.Write(metrics))
+
+	validateFile(t, filepath.Join(dir, "a", "2023-03-01.out"),
+		fmt.Sprintf("cpu,host=a value=1i %d\ncpu,host=a value=4i %d\n", day1.UnixNano(), day1.UnixNano()))
+	validateFile(t, filepath.Join(dir, "b", "2023-03-01.out"),
+		fmt.Sprintf("cpu,host=b value=2i %d\n", day1.UnixNano()))
+	validateFile(t, filepath.Join(dir, "a", "2023-03-02.out"),
+		fmt.Sprintf("cpu,host=a value=3i %d\n", day2.UnixNano()))
+	require.Len(t, f.writers, 3)
+
+	// Files not written anymore are closed
+	require.NoError(t, f.Write(metrics[2:3]))
+	require.Len(t, f.writers, 1)
+	require.Contains(t, f.writers, filepath.Join(dir, "a", "2023-03-02.out"))
+}
+
+func TestRotationCompression(t *testing.T) {
+	s := &influx.Serializer{}
+	require.NoError(t, s.Init())
+
+	dir := t.TempDir()
+	f := File{
+		Files:               []string{filepath.Join(dir, "metrics.out")},
+		RotationMaxSize:     config.Size(10),
+		RotationMaxArchives: -1,
+		RotationCompression: "gzip",
+		serializer:          s,
+		CompressionLevel:    -1,
+		Log:                 testutil.Logger{},
+	}
+	require.NoError(t, f.Init())
+	require.NoError(t, f.Connect())
+	require.NoError(t, f.Write(testutil.MockMetrics()))
+	require.NoError(t, f.Close())
+
+	archives, err := filepath.Glob(filepath.Join(dir, "metrics.*-*.out.gz"))
+	require.NoError(t, err)
+	require.Len(t, archives, 1)
+	validateGzipCompressedFile(t, archives[0], expNewFile)
+}
+
+func TestStrftime(t *testing.T) {
+	ts := time.Date(2023, 2, 5, 7, 8, 9, 0, time.UTC)
+	require.Equal(t, "2023/23/02/05/07/08/09/036/Feb/1675580889/%/%q", strftime("%Y/%y/%m/%d/%H/%M/%S/%j/%b/%s/%%/%q", ts))
+	require.Equal(t, "no-directives", strftime("no-directives", ts))
+	require.Equal(t, "trailing%", strftime("trailing%", ts))
+}
+
 func createFile(t *testing.T) *os.File {
 	f, err := os.CreateTemp(t.TempDir(), "")
 	require.NoError(t, err)
@@ -0,0 +1,80 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// pathTemplate renders the path of a file for a metric. The path may contain
+// Go templates with access to the metric as well as strftime-like directives
+// which are replaced using the metric's timestamp in local time.
+type pathTemplate struct {
+	tmpl *template.Template
+}
+
+func isTemplate(path string) bool {
+	return strings.Contains(path, "{{") || strings.Contains(path, "%")
+}
+
+func (p *pathTemplate) render(m telegraf.Metric) (string, error) {
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return "", errors.New("metric is not a template metric")
+	}
+	var buf bytes.Buffer
+	if err := p.tmpl.Execute(&buf, tm); err != nil {
+		return "", err
+	}
+	return strftime(buf.String(), m.Time().Local()), nil
+}
+
+// strftime replaces the supported directives in the format by the
+// corresponding elements of the given time. Unknown directives are kept.
+func strftime(format string, t time.Time) string {
+	if !strings.Contains(format, "%") {
+		return format
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch format[i] {
+		case 'Y':
+			b.WriteString(strconv.Itoa(t.Year()))
+		case 'y':
+			b.WriteString(t.Format("06"))
+		case 'm':
+			b.WriteString(t.Format("01"))
+		case 'd':
+			b.WriteString(t.Format("02"))
+		case 'H':
+			b.WriteString(t.Format("15"))
+		case 'M':
+			b.WriteString(t.Format("04"))
+		case 'S':
+			b.WriteString(t.Format("05"))
+		case 'j':
+			b.WriteString(t.Format("002"))
+		case 'b':
+			b.WriteString(t.Format("Jan"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(format[i])
+		}
+	}
+	return b.String()
+}
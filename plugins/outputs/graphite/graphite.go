@@ -9,11 +9,14 @@ import (
 	"io"
 	"math/rand"
 	"net"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
 	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/graphite"
@@ -41,11 +44,14 @@ type Graphite struct {
 	Template  string          `toml:"template"`
 	Templates []string        `toml:"templates"`
 	Timeout   config.Duration `toml:"timeout"`
+	Protocol  string          `toml:"protocol"`
+	Routing   string          `toml:"routing"`
 	Log       telegraf.Logger `toml:"-"`
 	tlsint.ClientConfig
 
 	connections []connection
 	serializer  *graphite.GraphiteSerializer
+	ring        *balancer.Ring
 }
 
 func (*Graphite) SampleConfig() string {
@@ -67,9 +73,30 @@ func (g *Graphite) Init() error {
 	}
 	g.serializer = s
 
+	switch g.Protocol {
+	case "":
+		g.Protocol = "plaintext"
+	case "plaintext", "pickle":
+	default:
+		return fmt.Errorf("invalid protocol %q", g.Protocol)
+	}
+
 	// Set default values
 	if len(g.Servers) == 0 {
-		g.Servers = append(g.Servers, "localhost:2003")
+		port := "2003"
+		if g.Protocol == "pickle" {
+			port = "2004"
+		}
+		g.Servers = append(g.Servers, "localhost:"+port)
+	}
+
+	switch g.Routing {
+	case "", "random":
+		g.Routing = "random"
+	case "consistent_hash":
+		g.ring = balancer.NewRing(g.Servers)
+	default:
+		return fmt.Errorf("invalid routing %q", g.Routing)
 	}
 
 	// Fill in the connections from the server
@@ -185,15 +212,23 @@ func (g *Graphite) checkEOF(conn net.Conn) error {
 
 // Choose a random server in the cluster to write to until a successful write
 // occurs, logging each unsuccessful. If all servers fail, return error.
+// For consistent-hash routing each series is sent to its server in the ring,
+// failing over to the next servers in the ring.
 func (g *Graphite) Write(metrics []telegraf.Metric) error {
-	// Prepare data
-	var batch []byte
-	for _, metric := range metrics {
+	// Prepare data and remember the metric each line originates from
+	var lines []string
+	var origins []int
+	for i, metric := range metrics {
 		buf, err := g.serializer.Serialize(metric)
 		if err != nil {
 			g.Log.Errorf("Error serializing some metrics to graphite: %s", err.Error())
 		}
-		batch = append(batch, buf...)
+		for _, line := range strings.Split(string(buf), "\n") {
+			if line != "" {
+				lines = append(lines, line)
+				origins = append(origins, i)
+			}
+		}
 	}
 
 	// Try to connect to all servers not yet connected if any
@@ -201,12 +236,115 @@ func (g *Graphite) Write(metrics []telegraf.Metric) error {
 		return fmt.Errorf("failed to reconnect: %w", err)
 	}
 
+	if g.ring != nil {
+		return g.writeConsistent(lines, origins, len(metrics))
+	}
+
+	batch, err := g.encode(lines)
+	if err != nil {
+		return err
+	}
+
 	// Return on success of if we encounter a non-retryable error
-	if err := g.send(batch); err == nil || !errors.Is(err, ErrNotConnected) {
+	if err := g.send(batch, rand.Perm(len(g.connections))); err == nil || !errors.Is(err, ErrNotConnected) {
 		return err
 	}
 
 	// Try to reconnect and resend
+	if err := g.reconnectFailed(); err != nil {
+		return err
+	}
+
+	return g.send(batch, rand.Perm(len(g.connections)))
+}
+
+// writeConsistent groups the lines by their server order in the hash ring
+// and sends each group to the first available server in that order. If only
+// some of the groups failed, the metrics of the failed lines are returned as
+// partial write to not send the other groups again.
+func (g *Graphite) writeConsistent(lines []string, origins []int, count int) error {
+	type group struct {
+		order   []int
+		lines   []string
+		origins []int
+	}
+	var groups []*group
+	index := make(map[string]*group)
+	for i, line := range lines {
+		path, _, _ := strings.Cut(line, " ")
+		order := g.ring.Order(balancer.HashKey(path))
+		key := fmt.Sprint(order)
+		grp, found := index[key]
+		if !found {
+			grp = &group{order: order}
+			index[key] = grp
+			groups = append(groups, grp)
+		}
+		grp.lines = append(grp.lines, line)
+		grp.origins = append(grp.origins, origins[i])
+	}
+
+	var errs []error
+	failed := make(map[int]bool)
+	for _, grp := range groups {
+		if err := g.writeGroup(grp.lines, grp.order); err != nil {
+			errs = append(errs, err)
+			for _, i := range grp.origins {
+				failed[i] = true
+			}
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	// A metric with lines in different groups is retried as a whole if any
+	// of its lines failed
+	err := errors.Join(errs...)
+	if len(failed) == count {
+		return err
+	}
+	indices := make([]int, 0, len(failed))
+	for i := range failed {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return &internal.PartialWriteError{Err: err, MetricsFailed: indices}
+}
+
+// writeGroup sends the lines to the first available server in the given
+// order, reconnecting to failed servers once if none is available
+func (g *Graphite) writeGroup(lines []string, order []int) error {
+	batch, err := g.encode(lines)
+	if err != nil {
+		return err
+	}
+
+	err = g.send(batch, order)
+	if err != nil && errors.Is(err, ErrNotConnected) {
+		if err := g.reconnectFailed(); err != nil {
+			return err
+		}
+		err = g.send(batch, order)
+	}
+	return err
+}
+
+// encode creates the payload for the configured protocol
+func (g *Graphite) encode(lines []string) ([]byte, error) {
+	if g.Protocol == "pickle" {
+		return encodePickle(lines)
+	}
+
+	var batch []byte
+	for _, line := range lines {
+		batch = append(batch, line...)
+		batch = append(batch, '\n')
+	}
+	return batch, nil
+}
+
+func (g *Graphite) reconnectFailed() error {
 	failedServers := make([]string, 0, len(g.connections))
 	for _, c := range g.connections {
 		if !c.connected {
@@ -219,14 +357,12 @@ func (g *Graphite) Write(metrics []telegraf.Metric) error {
 			return fmt.Errorf("failed to reconnect: %w", err)
 		}
 	}
-
-	return g.send(batch)
+	return nil
 }
 
-func (g *Graphite) send(batch []byte) error {
-	// Try sending the data to a server. Try them in random order
-	p := rand.Perm(len(g.connections))
-	for i, n := range p {
+// send tries sending the data to the servers in the given order
+func (g *Graphite) send(batch []byte, order []int) error {
+	for i, n := range order {
 		server := g.connections[n]
 
 		// Skip unconnected servers
@@ -256,7 +392,7 @@ func (g *Graphite) send(batch []byte) error {
 		}
 
 		g.Log.Errorf("Writing to %q failed: %v", server.name, err)
-		if i < len(p)-1 {
+		if i < len(order)-1 {
 			g.Log.Info("Trying next server...")
 		}
 		// Mark server as failed so a new connection will be made
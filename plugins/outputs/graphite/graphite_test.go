@@ -3,11 +3,14 @@ package graphite
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/textproto"
+	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -17,7 +20,9 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -801,3 +806,211 @@ func TCPServer2WithTagsSeparatorUnderscore(t *testing.T, wg *sync.WaitGroup) {
 		require.NoError(t, tcpServer.Close())
 	}()
 }
+
+func TestGraphitePickle(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var header [4]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return
+		}
+		size := int(header[0])<<24 | int(header[1])<<16 | int(header[2])<<8 | int(header[3])
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return
+		}
+		received <- payload
+	}()
+
+	g := Graphite{
+		Servers:  []string{listener.Addr().String()},
+		Protocol: "pickle",
+		Template: "measurement.field",
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, g.Init())
+	require.NoError(t, g.Connect())
+	defer g.Close()
+
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{"usage": float64(3.14)},
+		time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, g.Write([]telegraf.Metric{m}))
+
+	expected, err := encodePickle([]string{"cpu.usage 3.14 1289430000"})
+	require.NoError(t, err)
+	select {
+	case payload := <-received:
+		require.Equal(t, expected[4:], payload)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for data")
+	}
+}
+
+func TestGraphiteConsistentHashFailover(t *testing.T) {
+	// Start two relays collecting the received lines
+	var mu sync.Mutex
+	received := make(map[int][]string)
+	listeners := make([]net.Listener, 0, 2)
+	servers := make([]string, 0, 2)
+	for i := 0; i < 2; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+		listeners = append(listeners, listener)
+		servers = append(servers, listener.Addr().String())
+
+		go func(idx int, l net.Listener) {
+			for {
+				conn, err := l.Accept()
+				if err != nil {
+					return
+				}
+				go func() {
+					defer conn.Close()
+					scanner := bufio.NewScanner(conn)
+					for scanner.Scan() {
+						mu.Lock()
+						received[idx] = append(received[idx], scanner.Text())
+						mu.Unlock()
+					}
+				}()
+			}
+		}(i, listener)
+	}
+
+	g := Graphite{
+		Servers:  servers,
+		Routing:  "consistent_hash",
+		Template: "measurement.tags.field",
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, g.Init())
+	require.NoError(t, g.Connect())
+	defer g.Close()
+
+	metrics := make([]telegraf.Metric, 0, 20)
+	for i := 0; i < 20; i++ {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": fmt.Sprintf("host%d", i)},
+			map[string]interface{}{"value": float64(i)},
+			time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC),
+		))
+	}
+
+	// All series must end up at the server given by the ring
+	require.NoError(t, g.Write(metrics))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received[0])+len(received[1]) == len(metrics)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	for idx, lines := range received {
+		for _, line := range lines {
+			path, _, _ := strings.Cut(line, " ")
+			order := g.ring.Order(balancer.HashKey(path))
+			require.Equal(t, idx, order[0], "line %q routed to wrong server", line)
+		}
+	}
+	received = make(map[int][]string)
+	mu.Unlock()
+
+	// Stop the first relay and check that its series fail over to the second
+	require.NoError(t, listeners[0].Close())
+	require.NoError(t, g.connections[0].conn.Close())
+	g.connections[0].connected = false
+
+	require.NoError(t, g.Write(metrics))
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received[1]) == len(metrics)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// budgetConn accepts a limited number of writes shared across connections
+type budgetConn struct {
+	net.Conn
+	budget *int
+	lines  *[]string
+}
+
+func (c *budgetConn) Read([]byte) (int, error) {
+	return 0, os.ErrDeadlineExceeded
+}
+
+func (c *budgetConn) Write(b []byte) (int, error) {
+	if *c.budget <= 0 {
+		return 0, errors.New("broken pipe")
+	}
+	*c.budget--
+	*c.lines = append(*c.lines, strings.Split(strings.TrimSpace(string(b)), "\n")...)
+	return len(b), nil
+}
+
+func (*budgetConn) SetReadDeadline(time.Time) error {
+	return nil
+}
+
+func (*budgetConn) Close() error {
+	return nil
+}
+
+func TestGraphiteConsistentHashPartialWrite(t *testing.T) {
+	g := Graphite{
+		Servers:  []string{"127.0.0.1:1", "127.0.0.2:1"},
+		Routing:  "consistent_hash",
+		Template: "measurement.tags.field",
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, g.Init())
+
+	// Only the first group of lines can be written, all others fail on both
+	// servers
+	budget := 1
+	var written []string
+	for i := range g.connections {
+		g.connections[i].conn = &budgetConn{budget: &budget, lines: &written}
+		g.connections[i].connected = true
+	}
+
+	metrics := make([]telegraf.Metric, 0, 20)
+	for i := 0; i < 20; i++ {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": fmt.Sprintf("host%d", i)},
+			map[string]interface{}{"value": float64(i)},
+			time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC),
+		))
+	}
+
+	err := g.Write(metrics)
+	var partial *internal.PartialWriteError
+	require.ErrorAs(t, err, &partial)
+	require.NotEmpty(t, written)
+	require.Len(t, partial.MetricsFailed, len(metrics)-len(written))
+
+	// The failed metrics are exactly the ones not written
+	for _, i := range partial.MetricsFailed {
+		host, _ := metrics[i].GetTag("host")
+		for _, line := range written {
+			require.NotContains(t, line, "cpu."+host+".value ")
+		}
+	}
+}
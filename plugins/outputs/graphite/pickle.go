@@ -0,0 +1,103 @@
+package graphite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Opcodes of the pickle protocol version 2 used for encoding datapoints
+const (
+	pickleProto      = 0x80
+	pickleEmptyList  = ']'
+	pickleMark       = '('
+	pickleAppends    = 'e'
+	pickleBinUnicode = 'X'
+	pickleBinInt     = 'J'
+	pickleLong1      = 0x8a
+	pickleBinFloat   = 'G'
+	pickleTuple2     = 0x86
+	pickleStop       = '.'
+)
+
+// pickleBatchSize is the maximum number of datapoints per pickle message to
+// stay well below the message size limit of carbon
+const pickleBatchSize = 500
+
+// encodePickle converts the given plaintext lines of the form
+// "path value timestamp" into length-prefixed pickle messages containing
+// a list of (path, (timestamp, value)) tuples as expected by carbon.
+func encodePickle(lines []string) ([]byte, error) {
+	var out bytes.Buffer
+	for start := 0; start < len(lines); start += pickleBatchSize {
+		end := start + pickleBatchSize
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		var buf bytes.Buffer
+		buf.Write([]byte{pickleProto, 2, pickleEmptyList, pickleMark})
+		for _, line := range lines[start:end] {
+			parts := strings.Fields(line)
+			if len(parts) != 3 {
+				return nil, fmt.Errorf("invalid line %q", line)
+			}
+			value, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value in line %q: %w", line, err)
+			}
+			timestamp, err := strconv.ParseInt(parts[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp in line %q: %w", line, err)
+			}
+
+			pickleString(&buf, parts[0])
+			pickleInt(&buf, timestamp)
+			pickleFloat(&buf, value)
+			buf.WriteByte(pickleTuple2)
+			buf.WriteByte(pickleTuple2)
+		}
+		buf.Write([]byte{pickleAppends, pickleStop})
+
+		var header [4]byte
+		binary.BigEndian.PutUint32(header[:], uint32(buf.Len()))
+		out.Write(header[:])
+		out.Write(buf.Bytes())
+	}
+	return out.Bytes(), nil
+}
+
+func pickleString(buf *bytes.Buffer, s string) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(s)))
+	buf.WriteByte(pickleBinUnicode)
+	buf.Write(size[:])
+	buf.WriteString(s)
+}
+
+func pickleInt(buf *bytes.Buffer, v int64) {
+	if v >= math.MinInt32 && v <= math.MaxInt32 {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], uint32(int32(v)))
+		buf.WriteByte(pickleBinInt)
+		buf.Write(b[:])
+		return
+	}
+
+	// Encode larger values as little-endian two's complement long
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(v))
+	buf.WriteByte(pickleLong1)
+	buf.WriteByte(8)
+	buf.Write(b[:])
+}
+
+func pickleFloat(buf *bytes.Buffer, v float64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], math.Float64bits(v))
+	buf.WriteByte(pickleBinFloat)
+	buf.Write(b[:])
+}
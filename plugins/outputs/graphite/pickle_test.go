@@ -0,0 +1,50 @@
+package graphite
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodePickle(t *testing.T) {
+	// Reference encoding verified against python's pickle.loads() yielding
+	// [('my.prefix.cpu.value', (1289430000, 3.14)), ('a;tag=v', (4102444800, 42.0))]
+	expected := "0000004f80025d2858130000006d792e7072656669782e6370752e76616c75654af023db4c4740091eb851eb851f8686" +
+		"5807000000613b7461673d768a08005786f4000000004740450000000000008686652e"
+
+	actual, err := encodePickle([]string{
+		"my.prefix.cpu.value 3.14 1289430000",
+		"a;tag=v 42 4102444800",
+	})
+	require.NoError(t, err)
+	require.Equal(t, expected, hex.EncodeToString(actual))
+}
+
+func TestEncodePickleBatches(t *testing.T) {
+	lines := make([]string, pickleBatchSize+1)
+	for i := range lines {
+		lines[i] = "a.b 1 1289430000"
+	}
+	actual, err := encodePickle(lines)
+	require.NoError(t, err)
+
+	// Walk the length-prefixed messages
+	var messages int
+	for len(actual) > 0 {
+		require.GreaterOrEqual(t, len(actual), 4)
+		size := int(actual[0])<<24 | int(actual[1])<<16 | int(actual[2])<<8 | int(actual[3])
+		require.GreaterOrEqual(t, len(actual), 4+size)
+		actual = actual[4+size:]
+		messages++
+	}
+	require.Equal(t, 2, messages)
+}
+
+func TestEncodePickleInvalid(t *testing.T) {
+	_, err := encodePickle([]string{"a.b foo 1289430000"})
+	require.ErrorContains(t, err, "invalid value")
+
+	_, err = encodePickle([]string{"a.b"})
+	require.ErrorContains(t, err, "invalid line")
+}
@@ -5,11 +5,17 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha1" //nolint:gosec // required by some webhook receivers
 	"crypto/sha256"
+	"crypto/sha512"
 	_ "embed"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,7 +28,9 @@ import (
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
 	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/common/retry"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
@@ -43,6 +51,7 @@ const (
 
 type HTTP struct {
 	URL                     string            `toml:"url"`
+	URLs                    []string          `toml:"urls"`
 	Method                  string            `toml:"method"`
 	Username                config.Secret     `toml:"username"`
 	Password                config.Secret     `toml:"password"`
@@ -51,18 +60,39 @@ type HTTP struct {
 	UseBatchFormat          bool              `toml:"use_batch_format"`
 	AwsService              string            `toml:"aws_service"`
 	NonRetryableStatusCodes []int             `toml:"non_retryable_statuscodes"`
+	DropClientErrors        bool              `toml:"drop_client_errors"`
+	HonorRetryAfter         bool              `toml:"honor_retry_after"`
+	MaxRetryAfter           config.Duration   `toml:"max_retry_after"`
+	BreakerThreshold        int               `toml:"circuit_breaker_threshold"`
+	BreakerTimeout          config.Duration   `toml:"circuit_breaker_timeout"`
+	HMACSecret              config.Secret     `toml:"hmac_secret"`
+	HMACHeader              string            `toml:"hmac_header"`
+	HMACAlgorithm           string            `toml:"hmac_algorithm"`
+	HMACPrefix              string            `toml:"hmac_prefix"`
+	HMACTimestampHeader     string            `toml:"hmac_timestamp_header"`
 	httpconfig.HTTPClientConfig
+	balancer.BalancingConfig
+	retry.RetryConfig
 	Log telegraf.Logger `toml:"-"`
 
 	client     *http.Client
 	serializer serializers.Serializer
+	hmacHash   func() hash.Hash
+	urls       []string
+	balancer   *balancer.Balancer
+	retrier    *retry.Retrier
+
+	// Retry and circuit-breaker state
+	retryAfter  time.Time
+	failures    int
+	breakerOpen time.Time
 
 	awsCfg *awsV2.Config
 	internalaws.CredentialConfig
 
 	// Google API Auth
 	CredentialsFile string `toml:"google_application_credentials"`
-	oauth2Token     *oauth2.Token
+	oauth2Tokens    map[string]*oauth2.Token
 }
 
 func (*HTTP) SampleConfig() string {
@@ -89,6 +119,41 @@ func (h *HTTP) Connect() error {
 		return fmt.Errorf("invalid method [%s] %s", h.URL, h.Method)
 	}
 
+	if !h.HMACSecret.Empty() {
+		switch h.HMACAlgorithm {
+		case "sha1":
+			h.hmacHash = sha1.New
+		case "", "sha256":
+			h.hmacHash = sha256.New
+		case "sha512":
+			h.hmacHash = sha512.New
+		default:
+			return fmt.Errorf("invalid hmac_algorithm %q", h.HMACAlgorithm)
+		}
+		if h.HMACHeader == "" {
+			h.HMACHeader = "X-Signature"
+		}
+	}
+	if h.BreakerThreshold < 0 {
+		return fmt.Errorf("invalid circuit_breaker_threshold %d", h.BreakerThreshold)
+	}
+
+	h.urls = h.URLs
+	if len(h.urls) == 0 {
+		h.urls = []string{h.URL}
+	}
+	b, err := h.BalancingConfig.NewBalancer(h.urls, h.Log)
+	if err != nil {
+		return err
+	}
+	h.balancer = b
+
+	r, err := h.RetryConfig.NewRetrier(h.Log)
+	if err != nil {
+		return err
+	}
+	h.retrier = r
+
 	ctx := context.Background()
 	client, err := h.HTTPClientConfig.CreateClient(ctx, h.Log)
 	if err != nil {
@@ -105,6 +170,47 @@ func (h *HTTP) Close() error {
 }
 
 func (h *HTTP) Write(metrics []telegraf.Metric) error {
+	now := time.Now()
+	if h.retryAfter.After(now) {
+		return fmt.Errorf("server requested to retry after %s", h.retryAfter.Format(time.RFC3339))
+	}
+	if h.BreakerThreshold > 0 && h.breakerOpen.After(now) {
+		return fmt.Errorf("circuit breaker open after %d consecutive failures, pausing writes until %s",
+			h.failures, h.breakerOpen.Format(time.RFC3339))
+	}
+
+	err := h.retrier.Do(context.Background(), func() error {
+		return h.write(metrics)
+	})
+	if err == nil {
+		h.failures = 0
+		return nil
+	}
+
+	h.failures++
+	if h.BreakerThreshold > 0 && h.failures >= h.BreakerThreshold {
+		h.breakerOpen = time.Now().Add(time.Duration(h.BreakerTimeout))
+		h.Log.Warnf("Opening circuit breaker after %d consecutive failures, pausing writes for %s",
+			h.failures, time.Duration(h.BreakerTimeout))
+	}
+	return err
+}
+
+func (h *HTTP) write(metrics []telegraf.Metric) error {
+	if len(h.urls) == 1 {
+		return h.writeTo(h.urls[0], metrics)
+	}
+
+	return h.balancer.Write(metrics, func(idx int, batch []telegraf.Metric) error {
+		err := h.writeTo(h.urls[idx], batch)
+		if err != nil {
+			h.Log.Errorf("When writing to [%s]: %v", h.urls[idx], err)
+		}
+		return err
+	})
+}
+
+func (h *HTTP) writeTo(url string, metrics []telegraf.Metric) error {
 	var reqBody []byte
 
 	if h.UseBatchFormat {
@@ -114,7 +220,7 @@ func (h *HTTP) Write(metrics []telegraf.Metric) error {
 			return err
 		}
 
-		return h.writeMetric(reqBody)
+		return h.writeMetric(url, reqBody)
 	}
 
 	for _, metric := range metrics {
@@ -124,14 +230,14 @@ func (h *HTTP) Write(metrics []telegraf.Metric) error {
 			return err
 		}
 
-		if err := h.writeMetric(reqBody); err != nil {
+		if err := h.writeMetric(url, reqBody); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (h *HTTP) writeMetric(reqBody []byte) error {
+func (h *HTTP) writeMetric(url string, reqBody []byte) error {
 	var reqBodyBuffer io.Reader = bytes.NewBuffer(reqBody)
 
 	var err error
@@ -142,23 +248,27 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 	}
 
 	var payloadHash *string
-	if h.awsCfg != nil {
-		// We need a local copy of the full buffer, the signature scheme requires a sha256 of the request body.
+	var payload []byte
+	if h.awsCfg != nil || h.hmacHash != nil {
+		// We need a local copy of the full buffer, the signature schemes
+		// require a hash of the request body.
 		buf := new(bytes.Buffer)
 		_, err = io.Copy(buf, reqBodyBuffer)
 		if err != nil {
 			return err
 		}
-
-		sum := sha256.Sum256(buf.Bytes())
+		payload = buf.Bytes()
 		reqBodyBuffer = buf
+	}
+	if h.awsCfg != nil {
+		sum := sha256.Sum256(payload)
 
 		// sha256 is hex encoded
 		hash := fmt.Sprintf("%x", sum)
 		payloadHash = &hash
 	}
 
-	req, err := http.NewRequest(h.Method, h.URL, reqBodyBuffer)
+	req, err := http.NewRequest(h.Method, url, reqBodyBuffer)
 	if err != nil {
 		return err
 	}
@@ -195,7 +305,7 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 
 	// google api auth
 	if h.CredentialsFile != "" {
-		token, err := h.getAccessToken(context.Background(), h.URL)
+		token, err := h.getAccessToken(context.Background(), url)
 		if err != nil {
 			return err
 		}
@@ -214,6 +324,12 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 		req.Header.Set(k, v)
 	}
 
+	if h.hmacHash != nil {
+		if err := h.signHMAC(req, payload); err != nil {
+			return err
+		}
+	}
+
 	resp, err := h.client.Do(req)
 	if err != nil {
 		return err
@@ -234,30 +350,103 @@ func (h *HTTP) writeMetric(reqBody []byte) error {
 			errorLine = scanner.Text()
 		}
 
-		return fmt.Errorf("when writing to [%s] received status code: %d. body: %s", h.URL, resp.StatusCode, errorLine)
+		err := fmt.Errorf("when writing to [%s] received status code: %d. body: %s", url, resp.StatusCode, errorLine)
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+			delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if ok && h.MaxRetryAfter > 0 && delay > time.Duration(h.MaxRetryAfter) {
+				delay = time.Duration(h.MaxRetryAfter)
+			}
+			if ok && h.HonorRetryAfter {
+				h.retryAfter = time.Now().Add(delay)
+				h.Log.Debugf("Server requested to retry after %s", delay)
+			}
+			return retry.NewThrottledError(err, delay)
+		case resp.StatusCode == http.StatusRequestTimeout:
+			// The request might succeed on retry
+		case h.DropClientErrors && resp.StatusCode >= 400 && resp.StatusCode < 500:
+			h.Log.Errorf("Received client error status %v, metrics are lost: %s", resp.StatusCode, errorLine)
+			return nil
+		case resp.StatusCode >= 400 && resp.StatusCode < 500:
+			return retry.NewPermanentError(err)
+		}
+
+		return err
 	}
 
 	_, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("when writing to [%s] received error: %w", h.URL, err)
+		return fmt.Errorf("when writing to [%s] received error: %w", url, err)
 	}
 
 	return nil
 }
 
+// signHMAC adds the HMAC signature of the request body to the request. If a
+// timestamp header is configured, the signed message is the timestamp
+// followed by a dot and the body to protect against replay attacks.
+func (h *HTTP) signHMAC(req *http.Request, payload []byte) error {
+	secret, err := h.HMACSecret.Get()
+	if err != nil {
+		return fmt.Errorf("getting HMAC secret failed: %w", err)
+	}
+	mac := hmac.New(h.hmacHash, secret)
+	config.ReleaseSecret(secret)
+
+	if h.HMACTimestampHeader != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(h.HMACTimestampHeader, timestamp)
+		mac.Write([]byte(timestamp + "."))
+	}
+	mac.Write(payload)
+	req.Header.Set(h.HMACHeader, h.HMACPrefix+hex.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header containing either
+// the delay in seconds or an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseUint(value, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if delay := t.Sub(now); delay > 0 {
+			return delay, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
 func init() {
 	outputs.Add("http", func() telegraf.Output {
 		return &HTTP{
 			Method:         defaultMethod,
 			URL:            defaultURL,
 			UseBatchFormat: defaultUseBatchFormat,
+			MaxRetryAfter:  config.Duration(10 * time.Minute),
+			BreakerTimeout: config.Duration(30 * time.Second),
+			BalancingConfig: balancer.BalancingConfig{
+				FailureThreshold: 3,
+				EjectionTime:     config.Duration(30 * time.Second),
+			},
+			RetryConfig: retry.RetryConfig{
+				InitialInterval: config.Duration(500 * time.Millisecond),
+				MaxInterval:     config.Duration(10 * time.Second),
+				Multiplier:      2,
+				Jitter:          0.5,
+				Budget:          0.2,
+			},
 		}
 	})
 }
 
 func (h *HTTP) getAccessToken(ctx context.Context, audience string) (*oauth2.Token, error) {
-	if h.oauth2Token.Valid() {
-		return h.oauth2Token, nil
+	if token := h.oauth2Tokens[audience]; token.Valid() {
+		return token, nil
 	}
 
 	ts, err := idtoken.NewTokenSource(ctx, audience, idtoken.WithCredentialsFile(h.CredentialsFile))
@@ -270,7 +459,10 @@ func (h *HTTP) getAccessToken(ctx context.Context, audience string) (*oauth2.Tok
 		return nil, fmt.Errorf("error fetching oauth2 token: %w", err)
 	}
 
-	h.oauth2Token = token
+	if h.oauth2Tokens == nil {
+		h.oauth2Tokens = make(map[string]*oauth2.Token)
+	}
+	h.oauth2Tokens[audience] = token
 
 	return token, nil
 }
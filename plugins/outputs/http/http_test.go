@@ -2,12 +2,16 @@ package http
 
 import (
 	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,8 +22,10 @@ import (
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
 	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
 	"github.com/influxdata/telegraf/plugins/common/oauth"
+	"github.com/influxdata/telegraf/plugins/common/retry"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/plugins/serializers/json"
@@ -752,3 +758,236 @@ func TestAwsCredentials(t *testing.T) {
 		})
 	}
 }
+
+func TestDropClientErrors(t *testing.T) {
+	var status atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:              ts.URL,
+		DropClientErrors: true,
+		Log:              testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	// Client errors are dropped except for timeouts and rate limiting
+	status.Store(http.StatusBadRequest)
+	require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	status.Store(http.StatusRequestTimeout)
+	require.Error(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	status.Store(http.StatusTooManyRequests)
+	require.Error(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	status.Store(http.StatusInternalServerError)
+	require.Error(t, plugin.Write([]telegraf.Metric{getMetric()}))
+}
+
+func TestRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:             ts.URL,
+		HonorRetryAfter: true,
+		MaxRetryAfter:   config.Duration(time.Minute),
+		Log:             testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{getMetric()}), "status code: 429")
+	require.WithinDuration(t, time.Now().Add(time.Minute), plugin.retryAfter, 5*time.Second)
+
+	// No request must be sent before the retry time
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{getMetric()}), "server requested to retry after")
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 10, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("30", now)
+	require.True(t, ok)
+	require.Equal(t, 30*time.Second, delay)
+
+	delay, ok = parseRetryAfter("Thu, 01 Jun 2023 10:02:00 GMT", now)
+	require.True(t, ok)
+	require.Equal(t, 2*time.Minute, delay)
+
+	_, ok = parseRetryAfter("Thu, 01 Jun 2023 09:00:00 GMT", now)
+	require.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	require.False(t, ok)
+	_, ok = parseRetryAfter("", now)
+	require.False(t, ok)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusInternalServerError)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:              ts.URL,
+		BreakerThreshold: 2,
+		BreakerTimeout:   config.Duration(time.Hour),
+		Log:              testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{getMetric()}), "status code: 500")
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{getMetric()}), "status code: 500")
+
+	// The breaker is open now and no requests are sent
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{getMetric()}), "circuit breaker open")
+	require.Equal(t, int32(2), requests.Load())
+
+	// After the timeout a trial request is sent closing the breaker on success
+	plugin.breakerOpen = time.Now().Add(-time.Second)
+	status.Store(http.StatusOK)
+	require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	require.Equal(t, int32(3), requests.Load())
+	require.Zero(t, plugin.failures)
+}
+
+func TestRetryWithinFlush(t *testing.T) {
+	var requests atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusBadGateway)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first two attempts of each write
+		if requests.Add(1)%3 != 0 {
+			w.WriteHeader(int(status.Load()))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL: ts.URL,
+		RetryConfig: retry.RetryConfig{
+			MaxAttempts:     3,
+			InitialInterval: config.Duration(time.Millisecond),
+			MaxInterval:     config.Duration(10 * time.Millisecond),
+			Multiplier:      2,
+		},
+		Log: testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	require.Equal(t, int32(3), requests.Load())
+
+	// Client errors are not retried
+	requests.Store(0)
+	status.Store(http.StatusBadRequest)
+	require.ErrorContains(t, plugin.Write([]telegraf.Metric{getMetric()}), "status code: 400")
+	require.Equal(t, int32(1), requests.Load())
+}
+
+func TestMultipleURLs(t *testing.T) {
+	var first, second atomic.Int32
+	ts1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts1.Close()
+	ts2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		second.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts2.Close()
+
+	plugin := &HTTP{
+		URLs: []string{ts1.URL, ts2.URL},
+		BalancingConfig: balancer.BalancingConfig{
+			LoadBalancing:    "round_robin",
+			FailureThreshold: 2,
+			EjectionTime:     config.Duration(time.Hour),
+		},
+		Log: testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+
+	// Failed writes are retried on the next URL until the unavailable server
+	// is ejected after two consecutive failures
+	for i := 0; i < 4; i++ {
+		require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+	}
+	require.Equal(t, int32(2), first.Load())
+	require.Equal(t, int32(4), second.Load())
+
+	// Writes fail if no URL accepts the metrics
+	ts2.Close()
+	require.Error(t, plugin.Write([]telegraf.Metric{getMetric()}))
+}
+
+func TestHMACSignature(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		timestamp := r.Header.Get("X-Timestamp")
+		require.NotEmpty(t, timestamp)
+
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(timestamp + "."))
+		mac.Write(body)
+		expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if r.Header.Get("X-Hub-Signature-256") != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &HTTP{
+		URL:                 ts.URL,
+		ContentEncoding:     "gzip",
+		HMACSecret:          config.NewSecret([]byte("secret")),
+		HMACHeader:          "X-Hub-Signature-256",
+		HMACPrefix:          "sha256=",
+		HMACTimestampHeader: "X-Timestamp",
+		Log:                 testutil.Logger{},
+	}
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Connect())
+	require.NoError(t, plugin.Write([]telegraf.Metric{getMetric()}))
+
+	plugin = &HTTP{
+		URL:           ts.URL,
+		HMACSecret:    config.NewSecret([]byte("secret")),
+		HMACAlgorithm: "md5",
+	}
+	require.ErrorContains(t, plugin.Connect(), "invalid hmac_algorithm")
+}
@@ -0,0 +1,75 @@
+package influxdb_v2
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/apache/arrow/go/v13/arrow/flight/flightsql"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/influxdata/telegraf/config"
+)
+
+// checkFlightHealth checks the Arrow Flight SQL endpoint of an InfluxDB v3
+// server by planning a trivial query on the configured database. InfluxDB v3
+// serves Flight on the same port as the HTTP API.
+func (i *InfluxDB) checkFlightHealth(address *url.URL) error {
+	host := address.Host
+	if address.Port() == "" {
+		port := "80"
+		if address.Scheme == "https" {
+			port = "443"
+		}
+		host = net.JoinHostPort(address.Hostname(), port)
+	}
+
+	var creds credentials.TransportCredentials
+	if address.Scheme == "https" {
+		tlsConfig, err := i.ClientConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	} else {
+		creds = insecure.NewCredentials()
+	}
+
+	client, err := flightsql.NewClient(host, nil, nil, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("creating flight client failed: %w", err)
+	}
+	defer client.Close()
+
+	timeout := time.Duration(i.Timeout)
+	if timeout == 0 {
+		timeout = defaultRequestTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	md := metadata.Pairs("database", databaseName(i.Bucket))
+	if !i.Token.Empty() {
+		token, err := i.Token.Get()
+		if err != nil {
+			return fmt.Errorf("getting token failed: %w", err)
+		}
+		md.Set("authorization", "Bearer "+string(token))
+		config.ReleaseSecret(token)
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	if _, err := client.Execute(ctx, "SELECT 1"); err != nil {
+		return fmt.Errorf("executing flight query failed: %w", err)
+	}
+	return nil
+}
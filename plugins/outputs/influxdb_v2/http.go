@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -56,6 +57,9 @@ type HTTPConfig struct {
 	PingTimeout      config.Duration
 	ReadIdleTimeout  config.Duration
 	TLSConfig        *tls.Config
+	APIVersion       string
+	AcceptPartial    bool
+	NoSync           bool
 
 	Serializer *influx.Serializer
 	Log        telegraf.Logger
@@ -69,6 +73,9 @@ type httpClient struct {
 	Bucket           string
 	BucketTag        string
 	ExcludeBucketTag bool
+	APIVersion       string
+	AcceptPartial    bool
+	NoSync           bool
 
 	client     *http.Client
 	serializer *influx.Serializer
@@ -100,7 +107,11 @@ func NewHTTPClient(cfg *HTTPConfig) (*httpClient, error) {
 	if err != nil {
 		return nil, fmt.Errorf("getting token failed: %w", err)
 	}
-	headers["Authorization"] = "Token " + string(token)
+	if cfg.APIVersion == "v3" {
+		headers["Authorization"] = "Bearer " + string(token)
+	} else {
+		headers["Authorization"] = "Token " + string(token)
+	}
 	config.ReleaseSecret(token)
 
 	for k, v := range cfg.Headers {
@@ -164,6 +175,9 @@ func NewHTTPClient(cfg *HTTPConfig) (*httpClient, error) {
 		Bucket:           cfg.Bucket,
 		BucketTag:        cfg.BucketTag,
 		ExcludeBucketTag: cfg.ExcludeBucketTag,
+		APIVersion:       cfg.APIVersion,
+		AcceptPartial:    cfg.AcceptPartial,
+		NoSync:           cfg.NoSync,
 		log:              cfg.Log,
 	}
 	return client, nil
@@ -179,9 +193,27 @@ type genericRespError struct {
 	Message   string
 	Line      *int32
 	MaxLength *int32
+
+	// Error response of the InfluxDB v3 API
+	Err  string        `json:"error"`
+	Data []v3LineError `json:"data"`
+}
+
+type v3LineError struct {
+	OriginalLine string `json:"original_line"`
+	LineNumber   int    `json:"line_number"`
+	ErrorMessage string `json:"error_message"`
 }
 
 func (g genericRespError) Error() string {
+	if g.Code == "" && g.Err != "" {
+		errString := g.Err
+		for _, lineErr := range g.Data {
+			errString += fmt.Sprintf("; line[%d]: %s", lineErr.LineNumber, lineErr.ErrorMessage)
+		}
+		return errString
+	}
+
 	errString := fmt.Sprintf("%s: %s", g.Code, g.Message)
 	if g.Line != nil {
 		return fmt.Sprintf("%s - line[%d]", errString, g.Line)
@@ -259,7 +291,13 @@ func (c *httpClient) splitAndWriteBatch(ctx context.Context, bucket string, metr
 }
 
 func (c *httpClient) writeBatch(ctx context.Context, bucket string, metrics []telegraf.Metric) error {
-	loc, err := makeWriteURL(*c.url, c.Organization, bucket)
+	var loc string
+	var err error
+	if c.APIVersion == "v3" {
+		loc, err = makeWriteURLv3(*c.url, databaseName(bucket), c.AcceptPartial, c.NoSync)
+	} else {
+		loc, err = makeWriteURL(*c.url, c.Organization, bucket)
+	}
 	if err != nil {
 		return err
 	}
@@ -434,6 +472,38 @@ func makeWriteURL(loc url.URL, org, bucket string) (string, error) {
 	return loc.String(), nil
 }
 
+// makeWriteURLv3 returns the URL of the InfluxDB v3 line-protocol write
+// endpoint for the given database
+func makeWriteURLv3(loc url.URL, database string, acceptPartial, noSync bool) (string, error) {
+	params := url.Values{}
+	params.Set("db", database)
+	params.Set("precision", "nanosecond")
+	params.Set("accept_partial", strconv.FormatBool(acceptPartial))
+	if noSync {
+		params.Set("no_sync", "true")
+	}
+
+	switch loc.Scheme {
+	case "unix":
+		loc.Scheme = "http"
+		loc.Host = "127.0.0.1"
+		loc.Path = "/api/v3/write_lp"
+	case "http", "https":
+		loc.Path = path.Join(loc.Path, "/api/v3/write_lp")
+	default:
+		return "", fmt.Errorf("unsupported scheme: %q", loc.Scheme)
+	}
+	loc.RawQuery = params.Encode()
+	return loc.String(), nil
+}
+
+// databaseName strips the retention-policy part of v1 style "db/rp" bucket
+// names as InfluxDB v3 has no retention policies.
+func databaseName(bucket string) string {
+	db, _, _ := strings.Cut(bucket, "/")
+	return db
+}
+
 func (c *httpClient) Close() {
 	c.client.CloseIdleConnections()
 }
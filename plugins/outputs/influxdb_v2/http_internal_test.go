@@ -98,3 +98,21 @@ func TestExponentialBackoffCalculationWithRetryAfter(t *testing.T) {
 		})
 	}
 }
+
+func TestMakeWriteURLv3(t *testing.T) {
+	rURL, err := makeWriteURLv3(*genURL("http://localhost:8181"), "telegraf", true, false)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8181/api/v3/write_lp?accept_partial=true&db=telegraf&precision=nanosecond", rURL)
+
+	rURL, err = makeWriteURLv3(*genURL("unix://var/run/influxdb3.sock"), "telegraf", false, true)
+	require.NoError(t, err)
+	require.Equal(t, "http://127.0.0.1/api/v3/write_lp?accept_partial=false&db=telegraf&no_sync=true&precision=nanosecond", rURL)
+
+	_, err = makeWriteURLv3(*genURL("udp://localhost:8181"), "telegraf", true, false)
+	require.Error(t, err)
+}
+
+func TestDatabaseName(t *testing.T) {
+	require.Equal(t, "telegraf", databaseName("telegraf"))
+	require.Equal(t, "telegraf", databaseName("telegraf/autogen"))
+}
@@ -280,3 +280,56 @@ func TestTooLargeWriteRetry(t *testing.T) {
 	err = client.Write(ctx, hugeMetrics)
 	require.Error(t, err)
 }
+
+func TestWriteV3(t *testing.T) {
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v3/write_lp" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			require.Equal(t, "Bearer sometoken", r.Header.Get("Authorization"))
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, []string{"foobar"}, r.Form["db"])
+			require.Equal(t, []string{"nanosecond"}, r.Form["precision"])
+			require.Equal(t, []string{"true"}, r.Form["accept_partial"])
+			require.Empty(t, r.Form["org"])
+
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			require.Contains(t, string(body), "cpu value=42.123")
+
+			// Partial writes are rejected with a bad-request status
+			w.WriteHeader(http.StatusBadRequest)
+			_, err = w.Write([]byte(`{"error":"partial write of line protocol occurred","data":[{"original_line":"cpu value=","line_number":2,"error_message":"invalid field value"}]}`))
+			require.NoError(t, err)
+		}),
+	)
+	defer ts.Close()
+
+	cfg := &influxdb.HTTPConfig{
+		URL:           genURL(ts.URL),
+		Token:         config.NewSecret([]byte("sometoken")),
+		Bucket:        "foobar/autogen",
+		APIVersion:    "v3",
+		AcceptPartial: true,
+		Log:           testutil.Logger{},
+	}
+
+	client, err := influxdb.NewHTTPClient(cfg)
+	require.NoError(t, err)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"value": 42.123,
+			},
+			time.Unix(0, 0),
+		),
+	}
+
+	// Rejected lines are dropped and not retried
+	require.NoError(t, client.Write(context.Background(), metrics))
+}
@@ -6,12 +6,14 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
-	"math/rand"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
@@ -48,11 +50,17 @@ type InfluxDB struct {
 	UintSupport      bool              `toml:"influx_uint_support"`
 	PingTimeout      config.Duration   `toml:"ping_timeout"`
 	ReadIdleTimeout  config.Duration   `toml:"read_idle_timeout"`
+	APIVersion       string            `toml:"api_version"`
+	AcceptPartial    bool              `toml:"accept_partial"`
+	NoSync           bool              `toml:"no_sync"`
+	FlightHealth     bool              `toml:"flight_health_check"`
 	tls.ClientConfig
+	balancer.BalancingConfig
 
 	Log telegraf.Logger `toml:"-"`
 
-	clients []Client
+	clients  []Client
+	balancer *balancer.Balancer
 }
 
 func (*InfluxDB) SampleConfig() string {
@@ -64,6 +72,24 @@ func (i *InfluxDB) Connect() error {
 		i.URLs = append(i.URLs, defaultURL)
 	}
 
+	switch i.APIVersion {
+	case "":
+		i.APIVersion = "v2"
+	case "v2":
+	case "v3":
+		if strings.Contains(i.Bucket, "/") {
+			i.Log.Warnf("InfluxDB v3 has no retention policies, writing to database %q", databaseName(i.Bucket))
+		}
+	default:
+		return fmt.Errorf("invalid api_version %q", i.APIVersion)
+	}
+	if i.APIVersion != "v3" && (i.NoSync || i.FlightHealth) {
+		return errors.New("no_sync and flight_health_check require api_version v3")
+	}
+	if i.FlightHealth && i.Bucket == "" {
+		return errors.New("flight_health_check requires a bucket")
+	}
+
 	for _, u := range i.URLs {
 		parts, err := url.Parse(u)
 		if err != nil {
@@ -85,12 +111,24 @@ func (i *InfluxDB) Connect() error {
 				return err
 			}
 
+			if i.FlightHealth && parts.Scheme != "unix" {
+				if err := i.checkFlightHealth(parts); err != nil {
+					return fmt.Errorf("health check of %q failed: %w", u, err)
+				}
+			}
+
 			i.clients = append(i.clients, c)
 		default:
 			return fmt.Errorf("unsupported scheme [%q]: %q", u, parts.Scheme)
 		}
 	}
 
+	b, err := i.BalancingConfig.NewBalancer(i.URLs, i.Log)
+	if err != nil {
+		return err
+	}
+	i.balancer = b
+
 	return nil
 }
 
@@ -101,24 +139,29 @@ func (i *InfluxDB) Close() error {
 	return nil
 }
 
-// Write sends metrics to one of the configured servers, logging each
-// unsuccessful. If all servers fail, return an error.
+// Write sends metrics to the configured servers selected by the load
+// balancing strategy, logging each unsuccessful. If all servers fail, return
+// an error.
 func (i *InfluxDB) Write(metrics []telegraf.Metric) error {
 	ctx := context.Background()
 
-	var err error
-	p := rand.Perm(len(i.clients))
-	for _, n := range p {
-		client := i.clients[n]
-		err = client.Write(ctx, metrics)
-		if err == nil {
-			return nil
+	err := i.balancer.Write(metrics, func(idx int, batch []telegraf.Metric) error {
+		client := i.clients[idx]
+		if err := client.Write(ctx, batch); err != nil {
+			i.Log.Errorf("When writing to [%s]: %v", client.URL(), err)
+			return err
 		}
-
-		i.Log.Errorf("When writing to [%s]: %v", client.URL(), err)
+		return nil
+	})
+	if err != nil {
+		// Only retry the metrics not written to any server
+		var partial *internal.PartialWriteError
+		if errors.As(err, &partial) {
+			return err
+		}
+		return fmt.Errorf("failed to send metrics to any configured server(s)")
 	}
-
-	return fmt.Errorf("failed to send metrics to any configured server(s)")
+	return nil
 }
 
 func (i *InfluxDB) getHTTPClient(address *url.URL, proxy *url.URL) (Client, error) {
@@ -148,6 +191,9 @@ func (i *InfluxDB) getHTTPClient(address *url.URL, proxy *url.URL) (Client, erro
 		Serializer:       serializer,
 		PingTimeout:      i.PingTimeout,
 		ReadIdleTimeout:  i.ReadIdleTimeout,
+		APIVersion:       i.APIVersion,
+		AcceptPartial:    i.AcceptPartial,
+		NoSync:           i.NoSync,
 		Log:              i.Log,
 	}
 
@@ -164,6 +210,11 @@ func init() {
 		return &InfluxDB{
 			Timeout:         config.Duration(time.Second * 5),
 			ContentEncoding: "gzip",
+			AcceptPartial:   true,
+			BalancingConfig: balancer.BalancingConfig{
+				FailureThreshold: 3,
+				EjectionTime:     config.Duration(30 * time.Second),
+			},
 		}
 	})
 }
@@ -1,12 +1,26 @@
 package influxdb_v2_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/apache/arrow/go/v13/arrow/flight"
+	"github.com/apache/arrow/go/v13/arrow/flight/flightsql"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/common/balancer"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	influxdb "github.com/influxdata/telegraf/plugins/outputs/influxdb_v2"
-	"github.com/stretchr/testify/require"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func TestDefaultURL(t *testing.T) {
@@ -81,6 +95,35 @@ func TestConnect(t *testing.T) {
 				},
 			},
 		},
+		{
+			out: influxdb.InfluxDB{
+				URLs:       []string{"http://localhost:8181"},
+				APIVersion: "v3",
+				NoSync:     true,
+			},
+		},
+		{
+			err: true,
+			out: influxdb.InfluxDB{
+				URLs:       []string{"http://localhost:8181"},
+				APIVersion: "v4",
+			},
+		},
+		{
+			err: true,
+			out: influxdb.InfluxDB{
+				URLs:   []string{"http://localhost:8086"},
+				NoSync: true,
+			},
+		},
+		{
+			err: true,
+			out: influxdb.InfluxDB{
+				URLs:         []string{"http://localhost:8181"},
+				APIVersion:   "v3",
+				FlightHealth: true,
+			},
+		},
 	}
 
 	for i := range tests {
@@ -100,3 +143,111 @@ func TestUnused(_ *testing.T) {
 	thing.SampleConfig()
 	outputs.Outputs["influxdb_v2"]()
 }
+
+type flightSQLServer struct {
+	flightsql.BaseServer
+	database string
+	token    string
+}
+
+func (s *flightSQLServer) GetFlightInfoStatement(
+	ctx context.Context,
+	_ flightsql.StatementQuery,
+	desc *flight.FlightDescriptor,
+) (*flight.FlightInfo, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get("authorization"); len(v) != 1 || v[0] != "Bearer "+s.token {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	if v := md.Get("database"); len(v) != 1 || v[0] != s.database {
+		return nil, status.Error(codes.NotFound, "database not found")
+	}
+	return &flight.FlightInfo{FlightDescriptor: desc}, nil
+}
+
+func TestConnectFlightHealthCheck(t *testing.T) {
+	srv := flight.NewServerWithMiddleware(nil)
+	srv.RegisterFlightService(flightsql.NewFlightServer(&flightSQLServer{database: "telegraf", token: "sometoken"}))
+	require.NoError(t, srv.Init("127.0.0.1:0"))
+	go func() {
+		_ = srv.Serve()
+	}()
+	defer srv.Shutdown()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:         []string{"http://" + srv.Addr().String()},
+		Token:        config.NewSecret([]byte("sometoken")),
+		Bucket:       "telegraf",
+		APIVersion:   "v3",
+		FlightHealth: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+	plugin.Close()
+
+	plugin = &influxdb.InfluxDB{
+		URLs:         []string{"http://" + srv.Addr().String()},
+		Token:        config.NewSecret([]byte("sometoken")),
+		Bucket:       "unknown",
+		APIVersion:   "v3",
+		FlightHealth: true,
+		Log:          testutil.Logger{},
+	}
+	require.ErrorContains(t, plugin.Connect(), "database not found")
+}
+
+func TestConnectV3Write(t *testing.T) {
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:       []string{ts.URL},
+		Bucket:     "telegraf",
+		APIVersion: "v3",
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	require.NoError(t, plugin.Write(testutil.MockMetrics()))
+	require.Equal(t, "/api/v3/write_lp", path)
+}
+
+func TestWriteLoadBalancing(t *testing.T) {
+	var healthy, failing atomic.Int32
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		healthy.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer up.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		failing.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	plugin := &influxdb.InfluxDB{
+		URLs:   []string{down.URL, up.URL},
+		Bucket: "telegraf",
+		BalancingConfig: balancer.BalancingConfig{
+			LoadBalancing:    "round_robin",
+			FailureThreshold: 1,
+			EjectionTime:     config.Duration(time.Hour),
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// The first write fails over to the healthy server and ejects the failing
+	// one, so it is not used for the following writes.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, plugin.Write(testutil.MockMetrics()))
+	}
+	require.Equal(t, int32(1), failing.Load())
+	require.Equal(t, int32(3), healthy.Load())
+}
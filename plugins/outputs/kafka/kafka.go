@@ -143,6 +143,9 @@ func (k *Kafka) Init() error {
 	}
 
 	if k.Socks5ProxyEnabled {
+		if k.UseProxy {
+			return errors.New("socks5_enabled cannot be used together with use_proxy")
+		}
 		config.Net.Proxy.Enable = true
 
 		dialer, err := k.Socks5ProxyConfig.GetDialer()
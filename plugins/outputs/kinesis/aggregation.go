@@ -0,0 +1,146 @@
+package kinesis
+
+import (
+	"crypto/md5" //nolint:gosec // required by the KPL aggregation format
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/influxdata/telegraf"
+)
+
+// Magic number prefixing aggregated records, see
+// https://github.com/awslabs/amazon-kinesis-producer/blob/master/aggregation-format.md
+var aggregationMagic = []byte{0xf3, 0x89, 0x9a, 0xc2}
+
+// Protobuf field numbers of the AggregatedRecord and Record messages
+const (
+	fieldPartitionKeyTable protowire.Number = 1
+	fieldRecords           protowire.Number = 3
+	fieldPartitionKeyIndex protowire.Number = 1
+	fieldData              protowire.Number = 3
+)
+
+// aggregatedRecord collects user records sharing a single partition key in
+// the aggregation format of the Kinesis Producer Library (KPL). Consumers
+// using the Kinesis Client Library deaggregate these records transparently.
+type aggregatedRecord struct {
+	key   string
+	body  []byte
+	first []byte
+	count int
+}
+
+func newAggregatedRecord(key string) *aggregatedRecord {
+	body := protowire.AppendTag(nil, fieldPartitionKeyTable, protowire.BytesType)
+	body = protowire.AppendString(body, key)
+	return &aggregatedRecord{key: key, body: body}
+}
+
+// sizeWith returns the size of the encoded record including its partition
+// key after adding the given data
+func (a *aggregatedRecord) sizeWith(data []byte) int {
+	inner := recordMessageSize(data)
+	added := protowire.SizeTag(fieldRecords) + protowire.SizeBytes(inner)
+	return len(aggregationMagic) + len(a.body) + added + md5.Size + len(a.key)
+}
+
+func (a *aggregatedRecord) add(data []byte) {
+	a.body = protowire.AppendTag(a.body, fieldRecords, protowire.BytesType)
+	a.body = protowire.AppendVarint(a.body, uint64(recordMessageSize(data)))
+	a.body = protowire.AppendTag(a.body, fieldPartitionKeyIndex, protowire.VarintType)
+	a.body = protowire.AppendVarint(a.body, 0)
+	a.body = protowire.AppendTag(a.body, fieldData, protowire.BytesType)
+	a.body = protowire.AppendBytes(a.body, data)
+
+	if a.count == 0 {
+		a.first = data
+	}
+	a.count++
+}
+
+// entry returns the request entry for the collected records. A single record
+// is sent as-is as aggregation would only add overhead.
+func (a *aggregatedRecord) entry() types.PutRecordsRequestEntry {
+	if a.count == 1 {
+		return types.PutRecordsRequestEntry{
+			Data:         a.first,
+			PartitionKey: aws.String(a.key),
+		}
+	}
+
+	sum := md5.Sum(a.body) //nolint:gosec // required by the KPL aggregation format
+	data := make([]byte, 0, len(aggregationMagic)+len(a.body)+len(sum))
+	data = append(data, aggregationMagic...)
+	data = append(data, a.body...)
+	data = append(data, sum[:]...)
+
+	return types.PutRecordsRequestEntry{
+		Data:         data,
+		PartitionKey: aws.String(a.key),
+	}
+}
+
+func recordMessageSize(data []byte) int {
+	return protowire.SizeTag(fieldPartitionKeyIndex) + protowire.SizeVarint(0) +
+		protowire.SizeTag(fieldData) + protowire.SizeBytes(len(data))
+}
+
+// aggregate serializes the metrics and packs them into aggregated records of
+// at most the configured size per partition key. The order of the metrics
+// is kept within each partition key.
+func (k *KinesisOutput) aggregate(metrics []telegraf.Metric) []types.PutRecordsRequestEntry {
+	// Random partition keys would result in one record per metric, so
+	// a random key is chosen for each aggregated record instead.
+	random := k.randomPartition()
+
+	var order []string
+	groups := make(map[string][][]byte)
+	for _, metric := range metrics {
+		data, err := k.serializer.Serialize(metric)
+		if err != nil {
+			k.Log.Debugf("Could not serialize metric: %v", err)
+			continue
+		}
+
+		var key string
+		if !random {
+			key = k.getPartitionKey(metric)
+		}
+		if _, found := groups[key]; !found {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], data)
+	}
+
+	newRecord := func(key string) *aggregatedRecord {
+		if random {
+			key = k.randomKey()
+		}
+		return newAggregatedRecord(key)
+	}
+
+	entries := make([]types.PutRecordsRequestEntry, 0, len(order))
+	for _, key := range order {
+		record := newRecord(key)
+		for _, data := range groups[key] {
+			if record.count > 0 && record.sizeWith(data) > int(k.AggregateMaxSize) {
+				entries = append(entries, record.entry())
+				record = newRecord(key)
+			}
+			record.add(data)
+		}
+		entries = append(entries, record.entry())
+	}
+
+	return entries
+}
+
+// randomPartition returns true if random partition keys are configured
+func (k *KinesisOutput) randomPartition() bool {
+	if k.Partition != nil {
+		return k.Partition.Method == "random"
+	}
+	return k.RandomPartitionKey
+}
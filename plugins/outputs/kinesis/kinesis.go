@@ -2,8 +2,12 @@
 package kinesis
 
 import (
+	"bytes"
 	"context"
 	_ "embed"
+	"errors"
+	"fmt"
+	"text/template"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,6 +16,7 @@ import (
 	"github.com/gofrs/uuid/v5"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -20,20 +25,27 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
-// Limit set by AWS (https://docs.aws.amazon.com/kinesis/latest/APIReference/API_PutRecords.html)
-const maxRecordsPerRequest uint32 = 500
+// Limits set by AWS (https://docs.aws.amazon.com/kinesis/latest/APIReference/API_PutRecords.html)
+const (
+	maxRecordsPerRequest = 500
+	maxRequestSize       = 5 * 1024 * 1024
+	maxRecordSize        = 1024 * 1024
+)
 
 type (
 	KinesisOutput struct {
-		StreamName         string     `toml:"streamname"`
-		PartitionKey       string     `toml:"partitionkey" deprecated:"1.5.0;use 'partition.key' instead"`
-		RandomPartitionKey bool       `toml:"use_random_partitionkey" deprecated:"1.5.0;use 'partition.method' instead"`
-		Partition          *Partition `toml:"partition"`
-		Debug              bool       `toml:"debug"`
-
-		Log        telegraf.Logger `toml:"-"`
-		serializer serializers.Serializer
-		svc        kinesisClient
+		StreamName         string      `toml:"streamname"`
+		PartitionKey       string      `toml:"partitionkey" deprecated:"1.5.0;use 'partition.key' instead"`
+		RandomPartitionKey bool        `toml:"use_random_partitionkey" deprecated:"1.5.0;use 'partition.method' instead"`
+		Partition          *Partition  `toml:"partition"`
+		Aggregate          bool        `toml:"aggregate"`
+		AggregateMaxSize   config.Size `toml:"aggregate_max_size"`
+		Debug              bool        `toml:"debug"`
+
+		Log          telegraf.Logger `toml:"-"`
+		serializer   serializers.Serializer
+		svc          kinesisClient
+		partitionKey *template.Template
 
 		internalaws.CredentialConfig
 	}
@@ -53,6 +65,27 @@ func (*KinesisOutput) SampleConfig() string {
 	return sampleConfig
 }
 
+func (k *KinesisOutput) Init() error {
+	if k.Partition != nil && k.Partition.Method == "template" {
+		var err error
+		k.partitionKey, err = template.New("partition").Parse(k.Partition.Key)
+		if err != nil {
+			return fmt.Errorf("parsing partition key template failed: %w", err)
+		}
+	}
+
+	if k.Aggregate {
+		if k.AggregateMaxSize <= 0 {
+			return errors.New("'aggregate_max_size' must be positive")
+		}
+		if k.AggregateMaxSize > maxRecordSize {
+			return fmt.Errorf("'aggregate_max_size' must not exceed %d bytes", maxRecordSize)
+		}
+	}
+
+	return nil
+}
+
 func (k *KinesisOutput) Connect() error {
 	if k.Partition == nil {
 		k.Log.Error("Deprecated partitionkey configuration in use, please consider using outputs.kinesis.partition")
@@ -117,11 +150,7 @@ func (k *KinesisOutput) getPartitionKey(metric telegraf.Metric) string {
 		case "static":
 			return k.Partition.Key
 		case "random":
-			u, err := uuid.NewV4()
-			if err != nil {
-				return k.Partition.Default
-			}
-			return u.String()
+			return k.randomKey()
 		case "measurement":
 			return metric.Name()
 		case "tag":
@@ -132,64 +161,106 @@ func (k *KinesisOutput) getPartitionKey(metric telegraf.Metric) string {
 			}
 			// Default partition name if default is not set
 			return "telegraf"
+		case "template":
+			if key := k.renderPartitionKey(metric); key != "" {
+				return key
+			} else if len(k.Partition.Default) > 0 {
+				return k.Partition.Default
+			}
+			return "telegraf"
 		default:
 			k.Log.Errorf("You have configured a Partition method of %q which is not supported", k.Partition.Method)
 		}
 	}
 	if k.RandomPartitionKey {
-		u, err := uuid.NewV4()
-		if err != nil {
+		return k.randomKey()
+	}
+	return k.PartitionKey
+}
+
+func (k *KinesisOutput) randomKey() string {
+	u, err := uuid.NewV4()
+	if err != nil {
+		if k.Partition != nil {
 			return k.Partition.Default
 		}
-		return u.String()
+		return ""
 	}
-	return k.PartitionKey
+	return u.String()
 }
 
-func (k *KinesisOutput) Write(metrics []telegraf.Metric) error {
-	var sz uint32
+func (k *KinesisOutput) renderPartitionKey(metric telegraf.Metric) string {
+	tm, ok := metric.(telegraf.TemplateMetric)
+	if !ok {
+		k.Log.Errorf("Metric %q is not a template metric", metric.Name())
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := k.partitionKey.Execute(&buf, tm); err != nil {
+		k.Log.Errorf("Executing partition key template failed: %v", err)
+		return ""
+	}
+	return buf.String()
+}
 
+func (k *KinesisOutput) Write(metrics []telegraf.Metric) error {
 	if len(metrics) == 0 {
 		return nil
 	}
 
-	r := []types.PutRecordsRequestEntry{}
-
-	for _, metric := range metrics {
-		sz++
+	var entries []types.PutRecordsRequestEntry
+	if k.Aggregate {
+		entries = k.aggregate(metrics)
+	} else {
+		entries = make([]types.PutRecordsRequestEntry, 0, len(metrics))
+		for _, metric := range metrics {
+			values, err := k.serializer.Serialize(metric)
+			if err != nil {
+				k.Log.Debugf("Could not serialize metric: %v", err)
+				continue
+			}
 
-		values, err := k.serializer.Serialize(metric)
-		if err != nil {
-			k.Log.Debugf("Could not serialize metric: %v", err)
-			continue
+			entries = append(entries, types.PutRecordsRequestEntry{
+				Data:         values,
+				PartitionKey: aws.String(k.getPartitionKey(metric)),
+			})
 		}
+	}
 
-		partitionKey := k.getPartitionKey(metric)
-
-		d := types.PutRecordsRequestEntry{
-			Data:         values,
-			PartitionKey: aws.String(partitionKey),
-		}
+	for _, r := range requests(entries) {
+		elapsed := k.writeKinesis(r)
+		k.Log.Debugf("Wrote a %d record batch to Kinesis in %+v.", len(r), elapsed)
+	}
 
-		r = append(r, d)
+	return nil
+}
 
-		if sz == maxRecordsPerRequest {
-			elapsed := k.writeKinesis(r)
-			k.Log.Debugf("Wrote a %d point batch to Kinesis in %+v.", sz, elapsed)
-			sz = 0
-			r = nil
+// requests splits the entries into requests obeying the record count and
+// size limits of a single PutRecords call
+func requests(entries []types.PutRecordsRequestEntry) [][]types.PutRecordsRequestEntry {
+	var batches [][]types.PutRecordsRequestEntry
+	var batch []types.PutRecordsRequestEntry
+	var size int
+	for _, entry := range entries {
+		n := len(entry.Data) + len(aws.ToString(entry.PartitionKey))
+		if len(batch) == maxRecordsPerRequest || (len(batch) > 0 && size+n > maxRequestSize) {
+			batches = append(batches, batch)
+			batch = nil
+			size = 0
 		}
+		batch = append(batch, entry)
+		size += n
 	}
-	if sz > 0 {
-		elapsed := k.writeKinesis(r)
-		k.Log.Debugf("Wrote a %d point batch to Kinesis in %+v.", sz, elapsed)
+	if len(batch) > 0 {
+		batches = append(batches, batch)
 	}
-
-	return nil
+	return batches
 }
 
 func init() {
 	outputs.Add("kinesis", func() telegraf.Output {
-		return &KinesisOutput{}
+		return &KinesisOutput{
+			AggregateMaxSize: config.Size(51200),
+		}
 	})
 }
@@ -2,16 +2,21 @@ package kinesis
 
 import (
 	"context"
+	"crypto/md5" //nolint:gosec // required by the KPL aggregation format
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis"
 	"github.com/aws/aws-sdk-go-v2/service/kinesis/types"
 	"github.com/gofrs/uuid/v5"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
@@ -106,6 +111,37 @@ func TestPartitionKey(t *testing.T) {
 	require.Equal(t, byte(4), u.Version(), "PartitionKey should be UUIDv4")
 }
 
+func TestPartitionKeyTemplate(t *testing.T) {
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method:  "template",
+			Key:     `{{.Name}}-{{.Tag "host"}}`,
+			Default: "somedefault",
+		},
+	}
+	require.NoError(t, k.Init())
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.Equal(t, "cpu-a", k.getPartitionKey(m))
+
+	k.Partition.Key = `{{.Tag "host"}}`
+	require.NoError(t, k.Init())
+	m = metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.Equal(t, "somedefault", k.getPartitionKey(m), "PartitionKey should use default")
+
+	k.Partition.Key = "{{.Tag"
+	require.ErrorContains(t, k.Init(), "parsing partition key template failed")
+}
+
+func TestInitAggregateMaxSize(t *testing.T) {
+	k := KinesisOutput{Aggregate: true}
+	require.ErrorContains(t, k.Init(), "must be positive")
+
+	k.AggregateMaxSize = 2 * 1024 * 1024
+	require.ErrorContains(t, k.Init(), "must not exceed")
+}
+
 func TestWriteKinesis_WhenSuccess(t *testing.T) {
 	records := []types.PutRecordsRequestEntry{
 		{
@@ -457,6 +493,134 @@ func TestWrite_SerializerError(t *testing.T) {
 	})
 }
 
+func TestWrite_Aggregated(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupGenericResponse(2, 0)
+
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "tag",
+			Key:    "host",
+		},
+		Aggregate:        true,
+		AggregateMaxSize: 51200,
+		StreamName:       testStreamName,
+		serializer:       serializer,
+		svc:              svc,
+	}
+	require.NoError(t, k.Init())
+
+	metrics := make([]telegraf.Metric, 0, 3)
+	expected := make(map[string][][]byte)
+	for i, host := range []string{"a", "b", "a"} {
+		m := metric.New("cpu", map[string]string{"host": host}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0))
+		data, err := serializer.Serialize(m)
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+		expected[host] = append(expected[host], data)
+	}
+	require.NoError(t, k.Write(metrics))
+
+	// The records of host "a" are aggregated, the single record of host "b"
+	// is sent as-is
+	require.Len(t, svc.requests, 1)
+	records := svc.requests[0].Records
+	require.Len(t, records, 2)
+	require.Equal(t, "a", *records[0].PartitionKey)
+	require.Equal(t, expected["a"], decodeAggregatedRecord(t, records[0].Data))
+	require.Equal(t, "b", *records[1].PartitionKey)
+	require.Equal(t, expected["b"][0], records[1].Data)
+}
+
+func TestWrite_AggregatedMaxSize(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	metrics, metricsData := createTestMetrics(t, 10, serializer)
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupGenericResponse(5, 0)
+
+	// Allow two records per aggregated record
+	single := newAggregatedRecord(testPartitionKey)
+	single.add(metricsData[0])
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "static",
+			Key:    testPartitionKey,
+		},
+		Aggregate:        true,
+		AggregateMaxSize: config.Size(single.sizeWith(metricsData[1])),
+		StreamName:       testStreamName,
+		serializer:       serializer,
+		svc:              svc,
+	}
+	require.NoError(t, k.Init())
+	require.NoError(t, k.Write(metrics))
+
+	require.Len(t, svc.requests, 1)
+	records := svc.requests[0].Records
+	require.Len(t, records, 5)
+	for i, record := range records {
+		require.Equal(t, testPartitionKey, *record.PartitionKey)
+		require.LessOrEqual(t, len(record.Data)+len(testPartitionKey), int(k.AggregateMaxSize))
+		require.Equal(t, metricsData[2*i:2*i+2], decodeAggregatedRecord(t, record.Data))
+	}
+}
+
+func TestWrite_AggregatedRandom(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	metrics, metricsData := createTestMetrics(t, 3, serializer)
+
+	svc := &mockKinesisPutRecords{}
+	svc.SetupGenericResponse(1, 0)
+
+	k := KinesisOutput{
+		Log: testutil.Logger{},
+		Partition: &Partition{
+			Method: "random",
+		},
+		Aggregate:        true,
+		AggregateMaxSize: 51200,
+		StreamName:       testStreamName,
+		serializer:       serializer,
+		svc:              svc,
+	}
+	require.NoError(t, k.Init())
+	require.NoError(t, k.Write(metrics))
+
+	require.Len(t, svc.requests, 1)
+	records := svc.requests[0].Records
+	require.Len(t, records, 1)
+	u, err := uuid.FromString(*records[0].PartitionKey)
+	require.NoError(t, err, "Issue parsing UUID")
+	require.Equal(t, byte(4), u.Version(), "PartitionKey should be UUIDv4")
+	require.Equal(t, metricsData, decodeAggregatedRecord(t, records[0].Data))
+}
+
+func TestRequestsSizeLimit(t *testing.T) {
+	data := make([]byte, maxRecordSize-len(testPartitionKey))
+	entries := make([]types.PutRecordsRequestEntry, 0, 6)
+	for i := 0; i < 6; i++ {
+		entries = append(entries, types.PutRecordsRequestEntry{
+			Data:         data,
+			PartitionKey: aws.String(testPartitionKey),
+		})
+	}
+
+	batches := requests(entries)
+	require.Len(t, batches, 2)
+	require.Len(t, batches[0], 5)
+	require.Len(t, batches[1], 1)
+}
+
 type mockKinesisPutRecordsResponse struct {
 	Output *kinesis.PutRecordsOutput
 	Err    error
@@ -616,3 +780,58 @@ func createPutRecordsRequestEntries(
 
 	return records
 }
+
+// decodeAggregatedRecord returns the data of the user records contained in
+// the given KPL aggregated record
+func decodeAggregatedRecord(t *testing.T, data []byte) [][]byte {
+	require.Greater(t, len(data), len(aggregationMagic)+md5.Size)
+	require.Equal(t, aggregationMagic, data[:len(aggregationMagic)])
+
+	body := data[len(aggregationMagic) : len(data)-md5.Size]
+	sum := md5.Sum(body) //nolint:gosec // required by the KPL aggregation format
+	require.Equal(t, sum[:], data[len(data)-md5.Size:])
+
+	var keys []string
+	var records [][]byte
+	for len(body) > 0 {
+		num, typ, n := protowire.ConsumeTag(body)
+		require.GreaterOrEqual(t, n, 0)
+		require.Equal(t, protowire.BytesType, typ)
+		body = body[n:]
+		value, n := protowire.ConsumeBytes(body)
+		require.GreaterOrEqual(t, n, 0)
+		body = body[n:]
+
+		switch num {
+		case fieldPartitionKeyTable:
+			keys = append(keys, string(value))
+		case fieldRecords:
+			var index uint64
+			var payload []byte
+			for len(value) > 0 {
+				field, ftyp, n := protowire.ConsumeTag(value)
+				require.GreaterOrEqual(t, n, 0)
+				value = value[n:]
+				switch field {
+				case fieldPartitionKeyIndex:
+					require.Equal(t, protowire.VarintType, ftyp)
+					index, n = protowire.ConsumeVarint(value)
+				case fieldData:
+					require.Equal(t, protowire.BytesType, ftyp)
+					payload, n = protowire.ConsumeBytes(value)
+				default:
+					require.Failf(t, "unexpected field", "field %d in record", field)
+				}
+				require.GreaterOrEqual(t, n, 0)
+				value = value[n:]
+			}
+			require.Less(t, index, uint64(len(keys)))
+			records = append(records, payload)
+		default:
+			require.Failf(t, "unexpected field", "field %d in aggregated record", num)
+		}
+	}
+	require.Len(t, keys, 1)
+
+	return records
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 )
@@ -124,10 +125,23 @@ func (l *Loki) Write(metrics []telegraf.Metric) error {
 			m.AddTag(l.MetricNameLabel, m.Name())
 		}
 
+		// Events use their message as log line and the severity as level
+		// label, all other fields are appended to the line
+		message, isEvent := metric.EventMessage(m)
+		if isEvent {
+			m.AddTag("level", metric.EventSeverity(m).String())
+		}
+
 		tags := m.TagList()
 		var line string
+		if isEvent {
+			line = message + " "
+		}
 
 		for _, f := range m.FieldList() {
+			if isEvent && (f.Key == telegraf.EventMessageField || f.Key == telegraf.EventSeverityField) {
+				continue
+			}
 			line += fmt.Sprintf("%s=\"%v\" ", f.Key, f.Value)
 		}
 
@@ -16,6 +16,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -470,3 +471,29 @@ func TestMetricSorting(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestEventMetric(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var s Request
+		require.NoError(t, json.Unmarshal(payload, &s))
+		require.Len(t, s.Streams, 1)
+		require.Equal(t, map[string]string{"key1": "value1", "level": "error"}, s.Streams[0].Labels)
+		require.Len(t, s.Streams[0].Logs, 1)
+		require.Equal(t, `disk failure code="5" `, s.Streams[0].Logs[0][1])
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	event := metric.NewEvent("log", map[string]string{"key1": "value1"}, telegraf.SeverityError, "disk failure", time.Unix(123, 0))
+	event.AddField("code", 5)
+
+	client := &Loki{
+		Domain: ts.URL,
+	}
+	require.NoError(t, client.Connect())
+	require.NoError(t, client.Write([]telegraf.Metric{event}))
+}
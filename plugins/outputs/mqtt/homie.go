@@ -23,9 +23,9 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 		if err != nil {
 			return nil, "", fmt.Errorf("generating device name failed: %w", err)
 		}
-		messages = append(messages, message{topic + "/$homie", []byte("4.0")})
-		messages = append(messages, message{topic + "/$name", []byte(deviceName)})
-		messages = append(messages, message{topic + "/$state", []byte("ready")})
+		messages = append(messages, message{topic + "/$homie", []byte("4.0"), nil})
+		messages = append(messages, message{topic + "/$name", []byte(deviceName), nil})
+		messages = append(messages, message{topic + "/$state", []byte("ready"), nil})
 		m.homieSeen[topic] = make(map[string]bool)
 	}
 
@@ -46,10 +46,12 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 		messages = append(messages, message{
 			topic + "/$nodes",
 			[]byte(strings.Join(nodeIDs, ",")),
+			nil,
 		})
 		messages = append(messages, message{
 			topic + "/" + nodeID + "/$name",
 			[]byte(nodeName),
+			nil,
 		})
 	}
 
@@ -65,6 +67,7 @@ func (m *MQTT) collectHomieDeviceMessages(topic string, metric telegraf.Metric)
 	messages = append(messages, message{
 		topic + "/" + nodeID + "/$properties",
 		[]byte(strings.Join(properties, ",")),
+		nil,
 	})
 
 	return messages, nodeID, nil
@@ -21,8 +21,9 @@ import (
 var sampleConfig string
 
 type message struct {
-	topic   string
-	payload []byte
+	topic      string
+	payload    []byte
+	properties map[string]string
 }
 
 type MQTT struct {
@@ -62,6 +63,10 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("qos value must be 0, 1, or 2: %d", m.QoS)
 	}
 
+	if m.PublishPropertiesV5 != nil && len(m.PublishPropertiesV5.UserPropertyTags) > 0 && m.Protocol != "5" {
+		return errors.New("user_property_tags requires protocol 5")
+	}
+
 	var err error
 	m.generator, err = NewTopicNameGenerator(m.TopicPrefix, m.Topic)
 	if err != nil {
@@ -99,6 +104,10 @@ func (m *MQTT) Init() error {
 		return fmt.Errorf("invalid layout %q", m.Layout)
 	}
 
+	if m.generator.UsesFieldName && m.Layout != "field" {
+		return errors.New("'.FieldName' in topic requires the 'field' layout")
+	}
+
 	return nil
 }
 
@@ -166,14 +175,43 @@ func (m *MQTT) Write(metrics []telegraf.Metric) error {
 	}
 
 	for _, msg := range topicMessages {
-		if err := m.client.Publish(msg.topic, msg.payload); err != nil {
-			m.Log.Warn("Could not publish message to MQTT server, %s", err)
+		if err := m.client.PublishWithProperties(msg.topic, msg.payload, msg.properties); err != nil {
+			// Messages with QoS 0 are sent on a best-effort basis only, for
+			// higher levels keep the metrics for retrying.
+			if m.QoS > 0 {
+				return fmt.Errorf("could not publish message to MQTT server: %w", err)
+			}
+			m.Log.Warnf("Could not publish message to MQTT server, %s", err)
 		}
 	}
 
 	return nil
 }
 
+// userProperties returns the values of the tags configured as user
+// properties for the given metrics
+func (m *MQTT) userProperties(metrics ...telegraf.Metric) map[string]string {
+	if m.PublishPropertiesV5 == nil || len(m.PublishPropertiesV5.UserPropertyTags) == 0 {
+		return nil
+	}
+
+	properties := make(map[string]string, len(m.PublishPropertiesV5.UserPropertyTags))
+	for _, key := range m.PublishPropertiesV5.UserPropertyTags {
+		// For batches only add properties shared by all metrics
+		value, found := metrics[0].GetTag(key)
+		for _, metric := range metrics[1:] {
+			if v, ok := metric.GetTag(key); !ok || v != value {
+				found = false
+				break
+			}
+		}
+		if found {
+			properties[key] = value
+		}
+	}
+	return properties
+}
+
 func (m *MQTT) collectNonBatch(hostname string, metrics []telegraf.Metric) []message {
 	collection := make([]message, 0, len(metrics))
 	for _, metric := range metrics {
@@ -190,7 +228,7 @@ func (m *MQTT) collectNonBatch(hostname string, metrics []telegraf.Metric) []mes
 			m.Log.Debugf("metric was: %v", metric)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, m.userProperties(metric)})
 	}
 
 	return collection
@@ -215,7 +253,7 @@ func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []messag
 			m.Log.Warnf("Could not serialize metric batch for topic %q: %v", topic, err)
 			continue
 		}
-		collection = append(collection, message{topic, buf})
+		collection = append(collection, message{topic, buf, m.userProperties(ms...)})
 	}
 	return collection
 }
@@ -223,21 +261,30 @@ func (m *MQTT) collectBatch(hostname string, metrics []telegraf.Metric) []messag
 func (m *MQTT) collectField(hostname string, metrics []telegraf.Metric) []message {
 	var collection []message
 	for _, metric := range metrics {
-		topic, err := m.generator.Generate(hostname, metric)
-		if err != nil {
-			m.Log.Warnf("Generating topic name failed: %w", err)
-			m.Log.Debugf("metric was: %v", metric)
-			continue
-		}
+		properties := m.userProperties(metric)
+		for _, field := range metric.FieldList() {
+			// Append the field name to the topic unless the template places it
+			var topic string
+			var err error
+			if m.generator.UsesFieldName {
+				topic, err = m.generator.GenerateForField(hostname, metric, field.Key)
+			} else {
+				topic, err = m.generator.Generate(hostname, metric)
+				topic += "/" + field.Key
+			}
+			if err != nil {
+				m.Log.Warnf("Generating topic name failed: %w", err)
+				m.Log.Debugf("metric was: %v", metric)
+				continue
+			}
 
-		for n, v := range metric.Fields() {
-			buf, err := internal.ToString(v)
+			buf, err := internal.ToString(field.Value)
 			if err != nil {
-				m.Log.Warnf("Could not serialize metric for topic %q field %q: %v", topic, n, err)
+				m.Log.Warnf("Could not serialize metric for topic %q field %q: %v", topic, field.Key, err)
 				m.Log.Debugf("metric was: %v", metric)
 				continue
 			}
-			collection = append(collection, message{topic + "/" + n, []byte(buf)})
+			collection = append(collection, message{topic, []byte(buf), properties})
 		}
 	}
 
@@ -270,9 +317,9 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 				continue
 			}
 			propID := normalizeID(tag.Key)
-			collection = append(collection, message{path + "/" + propID, []byte(tag.Value)})
-			collection = append(collection, message{path + "/" + propID + "/$name", []byte(tag.Key)})
-			collection = append(collection, message{path + "/" + propID + "/$datatype", []byte("string")})
+			collection = append(collection, message{path + "/" + propID, []byte(tag.Value), nil})
+			collection = append(collection, message{path + "/" + propID + "/$name", []byte(tag.Key), nil})
+			collection = append(collection, message{path + "/" + propID + "/$datatype", []byte("string"), nil})
 		}
 
 		for _, field := range metric.FieldList() {
@@ -283,9 +330,9 @@ func (m *MQTT) collectHomieV4(hostname string, metrics []telegraf.Metric) []mess
 				continue
 			}
 			propID := normalizeID(field.Key)
-			collection = append(collection, message{path + "/" + propID, []byte(v)})
-			collection = append(collection, message{path + "/" + propID + "/$name", []byte(field.Key)})
-			collection = append(collection, message{path + "/" + propID + "/$datatype", []byte(dt)})
+			collection = append(collection, message{path + "/" + propID, []byte(v), nil})
+			collection = append(collection, message{path + "/" + propID + "/$name", []byte(field.Key), nil})
+			collection = append(collection, message{path + "/" + propID + "/$datatype", []byte(dt), nil})
 		}
 	}
 
@@ -508,7 +508,7 @@ func TestIntegrationMQTTLayoutField(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{msg.Topic(), msg.Payload(), nil})
 	}
 
 	// Add routing for the messages
@@ -625,7 +625,7 @@ func TestIntegrationMQTTLayoutHomieV4(t *testing.T) {
 	onMessage := func(_ paho.Client, msg paho.Message) {
 		mtx.Lock()
 		defer mtx.Unlock()
-		received = append(received, message{msg.Topic(), msg.Payload()})
+		received = append(received, message{msg.Topic(), msg.Payload(), nil})
 	}
 
 	// Add routing for the messages
@@ -926,3 +926,81 @@ func TestGenerateTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestInitV5Options(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers:             []string{"tcp://localhost:1883"},
+			Protocol:            "3.1.1",
+			PublishPropertiesV5: &mqtt.PublishProperties{UserPropertyTags: []string{"site"}},
+		},
+	}
+	require.ErrorContains(t, plugin.Init(), "user_property_tags requires protocol 5")
+
+	plugin = &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers: []string{"tcp://localhost:1883"},
+		},
+		Topic:  "telegraf/{{ .FieldName }}",
+		Layout: "batch",
+	}
+	require.ErrorContains(t, plugin.Init(), "requires the 'field' layout")
+}
+
+func TestCollectFieldTopicTemplate(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			Servers:             []string{"tcp://localhost:1883"},
+			Protocol:            "5",
+			PublishPropertiesV5: &mqtt.PublishProperties{UserPropertyTags: []string{"site", "unit"}},
+		},
+		Topic:  `plant/{{ .Tag "line" }}/{{ .FieldName }}/{{ .PluginName }}`,
+		Layout: "field",
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New(
+		"sensor",
+		map[string]string{"line": "l1", "site": "north"},
+		map[string]interface{}{"pressure": 1.5, "temperature": int64(21)},
+		time.Unix(0, 0),
+	)
+
+	expected := []message{
+		{
+			topic:      "plant/l1/pressure/sensor",
+			payload:    []byte("1.5"),
+			properties: map[string]string{"site": "north"},
+		},
+		{
+			topic:      "plant/l1/temperature/sensor",
+			payload:    []byte("21"),
+			properties: map[string]string{"site": "north"},
+		},
+	}
+	require.Equal(t, expected, plugin.collectField("hostname", []telegraf.Metric{m}))
+
+	// Without the field name in the template, the name is appended
+	plugin.Topic = `plant/{{ .Tag "line" }}`
+	plugin.PublishPropertiesV5 = nil
+	require.NoError(t, plugin.Init())
+	actual := plugin.collectField("hostname", []telegraf.Metric{m})
+	require.Len(t, actual, 2)
+	require.Equal(t, "plant/l1/pressure", actual[0].topic)
+	require.Nil(t, actual[0].properties)
+}
+
+func TestUserPropertiesBatch(t *testing.T) {
+	plugin := &MQTT{
+		MqttConfig: mqtt.MqttConfig{
+			PublishPropertiesV5: &mqtt.PublishProperties{UserPropertyTags: []string{"site", "line"}},
+		},
+	}
+
+	metrics := []telegraf.Metric{
+		metric.New("sensor", map[string]string{"site": "north", "line": "l1"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("sensor", map[string]string{"site": "north", "line": "l2"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+	require.Equal(t, map[string]string{"site": "north"}, plugin.userProperties(metrics...))
+}
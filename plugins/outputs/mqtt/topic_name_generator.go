@@ -12,8 +12,12 @@ type TopicNameGenerator struct {
 	Hostname    string
 	TopicPrefix string
 	PluginName  string
+	FieldName   string
 	metric      telegraf.Metric
 	template    *template.Template
+
+	// UsesFieldName is true if the template references the field name
+	UsesFieldName bool
 }
 
 func NewTopicNameGenerator(topicPrefix string, topic string) (*TopicNameGenerator, error) {
@@ -26,7 +30,11 @@ func NewTopicNameGenerator(topicPrefix string, topic string) (*TopicNameGenerato
 			return nil, fmt.Errorf("found forbidden character %s in the topic name %s", p, topic)
 		}
 	}
-	return &TopicNameGenerator{TopicPrefix: topicPrefix, template: tt}, nil
+	return &TopicNameGenerator{
+		TopicPrefix:   topicPrefix,
+		template:      tt,
+		UsesFieldName: strings.Contains(topic, ".FieldName"),
+	}, nil
 }
 
 func (t *TopicNameGenerator) Tag(key string) string {
@@ -35,9 +43,15 @@ func (t *TopicNameGenerator) Tag(key string) string {
 }
 
 func (t *TopicNameGenerator) Generate(hostname string, m telegraf.Metric) (string, error) {
+	return t.GenerateForField(hostname, m, "")
+}
+
+// GenerateForField generates the topic name for the given field of the metric
+func (t *TopicNameGenerator) GenerateForField(hostname string, m telegraf.Metric, field string) (string, error) {
 	t.Hostname = hostname
 	t.metric = m
 	t.PluginName = m.Name()
+	t.FieldName = field
 	var b strings.Builder
 	err := t.template.Execute(&b, t)
 	if err != nil {
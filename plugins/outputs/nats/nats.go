@@ -3,13 +3,17 @@ package nats
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/nats-io/nats.go"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/plugins/common/tls"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -26,15 +30,36 @@ type NATS struct {
 	Password    config.Secret `toml:"password"`
 	Credentials string        `toml:"credentials"`
 	Subject     string        `toml:"subject"`
+	Jetstream   *StreamConfig `toml:"jetstream"`
 
 	tls.ClientConfig
 
 	Log telegraf.Logger `toml:"-"`
 
 	conn       *nats.Conn
+	js         nats.JetStreamContext
+	subject    *template.Template
 	serializer serializers.Serializer
 }
 
+// StreamConfig specifies the JetStream stream to publish to and, if enabled,
+// the settings used to create the stream if it does not exist.
+type StreamConfig struct {
+	Name       string          `toml:"name"`
+	AutoCreate bool            `toml:"auto_create"`
+	Subjects   []string        `toml:"subjects"`
+	Retention  string          `toml:"retention"`
+	Storage    string          `toml:"storage"`
+	Replicas   int             `toml:"replicas"`
+	MaxAge     config.Duration `toml:"max_age"`
+	MaxBytes   int64           `toml:"max_bytes"`
+	AckTimeout config.Duration `toml:"ack_timeout"`
+
+	retention nats.RetentionPolicy
+	storage   nats.StorageType
+	subjects  []string
+}
+
 func (*NATS) SampleConfig() string {
 	return sampleConfig
 }
@@ -43,6 +68,74 @@ func (n *NATS) SetSerializer(serializer serializers.Serializer) {
 	n.serializer = serializer
 }
 
+func (n *NATS) Init() error {
+	tmpl, err := template.New("subject").Parse(n.Subject)
+	if err != nil {
+		return fmt.Errorf("parsing subject template failed: %w", err)
+	}
+	n.subject = tmpl
+
+	if n.Jetstream == nil {
+		return nil
+	}
+
+	if n.Jetstream.Name == "" {
+		return errors.New("stream name required for jetstream")
+	}
+	if n.Jetstream.AckTimeout <= 0 {
+		n.Jetstream.AckTimeout = config.Duration(5 * time.Second)
+	}
+
+	switch n.Jetstream.Retention {
+	case "", "limits":
+		n.Jetstream.retention = nats.LimitsPolicy
+	case "interest":
+		n.Jetstream.retention = nats.InterestPolicy
+	case "workqueue":
+		n.Jetstream.retention = nats.WorkQueuePolicy
+	default:
+		return fmt.Errorf("invalid retention %q", n.Jetstream.Retention)
+	}
+
+	switch n.Jetstream.Storage {
+	case "", "file":
+		n.Jetstream.storage = nats.FileStorage
+	case "memory":
+		n.Jetstream.storage = nats.MemoryStorage
+	default:
+		return fmt.Errorf("invalid storage %q", n.Jetstream.Storage)
+	}
+
+	if n.Jetstream.Replicas < 0 {
+		return fmt.Errorf("invalid replicas %d", n.Jetstream.Replicas)
+	}
+
+	n.Jetstream.subjects = n.Jetstream.Subjects
+	if n.Jetstream.AutoCreate && len(n.Jetstream.subjects) == 0 {
+		subject, err := streamSubject(n.Subject)
+		if err != nil {
+			return err
+		}
+		n.Jetstream.subjects = []string{subject}
+	}
+
+	return nil
+}
+
+// streamSubject returns the subject to bind to the stream for the given
+// subject setting. Templated subjects are matched with a wildcard below the
+// literal prefix of the template, e.g. "telegraf.>" for "telegraf.{{ .Name }}".
+func streamSubject(subject string) (string, error) {
+	prefix, _, templated := strings.Cut(subject, "{{")
+	if !templated {
+		return subject, nil
+	}
+	if !strings.HasSuffix(prefix, ".") || strings.Trim(prefix, ".") == "" {
+		return "", fmt.Errorf("cannot derive stream subject from %q, set 'subjects' of the stream explicitly", subject)
+	}
+	return prefix + ">", nil
+}
+
 func (n *NATS) Connect() error {
 	var err error
 
@@ -85,8 +178,53 @@ func (n *NATS) Connect() error {
 
 	// try and connect
 	n.conn, err = nats.Connect(strings.Join(n.Servers, ","), opts...)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if n.Jetstream == nil {
+		return nil
+	}
+
+	n.js, err = n.conn.JetStream()
+	if err != nil {
+		return fmt.Errorf("creating jetstream context failed: %w", err)
+	}
+	return n.ensureStream()
+}
+
+// ensureStream checks that the configured stream exists and creates it if
+// auto-creation is enabled.
+func (n *NATS) ensureStream() error {
+	_, err := n.js.StreamInfo(n.Jetstream.Name)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, nats.ErrStreamNotFound) {
+		return fmt.Errorf("getting info for stream %q failed: %w", n.Jetstream.Name, err)
+	}
+	if !n.Jetstream.AutoCreate {
+		return fmt.Errorf("stream %q does not exist", n.Jetstream.Name)
+	}
+
+	cfg := &nats.StreamConfig{
+		Name:      n.Jetstream.Name,
+		Subjects:  n.Jetstream.subjects,
+		Retention: n.Jetstream.retention,
+		Storage:   n.Jetstream.storage,
+		Replicas:  n.Jetstream.Replicas,
+		MaxAge:    time.Duration(n.Jetstream.MaxAge),
+		MaxBytes:  n.Jetstream.MaxBytes,
+	}
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = -1
+	}
+	if _, err := n.js.AddStream(cfg); err != nil {
+		return fmt.Errorf("creating stream %q failed: %w", n.Jetstream.Name, err)
+	}
+	n.Log.Infof("Created stream %q", n.Jetstream.Name)
+
+	return nil
 }
 
 func (n *NATS) Close() error {
@@ -99,21 +237,66 @@ func (n *NATS) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
+	futures := make([]nats.PubAckFuture, 0, len(metrics))
 	for _, metric := range metrics {
+		subject, err := n.generateSubject(metric)
+		if err != nil {
+			n.Log.Errorf("Generating subject for metric %q failed: %v", metric.Name(), err)
+			continue
+		}
+
 		buf, err := n.serializer.Serialize(metric)
 		if err != nil {
 			n.Log.Debugf("Could not serialize metric: %v", err)
 			continue
 		}
 
-		err = n.conn.Publish(n.Subject, buf)
+		if n.js == nil {
+			err = n.conn.Publish(subject, buf)
+			if err != nil {
+				return fmt.Errorf("FAILED to send NATS message: %w", err)
+			}
+			continue
+		}
+
+		future, err := n.js.PublishAsync(subject, buf, nats.ExpectStream(n.Jetstream.Name))
 		if err != nil {
-			return fmt.Errorf("FAILED to send NATS message: %w", err)
+			return fmt.Errorf("publishing to jetstream failed: %w", err)
+		}
+		futures = append(futures, future)
+	}
+
+	if n.js == nil {
+		return nil
+	}
+
+	// Wait for the acknowledgements of all messages
+	timeout := time.NewTimer(time.Duration(n.Jetstream.AckTimeout))
+	defer timeout.Stop()
+	for _, future := range futures {
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			return fmt.Errorf("publishing to jetstream failed: %w", err)
+		case <-timeout.C:
+			return errors.New("timeout waiting for jetstream acknowledgements")
 		}
 	}
 	return nil
 }
 
+func (n *NATS) generateSubject(metric telegraf.Metric) (string, error) {
+	var b strings.Builder
+	if err := n.subject.Execute(&b, &templating.MetricData{Metric: metric}); err != nil {
+		return "", err
+	}
+	subject := b.String()
+	if subject == "" || strings.HasPrefix(subject, ".") || strings.HasSuffix(subject, ".") || strings.Contains(subject, "..") {
+		return "", fmt.Errorf("invalid subject %q", subject)
+	}
+	return subject, nil
+}
+
 func init() {
 	outputs.Add("nats", func() telegraf.Output {
 		return &NATS{}
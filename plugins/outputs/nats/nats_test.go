@@ -3,14 +3,187 @@ package nats
 import (
 	"fmt"
 	"testing"
+	"time"
 
+	gnatsd "github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/wait"
 
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/serializers/influx"
 	"github.com/influxdata/telegraf/testutil"
 )
 
+func startJetstreamServer(t *testing.T) *gnatsd.Server {
+	srv, err := gnatsd.NewServer(&gnatsd.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	srv.Start()
+	t.Cleanup(srv.Shutdown)
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+	return srv
+}
+
+func TestInitFail(t *testing.T) {
+	n := &NATS{Subject: "telegraf.{{ .Name"}
+	require.ErrorContains(t, n.Init(), "parsing subject template failed")
+
+	n = &NATS{Subject: "telegraf", Jetstream: &StreamConfig{}}
+	require.ErrorContains(t, n.Init(), "stream name required")
+
+	n = &NATS{Subject: "telegraf", Jetstream: &StreamConfig{Name: "telegraf", Retention: "forever"}}
+	require.ErrorContains(t, n.Init(), "invalid retention")
+
+	n = &NATS{Subject: "telegraf", Jetstream: &StreamConfig{Name: "telegraf", Storage: "tape"}}
+	require.ErrorContains(t, n.Init(), "invalid storage")
+
+	n = &NATS{Subject: "telegraf_{{ .Name }}", Jetstream: &StreamConfig{Name: "telegraf", AutoCreate: true}}
+	require.ErrorContains(t, n.Init(), "set 'subjects' of the stream explicitly")
+}
+
+func TestStreamSubject(t *testing.T) {
+	tests := []struct {
+		subject  string
+		expected string
+	}{
+		{subject: "telegraf", expected: "telegraf"},
+		{subject: "telegraf.{{ .Name }}", expected: "telegraf.>"},
+		{subject: `telegraf.metrics.{{ .Tag "host" }}.{{ .Name }}`, expected: "telegraf.metrics.>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.subject, func(t *testing.T) {
+			actual, err := streamSubject(tt.subject)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+
+	for _, subject := range []string{"{{ .Name }}", "telegraf_{{ .Name }}", ".{{ .Name }}"} {
+		_, err := streamSubject(subject)
+		require.Error(t, err, subject)
+	}
+}
+
+func TestJetstreamTemplatedSubject(t *testing.T) {
+	srv := startJetstreamServer(t)
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	n := &NATS{
+		Servers: []string{srv.ClientURL()},
+		Subject: "telegraf.{{ .Name }}",
+		Jetstream: &StreamConfig{
+			Name:       "metrics",
+			AutoCreate: true,
+			Storage:    "memory",
+		},
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, n.Init())
+	require.NoError(t, n.Connect())
+	defer n.Close()
+
+	info, err := n.js.StreamInfo("metrics")
+	require.NoError(t, err)
+	require.Equal(t, []string{"telegraf.>"}, info.Config.Subjects)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric("cpu", map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 1)),
+	}
+	require.NoError(t, n.Write(metrics))
+
+	msg, err := n.js.GetLastMsg("metrics", "telegraf.cpu")
+	require.NoError(t, err)
+	require.Equal(t, "cpu value=1 1\n", string(msg.Data))
+}
+
+func TestJetstreamMissingStream(t *testing.T) {
+	srv := startJetstreamServer(t)
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	n := &NATS{
+		Servers:    []string{srv.ClientURL()},
+		Subject:    "telegraf",
+		Jetstream:  &StreamConfig{Name: "metrics"},
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, n.Init())
+	require.ErrorContains(t, n.Connect(), `stream "metrics" does not exist`)
+	require.NoError(t, n.Close())
+}
+
+func TestJetstreamWrite(t *testing.T) {
+	srv := startJetstreamServer(t)
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	n := &NATS{
+		Servers: []string{srv.ClientURL()},
+		Subject: `telegraf.{{ .Tag "host" }}.{{ .Name }}`,
+		Jetstream: &StreamConfig{
+			Name:       "metrics",
+			AutoCreate: true,
+			Subjects:   []string{"telegraf.>"},
+			Storage:    "memory",
+			MaxAge:     config.Duration(time.Hour),
+		},
+		serializer: serializer,
+		Log:        testutil.Logger{},
+	}
+	require.NoError(t, n.Init())
+	require.NoError(t, n.Connect())
+	defer n.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 1),
+		),
+		testutil.MustMetric(
+			"mem",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"value": 2.0},
+			time.Unix(0, 2),
+		),
+		// Missing tag results in an invalid subject, the metric is skipped
+		testutil.MustMetric(
+			"disk",
+			map[string]string{},
+			map[string]interface{}{"value": 3.0},
+			time.Unix(0, 3),
+		),
+	}
+	require.NoError(t, n.Write(metrics))
+
+	info, err := n.js.StreamInfo("metrics")
+	require.NoError(t, err)
+	require.Equal(t, nats.MemoryStorage, info.Config.Storage)
+	require.Equal(t, time.Hour, info.Config.MaxAge)
+	require.EqualValues(t, 2, info.State.Msgs)
+
+	msg, err := n.js.GetLastMsg("metrics", "telegraf.b.mem")
+	require.NoError(t, err)
+	require.Equal(t, "mem,host=b value=2 2\n", string(msg.Data))
+
+	// Publishing to a subject outside of the stream is not acknowledged
+	n.Subject = "other"
+	require.NoError(t, n.Init())
+	require.Error(t, n.Write(metrics[:1]))
+}
+
 func TestConnectAndWriteIntegration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
@@ -35,6 +208,7 @@ func TestConnectAndWriteIntegration(t *testing.T) {
 		Subject:    "telegraf",
 		serializer: serializer,
 	}
+	require.NoError(t, n.Init())
 
 	// Verify that we can connect to the NATS daemon
 	err = n.Connect()
@@ -20,6 +20,14 @@ import (
 //go:embed sample.conf
 var sampleConfig string
 
+// Limits of the metric API, see
+// https://docs.newrelic.com/docs/data-apis/ingest-apis/metric-api/metric-api-limits-restricted-attributes/
+const (
+	maxAttributes           = 100
+	maxNameLength           = 255
+	maxAttributeValueLength = 4096
+)
+
 // NewRelic nr structure
 type NewRelic struct {
 	InsightsKey  string          `toml:"insights_key"`
@@ -27,6 +35,7 @@ type NewRelic struct {
 	Timeout      config.Duration `toml:"timeout"`
 	HTTPProxy    string          `toml:"http_proxy"`
 	MetricURL    string          `toml:"metric_url"`
+	Log          telegraf.Logger `toml:"-"`
 
 	harvestor   *telemetry.Harvester
 	dc          *cumulative.DeltaCalculator
@@ -89,11 +98,7 @@ func (nr *NewRelic) Write(metrics []telegraf.Metric) error {
 	nr.savedErrors = make(map[int]interface{})
 
 	for _, metric := range metrics {
-		// create tag map
-		tags := make(map[string]interface{})
-		for _, tag := range metric.TagList() {
-			tags[tag.Key] = tag.Value
-		}
+		tags := nr.buildAttributes(metric)
 		for _, field := range metric.FieldList() {
 			var mvalue float64
 			var mname string
@@ -102,6 +107,10 @@ func (nr *NewRelic) Write(metrics []telegraf.Metric) error {
 			} else {
 				mname = metric.Name() + "." + field.Key
 			}
+			if len(mname) > maxNameLength {
+				nr.Log.Warnf("Skipping metric %q exceeding the name length limit of %d", mname, maxNameLength)
+				continue
+			}
 			switch n := field.Value.(type) {
 			case int64:
 				mvalue = float64(n)
@@ -138,7 +147,8 @@ func (nr *NewRelic) Write(metrics []telegraf.Metric) error {
 	}
 	// By default, the Harvester sends metrics and spans to the New Relic
 	// backend every 5 seconds.  You can force data to be sent at any time
-	// using HarvestNow.
+	// using HarvestNow. Payloads exceeding the compressed size limit of the
+	// API are split into multiple requests by the Harvester.
 	nr.harvestor.HarvestNow(context.Background())
 
 	//Check if we encountered errors
@@ -148,6 +158,29 @@ func (nr *NewRelic) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// buildAttributes converts the tags of the metric to attributes obeying
+// the API limits. Attributes with too long names or exceeding the number
+// of attributes are dropped, too long values are truncated.
+func (nr *NewRelic) buildAttributes(metric telegraf.Metric) map[string]interface{} {
+	tags := make(map[string]interface{}, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		if len(tags) == maxAttributes {
+			nr.Log.Debugf("Dropping attributes of metric %q exceeding the limit of %d", metric.Name(), maxAttributes)
+			break
+		}
+		if len(tag.Key) > maxNameLength {
+			nr.Log.Debugf("Dropping attribute %q of metric %q exceeding the name length limit", tag.Key, metric.Name())
+			continue
+		}
+		value := tag.Value
+		if len(value) > maxAttributeValueLength {
+			value = value[:maxAttributeValueLength]
+		}
+		tags[tag.Key] = value
+	}
+	return tags
+}
+
 func init() {
 	outputs.Add("newrelic", func() telegraf.Output {
 		return &NewRelic{
@@ -1,7 +1,9 @@
 package newrelic
 
 import (
+	"fmt"
 	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -189,3 +191,40 @@ func TestNewRelic_Connect(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRelic_AttributeLimits(t *testing.T) {
+	tags := map[string]string{
+		"short":                  "value",
+		strings.Repeat("k", 256): "dropped",
+		"long":                   strings.Repeat("v", 5000),
+	}
+	for i := 0; i < maxAttributes; i++ {
+		tags[fmt.Sprintf("tag%03d", i)] = "x"
+	}
+	m := testutil.MustMetric("test", tags, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+
+	nr := &NewRelic{Log: testutil.Logger{}}
+	attributes := nr.buildAttributes(m)
+	require.Len(t, attributes, maxAttributes)
+	require.Equal(t, strings.Repeat("v", maxAttributeValueLength), attributes["long"])
+	require.NotContains(t, attributes, strings.Repeat("k", 256))
+	require.NotContains(t, attributes, "tag099")
+}
+
+func TestNewRelic_NameLimit(t *testing.T) {
+	var auditLog map[string]interface{}
+	nr := &NewRelic{Log: testutil.Logger{}}
+	nr.harvestor, _ = telemetry.NewHarvester(
+		telemetry.ConfigHarvestPeriod(0),
+		func(cfg *telemetry.Config) {
+			cfg.APIKey = "dummyTestKey"
+			cfg.HarvestTimeout = 0
+			cfg.AuditLogger = func(e map[string]interface{}) {
+				auditLog = e
+			}
+		})
+
+	m := testutil.MustMetric(strings.Repeat("m", maxNameLength), map[string]string{}, map[string]interface{}{"value": 1.0}, time.Unix(0, 0))
+	require.NoError(t, nr.Write([]telegraf.Metric{m}))
+	require.Nil(t, auditLog["data"])
+}
@@ -50,6 +50,11 @@ type Postgresql struct {
 	TagCacheSize               int                     `toml:"tag_cache_size"`
 	LogLevel                   string                  `toml:"log_level"`
 
+	TimescaleHypertable        bool            `toml:"timescaledb_hypertable"`
+	TimescaleChunkTimeInterval config.Duration `toml:"timescaledb_chunk_time_interval"`
+	TimescaleCompressAfter     config.Duration `toml:"timescaledb_compress_after"`
+	TimescaleCompressSegmentBy []string        `toml:"timescaledb_compress_segmentby"`
+
 	dbContext       context.Context
 	dbContextCancel func()
 	dbConfig        *pgxpool.Config
@@ -82,6 +87,7 @@ func newPostgresql() *Postgresql {
 		RetryMaxBackoff:            config.Duration(time.Second * 15),
 		Logger:                     models.NewLogger("outputs", "postgresql", ""),
 		LogLevel:                   "warn",
+		TimescaleChunkTimeInterval: config.Duration(7 * 24 * time.Hour),
 	}
 
 	_ = p.CreateTemplates[0].UnmarshalText([]byte(`CREATE TABLE {{ .table }} ({{ .columns }})`))
@@ -129,6 +135,66 @@ func (p *Postgresql) Init() error {
 		return fmt.Errorf("invalid uint64_type")
 	}
 
+	if p.TimescaleHypertable {
+		if err := p.addTimescaleTemplates(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addTimescaleTemplates appends the statements for converting newly created metric tables into TimescaleDB
+// hypertables, and optionally setting up native compression, to the create templates.
+func (p *Postgresql) addTimescaleTemplates() error {
+	chunkInterval := time.Duration(p.TimescaleChunkTimeInterval)
+	if chunkInterval <= 0 {
+		return errors.New("invalid timescaledb_chunk_time_interval")
+	}
+	if p.TimescaleCompressAfter < 0 {
+		return errors.New("invalid timescaledb_compress_after")
+	}
+
+	statements := []string{
+		fmt.Sprintf(
+			`SELECT create_hypertable({{ .table|quoteLiteral }}, 'time', chunk_time_interval => INTERVAL '%d seconds', if_not_exists => true)`,
+			int64(chunkInterval.Seconds()),
+		),
+	}
+
+	if p.TimescaleCompressAfter > 0 {
+		segmentBy := p.TimescaleCompressSegmentBy
+		if len(segmentBy) == 0 && p.TagsAsForeignKeys {
+			segmentBy = []string{"tag_id"}
+		}
+		quoted := make([]string, 0, len(segmentBy))
+		for _, col := range segmentBy {
+			quoted = append(quoted, utils.QuoteIdentifier(col))
+		}
+
+		compress := `ALTER TABLE {{ .table }} SET (timescaledb.compress`
+		if len(quoted) > 0 {
+			compress += ", timescaledb.compress_segmentby = " + utils.QuoteLiteral(strings.Join(quoted, ","))
+		}
+		compress += ")"
+
+		statements = append(statements,
+			compress,
+			fmt.Sprintf(
+				`SELECT add_compression_policy({{ .table|quoteLiteral }}, INTERVAL '%d seconds', if_not_exists => true)`,
+				int64(time.Duration(p.TimescaleCompressAfter).Seconds()),
+			),
+		)
+	}
+
+	for _, stmt := range statements {
+		tmpl := &sqltemplate.Template{}
+		if err := tmpl.UnmarshalText([]byte(stmt)); err != nil {
+			return fmt.Errorf("parsing timescaledb template: %w", err)
+		}
+		p.CreateTemplates = append(p.CreateTemplates, tmpl)
+	}
+
 	return nil
 }
 
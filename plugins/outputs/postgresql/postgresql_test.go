@@ -17,6 +17,8 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/outputs/postgresql/sqltemplate"
 	"github.com/influxdata/telegraf/plugins/outputs/postgresql/utils"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -265,6 +267,36 @@ func TestPostgresqlConnectIntegration(t *testing.T) {
 	require.EqualValues(t, 2, p.db.Stat().MaxConns())
 }
 
+func TestTimescaleTemplates(t *testing.T) {
+	p := newPostgresql()
+	p.Connection = "host=localhost"
+	p.TagsAsForeignKeys = true
+	p.TimescaleHypertable = true
+	p.TimescaleChunkTimeInterval = config.Duration(24 * time.Hour)
+	p.TimescaleCompressAfter = config.Duration(14 * 24 * time.Hour)
+	p.Logger = testutil.Logger{}
+	require.NoError(t, p.Init())
+	require.Len(t, p.CreateTemplates, 4)
+
+	tbl := sqltemplate.NewTable("public", "cpu", nil)
+	expected := []string{
+		`CREATE TABLE "public"."cpu" ()`,
+		`SELECT create_hypertable('"public"."cpu"', 'time', chunk_time_interval => INTERVAL '86400 seconds', if_not_exists => true)`,
+		`ALTER TABLE "public"."cpu" SET (timescaledb.compress, timescaledb.compress_segmentby = '"tag_id"')`,
+		`SELECT add_compression_policy('"public"."cpu"', INTERVAL '1209600 seconds', if_not_exists => true)`,
+	}
+	for i, tmpl := range p.CreateTemplates {
+		sql, err := tmpl.Render(tbl, nil, tbl, nil)
+		require.NoError(t, err)
+		require.Equal(t, expected[i], string(sql))
+	}
+
+	p = newPostgresql()
+	p.TimescaleHypertable = true
+	p.TimescaleChunkTimeInterval = 0
+	require.ErrorContains(t, p.Init(), "invalid timescaledb_chunk_time_interval")
+}
+
 func newMetric(
 	t *testing.T,
 	suffix string,
@@ -0,0 +1,329 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package questdb
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	_ "embed"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/templating"
+	tlsint "github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Characters not allowed in QuestDB table and column names
+var (
+	tableNameReplacer  = strings.NewReplacer(invalidNameChars("")...)
+	columnNameReplacer = strings.NewReplacer(invalidNameChars("-")...)
+)
+
+// QuestDB writes metrics using ILP over TCP implemented on top of the influx
+// serializer. The official go-questdb-client is not used as it is not
+// available as a dependency, see the README for the consequences.
+type QuestDB struct {
+	Address         string           `toml:"address"`
+	Username        config.Secret    `toml:"username"`
+	Token           config.Secret    `toml:"token"`
+	TableTemplate   string           `toml:"table_template"`
+	Timeout         config.Duration  `toml:"timeout"`
+	KeepAlivePeriod *config.Duration `toml:"keep_alive_period"`
+	tlsint.ClientConfig
+	Log telegraf.Logger `toml:"-"`
+
+	tlsCfg     *tls.Config
+	tableName  *template.Template
+	serializer *influx.Serializer
+	conn       net.Conn
+}
+
+func (*QuestDB) SampleConfig() string {
+	return sampleConfig
+}
+
+func (q *QuestDB) Init() error {
+	if q.Address == "" {
+		return errors.New("address required")
+	}
+	if _, _, err := net.SplitHostPort(q.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", q.Address, err)
+	}
+	if q.Username.Empty() != q.Token.Empty() {
+		return errors.New("username and token must be set together")
+	}
+
+	if q.TableTemplate == "" {
+		q.TableTemplate = "{{ .Name }}"
+	}
+	tmpl, err := template.New("table_template").Parse(q.TableTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing table template failed: %w", err)
+	}
+	q.tableName = tmpl
+
+	tlsCfg, err := q.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+	q.tlsCfg = tlsCfg
+
+	// QuestDB does not support the unsigned integer type of line protocol
+	q.serializer = &influx.Serializer{}
+	return q.serializer.Init()
+}
+
+func (q *QuestDB) Connect() error {
+	dialer := &net.Dialer{Timeout: time.Duration(q.Timeout)}
+	if q.KeepAlivePeriod != nil {
+		dialer.KeepAlive = time.Duration(*q.KeepAlivePeriod)
+		if dialer.KeepAlive == 0 {
+			dialer.KeepAlive = -1
+		}
+	}
+
+	var conn net.Conn
+	var err error
+	if q.tlsCfg == nil {
+		conn, err = dialer.Dial("tcp", q.Address)
+	} else {
+		conn, err = tls.DialWithDialer(dialer, "tcp", q.Address, q.tlsCfg)
+	}
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", q.Address, err)
+	}
+
+	if !q.Username.Empty() {
+		if err := q.authenticate(conn); err != nil {
+			conn.Close()
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+	q.conn = conn
+
+	return nil
+}
+
+func (q *QuestDB) Close() error {
+	if q.conn == nil {
+		return nil
+	}
+	err := q.conn.Close()
+	q.conn = nil
+	return err
+}
+
+func (q *QuestDB) Write(metrics []telegraf.Metric) error {
+	// QuestDB closes the connection on invalid data without any further
+	// response, so check if the server hung up on us after the last write.
+	if q.conn != nil && !q.alive() {
+		q.Log.Warn("Connection closed by server, the previous batch might have been rejected; check the QuestDB server log")
+		q.Close()
+	}
+	if q.conn == nil {
+		if err := q.Connect(); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, m := range metrics {
+		m, err := q.prepare(m)
+		if err != nil {
+			q.Log.Errorf("Generating table name for metric %q failed: %v", m.Name(), err)
+			continue
+		}
+		if err := q.serializer.Write(&buf, m); err != nil {
+			q.Log.Debugf("Could not serialize metric: %v", err)
+		}
+	}
+	if buf.Len() == 0 {
+		return nil
+	}
+
+	if q.Timeout > 0 {
+		if err := q.conn.SetWriteDeadline(time.Now().Add(time.Duration(q.Timeout))); err != nil {
+			return err
+		}
+	}
+	if _, err := q.conn.Write(buf.Bytes()); err != nil {
+		q.Close()
+		return fmt.Errorf("writing to %q failed: %w", q.Address, err)
+	}
+
+	return nil
+}
+
+// prepare returns the metric with the table name applied and the table and
+// column names sanitized. The metric is only copied if it needs modification.
+func (q *QuestDB) prepare(m telegraf.Metric) (telegraf.Metric, error) {
+	var b strings.Builder
+	if err := q.tableName.Execute(&b, &templating.MetricData{Metric: m}); err != nil {
+		return m, err
+	}
+	name := tableNameReplacer.Replace(b.String())
+	if name == "" {
+		return m, errors.New("empty table name")
+	}
+
+	modified := name != m.Name()
+	for _, tag := range m.TagList() {
+		modified = modified || columnNameReplacer.Replace(tag.Key) != tag.Key
+	}
+	for _, field := range m.FieldList() {
+		modified = modified || columnNameReplacer.Replace(field.Key) != field.Key
+	}
+	if !modified {
+		return m, nil
+	}
+
+	out := m.Copy()
+	out.SetName(name)
+	for _, tag := range m.TagList() {
+		if key := columnNameReplacer.Replace(tag.Key); key != tag.Key {
+			out.RemoveTag(tag.Key)
+			out.AddTag(key, tag.Value)
+		}
+	}
+	for _, field := range m.FieldList() {
+		if key := columnNameReplacer.Replace(field.Key); key != field.Key {
+			out.RemoveField(field.Key)
+			out.AddField(key, field.Value)
+		}
+	}
+	return out, nil
+}
+
+// alive checks if the connection was closed by the remote side
+func (q *QuestDB) alive() bool {
+	if err := q.conn.SetReadDeadline(time.Now().Add(time.Millisecond)); err != nil {
+		return false
+	}
+	defer q.conn.SetReadDeadline(time.Time{}) //nolint:errcheck // resetting the deadline only
+
+	var b [1]byte
+	_, err := q.conn.Read(b[:])
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// authenticate performs the challenge-response authentication of the QuestDB
+// ILP endpoint by signing the server challenge with the private key
+func (q *QuestDB) authenticate(conn net.Conn) error {
+	username, err := q.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer config.ReleaseSecret(username)
+
+	token, err := q.Token.Get()
+	if err != nil {
+		return fmt.Errorf("getting token failed: %w", err)
+	}
+	key, err := parsePrivateKey(token)
+	config.ReleaseSecret(token)
+	if err != nil {
+		return err
+	}
+
+	if q.Timeout > 0 {
+		if err := conn.SetDeadline(time.Now().Add(time.Duration(q.Timeout))); err != nil {
+			return err
+		}
+		defer conn.SetDeadline(time.Time{}) //nolint:errcheck // resetting the deadline only
+	}
+
+	if _, err := conn.Write(append(username, '\n')); err != nil {
+		return fmt.Errorf("sending key ID failed: %w", err)
+	}
+
+	challenge, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("connection closed by server, unknown key ID")
+		}
+		return fmt.Errorf("reading challenge failed: %w", err)
+	}
+	challenge = challenge[:len(challenge)-1]
+
+	hash := sha256.Sum256(challenge)
+	signature, err := ecdsa.SignASN1(rand.Reader, key, hash[:])
+	if err != nil {
+		return fmt.Errorf("signing challenge failed: %w", err)
+	}
+	response := base64.StdEncoding.EncodeToString(signature) + "\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		return fmt.Errorf("sending signature failed: %w", err)
+	}
+
+	return nil
+}
+
+// parsePrivateKey creates a P-256 private key from the base64url encoded "d"
+// component of a JSON Web Key as used by QuestDB
+func parsePrivateKey(token []byte) (*ecdsa.PrivateKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(string(token), "="))
+	if err != nil {
+		return nil, fmt.Errorf("decoding token failed: %w", err)
+	}
+	if len(raw) > 32 {
+		return nil, fmt.Errorf("invalid private key length %d", len(raw))
+	}
+	d := make([]byte, 32)
+	copy(d[32-len(raw):], raw)
+
+	// Validate the key and derive the public key, the latter is returned in
+	// uncompressed form i.e. 0x04 followed by the X and Y coordinates
+	priv, err := ecdh.P256().NewPrivateKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+	point := priv.PublicKey().Bytes()
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(point[1:33]),
+			Y:     new(big.Int).SetBytes(point[33:]),
+		},
+		D: new(big.Int).SetBytes(d),
+	}, nil
+}
+
+func invalidNameChars(extra string) []string {
+	chars := `.?,'"\/:()+*%~` + "\x00" + extra
+	replacements := make([]string, 0, 2*len(chars))
+	for _, c := range chars {
+		replacements = append(replacements, string(c), "_")
+	}
+	return replacements
+}
+
+func init() {
+	outputs.Add("questdb", func() telegraf.Output {
+		return &QuestDB{
+			Address:       "localhost:9009",
+			TableTemplate: "{{ .Name }}",
+			Timeout:       config.Duration(5 * time.Second),
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package questdb
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type ilpServer struct {
+	listener net.Listener
+	key      *ecdsa.PublicKey
+	lines    chan string
+}
+
+func newILPServer(t *testing.T, key *ecdsa.PublicKey) *ilpServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &ilpServer{
+		listener: listener,
+		key:      key,
+		lines:    make(chan string, 100),
+	}
+	go srv.serve()
+	t.Cleanup(func() { listener.Close() })
+	return srv
+}
+
+func (s *ilpServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *ilpServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	if s.key != nil {
+		kid, err := reader.ReadString('\n')
+		if err != nil || kid != "testuser\n" {
+			return
+		}
+		challenge := "abcdef0123456789"
+		if _, err := conn.Write([]byte(challenge + "\n")); err != nil {
+			return
+		}
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		signature, err := base64.StdEncoding.DecodeString(strings.TrimSuffix(response, "\n"))
+		if err != nil {
+			return
+		}
+		hash := sha256.Sum256([]byte(challenge))
+		if !ecdsa.VerifyASN1(s.key, hash[:], signature) {
+			// QuestDB closes the connection on failed authentication
+			return
+		}
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		s.lines <- line
+	}
+}
+
+func (s *ilpServer) receive(t *testing.T, n int) []string {
+	lines := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		select {
+		case line := <-s.lines:
+			lines = append(lines, line)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for lines", "received %d of %d", len(lines), n)
+		}
+	}
+	return lines
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &QuestDB{}
+	require.ErrorContains(t, plugin.Init(), "address required")
+
+	plugin = &QuestDB{Address: "localhost"}
+	require.ErrorContains(t, plugin.Init(), "invalid address")
+
+	plugin = &QuestDB{Address: "localhost:9009", Username: config.NewSecret([]byte("user"))}
+	require.ErrorContains(t, plugin.Init(), "username and token must be set together")
+
+	plugin = &QuestDB{Address: "localhost:9009", TableTemplate: "{{ .Name"}
+	require.ErrorContains(t, plugin.Init(), "parsing table template failed")
+}
+
+func TestWrite(t *testing.T) {
+	srv := newILPServer(t, nil)
+
+	plugin := &QuestDB{
+		Address: srv.listener.Addr().String(),
+		Timeout: config.Duration(time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Add the fields separately to get a deterministic field order
+	m := testutil.MustMetric(
+		"cpu",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"usage_idle": 42.0},
+		time.Unix(0, 1),
+	)
+	m.AddField("count", uint64(3))
+
+	metrics := []telegraf.Metric{
+		m,
+		testutil.MustMetric(
+			"disk.io",
+			map[string]string{"dev.name": "sda"},
+			map[string]interface{}{"read-bytes": int64(1)},
+			time.Unix(0, 2),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := []string{
+		"cpu,host=a usage_idle=42,count=3i 1\n",
+		"disk_io,dev_name=sda read_bytes=1i 2\n",
+	}
+	require.ElementsMatch(t, expected, srv.receive(t, 2))
+
+	// The input metrics must not be modified
+	require.Equal(t, "disk.io", metrics[1].Name())
+}
+
+func TestWriteTableTemplate(t *testing.T) {
+	srv := newILPServer(t, nil)
+
+	plugin := &QuestDB{
+		Address:       srv.listener.Addr().String(),
+		TableTemplate: `{{ .Tag "env" }}_{{ .Name }}`,
+		Timeout:       config.Duration(time.Second),
+		Log:           testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"env": "prod"},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 1),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, []string{"prod_cpu,env=prod value=1 1\n"}, srv.receive(t, 1))
+}
+
+func TestWriteAuthenticated(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	token := base64.RawURLEncoding.EncodeToString(key.D.FillBytes(make([]byte, 32)))
+
+	srv := newILPServer(t, &key.PublicKey)
+
+	plugin := &QuestDB{
+		Address:  srv.listener.Addr().String(),
+		Username: config.NewSecret([]byte("testuser")),
+		Token:    config.NewSecret([]byte(token)),
+		Timeout:  config.Duration(time.Second),
+		Log:      testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 1),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.Equal(t, []string{"cpu value=1 1\n"}, srv.receive(t, 1))
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	token := base64.RawURLEncoding.EncodeToString(key.D.FillBytes(make([]byte, 32)))
+
+	actual, err := parsePrivateKey([]byte(token))
+	require.NoError(t, err)
+	require.True(t, key.Equal(actual))
+
+	_, err = parsePrivateKey([]byte(base64.RawURLEncoding.EncodeToString(make([]byte, 32))))
+	require.ErrorContains(t, err, "invalid private key")
+	_, err = parsePrivateKey([]byte(base64.RawURLEncoding.EncodeToString(make([]byte, 33))))
+	require.ErrorContains(t, err, "invalid private key length 33")
+}
+
+func TestReconnectAfterServerClose(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		// First connection is closed immediately, second one is served
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		conn.Close()
+
+		conn, err = listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil {
+			lines <- line
+		}
+	}()
+
+	plugin := &QuestDB{
+		Address: listener.Addr().String(),
+		Timeout: config.Duration(time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	// Wait for the server to hang up
+	time.Sleep(50 * time.Millisecond)
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"value": 1.0},
+			time.Unix(0, 1),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	select {
+	case line := <-lines:
+		require.Equal(t, "cpu value=1 1\n", line)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout waiting for line")
+	}
+}
@@ -0,0 +1,304 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package redis
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/common/tls"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Redis struct {
+	Address         string            `toml:"address"`
+	Username        config.Secret     `toml:"username"`
+	Password        config.Secret     `toml:"password"`
+	Database        int               `toml:"database"`
+	Timeout         config.Duration   `toml:"timeout"`
+	Mode            string            `toml:"mode"`
+	Key             string            `toml:"key"`
+	StreamMaxLen    int64             `toml:"stream_maxlen"`
+	StreamExact     bool              `toml:"stream_maxlen_exact"`
+	StreamField     string            `toml:"stream_field"`
+	Retention       config.Duration   `toml:"retention"`
+	DuplicatePolicy string            `toml:"duplicate_policy"`
+	LabelTags       []string          `toml:"label_tags"`
+	LabelMapping    map[string]string `toml:"label_mapping"`
+	Log             telegraf.Logger   `toml:"-"`
+	tls.ClientConfig
+
+	key        *template.Template
+	labelTags  filter.Filter
+	serializer serializers.Serializer
+	client     *redis.Client
+}
+
+// keyData is passed to the key template. For time series, the name of the
+// field the series is written for is available.
+type keyData struct {
+	telegraf.TemplateMetric
+	field string
+}
+
+func (k *keyData) FieldName() string {
+	return k.field
+}
+
+func (*Redis) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Redis) SetSerializer(serializer serializers.Serializer) {
+	r.serializer = serializer
+}
+
+func (r *Redis) Init() error {
+	if r.Address == "" {
+		return errors.New("redis address must be specified")
+	}
+
+	if err := choice.Check(r.Mode, []string{"stream", "timeseries"}); err != nil {
+		return fmt.Errorf("invalid 'mode': %w", err)
+	}
+	if r.Key == "" {
+		if r.Mode == "stream" {
+			r.Key = "telegraf:{{.Name}}"
+		} else {
+			r.Key = "{{.Name}}:{{.FieldName}}"
+		}
+	}
+
+	var err error
+	r.key, err = template.New("key").Parse(r.Key)
+	if err != nil {
+		return fmt.Errorf("parsing key template failed: %w", err)
+	}
+
+	switch r.Mode {
+	case "stream":
+		if r.StreamMaxLen < 0 {
+			return errors.New("'stream_maxlen' must not be negative")
+		}
+		if r.StreamField == "" {
+			r.StreamField = "metric"
+		}
+	case "timeseries":
+		if r.Retention < 0 {
+			return errors.New("'retention' must not be negative")
+		}
+		if r.DuplicatePolicy != "" {
+			policies := []string{"block", "first", "last", "min", "max", "sum"}
+			if err := choice.Check(r.DuplicatePolicy, policies); err != nil {
+				return fmt.Errorf("invalid 'duplicate_policy': %w", err)
+			}
+		}
+		r.labelTags, err = filter.Compile(r.LabelTags)
+		if err != nil {
+			return fmt.Errorf("creating label filter failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Redis) Connect() error {
+	username, err := r.Username.Get()
+	if err != nil {
+		return fmt.Errorf("getting username failed: %w", err)
+	}
+	defer config.ReleaseSecret(username)
+
+	password, err := r.Password.Get()
+	if err != nil {
+		return fmt.Errorf("getting password failed: %w", err)
+	}
+	defer config.ReleaseSecret(password)
+
+	tlsCfg, err := r.ClientConfig.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	r.client = redis.NewClient(&redis.Options{
+		Addr:         r.Address,
+		Username:     string(username),
+		Password:     string(password),
+		DB:           r.Database,
+		DialTimeout:  time.Duration(r.Timeout),
+		ReadTimeout:  time.Duration(r.Timeout),
+		WriteTimeout: time.Duration(r.Timeout),
+		TLSConfig:    tlsCfg,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
+	defer cancel()
+	return r.client.Ping(ctx).Err()
+}
+
+func (r *Redis) Close() error {
+	if r.client == nil {
+		return nil
+	}
+	return r.client.Close()
+}
+
+func (r *Redis) Write(metrics []telegraf.Metric) error {
+	var commands [][]interface{}
+	for _, m := range metrics {
+		var cmds [][]interface{}
+		var err error
+		if r.Mode == "stream" {
+			cmds, err = r.streamCommands(m)
+		} else {
+			cmds, err = r.timeseriesCommands(m)
+		}
+		if err != nil {
+			r.Log.Errorf("Dropping metric %q: %v", m.Name(), err)
+			continue
+		}
+		commands = append(commands, cmds...)
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.Timeout))
+	defer cancel()
+
+	pipe := r.client.Pipeline()
+	for _, args := range commands {
+		pipe.Do(ctx, args...)
+	}
+	cmds, err := pipe.Exec(ctx)
+	if err != nil {
+		// Return the first failing command for a meaningful error message
+		for _, cmd := range cmds {
+			if cmd.Err() != nil {
+				return fmt.Errorf("executing %q failed: %w", cmd.Name(), cmd.Err())
+			}
+		}
+		return fmt.Errorf("executing pipeline failed: %w", err)
+	}
+
+	return nil
+}
+
+// streamCommands returns the XADD command appending the serialized metric to
+// the stream
+func (r *Redis) streamCommands(m telegraf.Metric) ([][]interface{}, error) {
+	key, err := r.renderKey(m, "")
+	if err != nil {
+		return nil, err
+	}
+	payload, err := r.serializer.Serialize(m)
+	if err != nil {
+		return nil, fmt.Errorf("serializing failed: %w", err)
+	}
+
+	args := []interface{}{"XADD", key}
+	if r.StreamMaxLen > 0 {
+		args = append(args, "MAXLEN")
+		if !r.StreamExact {
+			args = append(args, "~")
+		}
+		args = append(args, r.StreamMaxLen)
+	}
+	args = append(args, "*", r.StreamField, payload)
+
+	return [][]interface{}{args}, nil
+}
+
+// timeseriesCommands returns a TS.ADD command for each numeric field of the
+// metric. The labels are only used by Redis when the series is created.
+func (r *Redis) timeseriesCommands(m telegraf.Metric) ([][]interface{}, error) {
+	labels := []interface{}{"measurement", m.Name()}
+	tags := m.TagList()
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	for _, tag := range tags {
+		if r.labelTags != nil && !r.labelTags.Match(tag.Key) {
+			continue
+		}
+		name := tag.Key
+		if mapped, found := r.LabelMapping[tag.Key]; found {
+			name = mapped
+		}
+		labels = append(labels, name, tag.Value)
+	}
+
+	timestamp := m.Time().UnixMilli()
+	commands := make([][]interface{}, 0, len(m.FieldList()))
+	for _, field := range m.FieldList() {
+		var value float64
+		switch v := field.Value.(type) {
+		case float64:
+			value = v
+		case int64:
+			value = float64(v)
+		case uint64:
+			value = float64(v)
+		case bool:
+			if v {
+				value = 1
+			}
+		default:
+			r.Log.Debugf("Ignoring non-numeric field %q of metric %q", field.Key, m.Name())
+			continue
+		}
+
+		key, err := r.renderKey(m, field.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		args := []interface{}{"TS.ADD", key, timestamp, value}
+		if r.Retention > 0 {
+			args = append(args, "RETENTION", time.Duration(r.Retention).Milliseconds())
+		}
+		if r.DuplicatePolicy != "" {
+			args = append(args, "ON_DUPLICATE", r.DuplicatePolicy)
+		}
+		args = append(args, "LABELS")
+		args = append(args, labels...)
+		args = append(args, "field", field.Key)
+		commands = append(commands, args)
+	}
+
+	return commands, nil
+}
+
+func (r *Redis) renderKey(m telegraf.Metric, field string) (string, error) {
+	tm, ok := m.(telegraf.TemplateMetric)
+	if !ok {
+		return "", errors.New("metric is not a template metric")
+	}
+	var buf bytes.Buffer
+	if err := r.key.Execute(&buf, &keyData{TemplateMetric: tm, field: field}); err != nil {
+		return "", fmt.Errorf("executing key template failed: %w", err)
+	}
+	return buf.String(), nil
+}
+
+func init() {
+	outputs.Add("redis", func() telegraf.Output {
+		return &Redis{
+			Mode:    "stream",
+			Timeout: config.Duration(5 * time.Second),
+		}
+	})
+}
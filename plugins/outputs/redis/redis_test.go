@@ -0,0 +1,202 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers/influx"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Redis
+		expected string
+	}{
+		{
+			name:     "no address",
+			plugin:   &Redis{Mode: "stream"},
+			expected: "address must be specified",
+		},
+		{
+			name:     "invalid mode",
+			plugin:   &Redis{Address: "localhost:6379", Mode: "list"},
+			expected: "invalid 'mode'",
+		},
+		{
+			name:     "invalid key",
+			plugin:   &Redis{Address: "localhost:6379", Mode: "stream", Key: "{{.Name"},
+			expected: "parsing key template failed",
+		},
+		{
+			name:     "invalid duplicate policy",
+			plugin:   &Redis{Address: "localhost:6379", Mode: "timeseries", DuplicatePolicy: "newest"},
+			expected: "invalid 'duplicate_policy'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestStreamCommands(t *testing.T) {
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+
+	plugin := &Redis{
+		Address:      "localhost:6379",
+		Mode:         "stream",
+		Key:          `metrics:{{.Tag "host"}}`,
+		StreamMaxLen: 1000,
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5}, time.Unix(1677628800, 0))
+	actual, err := plugin.streamCommands(m)
+	require.NoError(t, err)
+	require.Equal(t, [][]interface{}{
+		{"XADD", "metrics:a", "MAXLEN", "~", int64(1000), "*", "metric", []byte("cpu,host=a usage=42.5 1677628800000000000\n")},
+	}, actual)
+
+	// Exact trimming
+	plugin.StreamExact = true
+	actual, err = plugin.streamCommands(m)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{"XADD", "metrics:a", "MAXLEN", int64(1000), "*"}, actual[0][:5])
+}
+
+func TestTimeseriesCommands(t *testing.T) {
+	plugin := &Redis{
+		Address:         "localhost:6379",
+		Mode:            "timeseries",
+		Retention:       config.Duration(time.Hour),
+		DuplicatePolicy: "last",
+		LabelTags:       []string{"host", "region"},
+		LabelMapping:    map[string]string{"host": "hostname"},
+		Log:             testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New(
+		"cpu",
+		map[string]string{"host": "a", "region": "eu", "cpu": "cpu0"},
+		map[string]interface{}{"count": int64(3)},
+		time.Unix(1677628800, 0),
+	)
+	m.AddField("ok", true)
+	m.AddField("state", "running")
+	m.AddField("usage", 42.5)
+	actual, err := plugin.timeseriesCommands(m)
+	require.NoError(t, err)
+
+	labels := []interface{}{"LABELS", "measurement", "cpu", "hostname", "a", "region", "eu", "field"}
+	expected := make([][]interface{}, 0, 3)
+	for _, sample := range []struct {
+		field string
+		value float64
+	}{{"count", 3}, {"ok", 1}, {"usage", 42.5}} {
+		args := []interface{}{"TS.ADD", "cpu:" + sample.field, int64(1677628800000), sample.value, "RETENTION", int64(3600000), "ON_DUPLICATE", "last"}
+		args = append(args, labels...)
+		args = append(args, sample.field)
+		expected = append(expected, args)
+	}
+	require.Equal(t, expected, actual)
+}
+
+func TestStreamIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	address := startRedis(t)
+
+	serializer := &influx.Serializer{}
+	require.NoError(t, serializer.Init())
+	plugin := &Redis{
+		Address:      address,
+		Mode:         "stream",
+		StreamMaxLen: 2,
+		StreamExact:  true,
+		Timeout:      config.Duration(5 * time.Second),
+		Log:          testutil.Logger{},
+	}
+	plugin.SetSerializer(serializer)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	var metrics []telegraf.Metric
+	for i := 0; i < 3; i++ {
+		metrics = append(metrics, metric.New("cpu", map[string]string{}, map[string]interface{}{"value": i}, time.Unix(int64(i), 0)))
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	client := redis.NewClient(&redis.Options{Addr: address})
+	defer client.Close()
+	entries, err := client.XRange(context.Background(), "telegraf:cpu", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "cpu value=2i 2000000000\n", entries[1].Values["metric"])
+}
+
+func TestTimeseriesIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	address := startRedis(t)
+
+	plugin := &Redis{
+		Address: address,
+		Mode:    "timeseries",
+		Timeout: config.Duration(5 * time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 42.5}, time.Unix(1, 0)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 43.5}, time.Unix(2, 0)),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	client := redis.NewClient(&redis.Options{Addr: address})
+	defer client.Close()
+	samples, err := client.Do(context.Background(), "TS.RANGE", "cpu:usage", "-", "+").Slice()
+	require.NoError(t, err)
+	require.Len(t, samples, 2)
+
+	labels, err := client.Do(context.Background(), "TS.QUERYINDEX", "host=a").StringSlice()
+	require.NoError(t, err)
+	require.Equal(t, []string{"cpu:usage"}, labels)
+}
+
+func startRedis(t *testing.T) string {
+	servicePort := "6379"
+	container := testutil.Container{
+		Image:        "redis/redis-stack-server",
+		ExposedPorts: []string{servicePort},
+		WaitingFor:   wait.ForListeningPort(nat.Port(servicePort)),
+	}
+	require.NoError(t, container.Start(), "failed to start container")
+	t.Cleanup(container.Terminate)
+	return fmt.Sprintf("%s:%s", container.Address, container.Ports[servicePort])
+}
@@ -0,0 +1,497 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package splunk_hec
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	httpconfig "github.com/influxdata/telegraf/plugins/common/http"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+const (
+	maxErrMsgLen = 1024
+
+	eventEndpoint = "/services/collector/event"
+	ackEndpoint   = "/services/collector/ack"
+)
+
+type SplunkHEC struct {
+	URL             string          `toml:"url"`
+	Token           config.Secret   `toml:"token"`
+	Index           string          `toml:"index"`
+	IndexTag        string          `toml:"index_tag"`
+	Sourcetype      string          `toml:"sourcetype"`
+	SourcetypeTag   string          `toml:"sourcetype_tag"`
+	Source          string          `toml:"source"`
+	SourceTag       string          `toml:"source_tag"`
+	Host            string          `toml:"host"`
+	HostTag         string          `toml:"host_tag"`
+	MultiMetric     bool            `toml:"multimetric"`
+	MaxPayloadSize  config.Size     `toml:"max_payload_size"`
+	UseAck          bool            `toml:"use_ack"`
+	AckTimeout      config.Duration `toml:"ack_timeout"`
+	AckPollInterval config.Duration `toml:"ack_poll_interval"`
+	Channel         string          `toml:"channel"`
+	ContentEncoding string          `toml:"content_encoding"`
+	Log             telegraf.Logger `toml:"-"`
+	httpconfig.HTTPClientConfig
+
+	client *http.Client
+}
+
+type event struct {
+	Time       float64                `json:"time"`
+	Event      string                 `json:"event"`
+	Host       string                 `json:"host,omitempty"`
+	Index      string                 `json:"index,omitempty"`
+	Source     string                 `json:"source,omitempty"`
+	Sourcetype string                 `json:"sourcetype,omitempty"`
+	Fields     map[string]interface{} `json:"fields"`
+}
+
+type response struct {
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+	AckID *int64 `json:"ackId"`
+}
+
+func (*SplunkHEC) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SplunkHEC) Init() error {
+	if s.URL == "" {
+		return errors.New("url required")
+	}
+	s.URL = strings.TrimSuffix(s.URL, "/")
+
+	if s.Token.Empty() {
+		return errors.New("token required")
+	}
+
+	switch s.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("invalid content encoding %q", s.ContentEncoding)
+	}
+
+	if s.MaxPayloadSize <= 0 {
+		return errors.New("max_payload_size must be positive")
+	}
+
+	if s.Channel == "" {
+		s.Channel = uuid.New().String()
+	} else if _, err := uuid.Parse(s.Channel); err != nil {
+		return fmt.Errorf("invalid channel %q: %w", s.Channel, err)
+	}
+
+	if s.UseAck && s.AckPollInterval <= 0 {
+		return errors.New("ack_poll_interval must be positive")
+	}
+
+	return nil
+}
+
+func (s *SplunkHEC) Connect() error {
+	client, err := s.HTTPClientConfig.CreateClient(context.Background(), s.Log)
+	if err != nil {
+		return err
+	}
+	s.client = client
+
+	return nil
+}
+
+func (s *SplunkHEC) Close() error {
+	if s.client != nil {
+		s.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// chunk is the payload of a single request together with the indices of the
+// metrics contributing events to it
+type chunk struct {
+	payload []byte
+	metrics []int
+}
+
+// Write sends the metrics in chunks not exceeding the payload size limit. If
+// only some of the chunks were sent (and acknowledged), the metrics of the
+// failed chunks are returned as partial write to not send the others again.
+func (s *SplunkHEC) Write(metrics []telegraf.Metric) error {
+	chunks := s.chunks(metrics)
+
+	var sendErr error
+	sent := 0
+	ackIDs := make(map[int64]*chunk)
+	for _, c := range chunks {
+		ackID, err := s.send(c.payload)
+		if err != nil {
+			sendErr = err
+			break
+		}
+		if ackID != nil {
+			ackIDs[*ackID] = c
+		}
+		sent++
+	}
+
+	// Chunks not sent or not acknowledged failed
+	failedChunks := chunks[sent:]
+	var ackErr error
+	if s.UseAck && len(ackIDs) > 0 {
+		pending := make(map[int64]bool, len(ackIDs))
+		for id := range ackIDs {
+			pending[id] = true
+		}
+		ackErr = s.waitForAcks(pending)
+		for id := range pending {
+			failedChunks = append(failedChunks, ackIDs[id])
+		}
+	}
+
+	err := errors.Join(sendErr, ackErr)
+	if err == nil {
+		return nil
+	}
+
+	// A metric with events in multiple chunks is sent again as a whole if any
+	// of its chunks failed
+	failed := make(map[int]bool)
+	for _, c := range failedChunks {
+		for _, i := range c.metrics {
+			failed[i] = true
+		}
+	}
+	if len(failed) == 0 || len(failed) == len(metrics) {
+		return err
+	}
+	indices := make([]int, 0, len(failed))
+	for i := range failed {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return &internal.PartialWriteError{Err: err, MetricsFailed: indices}
+}
+
+// chunks serializes the events of the metrics and splits them into payloads
+// not exceeding the size limit
+func (s *SplunkHEC) chunks(metrics []telegraf.Metric) []*chunk {
+	limit := int(s.MaxPayloadSize)
+
+	var chunks []*chunk
+	current := &chunk{}
+	add := func(idx int, serialized []byte) {
+		if len(serialized) > limit {
+			s.Log.Errorf("Dropping event of %d bytes exceeding the payload size limit of %d bytes", len(serialized), limit)
+			return
+		}
+		if len(current.payload) > 0 && len(current.payload)+len(serialized) > limit {
+			chunks = append(chunks, current)
+			current = &chunk{}
+		}
+		current.payload = append(current.payload, serialized...)
+		if n := len(current.metrics); n == 0 || current.metrics[n-1] != idx {
+			current.metrics = append(current.metrics, idx)
+		}
+	}
+
+	for i, m := range metrics {
+		for _, ev := range s.events(m) {
+			serialized, err := json.Marshal(ev)
+			if err != nil {
+				s.Log.Errorf("Serializing event for metric %q failed: %v", m.Name(), err)
+				continue
+			}
+
+			// Multi-metric events exceeding the limit are split into
+			// single-metric events
+			if len(serialized) > limit && len(ev.Fields) > 1 && s.MultiMetric {
+				for _, single := range s.splitEvent(ev) {
+					b, err := json.Marshal(single)
+					if err != nil {
+						s.Log.Errorf("Serializing event for metric %q failed: %v", m.Name(), err)
+						continue
+					}
+					add(i, b)
+				}
+				continue
+			}
+			add(i, serialized)
+		}
+	}
+	if len(current.payload) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+func (s *SplunkHEC) events(m telegraf.Metric) []*event {
+	base := event{
+		Time:       float64(m.Time().UnixNano()) / float64(time.Second),
+		Event:      "metric",
+		Host:       s.Host,
+		Index:      s.Index,
+		Source:     s.Source,
+		Sourcetype: s.Sourcetype,
+	}
+
+	dimensions := make(map[string]interface{}, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		switch tag.Key {
+		case "":
+			continue
+		case s.IndexTag:
+			base.Index = tag.Value
+		case s.SourcetypeTag:
+			base.Sourcetype = tag.Value
+		case s.SourceTag:
+			base.Source = tag.Value
+		case s.HostTag:
+			base.Host = tag.Value
+		default:
+			dimensions[tag.Key] = tag.Value
+		}
+	}
+
+	var values []*telegraf.Field
+	for _, field := range m.FieldList() {
+		if v, ok := convertValue(field.Value); ok {
+			values = append(values, &telegraf.Field{Key: field.Key, Value: v})
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	if s.MultiMetric {
+		ev := base
+		ev.Fields = make(map[string]interface{}, len(dimensions)+len(values))
+		for k, v := range dimensions {
+			ev.Fields[k] = v
+		}
+		for _, field := range values {
+			ev.Fields["metric_name:"+m.Name()+"."+field.Key] = field.Value
+		}
+		return []*event{&ev}
+	}
+
+	events := make([]*event, 0, len(values))
+	for _, field := range values {
+		ev := base
+		ev.Fields = make(map[string]interface{}, len(dimensions)+2)
+		for k, v := range dimensions {
+			ev.Fields[k] = v
+		}
+		ev.Fields["metric_name"] = m.Name() + "." + field.Key
+		ev.Fields["_value"] = field.Value
+		events = append(events, &ev)
+	}
+	return events
+}
+
+// splitEvent splits a multi-metric event into one event per metric value
+func (*SplunkHEC) splitEvent(ev *event) []*event {
+	dimensions := make(map[string]interface{})
+	values := make(map[string]interface{})
+	for k, v := range ev.Fields {
+		if strings.HasPrefix(k, "metric_name:") {
+			values[k] = v
+		} else {
+			dimensions[k] = v
+		}
+	}
+
+	events := make([]*event, 0, len(values))
+	for k, v := range values {
+		single := *ev
+		single.Fields = make(map[string]interface{}, len(dimensions)+1)
+		for dk, dv := range dimensions {
+			single.Fields[dk] = dv
+		}
+		single.Fields[k] = v
+		events = append(events, &single)
+	}
+	return events
+}
+
+func (s *SplunkHEC) send(payload []byte) (*int64, error) {
+	var body io.Reader = bytes.NewBuffer(payload)
+	if s.ContentEncoding == "gzip" {
+		rc := internal.CompressWithGzip(body)
+		defer rc.Close()
+		body = rc
+	}
+
+	req, err := s.newRequest(s.URL+eventEndpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if s.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errorLine := ""
+		scanner := bufio.NewScanner(io.LimitReader(resp.Body, maxErrMsgLen))
+		if scanner.Scan() {
+			errorLine = scanner.Text()
+		}
+		return nil, fmt.Errorf("when writing to [%s] received status code: %d. body: %s", s.URL, resp.StatusCode, errorLine)
+	}
+
+	if !s.UseAck {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return nil, err
+	}
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	if r.AckID == nil {
+		return nil, errors.New("no acknowledgement ID in response, is indexer acknowledgement enabled for the token?")
+	}
+	return r.AckID, nil
+}
+
+// waitForAcks polls the acknowledgement endpoint until all pending IDs are
+// acknowledged or the timeout is exceeded. Acknowledged IDs are removed from
+// the pending set.
+func (s *SplunkHEC) waitForAcks(pending map[int64]bool) error {
+	total := len(pending)
+	deadline := time.Now().Add(time.Duration(s.AckTimeout))
+	for {
+		if err := s.pollAcks(pending); err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d of %d requests not acknowledged within %s", len(pending), total, time.Duration(s.AckTimeout))
+		}
+		time.Sleep(time.Duration(s.AckPollInterval))
+	}
+}
+
+func (s *SplunkHEC) pollAcks(pending map[int64]bool) error {
+	ids := make([]int64, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+	payload, err := json.Marshal(map[string][]int64{"acks": ids})
+	if err != nil {
+		return err
+	}
+
+	req, err := s.newRequest(s.URL+ackEndpoint+"?channel="+s.Channel, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("when querying acknowledgements from [%s] received status code: %d", s.URL, resp.StatusCode)
+	}
+
+	var r struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("decoding acknowledgement response failed: %w", err)
+	}
+	for k, acked := range r.Acks {
+		id, err := strconv.ParseInt(k, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid acknowledgement ID %q: %w", k, err)
+		}
+		if acked {
+			delete(pending, id)
+		}
+	}
+
+	return nil
+}
+
+func (s *SplunkHEC) newRequest(url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.Token.Get()
+	if err != nil {
+		return nil, fmt.Errorf("getting token failed: %w", err)
+	}
+	req.Header.Set("Authorization", "Splunk "+string(token))
+	config.ReleaseSecret(token)
+
+	req.Header.Set("User-Agent", internal.ProductToken())
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Splunk-Request-Channel", s.Channel)
+
+	return req, nil
+}
+
+func convertValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string:
+		return nil, false
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return v, true
+	}
+}
+
+func init() {
+	outputs.Add("splunk_hec", func() telegraf.Output {
+		return &SplunkHEC{
+			IndexTag:        "splunk_index",
+			SourcetypeTag:   "splunk_sourcetype",
+			SourceTag:       "splunk_source",
+			HostTag:         "host",
+			MultiMetric:     true,
+			MaxPayloadSize:  config.Size(1024 * 1024),
+			AckTimeout:      config.Duration(30 * time.Second),
+			AckPollInterval: config.Duration(time.Second),
+		}
+	})
+}
@@ -0,0 +1,347 @@
+package splunk_hec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type hecServer struct {
+	sync.Mutex
+	requests [][]event
+	channels []string
+	acked    bool
+	nextID   int64
+}
+
+func (hs *hecServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	hs.Lock()
+	defer hs.Unlock()
+
+	if r.Header.Get("Authorization") != "Splunk mytoken" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	hs.channels = append(hs.channels, r.Header.Get("X-Splunk-Request-Channel"))
+
+	switch r.URL.Path {
+	case eventEndpoint:
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var events []event
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		for decoder.More() {
+			var ev event
+			if err := decoder.Decode(&ev); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			events = append(events, ev)
+		}
+		hs.requests = append(hs.requests, events)
+		_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":` + strconv.FormatInt(hs.nextID, 10) + `}`))
+		hs.nextID++
+	case ackEndpoint:
+		var req struct {
+			Acks []int64 `json:"acks"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		acks := make(map[int64]bool, len(req.Acks))
+		for _, id := range req.Acks {
+			acks[id] = hs.acked
+		}
+		// Acknowledge on the second poll
+		hs.acked = true
+		_ = json.NewEncoder(w).Encode(map[string]map[int64]bool{"acks": acks})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newPlugin(url string) *SplunkHEC {
+	return &SplunkHEC{
+		URL:             url,
+		Token:           config.NewSecret([]byte("mytoken")),
+		IndexTag:        "splunk_index",
+		SourcetypeTag:   "splunk_sourcetype",
+		SourceTag:       "splunk_source",
+		HostTag:         "host",
+		MultiMetric:     true,
+		MaxPayloadSize:  config.Size(1024 * 1024),
+		AckTimeout:      config.Duration(5 * time.Second),
+		AckPollInterval: config.Duration(10 * time.Millisecond),
+		Log:             testutil.Logger{},
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := newPlugin("")
+	require.ErrorContains(t, plugin.Init(), "url required")
+
+	plugin = newPlugin("http://localhost")
+	plugin.Token = config.NewSecret(nil)
+	require.ErrorContains(t, plugin.Init(), "token required")
+
+	plugin = newPlugin("http://localhost")
+	plugin.Channel = "foo"
+	require.ErrorContains(t, plugin.Init(), "invalid channel")
+}
+
+func TestWriteRouting(t *testing.T) {
+	server := &hecServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.Index = "metrics"
+	plugin.Sourcetype = "telegraf"
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 42.0, "usage_user": 12.0, "info": "ignored"},
+			time.Unix(1, 500000000),
+		),
+		testutil.MustMetric(
+			"mem",
+			map[string]string{"host": "b", "splunk_index": "edge", "splunk_sourcetype": "mem"},
+			map[string]interface{}{"used": int64(1024)},
+			time.Unix(2, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := [][]event{
+		{
+			{
+				Time:       1.5,
+				Event:      "metric",
+				Host:       "a",
+				Index:      "metrics",
+				Sourcetype: "telegraf",
+				Fields: map[string]interface{}{
+					"cpu":                        "cpu0",
+					"metric_name:cpu.usage_idle": 42.0,
+					"metric_name:cpu.usage_user": 12.0,
+				},
+			},
+			{
+				Time:       2,
+				Event:      "metric",
+				Host:       "b",
+				Index:      "edge",
+				Sourcetype: "mem",
+				Fields: map[string]interface{}{
+					"metric_name:mem.used": 1024.0,
+				},
+			},
+		},
+	}
+	require.Equal(t, expected, server.requests)
+	for _, ch := range server.channels {
+		require.Equal(t, plugin.Channel, ch)
+	}
+}
+
+func TestWriteSingleMetric(t *testing.T) {
+	server := &hecServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.MultiMetric = false
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{"cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 42.0},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := [][]event{
+		{
+			{
+				Time:  1,
+				Event: "metric",
+				Fields: map[string]interface{}{
+					"cpu":         "cpu0",
+					"metric_name": "cpu.usage_idle",
+					"_value":      42.0,
+				},
+			},
+		},
+	}
+	require.Equal(t, expected, server.requests)
+}
+
+func TestWriteSplitPayload(t *testing.T) {
+	server := &hecServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.MaxPayloadSize = config.Size(150)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"a": 1.0},
+			time.Unix(1, 0),
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"b": 2.0},
+			time.Unix(2, 0),
+		),
+		// Too large for one event so it gets split into single events
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"field_with_a_long_name_1": 1.0,
+				"field_with_a_long_name_2": 2.0,
+				"field_with_a_long_name_3": 3.0,
+			},
+			time.Unix(3, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	var total int
+	for _, req := range server.requests {
+		size := 0
+		for _, ev := range req {
+			buf, err := json.Marshal(ev)
+			require.NoError(t, err)
+			size += len(buf)
+			total += len(ev.Fields)
+		}
+		require.LessOrEqual(t, size, 150)
+	}
+	require.Greater(t, len(server.requests), 1)
+	require.Equal(t, 5, total)
+}
+
+func TestWriteAck(t *testing.T) {
+	server := &hecServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.UseAck = true
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"usage_idle": 42.0},
+			time.Unix(1, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+	require.True(t, server.acked)
+}
+
+func TestWriteAckTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case eventEndpoint:
+			_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":0}`))
+		case ackEndpoint:
+			_, _ = w.Write([]byte(`{"acks":{"0":false}}`))
+		}
+	}))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.UseAck = true
+	plugin.AckTimeout = config.Duration(50 * time.Millisecond)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"usage_idle": 42.0},
+			time.Unix(1, 0),
+		),
+	}
+	require.ErrorContains(t, plugin.Write(metrics), "not acknowledged")
+}
+
+func TestWritePartial(t *testing.T) {
+	// Fail every request after the first one
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+	}))
+	defer ts.Close()
+
+	plugin := newPlugin(ts.URL)
+	plugin.MaxPayloadSize = config.Size(100)
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := make([]telegraf.Metric, 0, 3)
+	for i := 0; i < 3; i++ {
+		metrics = append(metrics, testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{"usage_idle": float64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	// Each metric is sent in its own request, only the first one succeeds
+	err := plugin.Write(metrics)
+	var partial *internal.PartialWriteError
+	require.ErrorAs(t, err, &partial)
+	require.Equal(t, []int{1, 2}, partial.MetricsFailed)
+	require.Equal(t, 2, requests)
+}
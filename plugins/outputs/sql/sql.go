@@ -4,6 +4,7 @@ package sql
 import (
 	gosql "database/sql"
 	_ "embed"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -40,12 +41,16 @@ type SQL struct {
 	TimestampColumn       string
 	TableTemplate         string
 	TableExistsTemplate   string
-	InitSQL               string `toml:"init_sql"`
+	TableTemplates        map[string]string `toml:"table_templates"`
+	InitSQL               string            `toml:"init_sql"`
 	Convert               ConvertStruct
 	ConnectionMaxIdleTime config.Duration
 	ConnectionMaxLifetime config.Duration
 	ConnectionMaxIdle     int
 	ConnectionMaxOpen     int
+	InsertBatchSize       int      `toml:"insert_batch_size"`
+	ConflictHandling      string   `toml:"conflict_handling"`
+	ConflictColumns       []string `toml:"conflict_columns"`
 
 	db     *gosql.DB
 	Log    telegraf.Logger `toml:"-"`
@@ -56,6 +61,27 @@ func (*SQL) SampleConfig() string {
 	return sampleConfig
 }
 
+func (p *SQL) Init() error {
+	if p.InsertBatchSize < 1 {
+		return errors.New("insert_batch_size must be at least one")
+	}
+
+	switch p.ConflictHandling {
+	case "", "none":
+		p.ConflictHandling = ""
+	case "ignore", "update":
+		switch p.Driver {
+		case "pgx", "sqlite", "mysql", "mssql":
+		default:
+			return fmt.Errorf("conflict handling is not supported for driver %q", p.Driver)
+		}
+	default:
+		return fmt.Errorf("invalid conflict_handling %q", p.ConflictHandling)
+	}
+
+	return nil
+}
+
 func (p *SQL) Connect() error {
 	db, err := gosql.Open(p.Driver, p.DataSourceName)
 	if err != nil {
@@ -158,36 +184,130 @@ func (p *SQL) generateCreateTable(metric telegraf.Metric) string {
 		columns = append(columns, fmt.Sprintf("%s %s", quoteIdent(field.Key), datatype))
 	}
 
+	keyColumns := p.keyColumns(metric)
+	quotedKeys := make([]string, 0, len(keyColumns))
+	for _, column := range keyColumns {
+		quotedKeys = append(quotedKeys, quoteIdent(column))
+	}
+
 	query := p.TableTemplate
+	if tmpl, found := p.TableTemplates[metric.Name()]; found {
+		query = tmpl
+	}
 	query = strings.ReplaceAll(query, "{TABLE}", quoteIdent(metric.Name()))
 	query = strings.ReplaceAll(query, "{TABLELITERAL}", quoteStr(metric.Name()))
 	query = strings.ReplaceAll(query, "{COLUMNS}", strings.Join(columns, ","))
+	query = strings.ReplaceAll(query, "{KEY_COLUMNS}", strings.Join(quotedKeys, ","))
 
 	return query
 }
 
-func (p *SQL) generateInsert(tablename string, columns []string) string {
-	placeholders := make([]string, 0, len(columns))
+// keyColumns returns the columns identifying a row of the metric, used for
+// resolving conflicts on insert
+func (p *SQL) keyColumns(metric telegraf.Metric) []string {
+	if len(p.ConflictColumns) > 0 {
+		return p.ConflictColumns
+	}
+
+	columns := make([]string, 0, len(metric.TagList())+1)
+	if p.TimestampColumn != "" {
+		columns = append(columns, p.TimestampColumn)
+	}
+	for _, tag := range metric.TagList() {
+		columns = append(columns, tag.Key)
+	}
+	return columns
+}
+
+func (p *SQL) generateInsert(tablename string, columns []string, keys []string, rows int) string {
 	quotedColumns := make([]string, 0, len(columns))
 	for _, column := range columns {
 		quotedColumns = append(quotedColumns, quoteIdent(column))
 	}
-	if p.Driver == "pgx" {
-		// Postgres uses $1 $2 $3 as placeholders
-		for i := 0; i < len(columns); i++ {
-			placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+
+	tuples := make([]string, 0, rows)
+	placeholders := make([]string, 0, len(columns))
+	for row := 0; row < rows; row++ {
+		placeholders = placeholders[:0]
+		if p.Driver == "pgx" {
+			// Postgres uses $1 $2 $3 as placeholders
+			for i := 0; i < len(columns); i++ {
+				placeholders = append(placeholders, fmt.Sprintf("$%d", row*len(columns)+i+1))
+			}
+		} else {
+			// Everything else uses ? ? ? as placeholders
+			for i := 0; i < len(columns); i++ {
+				placeholders = append(placeholders, "?")
+			}
 		}
-	} else {
-		// Everything else uses ? ? ? as placeholders
-		for i := 0; i < len(columns); i++ {
-			placeholders = append(placeholders, "?")
+		tuples = append(tuples, "("+strings.Join(placeholders, ",")+")")
+	}
+
+	table := quoteIdent(tablename)
+	columnList := strings.Join(quotedColumns, ",")
+	values := strings.Join(tuples, ",")
+
+	if p.ConflictHandling == "" {
+		return fmt.Sprintf("INSERT INTO %s(%s) VALUES%s", table, columnList, values)
+	}
+
+	isKey := make(map[string]bool, len(keys))
+	quotedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		isKey[key] = true
+		quotedKeys = append(quotedKeys, quoteIdent(key))
+	}
+	var updateColumns []string
+	for _, column := range columns {
+		if !isKey[column] {
+			updateColumns = append(updateColumns, quoteIdent(column))
 		}
 	}
+	update := p.ConflictHandling == "update" && len(updateColumns) > 0
 
-	return fmt.Sprintf("INSERT INTO %s(%s) VALUES(%s)",
-		quoteIdent(tablename),
-		strings.Join(quotedColumns, ","),
-		strings.Join(placeholders, ","))
+	switch p.Driver {
+	case "mysql":
+		if !update {
+			return fmt.Sprintf("INSERT IGNORE INTO %s(%s) VALUES%s", table, columnList, values)
+		}
+		assignments := make([]string, 0, len(updateColumns))
+		for _, column := range updateColumns {
+			assignments = append(assignments, fmt.Sprintf("%s=VALUES(%s)", column, column))
+		}
+		return fmt.Sprintf("INSERT INTO %s(%s) VALUES%s ON DUPLICATE KEY UPDATE %s",
+			table, columnList, values, strings.Join(assignments, ","))
+	case "mssql":
+		conditions := make([]string, 0, len(quotedKeys))
+		for _, key := range quotedKeys {
+			conditions = append(conditions, fmt.Sprintf("target.%s=source.%s", key, key))
+		}
+		sourceColumns := make([]string, 0, len(quotedColumns))
+		for _, column := range quotedColumns {
+			sourceColumns = append(sourceColumns, "source."+column)
+		}
+		var matched string
+		if update {
+			assignments := make([]string, 0, len(updateColumns))
+			for _, column := range updateColumns {
+				assignments = append(assignments, fmt.Sprintf("%s=source.%s", column, column))
+			}
+			matched = " WHEN MATCHED THEN UPDATE SET " + strings.Join(assignments, ",")
+		}
+		return fmt.Sprintf("MERGE INTO %s AS target USING (VALUES%s) AS source(%s) ON %s%s WHEN NOT MATCHED THEN INSERT(%s) VALUES(%s);",
+			table, values, columnList, strings.Join(conditions, " AND "), matched, columnList, strings.Join(sourceColumns, ","))
+	default:
+		// Postgres and SQLite
+		if !update {
+			return fmt.Sprintf("INSERT INTO %s(%s) VALUES%s ON CONFLICT(%s) DO NOTHING",
+				table, columnList, values, strings.Join(quotedKeys, ","))
+		}
+		assignments := make([]string, 0, len(updateColumns))
+		for _, column := range updateColumns {
+			assignments = append(assignments, fmt.Sprintf("%s=EXCLUDED.%s", column, column))
+		}
+		return fmt.Sprintf("INSERT INTO %s(%s) VALUES%s ON CONFLICT(%s) DO UPDATE SET %s",
+			table, columnList, values, strings.Join(quotedKeys, ","), strings.Join(assignments, ","))
+	}
 }
 
 func (p *SQL) tableExists(tableName string) bool {
@@ -197,8 +317,18 @@ func (p *SQL) tableExists(tableName string) bool {
 	return err == nil
 }
 
+// insertBatch holds rows of a table sharing the same columns, allowing to
+// insert them with a single statement
+type insertBatch struct {
+	table   string
+	columns []string
+	keys    []string
+	rows    [][]interface{}
+}
+
 func (p *SQL) Write(metrics []telegraf.Metric) error {
-	var err error
+	var batches []*insertBatch
+	batchIndex := make(map[string]*insertBatch)
 
 	for _, metric := range metrics {
 		tablename := metric.Name()
@@ -221,49 +351,136 @@ func (p *SQL) Write(metrics []telegraf.Metric) error {
 			values = append(values, metric.Time())
 		}
 
-		for column, value := range metric.Tags() {
-			columns = append(columns, column)
-			values = append(values, value)
+		for _, tag := range metric.TagList() {
+			columns = append(columns, tag.Key)
+			values = append(values, tag.Value)
 		}
 
-		for column, value := range metric.Fields() {
-			columns = append(columns, column)
-			values = append(values, value)
+		for _, field := range metric.FieldList() {
+			columns = append(columns, field.Key)
+			values = append(values, field.Value)
 		}
 
-		sql := p.generateInsert(tablename, columns)
-
-		switch p.Driver {
-		case "clickhouse":
-			// ClickHouse needs to batch inserts with prepared statements
-			tx, err := p.db.Begin()
-			if err != nil {
-				return fmt.Errorf("begin failed: %w", err)
-			}
-			stmt, err := tx.Prepare(sql)
-			if err != nil {
-				return fmt.Errorf("prepare failed: %w", err)
+		id := tablename + "\x00" + strings.Join(columns, "\x00")
+		batch, found := batchIndex[id]
+		if !found {
+			batch = &insertBatch{
+				table:   tablename,
+				columns: columns,
+				keys:    p.keyColumns(metric),
 			}
-			defer stmt.Close() //nolint:revive,gocritic // done on purpose, closing will be executed properly
+			batchIndex[id] = batch
+			batches = append(batches, batch)
+		}
+		batch.rows = append(batch.rows, values)
+	}
 
-			_, err = stmt.Exec(values...)
-			if err != nil {
-				return fmt.Errorf("execution failed: %w", err)
-			}
-			err = tx.Commit()
-			if err != nil {
-				return fmt.Errorf("commit failed: %w", err)
-			}
-		default:
-			_, err = p.db.Exec(sql, values...)
-			if err != nil {
+	for _, batch := range batches {
+		if err := p.insert(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *SQL) insert(batch *insertBatch) error {
+	if p.Driver == "clickhouse" {
+		// ClickHouse needs to batch inserts with prepared statements
+		sql := p.generateInsert(batch.table, batch.columns, batch.keys, 1)
+		tx, err := p.db.Begin()
+		if err != nil {
+			return fmt.Errorf("begin failed: %w", err)
+		}
+		stmt, err := tx.Prepare(sql)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("prepare failed: %w", err)
+		}
+		defer stmt.Close()
+
+		for _, values := range batch.rows {
+			if _, err := stmt.Exec(values...); err != nil {
+				_ = tx.Rollback()
 				return fmt.Errorf("execution failed: %w", err)
 			}
 		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("commit failed: %w", err)
+		}
+		return nil
+	}
+
+	rows := batch.rows
+	if p.ConflictHandling != "" {
+		rows = p.deduplicate(batch)
+	}
+
+	batchSize := p.InsertBatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	for start := 0; start < len(rows); start += batchSize {
+		end := start + batchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		values := make([]interface{}, 0, (end-start)*len(batch.columns))
+		for _, row := range rows[start:end] {
+			values = append(values, row...)
+		}
+
+		sql := p.generateInsert(batch.table, batch.columns, batch.keys, end-start)
+		if _, err := p.db.Exec(sql, values...); err != nil {
+			return fmt.Errorf("execution failed: %w", err)
+		}
 	}
 	return nil
 }
 
+// deduplicate removes rows with the same key values from the batch as a
+// statement must not affect the same row twice when resolving conflicts. The
+// last row wins when updating, the first one when ignoring conflicts, matching
+// the result of inserting the rows one by one.
+func (p *SQL) deduplicate(batch *insertBatch) [][]interface{} {
+	indices := make([]int, 0, len(batch.keys))
+	for _, key := range batch.keys {
+		idx := -1
+		for i, column := range batch.columns {
+			if column == key {
+				idx = i
+				break
+			}
+		}
+		// Rows lacking a key column never conflict
+		if idx < 0 {
+			return batch.rows
+		}
+		indices = append(indices, idx)
+	}
+
+	positions := make(map[string]int, len(batch.rows))
+	rows := make([][]interface{}, 0, len(batch.rows))
+	parts := make([]string, len(indices))
+	for _, row := range batch.rows {
+		for i, idx := range indices {
+			parts[i] = fmt.Sprintf("%T:%v", row[idx], row[idx])
+		}
+		id := strings.Join(parts, "\x00")
+
+		pos, found := positions[id]
+		if !found {
+			positions[id] = len(rows)
+			rows = append(rows, row)
+			continue
+		}
+		if p.ConflictHandling == "update" {
+			rows[pos] = row
+		}
+	}
+	return rows
+}
+
 func init() {
 	outputs.Add("sql", func() telegraf.Output { return newSQL() })
 }
@@ -289,5 +506,6 @@ func newSQL() *SQL {
 		// except max idle connections which is 2. See
 		// https://pkg.go.dev/database/sql#DB.SetMaxIdleConns
 		ConnectionMaxIdle: 2,
+		InsertBatchSize:   1,
 	}
 }
@@ -37,6 +37,152 @@ func TestSqlInsertStatementIntegration(t *testing.T) {
 	}
 }
 
+func TestInitInvalid(t *testing.T) {
+	p := newSQL()
+	p.Driver = "pgx"
+	p.InsertBatchSize = 0
+	require.ErrorContains(t, p.Init(), "insert_batch_size must be at least one")
+
+	p = newSQL()
+	p.Driver = "pgx"
+	p.ConflictHandling = "replace"
+	require.ErrorContains(t, p.Init(), `invalid conflict_handling "replace"`)
+
+	p = newSQL()
+	p.Driver = "clickhouse"
+	p.ConflictHandling = "update"
+	require.ErrorContains(t, p.Init(), `conflict handling is not supported for driver "clickhouse"`)
+}
+
+func TestGenerateInsert(t *testing.T) {
+	columns := []string{"timestamp", "host", "value"}
+	keys := []string{"timestamp", "host"}
+
+	tests := []struct {
+		name     string
+		driver   string
+		conflict string
+		expected string
+	}{
+		{
+			name:     "postgres multi-row",
+			driver:   "pgx",
+			expected: `INSERT INTO "cpu"("timestamp","host","value") VALUES($1,$2,$3),($4,$5,$6)`,
+		},
+		{
+			name:     "mysql multi-row",
+			driver:   "mysql",
+			expected: `INSERT INTO "cpu"("timestamp","host","value") VALUES(?,?,?),(?,?,?)`,
+		},
+		{
+			name:     "postgres ignore",
+			driver:   "pgx",
+			conflict: "ignore",
+			expected: `INSERT INTO "cpu"("timestamp","host","value") VALUES($1,$2,$3),($4,$5,$6) ON CONFLICT("timestamp","host") DO NOTHING`,
+		},
+		{
+			name:     "sqlite update",
+			driver:   "sqlite",
+			conflict: "update",
+			expected: `INSERT INTO "cpu"("timestamp","host","value") VALUES(?,?,?),(?,?,?) ON CONFLICT("timestamp","host") DO UPDATE SET "value"=EXCLUDED."value"`,
+		},
+		{
+			name:     "mysql ignore",
+			driver:   "mysql",
+			conflict: "ignore",
+			expected: `INSERT IGNORE INTO "cpu"("timestamp","host","value") VALUES(?,?,?),(?,?,?)`,
+		},
+		{
+			name:     "mysql update",
+			driver:   "mysql",
+			conflict: "update",
+			expected: `INSERT INTO "cpu"("timestamp","host","value") VALUES(?,?,?),(?,?,?) ON DUPLICATE KEY UPDATE "value"=VALUES("value")`,
+		},
+		{
+			name:     "mssql ignore",
+			driver:   "mssql",
+			conflict: "ignore",
+			expected: `MERGE INTO "cpu" AS target USING (VALUES(?,?,?),(?,?,?)) AS source("timestamp","host","value") ` +
+				`ON target."timestamp"=source."timestamp" AND target."host"=source."host" ` +
+				`WHEN NOT MATCHED THEN INSERT("timestamp","host","value") VALUES(source."timestamp",source."host",source."value");`,
+		},
+		{
+			name:     "mssql update",
+			driver:   "mssql",
+			conflict: "update",
+			expected: `MERGE INTO "cpu" AS target USING (VALUES(?,?,?),(?,?,?)) AS source("timestamp","host","value") ` +
+				`ON target."timestamp"=source."timestamp" AND target."host"=source."host" ` +
+				`WHEN MATCHED THEN UPDATE SET "value"=source."value" ` +
+				`WHEN NOT MATCHED THEN INSERT("timestamp","host","value") VALUES(source."timestamp",source."host",source."value");`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newSQL()
+			p.Driver = tt.driver
+			p.ConflictHandling = tt.conflict
+			require.NoError(t, p.Init())
+			require.Equal(t, tt.expected, p.generateInsert("cpu", columns, keys, 2))
+		})
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	ts := time.Unix(0, 0)
+	batch := &insertBatch{
+		table:   "cpu",
+		columns: []string{"timestamp", "host", "value"},
+		keys:    []string{"timestamp", "host"},
+		rows: [][]interface{}{
+			{ts, "a", 1},
+			{ts, "b", 2},
+			{ts, "a", 3},
+			{ts.Add(time.Second), "a", 4},
+		},
+	}
+
+	tests := []struct {
+		conflict string
+		expected [][]interface{}
+	}{
+		{
+			conflict: "update",
+			expected: [][]interface{}{{ts, "a", 3}, {ts, "b", 2}, {ts.Add(time.Second), "a", 4}},
+		},
+		{
+			conflict: "ignore",
+			expected: [][]interface{}{{ts, "a", 1}, {ts, "b", 2}, {ts.Add(time.Second), "a", 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.conflict, func(t *testing.T) {
+			p := newSQL()
+			p.Driver = "pgx"
+			p.ConflictHandling = tt.conflict
+			require.NoError(t, p.Init())
+			require.Equal(t, tt.expected, p.deduplicate(batch))
+		})
+	}
+}
+
+func TestGenerateCreateTablePerMeasurement(t *testing.T) {
+	p := newSQL()
+	p.TableTemplates = map[string]string{
+		"metric_two": "CREATE TABLE {TABLE}({COLUMNS}, PRIMARY KEY({KEY_COLUMNS}))",
+	}
+	p.Log = testutil.Logger{}
+	require.NoError(t, p.Init())
+
+	require.Equal(t,
+		`CREATE TABLE "metric_two"("timestamp" TIMESTAMP,"tag_three" TEXT,"string_one" TEXT, PRIMARY KEY("timestamp","tag_three"))`,
+		p.generateCreateTable(testMetrics[1]),
+	)
+	require.Equal(t,
+		`CREATE TABLE "metric three"("timestamp" TIMESTAMP,"tag four" TEXT,"string two" TEXT)`,
+		p.generateCreateTable(testMetrics[2]),
+	)
+}
+
 func pwgen(n int) string {
 	charset := []byte("abcdedfghijklmnopqrstABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
 
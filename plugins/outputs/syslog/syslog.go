@@ -32,7 +32,10 @@ type Syslog struct {
 	DefaultFacilityCode uint8
 	DefaultAppname      string
 	Sdids               []string
-	Separator           string `toml:"sdparam_separator"`
+	SDMapping           map[string][]string `toml:"sd_mapping"`
+	SeverityKey         string              `toml:"severity_key"`
+	FacilityKey         string              `toml:"facility_key"`
+	Separator           string              `toml:"sdparam_separator"`
 	Framing             framing.Framing
 	Trailer             nontransparent.TrailerType
 	Log                 telegraf.Logger `toml:"-"`
@@ -45,24 +48,65 @@ func (*Syslog) SampleConfig() string {
 	return sampleConfig
 }
 
+func (s *Syslog) Init() error {
+	spl := strings.SplitN(s.Address, "://", 2)
+	if len(spl) != 2 {
+		return fmt.Errorf("invalid address: %s", s.Address)
+	}
+	if s.DefaultSeverityCode > 7 {
+		return fmt.Errorf("invalid default_severity_code %d", s.DefaultSeverityCode)
+	}
+	if s.DefaultFacilityCode > 23 {
+		return fmt.Errorf("invalid default_facility_code %d", s.DefaultFacilityCode)
+	}
+
+	// RFC5425 requires octet-counting for syslog over TLS
+	if spl[0] == "tls" && s.Framing != framing.OctetCounting {
+		s.Log.Warn("Non-transparent framing over TLS violates RFC5425, receivers might not be able to parse the messages")
+	}
+
+	s.mapper = nil
+	return s.initializeSyslogMapper()
+}
+
 func (s *Syslog) Connect() error {
-	s.initializeSyslogMapper()
+	if err := s.initializeSyslogMapper(); err != nil {
+		return err
+	}
 
 	spl := strings.SplitN(s.Address, "://", 2)
 	if len(spl) != 2 {
 		return fmt.Errorf("invalid address: %s", s.Address)
 	}
+	network, address := spl[0], spl[1]
 
 	tlsCfg, err := s.ClientConfig.TLSConfig()
 	if err != nil {
 		return err
 	}
 
+	// The "tls" scheme denotes TCP with TLS even without further TLS settings
+	if network == "tls" {
+		network = "tcp"
+		if tlsCfg == nil {
+			tlsCfg = &tls.Config{}
+		}
+	}
+
+	// Keep alive is configured on the dialer to also cover TLS connections
+	dialer := &net.Dialer{}
+	if s.KeepAlivePeriod != nil && strings.HasPrefix(network, "tcp") {
+		dialer.KeepAlive = time.Duration(*s.KeepAlivePeriod)
+		if dialer.KeepAlive == 0 {
+			dialer.KeepAlive = -1
+		}
+	}
+
 	var c net.Conn
 	if tlsCfg == nil {
-		c, err = net.Dial(spl[0], spl[1])
+		c, err = dialer.Dial(network, address)
 	} else {
-		c, err = tls.Dial(spl[0], spl[1], tlsCfg)
+		c, err = tls.DialWithDialer(dialer, network, address, tlsCfg)
 	}
 	if err != nil {
 		return err
@@ -80,6 +124,9 @@ func (s *Syslog) setKeepAlive(c net.Conn) error {
 	if s.KeepAlivePeriod == nil {
 		return nil
 	}
+	if tlsc, ok := c.(*tls.Conn); ok {
+		c = tlsc.NetConn()
+	}
 	tcpc, ok := c.(*net.TCPConn)
 	if !ok {
 		return fmt.Errorf("cannot set keep alive on a %s socket", strings.SplitN(s.Address, "://", 2)[0])
@@ -152,17 +199,32 @@ func (s *Syslog) getSyslogMessageBytesWithFraming(msg *rfc5424.SyslogMessage) ([
 	return append(msgBytes, byte(trailer)), nil
 }
 
-func (s *Syslog) initializeSyslogMapper() {
+func (s *Syslog) initializeSyslogMapper() error {
 	if s.mapper != nil {
-		return
-	}
-	s.mapper = newSyslogMapper()
-	s.mapper.DefaultFacilityCode = s.DefaultFacilityCode
-	s.mapper.DefaultSeverityCode = s.DefaultSeverityCode
-	s.mapper.DefaultAppname = s.DefaultAppname
-	s.mapper.Separator = s.Separator
-	s.mapper.DefaultSdid = s.DefaultSdid
-	s.mapper.Sdids = s.Sdids
+		return nil
+	}
+	mapper := newSyslogMapper()
+	mapper.DefaultFacilityCode = s.DefaultFacilityCode
+	mapper.DefaultSeverityCode = s.DefaultSeverityCode
+	mapper.DefaultAppname = s.DefaultAppname
+	mapper.Separator = s.Separator
+	mapper.DefaultSdid = s.DefaultSdid
+	mapper.Sdids = s.Sdids
+	mapper.SeverityKey = s.SeverityKey
+	mapper.FacilityKey = s.FacilityKey
+	if err := mapper.SetStructuredDataMapping(s.SDMapping); err != nil {
+		return err
+	}
+
+	// Do not add the keys used for deriving the priority as parameters
+	if s.SeverityKey != "" {
+		mapper.reservedKeys[s.SeverityKey] = true
+	}
+	if s.FacilityKey != "" {
+		mapper.reservedKeys[s.FacilityKey] = true
+	}
+	s.mapper = mapper
+	return nil
 }
 
 func newSyslog() *Syslog {
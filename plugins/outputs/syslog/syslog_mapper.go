@@ -2,15 +2,25 @@ package syslog
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/influxdata/go-syslog/v3/common"
 	"github.com/influxdata/go-syslog/v3/rfc5424"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+)
+
+// Lookup tables from severity and facility names to their numeric codes
+var (
+	severityCodes = reverseLookup(common.SeverityLevelsShort, common.SeverityLevels)
+	facilityCodes = reverseLookup(common.FacilityKeywords)
 )
 
 type SyslogMapper struct {
@@ -20,7 +30,41 @@ type SyslogMapper struct {
 	DefaultAppname      string
 	Sdids               []string
 	Separator           string
+	SeverityKey         string
+	FacilityKey         string
 	reservedKeys        map[string]bool
+	sdElements          []sdElement
+}
+
+// sdElement is a structured data element collecting all tags and fields
+// matching the filter
+type sdElement struct {
+	sdid   string
+	filter filter.Filter
+}
+
+// SetStructuredDataMapping compiles the mapping of SD-IDs to the tag and
+// field keys placed into that element
+func (sm *SyslogMapper) SetStructuredDataMapping(mapping map[string][]string) error {
+	// Sort the SD-IDs to get a deterministic order for overlapping patterns
+	sdids := make([]string, 0, len(mapping))
+	for sdid := range mapping {
+		sdids = append(sdids, sdid)
+	}
+	sort.Strings(sdids)
+
+	sm.sdElements = make([]sdElement, 0, len(sdids))
+	for _, sdid := range sdids {
+		f, err := filter.Compile(mapping[sdid])
+		if err != nil {
+			return fmt.Errorf("compiling mapping for SD-ID %q failed: %w", sdid, err)
+		}
+		if f == nil {
+			continue
+		}
+		sm.sdElements = append(sm.sdElements, sdElement{sdid: sdid, filter: f})
+	}
+	return nil
 }
 
 // MapMetricToSyslogMessage maps metrics tags/fields to syslog messages
@@ -56,16 +100,19 @@ func (sm *SyslogMapper) mapStructuredDataItem(key string, value string, msg *rfc
 	if sm.reservedKeys[key] {
 		return
 	}
-	isExplicitSdid := false
+	for _, element := range sm.sdElements {
+		if element.filter.Match(key) {
+			msg.SetParameter(element.sdid, key, value)
+			return
+		}
+	}
 	for _, sdid := range sm.Sdids {
-		k := strings.TrimLeft(key, sdid+sm.Separator)
-		if len(key) > len(k) {
-			isExplicitSdid = true
-			msg.SetParameter(sdid, k, value)
-			break
+		if prefix := sdid + sm.Separator; strings.HasPrefix(key, prefix) {
+			msg.SetParameter(sdid, strings.TrimPrefix(key, prefix), value)
+			return
 		}
 	}
-	if !isExplicitSdid && len(sm.DefaultSdid) > 0 {
+	if len(sm.DefaultSdid) > 0 {
 		k := strings.TrimPrefix(key, sm.DefaultSdid+sm.Separator)
 		msg.SetParameter(sm.DefaultSdid, k, value)
 	}
@@ -118,10 +165,20 @@ func (sm *SyslogMapper) mapPriority(metric telegraf.Metric, msg *rfc5424.SyslogM
 	if value, ok := getFieldCode(metric, "severity_code"); ok {
 		severityCode = *value
 	}
+	if sm.SeverityKey != "" {
+		if value, ok := getNamedCode(metric, sm.SeverityKey, severityCodes); ok && value < 8 {
+			severityCode = value
+		}
+	}
 
 	if value, ok := getFieldCode(metric, "facility_code"); ok {
 		facilityCode = *value
 	}
+	if sm.FacilityKey != "" {
+		if value, ok := getNamedCode(metric, sm.FacilityKey, facilityCodes); ok && value < 24 {
+			facilityCode = value
+		}
+	}
 
 	priority := (8 * facilityCode) + severityCode
 	msg.SetPriority(priority)
@@ -188,6 +245,35 @@ func getFieldCode(metric telegraf.Metric, fieldKey string) (*uint8, bool) {
 	return nil, false
 }
 
+// getNamedCode returns the code given by the tag or field with the given key.
+// The value can either be the numeric code or the name in the lookup table.
+func getNamedCode(metric telegraf.Metric, key string, codes map[string]uint8) (uint8, bool) {
+	value, ok := metric.GetTag(key)
+	if !ok {
+		v, ok := metric.GetField(key)
+		if !ok {
+			return 0, false
+		}
+		value = formatValue(v)
+	}
+
+	if v, err := strconv.ParseUint(value, 10, 8); err == nil {
+		return uint8(v), true
+	}
+	code, ok := codes[strings.ToLower(value)]
+	return code, ok
+}
+
+func reverseLookup(tables ...map[uint8]string) map[string]uint8 {
+	lookup := make(map[string]uint8)
+	for _, table := range tables {
+		for code, name := range table {
+			lookup[name] = code
+		}
+	}
+	return lookup
+}
+
 func newSyslogMapper() *SyslogMapper {
 	return &SyslogMapper{
 		reservedKeys: map[string]bool{
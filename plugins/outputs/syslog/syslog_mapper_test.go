@@ -2,6 +2,7 @@ package syslog
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,7 +13,7 @@ import (
 
 func TestSyslogMapperWithDefaults(t *testing.T) {
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -31,7 +32,7 @@ func TestSyslogMapperWithDefaults(t *testing.T) {
 
 func TestSyslogMapperWithHostname(t *testing.T) {
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -51,7 +52,7 @@ func TestSyslogMapperWithHostname(t *testing.T) {
 }
 func TestSyslogMapperWithHostnameSourceFallback(t *testing.T) {
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -71,7 +72,7 @@ func TestSyslogMapperWithHostnameSourceFallback(t *testing.T) {
 
 func TestSyslogMapperWithHostnameHostFallback(t *testing.T) {
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -91,7 +92,7 @@ func TestSyslogMapperWithHostnameHostFallback(t *testing.T) {
 func TestSyslogMapperWithDefaultSdid(t *testing.T) {
 	s := newSyslog()
 	s.DefaultSdid = "default@32473"
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -133,7 +134,7 @@ func TestSyslogMapperWithDefaultSdidAndOtherSdids(t *testing.T) {
 	s := newSyslog()
 	s.DefaultSdid = "default@32473"
 	s.Sdids = []string{"bar@123", "foo@456"}
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -176,7 +177,7 @@ func TestSyslogMapperWithDefaultSdidAndOtherSdids(t *testing.T) {
 func TestSyslogMapperWithNoSdids(t *testing.T) {
 	// Init mapper
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -210,3 +211,130 @@ func TestSyslogMapperWithNoSdids(t *testing.T) {
 	str, _ := syslogMessage.String()
 	require.Equal(t, "<26>2 2010-11-10T23:30:00Z testhost testapp 25 555 - Test message", str, "Wrong syslog message")
 }
+
+func TestSyslogMapperWithSDMapping(t *testing.T) {
+	s := newSyslog()
+	s.DefaultSdid = "default@32473"
+	s.Sdids = []string{"foo@123"}
+	s.SDMapping = map[string][]string{
+		"origin@32473": {"site", "rack_*"},
+		"perf@32473":   {"cpu_*"},
+	}
+	require.NoError(t, s.initializeSyslogMapper())
+
+	m1 := metric.New(
+		"testmetric",
+		map[string]string{
+			"hostname": "testhost",
+			"site":     "north",
+			"rack_id":  "r1",
+		},
+		map[string]interface{}{
+			"cpu_usage":     float64(12.5),
+			"foo@123_value": int64(42),
+			"other":         true,
+		},
+		time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC),
+	)
+	syslogMessage, err := s.mapper.MapMetricToSyslogMessage(m1)
+	require.NoError(t, err)
+	str, _ := syslogMessage.String()
+	require.Equal(t,
+		"<13>1 2010-11-10T23:00:00Z testhost Telegraf - testmetric "+
+			"[default@32473 other=\"1\"][foo@123 value=\"42\"][origin@32473 rack_id=\"r1\" site=\"north\"][perf@32473 cpu_usage=\"12.5\"]",
+		str,
+	)
+}
+
+func TestSyslogMapperInvalidSDMapping(t *testing.T) {
+	s := newSyslog()
+	s.SDMapping = map[string][]string{"origin@32473": {"site["}}
+	require.ErrorContains(t, s.initializeSyslogMapper(), "compiling mapping for SD-ID \"origin@32473\" failed")
+}
+
+func TestSyslogMapperPriority(t *testing.T) {
+	tests := []struct {
+		name        string
+		severityKey string
+		facilityKey string
+		tags        map[string]string
+		fields      map[string]interface{}
+		expected    string
+	}{
+		{
+			name:     "defaults",
+			expected: "<13>",
+		},
+		{
+			name:     "codes",
+			fields:   map[string]interface{}{"severity_code": int64(3), "facility_code": int64(16)},
+			expected: "<131>",
+		},
+		{
+			name:     "names ignored without keys",
+			tags:     map[string]string{"severity": "err", "facility": "daemon"},
+			expected: "<13>",
+		},
+		{
+			name:        "names from syslog input",
+			severityKey: "severity",
+			facilityKey: "facility",
+			tags:        map[string]string{"severity": "err", "facility": "daemon"},
+			expected:    "<27>",
+		},
+		{
+			name:        "custom keys with names",
+			severityKey: "level",
+			facilityKey: "category",
+			tags:        map[string]string{"level": "warning", "category": "local3"},
+			expected:    "<156>",
+		},
+		{
+			name:        "custom keys with codes",
+			severityKey: "level",
+			fields:      map[string]interface{}{"level": int64(2)},
+			expected:    "<10>",
+		},
+		{
+			name:        "custom keys take precedence",
+			severityKey: "level",
+			tags:        map[string]string{"level": "debug"},
+			fields:      map[string]interface{}{"severity_code": int64(3)},
+			expected:    "<15>",
+		},
+		{
+			name:        "invalid values are ignored",
+			severityKey: "level",
+			facilityKey: "category",
+			tags:        map[string]string{"level": "unknown", "category": "99"},
+			expected:    "<13>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSyslog()
+			s.DefaultSdid = "default@32473"
+			s.SeverityKey = tt.severityKey
+			s.FacilityKey = tt.facilityKey
+			require.NoError(t, s.initializeSyslogMapper())
+
+			tags := map[string]string{"hostname": "testhost"}
+			for k, v := range tt.tags {
+				tags[k] = v
+			}
+			m := metric.New("testmetric", tags, tt.fields, time.Date(2010, time.November, 10, 23, 0, 0, 0, time.UTC))
+			syslogMessage, err := s.mapper.MapMetricToSyslogMessage(m)
+			require.NoError(t, err)
+			str, _ := syslogMessage.String()
+			require.Truef(t, strings.HasPrefix(str, tt.expected), "unexpected message %q", str)
+
+			// The keys used for the priority must not show up as parameters
+			for _, key := range []string{tt.severityKey, tt.facilityKey} {
+				if key != "" {
+					require.NotContains(t, str, key+"=")
+				}
+			}
+		})
+	}
+}
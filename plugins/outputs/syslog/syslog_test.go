@@ -1,6 +1,7 @@
 package syslog
 
 import (
+	"crypto/tls"
 	"net"
 	"sync"
 	"testing"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/influxdata/go-syslog/v3/nontransparent"
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	framing "github.com/influxdata/telegraf/internal/syslog"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
@@ -18,7 +20,7 @@ import (
 func TestGetSyslogMessageWithFramingOctectCounting(t *testing.T) {
 	// Init plugin
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 
 	// Init metrics
 	m1 := metric.New(
@@ -41,7 +43,7 @@ func TestGetSyslogMessageWithFramingOctectCounting(t *testing.T) {
 func TestGetSyslogMessageWithFramingNonTransparent(t *testing.T) {
 	// Init plugin
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 	s.Framing = framing.NonTransparent
 
 	// Init metrics
@@ -65,7 +67,7 @@ func TestGetSyslogMessageWithFramingNonTransparent(t *testing.T) {
 func TestGetSyslogMessageWithFramingNonTransparentNul(t *testing.T) {
 	// Init plugin
 	s := newSyslog()
-	s.initializeSyslogMapper()
+	require.NoError(t, s.initializeSyslogMapper())
 	s.Framing = framing.NonTransparent
 	s.Trailer = nontransparent.NUL
 
@@ -238,3 +240,58 @@ func TestSyslogWriteReconnect(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, string(messageBytesWithFraming), string(buf[:n]))
 }
+
+func TestInitFail(t *testing.T) {
+	s := newSyslog()
+	s.Address = "127.0.0.1:514"
+	require.ErrorContains(t, s.Init(), "invalid address")
+
+	s = newSyslog()
+	s.Address = "tcp://127.0.0.1:514"
+	s.DefaultSeverityCode = 8
+	require.ErrorContains(t, s.Init(), "invalid default_severity_code")
+
+	s = newSyslog()
+	s.Address = "tcp://127.0.0.1:514"
+	s.DefaultFacilityCode = 24
+	require.ErrorContains(t, s.Init(), "invalid default_facility_code")
+}
+
+func TestSyslogWriteWithTLS(t *testing.T) {
+	pki := testutil.NewPKI("../../../testutil/pki")
+	serverCfg := pki.TLSServerConfig()
+	serverCfg.TLSCipherSuites = nil
+	serverTLS, err := serverCfg.TLSConfig()
+	require.NoError(t, err)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverTLS)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	keepAlive := config.Duration(time.Minute)
+	s := newSyslog()
+	s.Address = "tls://" + listener.Addr().String()
+	s.KeepAlivePeriod = &keepAlive
+	s.ClientConfig = *pki.TLSClientConfig()
+	s.Log = testutil.Logger{}
+	require.NoError(t, s.Init())
+
+	// The TLS handshake requires the server side to be served concurrently
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(accepted)
+			return
+		}
+		_ = conn.(*tls.Conn).Handshake()
+		accepted <- conn
+	}()
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	lconn, ok := <-accepted
+	require.True(t, ok)
+	defer lconn.Close()
+
+	testSyslogWriteWithStream(t, s, lconn)
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/influxdata/telegraf"
 	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
 	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 //go:embed sample.conf
@@ -46,6 +47,8 @@ type (
 		Log telegraf.Logger
 		svc WriteClient
 
+		recordsRejected selfstat.Stat
+
 		internalaws.CredentialConfig
 	}
 
@@ -58,6 +61,13 @@ type (
 			...func(*timestreamwrite.Options),
 		) (*timestreamwrite.DescribeDatabaseOutput, error)
 	}
+
+	// writeRequest holds a write request along with the metrics the records
+	// were built from, i.e. the record at index i originates from metrics[i].
+	writeRequest struct {
+		input   *timestreamwrite.WriteRecordsInput
+		metrics []telegraf.Metric
+	}
 )
 
 // Mapping modes specify how Telegraf model should be represented in Timestream model.
@@ -182,6 +192,8 @@ func (t *Timestream) Connect() error {
 		t.MaxWriteGoRoutinesCount = MaxWriteRoutinesDefault
 	}
 
+	t.recordsRejected = selfstat.Register("timestream", "records_rejected", map[string]string{"database": t.DatabaseName})
+
 	t.Log.Infof("Constructing Timestream client for %q mode", t.MappingMode)
 
 	svc, err := WriteFactory(&t.CredentialConfig)
@@ -213,12 +225,14 @@ func (t *Timestream) Close() error {
 
 func init() {
 	outputs.Add("timestream", func() telegraf.Output {
-		return &Timestream{}
+		return &Timestream{
+			UseMultiMeasureRecords: true,
+		}
 	})
 }
 
 func (t *Timestream) Write(metrics []telegraf.Metric) error {
-	writeRecordsInputs := t.TransformMetrics(metrics)
+	writeRecordsInputs := t.transformMetrics(metrics)
 
 	maxWriteJobs := t.MaxWriteGoRoutinesCount
 	numberOfWriteRecordsInputs := len(writeRecordsInputs)
@@ -229,7 +243,7 @@ func (t *Timestream) Write(metrics []telegraf.Metric) error {
 
 	var wg sync.WaitGroup
 	errs := make(chan error, numberOfWriteRecordsInputs)
-	writeJobs := make(chan *timestreamwrite.WriteRecordsInput, maxWriteJobs)
+	writeJobs := make(chan *writeRequest, maxWriteJobs)
 
 	start := time.Now()
 
@@ -271,7 +285,8 @@ func (t *Timestream) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
-func (t *Timestream) writeToTimestream(writeRecordsInput *timestreamwrite.WriteRecordsInput, resourceNotFoundRetry bool) error {
+func (t *Timestream) writeToTimestream(request *writeRequest, resourceNotFoundRetry bool) error {
+	writeRecordsInput := request.input
 	_, err := t.svc.WriteRecords(context.Background(), writeRecordsInput)
 	if err != nil {
 		// Telegraf will retry ingesting the metrics if an error is returned from the plugin.
@@ -281,7 +296,7 @@ func (t *Timestream) writeToTimestream(writeRecordsInput *timestreamwrite.WriteR
 			if resourceNotFoundRetry {
 				t.Log.Warnf("Failed to write to Timestream database %q table %q: %s",
 					t.DatabaseName, *writeRecordsInput.TableName, notFound)
-				return t.createTableAndRetry(writeRecordsInput)
+				return t.createTableAndRetry(request)
 			}
 			t.logWriteToTimestreamError(notFound, writeRecordsInput.TableName)
 			// log error and return error to telegraf to retry in next flush interval
@@ -289,15 +304,44 @@ func (t *Timestream) writeToTimestream(writeRecordsInput *timestreamwrite.WriteR
 			return fmt.Errorf("failed to write to Timestream database %q table %q: %w", t.DatabaseName, *writeRecordsInput.TableName, err)
 		}
 
+		// Records not listed as rejected are ingested by Timestream, so
+		// retrying the request doesn't make sense.
 		var rejected *types.RejectedRecordsException
 		if errors.As(err, &rejected) {
-			t.logWriteToTimestreamError(err, writeRecordsInput.TableName)
 			for _, rr := range rejected.RejectedRecords {
-				t.Log.Errorf("reject reason: %q, record index: '%d'", aws.ToString(rr.Reason), rr.RecordIndex)
+				t.rejectRecord(request, int(rr.RecordIndex), aws.ToString(rr.Reason), rr.ExistingVersion)
 			}
 			return nil
 		}
 
+		// Validation errors fail the whole request, e.g. for exceeding the
+		// API limits, so split the request to isolate the offending records.
+		// All parts are written and only the failed ones are returned as
+		// error to not lose the records of the remaining parts.
+		var validation *types.ValidationException
+		if errors.As(err, &validation) {
+			if len(writeRecordsInput.Records) <= 1 {
+				return fmt.Errorf("unable to write %s to Timestream database %q table %q: %w",
+					request.describe(0), t.DatabaseName, *writeRecordsInput.TableName, validation)
+			}
+
+			t.Log.Debugf("Splitting request of %d records to Timestream database %q table %q: %s",
+				len(writeRecordsInput.Records), t.DatabaseName, *writeRecordsInput.TableName, validation)
+			var errs []error
+			var failed int
+			for _, part := range request.split() {
+				if err := t.writeToTimestream(part, resourceNotFoundRetry); err != nil {
+					errs = append(errs, err)
+					failed += len(part.input.Records)
+				}
+			}
+			if len(errs) > 0 {
+				t.Log.Debugf("Writing %d of %d records to Timestream database %q table %q failed",
+					failed, len(writeRecordsInput.Records), t.DatabaseName, *writeRecordsInput.TableName)
+			}
+			return errors.Join(errs...)
+		}
+
 		var throttling *types.ThrottlingException
 		if errors.As(err, &throttling) {
 			return fmt.Errorf("unable to write to Timestream database %q table %q: %w",
@@ -321,12 +365,51 @@ func (t *Timestream) writeToTimestream(writeRecordsInput *timestreamwrite.WriteR
 	return nil
 }
 
+// rejectRecord reports the metric the rejected record originates from
+// and drops the record.
+func (t *Timestream) rejectRecord(request *writeRequest, index int, reason string, existingVersion *int64) {
+	if t.recordsRejected != nil {
+		t.recordsRejected.Incr(1)
+	}
+
+	msg := fmt.Sprintf("Timestream rejected %s of table %q: %s", request.describe(index), *request.input.TableName, reason)
+	if existingVersion != nil {
+		msg += fmt.Sprintf(" (existing version %d)", *existingVersion)
+	}
+	t.Log.Error(msg + ". Skipping metric!")
+}
+
+// describe identifies the record of the request by the metric it originates
+// from, if known
+func (r *writeRequest) describe(index int) string {
+	if index < 0 || index >= len(r.metrics) {
+		return fmt.Sprintf("record %d", index)
+	}
+
+	m := r.metrics[index]
+	return fmt.Sprintf("record %d for metric %q with tags %v at %s",
+		index, m.Name(), m.Tags(), m.Time().Format(time.RFC3339Nano))
+}
+
+// split divides the request into two requests of half the size
+func (r *writeRequest) split() []*writeRequest {
+	middle := len(r.input.Records) / 2
+	parts := make([]*writeRequest, 0, 2)
+	for _, bounds := range [][2]int{{0, middle}, {middle, len(r.input.Records)}} {
+		input := *r.input
+		input.Records = r.input.Records[bounds[0]:bounds[1]]
+		parts = append(parts, &writeRequest{input: &input, metrics: r.metrics[bounds[0]:bounds[1]]})
+	}
+	return parts
+}
+
 func (t *Timestream) logWriteToTimestreamError(err error, tableName *string) {
 	t.Log.Errorf("Failed to write to Timestream database %q table %q: %s. Skipping metric!",
 		t.DatabaseName, *tableName, err.Error())
 }
 
-func (t *Timestream) createTableAndRetry(writeRecordsInput *timestreamwrite.WriteRecordsInput) error {
+func (t *Timestream) createTableAndRetry(request *writeRequest) error {
+	writeRecordsInput := request.input
 	if t.CreateTableIfNotExists {
 		t.Log.Infof(
 			"Trying to create table %q in database %q, as 'CreateTableIfNotExists' config key is 'true'.",
@@ -336,7 +419,7 @@ func (t *Timestream) createTableAndRetry(writeRecordsInput *timestreamwrite.Writ
 		err := t.createTable(writeRecordsInput.TableName)
 		if err == nil {
 			t.Log.Infof("Table %q in database %q created. Retrying writing.", *writeRecordsInput.TableName, t.DatabaseName)
-			return t.writeToTimestream(writeRecordsInput, false)
+			return t.writeToTimestream(request, false)
 		}
 		t.Log.Errorf("Failed to create table %q in database %q: %s. Skipping metric!", *writeRecordsInput.TableName, t.DatabaseName, err.Error())
 	} else {
@@ -381,7 +464,16 @@ func (t *Timestream) createTable(tableName *string) error {
 // Telegraf Metrics are grouped by Name, Tag Keys and Time to use Timestream CommonAttributes.
 // Returns collection of write requests to be performed to Timestream.
 func (t *Timestream) TransformMetrics(metrics []telegraf.Metric) []*timestreamwrite.WriteRecordsInput {
-	writeRequests := make(map[string]*timestreamwrite.WriteRecordsInput, len(metrics))
+	requests := t.transformMetrics(metrics)
+	result := make([]*timestreamwrite.WriteRecordsInput, 0, len(requests))
+	for _, request := range requests {
+		result = append(result, request.input)
+	}
+	return result
+}
+
+func (t *Timestream) transformMetrics(metrics []telegraf.Metric) []*writeRequest {
+	writeRequests := make(map[string]*writeRequest, len(metrics))
 	for _, m := range metrics {
 		// build MeasureName, MeasureValue, MeasureValueType
 		records := t.buildWriteRecords(m)
@@ -399,35 +491,39 @@ func (t *Timestream) TransformMetrics(metrics []telegraf.Metric) []*timestreamwr
 			tableName = m.Name()
 		}
 
-		if curr, ok := writeRequests[tableName]; !ok {
-			newWriteRecord := &timestreamwrite.WriteRecordsInput{
-				DatabaseName:     aws.String(t.DatabaseName),
-				TableName:        aws.String(tableName),
-				Records:          records,
-				CommonAttributes: &types.Record{},
+		curr, ok := writeRequests[tableName]
+		if !ok {
+			curr = &writeRequest{
+				input: &timestreamwrite.WriteRecordsInput{
+					DatabaseName:     aws.String(t.DatabaseName),
+					TableName:        aws.String(tableName),
+					CommonAttributes: &types.Record{},
+				},
 			}
-
-			writeRequests[tableName] = newWriteRecord
-		} else {
-			curr.Records = append(curr.Records, records...)
+			writeRequests[tableName] = curr
+		}
+		curr.input.Records = append(curr.input.Records, records...)
+		for range records {
+			curr.metrics = append(curr.metrics, m)
 		}
 	}
 
-	// Create result as array of WriteRecordsInput. Split requests over records count limit to smaller requests.
-	var result []*timestreamwrite.WriteRecordsInput
-	for _, writeRequest := range writeRequests {
-		if len(writeRequest.Records) > MaxRecordsPerCall {
-			for _, recordsPartition := range partitionRecords(MaxRecordsPerCall, writeRequest.Records) {
-				newWriteRecord := &timestreamwrite.WriteRecordsInput{
-					DatabaseName:     writeRequest.DatabaseName,
-					TableName:        writeRequest.TableName,
-					Records:          recordsPartition,
-					CommonAttributes: writeRequest.CommonAttributes,
-				}
-				result = append(result, newWriteRecord)
+	// Create result as array of write requests. Split requests over records count limit to smaller requests.
+	var result []*writeRequest
+	for _, request := range writeRequests {
+		if len(request.input.Records) > MaxRecordsPerCall {
+			partitions := partitionRecords(MaxRecordsPerCall, request.input.Records)
+			for i, recordsPartition := range partitions {
+				input := *request.input
+				input.Records = recordsPartition
+				start := i * MaxRecordsPerCall
+				result = append(result, &writeRequest{
+					input:   &input,
+					metrics: request.metrics[start : start+len(recordsPartition)],
+				})
 			}
 		} else {
-			result = append(result, writeRequest)
+			result = append(result, request)
 		}
 	}
 	return result
@@ -17,6 +17,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	internalaws "github.com/influxdata/telegraf/plugins/common/aws"
+	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -561,6 +562,123 @@ func TestRejectedRecordsErrorResultsInMetricsBeingSkipped(t *testing.T) {
 	require.Nil(t, err, "Expected to silently swallow the RejectedRecordsException, "+
 		"as retrying this error doesn't make sense.")
 }
+
+type mockTimestreamRejectingClient struct {
+	mockTimestreamClient
+	writeRecords func(*timestreamwrite.WriteRecordsInput) error
+	written      []types.Record
+}
+
+func (m *mockTimestreamRejectingClient) WriteRecords(
+	_ context.Context,
+	input *timestreamwrite.WriteRecordsInput,
+	_ ...func(*timestreamwrite.Options),
+) (*timestreamwrite.WriteRecordsOutput, error) {
+	m.WriteRecordsRequestCount++
+	if err := m.writeRecords(input); err != nil {
+		return nil, err
+	}
+	m.written = append(m.written, input.Records...)
+	return nil, nil
+}
+
+func TestRejectedRecordsAreReportedPerMetric(t *testing.T) {
+	WriteFactory = func(credentialConfig *internalaws.CredentialConfig) (WriteClient, error) {
+		return &mockTimestreamRejectingClient{
+			writeRecords: func(*timestreamwrite.WriteRecordsInput) error {
+				return &types.RejectedRecordsException{
+					Message: aws.String("RejectedRecords Test"),
+					RejectedRecords: []types.RejectedRecord{
+						{RecordIndex: 1, Reason: aws.String("Duplicate record"), ExistingVersion: aws.Int64(3)},
+					},
+				}
+			},
+		}, nil
+	}
+
+	logger := &testutil.CaptureLogger{}
+	plugin := Timestream{
+		MappingMode:                       MappingModeMultiTable,
+		DatabaseName:                      tsDbName,
+		UseMultiMeasureRecords:            true,
+		MeasureNameForMultiMeasureRecords: "multi",
+		Log:                               logger,
+	}
+	require.NoError(t, plugin.Connect())
+	rejectedBefore := plugin.recordsRejected.Get()
+
+	inputs := []telegraf.Metric{
+		testutil.MustMetric(metricName1, map[string]string{"tag1": "value1"}, map[string]interface{}{"value": float64(1)}, time1),
+		testutil.MustMetric(metricName1, map[string]string{"tag1": "value2"}, map[string]interface{}{"value": float64(2)}, time1),
+	}
+	require.NoError(t, plugin.Write(inputs))
+
+	require.Equal(t, rejectedBefore+1, plugin.recordsRejected.Get())
+	require.Len(t, logger.Errors(), 1)
+	require.Contains(t, logger.LastError(), `metric "metricName1" with tags map[tag1:value2]`)
+	require.Contains(t, logger.LastError(), "Duplicate record (existing version 3)")
+}
+
+func TestValidationErrorSplitsRequest(t *testing.T) {
+	client := &mockTimestreamRejectingClient{
+		writeRecords: func(input *timestreamwrite.WriteRecordsInput) error {
+			for _, record := range input.Records {
+				for _, dimension := range record.Dimensions {
+					if aws.ToString(dimension.Value) == "bad" {
+						return &types.ValidationException{Message: aws.String("invalid dimension value")}
+					}
+				}
+			}
+			return nil
+		},
+	}
+	WriteFactory = func(credentialConfig *internalaws.CredentialConfig) (WriteClient, error) {
+		return client, nil
+	}
+
+	logger := &testutil.CaptureLogger{}
+	plugin := Timestream{
+		MappingMode:                       MappingModeMultiTable,
+		DatabaseName:                      tsDbName,
+		UseMultiMeasureRecords:            true,
+		MeasureNameForMultiMeasureRecords: "multi",
+		Log:                               logger,
+	}
+	require.NoError(t, plugin.Connect())
+	rejectedBefore := plugin.recordsRejected.Get()
+
+	inputs := make([]telegraf.Metric, 0, 4)
+	for _, value := range []string{"a", "bad", "b", "c"} {
+		inputs = append(inputs, testutil.MustMetric(
+			metricName1,
+			map[string]string{"tag1": value},
+			map[string]interface{}{"value": float64(1)},
+			time1,
+		))
+	}
+	err := plugin.Write(inputs)
+
+	// The full request and the half containing the invalid record fail,
+	// then the invalid record is isolated. The other half must be written
+	// even though the first half failed.
+	require.ErrorContains(t, err, `with tags map[tag1:bad]`)
+	require.ErrorContains(t, err, "invalid dimension value")
+	require.Equal(t, 5, client.WriteRecordsRequestCount)
+	require.Len(t, client.written, 3)
+	for _, record := range client.written {
+		require.NotEqual(t, "bad", aws.ToString(record.Dimensions[0].Value))
+	}
+
+	// The invalid record is retried by Telegraf instead of being dropped
+	require.Equal(t, rejectedBefore, plugin.recordsRejected.Get())
+	require.Empty(t, logger.Errors())
+}
+
+func TestMultiMeasureRecordsByDefault(t *testing.T) {
+	plugin := outputs.Outputs["timestream"]().(*Timestream)
+	require.True(t, plugin.UseMultiMeasureRecords)
+}
+
 func TestWriteWhenRequestsGreaterThanMaxWriteGoRoutinesCount(t *testing.T) {
 	t.Skip("Skipping test due to data race, will be re-visited")
 	const maxWriteRecordsCalls = 5
@@ -0,0 +1,136 @@
+package zabbix
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// discovery collects the low-level discovery (LLD) data of the series seen
+// and generates the discovery items for Zabbix discovery rules. Each set of
+// tag keys of a measurement results in a discovery key with one row per
+// distinct set of tag values, the tags are available as "{#TAG}" macros.
+type discovery struct {
+	prefix        string
+	clearInterval time.Duration
+	lastClear     time.Time
+	changed       bool
+
+	// rows for each host and discovery key indexed by the tag values
+	hosts map[string]map[string]map[string]map[string]string
+}
+
+func newDiscovery(prefix string, clearInterval time.Duration) *discovery {
+	return &discovery{
+		prefix:        prefix,
+		clearInterval: clearInterval,
+		lastClear:     time.Now(),
+		hosts:         make(map[string]map[string]map[string]map[string]string),
+	}
+}
+
+// key returns the discovery key for the measurement and tag keys
+func (d *discovery) key(name string, keys []string) string {
+	return d.prefix + "lld." + name + "." + strings.Join(keys, ".")
+}
+
+func (d *discovery) add(host string, m telegraf.Metric, excluded map[string]bool) {
+	keys := sortedTagKeys(m, excluded)
+	if len(keys) == 0 {
+		return
+	}
+
+	rules, found := d.hosts[host]
+	if !found {
+		rules = make(map[string]map[string]map[string]string)
+		d.hosts[host] = rules
+	}
+	key := d.key(m.Name(), keys)
+	rows, found := rules[key]
+	if !found {
+		rows = make(map[string]map[string]string)
+		rules[key] = rows
+	}
+
+	values := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v, _ := m.GetTag(k)
+		values = append(values, v)
+	}
+	id := strings.Join(values, "\x00")
+	if _, found := rows[id]; found {
+		return
+	}
+
+	row := make(map[string]string, len(keys))
+	for i, k := range keys {
+		row["{#"+macroName(k)+"}"] = values[i]
+	}
+	rows[id] = row
+	d.changed = true
+}
+
+// items returns the discovery items for all hosts
+func (d *discovery) items(now time.Time) []item {
+	items := make([]item, 0, len(d.hosts))
+	for host, rules := range d.hosts {
+		for key, rows := range rules {
+			ids := make([]string, 0, len(rows))
+			for id := range rows {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+
+			data := make([]map[string]string, 0, len(ids))
+			for _, id := range ids {
+				data = append(data, rows[id])
+			}
+			// The marshalling of string maps cannot fail
+			value, _ := json.Marshal(map[string]interface{}{"data": data})
+
+			items = append(items, item{
+				Host:  host,
+				Key:   key,
+				Value: string(value),
+				Clock: now.Unix(),
+				NS:    now.Nanosecond(),
+			})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Host != items[j].Host {
+			return items[i].Host < items[j].Host
+		}
+		return items[i].Key < items[j].Key
+	})
+
+	return items
+}
+
+// sent marks the discovery data as sent. After the clear interval the
+// collected data is reset so series not seen anymore are dropped with the
+// next discovery.
+func (d *discovery) sent(now time.Time) {
+	d.changed = false
+	if d.clearInterval > 0 && now.Sub(d.lastClear) >= d.clearInterval {
+		d.hosts = make(map[string]map[string]map[string]map[string]string)
+		d.lastClear = now
+	}
+}
+
+// macroName converts the tag key to a valid LLD macro name consisting of
+// upper case letters, digits, underscores and dots
+func macroName(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, key)
+}
@@ -0,0 +1,366 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package zabbix
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/templating"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Header of the Zabbix protocol, the flag 0x01 denotes the standard protocol
+var header = []byte{'Z', 'B', 'X', 'D', 0x01}
+
+// maxResponseSize limits the size of a server response we accept
+const maxResponseSize = 1 << 20
+
+const (
+	defaultHostTemplate = `{{ .Tag "host" }}`
+	defaultKeyTemplate  = `{{ .Name }}.{{ .Field }}{{ with .TagValues }}[{{ . }}]{{ end }}`
+)
+
+var processedRegexp = regexp.MustCompile(`failed: (\d+)`)
+
+type Zabbix struct {
+	Address          string          `toml:"address"`
+	Timeout          config.Duration `toml:"timeout"`
+	KeyPrefix        string          `toml:"key_prefix"`
+	HostTemplate     string          `toml:"host_template"`
+	KeyTemplate      string          `toml:"key_template"`
+	ExcludeKeyTags   []string        `toml:"exclude_key_tags"`
+	BatchSize        int             `toml:"batch_size"`
+	LLDSendInterval  config.Duration `toml:"lld_send_interval"`
+	LLDClearInterval config.Duration `toml:"lld_clear_interval"`
+	Log              telegraf.Logger `toml:"-"`
+
+	hostname    string
+	hostTmpl    *template.Template
+	keyTmpl     *template.Template
+	excluded    map[string]bool
+	lld         *discovery
+	lldLastSend time.Time
+}
+
+// item is a single value sent to Zabbix
+type item struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+	NS    int    `json:"ns"`
+}
+
+type request struct {
+	Request string `json:"request"`
+	Data    []item `json:"data"`
+	Clock   int64  `json:"clock"`
+	NS      int    `json:"ns"`
+}
+
+type response struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+// templateData is passed to the host and key templates
+type templateData struct {
+	templating.MetricData
+	excluded map[string]bool
+}
+
+// TagValues returns the comma separated values of all tags not excluded from
+// the key, sorted by tag key
+func (t *templateData) TagValues() string {
+	values := make([]string, 0, len(t.Metric.TagList()))
+	for _, tag := range t.Metric.TagList() {
+		if !t.excluded[tag.Key] {
+			values = append(values, quoteParameter(tag.Value))
+		}
+	}
+	return strings.Join(values, ",")
+}
+
+func (*Zabbix) SampleConfig() string {
+	return sampleConfig
+}
+
+func (z *Zabbix) Init() error {
+	if z.Address == "" {
+		return errors.New("address required")
+	}
+	if _, _, err := net.SplitHostPort(z.Address); err != nil {
+		return fmt.Errorf("invalid address %q: %w", z.Address, err)
+	}
+	if z.BatchSize <= 0 {
+		z.BatchSize = 250
+	}
+
+	if z.HostTemplate == "" {
+		z.HostTemplate = defaultHostTemplate
+	}
+	tmpl, err := template.New("host_template").Parse(z.HostTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing host template failed: %w", err)
+	}
+	z.hostTmpl = tmpl
+
+	if z.KeyTemplate == "" {
+		z.KeyTemplate = defaultKeyTemplate
+	}
+	tmpl, err = template.New("key_template").Parse(z.KeyTemplate)
+	if err != nil {
+		return fmt.Errorf("parsing key template failed: %w", err)
+	}
+	z.keyTmpl = tmpl
+
+	z.excluded = make(map[string]bool, len(z.ExcludeKeyTags))
+	for _, key := range z.ExcludeKeyTags {
+		z.excluded[key] = true
+	}
+
+	// Use the local hostname for metrics without host information
+	hostname, err := os.Hostname()
+	if err != nil {
+		return fmt.Errorf("getting hostname failed: %w", err)
+	}
+	z.hostname = hostname
+
+	if z.LLDSendInterval > 0 {
+		z.lld = newDiscovery(z.KeyPrefix, time.Duration(z.LLDClearInterval))
+	}
+
+	return nil
+}
+
+func (*Zabbix) Connect() error {
+	return nil
+}
+
+func (*Zabbix) Close() error {
+	return nil
+}
+
+func (z *Zabbix) Write(metrics []telegraf.Metric) error {
+	now := time.Now()
+
+	items := make([]item, 0, len(metrics))
+	for _, m := range metrics {
+		host, err := z.execute(z.hostTmpl, &templateData{MetricData: templating.MetricData{Metric: m}, excluded: z.excluded})
+		if err != nil {
+			z.Log.Errorf("Generating host name for metric %q failed: %v", m.Name(), err)
+			continue
+		}
+		if host == "" {
+			host = z.hostname
+		}
+
+		for _, field := range m.FieldList() {
+			value, ok := formatValue(field.Value)
+			if !ok {
+				z.Log.Debugf("Skipping field %q of metric %q with unsupported type %T", field.Key, m.Name(), field.Value)
+				continue
+			}
+			key, err := z.execute(z.keyTmpl, &templateData{MetricData: templating.MetricData{Metric: m, FieldKey: field.Key}, excluded: z.excluded})
+			if err != nil {
+				z.Log.Errorf("Generating key for field %q of metric %q failed: %v", field.Key, m.Name(), err)
+				continue
+			}
+			items = append(items, item{
+				Host:  host,
+				Key:   z.KeyPrefix + key,
+				Value: value,
+				Clock: m.Time().Unix(),
+				NS:    m.Time().Nanosecond(),
+			})
+		}
+
+		if z.lld != nil {
+			z.lld.add(host, m, z.excluded)
+		}
+	}
+
+	// Send the discovery data first so Zabbix can create the items before
+	// receiving their values.
+	if z.lld != nil && (z.lld.changed || now.Sub(z.lldLastSend) >= time.Duration(z.LLDSendInterval)) {
+		discovered := z.lld.items(now)
+		if len(discovered) > 0 {
+			if err := z.send(discovered); err != nil {
+				return fmt.Errorf("sending discovery data failed: %w", err)
+			}
+		}
+		z.lld.sent(now)
+		z.lldLastSend = now
+	}
+
+	for start := 0; start < len(items); start += z.BatchSize {
+		end := start + z.BatchSize
+		if end > len(items) {
+			end = len(items)
+		}
+		if err := z.send(items[start:end]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (z *Zabbix) execute(tmpl *template.Template, data *templateData) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// send transmits the items using the trapper "sender data" request. The server
+// closes the connection after its response, so each batch uses a new one.
+func (z *Zabbix) send(items []item) error {
+	now := time.Now()
+	body, err := json.Marshal(&request{
+		Request: "sender data",
+		Data:    items,
+		Clock:   now.Unix(),
+		NS:      now.Nanosecond(),
+	})
+	if err != nil {
+		return fmt.Errorf("encoding request failed: %w", err)
+	}
+
+	conn, err := net.DialTimeout("tcp", z.Address, time.Duration(z.Timeout))
+	if err != nil {
+		return fmt.Errorf("connecting to %q failed: %w", z.Address, err)
+	}
+	defer conn.Close()
+	if z.Timeout > 0 {
+		if err := conn.SetDeadline(now.Add(time.Duration(z.Timeout))); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(len(header) + 8 + len(body))
+	buf.Write(header)
+	if err := binary.Write(&buf, binary.LittleEndian, uint64(len(body))); err != nil {
+		return err
+	}
+	buf.Write(body)
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing to %q failed: %w", z.Address, err)
+	}
+
+	resp, err := readResponse(conn)
+	if err != nil {
+		return fmt.Errorf("reading response from %q failed: %w", z.Address, err)
+	}
+	if resp.Response != "success" {
+		return fmt.Errorf("server responded with %q: %s", resp.Response, resp.Info)
+	}
+
+	// Items unknown to the server or with an unexpected value type are
+	// rejected individually. Retrying them would not help.
+	if match := processedRegexp.FindStringSubmatch(resp.Info); match != nil {
+		if failed, err := strconv.Atoi(match[1]); err == nil && failed > 0 {
+			z.Log.Warnf("Server rejected %d of %d items, check the host and key configuration in Zabbix: %s", failed, len(items), resp.Info)
+		}
+	}
+
+	return nil
+}
+
+func readResponse(r io.Reader) (*response, error) {
+	prefix := make([]byte, len(header)+8)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(prefix[:len(header)], header) {
+		return nil, errors.New("invalid response header")
+	}
+	size := binary.LittleEndian.Uint64(prefix[len(header):])
+	if size > maxResponseSize {
+		return nil, fmt.Errorf("response size %d exceeds limit", size)
+	}
+
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("decoding response failed: %w", err)
+	}
+	return &resp, nil
+}
+
+func formatValue(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case uint64:
+		return strconv.FormatUint(v, 10), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	case string:
+		return v, true
+	}
+	return "", false
+}
+
+// quoteParameter quotes item key parameters containing characters with a
+// special meaning in Zabbix item keys
+func quoteParameter(s string) string {
+	if !strings.ContainsAny(s, `,[]" `) && !strings.HasPrefix(s, `"`) {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// sortedTagKeys returns the keys of the tags not excluded from item keys
+func sortedTagKeys(m telegraf.Metric, excluded map[string]bool) []string {
+	keys := make([]string, 0, len(m.TagList()))
+	for _, tag := range m.TagList() {
+		if !excluded[tag.Key] {
+			keys = append(keys, tag.Key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	outputs.Add("zabbix", func() telegraf.Output {
+		return &Zabbix{
+			Address:          "localhost:10051",
+			Timeout:          config.Duration(5 * time.Second),
+			KeyPrefix:        "telegraf.",
+			ExcludeKeyTags:   []string{"host"},
+			BatchSize:        250,
+			LLDSendInterval:  config.Duration(10 * time.Minute),
+			LLDClearInterval: config.Duration(time.Hour),
+		}
+	})
+}
@@ -0,0 +1,307 @@
+package zabbix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+type trapper struct {
+	listener net.Listener
+	requests chan request
+	response string
+}
+
+func newTrapper(t *testing.T, response string) *trapper {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &trapper{
+		listener: listener,
+		requests: make(chan request, 100),
+		response: response,
+	}
+	go srv.serve()
+	t.Cleanup(func() { listener.Close() })
+	return srv
+}
+
+func (s *trapper) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *trapper) handle(conn net.Conn) {
+	defer conn.Close()
+
+	prefix := make([]byte, 13)
+	if _, err := io.ReadFull(conn, prefix); err != nil || !bytes.Equal(prefix[:5], header) {
+		return
+	}
+	body := make([]byte, binary.LittleEndian.Uint64(prefix[5:]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return
+	}
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return
+	}
+	s.requests <- req
+
+	info := s.response
+	if info == "" {
+		info = fmt.Sprintf("processed: %d; failed: 0; total: %d; seconds spent: 0.000055", len(req.Data), len(req.Data))
+	}
+	resp, err := json.Marshal(&response{Response: "success", Info: info})
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	buf.Write(header)
+	if err := binary.Write(&buf, binary.LittleEndian, uint64(len(resp))); err != nil {
+		return
+	}
+	buf.Write(resp)
+	_, _ = conn.Write(buf.Bytes())
+}
+
+func (s *trapper) receive(t *testing.T, n int) []request {
+	requests := make([]request, 0, n)
+	for i := 0; i < n; i++ {
+		select {
+		case req := <-s.requests:
+			requests = append(requests, req)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for requests", "received %d of %d", len(requests), n)
+		}
+	}
+	return requests
+}
+
+func TestInitFail(t *testing.T) {
+	plugin := &Zabbix{}
+	require.ErrorContains(t, plugin.Init(), "address required")
+
+	plugin = &Zabbix{Address: "localhost"}
+	require.ErrorContains(t, plugin.Init(), "invalid address")
+
+	plugin = &Zabbix{Address: "localhost:10051", HostTemplate: "{{ .Tag"}
+	require.ErrorContains(t, plugin.Init(), "parsing host template failed")
+
+	plugin = &Zabbix{Address: "localhost:10051", KeyTemplate: "{{ .Name"}
+	require.ErrorContains(t, plugin.Init(), "parsing key template failed")
+}
+
+func TestWrite(t *testing.T) {
+	srv := newTrapper(t, "")
+
+	plugin := &Zabbix{
+		Address:        srv.listener.Addr().String(),
+		Timeout:        config.Duration(time.Second),
+		KeyPrefix:      "telegraf.",
+		ExcludeKeyTags: []string{"host"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+	require.NoError(t, plugin.Connect())
+	defer plugin.Close()
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "server01", "cpu": "cpu0"},
+			map[string]interface{}{"usage_idle": 98.5},
+			time.Unix(1700000000, 5),
+		),
+		metric.New(
+			"disk",
+			map[string]string{"host": "server01", "device": "sda", "path": "/mnt/my data"},
+			map[string]interface{}{"free": uint64(10)},
+			time.Unix(1700000000, 5),
+		),
+		metric.New(
+			"system",
+			map[string]string{},
+			map[string]interface{}{"uptime": int64(42)},
+			time.Unix(1700000000, 5),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := []item{
+		{Host: "server01", Key: "telegraf.cpu.usage_idle[cpu0]", Value: "98.5", Clock: 1700000000, NS: 5},
+		{Host: "server01", Key: `telegraf.disk.free[sda,"/mnt/my data"]`, Value: "10", Clock: 1700000000, NS: 5},
+		{Host: plugin.hostname, Key: "telegraf.system.uptime", Value: "42", Clock: 1700000000, NS: 5},
+	}
+	requests := srv.receive(t, 1)
+	require.Equal(t, "sender data", requests[0].Request)
+	require.Equal(t, expected, requests[0].Data)
+}
+
+func TestWriteTemplates(t *testing.T) {
+	srv := newTrapper(t, "")
+
+	plugin := &Zabbix{
+		Address:      srv.listener.Addr().String(),
+		Timeout:      config.Duration(time.Second),
+		HostTemplate: `{{ .Tag "env" }}-{{ .Tag "node" }}`,
+		KeyTemplate:  `{{ .Name }}[{{ .Field }},{{ .Tag "node" }}]`,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"mem",
+			map[string]string{"env": "prod", "node": "n1"},
+			map[string]interface{}{"used": int64(1), "active": true},
+			time.Unix(1700000000, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	expected := []item{
+		{Host: "prod-n1", Key: "mem[used,n1]", Value: "1", Clock: 1700000000},
+		{Host: "prod-n1", Key: "mem[active,n1]", Value: "1", Clock: 1700000000},
+	}
+	require.ElementsMatch(t, expected, srv.receive(t, 1)[0].Data)
+}
+
+func TestWriteBatches(t *testing.T) {
+	srv := newTrapper(t, "")
+
+	plugin := &Zabbix{
+		Address:   srv.listener.Addr().String(),
+		Timeout:   config.Duration(time.Second),
+		BatchSize: 2,
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	metrics := make([]telegraf.Metric, 0, 5)
+	for i := 0; i < 5; i++ {
+		metrics = append(metrics, metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"value": i},
+			time.Unix(int64(i), 0),
+		))
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	requests := srv.receive(t, 3)
+	sizes := make([]int, 0, len(requests))
+	for _, req := range requests {
+		sizes = append(sizes, len(req.Data))
+	}
+	require.ElementsMatch(t, []int{2, 2, 1}, sizes)
+}
+
+func TestWriteFailedResponse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		prefix := make([]byte, 13)
+		if _, err := io.ReadFull(conn, prefix); err != nil {
+			return
+		}
+		if _, err := io.CopyN(io.Discard, conn, int64(binary.LittleEndian.Uint64(prefix[5:]))); err != nil {
+			return
+		}
+		resp := []byte(`{"response":"failed","info":"invalid request"}`)
+		var buf bytes.Buffer
+		buf.Write(header)
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(len(resp)))
+		buf.Write(resp)
+		_, _ = conn.Write(buf.Bytes())
+	}()
+
+	plugin := &Zabbix{
+		Address: listener.Addr().String(),
+		Timeout: config.Duration(time.Second),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+	}
+	require.ErrorContains(t, plugin.Write(metrics), `server responded with "failed": invalid request`)
+}
+
+func TestLowLevelDiscovery(t *testing.T) {
+	srv := newTrapper(t, "")
+
+	plugin := &Zabbix{
+		Address:          srv.listener.Addr().String(),
+		Timeout:          config.Duration(time.Second),
+		KeyPrefix:        "telegraf.",
+		ExcludeKeyTags:   []string{"host"},
+		LLDSendInterval:  config.Duration(time.Hour),
+		LLDClearInterval: config.Duration(time.Hour),
+		Log:              testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	metrics := []telegraf.Metric{
+		metric.New(
+			"net",
+			map[string]string{"host": "server01", "interface": "eth0", "net.ns": "default"},
+			map[string]interface{}{"bytes_recv": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"net",
+			map[string]string{"host": "server01", "interface": "eth1", "net.ns": "default"},
+			map[string]interface{}{"bytes_recv": int64(2)},
+			time.Unix(0, 0),
+		),
+	}
+	require.NoError(t, plugin.Write(metrics))
+
+	// The discovery data is sent before the values
+	requests := srv.receive(t, 2)
+	discovered := requests[0].Data
+	require.Len(t, discovered, 1)
+	require.Equal(t, "server01", discovered[0].Host)
+	require.Equal(t, "telegraf.lld.net.interface.net.ns", discovered[0].Key)
+	require.JSONEq(t,
+		`{"data":[{"{#INTERFACE}":"eth0","{#NET.NS}":"default"},{"{#INTERFACE}":"eth1","{#NET.NS}":"default"}]}`,
+		discovered[0].Value,
+	)
+	require.Len(t, requests[1].Data, 2)
+
+	// Known series do not trigger sending the discovery data again
+	require.NoError(t, plugin.Write(metrics[:1]))
+	requests = srv.receive(t, 1)
+	require.Len(t, requests[0].Data, 1)
+	require.Equal(t, "telegraf.net.bytes_recv[eth0,default]", requests[0].Data[0].Key)
+}
+
+func TestMacroName(t *testing.T) {
+	require.Equal(t, "MY_TAG.NAME_1", macroName("my-tag.name_1"))
+}
@@ -38,6 +38,15 @@ HTTPD20_ERRORLOG \[%{HTTPDERROR_DATE:timestamp}\] \[%{LOGLEVEL:loglevel:tag}\] (
 HTTPD24_ERRORLOG \[%{HTTPDERROR_DATE:timestamp}\] \[%{WORD:module}:%{LOGLEVEL:loglevel:tag}\] \[pid %{POSINT:pid:int}:tid %{NUMBER:tid:int}\]( \(%{POSINT:proxy_errorcode:int}\)%{DATA:proxy_errormessage}:)?( \[client %{IPORHOST:client}:%{POSINT:clientport}\])? %{DATA:errorcode}: %{GREEDYDATA:message}
 HTTPD_ERRORLOG %{HTTPD20_ERRORLOG}|%{HTTPD24_ERRORLOG}
 
+# NGINX error log format, e.g.
+#   2023/05/01 12:41:45 [error] 1234#5678: *9 open() "/var/www/favicon.ico" failed (2: No such file or directory)
+NGINX_ERROR_DATE %{YEAR}/%{MONTHNUM}/%{MONTHDAY} %{TIME}
+NGINX_ERRORLOG %{NGINX_ERROR_DATE:timestamp:ts-"2006/01/02 15:04:05"} \[%{LOGLEVEL:loglevel:tag}\] %{POSINT:pid:int}#%{NUMBER:tid:int}: (?:\*%{NUMBER:connection_id:int} )?%{GREEDYDATA:message}
+
+# Syslog messages as written to log files by syslog daemons, e.g.
+#   Jun  4 12:41:45 myhost sshd[1234]: Accepted publickey for user
+SYSLOG_FILE_FORMAT %{SYSLOGTIMESTAMP:timestamp:ts-syslog} %{SYSLOGHOST:logsource:tag} %{PROG:program:tag}(?:\[%{POSINT:pid:int}\])?: %{GREEDYDATA:message}
+
 # DATA spanning multiple lines
 MULTILINEDATA (.|\n)*
 `
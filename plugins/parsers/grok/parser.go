@@ -13,9 +13,11 @@ import (
 	"github.com/vjeantet/grok"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/selfstat"
 )
 
 var timeLayouts = map[string]string{
@@ -73,13 +75,17 @@ type Parser struct {
 	// specified by the user in Patterns.
 	// They will look like:
 	//   GROK_INTERNAL_PATTERN_0, GROK_INTERNAL_PATTERN_1, etc.
-	NamedPatterns      []string          `toml:"grok_named_patterns"`
-	CustomPatterns     string            `toml:"grok_custom_patterns"`
-	CustomPatternFiles []string          `toml:"grok_custom_pattern_files"`
-	Multiline          bool              `toml:"grok_multiline"`
-	Measurement        string            `toml:"-"`
-	DefaultTags        map[string]string `toml:"-"`
-	Log                telegraf.Logger   `toml:"-"`
+	NamedPatterns      []string        `toml:"grok_named_patterns"`
+	CustomPatterns     string          `toml:"grok_custom_patterns"`
+	CustomPatternFiles []string        `toml:"grok_custom_pattern_files"`
+	ReloadInterval     config.Duration `toml:"grok_reload_interval"`
+	Multiline          bool            `toml:"grok_multiline"`
+	// UnmatchedMeasurement is the name of the metrics created for lines not
+	// matching any pattern, the lines are dropped if empty.
+	UnmatchedMeasurement string            `toml:"grok_unmatched_measurement"`
+	Measurement          string            `toml:"-"`
+	DefaultTags          map[string]string `toml:"-"`
+	Log                  telegraf.Logger   `toml:"-"`
 
 	// Timezone is an optional component to help render log dates to
 	// your chosen zone.
@@ -125,6 +131,18 @@ type Parser struct {
 	// layouts.
 	foundTsLayouts []string
 
+	// customPatterns contains the default, custom and internally named
+	// patterns
+	customPatterns string
+	// modTimes contains the modification times of the custom pattern files
+	// when they were loaded.
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+
+	// matches counts the lines matched by each of the named patterns
+	matches   []selfstat.Stat
+	unmatched selfstat.Stat
+
 	timeFunc func() time.Time
 	g        *grok.Grok
 	tsModder *tsModder
@@ -132,15 +150,7 @@ type Parser struct {
 
 // Compile is a bound method to Parser which will process the options for our parser
 func (p *Parser) Compile() error {
-	p.typeMap = make(map[string]map[string]string)
-	p.tsMap = make(map[string]map[string]string)
-	p.patternsMap = make(map[string]string)
 	p.tsModder = &tsModder{}
-	var err error
-	p.g, err = grok.NewWithConfig(&grok.Config{NamedCapturesOnly: true})
-	if err != nil {
-		return err
-	}
 
 	if p.UniqueTimestamp == "" {
 		p.UniqueTimestamp = "auto"
@@ -148,76 +158,145 @@ func (p *Parser) Compile() error {
 
 	// Give Patterns fake names so that they can be treated as named
 	// "custom patterns"
+	var internalPatterns string
 	p.NamedPatterns = make([]string, 0, len(p.Patterns))
+	p.matches = make([]selfstat.Stat, 0, len(p.Patterns))
 	for i, pattern := range p.Patterns {
 		pattern = strings.TrimSpace(pattern)
 		if pattern == "" {
 			continue
 		}
 		name := fmt.Sprintf("GROK_INTERNAL_PATTERN_%d", i)
-		p.CustomPatterns += "\n" + name + " " + pattern + "\n"
+		internalPatterns += "\n" + name + " " + pattern + "\n"
 		p.NamedPatterns = append(p.NamedPatterns, "%{"+name+"}")
+		p.matches = append(p.matches, selfstat.Register("grok", "matches", map[string]string{"pattern": pattern}))
 	}
 
 	if len(p.NamedPatterns) == 0 {
 		return fmt.Errorf("pattern required")
 	}
+	p.unmatched = selfstat.Register("grok", "unmatched", map[string]string{})
 
 	// Combine user-supplied CustomPatterns with DEFAULT_PATTERNS and parse
 	// them together as the same type of pattern.
-	p.CustomPatterns = DefaultPatterns + p.CustomPatterns
-	if len(p.CustomPatterns) != 0 {
-		scanner := bufio.NewScanner(strings.NewReader(p.CustomPatterns))
-		p.addCustomPatterns(scanner)
+	p.customPatterns = DefaultPatterns + p.CustomPatterns + internalPatterns
+
+	var err error
+	p.loc, err = time.LoadLocation(p.Timezone)
+	if err != nil {
+		p.Log.Warnf("Improper timezone supplied (%s), setting loc to UTC", p.Timezone)
+		p.loc, _ = time.LoadLocation("UTC")
 	}
 
+	if p.timeFunc == nil {
+		p.timeFunc = time.Now
+	}
+	p.lastCheck = time.Now()
+
+	return p.loadPatterns()
+}
+
+// loadPatterns compiles the custom patterns including the ones of the custom
+// pattern files
+func (p *Parser) loadPatterns() error {
+	var err error
+	p.typeMap = make(map[string]map[string]string)
+	p.tsMap = make(map[string]map[string]string)
+	p.patternsMap = make(map[string]string)
+	p.g, err = grok.NewWithConfig(&grok.Config{NamedCapturesOnly: true})
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(p.customPatterns))
+	p.addCustomPatterns(scanner)
+
 	// Parse any custom pattern files supplied.
+	p.modTimes = make(map[string]time.Time, len(p.CustomPatternFiles))
 	for _, filename := range p.CustomPatternFiles {
-		file, fileErr := os.Open(filename)
-		if fileErr != nil {
-			return fileErr
+		if err := p.addCustomPatternFile(filename); err != nil {
+			return err
 		}
+	}
 
-		scanner := bufio.NewScanner(bufio.NewReader(file))
-		p.addCustomPatterns(scanner)
+	return p.compileCustomPatterns()
+}
+
+func (p *Parser) addCustomPatternFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
 
-	p.loc, err = time.LoadLocation(p.Timezone)
+	info, err := file.Stat()
 	if err != nil {
-		p.Log.Warnf("Improper timezone supplied (%s), setting loc to UTC", p.Timezone)
-		p.loc, _ = time.LoadLocation("UTC")
+		return err
 	}
+	p.modTimes[filename] = info.ModTime()
 
-	if p.timeFunc == nil {
-		p.timeFunc = time.Now
+	scanner := bufio.NewScanner(bufio.NewReader(file))
+	p.addCustomPatterns(scanner)
+	return nil
+}
+
+// reloadPatterns loads the patterns again if any of the custom pattern files
+// changed. The check is only done once per reload interval and the previous
+// patterns are kept in case of errors.
+func (p *Parser) reloadPatterns() {
+	if time.Since(p.lastCheck) < time.Duration(p.ReloadInterval) {
+		return
 	}
+	p.lastCheck = time.Now()
 
-	return p.compileCustomPatterns()
+	var changed bool
+	for _, filename := range p.CustomPatternFiles {
+		info, err := os.Stat(filename)
+		if err != nil {
+			p.Log.Errorf("Checking custom pattern file %q failed: %v", filename, err)
+			return
+		}
+		if !info.ModTime().Equal(p.modTimes[filename]) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	typeMap, tsMap, patternsMap, g, modTimes := p.typeMap, p.tsMap, p.patternsMap, p.g, p.modTimes
+	if err := p.loadPatterns(); err != nil {
+		p.Log.Errorf("Reloading custom pattern files failed, keeping previous patterns: %v", err)
+		p.typeMap, p.tsMap, p.patternsMap, p.g, p.modTimes = typeMap, tsMap, patternsMap, g, modTimes
+		return
+	}
+	p.Log.Infof("Reloaded custom pattern files")
 }
 
 // ParseLine is the primary function to process individual lines, returning the metrics
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	if p.ReloadInterval > 0 && len(p.CustomPatternFiles) > 0 {
+		p.reloadPatterns()
+	}
+
 	var err error
 	// values are the parsed fields from the log line
 	var values map[string]string
 	// the matching pattern string
 	var patternName string
-	for _, pattern := range p.NamedPatterns {
+	for i, pattern := range p.NamedPatterns {
 		if values, err = p.g.Parse(pattern, line); err != nil {
 			return nil, err
 		}
 		if len(values) != 0 {
 			patternName = pattern
+			if i < len(p.matches) {
+				p.matches[i].Incr(1)
+			}
 			break
 		}
 	}
 
-	if len(values) == 0 {
-		p.Log.Debugf("Grok no match found for: %q", line)
-		return nil, nil
-	}
-
-	fields := make(map[string]interface{})
 	tags := make(map[string]string)
 
 	//add default tags
@@ -225,6 +304,20 @@ func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 		tags[k] = v
 	}
 
+	if len(values) == 0 {
+		if p.unmatched != nil {
+			p.unmatched.Incr(1)
+		}
+		if p.UnmatchedMeasurement == "" {
+			p.Log.Debugf("Grok no match found for: %q", line)
+			return nil, nil
+		}
+		fields := map[string]interface{}{"message": line}
+		return metric.New(p.UnmatchedMeasurement, tags, fields, p.timeFunc()), nil
+	}
+
+	fields := make(map[string]interface{})
+
 	timestamp := time.Now()
 	for k, v := range values {
 		if k == "" || v == "" {
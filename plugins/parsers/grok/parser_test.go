@@ -3,12 +3,14 @@ package grok
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 )
@@ -1184,3 +1186,148 @@ func TestMultilineNilMetric(t *testing.T) {
 	require.NoError(t, err)
 	require.Empty(t, actual)
 }
+
+func TestMultiplePatternsMatchCounter(t *testing.T) {
+	p := &Parser{
+		Measurement: "logs",
+		Patterns: []string{
+			`counter-test first %{NUMBER:value:int}`,
+			`counter-test %{WORD:kind:tag} %{NUMBER:value:int}`,
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+	require.Len(t, p.matches, 2)
+	first, second, unmatched := p.matches[0].Get(), p.matches[1].Get(), p.unmatched.Get()
+
+	// The first matching pattern wins
+	actual, err := p.Parse([]byte("counter-test first 1\ncounter-test second 2\ncounter-test third 3\nother"))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New("logs", map[string]string{}, map[string]interface{}{"value": int64(1)}, time.Unix(0, 0)),
+		metric.New("logs", map[string]string{"kind": "second"}, map[string]interface{}{"value": int64(2)}, time.Unix(0, 0)),
+		metric.New("logs", map[string]string{"kind": "third"}, map[string]interface{}{"value": int64(3)}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+
+	require.Equal(t, int64(1), p.matches[0].Get()-first)
+	require.Equal(t, int64(2), p.matches[1].Get()-second)
+	require.Equal(t, int64(1), p.unmatched.Get()-unmatched)
+}
+
+func TestUnmatchedMeasurement(t *testing.T) {
+	p := &Parser{
+		Measurement:          "logs",
+		Patterns:             []string{`%{WORD:kind:tag} %{NUMBER:value:int}`},
+		UnmatchedMeasurement: "unmatched",
+		DefaultTags:          map[string]string{"host": "localhost"},
+		Log:                  testutil.Logger{},
+		timeFunc:             func() time.Time { return time.Unix(1, 0) },
+	}
+	require.NoError(t, p.Compile())
+
+	actual, err := p.Parse([]byte("first 1\nnot matching\n"))
+	require.NoError(t, err)
+
+	expected := []telegraf.Metric{
+		metric.New(
+			"logs",
+			map[string]string{"host": "localhost", "kind": "first"},
+			map[string]interface{}{"value": int64(1)},
+			time.Unix(0, 0),
+		),
+		metric.New(
+			"unmatched",
+			map[string]string{"host": "localhost"},
+			map[string]interface{}{"message": "not matching"},
+			time.Unix(1, 0),
+		),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+	require.Equal(t, time.Unix(1, 0), actual[1].Time())
+}
+
+func TestReloadCustomPatternFiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "patterns")
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_VALUE %{NUMBER:value:int}\n"), 0600))
+
+	p := &Parser{
+		Measurement:        "logs",
+		Patterns:           []string{`value=%{TEST_VALUE}`},
+		CustomPatternFiles: []string{filename},
+		ReloadInterval:     config.Duration(time.Nanosecond),
+		Log:                testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine("value=42")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]interface{}{"value": int64(42)}, m.Fields())
+
+	// Change the pattern and make sure the modification time differs
+	require.NoError(t, os.WriteFile(filename, []byte("TEST_VALUE %{NUMBER:value:float}\n"), 0600))
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filename, future, future))
+
+	m, err = p.ParseLine("value=42")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]interface{}{"value": float64(42)}, m.Fields())
+
+	// Keep the patterns if the file vanishes
+	require.NoError(t, os.Remove(filename))
+	m, err = p.ParseLine("value=42")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	require.Equal(t, map[string]interface{}{"value": float64(42)}, m.Fields())
+}
+
+func TestNginxErrorLog(t *testing.T) {
+	p := &Parser{
+		Measurement: "nginx",
+		Patterns:    []string{"%{NGINX_ERRORLOG}"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine(`2023/05/01 12:41:45 [error] 1234#5678: *9 open() "/var/www/favicon.ico" failed (2: No such file or directory)`)
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"nginx",
+		map[string]string{"loglevel": "error"},
+		map[string]interface{}{
+			"pid":           int64(1234),
+			"tid":           int64(5678),
+			"connection_id": int64(9),
+			"message":       `open() "/var/www/favicon.ico" failed (2: No such file or directory)`,
+		},
+		time.Date(2023, 5, 1, 12, 41, 45, 0, time.UTC),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+}
+
+func TestSyslogFileFormat(t *testing.T) {
+	p := &Parser{
+		Measurement: "syslog",
+		Patterns:    []string{"%{SYSLOG_FILE_FORMAT}"},
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, p.Compile())
+
+	m, err := p.ParseLine(`Jun  4 12:41:45 myhost sshd[1234]: Accepted publickey for user`)
+	require.NoError(t, err)
+
+	expected := metric.New(
+		"syslog",
+		map[string]string{"logsource": "myhost", "program": "sshd"},
+		map[string]interface{}{
+			"pid":     int64(1234),
+			"message": "Accepted publickey for user",
+		},
+		time.Date(time.Now().Year(), 6, 4, 12, 41, 45, 0, time.UTC),
+	)
+	testutil.RequireMetricEqual(t, expected, m)
+}
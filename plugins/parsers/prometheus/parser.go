@@ -25,6 +25,10 @@ type Parser struct {
 	DefaultTags     map[string]string `toml:"-"`
 	Header          http.Header       `toml:"-"` // set by the prometheus input
 	IgnoreTimestamp bool              `toml:"prometheus_ignore_timestamp"`
+
+	// Keep histograms and summaries as single field values instead of
+	// splitting them into buckets and quantiles
+	NativeHistograms bool `toml:"prometheus_native_histograms"`
 }
 
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
@@ -74,7 +78,10 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 			tags := common.MakeLabels(m, p.DefaultTags)
 			t := p.GetTimestamp(m, now)
 
-			if mf.GetType() == dto.MetricType_SUMMARY {
+			if p.NativeHistograms && (mf.GetType() == dto.MetricType_SUMMARY || mf.GetType() == dto.MetricType_HISTOGRAM) {
+				fields := map[string]interface{}{metricName: makeNativeValue(m)}
+				metrics = append(metrics, metric.New("prometheus", tags, fields, t, common.ValueType(mf.GetType())))
+			} else if mf.GetType() == dto.MetricType_SUMMARY {
 				// summary metric
 				telegrafMetrics := makeQuantiles(m, tags, metricName, mf.GetType(), t)
 				metrics = append(metrics, telegrafMetrics...)
@@ -180,6 +187,36 @@ func makeBuckets(m *dto.Metric, tags map[string]string, metricName string, metri
 	return metrics
 }
 
+// Get the histogram or summary as a single value
+func makeNativeValue(m *dto.Metric) interface{} {
+	if s := m.GetSummary(); s != nil {
+		v := &telegraf.SummaryValue{
+			Count:     s.GetSampleCount(),
+			Sum:       s.GetSampleSum(),
+			Quantiles: make([]telegraf.Quantile, 0, len(s.Quantile)),
+		}
+		for _, q := range s.Quantile {
+			v.Quantiles = append(v.Quantiles, telegraf.Quantile{Quantile: q.GetQuantile(), Value: q.GetValue()})
+		}
+		return v
+	}
+
+	h := m.GetHistogram()
+	v := &telegraf.HistogramValue{
+		Count:   h.GetSampleCount(),
+		Sum:     h.GetSampleSum(),
+		Buckets: make([]telegraf.Bucket, 0, len(h.Bucket)+1),
+	}
+	for _, b := range h.Bucket {
+		v.Buckets = append(v.Buckets, telegraf.Bucket{UpperBound: b.GetUpperBound(), Count: b.GetCumulativeCount()})
+	}
+	// Infinity bucket is required for proper function of histogram in prometheus
+	if len(v.Buckets) == 0 || !math.IsInf(v.Buckets[len(v.Buckets)-1].UpperBound, +1) {
+		v.Buckets = append(v.Buckets, telegraf.Bucket{UpperBound: math.Inf(1), Count: h.GetSampleCount()})
+	}
+	return v
+}
+
 // Get name and value from metric
 func getNameAndValue(m *dto.Metric, metricName string) map[string]interface{} {
 	fields := make(map[string]interface{})
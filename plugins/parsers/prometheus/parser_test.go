@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -647,3 +648,57 @@ func TestHistogramInfBucketPresence(t *testing.T) {
 
 	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime(), testutil.SortMetrics())
 }
+
+func TestParsingNativeHistogramAndSummary(t *testing.T) {
+	expected := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{
+				"resource": "bindings",
+				"verb":     "POST",
+			},
+			map[string]interface{}{
+				"apiserver_request_latencies": &telegraf.HistogramValue{
+					Count: 2025,
+					Sum:   1.02726334e+08,
+					Buckets: []telegraf.Bucket{
+						{UpperBound: 125000, Count: 1994},
+						{UpperBound: 250000, Count: 1997},
+						{UpperBound: 500000, Count: 2000},
+						{UpperBound: 1e+06, Count: 2005},
+						{UpperBound: 2e+06, Count: 2012},
+						{UpperBound: 4e+06, Count: 2017},
+						{UpperBound: 8e+06, Count: 2024},
+						{UpperBound: math.Inf(1), Count: 2025},
+					},
+				},
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{
+				"handler": "prometheus",
+			},
+			map[string]interface{}{
+				"http_request_duration_microseconds": &telegraf.SummaryValue{
+					Count: 9,
+					Sum:   1.8909097205e+07,
+					Quantiles: []telegraf.Quantile{
+						{Quantile: 0.5, Value: 552048.506},
+						{Quantile: 0.9, Value: 5.876804288e+06},
+						{Quantile: 0.99, Value: 5.876804288e+06},
+					},
+				},
+			},
+			time.Unix(0, 0),
+			telegraf.Summary,
+		),
+	}
+
+	parser := Parser{NativeHistograms: true}
+	metrics, err := parser.Parse([]byte(validUniqueHistogram + validUniqueSummary))
+	require.NoError(t, err)
+	testutil.RequireMetricsEqual(t, expected, metrics, testutil.IgnoreTime(), testutil.SortMetrics())
+}
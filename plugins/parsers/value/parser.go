@@ -2,10 +2,12 @@ package value
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
@@ -13,10 +15,12 @@ import (
 )
 
 type Parser struct {
-	DataType    string            `toml:"data_type"`
-	FieldName   string            `toml:"value_field_name"`
-	MetricName  string            `toml:"-"`
-	DefaultTags map[string]string `toml:"-"`
+	DataType         string            `toml:"data_type"`
+	FieldName        string            `toml:"value_field_name"`
+	FieldNames       []string          `toml:"value_field_names"`
+	MeasurementLabel bool              `toml:"value_measurement_label"`
+	MetricName       string            `toml:"-"`
+	DefaultTags      map[string]string `toml:"-"`
 }
 
 func (v *Parser) Init() error {
@@ -39,10 +43,25 @@ func (v *Parser) Init() error {
 		v.FieldName = "value"
 	}
 
+	seen := make(map[string]bool, len(v.FieldNames))
+	for _, name := range v.FieldNames {
+		if name == "" {
+			return errors.New("empty name in 'value_field_names'")
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate name %q in 'value_field_names'", name)
+		}
+		seen[name] = true
+	}
+
 	return nil
 }
 
 func (v *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	if len(v.FieldNames) > 0 || v.MeasurementLabel {
+		return v.parseLines(buf)
+	}
+
 	vStr := string(bytes.TrimSpace(bytes.Trim(buf, "\x00")))
 
 	// unless it's a string, separate out any fields in the buffer,
@@ -55,6 +74,85 @@ func (v *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 		vStr = values[len(values)-1]
 	}
 
+	value, err := v.convert(vStr)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{v.FieldName: value}
+	m := metric.New(v.MetricName, v.DefaultTags,
+		fields, time.Now().UTC())
+
+	return []telegraf.Metric{m}, nil
+}
+
+// parseLines creates a metric for each line containing one or more values
+// separated by whitespace or commas, optionally prefixed by a label used as
+// the measurement name, e.g. "temperature: 21.5, 45"
+func (v *Parser) parseLines(buf []byte) ([]telegraf.Metric, error) {
+	now := time.Now().UTC()
+
+	metrics := make([]telegraf.Metric, 0)
+	for _, line := range strings.Split(string(bytes.Trim(buf, "\x00")), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		name := v.MetricName
+		if v.MeasurementLabel {
+			var remainder string
+			if idx := strings.IndexFunc(line, isLabelSeparator); idx >= 0 {
+				name, remainder = line[:idx], line[idx:]
+			} else {
+				name, remainder = line, ""
+			}
+			if name == "" {
+				return nil, fmt.Errorf("empty label in line %q", line)
+			}
+			line = strings.TrimLeftFunc(remainder, isLabelSeparator)
+		}
+
+		var values []string
+		names := v.FieldNames
+		if len(names) == 0 {
+			// Use the remainder as a single value like for unlabeled data
+			names = []string{v.FieldName}
+			if v.DataType == "string" {
+				values = []string{line}
+			} else if fields := strings.FieldsFunc(line, isSeparator); len(fields) > 0 {
+				values = fields[len(fields)-1:]
+			}
+		} else {
+			values = strings.FieldsFunc(line, isSeparator)
+		}
+		if len(values) != len(names) {
+			return nil, fmt.Errorf("line %q contains %d values but %d are required", line, len(values), len(names))
+		}
+
+		fields := make(map[string]interface{}, len(names))
+		for i, vStr := range values {
+			value, err := v.convert(vStr)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", names[i], err)
+			}
+			fields[names[i]] = value
+		}
+		metrics = append(metrics, metric.New(name, v.DefaultTags, fields, now))
+	}
+
+	return metrics, nil
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == ','
+}
+
+func isLabelSeparator(r rune) bool {
+	return isSeparator(r) || r == ':'
+}
+
+func (v *Parser) convert(vStr string) (interface{}, error) {
 	var value interface{}
 	var err error
 	switch v.DataType {
@@ -79,15 +177,7 @@ func (v *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 			err = nil
 		}
 	}
-	if err != nil {
-		return nil, err
-	}
-
-	fields := map[string]interface{}{v.FieldName: value}
-	m := metric.New(v.MetricName, v.DefaultTags,
-		fields, time.Now().UTC())
-
-	return []telegraf.Metric{m}, nil
+	return value, err
 }
 
 func (v *Parser) ParseLine(line string) (telegraf.Metric, error) {
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
@@ -298,3 +299,204 @@ func TestInvalidDatatype(t *testing.T) {
 	}
 	require.ErrorContains(t, parser.Init(), "unknown datatype")
 }
+
+func TestParseMultipleValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   Parser
+		input    string
+		expected []telegraf.Metric
+	}{
+		{
+			name: "whitespace separated",
+			parser: Parser{
+				DataType:   "float",
+				FieldNames: []string{"temperature", "humidity"},
+			},
+			input: "21.5 45\n22 44.5\n",
+			expected: []telegraf.Metric{
+				metric.New(
+					"value_test",
+					map[string]string{},
+					map[string]interface{}{"temperature": 21.5, "humidity": float64(45)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"value_test",
+					map[string]string{},
+					map[string]interface{}{"temperature": float64(22), "humidity": 44.5},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "comma separated",
+			parser: Parser{
+				DataType:   "auto_integer",
+				FieldNames: []string{"a", "b", "c"},
+			},
+			input: "1,2, ok\r\n\n",
+			expected: []telegraf.Metric{
+				metric.New(
+					"value_test",
+					map[string]string{},
+					map[string]interface{}{"a": int64(1), "b": int64(2), "c": "ok"},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "labeled lines",
+			parser: Parser{
+				DataType:         "float",
+				FieldNames:       []string{"temperature", "humidity"},
+				MeasurementLabel: true,
+			},
+			input: "sensor1: 21.5, 45\nsensor2:22 44.5\n",
+			expected: []telegraf.Metric{
+				metric.New(
+					"sensor1",
+					map[string]string{},
+					map[string]interface{}{"temperature": 21.5, "humidity": float64(45)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"sensor2",
+					map[string]string{},
+					map[string]interface{}{"temperature": float64(22), "humidity": 44.5},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "labeled single value",
+			parser: Parser{
+				DataType:         "integer",
+				MeasurementLabel: true,
+			},
+			input: "entropy 3840\nload: 1 2 3\n",
+			expected: []telegraf.Metric{
+				metric.New(
+					"entropy",
+					map[string]string{},
+					map[string]interface{}{"value": int64(3840)},
+					time.Unix(0, 0),
+				),
+				metric.New(
+					"load",
+					map[string]string{},
+					map[string]interface{}{"value": int64(3)},
+					time.Unix(0, 0),
+				),
+			},
+		},
+		{
+			name: "labeled string",
+			parser: Parser{
+				DataType:         "string",
+				MeasurementLabel: true,
+			},
+			input: "status: all systems go\n",
+			expected: []telegraf.Metric{
+				metric.New(
+					"status",
+					map[string]string{},
+					map[string]interface{}{"value": "all systems go"},
+					time.Unix(0, 0),
+				),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := tt.parser
+			parser.MetricName = "value_test"
+			require.NoError(t, parser.Init())
+
+			actual, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, tt.expected, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestParseMultipleValuesInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		parser   Parser
+		input    string
+		expected string
+	}{
+		{
+			name: "too few values",
+			parser: Parser{
+				DataType:   "float",
+				FieldNames: []string{"temperature", "humidity"},
+			},
+			input:    "21.5\n",
+			expected: "contains 1 values but 2 are required",
+		},
+		{
+			name: "too many values",
+			parser: Parser{
+				DataType:   "float",
+				FieldNames: []string{"temperature", "humidity"},
+			},
+			input:    "21.5 45 3\n",
+			expected: "contains 3 values but 2 are required",
+		},
+		{
+			name: "invalid value",
+			parser: Parser{
+				DataType:   "integer",
+				FieldNames: []string{"temperature", "humidity"},
+			},
+			input:    "21 foo\n",
+			expected: `field "humidity"`,
+		},
+		{
+			name: "empty label",
+			parser: Parser{
+				DataType:         "integer",
+				MeasurementLabel: true,
+			},
+			input:    ": 42\n",
+			expected: "empty label",
+		},
+		{
+			name: "label without value",
+			parser: Parser{
+				DataType:         "integer",
+				MeasurementLabel: true,
+			},
+			input:    "entropy\n",
+			expected: "contains 0 values but 1 are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := tt.parser
+			parser.MetricName = "value_test"
+			require.NoError(t, parser.Init())
+
+			_, err := parser.Parse([]byte(tt.input))
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInvalidFieldNames(t *testing.T) {
+	parser := Parser{
+		DataType:   "float",
+		FieldNames: []string{"a", ""},
+	}
+	require.ErrorContains(t, parser.Init(), "empty name")
+
+	parser = Parser{
+		DataType:   "float",
+		FieldNames: []string{"a", "b", "a"},
+	}
+	require.ErrorContains(t, parser.Init(), `duplicate name "a"`)
+}
@@ -1,9 +1,9 @@
 package xpath
 
 import (
+	"bytes"
 	"reflect"
 	"strconv"
-	"strings"
 
 	path "github.com/antchfx/xpath"
 	"github.com/srebhan/cborquery"
@@ -12,21 +12,18 @@ import (
 type cborDocument struct{}
 
 func (d *cborDocument) Parse(buf []byte) (dataNode, error) {
-	return cborquery.Parse(strings.NewReader(string(buf)))
+	return cborquery.Parse(bytes.NewReader(buf))
 }
 
-func (d *cborDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
+func (d *cborDocument) QueryAll(node dataNode, expr *path.Expr) []dataNode {
 	// If this panics it's a programming error as we changed the document type while processing
-	native, err := cborquery.QueryAll(node.(*cborquery.Node), expr)
-	if err != nil {
-		return nil, err
-	}
+	native := cborquery.QuerySelectorAll(node.(*cborquery.Node), expr)
 
 	nodes := make([]dataNode, 0, len(native))
 	for _, n := range native {
 		nodes = append(nodes, n)
 	}
-	return nodes, nil
+	return nodes
 }
 
 func (d *cborDocument) CreateXPathNavigator(node dataNode) path.NodeNavigator {
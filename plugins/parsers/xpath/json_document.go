@@ -1,9 +1,9 @@
 package xpath
 
 import (
+	"bytes"
 	"reflect"
 	"strconv"
-	"strings"
 
 	"github.com/antchfx/jsonquery"
 	path "github.com/antchfx/xpath"
@@ -12,21 +12,18 @@ import (
 type jsonDocument struct{}
 
 func (d *jsonDocument) Parse(buf []byte) (dataNode, error) {
-	return jsonquery.Parse(strings.NewReader(string(buf)))
+	return jsonquery.Parse(bytes.NewReader(buf))
 }
 
-func (d *jsonDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
+func (d *jsonDocument) QueryAll(node dataNode, expr *path.Expr) []dataNode {
 	// If this panics it's a programming error as we changed the document type while processing
-	native, err := jsonquery.QueryAll(node.(*jsonquery.Node), expr)
-	if err != nil {
-		return nil, err
-	}
+	native := jsonquery.QuerySelectorAll(node.(*jsonquery.Node), expr)
 
 	nodes := make([]dataNode, 0, len(native))
 	for _, n := range native {
 		nodes = append(nodes, n)
 	}
-	return nodes, nil
+	return nodes
 }
 
 func (d *jsonDocument) CreateXPathNavigator(node dataNode) path.NodeNavigator {
@@ -21,7 +21,7 @@ func (d *msgpackDocument) Parse(buf []byte) (dataNode, error) {
 	return jsonquery.Parse(&json)
 }
 
-func (d *msgpackDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
+func (d *msgpackDocument) QueryAll(node dataNode, expr *path.Expr) []dataNode {
 	return (*jsonDocument)(d).QueryAll(node, expr)
 }
 
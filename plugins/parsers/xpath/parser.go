@@ -16,6 +16,7 @@ import (
 	"github.com/srebhan/cborquery"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/metric"
@@ -27,13 +28,19 @@ type dataNode interface{}
 
 type dataDocument interface {
 	Parse(buf []byte) (dataNode, error)
-	QueryAll(node dataNode, expr string) ([]dataNode, error)
+	QueryAll(node dataNode, expr *path.Expr) []dataNode
 	CreateXPathNavigator(node dataNode) path.NodeNavigator
 	GetNodePath(node, relativeTo dataNode, sep string) string
 	GetNodeName(node dataNode, sep string, withParent bool) string
 	OutputXML(node dataNode) string
 }
 
+// streamingDocument is implemented by documents able to process the selected
+// nodes one-by-one without building the tree of the whole document
+type streamingDocument interface {
+	Stream(buf []byte, selection string, fn func(doc, node dataNode) error) error
+}
+
 type Parser struct {
 	Format              string            `toml:"-"`
 	ProtobufMessageDef  string            `toml:"xpath_protobuf_file"`
@@ -43,6 +50,8 @@ type Parser struct {
 	PrintDocument       bool              `toml:"xpath_print_document"`
 	AllowEmptySelection bool              `toml:"xpath_allow_empty_selection"`
 	NativeTypes         bool              `toml:"xpath_native_types"`
+	MaxDocumentSize     config.Size       `toml:"xpath_max_document_size"`
+	Streaming           bool              `toml:"xpath_streaming"`
 	Configs             []Config          `toml:"xpath"`
 	DefaultMetricName   string            `toml:"-"`
 	DefaultTags         map[string]string `toml:"-"`
@@ -54,7 +63,8 @@ type Parser struct {
 	ConfigsMsgPack []Config `toml:"xpath_msgpack" deprecated:"1.23.1;use 'xpath' instead"`
 	ConfigsProto   []Config `toml:"xpath_protobuf" deprecated:"1.23.1;use 'xpath' instead"`
 
-	document dataDocument
+	document    dataDocument
+	expressions map[string]*path.Expr
 }
 
 type Config struct {
@@ -155,6 +165,18 @@ func (p *Parser) Init() error {
 		return errors.New("missing default metric name")
 	}
 
+	if p.Streaming {
+		if _, ok := p.document.(streamingDocument); !ok {
+			return fmt.Errorf("streaming is not supported for data-format %q", p.Format)
+		}
+	}
+
+	// Compile the default queries for batch tags and fields
+	p.expressions = make(map[string]*path.Expr)
+	if err := p.compileQueries("name()", "."); err != nil {
+		return err
+	}
+
 	// Update the configs with default values
 	for i, config := range p.Configs {
 		if config.Selection == "" {
@@ -184,6 +206,31 @@ func (p *Parser) Init() error {
 		}
 		config.FieldsBase64Filter = bf
 
+		// Compile all queries once to avoid doing so for each document
+		queries := []string{
+			config.Selection,
+			config.MetricQuery,
+			config.Timestamp,
+			config.FieldSelection,
+			config.FieldNameQuery,
+			config.FieldValueQuery,
+			config.TagSelection,
+			config.TagNameQuery,
+			config.TagValueQuery,
+		}
+		for _, query := range config.Tags {
+			queries = append(queries, query)
+		}
+		for _, query := range config.FieldsInt {
+			queries = append(queries, query)
+		}
+		for _, query := range config.Fields {
+			queries = append(queries, query)
+		}
+		if err := p.compileQueries(queries...); err != nil {
+			return fmt.Errorf("invalid query in config %d: %w", i+1, err)
+		}
+
 		p.Configs[i] = config
 	}
 
@@ -193,6 +240,14 @@ func (p *Parser) Init() error {
 func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	t := time.Now()
 
+	if p.MaxDocumentSize > 0 && int64(len(buf)) > int64(p.MaxDocumentSize) {
+		return nil, fmt.Errorf("document size of %d bytes exceeds the maximum of %d bytes", len(buf), int64(p.MaxDocumentSize))
+	}
+
+	if p.Streaming {
+		return p.parseStream(t, buf)
+	}
+
 	// Parse the XML
 	doc, err := p.document.Parse(buf)
 	if err != nil {
@@ -206,10 +261,11 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	metrics := make([]telegraf.Metric, 0)
 	p.Log.Debugf("Number of configs: %d", len(p.Configs))
 	for _, config := range p.Configs {
-		selectedNodes, err := p.document.QueryAll(doc, config.Selection)
+		expr, err := p.compile(config.Selection)
 		if err != nil {
 			return nil, err
 		}
+		selectedNodes := p.document.QueryAll(doc, expr)
 		if (len(selectedNodes) < 1 || selectedNodes[0] == nil) && !p.AllowEmptySelection {
 			p.debugEmptyQuery("metric selection", doc, config.Selection)
 			return metrics, fmt.Errorf("cannot parse with empty selection node")
@@ -229,6 +285,36 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	return metrics, nil
 }
 
+// parseStream processes the selected nodes of each config one-by-one while
+// reading the document instead of building the tree of the whole document
+func (p *Parser) parseStream(t time.Time, buf []byte) ([]telegraf.Metric, error) {
+	// If this panics it's a programming error as we checked the document type in Init
+	stream := p.document.(streamingDocument)
+
+	metrics := make([]telegraf.Metric, 0)
+	for _, config := range p.Configs {
+		var count int
+		err := stream.Stream(buf, config.Selection, func(doc, selected dataNode) error {
+			m, err := p.parseQuery(t, doc, selected, config)
+			if err != nil {
+				return err
+			}
+			metrics = append(metrics, m)
+			count++
+			return nil
+		})
+		if err != nil {
+			return metrics, err
+		}
+		if count == 0 && !p.AllowEmptySelection {
+			return metrics, fmt.Errorf("cannot parse with empty selection node")
+		}
+		p.Log.Debugf("Number of streamed metric nodes: %d", count)
+	}
+
+	return metrics, nil
+}
+
 func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
 	metrics, err := p.Parse([]byte(line))
 	if err != nil {
@@ -301,10 +387,11 @@ func (p *Parser) parseQuery(starttime time.Time, doc, selected dataNode, config
 		}
 
 		// Query all tags
-		selectedTagNodes, err := p.document.QueryAll(selected, config.TagSelection)
+		expr, err := p.compile(config.TagSelection)
 		if err != nil {
 			return nil, err
 		}
+		selectedTagNodes := p.document.QueryAll(selected, expr)
 		p.Log.Debugf("Number of selected tag nodes: %d", len(selectedTagNodes))
 		if len(selectedTagNodes) > 0 && selectedTagNodes[0] != nil {
 			for _, selectedtag := range selectedTagNodes {
@@ -387,10 +474,11 @@ func (p *Parser) parseQuery(starttime time.Time, doc, selected dataNode, config
 		}
 
 		// Query all fields
-		selectedFieldNodes, err := p.document.QueryAll(selected, config.FieldSelection)
+		expr, err := p.compile(config.FieldSelection)
 		if err != nil {
 			return nil, err
 		}
+		selectedFieldNodes := p.document.QueryAll(selected, expr)
 		p.Log.Debugf("Number of selected field nodes: %d", len(selectedFieldNodes))
 		if len(selectedFieldNodes) > 0 && selectedFieldNodes[0] != nil {
 			for _, selectedfield := range selectedFieldNodes {
@@ -481,7 +569,6 @@ func (p *Parser) parseQuery(starttime time.Time, doc, selected dataNode, config
 
 		// Handle complex types which would be dropped otherwise for
 		// native type handling
-		fmt.Printf("explicit field %q: %v (%T)\n", name, v, v)
 		if v != nil {
 			switch reflect.TypeOf(v).Kind() {
 			case reflect.Array, reflect.Slice, reflect.Map:
@@ -511,10 +598,9 @@ func (p *Parser) executeQuery(doc, selected dataNode, query string) (r interface
 		root = doc
 	}
 
-	// Compile the query
-	expr, err := path.Compile(query)
+	expr, err := p.compile(query)
 	if err != nil {
-		return nil, fmt.Errorf("failed to compile query %q: %w", query, err)
+		return nil, err
 	}
 
 	// Evaluate the compiled expression and handle returned node-iterators
@@ -548,6 +634,38 @@ func (p *Parser) executeQuery(doc, selected dataNode, query string) (r interface
 	return nil, nil
 }
 
+// compileQueries compiles the given non-empty queries and stores the resulting
+// expressions for later use
+func (p *Parser) compileQueries(queries ...string) error {
+	for _, query := range queries {
+		if query == "" {
+			continue
+		}
+		if _, found := p.expressions[query]; found {
+			continue
+		}
+		expr, err := path.Compile(query)
+		if err != nil {
+			return fmt.Errorf("failed to compile query %q: %w", query, err)
+		}
+		p.expressions[query] = expr
+	}
+	return nil
+}
+
+// compile returns the expression compiled at initialization or compiles the
+// query if it is unknown, e.g. for the queries derived when debugging
+func (p *Parser) compile(query string) (*path.Expr, error) {
+	if expr, found := p.expressions[query]; found {
+		return expr, nil
+	}
+	expr, err := path.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile query %q: %w", query, err)
+	}
+	return expr, nil
+}
+
 func splitLastPathElement(query string) []string {
 	// This is a rudimentary xpath-parser that splits the path
 	// into the last path element and the remaining path-part.
@@ -639,11 +757,12 @@ func (p *Parser) debugEmptyQuery(operation string, root dataNode, initialquery s
 		}
 		for i := len(parts) - 1; i >= 0; i-- {
 			q := parts[i]
-			nodes, err := p.document.QueryAll(root, q)
+			expr, err := p.compile(q)
 			if err != nil {
 				p.Log.Debugf("executing query %q in %s failed: %v", q, operation, err)
 				return
 			}
+			nodes := p.document.QueryAll(root, expr)
 			p.Log.Debugf("got %d nodes for query %q in %s", len(nodes), q, operation)
 			if len(nodes) > 0 && nodes[0] != nil {
 				return
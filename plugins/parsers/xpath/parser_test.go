@@ -1477,6 +1477,139 @@ func TestMultipleConfigs(t *testing.T) {
 	}
 }
 
+func TestStreaming(t *testing.T) {
+	var tests = []struct {
+		name     string
+		input    string
+		configs  []Config
+		expected int
+	}{
+		{
+			name:  "all elements",
+			input: multipleNodesXML,
+			configs: []Config{
+				{
+					Selection: "/Device",
+					Tags:      map[string]string{"name": "substring-after(@name, ' ')"},
+					Fields:    map[string]string{"value": "number(Value)", "active": "Active = 1"},
+					FieldsInt: map[string]string{"mode": "Value/@mode"},
+				},
+			},
+			expected: 5,
+		},
+		{
+			name:  "attribute predicate",
+			input: multipleNodesXML,
+			configs: []Config{
+				{
+					Selection: "/Device[@name='Device 2']",
+					Fields:    map[string]string{"state": "State"},
+				},
+			},
+			expected: 1,
+		},
+		{
+			name: "ancestors",
+			input: `
+<?xml version="1.0"?>
+<Bus name="main">
+	<Sensor name="a"><Value>1</Value></Sensor>
+	<Sensor name="b"><Value>2</Value></Sensor>
+</Bus>`,
+			configs: []Config{
+				{
+					Selection:      "//Sensor",
+					Tags:           map[string]string{"bus": "string(/Bus/@name)", "sensor": "@name"},
+					FieldSelection: "*",
+				},
+			},
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &Parser{
+				DefaultMetricName: "test",
+				Configs:           tt.configs,
+				Log:               testutil.Logger{Name: "parsers.xml"},
+			}
+			require.NoError(t, parser.Init())
+			expected, err := parser.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			require.Len(t, expected, tt.expected)
+
+			streaming := &Parser{
+				DefaultMetricName: "test",
+				Configs:           tt.configs,
+				Streaming:         true,
+				Log:               testutil.Logger{Name: "parsers.xml"},
+			}
+			require.NoError(t, streaming.Init())
+			actual, err := streaming.Parse([]byte(tt.input))
+			require.NoError(t, err)
+			testutil.RequireMetricsEqual(t, expected, actual, testutil.IgnoreTime())
+		})
+	}
+}
+
+func TestStreamingEmptySelection(t *testing.T) {
+	parser := &Parser{
+		DefaultMetricName: "test",
+		Configs:           []Config{{Selection: "/Device/NonExisting"}},
+		Streaming:         true,
+		Log:               testutil.Logger{Name: "parsers.xml"},
+	}
+	require.NoError(t, parser.Init())
+
+	_, err := parser.Parse([]byte(multipleNodesXML))
+	require.EqualError(t, err, "cannot parse with empty selection node")
+
+	parser.AllowEmptySelection = true
+	metrics, err := parser.Parse([]byte(multipleNodesXML))
+	require.NoError(t, err)
+	require.Empty(t, metrics)
+}
+
+func TestStreamingUnsupportedFormat(t *testing.T) {
+	parser := &Parser{
+		Format:            "xpath_json",
+		DefaultMetricName: "test",
+		Streaming:         true,
+		Log:               testutil.Logger{Name: "parsers.xpath_json"},
+	}
+	require.EqualError(t, parser.Init(), `streaming is not supported for data-format "xpath_json"`)
+}
+
+func TestMaxDocumentSize(t *testing.T) {
+	parser := &Parser{
+		DefaultMetricName: "test",
+		Configs:           []Config{{Fields: map[string]string{"name": "/Device_1/Name"}}},
+		MaxDocumentSize:   config.Size(len(singleMetricValuesXML)),
+		Log:               testutil.Logger{Name: "parsers.xml"},
+	}
+	require.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(singleMetricValuesXML))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+
+	_, err = parser.Parse([]byte(singleMetricValuesXML + " "))
+	require.ErrorContains(t, err, "exceeds the maximum")
+}
+
+func TestInvalidQuery(t *testing.T) {
+	parser := &Parser{
+		DefaultMetricName: "test",
+		Configs: []Config{
+			{Fields: map[string]string{"value": "number(Value)"}},
+			{Fields: map[string]string{"value": "number(Value"}},
+		},
+		Log: testutil.Logger{Name: "parsers.xml"},
+	}
+	require.ErrorContains(t, parser.Init(), `invalid query in config 2: failed to compile query "number(Value"`)
+}
+
 func loadTestConfiguration(filename string) (*Config, []string, error) {
 	buf, err := os.ReadFile(filename)
 	if err != nil {
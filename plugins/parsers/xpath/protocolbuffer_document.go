@@ -105,18 +105,15 @@ func (d *protobufDocument) Parse(buf []byte) (dataNode, error) {
 	return protobufquery.Parse(msg)
 }
 
-func (d *protobufDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
+func (d *protobufDocument) QueryAll(node dataNode, expr *path.Expr) []dataNode {
 	// If this panics it's a programming error as we changed the document type while processing
-	native, err := protobufquery.QueryAll(node.(*protobufquery.Node), expr)
-	if err != nil {
-		return nil, err
-	}
+	native := protobufquery.QuerySelectorAll(node.(*protobufquery.Node), expr)
 
 	nodes := make([]dataNode, 0, len(native))
 	for _, n := range native {
 		nodes = append(nodes, n)
 	}
-	return nodes, nil
+	return nodes
 }
 
 func (d *protobufDocument) CreateXPathNavigator(node dataNode) path.NodeNavigator {
@@ -1,7 +1,9 @@
 package xpath
 
 import (
-	"strings"
+	"bytes"
+	"errors"
+	"io"
 
 	"github.com/antchfx/xmlquery"
 	path "github.com/antchfx/xpath"
@@ -10,21 +12,50 @@ import (
 type xmlDocument struct{}
 
 func (d *xmlDocument) Parse(buf []byte) (dataNode, error) {
-	return xmlquery.Parse(strings.NewReader(string(buf)))
+	return xmlquery.Parse(bytes.NewReader(buf))
 }
 
-func (d *xmlDocument) QueryAll(node dataNode, expr string) ([]dataNode, error) {
-	// If this panics it's a programming error as we changed the document type while processing
-	native, err := xmlquery.QueryAll(node.(*xmlquery.Node), expr)
+// Stream parses the document element-by-element and calls the given function
+// for each element matching the selection. The elements are removed from the
+// tree after processing, so only the matching element and its ancestors are
+// kept in memory.
+func (d *xmlDocument) Stream(buf []byte, selection string, fn func(doc, node dataNode) error) error {
+	// Use the selection as filter to evaluate predicates on the complete element
+	sp, err := xmlquery.CreateStreamParser(bytes.NewReader(buf), selection, selection)
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	for {
+		node, err := sp.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// The element is still attached to its ancestors so use the top-most
+		// node as the document for absolute queries
+		doc := node
+		for doc.Parent != nil {
+			doc = doc.Parent
+		}
+		if err := fn(doc, node); err != nil {
+			return err
+		}
 	}
+}
+
+func (d *xmlDocument) QueryAll(node dataNode, expr *path.Expr) []dataNode {
+	// If this panics it's a programming error as we changed the document type while processing
+	native := xmlquery.QuerySelectorAll(node.(*xmlquery.Node), expr)
 
 	nodes := make([]dataNode, 0, len(native))
 	for _, n := range native {
 		nodes = append(nodes, n)
 	}
-	return nodes, nil
+	return nodes
 }
 
 func (d *xmlDocument) CreateXPathNavigator(node dataNode) path.NodeNavigator {
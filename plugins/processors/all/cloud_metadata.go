@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.cloud_metadata
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/cloud_metadata" // register plugin
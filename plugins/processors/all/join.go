@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.join
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/join" // register plugin
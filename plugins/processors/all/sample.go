@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.sample
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/sample" // register plugin
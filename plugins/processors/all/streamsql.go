@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.streamsql
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/streamsql" // register plugin
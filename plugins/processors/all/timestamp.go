@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.timestamp
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/timestamp" // register plugin
@@ -0,0 +1,5 @@
+//go:build !custom || processors || processors.wasm
+
+package all
+
+import _ "github.com/influxdata/telegraf/plugins/processors/wasm" // register plugin
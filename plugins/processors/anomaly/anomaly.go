@@ -0,0 +1,194 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package anomaly
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Anomaly struct {
+	Fields        []string        `toml:"fields"`
+	Method        string          `toml:"method"`
+	WindowSize    int             `toml:"window_size"`
+	Alpha         float64         `toml:"alpha"`
+	MinSamples    int             `toml:"min_samples"`
+	Threshold     float64         `toml:"threshold"`
+	AnomalyField  string          `toml:"anomaly_field"`
+	ZScoreSuffix  string          `toml:"zscore_suffix"`
+	SeriesTimeout config.Duration `toml:"series_timeout"`
+	Log           telegraf.Logger `toml:"-"`
+
+	fieldFilter filter.Filter
+	stats       map[uint64]map[string]statistics
+	seen        map[uint64]time.Time
+	lastCleanup time.Time
+}
+
+// statistics tracks the mean and standard deviation of a field of a series
+type statistics interface {
+	// mean and stddev return the statistics of the previous values
+	mean() float64
+	stddev() float64
+	// count returns the number of values added
+	count() int
+	add(v float64)
+}
+
+func (*Anomaly) SampleConfig() string {
+	return sampleConfig
+}
+
+func (a *Anomaly) Init() error {
+	if err := choice.Check(a.Method, []string{"rolling", "ewma"}); err != nil {
+		return fmt.Errorf("invalid 'method': %w", err)
+	}
+	switch a.Method {
+	case "rolling":
+		if a.WindowSize < 2 {
+			return errors.New("'window_size' must be at least two")
+		}
+	case "ewma":
+		if a.Alpha <= 0 || a.Alpha > 1 {
+			return errors.New("'alpha' must be greater than zero and at most one")
+		}
+	}
+	if a.Threshold <= 0 {
+		return errors.New("'threshold' must be greater than zero")
+	}
+	if a.MinSamples < 2 {
+		a.MinSamples = 2
+	}
+	if a.AnomalyField == "" && a.ZScoreSuffix == "" {
+		return errors.New("at least one of 'anomaly_field' or 'zscore_suffix' must be set")
+	}
+
+	var err error
+	a.fieldFilter, err = filter.Compile(a.Fields)
+	if err != nil {
+		return fmt.Errorf("creating field filter failed: %w", err)
+	}
+
+	a.stats = make(map[uint64]map[string]statistics)
+	a.seen = make(map[uint64]time.Time)
+	a.lastCleanup = time.Now()
+
+	return nil
+}
+
+func (a *Anomaly) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+	for _, m := range in {
+		id := m.HashID()
+		series, found := a.stats[id]
+		if !found {
+			series = make(map[string]statistics)
+			a.stats[id] = series
+		}
+		a.seen[id] = now
+
+		var checked, anomalous bool
+		scores := make(map[string]float64)
+		for _, field := range m.FieldList() {
+			if a.fieldFilter != nil && !a.fieldFilter.Match(field.Key) {
+				continue
+			}
+			if _, isString := field.Value.(string); isString {
+				continue
+			}
+			if _, isBool := field.Value.(bool); isBool {
+				continue
+			}
+			v, err := internal.ToFloat64(field.Value)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+
+			s, found := series[field.Key]
+			if !found {
+				s = a.newStatistics()
+				series[field.Key] = s
+			}
+
+			// Compare against the statistics of the previous values so an
+			// outlier does not mask itself
+			if s.count() >= a.MinSamples {
+				checked = true
+				mean, stddev := s.mean(), s.stddev()
+				if stddev > 0 {
+					z := (v - mean) / stddev
+					scores[field.Key] = z
+					anomalous = anomalous || math.Abs(z) > a.Threshold
+				} else if math.Abs(v-mean) > 1e-9*math.Max(1, math.Abs(mean)) {
+					// Any change of a so far constant value is anomalous,
+					// but there is no finite z-score
+					anomalous = true
+				}
+			}
+			s.add(v)
+		}
+
+		if a.ZScoreSuffix != "" {
+			for k, z := range scores {
+				m.AddField(k+a.ZScoreSuffix, z)
+			}
+		}
+		if checked && a.AnomalyField != "" {
+			m.AddField(a.AnomalyField, anomalous)
+		}
+	}
+
+	a.cleanup(now)
+
+	return in
+}
+
+func (a *Anomaly) newStatistics() statistics {
+	if a.Method == "ewma" {
+		return &ewma{alpha: a.Alpha}
+	}
+	return &window{values: make([]float64, 0, a.WindowSize), size: a.WindowSize}
+}
+
+// cleanup forgets about series not seen for a while
+func (a *Anomaly) cleanup(now time.Time) {
+	timeout := time.Duration(a.SeriesTimeout)
+	if timeout <= 0 || now.Sub(a.lastCleanup) < timeout {
+		return
+	}
+	a.lastCleanup = now
+
+	for id, seen := range a.seen {
+		if now.Sub(seen) >= timeout {
+			delete(a.seen, id)
+			delete(a.stats, id)
+		}
+	}
+}
+
+func init() {
+	processors.Add("anomaly", func() telegraf.Processor {
+		return &Anomaly{
+			Method:        "rolling",
+			WindowSize:    60,
+			Alpha:         0.1,
+			MinSamples:    10,
+			Threshold:     3,
+			AnomalyField:  "anomaly",
+			ZScoreSuffix:  "_zscore",
+			SeriesTimeout: config.Duration(time.Hour),
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package anomaly
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func newPlugin() *Anomaly {
+	return &Anomaly{
+		Method:        "rolling",
+		WindowSize:    5,
+		Alpha:         0.1,
+		MinSamples:    3,
+		Threshold:     3,
+		AnomalyField:  "anomaly",
+		ZScoreSuffix:  "_zscore",
+		SeriesTimeout: config.Duration(time.Hour),
+		Log:           testutil.Logger{},
+	}
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Anomaly)
+		expected string
+	}{
+		{
+			name:     "invalid method",
+			modify:   func(a *Anomaly) { a.Method = "magic" },
+			expected: "invalid 'method'",
+		},
+		{
+			name:     "window too small",
+			modify:   func(a *Anomaly) { a.WindowSize = 1 },
+			expected: "'window_size' must be at least two",
+		},
+		{
+			name:     "invalid alpha",
+			modify:   func(a *Anomaly) { a.Method, a.Alpha = "ewma", 1.5 },
+			expected: "'alpha' must be greater than zero",
+		},
+		{
+			name:     "invalid threshold",
+			modify:   func(a *Anomaly) { a.Threshold = 0 },
+			expected: "'threshold' must be greater than zero",
+		},
+		{
+			name:     "no output",
+			modify:   func(a *Anomaly) { a.AnomalyField, a.ZScoreSuffix = "", "" },
+			expected: "at least one of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := newPlugin()
+			tt.modify(plugin)
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestRolling(t *testing.T) {
+	plugin := newPlugin()
+	plugin.Fields = []string{"value"}
+	require.NoError(t, plugin.Init())
+
+	values := []float64{10, 12, 11, 10, 12, 50, 11}
+	input := make([]telegraf.Metric, 0, len(values))
+	for i, v := range values {
+		input = append(input, metric.New(
+			"test",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"value": v, "other": 1000.0 * float64(i)},
+			time.Unix(int64(i), 0),
+		))
+	}
+	actual := plugin.Apply(input...)
+
+	// The first values are used for warming up
+	for _, m := range actual[:3] {
+		require.False(t, m.HasField("anomaly"))
+		require.False(t, m.HasField("value_zscore"))
+	}
+	for _, m := range actual {
+		require.False(t, m.HasField("other_zscore"))
+	}
+
+	// Mean 11, stddev 1 of the window [10 12 11]
+	z, found := actual[3].GetField("value_zscore")
+	require.True(t, found)
+	require.InDelta(t, -1.0, z, 1e-9)
+	flag, found := actual[3].GetField("anomaly")
+	require.True(t, found)
+	require.Equal(t, false, flag)
+
+	flag, _ = actual[5].GetField("anomaly")
+	require.Equal(t, true, flag)
+	z, _ = actual[5].GetField("value_zscore")
+	require.Greater(t, z, 3.0)
+
+	// The outlier is part of the window, so the next value is not anomalous
+	flag, _ = actual[6].GetField("anomaly")
+	require.Equal(t, false, flag)
+}
+
+func TestEWMA(t *testing.T) {
+	plugin := newPlugin()
+	plugin.Method = "ewma"
+	plugin.Alpha = 0.5
+	plugin.ZScoreSuffix = ""
+	require.NoError(t, plugin.Init())
+
+	var flags []interface{}
+	for i, v := range []float64{10, 11, 10, 11, 10, 11, 30} {
+		m := metric.New("test", map[string]string{}, map[string]interface{}{"value": v}, time.Unix(int64(i), 0))
+		plugin.Apply(m)
+		require.False(t, m.HasField("value_zscore"))
+		if flag, found := m.GetField("anomaly"); found {
+			flags = append(flags, flag)
+		}
+	}
+	require.Equal(t, []interface{}{false, false, false, true}, flags)
+}
+
+func TestConstantSeries(t *testing.T) {
+	plugin := newPlugin()
+	require.NoError(t, plugin.Init())
+
+	var actual []telegraf.Metric
+	for i, v := range []float64{0.1, 0.1, 0.1, 0.1, 0.2} {
+		actual = append(actual, plugin.Apply(metric.New("test", map[string]string{}, map[string]interface{}{"value": v}, time.Unix(int64(i), 0)))...)
+	}
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 0.1}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 0.1}, time.Unix(1, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 0.1}, time.Unix(2, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 0.1, "anomaly": false}, time.Unix(3, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 0.2, "anomaly": true}, time.Unix(4, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestSeriesSeparation(t *testing.T) {
+	plugin := newPlugin()
+	plugin.MinSamples = 2
+	require.NoError(t, plugin.Init())
+
+	for i := 0; i < 5; i++ {
+		plugin.Apply(
+			metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": float64(i % 2)}, time.Unix(int64(i), 0)),
+			metric.New("test", map[string]string{"host": "b"}, map[string]interface{}{"value": 1000 + float64(i%2)}, time.Unix(int64(i), 0)),
+		)
+	}
+	require.Len(t, plugin.stats, 2)
+
+	// A value normal for one series is anomalous for the other one
+	a := metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 1000.0}, time.Unix(5, 0))
+	b := metric.New("test", map[string]string{"host": "b"}, map[string]interface{}{"value": 1000.0}, time.Unix(5, 0))
+	plugin.Apply(a, b)
+
+	flag, _ := a.GetField("anomaly")
+	require.Equal(t, true, flag)
+	flag, _ = b.GetField("anomaly")
+	require.Equal(t, false, flag)
+}
+
+func TestWindowStatistics(t *testing.T) {
+	w := &window{values: make([]float64, 0, 3), size: 3}
+	for _, v := range []float64{100, 1, 2, 3} {
+		w.add(v)
+	}
+	require.Equal(t, 3, w.count())
+	require.InDelta(t, 2.0, w.mean(), 1e-9)
+	require.InDelta(t, 1.0, w.stddev(), 1e-9)
+
+	e := &ewma{alpha: 0.5}
+	e.add(1)
+	e.add(3)
+	require.InDelta(t, 2.0, e.mean(), 1e-9)
+	require.InDelta(t, math.Sqrt(1), e.stddev(), 1e-9)
+}
@@ -0,0 +1,84 @@
+package anomaly
+
+import "math"
+
+// window computes the statistics over the last values of a fixed-size window
+type window struct {
+	values []float64
+	size   int
+	next   int
+	sum    float64
+	sumSq  float64
+}
+
+func (w *window) add(v float64) {
+	if len(w.values) < w.size {
+		w.values = append(w.values, v)
+	} else {
+		old := w.values[w.next]
+		w.sum -= old
+		w.sumSq -= old * old
+		w.values[w.next] = v
+		w.next = (w.next + 1) % w.size
+	}
+	w.sum += v
+	w.sumSq += v * v
+}
+
+func (w *window) count() int {
+	return len(w.values)
+}
+
+func (w *window) mean() float64 {
+	if len(w.values) == 0 {
+		return 0
+	}
+	return w.sum / float64(len(w.values))
+}
+
+func (w *window) stddev() float64 {
+	n := float64(len(w.values))
+	if n < 2 {
+		return 0
+	}
+	// Use the sample variance and treat tiny values as zero as those are
+	// caused by rounding errors of the running sums for constant values
+	mean := w.sum / n
+	variance := (w.sumSq - w.sum*mean) / (n - 1)
+	if variance <= 1e-12*math.Max(1, mean*mean) {
+		return 0
+	}
+	return math.Sqrt(variance)
+}
+
+// ewma computes exponentially weighted moving statistics giving recent
+// values a higher weight
+type ewma struct {
+	alpha    float64
+	n        int
+	average  float64
+	variance float64
+}
+
+func (e *ewma) add(v float64) {
+	e.n++
+	if e.n == 1 {
+		e.average = v
+		return
+	}
+	diff := v - e.average
+	e.average += e.alpha * diff
+	e.variance = (1 - e.alpha) * (e.variance + e.alpha*diff*diff)
+}
+
+func (e *ewma) count() int {
+	return e.n
+}
+
+func (e *ewma) mean() float64 {
+	return e.average
+}
+
+func (e *ewma) stddev() float64 {
+	return math.Sqrt(e.variance)
+}
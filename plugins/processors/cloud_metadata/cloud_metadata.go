@@ -0,0 +1,234 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package cloud_metadata
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+var items = []string{
+	"account_id",
+	"hostname",
+	"image_id",
+	"instance_id",
+	"instance_name",
+	"instance_type",
+	"private_ip",
+	"region",
+	"zone",
+}
+
+type CloudMetadata struct {
+	Provider        string          `toml:"provider"`
+	Tags            []string        `toml:"tags"`
+	TagPrefix       string          `toml:"tag_prefix"`
+	Overwrite       bool            `toml:"overwrite"`
+	RefreshInterval config.Duration `toml:"refresh_interval"`
+	Timeout         config.Duration `toml:"timeout"`
+	Log             telegraf.Logger `toml:"-"`
+
+	// Base URLs of the metadata services, overridden in tests
+	endpoints map[string]string
+
+	client   *http.Client
+	instance map[string]string
+	provider string
+	tags     map[string]string
+	sync.RWMutex
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (*CloudMetadata) SampleConfig() string {
+	return sampleConfig
+}
+
+func (c *CloudMetadata) Init() error {
+	if c.Provider == "" {
+		c.Provider = "auto"
+	}
+	if err := choice.Check(c.Provider, []string{"auto", "aws", "azure", "gcp"}); err != nil {
+		return fmt.Errorf("invalid 'provider': %w", err)
+	}
+
+	if len(c.Tags) == 0 {
+		return errors.New("no tags specified")
+	}
+	for _, tag := range c.Tags {
+		if name, found := strings.CutPrefix(tag, "tag:"); found {
+			if name == "" {
+				return fmt.Errorf("empty instance tag name in %q", tag)
+			}
+			continue
+		}
+		if err := choice.Check(tag, items); err != nil {
+			return fmt.Errorf("invalid tag %q: %w", tag, err)
+		}
+	}
+
+	if c.endpoints == nil {
+		c.endpoints = map[string]string{
+			"aws":   "http://169.254.169.254",
+			"azure": "http://169.254.169.254",
+			"gcp":   "http://metadata.google.internal",
+		}
+	}
+	c.client = &http.Client{Timeout: time.Duration(c.Timeout)}
+
+	return nil
+}
+
+func (c *CloudMetadata) Start(_ telegraf.Accumulator) error {
+	if err := c.refresh(context.Background()); err != nil {
+		return err
+	}
+	c.Log.Debugf("Using metadata of %s instance %q", c.provider, c.instance["instance_id"])
+
+	if c.RefreshInterval <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(c.RefreshInterval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// Keep the previous metadata on errors as the metadata
+				// service might be temporarily unavailable
+				if err := c.refresh(ctx); err != nil && ctx.Err() == nil {
+					c.Log.Errorf("Refreshing metadata failed: %v", err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (c *CloudMetadata) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	c.RLock()
+	for k, v := range c.tags {
+		if c.Overwrite || !m.HasTag(k) {
+			m.AddTag(k, v)
+		}
+	}
+	c.RUnlock()
+
+	acc.AddMetric(m)
+	return nil
+}
+
+func (c *CloudMetadata) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+}
+
+// refresh queries the metadata service and updates the tags to add
+func (c *CloudMetadata) refresh(ctx context.Context) error {
+	var instanceTags bool
+	for _, tag := range c.Tags {
+		if strings.HasPrefix(tag, "tag:") {
+			instanceTags = true
+			break
+		}
+	}
+
+	provider, metadata, err := c.query(ctx, instanceTags)
+	if err != nil {
+		return err
+	}
+
+	tags := make(map[string]string, len(c.Tags))
+	for _, item := range c.Tags {
+		v, found := metadata[item]
+		if !found || v == "" {
+			c.Log.Debugf("No value for %q in metadata", item)
+			continue
+		}
+		tags[c.TagPrefix+strings.TrimPrefix(item, "tag:")] = v
+	}
+
+	c.Lock()
+	c.provider = provider
+	c.instance = metadata
+	c.tags = tags
+	c.Unlock()
+
+	return nil
+}
+
+// query fetches the metadata from the configured provider or, in auto mode,
+// from the first provider answering
+func (c *CloudMetadata) query(ctx context.Context, instanceTags bool) (string, map[string]string, error) {
+	fetchers := map[string]func(context.Context, string, bool) (map[string]string, error){
+		"aws":   c.queryAWS,
+		"azure": c.queryAzure,
+		"gcp":   c.queryGCP,
+	}
+
+	if c.Provider != "auto" {
+		metadata, err := fetchers[c.Provider](ctx, c.endpoints[c.Provider], instanceTags)
+		if err != nil {
+			return "", nil, fmt.Errorf("querying %s metadata failed: %w", c.Provider, err)
+		}
+		return c.Provider, metadata, nil
+	}
+
+	// Remember the detected provider so refreshing does not need to probe
+	// all providers again
+	c.RLock()
+	detected := c.provider
+	c.RUnlock()
+	if detected != "" {
+		metadata, err := fetchers[detected](ctx, c.endpoints[detected], instanceTags)
+		if err != nil {
+			return "", nil, fmt.Errorf("querying %s metadata failed: %w", detected, err)
+		}
+		return detected, metadata, nil
+	}
+
+	var errs []error
+	for _, provider := range []string{"aws", "azure", "gcp"} {
+		metadata, err := fetchers[provider](ctx, c.endpoints[provider], instanceTags)
+		if err == nil {
+			return provider, metadata, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider, err))
+	}
+	return "", nil, fmt.Errorf("detecting cloud provider failed: %w", errors.Join(errs...))
+}
+
+func init() {
+	processors.AddStreaming("cloud_metadata", func() telegraf.StreamingProcessor {
+		return &CloudMetadata{
+			Provider:        "auto",
+			RefreshInterval: config.Duration(time.Hour),
+			Timeout:         config.Duration(5 * time.Second),
+		}
+	})
+}
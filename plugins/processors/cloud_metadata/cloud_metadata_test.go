@@ -0,0 +1,298 @@
+package cloud_metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+const awsDocument = `{
+  "accountId": "123456789012",
+  "availabilityZone": "eu-central-1a",
+  "imageId": "ami-0123456789abcdef0",
+  "instanceId": "i-0123456789abcdef0",
+  "instanceType": "m5.large",
+  "privateIp": "10.0.0.10",
+  "region": "eu-central-1"
+}`
+
+const azureDocument = `{
+  "compute": {
+    "location": "westeurope",
+    "name": "myvm",
+    "osProfile": {"computerName": "myvm-host"},
+    "subscriptionId": "xxxx-yyyy",
+    "tagsList": [{"name": "team", "value": "platform"}],
+    "vmId": "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+    "vmSize": "Standard_D2s_v3",
+    "zone": "1"
+  },
+  "network": {
+    "interface": [{"ipv4": {"ipAddress": [{"privateIpAddress": "10.1.0.4"}]}}]
+  }
+}`
+
+const gcpDocument = `{
+  "instance": {
+    "attributes": {"team": "platform"},
+    "hostname": "myvm.c.myproject.internal",
+    "id": 4520031799277581759,
+    "machineType": "projects/123/machineTypes/n1-standard-1",
+    "name": "myvm",
+    "networkInterfaces": [{"ip": "10.2.0.2"}],
+    "zone": "projects/123/zones/us-central1-a"
+  },
+  "project": {"projectId": "myproject"}
+}`
+
+func awsServer(tags map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut && r.URL.Path == "/latest/api/token" {
+			_, _ = w.Write([]byte("token"))
+			return
+		}
+		if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/latest/dynamic/instance-identity/document":
+			_, _ = w.Write([]byte(awsDocument))
+		case "/latest/meta-data/local-hostname":
+			_, _ = w.Write([]byte("ip-10-0-0-10.eu-central-1.compute.internal"))
+		case "/latest/meta-data/tags/instance":
+			for k := range tags {
+				_, _ = w.Write([]byte(k + "\n"))
+			}
+		default:
+			key := r.URL.Path[len("/latest/meta-data/tags/instance/"):]
+			if v, found := tags[key]; found {
+				_, _ = w.Write([]byte(v))
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func documentServer(path, header, value, doc string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path || r.Header.Get(header) != value {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(doc))
+	}))
+}
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *CloudMetadata
+		expected string
+	}{
+		{
+			name:     "no tags",
+			plugin:   &CloudMetadata{},
+			expected: "no tags specified",
+		},
+		{
+			name:     "invalid provider",
+			plugin:   &CloudMetadata{Provider: "foo", Tags: []string{"zone"}},
+			expected: "invalid 'provider'",
+		},
+		{
+			name:     "invalid item",
+			plugin:   &CloudMetadata{Tags: []string{"flavor"}},
+			expected: `invalid tag "flavor"`,
+		},
+		{
+			name:     "empty instance tag",
+			plugin:   &CloudMetadata{Tags: []string{"tag:"}},
+			expected: "empty instance tag name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestProviders(t *testing.T) {
+	awsTags := map[string]string{"Name": "web-1", "team": "platform"}
+
+	tests := []struct {
+		name     string
+		provider string
+		server   *httptest.Server
+		expected map[string]string
+	}{
+		{
+			name:     "aws",
+			provider: "aws",
+			server:   awsServer(awsTags),
+			expected: map[string]string{
+				"account_id":    "123456789012",
+				"hostname":      "ip-10-0-0-10.eu-central-1.compute.internal",
+				"image_id":      "ami-0123456789abcdef0",
+				"instance_id":   "i-0123456789abcdef0",
+				"instance_name": "web-1",
+				"instance_type": "m5.large",
+				"private_ip":    "10.0.0.10",
+				"region":        "eu-central-1",
+				"zone":          "eu-central-1a",
+				"team":          "platform",
+			},
+		},
+		{
+			name:     "azure",
+			provider: "azure",
+			server:   documentServer("/metadata/instance", "Metadata", "true", azureDocument),
+			expected: map[string]string{
+				"account_id":    "xxxx-yyyy",
+				"hostname":      "myvm-host",
+				"instance_id":   "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+				"instance_name": "myvm",
+				"instance_type": "Standard_D2s_v3",
+				"private_ip":    "10.1.0.4",
+				"region":        "westeurope",
+				"zone":          "1",
+				"team":          "platform",
+			},
+		},
+		{
+			name:     "gcp",
+			provider: "gcp",
+			server:   documentServer("/computeMetadata/v1/", "Metadata-Flavor", "Google", gcpDocument),
+			expected: map[string]string{
+				"account_id":    "myproject",
+				"hostname":      "myvm.c.myproject.internal",
+				"instance_id":   "4520031799277581759",
+				"instance_name": "myvm",
+				"instance_type": "n1-standard-1",
+				"private_ip":    "10.2.0.2",
+				"region":        "us-central1",
+				"zone":          "us-central1-a",
+				"team":          "platform",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer tt.server.Close()
+
+			plugin := &CloudMetadata{
+				Provider: tt.provider,
+				Tags: []string{
+					"account_id", "hostname", "image_id", "instance_id", "instance_name",
+					"instance_type", "private_ip", "region", "zone", "tag:team", "tag:missing",
+				},
+				Log:       testutil.Logger{},
+				endpoints: map[string]string{tt.provider: tt.server.URL},
+			}
+			require.NoError(t, plugin.Init())
+
+			var acc testutil.Accumulator
+			require.NoError(t, plugin.Start(&acc))
+			defer plugin.Stop()
+
+			require.NoError(t, plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, time.Unix(0, 0)), &acc))
+
+			expected := []telegraf.Metric{
+				metric.New("test", tt.expected, map[string]interface{}{"value": 42}, time.Unix(0, 0)),
+			}
+			testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+		})
+	}
+}
+
+func TestAutoDetect(t *testing.T) {
+	unavailable := httptest.NewServer(http.NotFoundHandler())
+	defer unavailable.Close()
+	gcp := documentServer("/computeMetadata/v1/", "Metadata-Flavor", "Google", gcpDocument)
+	defer gcp.Close()
+
+	plugin := &CloudMetadata{
+		Provider:  "auto",
+		Tags:      []string{"zone"},
+		TagPrefix: "cloud_",
+		Log:       testutil.Logger{},
+		endpoints: map[string]string{
+			"aws":   unavailable.URL,
+			"azure": unavailable.URL,
+			"gcp":   gcp.URL,
+		},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.Equal(t, "gcp", plugin.provider)
+
+	// Existing tags are not overwritten by default
+	require.NoError(t, plugin.Add(metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)), &acc))
+	require.NoError(t, plugin.Add(metric.New("test", map[string]string{"cloud_zone": "local"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{"cloud_zone": "us-central1-a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{"cloud_zone": "local"}, map[string]interface{}{"value": 2}, time.Unix(0, 0)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestStartFail(t *testing.T) {
+	unavailable := httptest.NewServer(http.NotFoundHandler())
+	defer unavailable.Close()
+
+	plugin := &CloudMetadata{
+		Tags:      []string{"zone"},
+		Log:       testutil.Logger{},
+		endpoints: map[string]string{"aws": unavailable.URL, "azure": unavailable.URL, "gcp": unavailable.URL},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.ErrorContains(t, plugin.Start(&acc), "detecting cloud provider failed")
+}
+
+func TestRefresh(t *testing.T) {
+	tags := map[string]string{"team": "platform"}
+	server := awsServer(tags)
+	defer server.Close()
+
+	plugin := &CloudMetadata{
+		Provider:  "aws",
+		Tags:      []string{"tag:team"},
+		Log:       testutil.Logger{},
+		endpoints: map[string]string{"aws": server.URL},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+	require.Equal(t, map[string]string{"team": "platform"}, plugin.tags)
+
+	// Changed metadata is picked up
+	tags["team"] = "database"
+	require.NoError(t, plugin.refresh(context.Background()))
+	require.Equal(t, map[string]string{"team": "database"}, plugin.tags)
+
+	// The previous metadata is kept on errors
+	server.Close()
+	require.Error(t, plugin.refresh(context.Background()))
+	require.Equal(t, map[string]string{"team": "database"}, plugin.tags)
+}
@@ -0,0 +1,228 @@
+package cloud_metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// request performs a request against the metadata service and returns the
+// body of a successful response
+func (c *CloudMetadata) request(ctx context.Context, method, url string, header map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s %s returned status %q", method, url, resp.Status)
+	}
+	return body, nil
+}
+
+// queryAWS uses the EC2 instance metadata service. A session token is used
+// if available (IMDSv2) with a fallback to IMDSv1 otherwise.
+func (c *CloudMetadata) queryAWS(ctx context.Context, endpoint string, instanceTags bool) (map[string]string, error) {
+	header := make(map[string]string)
+	token, err := c.request(ctx, http.MethodPut, endpoint+"/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "300",
+	})
+	if err == nil {
+		header["X-aws-ec2-metadata-token"] = string(token)
+	}
+
+	buf, err := c.request(ctx, http.MethodGet, endpoint+"/latest/dynamic/instance-identity/document", header)
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		AccountID        string `json:"accountId"`
+		AvailabilityZone string `json:"availabilityZone"`
+		ImageID          string `json:"imageId"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		PrivateIP        string `json:"privateIp"`
+		Region           string `json:"region"`
+	}
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return nil, fmt.Errorf("parsing instance identity document failed: %w", err)
+	}
+
+	metadata := map[string]string{
+		"account_id":    doc.AccountID,
+		"image_id":      doc.ImageID,
+		"instance_id":   doc.InstanceID,
+		"instance_type": doc.InstanceType,
+		"private_ip":    doc.PrivateIP,
+		"region":        doc.Region,
+		"zone":          doc.AvailabilityZone,
+	}
+	if hostname, err := c.request(ctx, http.MethodGet, endpoint+"/latest/meta-data/local-hostname", header); err == nil {
+		metadata["hostname"] = string(hostname)
+	}
+
+	if !instanceTags {
+		return metadata, nil
+	}
+
+	// Instance tags are only available if access to tags in the metadata
+	// is enabled for the instance
+	buf, err = c.request(ctx, http.MethodGet, endpoint+"/latest/meta-data/tags/instance", header)
+	if err != nil {
+		return nil, fmt.Errorf("listing instance tags failed: %w", err)
+	}
+	for _, key := range strings.Fields(string(buf)) {
+		value, err := c.request(ctx, http.MethodGet, endpoint+"/latest/meta-data/tags/instance/"+key, header)
+		if err != nil {
+			return nil, fmt.Errorf("getting instance tag %q failed: %w", key, err)
+		}
+		metadata["tag:"+key] = string(value)
+	}
+	if name, found := metadata["tag:Name"]; found {
+		metadata["instance_name"] = name
+	}
+
+	return metadata, nil
+}
+
+// queryAzure uses the Azure instance metadata service
+func (c *CloudMetadata) queryAzure(ctx context.Context, endpoint string, _ bool) (map[string]string, error) {
+	buf, err := c.request(ctx, http.MethodGet, endpoint+"/metadata/instance?api-version=2021-02-01", map[string]string{
+		"Metadata": "true",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Compute struct {
+			Location  string `json:"location"`
+			Name      string `json:"name"`
+			OsProfile struct {
+				ComputerName string `json:"computerName"`
+			} `json:"osProfile"`
+			StorageProfile struct {
+				ImageReference struct {
+					ID string `json:"id"`
+				} `json:"imageReference"`
+			} `json:"storageProfile"`
+			SubscriptionID string `json:"subscriptionId"`
+			TagsList       []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"tagsList"`
+			VMID   string `json:"vmId"`
+			VMSize string `json:"vmSize"`
+			Zone   string `json:"zone"`
+		} `json:"compute"`
+		Network struct {
+			Interface []struct {
+				IPv4 struct {
+					IPAddress []struct {
+						PrivateIPAddress string `json:"privateIpAddress"`
+					} `json:"ipAddress"`
+				} `json:"ipv4"`
+			} `json:"interface"`
+		} `json:"network"`
+	}
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return nil, fmt.Errorf("parsing instance metadata failed: %w", err)
+	}
+
+	metadata := map[string]string{
+		"account_id":    doc.Compute.SubscriptionID,
+		"hostname":      doc.Compute.OsProfile.ComputerName,
+		"image_id":      doc.Compute.StorageProfile.ImageReference.ID,
+		"instance_id":   doc.Compute.VMID,
+		"instance_name": doc.Compute.Name,
+		"instance_type": doc.Compute.VMSize,
+		"region":        doc.Compute.Location,
+		"zone":          doc.Compute.Zone,
+	}
+	if len(doc.Network.Interface) > 0 && len(doc.Network.Interface[0].IPv4.IPAddress) > 0 {
+		metadata["private_ip"] = doc.Network.Interface[0].IPv4.IPAddress[0].PrivateIPAddress
+	}
+	for _, tag := range doc.Compute.TagsList {
+		metadata["tag:"+tag.Name] = tag.Value
+	}
+
+	return metadata, nil
+}
+
+// queryGCP uses the Google Compute Engine metadata server. Labels are not
+// exposed by the metadata server so the custom metadata attributes of the
+// instance are used as instance tags.
+func (c *CloudMetadata) queryGCP(ctx context.Context, endpoint string, _ bool) (map[string]string, error) {
+	buf, err := c.request(ctx, http.MethodGet, endpoint+"/computeMetadata/v1/?recursive=true", map[string]string{
+		"Metadata-Flavor": "Google",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Instance struct {
+			Attributes        map[string]string `json:"attributes"`
+			Hostname          string            `json:"hostname"`
+			ID                json.Number       `json:"id"`
+			Image             string            `json:"image"`
+			MachineType       string            `json:"machineType"`
+			Name              string            `json:"name"`
+			NetworkInterfaces []struct {
+				IP string `json:"ip"`
+			} `json:"networkInterfaces"`
+			Zone string `json:"zone"`
+		} `json:"instance"`
+		Project struct {
+			ProjectID string `json:"projectId"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(buf, &doc); err != nil {
+		return nil, fmt.Errorf("parsing instance metadata failed: %w", err)
+	}
+
+	// The zone and machine type are given as resource paths like
+	// "projects/123/zones/us-central1-a"
+	zone := lastSegment(doc.Instance.Zone)
+	var region string
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		region = zone[:i]
+	}
+
+	metadata := map[string]string{
+		"account_id":    doc.Project.ProjectID,
+		"hostname":      doc.Instance.Hostname,
+		"image_id":      doc.Instance.Image,
+		"instance_id":   doc.Instance.ID.String(),
+		"instance_name": doc.Instance.Name,
+		"instance_type": lastSegment(doc.Instance.MachineType),
+		"region":        region,
+		"zone":          zone,
+	}
+	if len(doc.Instance.NetworkInterfaces) > 0 {
+		metadata["private_ip"] = doc.Instance.NetworkInterfaces[0].IP
+	}
+	for k, v := range doc.Instance.Attributes {
+		metadata["tag:"+k] = v
+	}
+
+	return metadata, nil
+}
+
+func lastSegment(resource string) string {
+	return resource[strings.LastIndex(resource, "/")+1:]
+}
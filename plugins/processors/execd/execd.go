@@ -13,6 +13,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/internal/process"
+	"github.com/influxdata/telegraf/plugins/common/framing"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -27,15 +28,21 @@ type Execd struct {
 	RestartDelay config.Duration `toml:"restart_delay"`
 	Log          telegraf.Logger
 
+	Protocol          string          `toml:"protocol"`
+	HeartbeatInterval config.Duration `toml:"heartbeat_interval"`
+	PluginConfig      string          `toml:"plugin_config"`
+
 	parser     telegraf.Parser
 	serializer serializers.Serializer
 	acc        telegraf.Accumulator
 	process    *process.Process
+	conn       *process.FrameConn
 }
 
 func New() *Execd {
 	return &Execd{
 		RestartDelay: config.Duration(10 * time.Second),
+		Protocol:     process.ProtocolV1,
 	}
 }
 
@@ -63,6 +70,12 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 	e.process.RestartDelay = time.Duration(e.RestartDelay)
 	e.process.ReadStdoutFn = e.cmdReadOut
 	e.process.ReadStderrFn = e.cmdReadErr
+	if e.Protocol == process.ProtocolV2 {
+		e.conn = process.NewFrameConn(e.process)
+		e.conn.HeartbeatInterval = time.Duration(e.HeartbeatInterval)
+		e.conn.Config = []byte(e.PluginConfig)
+		e.process.ReadStdoutFn = e.conn.Reader(e.handleFrame)
+	}
 
 	if err = e.process.Start(); err != nil {
 		// if there was only one argument, and it contained spaces, warn the user
@@ -74,6 +87,9 @@ func (e *Execd) Start(acc telegraf.Accumulator) error {
 		}
 		return fmt.Errorf("failed to start process %s: %w", e.Command, err)
 	}
+	if e.conn != nil {
+		e.conn.Start()
+	}
 
 	return nil
 }
@@ -84,7 +100,11 @@ func (e *Execd) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
 		return fmt.Errorf("metric serializing error: %w", err)
 	}
 
-	_, err = e.process.Stdin.Write(b)
+	if e.conn != nil {
+		err = e.conn.Write(framing.Data, b)
+	} else {
+		_, err = e.process.Stdin.Write(b)
+	}
 	if err != nil {
 		return fmt.Errorf("error writing to process stdin: %w", err)
 	}
@@ -97,9 +117,30 @@ func (e *Execd) Add(m telegraf.Metric, _ telegraf.Accumulator) error {
 }
 
 func (e *Execd) Stop() {
+	if e.conn != nil {
+		e.conn.Stop()
+	}
 	e.process.Stop()
 }
 
+// handleFrame processes the frames of the protocol v2
+func (e *Execd) handleFrame(f framing.Frame) {
+	switch f.Type {
+	case framing.Data:
+		metrics, err := e.parser.Parse(f.Payload)
+		if err != nil {
+			e.Log.Errorf("Parse error: %s", err)
+		}
+		for _, metric := range metrics {
+			e.acc.AddMetric(metric)
+		}
+	case framing.Error:
+		e.Log.Errorf("Process reported error: %s", f.Payload)
+	default:
+		e.Log.Debugf("Ignoring unexpected frame of type %q", f.Type)
+	}
+}
+
 func (e *Execd) cmdReadOut(out io.Reader) {
 	// Prefer using the StreamParser when parsing influx format.
 	if _, isInfluxParser := e.parser.(*influx.Parser); isInfluxParser {
@@ -171,6 +212,15 @@ func (e *Execd) Init() error {
 	if len(e.Command) == 0 {
 		return errors.New("no command specified")
 	}
+
+	switch e.Protocol {
+	case "":
+		e.Protocol = process.ProtocolV1
+	case process.ProtocolV1, process.ProtocolV2:
+	default:
+		return fmt.Errorf("invalid protocol %q", e.Protocol)
+	}
+
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
@@ -14,6 +15,7 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/common/framing"
 	_ "github.com/influxdata/telegraf/plugins/parsers/all"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/processors"
@@ -91,6 +93,48 @@ func TestExternalProcessorWorks(t *testing.T) {
 	}
 }
 
+func TestExternalProcessorProtocolV2(t *testing.T) {
+	e := New()
+	e.Log = testutil.Logger{}
+
+	parser := &influx.Parser{}
+	require.NoError(t, parser.Init())
+	e.SetParser(parser)
+
+	serializer := &influxSerializer.Serializer{}
+	require.NoError(t, serializer.Init())
+	e.SetSerializer(serializer)
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	e.Command = []string{exe, "-framedtagger"}
+	e.Environment = []string{"PLUGINS_PROCESSORS_EXECD_MODE=application"}
+	e.Protocol = "v2"
+	e.PluginConfig = "processed"
+	require.NoError(t, e.Init())
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+
+	now := time.Now()
+	m := metric.New("test",
+		map[string]string{"city": "Toronto"},
+		map[string]interface{}{"message": "multi\nline"},
+		now,
+	)
+	require.NoError(t, e.Add(m, acc))
+
+	acc.Wait(1)
+	e.Stop()
+
+	expected := testutil.MustMetric("test",
+		map[string]string{"city": "Toronto", "state": "processed"},
+		map[string]interface{}{"message": "multi\nline"},
+		now,
+	)
+	testutil.RequireMetricEqual(t, expected, acc.GetTelegrafMetrics()[0])
+}
+
 func TestParseLinesWithNewLines(t *testing.T) {
 	e := New()
 	e.Log = testutil.Logger{}
@@ -151,6 +195,9 @@ func TestParseLinesWithNewLines(t *testing.T) {
 var countmultiplier = flag.Bool("countmultiplier", false,
 	"if true, act like line input program instead of test")
 
+var framedtagger = flag.Bool("framedtagger", false,
+	"if true, act like framed processor program instead of test")
+
 func TestMain(m *testing.M) {
 	flag.Parse()
 	runMode := os.Getenv("PLUGINS_PROCESSORS_EXECD_MODE")
@@ -158,6 +205,14 @@ func TestMain(m *testing.M) {
 		runCountMultiplierProgram()
 		os.Exit(0)
 	}
+	if *framedtagger && runMode == "application" {
+		if err := runFramedTaggerProgram(); err != nil {
+			fmt.Fprintf(os.Stderr, "ERR %v\n", err)
+			//nolint:revive // os.Exit called intentionally
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
 	code := m.Run()
 	os.Exit(code)
 }
@@ -275,3 +330,49 @@ func TestCases(t *testing.T) {
 		})
 	}
 }
+
+// runFramedTaggerProgram adds a "state" tag with the value of the pushed
+// configuration to all metrics
+func runFramedTaggerProgram() error {
+	parser := &influx.Parser{}
+	if err := parser.Init(); err != nil {
+		return err
+	}
+	serializer := &influxSerializer.Serializer{}
+	if err := serializer.Init(); err != nil {
+		return err
+	}
+
+	var state string
+	for {
+		f, err := framing.Read(os.Stdin)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		switch f.Type {
+		case framing.Config:
+			state = string(f.Payload)
+		case framing.Data:
+			metrics, err := parser.Parse(f.Payload)
+			if err != nil {
+				return err
+			}
+			for _, m := range metrics {
+				m.AddTag("state", state)
+			}
+			b, err := serializer.SerializeBatch(metrics)
+			if err != nil {
+				return err
+			}
+			if err := framing.Write(os.Stdout, framing.Data, b); err != nil {
+				return err
+			}
+		case framing.Shutdown:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,262 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package join
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Join struct {
+	Left        string          `toml:"left"`
+	Right       string          `toml:"right"`
+	Tags        []string        `toml:"tags"`
+	Name        string          `toml:"name"`
+	LeftPrefix  string          `toml:"left_prefix"`
+	RightPrefix string          `toml:"right_prefix"`
+	Timeout     config.Duration `toml:"timeout"`
+	Unmatched   string          `toml:"unmatched"`
+	Log         telegraf.Logger `toml:"-"`
+
+	leftTags  []string
+	rightTags []string
+
+	pending map[string]*entry
+	acc     telegraf.Accumulator
+	done    chan struct{}
+	wg      sync.WaitGroup
+	sync.Mutex
+}
+
+// entry is a buffered metric waiting for its counterpart, only one of the
+// sides is set at any time
+type entry struct {
+	left    telegraf.Metric
+	right   telegraf.Metric
+	expires time.Time
+}
+
+func (*Join) SampleConfig() string {
+	return sampleConfig
+}
+
+func (j *Join) Init() error {
+	if j.Left == "" || j.Right == "" {
+		return errors.New("both 'left' and 'right' measurements must be set")
+	}
+	if j.Left == j.Right {
+		return errors.New("'left' and 'right' measurements must differ")
+	}
+	if len(j.Tags) == 0 {
+		return errors.New("no join 'tags' specified")
+	}
+	if j.Timeout <= 0 {
+		return errors.New("'timeout' must be greater than zero")
+	}
+	if err := choice.Check(j.Unmatched, []string{"pass", "drop"}); err != nil {
+		return fmt.Errorf("invalid 'unmatched': %w", err)
+	}
+
+	// Tags can be specified as "<left>:<right>" if the names differ
+	j.leftTags = make([]string, 0, len(j.Tags))
+	j.rightTags = make([]string, 0, len(j.Tags))
+	for _, tag := range j.Tags {
+		left, right, found := strings.Cut(tag, ":")
+		if !found {
+			right = left
+		}
+		if left == "" || right == "" {
+			return fmt.Errorf("invalid join tag %q", tag)
+		}
+		j.leftTags = append(j.leftTags, left)
+		j.rightTags = append(j.rightTags, right)
+	}
+
+	j.pending = make(map[string]*entry)
+
+	return nil
+}
+
+func (j *Join) Start(acc telegraf.Accumulator) error {
+	j.acc = acc
+
+	// Check for expired entries in fractions of the timeout to not exceed the
+	// configured timeout by much
+	tick := time.Duration(j.Timeout) / 10
+	if tick < 100*time.Millisecond {
+		tick = 100 * time.Millisecond
+	}
+
+	j.done = make(chan struct{})
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-j.done:
+				return
+			case now := <-ticker.C:
+				j.expire(now, false)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (j *Join) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	var isLeft bool
+	var tags []string
+	switch m.Name() {
+	case j.Left:
+		isLeft, tags = true, j.leftTags
+	case j.Right:
+		tags = j.rightTags
+	default:
+		acc.AddMetric(m)
+		return nil
+	}
+
+	key, found := joinKey(m, tags)
+	if !found {
+		j.Log.Debugf("Metric %q is missing join tags, passing it on", m.Name())
+		acc.AddMetric(m)
+		return nil
+	}
+
+	j.Lock()
+	defer j.Unlock()
+
+	e, found := j.pending[key]
+	if !found {
+		e = &entry{}
+		j.pending[key] = e
+	}
+
+	// Replace older metrics of the same side without counterpart
+	if isLeft && e.left != nil || !isLeft && e.right != nil {
+		if isLeft {
+			j.unmatched(e.left)
+		} else {
+			j.unmatched(e.right)
+		}
+		e.left, e.right = nil, nil
+	}
+
+	if isLeft {
+		e.left = m
+	} else {
+		e.right = m
+	}
+	if e.left == nil || e.right == nil {
+		e.expires = time.Now().Add(time.Duration(j.Timeout))
+		return nil
+	}
+
+	delete(j.pending, key)
+	acc.AddMetric(j.merge(e.left, e.right))
+	return nil
+}
+
+func (j *Join) Stop() {
+	if j.done != nil {
+		close(j.done)
+	}
+	j.wg.Wait()
+
+	// Handle the remaining metrics, there is no chance to join them anymore
+	j.expire(time.Now(), true)
+}
+
+// expire handles the buffered metrics without counterpart after the timeout
+// or all of them if requested
+func (j *Join) expire(now time.Time, all bool) {
+	j.Lock()
+	defer j.Unlock()
+
+	for key, e := range j.pending {
+		if !all && now.Before(e.expires) {
+			continue
+		}
+		if e.left != nil {
+			j.unmatched(e.left)
+		}
+		if e.right != nil {
+			j.unmatched(e.right)
+		}
+		delete(j.pending, key)
+	}
+}
+
+func (j *Join) unmatched(m telegraf.Metric) {
+	if j.Unmatched == "drop" {
+		m.Drop()
+		return
+	}
+	j.acc.AddMetric(m)
+}
+
+// merge adds the fields and additional tags of the right metric to the left
+// metric keeping its timestamp
+func (j *Join) merge(left, right telegraf.Metric) telegraf.Metric {
+	if j.LeftPrefix != "" {
+		fields := left.Fields()
+		for k := range fields {
+			left.RemoveField(k)
+		}
+		for k, v := range fields {
+			left.AddField(j.LeftPrefix+k, v)
+		}
+	}
+	for _, field := range right.FieldList() {
+		left.AddField(j.RightPrefix+field.Key, field.Value)
+	}
+	for _, tag := range right.TagList() {
+		if !left.HasTag(tag.Key) {
+			left.AddTag(tag.Key, tag.Value)
+		}
+	}
+	if j.Name != "" {
+		left.SetName(j.Name)
+	}
+	right.Drop()
+
+	return left
+}
+
+// joinKey builds a unique key of the values of the given tags
+func joinKey(m telegraf.Metric, tags []string) (string, bool) {
+	var b strings.Builder
+	for _, tag := range tags {
+		v, found := m.GetTag(tag)
+		if !found {
+			return "", false
+		}
+		b.WriteString(v)
+		b.WriteByte(0)
+	}
+	return b.String(), true
+}
+
+func init() {
+	processors.AddStreaming("join", func() telegraf.StreamingProcessor {
+		return &Join{
+			Timeout:   config.Duration(10 * time.Second),
+			Unmatched: "pass",
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package join
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Join
+		expected string
+	}{
+		{
+			name:     "missing right",
+			plugin:   &Join{Left: "a", Tags: []string{"host"}},
+			expected: "both 'left' and 'right' measurements must be set",
+		},
+		{
+			name:     "same measurement",
+			plugin:   &Join{Left: "a", Right: "a", Tags: []string{"host"}},
+			expected: "must differ",
+		},
+		{
+			name:     "no tags",
+			plugin:   &Join{Left: "a", Right: "b"},
+			expected: "no join 'tags' specified",
+		},
+		{
+			name:     "invalid tag",
+			plugin:   &Join{Left: "a", Right: "b", Tags: []string{"host:"}},
+			expected: `invalid join tag "host:"`,
+		},
+		{
+			name:     "invalid unmatched",
+			plugin:   &Join{Left: "a", Right: "b", Tags: []string{"host"}, Unmatched: "foo"},
+			expected: "invalid 'unmatched'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.plugin.Timeout == 0 {
+				tt.plugin.Timeout = config.Duration(time.Second)
+			}
+			if tt.plugin.Unmatched == "" {
+				tt.plugin.Unmatched = "pass"
+			}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	plugin := &Join{
+		Left:        "net",
+		Right:       "snmp",
+		Tags:        []string{"host", "interface:ifName"},
+		Name:        "interface",
+		RightPrefix: "snmp_",
+		Timeout:     config.Duration(time.Hour),
+		Unmatched:   "pass",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("net", map[string]string{"host": "a", "interface": "eth0"}, map[string]interface{}{"bytes_recv": 10}, now),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.0}, now),
+		metric.New("net", map[string]string{"host": "a", "interface": "eth1"}, map[string]interface{}{"bytes_recv": 20}, now),
+		metric.New("snmp", map[string]string{"host": "a", "ifName": "eth0", "ifAlias": "uplink"},
+			map[string]interface{}{"ifSpeed": 1000}, now.Add(time.Second)),
+		metric.New("net", map[string]string{"host": "b"}, map[string]interface{}{"bytes_recv": 30}, now),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	expected := []telegraf.Metric{
+		input[1],
+		metric.New("interface", map[string]string{"host": "a", "interface": "eth0", "ifName": "eth0", "ifAlias": "uplink"},
+			map[string]interface{}{"bytes_recv": 10, "snmp_ifSpeed": 1000}, now),
+		input[4],
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The remaining metric is passed on when stopping
+	acc.ClearMetrics()
+	plugin.Stop()
+	expected = []telegraf.Metric{
+		metric.New("net", map[string]string{"host": "a", "interface": "eth1"}, map[string]interface{}{"bytes_recv": 20}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestLeftPrefix(t *testing.T) {
+	plugin := &Join{
+		Left:        "a",
+		Right:       "b",
+		Tags:        []string{"host"},
+		LeftPrefix:  "a_",
+		RightPrefix: "b_",
+		Timeout:     config.Duration(time.Hour),
+		Unmatched:   "pass",
+		Log:         testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	now := time.Now()
+	require.NoError(t, plugin.Add(metric.New("b", map[string]string{"host": "x"}, map[string]interface{}{"value": 2}, now), &acc))
+	require.NoError(t, plugin.Add(metric.New("a", map[string]string{"host": "x"}, map[string]interface{}{"value": 1}, now), &acc))
+
+	expected := []telegraf.Metric{
+		metric.New("a", map[string]string{"host": "x"}, map[string]interface{}{"a_value": 1, "b_value": 2}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestTimeout(t *testing.T) {
+	plugin := &Join{
+		Left:      "a",
+		Right:     "b",
+		Tags:      []string{"host"},
+		Timeout:   config.Duration(time.Minute),
+		Unmatched: "drop",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	plugin.acc = &acc
+
+	now := time.Now()
+	first := metric.New("a", map[string]string{"host": "x"}, map[string]interface{}{"value": 1}, now)
+	first, _ = metric.WithTracking(first, func(telegraf.DeliveryInfo) {})
+	require.NoError(t, plugin.Add(first, &acc))
+
+	// Not expired yet
+	plugin.expire(now.Add(30*time.Second), false)
+	require.Len(t, plugin.pending, 1)
+
+	// Expired metrics are dropped without matching counterpart
+	plugin.expire(now.Add(2*time.Minute), false)
+	require.Empty(t, plugin.pending)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// A metric arriving after the timeout is not joined
+	second := metric.New("b", map[string]string{"host": "x"}, map[string]interface{}{"value": 2}, now)
+	require.NoError(t, plugin.Add(second, &acc))
+	require.Len(t, plugin.pending, 1)
+	require.Empty(t, acc.GetTelegrafMetrics())
+}
+
+func TestTracking(t *testing.T) {
+	plugin := &Join{
+		Left:      "a",
+		Right:     "b",
+		Tags:      []string{"host"},
+		Timeout:   config.Duration(time.Hour),
+		Unmatched: "pass",
+		Log:       testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	var delivered int
+	notify := func(telegraf.DeliveryInfo) { delivered++ }
+
+	now := time.Now()
+	left, _ := metric.WithTracking(metric.New("a", map[string]string{"host": "x"}, map[string]interface{}{"value": 1}, now), notify)
+	right, _ := metric.WithTracking(metric.New("b", map[string]string{"host": "x"}, map[string]interface{}{"other": 2}, now), notify)
+	require.NoError(t, plugin.Add(left, &acc))
+	require.NoError(t, plugin.Add(right, &acc))
+	plugin.Stop()
+
+	// The right metric is consumed by the join while the left one is passed on
+	require.Equal(t, 1, delivered)
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+}
@@ -3,10 +3,12 @@ package regex
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"regexp"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal/choice"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
@@ -25,11 +27,16 @@ type Regex struct {
 }
 
 type converter struct {
-	Key         string `toml:"key"`
-	Pattern     string `toml:"pattern"`
-	Replacement string `toml:"replacement"`
-	ResultKey   string `toml:"result_key"`
-	Append      bool   `toml:"append"`
+	Key          string   `toml:"key"`
+	Pattern      string   `toml:"pattern"`
+	Replacement  string   `toml:"replacement"`
+	ResultKey    string   `toml:"result_key"`
+	Append       bool     `toml:"append"`
+	NamedGroups  bool     `toml:"named_groups"`
+	ResultPrefix string   `toml:"result_prefix"`
+	Measurement  []string `toml:"measurement"`
+
+	measurementFilter filter.Filter
 }
 
 func (*Regex) SampleConfig() string {
@@ -40,19 +47,20 @@ func (r *Regex) Init() error {
 	r.regexCache = make(map[string]*regexp.Regexp)
 
 	// Compile the regular expressions
-	for _, c := range r.Tags {
-		if _, compiled := r.regexCache[c.Pattern]; !compiled {
-			r.regexCache[c.Pattern] = regexp.MustCompile(c.Pattern)
+	for i := range r.Tags {
+		if err := r.compile(&r.Tags[i]); err != nil {
+			return fmt.Errorf("invalid tags conversion: %w", err)
 		}
 	}
-	for _, c := range r.Fields {
-		if _, compiled := r.regexCache[c.Pattern]; !compiled {
-			r.regexCache[c.Pattern] = regexp.MustCompile(c.Pattern)
+	for i := range r.Fields {
+		if err := r.compile(&r.Fields[i]); err != nil {
+			return fmt.Errorf("invalid fields conversion: %w", err)
 		}
 	}
 
 	resultOptions := []string{"overwrite", "keep"}
-	for _, c := range r.TagRename {
+	for i := range r.TagRename {
+		c := &r.TagRename[i]
 		if c.Key != "" {
 			r.Log.Info("'tag_rename' section contains a key which is ignored during processing")
 		}
@@ -64,12 +72,16 @@ func (r *Regex) Init() error {
 			return fmt.Errorf("invalid metrics result_key: %w", err)
 		}
 
-		if _, compiled := r.regexCache[c.Pattern]; !compiled {
-			r.regexCache[c.Pattern] = regexp.MustCompile(c.Pattern)
+		if c.NamedGroups {
+			return errors.New("'named_groups' is only supported for tags and fields")
+		}
+		if err := r.compile(c); err != nil {
+			return err
 		}
 	}
 
-	for _, c := range r.FieldRename {
+	for i := range r.FieldRename {
+		c := &r.FieldRename[i]
 		if c.Key != "" {
 			r.Log.Info("'field_rename' section contains a key which is ignored during processing")
 		}
@@ -81,12 +93,16 @@ func (r *Regex) Init() error {
 			return fmt.Errorf("invalid metrics result_key: %w", err)
 		}
 
-		if _, compiled := r.regexCache[c.Pattern]; !compiled {
-			r.regexCache[c.Pattern] = regexp.MustCompile(c.Pattern)
+		if c.NamedGroups {
+			return errors.New("'named_groups' is only supported for tags and fields")
+		}
+		if err := r.compile(c); err != nil {
+			return err
 		}
 	}
 
-	for _, c := range r.MetricRename {
+	for i := range r.MetricRename {
+		c := &r.MetricRename[i]
 		if c.Key != "" {
 			r.Log.Info("'metric_rename' section contains a key which is ignored during processing")
 		}
@@ -95,17 +111,80 @@ func (r *Regex) Init() error {
 			r.Log.Info("'metric_rename' section contains a 'result_key' ignored during processing as metrics will ALWAYS the name")
 		}
 
-		if _, compiled := r.regexCache[c.Pattern]; !compiled {
-			r.regexCache[c.Pattern] = regexp.MustCompile(c.Pattern)
+		if c.NamedGroups {
+			return errors.New("'named_groups' is only supported for tags and fields")
+		}
+		if err := r.compile(c); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// compile compiles the pattern and measurement filter of the converter
+func (r *Regex) compile(c *converter) error {
+	regex, compiled := r.regexCache[c.Pattern]
+	if !compiled {
+		var err error
+		regex, err = regexp.Compile(c.Pattern)
+		if err != nil {
+			return fmt.Errorf("compiling pattern %q failed: %w", c.Pattern, err)
+		}
+		r.regexCache[c.Pattern] = regex
+	}
+
+	if c.NamedGroups {
+		var found bool
+		for _, name := range regex.SubexpNames() {
+			found = found || name != ""
+		}
+		if !found {
+			return fmt.Errorf("pattern %q does not contain named groups", c.Pattern)
+		}
+	}
+
+	f, err := filter.Compile(c.Measurement)
+	if err != nil {
+		return fmt.Errorf("creating measurement filter failed: %w", err)
+	}
+	c.measurementFilter = f
+
+	return nil
+}
+
+// applies checks if the converter should be used for the given metric
+func (c *converter) applies(metric telegraf.Metric) bool {
+	return c.measurementFilter == nil || c.measurementFilter.Match(metric.Name())
+}
+
 func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 	for _, metric := range in {
 		for _, converter := range r.Tags {
+			if !converter.applies(metric) {
+				continue
+			}
+			if converter.NamedGroups {
+				if converter.Key == "*" {
+					// Collect the new tags first as we cannot modify the
+					// tag-list while iterating it
+					var extracted []map[string]string
+					for _, tag := range metric.TagList() {
+						extracted = append(extracted, r.extract(converter, tag.Value))
+					}
+					for _, tags := range extracted {
+						for key, value := range tags {
+							updateTag(converter, metric, key, value)
+						}
+					}
+				} else if value, ok := metric.GetTag(converter.Key); ok {
+					for key, value := range r.extract(converter, value) {
+						updateTag(converter, metric, key, value)
+					}
+				}
+				continue
+			}
+
 			if converter.Key == "*" {
 				for _, tag := range metric.TagList() {
 					regex := r.regexCache[converter.Pattern]
@@ -122,8 +201,17 @@ func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		}
 
 		for _, converter := range r.Fields {
+			if !converter.applies(metric) {
+				continue
+			}
 			if value, ok := metric.GetField(converter.Key); ok {
 				if v, ok := value.(string); ok {
+					if converter.NamedGroups {
+						for key, value := range r.extract(converter, v) {
+							metric.AddField(key, value)
+						}
+						continue
+					}
 					if key, newValue := r.convert(converter, v); newValue != "" {
 						metric.AddField(key, newValue)
 					}
@@ -132,6 +220,9 @@ func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		}
 
 		for _, converter := range r.TagRename {
+			if !converter.applies(metric) {
+				continue
+			}
 			regex := r.regexCache[converter.Pattern]
 			replacements := make(map[string]string)
 			for _, tag := range metric.TagList() {
@@ -165,6 +256,9 @@ func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		}
 
 		for _, converter := range r.FieldRename {
+			if !converter.applies(metric) {
+				continue
+			}
 			regex := r.regexCache[converter.Pattern]
 			replacements := make(map[string]string)
 			for _, field := range metric.FieldList() {
@@ -198,6 +292,9 @@ func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
 		}
 
 		for _, converter := range r.MetricRename {
+			if !converter.applies(metric) {
+				continue
+			}
 			regex := r.regexCache[converter.Pattern]
 			value := metric.Name()
 			if regex.MatchString(value) {
@@ -224,6 +321,26 @@ func (r *Regex) convert(c converter, src string) (key string, value string) {
 	return c.Key, value
 }
 
+// extract returns the non-empty submatches of all named groups of the
+// pattern with the group name as key
+func (r *Regex) extract(c converter, src string) map[string]string {
+	regex := r.regexCache[c.Pattern]
+
+	matches := regex.FindStringSubmatch(src)
+	if matches == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(matches))
+	for i, name := range regex.SubexpNames() {
+		if name == "" || matches[i] == "" {
+			continue
+		}
+		result[c.ResultPrefix+name] = matches[i]
+	}
+	return result
+}
+
 func updateTag(converter converter, metric telegraf.Metric, key string, newValue string) {
 	if converter.Append {
 		if v, ok := metric.GetTag(key); ok {
@@ -884,3 +884,144 @@ func TestAnyTagConversion(t *testing.T) {
 		require.Equal(t, "access_log", processed[0].Name(), "Should not change name")
 	}
 }
+
+func TestNamedGroups(t *testing.T) {
+	regex := Regex{
+		Tags: []converter{
+			{
+				Key:          "resp_code",
+				Pattern:      "^(?P<class>\\d)(?P<detail>\\d\\d)$",
+				NamedGroups:  true,
+				ResultPrefix: "code_",
+			},
+		},
+		Fields: []converter{
+			{
+				Key:         "request",
+				Pattern:     "^/api/(?P<resource>\\w+)/\\?category=(?P<category>\\w+)(&page=(?P<page>\\d+))?",
+				NamedGroups: true,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+
+	processed := regex.Apply(newM2())
+
+	expectedFields := map[string]interface{}{
+		"request":       "/api/search/?category=plugins&q=regex&sort=asc",
+		"resource":      "search",
+		"category":      "plugins",
+		"ignore_number": int64(200),
+		"ignore_bool":   true,
+	}
+	expectedTags := map[string]string{
+		"verb":        "GET",
+		"resp_code":   "200",
+		"code_class":  "2",
+		"code_detail": "00",
+	}
+
+	require.Equal(t, expectedFields, processed[0].Fields())
+	require.Equal(t, expectedTags, processed[0].Tags())
+}
+
+func TestNamedGroupsAnyTag(t *testing.T) {
+	regex := Regex{
+		Tags: []converter{
+			{
+				Key:         "*",
+				Pattern:     "^(?P<prefix>[a-z]+)-[0-9a-f]{8}-",
+				NamedGroups: true,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+
+	processed := regex.Apply(newUUIDTags())
+
+	expectedTags := map[string]string{
+		"compound": "other-18cb0b46-73b8-4084-9fc4-5105f32a8a68",
+		"simple":   "d60be57c-2f43-4e4f-a68a-4ca8204bae41",
+		"control":  "not_uuid",
+		"prefix":   "other",
+	}
+	require.Equal(t, expectedTags, processed[0].Tags())
+}
+
+func TestNamedGroupsInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		regex    Regex
+		expected string
+	}{
+		{
+			name: "no named groups",
+			regex: Regex{
+				Fields: []converter{{Key: "request", Pattern: "^/(\\w+)/", NamedGroups: true}},
+			},
+			expected: "does not contain named groups",
+		},
+		{
+			name: "rename",
+			regex: Regex{
+				FieldRename: []converter{{Pattern: "^(?P<name>\\w+)$", NamedGroups: true}},
+			},
+			expected: "only supported for tags and fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.regex.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.regex.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMeasurementFilter(t *testing.T) {
+	regex := Regex{
+		Tags: []converter{
+			{
+				Key:         "resp_code",
+				Pattern:     "^(\\d)\\d\\d$",
+				Replacement: "${1}xx",
+				Measurement: []string{"nginx_*"},
+			},
+		},
+		Fields: []converter{
+			{
+				Key:         "request",
+				Pattern:     "^/users/(?P<user>\\d+)/$",
+				NamedGroups: true,
+				Measurement: []string{"access_*"},
+			},
+		},
+		MetricRename: []converter{
+			{
+				Pattern:     "^access_(\\w+)$",
+				Replacement: "${1}",
+				Measurement: []string{"access_log"},
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, regex.Init())
+
+	processed := regex.Apply(newM1())
+
+	expected := testutil.MustMetric(
+		"log",
+		map[string]string{
+			"verb":      "GET",
+			"resp_code": "200",
+		},
+		map[string]interface{}{
+			"request": "/users/42/",
+			"user":    "42",
+		},
+		time.Unix(0, 0),
+	)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{expected}, processed, testutil.IgnoreTime())
+}
@@ -0,0 +1,69 @@
+package rename
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readMappingFile reads a CSV file with one rename operation per line in the
+// form "<measurement|tag|field>,<name>,<dest>[,regex]"
+func readMappingFile(filename string) ([]Replace, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var replaces []Replace
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %q failed: %w", filename, err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if len(record) < 3 || len(record) > 4 {
+			return nil, fmt.Errorf("invalid mapping in %q line %d: expected 3 or 4 columns but got %d", filename, line, len(record))
+		}
+
+		var replace Replace
+		switch record[0] {
+		case "measurement":
+			replace.Measurement = record[1]
+		case "tag":
+			replace.Tag = record[1]
+		case "field":
+			replace.Field = record[1]
+		default:
+			return nil, fmt.Errorf("invalid mapping in %q line %d: unknown type %q", filename, line, record[0])
+		}
+		if record[1] == "" || record[2] == "" {
+			return nil, fmt.Errorf("invalid mapping in %q line %d: empty name or destination", filename, line)
+		}
+		replace.Dest = record[2]
+
+		if len(record) == 4 {
+			switch strings.TrimSpace(record[3]) {
+			case "regex":
+				replace.Regex = true
+			case "":
+			default:
+				return nil, fmt.Errorf("invalid mapping in %q line %d: unknown option %q", filename, line, record[3])
+			}
+		}
+		replaces = append(replaces, replace)
+	}
+	return replaces, nil
+}
@@ -3,8 +3,14 @@ package rename
 
 import (
 	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/processors"
 )
 
@@ -16,49 +22,240 @@ type Replace struct {
 	Tag         string `toml:"tag"`
 	Field       string `toml:"field"`
 	Dest        string `toml:"dest"`
+	Regex       bool   `toml:"regex"`
 }
 
 type Rename struct {
-	Replaces []Replace `toml:"replace"`
+	Replaces       []Replace       `toml:"replace"`
+	MappingFiles   []string        `toml:"mapping_files"`
+	ReloadInterval config.Duration `toml:"mapping_reload_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	rules        []rule
+	fileRules    []rule
+	modTimes     map[string]time.Time
+	lastReloaded time.Time
+}
+
+// rule is a compiled rename operation for measurements, tags or fields
+type rule struct {
+	kind  string
+	name  string
+	regex *regexp.Regexp
+	dest  string
+}
+
+func newRule(replace Replace) (rule, error) {
+	var r rule
+	switch {
+	case replace.Measurement != "":
+		r.kind, r.name = "measurement", replace.Measurement
+	case replace.Tag != "":
+		r.kind, r.name = "tag", replace.Tag
+	case replace.Field != "":
+		r.kind, r.name = "field", replace.Field
+	default:
+		return r, nil
+	}
+	r.dest = replace.Dest
+
+	if replace.Regex {
+		re, err := regexp.Compile("^(?:" + r.name + ")$")
+		if err != nil {
+			return r, fmt.Errorf("compiling pattern %q failed: %w", r.name, err)
+		}
+		r.regex = re
+		return r, nil
+	}
+
+	if !strings.Contains(r.name, "*") {
+		return r, nil
+	}
+
+	// Convert the wildcards to capturing groups and use the captured text
+	// for the wildcards in the destination in the same order
+	parts := strings.Split(r.name, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+	r.regex = regexp.MustCompile("^" + strings.Join(parts, "(.*)") + "$")
+
+	dest := strings.Split(strings.ReplaceAll(r.dest, "$", "$$"), "*")
+	if len(dest)-1 > len(parts)-1 {
+		return r, fmt.Errorf("destination %q contains more wildcards than %q", r.dest, r.name)
+	}
+	var sb strings.Builder
+	for i, p := range dest {
+		if i > 0 {
+			fmt.Fprintf(&sb, "${%d}", i)
+		}
+		sb.WriteString(p)
+	}
+	r.dest = sb.String()
+
+	return r, nil
+}
+
+// rename returns the new name if the rule matches the given name
+func (r *rule) rename(name string) (string, bool) {
+	if r.regex == nil {
+		return r.dest, name == r.name
+	}
+	if !r.regex.MatchString(name) {
+		return "", false
+	}
+	return r.regex.ReplaceAllString(name, r.dest), true
 }
 
 func (*Rename) SampleConfig() string {
 	return sampleConfig
 }
 
+func (r *Rename) Init() error {
+	for _, replace := range r.Replaces {
+		if replace.Dest == "" {
+			continue
+		}
+		rl, err := newRule(replace)
+		if err != nil {
+			return err
+		}
+		if rl.kind != "" {
+			r.rules = append(r.rules, rl)
+		}
+	}
+
+	if len(r.MappingFiles) > 0 {
+		r.modTimes = make(map[string]time.Time, len(r.MappingFiles))
+		rules, err := r.loadMappings()
+		if err != nil {
+			return err
+		}
+		r.fileRules = rules
+		r.lastReloaded = time.Now()
+	}
+
+	return nil
+}
+
+// loadMappings reads the rules of all mapping files and records the
+// modification times of the files
+func (r *Rename) loadMappings() ([]rule, error) {
+	var rules []rule
+	for _, filename := range r.MappingFiles {
+		stat, err := os.Stat(filename)
+		if err != nil {
+			return nil, err
+		}
+		replaces, err := readMappingFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		for i, replace := range replaces {
+			rl, err := newRule(replace)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mapping %d in %q: %w", i+1, filename, err)
+			}
+			rules = append(rules, rl)
+		}
+		r.modTimes[filename] = stat.ModTime()
+	}
+	return rules, nil
+}
+
+// reload reads the mapping files again if any of them changed since loading
+func (r *Rename) reload() {
+	if r.ReloadInterval <= 0 || time.Since(r.lastReloaded) < time.Duration(r.ReloadInterval) {
+		return
+	}
+	r.lastReloaded = time.Now()
+
+	var changed bool
+	for _, filename := range r.MappingFiles {
+		stat, err := os.Stat(filename)
+		if err != nil {
+			r.Log.Errorf("Checking mapping file failed: %v", err)
+			return
+		}
+		changed = changed || !stat.ModTime().Equal(r.modTimes[filename])
+	}
+	if !changed {
+		return
+	}
+
+	rules, err := r.loadMappings()
+	if err != nil {
+		r.Log.Errorf("Reloading mapping files failed, keeping previous mappings: %v", err)
+		return
+	}
+	r.fileRules = rules
+	r.Log.Debugf("Reloaded %d mappings", len(rules))
+}
+
 func (r *Rename) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	r.reload()
+
 	for _, point := range in {
-		for _, replace := range r.Replaces {
-			if replace.Dest == "" {
-				continue
-			}
+		for i := range r.rules {
+			applyRule(&r.rules[i], point)
+		}
+		for i := range r.fileRules {
+			applyRule(&r.fileRules[i], point)
+		}
+	}
 
-			if replace.Measurement != "" {
-				if value := point.Name(); value == replace.Measurement {
-					point.SetName(replace.Dest)
-				}
-				continue
+	return in
+}
+
+func applyRule(rl *rule, point telegraf.Metric) {
+	switch rl.kind {
+	case "measurement":
+		if dest, ok := rl.rename(point.Name()); ok {
+			point.SetName(dest)
+		}
+	case "tag":
+		if rl.regex == nil {
+			if value, ok := point.GetTag(rl.name); ok {
+				point.RemoveTag(rl.name)
+				point.AddTag(rl.dest, value)
 			}
+			return
+		}
 
-			if replace.Tag != "" {
-				if value, ok := point.GetTag(replace.Tag); ok {
-					point.RemoveTag(replace.Tag)
-					point.AddTag(replace.Dest, value)
-				}
-				continue
+		// We cannot modify the tag-list while iterating it, so collect the
+		// renames first
+		renames := make(map[string]string)
+		for _, tag := range point.TagList() {
+			if dest, ok := rl.rename(tag.Key); ok && dest != tag.Key {
+				renames[tag.Key] = dest
+			}
+		}
+		for name, dest := range renames {
+			value, _ := point.GetTag(name)
+			point.RemoveTag(name)
+			point.AddTag(dest, value)
+		}
+	case "field":
+		if rl.regex == nil {
+			if value, ok := point.GetField(rl.name); ok {
+				point.RemoveField(rl.name)
+				point.AddField(rl.dest, value)
 			}
+			return
+		}
 
-			if replace.Field != "" {
-				if value, ok := point.GetField(replace.Field); ok {
-					point.RemoveField(replace.Field)
-					point.AddField(replace.Dest, value)
-				}
-				continue
+		renames := make(map[string]string)
+		for _, field := range point.FieldList() {
+			if dest, ok := rl.rename(field.Key); ok && dest != field.Key {
+				renames[field.Key] = dest
 			}
 		}
+		for name, dest := range renames {
+			value, _ := point.GetField(name)
+			point.RemoveField(name)
+			point.AddField(dest, value)
+		}
 	}
-
-	return in
 }
 
 func init() {
@@ -1,13 +1,17 @@
 package rename
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
 )
 
 func newMetric(name string, tags map[string]string, fields map[string]interface{}) telegraf.Metric {
@@ -28,6 +32,7 @@ func TestMeasurementRename(t *testing.T) {
 			{Measurement: "baz", Dest: "quux"},
 		},
 	}
+	require.NoError(t, r.Init())
 	m1 := newMetric("foo", nil, nil)
 	m2 := newMetric("bar", nil, nil)
 	m3 := newMetric("baz", nil, nil)
@@ -43,6 +48,7 @@ func TestTagRename(t *testing.T) {
 			{Tag: "hostname", Dest: "host"},
 		},
 	}
+	require.NoError(t, r.Init())
 	m := newMetric("foo", map[string]string{"hostname": "localhost", "region": "east-1"}, nil)
 	results := r.Apply(m)
 
@@ -55,8 +61,153 @@ func TestFieldRename(t *testing.T) {
 			{Field: "time_msec", Dest: "time"},
 		},
 	}
+	require.NoError(t, r.Init())
 	m := newMetric("foo", nil, map[string]interface{}{"time_msec": int64(1250), "snakes": true})
 	results := r.Apply(m)
 
 	require.Equal(t, map[string]interface{}{"time": int64(1250), "snakes": true}, results[0].Fields(), "should change field 'time_msec' to 'time'")
 }
+
+func TestWildcardRename(t *testing.T) {
+	r := Rename{
+		Replaces: []Replace{
+			{Measurement: "net_*", Dest: "network_*"},
+			{Tag: "*_name", Dest: "*"},
+			{Field: "ifHC*Octets", Dest: "octets_*"},
+			{Field: "*_*_count", Dest: "*_*"},
+		},
+	}
+	require.NoError(t, r.Init())
+
+	m := newMetric(
+		"net_interface",
+		map[string]string{"host_name": "localhost", "region": "east-1"},
+		map[string]interface{}{"ifHCInOctets": int64(1), "ifHCOutOctets": int64(2), "rx_error_count": int64(3), "errors": int64(4)},
+	)
+	results := r.Apply(m)
+
+	require.Equal(t, "network_interface", results[0].Name())
+	require.Equal(t, map[string]string{"host": "localhost", "region": "east-1"}, results[0].Tags())
+	require.Equal(t, map[string]interface{}{"octets_In": int64(1), "octets_Out": int64(2), "rx_error": int64(3), "errors": int64(4)}, results[0].Fields())
+}
+
+func TestRegexRename(t *testing.T) {
+	r := Rename{
+		Replaces: []Replace{
+			{Field: "(rx|tx)_bytes_(\\w+)", Dest: "${2}_${1}_bytes", Regex: true},
+			{Measurement: "cpu|processor", Dest: "compute", Regex: true},
+		},
+	}
+	require.NoError(t, r.Init())
+
+	results := r.Apply(
+		newMetric("cpu", nil, map[string]interface{}{"rx_bytes_eth0": int64(1), "tx_bytes_eth0": int64(2), "rx_bytes": int64(3)}),
+		newMetric("processors", nil, nil),
+	)
+
+	require.Equal(t, "compute", results[0].Name())
+	require.Equal(t, map[string]interface{}{"eth0_rx_bytes": int64(1), "eth0_tx_bytes": int64(2), "rx_bytes": int64(3)}, results[0].Fields())
+	require.Equal(t, "processors", results[1].Name(), "the regex must match the whole name")
+}
+
+func TestInvalidRules(t *testing.T) {
+	r := Rename{Replaces: []Replace{{Field: "(", Dest: "foo", Regex: true}}}
+	require.ErrorContains(t, r.Init(), "compiling pattern")
+
+	r = Rename{Replaces: []Replace{{Field: "foo_*", Dest: "*_*"}}}
+	require.ErrorContains(t, r.Init(), "more wildcards")
+}
+
+func TestMappingFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "mapping.csv")
+	mapping := `# type,name,dest[,regex]
+measurement,network_interface_throughput,throughput
+tag, hostname, host
+field,"lower,min",min
+field,upper_*,max_*
+`
+	require.NoError(t, os.WriteFile(filename, []byte(mapping), 0600))
+
+	r := Rename{
+		Replaces:     []Replace{{Field: "mean", Dest: "avg"}},
+		MappingFiles: []string{filename},
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, r.Init())
+
+	m := newMetric(
+		"network_interface_throughput",
+		map[string]string{"hostname": "backend.example.com"},
+		map[string]interface{}{"lower,min": int64(10), "upper_bound": int64(1000), "mean": int64(500)},
+	)
+	results := r.Apply(m)
+
+	require.Equal(t, "throughput", results[0].Name())
+	require.Equal(t, map[string]string{"host": "backend.example.com"}, results[0].Tags())
+	require.Equal(t, map[string]interface{}{"min": int64(10), "max_bound": int64(1000), "avg": int64(500)}, results[0].Fields())
+}
+
+func TestMappingFileInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapping  string
+		expected string
+	}{
+		{
+			name:     "unknown type",
+			mapping:  "metric,foo,bar\n",
+			expected: "unknown type",
+		},
+		{
+			name:     "missing destination",
+			mapping:  "field,foo\n",
+			expected: "expected 3 or 4 columns",
+		},
+		{
+			name:     "unknown option",
+			mapping:  "field,foo,bar,glob\n",
+			expected: "unknown option",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "mapping.csv")
+			require.NoError(t, os.WriteFile(filename, []byte(tt.mapping), 0600))
+
+			r := Rename{MappingFiles: []string{filename}}
+			require.ErrorContains(t, r.Init(), tt.expected)
+		})
+	}
+}
+
+func TestMappingFileReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "mapping.csv")
+	require.NoError(t, os.WriteFile(filename, []byte("field,foo,bar\n"), 0600))
+
+	r := Rename{
+		MappingFiles:   []string{filename},
+		ReloadInterval: config.Duration(time.Nanosecond),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, r.Init())
+
+	results := r.Apply(newMetric("test", nil, map[string]interface{}{"foo": int64(1)}))
+	require.Equal(t, map[string]interface{}{"bar": int64(1)}, results[0].Fields())
+
+	// Modify the mapping and make sure the modification time differs
+	require.NoError(t, os.WriteFile(filename, []byte("field,foo,baz\n"), 0600))
+	mtime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+
+	results = r.Apply(newMetric("test", nil, map[string]interface{}{"foo": int64(1)}))
+	require.Equal(t, map[string]interface{}{"baz": int64(1)}, results[0].Fields())
+
+	// Invalid modifications keep the previous mapping
+	require.NoError(t, os.WriteFile(filename, []byte("field,foo\n"), 0600))
+	mtime = mtime.Add(time.Minute)
+	require.NoError(t, os.Chtimes(filename, mtime, mtime))
+
+	results = r.Apply(newMetric("test", nil, map[string]interface{}{"foo": int64(1)}))
+	require.Equal(t, map[string]interface{}{"baz": int64(1)}, results[0].Fields())
+}
@@ -0,0 +1,173 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package sample
+
+import (
+	_ "embed"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// minimumExpiry is the minimum time a series is remembered after its last
+// metric, which is required for rate based sampling
+const minimumExpiry = 10 * time.Minute
+
+type Sample struct {
+	SampleInterval config.Duration `toml:"sample_interval"`
+	SampleRate     float64         `toml:"sample_rate"`
+	Delta          float64         `toml:"delta"`
+	DeltaFields    []string        `toml:"delta_fields"`
+	Log            telegraf.Logger `toml:"-"`
+
+	deltaFilter filter.Filter
+	series      map[uint64]*series
+	expiry      time.Duration
+	lastCleanup time.Time
+	random      *rand.Rand
+}
+
+// series holds the state of the last forwarded metric of a series
+type series struct {
+	forwarded time.Time
+	values    map[string]float64
+	seen      time.Time
+}
+
+func (*Sample) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Sample) Init() error {
+	if s.SampleInterval < 0 || s.SampleRate < 0 || s.Delta < 0 {
+		return errors.New("'sample_interval', 'sample_rate' and 'delta' must not be negative")
+	}
+	if s.SampleInterval > 0 && s.SampleRate > 0 {
+		return errors.New("only one of 'sample_interval' or 'sample_rate' can be specified")
+	} else if s.SampleInterval == 0 && s.SampleRate == 0 {
+		return errors.New("one of 'sample_interval' or 'sample_rate' must be specified")
+	}
+	if s.SampleRate > 1 {
+		return errors.New("'sample_rate' must be between zero and one")
+	}
+
+	var err error
+	s.deltaFilter, err = filter.Compile(s.DeltaFields)
+	if err != nil {
+		return err
+	}
+
+	s.series = make(map[uint64]*series)
+	s.expiry = 10 * time.Duration(s.SampleInterval)
+	if s.expiry < minimumExpiry {
+		s.expiry = minimumExpiry
+	}
+	s.lastCleanup = time.Now()
+	if s.random == nil {
+		s.random = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // sampling does not require a secure random source
+	}
+
+	return nil
+}
+
+func (s *Sample) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	now := time.Now()
+
+	out := in[:0]
+	for _, m := range in {
+		id := m.HashID()
+		state, found := s.series[id]
+		if !found {
+			state = &series{}
+			s.series[id] = state
+		}
+		state.seen = now
+
+		if !s.forward(m, state, found) {
+			m.Drop()
+			continue
+		}
+
+		state.forwarded = m.Time()
+		if s.Delta > 0 {
+			state.values = s.values(m)
+		}
+		out = append(out, m)
+	}
+
+	s.cleanup(now)
+
+	return out
+}
+
+// forward decides if the metric should be passed on
+func (s *Sample) forward(m telegraf.Metric, state *series, known bool) bool {
+	if s.SampleInterval > 0 {
+		if !known || m.Time().Sub(state.forwarded) >= time.Duration(s.SampleInterval) {
+			return true
+		}
+	} else if s.random.Float64() < s.SampleRate {
+		return true
+	}
+
+	// Always pass metrics with significant changes
+	if s.Delta > 0 && state.values != nil {
+		for k, v := range s.values(m) {
+			previous, found := state.values[k]
+			if !found || math.Abs(v-previous) > s.Delta {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// values returns the numeric values of the fields considered for the delta
+func (s *Sample) values(m telegraf.Metric) map[string]float64 {
+	values := make(map[string]float64)
+	for _, field := range m.FieldList() {
+		if s.deltaFilter != nil && !s.deltaFilter.Match(field.Key) {
+			continue
+		}
+		if _, isString := field.Value.(string); isString {
+			continue
+		}
+		v, err := internal.ToFloat64(field.Value)
+		if err != nil {
+			continue
+		}
+		values[field.Key] = v
+	}
+	return values
+}
+
+// cleanup forgets about series not seen for a while
+func (s *Sample) cleanup(now time.Time) {
+	// No need to cleanup too often
+	if now.Sub(s.lastCleanup) < s.expiry {
+		return
+	}
+	s.lastCleanup = now
+
+	for id, state := range s.series {
+		if now.Sub(state.seen) >= s.expiry {
+			delete(s.series, id)
+		}
+	}
+}
+
+func init() {
+	processors.Add("sample", func() telegraf.Processor {
+		return &Sample{}
+	})
+}
@@ -0,0 +1,193 @@
+package sample
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Sample
+		expected string
+	}{
+		{
+			name:     "nothing specified",
+			plugin:   &Sample{},
+			expected: "must be specified",
+		},
+		{
+			name:     "interval and rate",
+			plugin:   &Sample{SampleInterval: config.Duration(time.Minute), SampleRate: 0.1},
+			expected: "can be specified",
+		},
+		{
+			name:     "rate too high",
+			plugin:   &Sample{SampleRate: 2},
+			expected: "between zero and one",
+		},
+		{
+			name:     "negative delta",
+			plugin:   &Sample{SampleRate: 0.5, Delta: -1},
+			expected: "must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestInterval(t *testing.T) {
+	start := time.Unix(1677628800, 0)
+
+	plugin := &Sample{
+		SampleInterval: config.Duration(time.Minute),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var input []telegraf.Metric
+	for i := 0; i < 150; i++ {
+		for _, sensor := range []string{"a", "b"} {
+			input = append(input, metric.New(
+				"temperature",
+				map[string]string{"sensor": sensor},
+				map[string]interface{}{"value": float64(i)},
+				start.Add(time.Duration(i)*time.Second),
+			))
+		}
+	}
+
+	expected := make([]telegraf.Metric, 0, 6)
+	for _, offset := range []int{0, 60, 120} {
+		for _, sensor := range []string{"a", "b"} {
+			expected = append(expected, metric.New(
+				"temperature",
+				map[string]string{"sensor": sensor},
+				map[string]interface{}{"value": float64(offset)},
+				start.Add(time.Duration(offset)*time.Second),
+			))
+		}
+	}
+
+	// Feed the metrics in multiple batches
+	var actual []telegraf.Metric
+	for i := 0; i < len(input); i += 20 {
+		actual = append(actual, plugin.Apply(input[i:i+20]...)...)
+	}
+	testutil.RequireMetricsEqual(t, expected, actual)
+}
+
+func TestIntervalDelta(t *testing.T) {
+	start := time.Unix(1677628800, 0)
+
+	plugin := &Sample{
+		SampleInterval: config.Duration(time.Minute),
+		Delta:          10,
+		DeltaFields:    []string{"value"},
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	values := []float64{21.5, 21.7, 21.6, 35.2, 35.0}
+	input := make([]telegraf.Metric, 0, len(values)+1)
+	for i, v := range values {
+		input = append(input, metric.New(
+			"temperature",
+			map[string]string{"sensor": "a"},
+			map[string]interface{}{"value": v, "ignored": float64(i) * 100},
+			start.Add(time.Duration(i)*time.Second),
+		))
+	}
+	input = append(input, metric.New(
+		"temperature",
+		map[string]string{"sensor": "a"},
+		map[string]interface{}{"value": 35.1, "ignored": float64(0)},
+		start.Add(63*time.Second),
+	))
+
+	expected := []telegraf.Metric{input[0], input[3], input[5]}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestRate(t *testing.T) {
+	plugin := &Sample{
+		SampleRate: 0.25,
+		Log:        testutil.Logger{},
+		random:     rand.New(rand.NewSource(42)), //nolint:gosec // fixed seed for reproducible results
+	}
+	require.NoError(t, plugin.Init())
+
+	input := make([]telegraf.Metric, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		input = append(input, metric.New(
+			"test",
+			map[string]string{},
+			map[string]interface{}{"value": 1},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	actual := plugin.Apply(input...)
+	require.InDelta(t, 250, len(actual), 50)
+}
+
+func TestRateDelta(t *testing.T) {
+	plugin := &Sample{
+		SampleRate: 0.000001,
+		Delta:      0.5,
+		Log:        testutil.Logger{},
+		random:     rand.New(rand.NewSource(42)), //nolint:gosec // fixed seed for reproducible results
+	}
+	require.NoError(t, plugin.Init())
+
+	// Both metrics are dropped due to the tiny rate as changes can only be
+	// detected after forwarding a metric of the series
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 2}, time.Unix(1, 0)),
+	}
+	require.Empty(t, plugin.Apply(input...))
+
+	// Pretend the first metric was forwarded and check that changes are
+	// passed on
+	for _, state := range plugin.series {
+		state.values = map[string]float64{"value": 2}
+	}
+	input = []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 2.1}, time.Unix(2, 0)),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 3}, time.Unix(3, 0)),
+	}
+	testutil.RequireMetricsEqual(t, input[1:], plugin.Apply(input...))
+}
+
+func TestCleanup(t *testing.T) {
+	plugin := &Sample{
+		SampleInterval: config.Duration(time.Second),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	m := metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Now())
+	require.Len(t, plugin.Apply(m), 1)
+	require.Len(t, plugin.series, 1)
+
+	// Pretend the series was not seen for a long time
+	for _, state := range plugin.series {
+		state.seen = state.seen.Add(-time.Hour)
+	}
+	plugin.lastCleanup = plugin.lastCleanup.Add(-time.Hour)
+	require.Empty(t, plugin.Apply())
+	require.Empty(t, plugin.series)
+}
@@ -0,0 +1,345 @@
+package streamsql
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	pathRe       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+	windowRe     = regexp.MustCompile(`(?i)^window\s*\(\s*(\S+)\s*\)$`)
+)
+
+var aggregateFunctions = []string{"count", "sum", "mean", "avg", "min", "max", "first", "last"}
+
+// parsedStatement is the syntactic representation of a statement. The
+// expressions are kept as text and compiled later.
+type parsedStatement struct {
+	items   []parsedItem
+	sources []source
+	join    bool
+	where   string
+	groupBy []string
+	window  time.Duration
+}
+
+type parsedItem struct {
+	function   string
+	expression string
+	alias      string
+}
+
+type source struct {
+	measurement string
+	alias       string
+}
+
+// parse splits the statement into its clauses of the form
+//
+//	SELECT <items> FROM <source> [JOIN <source>] [WHERE <expr>] [GROUP BY <tags>, window(<duration>)]
+//
+// with the keywords being case-insensitive.
+func parse(statement string) (*parsedStatement, error) {
+	clauses, err := splitClauses(statement)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &parsedStatement{where: clauses["WHERE"]}
+
+	if stmt.items, err = parseItems(clauses["SELECT"]); err != nil {
+		return nil, err
+	}
+	if stmt.sources, stmt.join, err = parseSources(clauses["FROM"]); err != nil {
+		return nil, err
+	}
+	if group, found := clauses["GROUP BY"]; found {
+		if stmt.groupBy, stmt.window, err = parseGroupBy(group); err != nil {
+			return nil, err
+		}
+	}
+
+	return stmt, nil
+}
+
+// splitClauses returns the text of the clauses by keyword
+func splitClauses(statement string) (map[string]string, error) {
+	keywords := []string{"SELECT", "FROM", "WHERE", "GROUP BY"}
+
+	type position struct {
+		keyword    string
+		start, end int
+	}
+	var positions []position
+	for _, kw := range keywords {
+		for _, p := range findKeyword(statement, kw) {
+			positions = append(positions, position{kw, p[0], p[1]})
+		}
+	}
+
+	// The keywords have to appear at most once and in order
+	sort.Slice(positions, func(i, j int) bool { return positions[i].start < positions[j].start })
+	last := -1
+	for _, p := range positions {
+		idx := indexOf(keywords, p.keyword)
+		if idx <= last {
+			return nil, fmt.Errorf("unexpected keyword %q", p.keyword)
+		}
+		last = idx
+	}
+	if len(positions) < 2 || positions[0].keyword != "SELECT" || positions[1].keyword != "FROM" {
+		return nil, errors.New("statement must start with SELECT followed by FROM")
+	}
+	if strings.TrimSpace(statement[:positions[0].start]) != "" {
+		return nil, errors.New("statement must start with SELECT")
+	}
+
+	clauses := make(map[string]string, len(positions))
+	for i, p := range positions {
+		end := len(statement)
+		if i+1 < len(positions) {
+			end = positions[i+1].start
+		}
+		text := strings.TrimSpace(statement[p.end:end])
+		if text == "" {
+			return nil, fmt.Errorf("empty %s clause", p.keyword)
+		}
+		clauses[p.keyword] = text
+	}
+	return clauses, nil
+}
+
+func parseItems(clause string) ([]parsedItem, error) {
+	parts := splitTopLevel(clause, ',')
+	items := make([]parsedItem, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, errors.New("empty item in SELECT clause")
+		}
+
+		var item parsedItem
+		if matches := findKeyword(part, "AS"); len(matches) > 0 {
+			p := matches[len(matches)-1]
+			item.alias = strings.TrimSpace(part[p[1]:])
+			part = strings.TrimSpace(part[:p[0]])
+			if !identifierRe.MatchString(item.alias) {
+				return nil, fmt.Errorf("invalid alias %q", item.alias)
+			}
+		}
+
+		item.function, item.expression = splitFunction(part)
+		if item.alias == "" && item.expression == "*" && item.function != "" {
+			item.alias = item.function
+		}
+		if item.alias == "" {
+			// Use the last element of simple paths like "fields.value" as name
+			if !pathRe.MatchString(item.expression) {
+				return nil, fmt.Errorf("item %q requires an alias", part)
+			}
+			elements := strings.Split(item.expression, ".")
+			item.alias = elements[len(elements)-1]
+			if item.function != "" {
+				item.alias += "_" + item.function
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// splitFunction separates the aggregation function from the expression if the
+// item is an aggregation, e.g. "max(fields.value)"
+func splitFunction(item string) (function, expression string) {
+	open := strings.IndexRune(item, '(')
+	if open < 0 || !strings.HasSuffix(item, ")") {
+		return "", item
+	}
+	name := strings.ToLower(strings.TrimSpace(item[:open]))
+	if indexOf(aggregateFunctions, name) < 0 {
+		return "", item
+	}
+
+	// Make sure the closing parenthesis belongs to the function call to not
+	// treat expressions like "max(a) + min(b)" as aggregation
+	inner := item[open+1 : len(item)-1]
+	if parts := splitTopLevel(inner, ')'); len(parts) > 1 {
+		return "", item
+	}
+	if name == "avg" {
+		name = "mean"
+	}
+	return name, strings.TrimSpace(inner)
+}
+
+func parseSources(clause string) ([]source, bool, error) {
+	var parts []string
+	var join bool
+	if matches := findKeyword(clause, "JOIN"); len(matches) > 0 {
+		if len(matches) > 1 {
+			return nil, false, errors.New("only one JOIN is supported")
+		}
+		join = true
+		parts = []string{clause[:matches[0][0]], clause[matches[0][1]:]}
+	} else {
+		parts = splitTopLevel(clause, ',')
+	}
+
+	sources := make([]source, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		var src source
+		if matches := findKeyword(part, "AS"); len(matches) == 1 {
+			src.alias = strings.TrimSpace(part[matches[0][1]:])
+			part = strings.TrimSpace(part[:matches[0][0]])
+			if !identifierRe.MatchString(src.alias) {
+				return nil, false, fmt.Errorf("invalid alias %q", src.alias)
+			}
+		}
+		src.measurement = unquote(part)
+		if src.measurement == "" {
+			return nil, false, errors.New("empty measurement in FROM clause")
+		}
+		if src.alias == "" && identifierRe.MatchString(src.measurement) {
+			src.alias = src.measurement
+		}
+		sources = append(sources, src)
+	}
+	return sources, join, nil
+}
+
+func parseGroupBy(clause string) ([]string, time.Duration, error) {
+	var tags []string
+	var window time.Duration
+	for _, part := range splitTopLevel(clause, ',') {
+		part = strings.TrimSpace(part)
+		if m := windowRe.FindStringSubmatch(part); m != nil {
+			if window > 0 {
+				return nil, 0, errors.New("only one window is supported")
+			}
+			d, err := time.ParseDuration(unquote(m[1]))
+			if err != nil {
+				return nil, 0, fmt.Errorf("invalid window: %w", err)
+			}
+			if d <= 0 {
+				return nil, 0, fmt.Errorf("invalid window %q", m[1])
+			}
+			window = d
+			continue
+		}
+		tag := unquote(strings.TrimPrefix(part, "tags."))
+		if tag == "" {
+			return nil, 0, errors.New("empty item in GROUP BY clause")
+		}
+		tags = append(tags, tag)
+	}
+	return tags, window, nil
+}
+
+// findKeyword returns the start and end positions of the keyword outside of
+// quotes and brackets. Whitespace within the keyword matches any whitespace.
+func findKeyword(s, keyword string) [][2]int {
+	words := strings.Fields(keyword)
+	var matches [][2]int
+	scan(s, func(i int) {
+		pos := i
+		for n, word := range words {
+			if n > 0 {
+				start := pos
+				for pos < len(s) && unicode.IsSpace(rune(s[pos])) {
+					pos++
+				}
+				if pos == start {
+					return
+				}
+			}
+			if pos+len(word) > len(s) || !strings.EqualFold(s[pos:pos+len(word)], word) {
+				return
+			}
+			pos += len(word)
+		}
+		// Require word boundaries
+		if i > 0 && isWordChar(s[i-1]) || pos < len(s) && isWordChar(s[pos]) {
+			return
+		}
+		matches = append(matches, [2]int{i, pos})
+	})
+	return matches
+}
+
+// splitTopLevel splits the string at the separator outside of quotes and
+// brackets
+func splitTopLevel(s string, sep byte) []string {
+	var parts []string
+	last := 0
+	scan(s, func(i int) {
+		if s[i] == sep {
+			parts = append(parts, s[last:i])
+			last = i + 1
+		}
+	})
+	return append(parts, s[last:])
+}
+
+// scan calls the function for each position outside of quotes and brackets
+func scan(s string, f func(i int)) {
+	var depth int
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			quote = c
+			continue
+		case '(', '[', '{':
+			depth++
+			continue
+		case ')', ']', '}':
+			depth--
+			if depth < 0 {
+				// Report unbalanced closing brackets to allow detecting them
+				f(i)
+				depth = 0
+			}
+			continue
+		}
+		if depth == 0 {
+			f(i)
+		}
+	}
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'' || s[0] == '`') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func indexOf(list []string, value string) int {
+	for i, v := range list {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
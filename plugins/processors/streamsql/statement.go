@@ -0,0 +1,359 @@
+package streamsql
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/ext"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// statement is the compiled form of a query
+type statement struct {
+	name         string
+	sources      []source
+	join         bool
+	where        cel.Program
+	items        []item
+	groupBy      []string
+	window       time.Duration
+	dropOriginal bool
+
+	// Groups of the open windows by start time and group key
+	windows map[int64]map[string]*group
+}
+
+type item struct {
+	alias    string
+	function string
+	program  cel.Program // nil for "count(*)"
+}
+
+type group struct {
+	tags       map[string]string
+	seen       map[string]bool
+	aggregates []aggregate
+}
+
+type aggregate struct {
+	count     int64
+	sum       float64
+	min       float64
+	max       float64
+	first     interface{}
+	firstTime time.Time
+	last      interface{}
+	lastTime  time.Time
+}
+
+func compile(text string) (*statement, error) {
+	parsed, err := parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	stmt := &statement{
+		sources: parsed.sources,
+		join:    parsed.join,
+		groupBy: parsed.groupBy,
+		window:  parsed.window,
+		windows: make(map[int64]map[string]*group),
+	}
+	if stmt.join && stmt.window == 0 {
+		return nil, errors.New("JOIN requires a window in the GROUP BY clause")
+	}
+	if len(stmt.groupBy) > 0 && stmt.window == 0 {
+		return nil, errors.New("GROUP BY requires a window")
+	}
+
+	// Declare the variables of the expressions, the aliases of the sources
+	// contain the fields of the metric if it belongs to that source
+	declarations := []*exprpb.Decl{
+		decls.NewVar("name", decls.String),
+		decls.NewVar("tags", decls.NewMapType(decls.String, decls.String)),
+		decls.NewVar("fields", decls.NewMapType(decls.String, decls.Dyn)),
+		decls.NewVar("time", decls.Timestamp),
+	}
+	aliases := make(map[string]bool)
+	for _, src := range stmt.sources {
+		if src.alias == "" {
+			continue
+		}
+		switch src.alias {
+		case "name", "tags", "fields", "time":
+			return nil, fmt.Errorf("alias %q of %q is reserved", src.alias, src.measurement)
+		}
+		if aliases[src.alias] {
+			return nil, fmt.Errorf("duplicate source alias %q", src.alias)
+		}
+		aliases[src.alias] = true
+		declarations = append(declarations, decls.NewVar(src.alias, decls.NewMapType(decls.String, decls.Dyn)))
+	}
+	env, err := cel.NewEnv(
+		cel.Declarations(declarations...),
+		ext.Encoders(),
+		ext.Math(),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("creating environment failed: %w", err)
+	}
+
+	if parsed.where != "" {
+		ast, issues := env.Compile(parsed.where)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("compiling WHERE clause failed: %w", issues.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, errors.New("WHERE clause needs to return a boolean")
+		}
+		if stmt.where, err = env.Program(ast, cel.EvalOptions(cel.OptOptimize)); err != nil {
+			return nil, fmt.Errorf("creating program for WHERE clause failed: %w", err)
+		}
+	}
+
+	names := make(map[string]bool, len(parsed.items))
+	for _, pi := range parsed.items {
+		if names[pi.alias] {
+			return nil, fmt.Errorf("duplicate name %q in SELECT clause", pi.alias)
+		}
+		names[pi.alias] = true
+
+		if stmt.window == 0 && pi.function != "" {
+			return nil, fmt.Errorf("aggregation %q requires a window in the GROUP BY clause", pi.alias)
+		}
+		if stmt.window > 0 && pi.function == "" {
+			return nil, fmt.Errorf("%q requires an aggregation function in windowed queries", pi.alias)
+		}
+
+		it := item{alias: pi.alias, function: pi.function}
+		if pi.expression == "*" {
+			if pi.function != "count" {
+				return nil, fmt.Errorf("'*' is only supported for count in %q", pi.alias)
+			}
+			stmt.items = append(stmt.items, it)
+			continue
+		}
+		ast, issues := env.Compile(pi.expression)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("compiling %q failed: %w", pi.alias, issues.Err())
+		}
+		if it.program, err = env.Program(ast, cel.EvalOptions(cel.OptOptimize)); err != nil {
+			return nil, fmt.Errorf("creating program for %q failed: %w", pi.alias, err)
+		}
+		stmt.items = append(stmt.items, it)
+	}
+
+	return stmt, nil
+}
+
+// source returns the source of the statement for the given measurement
+func (stmt *statement) source(measurement string) *source {
+	for i, src := range stmt.sources {
+		if src.measurement == measurement {
+			return &stmt.sources[i]
+		}
+	}
+	return nil
+}
+
+// variables adds the source aliases to the metric's variables
+func (stmt *statement) variables(env map[string]interface{}, src *source) map[string]interface{} {
+	vars := make(map[string]interface{}, len(env)+len(stmt.sources))
+	for k, v := range env {
+		vars[k] = v
+	}
+	for _, s := range stmt.sources {
+		if s.alias == "" {
+			continue
+		}
+		if s.alias == src.alias {
+			vars[s.alias] = env["fields"]
+		} else {
+			vars[s.alias] = map[string]interface{}{}
+		}
+	}
+	return vars
+}
+
+func (stmt *statement) match(vars map[string]interface{}) (bool, error) {
+	result, _, err := stmt.where.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	matched, ok := result.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("invalid result type %T", result.Value())
+	}
+	return matched, nil
+}
+
+// evaluate returns the value of the item's expression, the value is nil if
+// the expression does not apply to the metric, e.g. due to missing fields
+func (it *item) evaluate(vars map[string]interface{}) interface{} {
+	result, _, err := it.program.Eval(vars)
+	if err != nil {
+		return nil
+	}
+	switch v := result.Value().(type) {
+	case int64, uint64, float64, string, bool:
+		return v
+	case time.Time:
+		return v.UnixNano()
+	case time.Duration:
+		return int64(v)
+	}
+	return nil
+}
+
+// project computes the fields of statements without window
+func (stmt *statement) project(vars map[string]interface{}) map[string]interface{} {
+	fields := make(map[string]interface{}, len(stmt.items))
+	for i := range stmt.items {
+		if v := stmt.items[i].evaluate(vars); v != nil {
+			fields[stmt.items[i].alias] = v
+		}
+	}
+	return fields
+}
+
+// add updates the aggregations of the metric's group in the given window
+func (stmt *statement) add(start time.Time, m telegraf.Metric, src *source, vars map[string]interface{}) {
+	groups, found := stmt.windows[start.UnixNano()]
+	if !found {
+		groups = make(map[string]*group)
+		stmt.windows[start.UnixNano()] = groups
+	}
+
+	key := groupKey(m, stmt.groupBy)
+	g, found := groups[key]
+	if !found {
+		g = &group{
+			tags:       make(map[string]string, len(stmt.groupBy)),
+			seen:       make(map[string]bool, len(stmt.sources)),
+			aggregates: make([]aggregate, len(stmt.items)),
+		}
+		for _, tag := range stmt.groupBy {
+			if v, ok := m.GetTag(tag); ok {
+				g.tags[tag] = v
+			}
+		}
+		groups[key] = g
+	}
+	g.seen[src.measurement] = true
+
+	for i := range stmt.items {
+		it := &stmt.items[i]
+		agg := &g.aggregates[i]
+		if it.program == nil {
+			agg.count++
+			continue
+		}
+		v := it.evaluate(vars)
+		if v == nil {
+			continue
+		}
+		if it.function == "first" || it.function == "last" {
+			if agg.count == 0 || m.Time().Before(agg.firstTime) {
+				agg.first, agg.firstTime = v, m.Time()
+			}
+			if agg.count == 0 || !m.Time().Before(agg.lastTime) {
+				agg.last, agg.lastTime = v, m.Time()
+			}
+			agg.count++
+			continue
+		}
+		if it.function == "count" {
+			agg.count++
+			continue
+		}
+
+		value, ok := toFloat(v)
+		if !ok {
+			continue
+		}
+		if agg.count == 0 || value < agg.min {
+			agg.min = value
+		}
+		if agg.count == 0 || value > agg.max {
+			agg.max = value
+		}
+		agg.sum += value
+		agg.count++
+	}
+}
+
+// results creates the metrics of all groups of the window
+func (stmt *statement) results(start time.Time) []telegraf.Metric {
+	groups := stmt.windows[start.UnixNano()]
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	metrics := make([]telegraf.Metric, 0, len(groups))
+	for _, k := range keys {
+		g := groups[k]
+
+		// Inner join, all sources have to be present in the group
+		if stmt.join && len(g.seen) < len(stmt.sources) {
+			continue
+		}
+
+		fields := make(map[string]interface{}, len(stmt.items))
+		for i, it := range stmt.items {
+			agg := g.aggregates[i]
+			if it.function == "count" {
+				fields[it.alias] = agg.count
+				continue
+			}
+			if agg.count == 0 {
+				continue
+			}
+			switch it.function {
+			case "sum":
+				fields[it.alias] = agg.sum
+			case "mean":
+				fields[it.alias] = agg.sum / float64(agg.count)
+			case "min":
+				fields[it.alias] = agg.min
+			case "max":
+				fields[it.alias] = agg.max
+			case "first":
+				fields[it.alias] = agg.first
+			case "last":
+				fields[it.alias] = agg.last
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		metrics = append(metrics, metric.New(stmt.name, g.tags, fields, start))
+	}
+	return metrics
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch value := v.(type) {
+	case int64:
+		return float64(value), true
+	case uint64:
+		return float64(value), true
+	case float64:
+		return value, true
+	case bool:
+		if value {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,224 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package streamsql
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+type Query struct {
+	Name         string `toml:"name"`
+	Statement    string `toml:"statement"`
+	DropOriginal bool   `toml:"drop_original"`
+}
+
+type StreamSQL struct {
+	Queries []Query         `toml:"query"`
+	Delay   config.Duration `toml:"delay"`
+	Log     telegraf.Logger `toml:"-"`
+
+	statements []*statement
+	acc        telegraf.Accumulator
+	done       chan struct{}
+	wg         sync.WaitGroup
+	sync.Mutex
+}
+
+func (*StreamSQL) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *StreamSQL) Init() error {
+	if len(s.Queries) == 0 {
+		return errors.New("no queries specified")
+	}
+	if s.Delay < 0 {
+		return errors.New("'delay' must not be negative")
+	}
+
+	s.statements = make([]*statement, 0, len(s.Queries))
+	for i, q := range s.Queries {
+		if q.Statement == "" {
+			return fmt.Errorf("query %d: empty statement", i+1)
+		}
+		if q.Name == "" {
+			q.Name = "streamsql"
+		}
+		stmt, err := compile(q.Statement)
+		if err != nil {
+			return fmt.Errorf("query %d: %w", i+1, err)
+		}
+		stmt.name = q.Name
+		stmt.dropOriginal = q.DropOriginal
+		s.statements = append(s.statements, stmt)
+	}
+
+	return nil
+}
+
+func (s *StreamSQL) Start(acc telegraf.Accumulator) error {
+	s.acc = acc
+
+	// Check for complete windows in fractions of the shortest window to
+	// emit the results shortly after the window is complete
+	var tick time.Duration
+	for _, stmt := range s.statements {
+		if stmt.window > 0 && (tick == 0 || stmt.window < tick) {
+			tick = stmt.window
+		}
+	}
+	if tick == 0 {
+		return nil
+	}
+	tick /= 10
+	if tick < 100*time.Millisecond {
+		tick = 100 * time.Millisecond
+	}
+
+	s.done = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case now := <-ticker.C:
+				s.flush(now, false)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (s *StreamSQL) Add(m telegraf.Metric, acc telegraf.Accumulator) error {
+	now := time.Now()
+
+	var drop bool
+	var env map[string]interface{}
+	for _, stmt := range s.statements {
+		src := stmt.source(m.Name())
+		if src == nil {
+			continue
+		}
+		if env == nil {
+			env = map[string]interface{}{
+				"name":   m.Name(),
+				"tags":   m.Tags(),
+				"fields": m.Fields(),
+				"time":   m.Time(),
+			}
+		}
+		vars := stmt.variables(env, src)
+
+		if stmt.where != nil {
+			matched, err := stmt.match(vars)
+			if err != nil {
+				s.Log.Debugf("Evaluating WHERE clause of %q for %q failed: %v", stmt.name, m.Name(), err)
+				continue
+			}
+			if !matched {
+				continue
+			}
+		}
+		drop = drop || stmt.dropOriginal
+
+		// Statements without window directly produce a new metric
+		if stmt.window == 0 {
+			if fields := stmt.project(vars); len(fields) > 0 {
+				acc.AddMetric(metric.New(stmt.name, m.Tags(), fields, m.Time()))
+			}
+			continue
+		}
+
+		// Ignore metrics for windows already emitted
+		start := m.Time().Truncate(stmt.window)
+		if !start.Add(stmt.window + time.Duration(s.Delay)).After(now) {
+			s.Log.Debugf("Ignoring late metric %q for window starting at %v", m.Name(), start)
+			continue
+		}
+		s.Lock()
+		stmt.add(start, m, src, vars)
+		s.Unlock()
+	}
+
+	if drop {
+		m.Drop()
+		return nil
+	}
+	acc.AddMetric(m)
+	return nil
+}
+
+func (s *StreamSQL) Stop() {
+	if s.done != nil {
+		close(s.done)
+	}
+	s.wg.Wait()
+
+	// Emit the incomplete windows to not lose data
+	s.flush(time.Now(), true)
+}
+
+// flush emits the results of all complete windows or of all windows if
+// requested
+func (s *StreamSQL) flush(now time.Time, all bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, stmt := range s.statements {
+		if stmt.window == 0 {
+			continue
+		}
+
+		starts := make([]time.Time, 0, len(stmt.windows))
+		for start := range stmt.windows {
+			t := time.Unix(0, start)
+			if all || !t.Add(stmt.window+time.Duration(s.Delay)).After(now) {
+				starts = append(starts, t)
+			}
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+
+		for _, start := range starts {
+			for _, m := range stmt.results(start) {
+				s.acc.AddMetric(m)
+			}
+			delete(stmt.windows, start.UnixNano())
+		}
+	}
+}
+
+// groupKey builds a unique key of the values of the given tags
+func groupKey(m telegraf.Metric, tags []string) string {
+	var b strings.Builder
+	for _, tag := range tags {
+		v, _ := m.GetTag(tag)
+		b.WriteString(v)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func init() {
+	processors.AddStreaming("streamsql", func() telegraf.StreamingProcessor {
+		return &StreamSQL{}
+	})
+}
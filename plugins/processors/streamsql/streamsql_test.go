@@ -0,0 +1,300 @@
+package streamsql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestParse(t *testing.T) {
+	parsed, err := parse(`SELECT max(fields.value), fields.a + fields.b AS total ` +
+		`from "disk-io" AS d join net WHERE tags.host == "a" group by tags.host, window(10s)`)
+	require.NoError(t, err)
+
+	expected := &parsedStatement{
+		items: []parsedItem{
+			{function: "max", expression: "fields.value", alias: "value_max"},
+			{expression: "fields.a + fields.b", alias: "total"},
+		},
+		sources: []source{
+			{measurement: "disk-io", alias: "d"},
+			{measurement: "net", alias: "net"},
+		},
+		join:    true,
+		where:   `tags.host == "a"`,
+		groupBy: []string{"host"},
+		window:  10 * time.Second,
+	}
+	require.Equal(t, expected, parsed)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  string
+	}{
+		{
+			name:      "missing from",
+			statement: "SELECT fields.value",
+			expected:  "statement must start with SELECT followed by FROM",
+		},
+		{
+			name:      "wrong order",
+			statement: "SELECT fields.value FROM cpu GROUP BY window(1s) WHERE true",
+			expected:  `unexpected keyword "WHERE"`,
+		},
+		{
+			name:      "missing alias",
+			statement: "SELECT fields.a + fields.b FROM cpu",
+			expected:  "requires an alias",
+		},
+		{
+			name:      "invalid window",
+			statement: "SELECT count(*) FROM cpu GROUP BY window(foo)",
+			expected:  "invalid window",
+		},
+		{
+			name:      "empty clause",
+			statement: "SELECT fields.value FROM cpu WHERE",
+			expected:  "empty WHERE clause",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(tt.statement)
+			require.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		statement string
+		expected  string
+	}{
+		{
+			name:      "aggregation without window",
+			statement: "SELECT max(fields.value) FROM cpu",
+			expected:  "requires a window",
+		},
+		{
+			name:      "scalar with window",
+			statement: "SELECT fields.value FROM cpu GROUP BY window(1s)",
+			expected:  "requires an aggregation function",
+		},
+		{
+			name:      "join without window",
+			statement: "SELECT fields.value FROM cpu JOIN mem",
+			expected:  "JOIN requires a window",
+		},
+		{
+			name:      "group without window",
+			statement: "SELECT fields.value FROM cpu GROUP BY tags.host",
+			expected:  "GROUP BY requires a window",
+		},
+		{
+			name:      "non-boolean condition",
+			statement: "SELECT fields.value FROM cpu WHERE name",
+			expected:  "needs to return a boolean",
+		},
+		{
+			name:      "invalid expression",
+			statement: "SELECT fields.value + AS x FROM cpu",
+			expected:  `compiling "x" failed`,
+		},
+		{
+			name:      "reserved alias",
+			statement: "SELECT fields.value FROM cpu AS tags",
+			expected:  "is reserved",
+		},
+		{
+			name:      "duplicate name",
+			statement: "SELECT fields.value, tags.value FROM cpu",
+			expected:  "duplicate name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &StreamSQL{
+				Queries: []Query{{Statement: tt.statement}},
+				Log:     testutil.Logger{},
+			}
+			require.ErrorContains(t, plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestProjection(t *testing.T) {
+	plugin := &StreamSQL{
+		Queries: []Query{
+			{
+				Name:      "mem_usage",
+				Statement: `SELECT fields.used / fields.total * 100.0 AS used_percent, fields.total FROM mem WHERE tags.host != "b"`,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	now := time.Now()
+	input := []telegraf.Metric{
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"used": 25.0, "total": 100.0}, now),
+		metric.New("mem", map[string]string{"host": "b"}, map[string]interface{}{"used": 50.0, "total": 100.0}, now),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"usage": 1.0}, now),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("mem_usage", map[string]string{"host": "a"}, map[string]interface{}{"used_percent": 25.0, "total": 100.0}, now),
+		input[0],
+		input[1],
+		input[2],
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestWindowAggregation(t *testing.T) {
+	plugin := &StreamSQL{
+		Queries: []Query{
+			{
+				Statement: `SELECT count(*) AS n, sum(fields.value), mean(fields.value) AS avg, min(fields.value), ` +
+					`max(fields.value), first(fields.value), last(fields.value) FROM cpu GROUP BY tags.host, window(1h)`,
+				DropOriginal: true,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	start := time.Now().Truncate(time.Hour)
+	input := []telegraf.Metric{
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "0"}, map[string]interface{}{"value": int64(3)}, start.Add(2*time.Second)),
+		metric.New("cpu", map[string]string{"host": "a", "cpu": "1"}, map[string]interface{}{"value": int64(1)}, start),
+		metric.New("cpu", map[string]string{"host": "b"}, map[string]interface{}{"value": int64(5)}, start.Add(time.Second)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(2)}, start.Add(time.Second)),
+		metric.New("cpu", map[string]string{"host": "a"}, map[string]interface{}{"other": int64(2)}, start.Add(time.Second)),
+		metric.New("mem", map[string]string{"host": "a"}, map[string]interface{}{"value": int64(7)}, start),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+
+	// Only the non-matching metric passes as the original metrics are dropped
+	// and the window is not complete yet
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input[5]}, acc.GetTelegrafMetrics())
+	acc.ClearMetrics()
+
+	// Emit the incomplete window on stop
+	plugin.Stop()
+
+	expected := []telegraf.Metric{
+		metric.New("streamsql", map[string]string{"host": "a"}, map[string]interface{}{
+			"n":           int64(4),
+			"value_sum":   6.0,
+			"avg":         2.0,
+			"value_min":   1.0,
+			"value_max":   3.0,
+			"value_first": int64(1),
+			"value_last":  int64(3),
+		}, start),
+		metric.New("streamsql", map[string]string{"host": "b"}, map[string]interface{}{
+			"n":           int64(1),
+			"value_sum":   5.0,
+			"avg":         5.0,
+			"value_min":   5.0,
+			"value_max":   5.0,
+			"value_first": int64(5),
+			"value_last":  int64(5),
+		}, start),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
+
+func TestWindowFlush(t *testing.T) {
+	plugin := &StreamSQL{
+		Queries: []Query{{Statement: "SELECT count(*) AS n FROM cpu GROUP BY window(1h)"}},
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	plugin.acc = &acc
+
+	start := time.Now().Truncate(time.Hour)
+	m := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, start)
+	require.NoError(t, plugin.Add(m, &acc))
+	acc.ClearMetrics()
+
+	// The window is not complete yet
+	plugin.flush(start.Add(30*time.Minute), false)
+	require.Empty(t, acc.GetTelegrafMetrics())
+
+	// After the window the result is emitted exactly once
+	plugin.flush(start.Add(time.Hour), false)
+	expected := []telegraf.Metric{
+		metric.New("streamsql", map[string]string{}, map[string]interface{}{"n": int64(1)}, start),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+	plugin.flush(start.Add(2*time.Hour), true)
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// Metrics for past windows are ignored
+	acc.ClearMetrics()
+	late := metric.New("cpu", map[string]string{}, map[string]interface{}{"value": 1}, start.Add(-time.Hour))
+	require.NoError(t, plugin.Add(late, &acc))
+	plugin.flush(start.Add(2*time.Hour), true)
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{late}, acc.GetTelegrafMetrics())
+}
+
+func TestJoin(t *testing.T) {
+	plugin := &StreamSQL{
+		Queries: []Query{
+			{
+				Name: "http",
+				Statement: `SELECT sum(req.count) AS requests, sum(err.count) AS errors
+				            FROM http_requests AS req JOIN http_errors AS err
+				            GROUP BY tags.host, window(1h)`,
+				DropOriginal: true,
+			},
+		},
+		Log: testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+
+	start := time.Now().Truncate(time.Hour)
+	input := []telegraf.Metric{
+		metric.New("http_requests", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(10)}, start),
+		metric.New("http_requests", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(20)}, start),
+		metric.New("http_errors", map[string]string{"host": "a"}, map[string]interface{}{"count": int64(2)}, start),
+		metric.New("http_requests", map[string]string{"host": "b"}, map[string]interface{}{"count": int64(5)}, start),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	plugin.Stop()
+
+	// Host "b" has no errors and is thus not part of the inner join
+	expected := []telegraf.Metric{
+		metric.New("http", map[string]string{"host": "a"}, map[string]interface{}{"requests": 30.0, "errors": 2.0}, start),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+}
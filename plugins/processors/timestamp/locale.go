@@ -0,0 +1,119 @@
+package timestamp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var englishMonths = []string{
+	"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December",
+}
+
+var englishDays = []string{
+	"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday",
+}
+
+// locale holds the localized month names, their abbreviations and the day
+// names starting with Monday
+type locale struct {
+	months      []string
+	monthsShort []string
+	days        []string
+}
+
+var locales = map[string]locale{
+	"de": {
+		months: []string{
+			"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember",
+		},
+		monthsShort: []string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		days:        []string{"Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag", "Sonntag"},
+	},
+	"es": {
+		months: []string{
+			"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre",
+		},
+		monthsShort: []string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+		days:        []string{"lunes", "martes", "miércoles", "jueves", "viernes", "sábado", "domingo"},
+	},
+	"fr": {
+		months: []string{
+			"janvier", "février", "mars", "avril", "mai", "juin",
+			"juillet", "août", "septembre", "octobre", "novembre", "décembre",
+		},
+		monthsShort: []string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		days:        []string{"lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi", "dimanche"},
+	},
+	"it": {
+		months: []string{
+			"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno",
+			"luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre",
+		},
+		monthsShort: []string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		days:        []string{"lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato", "domenica"},
+	},
+	"nl": {
+		months: []string{
+			"januari", "februari", "maart", "april", "mei", "juni",
+			"juli", "augustus", "september", "oktober", "november", "december",
+		},
+		monthsShort: []string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		days:        []string{"maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag", "zondag"},
+	},
+	"pt": {
+		months: []string{
+			"janeiro", "fevereiro", "março", "abril", "maio", "junho",
+			"julho", "agosto", "setembro", "outubro", "novembro", "dezembro",
+		},
+		monthsShort: []string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		days:        []string{"segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado", "domingo"},
+	},
+}
+
+// newTranslator creates a replacer translating the month and day names of
+// the given locale to English as only those are understood by the Go time
+// parser
+func newTranslator(name string) (*strings.Replacer, error) {
+	l, found := locales[strings.ToLower(name)]
+	if !found {
+		supported := make([]string, 0, len(locales))
+		for k := range locales {
+			supported = append(supported, k)
+		}
+		sort.Strings(supported)
+		return nil, fmt.Errorf("unsupported locale %q, supported are %s", name, strings.Join(supported, ", "))
+	}
+
+	// The replacer tests the names in the given order at each position, so
+	// full names must go first to not only replace a prefix of them
+	var pairs []string
+	add := func(localized, english string) {
+		pairs = append(pairs, localized, english)
+		if capitalized := capitalize(localized); capitalized != localized {
+			pairs = append(pairs, capitalized, english)
+		}
+	}
+	for i, name := range l.days {
+		add(name, englishDays[i])
+	}
+	for i, name := range l.months {
+		add(name, englishMonths[i])
+	}
+	for i, name := range l.monthsShort {
+		add(name, englishMonths[i][:3])
+	}
+	return strings.NewReplacer(pairs...), nil
+}
+
+func capitalize(s string) string {
+	for i := range s {
+		if i > 0 {
+			return strings.ToUpper(s[:i]) + s[i:]
+		}
+	}
+	return strings.ToUpper(s)
+}
@@ -0,0 +1,275 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package timestamp
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/choice"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// autoLayouts are tried in order for the "auto" format
+var autoLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006/01/02 15:04:05.999999999",
+	"02/Jan/2006:15:04:05 -0700",
+	"02.01.2006 15:04:05",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC850,
+	time.RFC822Z,
+	time.RFC822,
+	time.RubyDate,
+	time.UnixDate,
+	time.ANSIC,
+	"Monday, January 2, 2006 15:04:05",
+	"January 2, 2006 15:04:05",
+	"2 January 2006 15:04:05",
+	"2. January 2006 15:04:05",
+	"2 Jan 2006 15:04:05",
+	"Jan _2 2006 15:04:05",
+	time.Stamp,
+	"2006-01-02",
+}
+
+type Timestamp struct {
+	SourceField    string          `toml:"source_timestamp_field"`
+	SourceTag      string          `toml:"source_timestamp_tag"`
+	SourceFormats  []string        `toml:"source_timestamp_format"`
+	SourceTimezone string          `toml:"source_timestamp_timezone"`
+	SourceLocale   string          `toml:"source_timestamp_locale"`
+	RemoveSource   bool            `toml:"remove_source"`
+	MaxFuture      config.Duration `toml:"max_future"`
+	MaxPast        config.Duration `toml:"max_past"`
+	OutlierAction  string          `toml:"outlier_action"`
+	CorrectionTag  string          `toml:"correction_tag"`
+	Log            telegraf.Logger `toml:"-"`
+
+	location   *time.Location
+	translator *strings.Replacer
+	now        func() time.Time
+}
+
+func (*Timestamp) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *Timestamp) Init() error {
+	if t.SourceField != "" && t.SourceTag != "" {
+		return errors.New("only one of 'source_timestamp_field' or 'source_timestamp_tag' can be specified")
+	} else if t.SourceField == "" && t.SourceTag == "" {
+		return errors.New("one of 'source_timestamp_field' or 'source_timestamp_tag' must be specified")
+	}
+
+	if len(t.SourceFormats) == 0 {
+		t.SourceFormats = []string{"auto"}
+	}
+
+	var err error
+	t.location, err = time.LoadLocation(t.SourceTimezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+
+	if t.SourceLocale != "" {
+		t.translator, err = newTranslator(t.SourceLocale)
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.OutlierAction == "" {
+		t.OutlierAction = "clamp"
+	}
+	if err := choice.Check(t.OutlierAction, []string{"clamp", "keep", "drop"}); err != nil {
+		return fmt.Errorf("invalid 'outlier_action': %w", err)
+	}
+	if t.MaxFuture < 0 || t.MaxPast < 0 {
+		return errors.New("'max_future' and 'max_past' must not be negative")
+	}
+
+	if t.now == nil {
+		t.now = time.Now
+	}
+
+	return nil
+}
+
+func (t *Timestamp) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		var raw interface{}
+		var found bool
+		if t.SourceField != "" {
+			raw, found = m.GetField(t.SourceField)
+		} else {
+			raw, found = m.GetTag(t.SourceTag)
+		}
+		if !found {
+			out = append(out, m)
+			continue
+		}
+
+		ts, err := t.parse(raw)
+		if err != nil {
+			t.Log.Debugf("Cannot parse timestamp %v of metric %q: %v", raw, m.Name(), err)
+			t.addCorrection(m, "unparsable")
+			out = append(out, m)
+			continue
+		}
+
+		// Handle outliers due to wrong device clocks
+		now := t.now()
+		var correction string
+		var bound time.Time
+		if t.MaxFuture > 0 && ts.After(now.Add(time.Duration(t.MaxFuture))) {
+			correction, bound = "future", now.Add(time.Duration(t.MaxFuture))
+		} else if t.MaxPast > 0 && ts.Before(now.Add(-time.Duration(t.MaxPast))) {
+			correction, bound = "past", now.Add(-time.Duration(t.MaxPast))
+		}
+		if correction != "" {
+			switch t.OutlierAction {
+			case "clamp":
+				ts = bound
+			case "keep":
+				ts = m.Time()
+			case "drop":
+				t.Log.Debugf("Dropping metric %q with timestamp %v in the %s", m.Name(), ts, correction)
+				m.Drop()
+				continue
+			}
+			t.addCorrection(m, correction)
+		}
+
+		m.SetTime(ts)
+		if t.RemoveSource {
+			if t.SourceField != "" {
+				m.RemoveField(t.SourceField)
+			} else {
+				m.RemoveTag(t.SourceTag)
+			}
+		}
+		out = append(out, m)
+	}
+
+	return out
+}
+
+func (t *Timestamp) addCorrection(m telegraf.Metric, correction string) {
+	if t.CorrectionTag != "" {
+		m.AddTag(t.CorrectionTag, correction)
+	}
+}
+
+// parse tries all configured formats in order and returns the first
+// successfully parsed time
+func (t *Timestamp) parse(raw interface{}) (time.Time, error) {
+	if s, ok := raw.(string); ok {
+		raw = strings.TrimSpace(s)
+		if t.translator != nil {
+			raw = t.translator.Replace(raw.(string))
+		}
+	}
+
+	var errs []error
+	for _, format := range t.SourceFormats {
+		var ts time.Time
+		var err error
+		if format == "auto" {
+			ts, err = t.parseAuto(raw)
+		} else {
+			ts, err = internal.ParseTimestamp(format, raw, t.location)
+		}
+		if err == nil {
+			return ts, nil
+		}
+		errs = append(errs, err)
+	}
+	return time.Time{}, errors.Join(errs...)
+}
+
+// parseAuto detects the precision of unix times and tries the common layouts
+// for time strings
+func (t *Timestamp) parseAuto(raw interface{}) (time.Time, error) {
+	if format, ok := unixFormat(raw); ok {
+		return internal.ParseTimestamp(format, raw, t.location)
+	}
+
+	s, ok := raw.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unsupported type %T", raw)
+	}
+	for _, layout := range autoLayouts {
+		ts, err := time.ParseInLocation(layout, s, t.location)
+		if err != nil {
+			continue
+		}
+		if ts.Year() == 0 {
+			ts = t.guessYear(ts)
+		}
+		return ts, nil
+	}
+	return time.Time{}, fmt.Errorf("no known layout matches %q", s)
+}
+
+// guessYear sets the current year for timestamps without a year as used
+// e.g. by syslog, using the previous year directly after new year
+func (t *Timestamp) guessYear(ts time.Time) time.Time {
+	now := t.now().In(t.location)
+	ts = ts.AddDate(now.Year(), 0, 0)
+	if ts.After(now.Add(24 * time.Hour)) {
+		ts = ts.AddDate(-1, 0, 0)
+	}
+	return ts
+}
+
+// unixFormat determines the precision of a unix timestamp by its magnitude
+func unixFormat(raw interface{}) (string, bool) {
+	var v float64
+	var err error
+	switch r := raw.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		v, err = internal.ToFloat64(r)
+	case string:
+		v, err = strconv.ParseFloat(r, 64)
+	default:
+		return "", false
+	}
+	if err != nil {
+		return "", false
+	}
+
+	if v < 0 {
+		v = -v
+	}
+	switch {
+	case v < 1e11:
+		return "unix", true
+	case v < 1e14:
+		return "unix_ms", true
+	case v < 1e17:
+		return "unix_us", true
+	}
+	return "unix_ns", true
+}
+
+func init() {
+	processors.Add("timestamp", func() telegraf.Processor {
+		return &Timestamp{}
+	})
+}
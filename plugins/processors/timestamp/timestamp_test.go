@@ -0,0 +1,227 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitFail(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *Timestamp
+		expected string
+	}{
+		{
+			name:     "no source",
+			plugin:   &Timestamp{},
+			expected: "must be specified",
+		},
+		{
+			name:     "field and tag",
+			plugin:   &Timestamp{SourceField: "a", SourceTag: "b"},
+			expected: "can be specified",
+		},
+		{
+			name:     "invalid timezone",
+			plugin:   &Timestamp{SourceField: "a", SourceTimezone: "Mars/Olympus_Mons"},
+			expected: "invalid timezone",
+		},
+		{
+			name:     "invalid locale",
+			plugin:   &Timestamp{SourceField: "a", SourceLocale: "tlh"},
+			expected: "unsupported locale",
+		},
+		{
+			name:     "invalid outlier action",
+			plugin:   &Timestamp{SourceField: "a", OutlierAction: "ignore"},
+			expected: "invalid 'outlier_action'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	expected := time.Date(2023, time.March, 1, 10, 15, 30, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		formats  []string
+		timezone string
+		locale   string
+		value    interface{}
+		expected time.Time
+	}{
+		{
+			name:     "rfc3339",
+			value:    "2023-03-01T10:15:30Z",
+			expected: expected,
+		},
+		{
+			name:     "rfc3339 with offset",
+			value:    "2023-03-01T11:15:30+01:00",
+			expected: expected,
+		},
+		{
+			name:     "space separated with timezone",
+			timezone: "Europe/Berlin",
+			value:    "2023-03-01 11:15:30",
+			expected: expected,
+		},
+		{
+			name:     "apache",
+			value:    "01/Mar/2023:05:15:30 -0500",
+			expected: expected,
+		},
+		{
+			name:     "syslog without year",
+			value:    "Mar  1 10:15:30",
+			expected: expected,
+		},
+		{
+			name:     "unix seconds",
+			value:    expected.Unix(),
+			expected: expected,
+		},
+		{
+			name:     "unix milliseconds as string",
+			value:    "1677665730000",
+			expected: expected,
+		},
+		{
+			name:     "unix nanoseconds",
+			value:    expected.UnixNano(),
+			expected: expected,
+		},
+		{
+			name:     "german locale",
+			locale:   "de",
+			value:    "1. März 2023 10:15:30",
+			expected: expected,
+		},
+		{
+			name:     "french locale",
+			locale:   "fr",
+			value:    "mercredi, mars 1, 2023 10:15:30",
+			expected: expected,
+		},
+		{
+			name:     "explicit layouts",
+			formats:  []string{"unix", "2006.01.02 15h04m05s"},
+			value:    "2023.03.01 10h15m30s",
+			expected: expected,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &Timestamp{
+				SourceField:    "time",
+				SourceFormats:  tt.formats,
+				SourceTimezone: tt.timezone,
+				SourceLocale:   tt.locale,
+				Log:            testutil.Logger{},
+				now:            func() time.Time { return now },
+			}
+			require.NoError(t, plugin.Init())
+
+			input := metric.New("test", map[string]string{}, map[string]interface{}{"time": tt.value, "value": 42}, now)
+			actual := plugin.Apply(input)
+			require.Len(t, actual, 1)
+			require.True(t, tt.expected.Equal(actual[0].Time()), "expected %v but got %v", tt.expected, actual[0].Time())
+		})
+	}
+}
+
+func TestSourceTag(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	plugin := &Timestamp{
+		SourceTag:    "time",
+		RemoveSource: true,
+		Log:          testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	input := []telegraf.Metric{
+		metric.New("test", map[string]string{"time": "2023-03-01T10:15:30Z"}, map[string]interface{}{"value": 42}, now),
+		metric.New("test", map[string]string{"other": "foo"}, map[string]interface{}{"value": 23}, now),
+	}
+	expected := []telegraf.Metric{
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 42}, time.Date(2023, time.March, 1, 10, 15, 30, 0, time.UTC)),
+		metric.New("test", map[string]string{"other": "foo"}, map[string]interface{}{"value": 23}, now),
+	}
+	testutil.RequireMetricsEqual(t, expected, plugin.Apply(input...))
+}
+
+func TestOutliers(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 12, 0, 0, 0, time.UTC)
+	original := now.Add(-time.Second)
+
+	newInput := func() []telegraf.Metric {
+		return []telegraf.Metric{
+			metric.New("test", map[string]string{"id": "valid"}, map[string]interface{}{"time": "2023-03-01T11:59:00Z"}, original),
+			metric.New("test", map[string]string{"id": "future"}, map[string]interface{}{"time": "2031-01-01T00:00:00Z"}, original),
+			metric.New("test", map[string]string{"id": "past"}, map[string]interface{}{"time": "1970-01-01T00:00:00Z"}, original),
+			metric.New("test", map[string]string{"id": "invalid"}, map[string]interface{}{"time": "soon"}, original),
+		}
+	}
+
+	tests := []struct {
+		action   string
+		expected []telegraf.Metric
+	}{
+		{
+			action: "clamp",
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"id": "valid"}, map[string]interface{}{"time": "2023-03-01T11:59:00Z"}, now.Add(-time.Minute)),
+				metric.New("test", map[string]string{"id": "future", "time_correction": "future"}, map[string]interface{}{"time": "2031-01-01T00:00:00Z"}, now.Add(5*time.Minute)),
+				metric.New("test", map[string]string{"id": "past", "time_correction": "past"}, map[string]interface{}{"time": "1970-01-01T00:00:00Z"}, now.Add(-24*time.Hour)),
+				metric.New("test", map[string]string{"id": "invalid", "time_correction": "unparsable"}, map[string]interface{}{"time": "soon"}, original),
+			},
+		},
+		{
+			action: "keep",
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"id": "valid"}, map[string]interface{}{"time": "2023-03-01T11:59:00Z"}, now.Add(-time.Minute)),
+				metric.New("test", map[string]string{"id": "future", "time_correction": "future"}, map[string]interface{}{"time": "2031-01-01T00:00:00Z"}, original),
+				metric.New("test", map[string]string{"id": "past", "time_correction": "past"}, map[string]interface{}{"time": "1970-01-01T00:00:00Z"}, original),
+				metric.New("test", map[string]string{"id": "invalid", "time_correction": "unparsable"}, map[string]interface{}{"time": "soon"}, original),
+			},
+		},
+		{
+			action: "drop",
+			expected: []telegraf.Metric{
+				metric.New("test", map[string]string{"id": "valid"}, map[string]interface{}{"time": "2023-03-01T11:59:00Z"}, now.Add(-time.Minute)),
+				metric.New("test", map[string]string{"id": "invalid", "time_correction": "unparsable"}, map[string]interface{}{"time": "soon"}, original),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			plugin := &Timestamp{
+				SourceField:   "time",
+				MaxFuture:     config.Duration(5 * time.Minute),
+				MaxPast:       config.Duration(24 * time.Hour),
+				OutlierAction: tt.action,
+				CorrectionTag: "time_correction",
+				Log:           testutil.Logger{},
+				now:           func() time.Time { return now },
+			}
+			require.NoError(t, plugin.Init())
+			testutil.RequireMetricsEqual(t, tt.expected, plugin.Apply(newInput()...))
+		})
+	}
+}
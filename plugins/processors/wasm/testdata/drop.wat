@@ -0,0 +1,7 @@
+;; Drops every metric by returning an empty result
+(module
+  (memory (export "memory") 1)
+  (func (export "allocate") (param i32) (result i32)
+    i32.const 1024)
+  (func (export "process") (param i32) (param i32) (result i64)
+    i64.const 0))
@@ -0,0 +1,9 @@
+;; Returns the input line unmodified
+(module
+  (memory (export "memory") 1)
+  (func (export "allocate") (param i32) (result i32)
+    i32.const 1024)
+  (func (export "process") (param $ptr i32) (param $len i32) (result i64)
+    (i64.or
+      (i64.shl (i64.extend_i32_u (local.get $ptr)) (i64.const 32))
+      (i64.extend_i32_u (local.get $len)))))
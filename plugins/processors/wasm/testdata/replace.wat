@@ -0,0 +1,8 @@
+;; Replaces every metric with two constant metrics
+(module
+  (memory (export "memory") 4)
+  (data (i32.const 0) "wasm,source=guest value=42i 1000\nwasm,source=guest value=43i 2000\n")
+  (func (export "allocate") (param i32) (result i32)
+    i32.const 1024)
+  (func (export "process") (param i32) (param i32) (result i64)
+    i64.const 66))
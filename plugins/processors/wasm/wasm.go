@@ -0,0 +1,437 @@
+//go:generate ../../../tools/readme_config_includer/generator
+package wasm
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/plugins/parsers/influx"
+	"github.com/influxdata/telegraf/plugins/processors"
+	serializer "github.com/influxdata/telegraf/plugins/serializers/influx"
+)
+
+//go:embed sample.conf
+var sampleConfig string
+
+// Size of a WebAssembly memory page
+const pageSize = 64 * 1024
+
+type WASM struct {
+	Module         string          `toml:"module"`
+	Function       string          `toml:"function"`
+	MemoryLimit    config.Size     `toml:"memory_limit"`
+	Timeout        config.Duration `toml:"timeout"`
+	ReloadInterval config.Duration `toml:"reload_interval"`
+	Log            telegraf.Logger `toml:"-"`
+
+	serializer *serializer.Serializer
+	parser     *influx.Parser
+	runtime    wazero.Runtime
+
+	instance *instance
+	modTime  time.Time
+
+	done chan struct{}
+	wg   sync.WaitGroup
+	sync.Mutex
+}
+
+// instance is a compiled module together with its instantiation, the module
+// is instantiated lazily after being closed due to a failed call
+type instance struct {
+	function string
+	compiled wazero.CompiledModule
+
+	module     api.Module
+	allocate   api.Function
+	deallocate api.Function
+	process    api.Function
+}
+
+func (*WASM) SampleConfig() string {
+	return sampleConfig
+}
+
+func (w *WASM) Init() error {
+	if w.Module == "" {
+		return errors.New("no 'module' specified")
+	}
+	if w.Function == "" {
+		w.Function = "process"
+	}
+	if w.MemoryLimit == 0 {
+		w.MemoryLimit = 16 * 1024 * 1024
+	}
+	if w.MemoryLimit < pageSize {
+		return fmt.Errorf("'memory_limit' must be at least %d bytes", pageSize)
+	}
+	if w.Timeout <= 0 {
+		w.Timeout = config.Duration(time.Second)
+	}
+
+	w.serializer = &serializer.Serializer{UintSupport: true}
+	if err := w.serializer.Init(); err != nil {
+		return fmt.Errorf("creating serializer failed: %w", err)
+	}
+	w.parser = &influx.Parser{}
+	if err := w.parser.Init(); err != nil {
+		return fmt.Errorf("creating parser failed: %w", err)
+	}
+
+	// Modules are closed when exceeding the timeout, so a misbehaving module
+	// cannot block the processing forever
+	ctx := context.Background()
+	cfg := wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(w.MemoryLimit / pageSize)).
+		WithCloseOnContextDone(true)
+	w.runtime = wazero.NewRuntimeWithConfig(ctx, cfg)
+
+	// Provide WASI to support modules built by common toolchains and the
+	// telegraf host functions
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, w.runtime); err != nil {
+		w.runtime.Close(ctx)
+		return fmt.Errorf("instantiating WASI failed: %w", err)
+	}
+	_, err := w.runtime.NewHostModuleBuilder("telegraf").
+		NewFunctionBuilder().WithFunc(w.log).Export("log").
+		Instantiate(ctx)
+	if err != nil {
+		w.runtime.Close(ctx)
+		return fmt.Errorf("instantiating host module failed: %w", err)
+	}
+
+	inst, modTime, err := w.load(ctx)
+	if err != nil {
+		w.runtime.Close(ctx)
+		return err
+	}
+	w.instance = inst
+	w.modTime = modTime
+
+	return nil
+}
+
+func (w *WASM) Start(_ telegraf.Accumulator) error {
+	w.done = make(chan struct{})
+	if w.ReloadInterval <= 0 {
+		return nil
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(w.ReloadInterval))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-ticker.C:
+				w.reload()
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (w *WASM) Add(metric telegraf.Metric, acc telegraf.Accumulator) error {
+	input, err := w.serializer.Serialize(metric)
+	if err != nil {
+		acc.AddMetric(metric)
+		return fmt.Errorf("serializing metric failed: %w", err)
+	}
+
+	w.Lock()
+	output, err := w.call(input)
+	w.Unlock()
+	if err != nil {
+		acc.AddMetric(metric)
+		return err
+	}
+
+	results, err := w.parser.Parse(output)
+	if err != nil {
+		acc.AddMetric(metric)
+		return fmt.Errorf("parsing module output failed: %w", err)
+	}
+	if len(results) == 0 {
+		metric.Drop()
+		return nil
+	}
+
+	// Apply the first result to the original metric to keep the tracking
+	// information, all other results are emitted as new metrics
+	replace(metric, results[0])
+	acc.AddMetric(metric)
+	for _, m := range results[1:] {
+		acc.AddMetric(m)
+	}
+
+	return nil
+}
+
+func (w *WASM) Stop() {
+	close(w.done)
+	w.wg.Wait()
+
+	ctx := context.Background()
+	w.Lock()
+	w.instance.close(ctx)
+	w.Unlock()
+	w.runtime.Close(ctx)
+}
+
+// call passes the serialized metric to the module and returns the module's
+// output, the caller must hold the lock
+func (w *WASM) call(input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(w.Timeout))
+	defer cancel()
+
+	inst := w.instance
+	if inst.module == nil {
+		if err := inst.instantiate(ctx, w.runtime); err != nil {
+			return nil, err
+		}
+	}
+
+	output, err := inst.call(ctx, input)
+	if err != nil {
+		// The module's state is undefined after a failed call, so start over
+		// with a fresh instance
+		inst.closeModule(ctx)
+		return nil, err
+	}
+	return output, nil
+}
+
+// log implements the "telegraf.log" host function
+func (w *WASM) log(_ context.Context, m api.Module, level, ptr, size uint32) {
+	msg, ok := m.Memory().Read(ptr, size)
+	if !ok {
+		w.Log.Errorf("Log message of module out of memory range (%d bytes at %d)", size, ptr)
+		return
+	}
+
+	switch level {
+	case 0:
+		w.Log.Error(string(msg))
+	case 1:
+		w.Log.Warn(string(msg))
+	case 2:
+		w.Log.Info(string(msg))
+	default:
+		w.Log.Debug(string(msg))
+	}
+}
+
+func (w *WASM) load(ctx context.Context) (*instance, time.Time, error) {
+	stat, err := os.Stat(w.Module)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("accessing module failed: %w", err)
+	}
+	code, err := os.ReadFile(w.Module)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("reading module failed: %w", err)
+	}
+
+	compiled, err := w.runtime.CompileModule(ctx, code)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("compiling module failed: %w", err)
+	}
+	inst := &instance{function: w.Function, compiled: compiled}
+	if err := inst.instantiate(ctx, w.runtime); err != nil {
+		compiled.Close(ctx)
+		return nil, time.Time{}, err
+	}
+
+	return inst, stat.ModTime(), nil
+}
+
+func (w *WASM) reload() {
+	stat, err := os.Stat(w.Module)
+	if err != nil {
+		w.Log.Errorf("Checking module for changes failed: %v", err)
+		return
+	}
+
+	w.Lock()
+	modTime := w.modTime
+	w.Unlock()
+	if stat.ModTime().Equal(modTime) {
+		return
+	}
+
+	ctx := context.Background()
+	inst, modTime, err := w.load(ctx)
+	if err != nil {
+		// Remember the modification time to not retry broken modules
+		// on every check
+		w.Lock()
+		w.modTime = stat.ModTime()
+		w.Unlock()
+		w.Log.Errorf("Reloading module failed, keeping the current module: %v", err)
+		return
+	}
+
+	w.Lock()
+	previous := w.instance
+	w.instance = inst
+	w.modTime = modTime
+	w.Unlock()
+	previous.close(ctx)
+
+	w.Log.Infof("Reloaded module %q", w.Module)
+}
+
+func (i *instance) instantiate(ctx context.Context, r wazero.Runtime) error {
+	// Use anonymous instances to allow the old and the new module to coexist
+	// during reloads
+	cfg := wazero.NewModuleConfig().WithName("").WithStartFunctions("_initialize")
+	module, err := r.InstantiateModule(ctx, i.compiled, cfg)
+	if err != nil {
+		return fmt.Errorf("instantiating module failed: %w", err)
+	}
+
+	if module.Memory() == nil {
+		module.Close(ctx)
+		return errors.New("module does not export a memory")
+	}
+	allocate := module.ExportedFunction("allocate")
+	if err := checkSignature(allocate, "allocate", []api.ValueType{api.ValueTypeI32}, api.ValueTypeI32); err != nil {
+		module.Close(ctx)
+		return err
+	}
+	process := module.ExportedFunction(i.function)
+	if err := checkSignature(process, i.function, []api.ValueType{api.ValueTypeI32, api.ValueTypeI32}, api.ValueTypeI64); err != nil {
+		module.Close(ctx)
+		return err
+	}
+
+	i.module = module
+	i.allocate = allocate
+	i.process = process
+	i.deallocate = module.ExportedFunction("deallocate")
+
+	return nil
+}
+
+func (i *instance) call(ctx context.Context, input []byte) ([]byte, error) {
+	results, err := i.allocate.Call(ctx, uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("allocating input memory failed: %w", err)
+	}
+	ptr := uint32(results[0])
+	if !i.module.Memory().Write(ptr, input) {
+		return nil, fmt.Errorf("input of %d bytes at %d is out of memory range", len(input), ptr)
+	}
+
+	results, err = i.process.Call(ctx, uint64(ptr), uint64(len(input)))
+	if err != nil {
+		return nil, fmt.Errorf("calling %q failed: %w", i.function, err)
+	}
+
+	// The result contains the pointer in the upper and the length of the
+	// output in the lower 32 bits
+	outPtr, outLen := uint32(results[0]>>32), uint32(results[0])
+	var output []byte
+	if outLen > 0 {
+		buf, ok := i.module.Memory().Read(outPtr, outLen)
+		if !ok {
+			return nil, fmt.Errorf("output of %d bytes at %d is out of memory range", outLen, outPtr)
+		}
+		output = make([]byte, len(buf))
+		copy(output, buf)
+	}
+
+	// Freeing the memory is optional for modules
+	if i.deallocate != nil {
+		if _, err := i.deallocate.Call(ctx, uint64(ptr), uint64(len(input))); err != nil {
+			return nil, fmt.Errorf("freeing input memory failed: %w", err)
+		}
+		if outLen > 0 {
+			if _, err := i.deallocate.Call(ctx, uint64(outPtr), uint64(outLen)); err != nil {
+				return nil, fmt.Errorf("freeing output memory failed: %w", err)
+			}
+		}
+	}
+
+	return output, nil
+}
+
+func (i *instance) closeModule(ctx context.Context) {
+	if i.module == nil {
+		return
+	}
+	i.module.Close(ctx)
+	i.module = nil
+}
+
+func (i *instance) close(ctx context.Context) {
+	i.closeModule(ctx)
+	i.compiled.Close(ctx)
+}
+
+func checkSignature(fn api.Function, name string, params []api.ValueType, result api.ValueType) error {
+	if fn == nil {
+		return fmt.Errorf("module does not export function %q", name)
+	}
+
+	def := fn.Definition()
+	valid := len(def.ParamTypes()) == len(params) && len(def.ResultTypes()) == 1 && def.ResultTypes()[0] == result
+	for idx := 0; valid && idx < len(params); idx++ {
+		valid = def.ParamTypes()[idx] == params[idx]
+	}
+	if !valid {
+		return fmt.Errorf("invalid signature of function %q", name)
+	}
+	return nil
+}
+
+// replace sets name, tags, fields and time of the metric to the ones of the
+// given replacement
+func replace(metric, replacement telegraf.Metric) {
+	metric.SetName(replacement.Name())
+
+	keys := make([]string, 0, len(metric.TagList()))
+	for _, tag := range metric.TagList() {
+		keys = append(keys, tag.Key)
+	}
+	for _, key := range keys {
+		metric.RemoveTag(key)
+	}
+	for _, tag := range replacement.TagList() {
+		metric.AddTag(tag.Key, tag.Value)
+	}
+
+	keys = make([]string, 0, len(metric.FieldList()))
+	for _, field := range metric.FieldList() {
+		keys = append(keys, field.Key)
+	}
+	for _, key := range keys {
+		metric.RemoveField(key)
+	}
+	for _, field := range replacement.FieldList() {
+		metric.AddField(field.Key, field.Value)
+	}
+
+	metric.SetTime(replacement.Time())
+}
+
+func init() {
+	processors.AddStreaming("wasm", func() telegraf.StreamingProcessor {
+		return &WASM{}
+	})
+}
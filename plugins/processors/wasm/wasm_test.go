@@ -0,0 +1,227 @@
+package wasm
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+)
+
+func TestInitErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		plugin   *WASM
+		expected string
+	}{
+		{
+			name:     "no module",
+			plugin:   &WASM{},
+			expected: "no 'module' specified",
+		},
+		{
+			name:     "missing module",
+			plugin:   &WASM{Module: filepath.Join("testdata", "nonexisting.wasm")},
+			expected: "accessing module failed",
+		},
+		{
+			name:     "invalid module",
+			plugin:   &WASM{Module: filepath.Join("testdata", "passthrough.wat")},
+			expected: "compiling module failed",
+		},
+		{
+			name:     "missing function",
+			plugin:   &WASM{Module: filepath.Join("testdata", "passthrough.wasm"), Function: "foo"},
+			expected: `module does not export function "foo"`,
+		},
+		{
+			name:     "invalid signature",
+			plugin:   &WASM{Module: filepath.Join("testdata", "passthrough.wasm"), Function: "allocate"},
+			expected: `invalid signature of function "allocate"`,
+		},
+		{
+			name:     "memory limit too small",
+			plugin:   &WASM{Module: filepath.Join("testdata", "passthrough.wasm"), MemoryLimit: 1024},
+			expected: "'memory_limit' must be at least 65536 bytes",
+		},
+		{
+			name:     "memory limit exceeded",
+			plugin:   &WASM{Module: filepath.Join("testdata", "replace.wasm"), MemoryLimit: 2 * pageSize},
+			expected: "compiling module failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.plugin.Log = testutil.Logger{}
+			require.ErrorContains(t, tt.plugin.Init(), tt.expected)
+		})
+	}
+}
+
+func TestPassthrough(t *testing.T) {
+	plugin := &WASM{
+		Module: filepath.Join("testdata", "passthrough.wasm"),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := []telegraf.Metric{
+		metric.New(
+			"test",
+			map[string]string{"host": "a", "source": "b"},
+			map[string]interface{}{"value": 42, "unsigned": uint64(23), "ratio": 0.5, "ok": true, "text": "foo bar"},
+			time.Unix(1689000000, 123),
+		),
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(1689000001, 0)),
+	}
+	for _, m := range input {
+		require.NoError(t, plugin.Add(m, &acc))
+	}
+	testutil.RequireMetricsEqual(t, input, acc.GetTelegrafMetrics())
+}
+
+func TestReplace(t *testing.T) {
+	plugin := &WASM{
+		Module: filepath.Join("testdata", "replace.wasm"),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// Use a tracking metric to check that the original metric is reused
+	var delivered bool
+	notify := func(telegraf.DeliveryInfo) { delivered = true }
+	input, _ := metric.WithTracking(
+		metric.New("test", map[string]string{"host": "a"}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		notify,
+	)
+	require.NoError(t, plugin.Add(input, &acc))
+
+	expected := []telegraf.Metric{
+		metric.New("wasm", map[string]string{"source": "guest"}, map[string]interface{}{"value": 42}, time.Unix(0, 1000)),
+		metric.New("wasm", map[string]string{"source": "guest"}, map[string]interface{}{"value": 43}, time.Unix(0, 2000)),
+	}
+	testutil.RequireMetricsEqual(t, expected, acc.GetTelegrafMetrics())
+
+	// The original metric must not be finished by the processor
+	require.False(t, delivered)
+	input.Accept()
+	require.True(t, delivered)
+}
+
+func TestDrop(t *testing.T) {
+	plugin := &WASM{
+		Module: filepath.Join("testdata", "drop.wasm"),
+		Log:    testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	var delivered bool
+	notify := func(telegraf.DeliveryInfo) { delivered = true }
+	input, _ := metric.WithTracking(
+		metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0)),
+		notify,
+	)
+	require.NoError(t, plugin.Add(input, &acc))
+	require.Empty(t, acc.GetTelegrafMetrics())
+	require.True(t, delivered)
+}
+
+func TestLog(t *testing.T) {
+	logger := &testutil.CaptureLogger{}
+	plugin := &WASM{
+		Module: filepath.Join("testdata", "log.wasm"),
+		Log:    logger,
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(input, &acc))
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input}, acc.GetTelegrafMetrics())
+
+	var found bool
+	for _, msg := range logger.Messages() {
+		if msg.Level == testutil.LevelInfo && msg.Text == "hello from guest" {
+			found = true
+		}
+	}
+	require.True(t, found)
+}
+
+func TestTimeout(t *testing.T) {
+	plugin := &WASM{
+		Module:  filepath.Join("testdata", "loop.wasm"),
+		Timeout: config.Duration(100 * time.Millisecond),
+		Log:     testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	// The metric must be passed on unmodified and the module must be usable
+	// again after timing out
+	input := metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	for i := 0; i < 2; i++ {
+		require.ErrorContains(t, plugin.Add(input, &acc), `calling "process" failed`)
+	}
+	testutil.RequireMetricsEqual(t, []telegraf.Metric{input, input}, acc.GetTelegrafMetrics())
+}
+
+func TestReload(t *testing.T) {
+	passthrough, err := os.ReadFile(filepath.Join("testdata", "passthrough.wasm"))
+	require.NoError(t, err)
+	drop, err := os.ReadFile(filepath.Join("testdata", "drop.wasm"))
+	require.NoError(t, err)
+
+	filename := filepath.Join(t.TempDir(), "processor.wasm")
+	require.NoError(t, os.WriteFile(filename, passthrough, 0600))
+
+	plugin := &WASM{
+		Module:         filename,
+		ReloadInterval: config.Duration(50 * time.Millisecond),
+		Log:            testutil.Logger{},
+	}
+	require.NoError(t, plugin.Init())
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Start(&acc))
+	defer plugin.Stop()
+
+	input := metric.New("test", map[string]string{}, map[string]interface{}{"value": 1}, time.Unix(0, 0))
+	require.NoError(t, plugin.Add(input, &acc))
+	require.Len(t, acc.GetTelegrafMetrics(), 1)
+
+	// Replace the module and make sure the modification is detected
+	require.NoError(t, os.WriteFile(filename, drop, 0600))
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filename, future, future))
+
+	require.Eventually(t, func() bool {
+		acc.ClearMetrics()
+		require.NoError(t, plugin.Add(input.Copy(), &acc))
+		return len(acc.GetTelegrafMetrics()) == 0
+	}, 3*time.Second, 50*time.Millisecond)
+}
@@ -22,6 +22,7 @@ type Serializer struct {
 
 	buffer bytes.Buffer
 	writer *csv.Writer
+	fields []*telegraf.Field
 }
 
 func (s *Serializer) Init() error {
@@ -114,11 +115,7 @@ func (s *Serializer) writeHeader(metric telegraf.Metric) error {
 		}
 	}
 
-	// Sort the fields by name
-	sort.Slice(metric.FieldList(), func(i, j int) bool {
-		return metric.FieldList()[i].Key < metric.FieldList()[j].Key
-	})
-	for _, field := range metric.FieldList() {
+	for _, field := range s.sortedFields(metric) {
 		if s.Prefix {
 			columns = append(columns, "field_"+field.Key)
 		} else {
@@ -154,11 +151,7 @@ func (s *Serializer) writeData(metric telegraf.Metric) error {
 		columns = append(columns, tag.Value)
 	}
 
-	// Sort the fields by name
-	sort.Slice(metric.FieldList(), func(i, j int) bool {
-		return metric.FieldList()[i].Key < metric.FieldList()[j].Key
-	})
-	for _, field := range metric.FieldList() {
+	for _, field := range s.sortedFields(metric) {
 		v, err := internal.ToString(field.Value)
 		if err != nil {
 			return fmt.Errorf("converting field %q to string failed: %w", field.Key, err)
@@ -186,3 +179,14 @@ func (s *Serializer) InitFromConfig(cfg *serializers.Config) error {
 
 	return nil
 }
+
+// sortedFields returns the fields of the metric sorted by name. The field list
+// might be shared with other outputs, so a copy is sorted to not modify the
+// metric.
+func (s *Serializer) sortedFields(metric telegraf.Metric) []*telegraf.Field {
+	s.fields = append(s.fields[:0], metric.FieldList()...)
+	sort.Slice(s.fields, func(i, j int) bool {
+		return s.fields[i].Key < s.fields[j].Key
+	})
+	return s.fields
+}
@@ -32,6 +32,11 @@ var (
 		`"`, `\"`,
 		`\`, `\\`,
 	)
+
+	newlineEscaper = strings.NewReplacer(
+		"\n", `\n`,
+		"\r", `\r`,
+	)
 )
 
 // Escape a tagkey, tagvalue, or fieldkey
@@ -57,3 +62,12 @@ func stringFieldEscape(s string) string {
 	}
 	return s
 }
+
+// Escape the line breaks of a string field to be kept on a single line, has
+// to be applied before stringFieldEscape
+func newlineEscape(s string) string {
+	if strings.ContainsAny(s, "\n\r") {
+		return newlineEscaper.Replace(s)
+	}
+	return s
+}
@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/serializers"
@@ -46,19 +47,48 @@ func (e FieldError) Error() string {
 
 // Serializer is a serializer for line protocol.
 type Serializer struct {
-	MaxLineBytes int  `toml:"influx_max_line_bytes"`
-	SortFields   bool `toml:"influx_sort_fields"`
-	UintSupport  bool `toml:"influx_uint_support"`
+	MaxLineBytes       int    `toml:"influx_max_line_bytes"`
+	DropOversizeFields bool   `toml:"influx_drop_oversize_fields"`
+	SortFields         bool   `toml:"influx_sort_fields"`
+	SortTags           bool   `toml:"influx_sort_tags"`
+	UintSupport        bool   `toml:"influx_uint_support"`
+	UintOverflow       string `toml:"influx_uint_overflow"`
+	TimestampPrecision string `toml:"influx_timestamp_precision"`
+	EscapeNewlines     bool   `toml:"influx_escape_newlines"`
 
 	bytesWritten int
+	precision    time.Duration
 
 	buf    bytes.Buffer
 	header []byte
 	footer []byte
 	pair   []byte
+	tags   []*telegraf.Tag
+	fields []*telegraf.Field
 }
 
 func (s *Serializer) Init() error {
+	switch s.UintOverflow {
+	case "":
+		s.UintOverflow = "clamp"
+	case "clamp", "drop":
+	default:
+		return fmt.Errorf("invalid 'influx_uint_overflow' setting %q", s.UintOverflow)
+	}
+
+	// The precision is parsed here as the serializer cannot depend on the
+	// config package
+	if s.TimestampPrecision != "" {
+		precision, err := time.ParseDuration(s.TimestampPrecision)
+		if err != nil {
+			return fmt.Errorf("invalid 'influx_timestamp_precision' setting: %w", err)
+		}
+		if precision < 0 {
+			return errors.New("'influx_timestamp_precision' must not be negative")
+		}
+		s.precision = precision
+	}
+
 	s.header = make([]byte, 0, 50)
 	s.footer = make([]byte, 0, 21)
 	s.pair = make([]byte, 0, 50)
@@ -83,6 +113,26 @@ func (s *Serializer) Serialize(m telegraf.Metric) ([]byte, error) {
 // SerializeBatch writes the slice of metrics and returns a byte slice of the
 // results.  The returned byte slice may contain multiple lines of data.
 func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if err := s.serializeBatch(metrics); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, s.buf.Len())
+	return append(out, s.buf.Bytes()...), nil
+}
+
+// SerializeBatchTo writes the slice of metrics to the given writer reusing
+// the internal buffer of the serializer.
+func (s *Serializer) SerializeBatchTo(w io.Writer, metrics []telegraf.Metric) error {
+	if err := s.serializeBatch(metrics); err != nil {
+		return err
+	}
+	_, err := w.Write(s.buf.Bytes())
+	return err
+}
+
+// serializeBatch serializes the metrics into the internal buffer skipping
+// metrics that can not be serialized
+func (s *Serializer) serializeBatch(metrics []telegraf.Metric) error {
 	s.buf.Reset()
 	for _, m := range metrics {
 		err := s.Write(&s.buf, m)
@@ -91,12 +141,12 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 			if errors.As(err, &mErr) {
 				continue
 			}
-			return nil, err
+			return err
 		}
 	}
-	out := make([]byte, 0, s.buf.Len())
-	return append(out, s.buf.Bytes()...), nil
+	return nil
 }
+
 func (s *Serializer) Write(w io.Writer, m telegraf.Metric) error {
 	return s.writeMetric(w, m)
 }
@@ -123,7 +173,19 @@ func (s *Serializer) buildHeader(m telegraf.Metric) error {
 
 	s.header = append(s.header, name...)
 
-	for _, tag := range m.TagList() {
+	tags := m.TagList()
+	if s.SortTags {
+		// Metrics usually keep their tags sorted, so only copy the tags if
+		// required to not modify the metric
+		less := func(i, j int) bool { return tags[i].Key < tags[j].Key }
+		if !sort.SliceIsSorted(tags, less) {
+			s.tags = append(s.tags[:0], tags...)
+			tags = s.tags
+			sort.SliceStable(tags, less)
+		}
+	}
+
+	for _, tag := range tags {
 		key := escape(tag.Key)
 		value := escape(tag.Value)
 
@@ -154,10 +216,27 @@ func (s *Serializer) buildHeader(m telegraf.Metric) error {
 func (s *Serializer) buildFooter(m telegraf.Metric) {
 	s.footer = s.footer[:0]
 	s.footer = append(s.footer, ' ')
-	s.footer = strconv.AppendInt(s.footer, m.Time().UnixNano(), 10)
+	s.footer = strconv.AppendInt(s.footer, s.timestamp(m.Time()), 10)
 	s.footer = append(s.footer, '\n')
 }
 
+// timestamp returns the nanosecond timestamp truncated to the configured
+// precision
+func (s *Serializer) timestamp(t time.Time) int64 {
+	ts := t.UnixNano()
+	precision := int64(s.precision)
+	if precision <= 1 {
+		return ts
+	}
+
+	// Round towards negative infinity for timestamps before the epoch
+	remainder := ts % precision
+	if remainder < 0 {
+		remainder += precision
+	}
+	return ts - remainder
+}
+
 func (s *Serializer) buildFieldPair(key string, value interface{}) error {
 	s.pair = s.pair[:0]
 	key = escape(key)
@@ -188,15 +267,21 @@ func (s *Serializer) writeMetric(w io.Writer, m telegraf.Metric) error {
 
 	s.buildFooter(m)
 
+	fields := m.FieldList()
 	if s.SortFields {
-		sort.Slice(m.FieldList(), func(i, j int) bool {
-			return m.FieldList()[i].Key < m.FieldList()[j].Key
-		})
+		// The field list might be shared with other outputs, so sort a copy
+		// to not modify the metric
+		less := func(i, j int) bool { return fields[i].Key < fields[j].Key }
+		if !sort.SliceIsSorted(fields, less) {
+			s.fields = append(s.fields[:0], fields...)
+			fields = s.fields
+			sort.Slice(fields, less)
+		}
 	}
 
 	pairsLen := 0
 	firstField := true
-	for _, field := range m.FieldList() {
+	for _, field := range fields {
 		err = s.buildFieldPair(field.Key, field.Value)
 		if err != nil {
 			log.Printf(
@@ -216,9 +301,21 @@ func (s *Serializer) writeMetric(w io.Writer, m telegraf.Metric) error {
 			// Need at least one field per line, this metric cannot be fit
 			// into the max line bytes.
 			if firstField {
+				if s.DropOversizeFields {
+					log.Printf("D! [serializers.influx] field %q does not fit into the max line bytes; discarding field", field.Key)
+					continue
+				}
 				return s.newMetricError(NeedMoreSpace)
 			}
 
+			// Skip fields not even fitting into a line on their own before
+			// starting a new line so the remaining fields can still be
+			// written to the current one.
+			if s.DropOversizeFields && len(s.header)+len(s.pair)+len(s.footer) > s.MaxLineBytes {
+				log.Printf("D! [serializers.influx] field %q does not fit into the max line bytes; discarding field", field.Key)
+				continue
+			}
+
 			err = s.writeBytes(w, s.footer)
 			if err != nil {
 				return err
@@ -278,6 +375,9 @@ func (s *Serializer) appendFieldValue(buf []byte, value interface{}) ([]byte, er
 		if v <= uint64(MaxInt64) {
 			return appendIntField(buf, int64(v)), nil
 		}
+		if s.UintOverflow == "drop" {
+			return nil, &FieldError{"exceeds the max int64"}
+		}
 		return appendIntField(buf, MaxInt64), nil
 	case int64:
 		return appendIntField(buf, v), nil
@@ -292,6 +392,9 @@ func (s *Serializer) appendFieldValue(buf []byte, value interface{}) ([]byte, er
 
 		return appendFloatField(buf, v), nil
 	case string:
+		if s.EscapeNewlines {
+			v = newlineEscape(v)
+		}
 		return appendStringField(buf, v), nil
 	case bool:
 		return appendBoolField(buf, v), nil
@@ -334,8 +437,13 @@ func init() {
 // InitFromConfig is a compatibility function to construct the parser the old way
 func (s *Serializer) InitFromConfig(cfg *serializers.Config) error {
 	s.MaxLineBytes = cfg.InfluxMaxLineBytes
+	s.DropOversizeFields = cfg.InfluxDropOversizeFields
 	s.SortFields = cfg.InfluxSortFields
+	s.SortTags = cfg.InfluxSortTags
 	s.UintSupport = cfg.InfluxUintSupport
+	s.UintOverflow = cfg.InfluxUintOverflow
+	s.TimestampPrecision = cfg.InfluxTimestampPrecision
+	s.EscapeNewlines = cfg.InfluxEscapeNewlines
 
 	return nil
 }
@@ -1,7 +1,10 @@
 package influx
 
 import (
+	"bytes"
+	"io"
 	"math"
+	"strconv"
 	"testing"
 	"time"
 
@@ -496,6 +499,190 @@ func TestSerializer(t *testing.T) {
 	}
 }
 
+func TestSerializerOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		serializer *Serializer
+		input      telegraf.Metric
+		output     string
+		errReason  string
+	}{
+		{
+			name:       "uint overflow clamp",
+			serializer: &Serializer{},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"value": uint64(math.MaxInt64) + 1},
+				time.Unix(0, 0),
+			),
+			output: "cpu value=9223372036854775807i 0\n",
+		},
+		{
+			name:       "uint overflow drop",
+			serializer: &Serializer{UintOverflow: "drop"},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"value": uint64(math.MaxInt64) + 1, "other": uint64(42)},
+				time.Unix(0, 0),
+			),
+			output: "cpu other=42i 0\n",
+		},
+		{
+			name:       "uint overflow drop with uint support",
+			serializer: &Serializer{UintSupport: true, UintOverflow: "drop"},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"value": uint64(math.MaxInt64) + 1},
+				time.Unix(0, 0),
+			),
+			output: "cpu value=9223372036854775808u 0\n",
+		},
+		{
+			name:       "timestamp precision",
+			serializer: &Serializer{TimestampPrecision: "1ms"},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"value": 42.0},
+				time.Unix(1422568543, 702900257),
+			),
+			output: "cpu value=42 1422568543702000000\n",
+		},
+		{
+			name:       "timestamp precision before epoch",
+			serializer: &Serializer{TimestampPrecision: "1s"},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"value": 42.0},
+				time.Unix(-1, -500000000),
+			),
+			output: "cpu value=42 -2000000000\n",
+		},
+		{
+			name:       "escape newlines",
+			serializer: &Serializer{EscapeNewlines: true},
+			input: metric.New(
+				"log",
+				map[string]string{},
+				map[string]interface{}{"message": "first\r\nsecond \"quoted\""},
+				time.Unix(0, 0),
+			),
+			output: `log message="first\\r\\nsecond \"quoted\"" 0` + "\n",
+		},
+		{
+			name:       "keep newlines",
+			serializer: &Serializer{},
+			input: metric.New(
+				"log",
+				map[string]string{},
+				map[string]interface{}{"message": "first\nsecond"},
+				time.Unix(0, 0),
+			),
+			output: "log message=\"first\nsecond\" 0\n",
+		},
+		{
+			name:       "oversize field error",
+			serializer: &Serializer{MaxLineBytes: 20, SortFields: true},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"a": 1.0, "b": "this string is too long", "c": 3.0},
+				time.Unix(0, 0),
+			),
+			errReason: NeedMoreSpace,
+		},
+		{
+			name:       "oversize field dropped",
+			serializer: &Serializer{MaxLineBytes: 20, SortFields: true, DropOversizeFields: true},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"a": 1.0, "b": "this string is too long", "c": 3.0},
+				time.Unix(0, 0),
+			),
+			output: "cpu a=1,c=3 0\n",
+		},
+		{
+			name:       "oversize first field dropped and split",
+			serializer: &Serializer{MaxLineBytes: 12, SortFields: true, DropOversizeFields: true},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"a": "too long", "b": 2.0, "c": 3.0},
+				time.Unix(0, 0),
+			),
+			output: "cpu b=2 0\ncpu c=3 0\n",
+		},
+		{
+			name:       "all fields oversize",
+			serializer: &Serializer{MaxLineBytes: 12, DropOversizeFields: true},
+			input: metric.New(
+				"cpu",
+				map[string]string{},
+				map[string]interface{}{"a": "too long"},
+				time.Unix(0, 0),
+			),
+			errReason: NoFields,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.serializer.Init())
+			output, err := tt.serializer.Serialize(tt.input)
+			if tt.errReason != "" {
+				require.ErrorContains(t, err, tt.errReason)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.output, string(output))
+		})
+	}
+}
+
+func TestSerializerInvalidOptions(t *testing.T) {
+	serializer := &Serializer{UintOverflow: "wrap"}
+	require.ErrorContains(t, serializer.Init(), "invalid 'influx_uint_overflow' setting")
+
+	serializer = &Serializer{TimestampPrecision: "foo"}
+	require.ErrorContains(t, serializer.Init(), "invalid 'influx_timestamp_precision' setting")
+
+	serializer = &Serializer{TimestampPrecision: "-1s"}
+	require.ErrorContains(t, serializer.Init(), "must not be negative")
+}
+
+func TestSerializerSortTags(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{"a": "1", "b": "2", "c": "3"},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(0, 0),
+	)
+
+	// Reorder the tags of the metric to simulate an unsorted metric
+	tags := m.TagList()
+	tags[0], tags[2] = tags[2], tags[0]
+
+	serializer := &Serializer{}
+	require.NoError(t, serializer.Init())
+	output, err := serializer.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, "cpu,c=3,b=2,a=1 value=42 0\n", string(output))
+
+	serializer = &Serializer{SortTags: true}
+	require.NoError(t, serializer.Init())
+	output, err = serializer.Serialize(m)
+	require.NoError(t, err)
+	require.Equal(t, "cpu,a=1,b=2,c=3 value=42 0\n", string(output))
+
+	// The metric must not be modified
+	require.Equal(t, "c", m.TagList()[0].Key)
+}
+
 func BenchmarkSerializer(b *testing.B) {
 	for _, tt := range tests {
 		b.Run(tt.name, func(b *testing.B) {
@@ -531,3 +718,59 @@ func TestSerialize_SerializeBatch(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, []byte("cpu value=42 0\ncpu value=42 0\n"), output)
 }
+
+func TestSerialize_SerializeBatchTo(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	invalid := metric.New("", map[string]string{}, map[string]interface{}{"value": 42.0}, time.Unix(0, 0))
+
+	serializer := &Serializer{
+		SortFields: true,
+	}
+	require.NoError(t, serializer.Init())
+
+	// Serialize twice to check the internal buffer is reset
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		require.NoError(t, serializer.SerializeBatchTo(&buf, []telegraf.Metric{m, invalid, m}))
+		require.Equal(t, "cpu value=42 0\ncpu value=42 0\n", buf.String())
+	}
+}
+
+func BenchmarkSerializeBatch(b *testing.B) {
+	metrics := make([]telegraf.Metric, 0, 1000)
+	for i := 0; i < cap(metrics); i++ {
+		metrics = append(metrics, metric.New(
+			"cpu",
+			map[string]string{"host": "localhost", "cpu": strconv.Itoa(i % 8)},
+			map[string]interface{}{"usage_idle": 98.5, "usage_user": 1.5},
+			time.Unix(int64(i), 0),
+		))
+	}
+
+	b.Run("SerializeBatch", func(b *testing.B) {
+		serializer := &Serializer{}
+		require.NoError(b, serializer.Init())
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			output, err := serializer.SerializeBatch(metrics)
+			require.NoError(b, err)
+			_, _ = io.Discard.Write(output)
+		}
+	})
+
+	b.Run("SerializeBatchTo", func(b *testing.B) {
+		serializer := &Serializer{}
+		require.NoError(b, serializer.Init())
+		b.ReportAllocs()
+		for n := 0; n < b.N; n++ {
+			require.NoError(b, serializer.SerializeBatchTo(io.Discard, metrics))
+		}
+	})
+}
@@ -1,9 +1,11 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"time"
 
@@ -23,6 +25,7 @@ type Serializer struct {
 	NestedFieldsExclude []string        `toml:"json_nested_fields_exclude"`
 
 	nestedfields filter.Filter
+	buf          bytes.Buffer
 }
 
 func (s *Serializer) Init() error {
@@ -78,6 +81,37 @@ func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
 }
 
 func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	obj, err := s.batchObject(metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	serialized, err := json.Marshal(obj)
+	if err != nil {
+		return []byte{}, err
+	}
+	return serialized, nil
+}
+
+// SerializeBatchTo writes the batch of metrics to the given writer reusing
+// the internal buffer of the serializer
+func (s *Serializer) SerializeBatchTo(w io.Writer, metrics []telegraf.Metric) error {
+	obj, err := s.batchObject(metrics)
+	if err != nil {
+		return err
+	}
+
+	s.buf.Reset()
+	if err := json.NewEncoder(&s.buf).Encode(obj); err != nil {
+		return err
+	}
+
+	// Strip the newline added by the encoder to match SerializeBatch
+	_, err = w.Write(bytes.TrimSuffix(s.buf.Bytes(), []byte("\n")))
+	return err
+}
+
+func (s *Serializer) batchObject(metrics []telegraf.Metric) (interface{}, error) {
 	objects := make([]interface{}, 0, len(metrics))
 	for _, metric := range metrics {
 		m := s.createObject(metric)
@@ -99,11 +133,7 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 		}
 	}
 
-	serialized, err := json.Marshal(obj)
-	if err != nil {
-		return []byte{}, err
-	}
-	return serialized, nil
+	return obj, nil
 }
 
 func (s *Serializer) createObject(metric telegraf.Metric) map[string]interface{} {
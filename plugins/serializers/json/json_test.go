@@ -1,6 +1,7 @@
 package json
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -202,6 +203,29 @@ func TestSerializeBatch(t *testing.T) {
 	)
 }
 
+func TestSerializeBatchTo(t *testing.T) {
+	m := metric.New(
+		"cpu",
+		map[string]string{},
+		map[string]interface{}{
+			"value": 42.0,
+		},
+		time.Unix(0, 0),
+	)
+	metrics := []telegraf.Metric{m, m}
+
+	s := Serializer{}
+	require.NoError(t, s.Init())
+	expected, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		require.NoError(t, s.SerializeBatchTo(&buf, metrics))
+		require.Equal(t, expected, buf.Bytes())
+	}
+}
+
 func TestSerializeBatchSkipInf(t *testing.T) {
 	metrics := []telegraf.Metric{
 		testutil.MustMetric(
@@ -0,0 +1,119 @@
+package msgpack
+
+import (
+	"sort"
+
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/influxdata/telegraf"
+)
+
+// marshalColumnar encodes the metrics as a single map containing one array per
+// column, i.e. the names, times and one array for each tag and field key. The
+// arrays contain one entry per metric with nil denoting a missing tag or field.
+// With the dictionary enabled, the tag columns contain indices into an array of
+// the distinct tag values instead of the values.
+func marshalColumnar(buf []byte, metrics []telegraf.Metric, dictionary bool) ([]byte, error) {
+	// Collect the tag and field keys of all metrics
+	tagIndex := make(map[string]int)
+	fieldIndex := make(map[string]int)
+	var tagKeys, fieldKeys []string
+	for _, m := range metrics {
+		for _, tag := range m.TagList() {
+			if _, found := tagIndex[tag.Key]; !found {
+				tagIndex[tag.Key] = len(tagKeys)
+				tagKeys = append(tagKeys, tag.Key)
+			}
+		}
+		for _, field := range m.FieldList() {
+			if _, found := fieldIndex[field.Key]; !found {
+				fieldIndex[field.Key] = len(fieldKeys)
+				fieldKeys = append(fieldKeys, field.Key)
+			}
+		}
+	}
+	sort.Strings(tagKeys)
+	sort.Strings(fieldKeys)
+
+	// Build the columns
+	tags := make(map[string][]interface{}, len(tagKeys))
+	for _, k := range tagKeys {
+		tags[k] = make([]interface{}, len(metrics))
+	}
+	fields := make(map[string][]interface{}, len(fieldKeys))
+	for _, k := range fieldKeys {
+		fields[k] = make([]interface{}, len(metrics))
+	}
+	var values []string
+	valueIndex := make(map[string]int)
+	for i, m := range metrics {
+		for _, tag := range m.TagList() {
+			if !dictionary {
+				tags[tag.Key][i] = tag.Value
+				continue
+			}
+			idx, found := valueIndex[tag.Value]
+			if !found {
+				idx = len(values)
+				valueIndex[tag.Value] = idx
+				values = append(values, tag.Value)
+			}
+			tags[tag.Key][i] = int64(idx)
+		}
+		for _, field := range m.FieldList() {
+			fields[field.Key][i] = field.Value
+		}
+	}
+
+	entries := uint32(4)
+	if dictionary {
+		entries++
+	}
+	buf = msgp.AppendMapHeader(buf, entries)
+
+	buf = msgp.AppendString(buf, "names")
+	buf = msgp.AppendArrayHeader(buf, uint32(len(metrics)))
+	for _, m := range metrics {
+		buf = msgp.AppendString(buf, m.Name())
+	}
+
+	buf = msgp.AppendString(buf, "times")
+	buf = msgp.AppendArrayHeader(buf, uint32(len(metrics)))
+	for _, m := range metrics {
+		var err error
+		if buf, err = msgp.AppendExtension(buf, &MessagePackTime{time: m.Time()}); err != nil {
+			return nil, err
+		}
+	}
+
+	if dictionary {
+		buf = msgp.AppendString(buf, "dictionary")
+		buf = msgp.AppendArrayHeader(buf, uint32(len(values)))
+		for _, v := range values {
+			buf = msgp.AppendString(buf, v)
+		}
+	}
+
+	var err error
+	buf = msgp.AppendString(buf, "tags")
+	if buf, err = appendColumns(buf, tagKeys, tags); err != nil {
+		return nil, err
+	}
+	buf = msgp.AppendString(buf, "fields")
+	return appendColumns(buf, fieldKeys, fields)
+}
+
+func appendColumns(buf []byte, keys []string, columns map[string][]interface{}) ([]byte, error) {
+	buf = msgp.AppendMapHeader(buf, uint32(len(keys)))
+	for _, k := range keys {
+		buf = msgp.AppendString(buf, k)
+		buf = msgp.AppendArrayHeader(buf, uint32(len(columns[k])))
+		for _, v := range columns[k] {
+			var err error
+			if buf, err = msgp.AppendIntf(buf, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
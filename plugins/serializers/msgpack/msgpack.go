@@ -1,12 +1,37 @@
 package msgpack
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
 // Serializer encodes metrics in MessagePack format
-type Serializer struct{}
+type Serializer struct {
+	Layout        string `toml:"msgpack_layout"`
+	TagDictionary bool   `toml:"msgpack_tag_dictionary"`
+
+	buf []byte
+}
+
+func (s *Serializer) Init() error {
+	if s.Layout == "" {
+		s.Layout = "metric"
+	}
+
+	switch s.Layout {
+	case "metric":
+		if s.TagDictionary {
+			return fmt.Errorf("'msgpack_tag_dictionary' requires the %q layout", "columnar")
+		}
+	case "columnar":
+	default:
+		return fmt.Errorf("invalid 'msgpack_layout' setting %q", s.Layout)
+	}
+	return nil
+}
 
 func marshalMetric(buf []byte, metric telegraf.Metric) ([]byte, error) {
 	return (&Metric{
@@ -20,12 +45,19 @@ func marshalMetric(buf []byte, metric telegraf.Metric) ([]byte, error) {
 // Serialize implements serializers.Serializer.Serialize
 // github.com/influxdata/telegraf/plugins/serializers/Serializer
 func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
+	if s.Layout == "columnar" {
+		return marshalColumnar(nil, []telegraf.Metric{metric}, s.TagDictionary)
+	}
 	return marshalMetric(nil, metric)
 }
 
 // SerializeBatch implements serializers.Serializer.SerializeBatch
 // github.com/influxdata/telegraf/plugins/serializers/Serializer
 func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
+	if s.Layout == "columnar" {
+		return marshalColumnar(nil, metrics, s.TagDictionary)
+	}
+
 	buf := make([]byte, 0)
 	for _, m := range metrics {
 		var err error
@@ -38,6 +70,30 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	return buf, nil
 }
 
+// SerializeBatchTo implements telegraf.BatchSerializer reusing the internal
+// buffer of the serializer between calls
+func (s *Serializer) SerializeBatchTo(w io.Writer, metrics []telegraf.Metric) error {
+	buf := s.buf[:0]
+	if s.Layout == "columnar" {
+		var err error
+		if buf, err = marshalColumnar(buf, metrics, s.TagDictionary); err != nil {
+			return err
+		}
+	} else {
+		for _, m := range metrics {
+			var err error
+			buf, err = marshalMetric(buf, m)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	s.buf = buf
+
+	_, err := w.Write(buf)
+	return err
+}
+
 func init() {
 	serializers.Add("msgpack",
 		func() serializers.Serializer {
@@ -47,6 +103,9 @@ func init() {
 }
 
 // InitFromConfig is a compatibility function to construct the parser the old way
-func (s *Serializer) InitFromConfig(_ *serializers.Config) error {
+func (s *Serializer) InitFromConfig(cfg *serializers.Config) error {
+	s.Layout = cfg.MsgpackLayout
+	s.TagDictionary = cfg.MsgpackTagDictionary
+
 	return nil
 }
@@ -1,9 +1,12 @@
 package msgpack
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/metric"
@@ -130,3 +133,136 @@ func TestSerializeBatch(t *testing.T) {
 		testutil.RequireMetricEqual(t, m, toTelegrafMetric(*decodeM))
 	}
 }
+
+func TestSerializeBatchTo(t *testing.T) {
+	m := testutil.TestMetric(90)
+	metrics := []telegraf.Metric{m, m, m, m}
+
+	s := Serializer{}
+	expected, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		var buf bytes.Buffer
+		require.NoError(t, s.SerializeBatchTo(&buf, metrics))
+		require.Equal(t, expected, buf.Bytes())
+	}
+}
+
+func TestInitInvalidLayout(t *testing.T) {
+	s := Serializer{Layout: "rows"}
+	require.ErrorContains(t, s.Init(), "invalid 'msgpack_layout' setting")
+
+	s = Serializer{TagDictionary: true}
+	require.ErrorContains(t, s.Init(), "requires the \"columnar\" layout")
+}
+
+func TestSerializeBatchColumnar(t *testing.T) {
+	metrics := []telegraf.Metric{
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a", "cpu": "cpu0"},
+			map[string]interface{}{"usage": 1.5},
+			time.Unix(0, 1),
+		),
+		metric.New(
+			"cpu",
+			map[string]string{"host": "a"},
+			map[string]interface{}{"usage": 2.5},
+			time.Unix(0, 2),
+		),
+		metric.New(
+			"mem",
+			map[string]string{"host": "b"},
+			map[string]interface{}{"free": int64(42), "ok": true},
+			time.Unix(0, 3),
+		),
+	}
+
+	tests := []struct {
+		name       string
+		dictionary bool
+		expected   map[string]interface{}
+	}{
+		{
+			name: "plain",
+			expected: map[string]interface{}{
+				"names": []interface{}{"cpu", "cpu", "mem"},
+				"tags": map[string]interface{}{
+					"cpu":  []interface{}{"cpu0", nil, nil},
+					"host": []interface{}{"a", "a", "b"},
+				},
+				"fields": map[string]interface{}{
+					"free":  []interface{}{nil, nil, int64(42)},
+					"ok":    []interface{}{nil, nil, true},
+					"usage": []interface{}{1.5, 2.5, nil},
+				},
+			},
+		},
+		{
+			name:       "dictionary",
+			dictionary: true,
+			expected: map[string]interface{}{
+				"names":      []interface{}{"cpu", "cpu", "mem"},
+				"dictionary": []interface{}{"cpu0", "a", "b"},
+				"tags": map[string]interface{}{
+					"cpu":  []interface{}{int64(0), nil, nil},
+					"host": []interface{}{int64(1), int64(1), int64(2)},
+				},
+				"fields": map[string]interface{}{
+					"free":  []interface{}{nil, nil, int64(42)},
+					"ok":    []interface{}{nil, nil, true},
+					"usage": []interface{}{1.5, 2.5, nil},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Serializer{Layout: "columnar", TagDictionary: tt.dictionary}
+			require.NoError(t, s.Init())
+
+			buf, err := s.SerializeBatch(metrics)
+			require.NoError(t, err)
+
+			decoded, left, err := msgp.ReadIntfBytes(buf)
+			require.NoError(t, err)
+			require.Empty(t, left)
+
+			actual, ok := decoded.(map[string]interface{})
+			require.True(t, ok)
+
+			// Check the times separately as they are decoded as extension
+			times, ok := actual["times"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, times, len(metrics))
+			for i, v := range times {
+				ts, ok := v.(*MessagePackTime)
+				require.True(t, ok)
+				require.True(t, metrics[i].Time().Equal(ts.time))
+			}
+			delete(actual, "times")
+
+			require.Equal(t, tt.expected, actual)
+
+			// Writing the batch must produce the same output
+			var out bytes.Buffer
+			require.NoError(t, s.SerializeBatchTo(&out, metrics))
+			require.Equal(t, buf, out.Bytes())
+		})
+	}
+}
+
+func TestSerializeColumnar(t *testing.T) {
+	m := testutil.TestMetric(int64(90))
+
+	s := Serializer{Layout: "columnar"}
+	require.NoError(t, s.Init())
+
+	single, err := s.Serialize(m)
+	require.NoError(t, err)
+	batch, err := s.SerializeBatch([]telegraf.Metric{m})
+	require.NoError(t, err)
+	require.Equal(t, batch, single)
+}
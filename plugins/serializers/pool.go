@@ -0,0 +1,50 @@
+package serializers
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+)
+
+// maxPooledBufferSize is the maximum capacity of buffers kept in the pool to
+// not hold on to the memory of exceptionally large batches
+const maxPooledBufferSize = 16 * 1024 * 1024
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// GetBuffer returns an empty buffer from the pool. Return the buffer using
+// PutBuffer once its content is not used anymore.
+func GetBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// PutBuffer returns the buffer to the pool for reuse
+func PutBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// SerializeBatchTo writes the serialized batch of metrics to the writer. The
+// SerializeBatchTo function of the serializer is used if implemented, otherwise
+// the result of SerializeBatch is written.
+func SerializeBatchTo(s Serializer, w io.Writer, metrics []telegraf.Metric) error {
+	if bs, ok := s.(telegraf.BatchSerializer); ok {
+		return bs.SerializeBatchTo(w, metrics)
+	}
+
+	octets, err := s.SerializeBatch(metrics)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(octets)
+	return err
+}
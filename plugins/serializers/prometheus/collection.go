@@ -181,9 +181,23 @@ func (c *Collection) createLabels(metric telegraf.Metric) []LabelPair {
 }
 
 func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
+	// Events have no representation in Prometheus
+	if metric.Type() == telegraf.Event {
+		return
+	}
+
 	labels := c.createLabels(metric)
 	for _, field := range metric.FieldList() {
-		metricName := MetricName(metric.Name(), field.Key, metric.Type())
+		// Native histogram and summary values determine the type on their own
+		valueType := metric.Type()
+		switch field.Value.(type) {
+		case *telegraf.HistogramValue:
+			valueType = telegraf.Histogram
+		case *telegraf.SummaryValue:
+			valueType = telegraf.Summary
+		}
+
+		metricName := MetricName(metric.Name(), field.Key, valueType)
 		metricName, ok := SanitizeMetricName(metricName)
 		if !ok {
 			continue
@@ -191,7 +205,7 @@ func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 
 		family := MetricFamily{
 			Name: metricName,
-			Type: metric.Type(),
+			Type: valueType,
 		}
 
 		entry, ok := c.Entries[family]
@@ -215,6 +229,34 @@ func (c *Collection) Add(metric telegraf.Metric, now time.Time) {
 			}
 		}
 
+		// Native values contain the complete histogram or summary
+		switch v := field.Value.(type) {
+		case *telegraf.HistogramValue:
+			h := &Histogram{Count: v.Count, Sum: v.Sum, Buckets: make([]Bucket, 0, len(v.Buckets))}
+			for _, b := range v.Buckets {
+				h.Buckets = append(h.Buckets, Bucket{Bound: b.UpperBound, Count: b.Count})
+			}
+			entry.Metrics[metricKey] = &Metric{
+				Labels:    labels,
+				Time:      metric.Time(),
+				AddTime:   now,
+				Histogram: h,
+			}
+			continue
+		case *telegraf.SummaryValue:
+			sm := &Summary{Count: v.Count, Sum: v.Sum, Quantiles: make([]Quantile, 0, len(v.Quantiles))}
+			for _, q := range v.Quantiles {
+				sm.Quantiles = append(sm.Quantiles, Quantile{Quantile: q.Quantile, Value: q.Value})
+			}
+			entry.Metrics[metricKey] = &Metric{
+				Labels:  labels,
+				Time:    metric.Time(),
+				AddTime: now,
+				Summary: sm,
+			}
+			continue
+		}
+
 		switch metric.Type() {
 		case telegraf.Counter:
 			fallthrough
@@ -1,6 +1,7 @@
 package prometheus
 
 import (
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -136,6 +137,60 @@ http_request_duration_seconds_bucket{le="0.5"} 129389
 http_request_duration_seconds_bucket{le="+Inf"} 0
 http_request_duration_seconds_sum 0
 http_request_duration_seconds_count 0
+`),
+		},
+		{
+			name: "native histogram",
+			metric: testutil.MustMetric(
+				"prometheus",
+				map[string]string{"method": "post"},
+				map[string]interface{}{
+					"http_request_duration_seconds": &telegraf.HistogramValue{
+						Count: 144320,
+						Sum:   53423,
+						Buckets: []telegraf.Bucket{
+							{UpperBound: 0.5, Count: 129389},
+							{UpperBound: 1, Count: 133988},
+							{UpperBound: math.Inf(1), Count: 144320},
+						},
+					},
+				},
+				time.Unix(0, 0),
+			),
+			expected: []byte(`
+# HELP http_request_duration_seconds Telegraf collected metric
+# TYPE http_request_duration_seconds histogram
+http_request_duration_seconds_bucket{method="post",le="0.5"} 129389
+http_request_duration_seconds_bucket{method="post",le="1"} 133988
+http_request_duration_seconds_bucket{method="post",le="+Inf"} 144320
+http_request_duration_seconds_sum{method="post"} 53423
+http_request_duration_seconds_count{method="post"} 144320
+`),
+		},
+		{
+			name: "native summary",
+			metric: testutil.MustMetric(
+				"rpc",
+				map[string]string{},
+				map[string]interface{}{
+					"duration_seconds": &telegraf.SummaryValue{
+						Count: 2693,
+						Sum:   17560473,
+						Quantiles: []telegraf.Quantile{
+							{Quantile: 0.5, Value: 4773},
+							{Quantile: 0.9, Value: 9001},
+						},
+					},
+				},
+				time.Unix(0, 0),
+			),
+			expected: []byte(`
+# HELP rpc_duration_seconds Telegraf collected metric
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 4773
+rpc_duration_seconds{quantile="0.9"} 9001
+rpc_duration_seconds_sum 1.7560473e+07
+rpc_duration_seconds_count 2693
 `),
 		},
 		{
@@ -236,6 +291,35 @@ func TestSerializeBatch(t *testing.T) {
 # TYPE cpu_time_idle untyped
 cpu_time_idle{host="one.example.org"} 42
 cpu_time_idle{host="two.example.org"} 42
+`),
+		},
+		{
+			name: "events are skipped",
+			metrics: []telegraf.Metric{
+				testutil.MustMetric(
+					"cpu",
+					map[string]string{},
+					map[string]interface{}{
+						"time_idle": 42.0,
+					},
+					time.Unix(0, 0),
+				),
+				testutil.MustMetric(
+					"syslog",
+					map[string]string{},
+					map[string]interface{}{
+						"message":       "disk almost full",
+						"severity":      "warning",
+						"severity_code": 4,
+					},
+					time.Unix(0, 0),
+					telegraf.Event,
+				),
+			},
+			expected: []byte(`
+# HELP cpu_time_idle Telegraf collected metric
+# TYPE cpu_time_idle untyped
+cpu_time_idle 42
 `),
 		},
 		{
@@ -2,17 +2,21 @@ package prometheusremotewrite
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/golang/snappy"
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/plugins/serializers/prometheus"
 )
@@ -20,8 +24,54 @@ import (
 type MetricKey uint64
 
 type Serializer struct {
-	SortMetrics   bool `toml:"prometheus_sort_metrics"`
-	StringAsLabel bool `toml:"prometheus_string_as_label"`
+	SortMetrics      bool            `toml:"prometheus_sort_metrics"`
+	StringAsLabel    bool            `toml:"prometheus_string_as_label"`
+	Version          string          `toml:"prometheus_remote_write_version"`
+	NativeHistograms bool            `toml:"prometheus_native_histograms"`
+	SendMetadata     bool            `toml:"prometheus_send_metadata"`
+	StalenessTimeout config.Duration `toml:"prometheus_staleness_timeout"`
+
+	// Series sent previously, used for generating staleness markers
+	seen map[MetricKey]*seenSeries
+}
+
+type seenSeries struct {
+	labels    []prompb.Label
+	histogram bool
+	last      time.Time
+}
+
+type histogramEntry struct {
+	labels    []prompb.Label
+	timestamp time.Time
+	buckets   map[float64]uint64
+	count     uint64
+	sum       float64
+}
+
+type family struct {
+	name  string
+	vtype telegraf.ValueType
+}
+
+func (s *Serializer) Init() error {
+	switch s.Version {
+	case "":
+		s.Version = "1.0"
+	case "1.0", "2.0":
+	default:
+		return fmt.Errorf("invalid remote write version %q", s.Version)
+	}
+
+	if s.NativeHistograms && s.Version != "2.0" {
+		return errors.New("native histograms require remote write version 2.0")
+	}
+
+	if s.StalenessTimeout > 0 {
+		s.seen = make(map[MetricKey]*seenSeries)
+	}
+
+	return nil
 }
 
 func (s *Serializer) Serialize(metric telegraf.Metric) ([]byte, error) {
@@ -32,8 +82,15 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 	var buf bytes.Buffer
 
 	var entries = make(map[MetricKey]prompb.TimeSeries)
+	var families = make(map[MetricKey]family)
+	var histograms = make(map[MetricKey]*histogramEntry)
 	var labels = make([]prompb.Label, 0)
 	for _, metric := range metrics {
+		// Events have no representation in Prometheus
+		if metric.Type() == telegraf.Event {
+			continue
+		}
+
 		labels = s.appendCommonLabels(labels[:0], metric)
 		var metrickey MetricKey
 		var promts prompb.TimeSeries
@@ -44,6 +101,20 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 				continue
 			}
 
+			// Native histogram and summary values are complete by themselves
+			switch v := field.Value.(type) {
+			case *telegraf.HistogramValue:
+				if s.NativeHistograms {
+					addHistogramValue(histograms, metricName, labels, v, metric.Time())
+				} else {
+					addClassicHistogram(entries, families, metricName, labels, v, metric.Time())
+				}
+				continue
+			case *telegraf.SummaryValue:
+				addSummary(entries, families, metricName, labels, v, metric.Time())
+				continue
+			}
+
 			switch metric.Type() {
 			case telegraf.Counter:
 				fallthrough
@@ -56,16 +127,22 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 				}
 				metrickey, promts = getPromTS(metricName, labels, value, metric.Time())
 			case telegraf.Histogram:
+				if s.NativeHistograms {
+					s.addNativeHistogram(histograms, metricName, labels, field, metric)
+					continue
+				}
 				switch {
 				case strings.HasSuffix(field.Key, "_bucket"):
 					// if bucket only, init sum, count, inf
 					metrickeysum, promtssum := getPromTS(fmt.Sprintf("%s_sum", metricName), labels, float64(0), metric.Time())
 					if _, ok = entries[metrickeysum]; !ok {
 						entries[metrickeysum] = promtssum
+						families[metrickeysum] = family{metricName, metric.Type()}
 					}
 					metrickeycount, promtscount := getPromTS(fmt.Sprintf("%s_count", metricName), labels, float64(0), metric.Time())
 					if _, ok = entries[metrickeycount]; !ok {
 						entries[metrickeycount] = promtscount
+						families[metrickeycount] = family{metricName, metric.Type()}
 					}
 					extraLabel := prompb.Label{
 						Name:  "le",
@@ -74,6 +151,7 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 					metrickeyinf, promtsinf := getPromTS(fmt.Sprintf("%s_bucket", metricName), labels, float64(0), metric.Time(), extraLabel)
 					if _, ok = entries[metrickeyinf]; !ok {
 						entries[metrickeyinf] = promtsinf
+						families[metrickeyinf] = family{metricName, metric.Type()}
 					}
 
 					le, ok := metric.GetTag("le")
@@ -115,6 +193,7 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 					metrickeyinf, promtsinf := getPromTS(fmt.Sprintf("%s_bucket", metricName), labels, float64(count), metric.Time(), extraLabel)
 					if minf, ok := entries[metrickeyinf]; !ok || minf.Samples[0].Value == 0 {
 						entries[metrickeyinf] = promtsinf
+						families[metrickeyinf] = family{metricName, metric.Type()}
 					}
 
 					metrickey, promts = getPromTS(fmt.Sprintf("%s_count", metricName), labels, float64(count), metric.Time())
@@ -171,48 +250,350 @@ func (s *Serializer) SerializeBatch(metrics []telegraf.Metric) ([]byte, error) {
 				}
 			}
 			entries[metrickey] = promts
+			families[metrickey] = family{metricName, metric.Type()}
 		}
 	}
 
-	var promTS = make([]prompb.TimeSeries, len(entries))
-	var i int
+	var promTS = make([]prompb.TimeSeries, 0, len(entries))
 	for _, promts := range entries {
-		promTS[i] = promts
-		i++
+		promTS = append(promTS, promts)
+	}
+	var nativeTS = make([]seriesV2, 0, len(histograms))
+	for _, h := range histograms {
+		nativeTS = append(nativeTS, h.series())
+	}
+
+	// Add staleness markers for series we did not see for a while
+	if s.seen != nil {
+		staleTS, staleNativeTS := s.updateSeen(entries, histograms, time.Now())
+		promTS = append(promTS, staleTS...)
+		nativeTS = append(nativeTS, staleNativeTS...)
 	}
 
 	if s.SortMetrics {
 		sort.Slice(promTS, func(i, j int) bool {
-			lhs := promTS[i].Labels
-			rhs := promTS[j].Labels
-			if len(lhs) != len(rhs) {
-				return len(lhs) < len(rhs)
-			}
+			return lessLabels(promTS[i].Labels, promTS[j].Labels)
+		})
+		sort.Slice(nativeTS, func(i, j int) bool {
+			return lessLabels(nativeTS[i].labels, nativeTS[j].labels)
+		})
+	}
 
-			for index := range lhs {
-				l := lhs[index]
-				r := rhs[index]
+	var data []byte
+	if s.Version == "2.0" {
+		series := make([]seriesV2, 0, len(promTS)+len(nativeTS))
+		for _, ts := range promTS {
+			series = append(series, seriesV2{
+				labels:     ts.Labels,
+				samples:    ts.Samples,
+				metricType: metricTypeV2(families[MakeMetricKey(ts.Labels)].vtype),
+			})
+		}
+		series = append(series, nativeTS...)
+		data = marshalRequestV2(series, s.SendMetadata)
+	} else {
+		pb := &prompb.WriteRequest{Timeseries: promTS}
+		if s.SendMetadata {
+			pb.Metadata = metadataV1(families)
+		}
+		var err error
+		data, err = pb.Marshal()
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal protobuf: %w", err)
+		}
+	}
+	encoded := snappy.Encode(nil, data)
+	buf.Write(encoded)
+	return buf.Bytes(), nil
+}
 
-				if l.Name != r.Name {
-					return l.Name < r.Name
-				}
+// addHistogramValue adds the histogram field value as native histogram
+func addHistogramValue(
+	histograms map[MetricKey]*histogramEntry,
+	name string,
+	labels []prompb.Label,
+	v *telegraf.HistogramValue,
+	ts time.Time,
+) {
+	key, series := getPromTS(name, labels, 0, ts)
+	if entry, found := histograms[key]; found && ts.Before(entry.timestamp) {
+		return
+	}
 
-				if l.Value != r.Value {
-					return l.Value < r.Value
-				}
-			}
+	entry := &histogramEntry{
+		labels:    series.Labels,
+		timestamp: ts,
+		buckets:   make(map[float64]uint64, len(v.Buckets)),
+		sum:       v.Sum,
+		count:     v.Count,
+	}
+	for _, b := range v.Buckets {
+		entry.buckets[b.UpperBound] = b.Count
+	}
+	histograms[key] = entry
+}
+
+// addClassicHistogram adds the histogram field value as bucket, sum and count
+// series
+func addClassicHistogram(
+	entries map[MetricKey]prompb.TimeSeries,
+	families map[MetricKey]family,
+	name string,
+	labels []prompb.Label,
+	v *telegraf.HistogramValue,
+	ts time.Time,
+) {
+	f := family{name, telegraf.Histogram}
+	infSeen := false
+	for _, b := range v.Buckets {
+		le := prompb.Label{Name: "le", Value: fmt.Sprint(b.UpperBound)}
+		if math.IsInf(b.UpperBound, 1) {
+			le.Value = "+Inf"
+			infSeen = true
+		}
+		addSeries(entries, families, f, name+"_bucket", labels, float64(b.Count), ts, le)
+	}
+	if !infSeen {
+		le := prompb.Label{Name: "le", Value: "+Inf"}
+		addSeries(entries, families, f, name+"_bucket", labels, float64(v.Count), ts, le)
+	}
+	addSeries(entries, families, f, name+"_sum", labels, v.Sum, ts)
+	addSeries(entries, families, f, name+"_count", labels, float64(v.Count), ts)
+}
+
+// addSummary adds the summary field value as quantile, sum and count series
+func addSummary(
+	entries map[MetricKey]prompb.TimeSeries,
+	families map[MetricKey]family,
+	name string,
+	labels []prompb.Label,
+	v *telegraf.SummaryValue,
+	ts time.Time,
+) {
+	f := family{name, telegraf.Summary}
+	for _, q := range v.Quantiles {
+		quantile := prompb.Label{Name: "quantile", Value: fmt.Sprint(q.Quantile)}
+		addSeries(entries, families, f, name, labels, q.Value, ts, quantile)
+	}
+	addSeries(entries, families, f, name+"_sum", labels, v.Sum, ts)
+	addSeries(entries, families, f, name+"_count", labels, float64(v.Count), ts)
+}
+
+// addSeries adds the sample unless the batch already contains a newer one for
+// the same series
+func addSeries(
+	entries map[MetricKey]prompb.TimeSeries,
+	families map[MetricKey]family,
+	f family,
+	name string,
+	labels []prompb.Label,
+	value float64,
+	ts time.Time,
+	extraLabels ...prompb.Label,
+) {
+	key, series := getPromTS(name, labels, value, ts, extraLabels...)
+	if m, ok := entries[key]; ok && series.Samples[0].Timestamp < m.Samples[0].Timestamp {
+		return
+	}
+	entries[key] = series
+	families[key] = f
+}
 
-			return false
+func (s *Serializer) addNativeHistogram(
+	histograms map[MetricKey]*histogramEntry,
+	name string,
+	labels []prompb.Label,
+	field *telegraf.Field,
+	metric telegraf.Metric,
+) {
+	key, series := getPromTS(name, labels, 0, metric.Time())
+
+	// Only keep the newest histogram of a series within the batch
+	entry, found := histograms[key]
+	if found && metric.Time().Before(entry.timestamp) {
+		return
+	}
+	if !found || entry.timestamp.Before(metric.Time()) {
+		entry = &histogramEntry{
+			labels:    series.Labels,
+			timestamp: metric.Time(),
+			buckets:   make(map[float64]uint64),
+		}
+		histograms[key] = entry
+	}
+
+	switch {
+	case strings.HasSuffix(field.Key, "_bucket"):
+		le, ok := metric.GetTag("le")
+		if !ok {
+			return
+		}
+		bound, err := strconv.ParseFloat(le, 64)
+		if err != nil {
+			return
+		}
+		if count, ok := prometheus.SampleCount(field.Value); ok {
+			entry.buckets[bound] = count
+		}
+	case strings.HasSuffix(field.Key, "_sum"):
+		if sum, ok := prometheus.SampleSum(field.Value); ok {
+			entry.sum = sum
+		}
+	case strings.HasSuffix(field.Key, "_count"):
+		if count, ok := prometheus.SampleCount(field.Value); ok {
+			entry.count = count
+		}
+	}
+}
+
+// series converts the cumulative classic buckets into a native histogram
+// with custom bucket boundaries
+func (h *histogramEntry) series() seriesV2 {
+	bounds := make([]float64, 0, len(h.buckets))
+	for bound := range h.buckets {
+		if !math.IsInf(bound, 1) {
+			bounds = append(bounds, bound)
+		}
+	}
+	sort.Float64s(bounds)
+
+	count := h.count
+	if inf, found := h.buckets[math.Inf(1)]; found && count == 0 {
+		count = inf
+	}
+
+	// Native histogram buckets are non-cumulative with an implicit +Inf
+	// bucket at the end
+	counts := make([]uint64, 0, len(bounds)+1)
+	var previous uint64
+	for _, bound := range bounds {
+		cumulative := h.buckets[bound]
+		if cumulative < previous {
+			cumulative = previous
+		}
+		counts = append(counts, cumulative-previous)
+		previous = cumulative
+	}
+	if count < previous {
+		count = previous
+	}
+	counts = append(counts, count-previous)
+
+	return seriesV2{
+		labels: h.labels,
+		histograms: []nativeHistogram{{
+			count:        count,
+			sum:          h.sum,
+			upperBounds:  bounds,
+			bucketCounts: counts,
+			timestamp:    h.timestamp.UnixNano() / int64(time.Millisecond),
+		}},
+		metricType: metricTypeHistogram,
+	}
+}
+
+// updateSeen records the series of the current batch and returns staleness
+// markers for all series not seen within the staleness timeout.
+func (s *Serializer) updateSeen(
+	entries map[MetricKey]prompb.TimeSeries,
+	histograms map[MetricKey]*histogramEntry,
+	now time.Time,
+) ([]prompb.TimeSeries, []seriesV2) {
+	for key, ts := range entries {
+		s.seen[key] = &seenSeries{labels: ts.Labels, last: now}
+	}
+	for key, h := range histograms {
+		s.seen[key] = &seenSeries{labels: h.labels, histogram: true, last: now}
+	}
+
+	timeout := time.Duration(s.StalenessTimeout)
+	timestamp := now.UnixNano() / int64(time.Millisecond)
+
+	var staleTS []prompb.TimeSeries
+	var staleNativeTS []seriesV2
+	for key, series := range s.seen {
+		if now.Sub(series.last) < timeout {
+			continue
+		}
+		delete(s.seen, key)
+
+		if series.histogram {
+			staleNativeTS = append(staleNativeTS, seriesV2{
+				labels:     series.labels,
+				histograms: []nativeHistogram{{timestamp: timestamp, stale: true}},
+				metricType: metricTypeHistogram,
+			})
+			continue
+		}
+		staleTS = append(staleTS, prompb.TimeSeries{
+			Labels:  series.labels,
+			Samples: []prompb.Sample{{Timestamp: timestamp, Value: math.Float64frombits(value.StaleNaN)}},
 		})
 	}
-	pb := &prompb.WriteRequest{Timeseries: promTS}
-	data, err := pb.Marshal()
-	if err != nil {
-		return nil, fmt.Errorf("unable to marshal protobuf: %w", err)
+
+	return staleTS, staleNativeTS
+}
+
+func metricTypeV2(vtype telegraf.ValueType) uint64 {
+	switch vtype {
+	case telegraf.Counter:
+		return metricTypeCounter
+	case telegraf.Gauge:
+		return metricTypeGauge
+	case telegraf.Histogram:
+		return metricTypeHistogram
+	case telegraf.Summary:
+		return metricTypeSummary
 	}
-	encoded := snappy.Encode(nil, data)
-	buf.Write(encoded)
-	return buf.Bytes(), nil
+	return metricTypeUnspecified
+}
+
+func metadataV1(families map[MetricKey]family) []prompb.MetricMetadata {
+	types := make(map[string]prompb.MetricMetadata_MetricType)
+	for _, f := range families {
+		switch f.vtype {
+		case telegraf.Counter:
+			types[f.name] = prompb.MetricMetadata_COUNTER
+		case telegraf.Gauge:
+			types[f.name] = prompb.MetricMetadata_GAUGE
+		case telegraf.Histogram:
+			types[f.name] = prompb.MetricMetadata_HISTOGRAM
+		case telegraf.Summary:
+			types[f.name] = prompb.MetricMetadata_SUMMARY
+		default:
+			types[f.name] = prompb.MetricMetadata_UNKNOWN
+		}
+	}
+
+	metadata := make([]prompb.MetricMetadata, 0, len(types))
+	for name, mtype := range types {
+		metadata = append(metadata, prompb.MetricMetadata{Type: mtype, MetricFamilyName: name})
+	}
+	sort.Slice(metadata, func(i, j int) bool {
+		return metadata[i].MetricFamilyName < metadata[j].MetricFamilyName
+	})
+	return metadata
+}
+
+func lessLabels(lhs, rhs []prompb.Label) bool {
+	if len(lhs) != len(rhs) {
+		return len(lhs) < len(rhs)
+	}
+
+	for index := range lhs {
+		l := lhs[index]
+		r := rhs[index]
+
+		if l.Name != r.Name {
+			return l.Name < r.Name
+		}
+
+		if l.Value != r.Value {
+			return l.Value < r.Value
+		}
+	}
+
+	return false
 }
 
 func hasLabel(name string, labels []prompb.Label) bool {
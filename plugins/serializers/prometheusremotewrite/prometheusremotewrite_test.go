@@ -3,16 +3,20 @@ package prometheusremotewrite
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/golang/snappy"
 	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
 	"github.com/prometheus/prometheus/prompb"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/config"
 	"github.com/influxdata/telegraf/testutil"
 )
 
@@ -180,6 +184,57 @@ http_request_duration_seconds_count 0
 http_request_duration_seconds_sum 0
 http_request_duration_seconds_bucket{le="+Inf"} 0
 http_request_duration_seconds_bucket{le="0.5"} 129389
+`),
+		},
+		{
+			name: "histogram field value",
+			metric: testutil.MustMetric(
+				"prometheus",
+				map[string]string{},
+				map[string]interface{}{
+					"http_request_duration_seconds": &telegraf.HistogramValue{
+						Count: 144320,
+						Sum:   53423,
+						Buckets: []telegraf.Bucket{
+							{UpperBound: 0.05, Count: 24054},
+							{UpperBound: 0.5, Count: 129389},
+						},
+					},
+				},
+				time.Unix(0, 0),
+				telegraf.Histogram,
+			),
+			expected: []byte(`
+http_request_duration_seconds_count 144320
+http_request_duration_seconds_sum 53423
+http_request_duration_seconds_bucket{le="+Inf"} 144320
+http_request_duration_seconds_bucket{le="0.05"} 24054
+http_request_duration_seconds_bucket{le="0.5"} 129389
+`),
+		},
+		{
+			name: "summary field value",
+			metric: testutil.MustMetric(
+				"prometheus",
+				map[string]string{},
+				map[string]interface{}{
+					"rpc_duration_seconds": &telegraf.SummaryValue{
+						Count: 2693,
+						Sum:   17560473,
+						Quantiles: []telegraf.Quantile{
+							{Quantile: 0.5, Value: 4773},
+							{Quantile: 0.9, Value: 9001},
+						},
+					},
+				},
+				time.Unix(0, 0),
+				telegraf.Summary,
+			),
+			expected: []byte(`
+rpc_duration_seconds_count 2693
+rpc_duration_seconds_sum 17560473
+rpc_duration_seconds{quantile="0.5"} 4773
+rpc_duration_seconds{quantile="0.9"} 9001
 `),
 		},
 	}
@@ -692,6 +747,356 @@ rpc_duration_seconds_sum 17560473
 	}
 }
 
+func TestInitInvalid(t *testing.T) {
+	s := &Serializer{Version: "3.0"}
+	require.ErrorContains(t, s.Init(), "invalid remote write version")
+
+	s = &Serializer{NativeHistograms: true}
+	require.ErrorContains(t, s.Init(), "require remote write version 2.0")
+}
+
+func TestRemoteWriteMetadata(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{},
+			map[string]interface{}{
+				"http_requests_total": 3.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{},
+			map[string]interface{}{
+				"rpc_duration_seconds_sum":   1.7560473e+07,
+				"rpc_duration_seconds_count": 2693,
+			},
+			time.Unix(0, 0),
+			telegraf.Summary,
+		),
+	}
+
+	s := &Serializer{SendMetadata: true}
+	require.NoError(t, s.Init())
+	data, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	protobuff, err := snappy.Decode(nil, data)
+	require.NoError(t, err)
+	var req prompb.WriteRequest
+	require.NoError(t, req.Unmarshal(protobuff))
+
+	expected := []prompb.MetricMetadata{
+		{Type: prompb.MetricMetadata_COUNTER, MetricFamilyName: "http_requests_total"},
+		{Type: prompb.MetricMetadata_SUMMARY, MetricFamilyName: "rpc_duration_seconds"},
+	}
+	require.Equal(t, expected, req.Metadata)
+}
+
+func TestRemoteWriteStalenessMarkers(t *testing.T) {
+	cpu0 := testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu0"},
+		map[string]interface{}{"time_idle": 42.0},
+		time.Unix(0, 0),
+	)
+	cpu1 := testutil.MustMetric(
+		"cpu",
+		map[string]string{"cpu": "cpu1"},
+		map[string]interface{}{"time_idle": 43.0},
+		time.Unix(0, 0),
+	)
+
+	s := &Serializer{
+		SortMetrics:      true,
+		StalenessTimeout: config.Duration(time.Nanosecond),
+	}
+	require.NoError(t, s.Init())
+
+	data, err := s.SerializeBatch([]telegraf.Metric{cpu0, cpu1})
+	require.NoError(t, err)
+	actual, err := prompbToText(data)
+	require.NoError(t, err)
+	require.Equal(t, "cpu_time_idle{cpu=\"cpu0\"} 42\ncpu_time_idle{cpu=\"cpu1\"} 43", strings.TrimSpace(string(actual)))
+
+	// The second series disappeared so we expect a staleness marker
+	data, err = s.SerializeBatch([]telegraf.Metric{cpu0})
+	require.NoError(t, err)
+	protobuff, err := snappy.Decode(nil, data)
+	require.NoError(t, err)
+	var req prompb.WriteRequest
+	require.NoError(t, req.Unmarshal(protobuff))
+	require.Len(t, req.Timeseries, 2)
+
+	var stale []string
+	for _, ts := range req.Timeseries {
+		require.Len(t, ts.Samples, 1)
+		if math.Float64bits(ts.Samples[0].Value) != value.StaleNaN {
+			continue
+		}
+		for _, l := range ts.Labels {
+			if l.Name == "cpu" {
+				stale = append(stale, l.Value)
+			}
+		}
+	}
+	require.Equal(t, []string{"cpu1"}, stale)
+
+	// Stale series are only reported once
+	data, err = s.SerializeBatch([]telegraf.Metric{cpu0})
+	require.NoError(t, err)
+	actual, err = prompbToText(data)
+	require.NoError(t, err)
+	require.Equal(t, "cpu_time_idle{cpu=\"cpu0\"} 42", strings.TrimSpace(string(actual)))
+}
+
+func TestRemoteWriteV2(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"code": "400"},
+			map[string]interface{}{
+				"http_requests_total": 3.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Counter,
+		),
+		testutil.MustMetric(
+			"cpu",
+			map[string]string{},
+			map[string]interface{}{
+				"time_idle": 42.0,
+			},
+			time.Unix(1, 0),
+			telegraf.Gauge,
+		),
+	}
+
+	s := &Serializer{
+		Version:      "2.0",
+		SortMetrics:  true,
+		SendMetadata: true,
+	}
+	require.NoError(t, s.Init())
+	data, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := []decodedSeriesV2{
+		{
+			labels:     map[string]string{"__name__": "cpu_time_idle"},
+			samples:    []prompb.Sample{{Value: 42, Timestamp: 1000}},
+			metricType: metricTypeGauge,
+		},
+		{
+			labels:     map[string]string{"__name__": "http_requests_total", "code": "400"},
+			samples:    []prompb.Sample{{Value: 3, Timestamp: 0}},
+			metricType: metricTypeCounter,
+		},
+	}
+	require.Equal(t, expected, decodeRequestV2(t, data))
+}
+
+func TestRemoteWriteV2NativeHistogram(t *testing.T) {
+	metrics := []telegraf.Metric{
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{},
+			map[string]interface{}{
+				"http_request_duration_seconds_sum":   53423,
+				"http_request_duration_seconds_count": 144320,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"le": "0.5"},
+			map[string]interface{}{
+				"http_request_duration_seconds_bucket": 129389.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"le": "0.05"},
+			map[string]interface{}{
+				"http_request_duration_seconds_bucket": 24054.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+		testutil.MustMetric(
+			"prometheus",
+			map[string]string{"le": "+Inf"},
+			map[string]interface{}{
+				"http_request_duration_seconds_bucket": 144320.0,
+			},
+			time.Unix(0, 0),
+			telegraf.Histogram,
+		),
+	}
+
+	s := &Serializer{
+		Version:          "2.0",
+		NativeHistograms: true,
+		SendMetadata:     true,
+	}
+	require.NoError(t, s.Init())
+	data, err := s.SerializeBatch(metrics)
+	require.NoError(t, err)
+
+	expected := []decodedSeriesV2{
+		{
+			labels: map[string]string{"__name__": "http_request_duration_seconds"},
+			histograms: []decodedHistogramV2{
+				{
+					count:        144320,
+					sum:          53423,
+					schema:       customBucketsSchema,
+					customValues: []float64{0.05, 0.5},
+					bucketCounts: []int64{24054, 105335, 14931},
+				},
+			},
+			metricType: metricTypeHistogram,
+		},
+	}
+	require.Equal(t, expected, decodeRequestV2(t, data))
+}
+
+type decodedHistogramV2 struct {
+	count        uint64
+	sum          float64
+	schema       int32
+	customValues []float64
+	bucketCounts []int64
+	timestamp    int64
+}
+
+type decodedSeriesV2 struct {
+	labels     map[string]string
+	samples    []prompb.Sample
+	histograms []decodedHistogramV2
+	metricType uint64
+}
+
+// decodeRequestV2 is a minimal decoder for remote-write 2.0 requests
+func decodeRequestV2(t *testing.T, data []byte) []decodedSeriesV2 {
+	t.Helper()
+
+	buf, err := snappy.Decode(nil, data)
+	require.NoError(t, err)
+
+	var symbols []string
+	var raw [][]byte
+	forEachField(t, buf, func(num protowire.Number, typ protowire.Type, v []byte, _ uint64) {
+		switch num {
+		case 4:
+			symbols = append(symbols, string(v))
+		case 5:
+			raw = append(raw, v)
+		}
+	})
+	require.NotEmpty(t, symbols)
+	require.Empty(t, symbols[0])
+
+	series := make([]decodedSeriesV2, 0, len(raw))
+	for _, r := range raw {
+		var s decodedSeriesV2
+		forEachField(t, r, func(num protowire.Number, _ protowire.Type, v []byte, _ uint64) {
+			switch num {
+			case 1:
+				s.labels = make(map[string]string)
+				for len(v) > 0 {
+					name, n := protowire.ConsumeVarint(v)
+					v = v[n:]
+					val, n := protowire.ConsumeVarint(v)
+					v = v[n:]
+					s.labels[symbols[name]] = symbols[val]
+				}
+			case 2:
+				var sample prompb.Sample
+				forEachField(t, v, func(num protowire.Number, _ protowire.Type, _ []byte, x uint64) {
+					switch num {
+					case 1:
+						sample.Value = math.Float64frombits(x)
+					case 2:
+						sample.Timestamp = int64(x)
+					}
+				})
+				s.samples = append(s.samples, sample)
+			case 3:
+				var h decodedHistogramV2
+				forEachField(t, v, func(num protowire.Number, _ protowire.Type, b []byte, x uint64) {
+					switch num {
+					case 1:
+						h.count = x
+					case 3:
+						h.sum = math.Float64frombits(x)
+					case 4:
+						h.schema = int32(protowire.DecodeZigZag(x))
+					case 12:
+						var previous int64
+						for len(b) > 0 {
+							d, n := protowire.ConsumeVarint(b)
+							b = b[n:]
+							previous += protowire.DecodeZigZag(d)
+							h.bucketCounts = append(h.bucketCounts, previous)
+						}
+					case 15:
+						h.timestamp = int64(x)
+					case 16:
+						for len(b) > 0 {
+							f, n := protowire.ConsumeFixed64(b)
+							b = b[n:]
+							h.customValues = append(h.customValues, math.Float64frombits(f))
+						}
+					}
+				})
+				s.histograms = append(s.histograms, h)
+			case 5:
+				forEachField(t, v, func(num protowire.Number, _ protowire.Type, _ []byte, x uint64) {
+					if num == 1 {
+						s.metricType = x
+					}
+				})
+			}
+		})
+		series = append(series, s)
+	}
+	return series
+}
+
+func forEachField(t *testing.T, buf []byte, fn func(protowire.Number, protowire.Type, []byte, uint64)) {
+	for len(buf) > 0 {
+		num, typ, n := protowire.ConsumeTag(buf)
+		require.GreaterOrEqual(t, n, 0)
+		buf = buf[n:]
+
+		switch typ {
+		case protowire.VarintType:
+			v, n := protowire.ConsumeVarint(buf)
+			require.GreaterOrEqual(t, n, 0)
+			buf = buf[n:]
+			fn(num, typ, nil, v)
+		case protowire.Fixed64Type:
+			v, n := protowire.ConsumeFixed64(buf)
+			require.GreaterOrEqual(t, n, 0)
+			buf = buf[n:]
+			fn(num, typ, nil, v)
+		case protowire.BytesType:
+			v, n := protowire.ConsumeBytes(buf)
+			require.GreaterOrEqual(t, n, 0)
+			buf = buf[n:]
+			fn(num, typ, v, 0)
+		default:
+			require.Failf(t, "unexpected wire type", "type %d", typ)
+		}
+	}
+}
+
 func prompbToText(data []byte) ([]byte, error) {
 	var buf = bytes.Buffer{}
 	protobuff, err := snappy.Decode(nil, data)
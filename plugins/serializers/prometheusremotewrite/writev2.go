@@ -0,0 +1,180 @@
+package prometheusremotewrite
+
+import (
+	"math"
+
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The types and encoding in this file implement the wire format of the
+// Prometheus remote-write 2.0 specification (io.prometheus.write.v2.Request),
+// see https://prometheus.io/docs/specs/remote_write_spec_2_0/.
+//
+// The upstream Prometheus module we depend on does not ship the generated
+// code for the 2.0 protocol, so we encode the (small) message set manually.
+
+// Schema used for native histograms with custom bucket boundaries (NHCB).
+// This is the representation for classic, explicitly bucketed histograms.
+const customBucketsSchema = -53
+
+// Metric types as defined by io.prometheus.write.v2.Metadata.MetricType
+const (
+	metricTypeUnspecified uint64 = 0
+	metricTypeCounter     uint64 = 1
+	metricTypeGauge       uint64 = 2
+	metricTypeHistogram   uint64 = 3
+	metricTypeSummary     uint64 = 5
+)
+
+type symbolTable struct {
+	symbols []string
+	index   map[string]uint32
+}
+
+func newSymbolTable() *symbolTable {
+	// The specification requires the first symbol to be the empty string
+	return &symbolTable{
+		symbols: []string{""},
+		index:   map[string]uint32{"": 0},
+	}
+}
+
+func (st *symbolTable) ref(s string) uint32 {
+	if idx, found := st.index[s]; found {
+		return idx
+	}
+	idx := uint32(len(st.symbols))
+	st.symbols = append(st.symbols, s)
+	st.index[s] = idx
+	return idx
+}
+
+type nativeHistogram struct {
+	count        uint64
+	sum          float64
+	upperBounds  []float64
+	bucketCounts []uint64
+	timestamp    int64
+	stale        bool
+}
+
+type seriesV2 struct {
+	labels     []prompb.Label
+	samples    []prompb.Sample
+	histograms []nativeHistogram
+	metricType uint64
+}
+
+func marshalRequestV2(series []seriesV2, withMetadata bool) []byte {
+	symbols := newSymbolTable()
+
+	var encodedSeries [][]byte
+	for _, s := range series {
+		encodedSeries = append(encodedSeries, appendTimeSeriesV2(nil, symbols, s, withMetadata))
+	}
+
+	var buf []byte
+	for _, sym := range symbols.symbols {
+		buf = protowire.AppendTag(buf, 4, protowire.BytesType)
+		buf = protowire.AppendString(buf, sym)
+	}
+	for _, ts := range encodedSeries {
+		buf = protowire.AppendTag(buf, 5, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, ts)
+	}
+	return buf
+}
+
+func appendTimeSeriesV2(buf []byte, symbols *symbolTable, s seriesV2, withMetadata bool) []byte {
+	// Labels are references into the symbol table in name, value order
+	var refs []byte
+	for _, l := range s.labels {
+		refs = protowire.AppendVarint(refs, uint64(symbols.ref(l.Name)))
+		refs = protowire.AppendVarint(refs, uint64(symbols.ref(l.Value)))
+	}
+	buf = protowire.AppendTag(buf, 1, protowire.BytesType)
+	buf = protowire.AppendBytes(buf, refs)
+
+	for _, sample := range s.samples {
+		var sbuf []byte
+		sbuf = protowire.AppendTag(sbuf, 1, protowire.Fixed64Type)
+		sbuf = protowire.AppendFixed64(sbuf, math.Float64bits(sample.Value))
+		sbuf = protowire.AppendTag(sbuf, 2, protowire.VarintType)
+		sbuf = protowire.AppendVarint(sbuf, uint64(sample.Timestamp))
+
+		buf = protowire.AppendTag(buf, 2, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, sbuf)
+	}
+
+	for _, h := range s.histograms {
+		buf = protowire.AppendTag(buf, 3, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, appendHistogramV2(nil, h))
+	}
+
+	if withMetadata {
+		var mbuf []byte
+		if s.metricType != metricTypeUnspecified {
+			mbuf = protowire.AppendTag(mbuf, 1, protowire.VarintType)
+			mbuf = protowire.AppendVarint(mbuf, s.metricType)
+		}
+		buf = protowire.AppendTag(buf, 5, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, mbuf)
+	}
+
+	return buf
+}
+
+func appendHistogramV2(buf []byte, h nativeHistogram) []byte {
+	if h.stale {
+		// A stale marker for a histogram series is signaled by the sum
+		buf = protowire.AppendTag(buf, 3, protowire.Fixed64Type)
+		buf = protowire.AppendFixed64(buf, value.StaleNaN)
+		buf = protowire.AppendTag(buf, 4, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, protowire.EncodeZigZag(customBucketsSchema))
+		buf = protowire.AppendTag(buf, 15, protowire.VarintType)
+		buf = protowire.AppendVarint(buf, uint64(h.timestamp))
+		return buf
+	}
+
+	buf = protowire.AppendTag(buf, 1, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, h.count)
+	buf = protowire.AppendTag(buf, 3, protowire.Fixed64Type)
+	buf = protowire.AppendFixed64(buf, math.Float64bits(h.sum))
+	buf = protowire.AppendTag(buf, 4, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, protowire.EncodeZigZag(customBucketsSchema))
+
+	if len(h.bucketCounts) > 0 {
+		// All buckets are encoded in a single span starting at offset zero
+		var span []byte
+		span = protowire.AppendTag(span, 2, protowire.VarintType)
+		span = protowire.AppendVarint(span, uint64(len(h.bucketCounts)))
+		buf = protowire.AppendTag(buf, 11, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, span)
+
+		// Bucket counts are delta encoded against the previous bucket
+		var deltas []byte
+		var previous int64
+		for _, c := range h.bucketCounts {
+			deltas = protowire.AppendVarint(deltas, protowire.EncodeZigZag(int64(c)-previous))
+			previous = int64(c)
+		}
+		buf = protowire.AppendTag(buf, 12, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, deltas)
+	}
+
+	buf = protowire.AppendTag(buf, 15, protowire.VarintType)
+	buf = protowire.AppendVarint(buf, uint64(h.timestamp))
+
+	if len(h.upperBounds) > 0 {
+		var bounds []byte
+		for _, b := range h.upperBounds {
+			bounds = protowire.AppendFixed64(bounds, math.Float64bits(b))
+		}
+		buf = protowire.AppendTag(buf, 16, protowire.BytesType)
+		buf = protowire.AppendBytes(buf, bounds)
+	}
+
+	return buf
+}
@@ -87,13 +87,36 @@ type Config struct {
 	// Maximum line length in bytes; influx format only
 	InfluxMaxLineBytes int `toml:"influx_max_line_bytes"`
 
+	// Drop fields not fitting into the maximum line length instead of the
+	// whole metric; influx format only
+	InfluxDropOversizeFields bool `toml:"influx_drop_oversize_fields"`
+
 	// Sort field keys, set to true only when debugging as it less performant
 	// than unsorted fields; influx format only
 	InfluxSortFields bool `toml:"influx_sort_fields"`
 
+	// Sort tag keys independent of the metric implementation; influx format only
+	InfluxSortTags bool `toml:"influx_sort_tags"`
+
 	// Support unsigned integer output; influx format only
 	InfluxUintSupport bool `toml:"influx_uint_support"`
 
+	// Handling of unsigned integers exceeding the int64 range if unsigned
+	// integers are not supported; influx format only
+	InfluxUintOverflow string `toml:"influx_uint_overflow"`
+
+	// Precision to truncate the timestamps to; influx format only
+	InfluxTimestampPrecision string `toml:"influx_timestamp_precision"`
+
+	// Escape line breaks in string fields; influx format only
+	InfluxEscapeNewlines bool `toml:"influx_escape_newlines"`
+
+	// Layout of the MessagePack output, either "metric" or "columnar"
+	MsgpackLayout string `toml:"msgpack_layout"`
+
+	// Encode tag values using a dictionary; msgpack columnar layout only
+	MsgpackTagDictionary bool `toml:"msgpack_tag_dictionary"`
+
 	// Prefix to add to all measurements, only supports Graphite
 	Prefix string `toml:"prefix"`
 
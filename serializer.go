@@ -1,5 +1,7 @@
 package telegraf
 
+import "io"
+
 // SerializerPlugin is an interface for plugins that are able to
 // serialize telegraf metrics into arbitrary data formats.
 type SerializerPlugin interface {
@@ -26,3 +28,12 @@ type Serializer interface {
 	// line oriented framing.
 	SerializeBatch(metrics []Metric) ([]byte, error)
 }
+
+// BatchSerializer is an optional interface for serializers able to write a
+// batch of metrics directly to a writer. This avoids allocating a new byte
+// slice for each batch as done by SerializeBatch.
+type BatchSerializer interface {
+	// SerializeBatchTo serializes the metrics in the same way as
+	// SerializeBatch but writes the result to the given writer.
+	SerializeBatchTo(w io.Writer, metrics []Metric) error
+}